@@ -0,0 +1,40 @@
+package backend
+
+import (
+	"sync/atomic"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+)
+
+// SetSenderAuthentication enables or disables checking that the IDs claimed by received messages
+// match the identity of the replica that sent them. It is enabled by default.
+// Test networks that intentionally send messages on behalf of other replicas must disable it.
+func (srv *Server) SetSenderAuthentication(enabled bool) {
+	srv.skipAuth = !enabled
+}
+
+// Impersonations returns the number of messages that were dropped because the ID claimed by the message
+// did not match the replica that sent it.
+func (srv *Server) Impersonations() uint64 {
+	return atomic.LoadUint64(&srv.impersonations)
+}
+
+// authenticate returns true if the signer of the signature, if any, is the replica that sent the message.
+func (srv *Server) authenticate(sender hotstuff.ID, class MessageClass, sigs ...consensus.Signature) bool {
+	if srv.skipAuth {
+		return true
+	}
+	for _, sig := range sigs {
+		if sig == nil {
+			continue
+		}
+		// some signature types do not carry the ID of the signer.
+		if claimed := sig.Signer(); claimed != 0 && claimed != sender {
+			atomic.AddUint64(&srv.impersonations, 1)
+			srv.mods.Logger().Warnf("Security: replica %d sent a %s message claiming to be from replica %d; dropped", sender, class, claimed)
+			return false
+		}
+	}
+	return true
+}
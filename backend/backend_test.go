@@ -5,8 +5,16 @@ import (
 	"crypto/ecdsa"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/rand"
 	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -16,9 +24,11 @@ import (
 	"github.com/relab/hotstuff/consensus"
 	"github.com/relab/hotstuff/crypto/keygen"
 	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
 	"github.com/relab/hotstuff/internal/testutil"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestConnect(t *testing.T) {
@@ -140,6 +150,28 @@ type setupFunc func(t *testing.T, ctrl *gomock.Controller, n int) testData
 
 func setupReplicas(t *testing.T, ctrl *gomock.Controller, n int) testData {
 	t.Helper()
+	return setupListeners(t, ctrl, n, func(int) (net.Listener, string) {
+		lis := testutil.CreateTCPListener(t)
+		return lis, lis.Addr().String()
+	})
+}
+
+// setupUnix sets up replicas that listen on unix domain sockets.
+func setupUnix(t *testing.T, ctrl *gomock.Controller, n int) testData {
+	t.Helper()
+	dir := t.TempDir()
+	return setupListeners(t, ctrl, n, func(i int) (net.Listener, string) {
+		addr := UnixScheme + filepath.Join(dir, fmt.Sprintf("replica%d.sock", i+1))
+		lis, err := listen(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return lis, addr
+	})
+}
+
+func setupListeners(t *testing.T, ctrl *gomock.Controller, n int, listen func(i int) (net.Listener, string)) testData {
+	t.Helper()
 
 	listeners := make([]net.Listener, n)
 	keys := make([]consensus.PrivateKey, 0, n)
@@ -147,11 +179,12 @@ func setupReplicas(t *testing.T, ctrl *gomock.Controller, n int) testData {
 
 	// generate keys and replicaInfo
 	for i := 0; i < n; i++ {
-		listeners[i] = testutil.CreateTCPListener(t)
+		var addr string
+		listeners[i], addr = listen(i)
 		keys = append(keys, testutil.GenerateECDSAKey(t))
 		replicas = append(replicas, ReplicaInfo{
 			ID:      hotstuff.ID(i) + 1,
-			Address: listeners[i].Addr().String(),
+			Address: addr,
 			PubKey:  keys[i].Public(),
 		})
 	}
@@ -168,7 +201,17 @@ func setupReplicas(t *testing.T, ctrl *gomock.Controller, n int) testData {
 
 func setupTLS(t *testing.T, ctrl *gomock.Controller, n int) testData {
 	t.Helper()
-	td := setupReplicas(t, ctrl, n)
+	return withTLS(t, setupReplicas(t, ctrl, n))
+}
+
+func setupUnixTLS(t *testing.T, ctrl *gomock.Controller, n int) testData {
+	t.Helper()
+	return withTLS(t, setupUnix(t, ctrl, n))
+}
+
+func withTLS(t *testing.T, td testData) testData {
+	t.Helper()
+	n := td.n
 
 	certificates := make([]*x509.Certificate, 0, n)
 
@@ -209,6 +252,8 @@ func runBoth(t *testing.T, run func(*testing.T, setupFunc)) {
 	t.Helper()
 	t.Run("NoTLS", func(t *testing.T) { run(t, setupReplicas) })
 	t.Run("WithTLS", func(t *testing.T) { run(t, setupTLS) })
+	t.Run("Unix", func(t *testing.T) { run(t, setupUnix) })
+	t.Run("UnixWithTLS", func(t *testing.T) { run(t, setupUnixTLS) })
 }
 
 func createServers(t *testing.T, td testData, ctrl *gomock.Controller) (teardown func()) {
@@ -225,3 +270,2086 @@ func createServers(t *testing.T, td testData, ctrl *gomock.Controller) (teardown
 		}
 	}
 }
+
+func TestReconnect(t *testing.T) {
+	run := func(t *testing.T, setup setupFunc) {
+		const n = 4
+		ctrl := gomock.NewController(t)
+		td := setup(t, ctrl, n)
+
+		servers := make([]*Server, td.n)
+		for i := range servers {
+			servers[i] = NewServer(gorums.WithGRPCServerOptions(grpc.Creds(td.creds)))
+			servers[i].StartOnListener(td.listeners[i])
+			td.builders[i].Register(servers[i])
+		}
+		defer func() {
+			for _, srv := range servers {
+				srv.Stop()
+			}
+		}()
+
+		cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second))
+		td.builders[0].Register(cfg)
+		hl := td.builders.Build()
+
+		disconnected := make(chan hotstuff.ID, n)
+		connected := make(chan hotstuff.ID, n)
+		hl[0].EventLoop().RegisterHandler(ReplicaDisconnected{}, func(event interface{}) {
+			disconnected <- event.(ReplicaDisconnected).ID
+		})
+		hl[0].EventLoop().RegisterHandler(ReplicaConnected{}, func(event interface{}) {
+			connected <- event.(ReplicaConnected).ID
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go hl[0].Run(ctx)
+
+		err := cfg.Connect(td.replicas)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cfg.Close()
+
+		for i := 1; i < n; i++ {
+			waitForID(t, connected)
+		}
+
+		// stop replica 2 and wait for the disconnect
+		servers[1].Stop()
+		if id := waitForID(t, disconnected); id != 2 {
+			t.Fatalf("got disconnect from replica %d, want 2", id)
+		}
+		if cfg.IsConnected(2) {
+			t.Error("replica 2 should not be connected")
+		}
+
+		// restart replica 2 on the same address
+		builder := testutil.TestModules(t, ctrl, 2, td.keys[1])
+		restarted := NewServer(gorums.WithGRPCServerOptions(grpc.Creds(td.creds)))
+		builder.Register(restarted)
+		hs := builder.Build()
+		received := make(chan struct{}, 1)
+		hs.EventLoop().RegisterHandler(consensus.ProposeMsg{}, func(_ interface{}) {
+			select {
+			case received <- struct{}{}:
+			default:
+			}
+		})
+		go hs.Run(ctx)
+
+		lis, err := listen(td.replicas[1].Address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		restarted.StartOnListener(lis)
+		servers[1] = restarted
+
+		if id := waitForID(t, connected); id != 2 {
+			t.Fatalf("got connect from replica %d, want 2", id)
+		}
+
+		// messages sent while the stream is being reestablished may be dropped, so we keep trying.
+		proposal := testutil.NewProposeMsg(consensus.GetGenesis().Hash(), consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()), "foo", 1, 1)
+		timeout := time.After(10 * time.Second)
+		for {
+			cfg.Propose(proposal)
+			select {
+			case <-received:
+				return
+			case <-timeout:
+				t.Fatal("restarted replica did not receive the proposal")
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	}
+	runBoth(t, run)
+}
+
+func waitForID(t *testing.T, c chan hotstuff.ID) hotstuff.ID {
+	t.Helper()
+	select {
+	case id := <-c:
+		return id
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for connection event")
+	}
+	return 0
+}
+
+type countingTransport struct {
+	Transport
+	dials int32
+}
+
+func (t *countingTransport) Dial(ctx context.Context, addr string) (net.Conn, error) {
+	atomic.AddInt32(&t.dials, 1)
+	return t.Transport.Dial(ctx, addr)
+}
+
+func TestTransport(t *testing.T) {
+	if _, err := GetTransport("no-such-transport"); err == nil {
+		t.Error("expected an error for an unknown transport")
+	}
+
+	run := func(t *testing.T, setup setupFunc) {
+		const n = 4
+		ctrl := gomock.NewController(t)
+		td := setup(t, ctrl, n)
+		builder := testutil.TestModules(t, ctrl, 1, td.keys[0])
+		teardown := createServers(t, td, ctrl)
+		defer teardown()
+		td.builders.Build()
+
+		tcp, err := GetTransport("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		transport := &countingTransport{Transport: tcp}
+		cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second), WithTransport(transport))
+
+		builder.Register(cfg)
+		builder.Build()
+
+		err = cfg.Connect(td.replicas)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cfg.Close()
+
+		if dials := atomic.LoadInt32(&transport.dials); dials < n-1 {
+			t.Errorf("transport was used for %d dials, want at least %d", dials, n-1)
+		}
+	}
+	runBoth(t, run)
+}
+
+func TestLatencyEmulation(t *testing.T) {
+	const n = 2
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+
+	latencies := []map[hotstuff.ID]time.Duration{
+		{2: 50 * time.Millisecond},
+		{1: 100 * time.Millisecond},
+	}
+
+	cfgs := make([]*Config, n)
+	for i := range cfgs {
+		srv := NewServer()
+		srv.StartOnListener(td.listeners[i])
+		defer srv.Stop()
+		cfgs[i] = NewConfig(nil, gorums.WithDialTimeout(time.Second))
+		cfgs[i].SetLatencies(latencies[i], 0)
+		td.builders[i].Register(srv, cfgs[i])
+	}
+	hl := td.builders.Build()
+
+	received := make([]chan time.Time, n)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for i, hs := range hl {
+		c := make(chan time.Time, 1)
+		received[i] = c
+		hs.EventLoop().RegisterHandler(consensus.VoteMsg{}, func(_ interface{}) {
+			c <- time.Now()
+		})
+		go hs.Run(ctx)
+	}
+
+	for _, cfg := range cfgs {
+		err := cfg.Connect(td.replicas)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cfg.Close()
+	}
+
+	block := consensus.NewBlock(consensus.GetGenesis().Hash(), consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()), "foo", 1, 1)
+
+	for i, cfg := range cfgs {
+		from := hotstuff.ID(i + 1)
+		to := hotstuff.ID(n - i)
+		want := latencies[i][to]
+
+		replica, _ := cfg.Replica(to)
+		start := time.Now()
+		replica.Vote(testutil.CreatePC(t, block, hl[i].Crypto()))
+
+		select {
+		case got := <-received[to-1]:
+			elapsed := got.Sub(start)
+			if elapsed < want || elapsed > want+50*time.Millisecond {
+				t.Errorf("message from %d to %d took %v, want %v", from, to, elapsed, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("message from %d to %d was not delivered", from, to)
+		}
+	}
+}
+
+func TestInboundRateLimit(t *testing.T) {
+	const n = 3
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+
+	srv := NewServer()
+	srv.SetRateLimits(RateLimits{VoteClass: {Rate: 10, Burst: 10}})
+	srv.StartOnListener(td.listeners[0])
+	defer srv.Stop()
+	td.builders[0].Register(srv)
+
+	cfgs := make([]*Config, n)
+	for i := 1; i < n; i++ {
+		cfgs[i] = NewConfig(nil, gorums.WithDialTimeout(time.Second))
+		td.builders[i].Register(cfgs[i])
+	}
+	hl := td.builders.Build()
+
+	var mut sync.Mutex
+	votes := make(map[hotstuff.ID]int)
+	hl[0].EventLoop().RegisterHandler(consensus.VoteMsg{}, func(event interface{}) {
+		mut.Lock()
+		votes[event.(consensus.VoteMsg).ID]++
+		mut.Unlock()
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hl[0].Run(ctx)
+
+	for i, cfg := range cfgs[1:] {
+		// only replica 1 runs a server
+		err := cfg.Connect([]ReplicaInfo{td.replicas[0], td.replicas[i+1]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cfg.Close()
+	}
+
+	block := consensus.NewBlock(consensus.GetGenesis().Hash(), consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()), "foo", 1, 1)
+
+	// replica 2 floods replica 1 with votes
+	flooder, _ := cfgs[1].Replica(1)
+	pc := testutil.CreatePC(t, block, hl[1].Crypto())
+	for i := 0; i < 1000; i++ {
+		flooder.(*Replica).node.Vote(context.Background(), hotstuffpb.PartialCertToProto(pc), gorums.WithNoSendWaiting())
+	}
+
+	// replica 3 sends a single vote
+	honest, _ := cfgs[2].Replica(1)
+	honest.Vote(testutil.CreatePC(t, block, hl[2].Crypto()))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mut.Lock()
+		got := votes[3]
+		mut.Unlock()
+		if got == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("vote from replica 3 was not delivered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// wait for the flood to be processed
+	for time.Now().Before(deadline) {
+		mut.Lock()
+		processed := srv.Dropped(VoteClass) + uint64(votes[2])
+		mut.Unlock()
+		if processed >= 1000 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mut.Lock()
+	defer mut.Unlock()
+	if votes[2] > 100 {
+		t.Errorf("got %d votes from the flooding replica, expected the rate limit to drop most of them", votes[2])
+	}
+	if srv.Dropped(VoteClass) == 0 {
+		t.Error("expected dropped votes to be counted")
+	}
+}
+
+func TestRateLimitDelay(t *testing.T) {
+	l := newInboundLimiter(RateLimits{VoteClass: {Rate: 100, Burst: 1}})
+	if delay, ok, _ := l.allow(1, VoteClass); !ok || delay != 0 {
+		t.Fatalf("first message: got delay %v and ok=%v, want no delay", delay, ok)
+	}
+	delay, ok, _ := l.allow(1, VoteClass)
+	if !ok || delay <= 0 {
+		t.Fatalf("second message: got delay %v and ok=%v, want a delay", delay, ok)
+	}
+	// the other peers have their own budgets.
+	if delay, ok, _ := l.allow(2, VoteClass); !ok || delay != 0 {
+		t.Errorf("message from another peer: got delay %v and ok=%v, want no delay", delay, ok)
+	}
+	dropped := false
+	for i := 0; i < 10 && !dropped; i++ {
+		delay, ok, _ := l.allow(1, VoteClass)
+		if delay > maxRateLimitDelay {
+			t.Errorf("got delay %v, which exceeds the maximum of %v", delay, maxRateLimitDelay)
+		}
+		dropped = !ok
+	}
+	if !dropped {
+		t.Error("expected messages to be dropped once the maximum delay is exceeded")
+	}
+}
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := ParseRateLimits("vote=1000:100, fetch=2.5:5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RateLimits{VoteClass: {Rate: 1000, Burst: 100}, FetchClass: {Rate: 2.5, Burst: 5}}
+	if !reflect.DeepEqual(limits, want) {
+		t.Errorf("got %v, want %v", limits, want)
+	}
+	for _, spec := range []string{"", "vote", "vote=1000", "ballot=1:1", "vote=-1:1", "vote=1:0", "vote=x:1"} {
+		if _, err := ParseRateLimits(spec); err == nil {
+			t.Errorf("expected '%s' to be rejected", spec)
+		}
+	}
+}
+
+func TestTreeDissemination(t *testing.T) {
+	const n = 15
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+
+	cfgs := make([]*Config, n)
+	for i := range cfgs {
+		srv := NewServer()
+		srv.StartOnListener(td.listeners[i])
+		defer srv.Stop()
+		cfgs[i] = NewConfig(nil, gorums.WithDialTimeout(time.Second))
+		cfgs[i].SetTreeDissemination(2, time.Second)
+		td.builders[i].Register(srv, cfgs[i])
+	}
+	hl := td.builders.Build()
+
+	var (
+		mut      sync.Mutex
+		received = make(map[hotstuff.ID]int)
+		wg       sync.WaitGroup
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for i, hs := range hl {
+		id := hotstuff.ID(i + 1)
+		hs.EventLoop().RegisterHandler(consensus.ProposeMsg{}, func(event interface{}) {
+			if event.(consensus.ProposeMsg).ID != 1 {
+				t.Errorf("replica %d: got proposal from %d, want 1", id, event.(consensus.ProposeMsg).ID)
+			}
+			mut.Lock()
+			received[id]++
+			mut.Unlock()
+			wg.Done()
+		})
+		go hs.Run(ctx)
+	}
+
+	for _, cfg := range cfgs {
+		err := cfg.Connect(td.replicas)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cfg.Close()
+	}
+
+	wg.Add(n - 1)
+	cfgs[0].Propose(testutil.NewProposeMsg(consensus.GetGenesis().Hash(), consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()), "foo", 1, 1))
+	wg.Wait()
+
+	// give duplicates a chance to arrive
+	time.Sleep(100 * time.Millisecond)
+
+	mut.Lock()
+	defer mut.Unlock()
+	for id := hotstuff.ID(2); id <= n; id++ {
+		if received[id] != 1 {
+			t.Errorf("replica %d received the proposal %d times, want 1", id, received[id])
+		}
+	}
+}
+
+func TestTreeChildren(t *testing.T) {
+	ids := []hotstuff.ID{1, 2, 3, 4, 5, 6, 7}
+	// with root 3, the tree order is 3 4 5 6 7 1 2
+	tests := []struct {
+		self hotstuff.ID
+		want []hotstuff.ID
+	}{
+		{3, []hotstuff.ID{4, 5}},
+		{4, []hotstuff.ID{6, 7}},
+		{5, []hotstuff.ID{1, 2}},
+		{6, nil},
+	}
+	for _, tt := range tests {
+		got := treeChildren(ids, 3, tt.self, 2)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("children of %d: got %v, want %v", tt.self, got, tt.want)
+		}
+	}
+}
+
+func TestTreeParent(t *testing.T) {
+	ids := []hotstuff.ID{1, 2, 3, 4, 5, 6, 7}
+	for _, root := range ids {
+		for _, self := range ids {
+			parent, ok := treeParent(ids, root, self, 2)
+			if self == root {
+				if ok {
+					t.Errorf("root %d: got parent %d, want none", root, parent)
+				}
+				continue
+			}
+			found := false
+			for _, child := range treeChildren(ids, root, parent, 2) {
+				found = found || child == self
+			}
+			if !ok || !found {
+				t.Errorf("root %d: %d is not a child of its parent %d", root, self, parent)
+			}
+		}
+	}
+}
+
+func TestTreeDropsInvalidForwards(t *testing.T) {
+	const n = 7
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+
+	cfgs := make([]*Config, n)
+	for i := range cfgs {
+		srv := NewServer()
+		srv.StartOnListener(td.listeners[i])
+		defer srv.Stop()
+		cfgs[i] = NewConfig(nil, gorums.WithDialTimeout(time.Second))
+		cfgs[i].SetTreeDissemination(2, time.Minute)
+		td.builders[i].Register(srv, cfgs[i])
+	}
+	hl := td.builders.Build()
+
+	// with the leader 1 at the root, the parent of replica 4 is replica 2.
+	received := make(chan consensus.ProposeMsg, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hl[3].EventLoop().RegisterHandler(consensus.ProposeMsg{}, func(event interface{}) {
+		received <- event.(consensus.ProposeMsg)
+	})
+	for _, hs := range hl {
+		go hs.Run(ctx)
+	}
+	for _, cfg := range cfgs {
+		if err := cfg.Connect(td.replicas); err != nil {
+			t.Fatal(err)
+		}
+		defer cfg.Close()
+	}
+
+	forward := func(from hotstuff.ID, proposer hotstuff.ID, cmd consensus.Command) {
+		t.Helper()
+		block := consensus.NewBlock(consensus.GetGenesis().Hash(), consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()), cmd, 1, proposer)
+		single, err := cfgs[from-1].singleConfig(cfgs[from-1].replicas[4].(*Replica))
+		if err != nil {
+			t.Fatal(err)
+		}
+		single.Forward(context.Background(), hotstuffpb.ProposalToProto(consensus.ProposeMsg{ID: proposer, Block: block}), gorums.WithNoSendWaiting())
+	}
+
+	forward(3, 1, "not the parent")
+	forward(2, 5, "not the leader")
+	select {
+	case msg := <-received:
+		t.Fatalf("replica 4 accepted an invalid forward: %v", msg.Block)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	forward(2, 1, "valid")
+	select {
+	case msg := <-received:
+		if msg.ID != 1 || msg.Block.Command() != "valid" {
+			t.Errorf("got proposal %v from %d, want the valid proposal from 1", msg.Block, msg.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("replica 4 did not accept the proposal forwarded by its parent")
+	}
+}
+
+func TestGossipLineTopology(t *testing.T) {
+	const n = 3
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+
+	cfgs := make([]*Config, n)
+	for i := range cfgs {
+		srv := NewServer()
+		srv.StartOnListener(td.listeners[i])
+		defer srv.Stop()
+		cfgs[i] = NewConfig(nil, gorums.WithDialTimeout(time.Second))
+		cfgs[i].SetGossip(20*time.Millisecond, 2, 10)
+		td.builders[i].Register(srv, cfgs[i])
+	}
+	hl := td.builders.Build()
+
+	proposals := make(chan consensus.ProposeMsg, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for i, hs := range hl {
+		i, hs := i, hs
+		hs.EventLoop().RegisterHandler(consensus.ProposeMsg{}, func(event interface{}) {
+			proposal := event.(consensus.ProposeMsg)
+			hs.BlockChain().Store(proposal.Block)
+			if i == 2 {
+				proposals <- proposal
+			}
+		})
+		go hs.Run(ctx)
+	}
+
+	// A (1) and C (3) are only connected to B (2), so they cannot talk directly.
+	links := [][]ReplicaInfo{
+		{td.replicas[0], td.replicas[1]},
+		td.replicas,
+		{td.replicas[1], td.replicas[2]},
+	}
+	for i, cfg := range cfgs {
+		err := cfg.Connect(links[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cfg.Close()
+	}
+
+	// the first block is offered to C once the second proposal carries a certificate for it.
+	// C only knows B and itself, so the certificate is signed by them.
+	genesisQC := consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash())
+	first := testutil.NewProposeMsg(consensus.GetGenesis().Hash(), genesisQC, "foo", 1, 1)
+	signers := hl.Signers()
+	qc := testutil.CreateQC(t, first.Block, []consensus.Crypto{signers[2], signers[1]})
+	second := testutil.NewProposeMsg(first.Block.Hash(), qc, "bar", 2, 1)
+	cfgs[0].Propose(first)
+	waitForBlock(t, hl[1], first.Block.Hash())
+	cfgs[0].Propose(second)
+
+	// an offer of the second block without a valid certificate must not be fetched.
+	secondHash := second.Block.Hash()
+	forged := hotstuffpb.QuorumCertToProto(consensus.NewQuorumCert(qc.Signature(), 2, secondHash))
+	cfgs[1].replicas[3].(*Replica).node.Offer(context.Background(), &hotstuffpb.BlockOffer{Blocks: []*hotstuffpb.BlockID{
+		{View: 2, Hash: secondHash[:], QC: forged},
+	}})
+
+	waitForBlock(t, hl[2], first.Block.Hash())
+
+	// give the forged offer a chance to be fetched.
+	time.Sleep(100 * time.Millisecond)
+	if _, ok := hl[2].BlockChain().LocalGet(secondHash); ok {
+		t.Error("C stored a block without a valid certificate")
+	}
+	select {
+	case p := <-proposals:
+		t.Errorf("C received a proposal through gossip: %v", p.Block)
+	default:
+	}
+}
+
+// waitForBlock waits until the block is stored in the blockchain of the replica.
+func waitForBlock(t *testing.T, hs *consensus.Modules, hash consensus.Hash) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := hs.BlockChain().LocalGet(hash); ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("replica %d did not receive block %.8x", hs.ID(), hash)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNetworkStats(t *testing.T) {
+	const n = 4
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+
+	servers := make([]*Server, n)
+	for i := range servers {
+		servers[i] = NewServer()
+		servers[i].StartOnListener(td.listeners[i])
+		td.builders[i].Register(servers[i])
+		defer servers[i].Stop()
+	}
+
+	cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second))
+	td.builders[0].Register(cfg)
+	hl := td.builders.Build()
+
+	if err := cfg.Connect(td.replicas); err != nil {
+		t.Fatal(err)
+	}
+	defer cfg.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for _, r := range td.replicas[1:] {
+		for !cfg.IsConnected(r.ID) {
+			if time.Now().After(deadline) {
+				t.Fatalf("replica %d did not connect", r.ID)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, hs := range hl[1:] {
+		hs.EventLoop().RegisterHandler(consensus.ProposeMsg{}, func(_ interface{}) { wg.Done() })
+		hs.EventLoop().RegisterHandler(consensus.TimeoutMsg{}, func(_ interface{}) { wg.Done() })
+		go hs.Run(ctx)
+	}
+
+	proposals := make([]consensus.ProposeMsg, 2)
+	for i := range proposals {
+		proposals[i] = consensus.ProposeMsg{
+			ID: 1,
+			Block: consensus.NewBlock(
+				consensus.GetGenesis().Hash(),
+				consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+				consensus.Command(fmt.Sprintf("command %d", i)), consensus.View(i+1), 1,
+			),
+		}
+	}
+	timeout := consensus.TimeoutMsg{ID: 1, View: 1, SyncInfo: consensus.NewSyncInfo()}
+
+	// wait for each message to be delivered, as a new proposal cancels the previous one.
+	for _, p := range proposals {
+		wg.Add(3)
+		cfg.Propose(p)
+		wg.Wait()
+	}
+	wg.Add(3)
+	cfg.Timeout(timeout)
+	wg.Wait()
+
+	var proposalBytes uint64
+	for _, p := range proposals {
+		proposalBytes += uint64(proto.Size(hotstuffpb.ProposalToProto(p)))
+	}
+	timeoutBytes := uint64(proto.Size(hotstuffpb.TimeoutMsgToProto(timeout)))
+
+	stats := cfg.NetworkStats()
+	if got, want := stats[ProposalClass], (MessageStats{Sent: 6, SentBytes: 3 * proposalBytes}); got != want {
+		t.Errorf("proposal stats: got %+v, want %+v", got, want)
+	}
+	if got, want := stats[TimeoutClass], (MessageStats{Sent: 3, SentBytes: 3 * timeoutBytes}); got != want {
+		t.Errorf("timeout stats: got %+v, want %+v", got, want)
+	}
+	if got := stats[VoteClass]; got != (MessageStats{}) {
+		t.Errorf("vote stats: got %+v, want no messages", got)
+	}
+
+	for _, srv := range servers[1:] {
+		stats := srv.NetworkStats()
+		if got, want := stats[ProposalClass], (MessageStats{Received: 2, ReceivedBytes: proposalBytes}); got != want {
+			t.Errorf("received proposal stats: got %+v, want %+v", got, want)
+		}
+		if got, want := stats[TimeoutClass], (MessageStats{Received: 1, ReceivedBytes: timeoutBytes}); got != want {
+			t.Errorf("received timeout stats: got %+v, want %+v", got, want)
+		}
+	}
+
+	// the counters for an interval are the difference between two snapshots.
+	wg.Add(3)
+	cfg.Timeout(timeout)
+	wg.Wait()
+	if got := cfg.NetworkStats().Sub(stats)[TimeoutClass].Sent; got != 3 {
+		t.Errorf("timeouts sent since last snapshot: got %d, want 3", got)
+	}
+}
+
+func TestProposalDeliveryReport(t *testing.T) {
+	const n = 4
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+
+	servers := make([]*Server, n)
+	for i := range servers {
+		servers[i] = NewServer()
+		servers[i].StartOnListener(td.listeners[i])
+		td.builders[i].Register(servers[i])
+	}
+	defer func() {
+		for _, srv := range servers[:n-1] {
+			srv.Stop()
+		}
+	}()
+
+	cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second))
+	cfg.SetDeliveryReports(true)
+	td.builders[0].Register(cfg)
+	hl := td.builders.Build()
+
+	disconnected := make(chan hotstuff.ID, n)
+	reports := make(chan ProposalDeliveryReport, 1)
+	hl[0].EventLoop().RegisterHandler(ReplicaDisconnected{}, func(event interface{}) {
+		disconnected <- event.(ReplicaDisconnected).ID
+	})
+	hl[0].EventLoop().RegisterHandler(ProposalDeliveryReport{}, func(event interface{}) {
+		reports <- event.(ProposalDeliveryReport)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hl[0].Run(ctx)
+
+	if err := cfg.Connect(td.replicas); err != nil {
+		t.Fatal(err)
+	}
+	defer cfg.Close()
+
+	// make the last replica unreachable.
+	servers[n-1].Stop()
+	if id := waitForID(t, disconnected); id != hotstuff.ID(n) {
+		t.Fatalf("unexpected disconnect from replica %d", id)
+	}
+
+	proposal := consensus.ProposeMsg{
+		ID: 1,
+		Block: consensus.NewBlock(
+			consensus.GetGenesis().Hash(),
+			consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+			"foo", 1, 1,
+		),
+	}
+	cfg.Propose(proposal)
+
+	select {
+	case report := <-reports:
+		if report.View != 1 {
+			t.Errorf("wrong view in report: got %d, want 1", report.View)
+		}
+		if want := []hotstuff.ID{n}; !reflect.DeepEqual(report.Failed, want) {
+			t.Errorf("wrong failed replicas: got %v, want %v", report.Failed, want)
+		}
+		if got := report.Outcomes[n]; got != PeerDown {
+			t.Errorf("wrong outcome for replica %d: got %v, want %v", n, got, PeerDown)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no delivery report")
+	}
+}
+
+type stubResolver struct {
+	mut   sync.Mutex
+	hosts map[string][]string
+}
+
+func (r *stubResolver) set(host string, addrs ...string) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	r.hosts[host] = addrs
+}
+
+func (r *stubResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	addrs, ok := r.hosts[host]
+	if !ok {
+		return nil, fmt.Errorf("no such host: %s", host)
+	}
+	return addrs, nil
+}
+
+func TestReResolveHostName(t *testing.T) {
+	const n = 2
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+
+	// replica 2 is addressed by its host name
+	host, port, err := net.SplitHostPort(td.listeners[1].Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	td.replicas[1].Address = net.JoinHostPort("replica2.test", port)
+	resolver := &stubResolver{hosts: make(map[string][]string)}
+	resolver.set("replica2.test", host)
+
+	servers := make([]*Server, n)
+	for i := range servers {
+		servers[i] = NewServer()
+		servers[i].StartOnListener(td.listeners[i])
+		td.builders[i].Register(servers[i])
+	}
+	defer func() {
+		for _, srv := range servers {
+			srv.Stop()
+		}
+	}()
+
+	cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second))
+	cfg.SetResolver(resolver)
+	td.builders[0].Register(cfg)
+	hl := td.builders.Build()
+
+	disconnected := make(chan hotstuff.ID, n)
+	connected := make(chan hotstuff.ID, n)
+	hl[0].EventLoop().RegisterHandler(ReplicaDisconnected{}, func(event interface{}) {
+		disconnected <- event.(ReplicaDisconnected).ID
+	})
+	hl[0].EventLoop().RegisterHandler(ReplicaConnected{}, func(event interface{}) {
+		connected <- event.(ReplicaConnected).ID
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hl[0].Run(ctx)
+
+	if err := cfg.Connect(td.replicas); err != nil {
+		t.Fatal(err)
+	}
+	defer cfg.Close()
+	if id := waitForID(t, connected); id != 2 {
+		t.Fatalf("got connect from replica %d, want 2", id)
+	}
+
+	servers[1].Stop()
+	if id := waitForID(t, disconnected); id != 2 {
+		t.Fatalf("got disconnect from replica %d, want 2", id)
+	}
+
+	// restart replica 2 with a new IP address. The first address returned by the resolver is unreachable,
+	// so the configuration must try the next one.
+	lis, err := net.Listen("tcp", net.JoinHostPort("127.0.0.2", port))
+	if err != nil {
+		t.Skipf("could not listen on 127.0.0.2: %v", err)
+	}
+	resolver.set("replica2.test", "127.0.0.3", "127.0.0.2")
+
+	builder := testutil.TestModules(t, ctrl, 2, td.keys[1])
+	restarted := NewServer()
+	builder.Register(restarted)
+	hs := builder.Build()
+	received := make(chan struct{}, 1)
+	hs.EventLoop().RegisterHandler(consensus.ProposeMsg{}, func(_ interface{}) {
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+	})
+	go hs.Run(ctx)
+	restarted.StartOnListener(lis)
+	servers[1] = restarted
+
+	if id := waitForID(t, connected); id != 2 {
+		t.Fatalf("got connect from replica %d, want 2", id)
+	}
+
+	proposal := testutil.NewProposeMsg(consensus.GetGenesis().Hash(), consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()), "foo", 1, 1)
+	timeout := time.After(10 * time.Second)
+	for {
+		cfg.Propose(proposal)
+		select {
+		case <-received:
+			return
+		case <-timeout:
+			t.Fatal("replica with new address did not receive the proposal")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// pausableTransport creates connections whose writes can be blocked, simulating a peer that stops responding
+// without closing the connection.
+type pausableTransport struct {
+	Transport
+	mut    sync.Mutex
+	paused chan struct{} // closed when the transport is resumed
+}
+
+func newPausableTransport() *pausableTransport {
+	t := &pausableTransport{Transport: tcpTransport{}, paused: make(chan struct{})}
+	close(t.paused)
+	return t
+}
+
+func (t *pausableTransport) pause() {
+	t.mut.Lock()
+	t.paused = make(chan struct{})
+	t.mut.Unlock()
+}
+
+func (t *pausableTransport) resume() {
+	t.mut.Lock()
+	close(t.paused)
+	t.mut.Unlock()
+}
+
+func (t *pausableTransport) wait() {
+	t.mut.Lock()
+	c := t.paused
+	t.mut.Unlock()
+	<-c
+}
+
+func (t *pausableTransport) Dial(ctx context.Context, addr string) (net.Conn, error) {
+	conn, err := t.Transport.Dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &pausableConn{Conn: conn, t: t}, nil
+}
+
+type pausableConn struct {
+	net.Conn
+	t *pausableTransport
+}
+
+func (c *pausableConn) Write(b []byte) (int, error) {
+	c.t.wait()
+	return c.Conn.Write(b)
+}
+
+func TestHealthCheck(t *testing.T) {
+	const (
+		n         = 2
+		interval  = 50 * time.Millisecond
+		threshold = 3
+	)
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+
+	servers := make([]*Server, n)
+	for i := range servers {
+		servers[i] = NewServer()
+		servers[i].StartOnListener(td.listeners[i])
+		td.builders[i].Register(servers[i])
+	}
+	defer func() {
+		for _, srv := range servers {
+			srv.Stop()
+		}
+	}()
+
+	transport := newPausableTransport()
+	cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second))
+	cfg.SetTransport(transport)
+	cfg.SetHealthCheck(interval, threshold)
+	td.builders[0].Register(cfg)
+	hl := td.builders.Build()
+
+	unreachable := make(chan hotstuff.ID, 1)
+	reachable := make(chan hotstuff.ID, 1)
+	hl[0].EventLoop().RegisterHandler(ReplicaUnreachable{}, func(event interface{}) {
+		unreachable <- event.(ReplicaUnreachable).ID
+	})
+	hl[0].EventLoop().RegisterHandler(ReplicaReachable{}, func(event interface{}) {
+		reachable <- event.(ReplicaReachable).ID
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hl[0].Run(ctx)
+
+	if err := cfg.Connect(td.replicas); err != nil {
+		t.Fatal(err)
+	}
+	defer cfg.Close()
+
+	// a responsive replica must not be reported as unreachable.
+	select {
+	case id := <-unreachable:
+		t.Fatalf("responsive replica %d reported as unreachable", id)
+	case <-time.After(2 * threshold * interval):
+	}
+
+	transport.pause()
+	paused := time.Now()
+	if id := waitForID(t, unreachable); id != 2 {
+		t.Fatalf("got unreachable event for replica %d, want 2", id)
+	}
+	elapsed := time.Since(paused)
+	if min, max := (threshold-1)*interval, (threshold+3)*interval; elapsed < min || elapsed > max {
+		t.Errorf("replica reported unreachable after %v, want between %v and %v", elapsed, min, max)
+	}
+	if cfg.IsReachable(2) {
+		t.Error("replica 2 should not be reachable")
+	}
+
+	transport.resume()
+	resumed := time.Now()
+	if id := waitForID(t, reachable); id != 2 {
+		t.Fatalf("got reachable event for replica %d, want 2", id)
+	}
+	if elapsed := time.Since(resumed); elapsed > 4*interval {
+		t.Errorf("replica reported reachable after %v, want at most %v", elapsed, 4*interval)
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*hotstuffpb.HandshakeMsg)
+		wantErr error
+	}{
+		{"Compatible", func(*hotstuffpb.HandshakeMsg) {}, nil},
+		{"CompatibleVersionRange", func(m *hotstuffpb.HandshakeMsg) { m.Version = ProtocolVersion + 1 }, nil},
+		{"GenesisMismatch", func(m *hotstuffpb.HandshakeMsg) { m.GenesisHash = make([]byte, len(m.GenesisHash)) }, ErrGenesisMismatch},
+		{"VersionMismatch", func(m *hotstuffpb.HandshakeMsg) {
+			m.Version = ProtocolVersion + 2
+			m.MinVersion = ProtocolVersion + 1
+		}, ErrVersionMismatch},
+		{"CryptoMismatch", func(m *hotstuffpb.HandshakeMsg) { m.CryptoSuite = "bls12" }, ErrCryptoMismatch},
+		{"RulesMismatch", func(m *hotstuffpb.HandshakeMsg) { m.ChainLength = 2 }, ErrRulesMismatch},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			const n = 2
+			ctrl := gomock.NewController(t)
+			td := setupReplicas(t, ctrl, n)
+
+			servers := make([]*Server, n)
+			for i := range servers {
+				servers[i] = NewServer()
+				servers[i].StartOnListener(td.listeners[i])
+				td.builders[i].Register(servers[i])
+				defer servers[i].Stop()
+			}
+
+			cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second))
+			td.builders[0].Register(cfg)
+			hl := td.builders.Build()
+			test.modify(cfg.handshake)
+
+			local := make(chan PeerIncompatible, 1)
+			remote := make(chan PeerIncompatible, 1)
+			hl[0].EventLoop().RegisterHandler(PeerIncompatible{}, func(event interface{}) {
+				local <- event.(PeerIncompatible)
+			})
+			hl[1].EventLoop().RegisterHandler(PeerIncompatible{}, func(event interface{}) {
+				remote <- event.(PeerIncompatible)
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go hl[0].Run(ctx)
+			go hl[1].Run(ctx)
+
+			if err := cfg.Connect(td.replicas); err != nil {
+				t.Fatal(err)
+			}
+			defer cfg.Close()
+
+			if test.wantErr == nil {
+				select {
+				case e := <-local:
+					t.Fatalf("unexpected incompatibility: %v", e.Err)
+				case e := <-remote:
+					t.Fatalf("unexpected incompatibility: %v", e.Err)
+				case <-time.After(500 * time.Millisecond):
+				}
+				if _, ok := cfg.Replica(2); !ok || !cfg.replicas[2].(*Replica).active() {
+					t.Error("compatible replica was excluded")
+				}
+				return
+			}
+
+			for _, c := range []struct {
+				events chan PeerIncompatible
+				id     hotstuff.ID
+			}{{local, 2}, {remote, 1}} {
+				select {
+				case e := <-c.events:
+					if e.ID != c.id {
+						t.Errorf("got incompatible replica %d, want %d", e.ID, c.id)
+					}
+					if !errors.Is(e.Err, test.wantErr) {
+						t.Errorf("got error %v, want %v", e.Err, test.wantErr)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatalf("no PeerIncompatible event for replica %d", c.id)
+				}
+			}
+			if cfg.replicas[2].(*Replica).active() {
+				t.Error("incompatible replica was not excluded")
+			}
+			// the connection to the incompatible replica is closed, and is not reestablished.
+			deadline := time.Now().Add(5 * time.Second)
+			for cfg.IsConnected(2) {
+				if time.Now().After(deadline) {
+					t.Fatal("the connection to the incompatible replica was not closed")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			time.Sleep(300 * time.Millisecond)
+			if cfg.IsConnected(2) {
+				t.Error("the connection to the incompatible replica was reestablished")
+			}
+			if !servers[1].isIncompatible(1) {
+				t.Error("server accepts messages from an incompatible replica")
+			}
+		})
+	}
+}
+
+func TestRejectImpersonatedVote(t *testing.T) {
+	const n = 3
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+
+	servers := make([]*Server, n)
+	for i := range servers {
+		servers[i] = NewServer()
+		servers[i].StartOnListener(td.listeners[i])
+		td.builders[i].Register(servers[i])
+		defer servers[i].Stop()
+	}
+
+	// replica 2 sends votes to replica 1
+	cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second))
+	td.builders[1].Register(cfg)
+	hl := td.builders.Build()
+
+	votes := make(chan consensus.VoteMsg, 2)
+	hl[0].EventLoop().RegisterHandler(consensus.VoteMsg{}, func(event interface{}) {
+		votes <- event.(consensus.VoteMsg)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hl[0].Run(ctx)
+
+	if err := cfg.Connect(td.replicas[:2]); err != nil {
+		t.Fatal(err)
+	}
+	defer cfg.Close()
+
+	block := testutil.NewProposeMsg(consensus.GetGenesis().Hash(), consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()), "foo", 1, 1).Block
+	forged := testutil.CreatePC(t, block, hl[2].Crypto()) // signed by replica 3
+	genuine := testutil.CreatePC(t, block, hl[1].Crypto())
+
+	replica, _ := cfg.Replica(1)
+	replica.Vote(forged)
+	deadline := time.Now().Add(5 * time.Second)
+	for servers[0].Impersonations() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("forged vote was not detected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	replica.Vote(genuine)
+
+	select {
+	case vote := <-votes:
+		if vote.ID != 2 || vote.PartialCert.Signature().Signer() != 2 {
+			t.Errorf("got vote from %d signed by %d, want the genuine vote from replica 2", vote.ID, vote.PartialCert.Signature().Signer())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("genuine vote was not received")
+	}
+	select {
+	case vote := <-votes:
+		t.Errorf("unexpected vote signed by %d", vote.PartialCert.Signature().Signer())
+	default:
+	}
+	if got := servers[0].Impersonations(); got != 1 {
+		t.Errorf("got %d impersonations, want 1", got)
+	}
+}
+
+func TestChunkedProposal(t *testing.T) {
+	const n = 4
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+	teardown := createServers(t, td, ctrl)
+	defer teardown()
+
+	cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second))
+	cfg.SetChunkSize(1 << 20)
+	td.builders[0].Register(cfg)
+	hl := td.builders.Build()
+
+	if err := cfg.Connect(td.replicas); err != nil {
+		t.Fatal(err)
+	}
+	defer cfg.Close()
+
+	// the command is much larger than the default maximum message size of the servers (4 MB).
+	cmd := make([]byte, 20<<20)
+	rand.New(rand.NewSource(1)).Read(cmd)
+	want := consensus.ProposeMsg{
+		ID: 1,
+		Block: consensus.NewBlock(
+			consensus.GetGenesis().Hash(),
+			consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+			consensus.Command(cmd), 1, 1,
+		),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan consensus.ProposeMsg, n)
+	for _, hs := range hl[1:] {
+		hs.EventLoop().RegisterHandler(consensus.ProposeMsg{}, func(event interface{}) {
+			received <- event.(consensus.ProposeMsg)
+		})
+		go hs.Run(ctx)
+	}
+
+	cfg.Propose(want)
+
+	for i := 1; i < n; i++ {
+		select {
+		case got := <-received:
+			if got.ID != want.ID {
+				t.Errorf("wrong id in proposal: got: %d, want: %d", got.ID, want.ID)
+			}
+			if got.Block.Hash() != want.Block.Hash() {
+				t.Error("block hashes do not match")
+			}
+		case <-time.After(30 * time.Second):
+			t.Fatalf("timed out waiting for proposal; received %d of %d", i-1, n-1)
+		}
+	}
+}
+
+func TestChunkReassemblyTimeout(t *testing.T) {
+	block := consensus.NewBlock(
+		consensus.GetGenesis().Hash(),
+		consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+		consensus.Command(make([]byte, 1000)), 1, 1,
+	)
+	chunks, err := splitProposal(hotstuffpb.ProposalToProto(consensus.ProposeMsg{Block: block}), block.Hash(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) < 10 {
+		t.Fatalf("expected at least 10 chunks, got %d", len(chunks))
+	}
+
+	timeouts := make(chan ProposalReassemblyTimeout, 1)
+	r := newReassembler(50*time.Millisecond, DefaultReassemblyBudget, func(event ProposalReassemblyTimeout) {
+		timeouts <- event
+	})
+
+	// the chunk at index 3 is never delivered
+	missing := chunks[3]
+	for _, c := range append(chunks[:3:3], chunks[4:]...) {
+		p, err := r.add(2, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != nil {
+			t.Fatal("proposal was reassembled despite a missing chunk")
+		}
+	}
+
+	select {
+	case event := <-timeouts:
+		want := ProposalReassemblyTimeout{ID: 2, View: 1, Received: len(chunks) - 1, Total: len(chunks)}
+		if event != want {
+			t.Errorf("got %+v, want %+v", event, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reassembly timeout")
+	}
+
+	r.mut.Lock()
+	used, pending := r.used[2], len(r.pending[2])
+	r.mut.Unlock()
+	if used != 0 || pending != 0 {
+		t.Errorf("reassembly state was not released: %d bytes used, %d pending proposals", used, pending)
+	}
+
+	// a late chunk must not complete the discarded proposal.
+	if p, err := r.add(2, missing); err != nil || p != nil {
+		t.Errorf("late chunk: got (%v, %v), want (nil, nil)", p, err)
+	}
+}
+
+func TestChunkReassemblyBudget(t *testing.T) {
+	block := consensus.NewBlock(
+		consensus.GetGenesis().Hash(),
+		consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+		consensus.Command(make([]byte, 1000)), 1, 1,
+	)
+	chunks, err := splitProposal(hotstuffpb.ProposalToProto(consensus.ProposeMsg{Block: block}), block.Hash(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := newReassembler(time.Minute, 500, func(ProposalReassemblyTimeout) {})
+	var budgetErr error
+	for _, c := range chunks {
+		if _, budgetErr = r.add(2, c); budgetErr != nil {
+			break
+		}
+	}
+	if !errors.Is(budgetErr, errBudgetExceeded) {
+		t.Fatalf("expected the budget to be exceeded, got %v", budgetErr)
+	}
+
+	r.mut.Lock()
+	used := r.used[2]
+	r.mut.Unlock()
+	if used != 0 {
+		t.Errorf("discarded chunks still use %d bytes of the budget", used)
+	}
+
+	// the budget of other peers is not affected.
+	for _, c := range chunks[:4] {
+		if _, err := r.add(3, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSendQueueDropsNonCriticalFirst(t *testing.T) {
+	q := newSendQueue(3)
+	defer q.close()
+
+	// block the queue's goroutine until the test has filled the queue.
+	unblock := make(chan struct{})
+	blocked := make(chan struct{})
+	q.enqueue(queuedMsg{critical: true, send: func() { close(blocked); <-unblock }})
+	<-blocked
+
+	var (
+		mut  sync.Mutex
+		sent []string
+	)
+	msg := func(name string, critical bool, dropped *bool) queuedMsg {
+		return queuedMsg{
+			critical: critical,
+			send: func() {
+				mut.Lock()
+				sent = append(sent, name)
+				mut.Unlock()
+			},
+			drop: func() { *dropped = true },
+		}
+	}
+	var pingDropped, voteDropped, proposalDropped, gossipDropped bool
+	q.enqueue(msg("vote", true, &voteDropped))
+	q.enqueue(msg("ping", false, &pingDropped))
+	q.enqueue(msg("proposal", true, &proposalDropped))
+	// the queue is full, so the ping is dropped.
+	q.enqueue(msg("timeout", true, new(bool)))
+	// the queue only contains critical messages, so the new non-critical message is dropped.
+	q.enqueue(msg("gossip", false, &gossipDropped))
+	// the oldest critical message is dropped.
+	q.enqueue(msg("newview", true, new(bool)))
+
+	if !pingDropped || !gossipDropped || !voteDropped || proposalDropped {
+		t.Errorf("unexpected drops: ping=%v gossip=%v vote=%v proposal=%v", pingDropped, gossipDropped, voteDropped, proposalDropped)
+	}
+	want := SendQueueStats{Length: 3, Dropped: 3, DroppedCritical: 1}
+	if got := q.snapshot(); got != want {
+		t.Errorf("got stats %+v, want %+v", got, want)
+	}
+
+	close(unblock)
+	deadline := time.Now().Add(5 * time.Second)
+	for q.snapshot().Length > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	mut.Lock()
+	defer mut.Unlock()
+	if !reflect.DeepEqual(sent, []string{"proposal", "timeout", "newview"}) {
+		t.Errorf("got sent messages %v", sent)
+	}
+}
+
+// throttledTransport slows down writes on connections to a single address, or all addresses if addr is empty,
+// once throttling is enabled.
+type throttledTransport struct {
+	Transport
+	addr    string
+	delay   time.Duration
+	enabled int32
+}
+
+func (t *throttledTransport) Dial(ctx context.Context, addr string) (net.Conn, error) {
+	conn, err := t.Transport.Dial(ctx, addr)
+	if err != nil || (t.addr != "" && addr != t.addr) {
+		return conn, err
+	}
+	return &throttledConn{Conn: conn, t: t}, nil
+}
+
+type throttledConn struct {
+	net.Conn
+	t *throttledTransport
+}
+
+func (c *throttledConn) Write(b []byte) (int, error) {
+	if atomic.LoadInt32(&c.t.enabled) == 1 {
+		time.Sleep(c.t.delay)
+	}
+	return c.Conn.Write(b)
+}
+
+func TestSendQueueSlowPeer(t *testing.T) {
+	const (
+		n        = 4
+		timeouts = 10
+	)
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+	teardown := createServers(t, td, ctrl)
+	defer teardown()
+
+	cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second))
+	throttled := &throttledTransport{Transport: tcpTransport{}, addr: td.replicas[n-1].Address, delay: 200 * time.Millisecond}
+	cfg.SetTransport(throttled)
+	cfg.SetSendQueues(4)
+	td.builders[0].Register(cfg)
+	hl := td.builders.Build()
+
+	if err := cfg.Connect(td.replicas); err != nil {
+		t.Fatal(err)
+	}
+	defer cfg.Close()
+	atomic.StoreInt32(&throttled.enabled, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	type receipt struct {
+		id   hotstuff.ID
+		view consensus.View
+	}
+	received := make(chan receipt, 2*n*timeouts)
+	for _, hs := range hl[1:] {
+		id := hs.ID()
+		hs.EventLoop().RegisterHandler(consensus.ProposeMsg{}, func(event interface{}) {
+			received <- receipt{id, event.(consensus.ProposeMsg).Block.View()}
+		})
+		hs.EventLoop().RegisterHandler(consensus.TimeoutMsg{}, func(event interface{}) {
+			received <- receipt{id, event.(consensus.TimeoutMsg).View}
+		})
+		go hs.Run(ctx)
+	}
+
+	// waitForFast waits until the fast replicas have received a message for the view, and returns the latency.
+	waitForFast := func(view consensus.View, start time.Time) time.Duration {
+		t.Helper()
+		fast := map[hotstuff.ID]bool{}
+		for len(fast) < n-2 {
+			select {
+			case r := <-received:
+				if r.id != hotstuff.ID(n) && r.view == view {
+					fast[r.id] = true
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("message for view %d was not delivered to the fast replicas", view)
+			}
+		}
+		return time.Since(start)
+	}
+
+	// a large proposal clogs the connection to the throttled replica for several seconds.
+	start := time.Now()
+	cfg.Propose(consensus.ProposeMsg{
+		ID: 1,
+		Block: consensus.NewBlock(
+			consensus.GetGenesis().Hash(),
+			consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+			consensus.Command(make([]byte, 1<<20)), 1, 1,
+		),
+	})
+	waitForFast(1, start)
+
+	// the following messages must reach the fast replicas without waiting for the throttled replica.
+	for view := consensus.View(2); view < timeouts+2; view++ {
+		start := time.Now()
+		cfg.Timeout(consensus.TimeoutMsg{ID: 1, View: view, SyncInfo: consensus.NewSyncInfo()})
+		if latency := waitForFast(view, start); latency > time.Second {
+			t.Errorf("timeout for view %d took %v to reach the fast replicas", view, latency)
+		}
+	}
+
+	stats := cfg.SendQueueStats()
+	for id := hotstuff.ID(2); id < n; id++ {
+		if stats[id].Dropped != 0 {
+			t.Errorf("dropped %d messages to fast replica %d", stats[id].Dropped, id)
+		}
+	}
+	if stats[n].Dropped == 0 {
+		t.Error("expected messages to the throttled replica to be dropped")
+	}
+}
+
+func TestSendQueuePriority(t *testing.T) {
+	q := newSendQueue(100)
+	defer q.close()
+
+	unblock := make(chan struct{})
+	blocked := make(chan struct{})
+	q.enqueue(queuedMsg{critical: true, send: func() { close(blocked); <-unblock }})
+	<-blocked
+
+	var (
+		mut  sync.Mutex
+		sent []string
+		done = make(chan struct{})
+	)
+	msg := func(name string, priority bool) queuedMsg {
+		return queuedMsg{critical: true, priority: priority, send: func() {
+			mut.Lock()
+			sent = append(sent, name)
+			if len(sent) == 14 {
+				close(done)
+			}
+			mut.Unlock()
+		}}
+	}
+	for i := 0; i < 4; i++ {
+		q.enqueue(msg("n", false))
+	}
+	for i := 0; i < 10; i++ {
+		q.enqueue(msg("p", true))
+	}
+	close(unblock)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for messages to be sent")
+	}
+
+	mut.Lock()
+	defer mut.Unlock()
+	// a normal message is sent after every four priority messages.
+	if got, want := strings.Join(sent, ""), "ppppnppppnppnn"; got != want {
+		t.Errorf("got order %s, want %s", got, want)
+	}
+}
+
+func TestTimeoutOvertakesProposal(t *testing.T) {
+	const n = 4
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+	teardown := createServers(t, td, ctrl)
+	defer teardown()
+
+	cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second))
+	throttled := &throttledTransport{Transport: tcpTransport{}, delay: 10 * time.Millisecond}
+	cfg.SetTransport(throttled)
+	cfg.SetSendQueues(256)
+	cfg.SetChunkSize(64 << 10)
+	td.builders[0].Register(cfg)
+	hl := td.builders.Build()
+
+	if err := cfg.Connect(td.replicas); err != nil {
+		t.Fatal(err)
+	}
+	defer cfg.Close()
+	atomic.StoreInt32(&throttled.enabled, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	proposals := make(chan time.Time, n)
+	timeouts := make(chan time.Time, n)
+	for _, hs := range hl[1:] {
+		hs.EventLoop().RegisterHandler(consensus.ProposeMsg{}, func(_ interface{}) {
+			proposals <- time.Now()
+		})
+		hs.EventLoop().RegisterHandler(consensus.TimeoutMsg{}, func(_ interface{}) {
+			timeouts <- time.Now()
+		})
+		go hs.Run(ctx)
+	}
+
+	// the proposal saturates the throttled links for several seconds.
+	start := time.Now()
+	cfg.Propose(consensus.ProposeMsg{
+		ID: 1,
+		Block: consensus.NewBlock(
+			consensus.GetGenesis().Hash(),
+			consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+			consensus.Command(make([]byte, 4<<20)), 1, 1,
+		),
+	})
+	cfg.Timeout(consensus.TimeoutMsg{ID: 1, View: 1, SyncInfo: consensus.NewSyncInfo()})
+
+	var timeoutLatency, proposalLatency time.Duration
+	for i := 1; i < n; i++ {
+		select {
+		case at := <-timeouts:
+			timeoutLatency = at.Sub(start)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the timeout message")
+		}
+	}
+	for i := 1; i < n; i++ {
+		select {
+		case at := <-proposals:
+			proposalLatency = at.Sub(start)
+		case <-time.After(30 * time.Second):
+			t.Fatal("timed out waiting for the proposal")
+		}
+	}
+	if timeoutLatency > time.Second || timeoutLatency > proposalLatency/2 {
+		t.Errorf("timeout message took %v, while the proposal took %v", timeoutLatency, proposalLatency)
+	}
+}
+
+func TestUnixSocketRemoval(t *testing.T) {
+	const n = 4
+	ctrl := gomock.NewController(t)
+	td := setupUnix(t, ctrl, n)
+	teardown := createServers(t, td, ctrl)
+
+	cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second))
+	td.builders[0].Register(cfg)
+	td.builders.Build()
+	if err := cfg.Connect(td.replicas); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Close()
+	teardown()
+
+	for _, r := range td.replicas {
+		_, path := splitAddress(r.Address)
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("socket file of replica %d was not removed: %v", r.ID, err)
+		}
+	}
+}
+
+func TestUnixSocketStale(t *testing.T) {
+	addr := UnixScheme + filepath.Join(t.TempDir(), "stale.sock")
+	_, path := splitAddress(addr)
+	// a socket file left behind by a process that did not shut down cleanly.
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+
+	lis, err := listen(addr)
+	if err != nil {
+		t.Fatalf("failed to listen on stale socket: %v", err)
+	}
+	lis.Close()
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("socket file was not removed: %v", err)
+	}
+}
+
+func TestDuplicateSuppression(t *testing.T) {
+	const n = 3
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+
+	srv := NewServer()
+	srv.SetDuplicateSuppression(DefaultDedupSize, time.Minute)
+	srv.StartOnListener(td.listeners[0])
+	defer srv.Stop()
+	td.builders[0].Register(srv)
+
+	cfgs := make([]*Config, n)
+	for i := 1; i < n; i++ {
+		cfgs[i] = NewConfig(nil, gorums.WithDialTimeout(time.Second))
+		td.builders[i].Register(cfgs[i])
+	}
+	hl := td.builders.Build()
+
+	var mut sync.Mutex
+	proposals := 0
+	timeouts := make(map[consensus.View]int)
+	hl[0].EventLoop().RegisterHandler(consensus.ProposeMsg{}, func(_ interface{}) {
+		mut.Lock()
+		proposals++
+		mut.Unlock()
+	})
+	hl[0].EventLoop().RegisterHandler(consensus.TimeoutMsg{}, func(event interface{}) {
+		mut.Lock()
+		timeouts[event.(consensus.TimeoutMsg).View]++
+		mut.Unlock()
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hl[0].Run(ctx)
+
+	for i, cfg := range cfgs[1:] {
+		// only replica 1 runs a server
+		err := cfg.Connect([]ReplicaInfo{td.replicas[0], td.replicas[i+1]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cfg.Close()
+	}
+	leader, _ := cfgs[1].Replica(1)
+	relay, _ := cfgs[2].Replica(1)
+
+	// the leader sends the same proposal three times, and replica 3 relays it.
+	qc := consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash())
+	proposal := hotstuffpb.ProposalToProto(testutil.NewProposeMsg(consensus.GetGenesis().Hash(), qc, "foo", 1, 2))
+	for i := 0; i < 3; i++ {
+		leader.(*Replica).single.Propose(context.Background(), proposal, gorums.WithNoSendWaiting())
+	}
+	relay.(*Replica).single.Forward(context.Background(), proposal, gorums.WithNoSendWaiting())
+
+	// the leader sends the timeout for view 1 three times, and the timeout for view 2 once.
+	for _, view := range []consensus.View{1, 1, 1, 2} {
+		timeout := consensus.TimeoutMsg{ID: 2, View: view, SyncInfo: consensus.NewSyncInfo()}
+		leader.(*Replica).single.Timeout(context.Background(), hotstuffpb.TimeoutMsgToProto(timeout), gorums.WithNoSendWaiting())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats := srv.NetworkStats()
+		if stats[ProposalClass].Received == 4 && stats[TimeoutClass].Received == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("messages were not received: %v", stats)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// wait for the delivered messages to be handled
+	time.Sleep(100 * time.Millisecond)
+
+	mut.Lock()
+	defer mut.Unlock()
+	if proposals != 1 {
+		t.Errorf("got %d proposals, want 1", proposals)
+	}
+	if timeouts[1] != 1 || timeouts[2] != 1 {
+		t.Errorf("got %d timeouts for view 1 and %d for view 2, want 1 of each", timeouts[1], timeouts[2])
+	}
+	stats := srv.NetworkStats()
+	if got := stats[ProposalClass].Duplicates; got != 3 {
+		t.Errorf("got %d duplicate proposals, want 3", got)
+	}
+	if got := stats[TimeoutClass].Duplicates; got != 2 {
+		t.Errorf("got %d duplicate timeouts, want 2", got)
+	}
+}
+
+// TestReplayedVoteDoesNotSuppressGenuine checks that a vote that is replayed by a replica other than its signer is
+// dropped without suppressing the genuine vote, when it is later received from its signer.
+func TestReplayedVoteDoesNotSuppressGenuine(t *testing.T) {
+	const n = 3
+	ctrl := gomock.NewController(t)
+	td := setupReplicas(t, ctrl, n)
+
+	srv := NewServer()
+	srv.SetDuplicateSuppression(DefaultDedupSize, time.Minute)
+	srv.StartOnListener(td.listeners[0])
+	defer srv.Stop()
+	td.builders[0].Register(srv)
+
+	cfgs := make([]*Config, n)
+	for i := 1; i < n; i++ {
+		cfgs[i] = NewConfig(nil, gorums.WithDialTimeout(time.Second))
+		td.builders[i].Register(cfgs[i])
+	}
+	hl := td.builders.Build()
+
+	votes := make(chan consensus.VoteMsg, 2)
+	hl[0].EventLoop().RegisterHandler(consensus.VoteMsg{}, func(event interface{}) {
+		votes <- event.(consensus.VoteMsg)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hl[0].Run(ctx)
+
+	for i, cfg := range cfgs[1:] {
+		// only replica 1 runs a server
+		err := cfg.Connect([]ReplicaInfo{td.replicas[0], td.replicas[i+1]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cfg.Close()
+	}
+
+	block := testutil.NewProposeMsg(consensus.GetGenesis().Hash(), consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()), "foo", 1, 1).Block
+	vote := testutil.CreatePC(t, block, hl[2].Crypto()) // signed by replica 3
+
+	// replica 2 replays the vote of replica 3 before replica 3 sends it.
+	replayer, _ := cfgs[1].Replica(1)
+	replayer.Vote(vote)
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.Impersonations() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the replayed vote was not detected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	signer, _ := cfgs[2].Replica(1)
+	signer.Vote(vote)
+
+	select {
+	case got := <-votes:
+		if got.ID != 3 {
+			t.Errorf("got the vote from replica %d, want the genuine vote from replica 3", got.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the genuine vote was not received")
+	}
+	if got := srv.NetworkStats()[VoteClass].Duplicates; got != 0 {
+		t.Errorf("got %d duplicate votes, want 0", got)
+	}
+}
+
+func TestDedupCacheBounds(t *testing.T) {
+	c := newDedupCache(2, time.Second)
+	key := func(i byte) dedupKey { return dedupKey{class: VoteClass, digest: [32]byte{i}} }
+	now := time.Now()
+
+	if c.duplicate(key(1), now) || !c.duplicate(key(1), now) {
+		t.Fatal("expected the second copy of a message to be a duplicate")
+	}
+	// the message is forgotten after the ttl.
+	if c.duplicate(key(1), now.Add(2*time.Second)) {
+		t.Error("expected the message to be forgotten after the ttl")
+	}
+	// adding two more messages evicts the oldest.
+	now = now.Add(2 * time.Second)
+	c.duplicate(key(2), now)
+	c.duplicate(key(3), now)
+	if c.duplicate(key(1), now) {
+		t.Error("expected the oldest message to be evicted")
+	}
+	if !c.duplicate(key(3), now) {
+		t.Error("expected the newest message to be remembered")
+	}
+}
+
+func TestInstances(t *testing.T) {
+	run := func(t *testing.T, setup setupFunc) {
+		const n = 4
+		ctrl := gomock.NewController(t)
+		td := setup(t, ctrl, n)
+		// instance 1 runs on all replicas except replica 4.
+		instBuilders := testutil.CreateBuilders(t, ctrl, n, td.keys...)
+
+		servers := make([]*Server, n)
+		instServers := make([]*Server, n-1)
+		for i := range servers {
+			servers[i] = NewServer(gorums.WithGRPCServerOptions(grpc.Creds(td.creds)))
+			servers[i].StartOnListener(td.listeners[i])
+			td.builders[i].Register(servers[i])
+			if i < n-1 {
+				instServers[i] = servers[i].NewInstance(1)
+				instBuilders[i].Register(instServers[i])
+			}
+		}
+		defer func() {
+			for _, srv := range servers {
+				srv.Stop()
+			}
+		}()
+
+		cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second))
+		td.builders[0].Register(cfg)
+		inst := cfg.NewInstance(1)
+		instBuilders[0].Register(inst)
+		roots := td.builders.Build()
+		instances := instBuilders.Build()
+
+		if err := cfg.Connect(td.replicas); err != nil {
+			t.Fatal(err)
+		}
+		if err := inst.Connect(td.replicas); err != nil {
+			t.Fatal(err)
+		}
+		defer cfg.Close()
+
+		type delivery struct {
+			instance uint32
+			id       hotstuff.ID
+		}
+		received := make(chan delivery, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		observe := func(mods *consensus.Modules, instance uint32) {
+			mods.EventLoop().RegisterHandler(consensus.ProposeMsg{}, func(event interface{}) {
+				received <- delivery{instance: instance, id: mods.ID()}
+			})
+			go mods.Run(ctx)
+		}
+		for i := 1; i < n; i++ {
+			observe(roots[i], 0)
+			if i < n-1 {
+				observe(instances[i], 1)
+			}
+		}
+
+		// expect receives the deliveries of a proposal, and checks that no other replicas receive it.
+		expect := func(instance uint32, ids ...hotstuff.ID) {
+			t.Helper()
+			want := make(map[hotstuff.ID]bool)
+			for _, id := range ids {
+				want[id] = true
+			}
+			timeout := time.After(2 * time.Second)
+			for len(want) > 0 {
+				select {
+				case d := <-received:
+					if d.instance != instance || !want[d.id] {
+						t.Fatalf("replica %d received a proposal of instance %d in instance %d", d.id, instance, d.instance)
+					}
+					delete(want, d.id)
+				case <-timeout:
+					t.Fatalf("timed out waiting for instance %d of replicas %v", instance, want)
+				}
+			}
+			select {
+			case d := <-received:
+				t.Fatalf("replica %d received a proposal of instance %d in instance %d", d.id, instance, d.instance)
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+
+		proposal := func(cmd string) consensus.ProposeMsg {
+			return consensus.ProposeMsg{ID: 1, Block: consensus.NewBlock(
+				consensus.GetGenesis().Hash(),
+				consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+				consensus.Command(cmd), 1, 1,
+			)}
+		}
+		// the proposal of instance 1 is dropped by replica 4, which does not run it.
+		inst.Propose(proposal("instance 1"))
+		expect(1, 2, 3)
+		cfg.Propose(proposal("instance 0"))
+		expect(0, 2, 3, 4)
+
+		// stopping the server of an instance only stops the delivery of its messages.
+		instServers[1].Stop()
+		inst.Propose(proposal("stopped"))
+		expect(1, 3)
+
+		// closing an instance leaves the shared connections open.
+		inst.Close()
+		cfg.Propose(proposal("closed"))
+		expect(0, 2, 3, 4)
+	}
+	runBoth(t, run)
+}
+
+// setupQUIC sets up replicas that listen with the given QUIC transport.
+func setupQUIC(transport Transport) setupFunc {
+	return func(t *testing.T, ctrl *gomock.Controller, n int) testData {
+		t.Helper()
+		return setupListeners(t, ctrl, n, func(int) (net.Listener, string) {
+			lis, err := transport.Listen("127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			return lis, lis.Addr().String()
+		})
+	}
+}
+
+func TestQUIC(t *testing.T) {
+	want := consensus.ProposeMsg{
+		ID: 1,
+		Block: consensus.NewBlock(
+			consensus.GetGenesis().Hash(),
+			consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+			"foo", 1, 1,
+		),
+	}
+	run := func(t *testing.T, setup setupFunc, transport Transport) {
+		const n = 4
+		ctrl := gomock.NewController(t)
+		td := setup(t, ctrl, n)
+		teardown := createServers(t, td, ctrl)
+		defer teardown()
+
+		cfg := NewConfig(td.creds, gorums.WithDialTimeout(time.Second), WithTransport(transport))
+		td.builders[0].Register(cfg)
+		hl := td.builders.Build()
+
+		if err := cfg.Connect(td.replicas); err != nil {
+			t.Fatal(err)
+		}
+		defer cfg.Close()
+
+		var wg sync.WaitGroup
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		for _, hs := range hl[1:] {
+			hs.EventLoop().RegisterHandler(want, func(event interface{}) {
+				if got := event.(consensus.ProposeMsg); got.Block.Hash() != want.Block.Hash() {
+					t.Error("block hashes do not match")
+				}
+				wg.Done()
+			})
+			go hs.Run(ctx)
+		}
+		wg.Add(n - 1)
+		cfg.Propose(want)
+		wg.Wait()
+	}
+	transport, err := GetTransport("quic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("NoTLS", func(t *testing.T) { run(t, setupQUIC(transport), transport) })
+	t.Run("WithTLS", func(t *testing.T) {
+		run(t, func(t *testing.T, ctrl *gomock.Controller, n int) testData {
+			return withTLS(t, setupQUIC(transport)(t, ctrl, n))
+		}, transport)
+	})
+	t.Run("PacketLoss", func(t *testing.T) {
+		lossy := NewQUICTransport(QUICOptions{PacketLoss: 0.1})
+		run(t, setupQUIC(lossy), lossy)
+	})
+}
+
+// TestQUICVerifiesCertificates checks that a QUIC transport with a client TLS configuration
+// does not connect to a listener whose certificate it does not trust.
+func TestQUICVerifiesCertificates(t *testing.T) {
+	lis, err := NewQUICTransport(QUICOptions{}).Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+
+	transport := NewQUICTransport(QUICOptions{}).(TLSTransport).WithTLS(nil, &tls.Config{RootCAs: x509.NewCertPool()})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if conn, err := transport.Dial(ctx, lis.Addr().String()); err == nil {
+		conn.Close()
+		t.Error("connected to a listener with an untrusted certificate")
+	}
+
+	if _, err := NewQUICTransport(QUICOptions{}).Listen(UnixScheme + filepath.Join(t.TempDir(), "replica.sock")); err == nil {
+		t.Error("listened on a unix domain socket")
+	}
+}
+
+// TestLossyPacketConn checks that the packet loss of the QUIC transport drops the packets that are written.
+func TestLossyPacketConn(t *testing.T) {
+	receiver, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer receiver.Close()
+	transport := NewQUICTransport(QUICOptions{PacketLoss: 1}).(*quicTransport)
+	sender, err := transport.packetConn("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sender.Close()
+
+	for i := 0; i < 10; i++ {
+		if _, err := sender.WriteTo([]byte("foo"), receiver.LocalAddr()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	receiver.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, _, err := receiver.ReadFrom(make([]byte, 8)); err == nil {
+		t.Error("received a packet that should have been dropped")
+	}
+}
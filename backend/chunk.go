@@ -0,0 +1,261 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/relab/gorums"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultReassemblyTimeout is the default time to wait for all chunks of a proposal to arrive.
+	DefaultReassemblyTimeout = 10 * time.Second
+	// DefaultReassemblyBudget is the default number of bytes of incomplete proposals that are buffered for each peer.
+	DefaultReassemblyBudget = 64 << 20
+)
+
+var (
+	errInvalidChunk   = errors.New("invalid chunk")
+	errBudgetExceeded = errors.New("reassembly budget exceeded")
+)
+
+// ProposalReassemblyTimeout is emitted when some chunks of a proposal did not arrive before the reassembly timeout.
+// The chunks that were received are discarded.
+type ProposalReassemblyTimeout struct {
+	ID       hotstuff.ID
+	View     consensus.View
+	Received int
+	Total    int
+}
+
+// SetChunkSize enables chunking of proposals whose serialized size exceeds the given number of bytes.
+// Such proposals are split into chunks carrying at most size bytes of the proposal, which are reassembled by the receivers.
+// This allows proposals that are larger than the maximum message size of the receivers to be sent.
+// Proposals are not chunked when using tree dissemination. SetChunkSize must be called before Connect.
+func (cfg *Config) SetChunkSize(size int) {
+	cfg.chunkSize = size
+}
+
+// splitProposal serializes the proposal and splits it into chunks of at most size bytes.
+// It returns nil if chunking is disabled or the proposal fits in a single chunk.
+func splitProposal(p *hotstuffpb.Proposal, hash consensus.Hash, size int) ([]*hotstuffpb.ProposalChunk, error) {
+	if size <= 0 || proto.Size(p) <= size {
+		return nil, nil
+	}
+	b, err := proto.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	total := (len(b) + size - 1) / size
+	chunks := make([]*hotstuffpb.ProposalChunk, 0, total)
+	for i := 0; i < total; i++ {
+		end := (i + 1) * size
+		if end > len(b) {
+			end = len(b)
+		}
+		chunks = append(chunks, &hotstuffpb.ProposalChunk{
+			View:     p.GetBlock().GetView(),
+			Hash:     hash[:],
+			Index:    uint32(i),
+			Total:    uint32(total),
+			Data:     b[i*size : end],
+			Instance: p.GetInstance(),
+		})
+	}
+	return chunks, nil
+}
+
+// proposeChunks sends the chunks of a proposal to all replicas.
+func (cfg *Config) proposeChunks(ctx context.Context, chunks []*hotstuffpb.ProposalChunk) {
+	ids := cfg.peerIDs()
+	for _, c := range chunks {
+		cfg.countSent(ProposalClass, c, ids...)
+	}
+	if cfg.latencies != nil {
+		cfg.delayed(func(r *Replica) {
+			for _, c := range chunks {
+				r.single.ProposeChunk(context.Background(), c, gorums.WithNoSendWaiting())
+			}
+		})
+		return
+	}
+	if cfg.sendQueueSize > 0 {
+		// each chunk is queued separately, such that priority messages can be sent between them.
+		for _, c := range chunks {
+			c := c
+			cfg.queued(queuedMsg{critical: true}, func(r *Replica) { r.single.ProposeChunk(ctx, c) })
+		}
+		return
+	}
+	for _, c := range chunks {
+		cfg.cfg.ProposeChunk(ctx, c, gorums.WithNoSendWaiting())
+	}
+}
+
+type chunkKey struct {
+	view uint64
+	hash consensus.Hash
+}
+
+// partialProposal holds the chunks of a proposal that have been received so far.
+type partialProposal struct {
+	total  uint32
+	chunks map[uint32][]byte
+	size   int
+	timer  *time.Timer
+}
+
+// reassembler reassembles proposals from chunks.
+// To limit the memory used by incomplete proposals, each peer has a budget of bytes that can be buffered,
+// and incomplete proposals are discarded after a timeout. It is safe for concurrent use.
+type reassembler struct {
+	timeout   time.Duration
+	budget    int
+	onTimeout func(ProposalReassemblyTimeout)
+
+	mut     sync.Mutex
+	used    map[hotstuff.ID]int
+	pending map[hotstuff.ID]map[chunkKey]*partialProposal
+}
+
+func newReassembler(timeout time.Duration, budget int, onTimeout func(ProposalReassemblyTimeout)) *reassembler {
+	return &reassembler{
+		timeout:   timeout,
+		budget:    budget,
+		onTimeout: onTimeout,
+		used:      make(map[hotstuff.ID]int),
+		pending:   make(map[hotstuff.ID]map[chunkKey]*partialProposal),
+	}
+}
+
+// add adds a chunk received from the given peer.
+// If the chunk completes a proposal, the proposal is returned.
+func (r *reassembler) add(id hotstuff.ID, chunk *hotstuffpb.ProposalChunk) (*hotstuffpb.Proposal, error) {
+	total, index, data := chunk.GetTotal(), chunk.GetIndex(), chunk.GetData()
+	if index >= total || len(data) == 0 || int(total) > r.budget {
+		return nil, fmt.Errorf("%w: chunk %d of %d with %d bytes", errInvalidChunk, index, total, len(data))
+	}
+	var key chunkKey
+	key.view = chunk.GetView()
+	copy(key.hash[:], chunk.GetHash())
+
+	r.mut.Lock()
+	peer, ok := r.pending[id]
+	if !ok {
+		peer = make(map[chunkKey]*partialProposal)
+		r.pending[id] = peer
+	}
+	partial, ok := peer[key]
+	if !ok {
+		partial = &partialProposal{total: total, chunks: make(map[uint32][]byte)}
+		partial.timer = time.AfterFunc(r.timeout, func() { r.expire(id, key, partial) })
+		peer[key] = partial
+	}
+	if partial.total != total {
+		r.removeLocked(id, key, partial)
+		r.mut.Unlock()
+		return nil, fmt.Errorf("%w: chunk %d has total %d, previous chunks had total %d", errInvalidChunk, index, total, partial.total)
+	}
+	if _, ok := partial.chunks[index]; ok {
+		r.mut.Unlock()
+		return nil, nil
+	}
+	if r.used[id]+len(data) > r.budget {
+		r.removeLocked(id, key, partial)
+		r.mut.Unlock()
+		return nil, fmt.Errorf("%w: discarded proposal for view %d", errBudgetExceeded, key.view)
+	}
+	partial.chunks[index] = data
+	partial.size += len(data)
+	r.used[id] += len(data)
+	if len(partial.chunks) < int(total) {
+		r.mut.Unlock()
+		return nil, nil
+	}
+	r.removeLocked(id, key, partial)
+	r.mut.Unlock()
+
+	b := make([]byte, 0, partial.size)
+	for i := uint32(0); i < total; i++ {
+		b = append(b, partial.chunks[i]...)
+	}
+	p := &hotstuffpb.Proposal{}
+	if err := proto.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidChunk, err)
+	}
+	return p, nil
+}
+
+// expire discards a proposal whose chunks did not arrive in time.
+func (r *reassembler) expire(id hotstuff.ID, key chunkKey, partial *partialProposal) {
+	r.mut.Lock()
+	if r.pending[id][key] != partial {
+		// already completed or discarded
+		r.mut.Unlock()
+		return
+	}
+	r.removeLocked(id, key, partial)
+	r.mut.Unlock()
+	r.onTimeout(ProposalReassemblyTimeout{
+		ID:       id,
+		View:     consensus.View(key.view),
+		Received: len(partial.chunks),
+		Total:    int(partial.total),
+	})
+}
+
+func (r *reassembler) removeLocked(id hotstuff.ID, key chunkKey, partial *partialProposal) {
+	partial.timer.Stop()
+	r.used[id] -= partial.size
+	delete(r.pending[id], key)
+}
+
+// SetChunkReassembly sets how long to wait for all chunks of a proposal to arrive,
+// and how many bytes of incomplete proposals can be buffered for each peer.
+// The defaults are DefaultReassemblyTimeout and DefaultReassemblyBudget.
+// SetChunkReassembly must be called before the server is started.
+func (srv *Server) SetChunkReassembly(timeout time.Duration, budget int) {
+	srv.chunks = newReassembler(timeout, budget, srv.reassemblyTimeout)
+}
+
+func (srv *Server) reassemblyTimeout(event ProposalReassemblyTimeout) {
+	srv.mods.Logger().Infof("Discarded proposal for view %d from replica %d: received %d of %d chunks before timing out",
+		event.View, event.ID, event.Received, event.Total)
+	srv.mods.EventLoop().AddEvent(event)
+}
+
+// ProposeChunk handles a chunk of a proposal that was too large to be sent in a single message.
+// The proposal is handled once all of its chunks have been received.
+func (impl *serviceImpl) ProposeChunk(ctx gorums.ServerCtx, chunk *hotstuffpb.ProposalChunk) {
+	srv := impl.srv
+	srv.stats.received(ProposalClass, chunk)
+
+	id, err := GetPeerIDFromContext(ctx, srv.mods.Configuration())
+	if err != nil {
+		srv.mods.Logger().Infof("Failed to get client ID: %v", err)
+		return
+	}
+	if srv.isIncompatible(id) {
+		return
+	}
+	proposal, err := srv.chunks.add(id, chunk)
+	if err != nil {
+		srv.mods.Logger().Infof("Failed to reassemble proposal from replica %d: %v", id, err)
+		return
+	}
+	if proposal == nil {
+		return
+	}
+	// the rate limit applies to whole proposals, as the memory used by chunks is limited by the reassembly budget.
+	if !srv.allow(id, ProposalClass) {
+		return
+	}
+	srv.handleProposal(id, proposal)
+}
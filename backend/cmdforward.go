@@ -0,0 +1,64 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/relab/gorums"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+)
+
+// CommandHandler handles a client command that was forwarded by the replica with the given id.
+// The command is serialized by the client protocol, and is not interpreted by the backend.
+type CommandHandler func(id hotstuff.ID, cmd []byte)
+
+// SetCommandHandler sets the handler for client commands forwarded by other replicas.
+// The handler is called by the server's goroutines rather than the event loop, because the leader's event loop
+// may be blocked while the command queue waits for commands. The handler must be safe for concurrent use.
+// Forwarded commands are dropped if no handler is set. SetCommandHandler must be called before the server is started.
+func (srv *Server) SetCommandHandler(handler CommandHandler) {
+	srv.cmdHandler = handler
+}
+
+// ForwardCommand sends a serialized client command to the replica with the given id,
+// typically because that replica is, or will soon be, the leader.
+func (cfg *Config) ForwardCommand(id hotstuff.ID, cmd []byte) {
+	replica, ok := cfg.replicas[id].(*Replica)
+	if !ok || !replica.active() {
+		return
+	}
+	msg := &hotstuffpb.ClientCommand{Command: cmd, Instance: cfg.instance}
+	cfg.countSent(CommandClass, msg, id)
+	send := func() {
+		if replica.active() {
+			replica.node.ForwardCommand(context.Background(), msg, gorums.WithNoSendWaiting())
+		}
+	}
+	if replica.delay != nil {
+		replica.delay.enqueue(send)
+		return
+	}
+	if replica.queue != nil {
+		// the sender keeps the command, so it is not lost if the forwarded copy is dropped.
+		replica.queue.enqueue(queuedMsg{send: send})
+		return
+	}
+	send()
+}
+
+// ForwardCommand handles a client command that was forwarded by another replica.
+func (impl *serviceImpl) ForwardCommand(ctx gorums.ServerCtx, msg *hotstuffpb.ClientCommand) {
+	impl.srv.stats.received(CommandClass, msg)
+
+	id, err := GetPeerIDFromContext(ctx, impl.srv.mods.Configuration())
+	if err != nil {
+		impl.srv.mods.Logger().Infof("Failed to get client ID: %v", err)
+		return
+	}
+	if !impl.srv.allow(id, CommandClass) {
+		return
+	}
+	if impl.srv.cmdHandler != nil {
+		impl.srv.cmdHandler(id, msg.GetCommand())
+	}
+}
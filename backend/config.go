@@ -4,6 +4,8 @@ package backend
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/relab/gorums"
 	"github.com/relab/hotstuff"
@@ -13,15 +15,25 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 )
 
 // Replica provides methods used by hotstuff to send messages to replicas.
 type Replica struct {
+	cfg           *Config
 	node          *hotstuffpb.Node
 	id            hotstuff.ID
 	pubKey        consensus.PublicKey
 	voteCancel    context.CancelFunc
 	newviewCancel context.CancelFunc
+
+	// the following fields are only used when latency emulation is enabled.
+	delay  *delayQueue
+	single *hotstuffpb.Configuration // configuration containing only this replica, used for multicasts
+
+	queue *sendQueue // only used when send queues are enabled.
+
+	excluded int32 // set atomically when the replica is excluded because it is incompatible.
 }
 
 // ID returns the replica's ID.
@@ -29,6 +41,12 @@ func (r *Replica) ID() hotstuff.ID {
 	return r.id
 }
 
+// active returns true if messages can be sent to the replica.
+// The node of a replica does not change after Connect, so it can be used by goroutines that check active first.
+func (r *Replica) active() bool {
+	return atomic.LoadInt32(&r.cfg.connected) == 1 && r.node != nil && atomic.LoadInt32(&r.excluded) == 0
+}
+
 // PublicKey returns the replica's public key.
 func (r *Replica) PublicKey() consensus.PublicKey {
 	return r.pubKey
@@ -36,25 +54,68 @@ func (r *Replica) PublicKey() consensus.PublicKey {
 
 // Vote sends the partial certificate to the other replica.
 func (r *Replica) Vote(cert consensus.PartialCert) {
-	if r.node == nil {
+	if !r.active() {
+		return
+	}
+	pCert := hotstuffpb.PartialCertToProto(cert)
+	pCert.Instance = r.cfg.instance
+	r.cfg.countSent(VoteClass, pCert, r.id)
+	if r.delay != nil {
+		r.delay.enqueue(func() {
+			if r.active() {
+				r.node.Vote(context.Background(), pCert, gorums.WithNoSendWaiting())
+			}
+		})
+		return
+	}
+	if r.queue != nil {
+		r.queue.enqueue(queuedMsg{critical: true, send: func() {
+			if r.active() {
+				r.node.Vote(context.Background(), pCert)
+			}
+		}})
 		return
 	}
 	var ctx context.Context
 	r.voteCancel()
 	ctx, r.voteCancel = context.WithCancel(context.Background())
-	pCert := hotstuffpb.PartialCertToProto(cert)
 	r.node.Vote(ctx, pCert, gorums.WithNoSendWaiting())
 }
 
 // NewView sends the quorum certificate to the other replica.
 func (r *Replica) NewView(msg consensus.SyncInfo) {
-	if r.node == nil {
+	if !r.active() {
+		return
+	}
+	pMsg := hotstuffpb.SyncInfoToProto(msg)
+	pMsg.Instance = r.cfg.instance
+	r.cfg.countSent(NewViewClass, pMsg, r.id)
+	if r.delay != nil {
+		r.delay.enqueue(func() {
+			if r.active() {
+				r.node.NewView(context.Background(), pMsg, gorums.WithNoSendWaiting())
+			}
+		})
+		return
+	}
+	if r.queue != nil {
+		r.queue.enqueue(queuedMsg{critical: true, priority: true, send: func() {
+			if r.active() {
+				r.node.NewView(context.Background(), pMsg)
+			}
+		}})
 		return
 	}
 	var ctx context.Context
 	r.newviewCancel()
 	ctx, r.newviewCancel = context.WithCancel(context.Background())
-	r.node.NewView(ctx, hotstuffpb.SyncInfoToProto(msg), gorums.WithNoSendWaiting())
+	r.node.NewView(ctx, pMsg, gorums.WithNoSendWaiting())
+}
+
+// ProposalCompressedEvent is emitted when the command of a proposal has been compressed before sending it.
+type ProposalCompressedEvent struct {
+	RawSize        int // The size of the command before compression.
+	CompressedSize int // The size of the command after compression.
 }
 
 // Config holds information about the current configuration of replicas that participate in the protocol,
@@ -63,11 +124,33 @@ type Config struct {
 	mods    *consensus.Modules
 	optsPtr *[]gorums.ManagerOption // using a pointer so that options can be GCed after initialization
 
-	mgr           *hotstuffpb.Manager
-	cfg           *hotstuffpb.Configuration
-	replicas      map[hotstuff.ID]consensus.Replica
-	proposeCancel context.CancelFunc
-	timeoutCancel context.CancelFunc
+	mgr             *hotstuffpb.Manager
+	cfg             *hotstuffpb.Configuration
+	supervisor      *connSupervisor
+	dialer          *dialer
+	replicas        map[hotstuff.ID]consensus.Replica
+	stats           statsCollector
+	chunkSize       int
+	sendQueueSize   int
+	deliveryReports bool
+	proposeCancel   context.CancelFunc
+	timeoutCancel   context.CancelFunc
+
+	latencies map[hotstuff.ID]time.Duration
+	jitter    time.Duration
+	seed      int64 // the seed of the random number generators of the latency emulation and gossip.
+
+	tree   *treeDissemination
+	gossip *gossip
+	health *healthChecker
+
+	handshake    *hotstuffpb.HandshakeMsg
+	incompatible map[hotstuff.ID]bool
+
+	instance uint32  // the consensus instance that the messages sent by this configuration are addressed to.
+	root     *Config // the configuration whose connections are shared, or nil if this is the root.
+
+	connected int32 // set atomically when Connect has assigned the nodes of the replicas.
 }
 
 // InitConsensusModule gives the module a reference to the Modules object.
@@ -75,6 +158,22 @@ type Config struct {
 func (cfg *Config) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
 	cfg.mods = mods
 
+	if cfg.root != nil {
+		// the root must be initialized first, since the instances share its manager.
+		cfg.mgr = cfg.root.mgr
+		cfg.supervisor.addInstance(cfg)
+	} else {
+		cfg.initManager()
+	}
+
+	cfg.initTree()
+	cfg.initGossip()
+	cfg.initHealthCheck()
+	cfg.initHandshake()
+}
+
+// initManager creates the manager that connects to the other replicas.
+func (cfg *Config) initManager() {
 	opts := *cfg.optsPtr
 	cfg.optsPtr = nil // we don't need to keep the options around beyond this point, so we'll allow them to be GCed.
 
@@ -88,29 +187,75 @@ func (cfg *Config) InitConsensusModule(mods *consensus.Modules, _ *consensus.Opt
 	cfg.mgr = hotstuffpb.NewManager(opts...)
 }
 
-// NewConfig creates a new configuration.
-func NewConfig(creds credentials.TransportCredentials, opts ...gorums.ManagerOption) *Config {
-	if creds == nil {
-		creds = insecure.NewCredentials()
+// DefaultManagerOptions returns the gorums manager options that NewConfig uses by default:
+// the reconnect backoff, and the default gRPC dial options (see DefaultDialOptions).
+func DefaultManagerOptions() []gorums.ManagerOption {
+	return []gorums.ManagerOption{
+		gorums.WithBackoff(reconnectBackoff),
+		gorums.WithGrpcDialOptions(DefaultDialOptions()...),
 	}
-	grpcOpts := []grpc.DialOption{
+}
+
+// DefaultDialOptions returns the gRPC dial options that NewConfig uses by default.
+// Connecting blocks until the connections to the replicas are established,
+// and connection errors are returned instead of timing out.
+func DefaultDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
 		grpc.WithBlock(),
 		grpc.WithReturnConnectionError(),
-		grpc.WithTransportCredentials(creds),
 	}
-	opts = append(opts, gorums.WithGrpcDialOptions(grpcOpts...))
+}
 
+// NewConfig creates a new configuration.
+// The given manager options are applied after the defaults (see DefaultManagerOptions),
+// and may override them; gRPC dial options can be given with gorums.WithGrpcDialOptions.
+// The configuration also installs a dialer (see SetTransport) and the given credentials,
+// which can be overridden, and a stats handler used to detect disconnects, which cannot be overridden.
+func NewConfig(creds credentials.TransportCredentials, opts ...gorums.ManagerOption) *Config {
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
 	// initialization will be finished by InitConsensusModule
 	cfg := &Config{
 		replicas:      make(map[hotstuff.ID]consensus.Replica),
-		optsPtr:       &opts,
 		proposeCancel: func() {},
 		timeoutCancel: func() {},
+		seed:          time.Now().UnixNano(),
 	}
+	cfg.supervisor = newConnSupervisor(cfg)
+	cfg.dialer = newDialer()
+
+	// the defaults can be overridden by the options given by the caller.
+	defaults := append(DefaultManagerOptions(), gorums.WithGrpcDialOptions(
+		grpc.WithContextDialer(cfg.dial),
+		grpc.WithTransportCredentials(UnixCredentials(creds)),
+	))
+	opts = append(defaults, opts...)
+	opts = append(opts, gorums.WithGrpcDialOptions(grpc.WithStatsHandler(cfg.supervisor)))
+	cfg.optsPtr = &opts
+
 	return cfg
 }
 
+// SetLatencies enables latency emulation, which delays each message sent to a replica by the given latency,
+// plus or minus a uniformly distributed jitter. Messages to replicas without a latency are only delayed by the jitter.
+// The order of messages sent to each replica is preserved. Latency emulation is disabled by default.
+// SetLatencies must be called before Connect.
+func (cfg *Config) SetLatencies(latencies map[hotstuff.ID]time.Duration, jitter time.Duration) {
+	cfg.latencies = latencies
+	cfg.jitter = jitter
+}
+
+// SetRandomSeed sets the seed of the random number generators used by the latency emulation and the gossip layer,
+// such that their random choices can be reproduced. The seed is based on the current time by default.
+// SetRandomSeed must be called before SetGossip and Connect.
+func (cfg *Config) SetRandomSeed(seed int64) {
+	cfg.seed = seed
+}
+
 // ReplicaInfo holds information about a replica.
+// The address may contain a host name, which is resolved again whenever the replica is dialed,
+// or refer to a unix domain socket, for example unix:///tmp/replica1.sock.
 type ReplicaInfo struct {
 	ID      hotstuff.ID
 	Address string
@@ -124,6 +269,7 @@ func (cfg *Config) Connect(replicas []ReplicaInfo) (err error) {
 	for _, replica := range replicas {
 		// also initialize Replica structures
 		cfg.replicas[replica.ID] = &Replica{
+			cfg:           cfg,
 			id:            replica.ID,
 			pubKey:        replica.PubKey,
 			newviewCancel: func() {},
@@ -131,7 +277,19 @@ func (cfg *Config) Connect(replicas []ReplicaInfo) (err error) {
 		}
 		// we do not want to connect to ourself
 		if replica.ID != cfg.mods.ID() {
-			idMapping[replica.Address] = uint32(replica.ID)
+			addr, err := cfg.dialer.resolve(context.Background(), replica.ID, replica.Address)
+			if err != nil {
+				return fmt.Errorf("invalid address for replica %d: %w", replica.ID, err)
+			}
+			idMapping[addr] = uint32(replica.ID)
+			// connections to unix domain sockets are identified by the path of the socket.
+			remote := addr
+			if IsUnixAddress(replica.Address) {
+				_, remote = splitAddress(replica.Address)
+			}
+			if cfg.root == nil {
+				cfg.supervisor.addReplica(replica.ID, remote)
+			}
 		}
 	}
 
@@ -148,11 +306,94 @@ func (cfg *Config) Connect(replicas []ReplicaInfo) (err error) {
 		id := hotstuff.ID(node.ID())
 		replica := cfg.replicas[id].(*Replica)
 		replica.node = node
+		if _, err = cfg.singleConfig(replica); err != nil {
+			return err
+		}
+		if cfg.latencies != nil {
+			err = cfg.enableDelay(replica)
+			if err != nil {
+				return err
+			}
+		} else if cfg.sendQueueSize > 0 {
+			replica.queue = newSendQueue(cfg.sendQueueSize)
+		}
+	}
+
+	atomic.StoreInt32(&cfg.connected, 1)
+
+	if cfg.gossip != nil {
+		cfg.startGossip()
+	}
+	if cfg.health != nil {
+		cfg.startHealthCheck()
+	}
+	for _, node := range cfg.cfg.Nodes() {
+		cfg.startHandshake(hotstuff.ID(node.ID()))
 	}
 
 	return nil
 }
 
+// singleConfig returns a configuration that contains only the given replica.
+// It is used to send multicast messages to a single replica.
+func (cfg *Config) singleConfig(replica *Replica) (_ *hotstuffpb.Configuration, err error) {
+	if replica.single == nil {
+		replica.single, err = cfg.mgr.NewConfiguration(qspec{}, gorums.WithNodeIDs([]uint32{uint32(replica.id)}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create configuration for replica %d: %w", replica.id, err)
+		}
+	}
+	return replica.single, nil
+}
+
+func (cfg *Config) enableDelay(replica *Replica) (err error) {
+	if _, err = cfg.singleConfig(replica); err != nil {
+		return err
+	}
+	seed := cfg.seed + int64(replica.id)
+	replica.delay = newDelayQueue(cfg.latencies[replica.id], cfg.jitter, seed)
+	return nil
+}
+
+// delayed sends a message to each replica through its delay queue.
+func (cfg *Config) delayed(send func(*Replica)) {
+	for _, r := range cfg.replicas {
+		replica := r.(*Replica)
+		if replica.delay == nil {
+			continue
+		}
+		if !replica.delay.enqueue(func() {
+			if replica.active() {
+				send(replica)
+			}
+		}) {
+			cfg.mods.Logger().Warnf("Delay queue for replica %d is full; dropping message", replica.id)
+		}
+	}
+}
+
+// queued sends a message to each replica through its send queue.
+// The send function of msg is replaced by a call to send for each replica.
+func (cfg *Config) queued(msg queuedMsg, send func(*Replica)) {
+	for _, r := range cfg.replicas {
+		replica := r.(*Replica)
+		if replica.queue == nil {
+			continue
+		}
+		msg.send = func() {
+			if replica.active() {
+				send(replica)
+			}
+		}
+		replica.queue.enqueue(msg)
+	}
+}
+
+// IsConnected returns true if there is currently a connection to the replica with the given id.
+func (cfg *Config) IsConnected(id hotstuff.ID) bool {
+	return cfg.supervisor.isConnected(id)
+}
+
 // Replicas returns all of the replicas in the configuration.
 func (cfg *Config) Replicas() map[hotstuff.ID]consensus.Replica {
 	return cfg.replicas
@@ -183,6 +424,37 @@ func (cfg *Config) Propose(proposal consensus.ProposeMsg) {
 	cfg.proposeCancel()
 	ctx, cfg.proposeCancel = context.WithCancel(context.Background())
 	p := hotstuffpb.ProposalToProto(proposal)
+	p.Instance = cfg.instance
+	if cfg.mods.Options().ShouldCompressProposals() {
+		raw, compressed := hotstuffpb.CompressBlock(p.GetBlock())
+		cfg.mods.EventLoop().AddEvent(ProposalCompressedEvent{RawSize: raw, CompressedSize: compressed})
+	}
+	if cfg.tree != nil {
+		cfg.proposeTree(p)
+		return
+	}
+	chunks, err := splitProposal(p, proposal.Block.Hash(), cfg.chunkSize)
+	if err != nil {
+		cfg.mods.Logger().Errorf("Failed to split proposal into chunks: %v", err)
+		return
+	}
+	if chunks != nil {
+		cfg.proposeChunks(ctx, chunks)
+		return
+	}
+	cfg.countSent(ProposalClass, p, cfg.peerIDs()...)
+	if cfg.latencies != nil {
+		cfg.delayed(func(r *Replica) { r.single.Propose(context.Background(), p, gorums.WithNoSendWaiting()) })
+		return
+	}
+	if cfg.deliveryReports {
+		cfg.deliverProposal(ctx, p)
+		return
+	}
+	if cfg.sendQueueSize > 0 {
+		cfg.queued(queuedMsg{critical: true}, func(r *Replica) { r.single.Propose(ctx, p) })
+		return
+	}
 	cfg.cfg.Propose(ctx, p, gorums.WithNoSendWaiting())
 }
 
@@ -191,15 +463,31 @@ func (cfg *Config) Timeout(msg consensus.TimeoutMsg) {
 	if cfg.cfg == nil {
 		return
 	}
+	pMsg := hotstuffpb.TimeoutMsgToProto(msg)
+	pMsg.Instance = cfg.instance
+	cfg.countSent(TimeoutClass, pMsg, cfg.peerIDs()...)
+	if cfg.latencies != nil {
+		cfg.delayed(func(r *Replica) { r.single.Timeout(context.Background(), pMsg, gorums.WithNoSendWaiting()) })
+		return
+	}
+	if cfg.sendQueueSize > 0 {
+		cfg.queued(queuedMsg{critical: true, priority: true}, func(r *Replica) { r.single.Timeout(context.Background(), pMsg) })
+		return
+	}
 	var ctx context.Context
 	cfg.timeoutCancel()
 	ctx, cfg.timeoutCancel = context.WithCancel(context.Background())
-	cfg.cfg.Timeout(ctx, hotstuffpb.TimeoutMsgToProto(msg), gorums.WithNoSendWaiting())
+	cfg.cfg.Timeout(ctx, pMsg, gorums.WithNoSendWaiting())
 }
 
 // Fetch requests a block from all the replicas in the configuration
 func (cfg *Config) Fetch(ctx context.Context, hash consensus.Hash) (*consensus.Block, bool) {
-	protoBlock, err := cfg.cfg.Fetch(ctx, &hotstuffpb.BlockHash{Hash: hash[:]})
+	if cfg.cfg == nil {
+		return nil, false
+	}
+	req := &hotstuffpb.BlockHash{Hash: hash[:], Instance: cfg.instance}
+	cfg.countSent(FetchClass, req, cfg.peerIDs()...)
+	protoBlock, err := cfg.cfg.Fetch(ctx, req)
 	if err != nil {
 		qcErr, ok := err.(gorums.QuorumCallError)
 		// filter out context errors
@@ -208,11 +496,75 @@ func (cfg *Config) Fetch(ctx context.Context, hash consensus.Hash) (*consensus.B
 		}
 		return nil, false
 	}
-	return hotstuffpb.BlockFromProto(protoBlock), true
+	block, err := hotstuffpb.BlockFromProto(protoBlock)
+	if err != nil {
+		cfg.mods.Logger().Infof("Failed to decode fetched block: %v", err)
+		return nil, false
+	}
+	return block, true
+}
+
+// peerIDs returns the IDs of the replicas that this replica is connected to.
+func (cfg *Config) peerIDs() []hotstuff.ID {
+	ids := make([]hotstuff.ID, 0, len(cfg.replicas))
+	for id, r := range cfg.replicas {
+		if r.(*Replica).active() {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// countSent updates the network counters for a message sent to the given replicas.
+// Messages to replicas that are currently disconnected are counted as failed.
+func (cfg *Config) countSent(class MessageClass, msg proto.Message, ids ...hotstuff.ID) {
+	sent := 0
+	for _, id := range ids {
+		if cfg.supervisor.isConnected(id) {
+			sent++
+		}
+	}
+	if sent > 0 {
+		cfg.stats.sent(class, msg, sent)
+	}
+	if failed := len(ids) - sent; failed > 0 {
+		cfg.stats.failed(class, failed)
+	}
+}
+
+// NetworkStats returns the network counters of this replica since it was started.
+// The counters for received messages are included if the replica's Server is registered in the same modules.
+func (cfg *Config) NetworkStats() NetworkStats {
+	stats := cfg.stats.snapshot()
+	var srv *Server
+	if cfg.mods != nil && cfg.mods.GetModuleByType(&srv) {
+		for class, s := range srv.NetworkStats() {
+			c := stats[class]
+			c.Received = s.Received
+			c.ReceivedBytes = s.ReceivedBytes
+			c.Duplicates = s.Duplicates
+			stats[class] = c
+		}
+	}
+	return stats
 }
 
 // Close closes all connections made by this configuration.
+// The configuration of an instance created by NewInstance leaves the shared connections open; they are closed by the root.
 func (cfg *Config) Close() {
+	for _, r := range cfg.replicas {
+		replica := r.(*Replica)
+		if replica.delay != nil {
+			replica.delay.close()
+		}
+		if replica.queue != nil {
+			replica.queue.close()
+		}
+	}
+	if cfg.root != nil {
+		cfg.supervisor.removeInstance(cfg)
+		return
+	}
 	cfg.mgr.Close()
 }
 
@@ -226,8 +578,8 @@ func (q qspec) FetchQF(in *hotstuffpb.BlockHash, replies map[uint32]*hotstuffpb.
 	var h consensus.Hash
 	copy(h[:], in.GetHash())
 	for _, b := range replies {
-		block := hotstuffpb.BlockFromProto(b)
-		if h == block.Hash() {
+		block, err := hotstuffpb.BlockFromProto(b)
+		if err == nil && h == block.Hash() {
 			return b, true
 		}
 	}
@@ -0,0 +1,124 @@
+package backend
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultDedupSize is the default number of recently received messages that are remembered by the duplicate filter.
+	DefaultDedupSize = 4096
+	// DefaultDedupTTL is the default time that a received message is remembered by the duplicate filter.
+	DefaultDedupTTL = 30 * time.Second
+)
+
+// dedupKey identifies a logical message, regardless of which replica relayed it.
+type dedupKey struct {
+	class  MessageClass
+	digest [sha256.Size]byte
+}
+
+type dedupEntry struct {
+	key  dedupKey
+	seen time.Time
+}
+
+// dedupCache remembers recently received messages, such that copies of a message
+// that are received again, for example because of rebroadcasts or gossip, can be dropped.
+// It holds at most size messages, which are forgotten after ttl. It is safe for concurrent use.
+type dedupCache struct {
+	size int
+	ttl  time.Duration
+
+	mut     sync.Mutex
+	entries []dedupEntry // ring buffer in the order the messages were received
+	next    int
+	seen    map[dedupKey]time.Time
+}
+
+func newDedupCache(size int, ttl time.Duration) *dedupCache {
+	if size < 1 {
+		size = 1
+	}
+	return &dedupCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make([]dedupEntry, 0, size),
+		seen:    make(map[dedupKey]time.Time, size),
+	}
+}
+
+// duplicate returns true if the message was seen recently. Otherwise, the message is remembered.
+func (c *dedupCache) duplicate(key dedupKey, now time.Time) bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if seen, ok := c.seen[key]; ok && now.Sub(seen) < c.ttl {
+		return true
+	}
+	if len(c.entries) < c.size {
+		c.entries = append(c.entries, dedupEntry{key, now})
+	} else {
+		// forget the oldest message, unless it has been seen again since it was added.
+		old := c.entries[c.next]
+		if c.seen[old.key].Equal(old.seen) {
+			delete(c.seen, old.key)
+		}
+		c.entries[c.next] = dedupEntry{key, now}
+		c.next = (c.next + 1) % c.size
+	}
+	c.seen[key] = now
+	return false
+}
+
+// digestKey returns a key that identifies a message by a digest of its serialized contents.
+func digestKey(class MessageClass, msg proto.Message) dedupKey {
+	b, _ := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	return dedupKey{class: class, digest: sha256.Sum256(b)}
+}
+
+// voteKey returns a key that identifies a vote by its sender and a digest of its serialized contents.
+// The sender is included, such that a vote that is replayed by another replica, and dropped since it is signed by
+// a different replica than the sender, does not cause the genuine vote to be dropped as a duplicate.
+func voteKey(id hotstuff.ID, cert *hotstuffpb.PartialCert) dedupKey {
+	b, _ := proto.MarshalOptions{Deterministic: true}.Marshal(cert)
+	h := sha256.New()
+	var sender [4]byte
+	binary.LittleEndian.PutUint32(sender[:], uint32(id))
+	h.Write(sender[:])
+	h.Write(b)
+	key := dedupKey{class: VoteClass}
+	h.Sum(key.digest[:0])
+	return key
+}
+
+// timeoutKey returns a key that identifies a timeout by its view and sender.
+func timeoutKey(id hotstuff.ID, msg *hotstuffpb.TimeoutMsg) dedupKey {
+	key := dedupKey{class: TimeoutClass}
+	binary.LittleEndian.PutUint64(key.digest[:], msg.GetView())
+	binary.LittleEndian.PutUint32(key.digest[8:], uint32(id))
+	return key
+}
+
+// SetDuplicateSuppression enables suppression of duplicate messages. The server remembers the last size messages
+// that it received within ttl, and drops copies of them before they are decoded and delivered to the event loop.
+// Proposals are identified by their block, regardless of which replica sent them, timeouts by their view and sender,
+// votes by their contents and sender, and other messages by their contents. Suppressed messages are counted as duplicates in the network stats.
+// Duplicate suppression is disabled by default. SetDuplicateSuppression must be called before the server is started.
+func (srv *Server) SetDuplicateSuppression(size int, ttl time.Duration) {
+	srv.dedup = newDedupCache(size, ttl)
+}
+
+// isDuplicate returns true if the message identified by key was received recently, and should be dropped.
+func (srv *Server) isDuplicate(key dedupKey) bool {
+	if srv.dedup == nil || !srv.dedup.duplicate(key, time.Now()) {
+		return false
+	}
+	srv.stats.duplicate(key.class)
+	return true
+}
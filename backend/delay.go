@@ -0,0 +1,88 @@
+package backend
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// delayQueueSize is the maximum number of messages that can be waiting in a delay queue.
+const delayQueueSize = 4096
+
+type delayedMsg struct {
+	deliverAt time.Time
+	send      func()
+}
+
+// delayQueue delays outgoing messages to a single replica in order to emulate network latency.
+// Messages are sent in the order they were enqueued, even if jitter would make a later message due earlier.
+type delayQueue struct {
+	delay  time.Duration
+	jitter time.Duration
+
+	mut sync.Mutex
+	rnd *rand.Rand
+
+	msgs chan delayedMsg
+	done chan struct{}
+}
+
+func newDelayQueue(delay, jitter time.Duration, seed int64) *delayQueue {
+	q := &delayQueue{
+		delay:  delay,
+		jitter: jitter,
+		rnd:    rand.New(rand.NewSource(seed)),
+		msgs:   make(chan delayedMsg, delayQueueSize),
+		done:   make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// enqueue schedules the send function to be called after the configured delay.
+// It returns false if the queue is full, in which case the message is dropped.
+func (q *delayQueue) enqueue(send func()) bool {
+	d := q.delay
+	if q.jitter > 0 {
+		q.mut.Lock()
+		d += time.Duration(q.rnd.Int63n(2*int64(q.jitter))) - q.jitter
+		q.mut.Unlock()
+	}
+	if d < 0 {
+		d = 0
+	}
+	select {
+	case q.msgs <- delayedMsg{deliverAt: time.Now().Add(d), send: send}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (q *delayQueue) run() {
+	timer := time.NewTimer(0)
+	<-timer.C
+	for {
+		var msg delayedMsg
+		select {
+		case msg = <-q.msgs:
+		case <-q.done:
+			return
+		}
+		if wait := time.Until(msg.deliverAt); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-timer.C:
+			case <-q.done:
+				timer.Stop()
+				return
+			}
+		}
+		msg.send()
+	}
+}
+
+// close stops the queue. Messages that have not been sent yet are dropped.
+func (q *delayQueue) close() {
+	close(q.done)
+}
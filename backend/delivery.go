@@ -0,0 +1,129 @@
+package backend
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+)
+
+// DeliveryOutcome is the outcome of sending a message to a single replica.
+type DeliveryOutcome int
+
+const (
+	// Delivered means that the message was handed to the network.
+	Delivered DeliveryOutcome = iota
+	// TransientFailure means that the message could not be sent, although the replica appeared to be connected.
+	TransientFailure
+	// PeerDown means that the message was not sent because the replica is disconnected.
+	PeerDown
+	// Dropped means that the message was dropped because the send queue of the replica was full.
+	Dropped
+)
+
+func (o DeliveryOutcome) String() string {
+	switch o {
+	case Delivered:
+		return "delivered"
+	case TransientFailure:
+		return "transient failure"
+	case PeerDown:
+		return "peer down"
+	case Dropped:
+		return "dropped"
+	}
+	return "unknown"
+}
+
+// ProposalDeliveryReport is emitted once a proposal has been sent to every replica in the configuration.
+// Failed contains the IDs of the replicas that the proposal could not be delivered to, in ascending order.
+// Reports are only emitted if enabled by SetDeliveryReports, and when the proposal is sent directly to all replicas,
+// that is, not when using tree dissemination, latency emulation, or chunking.
+type ProposalDeliveryReport struct {
+	View     consensus.View
+	Failed   []hotstuff.ID
+	Outcomes map[hotstuff.ID]DeliveryOutcome
+}
+
+// SetDeliveryReports enables ProposalDeliveryReport events. Instead of a single multicast,
+// each proposal is then sent to every replica separately, such that the outcome of each send can be tracked.
+// Delivery reports are disabled by default. SetDeliveryReports must be called before Connect.
+func (cfg *Config) SetDeliveryReports(enabled bool) {
+	cfg.deliveryReports = enabled
+}
+
+// deliverProposal sends the proposal to each replica and emits a ProposalDeliveryReport when all sends have completed.
+// Sends that fail transiently are retried once, unless the context has been cancelled by then.
+// deliverProposal does not block; ctx should be cancelled when the next proposal is made.
+func (cfg *Config) deliverProposal(ctx context.Context, p *hotstuffpb.Proposal) {
+	var (
+		mut      sync.Mutex
+		wg       sync.WaitGroup
+		outcomes = make(map[hotstuff.ID]DeliveryOutcome, len(cfg.replicas))
+	)
+	setOutcome := func(id hotstuff.ID, outcome DeliveryOutcome) {
+		mut.Lock()
+		outcomes[id] = outcome
+		mut.Unlock()
+		wg.Done()
+	}
+	for _, r := range cfg.replicas {
+		replica := r.(*Replica)
+		if !replica.active() {
+			continue
+		}
+		wg.Add(1)
+		send := func(replica *Replica) {
+			outcome := cfg.sendProposal(ctx, replica, p)
+			if outcome == TransientFailure && ctx.Err() == nil {
+				outcome = cfg.sendProposal(ctx, replica, p)
+			}
+			setOutcome(replica.id, outcome)
+		}
+		if replica.queue != nil {
+			replica.queue.enqueue(queuedMsg{
+				critical: true,
+				send:     func() { send(replica) },
+				drop:     func() { setOutcome(replica.id, Dropped) },
+			})
+			continue
+		}
+		go send(replica)
+	}
+
+	go func() {
+		wg.Wait()
+		report := ProposalDeliveryReport{
+			View:     consensus.View(p.GetBlock().GetView()),
+			Outcomes: outcomes,
+		}
+		for id, outcome := range outcomes {
+			if outcome != Delivered {
+				report.Failed = append(report.Failed, id)
+			}
+		}
+		sort.Slice(report.Failed, func(i, j int) bool { return report.Failed[i] < report.Failed[j] })
+		cfg.mods.EventLoop().AddEvent(report)
+	}()
+}
+
+// sendProposal sends the proposal to a single replica and waits until it has been sent.
+func (cfg *Config) sendProposal(ctx context.Context, replica *Replica, p *hotstuffpb.Proposal) DeliveryOutcome {
+	if !replica.active() || !cfg.supervisor.isConnected(replica.id) {
+		return PeerDown
+	}
+	// gorums does not return the errors of multicast calls,
+	// so we detect failures by checking if the send caused a new error on the node's stream.
+	lastErr := replica.node.LastErr()
+	replica.single.Propose(ctx, p)
+	switch err := replica.node.LastErr(); {
+	case !cfg.supervisor.isConnected(replica.id):
+		return PeerDown
+	case err != nil && err != lastErr, ctx.Err() != nil:
+		return TransientFailure
+	}
+	return Delivered
+}
@@ -0,0 +1,192 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/relab/hotstuff"
+)
+
+// Resolver resolves host names to IP addresses. It is implemented by *net.Resolver.
+type Resolver interface {
+	// LookupHost returns the addresses of the given host.
+	LookupHost(ctx context.Context, host string) (addrs []string, err error)
+}
+
+// hostAddr is the address of a replica that was given as a host name.
+type hostAddr struct {
+	id   hotstuff.ID
+	host string
+	port string
+}
+
+// dialer connects to the replicas using a transport.
+// Addresses that contain host names are resolved again each time they are dialed,
+// such that a replica that restarts with a new IP address can be reached.
+// The dialer keeps track of the open connections, such that the connections to a replica can be closed.
+type dialer struct {
+	transport Transport
+	resolver  Resolver
+
+	mut      sync.Mutex
+	hosts    map[string]hostAddr                  // the address given to gorums -> the original host name
+	addrs    map[hotstuff.ID]string               // the address given to gorums for each replica
+	conns    map[string]map[*trackedConn]struct{} // the open connections, by the address given to gorums
+	excluded map[string]bool                      // the addresses that must not be dialed again
+}
+
+func newDialer() *dialer {
+	return &dialer{
+		transport: tcpTransport{},
+		resolver:  net.DefaultResolver,
+		hosts:     make(map[string]hostAddr),
+		addrs:     make(map[hotstuff.ID]string),
+		conns:     make(map[string]map[*trackedConn]struct{}),
+		excluded:  make(map[string]bool),
+	}
+}
+
+// trackedConn removes itself from the open connections of the dialer when it is closed.
+type trackedConn struct {
+	net.Conn
+	once    sync.Once
+	untrack func()
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(c.untrack)
+	return c.Conn.Close()
+}
+
+// track adds the connection to the open connections to the address.
+// If the address was excluded while the connection was being dialed, the connection is closed instead.
+func (d *dialer) track(addr string, conn net.Conn) (net.Conn, error) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	if d.excluded[addr] {
+		conn.Close()
+		return nil, fmt.Errorf("connections to %s are closed", addr)
+	}
+	tc := &trackedConn{Conn: conn}
+	tc.untrack = func() {
+		d.mut.Lock()
+		delete(d.conns[addr], tc)
+		d.mut.Unlock()
+	}
+	if d.conns[addr] == nil {
+		d.conns[addr] = make(map[*trackedConn]struct{})
+	}
+	d.conns[addr][tc] = struct{}{}
+	return tc, nil
+}
+
+// close closes the connections to the replica with the given id, and prevents the replica from being dialed again.
+func (d *dialer) close(id hotstuff.ID) {
+	d.mut.Lock()
+	addr, ok := d.addrs[id]
+	if !ok {
+		d.mut.Unlock()
+		return
+	}
+	d.excluded[addr] = true
+	conns := d.conns[addr]
+	delete(d.conns, addr)
+	d.mut.Unlock()
+
+	for conn := range conns {
+		conn.Conn.Close()
+	}
+}
+
+// SetTransport sets the transport used to connect to the replicas.
+// SetTransport must be called before Connect.
+func (cfg *Config) SetTransport(transport Transport) {
+	cfg.dialer.transport = transport
+}
+
+// SetResolver sets the resolver used to resolve the host names of the replicas.
+// The default is net.DefaultResolver. SetResolver must be called before Connect.
+func (cfg *Config) SetResolver(resolver Resolver) {
+	cfg.dialer.resolver = resolver
+}
+
+// resolve resolves the address of a replica to an IP address.
+// If the address contains a host name, it is remembered such that it can be resolved again when dialing.
+// The address of a unix domain socket is replaced by a placeholder (see GorumsAddress).
+func (d *dialer) resolve(ctx context.Context, id hotstuff.ID, addr string) (resolved string, err error) {
+	defer func() {
+		if err == nil {
+			d.mut.Lock()
+			d.addrs[id] = resolved
+			d.mut.Unlock()
+		}
+	}()
+	if IsUnixAddress(addr) {
+		return GorumsAddress(addr), nil
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if host == "" || net.ParseIP(host) != nil {
+		return addr, nil
+	}
+	ips, err := d.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve '%s': %w", host, err)
+	}
+	if len(ips) == 0 {
+		return "", fmt.Errorf("failed to resolve '%s': no addresses", host)
+	}
+	resolved = net.JoinHostPort(ips[0], port)
+	d.mut.Lock()
+	d.hosts[resolved] = hostAddr{id: id, host: host, port: port}
+	d.mut.Unlock()
+	return resolved, nil
+}
+
+// dial connects to the given address, unless the connections to the replica at the address have been closed.
+func (cfg *Config) dial(ctx context.Context, addr string) (net.Conn, error) {
+	d := cfg.dialer
+	d.mut.Lock()
+	excluded := d.excluded[addr]
+	d.mut.Unlock()
+	if excluded {
+		return nil, fmt.Errorf("connections to %s are closed", addr)
+	}
+	conn, err := cfg.dialHost(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	return d.track(addr, conn)
+}
+
+// dialHost connects to the given address. If the address was resolved from a host name,
+// the host name is resolved again, and each of its addresses are tried in turn.
+func (cfg *Config) dialHost(ctx context.Context, addr string) (net.Conn, error) {
+	d := cfg.dialer
+	d.mut.Lock()
+	h, ok := d.hosts[addr]
+	d.mut.Unlock()
+	if !ok {
+		return d.transport.Dial(ctx, addr)
+	}
+
+	ips, err := d.resolver.LookupHost(ctx, h.host)
+	if err != nil || len(ips) == 0 {
+		// fall back to the address that was last known to work
+		return d.transport.Dial(ctx, addr)
+	}
+	for _, ip := range ips {
+		var conn net.Conn
+		conn, err = d.transport.Dial(ctx, net.JoinHostPort(ip, h.port))
+		if err == nil {
+			// the supervisor identifies connections by their remote address, which may have changed.
+			cfg.supervisor.addReplica(h.id, conn.RemoteAddr().String())
+			return conn, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,246 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/relab/gorums"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+)
+
+// gossip holds the state of the gossip layer.
+// At regular intervals, each replica offers the IDs of recently certified blocks, along with their quorum certificates,
+// to a few random peers. The peers verify the certificates, and fetch the blocks that they are missing from the replica
+// that offered them. This allows blocks to reach replicas that cannot communicate directly with the leader.
+// Fetched blocks are only stored in the blockchain; they are never delivered as proposals,
+// since only the leader can propose.
+type gossip struct {
+	interval  time.Duration
+	fanout    int
+	maxOffers int
+	rnd       *rand.Rand
+
+	recent  []*hotstuffpb.BlockID // the most recently certified blocks, oldest first
+	offered map[hotstuff.ID]map[consensus.Hash]struct{}
+	pending map[consensus.Hash]struct{}
+}
+
+type gossipOfferEvent struct {
+	id     hotstuff.ID
+	blocks []*hotstuffpb.BlockID
+}
+
+// gossipFetchedRequest asks the event loop to store a block that was fetched through gossip.
+// The error of the response tells why the block was not stored.
+type gossipFetchedRequest struct {
+	qc    consensus.QuorumCert // the verified certificate of the block
+	block *consensus.Block     // nil if the fetch failed
+}
+
+type gossipTickEvent struct{}
+
+// SetGossip enables the gossip layer. Every interval, the IDs of at most maxOffers recently certified blocks
+// are offered to fanout randomly selected replicas. Each block is offered at most once to each replica.
+// Gossip is disabled by default. SetGossip must be called before Connect.
+func (cfg *Config) SetGossip(interval time.Duration, fanout, maxOffers int) {
+	cfg.gossip = &gossip{
+		interval:  interval,
+		fanout:    fanout,
+		maxOffers: maxOffers,
+		rnd:       rand.New(rand.NewSource(cfg.seed)),
+		offered:   make(map[hotstuff.ID]map[consensus.Hash]struct{}),
+		pending:   make(map[consensus.Hash]struct{}),
+	}
+}
+
+func (cfg *Config) initGossip() {
+	cfg.mods.EventLoop().RegisterObserver(consensus.ProposeMsg{}, func(event interface{}) {
+		if cfg.gossip == nil {
+			return
+		}
+		// the block of a proposal is offered once it is certified; the certificate is verified by the receivers.
+		cfg.addRecentBlock(event.(consensus.ProposeMsg).Block.QuorumCert())
+	})
+	cfg.mods.EventLoop().RegisterHandler(gossipTickEvent{}, func(_ interface{}) {
+		cfg.offerBlocks()
+	})
+	cfg.mods.EventLoop().RegisterHandler(gossipOfferEvent{}, func(event interface{}) {
+		e := event.(gossipOfferEvent)
+		cfg.handleOffer(e.id, e.blocks)
+	})
+	cfg.mods.EventLoop().RegisterHandler(gossipFetchedRequest{}, func(event interface{}) {
+		r := event.(*eventloop.Request)
+		r.Respond(nil, cfg.handleFetched(r.Payload.(gossipFetchedRequest)))
+	})
+	// offers are repeated every gossip round, but a fetched block must clear its pending fetch,
+	// and it is added by the goroutine that fetched it.
+	cfg.mods.EventLoop().SetOverflowPolicy(gossipOfferEvent{}, eventloop.DropNewest)
+	cfg.mods.EventLoop().SetOverflowPolicy(gossipFetchedRequest{}, eventloop.Block)
+}
+
+func (cfg *Config) startGossip() {
+	cfg.mods.EventLoop().AddTicker(cfg.gossip.interval, func(_ time.Time) interface{} {
+		return gossipTickEvent{}
+	})
+}
+
+func (cfg *Config) addRecentBlock(qc consensus.QuorumCert) {
+	g := cfg.gossip
+	view, hash := qc.View(), qc.BlockHash()
+	if view == 0 {
+		return // don't offer the genesis block
+	}
+	for _, id := range g.recent {
+		if consensus.View(id.GetView()) == view && string(id.GetHash()) == string(hash[:]) {
+			return
+		}
+	}
+	g.recent = append(g.recent, &hotstuffpb.BlockID{View: uint64(view), Hash: hash[:], QC: hotstuffpb.QuorumCertToProto(qc)})
+	if len(g.recent) > g.maxOffers {
+		g.recent = g.recent[len(g.recent)-g.maxOffers:]
+	}
+}
+
+// offerBlocks offers recent blocks to randomly selected replicas.
+func (cfg *Config) offerBlocks() {
+	g := cfg.gossip
+	if len(g.recent) == 0 {
+		return
+	}
+
+	peers := make([]*Replica, 0, len(cfg.replicas))
+	for _, r := range cfg.replicas {
+		if replica := r.(*Replica); replica.active() {
+			peers = append(peers, replica)
+		}
+	}
+	g.rnd.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+	if len(peers) > g.fanout {
+		peers = peers[:g.fanout]
+	}
+
+	recent := make(map[consensus.Hash]struct{}, len(g.recent))
+	for _, id := range g.recent {
+		var h consensus.Hash
+		copy(h[:], id.GetHash())
+		recent[h] = struct{}{}
+	}
+
+	for _, peer := range peers {
+		offered, ok := g.offered[peer.id]
+		if !ok {
+			offered = make(map[consensus.Hash]struct{})
+			g.offered[peer.id] = offered
+		}
+		// forget blocks that are no longer recent, such that the set stays bounded.
+		for h := range offered {
+			if _, ok := recent[h]; !ok {
+				delete(offered, h)
+			}
+		}
+		offer := &hotstuffpb.BlockOffer{Instance: cfg.instance}
+		for _, id := range g.recent {
+			var h consensus.Hash
+			copy(h[:], id.GetHash())
+			if _, ok := offered[h]; ok {
+				continue
+			}
+			offered[h] = struct{}{}
+			offer.Blocks = append(offer.Blocks, id)
+		}
+		if len(offer.Blocks) > 0 {
+			cfg.countSent(FetchClass, offer, peer.id)
+			if peer.queue != nil {
+				peer := peer
+				peer.queue.enqueue(queuedMsg{send: func() {
+					if peer.active() {
+						peer.node.Offer(context.Background(), offer)
+					}
+				}})
+			} else {
+				peer.node.Offer(context.Background(), offer, gorums.WithNoSendWaiting())
+			}
+		}
+	}
+}
+
+// handleOffer fetches the offered blocks that are missing from the replica that offered them.
+// Blocks are only fetched if the offer includes a valid quorum certificate for them.
+func (cfg *Config) handleOffer(id hotstuff.ID, blocks []*hotstuffpb.BlockID) {
+	g := cfg.gossip
+	if g == nil {
+		return
+	}
+	replica, ok := cfg.replicas[id].(*Replica)
+	if !ok || !replica.active() {
+		return
+	}
+	if len(blocks) > g.maxOffers {
+		blocks = blocks[len(blocks)-g.maxOffers:]
+	}
+	for _, b := range blocks {
+		var hash consensus.Hash
+		copy(hash[:], b.GetHash())
+		if _, ok := g.pending[hash]; ok {
+			continue
+		}
+		if _, ok := cfg.mods.BlockChain().LocalGet(hash); ok {
+			continue
+		}
+		qc, err := hotstuffpb.QuorumCertFromProto(b.GetQC())
+		if err != nil || b.GetQC() == nil || qc.BlockHash() != hash || !cfg.mods.Crypto().VerifyQuorumCert(qc) {
+			cfg.mods.Logger().Infof("Gossip: replica %d offered block %.8x without a valid quorum certificate", id, hash)
+			continue
+		}
+		single, err := cfg.singleConfig(replica)
+		if err != nil {
+			cfg.mods.Logger().Warnf("Gossip: %v", err)
+			return
+		}
+		g.pending[hash] = struct{}{}
+		go func(hash consensus.Hash, qc consensus.QuorumCert) {
+			block := cfg.fetchOffered(single, id, hash)
+			// the event loop clears the pending fetch even if the fetch failed.
+			ctx, cancel := context.WithTimeout(context.Background(), g.interval)
+			defer cancel()
+			if _, err := cfg.mods.EventLoop().Request(ctx, gossipFetchedRequest{qc: qc, block: block}); err != nil {
+				cfg.mods.Logger().Infof("Gossip: block %.8x from replica %d: %v", hash, id, err)
+			}
+		}(hash, qc)
+	}
+}
+
+// fetchOffered fetches an offered block from the replica that offered it, and returns nil if the fetch fails.
+func (cfg *Config) fetchOffered(single *hotstuffpb.Configuration, id hotstuff.ID, hash consensus.Hash) *consensus.Block {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.gossip.interval)
+	defer cancel()
+	req := &hotstuffpb.BlockHash{Hash: hash[:], Instance: cfg.instance}
+	cfg.countSent(FetchClass, req, id)
+	pb, err := single.Fetch(ctx, req)
+	if err != nil {
+		return nil
+	}
+	// a block that cannot be decoded is treated as a failed fetch.
+	block, _ := hotstuffpb.BlockFromProto(pb)
+	return block
+}
+
+// handleFetched stores a block obtained through gossip, if it is the block that its verified certificate references.
+func (cfg *Config) handleFetched(e gossipFetchedRequest) error {
+	g := cfg.gossip
+	delete(g.pending, e.qc.BlockHash())
+	if e.block == nil {
+		return nil
+	}
+	if e.block.Hash() != e.qc.BlockHash() || e.block.View() != e.qc.View() {
+		return fmt.Errorf("the fetched block %.8x does not match its quorum certificate", e.block.Hash())
+	}
+	cfg.mods.BlockChain().Store(e.block)
+	cfg.addRecentBlock(e.qc)
+	return nil
+}
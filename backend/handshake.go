@@ -0,0 +1,209 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/relab/gorums"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+)
+
+const (
+	// ProtocolVersion is the newest version of the replica protocol supported by this implementation.
+	// It is the version of the wire format used to encode certificates.
+	ProtocolVersion = hotstuffpb.WireVersion
+	// MinProtocolVersion is the oldest version of the replica protocol supported by this implementation.
+	// Two replicas are compatible if the ranges of versions that they support overlap.
+	MinProtocolVersion = 1
+)
+
+// handshakeTimeout is the maximum time to wait for the response to a handshake.
+const handshakeTimeout = 10 * time.Second
+
+var (
+	// ErrGenesisMismatch is returned when two replicas have different genesis blocks.
+	ErrGenesisMismatch = errors.New("genesis block mismatch")
+	// ErrVersionMismatch is returned when two replicas do not support a common protocol version.
+	ErrVersionMismatch = errors.New("incompatible protocol version")
+	// ErrCryptoMismatch is returned when two replicas use different crypto implementations.
+	ErrCryptoMismatch = errors.New("crypto suite mismatch")
+	// ErrRulesMismatch is returned when the consensus rules of two replicas require chains of different lengths.
+	ErrRulesMismatch = errors.New("consensus rules mismatch")
+)
+
+// PeerIncompatible is emitted when the handshake with a replica shows that the replica is incompatible.
+// No further messages are exchanged with the replica.
+type PeerIncompatible struct {
+	ID  hotstuff.ID
+	Err error
+}
+
+type handshakeResultEvent struct {
+	id     hotstuff.ID
+	remote *hotstuffpb.HandshakeMsg
+	err    error
+}
+
+// localHandshake returns the handshake message that describes this replica.
+func localHandshake(mods *consensus.Modules) *hotstuffpb.HandshakeMsg {
+	genesis := consensus.GetGenesis().Hash()
+	msg := &hotstuffpb.HandshakeMsg{
+		GenesisHash: genesis[:],
+		Version:     ProtocolVersion,
+		MinVersion:  MinProtocolVersion,
+		CryptoSuite: cryptoSuite(mods.PrivateKey().Public()),
+	}
+	if cs := mods.Consensus(); cs != nil {
+		msg.ChainLength = uint32(cs.ChainLength())
+	}
+	return msg
+}
+
+// cryptoSuite identifies the crypto implementation by the package of its public key type.
+func cryptoSuite(key consensus.PublicKey) string {
+	t := reflect.TypeOf(key)
+	if t == nil {
+		return ""
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return path.Base(t.PkgPath())
+}
+
+// checkCompatible returns an error if the replicas described by the handshake messages are incompatible.
+func checkCompatible(local, remote *hotstuffpb.HandshakeMsg) error {
+	if !bytes.Equal(local.GetGenesisHash(), remote.GetGenesisHash()) {
+		return fmt.Errorf("%w: local %.8x, remote %.8x", ErrGenesisMismatch, local.GetGenesisHash(), remote.GetGenesisHash())
+	}
+	if remote.GetVersion() < local.GetMinVersion() || local.GetVersion() < remote.GetMinVersion() {
+		return fmt.Errorf("%w: local supports versions %d-%d, remote supports versions %d-%d", ErrVersionMismatch,
+			local.GetMinVersion(), local.GetVersion(), remote.GetMinVersion(), remote.GetVersion())
+	}
+	if local.GetCryptoSuite() != remote.GetCryptoSuite() {
+		return fmt.Errorf("%w: local %q, remote %q", ErrCryptoMismatch, local.GetCryptoSuite(), remote.GetCryptoSuite())
+	}
+	// the chain length is unknown if it is zero, such as for replicas that do not send it.
+	if local.GetChainLength() != 0 && remote.GetChainLength() != 0 && local.GetChainLength() != remote.GetChainLength() {
+		return fmt.Errorf("%w: local commits %d-chains, remote commits %d-chains", ErrRulesMismatch,
+			local.GetChainLength(), remote.GetChainLength())
+	}
+	return nil
+}
+
+func (cfg *Config) initHandshake() {
+	cfg.handshake = localHandshake(cfg.mods)
+	cfg.handshake.Instance = cfg.instance
+	cfg.incompatible = make(map[hotstuff.ID]bool)
+	cfg.mods.EventLoop().RegisterObserver(ReplicaConnected{}, func(event interface{}) {
+		cfg.startHandshake(event.(ReplicaConnected).ID)
+	})
+	cfg.mods.EventLoop().RegisterHandler(handshakeResultEvent{}, func(event interface{}) {
+		cfg.handleHandshake(event.(handshakeResultEvent))
+	})
+	// the result of a handshake is added by the goroutine that sent it, and is not sent again.
+	cfg.mods.EventLoop().SetOverflowPolicy(handshakeResultEvent{}, eventloop.Block)
+}
+
+// startHandshake sends a handshake to the replica in the background.
+// A handshake is sent when the configuration connects, and again whenever a replica reconnects.
+func (cfg *Config) startHandshake(id hotstuff.ID) {
+	replica, ok := cfg.replicas[id].(*Replica)
+	if !ok || !replica.active() {
+		return
+	}
+	go func(node *hotstuffpb.Node, local *hotstuffpb.HandshakeMsg) {
+		ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+		defer cancel()
+		resp, err := rpcCall(ctx, node, "hotstuffpb.Hotstuff.Handshake", local)
+		remote, _ := resp.(*hotstuffpb.HandshakeMsg)
+		cfg.mods.EventLoop().AddEvent(handshakeResultEvent{id: id, remote: remote, err: err})
+	}(replica.node, cfg.handshake)
+}
+
+func (cfg *Config) handleHandshake(result handshakeResultEvent) {
+	if result.err != nil {
+		// the handshake is attempted again when the replica reconnects.
+		cfg.mods.Logger().Debugf("Handshake with replica %d failed: %v", result.id, result.err)
+		return
+	}
+	err := checkCompatible(cfg.handshake, result.remote)
+	if err == nil || cfg.incompatible[result.id] {
+		return
+	}
+	cfg.incompatible[result.id] = true
+	cfg.mods.Logger().Errorf("Replica %d is incompatible: %v", result.id, err)
+	cfg.exclude(result.id)
+	cfg.mods.EventLoop().AddEvent(PeerIncompatible{ID: result.id, Err: err})
+}
+
+// exclude stops sending messages to the replica with the given id, and closes the connection to it.
+// Messages that were queued for the replica before it was excluded are discarded when they are due to be sent.
+func (cfg *Config) exclude(id hotstuff.ID) {
+	replica, ok := cfg.replicas[id].(*Replica)
+	if !ok || !replica.active() {
+		return
+	}
+	atomic.StoreInt32(&replica.excluded, 1)
+	// the connection is shared by the instances, so it is only closed if the root configuration is incompatible.
+	if cfg.root == nil {
+		cfg.dialer.close(id)
+	}
+	if cfg.tree != nil {
+		cfg.tree.children = make(map[hotstuff.ID]*hotstuffpb.Configuration)
+	}
+	var nodeIDs []uint32
+	for id, r := range cfg.replicas {
+		if r.(*Replica).active() {
+			nodeIDs = append(nodeIDs, uint32(id))
+		}
+	}
+	if len(nodeIDs) == 0 {
+		cfg.cfg = nil
+		return
+	}
+	c, err := cfg.mgr.NewConfiguration(qspec{}, gorums.WithNodeIDs(nodeIDs))
+	if err != nil {
+		cfg.mods.Logger().Errorf("Failed to exclude replica %d: %v", id, err)
+		return
+	}
+	cfg.cfg = c
+}
+
+// Handshake checks that the replica that sent the handshake is compatible with this replica.
+// The local handshake is returned in either case, so that the sender can perform the same check.
+func (impl *serviceImpl) Handshake(ctx gorums.ServerCtx, remote *hotstuffpb.HandshakeMsg) (*hotstuffpb.HandshakeMsg, error) {
+	srv := impl.srv
+	id, err := GetPeerIDFromContext(ctx, srv.mods.Configuration())
+	if err != nil {
+		srv.mods.Logger().Infof("Failed to get client ID: %v", err)
+		return srv.handshake, nil
+	}
+	if err := checkCompatible(srv.handshake, remote); err != nil {
+		srv.mut.Lock()
+		logged := srv.incompatible[id]
+		srv.incompatible[id] = true
+		srv.mut.Unlock()
+		if !logged {
+			srv.mods.Logger().Errorf("Replica %d is incompatible: %v", id, err)
+			srv.mods.EventLoop().AddEvent(PeerIncompatible{ID: id, Err: err})
+		}
+	}
+	return srv.handshake, nil
+}
+
+// isIncompatible returns true if the replica has sent an incompatible handshake.
+func (srv *Server) isIncompatible(id hotstuff.ID) bool {
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+	return srv.incompatible[id]
+}
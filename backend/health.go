@@ -0,0 +1,145 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/relab/gorums"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ReplicaUnreachable is emitted when a replica has not responded to a number of consecutive health checks.
+// Unlike ReplicaDisconnected, this also detects replicas whose connection appears to be open, but that do not respond.
+type ReplicaUnreachable struct {
+	ID hotstuff.ID
+}
+
+// ReplicaReachable is emitted when a replica that was unreachable responds to a health check.
+type ReplicaReachable struct {
+	ID hotstuff.ID
+}
+
+// healthChecker pings the other replicas at regular intervals.
+// A replica is marked unreachable after missing threshold consecutive pings.
+type healthChecker struct {
+	interval  time.Duration
+	threshold int
+	nonce     uint64
+
+	missed      map[hotstuff.ID]int
+	unreachable map[hotstuff.ID]bool
+	inflight    map[hotstuff.ID]bool
+}
+
+type healthTickEvent struct{}
+
+type pingResultEvent struct {
+	id hotstuff.ID
+	ok bool
+}
+
+// SetHealthCheck enables health checking. Each replica is pinged every interval,
+// and is considered unreachable if it does not respond to threshold consecutive pings.
+// A ping that has not been answered within the interval counts as missed.
+// Health checking is disabled by default. SetHealthCheck must be called before Connect.
+func (cfg *Config) SetHealthCheck(interval time.Duration, threshold int) {
+	if threshold < 1 {
+		threshold = 1
+	}
+	cfg.health = &healthChecker{
+		interval:    interval,
+		threshold:   threshold,
+		missed:      make(map[hotstuff.ID]int),
+		unreachable: make(map[hotstuff.ID]bool),
+		inflight:    make(map[hotstuff.ID]bool),
+	}
+}
+
+// IsReachable returns true unless the replica has been marked as unreachable by the health checker.
+func (cfg *Config) IsReachable(id hotstuff.ID) bool {
+	return cfg.health == nil || !cfg.health.unreachable[id]
+}
+
+func (cfg *Config) initHealthCheck() {
+	cfg.mods.EventLoop().RegisterHandler(healthTickEvent{}, func(_ interface{}) {
+		cfg.pingReplicas()
+	})
+	cfg.mods.EventLoop().RegisterHandler(pingResultEvent{}, func(event interface{}) {
+		cfg.handlePingResult(event.(pingResultEvent))
+	})
+}
+
+func (cfg *Config) startHealthCheck() {
+	cfg.mods.EventLoop().AddTicker(cfg.health.interval, func(_ time.Time) interface{} {
+		return healthTickEvent{}
+	})
+}
+
+// pingReplicas sends a ping to each replica that does not already have a ping in flight.
+// Each ping times out after one interval, at which point it is counted as missed.
+func (cfg *Config) pingReplicas() {
+	h := cfg.health
+	for id, r := range cfg.replicas {
+		replica := r.(*Replica)
+		if !replica.active() {
+			continue
+		}
+		if h.inflight[id] {
+			continue
+		}
+		h.inflight[id] = true
+		h.nonce++
+		ping := func(id hotstuff.ID, node *hotstuffpb.Node, nonce uint64) {
+			ctx, cancel := context.WithTimeout(context.Background(), h.interval)
+			defer cancel()
+			resp, err := rpcCall(ctx, node, "hotstuffpb.Hotstuff.Ping", &hotstuffpb.PingMsg{Nonce: nonce})
+			ping, _ := resp.(*hotstuffpb.PingMsg)
+			ok := err == nil && ping.GetNonce() == nonce
+			cfg.mods.EventLoop().AddEvent(pingResultEvent{id: id, ok: ok})
+		}
+		if replica.queue == nil {
+			go ping(id, replica.node, h.nonce)
+			continue
+		}
+		// pings are not critical, and are the first to be dropped if the replica's send queue is full.
+		// A dropped ping counts as missed.
+		id, node, nonce := id, replica.node, h.nonce
+		replica.queue.enqueue(queuedMsg{
+			send: func() { go ping(id, node, nonce) },
+			drop: func() { cfg.mods.EventLoop().AddEvent(pingResultEvent{id: id, ok: false}) },
+		})
+	}
+}
+
+// rpcCall calls a method on a single node.
+// Unlike the generated methods, it does not panic if the call fails without returning an error.
+func rpcCall(ctx context.Context, node *hotstuffpb.Node, method string, in protoreflect.ProtoMessage) (protoreflect.ProtoMessage, error) {
+	resp, err := node.RPCCall(ctx, gorums.CallData{Message: in, Method: method})
+	if err == nil && resp == nil {
+		err = fmt.Errorf("%s: no response from replica %d", method, node.ID())
+	}
+	return resp, err
+}
+
+func (cfg *Config) handlePingResult(result pingResultEvent) {
+	h := cfg.health
+	h.inflight[result.id] = false
+	if result.ok {
+		h.missed[result.id] = 0
+		if h.unreachable[result.id] {
+			h.unreachable[result.id] = false
+			cfg.mods.Logger().Infof("Replica %d is reachable", result.id)
+			cfg.mods.EventLoop().AddEvent(ReplicaReachable{ID: result.id})
+		}
+		return
+	}
+	h.missed[result.id]++
+	if h.missed[result.id] == h.threshold {
+		h.unreachable[result.id] = true
+		cfg.mods.Logger().Infof("Replica %d is unreachable", result.id)
+		cfg.mods.EventLoop().AddEvent(ReplicaUnreachable{ID: result.id})
+	}
+}
@@ -0,0 +1,191 @@
+package backend
+
+import (
+	"sync"
+
+	"github.com/relab/gorums"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewInstance returns a configuration for another consensus instance that shares the connections of cfg.
+// The messages sent by the new configuration are addressed to the given instance, which must be unique among the
+// configurations that share the connections, and are delivered to the Server of the same instance at the receiver
+// (see Server.NewInstance). The configuration of cfg itself belongs to instance 0.
+//
+// The new configuration must be registered in the modules of its instance, which must be built after those of cfg,
+// and its Connect method must be called after that of cfg, with the same replicas.
+// The transport, resolver, and credentials of cfg are used, while the other settings apply to each instance.
+func (cfg *Config) NewInstance(instance uint32) *Config {
+	return &Config{
+		replicas:      make(map[hotstuff.ID]consensus.Replica),
+		proposeCancel: func() {},
+		timeoutCancel: func() {},
+		seed:          cfg.seed,
+		supervisor:    cfg.supervisor,
+		dialer:        cfg.dialer,
+		instance:      instance,
+		root:          cfg,
+	}
+}
+
+// Instance returns the consensus instance that the configuration belongs to.
+func (cfg *Config) Instance() uint32 {
+	return cfg.instance
+}
+
+// instanceRegistry holds the servers of the consensus instances that share a gRPC server.
+type instanceRegistry struct {
+	mut     sync.RWMutex
+	servers map[uint32]*serviceImpl
+}
+
+func newInstanceRegistry() *instanceRegistry {
+	return &instanceRegistry{servers: make(map[uint32]*serviceImpl)}
+}
+
+func (r *instanceRegistry) add(srv *Server) {
+	r.mut.Lock()
+	r.servers[srv.instance] = &serviceImpl{srv}
+	r.mut.Unlock()
+}
+
+func (r *instanceRegistry) remove(srv *Server) {
+	r.mut.Lock()
+	if impl, ok := r.servers[srv.instance]; ok && impl.srv == srv {
+		delete(r.servers, srv.instance)
+	}
+	r.mut.Unlock()
+}
+
+func (r *instanceRegistry) get(instance uint32) (impl *serviceImpl, ok bool) {
+	r.mut.RLock()
+	impl, ok = r.servers[instance]
+	r.mut.RUnlock()
+	return impl, ok
+}
+
+// NewInstance returns a server for another consensus instance that shares the gRPC server of srv.
+// The messages that are addressed to the instance (see Config.NewInstance) are delivered to the modules of the new
+// server, while messages addressed to an instance that does not exist on this replica are dropped.
+// The server of srv itself handles instance 0. Its gRPC options, rate limits, and sender authentication apply to all
+// instances, and must be set before NewInstance is called.
+//
+// The new server must be registered in the modules of its instance, and starts to receive messages once the modules
+// are built. The server of an instance is not started, and stopping it only stops the delivery of its messages.
+func (srv *Server) NewInstance(instance uint32) *Server {
+	inst := &Server{
+		gorumsSrv:    srv.gorumsSrv,
+		limiter:      srv.limiter,
+		skipAuth:     srv.skipAuth,
+		incompatible: make(map[hotstuff.ID]bool),
+		instance:     instance,
+		root:         srv,
+		instances:    srv.instances,
+	}
+	inst.chunks = newReassembler(DefaultReassemblyTimeout, DefaultReassemblyBudget, inst.reassemblyTimeout)
+	return inst
+}
+
+// Instance returns the consensus instance that the server belongs to.
+func (srv *Server) Instance() uint32 {
+	return srv.instance
+}
+
+// instanceMux implements the HotStuff service by delivering each message to the server of its instance.
+// Pings concern the connection rather than an instance, and are answered by the root server.
+type instanceMux struct {
+	root      *Server
+	instances *instanceRegistry
+}
+
+func (mux *instanceMux) Propose(ctx gorums.ServerCtx, request *hotstuffpb.Proposal) {
+	if impl, ok := mux.instances.get(request.GetInstance()); ok {
+		impl.Propose(ctx, request)
+	}
+}
+
+func (mux *instanceMux) Vote(ctx gorums.ServerCtx, request *hotstuffpb.PartialCert) {
+	if impl, ok := mux.instances.get(request.GetInstance()); ok {
+		impl.Vote(ctx, request)
+	}
+}
+
+func (mux *instanceMux) Timeout(ctx gorums.ServerCtx, request *hotstuffpb.TimeoutMsg) {
+	if impl, ok := mux.instances.get(request.GetInstance()); ok {
+		impl.Timeout(ctx, request)
+	}
+}
+
+func (mux *instanceMux) NewView(ctx gorums.ServerCtx, request *hotstuffpb.SyncInfo) {
+	if impl, ok := mux.instances.get(request.GetInstance()); ok {
+		impl.NewView(ctx, request)
+	}
+}
+
+func (mux *instanceMux) Fetch(ctx gorums.ServerCtx, request *hotstuffpb.BlockHash) (*hotstuffpb.Block, error) {
+	impl, ok := mux.instances.get(request.GetInstance())
+	if !ok {
+		return nil, unknownInstance(request.GetInstance())
+	}
+	return impl.Fetch(ctx, request)
+}
+
+func (mux *instanceMux) Forward(ctx gorums.ServerCtx, request *hotstuffpb.Proposal) {
+	if impl, ok := mux.instances.get(request.GetInstance()); ok {
+		impl.Forward(ctx, request)
+	}
+}
+
+func (mux *instanceMux) RequestProposal(ctx gorums.ServerCtx, request *hotstuffpb.ProposalRequest) {
+	if impl, ok := mux.instances.get(request.GetInstance()); ok {
+		impl.RequestProposal(ctx, request)
+	}
+}
+
+func (mux *instanceMux) Offer(ctx gorums.ServerCtx, request *hotstuffpb.BlockOffer) {
+	if impl, ok := mux.instances.get(request.GetInstance()); ok {
+		impl.Offer(ctx, request)
+	}
+}
+
+func (mux *instanceMux) Ping(ctx gorums.ServerCtx, request *hotstuffpb.PingMsg) (*hotstuffpb.PingMsg, error) {
+	return (&serviceImpl{mux.root}).Ping(ctx, request)
+}
+
+func (mux *instanceMux) Handshake(ctx gorums.ServerCtx, request *hotstuffpb.HandshakeMsg) (*hotstuffpb.HandshakeMsg, error) {
+	impl, ok := mux.instances.get(request.GetInstance())
+	if !ok {
+		return nil, unknownInstance(request.GetInstance())
+	}
+	return impl.Handshake(ctx, request)
+}
+
+func (mux *instanceMux) ProposeChunk(ctx gorums.ServerCtx, request *hotstuffpb.ProposalChunk) {
+	if impl, ok := mux.instances.get(request.GetInstance()); ok {
+		impl.ProposeChunk(ctx, request)
+	}
+}
+
+func (mux *instanceMux) ForwardCommand(ctx gorums.ServerCtx, request *hotstuffpb.ClientCommand) {
+	if impl, ok := mux.instances.get(request.GetInstance()); ok {
+		impl.ForwardCommand(ctx, request)
+	}
+}
+
+func (mux *instanceMux) FetchSnapshot(ctx gorums.ServerCtx, request *hotstuffpb.SnapshotRequest) (*hotstuffpb.SnapshotChunk, error) {
+	impl, ok := mux.instances.get(request.GetInstance())
+	if !ok {
+		return nil, unknownInstance(request.GetInstance())
+	}
+	return impl.FetchSnapshot(ctx, request)
+}
+
+func unknownInstance(instance uint32) error {
+	return status.Errorf(codes.NotFound, "consensus instance %d does not exist", instance)
+}
+
+var _ hotstuffpb.Hotstuff = (*instanceMux)(nil)
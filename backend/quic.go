@@ -0,0 +1,299 @@
+package backend
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// quicProtocol is the application protocol that is negotiated for the QUIC connections between replicas.
+const quicProtocol = "hotstuff"
+
+// quicKeepAlive is how often idle QUIC connections are kept alive, which must be shorter than the idle timeout.
+const quicKeepAlive = 10 * time.Second
+
+func init() {
+	RegisterTransport("quic", NewQUICTransport(QUICOptions{}))
+}
+
+// QUICOptions configures a QUIC transport.
+type QUICOptions struct {
+	// ServerTLS is the TLS configuration that the listeners present to the replicas that connect to them.
+	// If it is nil, the listeners present a self-signed certificate.
+	ServerTLS *tls.Config
+	// ClientTLS is the TLS configuration that is used to verify the replicas that are dialed.
+	// If it is nil, the certificates of the replicas are not verified.
+	ClientTLS *tls.Config
+	// PacketLoss is the fraction of the outgoing packets that are dropped, such as 0.01,
+	// which emulates a lossy network. No packets are dropped if it is zero.
+	PacketLoss float64
+}
+
+// NewQUICTransport returns a transport that carries each connection between two replicas over a bidirectional stream
+// of a QUIC connection of its own, which recovers lost packets without the head-of-line blocking of TCP at
+// the packet level. QUIC always encrypts its connections. The gRPC credentials of the replicas apply on top
+// of the transport, such that the replicas authenticate each other as they do over TCP, while the TLS configuration
+// of the transport authenticates the QUIC connections, if it is set (see TLSTransport).
+// The QUIC transport does not support unix domain sockets.
+func NewQUICTransport(opts QUICOptions) Transport {
+	return &quicTransport{opts: opts}
+}
+
+// TLSTransport is implemented by the transports that secure their connections themselves, such as QUIC.
+type TLSTransport interface {
+	Transport
+	// WithTLS returns a copy of the transport that presents the certificates of server to the replicas that connect
+	// to it, and verifies the replicas that it dials with client.
+	WithTLS(server, client *tls.Config) Transport
+}
+
+type quicTransport struct {
+	opts QUICOptions
+}
+
+// WithTLS returns a copy of the transport that uses the given TLS configurations.
+func (t *quicTransport) WithTLS(server, client *tls.Config) Transport {
+	opts := t.opts
+	opts.ServerTLS, opts.ClientTLS = server, client
+	return &quicTransport{opts: opts}
+}
+
+func (t *quicTransport) config() *quic.Config {
+	return &quic.Config{KeepAlivePeriod: quicKeepAlive}
+}
+
+// packetConn returns the packet connection to use for QUIC, which drops packets if packet loss is emulated.
+func (t *quicTransport) packetConn(addr string) (net.PacketConn, error) {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if t.opts.PacketLoss > 0 {
+		return &lossyPacketConn{PacketConn: conn, udp: conn.(*net.UDPConn), loss: t.opts.PacketLoss, rnd: mrand.New(mrand.NewSource(time.Now().UnixNano()))}, nil
+	}
+	return conn, nil
+}
+
+// Listen creates a QUIC listener on the given UDP address.
+func (t *quicTransport) Listen(addr string) (net.Listener, error) {
+	if IsUnixAddress(addr) {
+		return nil, fmt.Errorf("the QUIC transport does not support unix domain sockets: %s", addr)
+	}
+	tlsConf := t.opts.ServerTLS
+	if tlsConf == nil {
+		cert, err := selfSignedCertificate()
+		if err != nil {
+			return nil, err
+		}
+		tlsConf = &tls.Config{Certificates: []tls.Certificate{cert}}
+	} else {
+		tlsConf = tlsConf.Clone()
+	}
+	tlsConf.NextProtos = []string{quicProtocol}
+	conn, err := t.packetConn(addr)
+	if err != nil {
+		return nil, err
+	}
+	lis, err := quic.Listen(conn, tlsConf, t.config())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	ql := &quicListener{
+		lis:   lis,
+		conn:  conn,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+	go ql.acceptLoop()
+	return ql, nil
+}
+
+// Dial connects to the replica at the given UDP address, and opens the stream that carries the connection.
+func (t *quicTransport) Dial(ctx context.Context, addr string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	var tlsConf *tls.Config
+	if t.opts.ClientTLS == nil {
+		tlsConf = &tls.Config{InsecureSkipVerify: true} // #nosec G402 -- the replicas are authenticated by gRPC.
+	} else {
+		tlsConf = t.opts.ClientTLS.Clone()
+		if tlsConf.ServerName == "" {
+			tlsConf.ServerName = host
+		}
+	}
+	tlsConf.NextProtos = []string{quicProtocol}
+	packetConn, err := t.packetConn(":0")
+	if err != nil {
+		return nil, err
+	}
+	conn, err := quic.Dial(ctx, packetConn, udpAddr, tlsConf, t.config())
+	if err != nil {
+		packetConn.Close()
+		return nil, err
+	}
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		conn.CloseWithError(0, "")
+		packetConn.Close()
+		return nil, err
+	}
+	return &quicConn{Stream: stream, conn: conn, packetConn: packetConn}, nil
+}
+
+// quicListener accepts QUIC connections, and returns the first stream of each connection as a net.Conn.
+type quicListener struct {
+	lis   *quic.Listener
+	conn  net.PacketConn
+	conns chan net.Conn
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// acceptLoop accepts the connections until the listener is closed. The first stream of each connection is awaited
+// concurrently, such that a replica that does not open a stream does not block the others.
+func (l *quicListener) acceptLoop() {
+	for {
+		conn, err := l.lis.Accept(context.Background())
+		if err != nil {
+			return
+		}
+		go func() {
+			stream, err := conn.AcceptStream(conn.Context())
+			if err != nil {
+				conn.CloseWithError(0, "")
+				return
+			}
+			select {
+			case l.conns <- &quicConn{Stream: stream, conn: conn}:
+			case <-l.done:
+				conn.CloseWithError(0, "")
+			}
+		}()
+	}
+}
+
+// Accept waits for the next connection.
+func (l *quicListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close stops the listener and closes its connections.
+func (l *quicListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.done)
+		err = l.lis.Close()
+		if cerr := l.conn.Close(); err == nil {
+			err = cerr
+		}
+	})
+	return err
+}
+
+// Addr returns the UDP address that the listener listens on.
+func (l *quicListener) Addr() net.Addr {
+	return l.lis.Addr()
+}
+
+// quicConn is a stream of a QUIC connection that is used as a net.Conn.
+type quicConn struct {
+	quic.Stream
+	conn       quic.Connection
+	packetConn net.PacketConn // the packet connection of a dialed connection, which is closed with it
+}
+
+func (c *quicConn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c *quicConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// Close closes the stream and its QUIC connection.
+func (c *quicConn) Close() error {
+	c.Stream.CancelRead(0)
+	err := c.Stream.Close()
+	if cerr := c.conn.CloseWithError(0, ""); err == nil {
+		err = cerr
+	}
+	if c.packetConn != nil {
+		c.packetConn.Close()
+	}
+	return err
+}
+
+// lossyPacketConn drops a fraction of the packets that are written to it.
+// It only forwards the buffer sizes to the UDP connection, such that quic-go writes every packet with WriteTo.
+type lossyPacketConn struct {
+	net.PacketConn
+	udp  *net.UDPConn
+	loss float64
+
+	mut sync.Mutex
+	rnd *mrand.Rand
+}
+
+func (c *lossyPacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	c.mut.Lock()
+	drop := c.rnd.Float64() < c.loss
+	c.mut.Unlock()
+	if drop {
+		return len(p), nil
+	}
+	return c.PacketConn.WriteTo(p, addr)
+}
+
+func (c *lossyPacketConn) SetReadBuffer(bytes int) error {
+	return c.udp.SetReadBuffer(bytes)
+}
+
+func (c *lossyPacketConn) SetWriteBuffer(bytes int) error {
+	return c.udp.SetWriteBuffer(bytes)
+}
+
+// selfSignedCertificate returns a certificate for the listeners of a QUIC transport without a TLS configuration.
+func selfSignedCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: quicProtocol},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
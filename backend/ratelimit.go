@@ -0,0 +1,212 @@
+package backend
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/relab/hotstuff"
+)
+
+// MessageClass identifies a class of messages that share a rate limit budget.
+type MessageClass int
+
+const (
+	// ProposalClass is the class of proposal messages.
+	ProposalClass MessageClass = iota
+	// VoteClass is the class of vote messages.
+	VoteClass
+	// TimeoutClass is the class of timeout messages.
+	TimeoutClass
+	// NewViewClass is the class of new view messages.
+	NewViewClass
+	// FetchClass is the class of fetch requests.
+	FetchClass
+	// CommandClass is the class of client commands forwarded to the leader.
+	CommandClass
+
+	numMessageClasses
+)
+
+func (c MessageClass) String() string {
+	switch c {
+	case ProposalClass:
+		return "proposal"
+	case VoteClass:
+		return "vote"
+	case TimeoutClass:
+		return "timeout"
+	case NewViewClass:
+		return "newview"
+	case FetchClass:
+		return "fetch"
+	case CommandClass:
+		return "command"
+	}
+	return "unknown"
+}
+
+// ParseMessageClass returns the message class with the given name.
+func ParseMessageClass(name string) (MessageClass, error) {
+	for c := MessageClass(0); c < numMessageClasses; c++ {
+		if c.String() == name {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown message class '%s'", name)
+}
+
+// RateLimit specifies the rate and burst size of a token bucket.
+// A zero rate means that the message class is not rate limited.
+type RateLimit struct {
+	// The number of messages per second that are allowed.
+	Rate float64
+	// The number of messages that can be received in a burst.
+	Burst int
+}
+
+// RateLimits specifies the inbound rate limit of each message class.
+// The limits apply to each peer separately, so that a single peer cannot exhaust the budget of the others.
+type RateLimits map[MessageClass]RateLimit
+
+// ParseRateLimits parses a comma separated list of rate limits of the form 'class=rate:burst',
+// such as 'vote=1000:100,fetch=10:5'.
+func ParseRateLimits(spec string) (RateLimits, error) {
+	limits := make(RateLimits)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		eq := strings.Index(entry, "=")
+		colon := strings.LastIndex(entry, ":")
+		if eq < 0 || colon < eq {
+			return nil, fmt.Errorf("expected 'class=rate:burst', got '%s'", entry)
+		}
+		class, err := ParseMessageClass(entry[:eq])
+		if err != nil {
+			return nil, err
+		}
+		rate, err := strconv.ParseFloat(entry[eq+1:colon], 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("invalid rate of %s messages: '%s'", class, entry[eq+1:colon])
+		}
+		burst, err := strconv.Atoi(entry[colon+1:])
+		if err != nil || burst < 1 {
+			return nil, fmt.Errorf("invalid burst size of %s messages: '%s'", class, entry[colon+1:])
+		}
+		limits[class] = RateLimit{Rate: rate, Burst: burst}
+	}
+	return limits, nil
+}
+
+// DefaultRateLimits returns rate limits that should not affect well-behaved peers.
+// Timeout and new view messages are given a higher budget than the other messages,
+// because dropping them could harm liveness.
+func DefaultRateLimits() RateLimits {
+	return RateLimits{
+		ProposalClass: {Rate: 1000, Burst: 100},
+		VoteClass:     {Rate: 1000, Burst: 100},
+		TimeoutClass:  {Rate: 5000, Burst: 500},
+		NewViewClass:  {Rate: 5000, Burst: 500},
+		FetchClass:    {Rate: 1000, Burst: 100},
+		CommandClass:  {Rate: 10000, Burst: 1000},
+	}
+}
+
+// dropLogInterval is the minimum time between each log message about dropped messages.
+const dropLogInterval = 5 * time.Second
+
+// maxRateLimitDelay is the longest time that a message exceeding its rate limit is delayed before it is processed.
+// Delaying a message blocks the stream of the peer, which slows the peer down. Messages that would have to wait
+// longer are dropped.
+const maxRateLimitDelay = 50 * time.Millisecond
+
+// unknownPeer is the ID whose budget is shared by the peers that are not replicas in the configuration.
+const unknownPeer hotstuff.ID = 0
+
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token from the bucket, and returns how long the caller must wait until the token is available.
+// If the token would not be available within maxDelay, no token is taken and false is returned.
+func (b *tokenBucket) reserve(now time.Time, maxDelay time.Duration) (time.Duration, bool) {
+	b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+	if max := float64(b.limit.Burst); b.tokens > max {
+		b.tokens = max
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	delay := time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+	if delay > maxDelay {
+		return 0, false
+	}
+	b.tokens--
+	return delay, true
+}
+
+// inboundLimiter applies token bucket rate limits to the messages received from each peer.
+// The callers must ensure that the peer IDs are bounded, as the buckets of a peer are never removed.
+type inboundLimiter struct {
+	mut     sync.Mutex
+	limits  RateLimits
+	buckets map[hotstuff.ID]*[numMessageClasses]*tokenBucket
+	dropped [numMessageClasses]uint64
+	lastLog time.Time
+}
+
+func newInboundLimiter(limits RateLimits) *inboundLimiter {
+	return &inboundLimiter{
+		limits:  limits,
+		buckets: make(map[hotstuff.ID]*[numMessageClasses]*tokenBucket),
+		lastLog: time.Now(),
+	}
+}
+
+// allow returns true if a message of the given class from the given peer is within the rate limit,
+// possibly after waiting for the returned delay.
+// If the message should be dropped, the counts of dropped messages are returned when they are due to be logged.
+func (l *inboundLimiter) allow(id hotstuff.ID, class MessageClass) (delay time.Duration, ok bool, logDropped []uint64) {
+	limit, limited := l.limits[class]
+	if !limited || limit.Rate <= 0 {
+		return 0, true, nil
+	}
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	now := time.Now()
+	peerBuckets, ok := l.buckets[id]
+	if !ok {
+		peerBuckets = new([numMessageClasses]*tokenBucket)
+		l.buckets[id] = peerBuckets
+	}
+	bucket := peerBuckets[class]
+	if bucket == nil {
+		bucket = &tokenBucket{limit: limit, tokens: float64(limit.Burst), last: now}
+		peerBuckets[class] = bucket
+	}
+
+	if delay, ok := bucket.reserve(now, maxRateLimitDelay); ok {
+		return delay, true, nil
+	}
+
+	l.dropped[class]++
+	if now.Sub(l.lastLog) >= dropLogInterval {
+		l.lastLog = now
+		logDropped = append([]uint64(nil), l.dropped[:]...)
+	}
+	return 0, false, logDropped
+}
+
+// droppedCount returns the total number of dropped messages of the given class.
+func (l *inboundLimiter) droppedCount(class MessageClass) uint64 {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return l.dropped[class]
+}
@@ -0,0 +1,216 @@
+package backend
+
+import (
+	"sync"
+
+	"github.com/relab/hotstuff"
+)
+
+// SendQueueStats contains the counters of the send queue of a single replica.
+type SendQueueStats struct {
+	Length          int    // The number of messages currently waiting to be sent.
+	Dropped         uint64 // The number of messages dropped because the queue was full.
+	DroppedCritical uint64 // The number of dropped messages that were critical.
+}
+
+type queuedMsg struct {
+	critical bool
+	priority bool // priority messages are sent ahead of other messages.
+	send     func()
+	drop     func() // called if the message is dropped because the queue is full; may be nil.
+}
+
+func (m queuedMsg) dropped() {
+	if m.drop != nil {
+		m.drop()
+	}
+}
+
+const (
+	normalLane = iota
+	priorityLane
+	numLanes
+)
+
+// priorityWeight is the number of consecutive priority messages that are sent before a waiting normal message is sent.
+const priorityWeight = 4
+
+// sendQueue is a bounded queue of outgoing messages to a single replica,
+// which are sent in order by a dedicated goroutine. This prevents a slow replica
+// from delaying messages to the other replicas.
+//
+// Priority messages, such as timeouts and new view messages, have their own lane, and are sent ahead of
+// the other messages. To avoid starving the normal lane, a normal message is sent after every
+// priorityWeight consecutive priority messages.
+//
+// When the queue is full, the oldest non-critical message is dropped.
+// Critical messages are only dropped if the queue contains no non-critical messages,
+// in which case the oldest critical message is dropped, starting with the normal lane.
+type sendQueue struct {
+	size int
+
+	mut    sync.Mutex
+	lanes  [numLanes][]queuedMsg
+	streak int
+	stats  SendQueueStats
+
+	ready chan struct{}
+	done  chan struct{}
+}
+
+func newSendQueue(size int) *sendQueue {
+	if size < 1 {
+		size = 1
+	}
+	q := &sendQueue{
+		size:  size,
+		ready: make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *sendQueue) lenLocked() int {
+	return len(q.lanes[normalLane]) + len(q.lanes[priorityLane])
+}
+
+func (q *sendQueue) removeLocked(lane, i int) queuedMsg {
+	msg := q.lanes[lane][i]
+	q.lanes[lane] = append(q.lanes[lane][:i], q.lanes[lane][i+1:]...)
+	return msg
+}
+
+// victimLocked returns the position of the message to drop when the queue is full,
+// or ok=false if the new message should be dropped instead.
+func (q *sendQueue) victimLocked(msg queuedMsg) (lane, i int, ok bool) {
+	for lane := range q.lanes {
+		for i, m := range q.lanes[lane] {
+			if !m.critical {
+				return lane, i, true
+			}
+		}
+	}
+	if !msg.critical {
+		// the new message is the oldest non-critical message.
+		return 0, 0, false
+	}
+	if len(q.lanes[normalLane]) > 0 {
+		return normalLane, 0, true
+	}
+	return priorityLane, 0, true
+}
+
+// enqueue adds a message to the queue and returns immediately.
+func (q *sendQueue) enqueue(msg queuedMsg) {
+	q.mut.Lock()
+	if q.lenLocked() >= q.size {
+		lane, i, ok := q.victimLocked(msg)
+		if !ok {
+			q.countDropLocked(msg)
+			q.mut.Unlock()
+			msg.dropped()
+			return
+		}
+		dropped := q.removeLocked(lane, i)
+		q.countDropLocked(dropped)
+		defer dropped.dropped()
+	}
+	lane := normalLane
+	if msg.priority {
+		lane = priorityLane
+	}
+	q.lanes[lane] = append(q.lanes[lane], msg)
+	q.mut.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *sendQueue) countDropLocked(msg queuedMsg) {
+	q.stats.Dropped++
+	if msg.critical {
+		q.stats.DroppedCritical++
+	}
+}
+
+// next removes and returns the next message to send.
+func (q *sendQueue) next() (queuedMsg, bool) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	if q.streak < priorityWeight && len(q.lanes[priorityLane]) > 0 {
+		q.streak++
+		return q.removeLocked(priorityLane, 0), true
+	}
+	q.streak = 0
+	for _, lane := range []int{normalLane, priorityLane} {
+		if len(q.lanes[lane]) > 0 {
+			return q.removeLocked(lane, 0), true
+		}
+	}
+	return queuedMsg{}, false
+}
+
+func (q *sendQueue) run() {
+	for {
+		select {
+		case <-q.ready:
+		case <-q.done:
+			return
+		}
+		for {
+			msg, ok := q.next()
+			if !ok {
+				break
+			}
+			select {
+			case <-q.done:
+				return
+			default:
+			}
+			msg.send()
+		}
+	}
+}
+
+func (q *sendQueue) snapshot() SendQueueStats {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	stats := q.stats
+	stats.Length = q.lenLocked()
+	return stats
+}
+
+// close stops the queue. Messages that have not been sent yet are dropped.
+func (q *sendQueue) close() {
+	close(q.done)
+}
+
+// SetSendQueues enables per-replica send queues. Messages are added to the queue of each recipient,
+// and are sent by a dedicated goroutine for each replica, such that a slow replica does not delay
+// the messages to the other replicas. Each queue holds at most size messages.
+// Proposals, votes, timeouts and new view messages are critical and are only dropped if a queue is full of critical messages;
+// health checks and gossip are dropped first. Timeouts and new view messages are sent ahead of other messages,
+// and large proposals should be chunked (see SetChunkSize) to let them overtake proposals that are being sent.
+// Send queues are not used for tree dissemination or with latency emulation.
+// Send queues are disabled by default. SetSendQueues must be called before Connect.
+func (cfg *Config) SetSendQueues(size int) {
+	cfg.sendQueueSize = size
+}
+
+// SendQueueStats returns the counters of the send queue of each replica.
+// It returns nil if send queues are disabled.
+func (cfg *Config) SendQueueStats() map[hotstuff.ID]SendQueueStats {
+	if cfg.sendQueueSize == 0 {
+		return nil
+	}
+	stats := make(map[hotstuff.ID]SendQueueStats)
+	for id, r := range cfg.replicas {
+		if q := r.(*Replica).queue; q != nil {
+			stats[id] = q.snapshot()
+		}
+	}
+	return stats
+}
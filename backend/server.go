@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/relab/gorums"
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/eventloop"
 	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -23,31 +26,123 @@ import (
 type Server struct {
 	mods      *consensus.Modules
 	gorumsSrv *gorums.Server
+	limiter   *inboundLimiter
+	stats     statsCollector
+	handshake *hotstuffpb.HandshakeMsg
+	chunks    *reassembler
+	dedup     *dedupCache
+
+	cmdHandler CommandHandler
+	snapshots  SnapshotProvider
+
+	skipAuth       bool
+	impersonations uint64
+
+	mut          sync.Mutex
+	incompatible map[hotstuff.ID]bool
+
+	instance  uint32            // the consensus instance that the server delivers messages to.
+	root      *Server           // the server whose gRPC server is shared, or nil if this is the root.
+	instances *instanceRegistry // the instances that share the gRPC server.
 }
 
 // InitConsensusModule gives the module a reference to the Modules object.
 // It also allows the module to set module options using the OptionsBuilder.
 func (srv *Server) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
 	srv.mods = mods
+	srv.mods.Require(srv, consensus.BlockChainModule, consensus.ConfigurationModule)
+	srv.handshake = localHandshake(mods)
+	// votes, new view messages, and timeouts are sent again or superseded by newer messages,
+	// so the oldest of them are dropped first when the event queue is full.
+	for _, eventType := range []interface{}{consensus.VoteMsg{}, consensus.NewViewMsg{}, consensus.TimeoutMsg{}} {
+		mods.EventLoop().SetOverflowPolicy(eventType, eventloop.DropOldest)
+	}
+	srv.handshake.Instance = srv.instance
+	if srv.root != nil {
+		srv.instances.add(srv)
+	}
+}
+
+// DefaultServerOptions returns the gRPC server options that NewServer uses by default.
+// Currently, the gRPC defaults are used.
+func DefaultServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{}
 }
 
 // NewServer creates a new Server.
+// The given server options are applied after the defaults (see DefaultServerOptions), and may override them;
+// gRPC server options can be given with gorums.WithGRPCServerOptions.
 func NewServer(opts ...gorums.ServerOption) *Server {
-	srv := &Server{}
+	srv := &Server{incompatible: make(map[hotstuff.ID]bool), instances: newInstanceRegistry()}
+	srv.chunks = newReassembler(DefaultReassemblyTimeout, DefaultReassemblyBudget, srv.reassemblyTimeout)
 
-	grpcServerOpts := []grpc.ServerOption{}
-
-	opts = append(opts, gorums.WithGRPCServerOptions(grpcServerOpts...))
+	opts = append([]gorums.ServerOption{gorums.WithGRPCServerOptions(DefaultServerOptions()...)}, opts...)
 
 	srv.gorumsSrv = gorums.NewServer(opts...)
 
-	hotstuffpb.RegisterHotstuffServer(srv.gorumsSrv, &serviceImpl{srv})
+	srv.instances.add(srv)
+	hotstuffpb.RegisterHotstuffServer(srv.gorumsSrv, &instanceMux{root: srv, instances: srv.instances})
 	return srv
 }
 
+// SetRateLimits enables per-peer rate limiting of inbound messages.
+// Messages that exceed the rate limit of their class are delayed for a short while, which slows down the stream of
+// the peer, and are dropped if the peer keeps exceeding the rate limit.
+// Each replica in the configuration has its own budget, while all other peers share a single budget.
+// Peers are only authenticated when using TLS; otherwise, a peer can use up the budget of the replica that it claims to be.
+// SetRateLimits must be called before the server is started.
+func (srv *Server) SetRateLimits(limits RateLimits) {
+	srv.limiter = newInboundLimiter(limits)
+}
+
+// NetworkStats returns the counters of the messages received by the server since it was started.
+func (srv *Server) NetworkStats() NetworkStats {
+	return srv.stats.snapshot()
+}
+
+// Dropped returns the number of messages of the given class that were dropped due to rate limiting.
+func (srv *Server) Dropped(class MessageClass) uint64 {
+	if srv.limiter == nil {
+		return 0
+	}
+	return srv.limiter.droppedCount(class)
+}
+
+// allow returns true if the message should be processed according to the rate limits.
+// Messages from replicas that sent an incompatible handshake are never processed.
+func (srv *Server) allow(id hotstuff.ID, class MessageClass) bool {
+	if srv.isIncompatible(id) {
+		return false
+	}
+	if srv.limiter == nil {
+		return true
+	}
+	// peers that are not replicas share a budget, such that the number of buckets is bounded.
+	if _, ok := srv.mods.Configuration().Replicas()[id]; !ok {
+		id = unknownPeer
+	}
+	delay, ok, dropped := srv.limiter.allow(id, class)
+	if dropped != nil {
+		for c, count := range dropped {
+			if count > 0 {
+				srv.mods.Logger().Warnf("Rate limiting: dropped %d %s messages in total", count, MessageClass(c))
+			}
+		}
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return ok
+}
+
 // Start creates a listener on the configured address and starts the server.
 func (srv *Server) Start(addr string) error {
-	lis, err := net.Listen("tcp", addr)
+	return srv.StartWithTransport(tcpTransport{}, addr)
+}
+
+// StartWithTransport creates a listener on the configured address using the given transport and starts the server.
+func (srv *Server) StartWithTransport(transport Transport, addr string) error {
+	lis, err := transport.Listen(addr)
 	if err != nil {
 		return fmt.Errorf("failed to listen on %s: %w", addr, err)
 	}
@@ -107,11 +202,25 @@ func GetPeerIDFromContext(ctx context.Context, cfg consensus.Configuration) (hot
 	return hotstuff.ID(id), nil
 }
 
-// Stop stops the server.
+// Stop stops the server. The server of an instance created by NewInstance only stops delivering messages.
 func (srv *Server) Stop() {
+	if srv.root != nil {
+		srv.instances.remove(srv)
+		return
+	}
 	srv.gorumsSrv.Stop()
 }
 
+// GracefulStop stops the server from accepting new connections and RPCs, and waits for the RPCs in progress to finish.
+// The server of an instance created by NewInstance only stops delivering messages.
+func (srv *Server) GracefulStop() {
+	if srv.root != nil {
+		srv.instances.remove(srv)
+		return
+	}
+	srv.gorumsSrv.GracefulStop()
+}
+
 // serviceImpl provides the implementation of the HotStuff gorums service.
 type serviceImpl struct {
 	srv *Server
@@ -119,49 +228,171 @@ type serviceImpl struct {
 
 // Propose handles a replica's response to the Propose QC from the leader.
 func (impl *serviceImpl) Propose(ctx gorums.ServerCtx, proposal *hotstuffpb.Proposal) {
+	impl.srv.stats.received(ProposalClass, proposal)
+
 	id, err := GetPeerIDFromContext(ctx, impl.srv.mods.Configuration())
 	if err != nil {
 		impl.srv.mods.Logger().Infof("Failed to get client ID: %v", err)
 		return
 	}
+	if !impl.srv.allow(id, ProposalClass) {
+		return
+	}
+	impl.srv.handleProposal(id, proposal)
+}
 
+// handleProposal delivers a proposal received from the leader to the event loop.
+func (srv *Server) handleProposal(id hotstuff.ID, proposal *hotstuffpb.Proposal) {
+	if proposal.Block == nil {
+		proposal.Block = &hotstuffpb.Block{}
+	}
 	proposal.Block.Proposer = uint32(id)
-	proposeMsg := hotstuffpb.ProposalFromProto(proposal)
+	if srv.isDuplicate(digestKey(ProposalClass, proposal.Block)) {
+		return
+	}
+	proposeMsg, err := hotstuffpb.ProposalFromProto(proposal)
+	if err != nil {
+		srv.mods.Logger().Infof("Failed to decode proposal from replica %d: %v", id, err)
+		return
+	}
 	proposeMsg.ID = id
 
-	impl.srv.mods.EventLoop().AddEvent(proposeMsg)
+	srv.mods.EventLoop().AddEvent(proposeMsg)
+	srv.mods.EventLoop().AddEvent(receivedProposal{proposal})
+}
+
+// Forward handles a proposal that was forwarded by a replica other than the leader.
+func (impl *serviceImpl) Forward(ctx gorums.ServerCtx, proposal *hotstuffpb.Proposal) {
+	impl.srv.stats.received(ProposalClass, proposal)
+
+	id, err := GetPeerIDFromContext(ctx, impl.srv.mods.Configuration())
+	if err != nil {
+		impl.srv.mods.Logger().Infof("Failed to get client ID: %v", err)
+		return
+	}
+	if !impl.srv.allow(id, ProposalClass) {
+		return
+	}
+	if impl.srv.isDuplicate(digestKey(ProposalClass, proposal.GetBlock())) {
+		return
+	}
+
+	proposeMsg, err := hotstuffpb.ProposalFromProto(proposal)
+	if err != nil {
+		impl.srv.mods.Logger().Infof("Failed to decode proposal forwarded by replica %d: %v", id, err)
+		return
+	}
+
+	// the sender and the proposer are checked against the tree on the event loop.
+	impl.srv.mods.EventLoop().AddEvent(forwardedProposal{sender: id, proposal: proposal, msg: proposeMsg})
+}
+
+// RequestProposal handles a request from a replica that did not receive the proposal through the tree.
+func (impl *serviceImpl) RequestProposal(ctx gorums.ServerCtx, req *hotstuffpb.ProposalRequest) {
+	impl.srv.stats.received(FetchClass, req)
+
+	id, err := GetPeerIDFromContext(ctx, impl.srv.mods.Configuration())
+	if err != nil {
+		impl.srv.mods.Logger().Infof("Failed to get client ID: %v", err)
+		return
+	}
+	if !impl.srv.allow(id, FetchClass) {
+		return
+	}
+	impl.srv.mods.EventLoop().AddEvent(proposalRequestEvent{id: id, view: consensus.View(req.GetView())})
+}
+
+// Offer handles an offer of recent blocks from the gossip layer of another replica.
+func (impl *serviceImpl) Offer(ctx gorums.ServerCtx, offer *hotstuffpb.BlockOffer) {
+	impl.srv.stats.received(FetchClass, offer)
+
+	id, err := GetPeerIDFromContext(ctx, impl.srv.mods.Configuration())
+	if err != nil {
+		impl.srv.mods.Logger().Infof("Failed to get client ID: %v", err)
+		return
+	}
+	if !impl.srv.allow(id, FetchClass) {
+		return
+	}
+	impl.srv.mods.EventLoop().AddEvent(gossipOfferEvent{id: id, blocks: offer.GetBlocks()})
+}
+
+// Ping responds to a health check from another replica.
+func (impl *serviceImpl) Ping(_ gorums.ServerCtx, ping *hotstuffpb.PingMsg) (*hotstuffpb.PingMsg, error) {
+	return &hotstuffpb.PingMsg{Nonce: ping.GetNonce()}, nil
 }
 
 // Vote handles an incoming vote message.
 func (impl *serviceImpl) Vote(ctx gorums.ServerCtx, cert *hotstuffpb.PartialCert) {
+	impl.srv.stats.received(VoteClass, cert)
+
 	id, err := GetPeerIDFromContext(ctx, impl.srv.mods.Configuration())
 	if err != nil {
 		impl.srv.mods.Logger().Infof("Failed to get client ID: %v", err)
 		return
 	}
+	if !impl.srv.allow(id, VoteClass) {
+		return
+	}
+	if impl.srv.isDuplicate(voteKey(id, cert)) {
+		return
+	}
+
+	pc, err := hotstuffpb.PartialCertFromProto(cert)
+	if err != nil {
+		impl.srv.mods.Logger().Infof("Failed to decode vote from replica %d: %v", id, err)
+		return
+	}
+	if !impl.srv.authenticate(id, VoteClass, pc.Signature()) {
+		return
+	}
 
 	impl.srv.mods.EventLoop().AddEvent(consensus.VoteMsg{
 		ID:          id,
-		PartialCert: hotstuffpb.PartialCertFromProto(cert),
+		PartialCert: pc,
 	})
 }
 
 // NewView handles the leader's response to receiving a NewView rpc from a replica.
 func (impl *serviceImpl) NewView(ctx gorums.ServerCtx, msg *hotstuffpb.SyncInfo) {
+	impl.srv.stats.received(NewViewClass, msg)
+
 	id, err := GetPeerIDFromContext(ctx, impl.srv.mods.Configuration())
 	if err != nil {
 		impl.srv.mods.Logger().Infof("Failed to get client ID: %v", err)
 		return
 	}
+	if !impl.srv.allow(id, NewViewClass) {
+		return
+	}
+	if impl.srv.isDuplicate(digestKey(NewViewClass, msg)) {
+		return
+	}
+
+	syncInfo, err := hotstuffpb.SyncInfoFromProto(msg)
+	if err != nil {
+		impl.srv.mods.Logger().Infof("Failed to decode new view from replica %d: %v", id, err)
+		return
+	}
 
+	// new view and timeout messages are processed ahead of other messages, as the synchronizer registers their
+	// handlers with high priority.
 	impl.srv.mods.EventLoop().AddEvent(consensus.NewViewMsg{
 		ID:       id,
-		SyncInfo: hotstuffpb.SyncInfoFromProto(msg),
+		SyncInfo: syncInfo,
 	})
 }
 
 // Fetch handles an incoming fetch request.
 func (impl *serviceImpl) Fetch(ctx gorums.ServerCtx, pb *hotstuffpb.BlockHash) (*hotstuffpb.Block, error) {
+	impl.srv.stats.received(FetchClass, pb)
+
+	// fetch requests from unknown peers share a single budget.
+	id, _ := GetPeerIDFromContext(ctx, impl.srv.mods.Configuration())
+	if !impl.srv.allow(id, FetchClass) {
+		return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
 	var hash consensus.Hash
 	copy(hash[:], pb.GetHash())
 
@@ -177,11 +408,26 @@ func (impl *serviceImpl) Fetch(ctx gorums.ServerCtx, pb *hotstuffpb.BlockHash) (
 
 // Timeout handles an incoming TimeoutMsg.
 func (impl *serviceImpl) Timeout(ctx gorums.ServerCtx, msg *hotstuffpb.TimeoutMsg) {
-	var err error
-	timeoutMsg := hotstuffpb.TimeoutMsgFromProto(msg)
-	timeoutMsg.ID, err = GetPeerIDFromContext(ctx, impl.srv.mods.Configuration())
+	impl.srv.stats.received(TimeoutClass, msg)
+
+	id, idErr := GetPeerIDFromContext(ctx, impl.srv.mods.Configuration())
+	if idErr != nil {
+		impl.srv.mods.Logger().Infof("Could not get ID of replica: %v", idErr)
+	}
+	if !impl.srv.allow(id, TimeoutClass) {
+		return
+	}
+	if idErr == nil && impl.srv.isDuplicate(timeoutKey(id, msg)) {
+		return
+	}
+	timeoutMsg, err := hotstuffpb.TimeoutMsgFromProto(msg)
 	if err != nil {
-		impl.srv.mods.Logger().Infof("Could not get ID of replica: %v", err)
+		impl.srv.mods.Logger().Infof("Failed to decode timeout: %v", err)
+		return
+	}
+	timeoutMsg.ID = id
+	if idErr == nil && !impl.srv.authenticate(timeoutMsg.ID, TimeoutClass, timeoutMsg.ViewSignature, timeoutMsg.MsgSignature) {
+		return
 	}
 	impl.srv.mods.EventLoop().AddEvent(timeoutMsg)
 }
@@ -0,0 +1,174 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/relab/gorums"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxSnapshotChunkSize is the maximum number of bytes of a snapshot that are sent in a single message.
+const MaxSnapshotChunkSize = 1 << 20
+
+const fetchSnapshotMethod = "hotstuffpb.Hotstuff.FetchSnapshot"
+
+// SnapshotInfo describes a snapshot of the state of a replica.
+type SnapshotInfo struct {
+	// The block after which the snapshot was taken.
+	Block *consensus.Block
+	// A quorum certificate for the block.
+	QC consensus.QuorumCert
+	// The SHA-256 hash of the snapshot.
+	Digest consensus.Hash
+	// The size of the snapshot in bytes.
+	Size int
+}
+
+// SnapshotProvider provides the snapshots that are served to other replicas for state transfer.
+// The methods are called by the server's goroutines, and must be safe for concurrent use.
+type SnapshotProvider interface {
+	// LatestSnapshot returns a description of the most recent snapshot, or false if there is none.
+	LatestSnapshot() (info SnapshotInfo, ok bool)
+	// Snapshot returns the snapshot of the state after the given block, or false if it is not available.
+	Snapshot(block consensus.Hash) (data []byte, ok bool)
+}
+
+// SetSnapshotProvider sets the provider of the snapshots that are served to other replicas.
+// Snapshot requests are refused if no provider is set. SetSnapshotProvider must be called before the server is started.
+func (srv *Server) SetSnapshotProvider(provider SnapshotProvider) {
+	srv.snapshots = provider
+}
+
+// FetchSnapshot handles a request for a snapshot from a replica that performs a state transfer.
+func (impl *serviceImpl) FetchSnapshot(ctx gorums.ServerCtx, req *hotstuffpb.SnapshotRequest) (*hotstuffpb.SnapshotChunk, error) {
+	impl.srv.stats.received(FetchClass, req)
+
+	// requests from unknown peers share the budget of ID 0.
+	id, _ := GetPeerIDFromContext(ctx, impl.srv.mods.Configuration())
+	if !impl.srv.allow(id, FetchClass) {
+		return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	provider := impl.srv.snapshots
+	if provider == nil {
+		return nil, status.Errorf(codes.Unimplemented, "state transfer is not enabled")
+	}
+
+	if len(req.GetBlock()) == 0 {
+		info, ok := provider.LatestSnapshot()
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "no snapshot has been taken")
+		}
+		return &hotstuffpb.SnapshotChunk{
+			Block:  hotstuffpb.BlockToProto(info.Block),
+			QC:     hotstuffpb.QuorumCertToProto(info.QC),
+			Digest: info.Digest[:],
+			Size:   uint64(info.Size),
+		}, nil
+	}
+
+	var hash consensus.Hash
+	copy(hash[:], req.GetBlock())
+	data, ok := provider.Snapshot(hash)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "the snapshot of block %.8s is not available", hash)
+	}
+	if req.GetOffset() > uint64(len(data)) {
+		return nil, status.Errorf(codes.OutOfRange, "offset %d is beyond the end of the snapshot", req.GetOffset())
+	}
+	length := uint64(req.GetLength())
+	if length == 0 || length > MaxSnapshotChunkSize {
+		length = MaxSnapshotChunkSize
+	}
+	end := req.GetOffset() + length
+	if end > uint64(len(data)) {
+		end = uint64(len(data))
+	}
+	return &hotstuffpb.SnapshotChunk{Data: data[req.GetOffset():end]}, nil
+}
+
+// SnapshotInfos asks the other replicas to describe their latest snapshots, and returns the descriptions
+// that were received before the context was done.
+func (cfg *Config) SnapshotInfos(ctx context.Context) map[hotstuff.ID]SnapshotInfo {
+	var (
+		mut   sync.Mutex
+		wg    sync.WaitGroup
+		infos = make(map[hotstuff.ID]SnapshotInfo)
+	)
+	for _, id := range cfg.peerIDs() {
+		node := cfg.replicas[id].(*Replica).node
+		req := &hotstuffpb.SnapshotRequest{Instance: cfg.instance}
+		cfg.countSent(FetchClass, req, id)
+		wg.Add(1)
+		go func(id hotstuff.ID) {
+			defer wg.Done()
+			resp, err := rpcCall(ctx, node, fetchSnapshotMethod, req)
+			if err != nil {
+				cfg.mods.Logger().Debugf("Failed to get the latest snapshot of replica %d: %v", id, err)
+				return
+			}
+			info, err := snapshotInfoFromProto(resp.(*hotstuffpb.SnapshotChunk))
+			if err != nil {
+				cfg.mods.Logger().Infof("Invalid snapshot description from replica %d: %v", id, err)
+				return
+			}
+			mut.Lock()
+			infos[id] = info
+			mut.Unlock()
+		}(id)
+	}
+	wg.Wait()
+	return infos
+}
+
+// FetchSnapshot downloads the snapshot described by info from the replica with the given id.
+// The snapshot is requested in chunks of at most chunkSize bytes, and the next chunk is not requested
+// until the previous one has been received, such that the transfer proceeds at the pace of the receiver.
+func (cfg *Config) FetchSnapshot(ctx context.Context, id hotstuff.ID, info SnapshotInfo, chunkSize int) ([]byte, error) {
+	replica, ok := cfg.replicas[id].(*Replica)
+	if !ok || !replica.active() {
+		return nil, fmt.Errorf("replica %d is not connected", id)
+	}
+	hash := info.Block.Hash()
+	var data []byte
+	for len(data) < info.Size {
+		req := &hotstuffpb.SnapshotRequest{Block: hash[:], Offset: uint64(len(data)), Length: uint32(chunkSize), Instance: cfg.instance}
+		cfg.countSent(FetchClass, req, id)
+		msg, err := rpcCall(ctx, replica.node, fetchSnapshotMethod, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch snapshot from replica %d: %w", id, err)
+		}
+		resp := msg.(*hotstuffpb.SnapshotChunk)
+		if len(resp.GetData()) == 0 || len(data)+len(resp.GetData()) > info.Size {
+			return nil, fmt.Errorf("replica %d sent a chunk of %d bytes at offset %d of a snapshot of %d bytes",
+				id, len(resp.GetData()), len(data), info.Size)
+		}
+		data = append(data, resp.GetData()...)
+	}
+	return data, nil
+}
+
+func snapshotInfoFromProto(pb *hotstuffpb.SnapshotChunk) (info SnapshotInfo, err error) {
+	if pb.GetBlock() == nil || pb.GetQC() == nil {
+		return info, fmt.Errorf("missing block or QC")
+	}
+	if len(pb.GetDigest()) != len(info.Digest) {
+		return info, fmt.Errorf("digest has length %d", len(pb.GetDigest()))
+	}
+	info.Block, err = hotstuffpb.BlockFromProto(pb.GetBlock())
+	if err != nil {
+		return info, err
+	}
+	info.QC, err = hotstuffpb.QuorumCertFromProto(pb.GetQC())
+	if err != nil {
+		return info, err
+	}
+	copy(info.Digest[:], pb.GetDigest())
+	info.Size = int(pb.GetSize())
+	return info, nil
+}
@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// MessageStats contains network counters for a single class of messages.
+type MessageStats struct {
+	Sent          uint64 // The number of messages sent, counted once per recipient.
+	Received      uint64 // The number of messages received.
+	Failed        uint64 // The number of messages that could not be sent.
+	SentBytes     uint64 // The size of the messages sent.
+	ReceivedBytes uint64 // The size of the messages received.
+	Duplicates    uint64 // The number of received messages that were dropped as duplicates.
+}
+
+// NetworkStats contains network counters for each class of messages.
+type NetworkStats map[MessageClass]MessageStats
+
+// Sub returns the difference between the counters of s and other.
+// This can be used to compute the counters for an interval from two snapshots.
+func (s NetworkStats) Sub(other NetworkStats) NetworkStats {
+	diff := make(NetworkStats, len(s))
+	for class, a := range s {
+		b := other[class]
+		diff[class] = MessageStats{
+			Sent:          a.Sent - b.Sent,
+			Received:      a.Received - b.Received,
+			Failed:        a.Failed - b.Failed,
+			SentBytes:     a.SentBytes - b.SentBytes,
+			ReceivedBytes: a.ReceivedBytes - b.ReceivedBytes,
+			Duplicates:    a.Duplicates - b.Duplicates,
+		}
+	}
+	return diff
+}
+
+// statsCollector counts the messages that are sent and received.
+// It is safe for concurrent use.
+type statsCollector struct {
+	mut   sync.Mutex
+	stats [numMessageClasses]MessageStats
+}
+
+func (c *statsCollector) sent(class MessageClass, msg proto.Message, recipients int) {
+	size := uint64(proto.Size(msg))
+	c.mut.Lock()
+	c.stats[class].Sent += uint64(recipients)
+	c.stats[class].SentBytes += size * uint64(recipients)
+	c.mut.Unlock()
+}
+
+func (c *statsCollector) failed(class MessageClass, recipients int) {
+	c.mut.Lock()
+	c.stats[class].Failed += uint64(recipients)
+	c.mut.Unlock()
+}
+
+func (c *statsCollector) received(class MessageClass, msg proto.Message) {
+	size := uint64(proto.Size(msg))
+	c.mut.Lock()
+	c.stats[class].Received++
+	c.stats[class].ReceivedBytes += size
+	c.mut.Unlock()
+}
+
+func (c *statsCollector) duplicate(class MessageClass) {
+	c.mut.Lock()
+	c.stats[class].Duplicates++
+	c.mut.Unlock()
+}
+
+// snapshot returns a copy of the counters.
+func (c *statsCollector) snapshot() NetworkStats {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	stats := make(NetworkStats, numMessageClasses)
+	for class, s := range c.stats {
+		stats[MessageClass(class)] = s
+	}
+	return stats
+}
@@ -0,0 +1,151 @@
+package backend
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/stats"
+)
+
+// reconnectBackoff is the default backoff configuration used when reconnecting to a replica.
+// It is used both by gRPC when redialing, and by gorums when reestablishing the message stream.
+var reconnectBackoff = backoff.Config{
+	BaseDelay:  100 * time.Millisecond,
+	Multiplier: 1.6,
+	Jitter:     0.2,
+	MaxDelay:   5 * time.Second,
+}
+
+// ReplicaDisconnected is emitted when the connection to a replica is lost.
+// Messages sent to the replica while it is disconnected may be dropped.
+type ReplicaDisconnected struct {
+	ID hotstuff.ID
+}
+
+// ReplicaConnected is emitted when a connection to a replica has been established,
+// either initially or after the replica was disconnected.
+type ReplicaConnected struct {
+	ID hotstuff.ID
+}
+
+type connTagKey struct{}
+
+// connSupervisor is a gRPC stats handler that monitors the connections to the other replicas.
+// It emits ReplicaConnected and ReplicaDisconnected events when the state of a connection changes,
+// to the root configuration and the instances that share its connections.
+type connSupervisor struct {
+	cfg *Config
+
+	mut       sync.Mutex
+	instances []*Config
+	addrs     map[string]hotstuff.ID
+	connected map[hotstuff.ID]bool
+}
+
+func newConnSupervisor(cfg *Config) *connSupervisor {
+	return &connSupervisor{
+		cfg:       cfg,
+		addrs:     make(map[string]hotstuff.ID),
+		connected: make(map[hotstuff.ID]bool),
+	}
+}
+
+// addReplica registers the address of a replica so that its connections can be identified.
+func (s *connSupervisor) addReplica(id hotstuff.ID, addr string) {
+	// gorums resolves addresses before dialing, so we must do the same.
+	if tcpAddr, err := net.ResolveTCPAddr("tcp", addr); err == nil {
+		addr = tcpAddr.String()
+	}
+	s.mut.Lock()
+	s.addrs[addr] = id
+	s.mut.Unlock()
+}
+
+// addInstance registers a configuration that shares the connections, such that it receives the connection events.
+func (s *connSupervisor) addInstance(cfg *Config) {
+	s.mut.Lock()
+	s.instances = append(s.instances, cfg)
+	s.mut.Unlock()
+}
+
+// removeInstance stops sending connection events to a configuration that was registered by addInstance.
+func (s *connSupervisor) removeInstance(cfg *Config) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	for i, c := range s.instances {
+		if c == cfg {
+			s.instances = append(s.instances[:i:i], s.instances[i+1:]...)
+			return
+		}
+	}
+}
+
+// TagRPC is not used.
+func (s *connSupervisor) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC is not used.
+func (s *connSupervisor) HandleRPC(context.Context, stats.RPCStats) {}
+
+// TagConn attaches the ID of the remote replica to the context of the connection.
+func (s *connSupervisor) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if id, ok := s.addrs[info.RemoteAddr.String()]; ok {
+		return context.WithValue(ctx, connTagKey{}, id)
+	}
+	return ctx
+}
+
+// HandleConn emits an event when a connection to a replica is established or lost.
+func (s *connSupervisor) HandleConn(ctx context.Context, connStats stats.ConnStats) {
+	id, ok := ctx.Value(connTagKey{}).(hotstuff.ID)
+	if !ok {
+		return
+	}
+
+	var connected bool
+	switch connStats.(type) {
+	case *stats.ConnBegin:
+		connected = true
+	case *stats.ConnEnd:
+		connected = false
+	default:
+		return
+	}
+
+	s.mut.Lock()
+	changed := s.connected[id] != connected
+	s.connected[id] = connected
+	instances := s.instances
+	s.mut.Unlock()
+
+	if !changed || s.cfg.mods == nil {
+		return
+	}
+
+	if connected {
+		s.cfg.mods.Logger().Infof("Connected to replica %d", id)
+	} else {
+		s.cfg.mods.Logger().Infof("Lost connection to replica %d", id)
+	}
+	for _, cfg := range append([]*Config{s.cfg}, instances...) {
+		if connected {
+			cfg.mods.EventLoop().AddEvent(ReplicaConnected{ID: id})
+		} else {
+			cfg.mods.EventLoop().AddEvent(ReplicaDisconnected{ID: id})
+		}
+	}
+}
+
+// isConnected returns true if the replica with the given id is currently connected.
+func (s *connSupervisor) isConnected(id hotstuff.ID) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.connected[id]
+}
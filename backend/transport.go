@@ -0,0 +1,77 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/relab/gorums"
+	"google.golang.org/grpc"
+)
+
+// Transport creates the listeners and connections used for replica-to-replica communication.
+// The gRPC and gorums layers are bound to the listeners and connections created by the transport,
+// so all RPCs work unchanged regardless of the transport that is used.
+//
+// Transports that are not stream-oriented, such as QUIC, must present each connection as a net.Conn,
+// for example by wrapping a single bidirectional stream.
+type Transport interface {
+	// Listen creates a listener on the given address.
+	Listen(addr string) (net.Listener, error)
+	// Dial connects to the given address.
+	Dial(ctx context.Context, addr string) (net.Conn, error)
+}
+
+var (
+	transportMut sync.Mutex
+	transports   = map[string]Transport{
+		"tcp": tcpTransport{},
+	}
+)
+
+// RegisterTransport makes a transport available by name.
+// Registering a transport with an existing name replaces the old transport.
+func RegisterTransport(name string, transport Transport) {
+	transportMut.Lock()
+	defer transportMut.Unlock()
+	transports[name] = transport
+}
+
+// GetTransport returns the transport with the given name.
+// An empty name returns the default TCP transport.
+func GetTransport(name string) (Transport, error) {
+	if name == "" {
+		name = "tcp"
+	}
+	transportMut.Lock()
+	defer transportMut.Unlock()
+	if t, ok := transports[name]; ok {
+		return t, nil
+	}
+	available := make([]string, 0, len(transports))
+	for n := range transports {
+		available = append(available, n)
+	}
+	sort.Strings(available)
+	return nil, fmt.Errorf("unknown transport '%s' (available: %v)", name, available)
+}
+
+// WithTransport returns a manager option that makes the configuration dial replicas using the given transport.
+// When used with NewConfig, this option replaces the dialer of the configuration,
+// such that host names are not resolved again when reconnecting. Use Config.SetTransport instead.
+func WithTransport(transport Transport) gorums.ManagerOption {
+	return gorums.WithGrpcDialOptions(grpc.WithContextDialer(transport.Dial))
+}
+
+// tcpTransport is the default transport. It also accepts addresses of unix domain sockets (see UnixScheme).
+type tcpTransport struct{}
+
+func (tcpTransport) Listen(addr string) (net.Listener, error) {
+	return listen(addr)
+}
+
+func (tcpTransport) Dial(ctx context.Context, addr string) (net.Conn, error) {
+	return dial(ctx, addr)
+}
@@ -0,0 +1,283 @@
+package backend
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/relab/gorums"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"github.com/relab/hotstuff/synchronizer"
+)
+
+// treeDissemination holds the state of tree-based proposal dissemination.
+// Instead of sending the proposal to every replica, the leader sends it to its children in a tree
+// rooted at the leader, and each replica forwards proposals to its own children.
+// If a replica does not receive the proposal for a view in time, it requests it directly from the leader.
+type treeDissemination struct {
+	fanout     int
+	subTimeout time.Duration
+
+	// configurations of the children of this replica, indexed by the root of the tree.
+	children map[hotstuff.ID]*hotstuffpb.Configuration
+
+	lastProposal *hotstuffpb.Proposal // the most recent proposal made by this replica
+	highestView  consensus.View       // the highest view in which a proposal was received
+}
+
+// proposalCheckEvent is raised when a replica should check that it has received the proposal for a view.
+type proposalCheckEvent struct {
+	view consensus.View
+}
+
+// proposalRequestEvent is raised when a replica asks the leader to resend its proposal.
+type proposalRequestEvent struct {
+	id   hotstuff.ID
+	view consensus.View
+}
+
+// receivedProposal is raised when a proposal was received directly from its proposer,
+// and should be forwarded to the children of this replica.
+type receivedProposal struct {
+	proposal *hotstuffpb.Proposal
+}
+
+// forwardedProposal is raised when a replica other than the leader forwarded a proposal to this replica.
+type forwardedProposal struct {
+	sender   hotstuff.ID
+	proposal *hotstuffpb.Proposal
+	msg      consensus.ProposeMsg
+}
+
+// SetTreeDissemination enables tree-based proposal dissemination with the given fanout.
+// If a replica has not received the proposal for a view within subTimeout after entering the view,
+// it requests the proposal from the leader. SetTreeDissemination must be called before Connect.
+func (cfg *Config) SetTreeDissemination(fanout int, subTimeout time.Duration) {
+	if fanout < 1 {
+		fanout = 1
+	}
+	cfg.tree = &treeDissemination{
+		fanout:     fanout,
+		subTimeout: subTimeout,
+		children:   make(map[hotstuff.ID]*hotstuffpb.Configuration),
+	}
+}
+
+func (cfg *Config) initTree() {
+	cfg.mods.EventLoop().RegisterObserver(consensus.ProposeMsg{}, func(event interface{}) {
+		if cfg.tree == nil {
+			return
+		}
+		if view := event.(consensus.ProposeMsg).Block.View(); view > cfg.tree.highestView {
+			cfg.tree.highestView = view
+		}
+	})
+	cfg.mods.EventLoop().RegisterHandler(receivedProposal{}, func(event interface{}) {
+		p := event.(receivedProposal).proposal
+		proposer := hotstuff.ID(p.GetBlock().GetProposer())
+		if cfg.tree == nil || proposer == cfg.mods.ID() ||
+			proposer != cfg.mods.LeaderRotation().GetLeader(consensus.View(p.GetBlock().GetView())) {
+			return
+		}
+		cfg.forward(proposer, p)
+	})
+	cfg.mods.EventLoop().RegisterHandler(forwardedProposal{}, func(event interface{}) {
+		cfg.acceptForwarded(event.(forwardedProposal))
+	})
+	cfg.mods.EventLoop().RegisterObserver(synchronizer.ViewChangeEvent{}, func(event interface{}) {
+		cfg.scheduleProposalCheck(event.(synchronizer.ViewChangeEvent).View)
+	})
+	cfg.mods.EventLoop().RegisterHandler(proposalCheckEvent{}, func(event interface{}) {
+		cfg.checkProposal(event.(proposalCheckEvent).view)
+	})
+	cfg.mods.EventLoop().RegisterHandler(proposalRequestEvent{}, func(event interface{}) {
+		e := event.(proposalRequestEvent)
+		cfg.resendProposal(e.id, e.view)
+	})
+	// a replica that does not get the proposal it requested requests it again.
+	cfg.mods.EventLoop().SetOverflowPolicy(proposalRequestEvent{}, eventloop.DropNewest)
+}
+
+// treeChildren returns the children of the replica 'self' in a tree rooted at 'root'.
+// The tree is derived deterministically from the sorted replica IDs, such that all replicas agree on it.
+func treeChildren(ids []hotstuff.ID, root, self hotstuff.ID, fanout int) []hotstuff.ID {
+	sorted := append([]hotstuff.ID(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	n := len(sorted)
+	rootIdx, selfIdx := -1, -1
+	for i, id := range sorted {
+		if id == root {
+			rootIdx = i
+		}
+		if id == self {
+			selfIdx = i
+		}
+	}
+	if rootIdx < 0 || selfIdx < 0 {
+		return nil
+	}
+
+	// the position of self in the tree, where the root has position 0.
+	pos := (selfIdx - rootIdx + n) % n
+	var children []hotstuff.ID
+	for c := pos*fanout + 1; c <= pos*fanout+fanout && c < n; c++ {
+		children = append(children, sorted[(rootIdx+c)%n])
+	}
+	return children
+}
+
+// treeParent returns the parent of the replica 'self' in a tree rooted at 'root', and false if self is the root
+// or not in the tree.
+func treeParent(ids []hotstuff.ID, root, self hotstuff.ID, fanout int) (hotstuff.ID, bool) {
+	sorted := append([]hotstuff.ID(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	n := len(sorted)
+	rootIdx, selfIdx := -1, -1
+	for i, id := range sorted {
+		if id == root {
+			rootIdx = i
+		}
+		if id == self {
+			selfIdx = i
+		}
+	}
+	if rootIdx < 0 || selfIdx < 0 || rootIdx == selfIdx {
+		return 0, false
+	}
+
+	pos := (selfIdx - rootIdx + n) % n
+	return sorted[(rootIdx+(pos-1)/fanout)%n], true
+}
+
+// childConfig returns a configuration containing the children of this replica in the tree rooted at root.
+func (cfg *Config) childConfig(root hotstuff.ID) *hotstuffpb.Configuration {
+	if c, ok := cfg.tree.children[root]; ok {
+		return c
+	}
+	ids := make([]hotstuff.ID, 0, len(cfg.replicas))
+	for id := range cfg.replicas {
+		ids = append(ids, id)
+	}
+	var nodeIDs []uint32
+	for _, id := range treeChildren(ids, root, cfg.mods.ID(), cfg.tree.fanout) {
+		if r, ok := cfg.replicas[id].(*Replica); ok && r.active() {
+			nodeIDs = append(nodeIDs, uint32(id))
+		}
+	}
+	var c *hotstuffpb.Configuration
+	if len(nodeIDs) > 0 {
+		var err error
+		c, err = cfg.mgr.NewConfiguration(qspec{}, gorums.WithNodeIDs(nodeIDs))
+		if err != nil {
+			cfg.mods.Logger().Warnf("Failed to create configuration of children: %v", err)
+			return nil
+		}
+	}
+	cfg.tree.children[root] = c
+	return c
+}
+
+// proposeTree sends the proposal to the children of the leader.
+func (cfg *Config) proposeTree(p *hotstuffpb.Proposal) {
+	cfg.tree.lastProposal = p
+	if children := cfg.childConfig(cfg.mods.ID()); children != nil {
+		cfg.countSent(ProposalClass, p, nodeIDs(children)...)
+		children.Propose(context.Background(), p, gorums.WithNoSendWaiting())
+	}
+}
+
+// acceptForwarded delivers a forwarded proposal and forwards it to the children of this replica.
+// The proposal is dropped unless its proposer is the leader of its view,
+// and it was forwarded by the parent of this replica in the tree rooted at the leader.
+func (cfg *Config) acceptForwarded(e forwardedProposal) {
+	if cfg.tree == nil {
+		return
+	}
+	block := e.msg.Block
+	leader := cfg.mods.LeaderRotation().GetLeader(block.View())
+	if block.Proposer() != leader {
+		cfg.mods.Logger().Infof("Dropping proposal forwarded by replica %d: proposer %d is not the leader %d of view %d",
+			e.sender, block.Proposer(), leader, block.View())
+		return
+	}
+	ids := make([]hotstuff.ID, 0, len(cfg.replicas))
+	for id := range cfg.replicas {
+		ids = append(ids, id)
+	}
+	if parent, ok := treeParent(ids, leader, cfg.mods.ID(), cfg.tree.fanout); !ok || parent != e.sender {
+		cfg.mods.Logger().Infof("Dropping proposal forwarded by replica %d, which is not the parent of this replica", e.sender)
+		return
+	}
+	// the proposer is the leader at the root of the tree, not the replica that forwarded the proposal.
+	e.msg.ID = leader
+	cfg.mods.EventLoop().AddEvent(e.msg)
+	cfg.forward(leader, e.proposal)
+}
+
+// forward sends a proposal received from the parent to the children of this replica in the tree rooted at root.
+func (cfg *Config) forward(root hotstuff.ID, p *hotstuffpb.Proposal) {
+	if children := cfg.childConfig(root); children != nil {
+		cfg.countSent(ProposalClass, p, nodeIDs(children)...)
+		children.Forward(context.Background(), p, gorums.WithNoSendWaiting())
+	}
+}
+
+func (cfg *Config) scheduleProposalCheck(view consensus.View) {
+	if cfg.tree == nil || cfg.mods.LeaderRotation().GetLeader(view) == cfg.mods.ID() {
+		return
+	}
+	cfg.mods.Clock().AfterFunc(cfg.tree.subTimeout, func() {
+		cfg.mods.EventLoop().AddEvent(proposalCheckEvent{view: view})
+	})
+}
+
+// checkProposal requests the proposal from the leader if it has not been received yet.
+func (cfg *Config) checkProposal(view consensus.View) {
+	if cfg.tree.highestView >= view || cfg.mods.Synchronizer().View() != view {
+		return
+	}
+	leader := cfg.mods.LeaderRotation().GetLeader(view)
+	replica, ok := cfg.replicas[leader].(*Replica)
+	if !ok || !replica.active() {
+		return
+	}
+	cfg.mods.Logger().Debugf("Proposal for view %d not received through the tree; requesting it from the leader", view)
+	req := &hotstuffpb.ProposalRequest{View: uint64(view), Instance: cfg.instance}
+	cfg.countSent(FetchClass, req, leader)
+	replica.node.RequestProposal(context.Background(), req, gorums.WithNoSendWaiting())
+}
+
+// resendProposal sends the most recent proposal directly to the replica that requested it.
+func (cfg *Config) resendProposal(id hotstuff.ID, view consensus.View) {
+	if cfg.tree == nil {
+		return
+	}
+	p := cfg.tree.lastProposal
+	if p == nil || consensus.View(p.GetBlock().GetView()) != view {
+		return
+	}
+	replica, ok := cfg.replicas[id].(*Replica)
+	if !ok || !replica.active() {
+		return
+	}
+	single, err := cfg.singleConfig(replica)
+	if err != nil {
+		cfg.mods.Logger().Warnf("Failed to resend proposal: %v", err)
+		return
+	}
+	cfg.countSent(ProposalClass, p, id)
+	single.Propose(context.Background(), p, gorums.WithNoSendWaiting())
+}
+
+func nodeIDs(c *hotstuffpb.Configuration) []hotstuff.ID {
+	ids := make([]hotstuff.ID, 0, c.Size())
+	for _, node := range c.Nodes() {
+		ids = append(ids, hotstuff.ID(node.ID()))
+	}
+	return ids
+}
@@ -0,0 +1,123 @@
+package backend
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// UnixScheme is the prefix of addresses that refer to unix domain sockets, for example unix:///tmp/replica1.sock.
+// Unix domain sockets can be used instead of TCP when all replicas and clients run on the same machine.
+const UnixScheme = "unix://"
+
+// IsUnixAddress returns true if the address refers to a unix domain socket.
+func IsUnixAddress(addr string) bool {
+	return strings.HasPrefix(addr, UnixScheme)
+}
+
+// splitAddress returns the network and address to use with the net package.
+func splitAddress(addr string) (network, address string) {
+	if IsUnixAddress(addr) {
+		return "unix", strings.TrimPrefix(addr, UnixScheme)
+	}
+	return "tcp", addr
+}
+
+// listen creates a listener on a TCP address or unix domain socket.
+// A socket file left behind by a previous process is removed before listening.
+// The socket file is removed when the listener is closed.
+func listen(addr string) (net.Listener, error) {
+	network, address := splitAddress(addr)
+	if network == "unix" {
+		if fi, err := os.Stat(address); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			if err := os.Remove(address); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return net.Listen(network, address)
+}
+
+// dial connects to a TCP address or unix domain socket.
+// Placeholders returned by GorumsAddress are translated to the address of the socket.
+func dial(ctx context.Context, addr string) (net.Conn, error) {
+	var d net.Dialer
+	network, address := splitAddress(sockets.address(addr))
+	return d.DialContext(ctx, network, address)
+}
+
+// GorumsAddress returns an address that can be given to gorums for the given address.
+// Gorums only accepts TCP addresses, so the address of a unix domain socket is replaced by a placeholder TCP address,
+// which the default transport translates back to the address of the socket when dialing.
+// Other addresses are returned unchanged.
+func GorumsAddress(addr string) string {
+	if !IsUnixAddress(addr) {
+		return addr
+	}
+	return sockets.placeholder(addr)
+}
+
+var sockets = &socketMap{
+	addrs: make(map[string]string),
+	known: make(map[string]string),
+}
+
+// socketMap assigns placeholder TCP addresses to unix domain sockets.
+type socketMap struct {
+	mut   sync.Mutex
+	next  uint32
+	addrs map[string]string // placeholder -> unix address
+	known map[string]string // unix address -> placeholder
+}
+
+func (m *socketMap) placeholder(addr string) string {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if p, ok := m.known[addr]; ok {
+		return p
+	}
+	m.next++
+	// the placeholders are taken from the IPv6 unique local address range, and are never dialed.
+	ip := net.IP{0xfd, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, byte(m.next >> 24), byte(m.next >> 16), byte(m.next >> 8), byte(m.next)}
+	p := (&net.TCPAddr{IP: ip, Port: 1}).String()
+	m.addrs[p] = addr
+	m.known[addr] = p
+	return p
+}
+
+// address returns the address that the placeholder stands in for.
+// Addresses that are not placeholders are returned unchanged.
+func (m *socketMap) address(placeholder string) string {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if addr, ok := m.addrs[placeholder]; ok {
+		return addr
+	}
+	return placeholder
+}
+
+// UnixCredentials wraps transport credentials such that TLS can be used over unix domain sockets.
+// Since the address of a socket is not a host name, "localhost" is used as the server name
+// when verifying the certificate of a server that is connected through a unix domain socket.
+func UnixCredentials(creds credentials.TransportCredentials) credentials.TransportCredentials {
+	return unixCredentials{creds}
+}
+
+type unixCredentials struct {
+	credentials.TransportCredentials
+}
+
+func (c unixCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	if conn.RemoteAddr().Network() == "unix" {
+		authority = "localhost"
+	}
+	return c.TransportCredentials.ClientHandshake(ctx, authority, conn)
+}
+
+func (c unixCredentials) Clone() credentials.TransportCredentials {
+	return unixCredentials{c.TransportCredentials.Clone()}
+}
@@ -23,6 +23,7 @@ type blockChain struct {
 // It also allows the module to set module options using the OptionsBuilder.
 func (chain *blockChain) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
 	chain.mods = mods
+	chain.mods.Require(chain, consensus.ConfigurationModule, consensus.ConsensusModule, consensus.SynchronizerModule)
 }
 
 // New creates a new blockChain with a maximum size.
@@ -153,4 +154,42 @@ func (chain *blockChain) PruneToHeight(height consensus.View) (forkedBlocks []*c
 	return forkedBlocks
 }
 
+// DiscardBelow deletes the blocks whose view is lower than height.
+func (chain *blockChain) DiscardBelow(height consensus.View) {
+	chain.mut.Lock()
+	defer chain.mut.Unlock()
+
+	for hash, block := range chain.blocks {
+		if block.View() < height {
+			delete(chain.blocks, hash)
+			delete(chain.blockAtHeight, block.View())
+		}
+	}
+	if chain.pruneHeight < height {
+		chain.pruneHeight = height
+	}
+}
+
+// Range returns the blocks of the branch that ends with head whose views are at least from, in increasing order of
+// view. It returns false if any of these blocks are no longer stored.
+func (chain *blockChain) Range(head *consensus.Block, from consensus.View) (blocks []*consensus.Block, ok bool) {
+	chain.mut.Lock()
+	defer chain.mut.Unlock()
+
+	for block := head; block.View() >= from; {
+		blocks = append(blocks, block)
+		// the QC of a block certifies its parent, so the parent is not needed to tell that the branch ends at from.
+		if block.View() == from || block.QuorumCert().View() < from || block.Hash() == consensus.GetGenesis().Hash() {
+			break
+		}
+		if block, ok = chain.blocks[block.Parent()]; !ok {
+			return nil, false
+		}
+	}
+	for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+		blocks[i], blocks[j] = blocks[j], blocks[i]
+	}
+	return blocks, true
+}
+
 var _ consensus.BlockChain = (*blockChain)(nil)
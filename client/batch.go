@@ -0,0 +1,95 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+)
+
+// defaultBatchTimeout is how long a partial batch waits for more commands if the batch timeout is not set.
+const defaultBatchTimeout = time.Millisecond
+
+// commandReply is the reply of the replicas to a command that was sent on its own or in a batch.
+type commandReply interface {
+	Get() (*clientpb.CommandResponse, error)
+}
+
+// batcher collects the commands of a session into batches, which are sent in a single message when they are full,
+// or when the first command of the batch has waited for the batch timeout.
+type batcher struct {
+	mut     sync.Mutex
+	size    int
+	timeout time.Duration
+	send    func(*clientpb.Batch) *clientpb.AsyncBatchResponse
+	report  func(size int) // called when a batch is sent
+	cmds    []*clientpb.Command
+	batch   *pendingBatch
+	timer   *time.Timer
+}
+
+func newBatcher(size int, timeout time.Duration, send func(*clientpb.Batch) *clientpb.AsyncBatchResponse, report func(int)) *batcher {
+	if timeout <= 0 {
+		timeout = defaultBatchTimeout
+	}
+	return &batcher{size: size, timeout: timeout, send: send, report: report}
+}
+
+// pendingBatch is a batch that is being collected, or has been sent.
+type pendingBatch struct {
+	sent    chan struct{} // closed when the batch has been sent
+	promise *clientpb.AsyncBatchResponse
+}
+
+// batchReply is the reply to a command at the given position in a batch.
+type batchReply struct {
+	batch *pendingBatch
+	index int
+}
+
+// Get waits until the batch has been sent and the replicas have replied, and returns the response to the command.
+func (r batchReply) Get() (*clientpb.CommandResponse, error) {
+	<-r.batch.sent
+	resp, err := r.batch.promise.Get()
+	if err != nil {
+		return nil, err
+	}
+	if r.index >= len(resp.GetResponses()) {
+		return nil, fmt.Errorf("no response to command %d of the batch", r.index)
+	}
+	return resp.GetResponses()[r.index], nil
+}
+
+// add adds the command to the current batch, and sends the batch if it is full.
+func (b *batcher) add(cmd *clientpb.Command) commandReply {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	if b.batch == nil {
+		batch := &pendingBatch{sent: make(chan struct{})}
+		b.batch = batch
+		b.timer = time.AfterFunc(b.timeout, func() {
+			b.mut.Lock()
+			defer b.mut.Unlock()
+			// the batch may already have been sent because it was full.
+			if b.batch == batch {
+				b.flush()
+			}
+		})
+	}
+	reply := batchReply{batch: b.batch, index: len(b.cmds)}
+	b.cmds = append(b.cmds, cmd)
+	if len(b.cmds) >= b.size {
+		b.timer.Stop()
+		b.flush()
+	}
+	return reply
+}
+
+// flush sends the current batch. The caller must hold the mutex.
+func (b *batcher) flush() {
+	b.batch.promise = b.send(&clientpb.Batch{Commands: b.cmds})
+	close(b.batch.sent)
+	b.report(len(b.cmds))
+	b.batch, b.cmds = nil, nil
+}
@@ -1,46 +1,231 @@
 // Package client implements a simple client for testing HotStuff.
 // The client reads data from an input stream and sends the data in commands to a HotStuff replica.
-// The client waits for replies from f+1 replicas before it considers a command to be executed.
+// The client waits for matching replies from f+1 replicas before it considers a command to be executed.
 package client
 
 import (
+	"bytes"
 	"context"
 	"crypto/x509"
 	"errors"
+	"fmt"
 	"io"
-	"math"
+	"math/rand"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/relab/gorums"
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/backend"
 	"github.com/relab/hotstuff/internal/proto/clientpb"
 	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/metrics/types"
 	"github.com/relab/hotstuff/modules"
-	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 type qspec struct {
-	faulty int
+	faulty  int
+	ackMode AckMode
 }
 
-func (q *qspec) ExecCommandQF(_ *clientpb.Command, signatures map[uint32]*empty.Empty) (*empty.Empty, bool) {
-	if len(signatures) < q.faulty+1 {
+// ExecCommandQF requires f+1 replies with the same block hash and result, such that at least one correct replica
+// has executed the command, or only the first reply with the AckFirst mode. If the client requested a proof,
+// any of the proofs among the matching replies is returned, since the client must verify it anyway.
+func (q *qspec) ExecCommandQF(_ *clientpb.Command, replies map[uint32]*clientpb.CommandResponse) (*clientpb.CommandResponse, bool) {
+	if q.ackMode == AckFirst {
+		for _, reply := range replies {
+			return reply, true
+		}
+		return nil, false
+	}
+	if len(replies) < q.faulty+1 {
 		return nil, false
 	}
-	return &empty.Empty{}, true
+	for _, reply := range replies {
+		var (
+			matching int
+			proof    *clientpb.CommandResponse
+		)
+		for _, other := range replies {
+			if sameOutcome(other, reply) {
+				matching++
+				if other.GetProof() != nil {
+					proof = other
+				}
+			}
+		}
+		if matching >= q.faulty+1 {
+			if proof != nil {
+				return proof, true
+			}
+			return reply, true
+		}
+	}
+	return nil, false
+}
+
+// ExecCommandBatchQF requires f+1 replies that acknowledge each command of the batch with the same block hash and result,
+// or only the first reply with the AckFirst mode. Replies that do not respond to every command are ignored.
+func (q *qspec) ExecCommandBatchQF(in *clientpb.Batch, replies map[uint32]*clientpb.BatchResponse) (*clientpb.BatchResponse, bool) {
+	complete := make([]*clientpb.BatchResponse, 0, len(replies))
+	for _, reply := range replies {
+		if len(reply.GetResponses()) == len(in.GetCommands()) {
+			complete = append(complete, reply)
+		}
+	}
+	if q.ackMode == AckFirst {
+		if len(complete) > 0 {
+			return complete[0], true
+		}
+		return nil, false
+	}
+	for _, reply := range complete {
+		matching := 0
+		for _, other := range complete {
+			if sameOutcomes(reply, other) {
+				matching++
+			}
+		}
+		if matching >= q.faulty+1 {
+			return reply, true
+		}
+	}
+	return nil, false
+}
+
+// sameOutcome returns true if the replies acknowledge the command with the same block hash and result.
+func sameOutcome(a, b *clientpb.CommandResponse) bool {
+	return bytes.Equal(a.GetBlockHash(), b.GetBlockHash()) && bytes.Equal(a.GetResult(), b.GetResult())
+}
+
+// sameOutcomes returns true if the replies acknowledge each command with the same block hash and result.
+func sameOutcomes(a, b *clientpb.BatchResponse) bool {
+	for i, resp := range a.GetResponses() {
+		if !sameOutcome(resp, b.GetResponses()[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (q *qspec) QueryQF(in *clientpb.QueryRequest, replies map[uint32]*clientpb.QueryResponse) (*clientpb.QueryResponse, bool) {
+	if in.GetConsistency() == clientpb.ReadConsistency_LOCAL {
+		for _, reply := range replies {
+			return reply, true
+		}
+		return nil, false
+	}
+	for _, reply := range replies {
+		matching := 0
+		for _, other := range replies {
+			if other.GetHeight() == reply.GetHeight() && bytes.Equal(other.GetResult(), reply.GetResult()) {
+				matching++
+			}
+		}
+		if matching >= q.faulty+1 {
+			return reply, true
+		}
+	}
+	return nil, false
 }
 
 type pendingCmd struct {
 	sequenceNumber uint64
 	sendTime       time.Time
-	promise        *clientpb.AsyncEmpty
+	promise        commandReply
+	cmd            *clientpb.Command
+	cancel         context.CancelFunc // cancels the deadline of the current attempt, unless it was sent in a batch
+}
+
+// LoadMode determines how a client issues commands.
+type LoadMode string
+
+const (
+	// ClosedLoop issues a new command whenever fewer than MaxConcurrent commands are in flight.
+	ClosedLoop LoadMode = "closed"
+	// PoissonLoad issues commands with exponentially distributed intervals at the target rate,
+	// regardless of whether earlier commands have been executed.
+	PoissonLoad LoadMode = "poisson"
+	// FixedLoad issues commands at fixed intervals at the target rate,
+	// regardless of whether earlier commands have been executed.
+	FixedLoad LoadMode = "fixed"
+	// TraceLoad issues commands with the intervals and payload sizes of a trace,
+	// regardless of whether earlier commands have been executed.
+	TraceLoad LoadMode = "trace"
+)
+
+// ParseLoadMode returns the load mode with the given name. The empty string is the closed-loop mode.
+func ParseLoadMode(name string) (LoadMode, error) {
+	switch mode := LoadMode(name); mode {
+	case "":
+		return ClosedLoop, nil
+	case ClosedLoop, PoissonLoad, FixedLoad, TraceLoad:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown load mode '%s'", name)
+}
+
+// IsOpenLoop returns true if commands are issued at a target rate, rather than when earlier commands are executed.
+func (m LoadMode) IsOpenLoop() bool {
+	return m == PoissonLoad || m == FixedLoad || m == TraceLoad
+}
+
+// AckMode determines how many acknowledgements a client waits for before it considers a command to be executed.
+type AckMode string
+
+const (
+	// AckQuorum waits for f+1 acknowledgements with the same block hash, such that at least one correct replica
+	// vouches for the command, even if f replicas acknowledge commands that were never committed.
+	AckQuorum AckMode = "quorum"
+	// AckFirst trusts the first acknowledgement, which is faster, but may be given by a faulty replica.
+	AckFirst AckMode = "first"
+)
+
+// ParseAckMode returns the acknowledgement mode with the given name. The empty string is the quorum mode.
+func ParseAckMode(name string) (AckMode, error) {
+	switch mode := AckMode(name); mode {
+	case "":
+		return AckQuorum, nil
+	case AckQuorum, AckFirst:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown acknowledgement mode '%s'", name)
+}
+
+// ReadConsistency determines how many replicas must answer a query.
+type ReadConsistency string
+
+const (
+	// LocalRead returns the answer of the first replica to reply, which may not reflect the latest commands.
+	LocalRead ReadConsistency = "local"
+	// QuorumRead requires f+1 replicas to give the same answer at the same height,
+	// such that at least one correct replica vouches for the answer.
+	QuorumRead ReadConsistency = "quorum"
+)
+
+// ParseReadConsistency returns the read consistency with the given name. The empty string is the local read consistency.
+func ParseReadConsistency(name string) (ReadConsistency, error) {
+	switch consistency := ReadConsistency(name); consistency {
+	case "":
+		return LocalRead, nil
+	case LocalRead, QuorumRead:
+		return consistency, nil
+	}
+	return "", fmt.Errorf("unknown read consistency '%s'", name)
+}
+
+func (r ReadConsistency) proto() clientpb.ReadConsistency {
+	if r == QuorumRead {
+		return clientpb.ReadConsistency_QUORUM
+	}
+	return clientpb.ReadConsistency_LOCAL
 }
 
 // Config contains config options for a client.
@@ -48,31 +233,89 @@ type Config struct {
 	ID               hotstuff.ID
 	TLS              bool
 	RootCAs          *x509.CertPool
-	MaxConcurrent    uint32
-	PayloadSize      uint32
+	MaxConcurrent    uint32 // in open-loop mode, commands that would exceed this are dropped
+	MinConcurrent    uint32 // floor of the adaptive window; at least one
+	PayloadSize      uint32 // size of the payloads with the fixed size distribution
 	Input            io.ReadCloser
 	ManagerOptions   []gorums.ManagerOption
 	RateLimit        float64       // initial rate limit
 	RateStep         float64       // rate limit step up
 	RateStepInterval time.Duration // step up interval
+	LoadMode         LoadMode      // closed-loop if empty
+	TargetRate       float64       // commands per second in open-loop mode
+	LoadProfile      LoadProfile   // varies the rate in the poisson and fixed modes; TargetRate is used if it has no steps
+	Trace            Trace         // the commands issued in trace mode
+	TraceSpeed       float64       // the trace is replayed this many times faster; 1 if zero
+	TraceLoop        bool          // replay the trace again when it ends, instead of stopping
+	Seed             int64         // seed for the arrival times in the poisson mode; based on the current time if zero
+
+	PayloadDistribution SizeDistribution // fixed size if empty
+	PayloadMin          uint32           // minimum payload size with the uniform and zipf distributions
+	PayloadMax          uint32           // maximum payload size with the uniform and zipf distributions
+	PayloadZipfS        float64          // exponent of the zipf distribution, which must be greater than 1
+	PayloadSeed         int64            // seed for the payload sizes
+
+	Workload             Workload // opaque payloads if empty
+	KVKeys               uint32   // number of keys of the key-value workload; 1000 if zero
+	KVReadRatio          float64  // fraction of the key-value commands that are gets
+	KVDeleteRatio        float64  // fraction of the key-value commands that are deletes; the rest are puts
+	KVCheckpointInterval uint32   // every KVCheckpointInterval'th key-value command is a checkpoint; none if zero
+
+	RequestTimeout    time.Duration // deadline of the first attempt of a command; commands are not retried if zero
+	MaxRequestTimeout time.Duration // cap on the deadlines, which are doubled for each retry; uncapped if zero
+	MaxRetries        int           // number of retries before a command is considered failed, including retries of rejected commands
+	DrainTimeout      time.Duration // how long Stop waits for the commands in flight; they are abandoned at once if zero
+
+	// If BatchSize is greater than one, up to BatchSize commands are sent in a single message, which is sent when it is full,
+	// or when its first command has waited for BatchTimeout. The latencies are measured from when each command was issued.
+	BatchSize    uint32
+	BatchTimeout time.Duration // 1ms if zero
+
+	// If Tagger is not nil, it returns the tag of each command, given the session and the sequence number of the command.
+	// The tag is an opaque value, such as a trace context, that is carried with the command through consensus
+	// and echoed in the replies of the replicas.
+	Tagger func(session hotstuff.ID, sequenceNumber uint64) []byte
+
+	// If TargetLatency is non-zero, a closed-loop client adapts the number of commands in flight between
+	// MinConcurrent and MaxConcurrent, such that the latency of the commands stays below TargetLatency.
+	TargetLatency time.Duration
+
+	ReadConsistency ReadConsistency // consistency of queries; local if empty
+	AckMode         AckMode         // acknowledgements required for commands; quorum if empty
+
+	// The number of sessions. Session k uses the client ID ID+k, and has its own sequence numbers and connections.
+	// Each session issues commands as configured above, such that the load is multiplied by the number of sessions.
+	// A single session is used if this is zero.
+	Sessions int
 }
 
 // Client is a hotstuff client.
 type Client struct {
-	mut              sync.Mutex
-	id               hotstuff.ID
-	mods             *modules.Modules
-	mgr              *clientpb.Manager
-	gorumsConfig     *clientpb.Configuration
-	payloadSize      uint32
-	highestCommitted uint64 // highest sequence number acknowledged by the replicas
-	pendingCmds      chan pendingCmd
-	cancel           context.CancelFunc
-	done             chan struct{}
-	reader           io.ReadCloser
-	limiter          *rate.Limiter
-	stepUp           float64
-	stepUpInterval   time.Duration
+	id              hotstuff.ID
+	mods            *modules.Modules
+	sessions        []*session
+	cancel          context.CancelFunc
+	done            chan struct{}
+	reader          io.ReadCloser
+	stepUp          float64
+	stepUpInterval  time.Duration
+	loadMode        LoadMode
+	targetRate      float64
+	loadProfile     LoadProfile
+	trace           Trace
+	traceSpeed      float64
+	traceLoop       bool
+	requestTimeout  time.Duration
+	maxTimeout      time.Duration
+	maxRetries      int
+	drainTimeout    time.Duration
+	batchSize       int
+	batchTimeout    time.Duration
+	tagger          func(hotstuff.ID, uint64) []byte
+	lastTick        time.Time // the time of the last measurement, which is only accessed from the event loop
+	readConsistency ReadConsistency
+	ackMode         AckMode
+	completed       uint64 // the number of completed commands, accessed atomically
 }
 
 // New returns a new Client.
@@ -81,16 +324,38 @@ func New(conf Config, builder modules.Builder) (client *Client) {
 	mods := builder.Build()
 
 	client = &Client{
-		id:               conf.ID,
-		mods:             mods,
-		pendingCmds:      make(chan pendingCmd, conf.MaxConcurrent),
-		highestCommitted: 1,
-		done:             make(chan struct{}),
-		reader:           conf.Input,
-		payloadSize:      conf.PayloadSize,
-		limiter:          rate.NewLimiter(rate.Limit(conf.RateLimit), 1),
-		stepUp:           conf.RateStep,
-		stepUpInterval:   conf.RateStepInterval,
+		id:              conf.ID,
+		mods:            mods,
+		done:            make(chan struct{}),
+		reader:          conf.Input,
+		stepUp:          conf.RateStep,
+		stepUpInterval:  conf.RateStepInterval,
+		loadMode:        conf.LoadMode,
+		targetRate:      conf.TargetRate,
+		loadProfile:     conf.LoadProfile,
+		trace:           conf.Trace,
+		traceSpeed:      conf.TraceSpeed,
+		traceLoop:       conf.TraceLoop,
+		requestTimeout:  conf.RequestTimeout,
+		maxTimeout:      conf.MaxRequestTimeout,
+		maxRetries:      conf.MaxRetries,
+		drainTimeout:    conf.DrainTimeout,
+		batchSize:       int(conf.BatchSize),
+		batchTimeout:    conf.BatchTimeout,
+		tagger:          conf.Tagger,
+		readConsistency: conf.ReadConsistency,
+		ackMode:         conf.AckMode,
+	}
+
+	mods.EventLoop().RegisterObserver(types.TickEvent{}, func(event interface{}) {
+		client.lastTick = time.Now()
+	})
+
+	if err := conf.ValidatePayload(); err != nil {
+		mods.Logger().Errorf("Invalid payload size distribution, using fixed size payloads: %v", err)
+	}
+	if err := conf.ValidateKV(); err != nil {
+		mods.Logger().Errorf("Invalid key-value workload, using puts only: %v", err)
 	}
 
 	grpcOpts := []grpc.DialOption{grpc.WithBlock()}
@@ -101,61 +366,118 @@ func New(conf Config, builder modules.Builder) (client *Client) {
 	} else {
 		creds = insecure.NewCredentials()
 	}
-	grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(creds))
+	grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(backend.UnixCredentials(creds)))
 
-	opts := conf.ManagerOptions
+	// the default transport can dial unix domain sockets. It can be overridden by the options given by the caller.
+	transport, _ := backend.GetTransport("")
+	opts := append([]gorums.ManagerOption{backend.WithTransport(transport)}, conf.ManagerOptions...)
 	opts = append(opts, gorums.WithGrpcDialOptions(grpcOpts...))
 
-	client.mgr = clientpb.NewManager(opts...)
+	sessions := conf.Sessions
+	if sessions < 1 {
+		sessions = 1
+	}
+	if sessions > 1 {
+		client.reader = &lockedReader{reader: conf.Input}
+	}
+	// each session has its own manager, such that its commands are sent on its own connections.
+	for i := 0; i < sessions; i++ {
+		client.sessions = append(client.sessions, newSession(client, conf, i, clientpb.NewManager(opts...)))
+	}
 
 	return client
 }
 
+// Completed returns the number of commands of the client that have been committed.
+func (c *Client) Completed() uint64 {
+	return atomic.LoadUint64(&c.completed)
+}
+
 // Connect connects the client to the replicas.
 func (c *Client) Connect(replicas []backend.ReplicaInfo) (err error) {
 	nodes := make(map[string]uint32, len(replicas))
 	for _, r := range replicas {
-		nodes[r.Address] = uint32(r.ID)
+		nodes[backend.GorumsAddress(r.Address)] = uint32(r.ID)
 	}
-	c.gorumsConfig, err = c.mgr.NewConfiguration(&qspec{faulty: hotstuff.NumFaulty(len(replicas))}, gorums.WithNodeMap(nodes))
-	if err != nil {
-		c.mgr.Close()
-		return err
+	for _, s := range c.sessions {
+		s.gorumsConfig, err = s.mgr.NewConfiguration(&qspec{faulty: hotstuff.NumFaulty(len(replicas)), ackMode: c.ackMode}, gorums.WithNodeMap(nodes))
+		if err != nil {
+			for _, s := range c.sessions {
+				s.close()
+			}
+			return err
+		}
 	}
 	return nil
 }
 
-// Run runs the client until the context is closed.
+// Run runs the client until the context is closed or the input ends.
+// When the context is closed, the client stops sending commands and waits up to the drain timeout
+// for the commands in flight, whose latencies are recorded as usual. The commands that are still in flight
+// after the drain timeout are abandoned. Finally, the measurements since the last tick and the latency histograms
+// are written to the metrics logger, and no measurements are written after Run returns.
 func (c *Client) Run(ctx context.Context) {
+	c.lastTick = time.Now()
+	eventLoopCtx, stopEventLoop := context.WithCancel(context.Background())
 	eventLoopDone := make(chan struct{})
 	go func() {
-		c.mods.EventLoop().Run(ctx)
+		c.mods.EventLoop().Run(eventLoopCtx)
 		close(eventLoopDone)
 	}()
 	c.mods.Logger().Info("Starting to send commands")
 
-	commandStatsChan := make(chan struct{ executed, failed int })
-	// start the command handler
+	callCtx, abandon := context.WithCancel(context.Background())
+	defer abandon()
+	sessionsDone := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, s := range c.sessions {
+		wg.Add(1)
+		go func(s *session) {
+			defer wg.Done()
+			s.run(ctx, callCtx)
+		}(s)
+	}
 	go func() {
-		executed, failed := c.handleCommands(ctx)
-		commandStatsChan <- struct {
-			executed int
-			failed   int
-		}{executed, failed}
+		wg.Wait()
+		close(sessionsDone)
 	}()
 
-	err := c.sendCommands(ctx)
-	if err != nil && !errors.Is(err, io.EOF) {
-		c.mods.Logger().Panicf("Failed to send commands: %v", err)
+	select {
+	case <-sessionsDone:
+	case <-ctx.Done():
+		c.drain(sessionsDone, abandon)
 	}
 	c.close()
 
-	stats := <-commandStatsChan
-	c.mods.Logger().Infof("Done sending commands (executed: %d, failed: %d)", stats.executed, stats.failed)
+	// the sessions are done, so the remaining events are processed here, followed by a final tick.
+	stopEventLoop()
 	<-eventLoopDone
+	c.mods.EventLoop().AddEvent(types.TickEvent{LastTick: c.lastTick})
+	for c.mods.EventLoop().Tick() {
+	}
+	for _, s := range c.sessions {
+		c.mods.MetricsLogger().Log(s.latencies.toProto(types.NewClientEvent(uint32(s.id), time.Now())))
+	}
 	close(c.done)
 }
 
+// drain waits up to the drain timeout for the sessions to complete the commands in flight,
+// and then abandons the remaining commands.
+func (c *Client) drain(sessionsDone <-chan struct{}, abandon context.CancelFunc) {
+	if c.drainTimeout > 0 {
+		c.mods.Logger().Infof("Waiting up to %v for the commands in flight", c.drainTimeout)
+		timer := time.NewTimer(c.drainTimeout)
+		defer timer.Stop()
+		select {
+		case <-sessionsDone:
+			return
+		case <-timer.C:
+		}
+	}
+	abandon()
+	<-sessionsDone
+}
+
 // Start starts the client.
 func (c *Client) Start() {
 	var ctx context.Context
@@ -170,120 +492,185 @@ func (c *Client) Stop() {
 }
 
 func (c *Client) close() {
-	c.mgr.Close()
 	err := c.reader.Close()
 	if err != nil {
 		c.mods.Logger().Warn("Failed to close reader: ", err)
 	}
 }
 
-func (c *Client) sendCommands(ctx context.Context) error {
-	var (
-		num         uint64 = 1
-		lastCommand uint64 = math.MaxUint64
-		lastStep           = time.Now()
-	)
+// arrivalProcess generates the intervals between the commands issued in open-loop mode.
+type arrivalProcess struct {
+	poisson bool
+	mean    time.Duration
+	rnd     *rand.Rand
+}
 
-loop:
-	for {
-		if ctx.Err() != nil {
-			break
-		}
+func newArrivalProcess(mode LoadMode, rate float64, rnd *rand.Rand) *arrivalProcess {
+	return &arrivalProcess{
+		poisson: mode == PoissonLoad,
+		mean:    time.Duration(float64(time.Second) / rate),
+		rnd:     rnd,
+	}
+}
 
-		// step up the rate limiter
-		now := time.Now()
-		if now.Sub(lastStep) > c.stepUpInterval {
-			c.limiter.SetLimit(c.limiter.Limit() + rate.Limit(c.stepUp))
-			lastStep = now
-		}
+// next returns the interval until the next command should be issued.
+func (a *arrivalProcess) next() time.Duration {
+	return time.Duration(a.unit() * float64(a.mean))
+}
 
-		err := c.limiter.Wait(ctx)
-		if err != nil && !errors.Is(err, context.Canceled) {
-			return err
-		}
+// unit returns the interval until the next command in units of the mean interval.
+func (a *arrivalProcess) unit() float64 {
+	if a.poisson {
+		return a.rnd.ExpFloat64()
+	}
+	return 1
+}
 
-		// annoyingly, we need a mutex here to prevent the data race detector from complaining.
-		c.mut.Lock()
-		shouldStop := lastCommand <= c.highestCommitted
-		c.mut.Unlock()
+// Query sends a read-only query to the replicas, which answer it from their executed state without ordering it
+// through consensus. With LocalRead, the first answer is returned, which may not reflect recently executed commands.
+// With QuorumRead, f+1 replicas must give the same answer at the same height. If they do not,
+// for example because the replicas are executing commands, an error is returned and the query may be retried.
+func (c *Client) Query(ctx context.Context, query []byte) ([]byte, error) {
+	start := time.Now()
+	resp, err := c.sessions[0].gorumsConfig.Query(ctx, &clientpb.QueryRequest{
+		ClientID:    uint32(c.id),
+		Data:        query,
+		Consistency: c.readConsistency.proto(),
+	})
+	if err != nil {
+		c.mods.EventLoop().AddEvent(QueryFailedEvent{})
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	c.mods.EventLoop().AddEvent(QueryLatencyMeasurementEvent{Latency: time.Since(start)})
+	return resp.GetResult(), nil
+}
 
-		if shouldStop {
-			break
-		}
+// CommandResult is the outcome of a command that was executed by the replicas.
+type CommandResult struct {
+	Command   *clientpb.Command
+	BlockHash []byte // the hash of the block in which the command was executed
+	Result    []byte // the result returned by the application, which is empty if the application returns no results
+}
 
-		data := make([]byte, c.payloadSize)
-		n, err := c.reader.Read(data)
-		if err != nil && err != io.EOF {
-			// if we get an error other than EOF
-			return err
-		} else if err == io.EOF && n == 0 && lastCommand > num {
-			lastCommand = num
-			c.mods.Logger().Info("Reached end of file. Sending empty commands until last command is executed...")
-		}
+// ExecCommand sends a command with the given data and waits for it to be executed, independently of the
+// commands sent by Run, and returns the result of the command. With AckQuorum, f+1 replicas must agree on the result.
+func (c *Client) ExecCommand(ctx context.Context, data []byte) (*CommandResult, error) {
+	s := c.sessions[0]
+	cmd := &clientpb.Command{
+		ClientID:       uint32(s.id),
+		SequenceNumber: s.nextSequenceNumber(),
+		Data:           data,
+	}
+	resp, err := s.gorumsConfig.ExecCommand(ctx, cmd).Get()
+	if err != nil {
+		return nil, fmt.Errorf("command failed: %w", err)
+	}
+	return &CommandResult{Command: cmd, BlockHash: resp.GetBlockHash(), Result: resp.GetResult()}, nil
+}
 
-		cmd := &clientpb.Command{
-			ClientID:       uint32(c.id),
-			SequenceNumber: num,
-			Data:           data[:n],
-		}
+// ExecCommandWithProof sends a command with the given data and waits for it to be executed, independently of the
+// commands sent by Run. The replicas reply with a proof that the command was committed, which should be checked
+// with VerifyProof, since the proof is taken from a single replica.
+func (c *Client) ExecCommandWithProof(ctx context.Context, data []byte) (*clientpb.Command, *clientpb.CommitProof, error) {
+	s := c.sessions[0]
+	cmd := &clientpb.Command{
+		ClientID:       uint32(s.id),
+		SequenceNumber: s.nextSequenceNumber(),
+		Data:           data,
+		RequestProof:   true,
+	}
+	resp, err := s.gorumsConfig.ExecCommand(ctx, cmd).Get()
+	if err != nil {
+		return cmd, nil, fmt.Errorf("command failed: %w", err)
+	}
+	if resp.GetProof() == nil {
+		return cmd, nil, fmt.Errorf("no proof for command %d", cmd.GetSequenceNumber())
+	}
+	return cmd, resp.GetProof(), nil
+}
 
-		promise := c.gorumsConfig.ExecCommand(ctx, cmd)
+// isCanceled returns true if the command failed because the client was stopped.
+func isCanceled(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return true
+	}
+	qcError, ok := err.(gorums.QuorumCallError)
+	return ok && qcError.Reason == context.Canceled.Error()
+}
 
-		num++
-		select {
-		case c.pendingCmds <- pendingCmd{sequenceNumber: num, sendTime: time.Now(), promise: promise}:
-		case <-ctx.Done():
-			break loop
+// isOverloaded returns true if the command failed because replicas rejected it since their command caches were full.
+func isOverloaded(err error) bool {
+	qcError, ok := err.(gorums.QuorumCallError)
+	if !ok {
+		return false
+	}
+	for _, nodeErr := range qcError.Errors {
+		if status.Code(nodeErr.Cause) == codes.ResourceExhausted {
+			return true
 		}
+	}
+	return false
+}
 
-		if num%100 == 0 {
-			c.mods.Logger().Infof("%d commands sent, payloadSize: %d", num, n)
+// isDeadlineExceeded returns true if the command failed because the deadline of the attempt expired.
+func isDeadlineExceeded(err error) bool {
+	qcError, ok := err.(gorums.QuorumCallError)
+	return ok && qcError.Reason == context.DeadlineExceeded.Error()
+}
 
-		}
+// LatencyMeasurementEvent represents a single latency measurement.
+type LatencyMeasurementEvent struct {
+	Session hotstuff.ID // the client ID of the session that sent the command
+	Latency time.Duration
+	Tag     []byte // the tag of the command, as echoed by the replicas
+}
 
-	}
-	return nil
+// CommandRetriedEvent is emitted when a client sends a command again because the deadline of the previous attempt expired.
+type CommandRetriedEvent struct {
+	Session hotstuff.ID
 }
 
-// handleCommands will get pending commands from the pendingCmds channel and then
-// handle them as they become acknowledged by the replicas. We expect the commands to be
-// acknowledged in the order that they were sent.
-func (c *Client) handleCommands(ctx context.Context) (executed, failed int) {
-	for {
-		var (
-			cmd pendingCmd
-			ok  bool
-		)
-		select {
-		case cmd, ok = <-c.pendingCmds:
-			if !ok {
-				return
-			}
-		case <-ctx.Done():
-			return
-		}
-		_, err := cmd.promise.Get()
-		if err != nil {
-			qcError, ok := err.(gorums.QuorumCallError)
-			if !ok || qcError.Reason != context.Canceled.Error() {
-				c.mods.Logger().Debugf("Did not get enough replies for command: %v\n", err)
-				failed++
-			}
-		} else {
-			executed++
-		}
-		c.mut.Lock()
-		if cmd.sequenceNumber > c.highestCommitted {
-			c.highestCommitted = cmd.sequenceNumber
-		}
-		c.mut.Unlock()
+// CommandFailedEvent is emitted when a command fails, for example because its last retry timed out.
+type CommandFailedEvent struct {
+	Session hotstuff.ID
+}
 
-		duration := time.Since(cmd.sendTime)
-		c.mods.EventLoop().AddEvent(LatencyMeasurementEvent{Latency: duration})
-	}
+// CommandAbandonedEvent is emitted when a client stops waiting for a command that is still in flight,
+// because the command did not complete before the drain timeout expired.
+type CommandAbandonedEvent struct {
+	Session hotstuff.ID
 }
 
-// LatencyMeasurementEvent represents a single latency measurement.
-type LatencyMeasurementEvent struct {
+// CommandDroppedEvent is emitted when a client in open-loop mode drops a command,
+// because too many commands were already in flight.
+type CommandDroppedEvent struct {
+	Session hotstuff.ID
+}
+
+// TargetRateEvent is emitted when an open-loop session starts issuing commands at a target rate,
+// and when the rate changes according to the load profile.
+type TargetRateEvent struct {
+	Session hotstuff.ID
+	Rate    float64 // commands per second
+	Step    int     // the number of the step of the load profile, starting from one, or zero if there is no profile
+}
+
+// BatchSentEvent is emitted when a session sends a batch of commands.
+type BatchSentEvent struct {
+	Session hotstuff.ID
+	Size    int // the number of commands in the batch
+}
+
+// WindowSizeEvent is emitted when the adaptive window of a closed-loop session changes size.
+type WindowSizeEvent struct {
+	Session hotstuff.ID
+	Size    int // the number of commands that may be in flight
+}
+
+// QueryLatencyMeasurementEvent represents the latency of a single query.
+type QueryLatencyMeasurementEvent struct {
 	Latency time.Duration
 }
+
+// QueryFailedEvent is emitted when a query fails, for example because the replicas did not give matching answers.
+type QueryFailedEvent struct{}
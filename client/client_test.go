@@ -0,0 +1,804 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/relab/gorums"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/examples/kvstore"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/metrics/types"
+	"github.com/relab/hotstuff/modules"
+)
+
+// fakeReplica records when commands arrive, and replies after a delay, or when released.
+type fakeReplica struct {
+	delay   time.Duration
+	release chan struct{}
+	slots   chan struct{} // if not nil, limits how many commands are served at the same time
+	hash    []byte        // the block hash with which commands are acknowledged
+
+	mut      sync.Mutex
+	arrivals []time.Time
+	commands []*clientpb.Command
+	batches  int // the number of batches received
+}
+
+func (r *fakeReplica) ExecCommand(ctx gorums.ServerCtx, cmd *clientpb.Command) (*clientpb.CommandResponse, error) {
+	r.mut.Lock()
+	r.arrivals = append(r.arrivals, time.Now())
+	r.commands = append(r.commands, cmd)
+	r.mut.Unlock()
+	// handle the following commands concurrently.
+	ctx.Release()
+	if r.slots != nil {
+		r.slots <- struct{}{}
+		defer func() { <-r.slots }()
+	}
+	select {
+	case <-time.After(r.delay):
+	case <-r.release:
+	}
+	return &clientpb.CommandResponse{BlockHash: r.hash}, nil
+}
+
+func (r *fakeReplica) ExecCommandBatch(ctx gorums.ServerCtx, batch *clientpb.Batch) (*clientpb.BatchResponse, error) {
+	r.mut.Lock()
+	for _, cmd := range batch.GetCommands() {
+		r.arrivals = append(r.arrivals, time.Now())
+		r.commands = append(r.commands, cmd)
+	}
+	r.batches++
+	r.mut.Unlock()
+	ctx.Release()
+	select {
+	case <-time.After(r.delay):
+	case <-r.release:
+	}
+	resp := &clientpb.BatchResponse{}
+	for range batch.GetCommands() {
+		resp.Responses = append(resp.Responses, &clientpb.CommandResponse{BlockHash: r.hash})
+	}
+	return resp, nil
+}
+
+func (r *fakeReplica) receivedBatches() int {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return r.batches
+}
+
+func (r *fakeReplica) Query(_ gorums.ServerCtx, _ *clientpb.QueryRequest) (*clientpb.QueryResponse, error) {
+	return &clientpb.QueryResponse{}, nil
+}
+
+func (r *fakeReplica) received() []time.Time {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return append([]time.Time(nil), r.arrivals...)
+}
+
+func (r *fakeReplica) receivedCommands() []*clientpb.Command {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return append([]*clientpb.Command(nil), r.commands...)
+}
+
+func startFakeReplica(t *testing.T, delay time.Duration) (*fakeReplica, string) {
+	t.Helper()
+	return serveFakeReplica(t, &fakeReplica{delay: delay, release: make(chan struct{})})
+}
+
+func serveFakeReplica(t *testing.T, replica *fakeReplica) (*fakeReplica, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := gorums.NewServer()
+	clientpb.RegisterClientServer(srv, replica)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() {
+		close(replica.release)
+		srv.Stop()
+	})
+	return replica, lis.Addr().String()
+}
+
+// startClient starts a client that sends the given number of commands to the replica.
+func startClient(t *testing.T, addr string, conf Config, commands int) (*Client, chan time.Duration, chan struct{}) {
+	t.Helper()
+	return startClientOn(t, []backend.ReplicaInfo{{ID: 1, Address: addr}}, conf, commands)
+}
+
+// startClientOn is like startClient, but sends the commands to all of the replicas.
+func startClientOn(t *testing.T, replicas []backend.ReplicaInfo, conf Config, commands int) (*Client, chan time.Duration, chan struct{}) {
+	t.Helper()
+	cli, latencies, dropped := newTestClient(conf, commands)
+	if err := cli.Connect(replicas); err != nil {
+		t.Fatal(err)
+	}
+	cli.Start()
+	return cli, latencies, dropped
+}
+
+// newTestClient returns a client that sends the given number of commands,
+// such that the test can register more event handlers before the client is started.
+func newTestClient(conf Config, commands int) (*Client, chan time.Duration, chan struct{}) {
+	conf.ID = 1
+	conf.PayloadSize = 1
+	conf.Input = io.NopCloser(bytes.NewReader(make([]byte, commands)))
+	conf.ManagerOptions = []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)}
+	cli := New(conf, modules.NewBuilder(conf.ID))
+
+	latencies := make(chan time.Duration, commands)
+	dropped := make(chan struct{}, commands)
+	cli.mods.EventLoop().RegisterHandler(LatencyMeasurementEvent{}, func(event interface{}) {
+		latencies <- event.(LatencyMeasurementEvent).Latency
+	})
+	cli.mods.EventLoop().RegisterHandler(CommandDroppedEvent{}, func(_ interface{}) {
+		dropped <- struct{}{}
+	})
+	return cli, latencies, dropped
+}
+
+func meanAndCV(intervals []float64) (mean, cv float64) {
+	for _, x := range intervals {
+		mean += x
+	}
+	mean /= float64(len(intervals))
+	var variance float64
+	for _, x := range intervals {
+		variance += (x - mean) * (x - mean)
+	}
+	variance /= float64(len(intervals) - 1)
+	return mean, math.Sqrt(variance) / mean
+}
+
+func TestArrivalProcess(t *testing.T) {
+	const (
+		rate    = 1000.0
+		samples = 100000
+	)
+	want := float64(time.Second) / rate
+
+	tests := []struct {
+		mode   LoadMode
+		wantCV float64
+	}{
+		// the intervals of a Poisson process are exponentially distributed, such that the standard deviation equals the mean.
+		{PoissonLoad, 1},
+		{FixedLoad, 0},
+	}
+	for _, test := range tests {
+		t.Run(string(test.mode), func(t *testing.T) {
+			arrivals := newArrivalProcess(test.mode, rate, rand.New(rand.NewSource(1)))
+			intervals := make([]float64, samples)
+			for i := range intervals {
+				intervals[i] = float64(arrivals.next())
+			}
+			mean, cv := meanAndCV(intervals)
+			if math.Abs(mean-want)/want > 0.02 {
+				t.Errorf("mean interval was %v, want %v", time.Duration(mean), time.Duration(want))
+			}
+			if math.Abs(cv-test.wantCV) > 0.02 {
+				t.Errorf("coefficient of variation was %.3f, want %.3f", cv, test.wantCV)
+			}
+		})
+	}
+}
+
+func TestOpenLoopRateAndLatency(t *testing.T) {
+	const (
+		commands = 100
+		rate     = 200.0
+		delay    = 50 * time.Millisecond
+	)
+	for _, mode := range []LoadMode{PoissonLoad, FixedLoad} {
+		t.Run(string(mode), func(t *testing.T) {
+			replica, addr := startFakeReplica(t, delay)
+			// the responses arrive after several more commands have been issued.
+			cli, latencies, dropped := startClient(t, addr, Config{
+				MaxConcurrent: commands,
+				LoadMode:      mode,
+				TargetRate:    rate,
+			}, commands)
+			defer cli.Stop()
+
+			var measured []time.Duration
+			timeout := time.After(10 * time.Second)
+			for len(measured) < commands {
+				select {
+				case l := <-latencies:
+					measured = append(measured, l)
+				case <-timeout:
+					t.Fatalf("got %d latency measurements, want %d", len(measured), commands)
+				}
+			}
+			if len(dropped) > 0 {
+				t.Errorf("%d commands were dropped, want 0", len(dropped))
+			}
+
+			arrivals := replica.received()
+			if len(arrivals) != commands {
+				t.Fatalf("replica received %d commands, want %d", len(arrivals), commands)
+			}
+			intervals := make([]float64, len(arrivals)-1)
+			for i := range intervals {
+				intervals[i] = float64(arrivals[i+1].Sub(arrivals[i]))
+			}
+			want := float64(time.Second) / rate
+			mean, _ := meanAndCV(intervals)
+			// the mean of 99 exponentially distributed intervals has a standard deviation of about 10% of the mean.
+			if math.Abs(mean-want)/want > 0.35 {
+				t.Errorf("mean interval was %v, want %v", time.Duration(mean), time.Duration(want))
+			}
+
+			// the latencies are measured from when each command was sent, even though the responses are late.
+			sort.Slice(measured, func(i, j int) bool { return measured[i] < measured[j] })
+			if measured[0] < delay {
+				t.Errorf("minimum latency was %v, want at least %v", measured[0], delay)
+			}
+			if median := measured[len(measured)/2]; median > 2*delay {
+				t.Errorf("median latency was %v, want at most %v", median, 2*delay)
+			}
+		})
+	}
+}
+
+func TestOpenLoopDropsCommands(t *testing.T) {
+	const maxConcurrent = 5
+
+	// the replica does not reply until the test is done.
+	replica, addr := startFakeReplica(t, time.Hour)
+	cli, _, dropped := startClient(t, addr, Config{
+		MaxConcurrent: maxConcurrent,
+		LoadMode:      FixedLoad,
+		TargetRate:    500,
+	}, 1000)
+
+	time.Sleep(200 * time.Millisecond)
+	cli.Stop()
+
+	if got := len(replica.received()); got != maxConcurrent {
+		t.Errorf("replica received %d commands, want %d", got, maxConcurrent)
+	}
+	if len(dropped) == 0 {
+		t.Error("no commands were dropped")
+	}
+}
+
+func TestParseLoadMode(t *testing.T) {
+	for name, want := range map[string]LoadMode{"": ClosedLoop, "closed": ClosedLoop, "poisson": PoissonLoad, "fixed": FixedLoad} {
+		got, err := ParseLoadMode(name)
+		if err != nil || got != want {
+			t.Errorf("ParseLoadMode(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseLoadMode("bursty"); err == nil {
+		t.Error("expected an error for an unknown load mode")
+	}
+}
+
+func TestExecCommandQuorumFunction(t *testing.T) {
+	ack := func(hash string) *clientpb.CommandResponse {
+		return &clientpb.CommandResponse{BlockHash: []byte(hash)}
+	}
+	tests := []struct {
+		name    string
+		mode    AckMode
+		replies map[uint32]*clientpb.CommandResponse
+		want    string
+		wantOK  bool
+	}{
+		{"first/none", AckFirst, map[uint32]*clientpb.CommandResponse{}, "", false},
+		{"first/one", AckFirst, map[uint32]*clientpb.CommandResponse{1: ack("forged")}, "forged", true},
+		{"quorum/one", AckQuorum, map[uint32]*clientpb.CommandResponse{1: ack("forged")}, "", false},
+		{"quorum/different blocks", AckQuorum, map[uint32]*clientpb.CommandResponse{1: ack("forged"), 2: ack("block")}, "", false},
+		{"quorum/matching", AckQuorum, map[uint32]*clientpb.CommandResponse{1: ack("forged"), 2: ack("block"), 3: ack("block")}, "block", true},
+		{"quorum/different results", AckQuorum, map[uint32]*clientpb.CommandResponse{
+			1: {BlockHash: []byte("block"), Result: []byte("forged")},
+			2: {BlockHash: []byte("block"), Result: []byte("value")},
+		}, "", false},
+	}
+	for _, test := range tests {
+		q := &qspec{faulty: 1, ackMode: test.mode}
+		reply, ok := q.ExecCommandQF(&clientpb.Command{}, test.replies)
+		if ok != test.wantOK || (ok && string(reply.GetBlockHash()) != test.want) {
+			t.Errorf("%s: got %q, %v, want %q, %v", test.name, reply.GetBlockHash(), ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestLyingReplica(t *testing.T) {
+	const delay = 200 * time.Millisecond
+	// the correct replicas acknowledge the commands after a delay, whereas replica 4 acknowledges them at once,
+	// with a block that was never committed.
+	replicas := make([]backend.ReplicaInfo, 4)
+	for i := range replicas {
+		replica := &fakeReplica{delay: delay, release: make(chan struct{}), hash: []byte("committed")}
+		if i == len(replicas)-1 {
+			replica.delay = 0
+			replica.hash = []byte("forged")
+		}
+		_, addr := serveFakeReplica(t, replica)
+		replicas[i] = backend.ReplicaInfo{ID: hotstuff.ID(i + 1), Address: addr}
+	}
+
+	latency := func(mode AckMode) time.Duration {
+		cli, latencies, _ := startClientOn(t, replicas, Config{MaxConcurrent: 1, AckMode: mode}, 100)
+		defer cli.Stop()
+		select {
+		case latency := <-latencies:
+			return latency
+		case <-time.After(5 * time.Second):
+			t.Fatal("no command was acknowledged")
+		}
+		return 0
+	}
+
+	if got := latency(AckFirst); got >= delay {
+		t.Errorf("with the first acknowledgement, the latency was %v, want less than %v", got, delay)
+	}
+	if got := latency(AckQuorum); got < delay {
+		t.Errorf("with a quorum of acknowledgements, the latency was %v: the client trusted the lying replica", got)
+	}
+}
+
+func TestQueryQuorumFunction(t *testing.T) {
+	q := &qspec{faulty: 1}
+	answer := func(result string, height uint64) *clientpb.QueryResponse {
+		return &clientpb.QueryResponse{Result: []byte(result), Height: height}
+	}
+	tests := []struct {
+		name        string
+		consistency ReadConsistency
+		replies     map[uint32]*clientpb.QueryResponse
+		want        string
+		wantOK      bool
+	}{
+		{"local/none", LocalRead, map[uint32]*clientpb.QueryResponse{}, "", false},
+		{"local/one", LocalRead, map[uint32]*clientpb.QueryResponse{1: answer("old", 1)}, "old", true},
+		{"quorum/one", QuorumRead, map[uint32]*clientpb.QueryResponse{1: answer("new", 2)}, "", false},
+		{"quorum/different results", QuorumRead, map[uint32]*clientpb.QueryResponse{1: answer("old", 2), 2: answer("new", 2)}, "", false},
+		{"quorum/different heights", QuorumRead, map[uint32]*clientpb.QueryResponse{1: answer("new", 2), 2: answer("new", 3)}, "", false},
+		{"quorum/matching", QuorumRead, map[uint32]*clientpb.QueryResponse{1: answer("old", 1), 2: answer("new", 2), 3: answer("new", 2)}, "new", true},
+	}
+	for _, test := range tests {
+		reply, ok := q.QueryQF(&clientpb.QueryRequest{Consistency: test.consistency.proto()}, test.replies)
+		if ok != test.wantOK || (ok && string(reply.GetResult()) != test.want) {
+			t.Errorf("%s: got %q, %v, want %q, %v", test.name, reply.GetResult(), ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestSessions(t *testing.T) {
+	const (
+		sessions = 8
+		delay    = 20 * time.Millisecond
+		duration = time.Second
+	)
+	// throughput returns the number of commands received by the replica from a client with the given number of sessions.
+	throughput := func(n int) []*clientpb.Command {
+		replica, addr := startFakeReplica(t, delay)
+		cli, _, _ := startClient(t, addr, Config{MaxConcurrent: 1, Sessions: n}, 10000)
+		time.Sleep(duration)
+		cli.Stop()
+		return replica.receivedCommands()
+	}
+
+	single := len(throughput(1))
+	cmds := throughput(sessions)
+	if len(cmds) < 5*single {
+		t.Errorf("%d sessions sent %d commands, and a single session sent %d commands", sessions, len(cmds), single)
+	}
+
+	seqs := make(map[uint32]map[uint64]bool)
+	for _, cmd := range cmds {
+		if seqs[cmd.GetClientID()] == nil {
+			seqs[cmd.GetClientID()] = make(map[uint64]bool)
+		}
+		if seqs[cmd.GetClientID()][cmd.GetSequenceNumber()] {
+			t.Fatalf("command %d of client %d was sent twice", cmd.GetSequenceNumber(), cmd.GetClientID())
+		}
+		seqs[cmd.GetClientID()][cmd.GetSequenceNumber()] = true
+	}
+	for id := uint32(1); id <= sessions; id++ {
+		// each session numbers its commands from 1.
+		for seq := uint64(1); seq <= uint64(len(seqs[id])); seq++ {
+			if !seqs[id][seq] {
+				t.Errorf("session %d sent %d commands, but not command %d", id, len(seqs[id]), seq)
+				break
+			}
+		}
+	}
+	if len(seqs) != sessions {
+		t.Errorf("got commands from %d client IDs, want %d", len(seqs), sessions)
+	}
+}
+
+func TestAdaptiveWindow(t *testing.T) {
+	const (
+		capacity = 8
+		delay    = 10 * time.Millisecond
+		ceiling  = 64
+	)
+	// the replica serves capacity commands at a time, such that the latency grows with the number of commands in flight
+	// beyond the capacity, whereas the throughput does not.
+	_, addr := serveFakeReplica(t, &fakeReplica{
+		delay:   delay,
+		release: make(chan struct{}),
+		slots:   make(chan struct{}, capacity),
+	})
+	cli, _, _ := startClient(t, addr, Config{
+		MaxConcurrent: ceiling,
+		MinConcurrent: 1,
+		TargetLatency: delay * 3 / 2,
+		RateLimit:     math.Inf(1),
+	}, 100000)
+	defer cli.Stop()
+
+	time.Sleep(time.Second)
+	var sizes []float64
+	for i := 0; i < 100; i++ {
+		sizes = append(sizes, float64(cli.sessions[0].window.current()))
+		time.Sleep(10 * time.Millisecond)
+	}
+	sort.Float64s(sizes)
+	mean, _ := meanAndCV(sizes)
+	if mean < capacity/2 || mean > 2*capacity {
+		t.Errorf("mean window was %.1f, want it near the capacity %d", mean, capacity)
+	}
+	if max := sizes[len(sizes)-1]; max >= ceiling {
+		t.Errorf("window reached the ceiling %d", ceiling)
+	}
+}
+
+func TestTraceReplay(t *testing.T) {
+	trace := Trace{
+		{Delta: 0, Size: 3},
+		{Delta: 100 * time.Millisecond, Size: 10, Key: "a"},
+		{Delta: 40 * time.Millisecond, Size: 1},
+		{Delta: 200 * time.Millisecond, Size: 7, Key: "b"},
+		{Delta: 60 * time.Millisecond, Size: 4},
+	}
+	const speed = 2
+	replica, addr := startFakeReplica(t, 0)
+	cli, latencies, _ := startClient(t, addr, Config{
+		MaxConcurrent: 10,
+		LoadMode:      TraceLoad,
+		Trace:         trace,
+		TraceSpeed:    speed,
+	}, 1000)
+	defer cli.Stop()
+	for range trace {
+		select {
+		case <-latencies:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the commands of the trace were not acknowledged")
+		}
+	}
+	// the client stops at the end of the trace.
+	time.Sleep(100 * time.Millisecond)
+
+	cmds := replica.receivedCommands()
+	arrivals := replica.received()
+	if len(cmds) != len(trace) {
+		t.Fatalf("replica received %d commands, want %d", len(cmds), len(trace))
+	}
+	for i, entry := range trace {
+		data := cmds[i].GetData()
+		if entry.Key != "" {
+			prefix := kvstore.Put(entry.Key, nil)
+			if !bytes.HasPrefix(data, prefix) {
+				t.Errorf("command %d was %q, want it to set the key %q", i, data, entry.Key)
+			}
+			data = data[len(prefix):]
+		}
+		if len(data) != int(entry.Size) {
+			t.Errorf("command %d had a payload of %d bytes, want %d", i, len(data), entry.Size)
+		}
+		if i == 0 {
+			continue
+		}
+		interval := arrivals[i].Sub(arrivals[i-1])
+		want := entry.Delta / speed
+		if interval < want-10*time.Millisecond || interval > want+20*time.Millisecond {
+			t.Errorf("command %d arrived %v after the previous command, want %v", i, interval, want)
+		}
+	}
+}
+
+// eventLog records the order of the measurement events of a client.
+type eventLog struct {
+	mut    sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(event string) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *eventLog) get() []string {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+// count returns the number of events of the given kind.
+func (l *eventLog) count(event string) (n int) {
+	for _, e := range l.get() {
+		if e == event {
+			n++
+		}
+	}
+	return n
+}
+
+// startLoggedClient starts a closed-loop client, and logs its latency measurements, abandoned commands, and ticks.
+func startLoggedClient(t *testing.T, addr string, conf Config) (*Client, *eventLog) {
+	t.Helper()
+	cli, latencies, _ := newTestClient(conf, 1000)
+	log := &eventLog{}
+	cli.mods.EventLoop().RegisterHandler(LatencyMeasurementEvent{}, func(event interface{}) {
+		latencies <- event.(LatencyMeasurementEvent).Latency
+		log.add("latency")
+	})
+	cli.mods.EventLoop().RegisterHandler(CommandAbandonedEvent{}, func(_ interface{}) {
+		log.add("abandoned")
+	})
+	cli.mods.EventLoop().RegisterHandler(types.TickEvent{}, func(_ interface{}) {
+		log.add("tick")
+	})
+	if err := cli.Connect([]backend.ReplicaInfo{{ID: 1, Address: addr}}); err != nil {
+		t.Fatal(err)
+	}
+	cli.Start()
+	return cli, log
+}
+
+func TestStopDrainsCommands(t *testing.T) {
+	const delay = 300 * time.Millisecond
+	replica, addr := startFakeReplica(t, delay)
+	cli, log := startLoggedClient(t, addr, Config{
+		MaxConcurrent: 4,
+		RateLimit:     math.Inf(1),
+		DrainTimeout:  10 * delay,
+	})
+
+	// stop the client while the first commands are in flight.
+	time.Sleep(delay / 3)
+	inFlight := len(replica.received())
+	if inFlight == 0 {
+		t.Fatal("no commands were in flight")
+	}
+	start := time.Now()
+	cli.Stop()
+	if elapsed := time.Since(start); elapsed < delay/3 || elapsed > 5*delay {
+		t.Errorf("stop took %v, want it to wait for the commands in flight", elapsed)
+	}
+
+	events := log.get()
+	if completed, sent := log.count("latency"), len(replica.received()); completed != sent || completed < inFlight {
+		t.Errorf("%d of %d commands were recorded, want all of them", completed, sent)
+	}
+	if abandoned := log.count("abandoned"); abandoned != 0 {
+		t.Errorf("%d commands were abandoned, want 0", abandoned)
+	}
+	if len(events) == 0 || events[len(events)-1] != "tick" {
+		t.Errorf("the last event was not a tick: %v", events)
+	}
+
+	time.Sleep(delay)
+	if after := log.get(); len(after) != len(events) {
+		t.Errorf("%d events were emitted after the client stopped", len(after)-len(events))
+	}
+}
+
+func TestStopAbandonsCommands(t *testing.T) {
+	const drainTimeout = 100 * time.Millisecond
+	// the replica does not reply until the test is done.
+	replica, addr := startFakeReplica(t, time.Hour)
+	cli, log := startLoggedClient(t, addr, Config{
+		MaxConcurrent: 4,
+		RateLimit:     math.Inf(1),
+		DrainTimeout:  drainTimeout,
+	})
+
+	time.Sleep(200 * time.Millisecond)
+	start := time.Now()
+	cli.Stop()
+	if elapsed := time.Since(start); elapsed < drainTimeout || elapsed > 10*drainTimeout {
+		t.Errorf("stop took %v, want the drain timeout %v", elapsed, drainTimeout)
+	}
+
+	events := log.get()
+	if sent, abandoned := len(replica.received()), log.count("abandoned"); sent == 0 || abandoned != sent {
+		t.Errorf("%d of %d commands were abandoned, want all of them", abandoned, sent)
+	}
+	if completed := log.count("latency"); completed != 0 {
+		t.Errorf("%d abandoned commands were recorded", completed)
+	}
+	if len(events) == 0 || events[len(events)-1] != "tick" {
+		t.Errorf("the last event was not a tick: %v", events)
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	const stepDuration = 400 * time.Millisecond
+	rates := []float64{100, 300, 200}
+	profile := LoadProfile{}
+	for _, rate := range rates {
+		profile.Steps = append(profile.Steps, LoadStep{Duration: stepDuration, Rate: rate})
+	}
+
+	replica, addr := startFakeReplica(t, time.Millisecond)
+	cli, _, _ := newTestClient(Config{
+		MaxConcurrent: 100,
+		LoadMode:      FixedLoad,
+		LoadProfile:   profile,
+	}, 10000)
+	var (
+		mut      sync.Mutex
+		reported []TargetRateEvent
+	)
+	cli.mods.EventLoop().RegisterHandler(TargetRateEvent{}, func(event interface{}) {
+		mut.Lock()
+		reported = append(reported, event.(TargetRateEvent))
+		mut.Unlock()
+	})
+	if err := cli.Connect([]backend.ReplicaInfo{{ID: 1, Address: addr}}); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	cli.Start()
+
+	// the client stops issuing commands after the last step.
+	select {
+	case <-cli.done:
+	case <-time.After(time.Duration(len(rates)+5) * stepDuration):
+		t.Fatal("the client did not stop after the load profile")
+	}
+	cli.Stop()
+
+	counts := make([]int, len(rates))
+	for _, arrival := range replica.received() {
+		if step := int(arrival.Sub(start) / stepDuration); step < len(rates) {
+			counts[step]++
+		}
+	}
+	for i, rate := range rates {
+		want := rate * stepDuration.Seconds()
+		if got := float64(counts[i]); math.Abs(got-want) > 0.2*want {
+			t.Errorf("step %d issued %.0f commands, want %.0f", i+1, got, want)
+		}
+	}
+
+	mut.Lock()
+	defer mut.Unlock()
+	want := []TargetRateEvent{{Session: 1, Rate: 100, Step: 1}, {Session: 1, Rate: 300, Step: 2}, {Session: 1, Rate: 200, Step: 3}}
+	if !reflect.DeepEqual(reported, want) {
+		t.Errorf("reported target rates %v, want %v", reported, want)
+	}
+}
+
+func TestExecCommandBatchQuorumFunction(t *testing.T) {
+	batch := &clientpb.Batch{Commands: []*clientpb.Command{{SequenceNumber: 1}, {SequenceNumber: 2}}}
+	reply := func(hashes ...string) *clientpb.BatchResponse {
+		resp := &clientpb.BatchResponse{}
+		for _, hash := range hashes {
+			resp.Responses = append(resp.Responses, &clientpb.CommandResponse{BlockHash: []byte(hash)})
+		}
+		return resp
+	}
+	q := &qspec{faulty: 1}
+
+	// the replies must agree on the blocks of all of the commands.
+	replies := map[uint32]*clientpb.BatchResponse{1: reply("a", "b"), 2: reply("a", "c")}
+	if _, ok := q.ExecCommandBatchQF(batch, replies); ok {
+		t.Error("quorum with different blocks for the second command")
+	}
+	replies[3] = reply("a")
+	if _, ok := q.ExecCommandBatchQF(batch, replies); ok {
+		t.Error("quorum with a reply that does not respond to every command")
+	}
+	replies[4] = reply("a", "c")
+	if resp, ok := q.ExecCommandBatchQF(batch, replies); !ok || string(resp.GetResponses()[1].GetBlockHash()) != "c" {
+		t.Error("no quorum with f+1 matching replies")
+	}
+
+	first := &qspec{faulty: 1, ackMode: AckFirst}
+	if _, ok := first.ExecCommandBatchQF(batch, map[uint32]*clientpb.BatchResponse{1: reply("a")}); ok {
+		t.Error("the first mode accepted an incomplete reply")
+	}
+	if _, ok := first.ExecCommandBatchQF(batch, map[uint32]*clientpb.BatchResponse{1: reply("a", "b")}); !ok {
+		t.Error("the first mode did not accept the first complete reply")
+	}
+}
+
+func TestBatchLatencyFromSubmission(t *testing.T) {
+	const (
+		batchSize = 5
+		interval  = 20 * time.Millisecond
+	)
+	replica, addr := startFakeReplica(t, 0)
+	_, latencies, _ := startClient(t, addr, Config{
+		MaxConcurrent: 100,
+		LoadMode:      FixedLoad,
+		TargetRate:    float64(time.Second / interval),
+		BatchSize:     batchSize,
+		BatchTimeout:  time.Second,
+	}, 2*batchSize)
+
+	// the first command of each batch waits for the others, which is included in its latency.
+	var got []time.Duration
+	for i := 0; i < 2*batchSize; i++ {
+		select {
+		case latency := <-latencies:
+			got = append(got, latency)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d of %d commands were executed", i, 2*batchSize)
+		}
+	}
+	if batches := replica.receivedBatches(); batches != 2 {
+		t.Errorf("replica received %d batches, want 2", batches)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	if min, max := got[0], got[len(got)-1]; min > interval || max < (batchSize-1)*interval*3/4 {
+		t.Errorf("latencies were between %v and %v, want them to include the time waiting for the batch", min, max)
+	}
+}
+
+func benchmarkCommands(b *testing.B, batchSize uint32) {
+	replica := &fakeReplica{release: make(chan struct{})}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Fatal(err)
+	}
+	srv := gorums.NewServer()
+	clientpb.RegisterClientServer(srv, replica)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	cli, _, _ := newTestClient(Config{MaxConcurrent: 256, RateLimit: math.Inf(1), BatchSize: batchSize}, 64*(b.N+256))
+	cli.sessions[0].payloadSizes = &payloadSizes{size: 64}
+	var (
+		executed int
+		done     = make(chan struct{})
+	)
+	cli.mods.EventLoop().RegisterHandler(LatencyMeasurementEvent{}, func(_ interface{}) {
+		if executed++; executed == b.N {
+			close(done)
+		}
+	})
+	if err := cli.Connect([]backend.ReplicaInfo{{ID: 1, Address: lis.Addr().String()}}); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	cli.Start()
+	<-done
+	b.StopTimer()
+	cli.Stop()
+}
+
+// BenchmarkCommands measures the throughput of a client with 64-byte payloads, with and without batching.
+func BenchmarkCommands(b *testing.B) {
+	for _, batchSize := range []uint32{1, 16} {
+		b.Run(fmt.Sprintf("batch=%d", batchSize), func(b *testing.B) {
+			benchmarkCommands(b, batchSize)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+
+	"github.com/relab/hotstuff/metrics/types"
+)
+
+// histogramPrecision is the number of significant bits with which the latencies are recorded.
+// The relative error of a recorded latency is at most 2^-(histogramPrecision-1), which is less than 1%.
+const histogramPrecision = 8
+
+const (
+	subBuckets     = 1 << histogramPrecision
+	halfSubBuckets = subBuckets / 2
+)
+
+// histogram records latencies in buckets whose width grows with the latency, like an HDR histogram,
+// such that the relative error is bounded and the number of buckets grows with the logarithm of the largest latency.
+type histogram struct {
+	mut      sync.Mutex
+	counts   []uint64
+	count    uint64
+	min, max time.Duration
+}
+
+// bucketIndex returns the index of the bucket that holds the value.
+// Values below subBuckets have their own bucket, and the buckets double in width for each power of two above that.
+func bucketIndex(v uint64) int {
+	if v < subBuckets {
+		return int(v)
+	}
+	shift := bits.Len64(v) - histogramPrecision
+	return subBuckets + (shift-1)*halfSubBuckets + int(v>>shift) - halfSubBuckets
+}
+
+// bucketUpperBound returns the highest value held by the bucket with the given index.
+func bucketUpperBound(i int) uint64 {
+	if i < subBuckets {
+		return uint64(i)
+	}
+	shift := (i-subBuckets)/halfSubBuckets + 1
+	m := uint64((i-subBuckets)%halfSubBuckets + halfSubBuckets)
+	return (m+1)<<shift - 1
+}
+
+// record adds a latency to the histogram.
+func (h *histogram) record(latency time.Duration) {
+	if latency < 0 {
+		latency = 0
+	}
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	i := bucketIndex(uint64(latency))
+	if i >= len(h.counts) {
+		counts := make([]uint64, i+1)
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+	h.counts[i]++
+	if h.count == 0 || latency < h.min {
+		h.min = latency
+	}
+	if latency > h.max {
+		h.max = latency
+	}
+	h.count++
+}
+
+// percentile returns the latency below which the given percentage of the recorded latencies fall.
+func (h *histogram) percentile(p float64) time.Duration {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	if h.count == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(p / 100 * float64(h.count)))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen uint64
+	for i, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			if latency := time.Duration(bucketUpperBound(i)); latency < h.max {
+				return latency
+			}
+			break
+		}
+	}
+	return h.max
+}
+
+// toProto returns the histogram as a LatencyHistogram with the latencies in milliseconds.
+func (h *histogram) toProto(event *types.Event) *types.LatencyHistogram {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	msg := &types.LatencyHistogram{
+		Event: event,
+		Count: h.count,
+		Min:   float64(h.min) / float64(time.Millisecond),
+		Max:   float64(h.max) / float64(time.Millisecond),
+	}
+	for i, count := range h.counts {
+		if count > 0 {
+			msg.Buckets = append(msg.Buckets, &types.HistogramBucket{
+				UpperBound: float64(bucketUpperBound(i)) / float64(time.Millisecond),
+				Count:      count,
+			})
+		}
+	}
+	return msg
+}
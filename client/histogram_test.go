@@ -0,0 +1,64 @@
+package client
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestBucketBounds(t *testing.T) {
+	for _, v := range []uint64{0, 1, subBuckets - 1, subBuckets, subBuckets + 1, 1000, 123456789, math.MaxUint64 >> 1} {
+		i := bucketIndex(v)
+		if upper := bucketUpperBound(i); v > upper {
+			t.Errorf("value %d is above the upper bound %d of its bucket", v, upper)
+		}
+		if i > 0 && v <= bucketUpperBound(i-1) {
+			t.Errorf("value %d is not above the upper bound %d of the previous bucket", v, bucketUpperBound(i-1))
+		}
+		if i >= subBuckets && float64(bucketUpperBound(i)-v) > float64(v)/halfSubBuckets {
+			t.Errorf("upper bound %d of the bucket of %d exceeds the relative error", bucketUpperBound(i), v)
+		}
+	}
+}
+
+func TestHistogramPercentiles(t *testing.T) {
+	const samples = 100000
+	rnd := rand.New(rand.NewSource(1))
+	var h histogram
+	raw := make([]time.Duration, samples)
+	for i := range raw {
+		// log-normally distributed latencies with a median of 5 ms and a long tail.
+		raw[i] = time.Duration(math.Exp(rnd.NormFloat64()) * float64(5*time.Millisecond))
+		h.record(raw[i])
+	}
+	sort.Slice(raw, func(i, j int) bool { return raw[i] < raw[j] })
+
+	for _, p := range []float64{0, 50, 90, 99, 99.9, 100} {
+		rank := int(math.Ceil(p / 100 * samples))
+		if rank < 1 {
+			rank = 1
+		}
+		want := raw[rank-1]
+		got := h.percentile(p)
+		if math.Abs(float64(got-want))/float64(want) > 1.0/halfSubBuckets {
+			t.Errorf("p%v was %v, want %v", p, got, want)
+		}
+	}
+
+	msg := h.toProto(nil)
+	var count uint64
+	for i, bucket := range msg.GetBuckets() {
+		if i > 0 && bucket.GetUpperBound() <= msg.GetBuckets()[i-1].GetUpperBound() {
+			t.Fatal("buckets are not in increasing order")
+		}
+		count += bucket.GetCount()
+	}
+	if count != samples || msg.GetCount() != samples {
+		t.Errorf("histogram holds %d (%d) latencies, want %d", count, msg.GetCount(), samples)
+	}
+	if wantMax := float64(raw[samples-1]) / float64(time.Millisecond); msg.GetMax() != wantMax {
+		t.Errorf("maximum was %v ms, want %v ms", msg.GetMax(), wantMax)
+	}
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/relab/hotstuff/examples/kvstore"
+)
+
+// Workload determines what the commands sent by a client do.
+type Workload string
+
+const (
+	// OpaqueWorkload sends the payloads as they are, such that the replicas only order and hash them.
+	OpaqueWorkload Workload = "opaque"
+	// KVWorkload sends commands of the key-value store of the kvstore package, which the payloads are the values of.
+	KVWorkload Workload = "kv"
+)
+
+// ParseWorkload returns the workload with the given name. The empty string is the opaque workload.
+func ParseWorkload(name string) (Workload, error) {
+	switch workload := Workload(name); workload {
+	case "":
+		return OpaqueWorkload, nil
+	case OpaqueWorkload, KVWorkload:
+		return workload, nil
+	}
+	return "", fmt.Errorf("unknown workload '%s'", name)
+}
+
+// defaultKVKeys is the number of keys of the key-value workload if KVKeys is zero.
+const defaultKVKeys = 1000
+
+// ValidateKV returns an error if the options of the key-value workload are invalid.
+func (conf Config) ValidateKV() error {
+	if conf.KVReadRatio < 0 || conf.KVDeleteRatio < 0 || conf.KVReadRatio+conf.KVDeleteRatio > 1 {
+		return fmt.Errorf("invalid key-value workload ratios: read %v and delete %v must be non-negative and sum to at most 1",
+			conf.KVReadRatio, conf.KVDeleteRatio)
+	}
+	return nil
+}
+
+// kvWorkload turns payloads into commands of the key-value store.
+// Each command reads, deletes, or sets a key drawn uniformly from the key space,
+// and every checkpointInterval'th command is a checkpoint.
+type kvWorkload struct {
+	rnd                *rand.Rand
+	keys               int
+	readRatio          float64
+	deleteRatio        float64
+	checkpointInterval uint32
+	count              uint32
+}
+
+// newKVWorkload returns a generator for the key-value workload configured by conf.
+// The commands are generated deterministically from conf.PayloadSeed.
+func newKVWorkload(conf Config) *kvWorkload {
+	keys := int(conf.KVKeys)
+	if keys <= 0 {
+		keys = defaultKVKeys
+	}
+	return &kvWorkload{
+		rnd:                rand.New(rand.NewSource(conf.PayloadSeed)),
+		keys:               keys,
+		readRatio:          conf.KVReadRatio,
+		deleteRatio:        conf.KVDeleteRatio,
+		checkpointInterval: conf.KVCheckpointInterval,
+	}
+}
+
+// next returns the next command, which sets its key to the payload if it is a put.
+func (w *kvWorkload) next(payload []byte) []byte {
+	w.count++
+	if w.checkpointInterval > 0 && w.count%w.checkpointInterval == 0 {
+		return kvstore.Checkpoint()
+	}
+	key := "k" + strconv.Itoa(w.rnd.Intn(w.keys))
+	switch p := w.rnd.Float64(); {
+	case p < w.readRatio:
+		return kvstore.Get(key)
+	case p < w.readRatio+w.deleteRatio:
+		return kvstore.Delete(key)
+	}
+	return kvstore.Put(key, payload)
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/relab/hotstuff/examples/kvstore"
+)
+
+func TestKVWorkload(t *testing.T) {
+	const samples = 100000
+	conf := Config{
+		KVKeys:               10,
+		KVReadRatio:          0.3,
+		KVDeleteRatio:        0.1,
+		KVCheckpointInterval: 100,
+		PayloadSeed:          1,
+	}
+	w, same := newKVWorkload(conf), newKVWorkload(conf)
+	store := kvstore.New()
+	var gets, deletes, puts, checkpoints int
+	for i := 0; i < samples; i++ {
+		cmd := w.next([]byte("v"))
+		if !bytes.Equal(cmd, same.next([]byte("v"))) {
+			t.Fatal("the commands are not generated deterministically from the seed")
+		}
+		if err := store.Validate(cmd); err != nil {
+			t.Fatalf("generated invalid command %q: %v", cmd, err)
+		}
+		store.Execute(cmd)
+		switch {
+		case bytes.Equal(cmd, kvstore.Checkpoint()):
+			checkpoints++
+		case bytes.HasSuffix(cmd, []byte("v")):
+			puts++
+		default:
+			// gets and deletes of the same key differ only in the operation, which precedes the key.
+			for key := 0; key < int(conf.KVKeys); key++ {
+				k := "k" + strconv.Itoa(key)
+				if bytes.Equal(cmd, kvstore.Get(k)) {
+					gets++
+				} else if bytes.Equal(cmd, kvstore.Delete(k)) {
+					deletes++
+				}
+			}
+		}
+	}
+	if checkpoints != samples/int(conf.KVCheckpointInterval) {
+		t.Errorf("got %d checkpoints, want %d", checkpoints, samples/int(conf.KVCheckpointInterval))
+	}
+	ops := float64(samples - checkpoints)
+	for _, ratio := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"get", float64(gets) / ops, conf.KVReadRatio},
+		{"delete", float64(deletes) / ops, conf.KVDeleteRatio},
+		{"put", float64(puts) / ops, 1 - conf.KVReadRatio - conf.KVDeleteRatio},
+	} {
+		if math.Abs(ratio.got-ratio.want) > 0.01 {
+			t.Errorf("%s ratio was %.3f, want %.3f", ratio.name, ratio.got, ratio.want)
+		}
+	}
+	if len(store.Checkpoints()) != checkpoints {
+		t.Errorf("the store recorded %d checkpoints, want %d", len(store.Checkpoints()), checkpoints)
+	}
+}
+
+func TestValidateKV(t *testing.T) {
+	for _, conf := range []Config{
+		{KVReadRatio: -0.1},
+		{KVDeleteRatio: -0.1},
+		{KVReadRatio: 0.6, KVDeleteRatio: 0.5},
+	} {
+		if conf.ValidateKV() == nil {
+			t.Errorf("ratios read %v and delete %v should be invalid", conf.KVReadRatio, conf.KVDeleteRatio)
+		}
+	}
+	if err := (Config{KVReadRatio: 0.5, KVDeleteRatio: 0.5}).ValidateKV(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// SizeDistribution determines the sizes of the payloads of the commands sent by a client.
+type SizeDistribution string
+
+const (
+	// FixedSize gives every payload the size PayloadSize.
+	FixedSize SizeDistribution = "fixed"
+	// UniformSize draws the payload sizes uniformly from [PayloadMin, PayloadMax].
+	UniformSize SizeDistribution = "uniform"
+	// ZipfSize draws the payload sizes from [PayloadMin, PayloadMax] with a Zipf distribution with exponent PayloadZipfS,
+	// such that small payloads are common and large payloads are rare.
+	ZipfSize SizeDistribution = "zipf"
+)
+
+// ParseSizeDistribution returns the size distribution with the given name. The empty string is the fixed size distribution.
+func ParseSizeDistribution(name string) (SizeDistribution, error) {
+	switch dist := SizeDistribution(name); dist {
+	case "":
+		return FixedSize, nil
+	case FixedSize, UniformSize, ZipfSize:
+		return dist, nil
+	}
+	return "", fmt.Errorf("unknown payload size distribution '%s'", name)
+}
+
+// ValidatePayload returns an error if the payload size distribution is invalid.
+func (conf Config) ValidatePayload() error {
+	_, err := newPayloadSizes(conf)
+	return err
+}
+
+// payloadSizes generates payload sizes from a size distribution.
+type payloadSizes struct {
+	dist     SizeDistribution
+	size     uint32
+	min, max uint32
+	rnd      *rand.Rand
+	zipf     *rand.Zipf
+}
+
+// newPayloadSizes returns a generator for the payload sizes configured by conf.
+// The sizes are generated deterministically from conf.PayloadSeed.
+func newPayloadSizes(conf Config) (*payloadSizes, error) {
+	dist, err := ParseSizeDistribution(string(conf.PayloadDistribution))
+	if err != nil {
+		return nil, err
+	}
+	p := &payloadSizes{
+		dist: dist,
+		size: conf.PayloadSize,
+		min:  conf.PayloadMin,
+		max:  conf.PayloadMax,
+		rnd:  rand.New(rand.NewSource(conf.PayloadSeed)),
+	}
+	if dist != FixedSize && p.min > p.max {
+		return nil, fmt.Errorf("minimum payload size %d is greater than the maximum %d", p.min, p.max)
+	}
+	if dist == ZipfSize {
+		p.zipf = rand.NewZipf(p.rnd, conf.PayloadZipfS, 1, uint64(p.max-p.min))
+		if p.zipf == nil {
+			return nil, fmt.Errorf("invalid zipf exponent %v: must be greater than 1", conf.PayloadZipfS)
+		}
+	}
+	return p, nil
+}
+
+// next returns the size of the next payload.
+func (p *payloadSizes) next() uint32 {
+	switch p.dist {
+	case UniformSize:
+		return p.min + uint32(p.rnd.Int63n(int64(p.max-p.min)+1))
+	case ZipfSize:
+		return p.min + uint32(p.zipf.Uint64())
+	}
+	return p.size
+}
+
+// compressibleAlphabet is the set of bytes that compressible payloads are made of.
+// Each byte carries 4 bits of entropy, such that the payloads can be compressed to about half their size.
+const compressibleAlphabet = "abcdefghijklmnop"
+
+type payloadReader struct {
+	rnd          *rand.Rand
+	compressible bool
+}
+
+// NewPayloadReader returns a reader that generates payload bytes deterministically from the seed.
+// If compressible is true, the bytes are drawn from a small alphabet, such that the payloads can be compressed.
+// Otherwise, the bytes are uniformly random.
+func NewPayloadReader(seed int64, compressible bool) io.ReadCloser {
+	return &payloadReader{rnd: rand.New(rand.NewSource(seed)), compressible: compressible}
+}
+
+func (r *payloadReader) Read(p []byte) (int, error) {
+	if !r.compressible {
+		return r.rnd.Read(p)
+	}
+	for i := range p {
+		p[i] = compressibleAlphabet[r.rnd.Intn(len(compressibleAlphabet))]
+	}
+	return len(p), nil
+}
+
+func (r *payloadReader) Close() error {
+	return nil
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+const sizeSamples = 100000
+
+func generateSizes(t *testing.T, conf Config) []float64 {
+	t.Helper()
+	sizes, err := newPayloadSizes(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := make([]float64, sizeSamples)
+	for i := range samples {
+		size := sizes.next()
+		if conf.PayloadDistribution != FixedSize && (size < conf.PayloadMin || size > conf.PayloadMax) {
+			t.Fatalf("size %d is outside [%d, %d]", size, conf.PayloadMin, conf.PayloadMax)
+		}
+		samples[i] = float64(size)
+	}
+	return samples
+}
+
+func meanAndVariance(samples []float64) (mean, variance float64) {
+	mean, cv := meanAndCV(samples)
+	return mean, (cv * mean) * (cv * mean)
+}
+
+func TestFixedPayloadSize(t *testing.T) {
+	for _, size := range generateSizes(t, Config{PayloadDistribution: FixedSize, PayloadSize: 128}) {
+		if size != 128 {
+			t.Fatalf("got size %v, want 128", size)
+		}
+	}
+}
+
+func TestUniformPayloadSize(t *testing.T) {
+	const min, max = 64, 1024
+	mean, variance := meanAndVariance(generateSizes(t, Config{
+		PayloadDistribution: UniformSize,
+		PayloadMin:          min,
+		PayloadMax:          max,
+		PayloadSeed:         1,
+	}))
+	// the mean and variance of the discrete uniform distribution.
+	wantMean := (min + max) / 2.0
+	n := float64(max - min + 1)
+	wantVariance := (n*n - 1) / 12
+	if math.Abs(mean-wantMean)/wantMean > 0.01 {
+		t.Errorf("mean size was %.1f, want %.1f", mean, wantMean)
+	}
+	if math.Abs(variance-wantVariance)/wantVariance > 0.03 {
+		t.Errorf("size variance was %.1f, want %.1f", variance, wantVariance)
+	}
+}
+
+func TestZipfPayloadSize(t *testing.T) {
+	const (
+		min, max = 10, 10000
+		s        = 1.5
+	)
+	samples := generateSizes(t, Config{
+		PayloadDistribution: ZipfSize,
+		PayloadMin:          min,
+		PayloadMax:          max,
+		PayloadZipfS:        s,
+		PayloadSeed:         1,
+	})
+	counts := make(map[float64]int)
+	for _, size := range samples {
+		counts[size]++
+	}
+	// the probability of min+k is proportional to (k+1)^-s.
+	var norm float64
+	for k := 0; k <= max-min; k++ {
+		norm += math.Pow(float64(k+1), -s)
+	}
+	for k := 0; k < 3; k++ {
+		want := math.Pow(float64(k+1), -s) / norm
+		got := float64(counts[float64(min+k)]) / sizeSamples
+		if math.Abs(got-want) > 0.01 {
+			t.Errorf("frequency of size %d was %.3f, want %.3f", min+k, got, want)
+		}
+	}
+}
+
+func TestPayloadSizesAreDeterministic(t *testing.T) {
+	conf := Config{PayloadDistribution: UniformSize, PayloadMin: 0, PayloadMax: 1 << 20, PayloadSeed: 42}
+	a, b := generateSizes(t, conf), generateSizes(t, conf)
+	conf.PayloadSeed++
+	c := generateSizes(t, conf)
+	same := 0
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("sizes with the same seed differ at %d: %v != %v", i, a[i], b[i])
+		}
+		if a[i] == c[i] {
+			same++
+		}
+	}
+	if same == len(a) {
+		t.Error("sizes with different seeds are identical")
+	}
+}
+
+func TestInvalidPayloadDistribution(t *testing.T) {
+	tests := map[string]Config{
+		"unknown":    {PayloadDistribution: "normal"},
+		"min>max":    {PayloadDistribution: UniformSize, PayloadMin: 10, PayloadMax: 5},
+		"zipf s<=1":  {PayloadDistribution: ZipfSize, PayloadMin: 1, PayloadMax: 10, PayloadZipfS: 1},
+		"zipf unset": {PayloadDistribution: ZipfSize, PayloadMin: 1, PayloadMax: 10},
+	}
+	for name, conf := range tests {
+		if err := conf.ValidatePayload(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func compressedRatio(t *testing.T, data []byte) float64 {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write(data)
+	_ = w.Close()
+	return float64(buf.Len()) / float64(len(data))
+}
+
+func TestPayloadReader(t *testing.T) {
+	read := func(seed int64, compressible bool) []byte {
+		data := make([]byte, 1<<16)
+		if _, err := io.ReadFull(NewPayloadReader(seed, compressible), data); err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	random := read(1, false)
+	if !bytes.Equal(random, read(1, false)) {
+		t.Error("payloads with the same seed differ")
+	}
+	if bytes.Equal(random, read(2, false)) {
+		t.Error("payloads with different seeds are identical")
+	}
+	if ratio := compressedRatio(t, random); ratio < 0.95 {
+		t.Errorf("random payload was compressed to %.2f of its size", ratio)
+	}
+
+	compressible := read(1, true)
+	if strings.Trim(string(compressible), compressibleAlphabet) != "" {
+		t.Error("compressible payload contains bytes outside the alphabet")
+	}
+	if ratio := compressedRatio(t, compressible); ratio > 0.6 {
+		t.Errorf("compressible payload was compressed to %.2f of its size", ratio)
+	}
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadStep is a step of a load profile.
+type LoadStep struct {
+	Duration time.Duration // how long the step lasts
+	Rate     float64       // commands per second at the end of the step
+}
+
+// LoadProfile varies the rate at which an open-loop client issues commands, such that a single run can measure
+// several load levels. The steps are run in order, and the client stops issuing commands after the last step.
+// If Ramp is false, the rate of each step is constant. If Ramp is true, the rate changes linearly during each step,
+// from the rate of the previous step to the rate of the step. The first step keeps its rate, such that a ramp
+// between two rates is a step of zero duration at the first rate followed by a step at the second rate.
+type LoadProfile struct {
+	Steps []LoadStep
+	Ramp  bool
+}
+
+// ParseLoadProfile parses a comma separated list of steps, such as "10s:100,10s:200",
+// where each step is given by its duration and rate, separated by a colon.
+func ParseLoadProfile(profile string, ramp bool) (LoadProfile, error) {
+	p := LoadProfile{Ramp: ramp}
+	for i, step := range strings.Split(profile, ",") {
+		fields := strings.Split(strings.TrimSpace(step), ":")
+		if len(fields) != 2 {
+			return LoadProfile{}, fmt.Errorf("load step %d: expected 'duration:rate', got '%s'", i+1, step)
+		}
+		duration, err := time.ParseDuration(fields[0])
+		if err != nil {
+			return LoadProfile{}, fmt.Errorf("load step %d: invalid duration: %w", i+1, err)
+		}
+		rate, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return LoadProfile{}, fmt.Errorf("load step %d: invalid rate: %w", i+1, err)
+		}
+		p.Steps = append(p.Steps, LoadStep{Duration: duration, Rate: rate})
+	}
+	if err := p.Validate(); err != nil {
+		return LoadProfile{}, err
+	}
+	return p, nil
+}
+
+// Validate returns an error if a step has a negative duration or rate, or if no commands would be issued.
+func (p LoadProfile) Validate() error {
+	var total float64
+	for i, step := range p.Steps {
+		if step.Duration < 0 {
+			return fmt.Errorf("load step %d: negative duration %v", i+1, step.Duration)
+		}
+		if step.Rate < 0 || math.IsNaN(step.Rate) || math.IsInf(step.Rate, 0) {
+			return fmt.Errorf("load step %d: invalid rate %v", i+1, step.Rate)
+		}
+		total += step.Duration.Seconds() * step.Rate
+	}
+	if total == 0 {
+		return fmt.Errorf("the load profile does not issue any commands")
+	}
+	return nil
+}
+
+// segment returns the step at the given time since the start of the profile, the rate at that time,
+// the change of the rate per second, and the time at which the step ends. It returns false if the profile has ended.
+func (p LoadProfile) segment(elapsed time.Duration) (step int, rate, slope float64, end time.Duration, ok bool) {
+	var start time.Duration
+	for i, s := range p.Steps {
+		end = start + s.Duration
+		if elapsed < end {
+			if !p.Ramp || i == 0 {
+				return i, s.Rate, 0, end, true
+			}
+			prev := p.Steps[i-1].Rate
+			slope = (s.Rate - prev) / s.Duration.Seconds()
+			return i, prev + slope*(elapsed-start).Seconds(), slope, end, true
+		}
+		start = end
+	}
+	return 0, 0, 0, 0, false
+}
+
+// profileSchedule issues commands with an arrival process whose rate follows a load profile. Each command is due
+// when the integral of the rate since the previous command reaches a unit drawn from the arrival process,
+// which is one with fixed intervals, and exponentially distributed with poisson arrivals.
+// The time of each command is computed from the profile rather than read from the clock, such that the offered load
+// follows the profile even if the client falls behind. The report function is called when the step changes,
+// or when a ramp has changed the rate by more than a percent since it was last reported.
+type profileSchedule struct {
+	profile  LoadProfile
+	arrivals *arrivalProcess
+	sizes    *payloadSizes
+	elapsed  time.Duration // the time of the previous command since the start of the profile
+	report   func(rate float64, step int)
+	lastRate float64
+	lastStep int
+}
+
+func newProfileSchedule(profile LoadProfile, arrivals *arrivalProcess, sizes *payloadSizes, report func(float64, int)) *profileSchedule {
+	return &profileSchedule{profile: profile, arrivals: arrivals, sizes: sizes, report: report, lastStep: -1}
+}
+
+func (s *profileSchedule) next() (time.Duration, uint32, string, bool) {
+	prev := s.elapsed
+	work := s.arrivals.unit()
+	for {
+		step, rate, slope, end, ok := s.profile.segment(s.elapsed)
+		if !ok {
+			return 0, 0, "", false
+		}
+		// the number of commands that are due before the end of the step.
+		remaining := (end - s.elapsed).Seconds()
+		due := rate*remaining + slope*remaining*remaining/2
+		if due == 0 || due < work {
+			work -= due
+			s.elapsed = end
+			continue
+		}
+		// solve rate*x + slope*x*x/2 = work for the time x until the command is due.
+		x := work / rate
+		if slope != 0 {
+			x = (math.Sqrt(math.Max(0, rate*rate+2*slope*work)) - rate) / slope
+		}
+		s.elapsed += time.Duration(x * float64(time.Second))
+		if rate += slope * x; step != s.lastStep || math.Abs(rate-s.lastRate) > s.lastRate/100 {
+			s.report(rate, step)
+			s.lastRate, s.lastStep = rate, step
+		}
+		return s.elapsed - prev, s.sizes.next(), "", true
+	}
+}
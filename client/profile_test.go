@@ -0,0 +1,83 @@
+package client
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLoadProfile(t *testing.T) {
+	profile, err := ParseLoadProfile("10s:100, 500ms:0,1m:2.5", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LoadProfile{
+		Steps: []LoadStep{
+			{Duration: 10 * time.Second, Rate: 100},
+			{Duration: 500 * time.Millisecond, Rate: 0},
+			{Duration: time.Minute, Rate: 2.5},
+		},
+		Ramp: true,
+	}
+	if !reflect.DeepEqual(profile, want) {
+		t.Errorf("got %v, want %v", profile, want)
+	}
+
+	for _, invalid := range []string{"", "10s", "10s:100:1", "soon:100", "10s:many", "-1s:100", "10s:-100", "10s:0,0s:100"} {
+		if _, err := ParseLoadProfile(invalid, false); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+// countSteps counts the commands of a profile schedule that fall in each step, as well as the reported steps.
+func countSteps(profile LoadProfile, buckets []time.Duration) (counts []int, reported []int) {
+	counts = make([]int, len(buckets))
+	sched := newProfileSchedule(profile, newArrivalProcess(FixedLoad, 1, rand.New(rand.NewSource(1))), &payloadSizes{size: 1},
+		func(_ float64, step int) { reported = append(reported, step) })
+	var elapsed time.Duration
+	for {
+		interval, _, _, ok := sched.next()
+		if !ok {
+			return counts, reported
+		}
+		elapsed += interval
+		for i, end := range buckets {
+			if elapsed <= end {
+				counts[i]++
+				break
+			}
+		}
+	}
+}
+
+func TestProfileSchedule(t *testing.T) {
+	steps := LoadProfile{Steps: []LoadStep{
+		{Duration: time.Second, Rate: 100},
+		{Duration: time.Second, Rate: 0},
+		{Duration: time.Second, Rate: 400},
+	}}
+	counts, reported := countSteps(steps, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second})
+	for i, want := range []int{100, 0, 400} {
+		if math.Abs(float64(counts[i]-want)) > 1 {
+			t.Errorf("step %d issued %d commands, want %d", i+1, counts[i], want)
+		}
+	}
+	// no commands are issued in the step with the zero rate, so it is not reported.
+	// The commands that are due at the end of a step belong to that step.
+	if !reflect.DeepEqual(reported, []int{0, 2}) {
+		t.Errorf("reported steps %v, want [0 2]", reported)
+	}
+
+	// the rate ramps from 0 to 1000 in one second, such that a quarter of the commands are issued in the first half.
+	ramp := LoadProfile{Steps: []LoadStep{{Duration: 0, Rate: 0}, {Duration: time.Second, Rate: 1000}}, Ramp: true}
+	counts, reported = countSteps(ramp, []time.Duration{500 * time.Millisecond, time.Second})
+	if math.Abs(float64(counts[0]-125)) > 5 || math.Abs(float64(counts[1]-375)) > 5 {
+		t.Errorf("the ramp issued %v commands in each half, want [125 375]", counts)
+	}
+	if len(reported) < 100 {
+		t.Errorf("the rate of the ramp was reported %d times, want it to be reported as it changes", len(reported))
+	}
+}
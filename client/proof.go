@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/crypto"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrInvalidProof is returned by VerifyProof if the proof does not show that the command was committed.
+var ErrInvalidProof = errors.New("invalid commit proof")
+
+// VerifyProof checks that the proof shows that the command was committed. The quorum certificates of the proof are
+// verified with the public keys of the replicas, using the given crypto implementation, which must be the one used
+// by the replicas, such as ecdsa.New(). chainLength is the number of blocks that the consensus protocol of the
+// replicas needs to chain together in order to commit.
+func VerifyProof(proof *clientpb.CommitProof, cmd *clientpb.Command, replicas []backend.ReplicaInfo, impl consensus.CryptoImpl, chainLength int) error {
+	if chainLength < 1 || len(proof.GetBlocks()) < chainLength {
+		return fmt.Errorf("%w: expected at least %d blocks, got %d", ErrInvalidProof, chainLength, len(proof.GetBlocks()))
+	}
+	chain := make([]*consensus.Block, 0, len(proof.GetBlocks()))
+	for i, b := range proof.GetBlocks() {
+		block, err := hotstuffpb.BlockFromProto(b)
+		if err != nil {
+			return fmt.Errorf("%w: block %d: %v", ErrInvalidProof, i, err)
+		}
+		chain = append(chain, block)
+	}
+
+	var hash consensus.Hash
+	copy(hash[:], proof.GetBlockHash())
+	if chain[0].Hash() != hash {
+		return fmt.Errorf("%w: the first block does not match the block hash", ErrInvalidProof)
+	}
+	batch := new(clientpb.Batch)
+	err := proto.UnmarshalOptions{AllowPartial: true}.Unmarshal([]byte(chain[0].Command()), batch)
+	if err != nil {
+		return fmt.Errorf("%w: failed to unmarshal batch: %v", ErrInvalidProof, err)
+	}
+	if int(proof.GetPosition()) >= len(batch.GetCommands()) ||
+		!sameCommand(batch.GetCommands()[proof.GetPosition()], cmd) {
+		return fmt.Errorf("%w: the command is not at position %d of the block", ErrInvalidProof, proof.GetPosition())
+	}
+
+	for i := 1; i < len(chain); i++ {
+		if chain[i].Parent() != chain[i-1].Hash() {
+			return fmt.Errorf("%w: block %d does not extend block %d", ErrInvalidProof, i, i-1)
+		}
+	}
+
+	// the last blocks must be certified by the QC of the next block, or by the QC of the proof.
+	verifier := newProofVerifier(replicas, impl)
+	for i := len(chain) - chainLength; i < len(chain); i++ {
+		var qc consensus.QuorumCert
+		if i == len(chain)-1 {
+			if proof.GetQC() == nil {
+				return fmt.Errorf("%w: missing QC", ErrInvalidProof)
+			}
+			var err error
+			qc, err = hotstuffpb.QuorumCertFromProto(proof.GetQC())
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+			}
+		} else {
+			qc = chain[i+1].QuorumCert()
+		}
+		if qc.BlockHash() != chain[i].Hash() {
+			return fmt.Errorf("%w: block %d is not certified", ErrInvalidProof, i)
+		}
+		if !verifier.VerifyQuorumCert(qc) {
+			return fmt.Errorf("%w: invalid QC for block %d", ErrInvalidProof, i)
+		}
+	}
+	return nil
+}
+
+// sameCommand returns true if the commands have the same client, sequence number, and data.
+func sameCommand(a, b *clientpb.Command) bool {
+	return a.GetClientID() == b.GetClientID() &&
+		a.GetSequenceNumber() == b.GetSequenceNumber() &&
+		string(a.GetData()) == string(b.GetData())
+}
+
+// newProofVerifier returns a crypto module that verifies signatures with the public keys of the replicas.
+func newProofVerifier(replicas []backend.ReplicaInfo, impl consensus.CryptoImpl) consensus.Crypto {
+	config := make(proofConfig, len(replicas))
+	for _, r := range replicas {
+		config[r.ID] = proofReplica{id: r.ID, pubKey: r.PubKey}
+	}
+	verifier := crypto.New(impl)
+	builder := consensus.NewBuilder(0, nil)
+	builder.Register(config, verifier)
+	builder.Build()
+	return verifier
+}
+
+// proofConfig is a configuration that only knows the public keys of the replicas,
+// which is all that is needed to verify quorum certificates.
+type proofConfig map[hotstuff.ID]consensus.Replica
+
+// Replicas returns all of the replicas in the configuration.
+func (cfg proofConfig) Replicas() map[hotstuff.ID]consensus.Replica {
+	return cfg
+}
+
+// Replica returns a replica if present in the configuration.
+func (cfg proofConfig) Replica(id hotstuff.ID) (consensus.Replica, bool) {
+	r, ok := cfg[id]
+	return r, ok
+}
+
+// Len returns the number of replicas in the configuration.
+func (cfg proofConfig) Len() int {
+	return len(cfg)
+}
+
+// QuorumSize returns the size of a quorum.
+func (cfg proofConfig) QuorumSize() int {
+	return hotstuff.QuorumSize(len(cfg))
+}
+
+// Propose does nothing.
+func (cfg proofConfig) Propose(consensus.ProposeMsg) {}
+
+// Timeout does nothing.
+func (cfg proofConfig) Timeout(consensus.TimeoutMsg) {}
+
+// Fetch does nothing.
+func (cfg proofConfig) Fetch(context.Context, consensus.Hash) (*consensus.Block, bool) {
+	return nil, false
+}
+
+// proofReplica is a replica that is only used for its public key.
+type proofReplica struct {
+	id     hotstuff.ID
+	pubKey consensus.PublicKey
+}
+
+// ID returns the replica's id.
+func (r proofReplica) ID() hotstuff.ID {
+	return r.id
+}
+
+// PublicKey returns the replica's public key.
+func (r proofReplica) PublicKey() consensus.PublicKey {
+	return r.pubKey
+}
+
+// Vote does nothing.
+func (r proofReplica) Vote(consensus.PartialCert) {}
+
+// NewView does nothing.
+func (r proofReplica) NewView(consensus.SyncInfo) {}
@@ -0,0 +1,491 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/examples/kvstore"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"golang.org/x/time/rate"
+)
+
+// session is a logical client with its own client ID, sequence numbers, and connections to the replicas.
+// The sessions of a client share its configuration, modules, and input.
+type session struct {
+	*Client
+	mut              sync.Mutex
+	id               hotstuff.ID
+	lastSeq          uint64 // the last sequence number used, accessed atomically
+	mgr              *clientpb.Manager
+	gorumsConfig     *clientpb.Configuration
+	payloadSizes     *payloadSizes
+	kv               *kvWorkload // turns the payloads into key-value commands; nil with the opaque workload
+	highestCommitted uint64      // highest sequence number acknowledged by the replicas
+	pendingCmds      chan pendingCmd
+	limiter          *rate.Limiter
+	inFlight         chan struct{} // limits the number of commands in flight in open-loop mode
+	window           *window       // adapts the number of commands in flight in closed-loop mode, if enabled
+	latencies        histogram     // the latencies of all commands, which are written when the client stops
+	batcher          *batcher      // collects the commands into batches, if batching is enabled
+	seed             int64         // seed for the arrival times in open-loop mode
+}
+
+// newSession returns the session with the given index. The session uses the client ID conf.ID+index,
+// and its payload sizes and arrival times are generated from conf.PayloadSeed+index and conf.Seed+index,
+// such that the sessions differ.
+func newSession(client *Client, conf Config, index int, mgr *clientpb.Manager) *session {
+	s := &session{
+		Client:           client,
+		id:               conf.ID + hotstuff.ID(index),
+		mgr:              mgr,
+		highestCommitted: 1,
+		pendingCmds:      make(chan pendingCmd, conf.MaxConcurrent),
+		limiter:          rate.NewLimiter(rate.Limit(conf.RateLimit), 1),
+		inFlight:         make(chan struct{}, conf.MaxConcurrent),
+	}
+	if conf.TargetLatency > 0 && !conf.LoadMode.IsOpenLoop() {
+		s.window = newWindow(conf.MinConcurrent, conf.MaxConcurrent, conf.TargetLatency)
+	}
+	conf.PayloadSeed += int64(index)
+	s.seed = conf.Seed + int64(index)
+	if conf.Seed == 0 {
+		s.seed = time.Now().UnixNano()
+	}
+	var err error
+	s.payloadSizes, err = newPayloadSizes(conf)
+	if err != nil {
+		s.payloadSizes = &payloadSizes{dist: FixedSize, size: conf.PayloadSize}
+	}
+	if conf.Workload == KVWorkload {
+		if conf.ValidateKV() != nil {
+			conf.KVReadRatio, conf.KVDeleteRatio = 0, 0
+		}
+		s.kv = newKVWorkload(conf)
+	}
+	return s
+}
+
+// run sends commands until ctx is closed or the input ends, and then waits for the commands in flight.
+// The commands are sent with callCtx, such that the commands in flight are abandoned when callCtx is closed.
+func (s *session) run(ctx, callCtx context.Context) {
+	if s.batchSize > 1 {
+		s.batcher = newBatcher(s.batchSize, s.batchTimeout, func(batch *clientpb.Batch) *clientpb.AsyncBatchResponse {
+			return s.sendBatch(callCtx, batch)
+		}, func(size int) {
+			s.mods.EventLoop().AddEvent(BatchSentEvent{Session: s.id, Size: size})
+		})
+	}
+	if s.loadMode.IsOpenLoop() {
+		s.runOpenLoop(ctx, callCtx)
+	} else {
+		s.runClosedLoop(ctx, callCtx)
+	}
+}
+
+func (s *session) close() {
+	s.mgr.Close()
+}
+
+func (s *session) runClosedLoop(ctx, callCtx context.Context) {
+	commandStatsChan := make(chan commandStats)
+	// start the command handler
+	go func() {
+		commandStatsChan <- s.handleCommands(callCtx)
+	}()
+
+	err := s.sendCommands(ctx, callCtx)
+	close(s.pendingCmds)
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.mods.Logger().Panicf("Failed to send commands: %v", err)
+	}
+
+	stats := <-commandStatsChan
+	s.close()
+	s.mods.Logger().Infof("Session %d done sending commands (executed: %d, failed: %d, abandoned: %d)",
+		s.id, stats.executed, stats.failed, stats.abandoned)
+}
+
+// commandStats counts the outcomes of the commands of a session.
+type commandStats struct {
+	executed, failed, abandoned int
+}
+
+func (stats *commandStats) add(err error) {
+	switch {
+	case err == nil:
+		stats.executed++
+	case isCanceled(err):
+		stats.abandoned++
+	default:
+		stats.failed++
+	}
+}
+
+func (s *session) sendCommands(ctx, callCtx context.Context) error {
+	var (
+		lastCommand uint64 = math.MaxUint64
+		lastStep           = time.Now()
+	)
+
+	for {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// step up the rate limiter
+		now := time.Now()
+		if now.Sub(lastStep) > s.stepUpInterval {
+			s.limiter.SetLimit(s.limiter.Limit() + rate.Limit(s.stepUp))
+			lastStep = now
+		}
+
+		err := s.limiter.Wait(ctx)
+		if errors.Is(err, context.Canceled) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// annoyingly, we need a mutex here to prevent the data race detector from complaining.
+		s.mut.Lock()
+		shouldStop := lastCommand <= s.highestCommitted
+		s.mut.Unlock()
+
+		if shouldStop {
+			break
+		}
+
+		if s.window != nil && s.window.acquire(ctx) != nil {
+			break
+		}
+
+		data := make([]byte, s.payloadSizes.next())
+		n, err := s.reader.Read(data)
+		if err != nil && err != io.EOF {
+			// if we get an error other than EOF
+			return err
+		}
+		num := s.nextSequenceNumber()
+		if err == io.EOF && n == 0 && lastCommand > num {
+			lastCommand = num
+			s.mods.Logger().Info("Reached end of file. Sending empty commands until last command is executed...")
+		}
+
+		cmd := &clientpb.Command{
+			ClientID:       uint32(s.id),
+			SequenceNumber: num,
+			Data:           s.command(data[:n]),
+			Tag:            s.tag(num),
+		}
+
+		sendTime := time.Now()
+		promise, cancel := s.submitCommand(callCtx, cmd)
+		// the command handler takes the commands until the channel is closed, so this does not block for long.
+		s.pendingCmds <- pendingCmd{sequenceNumber: num, sendTime: sendTime, promise: promise, cmd: cmd, cancel: cancel}
+
+		if num%100 == 0 {
+			s.mods.Logger().Infof("%d commands sent, payloadSize: %d", num, n)
+
+		}
+
+	}
+	return nil
+}
+
+// runOpenLoop issues commands at the target rate until the context is closed or the input ends,
+// and then waits for the commands in flight.
+func (s *session) runOpenLoop(ctx, callCtx context.Context) {
+	var (
+		wg      sync.WaitGroup
+		mut     sync.Mutex
+		stats   commandStats
+		dropped int
+	)
+	var sched schedule
+	switch {
+	case s.loadMode == TraceLoad:
+		sched = newTraceSchedule(s.trace, s.traceSpeed, s.traceLoop)
+	case len(s.loadProfile.Steps) > 0:
+		arrivals := newArrivalProcess(s.loadMode, s.targetRate, rand.New(rand.NewSource(s.seed)))
+		sched = newProfileSchedule(s.loadProfile, arrivals, s.payloadSizes, func(rate float64, step int) {
+			s.mods.EventLoop().AddEvent(TargetRateEvent{Session: s.id, Rate: rate, Step: step + 1})
+		})
+	default:
+		sched = syntheticSchedule{
+			arrivals: newArrivalProcess(s.loadMode, s.targetRate, rand.New(rand.NewSource(s.seed))),
+			sizes:    s.payloadSizes,
+		}
+		s.mods.EventLoop().AddEvent(TargetRateEvent{Session: s.id, Rate: s.targetRate})
+	}
+	err := s.sendOpenLoop(ctx, callCtx, sched, func(cmd pendingCmd) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.awaitCommand(callCtx, cmd)
+			<-s.inFlight
+			mut.Lock()
+			stats.add(err)
+			mut.Unlock()
+		}()
+	}, func() {
+		dropped++
+		s.mods.EventLoop().AddEvent(CommandDroppedEvent{Session: s.id})
+	})
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.mods.Logger().Panicf("Failed to send commands: %v", err)
+	}
+	wg.Wait()
+	s.close()
+
+	s.mods.Logger().Infof("Session %d done sending commands (executed: %d, failed: %d, dropped: %d, abandoned: %d)",
+		s.id, stats.executed, stats.failed, dropped, stats.abandoned)
+}
+
+// sendOpenLoop issues commands at the times given by the schedule, regardless of whether earlier
+// commands have been executed. If MaxConcurrent commands are already in flight when a command is due,
+// the command is dropped rather than delaying the following commands. The commands are sent with callCtx.
+func (s *session) sendOpenLoop(ctx, callCtx context.Context, sched schedule, sent func(pendingCmd), drop func()) error {
+	next := time.Now()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+	for {
+		interval, size, key, ok := sched.next()
+		if !ok {
+			s.mods.Logger().Info("Reached end of schedule. Waiting for the commands in flight...")
+			return io.EOF
+		}
+		// the arrival times do not depend on when the previous commands were actually sent,
+		// such that the offered load does not drift if the client falls behind.
+		next = next.Add(interval)
+		timer.Reset(time.Until(next))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil
+		}
+
+		select {
+		case s.inFlight <- struct{}{}:
+		default:
+			drop()
+			continue
+		}
+
+		data := make([]byte, size)
+		n, err := s.reader.Read(data)
+		if err != nil && err != io.EOF {
+			<-s.inFlight
+			return err
+		} else if err == io.EOF && n == 0 {
+			<-s.inFlight
+			s.mods.Logger().Info("Reached end of file. Waiting for the commands in flight...")
+			return io.EOF
+		}
+		data = data[:n]
+		if key != "" {
+			data = kvstore.Put(key, data)
+		} else {
+			data = s.command(data)
+		}
+
+		num := s.nextSequenceNumber()
+		cmd := &clientpb.Command{
+			ClientID:       uint32(s.id),
+			SequenceNumber: num,
+			Data:           data,
+			Tag:            s.tag(num),
+		}
+		// the latency is measured from when the command was due rather than when it was actually sent,
+		// such that delays in the client are not omitted from the measurements.
+		promise, cancel := s.submitCommand(callCtx, cmd)
+		sent(pendingCmd{sequenceNumber: num, sendTime: next, promise: promise, cmd: cmd, cancel: cancel})
+
+		if num%100 == 0 {
+			s.mods.Logger().Infof("%d commands sent, payloadSize: %d", num, n)
+		}
+	}
+}
+
+// command returns the data of the command with the given payload.
+// The payload is sent as it is, unless the session generates the key-value workload.
+func (s *session) command(payload []byte) []byte {
+	if s.kv == nil {
+		return payload
+	}
+	return s.kv.next(payload)
+}
+
+// tag returns the tag of the command with the given sequence number, or nil if the commands are untagged.
+func (s *session) tag(sequenceNumber uint64) []byte {
+	if s.tagger == nil {
+		return nil
+	}
+	return s.tagger(s.id, sequenceNumber)
+}
+
+// nextSequenceNumber returns the sequence number of the next command sent by the session.
+func (s *session) nextSequenceNumber() uint64 {
+	return atomic.AddUint64(&s.lastSeq, 1)
+}
+
+// handleCommands will get pending commands from the pendingCmds channel and then
+// handle them as they become acknowledged by the replicas, until the channel is closed.
+// We expect the commands to be acknowledged in the order that they were sent.
+func (s *session) handleCommands(callCtx context.Context) (stats commandStats) {
+	for cmd := range s.pendingCmds {
+		stats.add(s.awaitCommand(callCtx, cmd))
+	}
+	return stats
+}
+
+// submitCommand adds the command to the current batch if batching is enabled, and sends it on its own otherwise.
+func (s *session) submitCommand(ctx context.Context, cmd *clientpb.Command) (commandReply, context.CancelFunc) {
+	if s.batcher != nil {
+		return s.batcher.add(cmd), func() {}
+	}
+	return s.sendCommand(ctx, cmd, 0)
+}
+
+// sendBatch sends a batch of commands to the replicas. If request timeouts are enabled,
+// the batch is given the deadline of the first attempt of a command.
+func (s *session) sendBatch(ctx context.Context, batch *clientpb.Batch) *clientpb.AsyncBatchResponse {
+	if s.requestTimeout <= 0 {
+		return s.gorumsConfig.ExecCommandBatch(ctx, batch)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.requestTimeout)
+	promise := s.gorumsConfig.ExecCommandBatch(ctx, batch)
+	go func() {
+		_, _ = promise.Get()
+		cancel()
+	}()
+	return promise
+}
+
+// sendCommand sends the command to the replicas. If request timeouts are enabled,
+// the attempt is given a deadline that doubles with each retry, up to the maximum request timeout.
+func (s *session) sendCommand(ctx context.Context, cmd *clientpb.Command, retry int) (*clientpb.AsyncCommandResponse, context.CancelFunc) {
+	if s.requestTimeout <= 0 {
+		return s.gorumsConfig.ExecCommand(ctx, cmd), func() {}
+	}
+	timeout := s.requestTimeout
+	for i := 0; i < retry && (s.maxTimeout <= 0 || timeout < s.maxTimeout); i++ {
+		timeout *= 2
+	}
+	if s.maxTimeout > 0 && timeout > s.maxTimeout {
+		timeout = s.maxTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return s.gorumsConfig.ExecCommand(ctx, cmd), cancel
+}
+
+// awaitCommand waits for the command to be acknowledged by the replicas, and records its latency.
+// If the deadline of an attempt expires, the same command is sent again, such that the replicas can recognize it
+// and execute it at most once. A command that was sent in a batch is sent again on its own. The retries are sent to all replicas, since f+1 of them must acknowledge the command,
+// and replicas that forward commands relay it to the leader. A command that fails after the last retry is recorded as failed.
+// A command that the replicas rejected because they were overloaded is also sent again, after a backoff.
+// A command that is still in flight when ctx is closed is abandoned, and its latency is not recorded.
+func (s *session) awaitCommand(ctx context.Context, cmd pendingCmd) error {
+	resp, err := cmd.promise.Get()
+	cmd.cancel()
+	for retry := 1; retry <= s.maxRetries && (isDeadlineExceeded(err) || isOverloaded(err)) && ctx.Err() == nil; retry++ {
+		if isOverloaded(err) {
+			s.mods.Logger().Debugf("Command %d was rejected by overloaded replicas, retrying (attempt %d)", cmd.cmd.GetSequenceNumber(), retry+1)
+			if !s.backoff(ctx, retry) {
+				s.mods.EventLoop().AddEvent(CommandAbandonedEvent{Session: s.id})
+				return ctx.Err()
+			}
+		} else {
+			s.mods.Logger().Debugf("Command %d timed out, retrying (attempt %d)", cmd.cmd.GetSequenceNumber(), retry+1)
+		}
+		s.mods.EventLoop().AddEvent(CommandRetriedEvent{Session: s.id})
+		if s.window != nil {
+			s.reportWindow(s.window.timedOut(cmd.sendTime))
+		}
+		promise, cancel := s.sendCommand(ctx, cmd.cmd, retry)
+		resp, err = promise.Get()
+		cancel()
+	}
+	if isCanceled(err) {
+		s.mods.EventLoop().AddEvent(CommandAbandonedEvent{Session: s.id})
+		return err
+	}
+	if err != nil {
+		s.mods.Logger().Debugf("Did not get enough replies for command: %v\n", err)
+		s.mods.EventLoop().AddEvent(CommandFailedEvent{Session: s.id})
+	}
+	s.mut.Lock()
+	if cmd.sequenceNumber > s.highestCommitted {
+		s.highestCommitted = cmd.sequenceNumber
+	}
+	s.mut.Unlock()
+
+	duration := time.Since(cmd.sendTime)
+	if s.window != nil {
+		s.reportWindow(s.window.complete(cmd.sendTime, duration, err != nil))
+	}
+	s.latencies.record(duration)
+	if err == nil {
+		atomic.AddUint64(&s.completed, 1)
+	}
+	s.mods.EventLoop().AddEvent(LatencyMeasurementEvent{Session: s.id, Latency: duration, Tag: resp.GetTag()})
+	return err
+}
+
+// overloadBackoff is how long a client waits before it sends a command that was rejected by overloaded replicas again.
+// The backoff is doubled for each retry, up to maxOverloadBackoff.
+const (
+	overloadBackoff    = 10 * time.Millisecond
+	maxOverloadBackoff = time.Second
+)
+
+// backoff waits before the given retry of a command that was rejected by overloaded replicas.
+// It returns false if ctx was closed while waiting.
+func (s *session) backoff(ctx context.Context, retry int) bool {
+	wait := overloadBackoff
+	for i := 1; i < retry && wait < maxOverloadBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxOverloadBackoff {
+		wait = maxOverloadBackoff
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// reportWindow emits a WindowSizeEvent if the size of the adaptive window changed.
+func (s *session) reportWindow(size int, changed bool) {
+	if changed {
+		s.mods.EventLoop().AddEvent(WindowSizeEvent{Session: s.id, Size: size})
+	}
+}
+
+// lockedReader allows the sessions to read from the input concurrently.
+type lockedReader struct {
+	mut    sync.Mutex
+	reader io.ReadCloser
+}
+
+func (r *lockedReader) Read(p []byte) (int, error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return r.reader.Read(p)
+}
+
+func (r *lockedReader) Close() error {
+	return r.reader.Close()
+}
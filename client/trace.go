@@ -0,0 +1,102 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TraceEntry is a command in a workload trace.
+type TraceEntry struct {
+	Delta time.Duration // the time since the previous command
+	Size  uint32        // the size of the payload
+	Key   string        // if not empty, the command sets the key in the key-value store to the payload
+}
+
+// Trace is a recorded workload that is replayed by a client in the trace load mode.
+type Trace []TraceEntry
+
+// ParseTrace reads a trace with one command per line. Each line holds the time since the previous command
+// as a duration, such as "1.5ms", the size of the payload in bytes, and optionally a key, separated by whitespace.
+// Empty lines and lines that start with '#' are ignored.
+func ParseTrace(r io.Reader) (Trace, error) {
+	var trace Trace
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("trace line %d: expected 2 or 3 fields, got %d", line, len(fields))
+		}
+		delta, err := time.ParseDuration(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("trace line %d: invalid delta: %w", line, err)
+		}
+		if delta < 0 {
+			return nil, fmt.Errorf("trace line %d: negative delta %v", line, delta)
+		}
+		size, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("trace line %d: invalid payload size: %w", line, err)
+		}
+		entry := TraceEntry{Delta: delta, Size: uint32(size)}
+		if len(fields) == 3 {
+			entry.Key = fields[2]
+		}
+		trace = append(trace, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trace: %w", err)
+	}
+	return trace, nil
+}
+
+// schedule determines when an open-loop session issues its commands, and the sizes of their payloads.
+type schedule interface {
+	// next returns the time from the previous command until the next one, and the size and key of its payload.
+	// It returns false if there are no more commands.
+	next() (interval time.Duration, size uint32, key string, ok bool)
+}
+
+// syntheticSchedule issues commands with the intervals of an arrival process and the sizes of a payload distribution.
+type syntheticSchedule struct {
+	arrivals *arrivalProcess
+	sizes    *payloadSizes
+}
+
+func (s syntheticSchedule) next() (time.Duration, uint32, string, bool) {
+	return s.arrivals.next(), s.sizes.next(), "", true
+}
+
+// traceSchedule issues the commands of a trace. The intervals are divided by the speed factor.
+type traceSchedule struct {
+	trace Trace
+	pos   int
+	speed float64
+	loop  bool // start over at the end of the trace
+}
+
+func newTraceSchedule(trace Trace, speed float64, loop bool) *traceSchedule {
+	if speed <= 0 {
+		speed = 1
+	}
+	return &traceSchedule{trace: trace, speed: speed, loop: loop}
+}
+
+func (s *traceSchedule) next() (time.Duration, uint32, string, bool) {
+	if s.pos == len(s.trace) {
+		if !s.loop || len(s.trace) == 0 {
+			return 0, 0, "", false
+		}
+		s.pos = 0
+	}
+	entry := s.trace[s.pos]
+	s.pos++
+	return time.Duration(float64(entry.Delta) / s.speed), entry.Size, entry.Key, true
+}
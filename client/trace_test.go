@@ -0,0 +1,62 @@
+package client
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTrace(t *testing.T) {
+	const input = `# delta size key
+0s 10
+1.5ms 200 user1
+
+250us 0 user2
+`
+	trace, err := ParseTrace(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Trace{
+		{Delta: 0, Size: 10},
+		{Delta: 1500 * time.Microsecond, Size: 200, Key: "user1"},
+		{Delta: 250 * time.Microsecond, Size: 0, Key: "user2"},
+	}
+	if !reflect.DeepEqual(trace, want) {
+		t.Errorf("got %v, want %v", trace, want)
+	}
+
+	for _, invalid := range []string{"1ms", "1ms 10 key extra", "soon 10", "-1ms 10", "1ms -10", "1ms ten"} {
+		if _, err := ParseTrace(strings.NewReader(invalid)); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestTraceSchedule(t *testing.T) {
+	trace := Trace{{Delta: 10 * time.Millisecond, Size: 1}, {Delta: 30 * time.Millisecond, Size: 2, Key: "k"}}
+
+	sched := newTraceSchedule(trace, 2, false)
+	for _, want := range []struct {
+		interval time.Duration
+		size     uint32
+		key      string
+	}{{5 * time.Millisecond, 1, ""}, {15 * time.Millisecond, 2, "k"}} {
+		interval, size, key, ok := sched.next()
+		if !ok || interval != want.interval || size != want.size || key != want.key {
+			t.Errorf("got %v, %d, %q, %v, want %v, %d, %q", interval, size, key, ok, want.interval, want.size, want.key)
+		}
+	}
+	if _, _, _, ok := sched.next(); ok {
+		t.Error("schedule did not stop at the end of the trace")
+	}
+
+	looping := newTraceSchedule(trace, 0, true)
+	for i := 0; i < 5; i++ {
+		_, size, _, ok := looping.next()
+		if !ok || size != trace[i%len(trace)].Size {
+			t.Fatalf("command %d of the looping schedule had size %d, want %d", i, size, trace[i%len(trace)].Size)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// window limits the number of commands that a closed-loop session has in flight, and adapts the limit to the latency
+// of the commands, like the congestion window of TCP: the window grows by one command for each window of commands
+// that are executed within the target latency, and is halved when a command exceeds the target latency or times out.
+// The window is cut at most once for the commands that were in flight when it was last cut, since they are likely
+// to be late for the same reason.
+type window struct {
+	mut          sync.Mutex
+	size         float64
+	min, max     float64
+	target       time.Duration
+	inFlight     int
+	lastDecrease time.Time
+	changed      chan struct{} // closed when a slot may have become available
+}
+
+// newWindow returns a window that starts at the floor and is adapted between min and max.
+func newWindow(min, max uint32, target time.Duration) *window {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return &window{
+		size:    float64(min),
+		min:     float64(min),
+		max:     float64(max),
+		target:  target,
+		changed: make(chan struct{}),
+	}
+}
+
+// acquire waits until fewer commands than the window size are in flight, and then takes a slot for a new command.
+func (w *window) acquire(ctx context.Context) error {
+	for {
+		w.mut.Lock()
+		if w.inFlight < int(w.size) {
+			w.inFlight++
+			w.mut.Unlock()
+			return nil
+		}
+		changed := w.changed
+		w.mut.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// complete releases the slot of a command that was sent at sendTime, and adapts the window to its latency.
+// It returns the window size, and whether it changed.
+func (w *window) complete(sendTime time.Time, latency time.Duration, failed bool) (size int, changed bool) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	w.inFlight--
+	old := int(w.size)
+	if failed || latency > w.target {
+		w.decrease(sendTime)
+	} else {
+		w.size = math.Min(w.max, w.size+1/w.size)
+	}
+	close(w.changed)
+	w.changed = make(chan struct{})
+	return int(w.size), int(w.size) != old
+}
+
+// timedOut cuts the window when an attempt of the command that was sent at sendTime times out.
+// The command keeps its slot while it is retried.
+func (w *window) timedOut(sendTime time.Time) (size int, changed bool) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	old := int(w.size)
+	w.decrease(sendTime)
+	return int(w.size), int(w.size) != old
+}
+
+// decrease halves the window, unless it was already cut after the command was sent.
+func (w *window) decrease(sendTime time.Time) {
+	if !sendTime.After(w.lastDecrease) {
+		return
+	}
+	w.size = math.Max(w.min, w.size/2)
+	w.lastDecrease = time.Now()
+}
+
+// current returns the current window size.
+func (w *window) current() int {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	return int(w.size)
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWindowCutOncePerWindow(t *testing.T) {
+	w := newWindow(1, 64, 10*time.Millisecond)
+	complete := func(sendTime time.Time, latency time.Duration, failed bool) (int, bool) {
+		t.Helper()
+		if err := w.acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return w.complete(sendTime, latency, failed)
+	}
+	// each window of commands within the target latency grows the window by one.
+	for w.current() < 16 {
+		complete(time.Now(), time.Millisecond, false)
+	}
+	sent := time.Now()
+
+	// the first timeout of a command cuts the window, but the retry of the same command
+	// and the other commands that were in flight at the time do not cut it again.
+	if size, changed := w.timedOut(sent); size != 8 || !changed {
+		t.Fatalf("window was %d after a timeout, want 8", size)
+	}
+	if size, changed := complete(sent, time.Second, true); size != 8 || changed {
+		t.Errorf("window was %d after the retried command failed, want 8", size)
+	}
+	if size, _ := complete(sent, time.Second, false); size != 8 {
+		t.Errorf("window was %d after a late command that was in flight when it was cut, want 8", size)
+	}
+
+	time.Sleep(time.Millisecond)
+	if size, _ := complete(time.Now(), time.Second, false); size != 4 {
+		t.Errorf("window was %d after a late command that was sent after it was cut, want 4", size)
+	}
+	for i := 0; i < 10; i++ {
+		time.Sleep(time.Millisecond)
+		complete(time.Now(), time.Second, false)
+	}
+	if size := w.current(); size != 1 {
+		t.Errorf("window was %d, want the floor 1", size)
+	}
+}
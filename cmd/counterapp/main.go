@@ -0,0 +1,53 @@
+// Command counterapp runs the counting workload as an external application for a replica.
+// The replica connects to it when it is started with the address of the application, for example:
+//
+//	counterapp --listen unix:///tmp/app1.sock &
+//	hotstuff replica --app-address unix:///tmp/app1.sock ...
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/examples/counter"
+	"github.com/relab/hotstuff/internal/proto/apppb"
+	"google.golang.org/grpc"
+)
+
+var (
+	listen         = flag.String("listen", "unix:///tmp/counterapp.sock", "The address to listen on. Addresses of the form unix:///path refer to unix domain sockets.")
+	maxCommandSize = flag.Int("max-command-size", 0, "Reject proposals with commands larger than this number of bytes (no limit if zero).")
+	batchSize      = flag.Uint("batch-size", 0, "The batch size to suggest to the replica (the replica's own batch size if zero).")
+)
+
+func main() {
+	flag.Parse()
+
+	transport, _ := backend.GetTransport("")
+	lis, err := transport.Listen(*listen)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	app := counter.New(counter.Config{MaxCommandSize: *maxCommandSize, BatchSize: uint32(*batchSize)})
+	srv := grpc.NewServer()
+	apppb.RegisterApplicationServer(srv, app)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		srv.Stop()
+	}()
+
+	log.Printf("Listening on %s", lis.Addr())
+	if err := srv.Serve(lis); err != nil {
+		log.Fatalln(err)
+	}
+	log.Printf("Executed %d commands in %d blocks with hash %.8x", app.Commands(), app.Height(), app.Hash())
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"time"
 
 	_ "github.com/relab/hotstuff/internal/proto/orchestrationpb"
@@ -17,6 +18,9 @@ var (
 	latency             = flag.String("latency", "tmp/latency.png", "File to save latency plot to.")
 	throughput          = flag.String("throughput", "tmp/throughput.png", "File to save throughput plot to.")
 	throughputVSLatency = flag.String("throughputvslatency", "tmp/throughputVSLatency.png", "File to save throughput vs latency plot to.")
+	queueWait           = flag.String("queuewait", "tmp/queueWait.png", "File to save command queue wait plot to.")
+	sweep               = flag.String("sweep", "tmp/sweep.png", "File to save the throughput vs latency plot of a sweep to.")
+	sweepSeries         = flag.String("sweep-series", "", "Sweep parameter to plot one line for each value of.")
 )
 
 func main() {
@@ -24,10 +28,15 @@ func main() {
 
 	srcPath := flag.Arg(0)
 	if srcPath == "" {
-		fmt.Fprintf(os.Stderr, "usage: %s [flags] [path to measurements]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] [path to measurements or sweep index]\n", os.Args[0])
 		os.Exit(1)
 	}
 
+	if filepath.Base(srcPath) == plotting.SweepIndexFile {
+		plotSweep(srcPath)
+		return
+	}
+
 	file, err := os.Open(srcPath)
 	if err != nil {
 		log.Fatalln(err)
@@ -36,14 +45,45 @@ func main() {
 	latencyPlot := plotting.NewClientLatencyPlot()
 	throughputPlot := plotting.NewThroughputPlot()
 	throughputVSLatencyPlot := plotting.NewThroughputVSLatencyPlot()
+	latencyPercentiles := plotting.NewLatencyPercentiles()
+	loadSteps := plotting.NewLoadSteps()
+	byzantineReplicas := plotting.NewByzantineReplicas()
+	faults := plotting.NewFaults()
+	queuePlot := plotting.NewCommandQueuePlot()
 
-	reader := plotting.NewReader(file, &latencyPlot, &throughputPlot, &throughputVSLatencyPlot)
+	reader := plotting.NewReader(file, &latencyPlot, &throughputPlot, &throughputVSLatencyPlot, &latencyPercentiles, &loadSteps, &byzantineReplicas, &faults, &queuePlot)
 	if err := reader.ReadAll(); err != nil {
 		log.Fatalln(err)
 	}
 
 	fmt.Printf("la: %v, th: %v, th_vs_la: %v", latencyPlot, throughputPlot, throughputVSLatencyPlot)
 
+	if latencyPercentiles.Count() > 0 {
+		fmt.Printf("\nlatency of %d commands (ms): ", latencyPercentiles.Count())
+		for _, percentile := range []float64{50, 90, 99, 99.9} {
+			fmt.Printf("p%v: %.3f ", percentile, latencyPercentiles.Percentile(percentile))
+		}
+		fmt.Println()
+	}
+
+	if len(byzantineReplicas.IDs()) > 0 {
+		fmt.Printf("byzantine replicas: %v\n", &byzantineReplicas)
+	}
+
+	if len(faults.Faults()) > 0 {
+		fmt.Printf("faults: %v\n", &faults)
+	}
+
+	if queue := queuePlot.Summary(); queue.Commands > 0 {
+		fmt.Printf("command queue: depth: %.1f, max depth: %d, commands: %d, wait (ms): %.3f, count fill: %v, bytes fill: %v\n",
+			queue.Depth, queue.MaxDepth, queue.Commands, float64(queue.MeanWait)/float64(time.Millisecond), queue.CountFill, queue.BytesFill)
+	}
+
+	for _, step := range loadSteps.Summaries(*interval) {
+		fmt.Printf("load step %d: target rate: %.1f, throughput: %.1f, latency (ms): %.3f, commands: %d, dropped: %d, failed: %d\n",
+			step.Step, step.TargetRate, step.Throughput, step.Latency, step.Commands, step.Dropped, step.Failed)
+	}
+
 	if *latency != "" {
 		if err := latencyPlot.PlotAverage(*latency, *interval); err != nil {
 			log.Fatalln(err)
@@ -70,4 +110,34 @@ func main() {
 	} else {
 		fmt.Println("no throughputVSLatency")
 	}
+
+	if *queueWait != "" && queuePlot.Summary().Commands > 0 {
+		if err := queuePlot.PlotWait(*queueWait, *interval); err != nil {
+			log.Fatalln(err)
+		}
+		fmt.Println("draw queueWait ok")
+	} else {
+		fmt.Println("no queueWait")
+	}
+}
+
+func plotSweep(indexFile string) {
+	summaries, err := plotting.SummarizeSweep(indexFile)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	for _, summary := range summaries {
+		fmt.Printf("%v: runs: %d, failed: %d, throughput: %.1f, latency (ms): %.3f\n",
+			summary, summary.Runs, summary.Failed, summary.Throughput, summary.Latency)
+	}
+
+	if *sweep != "" {
+		if err := plotting.PlotSweep(*sweep, summaries, *sweepSeries); err != nil {
+			log.Fatalln(err)
+		}
+		fmt.Println("draw sweep ok")
+	} else {
+		fmt.Println("no sweep")
+	}
 }
@@ -1,7 +1,15 @@
 // Package byzantine contiains byzantine behaviors that can be applied to the consensus protocols.
 package byzantine
 
-import "github.com/relab/hotstuff/consensus"
+import (
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/modules"
+)
+
+func init() {
+	Register("silence", func() Byzantine { return &silence{} })
+	Register("fork", func() Byzantine { return &fork{} })
+}
 
 // Byzantine wraps a consensus rules implementation and alters its behavior.
 type Byzantine interface {
@@ -9,6 +17,24 @@ type Byzantine interface {
 	Wrap(consensus.Rules) consensus.Rules
 }
 
+// Register registers a byzantine strategy with the specified name.
+func Register(name string, constructor func() Byzantine) {
+	modules.RegisterModule(name, constructor)
+}
+
+// Get returns a new instance of the byzantine strategy with the specified name.
+// The error lists the registered strategies if there is none with that name.
+func Get(name string) (byz Byzantine, err error) {
+	err = modules.NewModule(name, &byz)
+	return byz, err
+}
+
+// Names returns the names of the registered byzantine strategies.
+func Names() []string {
+	var byz Byzantine
+	return modules.Names(&byz)
+}
+
 type silence struct {
 	consensus.Rules
 }
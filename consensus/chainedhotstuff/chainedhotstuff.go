@@ -3,11 +3,10 @@ package chainedhotstuff
 
 import (
 	"github.com/relab/hotstuff/consensus"
-	"github.com/relab/hotstuff/modules"
 )
 
 func init() {
-	modules.RegisterModule("chainedhotstuff", New)
+	consensus.RegisterRules("chainedhotstuff", New)
 }
 
 // ChainedHotStuff implements the pipelined three-phase HotStuff protocol.
@@ -20,12 +20,27 @@ type Rules interface {
 }
 
 // ProposeRuler is an optional interface that adds a ProposeRule method.
-// This allows implementors to specify how new blocks are created.
+// This allows implementors to specify how new blocks are created, such as which block to extend, and which QC or
+// AggregateQC to embed. The proposal is then stored, broadcast, and voted for by the ConsensusBase struct.
 type ProposeRuler interface {
 	// ProposeRule creates a new proposal.
 	ProposeRule(cert SyncInfo, cmd Command) (proposal ProposeMsg, ok bool)
 }
 
+// MaxPendingProposals is the maximum number of proposals that a replica holds back until their parent blocks are
+// stored. When more proposals are held, the one with the lowest view is dropped.
+const MaxPendingProposals = 64
+
+// MaxVotedViews is the number of views in which a replica remembers the proposal that it voted for, in order to detect
+// leaders that propose conflicting blocks. The proposals of the oldest views are forgotten first.
+const MaxVotedViews = 64
+
+// pendingProposal re-delivers a proposal that was held back until its parent block was stored.
+// It is a separate event type, such that the observers of proposals do not see the proposal twice.
+type pendingProposal struct {
+	proposal ProposeMsg
+}
+
 // consensusBase provides a default implementation of the Consensus interface
 // for implementations of the ConsensusImpl interface.
 type consensusBase struct {
@@ -34,6 +49,14 @@ type consensusBase struct {
 
 	lastVote View
 
+	// the proposals whose parent blocks are missing, by the hash of the parent.
+	pending      map[Hash][]ProposeMsg
+	pendingCount int
+	// the blocks that are being fetched, and the functions that resume the work that waits for them.
+	fetching map[Hash][]func()
+	// the proposals that were voted for in the views after the committed block, by view.
+	voted map[View]ProposeMsg
+
 	mut   sync.Mutex
 	bExec *Block
 }
@@ -43,6 +66,9 @@ func New(impl Rules) Consensus {
 	return &consensusBase{
 		impl:     impl,
 		lastVote: 0,
+		pending:  make(map[Hash][]ProposeMsg),
+		fetching: make(map[Hash][]func()),
+		voted:    make(map[View]ProposeMsg),
 		bExec:    GetGenesis(),
 	}
 }
@@ -55,18 +81,54 @@ func (cs *consensusBase) CommittedBlock() *Block {
 
 func (cs *consensusBase) InitConsensusModule(mods *Modules, opts *OptionsBuilder) {
 	cs.mods = mods
+	cs.mods.Require(cs.impl, AcceptorModule, BlockChainModule, CommandQueueModule, ConfigurationModule, CryptoModule,
+		ExecutorModule, LeaderRotationModule, SynchronizerModule)
 	if mod, ok := cs.impl.(Module); ok {
 		mod.InitConsensusModule(mods, opts)
 	}
 	cs.mods.EventLoop().RegisterHandler(ProposeMsg{}, func(event interface{}) {
 		cs.OnPropose(event.(ProposeMsg))
 	})
+	cs.mods.EventLoop().RegisterHandler(pendingProposal{}, func(event interface{}) {
+		cs.OnPropose(event.(pendingProposal).proposal)
+	})
+	cs.mods.EventLoop().RegisterHandler(FetchedEvent{}, func(event interface{}) {
+		cs.onFetched(event.(FetchedEvent))
+	})
+	cs.restoreSafetyState()
+}
+
+// restoreSafetyState restores the last vote and the last executed block from the safety storage, if there is one,
+// such that a replica that was restarted neither votes twice in a view, nor executes a block twice.
+// A replica whose state cannot be loaded does not vote at all, since it cannot tell which views it voted in.
+func (cs *consensusBase) restoreSafetyState() {
+	storage := cs.mods.SafetyStorage()
+	if storage == nil {
+		return
+	}
+	state, err := storage.Load()
+	if err != nil {
+		cs.mods.Logger().Errorf("Failed to load the safety state; the replica will not vote: %v", err)
+		cs.lastVote = ^View(0)
+		return
+	}
+	cs.lastVote = state.LastVote
+	if state.Executed != nil {
+		cs.bExec = state.Executed
+	}
+	cs.mods.Logger().Infof("Restored the safety state: last vote in view %d, last executed block in view %d",
+		cs.lastVote, cs.bExec.View())
 }
 
 // StopVoting ensures that no voting happens in a view earlier than `view`.
 func (cs *consensusBase) StopVoting(view View) {
 	if cs.lastVote < view {
 		cs.lastVote = view
+		if storage := cs.mods.SafetyStorage(); storage != nil {
+			if err := storage.StoreVote(view); err != nil {
+				cs.mods.Logger().Warnf("StopVoting: failed to persist the last vote: %v", err)
+			}
+		}
 	}
 }
 
@@ -74,12 +136,26 @@ func (cs *consensusBase) StopVoting(view View) {
 func (cs *consensusBase) Propose(cert SyncInfo) {
 	cs.mods.Logger().Debug("Propose")
 
+	// the leader votes for its own proposal, so a replica that was restarted must not propose in a view that it voted
+	// in before the restart, since it would propose a different block than the one it proposed then.
+	if view := cs.mods.Synchronizer().View(); view <= cs.lastVote {
+		cs.mods.Logger().Infof("Propose: already voted in view %d", view)
+		return
+	}
+
 	qc, ok := cert.QC()
 	if ok {
 		// tell the acceptor that the previous proposal succeeded.
-		qcBlock, ok := cs.mods.BlockChain().Get(qc.BlockHash())
+		qcBlock, ok := cs.mods.BlockChain().LocalGet(qc.BlockHash())
 		if !ok {
-			cs.mods.Logger().Errorf("Could not find block for QC: %s", qc)
+			// the proposal is resumed when the block has been fetched, unless the view has ended by then.
+			cs.mods.Logger().Infof("Propose: fetching block for QC: %s", qc)
+			view := cs.mods.Synchronizer().View()
+			cs.fetchBlock(qc.BlockHash(), func() {
+				if cs.mods.Synchronizer().View() == view {
+					cs.Propose(cert)
+				}
+			})
 			return
 		}
 		cs.mods.Acceptor().Proposed(qcBlock.Command())
@@ -90,6 +166,10 @@ func (cs *consensusBase) Propose(cert SyncInfo) {
 		cs.mods.Logger().Debug("Propose: No command")
 		return
 	}
+	if err := cs.mods.CommandValidator().Validate(cmd); err != nil {
+		cs.mods.Logger().Warnf("Propose: invalid command: %v", err)
+		return
+	}
 
 	var proposal ProposeMsg
 	if proposer, ok := cs.impl.(ProposeRuler); ok {
@@ -116,6 +196,7 @@ func (cs *consensusBase) Propose(cert SyncInfo) {
 	}
 
 	cs.mods.BlockChain().Store(proposal.Block)
+	cs.releaseProposals(proposal.Block)
 
 	cs.mods.Configuration().Propose(proposal)
 	// self vote
@@ -130,12 +211,12 @@ func (cs *consensusBase) OnPropose(proposal ProposeMsg) {
 	if cs.mods.Options().ShouldUseAggQC() && proposal.AggregateQC != nil {
 		ok, highQC := cs.mods.Crypto().VerifyAggregateQC(*proposal.AggregateQC)
 		if !ok {
-			cs.mods.Logger().Warn("OnPropose: failed to verify aggregate QC")
+			cs.rejectAggregateQC(proposal, "failed to verify aggregate QC")
 			return
 		}
 		// NOTE: for simplicity, we require that the highQC found in the AggregateQC equals the QC embedded in the block.
 		if !block.QuorumCert().Equals(highQC) {
-			cs.mods.Logger().Warn("OnPropose: block QC does not equal highQC")
+			cs.rejectAggregateQC(proposal, "block QC does not equal highQC")
 			return
 		}
 	}
@@ -153,6 +234,19 @@ func (cs *consensusBase) OnPropose(proposal ProposeMsg) {
 		return
 	}
 
+	// the vote rule and the commit rule look at the ancestors of the block, so a proposal that arrives before its
+	// parent, such as when a replica rejoins after a partition, is held until the parent is stored.
+	// the parent is fetched from the other replicas in the background, such that the event loop is not blocked.
+	if _, ok := cs.mods.BlockChain().LocalGet(block.Parent()); !ok {
+		cs.mods.Logger().Infof("OnPropose: holding block until its parent %.8s is stored", block.Parent())
+		cs.holdProposal(proposal)
+		cs.fetchBlock(block.Parent(), nil)
+		return
+	}
+
+	// a held proposal is only checked once it is released, such that it is not reported twice.
+	cs.checkEquivocation(proposal)
+
 	if !cs.impl.VoteRule(proposal) {
 		cs.mods.Logger().Info("OnPropose: Block not voted for")
 		return
@@ -164,6 +258,11 @@ func (cs *consensusBase) OnPropose(proposal ProposeMsg) {
 		cs.mods.Logger().Info("OnPropose: Failed to fetch qcBlock")
 	}
 
+	if err := cs.mods.CommandValidator().Validate(block.Command()); err != nil {
+		cs.mods.Logger().Infof("OnPropose: invalid command: %v", err)
+		return
+	}
+
 	if !cs.mods.Acceptor().Accept(block.Command()) {
 		cs.mods.Logger().Info("OnPropose: command not accepted")
 		return
@@ -171,6 +270,7 @@ func (cs *consensusBase) OnPropose(proposal ProposeMsg) {
 
 	// block is safe and was accepted
 	cs.mods.BlockChain().Store(block)
+	cs.releaseProposals(block)
 
 	// we defer the following in order to speed up voting
 	defer func() {
@@ -191,7 +291,15 @@ func (cs *consensusBase) OnPropose(proposal ProposeMsg) {
 		return
 	}
 
+	// the vote is persisted before it is sent, such that the replica does not vote again in the view if it crashes.
+	if storage := cs.mods.SafetyStorage(); storage != nil {
+		if err := storage.StoreVote(block.View()); err != nil {
+			cs.mods.Logger().Error("OnPropose: failed to persist vote: ", err)
+			return
+		}
+	}
 	cs.lastVote = block.View()
+	cs.recordVote(proposal)
 
 	leaderID := cs.mods.LeaderRotation().GetLeader(cs.lastVote + 1)
 	if leaderID == cs.mods.ID() {
@@ -208,31 +316,232 @@ func (cs *consensusBase) OnPropose(proposal ProposeMsg) {
 	leader.Vote(pc)
 }
 
+// rejectAggregateQC drops a proposal whose AggregateQC could not be used to justify the proposed block.
+// The rejection is emitted as an event, such that it is counted along with the other events on the status server.
+func (cs *consensusBase) rejectAggregateQC(proposal ProposeMsg, reason string) {
+	cs.mods.Logger().Warnf("OnPropose: rejected proposal for view %d from replica %d: %s",
+		proposal.Block.View(), proposal.ID, reason)
+	cs.mods.EventLoop().AddEvent(AggregateQCRejectedEvent{
+		ID:     proposal.ID,
+		View:   proposal.Block.View(),
+		Reason: reason,
+	})
+}
+
 func (cs *consensusBase) commit(block *Block) {
 	cs.mut.Lock()
-	// can't recurse due to requiring the mutex, so we use a helper instead.
-	cs.commitInner(block)
+	ok := cs.execChain(block)
 	cs.mut.Unlock()
+	if !ok {
+		return
+	}
+
+	cs.dropPendingBelow(block.View())
+	for view := range cs.voted {
+		if view <= block.View() {
+			delete(cs.voted, view)
+		}
+	}
 
 	// prune the blockchain and handle forked blocks
 	forkedBlocks := cs.mods.BlockChain().PruneToHeight(block.View())
-	for _, block := range forkedBlocks {
-		cs.mods.ForkHandler().Fork(block)
+	if forkHandler := cs.mods.ForkHandler(); forkHandler != nil {
+		for _, block := range forkedBlocks {
+			forkHandler.Fork(block)
+		}
 	}
 }
 
-// recursive helper for commit
-func (cs *consensusBase) commitInner(block *Block) {
-	if cs.bExec.View() < block.View() {
-		if parent, ok := cs.mods.BlockChain().Get(block.Parent()); ok {
-			cs.commitInner(parent)
-		} else {
+// execChain executes the blocks from the one after bExec up to and including the given block, in order.
+// Returns false if an ancestor of the block could not be retrieved, in which case no blocks are executed.
+// The chain is walked iteratively, since it can be thousands of blocks long after a partition.
+// It must be called with the mutex held.
+func (cs *consensusBase) execChain(block *Block) bool {
+	var chain []*Block
+	for b := block; cs.bExec.View() < b.View(); {
+		chain = append(chain, b)
+		parent, ok := cs.mods.BlockChain().Get(b.Parent())
+		if !ok {
 			cs.mods.Logger().Warn("Refusing to commit because parent block could not be retrieved.")
+			return false
+		}
+		b = parent
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		cs.mods.Logger().Debug("EXEC: ", chain[i])
+		cs.mods.Executor().Exec(chain[i])
+		cs.bExec = chain[i]
+		cs.mods.EventLoop().AddEvent(BlockCommitEvent{Block: chain[i], Timestamp: cs.mods.Clock().Now()})
+	}
+	if len(chain) > 0 {
+		cs.storeExecuted(cs.bExec)
+	}
+	return true
+}
+
+// storeExecuted persists the last executed block, if there is a safety storage.
+// It must be called with the mutex held.
+func (cs *consensusBase) storeExecuted(block *Block) {
+	if storage := cs.mods.SafetyStorage(); storage != nil {
+		if err := storage.StoreExecuted(block); err != nil {
+			cs.mods.Logger().Warnf("Failed to persist the last executed block: %v", err)
+		}
+	}
+}
+
+// checkEquivocation emits an EquivocationEvent if the leader proposed a different block than the one the replica
+// voted for in the same view.
+func (cs *consensusBase) checkEquivocation(proposal ProposeMsg) {
+	voted, ok := cs.voted[proposal.Block.View()]
+	if !ok || voted.ID != proposal.ID || voted.Block.Hash() == proposal.Block.Hash() {
+		return
+	}
+	cs.mods.Logger().Warnf("OnPropose: replica %d proposed conflicting blocks %.8s and %.8s in view %d",
+		proposal.ID, voted.Block.Hash(), proposal.Block.Hash(), proposal.Block.View())
+	cs.mods.EventLoop().AddEvent(EquivocationEvent{First: voted, Second: proposal})
+}
+
+// recordVote remembers the proposal that the replica voted for, and forgets the proposal of the oldest view if the
+// proposals of more than MaxVotedViews views are remembered.
+func (cs *consensusBase) recordVote(proposal ProposeMsg) {
+	cs.voted[proposal.Block.View()] = proposal
+	if len(cs.voted) <= MaxVotedViews {
+		return
+	}
+	oldest := proposal.Block.View()
+	for view := range cs.voted {
+		if view < oldest {
+			oldest = view
+		}
+	}
+	delete(cs.voted, oldest)
+}
+
+// holdProposal holds the proposal until its parent block is stored. If too many proposals are held, the one with the
+// lowest view is dropped, such that a leader cannot exhaust the memory of the replica with proposals that extend
+// blocks that do not exist.
+func (cs *consensusBase) holdProposal(proposal ProposeMsg) {
+	block := proposal.Block
+	if block.View() <= cs.CommittedBlock().View() {
+		return
+	}
+	for _, p := range cs.pending[block.Parent()] {
+		if p.Block.Hash() == block.Hash() {
 			return
 		}
-		cs.mods.Logger().Debug("EXEC: ", block)
-		cs.mods.Executor().Exec(block)
+	}
+	cs.pending[block.Parent()] = append(cs.pending[block.Parent()], proposal)
+	cs.pendingCount++
+
+	if cs.pendingCount <= MaxPendingProposals {
+		return
+	}
+	var (
+		lowest   Hash
+		lowestAt int
+		found    bool
+	)
+	for parent, proposals := range cs.pending {
+		for i, p := range proposals {
+			if !found || p.Block.View() < cs.pending[lowest][lowestAt].Block.View() {
+				lowest, lowestAt, found = parent, i, true
+			}
+		}
+	}
+	cs.mods.Logger().Infof("OnPropose: dropping held block of view %d", cs.pending[lowest][lowestAt].Block.View())
+	cs.removePending(lowest, lowestAt)
+}
+
+// releaseProposals re-delivers the proposals that were held until the block was stored.
+func (cs *consensusBase) releaseProposals(block *Block) {
+	proposals, ok := cs.pending[block.Hash()]
+	if !ok {
+		return
+	}
+	delete(cs.pending, block.Hash())
+	cs.pendingCount -= len(proposals)
+	for _, p := range proposals {
+		cs.mods.EventLoop().AddEvent(pendingProposal{p})
+	}
+}
+
+// dropPendingBelow drops the held proposals whose views are not higher than the view, since they can no longer be
+// voted for nor committed.
+func (cs *consensusBase) dropPendingBelow(view View) {
+	for parent, proposals := range cs.pending {
+		for i := len(proposals) - 1; i >= 0; i-- {
+			if proposals[i].Block.View() <= view {
+				cs.removePending(parent, i)
+			}
+		}
+	}
+}
+
+func (cs *consensusBase) removePending(parent Hash, i int) {
+	proposals := cs.pending[parent]
+	proposals = append(proposals[:i], proposals[i+1:]...)
+	if len(proposals) == 0 {
+		delete(cs.pending, parent)
+	} else {
+		cs.pending[parent] = proposals
+	}
+	cs.pendingCount--
+}
+
+// fetchBlock fetches the block from the other replicas in the background, until the current view ends, and calls
+// resume on the event loop once the block has been stored. Only one fetch of each block is in progress at a time.
+// The result is delivered by a FetchedEvent, such that it is processed in order with the other events, even if the
+// block is fetched synchronously.
+func (cs *consensusBase) fetchBlock(hash Hash, resume func()) {
+	waiting, inProgress := cs.fetching[hash]
+	if resume != nil {
+		waiting = append(waiting, resume)
+	}
+	cs.fetching[hash] = waiting
+	if inProgress {
+		return
+	}
+	ctx := cs.mods.Synchronizer().ViewContext()
+	fetch := func() {
+		block, ok := cs.mods.Configuration().Fetch(ctx, hash)
+		if !ok {
+			block = nil
+		}
+		cs.mods.EventLoop().AddEvent(FetchedEvent{Hash: hash, Block: block})
+	}
+	if cs.mods.Options().ShouldFetchSync() {
+		fetch()
+	} else {
+		go fetch()
+	}
+}
+
+// onFetched stores a fetched block, and resumes the work that waited for it.
+func (cs *consensusBase) onFetched(event FetchedEvent) {
+	resume := cs.fetching[event.Hash]
+	delete(cs.fetching, event.Hash)
+	if event.Block == nil {
+		cs.mods.Logger().Infof("Failed to fetch block: %.8s", event.Hash)
+		return
+	}
+	if event.Block.Hash() != event.Hash {
+		cs.mods.Logger().Warnf("Fetched the wrong block for %.8s", event.Hash)
+		return
+	}
+	cs.mods.BlockChain().Store(event.Block)
+	cs.releaseProposals(event.Block)
+	for _, f := range resume {
+		f()
+	}
+}
+
+// SetCommittedBlock makes the block the most recently committed block, without executing it or its ancestors.
+func (cs *consensusBase) SetCommittedBlock(block *Block) {
+	cs.mut.Lock()
+	defer cs.mut.Unlock()
+	if cs.bExec.View() < block.View() {
 		cs.bExec = block
+		cs.storeExecuted(block)
 	}
 }
 
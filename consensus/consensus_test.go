@@ -2,13 +2,22 @@ package consensus_test
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/blockchain"
 	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/consensus/chainedhotstuff"
+	"github.com/relab/hotstuff/eventloop"
 	"github.com/relab/hotstuff/internal/mocks"
 	"github.com/relab/hotstuff/internal/testutil"
+	"github.com/relab/hotstuff/safety"
 	"github.com/relab/hotstuff/synchronizer"
 )
 
@@ -18,17 +27,17 @@ func TestVote(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	bl := testutil.CreateBuilders(t, ctrl, n)
 	cs := mocks.NewMockConsensus(ctrl)
-	bl[0].Register(synchronizer.New(testutil.FixedTimeout(1000)), cs)
+	bl[0].Register(synchronizer.New(testutil.FixedTimeout(1000)), cs, eventloop.NewSynchronous())
+	// the votes are verified on the event loop, such that they are all processed by RunUntilIdle.
+	bl[0].OptionsBuilder().SetShouldVerifyVotesSync()
 	hl := bl.Build()
 	hs := hl[0]
 
 	cs.EXPECT().Propose(gomock.AssignableToTypeOf(consensus.NewSyncInfo()))
 
 	ok := false
-	ctx, cancel := context.WithCancel(context.Background())
 	hs.EventLoop().RegisterObserver(consensus.NewViewMsg{}, func(event interface{}) {
 		ok = true
-		cancel()
 	})
 
 	b := testutil.NewProposeMsg(
@@ -46,9 +55,971 @@ func TestVote(t *testing.T) {
 		hs.EventLoop().AddEvent(consensus.VoteMsg{ID: hotstuff.ID(i + 1), PartialCert: pc})
 	}
 
-	hs.Run(ctx)
+	hs.EventLoop().RunUntilIdle()
 
 	if !ok {
 		t.Error("No new view event happened")
 	}
 }
+
+// TestOnProposeOrdering checks that a leader that votes for its own proposal advances the view
+// before its vote is processed, such that the vote is counted in the next view.
+func TestOnProposeOrdering(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	builder := testutil.TestModules(t, ctrl, 1, testutil.GenerateECDSAKey(t))
+	mockSync := mocks.NewMockSynchronizer(ctrl)
+	builder.Register(consensus.New(chainedhotstuff.New()), mockSync, eventloop.NewSynchronous())
+	builder.OptionsBuilder().SetShouldVerifyVotesSync()
+	hs := builder.Build()
+
+	mockSync.EXPECT().LeafBlock().AnyTimes().Return(consensus.GetGenesis())
+
+	var order []string
+	mockSync.EXPECT().UpdateHighQC(gomock.Any()).Do(func(consensus.QuorumCert) {
+		order = append(order, "UpdateHighQC")
+	})
+	mockSync.EXPECT().AdvanceView(gomock.Any()).Do(func(consensus.SyncInfo) {
+		order = append(order, "AdvanceView")
+	})
+	hs.EventLoop().RegisterObserver(consensus.VoteMsg{}, func(event interface{}) {
+		if vote := event.(consensus.VoteMsg); vote.ID != 1 {
+			t.Errorf("got a vote from %d, want a vote from 1", vote.ID)
+		}
+		order = append(order, "VoteMsg")
+	})
+
+	hs.EventLoop().AddEvent(testutil.NewProposeMsg(
+		consensus.GetGenesis().Hash(),
+		consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+		"foo", 1, 1,
+	))
+	if n := hs.EventLoop().RunUntilIdle(); n != 2 {
+		t.Errorf("processed %d events, want 2", n)
+	}
+
+	if want := []string{"UpdateHighQC", "AdvanceView", "VoteMsg"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("got %v, want %v", order, want)
+	}
+}
+
+// rejectCommand is a command validator that rejects a single command.
+type rejectCommand consensus.Command
+
+func (r rejectCommand) Validate(cmd consensus.Command) error {
+	if cmd == consensus.Command(r) {
+		return errors.New("invalid command")
+	}
+	return nil
+}
+
+// TestInvalidCommandNotVoted checks that a replica does not vote for a proposal with a command that the command
+// validator rejects, such as one that a malicious leader proposed.
+func TestInvalidCommandNotVoted(t *testing.T) {
+	for _, tt := range []struct {
+		cmd   consensus.Command
+		votes int
+	}{
+		{"valid", 1},
+		{"invalid", 0},
+	} {
+		t.Run(string(tt.cmd), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			keys := testutil.GenerateKeys(t, 2, testutil.GenerateECDSAKey)
+			builder := testutil.TestModules(t, ctrl, 2, keys[1])
+			cfg, replicas := testutil.CreateMockConfigurationWithReplicas(t, ctrl, 2, keys...)
+			mockSync := mocks.NewMockSynchronizer(ctrl)
+			builder.Register(consensus.New(chainedhotstuff.New()), cfg, mockSync, rejectCommand("invalid"), eventloop.NewSynchronous())
+			hs := builder.Build()
+
+			mockSync.EXPECT().LeafBlock().AnyTimes().Return(consensus.GetGenesis())
+			mockSync.EXPECT().UpdateHighQC(gomock.Any()).AnyTimes()
+			mockSync.EXPECT().AdvanceView(gomock.Any()).AnyTimes()
+			// replica 1 is the leader of every view, and receives the votes.
+			replicas[0].EXPECT().Vote(gomock.Any()).Times(tt.votes)
+
+			hs.EventLoop().AddEvent(testutil.NewProposeMsg(
+				consensus.GetGenesis().Hash(),
+				consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+				tt.cmd, 1, 1,
+			))
+			hs.EventLoop().RunUntilIdle()
+		})
+	}
+}
+
+// TestMissingModules checks that building an incomplete set of modules reports every missing module
+// along with the modules that require it.
+func TestMissingModules(t *testing.T) {
+	builder := consensus.NewBuilder(1, testutil.GenerateECDSAKey(t))
+	builder.Register(
+		consensus.New(chainedhotstuff.New()),
+		synchronizer.New(testutil.FixedTimeout(1000)),
+		blockchain.New(),
+	)
+	_, err := builder.TryBuild()
+
+	want := "missing modules: Configuration, Crypto required by *consensus.VotingMachine; " +
+		"Acceptor, CommandQueue, Configuration, Crypto, Executor, LeaderRotation required by *chainedhotstuff.ChainedHotStuff; " +
+		"Configuration, Crypto, LeaderRotation required by *synchronizer.Synchronizer; " +
+		"Configuration required by *blockchain.blockChain"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %q, want %q", err, want)
+	}
+	if !errors.Is(err, consensus.ErrMissingModule) {
+		t.Error("the error does not match ErrMissingModule")
+	}
+	var missingErr *consensus.MissingModulesError
+	if !errors.As(err, &missingErr) {
+		t.Fatal("the error is not a *MissingModulesError")
+	}
+	if got := missingErr.Missing["*synchronizer.Synchronizer"]; !reflect.DeepEqual(got, []consensus.ModuleName{
+		consensus.ConfigurationModule, consensus.CryptoModule, consensus.LeaderRotationModule,
+	}) {
+		t.Errorf("got missing modules %v for the synchronizer", got)
+	}
+}
+
+// TestOptionalModule checks that an optional module that has not been registered is reported by Get.
+func TestOptionalModule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	builder := testutil.TestModules(t, ctrl, 1, testutil.GenerateECDSAKey(t))
+	mods := builder.Build()
+
+	if _, err := mods.Get(consensus.ForkHandlerModule); !errors.Is(err, consensus.ErrMissingModule) {
+		t.Errorf("got error %v for the fork handler, want ErrMissingModule", err)
+	}
+	if m, err := mods.Get(consensus.BlockChainModule); err != nil || m != mods.BlockChain() {
+		t.Errorf("got %v, %v for the block chain, want the registered block chain", m, err)
+	}
+	// the command validator is optional, and every command is valid if it has not been registered.
+	if _, err := mods.Get(consensus.CommandValidatorModule); !errors.Is(err, consensus.ErrMissingModule) {
+		t.Errorf("got error %v for the command validator, want ErrMissingModule", err)
+	}
+	if err := mods.CommandValidator().Validate("foo"); err != nil {
+		t.Errorf("the default command validator rejected a command: %v", err)
+	}
+}
+
+// fakeBlockChain stores blocks in a map, and counts the number of times each block is retrieved.
+type fakeBlockChain struct {
+	blocks map[consensus.Hash]*consensus.Block
+	gets   map[consensus.Hash]int
+}
+
+func newFakeBlockChain() *fakeBlockChain {
+	chain := &fakeBlockChain{
+		blocks: make(map[consensus.Hash]*consensus.Block),
+		gets:   make(map[consensus.Hash]int),
+	}
+	chain.Store(consensus.GetGenesis())
+	return chain
+}
+
+func (chain *fakeBlockChain) Store(block *consensus.Block) { chain.blocks[block.Hash()] = block }
+
+func (chain *fakeBlockChain) Get(hash consensus.Hash) (*consensus.Block, bool) {
+	chain.gets[hash]++
+	return chain.LocalGet(hash)
+}
+
+func (chain *fakeBlockChain) LocalGet(hash consensus.Hash) (*consensus.Block, bool) {
+	block, ok := chain.blocks[hash]
+	return block, ok
+}
+
+func (chain *fakeBlockChain) Extends(_, _ *consensus.Block) bool { return false }
+
+func (chain *fakeBlockChain) PruneToHeight(_ consensus.View) []*consensus.Block { return nil }
+
+func (chain *fakeBlockChain) DiscardBelow(_ consensus.View) {}
+
+func (chain *fakeBlockChain) Range(_ *consensus.Block, _ consensus.View) ([]*consensus.Block, bool) {
+	return nil, false
+}
+
+// commitTarget is a set of rules that votes for every block, and commits the target block.
+type commitTarget struct {
+	target *consensus.Block
+}
+
+func (r *commitTarget) VoteRule(_ consensus.ProposeMsg) bool { return true }
+
+func (r *commitTarget) CommitRule(_ *consensus.Block) *consensus.Block { return r.target }
+
+func (r *commitTarget) ChainLength() int { return 3 }
+
+// execRecorder records the blocks that are executed, in order.
+type execRecorder struct {
+	executed []*consensus.Block
+}
+
+func (r *execRecorder) Exec(block *consensus.Block) { r.executed = append(r.executed, block) }
+
+// TestCommitLongChain checks that a replica that commits a chain of thousands of uncommitted blocks, as it may after
+// a long partition, executes each of them once and in order, and that no block is executed if an ancestor is missing.
+func TestCommitLongChain(t *testing.T) {
+	const n = 5000
+	chain := newFakeBlockChain()
+	blocks := make([]*consensus.Block, n)
+	parent := consensus.GetGenesis()
+	for i := range blocks {
+		blocks[i] = consensus.NewBlock(
+			parent.Hash(),
+			consensus.NewQuorumCert(nil, parent.View(), parent.Hash()),
+			consensus.Command(strconv.Itoa(i)), consensus.View(i+1), 1,
+		)
+		chain.Store(blocks[i])
+		parent = blocks[i]
+	}
+	tip := blocks[n-1]
+
+	ctrl := gomock.NewController(t)
+	keys := testutil.GenerateKeys(t, 2, testutil.GenerateECDSAKey)
+	builder := testutil.TestModules(t, ctrl, 2, keys[1])
+	cfg, replicas := testutil.CreateMockConfigurationWithReplicas(t, ctrl, 2, keys...)
+	mockSync := mocks.NewMockSynchronizer(ctrl)
+	rules := &commitTarget{}
+	recorder := &execRecorder{}
+	builder.Register(consensus.New(rules), chain, recorder, cfg, mockSync, eventloop.NewSynchronous())
+	hs := builder.Build()
+
+	mockSync.EXPECT().UpdateHighQC(gomock.Any()).AnyTimes()
+	mockSync.EXPECT().AdvanceView(gomock.Any()).AnyTimes()
+	replicas[0].EXPECT().Vote(gomock.Any()).AnyTimes()
+
+	view := consensus.View(n)
+	commit := func(target *consensus.Block) {
+		t.Helper()
+		rules.target = target
+		view++
+		hs.EventLoop().AddEvent(testutil.NewProposeMsg(
+			consensus.GetGenesis().Hash(),
+			consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+			"foo", view, 1,
+		))
+		hs.EventLoop().RunUntilIdle()
+	}
+
+	// a block in the middle of the chain is missing, so none of the blocks can be committed.
+	delete(chain.blocks, blocks[n/2].Hash())
+	commit(tip)
+	if len(recorder.executed) != 0 {
+		t.Fatalf("executed %d blocks with a missing ancestor, want 0", len(recorder.executed))
+	}
+	if committed := hs.Consensus().CommittedBlock(); committed != consensus.GetGenesis() {
+		t.Fatalf("the committed block is %v, want the genesis block", committed)
+	}
+
+	chain.Store(blocks[n/2])
+	chain.gets = make(map[consensus.Hash]int)
+	commit(tip)
+	if len(recorder.executed) != n {
+		t.Fatalf("executed %d blocks, want %d", len(recorder.executed), n)
+	}
+	for i, block := range recorder.executed {
+		if block != blocks[i] {
+			t.Fatalf("block %d executed in view %d, want view %d", i, block.View(), blocks[i].View())
+		}
+	}
+	// each ancestor of the tip is retrieved once on the way down to the committed block.
+	for _, block := range blocks[:n-1] {
+		if gets := chain.gets[block.Hash()]; gets != 1 {
+			t.Fatalf("the block of view %d was retrieved %d times, want 1", block.View(), gets)
+		}
+	}
+	if committed := hs.Consensus().CommittedBlock(); committed != tip {
+		t.Fatalf("the committed block is %v, want %v", committed, tip)
+	}
+
+	// committing the tip or one of its ancestors again executes nothing.
+	commit(tip)
+	commit(blocks[n/2])
+	if len(recorder.executed) != n {
+		t.Errorf("executed %d blocks after committing the chain again, want %d", len(recorder.executed), n)
+	}
+	if committed := hs.Consensus().CommittedBlock(); committed != tip {
+		t.Errorf("the committed block is %v, want %v", committed, tip)
+	}
+}
+
+// TestBlockCommitEvents checks that a BlockCommitEvent is emitted exactly once for every block that is executed,
+// with the parent of a block before the block itself, and that no event is emitted for a chain with a missing ancestor.
+func TestBlockCommitEvents(t *testing.T) {
+	const n = 10
+	chain := newFakeBlockChain()
+	blocks := make([]*consensus.Block, n)
+	parent := consensus.GetGenesis()
+	for i := range blocks {
+		blocks[i] = consensus.NewBlock(
+			parent.Hash(),
+			consensus.NewQuorumCert(nil, parent.View(), parent.Hash()),
+			consensus.Command(strconv.Itoa(i)), consensus.View(i+1), hotstuff.ID(i%2+1),
+		)
+		chain.Store(blocks[i])
+		parent = blocks[i]
+	}
+
+	ctrl := gomock.NewController(t)
+	keys := testutil.GenerateKeys(t, 2, testutil.GenerateECDSAKey)
+	builder := testutil.TestModules(t, ctrl, 2, keys[1])
+	cfg, replicas := testutil.CreateMockConfigurationWithReplicas(t, ctrl, 2, keys...)
+	mockSync := mocks.NewMockSynchronizer(ctrl)
+	rules := &commitTarget{}
+	recorder := &execRecorder{}
+	builder.Register(consensus.New(rules), chain, recorder, cfg, mockSync, eventloop.NewSynchronous())
+	hs := builder.Build()
+
+	mockSync.EXPECT().UpdateHighQC(gomock.Any()).AnyTimes()
+	mockSync.EXPECT().AdvanceView(gomock.Any()).AnyTimes()
+	replicas[0].EXPECT().Vote(gomock.Any()).AnyTimes()
+
+	var commits []consensus.BlockCommitEvent
+	hs.EventLoop().RegisterHandler(consensus.BlockCommitEvent{}, func(event interface{}) {
+		commits = append(commits, event.(consensus.BlockCommitEvent))
+	})
+
+	view := consensus.View(n)
+	commit := func(target *consensus.Block) {
+		t.Helper()
+		rules.target = target
+		view++
+		hs.EventLoop().AddEvent(testutil.NewProposeMsg(
+			consensus.GetGenesis().Hash(),
+			consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+			"foo", view, 1,
+		))
+		hs.EventLoop().RunUntilIdle()
+	}
+
+	delete(chain.blocks, blocks[2].Hash())
+	commit(blocks[5])
+	if len(commits) != 0 {
+		t.Fatalf("got %d commit events with a missing ancestor, want 0", len(commits))
+	}
+
+	chain.Store(blocks[2])
+	commit(blocks[5])
+	commit(blocks[3])
+	commit(blocks[n-1])
+	commit(blocks[n-1])
+	if len(commits) != n {
+		t.Fatalf("got %d commit events, want %d", len(commits), n)
+	}
+	for i, event := range commits {
+		if event.Block != blocks[i] || event.Block != recorder.executed[i] {
+			t.Fatalf("commit event %d is for the block of view %d, want view %d", i, event.Block.View(), blocks[i].View())
+		}
+		if event.Timestamp.IsZero() || (i > 0 && event.Timestamp.Before(commits[i-1].Timestamp)) {
+			t.Errorf("commit event %d has the timestamp %v, want a time no earlier than the previous event", i, event.Timestamp)
+		}
+	}
+}
+
+// followerWithChain creates replica 2 of four replicas, and a chain of n certified blocks proposed by replica 1,
+// which is the leader of every view. It returns the modules of replica 2, the proposals, the hashes of the blocks
+// that replica 2 votes for, in the order it votes, and the mocked configuration and synchronizer of replica 2.
+func followerWithChain(t *testing.T, n int) (*consensus.Modules, []consensus.ProposeMsg, *[]consensus.Hash, *mocks.MockConfiguration, *mocks.MockSynchronizer) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	keys := testutil.GenerateKeys(t, 4, testutil.GenerateECDSAKey)
+	signers := testutil.CreateBuilders(t, ctrl, 4, keys...).Build().Signers()
+
+	builder := testutil.TestModules(t, ctrl, 2, keys[1])
+	cfg, replicas := testutil.CreateMockConfigurationWithReplicas(t, ctrl, 4, keys...)
+	mockSync := mocks.NewMockSynchronizer(ctrl)
+	builder.Register(consensus.New(chainedhotstuff.New()), cfg, mockSync, eventloop.NewSynchronous())
+	hs := builder.Build()
+
+	mockSync.EXPECT().UpdateHighQC(gomock.Any()).AnyTimes()
+	mockSync.EXPECT().AdvanceView(gomock.Any()).AnyTimes()
+	votes := new([]consensus.Hash)
+	replicas[0].EXPECT().Vote(gomock.Any()).AnyTimes().Do(func(pc consensus.PartialCert) {
+		*votes = append(*votes, pc.BlockHash())
+	})
+
+	var proposals []consensus.ProposeMsg
+	parent := consensus.GetGenesis()
+	qc := consensus.NewQuorumCert(nil, 0, parent.Hash())
+	for i := 0; i < n; i++ {
+		proposal := testutil.NewProposeMsg(parent.Hash(), qc, consensus.Command(strconv.Itoa(i)), consensus.View(i+1), 1)
+		proposals = append(proposals, proposal)
+		parent = proposal.Block
+		qc = testutil.CreateQC(t, parent, signers[:3])
+	}
+	return hs, proposals, votes, cfg, mockSync
+}
+
+// reverseOrderReplica is like followerWithChain, but the missing blocks cannot be fetched from the other replicas.
+func reverseOrderReplica(t *testing.T, n int) (*consensus.Modules, []consensus.ProposeMsg, *[]consensus.Hash) {
+	t.Helper()
+	hs, proposals, votes, cfg, mockSync := followerWithChain(t, n)
+	mockSync.EXPECT().ViewContext().AnyTimes().Return(context.Background())
+	cfg.EXPECT().Fetch(gomock.Any(), gomock.Any()).AnyTimes().Return(nil, false)
+	return hs, proposals, votes
+}
+
+// TestProposalsInReverseOrder checks that a replica that receives proposals before their parents, as it may when it
+// rejoins after a partition, holds them until the parents arrive, and then votes for every block in order.
+func TestProposalsInReverseOrder(t *testing.T) {
+	const n = 10
+	hs, proposals, votes := reverseOrderReplica(t, n)
+
+	for i := n - 1; i >= 0; i-- {
+		hs.EventLoop().AddEvent(proposals[i])
+		hs.EventLoop().RunUntilIdle()
+		if i > 0 && len(*votes) != 0 {
+			t.Fatalf("voted for %d blocks before the first block arrived", len(*votes))
+		}
+	}
+
+	if len(*votes) != n {
+		t.Fatalf("voted for %d blocks, want %d", len(*votes), n)
+	}
+	for i, hash := range *votes {
+		if hash != proposals[i].Block.Hash() {
+			t.Errorf("vote %d is for the wrong block", i)
+		}
+	}
+	if committed := hs.Consensus().CommittedBlock().View(); committed != n-3 {
+		t.Errorf("committed view %d, want %d", committed, n-3)
+	}
+}
+
+// TestHeldProposalsAreBounded checks that a replica holds at most MaxPendingProposals proposals, and drops the one
+// with the lowest view when it has to hold more.
+func TestHeldProposalsAreBounded(t *testing.T) {
+	const n = consensus.MaxPendingProposals + 2
+	hs, proposals, votes := reverseOrderReplica(t, n)
+
+	// the proposal of view 2 is the lowest one held when there are too many, so it is dropped.
+	for i := n - 1; i >= 1; i-- {
+		hs.EventLoop().AddEvent(proposals[i])
+	}
+	hs.EventLoop().AddEvent(proposals[0])
+	hs.EventLoop().RunUntilIdle()
+	if len(*votes) != 1 {
+		t.Fatalf("voted for %d blocks, want 1", len(*votes))
+	}
+
+	// once the dropped proposal is delivered again, the rest of the held proposals are released.
+	hs.EventLoop().AddEvent(proposals[1])
+	hs.EventLoop().RunUntilIdle()
+	if len(*votes) != n {
+		t.Fatalf("voted for %d blocks, want %d", len(*votes), n)
+	}
+}
+
+// runUntil runs the event loop until cond returns true, waiting for the events that other goroutines add to it.
+func runUntil(t *testing.T, hs *consensus.Modules, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for hs.EventLoop().RunUntilIdle(); !cond(); hs.EventLoop().RunUntilIdle() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestFetchMissingParent checks that a replica fetches the missing parent of proposals once, however many proposals
+// refer to it, and votes for the proposals when the parent has been fetched.
+func TestFetchMissingParent(t *testing.T) {
+	hs, proposals, votes, cfg, mockSync := followerWithChain(t, 2)
+	parent := proposals[0].Block
+	// a proposal in a later view that extends the same missing block, as after a view without a proposal.
+	later := testutil.NewProposeMsg(parent.Hash(), proposals[1].Block.QuorumCert(), "later", 3, 1)
+
+	mockSync.EXPECT().ViewContext().AnyTimes().Return(context.Background())
+	fetched := make(chan struct{})
+	cfg.EXPECT().Fetch(gomock.Any(), parent.Hash()).Times(1).DoAndReturn(func(context.Context, consensus.Hash) (*consensus.Block, bool) {
+		<-fetched
+		return parent, true
+	})
+
+	hs.EventLoop().AddEvent(proposals[1])
+	hs.EventLoop().AddEvent(later)
+	hs.EventLoop().RunUntilIdle()
+	if len(*votes) != 0 {
+		t.Fatalf("voted for %d blocks before the parent was fetched", len(*votes))
+	}
+	close(fetched)
+	runUntil(t, hs, "the votes", func() bool { return len(*votes) == 2 })
+	if (*votes)[0] != proposals[1].Block.Hash() || (*votes)[1] != later.Block.Hash() {
+		t.Error("voted for the wrong blocks")
+	}
+}
+
+// TestFetchTimeout checks that a fetch of a missing parent ends with the view, and that the parent is fetched again
+// when a proposal that extends it is received in a later view.
+func TestFetchTimeout(t *testing.T) {
+	hs, proposals, votes, cfg, mockSync := followerWithChain(t, 2)
+	parent := proposals[0].Block
+
+	viewCtx, endView := context.WithCancel(context.Background())
+	mockSync.EXPECT().ViewContext().AnyTimes().DoAndReturn(func() context.Context { return viewCtx })
+	first := cfg.EXPECT().Fetch(gomock.Any(), parent.Hash()).DoAndReturn(func(ctx context.Context, _ consensus.Hash) (*consensus.Block, bool) {
+		<-ctx.Done()
+		return nil, false
+	})
+	cfg.EXPECT().Fetch(gomock.Any(), parent.Hash()).After(first).Return(parent, true)
+	var failed int
+	hs.EventLoop().RegisterObserver(consensus.FetchedEvent{}, func(event interface{}) {
+		if event.(consensus.FetchedEvent).Block == nil {
+			failed++
+		}
+	})
+
+	hs.EventLoop().AddEvent(proposals[1])
+	hs.EventLoop().RunUntilIdle()
+	endView()
+	runUntil(t, hs, "the fetch to fail", func() bool { return failed == 1 })
+	if len(*votes) != 0 {
+		t.Fatalf("voted for %d blocks without the parent", len(*votes))
+	}
+
+	viewCtx = context.Background()
+	hs.EventLoop().AddEvent(proposals[1])
+	runUntil(t, hs, "the vote", func() bool { return len(*votes) == 1 })
+}
+
+// TestProposeFetchesQCBlock checks that a leader that does not have the block certified by its QC fetches it,
+// and then makes its proposal.
+func TestProposeFetchesQCBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	keys := testutil.GenerateKeys(t, 4, testutil.GenerateECDSAKey)
+	signers := testutil.CreateBuilders(t, ctrl, 4, keys...).Build().Signers()
+	builder := testutil.TestModules(t, ctrl, 1, keys[0])
+	cfg, _ := testutil.CreateMockConfigurationWithReplicas(t, ctrl, 4, keys...)
+	mockSync := mocks.NewMockSynchronizer(ctrl)
+	builder.Register(consensus.New(chainedhotstuff.New()), cfg, mockSync, eventloop.NewSynchronous())
+	builder.OptionsBuilder().SetShouldVerifyVotesSync()
+	hs := builder.Build()
+
+	qcBlock := testutil.NewProposeMsg(
+		consensus.GetGenesis().Hash(),
+		consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+		"foo", 1, 1,
+	).Block
+	qc := testutil.CreateQC(t, qcBlock, signers[:3])
+
+	mockSync.EXPECT().ViewContext().AnyTimes().Return(context.Background())
+	mockSync.EXPECT().View().AnyTimes().Return(consensus.View(2))
+	mockSync.EXPECT().LeafBlock().AnyTimes().Return(qcBlock)
+	mockSync.EXPECT().UpdateHighQC(gomock.Any()).AnyTimes()
+	mockSync.EXPECT().AdvanceView(gomock.Any()).AnyTimes()
+	cfg.EXPECT().Fetch(gomock.Any(), qcBlock.Hash()).Times(1).Return(qcBlock, true)
+	var proposals []consensus.ProposeMsg
+	cfg.EXPECT().Propose(gomock.Any()).Times(1).Do(func(proposal consensus.ProposeMsg) {
+		proposals = append(proposals, proposal)
+	})
+
+	hs.Consensus().Propose(consensus.NewSyncInfo().WithQC(qc))
+	runUntil(t, hs, "the proposal", func() bool { return len(proposals) == 1 })
+	if block := proposals[0].Block; block.Parent() != qcBlock.Hash() || block.View() != 2 {
+		t.Errorf("proposed %v, want a block of view 2 that extends the fetched block", block)
+	}
+}
+
+// customProposal is a set of rules that creates its own proposals, with an AggregateQC.
+type customProposal struct {
+	chainedhotstuff.ChainedHotStuff
+	aggQC    consensus.AggregateQC
+	cmds     []consensus.Command
+	proposal consensus.ProposeMsg
+}
+
+func (r *customProposal) ProposeRule(cert consensus.SyncInfo, cmd consensus.Command) (consensus.ProposeMsg, bool) {
+	r.cmds = append(r.cmds, cmd)
+	qc, _ := cert.QC()
+	r.proposal = consensus.ProposeMsg{
+		ID:          1,
+		Block:       consensus.NewBlock(qc.BlockHash(), qc, cmd, 1, 1),
+		AggregateQC: &r.aggQC,
+	}
+	return r.proposal, true
+}
+
+// TestProposeRule checks that the proposals of rules that implement ProposeRuler are stored and broadcast as they
+// are created, instead of the default proposals.
+func TestProposeRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	keys := testutil.GenerateKeys(t, 4, testutil.GenerateECDSAKey)
+	builder := testutil.TestModules(t, ctrl, 1, keys[0])
+	cfg, _ := testutil.CreateMockConfigurationWithReplicas(t, ctrl, 4, keys...)
+	mockSync := mocks.NewMockSynchronizer(ctrl)
+	rules := &customProposal{ChainedHotStuff: *chainedhotstuff.New().(*chainedhotstuff.ChainedHotStuff)}
+	builder.Register(consensus.New(rules), cfg, mockSync, eventloop.NewSynchronous())
+	builder.OptionsBuilder().SetShouldVerifyVotesSync()
+	hs := builder.Build()
+
+	genesisQC := consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash())
+	rules.aggQC = consensus.NewAggregateQC(map[hotstuff.ID]consensus.QuorumCert{2: genesisQC}, nil, 1)
+	mockSync.EXPECT().ViewContext().AnyTimes().Return(context.Background())
+	mockSync.EXPECT().View().AnyTimes().Return(consensus.View(1))
+	// the leaf block is used by the voting machine when the leader votes for its own proposal.
+	mockSync.EXPECT().LeafBlock().AnyTimes().Return(consensus.GetGenesis())
+	mockSync.EXPECT().UpdateHighQC(gomock.Any()).AnyTimes()
+	mockSync.EXPECT().AdvanceView(gomock.Any()).AnyTimes()
+	var broadcast []consensus.ProposeMsg
+	cfg.EXPECT().Propose(gomock.Any()).Times(1).Do(func(proposal consensus.ProposeMsg) {
+		broadcast = append(broadcast, proposal)
+	})
+
+	hs.Consensus().Propose(consensus.NewSyncInfo().WithQC(genesisQC))
+	hs.EventLoop().RunUntilIdle()
+
+	if want := []consensus.Command{"foo"}; !reflect.DeepEqual(rules.cmds, want) {
+		t.Fatalf("the rules got the commands %v, want %v", rules.cmds, want)
+	}
+	if len(broadcast) != 1 || !reflect.DeepEqual(broadcast[0], rules.proposal) {
+		t.Fatalf("broadcast %v, want %v", broadcast, rules.proposal)
+	}
+	if broadcast[0].AggregateQC != &rules.aggQC {
+		t.Error("the AggregateQC of the proposal was replaced")
+	}
+	if stored, ok := hs.BlockChain().LocalGet(rules.proposal.Block.Hash()); !ok || stored != rules.proposal.Block {
+		t.Error("the block of the proposal was not stored")
+	}
+}
+
+// TestAggregateQCRejected checks that a replica using AggregateQCs only votes for a proposal whose AggregateQC can be
+// verified and whose block extends the highQC of the AggregateQC, and that it reports the proposals that it rejects.
+func TestAggregateQCRejected(t *testing.T) {
+	genesisQC := consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash())
+	otherQC := consensus.NewQuorumCert(nil, 1, consensus.Hash{1})
+	for _, tt := range []struct {
+		name     string
+		ok       bool
+		highQC   consensus.QuorumCert
+		rejected bool
+	}{
+		{"valid", true, genesisQC, false},
+		{"invalid signature", false, consensus.QuorumCert{}, true},
+		{"highQC mismatch", true, otherQC, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			keys := testutil.GenerateKeys(t, 2, testutil.GenerateECDSAKey)
+			builder := testutil.TestModules(t, ctrl, 2, keys[1])
+			cfg, replicas := testutil.CreateMockConfigurationWithReplicas(t, ctrl, 2, keys...)
+			mockSync := mocks.NewMockSynchronizer(ctrl)
+			mockCrypto := mocks.NewMockCrypto(ctrl)
+			builder.Register(consensus.New(chainedhotstuff.New()), cfg, mockSync, mockCrypto, eventloop.NewSynchronous())
+			builder.OptionsBuilder().SetShouldUseAggQC()
+			hs := builder.Build()
+
+			mockSync.EXPECT().LeafBlock().AnyTimes().Return(consensus.GetGenesis())
+			mockSync.EXPECT().UpdateHighQC(gomock.Any()).AnyTimes()
+			mockSync.EXPECT().AdvanceView(gomock.Any()).AnyTimes()
+			mockCrypto.EXPECT().VerifyQuorumCert(gomock.Any()).AnyTimes().Return(true)
+			mockCrypto.EXPECT().VerifyAggregateQC(gomock.Any()).Return(tt.ok, tt.highQC)
+
+			votes := 1
+			if tt.rejected {
+				votes = 0
+			}
+			mockCrypto.EXPECT().CreatePartialCert(gomock.Any()).Times(votes).DoAndReturn(func(block *consensus.Block) (consensus.PartialCert, error) {
+				return consensus.NewPartialCert(nil, block.Hash()), nil
+			})
+			replicas[0].EXPECT().Vote(gomock.Any()).Times(votes)
+
+			var rejected []consensus.AggregateQCRejectedEvent
+			hs.EventLoop().RegisterObserver(consensus.AggregateQCRejectedEvent{}, func(event interface{}) {
+				rejected = append(rejected, event.(consensus.AggregateQCRejectedEvent))
+			})
+
+			proposal := testutil.NewProposeMsg(consensus.GetGenesis().Hash(), genesisQC, "foo", 1, 1)
+			aggQC := consensus.NewAggregateQC(map[hotstuff.ID]consensus.QuorumCert{1: genesisQC}, nil, 0)
+			proposal.AggregateQC = &aggQC
+			hs.EventLoop().AddEvent(proposal)
+			hs.EventLoop().RunUntilIdle()
+
+			if !tt.rejected {
+				if len(rejected) != 0 {
+					t.Errorf("got %d rejections, want none", len(rejected))
+				}
+				return
+			}
+			if len(rejected) != 1 {
+				t.Fatalf("got %d rejections, want 1", len(rejected))
+			}
+			if got := rejected[0]; got.ID != 1 || got.View != 1 || got.Reason == "" {
+				t.Errorf("got rejection %+v, want one for view 1 from replica 1 with a reason", got)
+			}
+		})
+	}
+}
+
+// crashAfterVote is a safety storage that simulates a crash of the replica right after the vote is persisted,
+// and before it is sent to the leader.
+type crashAfterVote struct {
+	consensus.SafetyStorage
+}
+
+func (s crashAfterVote) StoreVote(view consensus.View) error {
+	if err := s.SafetyStorage.StoreVote(view); err != nil {
+		return err
+	}
+	panic("crashed after persisting the vote")
+}
+
+// restartedFollower creates replica 2 of two replicas with the given rules, safety storage, and additional modules,
+// where replica 1 is the leader of every view. It returns the modules of replica 2, and the mocked replica 1,
+// which receives the votes.
+func restartedFollower(t *testing.T, keys []consensus.PrivateKey, rules consensus.Rules, storage consensus.SafetyStorage, modules ...interface{}) (*consensus.Modules, *mocks.MockReplica) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	builder := testutil.TestModules(t, ctrl, 2, keys[1])
+	cfg, replicas := testutil.CreateMockConfigurationWithReplicas(t, ctrl, 2, keys...)
+	mockSync := mocks.NewMockSynchronizer(ctrl)
+	builder.Register(consensus.New(rules), cfg, mockSync, storage, eventloop.NewSynchronous())
+	builder.Register(modules...)
+	hs := builder.Build()
+
+	mockSync.EXPECT().LeafBlock().AnyTimes().Return(consensus.GetGenesis())
+	mockSync.EXPECT().UpdateHighQC(gomock.Any()).AnyTimes()
+	mockSync.EXPECT().AdvanceView(gomock.Any()).AnyTimes()
+	return hs, replicas[0]
+}
+
+// TestNoDoubleVoteAfterCrash checks that a replica that crashes after persisting its vote, but before sending it,
+// does not vote again in the same view when it is restarted, even for a conflicting block.
+func TestNoDoubleVoteAfterCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "safety")
+	keys := testutil.GenerateKeys(t, 2, testutil.GenerateECDSAKey)
+	genesisQC := consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash())
+	proposal := testutil.NewProposeMsg(consensus.GetGenesis().Hash(), genesisQC, "foo", 1, 1)
+	conflicting := testutil.NewProposeMsg(consensus.GetGenesis().Hash(), genesisQC, "bar", 1, 1)
+	next := testutil.NewProposeMsg(proposal.Block.Hash(), genesisQC, "baz", 2, 1)
+
+	hs, leader := restartedFollower(t, keys, chainedhotstuff.New(), crashAfterVote{safety.NewFile(path)})
+	leader.EXPECT().Vote(gomock.Any()).Times(0)
+	hs.EventLoop().AddEvent(proposal)
+	hs.EventLoop().RunUntilIdle()
+	if panics := hs.EventLoop().Panics()["consensus.ProposeMsg"]; panics != 1 {
+		t.Fatalf("the replica crashed %d times, want 1", panics)
+	}
+
+	hs, leader = restartedFollower(t, keys, chainedhotstuff.New(), safety.NewFile(path))
+	var votes []consensus.View
+	leader.EXPECT().Vote(gomock.Any()).AnyTimes().Do(func(pc consensus.PartialCert) {
+		block, _ := hs.BlockChain().LocalGet(pc.BlockHash())
+		votes = append(votes, block.View())
+	})
+	for _, p := range []consensus.ProposeMsg{proposal, conflicting, next} {
+		hs.EventLoop().AddEvent(p)
+		hs.EventLoop().RunUntilIdle()
+	}
+	if !reflect.DeepEqual(votes, []consensus.View{2}) {
+		t.Errorf("the restarted replica voted in the views %v, want only view 2", votes)
+	}
+}
+
+// TestNoProposalAfterRestart checks that a leader that is restarted does not propose again in a view that it voted in
+// before the restart, but proposes in the next view that it leads.
+func TestNoProposalAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "safety")
+	if err := safety.NewFile(path).StoreVote(1); err != nil {
+		t.Fatal(err)
+	}
+	ctrl := gomock.NewController(t)
+	keys := testutil.GenerateKeys(t, 4, testutil.GenerateECDSAKey)
+	builder := testutil.TestModules(t, ctrl, 1, keys[0])
+	cfg, _ := testutil.CreateMockConfigurationWithReplicas(t, ctrl, 4, keys...)
+	mockSync := mocks.NewMockSynchronizer(ctrl)
+	builder.Register(consensus.New(chainedhotstuff.New()), cfg, mockSync, safety.NewFile(path), eventloop.NewSynchronous())
+	builder.OptionsBuilder().SetShouldVerifyVotesSync()
+	hs := builder.Build()
+
+	view := consensus.View(1)
+	mockSync.EXPECT().View().AnyTimes().DoAndReturn(func() consensus.View { return view })
+	mockSync.EXPECT().ViewContext().AnyTimes().Return(context.Background())
+	mockSync.EXPECT().LeafBlock().AnyTimes().Return(consensus.GetGenesis())
+	mockSync.EXPECT().UpdateHighQC(gomock.Any()).AnyTimes()
+	mockSync.EXPECT().AdvanceView(gomock.Any()).AnyTimes()
+	var proposed []consensus.View
+	cfg.EXPECT().Propose(gomock.Any()).AnyTimes().Do(func(proposal consensus.ProposeMsg) {
+		proposed = append(proposed, proposal.Block.View())
+	})
+
+	genesisQC := consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash())
+	for view = 1; view <= 2; view++ {
+		hs.Consensus().Propose(consensus.NewSyncInfo().WithQC(genesisQC))
+		hs.EventLoop().RunUntilIdle()
+	}
+	if !reflect.DeepEqual(proposed, []consensus.View{2}) {
+		t.Errorf("the restarted leader proposed in the views %v, want only view 2", proposed)
+	}
+}
+
+// TestExecutionResumesAfterRestart checks that a replica that is restarted resumes committing after the last block
+// that it executed before it was restarted, instead of executing the chain again from the genesis block.
+func TestExecutionResumesAfterRestart(t *testing.T) {
+	const n = 8
+	path := filepath.Join(t.TempDir(), "safety")
+	keys := testutil.GenerateKeys(t, 2, testutil.GenerateECDSAKey)
+	chain := newFakeBlockChain()
+	blocks := make([]*consensus.Block, n)
+	parent := consensus.GetGenesis()
+	for i := range blocks {
+		blocks[i] = consensus.NewBlock(
+			parent.Hash(),
+			consensus.NewQuorumCert(nil, parent.View(), parent.Hash()),
+			consensus.Command(strconv.Itoa(i)), consensus.View(i+1), 1,
+		)
+		chain.Store(blocks[i])
+		parent = blocks[i]
+	}
+
+	view := consensus.View(n)
+	commit := func(target *consensus.Block) *execRecorder {
+		t.Helper()
+		recorder := &execRecorder{}
+		hs, leader := restartedFollower(t, keys, &commitTarget{target: target}, safety.NewFile(path), chain, recorder)
+		leader.EXPECT().Vote(gomock.Any()).AnyTimes()
+		view++
+		hs.EventLoop().AddEvent(testutil.NewProposeMsg(
+			consensus.GetGenesis().Hash(),
+			consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash()),
+			"foo", view, 1,
+		))
+		hs.EventLoop().RunUntilIdle()
+		if committed := hs.Consensus().CommittedBlock(); committed.Hash() != target.Hash() {
+			t.Fatalf("the committed block is in view %d, want view %d", committed.View(), target.View())
+		}
+		return recorder
+	}
+
+	if executed := commit(blocks[3]).executed; len(executed) != 4 {
+		t.Fatalf("executed %d blocks, want 4", len(executed))
+	}
+	executed := commit(blocks[n-1]).executed
+	if len(executed) != n-4 {
+		t.Fatalf("executed %d blocks after the restart, want %d", len(executed), n-4)
+	}
+	for i, block := range executed {
+		if block != blocks[i+4] {
+			t.Fatalf("executed the block of view %d after the restart, want view %d", block.View(), blocks[i+4].View())
+		}
+	}
+}
+
+// observeEquivocations records the equivocation events that are emitted by the replica.
+func observeEquivocations(hs *consensus.Modules) *[]consensus.EquivocationEvent {
+	events := new([]consensus.EquivocationEvent)
+	hs.EventLoop().RegisterObserver(consensus.EquivocationEvent{}, func(event interface{}) {
+		*events = append(*events, event.(consensus.EquivocationEvent))
+	})
+	return events
+}
+
+// conflictingProposal returns a proposal for a different block in the same view as the given proposal.
+func conflictingProposal(proposal consensus.ProposeMsg) consensus.ProposeMsg {
+	block := proposal.Block
+	return testutil.NewProposeMsg(block.Parent(), block.QuorumCert(), block.Command()+"'", block.View(), proposal.ID)
+}
+
+// TestEquivocation checks that a replica emits evidence when the leader proposes a block that conflicts with the one
+// the replica voted for, but not when it receives the same proposal twice, or a conflicting proposal for a view that
+// was committed. A conflicting proposal whose parent is missing is reported once, when its parent has been fetched.
+func TestEquivocation(t *testing.T) {
+	const n = 6
+	hs, proposals, votes, cfg, mockSync := followerWithChain(t, n)
+	events := observeEquivocations(hs)
+	deliver := func(proposal consensus.ProposeMsg) {
+		hs.EventLoop().AddEvent(proposal)
+		hs.EventLoop().RunUntilIdle()
+	}
+
+	for _, proposal := range proposals {
+		deliver(proposal)
+		deliver(proposal)
+	}
+	if len(*votes) != n {
+		t.Fatalf("voted %d times, want %d", len(*votes), n)
+	}
+	if len(*events) != 0 {
+		t.Fatalf("got %d equivocation events for duplicate proposals, want 0", len(*events))
+	}
+
+	// the block of view 1 was committed when the block of view 4 was proposed.
+	deliver(conflictingProposal(proposals[0]))
+	if len(*events) != 0 {
+		t.Fatalf("got %d equivocation events for a committed view, want 0", len(*events))
+	}
+
+	last := proposals[n-1]
+	conflict := conflictingProposal(last)
+	deliver(conflict)
+	if len(*votes) != n {
+		t.Errorf("voted %d times after the conflicting proposal, want %d", len(*votes), n)
+	}
+	if len(*events) != 1 {
+		t.Fatalf("got %d equivocation events, want 1", len(*events))
+	}
+	if e := (*events)[0]; e.First.Block.Hash() != last.Block.Hash() || e.Second.Block.Hash() != conflict.Block.Hash() {
+		t.Errorf("the evidence is for the blocks %.8s and %.8s, want %.8s and %.8s",
+			e.First.Block.Hash(), e.Second.Block.Hash(), last.Block.Hash(), conflict.Block.Hash())
+	}
+
+	// the parent of this conflicting proposal is unknown, so the proposal is held until the parent is fetched.
+	prev := proposals[n-2].Block
+	parent := consensus.NewBlock(prev.Parent(), prev.QuorumCert(), "unknown", prev.View(), 1)
+	held := testutil.NewProposeMsg(parent.Hash(), last.Block.QuorumCert(), "held", last.Block.View(), 1)
+	mockSync.EXPECT().ViewContext().AnyTimes().Return(context.Background())
+	fetched := make(chan struct{})
+	cfg.EXPECT().Fetch(gomock.Any(), parent.Hash()).DoAndReturn(func(context.Context, consensus.Hash) (*consensus.Block, bool) {
+		<-fetched
+		return parent, true
+	})
+	deliver(held)
+	if len(*events) != 1 {
+		t.Fatalf("got %d equivocation events while the proposal was held, want 1", len(*events))
+	}
+	close(fetched)
+	runUntil(t, hs, "the parent to be fetched", func() bool {
+		_, ok := hs.BlockChain().LocalGet(parent.Hash())
+		return ok
+	})
+	if len(*events) != 2 {
+		t.Fatalf("got %d equivocation events after the held proposal was released, want 2", len(*events))
+	}
+	if e := (*events)[1]; e.Second.Block.Hash() != held.Block.Hash() {
+		t.Errorf("the evidence is for the block %.8s, want %.8s", e.Second.Block.Hash(), held.Block.Hash())
+	}
+}
+
+// TestEquivocationRecordIsBounded checks that a replica that does not commit only remembers the proposals of the last
+// MaxVotedViews views that it voted in.
+func TestEquivocationRecordIsBounded(t *testing.T) {
+	const n = consensus.MaxVotedViews + 10
+	ctrl := gomock.NewController(t)
+	keys := testutil.GenerateKeys(t, 2, testutil.GenerateECDSAKey)
+	builder := testutil.TestModules(t, ctrl, 2, keys[1])
+	cfg, replicas := testutil.CreateMockConfigurationWithReplicas(t, ctrl, 2, keys...)
+	mockSync := mocks.NewMockSynchronizer(ctrl)
+	builder.Register(consensus.New(&commitTarget{}), cfg, mockSync, eventloop.NewSynchronous())
+	hs := builder.Build()
+
+	mockSync.EXPECT().UpdateHighQC(gomock.Any()).AnyTimes()
+	mockSync.EXPECT().AdvanceView(gomock.Any()).AnyTimes()
+	replicas[0].EXPECT().Vote(gomock.Any()).Times(n)
+	events := observeEquivocations(hs)
+
+	genesisQC := consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash())
+	proposals := make([]consensus.ProposeMsg, n)
+	for i := range proposals {
+		proposals[i] = testutil.NewProposeMsg(consensus.GetGenesis().Hash(), genesisQC, "foo", consensus.View(i+1), 1)
+		hs.EventLoop().AddEvent(proposals[i])
+	}
+	hs.EventLoop().AddEvent(conflictingProposal(proposals[n-consensus.MaxVotedViews-1]))
+	hs.EventLoop().AddEvent(conflictingProposal(proposals[n-consensus.MaxVotedViews]))
+	hs.EventLoop().RunUntilIdle()
+
+	if len(*events) != 1 {
+		t.Fatalf("got %d equivocation events, want 1", len(*events))
+	}
+	if view := (*events)[0].Second.Block.View(); view != n-consensus.MaxVotedViews+1 {
+		t.Errorf("got evidence for view %d, want view %d", view, n-consensus.MaxVotedViews+1)
+	}
+}
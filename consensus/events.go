@@ -3,6 +3,7 @@ package consensus
 import (
 	"crypto/sha256"
 	"fmt"
+	"time"
 
 	"github.com/relab/hotstuff"
 )
@@ -58,5 +59,69 @@ type NewViewMsg struct {
 // CommitEvent is raised whenever a block is committed,
 // and includes the number of client commands that were executed.
 type CommitEvent struct {
+	Block    *Block
 	Commands int
 }
+
+// BlockCommitEvent is raised by the consensus for every block that it executes, in the order that the blocks are executed,
+// such that the parent of a block is always committed before the block itself.
+type BlockCommitEvent struct {
+	Block     *Block    // The committed block.
+	Timestamp time.Time // The time at which the block was executed.
+}
+
+// AggregateQCRejectedEvent is emitted when a proposal is rejected because its AggregateQC is invalid,
+// or because the QC in the proposed block is not the highQC of the AggregateQC.
+type AggregateQCRejectedEvent struct {
+	ID     hotstuff.ID // The ID of the replica who sent the proposal.
+	View   View        // The view of the proposed block.
+	Reason string      // Why the AggregateQC was rejected.
+}
+
+// EquivocationEvent is emitted when the leader of a view proposes a block that conflicts with the block that the
+// replica voted for in the same view. The two proposals are evidence that the leader is faulty.
+type EquivocationEvent struct {
+	First  ProposeMsg // The proposal that the replica voted for.
+	Second ProposeMsg // The conflicting proposal.
+}
+
+// FetchedEvent delivers the result of fetching a block from the other replicas in the background,
+// which the consensus does when a proposal refers to a block that the replica does not have.
+type FetchedEvent struct {
+	Hash  Hash   // The hash of the requested block.
+	Block *Block // The fetched block, or nil if it could not be fetched before the view ended.
+}
+
+// RequeueEvent is emitted by the command queue when it has put the commands of a forked block back in the queue.
+type RequeueEvent struct {
+	Commands int // The number of commands that were requeued.
+	Skipped  int // The number of commands that were not requeued, since they were executed or are already in the queue.
+}
+
+// BatchEvent is emitted by the command queue when it has released a batch of commands to be proposed.
+type BatchEvent struct {
+	Commands      int // The number of commands in the batch.
+	Urgent        int // The number of urgent commands in the batch, which come first.
+	Bytes         int // The size of the marshaled batch.
+	Pending       int // The number of commands that wait to be proposed after the batch.
+	UrgentPending int // The number of urgent commands that wait to be proposed after the batch.
+}
+
+// FillBuckets is the number of buckets of the batch fill histograms of a CommandQueueEvent. Bucket i counts the
+// batches that fill at least i/FillBuckets, but less than (i+1)/FillBuckets, of their limit.
+// The last bucket also counts the full batches.
+const FillBuckets = 10
+
+// CommandQueueEvent is emitted by the command queue at each metrics tick with the statistics of its client commands
+// since the previous tick.
+type CommandQueueEvent struct {
+	Depth    int           // The number of client commands that wait to be proposed.
+	MaxDepth int           // The largest number of client commands that waited to be proposed since the previous tick.
+	Commands int           // The number of client commands that were included in batches, except requeued commands.
+	Wait     time.Duration // The total time that the included commands waited from their admission to their batches.
+	// The number of commands in each batch relative to the batch size.
+	CountFill [FillBuckets]uint64
+	// The size of each batch relative to its byte limit, which is the smaller of the byte limit of the batches
+	// and the room in a block. Batches without a byte limit are not counted.
+	BytesFill [FillBuckets]uint64
+}
@@ -3,11 +3,10 @@ package fasthotstuff
 
 import (
 	"github.com/relab/hotstuff/consensus"
-	"github.com/relab/hotstuff/modules"
 )
 
 func init() {
-	modules.RegisterModule("fasthotstuff", New)
+	consensus.RegisterRules("fasthotstuff", New)
 }
 
 // FastHotStuff is an implementation of the Fast-HotStuff protocol.
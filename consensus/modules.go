@@ -2,8 +2,10 @@ package consensus
 
 import (
 	"context"
+	"io"
 
 	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/eventloop"
 	"github.com/relab/hotstuff/modules"
 )
 
@@ -26,6 +28,10 @@ type Modules struct {
 	crypto         Crypto
 	synchronizer   Synchronizer
 	forkHandler    ForkHandlerExt
+	validator      CommandValidator
+	safetyStorage  SafetyStorage
+
+	missing *MissingModulesError // the modules that were required by other modules, but not registered
 }
 
 // Run starts both event loops using the provided context and returns when both event loops have exited.
@@ -88,11 +94,26 @@ func (mods *Modules) Synchronizer() Synchronizer {
 	return mods.synchronizer
 }
 
-// ForkHandler returns the module responsible for handling forked blocks.
+// ForkHandler returns the module responsible for handling forked blocks, or nil if there is none.
+// The fork handler is optional.
 func (mods *Modules) ForkHandler() ForkHandlerExt {
 	return mods.forkHandler
 }
 
+// CommandValidator returns the command validator, or a validator that accepts every command if none has been registered.
+func (mods *Modules) CommandValidator() CommandValidator {
+	if mods.validator == nil {
+		return acceptAll{}
+	}
+	return mods.validator
+}
+
+// SafetyStorage returns the module that persists the safety state of the consensus, or nil if there is none.
+// The safety storage is optional.
+func (mods *Modules) SafetyStorage() SafetyStorage {
+	return mods.safetyStorage
+}
+
 // Builder is a helper for constructing a HotStuff instance.
 type Builder struct {
 	baseBuilder modules.Builder
@@ -161,12 +182,24 @@ func (b *Builder) Register(mods ...interface{}) { //nolint:gocyclo
 		if m, ok := module.(ForkHandler); ok {
 			b.mods.forkHandler = forkHandlerWrapper{m}
 		}
+		if m, ok := module.(CommandValidator); ok {
+			b.mods.validator = m
+		}
+		if m, ok := module.(SafetyStorage); ok {
+			b.mods.safetyStorage = m
+		}
 		if m, ok := module.(Module); ok {
 			b.modules = append(b.modules, m)
 		}
 	}
 }
 
+// Crypto returns the crypto module that is currently registered, or nil if there is none.
+// This allows a module that wraps the crypto module to be registered in its place.
+func (b *Builder) Crypto() Crypto {
+	return b.mods.crypto
+}
+
 // OptionsBuilder returns a pointer to the options builder.
 // This can be used to configure runtime options.
 func (b *Builder) OptionsBuilder() *OptionsBuilder {
@@ -174,12 +207,26 @@ func (b *Builder) OptionsBuilder() *OptionsBuilder {
 }
 
 // Build initializes all modules and returns the HotStuff object.
+// It panics if a module requires a module that has not been registered; see TryBuild.
 func (b *Builder) Build() *Modules {
+	mods, err := b.TryBuild()
+	if err != nil {
+		panic(err)
+	}
+	return mods
+}
+
+// TryBuild initializes all modules and returns the HotStuff object, or a *MissingModulesError that names
+// the modules that were required by the modules that were initialized, but have not been registered.
+func (b *Builder) TryBuild() (*Modules, error) {
 	b.mods.Modules = b.baseBuilder.Build()
 	for _, module := range b.modules {
 		module.InitConsensusModule(b.mods, &b.cfg)
 	}
-	return b.mods
+	if b.mods.missing != nil {
+		return nil, b.mods.missing
+	}
+	return b.mods, nil
 }
 
 // Module interfaces
@@ -245,6 +292,34 @@ type ForkHandlerExt interface {
 	Fork(block *Block)
 }
 
+// CommandValidator decides whether commands are valid, for example by checking a signature inside of the command,
+// or the balance that the command spends. Unlike the Acceptor, it does not decide whether the command has been proposed
+// or executed before. The leader does not propose invalid commands, and the replicas do not vote for blocks that
+// contain one. The validation must be deterministic, such that the correct replicas agree on which blocks to vote for.
+type CommandValidator interface {
+	// Validate returns an error if the command is invalid.
+	Validate(cmd Command) error
+}
+
+// SafetyState is the state that a replica must remember across restarts to remain safe.
+type SafetyState struct {
+	LastVote View   // The view of the last block that the replica voted for, or the last view it stopped voting in.
+	Executed *Block // The last block that was executed, or nil if no block has been executed.
+}
+
+// SafetyStorage persists the safety state of the consensus to stable storage, such that a replica that crashes and
+// is restarted does not vote again in a view that it already voted in, and resumes committing after the last block that
+// it executed. The consensus loads the state when it is initialized, before it processes any proposals.
+type SafetyStorage interface {
+	// Load returns the persisted state, or the zero state if nothing has been persisted.
+	Load() (SafetyState, error)
+	// StoreVote persists the view of the last vote. The state must be on stable storage when StoreVote returns,
+	// since the vote is sent after it returns.
+	StoreVote(view View) error
+	// StoreExecuted persists the last executed block.
+	StoreExecuted(block *Block) error
+}
+
 // CryptoImpl implements only the cryptographic primitives that are needed for HotStuff.
 // This interface is implemented by the ecdsa and bls12 packages.
 type CryptoImpl interface {
@@ -263,6 +338,8 @@ type CryptoImpl interface {
 	VerifyThresholdSignatureForMessageSet(signature ThresholdSignature, hashes map[hotstuff.ID]Hash) bool
 }
 
+//go:generate mockgen -destination=../internal/mocks/crypto_mock.go -package=mocks . Crypto
+
 // Crypto implements the methods required to create and verify signatures and certificates.
 // This is a higher level interface that is implemented by the crypto package itself.
 type Crypto interface {
@@ -304,6 +381,32 @@ type BlockChain interface {
 	// Prunes blocks from the in-memory tree up to the specified height.
 	// Returns a set of forked blocks (blocks that were on a different branch, and thus not committed).
 	PruneToHeight(height View) (forkedBlocks []*Block)
+
+	// DiscardBelow deletes the blocks whose view is lower than height, for example because they are covered by
+	// a snapshot of the state. The blocks can no longer be retrieved, nor served to other replicas.
+	DiscardBelow(height View)
+
+	// Range returns the blocks of the branch that ends with the given block whose views are at least from,
+	// in increasing order of view, without fetching them from other replicas.
+	// It returns false if any of these blocks are no longer stored.
+	Range(head *Block, from View) (blocks []*Block, ok bool)
+}
+
+// SnapshotMeta describes a durable snapshot of the state of an application.
+type SnapshotMeta struct {
+	View View   // the view of the last block whose commands are included in the snapshot
+	Size int64  // the size of the snapshot in bytes
+	Path string // where the snapshot is stored
+}
+
+// Snapshotter saves durable snapshots of the state of an application, such that the blocks that are covered by a
+// snapshot can be deleted, and a replica that is restarted can recover from its latest snapshot.
+type Snapshotter interface {
+	// TakeSnapshot saves the state after executing the blocks up to and including the block in view upToView.
+	// It returns when the snapshot has been written to stable storage.
+	TakeSnapshot(upToView View) (SnapshotMeta, error)
+	// Restore replaces the state with the snapshot described by meta, which is read from r.
+	Restore(meta SnapshotMeta, r io.Reader) error
 }
 
 //go:generate mockgen -destination=../internal/mocks/replica_mock.go -package=mocks . Replica
@@ -358,6 +461,23 @@ type Consensus interface {
 	ChainLength() int
 }
 
+// CommittedBlockSetter is an optional interface for Consensus implementations that can skip ahead to a block that
+// was committed by the other replicas. This is needed by replicas that obtain the state after the block by other means,
+// such as a state transfer. The consensus implementations returned by New implement this interface.
+type CommittedBlockSetter interface {
+	// SetCommittedBlock makes the block the most recently committed block, without executing it or its ancestors.
+	SetCommittedBlock(block *Block)
+}
+
+// ViewRestorer is an optional interface for Synchronizer implementations that can start in a later view than the first.
+// This is needed by replicas that are restarted, which must resume after the views that they voted in before.
+// The synchronizer returned by synchronizer.New implements this interface.
+type ViewRestorer interface {
+	// RestoreView makes the view the current view, if it is later than the current view.
+	// It must be called before the synchronizer is started.
+	RestoreView(view View)
+}
+
 // LeaderRotation implements a leader rotation scheme.
 type LeaderRotation interface {
 	// GetLeader returns the id of the leader in the given view.
@@ -385,6 +505,14 @@ type Synchronizer interface {
 	Start(context.Context)
 }
 
+// Clock tells the time and creates timers. The modules use the clock instead of the time package,
+// such that the time can be controlled, for example when a recorded execution is replayed.
+// A registered clock is also used by the tickers and scheduled events of the event loop.
+type Clock = eventloop.Clock
+
+// Timer is a timer created by a Clock. It is implemented by *time.Timer.
+type Timer = eventloop.Timer
+
 type executorWrapper struct {
 	executor Executor
 }
@@ -400,3 +528,8 @@ type forkHandlerWrapper struct {
 func (fhw forkHandlerWrapper) Fork(block *Block) {
 	fhw.forkHandler.Fork(block.cmd)
 }
+
+// acceptAll is the command validator that is used if none has been registered.
+type acceptAll struct{}
+
+func (acceptAll) Validate(Command) error { return nil }
@@ -4,6 +4,8 @@ package consensus
 type Options struct {
 	shouldUseAggQC        bool
 	shouldVerifyVotesSync bool
+	shouldFetchSync       bool
+	shouldCompress        bool
 
 	sharedRandomSeed int64
 }
@@ -20,6 +22,18 @@ func (c Options) ShouldVerifyVotesSync() bool {
 	return c.shouldVerifyVotesSync
 }
 
+// ShouldFetchSync returns true if missing blocks should be fetched synchronously.
+// Enabling this should make the consensus fetch blocks on the event loop, instead of in the background, which is
+// only suitable for configurations that answer at once, such as those of simulated networks.
+func (c Options) ShouldFetchSync() bool {
+	return c.shouldFetchSync
+}
+
+// ShouldCompressProposals returns true if the commands of proposals should be compressed before they are sent.
+func (c Options) ShouldCompressProposals() bool {
+	return c.shouldCompress
+}
+
 // SharedRandomSeed returns a random number that is shared between all replicas.
 func (c Options) SharedRandomSeed() int64 {
 	return c.sharedRandomSeed
@@ -40,6 +54,16 @@ func (builder *OptionsBuilder) SetShouldVerifyVotesSync() {
 	builder.opts.shouldVerifyVotesSync = true
 }
 
+// SetShouldFetchSync sets the ShouldFetchSync setting to true.
+func (builder *OptionsBuilder) SetShouldFetchSync() {
+	builder.opts.shouldFetchSync = true
+}
+
+// SetShouldCompressProposals sets the ShouldCompressProposals setting to true.
+func (builder *OptionsBuilder) SetShouldCompressProposals() {
+	builder.opts.shouldCompress = true
+}
+
 // SetSharedRandomSeed sets the shared random seed.
 func (builder *OptionsBuilder) SetSharedRandomSeed(seed int64) {
 	builder.opts.sharedRandomSeed = seed
@@ -0,0 +1,43 @@
+package consensus
+
+import "github.com/relab/hotstuff/modules"
+
+// This file contains typed wrappers of the module registry for the consensus, crypto, and leader rotation
+// implementations, such that implementations outside of this repository can be selected by name
+// in the same way as the built-in ones. Implementations are typically registered by an init function.
+
+// RegisterRules registers a consensus implementation with the specified name.
+func RegisterRules(name string, constructor func() Rules) {
+	modules.RegisterModule(name, constructor)
+}
+
+// RegisterCrypto registers a crypto implementation with the specified name.
+func RegisterCrypto(name string, constructor func() CryptoImpl) {
+	modules.RegisterModule(name, constructor)
+}
+
+// RegisterLeaderRotation registers a leader rotation implementation with the specified name.
+func RegisterLeaderRotation(name string, constructor func() LeaderRotation) {
+	modules.RegisterModule(name, constructor)
+}
+
+// GetRules returns a new instance of the consensus implementation with the specified name.
+// The error lists the registered implementations if there is none with that name.
+func GetRules(name string) (rules Rules, err error) {
+	err = modules.NewModule(name, &rules)
+	return rules, err
+}
+
+// GetCrypto returns a new instance of the crypto implementation with the specified name.
+// The error lists the registered implementations if there is none with that name.
+func GetCrypto(name string) (impl CryptoImpl, err error) {
+	err = modules.NewModule(name, &impl)
+	return impl, err
+}
+
+// GetLeaderRotation returns a new instance of the leader rotation implementation with the specified name.
+// The error lists the registered implementations if there is none with that name.
+func GetLeaderRotation(name string) (leaderRotation LeaderRotation, err error) {
+	err = modules.NewModule(name, &leaderRotation)
+	return leaderRotation, err
+}
@@ -0,0 +1,120 @@
+package consensus
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ModuleName is the name of a module of the Modules object, which is also the name of its getter.
+type ModuleName string
+
+// The names of the modules.
+const (
+	AcceptorModule         ModuleName = "Acceptor"
+	BlockChainModule       ModuleName = "BlockChain"
+	CommandQueueModule     ModuleName = "CommandQueue"
+	CommandValidatorModule ModuleName = "CommandValidator"
+	ConfigurationModule    ModuleName = "Configuration"
+	ConsensusModule        ModuleName = "Consensus"
+	CryptoModule           ModuleName = "Crypto"
+	ExecutorModule         ModuleName = "Executor"
+	ForkHandlerModule      ModuleName = "ForkHandler"
+	LeaderRotationModule   ModuleName = "LeaderRotation"
+	SynchronizerModule     ModuleName = "Synchronizer"
+)
+
+// ErrMissingModule is returned when a module that is needed has not been registered.
+var ErrMissingModule = errors.New("missing module")
+
+// MissingModulesError reports the modules that were required by other modules, but have not been registered.
+// It matches ErrMissingModule.
+type MissingModulesError struct {
+	// The requiring modules, in the order in which they were initialized.
+	Requirers []string
+	// The names of the missing modules, indexed by the requiring module.
+	Missing map[string][]ModuleName
+}
+
+func (err *MissingModulesError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("missing modules: ")
+	for i, requirer := range err.Requirers {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		for j, name := range err.Missing[requirer] {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(string(name))
+		}
+		sb.WriteString(" required by ")
+		sb.WriteString(requirer)
+	}
+	return sb.String()
+}
+
+// Unwrap returns ErrMissingModule.
+func (err *MissingModulesError) Unwrap() error {
+	return ErrMissingModule
+}
+
+// Get returns the module with the name, or an error that matches ErrMissingModule if it has not been registered.
+// It allows a module to use an optional module, such as the ForkHandler, only if it is present.
+func (mods *Modules) Get(name ModuleName) (interface{}, error) {
+	var m interface{}
+	switch name {
+	case AcceptorModule:
+		m = mods.acceptor
+	case BlockChainModule:
+		m = mods.blockChain
+	case CommandQueueModule:
+		m = mods.commandQueue
+	case CommandValidatorModule:
+		m = mods.validator
+	case ConfigurationModule:
+		m = mods.config
+	case ConsensusModule:
+		m = mods.consensus
+	case CryptoModule:
+		m = mods.crypto
+	case ExecutorModule:
+		m = mods.executor
+	case ForkHandlerModule:
+		m = mods.forkHandler
+	case LeaderRotationModule:
+		m = mods.leaderRotation
+	case SynchronizerModule:
+		m = mods.synchronizer
+	default:
+		return nil, fmt.Errorf("unknown module: %s", name)
+	}
+	if m == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMissingModule, name)
+	}
+	return m, nil
+}
+
+// Require records the modules that the requiring module cannot work without and that have not been registered,
+// such that Build reports all of the missing modules at once, instead of a nil pointer dereference later.
+// It should be called by InitConsensusModule, when all of the modules have been registered. It returns false if any
+// of the modules is missing, in which case InitConsensusModule should return without using them.
+func (mods *Modules) Require(requirer interface{}, names ...ModuleName) bool {
+	name := fmt.Sprintf("%T", requirer)
+	ok := true
+	for _, n := range names {
+		if _, err := mods.Get(n); err == nil {
+			continue
+		}
+		if mods.missing == nil {
+			mods.missing = &MissingModulesError{Missing: make(map[string][]ModuleName)}
+		}
+		if _, ok := mods.missing.Missing[name]; !ok {
+			mods.missing.Requirers = append(mods.missing.Requirers, name)
+		}
+		mods.missing.Missing[name] = append(mods.missing.Missing[name], n)
+		ok = false
+	}
+	return ok
+}
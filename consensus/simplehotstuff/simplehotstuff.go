@@ -3,11 +3,10 @@ package simplehotstuff
 
 import (
 	"github.com/relab/hotstuff/consensus"
-	"github.com/relab/hotstuff/modules"
 )
 
 func init() {
-	modules.RegisterModule("simplehotstuff", New)
+	consensus.RegisterRules("simplehotstuff", New)
 }
 
 // SimpleHotStuff implements a simplified version of the HotStuff algorithm.
@@ -22,6 +22,10 @@ func NewVotingMachine() *VotingMachine {
 // It also allows the module to set module options using the OptionsBuilder.
 func (vm *VotingMachine) InitConsensusModule(mods *Modules, _ *OptionsBuilder) {
 	vm.mods = mods
+	// the voting machine is registered by default, but it only receives votes when there is a consensus module.
+	if mods.Consensus() != nil {
+		vm.mods.Require(vm, BlockChainModule, ConfigurationModule, CryptoModule, SynchronizerModule)
+	}
 	vm.mods.EventLoop().RegisterHandler(VoteMsg{}, func(event interface{}) { vm.OnVote(event.(VoteMsg)) })
 }
 
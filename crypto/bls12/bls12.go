@@ -11,12 +11,11 @@ import (
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/consensus"
 	"github.com/relab/hotstuff/crypto"
-	"github.com/relab/hotstuff/modules"
 	"go.uber.org/multierr"
 )
 
 func init() {
-	modules.RegisterModule("bls12", New)
+	consensus.RegisterCrypto("bls12", New)
 }
 
 const (
@@ -102,6 +101,9 @@ func (s *Signature) ToBytes() []byte {
 
 // FromBytes unmarshals a signature from a byte slice.
 func (s *Signature) FromBytes(b []byte) (err error) {
+	if len(b) < 4 {
+		return fmt.Errorf("bls12: signature is too short: %d bytes", len(b))
+	}
 	s.signer = hotstuff.ID(binary.LittleEndian.Uint32(b))
 	s.s, err = bls12.NewG2().FromCompressed(b[4:])
 	if err != nil {
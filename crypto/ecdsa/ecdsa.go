@@ -11,12 +11,11 @@ import (
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/consensus"
 	"github.com/relab/hotstuff/crypto"
-	"github.com/relab/hotstuff/modules"
 	"go.uber.org/multierr"
 )
 
 func init() {
-	modules.RegisterModule("ecdsa", New)
+	consensus.RegisterCrypto("ecdsa", New)
 }
 
 const (
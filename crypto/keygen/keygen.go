@@ -207,6 +207,9 @@ func WriteCertFile(cert *x509.Certificate, file string) (err error) {
 // ParsePrivateKey parses a PEM encoded private key.
 func ParsePrivateKey(buf []byte) (key consensus.PrivateKey, err error) {
 	b, _ := pem.Decode(buf)
+	if b == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
 	switch b.Type {
 	case ecdsacrypto.PrivateKeyFileType:
 		key, err = x509.ParseECPrivateKey(b.Bytes)
@@ -0,0 +1,121 @@
+package eventloop
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and creates timers. The event loop uses the clock for its tickers and scheduled events,
+// such that the time can be controlled, for example when a recorded execution is replayed, or in tests.
+// NewTimer, NewTicker, and Sleep provide the channel-based timers of the time package on top of a clock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc waits for the duration to elapse and then calls f in its own goroutine.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by a Clock. It is implemented by *time.Timer.
+type Timer interface {
+	// Stop prevents the timer from firing. It returns false if the timer has already fired or been stopped.
+	Stop() bool
+	// Reset changes the timer to fire after the duration d. It returns true if the timer had been active.
+	Reset(d time.Duration) bool
+}
+
+// SystemClock is a Clock that uses the time package. It is the default clock.
+type SystemClock struct{}
+
+// Now returns the current local time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc calls f in its own goroutine when the duration has elapsed.
+func (SystemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// ChanTimer is a timer that sends the time of the clock on its channel when it fires, like time.Timer.
+type ChanTimer struct {
+	Timer
+	C <-chan time.Time
+}
+
+// NewTimer returns a timer of the clock that sends the time on its channel when the duration has elapsed.
+func NewTimer(clock Clock, d time.Duration) *ChanTimer {
+	c := make(chan time.Time, 1)
+	timer := clock.AfterFunc(d, func() {
+		select {
+		case c <- clock.Now():
+		default:
+		}
+	})
+	return &ChanTimer{Timer: timer, C: c}
+}
+
+// ChanTicker sends the time of the clock on its channel every interval, like time.Ticker.
+// The ticks are dropped if the receiver falls behind.
+type ChanTicker struct {
+	C <-chan time.Time
+
+	mut     sync.Mutex
+	timer   Timer
+	stopped bool
+}
+
+// NewTicker returns a ticker of the clock that sends the time on its channel every interval.
+// It panics if the interval is not positive.
+func NewTicker(clock Clock, interval time.Duration) *ChanTicker {
+	if interval <= 0 {
+		panic("eventloop: non-positive interval for NewTicker")
+	}
+	c := make(chan time.Time, 1)
+	t := &ChanTicker{C: c}
+	var tick func()
+	tick = func() {
+		select {
+		case c <- clock.Now():
+		default:
+		}
+		t.mut.Lock()
+		defer t.mut.Unlock()
+		if !t.stopped {
+			t.timer.Reset(interval)
+		}
+	}
+	t.mut.Lock()
+	t.timer = clock.AfterFunc(interval, tick)
+	t.mut.Unlock()
+	return t
+}
+
+// Stop stops the ticker. No more ticks are sent after Stop returns.
+func (t *ChanTicker) Stop() {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	t.stopped = true
+	t.timer.Stop()
+}
+
+// Sleep waits until the duration has elapsed on the clock, or until the context is done,
+// in which case the error of the context is returned.
+func Sleep(ctx context.Context, clock Clock, d time.Duration) error {
+	timer := NewTimer(clock, d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetClock sets the clock that the tickers and the scheduled events follow. The system clock is used by default.
+// It must be set before any tickers or scheduled events are added.
+func (el *EventLoop) SetClock(clock Clock) {
+	el.mut.Lock()
+	el.clock = clock
+	el.mut.Unlock()
+}
@@ -3,14 +3,42 @@
 // The event loop allows for flexible handling of events through the concept of observers and handlers.
 // An observer is a function that is able to view an event before it is handled.
 // Thus, there can be multiple observers for each event type.
-// A handler is a function that processes the event. There can only be one handler for each event type.
+// A handler is a function that processes the event. There can be multiple handlers for each event type, which are
+// called after the observers, in the order given when they were registered, and which can be unregistered.
+// Event observers, added with AddObserver, see every event before and after it is handled, along with the time it took
+// to handle it, which allows for tracing the event loop. When EnableStats has been called, the event loop also counts
+// the events of each type and the time spent on them, which are reported by Stats and TakeStats.
+//
+// Events can be added with normal or high priority. High priority events are processed before normal events,
+// but a normal event is processed after every priorityWeight consecutive high priority events, such that
+// normal events are not starved. The events of a type are added with high priority if the handler of the type
+// was registered with RegisterPriorityHandler. Events with the same priority are processed in the order they were added.
+//
+// Each event queue holds a bounded number of events. When an event is added to a full queue, the overflow policy of
+// its type decides whether an older event is dropped, the event itself is dropped, or the producer waits for room
+// (see OverflowPolicy). The dropped events are counted, and reported by periodic warnings in the log.
+//
+// The event loop can be shut down cooperatively with Stop or Shutdown. The events that were added before the shutdown
+// are processed, and then the shutdown hooks are run in the reverse order of their registration, such that modules are
+// shut down in the reverse order of their initialization. Stop also rejects the events that are added after it,
+// and bounds the time spent processing the queued events by a context.
+//
+// Other goroutines can ask the modules on the event loop for something with Request, which delivers a request to the
+// handlers of its type, and waits for one of them to respond.
+//
+// In tests, an event loop created by NewSynchronous is driven by the test itself with Tick and RunUntilIdle, and
+// together with a clock that the test advances, the events are processed in the same order on every run.
 package eventloop
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/relab/hotstuff/logging"
 )
 
 // EventHandler processes an event.
@@ -18,90 +46,380 @@ type EventHandler func(event interface{})
 
 // EventLoop accepts events of any type and executes relevant event handlers.
 // It supports registering both observers and handlers based on the type of event that they accept.
-// The difference between them is that there can be many observers per event type, but only one handler.
-// Handlers and observers can either be executed asynchronously, immediately after AddEvent() is called,
-// or synchronously, after the event has passed through the event queue.
-// An asynchronous handler consumes events, which means that synchronous observers will not be notified of them.
+// Observers are meant to look at events, for example to collect measurements, and they are all called before the
+// handlers, in the order of their registration. Handlers act on events, and there can be many handlers per event type,
+// which are called in the order given when they were registered, and which can be unregistered.
+// Both are executed on the event loop, after the event has passed through the event queue.
 type EventLoop struct {
-	mut sync.Mutex
+	requestID uint64 // the ID of the latest request; accessed atomically, and first in the struct for alignment.
 
-	eventQ        queue
-	waitingEvents map[reflect.Type][]interface{}
+	mut sync.Mutex
 
-	handlers  map[reflect.Type]EventHandler
-	observers map[reflect.Type][]EventHandler
+	eventQ         *queue
+	priorityQ      *queue
+	synchronous    bool // true if the events are only processed by Tick, and the queues grow instead of overflowing
+	capacity       uint // the capacity of each event queue; protected by mut.
+	priorityStreak int  // the number of consecutive high priority events processed; only accessed by the loop.
+	waitingEvents  map[reflect.Type][]interface{}
+
+	handlers   map[reflect.Type][]*handlerEntry // sorted by order, and then by id; only accessed by the loop.
+	handlerIDs map[int]reflect.Type             // the event types of the registered handlers; only accessed by the loop.
+	handlerID  int
+
+	statsEnabled bool                 // true if the statistics of the event types are collected; protected by mut.
+	typeIDs      map[reflect.Type]int // the IDs of the event types, which index stats; only accessed by the loop.
+	stats        []*typeStats         // the statistics of the event types; appended by the loop while holding mut.
+
+	fatalPanics      bool                            // true if the panics in handlers are not recovered; only accessed by the loop.
+	panics           map[reflect.Type]uint64         // the number of recovered panics of each event type; protected by mut.
+	handlerPanics    map[int]uint64                  // the number of panics of each handler; protected by mut.
+	disabledHandlers map[reflect.Type]uint64         // the number of handlers unregistered after panics; protected by mut.
+	priorities       map[reflect.Type]bool           // the event types that AddEvent adds with high priority; protected by mut.
+	policies         map[reflect.Type]OverflowPolicy // the overflow policies of the event types; protected by mut.
+	observers        map[reflect.Type][]EventHandler
+	taps             []EventHandler
+
+	eventObservers []EventObserver
+	logger         logging.Logger // created when it is first needed if not set; protected by mut.
+
+	// the number of events of each type that were dropped from full event queues; protected by mut.
+	dropped         map[reflect.Type]uint64
+	unreportedDrops uint64
+	lastDropWarning time.Time
+
+	// filter decides if an added event is queued; events are always queued if it is nil.
+	filter func(event interface{}) bool
+
+	clock Clock // protected by mut.
+
+	tickers        map[int]*ticker // protected by mut.
+	tickerID       int
+	tickersStopped bool // true when the event loop has stopped, such that the tickers are not started
+
+	// the events scheduled by AddEventAfter and AddEventAt; protected by mut.
+	schedule        schedule
+	scheduleSeq     uint64
+	scheduleStopped bool       // true when the event loop has shut down, such that no events are scheduled
+	wakeup          Timer      // adds the scheduled events to the event queues when they are due
+	dueMut          sync.Mutex // serializes adding the due events to the event queues
+
+	shutdownHooks []ShutdownHook
+	shutDown      bool // true when the shutdown hooks have run; only accessed by the loop.
+
+	stopped  bool            // true when no more events are accepted; protected by mut.
+	stopping chan struct{}   // closed by Stop
+	stopOnce sync.Once       // closes stopping
+	stopCtx  context.Context // bounds the processing of the queued events after Stop; set before stopping is closed.
+	stopErr  error           // reports the events that were abandoned; set before done is closed.
+	done     chan struct{}   // closed when the shutdown hooks have run
+}
 
-	tickers  map[int]*ticker
-	tickerID int
+// handlerEntry is a registered event handler.
+type handlerEntry struct {
+	id      int
+	order   int
+	handle  EventHandler
+	removed bool // true when the handler has been unregistered, such that a dispatch in progress skips it
 }
 
+// ShutdownHook is run when the event loop is shut down. It may return an event, such as a final measurement,
+// which is processed before the next hook is run. Otherwise, it should return nil.
+type ShutdownHook func() (event interface{})
+
+// shutdownEvent stops the event loop when it is processed.
+type shutdownEvent struct{}
+
 // New returns a new event loop with the requested buffer size.
 func New(bufferSize uint) *EventLoop {
 	el := &EventLoop{
-		eventQ:        newQueue(bufferSize),
-		waitingEvents: make(map[reflect.Type][]interface{}),
-		handlers:      make(map[reflect.Type]EventHandler),
-		observers:     make(map[reflect.Type][]EventHandler),
-		tickers:       make(map[int]*ticker),
+		eventQ:           newQueue(bufferSize),
+		priorityQ:        newQueue(bufferSize),
+		capacity:         bufferSize,
+		waitingEvents:    make(map[reflect.Type][]interface{}),
+		handlers:         make(map[reflect.Type][]*handlerEntry),
+		handlerIDs:       make(map[int]reflect.Type),
+		typeIDs:          make(map[reflect.Type]int),
+		panics:           make(map[reflect.Type]uint64),
+		handlerPanics:    make(map[int]uint64),
+		disabledHandlers: make(map[reflect.Type]uint64),
+		priorities:       make(map[reflect.Type]bool),
+		policies:         make(map[reflect.Type]OverflowPolicy),
+		dropped:          make(map[reflect.Type]uint64),
+		observers:        make(map[reflect.Type][]EventHandler),
+		clock:            SystemClock{},
+		tickers:          make(map[int]*ticker),
+		stopping:         make(chan struct{}),
+		done:             make(chan struct{}),
 	}
 	return el
 }
 
-// RegisterHandler registers a handler for events with the same type as the 'eventType' argument.
-// The handler is executed synchronously. There can be only one handler per event type.
-func (el *EventLoop) RegisterHandler(eventType interface{}, handler EventHandler) {
-	el.handlers[reflect.TypeOf(eventType)] = handler
+// NewSynchronous returns a new event loop that is driven by the caller, for use in tests.
+// The events are only processed by Tick and RunUntilIdle, on the goroutine that calls them, one at a time, and the
+// event queues grow as needed, such that no event is dropped, and adding an event never blocks.
+// Combined with a clock whose timers fire on the goroutine that advances it (see SetClock), the order of the events
+// is reproducible.
+func NewSynchronous() *EventLoop {
+	el := New(64)
+	el.synchronous = true
+	el.eventQ.grow = true
+	el.priorityQ.grow = true
+	return el
+}
+
+// RegisterHandler registers a handler for events with the same type as the 'eventType' argument,
+// and returns an id that can be passed to UnregisterHandler. It is equivalent to RegisterOrderedHandler with order 0.
+func (el *EventLoop) RegisterHandler(eventType interface{}, handler EventHandler) int {
+	return el.RegisterOrderedHandler(eventType, 0, handler)
+}
+
+// RegisterOrderedHandler registers a handler for events with the same type as the 'eventType' argument,
+// and returns an id that can be passed to UnregisterHandler.
+// The handlers of an event type are called in ascending order, and the handlers with the same order are called in the
+// order of their registration. A handler that is registered while an event is handled is not called for that event.
+// Handlers must be registered before the event loop is started, or from the event loop itself.
+func (el *EventLoop) RegisterOrderedHandler(eventType interface{}, order int, handler EventHandler) int {
+	t := reflect.TypeOf(eventType)
+	el.handlerID++
+	h := &handlerEntry{id: el.handlerID, order: order, handle: handler}
+	el.handlerIDs[h.id] = t
+	el.typeID(t)
+
+	// the slice is copied, such that a dispatch that is in progress is not affected.
+	old := el.handlers[t]
+	i := sort.Search(len(old), func(i int) bool { return old[i].order > order })
+	handlers := make([]*handlerEntry, 0, len(old)+1)
+	handlers = append(handlers, old[:i]...)
+	handlers = append(handlers, h)
+	handlers = append(handlers, old[i:]...)
+	el.handlers[t] = handlers
+	return h.id
+}
+
+// UnregisterHandler removes the handler with the specified id, such that it is no longer called, even for the event
+// that is being handled. It returns false if the handler does not exist.
+// Handlers must be unregistered from the event loop, or when it is not running.
+func (el *EventLoop) UnregisterHandler(id int) bool {
+	t, ok := el.handlerIDs[id]
+	if !ok {
+		return false
+	}
+	delete(el.handlerIDs, id)
+	old := el.handlers[t]
+	handlers := make([]*handlerEntry, 0, len(old)-1)
+	for _, h := range old {
+		if h.id == id {
+			h.removed = true
+			continue
+		}
+		handlers = append(handlers, h)
+	}
+	if len(handlers) == 0 {
+		delete(el.handlers, t)
+	} else {
+		el.handlers[t] = handlers
+	}
+	return true
+}
+
+// RegisterPriorityHandler registers a handler like RegisterHandler, and marks the events of the type as high priority,
+// such that AddEvent adds them to the high priority event queue. This should be reserved for events that are
+// important for liveness, such as timeouts, which would otherwise wait behind a backlog of other events.
+func (el *EventLoop) RegisterPriorityHandler(eventType interface{}, handler EventHandler) int {
+	id := el.RegisterHandler(eventType, handler)
+	el.mut.Lock()
+	el.priorities[reflect.TypeOf(eventType)] = true
+	el.mut.Unlock()
+	return id
 }
 
 // RegisterObserver registers an observer for events with the same type as the 'eventType' argument.
-// The observer is executed synchronously before any registered handler.
+// The observer is executed synchronously before any registered handler. Unlike handlers, observers cannot be
+// ordered or unregistered; they should only look at the events, and leave acting on them to the handlers.
 func (el *EventLoop) RegisterObserver(eventType interface{}, observer EventHandler) {
 	t := reflect.TypeOf(eventType)
 	el.observers[t] = append(el.observers[t], observer)
+	el.typeID(t)
+}
+
+// RegisterTap registers a function that sees every event that is processed by the event loop, in the order in which
+// they are processed, before the observers and the handler of the event. Taps are used to record the execution.
+func (el *EventLoop) RegisterTap(tap EventHandler) {
+	el.taps = append(el.taps, tap)
+}
+
+// SetFilter sets a function that decides whether an event that is added to the event loop should be queued.
+// Events for which the filter returns false are dropped. This is used when replaying a recorded execution,
+// where events of the recorded types are supplied by the replay rather than by the modules.
+func (el *EventLoop) SetFilter(filter func(event interface{}) bool) {
+	el.mut.Lock()
+	el.filter = filter
+	el.mut.Unlock()
+}
+
+// Process processes the event immediately, bypassing the event queues and the filter.
+// It must be called from the goroutine that runs or ticks the event loop.
+func (el *EventLoop) Process(event interface{}) {
+	el.processEvent(event)
+}
+
+// RegisterShutdownHook registers a hook that is run once when the event loop is shut down.
+// The hooks are run in the reverse order of registration.
+func (el *EventLoop) RegisterShutdownHook(hook ShutdownHook) {
+	el.shutdownHooks = append(el.shutdownHooks, hook)
+}
+
+// Shutdown stops the event loop after the events that were added before it have been processed.
+// The shutdown hooks are then run on the event loop, and Run returns without processing the events that are added later.
+// If the event loop is not running, the hooks are run by Tick instead. Unlike Stop, Shutdown does not wait for the
+// event loop to stop, and the events that are added after Shutdown are accepted, but not processed.
+func (el *EventLoop) Shutdown() {
+	el.AddEvent(shutdownEvent{})
+}
+
+// AddEvent adds an event to the event queue, or to the high priority event queue if its handler was registered with
+// RegisterPriorityHandler. It returns false if the event was not queued, because the event loop has been stopped,
+// the event was dropped by the filter, or the event was dropped from a full queue.
+func (el *EventLoop) AddEvent(event interface{}) bool {
+	return el.addEvent(event, event)
 }
 
-// AddEvent adds an event to the event queue.
-func (el *EventLoop) AddEvent(event interface{}) {
-	el.eventQ.push(event)
+// addEvent adds the entry to the event queue that the event belongs in, and returns false if the event is not queued.
+// The entry is either the event, or a scheduled event that wraps it.
+func (el *EventLoop) addEvent(event, entry interface{}) bool {
+	el.mut.Lock()
+	priority := el.priorities[reflect.TypeOf(event)]
+	el.mut.Unlock()
+	if !el.accepts(event) {
+		return false
+	}
+	if priority {
+		return el.push(el.priorityQ, event, entry)
+	}
+	return el.push(el.eventQ, event, entry)
+}
+
+// accepts returns true if the event loop has not been stopped, and the event is not dropped by the filter.
+func (el *EventLoop) accepts(event interface{}) bool {
+	el.mut.Lock()
+	filter, stopped := el.filter, el.stopped
+	el.mut.Unlock()
+	return !stopped && (filter == nil || filter(event))
+}
+
+// Len returns the number of events that are waiting to be processed, including high priority events.
+// It is safe to call from any goroutine.
+func (el *EventLoop) Len() int {
+	return el.eventQ.len() + el.priorityQ.len()
+}
+
+// priorityWeight is the number of consecutive high priority events that are processed
+// before a waiting normal event is processed.
+const priorityWeight = 4
+
+// AddPriorityEvent adds an event to the high priority event queue, regardless of its type.
+// High priority events are processed ahead of the normal events added with AddEvent.
+// This should be reserved for events that are important for liveness, such as timeouts.
+// It returns false if the event was not queued, like AddEvent.
+func (el *EventLoop) AddPriorityEvent(event interface{}) bool {
+	if !el.accepts(event) {
+		return false
+	}
+	return el.push(el.priorityQ, event, event)
+}
+
+// nextEvent returns the next event to process.
+func (el *EventLoop) nextEvent() (interface{}, bool) {
+	if el.priorityStreak < priorityWeight {
+		if event, ok := el.priorityQ.pop(); ok {
+			el.priorityStreak++
+			return event, true
+		}
+	}
+	el.priorityStreak = 0
+	if event, ok := el.eventQ.pop(); ok {
+		return event, true
+	}
+	return el.priorityQ.pop()
 }
 
 // Run runs the event loop. A context object can be provided to stop the event loop.
+// It must not be used with a synchronous event loop.
 func (el *EventLoop) Run(ctx context.Context) {
+	if el.synchronous {
+		panic("eventloop: Run called on a synchronous event loop")
+	}
 loop:
 	for {
-		event, ok := el.eventQ.pop()
+		event, ok := el.nextEvent()
+		if el.stopIfDone(ok) {
+			return
+		}
 		if !ok {
 			select {
 			case <-el.eventQ.ready():
 				continue loop
+			case <-el.priorityQ.ready():
+				continue loop
+			case <-el.stopping:
+				if el.shutDown {
+					return
+				}
+				continue loop
 			case <-ctx.Done():
 				break loop
 			}
 		}
 		if e, ok := event.(startTickerEvent); ok {
-			el.startTicker(ctx, e.tickerID)
+			el.startTicker(e.tickerID)
 			continue
 		}
+		if e, ok := event.(tickEvent); ok {
+			el.tick(e)
+			continue
+		}
+		if _, ok := event.(shutdownEvent); ok {
+			el.shutdown()
+			return
+		}
 		el.processEvent(event)
 	}
 
 	// HACK: when we get cancelled, we will handle the events that were in the queue at that time before quitting.
-	l := el.eventQ.len()
+	l := el.eventQ.len() + el.priorityQ.len()
 	for i := 0; i < l; i++ {
-		event, _ := el.eventQ.pop()
+		event, _ := el.nextEvent()
 		el.processEvent(event)
 	}
+	el.closeQueues()
+	el.stopTickers()
+}
+
+// closeQueues releases the producers that wait for room in the event queues when the event loop stops.
+func (el *EventLoop) closeQueues() {
+	el.eventQ.close()
+	el.priorityQ.close()
 }
 
 // Tick processes a single event. Returns true if an event was handled.
+// The scheduled events that are due are added to the event queues first.
+// If the event loop has been stopped, and the queued events have been processed, the shutdown hooks are run instead.
 func (el *EventLoop) Tick() bool {
-	event, ok := el.eventQ.pop()
+	el.addDueEvents()
+	event, ok := el.nextEvent()
+	if el.stopIfDone(ok) {
+		return true
+	}
 	if !ok {
 		return false
 	}
 
 	if e, ok := event.(startTickerEvent); ok {
-		el.startTicker(context.Background(), e.tickerID)
+		el.startTicker(e.tickerID)
+	} else if e, ok := event.(tickEvent); ok {
+		el.tick(e)
+	} else if _, ok := event.(shutdownEvent); ok {
+		el.shutdown()
 	} else {
 		el.processEvent(event)
 	}
@@ -109,35 +427,100 @@ func (el *EventLoop) Tick() bool {
 	return true
 }
 
+// RunUntilIdle processes events with Tick until there are no more events to process,
+// and returns the number of events that were processed.
+func (el *EventLoop) RunUntilIdle() int {
+	n := 0
+	for el.Tick() {
+		n++
+	}
+	return n
+}
+
+// shutdown runs the shutdown hooks in the reverse order of registration, unless they have already run.
+// The scheduled events that are not due are dropped.
+func (el *EventLoop) shutdown() {
+	if el.shutDown {
+		return
+	}
+	el.shutDown = true
+	defer close(el.done)
+	el.mut.Lock()
+	el.stopped = true
+	el.scheduleStopped = true
+	el.clearSchedule()
+	el.mut.Unlock()
+	el.closeQueues()
+	el.stopTickers()
+	for i := len(el.shutdownHooks) - 1; i >= 0; i-- {
+		if event := el.shutdownHooks[i](); event != nil {
+			el.processEvent(event)
+		}
+	}
+}
+
 // processEvent dispatches the event to the correct handler.
 func (el *EventLoop) processEvent(event interface{}) {
+	if e, ok := event.(*scheduledEvent); ok {
+		if event, ok = el.takeScheduled(e); !ok {
+			return
+		}
+	}
 	t := reflect.TypeOf(event)
+	if r, ok := event.(*Request); ok {
+		t = reflect.TypeOf(r.Payload)
+	}
 	defer el.dispatchDelayedEvents(t)
 
+	if len(el.eventObservers) == 0 && !el.statsEnabled {
+		el.dispatch(event, t)
+		return
+	}
+	el.observe(event, BeforeHandler, 0)
+	start := time.Now()
+	el.dispatch(event, t)
+	d := time.Since(start)
+	if el.statsEnabled {
+		el.stats[el.typeID(t)].record(d)
+	}
+	el.observe(event, AfterHandler, d)
+}
+
+// dispatch passes the event to the taps, and then to the observers and the handler of its type.
+func (el *EventLoop) dispatch(event interface{}, t reflect.Type) {
+	for _, tap := range el.taps {
+		tap(event)
+	}
+
 	if f, ok := event.(func()); ok {
-		f()
+		el.call(func(interface{}) { f() }, event, 0)
 		return
 	}
 
 	// run observers
 	for _, observer := range el.observers[t] {
-		observer(event)
+		el.call(observer, event, 0)
 	}
 
-	if handler, ok := el.handlers[t]; ok {
-		handler(event)
+	handlers := el.handlers[t]
+	for _, h := range handlers {
+		if !h.removed {
+			el.call(h.handle, event, h.id)
+		}
+	}
+	if r, ok := event.(*Request); ok && len(handlers) == 0 {
+		r.Respond(nil, fmt.Errorf("request %d (%v): %w", r.ID, t, ErrNoHandler))
 	}
 }
 
 func (el *EventLoop) dispatchDelayedEvents(t reflect.Type) {
 	el.mut.Lock()
-	if delayed, ok := el.waitingEvents[t]; ok {
-		for _, event := range delayed {
-			el.AddEvent(event)
-		}
-		delete(el.waitingEvents, t)
-	}
+	delayed := el.waitingEvents[t]
+	delete(el.waitingEvents, t)
 	el.mut.Unlock()
+	for _, event := range delayed {
+		el.AddEvent(event)
+	}
 }
 
 // DelayUntil allows us to delay handling of an event until after another event has happened.
@@ -151,86 +534,3 @@ func (el *EventLoop) DelayUntil(eventType, event interface{}) {
 	el.waitingEvents[t] = v
 	el.mut.Unlock()
 }
-
-type ticker struct {
-	interval time.Duration
-	callback func(time.Time) interface{}
-	cancel   context.CancelFunc
-}
-
-type startTickerEvent struct {
-	tickerID int
-}
-
-// AddTicker adds a ticker with the specified interval and returns the ticker id.
-// The ticker will send the specified event on the event loop at regular intervals.
-// The returned ticker id can be used to remove the ticker with RemoveTicker.
-// The ticker will not be started before the event loop is running.
-func (el *EventLoop) AddTicker(interval time.Duration, callback func(tick time.Time) (event interface{})) int {
-	el.mut.Lock()
-
-	id := el.tickerID
-	el.tickerID++
-
-	ticker := ticker{
-		interval: interval,
-		callback: callback,
-		cancel:   func() {}, // initialized to empty function to avoid nil
-	}
-	el.tickers[id] = &ticker
-
-	el.mut.Unlock()
-
-	el.eventQ.push(startTickerEvent{id})
-
-	return id
-}
-
-// RemoveTicker removes the ticker with the specified id.
-// If the ticker was removed, RemoveTicker will return true.
-// If the ticker does not exist, false will be returned instead.
-func (el *EventLoop) RemoveTicker(id int) bool {
-	el.mut.Lock()
-	defer el.mut.Unlock()
-
-	ticker, ok := el.tickers[id]
-	if !ok {
-		return false
-	}
-	ticker.cancel()
-	delete(el.tickers, id)
-	return true
-}
-
-func (el *EventLoop) startTicker(ctx context.Context, id int) {
-	// lock the mutex such that the ticker cannot be removed until we have started it
-	el.mut.Lock()
-	defer el.mut.Unlock()
-	ticker, ok := el.tickers[id]
-	if !ok {
-		return
-	}
-	ctx, ticker.cancel = context.WithCancel(ctx)
-	go el.runTicker(ctx, ticker)
-}
-
-func (el *EventLoop) runTicker(ctx context.Context, ticker *ticker) {
-	t := time.NewTicker(ticker.interval)
-	defer t.Stop()
-
-	if ctx.Err() != nil {
-		return
-	}
-
-	// send the first event immediately
-	el.AddEvent(ticker.callback(time.Now()))
-
-	for {
-		select {
-		case tick := <-t.C:
-			el.AddEvent(ticker.callback(tick))
-		case <-ctx.Done():
-			return
-		}
-	}
-}
@@ -2,11 +2,18 @@ package eventloop_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/testutil"
+	"github.com/relab/hotstuff/logging"
 )
 
 type testEvent int
@@ -91,6 +98,82 @@ func TestObserver(t *testing.T) {
 	}
 }
 
+func TestEventObserver(t *testing.T) {
+	type observation struct {
+		event interface{}
+		stage eventloop.Stage
+		d     time.Duration
+	}
+
+	const handlerTime = 5 * time.Millisecond
+	el := eventloop.New(10)
+	el.RegisterHandler(testEvent(0), func(event interface{}) {
+		if event == testEvent(2) {
+			time.Sleep(handlerTime)
+		}
+	})
+	var observed []observation
+	el.AddObserver(func(event interface{}, stage eventloop.Stage, d time.Duration) {
+		observed = append(observed, observation{event, stage, d})
+	})
+
+	el.AddEvent(testEvent(1))
+	el.AddEvent(testEvent(2))
+	el.AddEvent("unhandled")
+	for el.Tick() {
+	}
+
+	want := []interface{}{testEvent(1), testEvent(2), "unhandled"}
+	if len(observed) != 2*len(want) {
+		t.Fatalf("got %d observations, want %d", len(observed), 2*len(want))
+	}
+	for i, o := range observed {
+		if o.event != want[i/2] {
+			t.Errorf("observation %d: got event %v, want %v", i, o.event, want[i/2])
+		}
+		if stage := eventloop.Stage(i % 2); o.stage != stage {
+			t.Errorf("observation %d: got stage %v, want %v", i, o.stage, stage)
+		}
+		if o.stage == eventloop.BeforeHandler && o.d != 0 {
+			t.Errorf("observation %d: got duration %v before the handler, want 0", i, o.d)
+		}
+		if o.d < 0 || o.d > time.Second {
+			t.Errorf("observation %d: implausible duration %v", i, o.d)
+		}
+	}
+	if d := observed[3].d; d < handlerTime {
+		t.Errorf("got duration %v for the slow handler, want at least %v", d, handlerTime)
+	}
+}
+
+func TestSlowEventObserver(t *testing.T) {
+	var buf strings.Builder
+	el := eventloop.New(10)
+	el.SetLogger(logging.NewWithDest(&buf, "eventloop"))
+	el.AddObserver(func(_ interface{}, stage eventloop.Stage, _ time.Duration) {
+		if stage == eventloop.AfterHandler {
+			time.Sleep(20 * time.Millisecond)
+		}
+	})
+	el.AddEvent(testEvent(1))
+	el.Tick()
+
+	if !strings.Contains(buf.String(), "blocked the event loop") || !strings.Contains(buf.String(), "AfterHandler") {
+		t.Errorf("expected a warning about the slow observer, got: %q", buf.String())
+	}
+}
+
+func TestNoEventObserversAllocs(t *testing.T) {
+	el := eventloop.New(10)
+	el.RegisterHandler(testEvent(0), func(_ interface{}) {})
+	allocs := testing.AllocsPerRun(100, func() {
+		el.Process(testEvent(1))
+	})
+	if allocs != 0 {
+		t.Errorf("processing an event without event observers allocated %v times, want 0", allocs)
+	}
+}
+
 func TestTicker(t *testing.T) {
 	if os.Getenv("GITHUB_ACTIONS") != "" {
 		t.SkipNow()
@@ -156,3 +239,971 @@ func TestDelayedEvent(t *testing.T) {
 		}
 	}
 }
+
+func TestPriorityEvents(t *testing.T) {
+	type normalEvent int
+	type priorityEvent int
+
+	el := eventloop.New(100)
+	var order []string
+	el.RegisterHandler(normalEvent(0), func(_ interface{}) { order = append(order, "n") })
+	el.RegisterHandler(priorityEvent(0), func(_ interface{}) { order = append(order, "p") })
+
+	for i := 0; i < 4; i++ {
+		el.AddEvent(normalEvent(i))
+	}
+	for i := 0; i < 10; i++ {
+		el.AddPriorityEvent(priorityEvent(i))
+	}
+	for el.Tick() {
+	}
+
+	// high priority events go first, but a normal event is processed after every four high priority events.
+	want := "ppppnppppnppnn"
+	if got := strings.Join(order, ""); got != want {
+		t.Errorf("got order %s, want %s", got, want)
+	}
+}
+
+func TestPriorityHandler(t *testing.T) {
+	type proposal int
+	type timeout struct{}
+
+	const backlog = 10000
+	el := eventloop.New(2 * backlog)
+	var (
+		processed int  // the number of proposals processed
+		before    = -1 // the number of proposals processed before the timeout
+		next      proposal
+	)
+	el.RegisterHandler(proposal(0), func(event interface{}) {
+		if p := event.(proposal); p != next {
+			t.Fatalf("got proposal %d, want %d", p, next)
+		}
+		next++
+		processed++
+	})
+	el.RegisterPriorityHandler(timeout{}, func(_ interface{}) { before = processed })
+
+	for i := 0; i < backlog; i++ {
+		el.AddEvent(proposal(i))
+	}
+	// a proposal is processed before the timeout is added, such that the loop is in the middle of the backlog.
+	el.Tick()
+	el.AddEvent(timeout{})
+	for el.Tick() {
+	}
+
+	// the timeout is processed after at most one more proposal, rather than after the backlog.
+	if before < 0 {
+		t.Fatal("the timeout was not processed")
+	}
+	if before > 2 {
+		t.Errorf("the timeout was processed after %d proposals, want at most 2", before)
+	}
+	if processed != backlog {
+		t.Errorf("got %d proposals, want %d", processed, backlog)
+	}
+}
+
+func TestConcurrentPriorityEvents(t *testing.T) {
+	type normalEvent struct{ producer, seq int }
+	type priorityEvent struct{ producer, seq int }
+
+	const (
+		producers = 8
+		events    = 1000
+	)
+	el := eventloop.New(2 * producers * events)
+	var (
+		nextNormal   [producers]int
+		nextPriority [producers]int
+		processed    int
+		errs         []string
+	)
+	done := make(chan struct{})
+	count := func() {
+		processed++
+		if processed == 2*producers*events {
+			close(done)
+		}
+	}
+	// the events of each producer must be processed in order within each lane.
+	el.RegisterHandler(normalEvent{}, func(event interface{}) {
+		e := event.(normalEvent)
+		if e.seq != nextNormal[e.producer] {
+			errs = append(errs, fmt.Sprintf("producer %d: got normal event %d, want %d", e.producer, e.seq, nextNormal[e.producer]))
+		}
+		nextNormal[e.producer] = e.seq + 1
+		count()
+	})
+	el.RegisterPriorityHandler(priorityEvent{}, func(event interface{}) {
+		e := event.(priorityEvent)
+		if e.seq != nextPriority[e.producer] {
+			errs = append(errs, fmt.Sprintf("producer %d: got priority event %d, want %d", e.producer, e.seq, nextPriority[e.producer]))
+		}
+		nextPriority[e.producer] = e.seq + 1
+		count()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan struct{})
+	go func() {
+		el.Run(ctx)
+		close(stopped)
+	}()
+	for p := 0; p < producers; p++ {
+		go func(p int) {
+			for i := 0; i < events; i++ {
+				el.AddEvent(normalEvent{p, i})
+				el.AddEvent(priorityEvent{p, i})
+			}
+		}(p)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the events")
+	}
+	cancel()
+	<-stopped
+	for _, err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestLen(t *testing.T) {
+	el := eventloop.New(10)
+	el.RegisterHandler(testEvent(0), func(_ interface{}) {})
+
+	el.AddEvent(testEvent(1))
+	el.AddEvent(testEvent(2))
+	el.AddPriorityEvent(testEvent(3))
+	if n := el.Len(); n != 3 {
+		t.Errorf("got %d queued events, want 3", n)
+	}
+	el.Tick()
+	if n := el.Len(); n != 2 {
+		t.Errorf("got %d queued events after a tick, want 2", n)
+	}
+	for el.Tick() {
+	}
+	if n := el.Len(); n != 0 {
+		t.Errorf("got %d queued events after processing all events, want 0", n)
+	}
+}
+
+func TestShutdownHooks(t *testing.T) {
+	el := eventloop.New(10)
+	var order []string
+	el.RegisterHandler(testEvent(0), func(event interface{}) {
+		order = append(order, fmt.Sprintf("event %d", event))
+	})
+	// the hooks run in the reverse order of registration, and the event returned by a hook is processed before the next hook.
+	for i := 1; i <= 3; i++ {
+		i := i
+		el.RegisterShutdownHook(func() interface{} {
+			order = append(order, fmt.Sprintf("hook %d", i))
+			if i == 2 {
+				return testEvent(i)
+			}
+			return nil
+		})
+	}
+
+	el.AddEvent(testEvent(1))
+	el.Shutdown()
+	el.Shutdown()
+	done := make(chan struct{})
+	go func() {
+		el.Run(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the event loop did not stop")
+	}
+	// the second shutdown does not run the hooks again.
+	for el.Tick() {
+	}
+
+	want := "event 1, hook 3, hook 2, event 2, hook 1"
+	if got := strings.Join(order, ", "); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestAddEventAfter(t *testing.T) {
+	el := eventloop.New(10)
+	c := make(chan time.Time, 1)
+	el.RegisterHandler(testEvent(0), func(_ interface{}) { c <- time.Now() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go el.Run(ctx)
+
+	const delay = 20 * time.Millisecond
+	start := time.Now()
+	el.AddEventAfter(delay, testEvent(1))
+	select {
+	case processed := <-c:
+		if elapsed := processed.Sub(start); elapsed < delay {
+			t.Errorf("the event was processed after %v, want at least %v", elapsed, delay)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the scheduled event was not processed")
+	}
+}
+
+func TestScheduledEventsOrder(t *testing.T) {
+	el := eventloop.New(10)
+	var order []testEvent
+	el.RegisterHandler(testEvent(0), func(event interface{}) { order = append(order, event.(testEvent)) })
+
+	// the events that are scheduled for the same instant are processed in the order in which they were scheduled.
+	at := time.Now().Add(10 * time.Millisecond)
+	el.AddEventAt(at, testEvent(2))
+	el.AddEventAt(at, testEvent(3))
+	el.AddEventAt(at.Add(-time.Millisecond), testEvent(1))
+	el.AddEventAt(at.Add(time.Hour), testEvent(4))
+
+	for el.Tick() {
+	}
+	if len(order) > 0 {
+		t.Fatalf("events were processed before they were due: %v", order)
+	}
+	time.Sleep(time.Until(at))
+	for el.Tick() {
+	}
+	if want := []testEvent{1, 2, 3}; !reflect.DeepEqual(order, want) {
+		t.Errorf("got events %v, want %v", order, want)
+	}
+}
+
+func TestCancelScheduledEvent(t *testing.T) {
+	el := eventloop.New(10)
+	var processed []testEvent
+	el.RegisterHandler(testEvent(0), func(event interface{}) { processed = append(processed, event.(testEvent)) })
+
+	cancelPending := el.AddEventAfter(time.Millisecond, testEvent(1))
+	if !cancelPending() {
+		t.Error("a pending event could not be cancelled")
+	}
+	if cancelPending() {
+		t.Error("an event was cancelled twice")
+	}
+
+	// an event that has been queued can be cancelled until it is processed.
+	cancelQueued := el.AddEventAfter(time.Millisecond, testEvent(2))
+	deadline := time.Now().Add(time.Second)
+	for el.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if el.Len() == 0 {
+		t.Fatal("the scheduled event was not queued")
+	}
+	if !cancelQueued() {
+		t.Error("a queued event could not be cancelled")
+	}
+
+	cancelProcessed := el.AddEventAfter(0, testEvent(3))
+	for el.Tick() {
+	}
+	if cancelProcessed() {
+		t.Error("a processed event was cancelled")
+	}
+
+	// the events that are not due are dropped when the event loop shuts down.
+	el.AddEventAfter(time.Millisecond, testEvent(4))
+	el.Shutdown()
+	for el.Tick() {
+	}
+	el.AddEventAfter(0, testEvent(5))
+	time.Sleep(10 * time.Millisecond)
+	for el.Tick() {
+	}
+
+	if want := []testEvent{3}; !reflect.DeepEqual(processed, want) {
+		t.Errorf("got events %v, want %v", processed, want)
+	}
+}
+
+func TestCancelScheduledEventRace(t *testing.T) {
+	const events = 1000
+	el := eventloop.New(events)
+	processed := make(map[testEvent]bool)
+	el.RegisterHandler(testEvent(0), func(event interface{}) { processed[event.(testEvent)] = true })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		el.Run(ctx)
+		close(stopped)
+	}()
+
+	// the events are cancelled concurrently with their delivery, and each event is either processed or cancelled.
+	var (
+		wg        sync.WaitGroup
+		cancelled [events]bool
+	)
+	for i := 0; i < events; i++ {
+		cancelEvent := el.AddEventAfter(time.Duration(i%10)*time.Millisecond, testEvent(i))
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			time.Sleep(time.Duration(i%7) * time.Millisecond)
+			cancelled[i] = cancelEvent()
+		}(i)
+	}
+	wg.Wait()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-stopped
+
+	for i := 0; i < events; i++ {
+		if processed[testEvent(i)] == cancelled[i] {
+			t.Errorf("event %d: processed: %t, cancelled: %t", i, processed[testEvent(i)], cancelled[i])
+		}
+	}
+}
+
+type (
+	networkMsg  int
+	requestMsg  int
+	internalMsg int
+)
+
+func TestOverflowPolicies(t *testing.T) {
+	var buf strings.Builder
+	el := eventloop.New(10)
+	el.SetLogger(logging.NewWithDest(&buf, "eventloop"))
+	el.SetOverflowPolicy(networkMsg(0), eventloop.DropOldest)
+	el.SetOverflowPolicy(requestMsg(0), eventloop.DropNewest)
+	el.SetOverflowPolicy(internalMsg(0), eventloop.Block)
+	var handled []interface{}
+	for _, eventType := range []interface{}{networkMsg(0), requestMsg(0), internalMsg(0)} {
+		el.RegisterHandler(eventType, func(event interface{}) { handled = append(handled, event) })
+	}
+
+	// a fake network producer floods the event loop before it gets to run.
+	for i := 0; i < 25; i++ {
+		el.AddEvent(networkMsg(i))
+	}
+	for i := 0; i < 3; i++ {
+		el.AddEvent(requestMsg(i))
+	}
+	// the internal events take the place of the oldest network messages, without waiting.
+	done := make(chan struct{})
+	go func() {
+		el.AddEvent(internalMsg(0))
+		el.AddEvent(internalMsg(1))
+		close(done)
+	}()
+	<-done
+	el.AddEvent(networkMsg(25))
+
+	for el.Tick() {
+	}
+
+	var want []interface{}
+	for i := 18; i < 25; i++ {
+		want = append(want, networkMsg(i))
+	}
+	want = append(want, internalMsg(0), internalMsg(1), networkMsg(25))
+	if !reflect.DeepEqual(handled, want) {
+		t.Errorf("got handled events %v, want %v", handled, want)
+	}
+	wantDropped := map[string]uint64{"eventloop_test.networkMsg": 18, "eventloop_test.requestMsg": 3}
+	if dropped := el.DroppedEvents(); !reflect.DeepEqual(dropped, wantDropped) {
+		t.Errorf("got dropped events %v, want %v", dropped, wantDropped)
+	}
+	if !strings.Contains(buf.String(), "because the event queue was full") {
+		t.Errorf("expected a warning about the dropped events, got: %q", buf.String())
+	}
+}
+
+func TestOverflowPolicyBlock(t *testing.T) {
+	el := eventloop.New(2)
+	el.SetOverflowPolicy(internalMsg(0), eventloop.Block)
+	var handled []interface{}
+	el.RegisterHandler(internalMsg(0), func(event interface{}) { handled = append(handled, event) })
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			el.AddEvent(internalMsg(i))
+		}
+		close(done)
+	}()
+	for len(handled) < 5 {
+		if !el.Tick() {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	<-done
+
+	want := []interface{}{internalMsg(0), internalMsg(1), internalMsg(2), internalMsg(3), internalMsg(4)}
+	if !reflect.DeepEqual(handled, want) {
+		t.Errorf("got handled events %v, want %v", handled, want)
+	}
+	if dropped := el.DroppedEvents(); len(dropped) != 0 {
+		t.Errorf("got dropped events %v, want none", dropped)
+	}
+}
+
+func TestStopDrainsQueuedEvents(t *testing.T) {
+	el := eventloop.New(10)
+	var order []string
+	el.RegisterHandler(testEvent(0), func(event interface{}) {
+		order = append(order, fmt.Sprintf("event %d", event))
+		// events that are added while draining are rejected.
+		if el.AddEvent(testEvent(100)) {
+			t.Error("expected the event loop to reject an event added while it is stopping")
+		}
+	})
+	for i := 1; i <= 2; i++ {
+		i := i
+		el.RegisterShutdownHook(func() interface{} {
+			order = append(order, fmt.Sprintf("hook %d", i))
+			return nil
+		})
+	}
+	for i := 1; i <= 3; i++ {
+		el.AddEvent(testEvent(i))
+	}
+	go el.Run(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := el.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"event 1", "event 2", "event 3", "hook 2", "hook 1"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("got %v, want %v", order, want)
+	}
+	if el.AddEvent(testEvent(4)) {
+		t.Error("expected AddEvent to return false after Stop")
+	}
+	if err := el.Stop(ctx); err != nil {
+		t.Errorf("got error %v when stopping again, want nil", err)
+	}
+}
+
+func TestStopDeadline(t *testing.T) {
+	el := eventloop.New(10)
+	var handled []interface{}
+	el.RegisterHandler(testEvent(0), func(event interface{}) {
+		handled = append(handled, event)
+		time.Sleep(50 * time.Millisecond)
+	})
+	hookRan := false
+	el.RegisterShutdownHook(func() interface{} {
+		hookRan = true
+		return nil
+	})
+	for i := 1; i <= 5; i++ {
+		el.AddEvent(testEvent(i))
+	}
+	go el.Run(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 75*time.Millisecond)
+	defer cancel()
+	err := el.Stop(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	// the hooks run once the event that is being handled when the deadline passes is done.
+	if err := el.Stop(context.Background()); err == nil || !strings.Contains(err.Error(), "abandoned") {
+		t.Fatalf("got error %v, want an error about the abandoned events", err)
+	}
+	if !hookRan {
+		t.Error("expected the shutdown hook to run")
+	}
+	if len(handled) < 1 || len(handled) > 3 {
+		t.Errorf("got %d handled events, want between 1 and 3 before the deadline", len(handled))
+	}
+}
+
+func TestStopTick(t *testing.T) {
+	el := eventloop.New(10)
+	var order []string
+	el.RegisterHandler(testEvent(0), func(event interface{}) {
+		order = append(order, fmt.Sprintf("event %d", event))
+	})
+	el.RegisterShutdownHook(func() interface{} {
+		order = append(order, "hook")
+		return nil
+	})
+	el.AddEvent(testEvent(1))
+
+	errs := make(chan error)
+	go func() { errs <- el.Stop(context.Background()) }()
+	for len(order) < 2 {
+		el.Tick()
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"event 1", "hook"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("got %v, want %v", order, want)
+	}
+	if el.Tick() {
+		t.Error("expected no more events after the event loop was stopped")
+	}
+}
+
+func TestTickerClock(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Unix(0, 0))
+	el := eventloop.New(10)
+	el.SetClock(clock)
+	var ticks []time.Time
+	el.RegisterHandler(testEvent(0), func(event interface{}) {})
+	const interval = time.Second
+	id := el.AddTicker(interval, func(tick time.Time) interface{} {
+		ticks = append(ticks, tick)
+		return testEvent(1)
+	})
+
+	// the first tick happens when the ticker is started.
+	for el.Tick() {
+	}
+	if len(ticks) != 1 || !ticks[0].Equal(time.Unix(0, 0)) {
+		t.Fatalf("got ticks %v after starting the ticker, want one at the start", ticks)
+	}
+
+	for i := 1; i <= 5; i++ {
+		clock.Step(interval / 2)
+		for el.Tick() {
+		}
+		if len(ticks) != i {
+			t.Fatalf("got %d ticks after %d intervals and a half, want %d", len(ticks), i-1, i)
+		}
+		clock.Step(interval / 2)
+		for el.Tick() {
+		}
+		if len(ticks) != i+1 {
+			t.Fatalf("got %d ticks after %d intervals, want %d", len(ticks), i, i+1)
+		}
+		if want := time.Unix(int64(i), 0); !ticks[i].Equal(want) {
+			t.Errorf("got tick at %v, want %v", ticks[i], want)
+		}
+	}
+
+	// advancing the clock by several intervals at once ticks once for each of them.
+	clock.Step(3 * interval)
+	for el.Tick() {
+	}
+	if len(ticks) != 9 {
+		t.Errorf("got %d ticks after advancing 3 intervals, want 9", len(ticks))
+	}
+
+	el.RemoveTicker(id)
+	clock.Step(interval)
+	for el.Tick() {
+	}
+	if len(ticks) != 9 {
+		t.Errorf("got %d ticks after removing the ticker, want 9", len(ticks))
+	}
+}
+
+func TestScheduledEventsClock(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Unix(0, 0))
+	el := eventloop.New(10)
+	el.SetClock(clock)
+	var handled []interface{}
+	el.RegisterHandler(testEvent(0), func(event interface{}) { handled = append(handled, event) })
+
+	el.AddEventAfter(time.Second, testEvent(1))
+	clock.Step(time.Second - time.Nanosecond)
+	for el.Tick() {
+	}
+	if len(handled) != 0 {
+		t.Fatalf("got %v before the event was due, want none", handled)
+	}
+	clock.Step(time.Nanosecond)
+	for el.Tick() {
+	}
+	if want := []interface{}{testEvent(1)}; !reflect.DeepEqual(handled, want) {
+		t.Errorf("got %v, want %v", handled, want)
+	}
+}
+
+func TestSynchronous(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Unix(0, 0))
+	el := eventloop.NewSynchronous()
+	el.SetClock(clock)
+	var got []testEvent
+	el.RegisterHandler(testEvent(0), func(event interface{}) {
+		got = append(got, event.(testEvent))
+	})
+
+	// more events than the initial capacity of the queues are added, and none of them are dropped.
+	const n = 100
+	for i := 0; i < n; i++ {
+		if !el.AddEvent(testEvent(i)) {
+			t.Fatalf("event %d was not queued", i)
+		}
+	}
+	el.AddEventAfter(time.Second, testEvent(n))
+
+	if processed := el.RunUntilIdle(); processed != n {
+		t.Errorf("processed %d events, want %d", processed, n)
+	}
+	clock.Step(time.Second)
+	if processed := el.RunUntilIdle(); processed != 1 {
+		t.Errorf("processed %d events after advancing the clock, want 1", processed)
+	}
+
+	for i, e := range got {
+		if e != testEvent(i) {
+			t.Fatalf("got event %d at position %d, want events in the order they were added", e, i)
+		}
+	}
+	if len(got) != n+1 {
+		t.Errorf("got %d events, want %d", len(got), n+1)
+	}
+	if dropped := el.DroppedEvents(); len(dropped) != 0 {
+		t.Errorf("dropped events: %v", dropped)
+	}
+}
+
+func TestOrderedHandlers(t *testing.T) {
+	el := eventloop.NewSynchronous()
+	var calls []string
+	handler := func(name string) eventloop.EventHandler {
+		return func(event interface{}) { calls = append(calls, name) }
+	}
+	late := el.RegisterOrderedHandler(testEvent(0), 10, handler("late"))
+	el.RegisterOrderedHandler(testEvent(0), -10, handler("early"))
+	def := el.RegisterHandler(testEvent(0), handler("default"))
+	el.RegisterHandler(testEvent(0), handler("default 2"))
+	el.RegisterObserver(testEvent(0), handler("observer"))
+
+	el.AddEvent(testEvent(1))
+	el.RunUntilIdle()
+	if want := []string{"observer", "early", "default", "default 2", "late"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("got %v, want %v", calls, want)
+	}
+
+	if !el.UnregisterHandler(def) {
+		t.Error("failed to unregister the handler")
+	}
+	if el.UnregisterHandler(def) {
+		t.Error("unregistered the handler twice")
+	}
+	calls = nil
+	el.AddEvent(testEvent(2))
+	el.RunUntilIdle()
+	if want := []string{"observer", "early", "default 2", "late"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("got %v after unregistering a handler, want %v", calls, want)
+	}
+
+	// a handler that unregisters a later handler while the event is handled stops it from being called.
+	el.RegisterOrderedHandler(testEvent(0), 5, func(event interface{}) {
+		calls = append(calls, "unregister")
+		el.UnregisterHandler(late)
+	})
+	calls = nil
+	el.AddEvent(testEvent(3))
+	el.RunUntilIdle()
+	if want := []string{"observer", "early", "default 2", "unregister"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("got %v after unregistering a handler while handling, want %v", calls, want)
+	}
+}
+
+func TestHandlerPanic(t *testing.T) {
+	var buf strings.Builder
+	el := eventloop.NewSynchronous()
+	el.SetLogger(logging.NewWithDest(&buf, "eventloop"))
+	id := el.RegisterHandler(testEvent(0), func(event interface{}) {
+		panic(fmt.Sprintf("bad event %d", event.(testEvent)))
+	})
+	handled := 0
+	el.RegisterHandler(testEvent(0), func(_ interface{}) { handled++ })
+	var errs []eventloop.InternalErrorEvent
+	el.RegisterHandler(eventloop.InternalErrorEvent{}, func(event interface{}) {
+		errs = append(errs, event.(eventloop.InternalErrorEvent))
+	})
+
+	for i := 1; i <= 4; i++ {
+		el.AddEvent(testEvent(i))
+	}
+	el.RunUntilIdle()
+
+	// the handler is unregistered after three panics, and the other handler sees every event.
+	if handled != 4 {
+		t.Errorf("the other handler handled %d events, want 4", handled)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("got %d error events, want 3", len(errs))
+	}
+	e := errs[0]
+	if e.Event != testEvent(1) || e.HandlerID != id || e.PanicValue != "bad event 1" {
+		t.Errorf("got error event for %v from handler %d with %v, want event 1 from handler %d with \"bad event 1\"",
+			e.Event, e.HandlerID, e.PanicValue, id)
+	}
+	if !strings.Contains(string(e.Stack), "TestHandlerPanic") {
+		t.Errorf("the stack does not contain the panicking handler:\n%s", e.Stack)
+	}
+	if el.UnregisterHandler(id) {
+		t.Error("the panicking handler was not unregistered")
+	}
+	if !strings.Contains(buf.String(), "Unregistered handler") {
+		t.Errorf("expected an error about the unregistered handler, got: %q", buf.String())
+	}
+	if got := el.Panics()["eventloop_test.testEvent"]; got != 3 {
+		t.Errorf("got %d panics, want 3", got)
+	}
+	if got := el.DisabledHandlers()["eventloop_test.testEvent"]; got != 1 {
+		t.Errorf("got %d disabled handlers, want 1", got)
+	}
+}
+
+func TestHandlerPanicLogged(t *testing.T) {
+	var buf strings.Builder
+	el := eventloop.NewSynchronous()
+	el.SetLogger(logging.NewWithDest(&buf, "eventloop"))
+	el.RegisterHandler(testEvent(0), func(_ interface{}) { panic("oops") })
+
+	el.AddEvent(testEvent(1))
+	el.AddEvent(func() { panic("oops again") })
+	if n := el.RunUntilIdle(); n != 2 {
+		t.Errorf("processed %d events, want 2", n)
+	}
+	if !strings.Contains(buf.String(), "oops") || !strings.Contains(buf.String(), "oops again") ||
+		!strings.Contains(buf.String(), "TestHandlerPanicLogged") {
+		t.Errorf("expected the panics to be logged with the stack, got: %q", buf.String())
+	}
+}
+
+func TestFatalPanics(t *testing.T) {
+	el := eventloop.NewSynchronous()
+	el.SetFatalPanics(true)
+	el.RegisterHandler(testEvent(0), func(_ interface{}) { panic("oops") })
+	el.AddEvent(testEvent(1))
+	defer func() {
+		if v := recover(); v != "oops" {
+			t.Errorf("got panic %v, want oops", v)
+		}
+	}()
+	el.Tick()
+	t.Error("the panic was recovered by the event loop")
+}
+
+type testRequest struct{ n int }
+
+func TestRequest(t *testing.T) {
+	el := eventloop.New(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go el.Run(ctx)
+
+	secondResponse := make(chan bool, 1)
+	el.RegisterHandler(testRequest{}, func(event interface{}) {
+		r := event.(*eventloop.Request)
+		r.Respond(r.Payload.(testRequest).n*2, nil)
+		secondResponse <- r.Respond(0, errors.New("second response"))
+	})
+
+	resp, err := el.Request(context.Background(), testRequest{21})
+	if err != nil || resp != 42 {
+		t.Errorf("got %v, %v, want 42, nil", resp, err)
+	}
+	if <-secondResponse {
+		t.Error("the second response was accepted")
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	el := eventloop.New(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go el.Run(ctx)
+
+	late := make(chan bool, 1)
+	el.RegisterHandler(testRequest{}, func(event interface{}) {
+		r := event.(*eventloop.Request)
+		// respond after the requester has given up.
+		go func() {
+			<-r.Context().Done()
+			late <- r.Respond(0, nil)
+		}()
+	})
+
+	reqCtx, reqCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer reqCancel()
+	if _, err := el.Request(reqCtx, testRequest{1}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if <-late {
+		t.Error("the response after the timeout was accepted")
+	}
+}
+
+func TestRequestCancel(t *testing.T) {
+	el := eventloop.NewSynchronous()
+	el.RegisterHandler(testRequest{}, func(_ interface{}) {})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := el.Request(ctx, testRequest{1})
+		errs <- err
+	}()
+	// the request is handled, but not answered, before it is cancelled.
+	for el.RunUntilIdle() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestRequestNoHandler(t *testing.T) {
+	el := eventloop.New(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go el.Run(ctx)
+
+	if _, err := el.Request(context.Background(), testRequest{1}); !errors.Is(err, eventloop.ErrNoHandler) {
+		t.Errorf("got error %v, want %v", err, eventloop.ErrNoHandler)
+	}
+	el.Stop(context.Background())
+	if _, err := el.Request(context.Background(), testRequest{1}); !errors.Is(err, eventloop.ErrNotQueued) {
+		t.Errorf("got error %v after the event loop stopped, want %v", err, eventloop.ErrNotQueued)
+	}
+}
+
+func TestClockTimerTickerSleep(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Unix(0, 0))
+
+	timer := eventloop.NewTimer(clock, time.Second)
+	ticker := eventloop.NewTicker(clock, 300*time.Millisecond)
+	clock.Step(time.Second)
+	select {
+	case now := <-timer.C:
+		if !now.Equal(time.Unix(1, 0)) {
+			t.Errorf("the timer fired at %v, want %v", now, time.Unix(1, 0))
+		}
+	default:
+		t.Error("the timer did not fire")
+	}
+	// the receiver fell behind, so only the first of the three ticks was kept.
+	if now := <-ticker.C; !now.Equal(time.Unix(0, int64(300*time.Millisecond))) {
+		t.Errorf("got tick at %v, want %v", now, time.Unix(0, int64(300*time.Millisecond)))
+	}
+	ticker.Stop()
+	clock.Step(time.Second)
+	select {
+	case <-ticker.C:
+		t.Error("got a tick after the ticker was stopped")
+	default:
+	}
+
+	done := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { done <- eventloop.Sleep(ctx, clock, time.Second) }()
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v from a cancelled sleep, want %v", err, context.Canceled)
+	}
+}
+
+type otherEvent struct{}
+
+func TestStats(t *testing.T) {
+	el := eventloop.NewSynchronous()
+	if el.Stats() != nil {
+		t.Error("got statistics before they were enabled")
+	}
+	el.EnableStats()
+	el.RegisterHandler(testEvent(0), func(event interface{}) {
+		if event.(testEvent) == 1 {
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+	el.RegisterObserver(otherEvent{}, func(_ interface{}) {})
+
+	for i := 0; i < 3; i++ {
+		el.AddEvent(testEvent(i))
+	}
+	el.AddEvent(otherEvent{})
+	el.AddEvent(func() {})
+	el.RunUntilIdle()
+
+	stats := el.Stats()
+	if len(stats) != 3 {
+		t.Errorf("got statistics of %d event types, want 3: %v", len(stats), stats)
+	}
+	s := stats["eventloop_test.testEvent"]
+	if s.Count != 3 {
+		t.Errorf("got %d test events, want 3", s.Count)
+	}
+	if s.Max < 5*time.Millisecond || s.Total < s.Max {
+		t.Errorf("got max %v and total %v, want at least 5ms", s.Max, s.Total)
+	}
+	if n := stats["eventloop_test.otherEvent"].Count; n != 1 {
+		t.Errorf("got %d other events, want 1", n)
+	}
+	if n := stats["func()"].Count; n != 1 {
+		t.Errorf("got %d func() events, want 1", n)
+	}
+
+	if taken := el.TakeStats(); !reflect.DeepEqual(taken, stats) {
+		t.Errorf("the first period has the statistics %v, want %v", taken, stats)
+	}
+	el.AddEvent(testEvent(2))
+	el.RunUntilIdle()
+	taken := el.TakeStats()
+	if len(taken) != 1 || taken["eventloop_test.testEvent"].Count != 1 {
+		t.Errorf("the second period has the statistics %v, want one test event", taken)
+	}
+	if max := taken["eventloop_test.testEvent"].Max; max >= 5*time.Millisecond {
+		t.Errorf("the maximum of the second period is %v, want the maximum since the first period", max)
+	}
+	if n := el.Stats()["eventloop_test.testEvent"].Count; n != 4 {
+		t.Errorf("got %d test events since the start, want 4", n)
+	}
+	if taken := el.TakeStats(); len(taken) != 0 {
+		t.Errorf("the third period has the statistics %v, want none", taken)
+	}
+}
+
+func TestStatsAllocs(t *testing.T) {
+	el := eventloop.New(10)
+	el.EnableStats()
+	el.RegisterHandler(testEvent(0), func(_ interface{}) {})
+	allocs := testing.AllocsPerRun(100, func() {
+		el.Process(testEvent(1))
+	})
+	if allocs != 0 {
+		t.Errorf("processing an event with statistics allocated %v times, want 0", allocs)
+	}
+}
+
+// BenchmarkStats measures the overhead of the statistics on events whose handler does a little work.
+func BenchmarkStats(b *testing.B) {
+	for _, enabled := range []bool{false, true} {
+		b.Run(fmt.Sprintf("enabled=%t", enabled), func(b *testing.B) {
+			el := eventloop.New(10)
+			if enabled {
+				el.EnableStats()
+			}
+			var sum uint64
+			el.RegisterHandler(testEvent(0), func(event interface{}) {
+				for i := uint64(0); i < 1000; i++ {
+					sum += i * uint64(event.(testEvent))
+				}
+			})
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				el.Process(testEvent(1))
+			}
+		})
+	}
+}
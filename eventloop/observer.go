@@ -0,0 +1,76 @@
+package eventloop
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/relab/hotstuff/logging"
+)
+
+// Stage is the stage of the processing of an event at which an event observer is called.
+type Stage int
+
+const (
+	// BeforeHandler is the stage before the event is passed to the taps, observers, and handler of the event loop.
+	BeforeHandler Stage = iota
+	// AfterHandler is the stage after the event has been handled.
+	AfterHandler
+)
+
+func (s Stage) String() string {
+	switch s {
+	case BeforeHandler:
+		return "BeforeHandler"
+	case AfterHandler:
+		return "AfterHandler"
+	default:
+		return fmt.Sprintf("Stage(%d)", int(s))
+	}
+}
+
+// EventObserver is called before and after each event is processed. The duration is zero before the event is handled,
+// and the time it took to handle the event afterwards, which includes the taps, the observers, and the handler.
+type EventObserver func(event interface{}, stage Stage, d time.Duration)
+
+// observerBudget is the time that the event observers may spend on a single call before a warning is logged.
+const observerBudget = 10 * time.Millisecond
+
+// AddObserver adds an observer that sees every event that is processed by the event loop, including the events that
+// are processed with Process and the func() events, before and after the event is handled. This allows for tracing
+// the events and measuring the time spent on them without changing the handlers.
+//
+// The observers are called on the event loop, so they delay every event. They should only record the event and
+// return; an observer that takes longer than 10ms is reported by a warning in the log of the event loop.
+// Observers must be added before the event loop is started. When no observers are added, the events are not timed.
+func (el *EventLoop) AddObserver(observer EventObserver) {
+	el.eventObservers = append(el.eventObservers, observer)
+}
+
+// SetLogger sets the logger that the event loop reports slow event observers and dropped events to.
+func (el *EventLoop) SetLogger(logger logging.Logger) {
+	el.mut.Lock()
+	el.logger = logger
+	el.mut.Unlock()
+}
+
+// getLogger returns the logger, and creates it if it has not been set. The caller must hold the mutex.
+func (el *EventLoop) getLogger() logging.Logger {
+	if el.logger == nil {
+		el.logger = logging.New("eventloop")
+	}
+	return el.logger
+}
+
+// observe calls the event observers, and warns about the observers that exceed the budget.
+func (el *EventLoop) observe(event interface{}, stage Stage, d time.Duration) {
+	for i, observer := range el.eventObservers {
+		start := time.Now()
+		observer(event, stage, d)
+		if elapsed := time.Since(start); elapsed > observerBudget {
+			el.mut.Lock()
+			logger := el.getLogger()
+			el.mut.Unlock()
+			logger.Warnf("Event observer %d blocked the event loop for %v at stage %v of %T", i, elapsed, stage, event)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package eventloop
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// OverflowPolicy decides what happens when an event is added to a full event queue.
+type OverflowPolicy int
+
+const (
+	// DropOldest drops the oldest queued event with the DropOldest policy to make room for the event,
+	// or the event itself if there is no such event. This is the default policy, and it suits the messages
+	// from the network that are sent again, or superseded by newer messages.
+	DropOldest OverflowPolicy = iota
+	// DropNewest drops the event, and leaves the queued events alone.
+	DropNewest
+	// Block makes the producer wait until there is room for the event, unless an event with the DropOldest policy
+	// can be dropped to make room. It suits the internal events that must not be lost, but it must only be used for
+	// events that are added by other goroutines than the one that runs the event loop, which would wait for itself.
+	Block
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case DropOldest:
+		return "DropOldest"
+	case DropNewest:
+		return "DropNewest"
+	case Block:
+		return "Block"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
+
+// dropWarningInterval is the minimum time between the warnings about dropped events.
+const dropWarningInterval = 10 * time.Second
+
+// SetOverflowPolicy sets the policy that is applied when an event of the same type as the 'eventType' argument
+// is added to a full event queue. It is safe to call from any goroutine.
+func (el *EventLoop) SetOverflowPolicy(eventType interface{}, policy OverflowPolicy) {
+	el.mut.Lock()
+	el.policies[reflect.TypeOf(eventType)] = policy
+	el.mut.Unlock()
+}
+
+// SetCapacity sets the number of events that each of the event queues can hold. The events in the queues are kept.
+// A capacity of zero leaves the capacity unchanged.
+func (el *EventLoop) SetCapacity(capacity uint) {
+	if capacity == 0 {
+		return
+	}
+	el.eventQ.resize(capacity)
+	el.priorityQ.resize(capacity)
+	el.mut.Lock()
+	el.capacity = capacity
+	el.mut.Unlock()
+}
+
+// DroppedEvents returns the number of events of each type that have been dropped because an event queue was full.
+// It is safe to call from any goroutine.
+func (el *EventLoop) DroppedEvents() map[string]uint64 {
+	el.mut.Lock()
+	defer el.mut.Unlock()
+	dropped := make(map[string]uint64, len(el.dropped))
+	for t, n := range el.dropped {
+		dropped[t.String()] = n
+	}
+	return dropped
+}
+
+// push adds the entry to the queue with the overflow policy of the event, and counts the entry that is dropped.
+// It returns false if the entry itself was dropped.
+func (el *EventLoop) push(q *queue, event, entry interface{}) bool {
+	el.mut.Lock()
+	policy := el.policies[reflect.TypeOf(event)]
+	el.mut.Unlock()
+	dropped, ok, queued := q.pushWithPolicy(entry, policy)
+	if ok {
+		el.countDropped(dropped)
+	}
+	return queued
+}
+
+// countDropped counts the dropped entry, and logs a warning about the events that were dropped since the last warning,
+// unless the last warning was logged less than dropWarningInterval ago.
+func (el *EventLoop) countDropped(entry interface{}) {
+	el.mut.Lock()
+	event := entry
+	if e, ok := entry.(*scheduledEvent); ok {
+		e.state = scheduledDone
+		event = e.event
+	}
+	if r, ok := entry.(*Request); ok {
+		event = r.Payload
+		defer r.Respond(nil, fmt.Errorf("request %d (%T): %w", r.ID, event, ErrNotQueued))
+	}
+	el.dropped[reflect.TypeOf(event)]++
+	el.unreportedDrops++
+	now := time.Now()
+	if now.Sub(el.lastDropWarning) < dropWarningInterval {
+		el.mut.Unlock()
+		return
+	}
+	n, capacity := el.unreportedDrops, el.capacity
+	el.unreportedDrops = 0
+	el.lastDropWarning = now
+	logger := el.getLogger()
+	el.mut.Unlock()
+	logger.Warnf("Dropped %d events since the last warning because the event queue was full (capacity %d)", n, capacity)
+}
@@ -0,0 +1,102 @@
+package eventloop
+
+import (
+	"reflect"
+	"runtime/debug"
+)
+
+// InternalErrorEvent is processed by the event loop when a handler or an observer panics while processing an event,
+// instead of letting the panic crash the process. Handlers can be registered for it to react to the errors;
+// if there are none, the error is logged along with the stack.
+type InternalErrorEvent struct {
+	// The event that was processed when the panic happened.
+	Event interface{}
+	// The id of the handler that panicked, or zero if it was an observer or a func() event.
+	HandlerID int
+	// The value that was passed to panic.
+	PanicValue interface{}
+	// The stack of the goroutine that panicked.
+	Stack []byte
+}
+
+// maxHandlerPanics is the number of panics after which a handler is unregistered.
+const maxHandlerPanics = 3
+
+// SetFatalPanics decides whether a panic in a handler crashes the process, as if the handler had not been called by
+// the event loop, which preserves the stack for debugging. Otherwise, the panics are recovered and reported by an
+// InternalErrorEvent. The panics are recovered by default. It must be called before the event loop is started.
+func (el *EventLoop) SetFatalPanics(fatal bool) {
+	el.fatalPanics = fatal
+}
+
+// Panics returns the number of panics that were recovered while processing events, indexed by the event type.
+// It is safe to call from any goroutine.
+func (el *EventLoop) Panics() map[string]uint64 {
+	el.mut.Lock()
+	defer el.mut.Unlock()
+	return countsByName(el.panics)
+}
+
+// DisabledHandlers returns the number of handlers that were unregistered because they panicked repeatedly,
+// indexed by the event type. It is safe to call from any goroutine.
+func (el *EventLoop) DisabledHandlers() map[string]uint64 {
+	el.mut.Lock()
+	defer el.mut.Unlock()
+	return countsByName(el.disabledHandlers)
+}
+
+func countsByName(counts map[reflect.Type]uint64) map[string]uint64 {
+	byName := make(map[string]uint64, len(counts))
+	for t, n := range counts {
+		byName[t.String()] = n
+	}
+	return byName
+}
+
+// call calls the handler or observer with the event, and recovers from a panic in it.
+// The id is the id of the handler, or zero if f is not a registered handler.
+func (el *EventLoop) call(f EventHandler, event interface{}, id int) {
+	defer el.recoverPanic(event, id)
+	f(event)
+}
+
+// recoverPanic recovers from a panic while processing the event, unless panics are fatal. The panic is reported by an
+// InternalErrorEvent, and the handler with the id is unregistered if it has panicked maxHandlerPanics times.
+// It must be deferred by the function that calls the handler.
+func (el *EventLoop) recoverPanic(event interface{}, id int) {
+	if el.fatalPanics {
+		return
+	}
+	v := recover()
+	if v == nil {
+		return
+	}
+	e := InternalErrorEvent{Event: event, HandlerID: id, PanicValue: v, Stack: debug.Stack()}
+	t := reflect.TypeOf(event)
+
+	el.mut.Lock()
+	el.panics[t]++
+	disable := false
+	if id != 0 {
+		el.handlerPanics[id]++
+		disable = el.handlerPanics[id] >= maxHandlerPanics
+	}
+	if disable {
+		delete(el.handlerPanics, id)
+		el.disabledHandlers[t]++
+	}
+	logger := el.getLogger()
+	el.mut.Unlock()
+
+	if disable {
+		el.UnregisterHandler(id)
+		logger.Errorf("Unregistered handler %d of %v after it panicked %d times", id, t, maxHandlerPanics)
+	}
+
+	// a panic while an error is handled is only logged, such that it cannot repeat forever.
+	if _, ok := event.(InternalErrorEvent); ok || len(el.handlers[reflect.TypeOf(e)]) == 0 {
+		logger.Errorf("Recovered from a panic while handling %T: %v\n%s", event, v, e.Stack)
+		return
+	}
+	el.processEvent(e)
+}
@@ -3,25 +3,38 @@ package eventloop
 import "sync"
 
 // queue is a bounded circular buffer.
-// If an entry is pushed to the queue when it is full, the oldest entry will be dropped.
+// If an entry is pushed to the queue when it is full, the overflow policy of the entry decides whether the oldest
+// entry that may be dropped is dropped to make room for it, whether it is dropped itself, or whether the producer waits.
 type queue struct {
 	mut       sync.Mutex
+	room      *sync.Cond // signaled when an entry is popped
 	entries   []interface{}
+	policies  []OverflowPolicy // the overflow policies of the entries
 	head      int
-	tail      int
+	n         int
 	readyChan chan struct{}
+	closed    bool // true when no more entries are popped, such that producers no longer wait for room
+	grow      bool // true if the capacity is doubled when the queue is full, such that no entries are dropped
 }
 
-func newQueue(capacity uint) queue {
-	return queue{
+func newQueue(capacity uint) *queue {
+	q := &queue{
 		entries:   make([]interface{}, capacity),
-		head:      -1,
-		tail:      -1,
+		policies:  make([]OverflowPolicy, capacity),
 		readyChan: make(chan struct{}),
 	}
+	q.room = sync.NewCond(&q.mut)
+	return q
 }
 
+// push pushes the entry to the queue, and drops the oldest entry if the queue is full.
 func (q *queue) push(entry interface{}) {
+	q.pushWithPolicy(entry, DropOldest)
+}
+
+// pushWithPolicy pushes the entry to the queue, applying the overflow policy if the queue is full.
+// It returns the entry that was dropped, if any, which may be the pushed entry, and whether the pushed entry was queued.
+func (q *queue) pushWithPolicy(entry interface{}, policy OverflowPolicy) (dropped interface{}, ok, queued bool) {
 	q.mut.Lock()
 	defer q.mut.Unlock()
 
@@ -29,49 +42,71 @@ func (q *queue) push(entry interface{}) {
 		panic("cannot push to a queue with capacity 0")
 	}
 
-	pos := q.tail + 1
-	if pos == len(q.entries) {
-		pos = 0
+	if q.grow && q.n == len(q.entries) {
+		q.resizeLocked(2 * uint(len(q.entries)))
 	}
-	if pos == q.head {
-		// drop the entry at the head of the queue
-		q.head++
-		if q.head == len(q.entries) {
-			q.head = 0
+	for q.n == len(q.entries) {
+		if policy != DropNewest {
+			if i, found := q.oldestDroppable(); found {
+				dropped, ok = q.remove(i), true
+				break
+			}
 		}
+		if policy != Block || q.closed {
+			return entry, true, false
+		}
+		q.room.Wait()
 	}
-	q.entries[pos] = entry
-	q.tail = pos
 
-	if q.head == -1 {
-		q.head = pos
-	}
+	pos := (q.head + q.n) % len(q.entries)
+	q.entries[pos] = entry
+	q.policies[pos] = policy
+	q.n++
 
 	select {
 	case q.readyChan <- struct{}{}:
 	default:
 	}
+	return dropped, ok, true
+}
+
+// oldestDroppable returns the position, relative to the head, of the oldest entry with the DropOldest policy.
+func (q *queue) oldestDroppable() (int, bool) {
+	for i := 0; i < q.n; i++ {
+		if q.policies[(q.head+i)%len(q.entries)] == DropOldest {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// remove removes the entry at the position relative to the head, and moves the entries in front of it back.
+func (q *queue) remove(i int) interface{} {
+	c := len(q.entries)
+	entry := q.entries[(q.head+i)%c]
+	for j := i; j > 0; j-- {
+		to, from := (q.head+j)%c, (q.head+j-1)%c
+		q.entries[to], q.policies[to] = q.entries[from], q.policies[from]
+	}
+	q.entries[q.head] = nil
+	q.head = (q.head + 1) % c
+	q.n--
+	return entry
 }
 
 func (q *queue) pop() (entry interface{}, ok bool) {
 	q.mut.Lock()
 	defer q.mut.Unlock()
 
-	if q.head == -1 {
+	if q.n == 0 {
 		return nil, false
 	}
 
 	entry = q.entries[q.head]
-
-	if q.head == q.tail {
-		q.head = -1
-		q.tail = -1
-	} else {
-		q.head++
-		if q.head == len(q.entries) {
-			q.head = 0
-		}
-	}
+	q.entries[q.head] = nil
+	q.head = (q.head + 1) % len(q.entries)
+	q.n--
+	q.room.Signal()
 
 	return entry, true
 }
@@ -80,15 +115,38 @@ func (q *queue) len() int {
 	q.mut.Lock()
 	defer q.mut.Unlock()
 
-	if q.head == -1 {
-		return 0
-	}
+	return q.n
+}
+
+// resize changes the capacity of the queue, keeping its entries.
+// The capacity is not reduced below the number of entries in the queue.
+func (q *queue) resize(capacity uint) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	q.resizeLocked(capacity)
+}
 
-	if q.head <= q.tail {
-		return q.tail - q.head + 1
+// resizeLocked changes the capacity of the queue. The caller must hold the mutex.
+func (q *queue) resizeLocked(capacity uint) {
+	if int(capacity) < q.n {
+		capacity = uint(q.n)
 	}
+	entries := make([]interface{}, capacity)
+	policies := make([]OverflowPolicy, capacity)
+	for i := 0; i < q.n; i++ {
+		entries[i] = q.entries[(q.head+i)%len(q.entries)]
+		policies[i] = q.policies[(q.head+i)%len(q.policies)]
+	}
+	q.entries, q.policies, q.head = entries, policies, 0
+	q.room.Broadcast()
+}
 
-	return len(q.entries) - q.head + q.tail + 1
+// close stops the producers from waiting for room, such that they drop their entries instead.
+func (q *queue) close() {
+	q.mut.Lock()
+	q.closed = true
+	q.mut.Unlock()
+	q.room.Broadcast()
 }
 
 func (q *queue) ready() <-chan struct{} {
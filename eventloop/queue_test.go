@@ -1,6 +1,9 @@
 package eventloop
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestPopEmptyQueue(t *testing.T) {
 	q := newQueue(1)
@@ -107,3 +110,91 @@ func TestPopWhenTailInFrontOfHead(t *testing.T) {
 	}
 
 }
+
+func TestPushDropOldestSkipsOtherPolicies(t *testing.T) {
+	q := newQueue(3)
+	q.pushWithPolicy("internal", Block)
+	q.pushWithPolicy("old", DropOldest)
+	q.pushWithPolicy("new", DropOldest)
+
+	dropped, ok, _ := q.pushWithPolicy("newest", DropOldest)
+	if !ok || dropped != "old" {
+		t.Fatalf("got dropped entry %v, %v, want \"old\", true", dropped, ok)
+	}
+	for _, want := range []string{"internal", "new", "newest"} {
+		if elem, ok := q.pop(); !ok || elem != want {
+			t.Errorf("got %v, %v from q.pop(), want %q, true", elem, ok, want)
+		}
+	}
+}
+
+func TestPushDropNewest(t *testing.T) {
+	q := newQueue(1)
+	q.pushWithPolicy("hello", DropOldest)
+
+	dropped, ok, _ := q.pushWithPolicy("world", DropNewest)
+	if !ok || dropped != "world" {
+		t.Fatalf("got dropped entry %v, %v, want \"world\", true", dropped, ok)
+	}
+	if elem, ok := q.pop(); !ok || elem != "hello" {
+		t.Errorf("got %v, %v from q.pop(), want \"hello\", true", elem, ok)
+	}
+}
+
+func TestPushBlock(t *testing.T) {
+	q := newQueue(1)
+	q.pushWithPolicy("hello", Block)
+
+	done := make(chan struct{})
+	go func() {
+		if _, ok, queued := q.pushWithPolicy("world", Block); ok || !queued {
+			t.Error("expected the entry to be queued without dropping an entry")
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected the producer to wait for room")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if elem, ok := q.pop(); !ok || elem != "hello" {
+		t.Errorf("got %v, %v from q.pop(), want \"hello\", true", elem, ok)
+	}
+	<-done
+	if elem, ok := q.pop(); !ok || elem != "world" {
+		t.Errorf("got %v, %v from q.pop(), want \"world\", true", elem, ok)
+	}
+}
+
+func TestPushBlockClosed(t *testing.T) {
+	q := newQueue(1)
+	q.pushWithPolicy("hello", Block)
+
+	done := make(chan interface{})
+	go func() {
+		dropped, _, _ := q.pushWithPolicy("world", Block)
+		done <- dropped
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.close()
+	if dropped := <-done; dropped != "world" {
+		t.Errorf("got dropped entry %v, want \"world\"", dropped)
+	}
+}
+
+func TestResize(t *testing.T) {
+	q := newQueue(2)
+	q.push("hello")
+	q.push("world")
+	q.pop()
+	q.push("foo")
+
+	q.resize(3)
+	q.push("bar")
+	for _, want := range []string{"world", "foo", "bar"} {
+		if elem, ok := q.pop(); !ok || elem != want {
+			t.Errorf("got %v, %v from q.pop(), want %q, true", elem, ok, want)
+		}
+	}
+}
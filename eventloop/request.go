@@ -0,0 +1,77 @@
+package eventloop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrNoHandler is returned by Request when there is no handler for the type of the request.
+var ErrNoHandler = errors.New("no handler for the request")
+
+// ErrNotQueued is returned by Request when the request was not queued, or was dropped from a full event queue.
+var ErrNotQueued = errors.New("the request was not queued")
+
+// Request is a request that is sent to the handlers of the type of its payload by EventLoop.Request.
+// The handlers receive the *Request instead of the payload, and one of them completes the request by calling Respond,
+// either while handling it, or later from any goroutine. The observers of the type of the payload also receive the
+// *Request.
+type Request struct {
+	// ID correlates the request with its response. It is unique within the event loop.
+	ID uint64
+	// Payload is the request, whose type decides the handlers of the request, and its priority and overflow policy.
+	Payload interface{}
+
+	ctx      context.Context
+	done     chan response
+	answered uint32 // set atomically when the request is answered, or when the requester stops waiting
+}
+
+type response struct {
+	value interface{}
+	err   error
+}
+
+// Context returns the context of the requester. It is done when the requester stops waiting for the response.
+func (r *Request) Context() context.Context {
+	return r.ctx
+}
+
+// Respond completes the request with the response and the error. It returns false if the request has already been
+// answered, or if the context of the requester is done, in which case the response is discarded.
+// It is safe to call from any goroutine.
+func (r *Request) Respond(value interface{}, err error) bool {
+	if r.ctx.Err() != nil || !atomic.CompareAndSwapUint32(&r.answered, 0, 1) {
+		return false
+	}
+	r.done <- response{value, err}
+	return true
+}
+
+// Request adds the payload to the event loop as a request, as if by AddEvent, and waits until a handler of the type
+// of the payload responds to it, or the context is done, in which case the error of the context is returned.
+// The request is handled even if the context is done while it is queued; the handler can check Request.Context.
+// Request must not be called from the event loop, since it would wait for itself.
+func (el *EventLoop) Request(ctx context.Context, payload interface{}) (interface{}, error) {
+	r := &Request{
+		ID:      atomic.AddUint64(&el.requestID, 1),
+		Payload: payload,
+		ctx:     ctx,
+		done:    make(chan response, 1),
+	}
+	if !el.addEvent(payload, r) {
+		return nil, fmt.Errorf("request %d (%T): %w", r.ID, payload, ErrNotQueued)
+	}
+	select {
+	case resp := <-r.done:
+		return resp.value, resp.err
+	case <-ctx.Done():
+		if atomic.CompareAndSwapUint32(&r.answered, 0, 1) {
+			return nil, ctx.Err()
+		}
+		// the request was answered at the same time.
+		resp := <-r.done
+		return resp.value, resp.err
+	}
+}
@@ -0,0 +1,167 @@
+package eventloop
+
+import (
+	"container/heap"
+	"time"
+)
+
+// CancelFunc cancels a scheduled event. It returns true if the event will not be processed,
+// and false if the event has already been processed, or has been cancelled before.
+// It is safe to call from any goroutine.
+type CancelFunc func() bool
+
+// The states of a scheduled event.
+const (
+	scheduledPending = iota // waiting for its time in the schedule
+	scheduledQueued         // added to an event queue, but not processed yet
+	scheduledDone           // processed, dropped by the filter, or cancelled
+)
+
+// scheduledEvent is an event that is added to the event loop at a given time.
+// It is queued in place of the event, such that the event can be cancelled until it is processed.
+type scheduledEvent struct {
+	event interface{}
+	at    time.Time
+	seq   uint64 // orders the events that are scheduled for the same time by when they were scheduled
+	index int    // the index in the schedule while pending
+	state int
+}
+
+// schedule is a min-heap of the pending scheduled events, ordered by their times.
+type schedule []*scheduledEvent
+
+func (s schedule) Len() int { return len(s) }
+
+func (s schedule) Less(i, j int) bool {
+	if s[i].at.Equal(s[j].at) {
+		return s[i].seq < s[j].seq
+	}
+	return s[i].at.Before(s[j].at)
+}
+
+func (s schedule) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+	s[i].index = i
+	s[j].index = j
+}
+
+func (s *schedule) Push(x interface{}) {
+	e := x.(*scheduledEvent)
+	e.index = len(*s)
+	*s = append(*s, e)
+}
+
+func (s *schedule) Pop() interface{} {
+	old := *s
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*s = old[:len(old)-1]
+	return e
+}
+
+// AddEventAfter adds the event to the event loop when the duration has elapsed, as if by AddEvent.
+// The event is processed on the goroutine that runs or ticks the event loop, unless it is cancelled first,
+// or the event loop is shut down first.
+func (el *EventLoop) AddEventAfter(d time.Duration, event interface{}) CancelFunc {
+	el.mut.Lock()
+	now := el.clock.Now()
+	el.mut.Unlock()
+	return el.AddEventAt(now.Add(d), event)
+}
+
+// AddEventAt adds the event to the event loop at the given time of the clock of the event loop, as if by AddEvent.
+// The events that are scheduled for the same time are added in the order in which they were scheduled.
+func (el *EventLoop) AddEventAt(t time.Time, event interface{}) CancelFunc {
+	el.mut.Lock()
+	e := &scheduledEvent{event: event, at: t, seq: el.scheduleSeq}
+	el.scheduleSeq++
+	if el.scheduleStopped {
+		e.state = scheduledDone
+	} else {
+		heap.Push(&el.schedule, e)
+		el.resetWakeup()
+	}
+	el.mut.Unlock()
+	return func() bool { return el.cancelScheduled(e) }
+}
+
+// cancelScheduled cancels the event if it has not been processed.
+func (el *EventLoop) cancelScheduled(e *scheduledEvent) bool {
+	el.mut.Lock()
+	defer el.mut.Unlock()
+	switch e.state {
+	case scheduledPending:
+		heap.Remove(&el.schedule, e.index)
+		el.resetWakeup()
+	case scheduledQueued:
+		// the event is skipped when it is taken from the queue.
+	default:
+		return false
+	}
+	e.state = scheduledDone
+	return true
+}
+
+// resetWakeup sets the timer that adds the due events to the event loop to fire at the time of the next event.
+// The caller must hold the mutex.
+func (el *EventLoop) resetWakeup() {
+	if len(el.schedule) == 0 {
+		if el.wakeup != nil {
+			el.wakeup.Stop()
+		}
+		return
+	}
+	d := el.schedule[0].at.Sub(el.clock.Now())
+	if el.wakeup == nil {
+		el.wakeup = el.clock.AfterFunc(d, el.addDueEvents)
+	} else {
+		el.wakeup.Reset(d)
+	}
+}
+
+// addDueEvents adds the scheduled events whose times have come to the event queues, in the order of their times.
+func (el *EventLoop) addDueEvents() {
+	// the events are added by one caller at a time, such that they are queued in order.
+	el.dueMut.Lock()
+	defer el.dueMut.Unlock()
+	el.mut.Lock()
+	now := el.clock.Now()
+	var due []*scheduledEvent
+	for len(el.schedule) > 0 && !el.schedule[0].at.After(now) {
+		e := heap.Pop(&el.schedule).(*scheduledEvent)
+		e.state = scheduledQueued
+		due = append(due, e)
+	}
+	el.resetWakeup()
+	el.mut.Unlock()
+	for _, e := range due {
+		if !el.addEvent(e.event, e) {
+			el.mut.Lock()
+			e.state = scheduledDone
+			el.mut.Unlock()
+		}
+	}
+}
+
+// takeScheduled returns the event that was scheduled, and false if it was cancelled after it was queued.
+func (el *EventLoop) takeScheduled(e *scheduledEvent) (interface{}, bool) {
+	el.mut.Lock()
+	defer el.mut.Unlock()
+	if e.state != scheduledQueued {
+		return nil, false
+	}
+	e.state = scheduledDone
+	return e.event, true
+}
+
+// clearSchedule drops the pending scheduled events, such that their timer does not outlive the event loop.
+// The caller must hold the mutex.
+func (el *EventLoop) clearSchedule() {
+	for _, e := range el.schedule {
+		e.state = scheduledDone
+	}
+	el.schedule = nil
+	if el.wakeup != nil {
+		el.wakeup.Stop()
+	}
+}
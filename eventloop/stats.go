@@ -0,0 +1,123 @@
+package eventloop
+
+import (
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// EventStats are the processing statistics of an event type.
+type EventStats struct {
+	// The number of events of the type that were processed.
+	Count uint64 `json:"count"`
+	// The total time spent by the observers and handlers on the events of the type.
+	Total time.Duration `json:"total"`
+	// The longest time spent by the observers and handlers on a single event of the type.
+	Max time.Duration `json:"max"`
+}
+
+// typeStats are the statistics of an event type. The counters are written atomically by the event loop,
+// such that they can be read from any goroutine.
+type typeStats struct {
+	eventType reflect.Type
+	count     uint64
+	total     uint64 // nanoseconds
+	max       uint64 // nanoseconds since the start; only written by the loop
+	takenMax  uint64 // nanoseconds since the last call to TakeStats; reset by TakeStats
+
+	// the counters at the last call to TakeStats; protected by mut.
+	takenCount uint64
+	takenTotal uint64
+}
+
+// EnableStats makes the event loop collect the statistics that are returned by Stats and TakeStats.
+// Timing the events adds a small overhead to each event, so the statistics are not collected by default.
+// It must be called before the event loop is started.
+func (el *EventLoop) EnableStats() {
+	el.mut.Lock()
+	el.statsEnabled = true
+	el.mut.Unlock()
+}
+
+// typeID returns the ID of the event type, which indexes its statistics, and assigns an ID if the type has none.
+// It must be called from the event loop, or before it is started.
+func (el *EventLoop) typeID(t reflect.Type) int {
+	if id, ok := el.typeIDs[t]; ok {
+		return id
+	}
+	id := len(el.stats)
+	el.typeIDs[t] = id
+	el.mut.Lock()
+	el.stats = append(el.stats, &typeStats{eventType: t})
+	el.mut.Unlock()
+	return id
+}
+
+// record adds the time spent on an event to the statistics.
+func (s *typeStats) record(d time.Duration) {
+	ns := uint64(d)
+	atomic.AddUint64(&s.count, 1)
+	atomic.AddUint64(&s.total, ns)
+	if ns > atomic.LoadUint64(&s.max) {
+		atomic.StoreUint64(&s.max, ns)
+	}
+	for {
+		max := atomic.LoadUint64(&s.takenMax)
+		if ns <= max || atomic.CompareAndSwapUint64(&s.takenMax, max, ns) {
+			return
+		}
+	}
+}
+
+// Stats returns the statistics of the event types that have been processed since the event loop was created,
+// indexed by the event type. It returns nil if the statistics are not enabled (see EnableStats).
+// It is safe to call from any goroutine.
+func (el *EventLoop) Stats() map[string]EventStats {
+	el.mut.Lock()
+	defer el.mut.Unlock()
+	if !el.statsEnabled {
+		return nil
+	}
+	stats := make(map[string]EventStats, len(el.stats))
+	for _, s := range el.stats {
+		count := atomic.LoadUint64(&s.count)
+		if count == 0 {
+			continue
+		}
+		stats[s.eventType.String()] = EventStats{
+			Count: count,
+			Total: time.Duration(atomic.LoadUint64(&s.total)),
+			Max:   time.Duration(atomic.LoadUint64(&s.max)),
+		}
+	}
+	return stats
+}
+
+// TakeStats returns the statistics of the event types that have been processed since the previous call to TakeStats,
+// indexed by the event type, and starts a new period. It is meant for a single reader, such as a periodic measurement;
+// Stats is not affected by it. It returns nil if the statistics are not enabled (see EnableStats).
+// It is safe to call from any goroutine.
+func (el *EventLoop) TakeStats() map[string]EventStats {
+	el.mut.Lock()
+	defer el.mut.Unlock()
+	if !el.statsEnabled {
+		return nil
+	}
+	stats := make(map[string]EventStats)
+	for _, s := range el.stats {
+		count := atomic.LoadUint64(&s.count)
+		total := atomic.LoadUint64(&s.total)
+		max := atomic.SwapUint64(&s.takenMax, 0)
+		if count == s.takenCount {
+			continue
+		}
+		stats[s.eventType.String()] = EventStats{
+			Count: count - s.takenCount,
+			Total: time.Duration(total - s.takenTotal),
+			Max:   time.Duration(max),
+		}
+		s.takenCount = count
+		s.takenTotal = total
+	}
+	return stats
+}
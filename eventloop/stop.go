@@ -0,0 +1,53 @@
+package eventloop
+
+import (
+	"context"
+	"fmt"
+)
+
+// Stop stops the event loop from accepting events, such that AddEvent returns false, and waits until the events that
+// are already queued have been processed, and the shutdown hooks have run on the event loop. If the context is done
+// before the queued events have been processed, the event loop abandons them after the event that it is processing,
+// and runs the shutdown hooks. Stop returns an error if events were abandoned, or if the shutdown hooks have not run
+// when the context is done, such as when the event loop is not running. It must not be called from the event loop.
+func (el *EventLoop) Stop(ctx context.Context) error {
+	el.stopOnce.Do(func() {
+		el.mut.Lock()
+		el.stopped = true
+		el.stopCtx = ctx
+		el.mut.Unlock()
+		close(el.stopping)
+	})
+	select {
+	case <-el.done:
+	case <-ctx.Done():
+		select {
+		case <-el.done:
+		default:
+			return fmt.Errorf("the event loop did not stop: %w", ctx.Err())
+		}
+	}
+	return el.stopErr
+}
+
+// stopIfDone runs the shutdown hooks if Stop has been called, and either there are no more queued events, or the
+// context of Stop is done, in which case the next event and the remaining queued events are abandoned.
+// It returns true if the hooks were run.
+func (el *EventLoop) stopIfDone(haveEvent bool) bool {
+	select {
+	case <-el.stopping:
+	default:
+		return false
+	}
+	if el.shutDown {
+		return false
+	}
+	if haveEvent {
+		if el.stopCtx.Err() == nil {
+			return false
+		}
+		el.stopErr = fmt.Errorf("abandoned %d queued events: %w", 1+el.Len(), el.stopCtx.Err())
+	}
+	el.shutdown()
+	return true
+}
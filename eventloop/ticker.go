@@ -0,0 +1,120 @@
+package eventloop
+
+import "time"
+
+type ticker struct {
+	interval time.Duration
+	callback func(time.Time) interface{}
+	timer    Timer     // nil until the ticker is started
+	next     time.Time // the time of the next tick
+}
+
+// startTickerEvent starts a ticker when it is processed by the event loop.
+type startTickerEvent struct {
+	tickerID int
+}
+
+// tickEvent calls the callback of a ticker when it is processed by the event loop.
+type tickEvent struct {
+	tickerID int
+	tick     time.Time
+}
+
+// AddTicker adds a ticker with the specified interval and returns the ticker id.
+// The ticker calls the callback on the event loop at regular intervals, and processes the event that it returns,
+// unless it is nil. The ticks follow the clock of the event loop, such that a controlled clock (see SetClock)
+// produces one tick for each interval that it is advanced by. The ticks that are missed, because the timer of the
+// ticker fires late, are skipped, like those of a time.Ticker.
+// The returned ticker id can be used to remove the ticker with RemoveTicker.
+// The ticker will not be started before the event loop is running, and it is stopped when the event loop stops.
+func (el *EventLoop) AddTicker(interval time.Duration, callback func(tick time.Time) (event interface{})) int {
+	if interval <= 0 {
+		panic("non-positive interval for AddTicker")
+	}
+	el.mut.Lock()
+	id := el.tickerID
+	el.tickerID++
+	el.tickers[id] = &ticker{interval: interval, callback: callback}
+	el.mut.Unlock()
+
+	el.eventQ.push(startTickerEvent{id})
+
+	return id
+}
+
+// RemoveTicker removes the ticker with the specified id.
+// If the ticker was removed, RemoveTicker will return true.
+// If the ticker does not exist, false will be returned instead.
+func (el *EventLoop) RemoveTicker(id int) bool {
+	el.mut.Lock()
+	defer el.mut.Unlock()
+
+	ticker, ok := el.tickers[id]
+	if !ok {
+		return false
+	}
+	if ticker.timer != nil {
+		ticker.timer.Stop()
+	}
+	delete(el.tickers, id)
+	return true
+}
+
+// startTicker ticks the ticker once, and sets its timer to the next tick.
+func (el *EventLoop) startTicker(id int) {
+	el.mut.Lock()
+	ticker, ok := el.tickers[id]
+	if !ok || ticker.timer != nil || el.tickersStopped {
+		el.mut.Unlock()
+		return
+	}
+	now := el.clock.Now()
+	ticker.next = now.Add(ticker.interval)
+	ticker.timer = el.clock.AfterFunc(ticker.interval, func() { el.fireTicker(id) })
+	el.mut.Unlock()
+
+	el.tick(tickEvent{tickerID: id, tick: now})
+}
+
+// fireTicker adds a tick of the ticker to the event loop, and sets its timer to the next tick.
+func (el *EventLoop) fireTicker(id int) {
+	el.mut.Lock()
+	ticker, ok := el.tickers[id]
+	if !ok || el.tickersStopped {
+		el.mut.Unlock()
+		return
+	}
+	now := el.clock.Now()
+	for !ticker.next.After(now) {
+		ticker.next = ticker.next.Add(ticker.interval)
+	}
+	ticker.timer.Reset(ticker.next.Sub(now))
+	el.mut.Unlock()
+
+	el.AddEvent(tickEvent{tickerID: id, tick: now})
+}
+
+// tick calls the callback of the ticker, unless it has been removed, and processes the event that it returns.
+func (el *EventLoop) tick(e tickEvent) {
+	el.mut.Lock()
+	ticker, ok := el.tickers[e.tickerID]
+	el.mut.Unlock()
+	if !ok {
+		return
+	}
+	if event := ticker.callback(e.tick); event != nil {
+		el.processEvent(event)
+	}
+}
+
+// stopTickers stops the timers of the tickers, such that they do not outlive the event loop.
+func (el *EventLoop) stopTickers() {
+	el.mut.Lock()
+	defer el.mut.Unlock()
+	el.tickersStopped = true
+	for _, ticker := range el.tickers {
+		if ticker.timer != nil {
+			ticker.timer.Stop()
+		}
+	}
+}
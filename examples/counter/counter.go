@@ -0,0 +1,112 @@
+// Package counter implements the counting workload of the benchmarks as an external application,
+// which runs as a separate process from the replica and serves the Application service of apppb.
+// It counts the executed blocks and commands, and hashes the data of the commands in the order that they are executed,
+// such that its hash can be compared with the hash of the replica.
+package counter
+
+import (
+	"context"
+	"crypto/sha256"
+	"hash"
+	"sync"
+
+	"github.com/relab/hotstuff/internal/proto/apppb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Config configures the counter.
+type Config struct {
+	// Proposals with commands larger than this number of bytes are rejected. There is no limit if this is zero.
+	MaxCommandSize int
+	// The batch size suggested to the replica. The replica's own batch size is used if this is zero.
+	BatchSize uint32
+}
+
+// Counter counts and hashes the executed commands. It implements apppb.ApplicationServer.
+type Counter struct {
+	apppb.UnimplementedApplicationServer
+
+	conf     Config
+	mut      sync.Mutex
+	height   uint64 // the height of the last executed block
+	commands uint64
+	hash     hash.Hash
+}
+
+// New returns a counter that has not executed any commands.
+func New(conf Config) *Counter {
+	return &Counter{conf: conf, hash: sha256.New()}
+}
+
+// Accept rejects the proposals that contain commands larger than the maximum command size.
+func (c *Counter) Accept(_ context.Context, req *apppb.AcceptRequest) (*apppb.AcceptResponse, error) {
+	if c.conf.MaxCommandSize > 0 {
+		for _, cmd := range req.GetCommands() {
+			if len(cmd.GetData()) > c.conf.MaxCommandSize {
+				return &apppb.AcceptResponse{Accept: false}, nil
+			}
+		}
+	}
+	return &apppb.AcceptResponse{Accept: true}, nil
+}
+
+// BatchHint suggests the configured batch size.
+func (c *Counter) BatchHint(_ context.Context, _ *apppb.BatchHintRequest) (*apppb.BatchHintResponse, error) {
+	return &apppb.BatchHintResponse{BatchSize: c.conf.BatchSize}, nil
+}
+
+// Execute executes the blocks in the order of their heights. The blocks that have already been executed,
+// which are sent again when the replica reopens the stream, are acknowledged without being executed again.
+func (c *Counter) Execute(stream apppb.Application_ExecuteServer) error {
+	for {
+		block, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if err := c.execute(block); err != nil {
+			return err
+		}
+		if err := stream.Send(&apppb.Executed{Height: block.GetHeight()}); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Counter) execute(block *apppb.Block) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if block.GetHeight() <= c.height {
+		return nil
+	}
+	if block.GetHeight() != c.height+1 {
+		return status.Errorf(codes.FailedPrecondition, "expected block %d, but got block %d", c.height+1, block.GetHeight())
+	}
+	for _, cmd := range block.GetCommands() {
+		_, _ = c.hash.Write(cmd.GetData())
+		c.commands++
+	}
+	c.height = block.GetHeight()
+	return nil
+}
+
+// Height returns the height of the last executed block.
+func (c *Counter) Height() uint64 {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.height
+}
+
+// Commands returns the number of executed commands.
+func (c *Counter) Commands() uint64 {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.commands
+}
+
+// Hash returns the hash of the data of the executed commands.
+func (c *Counter) Hash() []byte {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.hash.Sum(nil)
+}
@@ -0,0 +1,382 @@
+// Package kvstore implements a key-value store that can be replicated by the HotStuff replicas.
+// Its commands are created by Put, Get, Delete, and Checkpoint. Its queries are keys, and are answered with the value of the key.
+// Other commands, such as the payloads of the benchmark client, are ignored.
+//
+// The store maintains a hash of its state, which is updated incrementally as commands are executed,
+// such that the states of the replicas can be compared without transferring them.
+// Checkpoint commands record the hash of the state at the point in the order of the commands where they are executed,
+// such that the replicas can also be compared while commands are still being executed.
+package kvstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/relab/hotstuff/consensus"
+)
+
+// magic starts each command of the store, such that other commands are ignored.
+var magic = []byte("\x00kv")
+
+// The operations of the commands.
+const (
+	opPut        = 'p'
+	opGet        = 'g'
+	opDelete     = 'd'
+	opCheckpoint = 'c'
+)
+
+// maxCheckpoints is the number of checkpoints that the store remembers.
+const maxCheckpoints = 1000
+
+// command returns a command with the operation, key, and value.
+// The key is preceded by its length, and the value is the rest of the command.
+func command(op byte, key string, value []byte) []byte {
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(lenBuf, uint64(len(key)))
+	cmd := make([]byte, 0, len(magic)+1+n+len(key)+len(value))
+	cmd = append(cmd, magic...)
+	cmd = append(cmd, op)
+	cmd = append(cmd, lenBuf[:n]...)
+	cmd = append(cmd, key...)
+	return append(cmd, value...)
+}
+
+// Put returns a command that sets the key to the value.
+func Put(key string, value []byte) []byte {
+	return command(opPut, key, value)
+}
+
+// Get returns a command that reads the key. It does not change the state, but orders the read with the writes:
+// its result is the value of the key when the command is executed.
+func Get(key string) []byte {
+	return command(opGet, key, nil)
+}
+
+// Delete returns a command that removes the key.
+func Delete(key string) []byte {
+	return command(opDelete, key, nil)
+}
+
+// Checkpoint returns a command that records the hash of the state when it is executed.
+func Checkpoint() []byte {
+	return command(opCheckpoint, "", nil)
+}
+
+// parse returns the operation, key, and value of a command. It returns false if the command is not a command of the store.
+func parse(data []byte) (op byte, key string, value []byte, ok bool, err error) {
+	if !bytes.HasPrefix(data, magic) {
+		return 0, "", nil, false, nil
+	}
+	data = data[len(magic):]
+	if len(data) == 0 {
+		return 0, "", nil, true, errors.New("kvstore: missing operation")
+	}
+	op, data = data[0], data[1:]
+	n, size := binary.Uvarint(data)
+	if size <= 0 || n > uint64(len(data)-size) {
+		return 0, "", nil, true, errors.New("kvstore: malformed key")
+	}
+	key, value = string(data[size:size+int(n)]), data[size+int(n):]
+	switch op {
+	case opPut:
+	case opGet, opDelete:
+		if len(value) > 0 {
+			return 0, "", nil, true, fmt.Errorf("kvstore: unexpected value in '%c' command", op)
+		}
+	case opCheckpoint:
+		if key != "" || len(value) > 0 {
+			return 0, "", nil, true, errors.New("kvstore: unexpected key or value in checkpoint command")
+		}
+	default:
+		return 0, "", nil, true, fmt.Errorf("kvstore: unknown operation '%c'", op)
+	}
+	return op, key, value, true, nil
+}
+
+// CheckpointHash is the hash of the state of the store when a checkpoint command was executed.
+type CheckpointHash struct {
+	Index uint64 // the number of commands of the store that were executed before the checkpoint
+	Hash  []byte
+}
+
+// Store is a replicated key-value store. It implements replica.Application, replica.ResultExecutor, replica.Validator,
+// replica.QueryExecutor, replica.Snapshotter, and replica.SpeculativeExecutor. Its snapshots are saved to files by
+// a FileSnapshotter.
+//
+// Speculatively executed commands are applied to the state like other commands, and the changes that they make are
+// recorded in an undo log until they are confirmed or rolled back. The snapshots, the queries, the state hash,
+// and the checkpoints only reflect the confirmed commands.
+type Store struct {
+	mut         sync.Mutex
+	values      map[string][]byte
+	executed    uint64            // the number of commands of the store that have been executed
+	hash        [sha256.Size]byte // the XOR of the hashes of the keys and values
+	checkpoints []CheckpointHash
+	undo        []undoEntry // the speculatively executed commands that have not been confirmed, in the order they were executed
+}
+
+// undoEntry records the state that a speculatively executed command changed, such that it can be rolled back.
+type undoEntry struct {
+	view        consensus.View
+	op          byte
+	key         string
+	value       []byte // the previous value of the key
+	set         bool   // the key was set before the command
+	executed    uint64
+	hash        [sha256.Size]byte
+	checkpoints []CheckpointHash // the previous checkpoints, if the command is a checkpoint
+}
+
+// New returns an empty store.
+func New() *Store {
+	return &Store{values: make(map[string][]byte)}
+}
+
+// Validate returns an error if the data starts like a command of the store, but is malformed.
+// The replicas do not vote for proposals with such commands. See Validator for a stricter validator.
+func (s *Store) Validate(data []byte) error {
+	_, _, _, _, err := parse(data)
+	return err
+}
+
+// Execute applies a command created by Put, Get, Delete, or Checkpoint.
+func (s *Store) Execute(data []byte) {
+	s.ExecuteWithResult(data)
+}
+
+// ExecuteWithResult applies a command created by Put, Get, Delete, or Checkpoint, and returns its result.
+// The result of a Get is the value of the key, which is empty if the key is not set.
+// The other commands have no results.
+func (s *Store) ExecuteWithResult(data []byte) []byte {
+	op, key, value, ok, err := parse(data)
+	if !ok || err != nil {
+		return nil
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.apply(op, key, value)
+}
+
+// Exec speculatively applies a command of the block in the view, and returns its result.
+// The command is recorded in the undo log until it is confirmed or rolled back.
+func (s *Store) Exec(view consensus.View, data []byte) []byte {
+	op, key, value, ok, err := parse(data)
+	if !ok || err != nil {
+		return nil
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	entry := undoEntry{view: view, op: op, key: key, executed: s.executed, hash: s.hash}
+	entry.value, entry.set = s.values[key]
+	if op == opCheckpoint {
+		entry.checkpoints = append([]CheckpointHash(nil), s.checkpoints...)
+	}
+	s.undo = append(s.undo, entry)
+	return s.apply(op, key, value)
+}
+
+// Confirm forgets how to roll back the speculatively executed commands of the blocks up to and including the view.
+func (s *Store) Confirm(view consensus.View) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	i := 0
+	for i < len(s.undo) && s.undo[i].view <= view {
+		i++
+	}
+	s.undo = append(s.undo[:0], s.undo[i:]...)
+}
+
+// Rollback undoes the speculatively executed commands of the blocks after the view, in the reverse order of their execution.
+func (s *Store) Rollback(toView consensus.View) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	for len(s.undo) > 0 && s.undo[len(s.undo)-1].view > toView {
+		entry := s.undo[len(s.undo)-1]
+		s.undo = s.undo[:len(s.undo)-1]
+		switch entry.op {
+		case opPut, opDelete:
+			if entry.set {
+				s.values[entry.key] = entry.value
+			} else {
+				delete(s.values, entry.key)
+			}
+		case opCheckpoint:
+			s.checkpoints = entry.checkpoints
+		}
+		s.executed = entry.executed
+		s.hash = entry.hash
+	}
+}
+
+// apply applies the command to the state, and returns its result. It must be called with the mutex held.
+func (s *Store) apply(op byte, key string, value []byte) (result []byte) {
+	switch op {
+	case opGet:
+		result = append([]byte(nil), s.values[key]...)
+	case opPut:
+		s.remove(key)
+		value = append([]byte(nil), value...)
+		s.values[key] = value
+		s.toggle(key, value)
+	case opDelete:
+		s.remove(key)
+	case opCheckpoint:
+		if len(s.checkpoints) == maxCheckpoints {
+			s.checkpoints = append(s.checkpoints[:0], s.checkpoints[1:]...)
+		}
+		s.checkpoints = append(s.checkpoints, CheckpointHash{Index: s.executed, Hash: s.stateHash()})
+	}
+	s.executed++
+	return result
+}
+
+// remove removes the key and its value from the state and its hash, if it is set.
+func (s *Store) remove(key string) {
+	if old, ok := s.values[key]; ok {
+		s.toggle(key, old)
+		delete(s.values, key)
+	}
+}
+
+// toggle adds the key and value to the hash of the state, or removes them if they were already added.
+func (s *Store) toggle(key string, value []byte) {
+	h := sha256.New()
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	_, _ = h.Write(lenBuf[:binary.PutUvarint(lenBuf, uint64(len(key)))])
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write(value)
+	for i, b := range h.Sum(nil) {
+		s.hash[i] ^= b
+	}
+}
+
+func (s *Store) stateHash() []byte {
+	hash := s.hash
+	return hash[:]
+}
+
+// StateHash returns the hash of the keys and values of the store.
+// Stores with the same keys and values have the same hash, regardless of the commands that led to them.
+func (s *Store) StateHash() []byte {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if len(s.undo) > 0 {
+		hash := s.undo[0].hash
+		return hash[:]
+	}
+	return s.stateHash()
+}
+
+// Checkpoints returns the hashes recorded by the most recently executed checkpoint commands, in the order they were executed.
+func (s *Store) Checkpoints() []CheckpointHash {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	for _, entry := range s.undo {
+		if entry.op == opCheckpoint {
+			return append([]CheckpointHash(nil), entry.checkpoints...)
+		}
+	}
+	return append([]CheckpointHash(nil), s.checkpoints...)
+}
+
+// Query returns the value of the key given by the query. The value of a key that has not been set is empty.
+func (s *Store) Query(query []byte) ([]byte, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	value, _ := s.confirmed(string(query))
+	return value, nil
+}
+
+// confirmed returns the value of the key before the speculatively executed commands, and whether it was set.
+// It must be called with the mutex held.
+func (s *Store) confirmed(key string) ([]byte, bool) {
+	for _, entry := range s.undo {
+		if (entry.op == opPut || entry.op == opDelete) && entry.key == key {
+			return entry.value, entry.set
+		}
+	}
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// Snapshot returns the number of executed commands, followed by the keys and values of the store, in the order of the keys.
+// The number of commands, and each key and value, is encoded with its length.
+func (s *Store) Snapshot() ([]byte, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	values, executed := s.values, s.executed
+	if len(s.undo) > 0 {
+		// the snapshot is taken of a copy of the values, to which the undo log is applied.
+		values = make(map[string][]byte, len(s.values))
+		for key, value := range s.values {
+			values[key] = value
+		}
+		for i := len(s.undo) - 1; i >= 0; i-- {
+			entry := s.undo[i]
+			if entry.op != opPut && entry.op != opDelete {
+				continue
+			}
+			if entry.set {
+				values[entry.key] = entry.value
+			} else {
+				delete(values, entry.key)
+			}
+		}
+		executed = s.undo[0].executed
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	buf.Write(lenBuf[:binary.PutUvarint(lenBuf, executed)])
+	for _, key := range keys {
+		for _, b := range [][]byte{[]byte(key), values[key]} {
+			buf.Write(lenBuf[:binary.PutUvarint(lenBuf, uint64(len(b)))])
+			buf.Write(b)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Restore replaces the keys and values of the store with those of a snapshot created by Snapshot.
+// The checkpoints that were recorded before the snapshot, and the speculatively executed commands, are forgotten.
+func (s *Store) Restore(snapshot []byte) error {
+	restored := New()
+	executed, size := binary.Uvarint(snapshot)
+	if size <= 0 {
+		return errors.New("kvstore: malformed snapshot")
+	}
+	restored.executed = executed
+	snapshot = snapshot[size:]
+	for len(snapshot) > 0 {
+		var pair [2][]byte
+		for i := range pair {
+			n, size := binary.Uvarint(snapshot)
+			if size <= 0 || n > uint64(len(snapshot)-size) {
+				return errors.New("kvstore: malformed snapshot")
+			}
+			pair[i] = snapshot[size : size+int(n)]
+			snapshot = snapshot[size+int(n):]
+		}
+		value := append([]byte(nil), pair[1]...)
+		restored.values[string(pair[0])] = value
+		restored.toggle(string(pair[0]), value)
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.values = restored.values
+	s.executed = restored.executed
+	s.hash = restored.hash
+	s.checkpoints = nil
+	s.undo = nil
+	return nil
+}
@@ -0,0 +1,70 @@
+package kvstore
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/atomicfile"
+)
+
+// keepSnapshots is the number of snapshot files that a FileSnapshotter keeps.
+// The snapshot before the latest is kept, since the latest may not yet be referenced by the replica's manifest.
+const keepSnapshots = 2
+
+// FileSnapshotter saves snapshots of a store to files in a directory. It implements consensus.Snapshotter.
+type FileSnapshotter struct {
+	store *Store
+	dir   string
+}
+
+// NewFileSnapshotter returns a snapshotter that saves the snapshots of the store to files in dir.
+func NewFileSnapshotter(store *Store, dir string) *FileSnapshotter {
+	return &FileSnapshotter{store: store, dir: dir}
+}
+
+// TakeSnapshot writes a snapshot of the store to a file named after the view, and removes the older snapshot files.
+// The file is written atomically, such that a crash does not leave a partially written snapshot.
+func (s *FileSnapshotter) TakeSnapshot(upToView consensus.View) (consensus.SnapshotMeta, error) {
+	data, err := s.store.Snapshot()
+	if err != nil {
+		return consensus.SnapshotMeta{}, err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return consensus.SnapshotMeta{}, fmt.Errorf("kvstore: failed to create the snapshot directory: %w", err)
+	}
+	path := filepath.Join(s.dir, fmt.Sprintf("kvstore-%020d.snap", upToView))
+	if err := atomicfile.WriteFile(path, data); err != nil {
+		return consensus.SnapshotMeta{}, fmt.Errorf("kvstore: failed to write snapshot: %w", err)
+	}
+	s.removeOld()
+	return consensus.SnapshotMeta{View: upToView, Size: int64(len(data)), Path: path}, nil
+}
+
+// removeOld removes all but the newest snapshot files. Errors are ignored, since the files are only wasting space.
+func (s *FileSnapshotter) removeOld() {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "kvstore-*.snap"))
+	if err != nil || len(paths) <= keepSnapshots {
+		return
+	}
+	// the views are zero-padded, so the names sort in the order of the views.
+	sort.Strings(paths)
+	for _, path := range paths[:len(paths)-keepSnapshots] {
+		_ = os.Remove(path)
+	}
+}
+
+// Restore replaces the state of the store with the snapshot read from r.
+func (s *FileSnapshotter) Restore(meta consensus.SnapshotMeta, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("kvstore: failed to read snapshot: %w", err)
+	}
+	if meta.Size > 0 && int64(len(data)) != meta.Size {
+		return fmt.Errorf("kvstore: snapshot has %d bytes, expected %d", len(data), meta.Size)
+	}
+	return s.store.Restore(data)
+}
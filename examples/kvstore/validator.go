@@ -0,0 +1,26 @@
+package kvstore
+
+import "fmt"
+
+// Validator rejects the commands of the store that are malformed, or whose keys or values are too large.
+// It implements replica.Validator, and can be set as the validator of the replicas in place of the store.
+type Validator struct {
+	MaxKeySize   int // the maximum size of a key in bytes; unbounded if zero
+	MaxValueSize int // the maximum size of a value in bytes; unbounded if zero
+}
+
+// Validate returns an error if the data is a malformed command of the store, or if its key or value is too large.
+// Other commands are valid.
+func (v Validator) Validate(data []byte) error {
+	_, key, value, ok, err := parse(data)
+	if !ok || err != nil {
+		return err
+	}
+	if v.MaxKeySize > 0 && len(key) > v.MaxKeySize {
+		return fmt.Errorf("kvstore: key of %d bytes exceeds the limit of %d bytes", len(key), v.MaxKeySize)
+	}
+	if v.MaxValueSize > 0 && len(value) > v.MaxValueSize {
+		return fmt.Errorf("kvstore: value of %d bytes exceeds the limit of %d bytes", len(value), v.MaxValueSize)
+	}
+	return nil
+}
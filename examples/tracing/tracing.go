@@ -0,0 +1,119 @@
+// Package tracing exports spans for the tagged commands of the clients, as an example of how the tags can be used
+// for distributed tracing. The tags are expected to hold a trace context, which is copied to the spans as is,
+// such that a collector can attach the spans to the traces of the requests.
+// The exporter is a consensus module, which is registered with the other modules of a replica,
+// and is only accessed from the event loop.
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/replica"
+)
+
+// The names of the spans.
+const (
+	// IncludeSpan is emitted when a replica learns of a proposal that includes the command.
+	IncludeSpan = "include"
+	// CommitSpan lasts from when the command was included until the block that includes it was committed.
+	CommitSpan = "commit"
+)
+
+// Span is a step in the processing of a tagged command by a replica.
+type Span struct {
+	Name           string
+	Tag            []byte
+	Replica        hotstuff.ID
+	ClientID       uint32
+	SequenceNumber uint64
+	Block          consensus.Hash
+	View           consensus.View
+	Start          time.Time
+	End            time.Time
+}
+
+// Exporter writes a span for each tagged command when it is included in a proposal and when it is committed.
+// The spans are written as JSON objects, one per line. The leader of a view does not receive its own proposal,
+// so it only exports the commit spans of its blocks, which then start when they are committed.
+type Exporter struct {
+	mods     *consensus.Modules
+	enc      *json.Encoder
+	included map[consensus.Hash]time.Time // when the proposals that have not been committed yet were received
+}
+
+// New returns an exporter that writes the spans to w.
+func New(w io.Writer) *Exporter {
+	return &Exporter{enc: json.NewEncoder(w), included: make(map[consensus.Hash]time.Time)}
+}
+
+// InitConsensusModule gives the module a reference to the Modules object.
+// It also allows the module to set module options using the OptionsBuilder.
+func (e *Exporter) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
+	e.mods = mods
+	e.mods.EventLoop().RegisterObserver(consensus.ProposeMsg{}, func(event interface{}) {
+		e.onPropose(event.(consensus.ProposeMsg))
+	})
+	e.mods.EventLoop().RegisterObserver(consensus.CommitEvent{}, func(event interface{}) {
+		e.onCommit(event.(consensus.CommitEvent))
+	})
+}
+
+func (e *Exporter) onPropose(proposal consensus.ProposeMsg) {
+	now := time.Now()
+	block := proposal.Block
+	e.included[block.Hash()] = now
+	e.export(IncludeSpan, block, now, now)
+}
+
+func (e *Exporter) onCommit(commit consensus.CommitEvent) {
+	block := commit.Block
+	if block == nil {
+		return
+	}
+	now := time.Now()
+	start, ok := e.included[block.Hash()]
+	if !ok {
+		// the proposal was not observed, for example because the block was fetched from another replica.
+		start = now
+	}
+	// proposals that were not committed by now have been forked, and will never be committed.
+	for hash := range e.included {
+		if b, ok := e.mods.BlockChain().LocalGet(hash); !ok || b.View() <= block.View() {
+			delete(e.included, hash)
+		}
+	}
+	e.export(CommitSpan, block, start, now)
+}
+
+// export writes a span for each tagged command in the block.
+func (e *Exporter) export(name string, block *consensus.Block, start, end time.Time) {
+	cmds, err := replica.ClientCommands(block.Command())
+	if err != nil {
+		e.mods.Logger().Warnf("Failed to decode the commands of block %.8s: %v", block.Hash(), err)
+		return
+	}
+	for _, cmd := range cmds {
+		if len(cmd.Tag) == 0 {
+			continue
+		}
+		err := e.enc.Encode(Span{
+			Name:           name,
+			Tag:            cmd.Tag,
+			Replica:        e.mods.ID(),
+			ClientID:       cmd.ClientID,
+			SequenceNumber: cmd.SequenceNumber,
+			Block:          block.Hash(),
+			View:           block.View(),
+			Start:          start,
+			End:            end,
+		})
+		if err != nil {
+			e.mods.Logger().Warnf("Failed to export span: %v", err)
+			return
+		}
+	}
+}
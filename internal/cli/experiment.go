@@ -0,0 +1,538 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/client"
+	"github.com/relab/hotstuff/internal/orchestration"
+	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"gopkg.in/yaml.v2"
+)
+
+// hostConfig is the assignment of replicas and clients to a host in the hosts-config list of an experiment file.
+type hostConfig struct {
+	Name     string `yaml:"name"`
+	Clients  int    `yaml:"clients"`
+	Replicas int    `yaml:"replicas"`
+}
+
+// replicaOverrides replaces the options of some replicas in the replica-overrides list of an experiment file.
+// The options that are not set are the same as for the other replicas.
+type replicaOverrides struct {
+	IDs               []uint32 `yaml:"ids" mapstructure:"ids"`
+	Consensus         string   `yaml:"consensus,omitempty" mapstructure:"consensus"`
+	Crypto            string   `yaml:"crypto,omitempty" mapstructure:"crypto"`
+	LeaderRotation    string   `yaml:"leader-rotation,omitempty" mapstructure:"leader-rotation"`
+	CryptoCacheSize   *uint32  `yaml:"crypto-cache-size,omitempty" mapstructure:"crypto-cache-size"`
+	BatchSize         uint32   `yaml:"batch-size,omitempty" mapstructure:"batch-size"`
+	ViewTimeout       string   `yaml:"view-timeout,omitempty" mapstructure:"view-timeout"`
+	MaxTimeout        string   `yaml:"max-timeout,omitempty" mapstructure:"max-timeout"`
+	TimeoutMultiplier float32  `yaml:"timeout-multiplier,omitempty" mapstructure:"timeout-multiplier"`
+}
+
+// resourceLimits limits the CPUs of some replicas and clients in the resources list of an experiment file.
+// The CPUs are given as a list of CPUs and ranges of CPUs, such as "0-3,6".
+type resourceLimits struct {
+	Replicas []uint32 `yaml:"replicas,omitempty" mapstructure:"replicas"`
+	Clients  []uint32 `yaml:"clients,omitempty" mapstructure:"clients"`
+	CPUs     string   `yaml:"cpus,omitempty" mapstructure:"cpus"`
+	MaxProcs uint32   `yaml:"max-procs,omitempty" mapstructure:"max-procs"`
+}
+
+// emulationConfig is the emulation section of an experiment file, which emulates the network links between the hosts.
+// The one-way latencies are given either as a full matrix of the hosts, or by labeling the hosts with regions, and
+// giving the latencies between the regions. The latencies between two regions are the same in both directions,
+// unless both directions are given, and the latencies in the matrix take precedence over those of the regions.
+type emulationConfig struct {
+	Interface       string                       `yaml:"interface,omitempty" mapstructure:"interface"`
+	Jitter          string                       `yaml:"jitter,omitempty" mapstructure:"jitter"`
+	Loss            float64                      `yaml:"loss,omitempty" mapstructure:"loss"`
+	Latencies       map[string]map[string]string `yaml:"latencies,omitempty" mapstructure:"latencies"`
+	Regions         map[string]string            `yaml:"regions,omitempty" mapstructure:"regions"`
+	RegionLatencies map[string]map[string]string `yaml:"region-latencies,omitempty" mapstructure:"region-latencies"`
+}
+
+// newExperiment returns the experiment that is configured by the flags and the experiment file read by v,
+// and checks that it is valid. The hosts of the experiment are added without workers,
+// such that the experiment can be validated before the workers are deployed.
+func newExperiment(v *viper.Viper, flags *pflag.FlagSet) (*orchestration.Experiment, error) {
+	var err error
+	experiment := &orchestration.Experiment{
+		Logger:      logging.New("ctrl"),
+		NumReplicas: v.GetInt("replicas"),
+		NumClients:  v.GetInt("clients"),
+		Duration:    v.GetDuration("duration"),
+		Seed:        v.GetInt64("seed"),
+
+		ProgressInterval: v.GetDuration("progress-interval"),
+		StallTimeout:     v.GetDuration("stall-timeout"),
+		Timeouts: orchestration.Timeouts{
+			Start:      v.GetDuration("start-timeout"),
+			Run:        v.GetDuration("run-timeout"),
+			Stop:       v.GetDuration("stop-timeout"),
+			Collect:    v.GetDuration("collect-timeout"),
+			RunRetries: v.GetInt("run-retries"),
+		},
+
+		ReplicaOpts: &orchestrationpb.ReplicaOpts{
+			UseTLS:               true,
+			BatchSize:            v.GetUint32("batch-size"),
+			BatchBytes:           v.GetUint32("batch-bytes"),
+			BatchDelay:           durationpb.New(v.GetDuration("batch-delay")),
+			MaxBlockBytes:        v.GetUint32("max-block-bytes"),
+			ExecutionLag:         v.GetUint32("execution-lag"),
+			TimeoutMultiplier:    float32(v.GetFloat64("timeout-multiplier")),
+			Consensus:            v.GetString("consensus"),
+			Crypto:               v.GetString("crypto"),
+			CryptoCacheSize:      proto.Uint32(v.GetUint32("crypto-cache-size")),
+			LeaderRotation:       v.GetString("leader-rotation"),
+			ConnectTimeout:       durationpb.New(v.GetDuration("connect-timeout")),
+			InitialTimeout:       durationpb.New(v.GetDuration("view-timeout")),
+			TimeoutSamples:       v.GetUint32("duration-samples"),
+			MaxTimeout:           durationpb.New(v.GetDuration("max-timeout")),
+			SharedSeed:           v.GetInt64("shared-seed"),
+			CompressProposals:    v.GetBool("compress-proposals"),
+			Transport:            v.GetString("transport"),
+			Jitter:               durationpb.New(v.GetDuration("jitter")),
+			RateLimitInbound:     v.GetBool("rate-limit-inbound"),
+			TreeFanout:           v.GetUint32("tree-fanout"),
+			GossipInterval:       durationpb.New(v.GetDuration("gossip-interval")),
+			HealthCheckInterval:  durationpb.New(v.GetDuration("health-check-interval")),
+			HealthCheckThreshold: v.GetUint32("health-check-threshold"),
+			ProposalChunkSize:    v.GetUint32("proposal-chunk-size"),
+			SendQueueSize:        v.GetUint32("send-queue-size"),
+			UnixSocketDir:        v.GetString("unix-socket-dir"),
+			DedupSize:            v.GetUint32("dedup-size"),
+			DedupTTL:             durationpb.New(v.GetDuration("dedup-ttl")),
+			ForwardCommands:      v.GetBool("forward-commands"),
+			CommandCacheSize:     v.GetUint32("command-cache-size"),
+			CommandCacheBytes:    v.GetUint64("command-cache-bytes"),
+			EvictCommands:        v.GetBool("evict-commands"),
+			EventQueueCapacity:   v.GetUint32("event-queue-capacity"),
+			FatalPanics:          v.GetBool("fatal-panics"),
+			DrainTimeout:         durationpb.New(v.GetDuration("replica-drain-timeout")),
+			SnapshotInterval:     v.GetUint32("snapshot-interval"),
+			StatusListenAddress:  v.GetString("status-listen"),
+			DataDir:              v.GetString("data-dir"),
+			DurableSnapshotViews: v.GetUint32("durable-snapshot-views"),
+			DurableSnapshotBytes: v.GetUint64("durable-snapshot-bytes"),
+			PersistCommands:      v.GetBool("persist-commands"),
+			CommandLogSync:       v.GetString("command-log-sync"),
+			BatchPolicy:          v.GetString("batch-policy"),
+		},
+		ClientOpts: &orchestrationpb.ClientOpts{
+			UseTLS:           true,
+			ConnectTimeout:   durationpb.New(v.GetDuration("connect-timeout")),
+			PayloadSize:      v.GetUint32("payload-size"),
+			MaxConcurrent:    v.GetUint32("max-concurrent"),
+			RateLimit:        v.GetFloat64("rate-limit"),
+			RateStep:         v.GetFloat64("rate-step"),
+			RateStepInterval: durationpb.New(v.GetDuration("rate-step-interval")),
+			LoadMode:         v.GetString("load-mode"),
+			TargetRate:       v.GetFloat64("target-rate"),
+			LoadRamp:         v.GetBool("load-ramp"),
+
+			PayloadDistribution: v.GetString("payload-distribution"),
+			PayloadMin:          v.GetUint32("payload-min"),
+			PayloadMax:          v.GetUint32("payload-max"),
+			PayloadZipfS:        v.GetFloat64("payload-zipf-s"),
+			PayloadSeed:         v.GetInt64("payload-seed"),
+			CompressiblePayload: v.GetBool("compressible-payload"),
+
+			RequestTimeout:    durationpb.New(v.GetDuration("request-timeout")),
+			MaxRequestTimeout: durationpb.New(v.GetDuration("max-request-timeout")),
+			MaxRetries:        v.GetUint32("max-retries"),
+
+			Sessions: v.GetUint32("client-sessions"),
+
+			TargetLatency: durationpb.New(v.GetDuration("target-latency")),
+			MinConcurrent: v.GetUint32("min-concurrent"),
+			AckMode:       v.GetString("ack-mode"),
+			TraceSpeed:    v.GetFloat64("trace-speed"),
+			TraceLoop:     v.GetBool("trace-loop"),
+			DrainTimeout:  durationpb.New(v.GetDuration("client-drain-timeout")),
+			BatchSize:     v.GetUint32("client-batch-size"),
+			BatchTimeout:  durationpb.New(v.GetDuration("client-batch-timeout")),
+
+			Workload:             v.GetString("workload"),
+			KVKeys:               v.GetUint32("kv-keys"),
+			KVReadRatio:          v.GetFloat64("kv-read-ratio"),
+			KVDeleteRatio:        v.GetFloat64("kv-delete-ratio"),
+			KVCheckpointInterval: v.GetUint32("kv-checkpoint-interval"),
+		},
+	}
+
+	// the commands of the kv workload are executed by a key-value store in each replica,
+	// whose state hashes are compared at the end of the experiment.
+	if v.GetString("workload") == string(client.KVWorkload) {
+		experiment.ReplicaOpts.Application = "kvstore"
+	}
+
+	if experiment.Byzantine, err = parseByzantine(v); err != nil {
+		return nil, err
+	}
+	if experiment.ByzantineReplicas, err = parseByzantineReplicas(v); err != nil {
+		return nil, err
+	}
+	if experiment.Faults, err = parseFaults(v); err != nil {
+		return nil, err
+	}
+	if experiment.ReplicaOverrides, err = parseReplicaOverrides(v); err != nil {
+		return nil, err
+	}
+
+	if experiment.ReplicaResources, experiment.ClientResources, err = parseResources(v); err != nil {
+		return nil, err
+	}
+
+	if err := setArtifacts(v, experiment); err != nil {
+		return nil, err
+	}
+
+	if path := v.GetString("latency-matrix"); path != "" {
+		if experiment.LatencyMatrix, err = parseLatencyMatrix(path); err != nil {
+			return nil, fmt.Errorf("failed to read latency matrix: %w", err)
+		}
+	}
+
+	if experiment.Emulation, err = parseEmulation(v); err != nil {
+		return nil, err
+	}
+
+	if spec := v.GetString("inbound-rate-limits"); spec != "" {
+		if experiment.ReplicaOpts.InboundRateLimits, err = parseInboundRateLimits(spec); err != nil {
+			return nil, fmt.Errorf("invalid inbound rate limits: %w", err)
+		}
+	}
+
+	experiment.ReplicaOpts.CommitStreamListenAddress = v.GetString("commit-stream-listen")
+	experiment.ReplicaOpts.CommitStreamBuffer = v.GetUint32("commit-stream-buffer")
+	experiment.ReplicaOpts.Shards = v.GetUint32("shards")
+
+	if spec := v.GetString("client-weights"); spec != "" {
+		if experiment.ReplicaOpts.ClientWeights, err = parseClientWeights(spec); err != nil {
+			return nil, fmt.Errorf("invalid client weights: %w", err)
+		}
+	}
+
+	if profile := v.GetString("load-profile"); profile != "" {
+		if experiment.ClientOpts.LoadProfile, err = parseLoadProfile(profile); err != nil {
+			return nil, fmt.Errorf("invalid load profile: %w", err)
+		}
+	}
+
+	if path := v.GetString("workload-trace"); path != "" {
+		if experiment.Trace, err = readTrace(path); err != nil {
+			return nil, fmt.Errorf("failed to read trace: %w", err)
+		}
+	}
+
+	var hostConfigs []hostConfig
+	if err := v.UnmarshalKey("hosts-config", &hostConfigs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal hosts-config: %w", err)
+	}
+	experiment.HostConfigs = make(map[string]orchestration.HostConfig)
+	for _, cfg := range hostConfigs {
+		experiment.HostConfigs[hostName(cfg.Name)] = orchestration.HostConfig{Replicas: cfg.Replicas, Clients: cfg.Clients}
+	}
+
+	experiment.Hosts = make(map[string]orchestration.RemoteWorker)
+	hosts, local := experimentHosts(v)
+	for _, host := range hosts {
+		experiment.Hosts[host] = orchestration.RemoteWorker{}
+	}
+	if local {
+		if _, ok := experiment.Hosts[localHost]; ok {
+			return nil, fmt.Errorf("the remote host '%s' cannot be used along with a local worker", localHost)
+		}
+		experiment.Hosts[localHost] = orchestration.RemoteWorker{}
+	}
+
+	if experiment.Config, err = resolveConfig(v, flags); err != nil {
+		return nil, err
+	}
+	return experiment, experiment.Validate()
+}
+
+// parseReplicaOverrides reads the replica-overrides list of the experiment file.
+func parseReplicaOverrides(v *viper.Viper) (map[hotstuff.ID]*orchestrationpb.ReplicaOpts, error) {
+	var list []replicaOverrides
+	if err := v.UnmarshalKey("replica-overrides", &list); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal replica-overrides: %w", err)
+	}
+	overrides := make(map[hotstuff.ID]*orchestrationpb.ReplicaOpts)
+	for _, o := range list {
+		if len(o.IDs) == 0 {
+			return nil, fmt.Errorf("replica-overrides must list the IDs of the replicas that they apply to")
+		}
+		opts := &orchestrationpb.ReplicaOpts{
+			Consensus:         o.Consensus,
+			Crypto:            o.Crypto,
+			LeaderRotation:    o.LeaderRotation,
+			CryptoCacheSize:   o.CryptoCacheSize,
+			BatchSize:         o.BatchSize,
+			TimeoutMultiplier: o.TimeoutMultiplier,
+		}
+		if o.ViewTimeout != "" {
+			d, err := time.ParseDuration(o.ViewTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid view-timeout in replica-overrides: %w", err)
+			}
+			opts.InitialTimeout = durationpb.New(d)
+		}
+		if o.MaxTimeout != "" {
+			d, err := time.ParseDuration(o.MaxTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid max-timeout in replica-overrides: %w", err)
+			}
+			opts.MaxTimeout = durationpb.New(d)
+		}
+		for _, id := range o.IDs {
+			if _, ok := overrides[hotstuff.ID(id)]; ok {
+				return nil, fmt.Errorf("replica %d is listed more than once in replica-overrides", id)
+			}
+			overrides[hotstuff.ID(id)] = opts
+		}
+	}
+	return overrides, nil
+}
+
+// parseResources reads the resources list of the experiment file, and returns the resource limits of the replicas
+// and clients.
+func parseResources(v *viper.Viper) (replicas, clients map[hotstuff.ID]*orchestrationpb.Resources, err error) {
+	var list []resourceLimits
+	if err := v.UnmarshalKey("resources", &list); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal resources: %w", err)
+	}
+	replicas = make(map[hotstuff.ID]*orchestrationpb.Resources)
+	clients = make(map[hotstuff.ID]*orchestrationpb.Resources)
+	for _, limits := range list {
+		if len(limits.Replicas) == 0 && len(limits.Clients) == 0 {
+			return nil, nil, fmt.Errorf("resources must list the IDs of the replicas or clients that they apply to")
+		}
+		cpus, err := parseCPUList(limits.CPUs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid cpus in resources: %w", err)
+		}
+		if len(cpus) == 0 && limits.MaxProcs == 0 {
+			return nil, nil, fmt.Errorf("resources must give the cpus or max-procs of the replicas or clients")
+		}
+		res := &orchestrationpb.Resources{CPUs: cpus, MaxProcs: limits.MaxProcs}
+		for _, id := range limits.Replicas {
+			if _, ok := replicas[hotstuff.ID(id)]; ok {
+				return nil, nil, fmt.Errorf("replica %d is listed more than once in resources", id)
+			}
+			replicas[hotstuff.ID(id)] = res
+		}
+		for _, id := range limits.Clients {
+			if _, ok := clients[hotstuff.ID(id)]; ok {
+				return nil, nil, fmt.Errorf("client %d is listed more than once in resources", id)
+			}
+			clients[hotstuff.ID(id)] = res
+		}
+	}
+	return replicas, clients, nil
+}
+
+// parseCPUList parses a list of CPUs and ranges of CPUs, such as "0-3,6", in the format of the cpuset of Linux.
+func parseCPUList(list string) ([]uint32, error) {
+	var cpus []uint32
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	for _, part := range strings.Split(list, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		first, err := strconv.ParseUint(bounds[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU '%s'", part)
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.ParseUint(bounds[1], 10, 32); err != nil || last < first {
+				return nil, fmt.Errorf("invalid range of CPUs '%s'", part)
+			}
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			cpus = append(cpus, uint32(cpu))
+		}
+	}
+	return cpus, nil
+}
+
+// parseEmulation reads the emulation section of the experiment file. It returns nil if the section is missing.
+func parseEmulation(v *viper.Viper) (*orchestration.NetworkEmulation, error) {
+	if !v.IsSet("emulation") {
+		return nil, nil
+	}
+	var cfg emulationConfig
+	if err := v.UnmarshalKey("emulation", &cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal emulation: %w", err)
+	}
+	var jitter time.Duration
+	if cfg.Jitter != "" {
+		var err error
+		if jitter, err = time.ParseDuration(cfg.Jitter); err != nil {
+			return nil, fmt.Errorf("invalid jitter in emulation: %w", err)
+		}
+	}
+	emulation := &orchestration.NetworkEmulation{
+		Interface: cfg.Interface,
+		Links:     make(map[string]map[string]orchestration.EmulatedLink),
+	}
+	setLink := func(from, to, latency string) error {
+		d, err := time.ParseDuration(latency)
+		if err != nil {
+			return fmt.Errorf("invalid latency from '%s' to '%s' in emulation: %w", from, to, err)
+		}
+		if emulation.Links[from] == nil {
+			emulation.Links[from] = make(map[string]orchestration.EmulatedLink)
+		}
+		emulation.Links[from][to] = orchestration.EmulatedLink{Latency: d, Jitter: jitter, Loss: cfg.Loss}
+		return nil
+	}
+	for from, fromRegion := range cfg.Regions {
+		for to, toRegion := range cfg.Regions {
+			if from == to {
+				continue
+			}
+			latency, ok := cfg.RegionLatencies[fromRegion][toRegion]
+			if !ok {
+				latency, ok = cfg.RegionLatencies[toRegion][fromRegion]
+			}
+			if !ok {
+				if fromRegion != toRegion {
+					return nil, fmt.Errorf("no latency between the regions '%s' and '%s' in emulation", fromRegion, toRegion)
+				}
+				// the hosts of a region are only affected by the jitter and loss.
+				latency = "0s"
+			}
+			if err := setLink(from, to, latency); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for from, row := range cfg.Latencies {
+		for to, latency := range row {
+			if err := setLink(from, to, latency); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(emulation.Links) == 0 {
+		return nil, fmt.Errorf("the emulation must give the latencies or the regions of the hosts")
+	}
+	return emulation, nil
+}
+
+// setArtifacts configures the collection of artifacts. The artifact directory is replaced by the absolute path of the
+// output directory of the run before the experiment is run.
+func setArtifacts(v *viper.Viper, experiment *orchestration.Experiment) error {
+	if v.GetBool("collect-artifacts") {
+		if v.GetString("output") == "" {
+			return fmt.Errorf("collecting artifacts requires an output directory")
+		}
+		experiment.ArtifactDir = v.GetString("output")
+	}
+	experiment.MaxArtifactSize = v.GetUint64("max-artifact-size")
+	for _, profile := range v.GetStringSlice("artifact-profiles") {
+		switch profile {
+		case "cpu":
+			if v.GetBool("cpu-profile") {
+				return fmt.Errorf("the cpu artifact profile cannot be used with cpu-profile")
+			}
+			experiment.CPUProfile = true
+		case "heap":
+			experiment.HeapProfile = true
+		default:
+			return fmt.Errorf("unknown artifact profile '%s': valid profiles are cpu and heap", profile)
+		}
+	}
+	return nil
+}
+
+// resolveConfig returns the configuration of the experiment as a YAML experiment file,
+// which contains the value of every flag, taken from the flag if it is set, and otherwise from the experiment file
+// or the default value of the flag. The values are converted to the types of the flags,
+// such that the file resolves to the same configuration when it is read again.
+func resolveConfig(v *viper.Viper, flags *pflag.FlagSet) (string, error) {
+	config := make(map[string]interface{})
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil {
+			return
+		}
+		if config[flag.Name], err = resolveValue(v.Get(flag.Name), flag.Value.Type()); err != nil {
+			err = fmt.Errorf("invalid value of '%s': %w", flag.Name, err)
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	var hostConfigs []hostConfig
+	if err := v.UnmarshalKey("hosts-config", &hostConfigs); err != nil {
+		return "", fmt.Errorf("failed to unmarshal hosts-config: %w", err)
+	}
+	if len(hostConfigs) > 0 {
+		sort.Slice(hostConfigs, func(i, j int) bool { return hostConfigs[i].Name < hostConfigs[j].Name })
+		config["hosts-config"] = hostConfigs
+	}
+	var overrides []replicaOverrides
+	if err := v.UnmarshalKey("replica-overrides", &overrides); err != nil {
+		return "", fmt.Errorf("failed to unmarshal replica-overrides: %w", err)
+	}
+	if len(overrides) > 0 {
+		config["replica-overrides"] = overrides
+	}
+	var resources []resourceLimits
+	if err := v.UnmarshalKey("resources", &resources); err != nil {
+		return "", fmt.Errorf("failed to unmarshal resources: %w", err)
+	}
+	if len(resources) > 0 {
+		config["resources"] = resources
+	}
+	if v.IsSet("emulation") {
+		var emulation emulationConfig
+		if err := v.UnmarshalKey("emulation", &emulation); err != nil {
+			return "", fmt.Errorf("failed to unmarshal emulation: %w", err)
+		}
+		config["emulation"] = emulation
+	}
+	b, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// resolveValue converts the value of a flag to the type of the flag.
+// Durations are converted to strings in Go's duration format, such that they are readable.
+func resolveValue(value interface{}, typ string) (interface{}, error) {
+	switch typ {
+	case "int", "int64":
+		return cast.ToInt64E(value)
+	case "uint32", "uint64":
+		return cast.ToUint64E(value)
+	case "float32", "float64":
+		return cast.ToFloat64E(value)
+	case "bool":
+		return cast.ToBoolE(value)
+	case "duration":
+		d, err := cast.ToDurationE(value)
+		return d.String(), err
+	case "stringSlice":
+		return cast.ToStringSliceE(value)
+	default:
+		return cast.ToStringE(value)
+	}
+}
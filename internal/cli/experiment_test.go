@@ -0,0 +1,353 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/internal/orchestration"
+	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/proto"
+)
+
+// readExperiment writes an experiment file with the given name and contents, and returns the experiment that it
+// configures with the flags given by args.
+func readExperiment(t *testing.T, name, contents string, args ...string) (*orchestration.Experiment, error) {
+	t.Helper()
+	v, flags, err := readConfig(t, name, contents, args...)
+	if err != nil {
+		return nil, err
+	}
+	return newExperiment(v, flags)
+}
+
+// readConfig writes an experiment file with the given name and contents, and reads it along with the flags given by args.
+func readConfig(t *testing.T, name, contents string, args ...string) (*viper.Viper, *pflag.FlagSet, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	flags := pflag.NewFlagSet("run", pflag.ContinueOnError)
+	addRunFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	v := viper.New()
+	if err := v.BindPFlags(flags); err != nil {
+		t.Fatal(err)
+	}
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, nil, err
+	}
+	return v, flags, nil
+}
+
+const tomlExperiment = `
+replicas = 7
+clients = 2
+hosts = ["worker1", "worker2"]
+duration = "30s"
+consensus = "fasthotstuff"
+crypto = "bls12"
+leader-rotation = "carousel"
+batch-size = 100
+view-timeout = "500ms"
+max-concurrent = 50
+rate-limit = 1000.5
+byzantine = ["silence:1"]
+byzantine-replicas = ["3:fork"]
+output = "results"
+
+[[hosts-config]]
+name = "worker1"
+clients = 2
+replicas = 0
+
+[[replica-overrides]]
+ids = [2, 3]
+consensus = "chainedhotstuff"
+crypto-cache-size = 0
+view-timeout = "1s"
+`
+
+const yamlExperiment = `
+replicas: 7
+clients: 2
+hosts: [worker1, worker2]
+duration: 30s
+consensus: fasthotstuff
+crypto: bls12
+leader-rotation: carousel
+batch-size: 100
+view-timeout: 500ms
+max-concurrent: 50
+rate-limit: 1000.5
+byzantine: ["silence:1"]
+byzantine-replicas: ["3:fork"]
+output: results
+hosts-config:
+- name: worker1
+  clients: 2
+  replicas: 0
+replica-overrides:
+- ids: [2, 3]
+  consensus: chainedhotstuff
+  crypto-cache-size: 0
+  view-timeout: 1s
+`
+
+func TestExperimentFile(t *testing.T) {
+	fromTOML, err := readExperiment(t, "experiment.toml", tomlExperiment, "--batch-size=400")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromTOML.NumReplicas != 7 || fromTOML.NumClients != 2 || fromTOML.Duration != 30*time.Second {
+		t.Errorf("got %d replicas and %d clients for %v, want 7 replicas and 2 clients for 30s",
+			fromTOML.NumReplicas, fromTOML.NumClients, fromTOML.Duration)
+	}
+	if opts := fromTOML.ReplicaOpts; opts.GetConsensus() != "fasthotstuff" || opts.GetCrypto() != "bls12" ||
+		opts.GetLeaderRotation() != "carousel" || opts.GetInitialTimeout().AsDuration() != 500*time.Millisecond {
+		t.Errorf("the replica options differ from the experiment file: %v", opts)
+	}
+	if got := fromTOML.ReplicaOpts.GetBatchSize(); got != 400 {
+		t.Errorf("got batch size %d, want 400 from the flag that overrides the experiment file", got)
+	}
+	if opts := fromTOML.ClientOpts; opts.GetMaxConcurrent() != 50 || opts.GetRateLimit() != 1000.5 {
+		t.Errorf("the client options differ from the experiment file: %v", opts)
+	}
+	if !reflect.DeepEqual(fromTOML.Byzantine, map[string]int{"silence": 1}) ||
+		!reflect.DeepEqual(fromTOML.ByzantineReplicas, map[hotstuff.ID]string{3: "fork"}) {
+		t.Errorf("the byzantine assignments differ from the experiment file: %v, %v", fromTOML.Byzantine, fromTOML.ByzantineReplicas)
+	}
+	if !reflect.DeepEqual(fromTOML.HostConfigs, map[string]orchestration.HostConfig{"worker1": {Clients: 2}}) {
+		t.Errorf("the host configurations differ from the experiment file: %v", fromTOML.HostConfigs)
+	}
+	for _, id := range []hotstuff.ID{2, 3} {
+		opts := fromTOML.ReplicaOverrides[id]
+		if opts.GetConsensus() != "chainedhotstuff" || opts.CryptoCacheSize == nil || opts.GetCryptoCacheSize() != 0 ||
+			opts.GetInitialTimeout().AsDuration() != time.Second || opts.GetCrypto() != "" {
+			t.Errorf("the overrides of replica %d differ from the experiment file: %v", id, opts)
+		}
+	}
+	if len(fromTOML.ReplicaOverrides) != 2 {
+		t.Errorf("got overrides for %d replicas, want 2", len(fromTOML.ReplicaOverrides))
+	}
+	if _, ok := fromTOML.Hosts["localhost"]; len(fromTOML.Hosts) != 2 || ok {
+		t.Errorf("expected the experiment to run on worker1 and worker2 only, got: %v", fromTOML.Hosts)
+	}
+	for _, line := range []string{"batch-size: 400", "output: results", "duration: 30s", "- name: worker1", "  crypto-cache-size: 0"} {
+		if !strings.Contains(fromTOML.Config, line+"\n") {
+			t.Errorf("the resolved configuration does not contain '%s':\n%s", line, fromTOML.Config)
+		}
+	}
+
+	// the same experiment in another format resolves to the same configuration.
+	fromYAML, err := readExperiment(t, "experiment.yaml", yamlExperiment, "--batch-size=400")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromYAML.Config != fromTOML.Config {
+		t.Errorf("the YAML and TOML files resolve to different configurations:\n%s\n%s", fromYAML.Config, fromTOML.Config)
+	}
+}
+
+func TestResolvedConfigRoundTrip(t *testing.T) {
+	want, err := readExperiment(t, "experiment.toml", tomlExperiment, "--batch-size=400", "--view-timeout=1s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readExperiment(t, "resolved.yaml", want.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Config != want.Config {
+		t.Errorf("the resolved configuration changed when it was read again:\n%s\n%s", got.Config, want.Config)
+	}
+	if !proto.Equal(got.ReplicaOpts, want.ReplicaOpts) || !proto.Equal(got.ClientOpts, want.ClientOpts) {
+		t.Error("the options changed when the resolved configuration was read again")
+	}
+	if got.NumReplicas != want.NumReplicas || got.NumClients != want.NumClients || got.Duration != want.Duration ||
+		!reflect.DeepEqual(got.Hosts, want.Hosts) || !reflect.DeepEqual(got.HostConfigs, want.HostConfigs) ||
+		!reflect.DeepEqual(got.Byzantine, want.Byzantine) || !reflect.DeepEqual(got.ByzantineReplicas, want.ByzantineReplicas) {
+		t.Error("the experiment changed when the resolved configuration was read again")
+	}
+	if len(got.ReplicaOverrides) != len(want.ReplicaOverrides) {
+		t.Errorf("got overrides for %d replicas, want %d", len(got.ReplicaOverrides), len(want.ReplicaOverrides))
+	}
+	for id, opts := range want.ReplicaOverrides {
+		if !proto.Equal(got.ReplicaOverrides[id], opts) {
+			t.Errorf("the overrides of replica %d changed when the resolved configuration was read again", id)
+		}
+	}
+}
+
+func TestLocalHost(t *testing.T) {
+	experiment, err := readExperiment(t, "experiment.yaml", `
+replicas: 4
+clients: 2
+hosts: [local, worker1]
+hosts-config:
+- {name: local, clients: 2}
+- {name: worker1, replicas: 4}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHosts := map[string]orchestration.RemoteWorker{localHost: {}, "worker1": {}}
+	if !reflect.DeepEqual(experiment.Hosts, wantHosts) {
+		t.Errorf("got hosts %v, want %v", experiment.Hosts, wantHosts)
+	}
+	wantConfigs := map[string]orchestration.HostConfig{localHost: {Clients: 2}, "worker1": {Replicas: 4}}
+	if !reflect.DeepEqual(experiment.HostConfigs, wantConfigs) {
+		t.Errorf("got host configurations %v, want %v", experiment.HostConfigs, wantConfigs)
+	}
+}
+
+const emulationExperiment = `
+hosts: [eu1.example.com, eu2.example.com, us1.example.com]
+emulation:
+  jitter: 1ms
+  loss: 0.5
+  regions:
+    eu1.example.com: eu
+    eu2.example.com: eu
+    us1.example.com: us
+  region-latencies:
+    eu: {us: 40ms}
+  latencies:
+    us1.example.com: {eu2.example.com: 45ms}
+`
+
+func TestEmulation(t *testing.T) {
+	experiment, err := readExperiment(t, "experiment.yaml", emulationExperiment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := func(latency time.Duration) orchestration.EmulatedLink {
+		return orchestration.EmulatedLink{Latency: latency, Jitter: time.Millisecond, Loss: 0.5}
+	}
+	want := map[string]map[string]orchestration.EmulatedLink{
+		"eu1.example.com": {"eu2.example.com": link(0), "us1.example.com": link(40 * time.Millisecond)},
+		"eu2.example.com": {"eu1.example.com": link(0), "us1.example.com": link(40 * time.Millisecond)},
+		"us1.example.com": {"eu1.example.com": link(40 * time.Millisecond), "eu2.example.com": link(45 * time.Millisecond)},
+	}
+	if experiment.Emulation == nil || !reflect.DeepEqual(experiment.Emulation.Links, want) {
+		t.Errorf("got emulation %+v, want links %v", experiment.Emulation, want)
+	}
+
+	resolved, err := readExperiment(t, "resolved.yaml", experiment.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resolved.Emulation, experiment.Emulation) {
+		t.Error("the emulation changed when the resolved configuration was read again")
+	}
+}
+
+const resourcesExperiment = `
+replicas: 4
+clients: 2
+client-sessions: 2
+resources:
+- {replicas: [1, 2], cpus: "0-2,5", max-procs: 2}
+- {clients: [3], max-procs: 1}
+`
+
+func TestResources(t *testing.T) {
+	experiment, err := readExperiment(t, "experiment.yaml", resourcesExperiment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replica := &orchestrationpb.Resources{CPUs: []uint32{0, 1, 2, 5}, MaxProcs: 2}
+	if len(experiment.ReplicaResources) != 2 {
+		t.Errorf("got the resources of %d replicas, want 2", len(experiment.ReplicaResources))
+	}
+	for _, id := range []hotstuff.ID{1, 2} {
+		if !proto.Equal(experiment.ReplicaResources[id], replica) {
+			t.Errorf("replica %d: got resources %v, want %v", id, experiment.ReplicaResources[id], replica)
+		}
+	}
+	client := &orchestrationpb.Resources{MaxProcs: 1}
+	if len(experiment.ClientResources) != 1 || !proto.Equal(experiment.ClientResources[3], client) {
+		t.Errorf("got client resources %v, want %v for client 3", experiment.ClientResources, client)
+	}
+
+	resolved, err := readExperiment(t, "resolved.yaml", experiment.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(resolved.ReplicaResources[1], replica) || !proto.Equal(resolved.ClientResources[3], client) {
+		t.Error("the resources changed when the resolved configuration was read again")
+	}
+}
+
+func TestShards(t *testing.T) {
+	experiment, err := readExperiment(t, "experiment.yaml", "shards: 2\nfaults: [\"3.1:pause@1s+1s\"]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := experiment.ReplicaOpts.GetShards(); got != 2 {
+		t.Errorf("got %d shards, want 2", got)
+	}
+	if faults := experiment.Faults[3]; len(faults) != 1 || faults[0].GetShard() != 1 || faults[0].GetAction() != "pause" {
+		t.Errorf("got the faults %v of replica 3, want a pause of shard 1", faults)
+	}
+}
+
+func TestInvalidExperimentFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		args     []string
+	}{
+		{"UnknownConsensus", "consensus: nohotstuff\n", nil},
+		{"UnknownCrypto", "crypto: rot13\n", nil},
+		{"UnknownLeaderRotation", "leader-rotation: random\n", nil},
+		{"UnknownTransport", "transport: carrier-pigeon\n", nil},
+		{"UnknownByzantineStrategy", "byzantine: [\"lie:1\"]\n", nil},
+		{"NoReplicas", "replicas: 0\n", nil},
+		{"NegativeClients", "clients: -1\n", nil},
+		{"TooManyByzantineReplicas", "replicas: 4\nbyzantine: [\"silence:5\"]\n", nil},
+		{"HostConfigExceedsReplicas", "replicas: 4\nhosts: [a, b]\nhosts-config:\n- {name: a, replicas: 5}\n", nil},
+		{"HostConfigOfUnknownHost", "hosts: [a, b]\nhosts-config:\n- {name: c, replicas: 1}\n", nil},
+		{"AllHostsConfiguredInconsistently", "replicas: 4\nclients: 1\nhosts: [a]\nhosts-config:\n- {name: a, replicas: 3, clients: 1}\n", nil},
+		{"InvalidFault", "faults: [\"1:explode@1s\"]\n", nil},
+		{"CrashWithShards", "shards: 2\nfaults: [\"1:crash@1s\"]\n", nil},
+		{"PauseOfUnknownShard", "shards: 2\nfaults: [\"1.2:pause@1s+1s\"]\n", nil},
+		{"OverrideWithoutIDs", "replica-overrides:\n- {consensus: fasthotstuff}\n", nil},
+		{"OverrideOfUnknownReplica", "replicas: 4\nreplica-overrides:\n- {ids: [5], consensus: fasthotstuff}\n", nil},
+		{"OverrideWithUnknownConsensus", "replica-overrides:\n- {ids: [1], consensus: nohotstuff}\n", nil},
+		{"ReplicaOverriddenTwice", "replica-overrides:\n- {ids: [1], batch-size: 2}\n- {ids: [1], batch-size: 3}\n", nil},
+		{"StallTimeoutWithoutProgress", "stall-timeout: 1s\nprogress-interval: 0s\n", nil},
+		{"NegativeStopTimeout", "stop-timeout: -1s\n", nil},
+		{"InvalidInboundRateLimit", "inbound-rate-limits: ballot=10:5\n", nil},
+		{"InvalidClientWeight", "client-weights: \"1=0\"\n", nil},
+		{"ResourcesWithoutIDs", "resources:\n- {max-procs: 1}\n", nil},
+		{"ResourcesWithoutLimits", "resources:\n- {replicas: [1]}\n", nil},
+		{"ResourcesOfUnknownReplica", "replicas: 4\nresources:\n- {replicas: [5], max-procs: 1}\n", nil},
+		{"ResourcesOfUnknownClient", "clients: 2\nclient-sessions: 2\nresources:\n- {clients: [2], max-procs: 1}\n", nil},
+		{"InvalidCPURange", "resources:\n- {replicas: [1], cpus: \"3-1\"}\n", nil},
+		{"RemoteHostNamedLikeLocalWorker", "hosts: [local, localhost]\n", nil},
+		{"EmulationOfUnknownHost", "hosts: [a, b]\nemulation:\n  latencies: {a: {c: 10ms}}\n", nil},
+		{"EmulationWithoutRegionLatency", "hosts: [a, b]\nemulation:\n  regions: {a: eu, b: us}\n", nil},
+		{"EmulationWithInvalidLoss", "hosts: [a, b]\nemulation:\n  loss: 101\n  latencies: {a: {b: 10ms}}\n", nil},
+		{"InvalidValue", "replicas: many\n", nil},
+		{"Syntax", "replicas: [4\n", nil},
+		{"InvalidOverride", "consensus: chainedhotstuff\n", []string{"--consensus=nohotstuff"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := readExperiment(t, "experiment.yaml", test.contents, test.args...); err == nil {
+				t.Error("expected the experiment file to be rejected")
+			}
+		})
+	}
+}
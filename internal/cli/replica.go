@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/internal/orchestration"
+	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
+	"github.com/relab/hotstuff/modules"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// replicaCmd represents the replica command
+var replicaCmd = &cobra.Command{
+	Use:   "replica",
+	Short: "Run a single replica.",
+	Long: `The replica command runs a single replica without a controller, until it is interrupted or terminated.
+The replica is configured with flags or a config file (--config), which must specify the ID of the replica,
+its private key, and the peers (including the replica itself) with their addresses and public keys.
+In a config file, the peers are given as a list:
+
+  [[peers]]
+  id = 1
+  address = "10.0.0.1:30000"
+  public-key = "keys/1.pub"
+
+With flags, each peer is given as '--peer id=address=public-key-file'.
+The address of a peer may be a unix domain socket prefixed with 'unix://'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// the flags are bound when the command runs, since the run command binds flags with the same names.
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return runReplica()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replicaCmd)
+
+	replicaCmd.Flags().Uint32("id", 0, "the ID of the replica")
+	replicaCmd.Flags().String("private-key", "", "path to the private key of the replica")
+	replicaCmd.Flags().StringArray("peer", nil, "a peer given as 'id=address=public-key-file' (repeat for each replica, including this one)")
+	replicaCmd.Flags().String("replica-listen", "", "the address to listen on for other replicas (a random port if empty)")
+	replicaCmd.Flags().String("client-listen", "", "the address to listen on for clients (a random port if empty)")
+	replicaCmd.Flags().String("app-address", "", "the address of an external application that executes the commands (the commands are only hashed if empty)")
+	replicaCmd.Flags().String("status-listen", "", "the address that the HTTP status server listens on (disabled if empty)")
+	replicaCmd.Flags().String("commit-stream-listen", "", "the address that the commit stream server listens on (disabled if empty)")
+	replicaCmd.Flags().Uint32("commit-stream-buffer", 1024, "the number of committed blocks buffered for each subscriber of the commit stream")
+	replicaCmd.Flags().String("tls-cert", "", "path to the TLS certificate of the replica (TLS is disabled if empty)")
+	replicaCmd.Flags().String("tls-key", "", "path to the private key of the TLS certificate")
+	replicaCmd.Flags().String("tls-ca", "", "path to the certificate of the authority that issued the TLS certificates")
+
+	replicaCmd.Flags().String("consensus", "chainedhotstuff", "name of the consensus implementation")
+	replicaCmd.Flags().String("crypto", "ecdsa", "name of the crypto implementation")
+	replicaCmd.Flags().String("leader-rotation", "round-robin", "name of the leader rotation algorithm")
+	replicaCmd.Flags().String("byzantine", "", "name of the byzantine strategy to use (the replica is correct if empty)")
+	replicaCmd.Flags().Int("batch-size", 1, "number of commands to batch together in each block")
+	replicaCmd.Flags().Int("batch-bytes", 0, "maximum number of bytes of the commands batched together in each block (unbounded if zero)")
+	replicaCmd.Flags().Duration("batch-delay", 0, "how long a command waits for its batch to fill up before a smaller batch is proposed (until it is full if zero)")
+	replicaCmd.Flags().String("batch-policy", "fifo", "the order in which the commands of different clients are batched: fifo, or fair to let the clients take turns")
+	replicaCmd.Flags().String("client-weights", "", "the weights of the clients under the fair batch policy as 'client=weight', such as '1=3,2=1' (1 if not given)")
+	replicaCmd.Flags().Int("max-block-bytes", 0, "maximum size in bytes of a serialized proposal (the maximum message size of the replica server if zero)")
+	replicaCmd.Flags().Int("execution-lag", 0, "number of committed blocks that may wait to be executed asynchronously (executed as they are committed if zero)")
+	replicaCmd.Flags().Duration("connect-timeout", 5*time.Second, "duration of the initial connection timeout")
+	replicaCmd.Flags().Duration("view-timeout", 100*time.Millisecond, "duration of the first view")
+	replicaCmd.Flags().Duration("max-timeout", 0, "upper limit on view timeouts")
+	replicaCmd.Flags().Int("duration-samples", 1000, "number of previous views to consider when predicting view duration")
+	replicaCmd.Flags().Float32("timeout-multiplier", 1.2, "number to multiply the view duration by in case of a timeout")
+	replicaCmd.Flags().Int64("shared-seed", 0, "shared random number generator seed (must be the same for all replicas)")
+	replicaCmd.Flags().Bool("compress-proposals", false, "compress the commands of proposals before sending them")
+	replicaCmd.Flags().String("transport", "tcp", "name of the transport used for replica-to-replica communication (tcp or quic)")
+	replicaCmd.Flags().Bool("forward-commands", false, "forward client commands to the leader instead of buffering them until the replica leads")
+	replicaCmd.Flags().Int("command-cache-size", 0, "maximum number of commands waiting to be proposed (unbounded if zero)")
+	replicaCmd.Flags().Int("command-cache-bytes", 0, "maximum number of bytes of the commands waiting to be proposed (unbounded if zero)")
+	replicaCmd.Flags().Bool("evict-commands", false, "evict the oldest commands from a full command cache instead of rejecting new commands")
+	replicaCmd.Flags().Uint32("event-queue-capacity", 1000, "number of events that each queue of the event loop can hold")
+	replicaCmd.Flags().Bool("fatal-panics", false, "crash on a panic in an event handler instead of recovering from it (for debugging)")
+	replicaCmd.Flags().Int("snapshot-interval", 0, "number of views between the snapshots transferred to replicas that fall far behind (state transfer is disabled if zero)")
+	replicaCmd.Flags().String("data-dir", "", "directory for durable snapshots of the replica's state, from which it recovers when restarted (disabled if empty)")
+	replicaCmd.Flags().Uint32("durable-snapshot-views", 100, "number of views between durable snapshots (disabled if zero)")
+	replicaCmd.Flags().Uint64("durable-snapshot-bytes", 0, "number of bytes of executed commands between durable snapshots (disabled if zero)")
+	replicaCmd.Flags().Bool("persist-commands", false, "persist the commands admitted from clients in the data directory, and reload them when restarted")
+	replicaCmd.Flags().String("command-log-sync", "batched", "when the persisted commands are synced: command, batched, or off")
+	replicaCmd.Flags().String("application", "", "the application that executes the commands: 'kvstore' (the commands are only hashed if empty)")
+	replicaCmd.Flags().Duration("replica-drain-timeout", time.Second, "how long the replica processes its queued events, and waits for the RPCs in progress when it shuts down")
+
+	replicaCmd.Flags().String("data-path", "", "path to store the measurements (disabled if empty)")
+	replicaCmd.Flags().StringSlice("metrics", nil, "list of metrics to enable")
+	replicaCmd.Flags().Duration("measurement-interval", 0, "time interval between measurements")
+}
+
+// runReplica runs a single replica until it receives a signal.
+func runReplica() error {
+	opts, peers, err := replicaConfig()
+	if err != nil {
+		return err
+	}
+
+	metricsLogger := modules.NopLogger()
+	if dataPath := viper.GetString("data-path"); dataPath != "" {
+		f, err := os.OpenFile(dataPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to create data path: %w", err)
+		}
+		defer func() { checkf("failed to close data file: %v", f.Close()) }()
+		writer := bufio.NewWriter(f)
+		defer func() { checkf("failed to flush writer: %v", writer.Flush()) }()
+		metricsLogger, err = modules.NewJSONLogger(writer)
+		if err != nil {
+			return fmt.Errorf("failed to create JSON logger: %w", err)
+		}
+		defer func() { checkf("failed to close metrics logger: %v", metricsLogger.Close()) }()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	r, err := orchestration.NewStandaloneReplica(
+		opts, peers, metricsLogger, viper.GetStringSlice("metrics"), viper.GetDuration("measurement-interval"),
+	)
+	if err != nil {
+		return err
+	}
+	if info := r.Info(); info.GetReplicaSocket() != "" {
+		log.Printf("Replica %d listening on %s (replicas) and %s (clients)", opts.GetID(), info.GetReplicaSocket(), info.GetClientSocket())
+	} else {
+		log.Printf("Replica %d listening on port %d (replicas) and %d (clients)", opts.GetID(), info.GetReplicaPort(), info.GetClientPort())
+	}
+	if port := r.Info().GetStatusPort(); port != 0 {
+		log.Printf("Replica %d serves its status on port %d", opts.GetID(), port)
+	}
+	if port := r.Info().GetCommitStreamPort(); port != 0 {
+		log.Printf("Replica %d streams its commits on port %d", opts.GetID(), port)
+	}
+	if err := r.Start(ctx); err != nil {
+		_, _ = r.Shutdown()
+		return fmt.Errorf("failed to start replica: %w", err)
+	}
+
+	<-ctx.Done()
+	log.Printf("Shutting down replica %d", opts.GetID())
+	hash, err := r.Shutdown()
+	if err != nil {
+		return err
+	}
+	log.Printf("Replica %d stopped with hash %.8x", opts.GetID(), hash)
+	return nil
+}
+
+// replicaConfig reads the options of the replica and its peers from the flags and the config file.
+func replicaConfig() (*orchestrationpb.ReplicaOpts, map[uint32]*orchestrationpb.ReplicaInfo, error) {
+	id := viper.GetUint32("id")
+	if id == 0 {
+		return nil, nil, fmt.Errorf("the ID of the replica must be set")
+	}
+	keyPath := viper.GetString("private-key")
+	if keyPath == "" {
+		return nil, nil, fmt.Errorf("the private key of the replica must be set")
+	}
+	privateKey, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	opts := &orchestrationpb.ReplicaOpts{
+		ID:                   id,
+		PrivateKey:           privateKey,
+		BatchSize:            viper.GetUint32("batch-size"),
+		BatchBytes:           viper.GetUint32("batch-bytes"),
+		BatchDelay:           durationpb.New(viper.GetDuration("batch-delay")),
+		MaxBlockBytes:        viper.GetUint32("max-block-bytes"),
+		ExecutionLag:         viper.GetUint32("execution-lag"),
+		TimeoutMultiplier:    float32(viper.GetFloat64("timeout-multiplier")),
+		Consensus:            viper.GetString("consensus"),
+		Crypto:               viper.GetString("crypto"),
+		LeaderRotation:       viper.GetString("leader-rotation"),
+		ByzantineStrategy:    viper.GetString("byzantine"),
+		ConnectTimeout:       durationpb.New(viper.GetDuration("connect-timeout")),
+		InitialTimeout:       durationpb.New(viper.GetDuration("view-timeout")),
+		TimeoutSamples:       viper.GetUint32("duration-samples"),
+		MaxTimeout:           durationpb.New(viper.GetDuration("max-timeout")),
+		SharedSeed:           viper.GetInt64("shared-seed"),
+		CompressProposals:    viper.GetBool("compress-proposals"),
+		Transport:            viper.GetString("transport"),
+		ReplicaListenAddress: viper.GetString("replica-listen"),
+		ClientListenAddress:  viper.GetString("client-listen"),
+		ForwardCommands:      viper.GetBool("forward-commands"),
+		CommandCacheSize:     viper.GetUint32("command-cache-size"),
+		CommandCacheBytes:    viper.GetUint64("command-cache-bytes"),
+		EvictCommands:        viper.GetBool("evict-commands"),
+		EventQueueCapacity:   viper.GetUint32("event-queue-capacity"),
+		FatalPanics:          viper.GetBool("fatal-panics"),
+		SnapshotInterval:     viper.GetUint32("snapshot-interval"),
+		StatusListenAddress:  viper.GetString("status-listen"),
+		ApplicationAddress:   viper.GetString("app-address"),
+		Application:          viper.GetString("application"),
+		DataDir:              viper.GetString("data-dir"),
+		DurableSnapshotViews: viper.GetUint32("durable-snapshot-views"),
+		DurableSnapshotBytes: viper.GetUint64("durable-snapshot-bytes"),
+		PersistCommands:      viper.GetBool("persist-commands"),
+		CommandLogSync:       viper.GetString("command-log-sync"),
+		BatchPolicy:          viper.GetString("batch-policy"),
+		DrainTimeout:         durationpb.New(viper.GetDuration("replica-drain-timeout")),
+	}
+
+	opts.CommitStreamListenAddress = viper.GetString("commit-stream-listen")
+	opts.CommitStreamBuffer = viper.GetUint32("commit-stream-buffer")
+
+	if spec := viper.GetString("client-weights"); spec != "" {
+		if opts.ClientWeights, err = parseClientWeights(spec); err != nil {
+			return nil, nil, fmt.Errorf("invalid client weights: %w", err)
+		}
+	}
+
+	if cert := viper.GetString("tls-cert"); cert != "" {
+		opts.UseTLS = true
+		files := []struct {
+			path string
+			dst  *[]byte
+		}{
+			{cert, &opts.Certificate},
+			{viper.GetString("tls-key"), &opts.CertificateKey},
+			{viper.GetString("tls-ca"), &opts.CertificateAuthority},
+		}
+		for _, file := range files {
+			if file.path == "" {
+				return nil, nil, fmt.Errorf("tls-key and tls-ca must be set when TLS is enabled")
+			}
+			if *file.dst, err = os.ReadFile(file.path); err != nil {
+				return nil, nil, fmt.Errorf("failed to read TLS file: %w", err)
+			}
+		}
+	}
+
+	peers, err := replicaPeers()
+	if err != nil {
+		return nil, nil, err
+	}
+	return opts, peers, nil
+}
+
+// peerConfig is the configuration of a peer in the config file.
+type peerConfig struct {
+	ID        uint32
+	Address   string
+	PublicKey string `mapstructure:"public-key"`
+}
+
+// replicaPeers reads the peers from the config file and from the peer flags.
+func replicaPeers() (map[uint32]*orchestrationpb.ReplicaInfo, error) {
+	var peerConfigs []peerConfig
+	if err := viper.UnmarshalKey("peers", &peerConfigs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal peers: %w", err)
+	}
+	for _, arg := range viper.GetStringSlice("peer") {
+		parts := strings.SplitN(arg, "=", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("peer must be specified as 'id=address=public-key-file': '%s'", arg)
+		}
+		id, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID of peer '%s': %w", arg, err)
+		}
+		peerConfigs = append(peerConfigs, peerConfig{ID: uint32(id), Address: parts[1], PublicKey: parts[2]})
+	}
+	if len(peerConfigs) == 0 {
+		return nil, fmt.Errorf("no peers are configured")
+	}
+
+	peers := make(map[uint32]*orchestrationpb.ReplicaInfo, len(peerConfigs))
+	for _, cfg := range peerConfigs {
+		if _, ok := peers[cfg.ID]; ok {
+			return nil, fmt.Errorf("peer %d is configured more than once", cfg.ID)
+		}
+		if cfg.PublicKey == "" {
+			return nil, fmt.Errorf("missing public key for peer %d", cfg.ID)
+		}
+		publicKey, err := os.ReadFile(cfg.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key of peer %d: %w", cfg.ID, err)
+		}
+		info := &orchestrationpb.ReplicaInfo{ID: cfg.ID, PublicKey: publicKey}
+		if strings.HasPrefix(cfg.Address, backend.UnixScheme) {
+			info.ReplicaSocket = cfg.Address
+		} else if cfg.Address != "" {
+			host, port, err := net.SplitHostPort(cfg.Address)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address of peer %d: %w", cfg.ID, err)
+			}
+			p, err := strconv.ParseUint(port, 10, 16)
+			if err != nil || p == 0 {
+				return nil, fmt.Errorf("invalid port in the address of peer %d: '%s'", cfg.ID, port)
+			}
+			info.Address = host
+			info.ReplicaPort = uint32(p)
+		}
+		peers[cfg.ID] = info
+	}
+	return peers, nil
+}
@@ -28,7 +28,8 @@ run the experiment, and fetch the experiment results.
 
 To run an experiment, use the 'hotstuff run' command.
 By default, this command will run a small configuration of replicas locally.
-use 'hotstuff help run' to view all possible parameters for this command.`,
+use 'hotstuff help run' to view all possible parameters for this command.
+To run a single replica without a controller, use the 'hotstuff replica' command.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if !listModules {
 				return cmd.Usage()
@@ -95,6 +96,10 @@ func initConfig() {
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	} else if cfgFile != "" {
+		// a config file that was asked for must not be ignored, since the experiment would differ from it.
+		fmt.Println("Failed to read config file:", err)
+		os.Exit(1)
 	}
 
 	logging.SetLogLevel(viper.GetString("log-level"))
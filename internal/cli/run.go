@@ -2,6 +2,8 @@ package cli
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -13,6 +15,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/client"
 	"github.com/relab/hotstuff/internal/orchestration"
 	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
 	"github.com/relab/hotstuff/internal/protostream"
@@ -20,7 +25,9 @@ import (
 	"github.com/relab/hotstuff/modules"
 	"github.com/relab/iago"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"go.uber.org/multierr"
 	"google.golang.org/protobuf/types/known/durationpb"
 )
 
@@ -35,48 +42,20 @@ This should at the very least specify the identity file to use.
 It is also required that the host keys for all remote machines are present in a 'known_hosts' file.
 Then, you must use the '--ssh-config' parameter to specify the location of your 'ssh_config' file
 (or omit it to use ~/.ssh/config). Then, you must specify the list of remote machines to connect to
-using the '--host' parameter. This should be a comma separated list of hostnames or ip addresses.`,
+using the '--host' parameter. This should be a comma separated list of hostnames or ip addresses.
+
+The parameters of an experiment can also be given in an experiment file with the '--config' parameter,
+in a format such as YAML or TOML, using the names of the flags as keys. Flags override the values of the file.
+The experiment is validated before the workers are deployed, and the resolved configuration is recorded
+in the start events of the measurements.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		runController()
+		runController(cmd.LocalFlags())
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(runCmd)
-
-	runCmd.Flags().Int("replicas", 4, "number of replicas to run")
-	runCmd.Flags().Int("clients", 1, "number of clients to run")
-	runCmd.Flags().Int("batch-size", 1, "number of commands to batch together in each block")
-	runCmd.Flags().Int("payload-size", 0, "size in bytes of the command payload")
-	runCmd.Flags().Int("max-concurrent", 4, "maximum number of conccurrent commands per client")
-	runCmd.Flags().Duration("duration", 10*time.Second, "duration of the experiment")
-	runCmd.Flags().Duration("connect-timeout", 5*time.Second, "duration of the initial connection timeout")
-	runCmd.Flags().Duration("view-timeout", 100*time.Millisecond, "duration of the first view")
-	runCmd.Flags().Duration("max-timeout", 0, "upper limit on view timeouts")
-	runCmd.Flags().Int("duration-samples", 1000, "number of previous views to consider when predicting view duration")
-	runCmd.Flags().Float32("timeout-multiplier", 1.2, "number to multiply the view duration by in case of a timeout")
-	runCmd.Flags().String("consensus", "chainedhotstuff", "name of the consensus implementation")
-	runCmd.Flags().String("crypto", "ecdsa", "name of the crypto implementation")
-	runCmd.Flags().String("leader-rotation", "round-robin", "name of the leader rotation algorithm")
-	runCmd.Flags().Int64("shared-seed", 0, "Shared random number generator seed")
-
-	runCmd.Flags().Bool("worker", false, "run a local worker")
-	runCmd.Flags().StringSlice("hosts", nil, "the remote hosts to run the experiment on via ssh")
-	runCmd.Flags().String("exe", "", "path to the executable to deploy and run on remote workers")
-	runCmd.Flags().String("ssh-config", "", "path to ssh_config file to resolve host aliases (defaults to ~/.ssh/config)")
-
-	runCmd.Flags().String("output", "", "the directory to save data and profiles to (disabled by default)")
-	runCmd.Flags().Bool("cpu-profile", false, "enable cpu profiling")
-	runCmd.Flags().Bool("mem-profile", false, "enable memory profiling")
-	runCmd.Flags().Bool("trace", false, "enable trace")
-	runCmd.Flags().Bool("fgprof-profile", false, "enable fgprof")
-
-	runCmd.Flags().StringSlice("metrics", []string{"client-latency", "throughput"}, "list of metrics to enable")
-	runCmd.Flags().Duration("measurement-interval", 0, "time interval between measurements")
-	runCmd.Flags().Float64("rate-limit", math.Inf(1), "rate limit for clients (in commands/second)")
-	runCmd.Flags().Float64("rate-step", 0, "rate limit step up for clients (in commands/second)")
-	runCmd.Flags().Duration("rate-step-interval", time.Hour, "how often the client rate limit should be increased")
-	runCmd.Flags().StringSlice("byzantine", nil, "byzantine strategies to use, as a comma separated list of 'name:count'")
+	addRunFlags(runCmd.Flags())
 
 	err := viper.BindPFlags(runCmd.Flags())
 	if err != nil {
@@ -84,8 +63,137 @@ func init() {
 	}
 }
 
-func runController() {
-	var err error
+// addRunFlags adds the flags of the run command to the flag set.
+// Each flag can also be set in an experiment file, using the name of the flag as the key.
+func addRunFlags(flags *pflag.FlagSet) {
+
+	flags.Int("replicas", 4, "number of replicas to run")
+	flags.Int("clients", 1, "number of clients to run")
+	flags.Int("client-sessions", 1, "number of sessions per client, each with its own client ID and connections")
+	flags.Int("batch-size", 1, "number of commands to batch together in each block")
+	flags.Int("batch-bytes", 0, "maximum number of bytes of the commands batched together in each block (unbounded if zero)")
+	flags.Duration("batch-delay", 0, "how long a command waits for its batch to fill up before a smaller batch is proposed (until it is full if zero)")
+	flags.String("batch-policy", "fifo", "the order in which the commands of different clients are batched: fifo, or fair to let the clients take turns")
+	flags.String("client-weights", "", "the weights of the clients under the fair batch policy as 'client=weight', such as '1=3,2=1' (1 if not given)")
+	flags.Int("max-block-bytes", 0, "maximum size in bytes of a serialized proposal (the maximum message size of the replica server if zero)")
+	flags.Int("execution-lag", 0, "number of committed blocks that may wait to be executed asynchronously (executed as they are committed if zero)")
+	flags.Int("payload-size", 0, "size in bytes of the command payload")
+	flags.String("payload-distribution", "fixed", "distribution of the payload sizes: fixed (payload-size), uniform, or zipf (payload-min to payload-max)")
+	flags.Int("payload-min", 0, "minimum payload size in bytes with the uniform and zipf distributions")
+	flags.Int("payload-max", 0, "maximum payload size in bytes with the uniform and zipf distributions")
+	flags.Float64("payload-zipf-s", 1.1, "exponent of the zipf payload size distribution (must be greater than 1)")
+	flags.Int64("payload-seed", 0, "seed for the payload sizes and contents (each client adds its ID; derived from the master seed if zero)")
+	flags.Bool("compressible-payload", false, "generate compressible payloads instead of random bytes")
+	flags.Int("max-concurrent", 4, "maximum number of conccurrent commands per client")
+	flags.Int("min-concurrent", 1, "minimum number of concurrent commands per client with an adaptive window")
+	flags.Duration("target-latency", 0, "latency below which closed-loop clients adapt the number of concurrent commands (fixed at max-concurrent if zero)")
+	flags.Duration("duration", 10*time.Second, "duration of the experiment")
+	flags.Duration("progress-interval", 5*time.Second, "how often to log the progress of the experiment (disabled if zero)")
+	flags.Duration("stall-timeout", 0, "abort the experiment if no commands are committed for this long (disabled if zero)")
+	flags.Duration("deploy-timeout", iago.DefaultTimeout, "how long each step of deploying a worker to a host may take")
+	flags.Duration("start-timeout", time.Minute, "how long to wait for a worker to create and start its replicas and clients (forever if zero)")
+	flags.Duration("run-timeout", 10*time.Second, "how long to wait for a worker to report its progress (forever if zero)")
+	flags.Int("run-retries", 3, "number of progress intervals to keep waiting for a late progress report before a worker is considered failed")
+	flags.Duration("stop-timeout", 2*time.Minute, "how long to wait for a worker to stop its clients and replicas (forever if zero)")
+	flags.Duration("collect-timeout", 5*time.Minute, "how long to wait for a worker to send its artifacts (forever if zero)")
+	flags.Duration("connect-timeout", 5*time.Second, "duration of the initial connection timeout")
+	flags.Duration("view-timeout", 100*time.Millisecond, "duration of the first view")
+	flags.Duration("max-timeout", 0, "upper limit on view timeouts")
+	flags.Int("duration-samples", 1000, "number of previous views to consider when predicting view duration")
+	flags.Float32("timeout-multiplier", 1.2, "number to multiply the view duration by in case of a timeout")
+	flags.String("consensus", "chainedhotstuff", "name of the consensus implementation")
+	flags.String("crypto", "ecdsa", "name of the crypto implementation")
+	flags.Uint32("crypto-cache-size", orchestration.DefaultCryptoCacheSize, "number of verified signatures and certificates that each replica caches (disabled if zero)")
+	flags.String("leader-rotation", "round-robin", "name of the leader rotation algorithm")
+	flags.Int64("shared-seed", 0, "Shared random number generator seed (derived from the master seed if zero)")
+	flags.Int64("seed", 0, "master seed that the seeds of the replicas and clients are derived from (chosen and logged if zero)")
+	flags.Bool("compress-proposals", false, "compress the commands of proposals before sending them")
+	flags.String("transport", "tcp", "name of the transport used for replica-to-replica communication (tcp or quic)")
+	flags.String("latency-matrix", "", "path to a file containing a matrix of one-way latencies between replicas to emulate")
+	flags.Duration("jitter", 0, "maximum jitter to add to the emulated latencies")
+	flags.Bool("rate-limit-inbound", false, "rate limit the messages received from each replica")
+	flags.String("inbound-rate-limits", "", "rate limits of the messages received from each replica as 'class=rate:burst', such as 'vote=1000:100,fetch=10:5', which replace the default limits (enables rate limiting)")
+	flags.Int("tree-fanout", 0, "disseminate proposals through a tree with the given fanout (disabled if zero)")
+	flags.Duration("gossip-interval", 0, "how often to gossip recently certified blocks to other replicas (disabled if zero)")
+	flags.Duration("health-check-interval", 0, "how often to ping the other replicas (disabled if zero)")
+	flags.Int("health-check-threshold", 3, "number of missed pings before a replica is considered unreachable")
+	flags.Int("proposal-chunk-size", 0, "send proposals larger than this number of bytes in chunks (disabled if zero)")
+	flags.Int("send-queue-size", 0, "number of messages that can be queued for each replica (send queues are disabled if zero)")
+	flags.Int("dedup-size", 0, "number of received messages to remember in order to drop duplicates (disabled if zero)")
+	flags.Duration("dedup-ttl", backend.DefaultDedupTTL, "how long received messages are remembered in order to drop duplicates")
+	flags.Bool("forward-commands", false, "forward client commands to the leader instead of buffering them until the replica leads")
+	flags.Int("command-cache-size", 0, "maximum number of commands waiting to be proposed (unbounded if zero)")
+	flags.Int("command-cache-bytes", 0, "maximum number of bytes of the commands waiting to be proposed (unbounded if zero)")
+	flags.Bool("evict-commands", false, "evict the oldest commands from a full command cache instead of rejecting new commands")
+	flags.Uint32("event-queue-capacity", 1000, "number of events that each queue of the event loop of a replica can hold")
+	flags.Bool("fatal-panics", false, "make the replicas crash on a panic in an event handler instead of recovering from it (for debugging)")
+	flags.Int("snapshot-interval", 0, "number of views between the snapshots transferred to replicas that fall far behind (state transfer is disabled if zero)")
+	flags.String("data-dir", "", "directory for durable snapshots of the replicas' state, from which they recover when restarted (disabled if empty)")
+	flags.Uint32("durable-snapshot-views", 100, "number of views between durable snapshots (disabled if zero)")
+	flags.Uint64("durable-snapshot-bytes", 0, "number of bytes of executed commands between durable snapshots (disabled if zero)")
+	flags.Bool("persist-commands", false, "persist the commands admitted from clients in the data directory, and reload them when restarted")
+	flags.String("command-log-sync", "batched", "when the persisted commands are synced: command, batched, or off")
+	flags.Duration("replica-drain-timeout", time.Second, "how long a replica that shuts down processes its queued events, and waits for the RPCs in progress before it closes its connections")
+	flags.String("status-listen", "", "the address that the HTTP status server of each replica listens on, such as ':0' (disabled if empty)")
+	flags.String("commit-stream-listen", "", "the address that the commit stream server of each replica listens on, such as ':0' (disabled if empty)")
+	flags.Uint32("commit-stream-buffer", 1024, "the number of committed blocks buffered for each subscriber of the commit stream")
+	flags.String("unix-socket-dir", "", "listen on unix domain sockets in this directory instead of TCP ports (local workers only)")
+	flags.Uint32("shards", 1, "number of consensus instances (shards) run by each replica over the same connections; the clients are assigned to the shards in turn")
+
+	flags.Bool("worker", false, "run a local worker")
+	flags.StringSlice("hosts", nil, "the remote hosts to run the experiment on via ssh")
+	flags.String("exe", "", "path to the executable to deploy and run on remote workers")
+	flags.String("ssh-config", "", "path to ssh_config file to resolve host aliases (defaults to ~/.ssh/config)")
+
+	flags.String("output", "", "the directory to save data and profiles to (disabled by default)")
+	flags.Bool("collect-artifacts", false, "collect the logs and measurements of each worker to a directory for each host in the output directory")
+	flags.StringSlice("artifact-profiles", nil, "profiles that the workers capture during the run and collect as artifacts: cpu and heap")
+	flags.Uint64("max-artifact-size", orchestration.DefaultMaxArtifactSize, "maximum number of bytes of each collected artifact (larger artifacts are truncated)")
+	flags.Bool("cpu-profile", false, "enable cpu profiling")
+	flags.Bool("mem-profile", false, "enable memory profiling")
+	flags.Bool("trace", false, "enable trace")
+	flags.Bool("fgprof-profile", false, "enable fgprof")
+
+	flags.StringSlice("metrics", []string{"client-latency", "throughput"}, "list of metrics to enable")
+	flags.Duration("measurement-interval", 0, "time interval between measurements")
+	flags.Float64("rate-limit", math.Inf(1), "rate limit for clients (in commands/second)")
+	flags.Float64("rate-step", 0, "rate limit step up for clients (in commands/second)")
+	flags.Duration("rate-step-interval", time.Hour, "how often the client rate limit should be increased")
+	flags.String("load-mode", "closed", "how clients issue commands: closed, poisson, fixed, or trace (open-loop modes drop commands beyond max-concurrent)")
+	flags.Float64("target-rate", 0, "rate at which open-loop clients issue commands (in commands/second)")
+	flags.String("load-profile", "", "steps of 'duration:rate' at which open-loop clients issue commands instead of the target rate, such as '30s:100,30s:200'")
+	flags.Bool("load-ramp", false, "change the rate of the load profile linearly from the rate of the previous step, instead of in steps")
+	flags.String("workload-trace", "", "path to a workload trace that clients replay in the trace load mode (lines of 'delta size [key]')")
+	flags.Float64("trace-speed", 1, "factor by which clients speed up the replay of the trace")
+	flags.Bool("trace-loop", false, "replay the trace again when it ends, instead of stopping the clients")
+	flags.Duration("request-timeout", 0, "deadline of the first attempt of a client command (commands are not retried if zero)")
+	flags.Duration("max-request-timeout", 10*time.Second, "maximum deadline of a retried client command (the deadline doubles for each retry)")
+	flags.String("workload", "opaque", "what client commands do: opaque (payloads are only ordered and hashed) or kv (key-value commands executed by a key-value store in the replicas)")
+	flags.Uint32("kv-keys", 1000, "number of keys of the kv workload")
+	flags.Float64("kv-read-ratio", 0, "fraction of the commands of the kv workload that are gets")
+	flags.Float64("kv-delete-ratio", 0, "fraction of the commands of the kv workload that are deletes (the rest are puts)")
+	flags.Uint32("kv-checkpoint-interval", 0, "number of commands of the kv workload between commands that record the state hash (none if zero)")
+	flags.String("ack-mode", "quorum", "acknowledgements a client command needs: quorum (f+1 replicas acknowledge the same block) or first")
+	flags.Int("max-retries", 3, "number of times a client command is retried before it is considered failed")
+	flags.Int("client-batch-size", 1, "number of commands that a client sends in a single message")
+	flags.Duration("client-batch-timeout", time.Millisecond, "how long a client waits for more commands before it sends a partial batch")
+	flags.Duration("client-drain-timeout", 2*time.Second, "how long a stopping client waits for its commands in flight before it abandons them")
+	flags.StringSlice("byzantine", nil, "byzantine strategies to use, as a comma separated list of 'name:count'")
+	flags.StringSlice("byzantine-replicas", nil, "byzantine strategies of specific replicas, as a comma separated list of 'id:name'")
+	flags.StringSlice("faults", nil, "faults to inject into replicas, as a comma separated list of 'id[.shard]:action@start[+duration]', where action is crash, pause, or restart, such as '2:crash@30s+15s', and only a shard can be paused if there are several")
+}
+
+func runController(flags *pflag.FlagSet) {
+	if viper.IsSet("sweep") {
+		err := runSweep(viper.GetViper(), flags)
+		checkf("sweep failed: %v", err)
+		return
+	}
+
+	// the experiment is validated before the workers are deployed.
+	experiment, err := newExperiment(viper.GetViper(), flags)
+	checkf("invalid experiment: %v", err)
+
 	outputDir := ""
 	if output := viper.GetString("output"); output != "" {
 		outputDir, err = filepath.Abs(output)
@@ -94,112 +202,253 @@ func runController() {
 		checkf("failed to create output directory: %v", err)
 	}
 
-	experiment := orchestration.Experiment{
-		Logger:      logging.New("ctrl"),
-		NumReplicas: viper.GetInt("replicas"),
-		NumClients:  viper.GetInt("clients"),
-		Duration:    viper.GetDuration("duration"),
-		ReplicaOpts: &orchestrationpb.ReplicaOpts{
-			UseTLS:            true,
-			BatchSize:         viper.GetUint32("batch-size"),
-			TimeoutMultiplier: float32(viper.GetFloat64("timeout-multiplier")),
-			Consensus:         viper.GetString("consensus"),
-			Crypto:            viper.GetString("crypto"),
-			LeaderRotation:    viper.GetString("leader-rotation"),
-			ConnectTimeout:    durationpb.New(viper.GetDuration("connect-timeout")),
-			InitialTimeout:    durationpb.New(viper.GetDuration("view-timeout")),
-			TimeoutSamples:    viper.GetUint32("duration-samples"),
-			MaxTimeout:        durationpb.New(viper.GetDuration("max-timeout")),
-			SharedSeed:        viper.GetInt64("shared-seed"),
-		},
-		ClientOpts: &orchestrationpb.ClientOpts{
-			UseTLS:           true,
-			ConnectTimeout:   durationpb.New(viper.GetDuration("connect-timeout")),
-			PayloadSize:      viper.GetUint32("payload-size"),
-			MaxConcurrent:    viper.GetUint32("max-concurrent"),
-			RateLimit:        viper.GetFloat64("rate-limit"),
-			RateStep:         viper.GetFloat64("rate-step"),
-			RateStepInterval: durationpb.New(viper.GetDuration("rate-step-interval")),
-		},
-	}
-
-	experiment.Byzantine, err = parseByzantine()
+	err = runExperiment(viper.GetViper(), experiment, outputDir)
 	checkf("%v", err)
+}
 
-	worker := viper.GetBool("worker")
-	hosts := viper.GetStringSlice("hosts")
-	exePath := viper.GetString("exe")
+// runExperiment deploys the workers, runs the experiment, and fetches the data of the workers to the output directory,
+// which is not used if it is empty.
+func runExperiment(v *viper.Viper, experiment *orchestration.Experiment, outputDir string) error {
+	d, err := deploy(v, outputDir, false)
+	if err != nil {
+		return err
+	}
+	d.use(experiment)
+	if experiment.ArtifactDir != "" {
+		experiment.ArtifactDir = outputDir
+	}
+	runErr := experiment.Run()
+	if err := d.report(experiment, outputDir); err != nil {
+		return err
+	}
+	return d.close(outputDir, runErr)
+}
 
-	g, err := iago.NewSSHGroup(hosts, viper.GetString("ssh-config"))
-	checkf("Failed to connect to remote hosts: %v", err)
+// deployment is the set of workers that experiments are run on.
+type deployment struct {
+	group    iago.Group
+	sessions map[string]orchestration.WorkerSession
+	errors   chan error
+	hosts    map[string]orchestration.RemoteWorker
+	wait     func() error // waits for the local worker to exit, if there is one
+
+	// the addresses that the replicas of the local worker are reached at by the remote workers.
+	addresses map[string]string
+	// set if the local worker writes its data to a directory of its own, like the data fetched from the remote hosts.
+	localDir bool
+
+	deployFailed map[string]error // the hosts that could not be deployed to
+	failed       map[string]bool  // the hosts whose workers have failed, which are not waited for
+}
+
+// localHost is the name of the host of the worker that runs in the process of the controller.
+// The hosts of an experiment file can also refer to it as "local".
+const localHost = "localhost"
+
+// experimentHosts returns the remote hosts of the experiment, and whether a worker runs in the process of the
+// controller, which it does if requested, if the hosts include "local", or if there are no remote hosts.
+func experimentHosts(v *viper.Viper) (remote []string, local bool) {
+	for _, host := range v.GetStringSlice("hosts") {
+		if host == "local" {
+			local = true
+			continue
+		}
+		remote = append(remote, host)
+	}
+	return remote, local || v.GetBool("worker") || len(remote) == 0
+}
+
+// hostName returns the name of the host in the experiment, which is localHost for "local".
+func hostName(name string) string {
+	if name == "local" {
+		return localHost
+	}
+	return name
+}
+
+// deploy deploys the workers to the remote hosts, and starts a local worker if requested or if there are no hosts.
+// In a mixed deployment, with both remote hosts and a local worker, the data of the local worker are written to a
+// directory of its own in the output directory, like the data fetched from the remote hosts.
+// If runs is set, the workers write the measurements of each run to the directory of the run,
+// which is named when they are reset, instead of to the output directory.
+func deploy(v *viper.Viper, outputDir string, runs bool) (_ *deployment, err error) {
+	hosts, local := experimentHosts(v)
+	exePath := v.GetString("exe")
+
+	g, err := iago.NewSSHGroup(hosts, v.GetString("ssh-config"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to remote hosts: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			g.Close()
+		}
+	}()
 
 	if exePath == "" {
 		exePath, err = os.Executable()
-		checkf("Failed to get executable path: %v", err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get executable path: %w", err)
+		}
 	}
 
 	sessions, err := orchestration.Deploy(g, orchestration.DeployConfig{
 		ExePath:             exePath,
-		LogLevel:            viper.GetString("log-level"),
-		CPUProfiling:        viper.GetBool("cpu-profile"),
-		MemProfiling:        viper.GetBool("mem-profile"),
-		Tracing:             viper.GetBool("trace"),
-		Fgprof:              viper.GetBool("fgprof-profile"),
-		Metrics:             viper.GetStringSlice("metrics"),
-		MeasurementInterval: viper.GetDuration("measurement-interval"),
+		LogLevel:            v.GetString("log-level"),
+		CPUProfiling:        v.GetBool("cpu-profile"),
+		MemProfiling:        v.GetBool("mem-profile"),
+		Tracing:             v.GetBool("trace"),
+		Fgprof:              v.GetBool("fgprof-profile"),
+		Metrics:             v.GetStringSlice("metrics"),
+		MeasurementInterval: v.GetDuration("measurement-interval"),
+		Timeout:             v.GetDuration("deploy-timeout"),
 	})
-	checkf("Failed to deploy workers: %v", err)
-
-	errors := make(chan error)
-
-	experiment.Hosts = make(map[string]orchestration.RemoteWorker)
+	// the experiment continues without the hosts that could not be deployed to.
+	var deployErr *orchestration.DeployError
+	if errors.As(err, &deployErr) && len(sessions) > 0 {
+		log.Printf("Continuing without the failed hosts: %v", err)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to deploy workers: %w", err)
+	}
 
+	d := &deployment{
+		group:    g,
+		sessions: sessions,
+		errors:   make(chan error, len(sessions)),
+		hosts:    make(map[string]orchestration.RemoteWorker),
+		failed:   make(map[string]bool),
+	}
+	if deployErr != nil {
+		d.deployFailed = deployErr.Failed
+	}
 	for host, session := range sessions {
-		experiment.Hosts[host] = orchestration.NewRemoteWorker(
+		d.hosts[host] = orchestration.NewRemoteWorker(
 			protostream.NewWriter(session.Stdin()), protostream.NewReader(session.Stdout()),
 		)
-		go stderrPipe(session.Stderr(), errors)
+		go stderrPipe(session.Stderr(), d.errors)
 	}
 
-	if worker || len(hosts) == 0 {
-		worker, wait := localWorker(outputDir, viper.GetStringSlice("metrics"), viper.GetDuration("measurement-interval"))
-		defer wait()
-		experiment.Hosts["localhost"] = worker
+	if local {
+		localDir := outputDir
+		if len(hosts) > 0 {
+			if err := d.addLocalAddress(); err != nil {
+				return nil, err
+			}
+			if outputDir != "" {
+				localDir = filepath.Join(outputDir, localHost)
+				if err := os.MkdirAll(localDir, 0755); err != nil {
+					return nil, fmt.Errorf("failed to create output directory: %w", err)
+				}
+				d.localDir = true
+			}
+		}
+		d.hosts[localHost], d.wait = localWorker(localDir, runs, v.GetStringSlice("metrics"), v.GetDuration("measurement-interval"))
 	}
+	return d, nil
+}
 
-	experiment.HostConfigs = make(map[string]orchestration.HostConfig)
-
-	var hostConfigs []struct {
-		Name     string
-		Clients  int
-		Replicas int
+// addLocalAddress sets the address that the remote workers reach the replicas of the local worker at,
+// which is the address of the controller on the route to the first remote host.
+func (d *deployment) addLocalAddress() error {
+	for _, host := range d.group.Hosts {
+		if _, ok := d.sessions[host.Name()]; !ok {
+			continue
+		}
+		addr, err := orchestration.ControllerAddress(host)
+		if err != nil {
+			return fmt.Errorf("failed to get the address of the local worker: %w", err)
+		}
+		d.addresses = map[string]string{localHost: addr}
+		return nil
 	}
+	return nil
+}
 
-	err = viper.UnmarshalKey("hosts-config", &hostConfigs)
-	checkf("failed to unmarshal hosts-config: %v", err)
+// use makes the experiment run on the workers of the deployment.
+func (d *deployment) use(experiment *orchestration.Experiment) {
+	experiment.Hosts = d.hosts
+	experiment.HostAddresses = d.addresses
+}
 
-	for _, cfg := range hostConfigs {
-		experiment.HostConfigs[cfg.Name] = orchestration.HostConfig{Replicas: cfg.Replicas, Clients: cfg.Clients}
+// report drops the workers that failed during the experiment, such that they are neither used nor waited for again,
+// and writes the hosts whose data are missing to report.json in the output directory.
+func (d *deployment) report(experiment *orchestration.Experiment, outputDir string) error {
+	report := experiment.Report()
+	for _, missing := range report.Missing {
+		d.failed[missing.Host] = true
+		delete(d.hosts, missing.Host)
+	}
+	for host, err := range d.deployFailed {
+		report.Add(host, orchestration.DeployPhase, err)
+	}
+	if len(report.Missing) == 0 || outputDir == "" {
+		return nil
 	}
+	log.Printf("The data of these hosts are missing: %v", report)
+	if err := report.Write(filepath.Join(outputDir, "report.json")); err != nil {
+		return fmt.Errorf("failed to write run report: %w", err)
+	}
+	return nil
+}
 
-	err = experiment.Run()
-	checkf("failed to run experiment: %v", err)
+// close waits for the workers to exit, and fetches their data to the output directory unless the run failed.
+// The data of the other workers are fetched if some of the workers failed. The ssh sessions are closed even if the
+// run failed, such that the remote workers exit, but the workers that failed are not waited for.
+func (d *deployment) close(outputDir string, runErr error) (err error) {
+	defer func() {
+		if cerr := d.group.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("failed to close ssh connections: %w", cerr)
+		}
+	}()
+	if d.wait != nil && !d.failed[localHost] {
+		defer func() {
+			if werr := d.wait(); err == nil && werr != nil {
+				err = fmt.Errorf("local worker failed: %w", werr)
+			}
+		}()
+	}
 
-	for _, session := range sessions {
-		err := session.Close()
-		checkf("failed to close ssh command session: %v", err)
+	for host, session := range d.sessions {
+		if d.failed[host] {
+			continue
+		}
+		if err := session.Close(); err != nil {
+			return fmt.Errorf("failed to close ssh command session: %w", err)
+		}
+		if err := <-d.errors; err != nil {
+			return fmt.Errorf("failed to read from remote's standard error stream: %w", err)
+		}
 	}
 
-	for range sessions {
-		err = <-errors
-		checkf("failed to read from remote's standard error stream %v", err)
+	if runErr != nil && !errors.Is(runErr, orchestration.ErrMissingData) {
+		return fmt.Errorf("failed to run experiment: %w", runErr)
 	}
 
-	err = orchestration.FetchData(g, outputDir)
-	checkf("failed to fetch data: %v", err)
+	// the data are fetched from the hosts that were deployed to, and whose workers did not fail.
+	g := d.group
+	g.Hosts = nil
+	for _, host := range d.group.Hosts {
+		if _, ok := d.sessions[host.Name()]; ok && !d.failed[host.Name()] {
+			g.Hosts = append(g.Hosts, host)
+		}
+	}
+	if err := orchestration.FetchData(g, outputDir); err != nil {
+		return fmt.Errorf("failed to fetch data: %w", err)
+	}
+	if runErr != nil {
+		return fmt.Errorf("failed to run experiment: %w", runErr)
+	}
+	return nil
+}
 
-	err = g.Close()
-	checkf("failed to close ssh connections: %v", err)
+// shutdown shuts down the workers, which then exit. Every worker is asked to shut down, even if another fails.
+func (d *deployment) shutdown() (err error) {
+	for host, worker := range d.hosts {
+		if _, serr := worker.Shutdown(&orchestrationpb.ShutdownRequest{}); serr != nil {
+			err = multierr.Append(err, fmt.Errorf("failed to shut down %s: %w", host, serr))
+		}
+	}
+	return err
 }
 
 func checkf(format string, args ...interface{}) {
@@ -210,9 +459,9 @@ func checkf(format string, args ...interface{}) {
 	}
 }
 
-func parseByzantine() (map[string]int, error) {
+func parseByzantine(v *viper.Viper) (map[string]int, error) {
 	strategies := make(map[string]int)
-	byzantine := viper.GetStringSlice("byzantine")
+	byzantine := v.GetStringSlice("byzantine")
 	for _, arg := range byzantine {
 		parts := strings.Split(arg, ":")
 		if len(parts) != 2 {
@@ -227,44 +476,121 @@ func parseByzantine() (map[string]int, error) {
 	return strategies, nil
 }
 
-func localWorker(output string, metrics []string, interval time.Duration) (worker orchestration.RemoteWorker, wait func()) {
-	// set up a local worker
-	controllerPipe, workerPipe := net.Pipe()
-	c := make(chan struct{})
-	go func() {
-		var logger modules.MetricsLogger
-		if output != "" {
-			f, err := os.OpenFile(filepath.Join(output, "measurements.json"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-			checkf("failed to create output file: %v", err)
-			defer func() { checkf("failed to close output file: %v", f.Close()) }()
-
-			wr := bufio.NewWriter(f)
-			defer func() { checkf("failed to flush writer: %v", wr.Flush()) }()
-
-			logger, err = modules.NewJSONLogger(wr)
-			checkf("failed to create JSON logger: %v", err)
-			defer func() { checkf("failed to close logger: %v", logger.Close()) }()
-		} else {
-			logger = modules.NopLogger()
+func parseByzantineReplicas(v *viper.Viper) (map[hotstuff.ID]string, error) {
+	strategies := make(map[hotstuff.ID]string)
+	for _, arg := range v.GetStringSlice("byzantine-replicas") {
+		parts := strings.Split(arg, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("byzantine-replicas must be specified as a comma separated list of 'id:name'")
 		}
+		id, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("could not read replica ID for byzantine strategy '%s': %w", arg, err)
+		}
+		strategies[hotstuff.ID(id)] = parts[1]
+	}
+	return strategies, nil
+}
 
-		worker := orchestration.NewWorker(
-			protostream.NewWriter(workerPipe),
-			protostream.NewReader(workerPipe),
-			logger,
-			metrics,
-			interval,
-		)
-
-		err := worker.Run()
+// parseFaults parses the faults to inject into the replicas, such as "2:crash@30s+15s".
+func parseFaults(v *viper.Viper) (map[hotstuff.ID][]*orchestrationpb.Fault, error) {
+	faults := make(map[hotstuff.ID][]*orchestrationpb.Fault)
+	for _, arg := range v.GetStringSlice("faults") {
+		parts := strings.SplitN(arg, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("faults must be specified as a comma separated list of 'id[.shard]:action@start[+duration]'")
+		}
+		fault := &orchestrationpb.Fault{}
+		replica := parts[0]
+		if i := strings.Index(replica, "."); i >= 0 {
+			shard, err := strconv.ParseUint(replica[i+1:], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("could not read shard of fault '%s': %w", arg, err)
+			}
+			replica, fault.Shard = replica[:i], uint32(shard)
+		}
+		id, err := strconv.ParseUint(replica, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("could not read replica ID of fault '%s': %w", arg, err)
+		}
+		i := strings.Index(parts[1], "@")
+		if i < 0 {
+			return nil, fmt.Errorf("missing start time of fault '%s'", arg)
+		}
+		action, times := parts[1][:i], parts[1][i+1:]
+		start, length := times, ""
+		if i := strings.Index(times, "+"); i >= 0 {
+			start, length = times[:i], times[i+1:]
+		}
+		fault.Action = action
+		d, err := time.ParseDuration(start)
 		if err != nil {
-			log.Fatal(err)
+			return nil, fmt.Errorf("could not read start time of fault '%s': %w", arg, err)
+		}
+		fault.Start = durationpb.New(d)
+		if length != "" {
+			d, err := time.ParseDuration(length)
+			if err != nil {
+				return nil, fmt.Errorf("could not read duration of fault '%s': %w", arg, err)
+			}
+			fault.Duration = durationpb.New(d)
+		}
+		faults[hotstuff.ID(id)] = append(faults[hotstuff.ID(id)], fault)
+	}
+	return faults, nil
+}
+
+// parseLatencyMatrix reads a latency matrix from a file.
+// Each line of the file is a row of the matrix containing whitespace separated durations, such as '50ms'.
+// Empty lines and lines starting with '#' are ignored.
+func parseLatencyMatrix(path string) ([][]time.Duration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var matrix [][]time.Duration
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var row []time.Duration
+		for _, field := range strings.Fields(line) {
+			latency, err := time.ParseDuration(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid latency '%s' in row %d: %w", field, len(matrix)+1, err)
+			}
+			row = append(row, latency)
+		}
+		matrix = append(matrix, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for i, row := range matrix {
+		if len(row) != len(matrix) {
+			return nil, fmt.Errorf("latency matrix must be square: row %d has %d columns, expected %d", i+1, len(row), len(matrix))
 		}
-		close(c)
+	}
+	return matrix, nil
+}
+
+// localWorker starts a worker in this process that writes its measurements to the output directory, unless it is empty.
+// If runs is set, the measurements of each run are written to the directory of the run in the output directory instead,
+// which is named when the worker is reset. The wait function waits for the worker to exit, and returns its error.
+func localWorker(output string, runs bool, metrics []string, interval time.Duration) (worker orchestration.RemoteWorker, wait func() error) {
+	// set up a local worker
+	controllerPipe, workerPipe := net.Pipe()
+	c := make(chan error, 1)
+	go func() {
+		c <- runLocalWorker(workerPipe, output, runs, metrics, interval)
 	}()
 
-	wait = func() {
-		<-c
+	wait = func() error {
+		return <-c
 	}
 
 	return orchestration.NewRemoteWorker(
@@ -272,7 +598,111 @@ func localWorker(output string, metrics []string, interval time.Duration) (worke
 	), wait
 }
 
+// runLocalWorker runs a worker that communicates with the controller through the pipe.
+// The pipe is closed when the worker exits, such that the controller does not wait for a worker that failed to start.
+func runLocalWorker(pipe net.Conn, output string, runs bool, metrics []string, interval time.Duration) (err error) {
+	defer pipe.Close()
+
+	// the loggers of the replicas and clients that are created by the worker are captured for artifact collection.
+	logs := orchestration.NewLogBuffer(orchestration.DefaultLogBufferSize)
+	logging.SetCapture(logs)
+	defer logging.SetCapture(nil)
+
+	// in a sweep, the measurements are written to the directory of each run when the worker is reset.
+	files := measurementFiles{dir: output}
+	defer closeAndKeepError(files.close, "failed to close measurements", &err)
+	logger := modules.NopLogger()
+	if output != "" && !runs {
+		logger, err = files.open(filepath.Join(output, "measurements.json"))
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+	}
+
+	worker := orchestration.NewWorker(
+		protostream.NewWriter(pipe),
+		protostream.NewReader(pipe),
+		logger,
+		metrics,
+		interval,
+	)
+	worker.SetLogBuffer(logs)
+	files.worker = &worker
+	if output != "" && !runs {
+		worker.AddArtifact("measurements.json", filepath.Join(output, "measurements.json"))
+	}
+	worker.SetResetHandler(files.reset)
+	return worker.Run()
+}
+
+// closeAndKeepError calls close, and stores its error in err unless err already holds an error.
+func closeAndKeepError(close func() error, msg string, err *error) {
+	if cerr := close(); *err == nil && cerr != nil {
+		*err = fmt.Errorf("%s: %w", msg, cerr)
+	}
+}
+
 func stderrPipe(r io.Reader, errChan chan<- error) {
 	_, err := io.Copy(os.Stderr, r)
 	errChan <- err
 }
+
+// readTrace reads the trace file, and checks that it can be parsed, such that an invalid trace is reported
+// before the experiment starts rather than by the workers.
+func readTrace(path string) ([]byte, error) {
+	trace, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := client.ParseTrace(bytes.NewReader(trace)); err != nil {
+		return nil, err
+	}
+	return trace, nil
+}
+
+// parseInboundRateLimits parses the rate limits of inbound messages, such as "vote=1000:100,fetch=10:5".
+func parseInboundRateLimits(spec string) (map[string]*orchestrationpb.RateLimit, error) {
+	limits, err := backend.ParseRateLimits(spec)
+	if err != nil {
+		return nil, err
+	}
+	pbLimits := make(map[string]*orchestrationpb.RateLimit, len(limits))
+	for class, limit := range limits {
+		pbLimits[class.String()] = &orchestrationpb.RateLimit{Rate: limit.Rate, Burst: uint32(limit.Burst)}
+	}
+	return pbLimits, nil
+}
+
+// parseClientWeights parses the weights of the clients under the fair batch policy, such as "1=3,2=1".
+func parseClientWeights(spec string) (map[uint32]uint32, error) {
+	weights := make(map[uint32]uint32)
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), "=")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid client weight '%s': expected 'client=weight'", entry)
+		}
+		client, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client ID '%s': %w", parts[0], err)
+		}
+		weight, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil || weight == 0 {
+			return nil, fmt.Errorf("invalid weight '%s' of client %d: must be a positive integer", parts[1], client)
+		}
+		weights[uint32(client)] = uint32(weight)
+	}
+	return weights, nil
+}
+
+// parseLoadProfile parses the steps of a load profile, such as "30s:100,30s:200".
+func parseLoadProfile(profile string) ([]*orchestrationpb.LoadStep, error) {
+	p, err := client.ParseLoadProfile(profile, false)
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]*orchestrationpb.LoadStep, 0, len(p.Steps))
+	for _, step := range p.Steps {
+		steps = append(steps, &orchestrationpb.LoadStep{Duration: durationpb.New(step.Duration), Rate: step.Rate})
+	}
+	return steps, nil
+}
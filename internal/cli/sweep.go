@@ -0,0 +1,273 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/relab/hotstuff/metrics/plotting"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// sweepConfig is the sweep section of an experiment file.
+// It lists the values of the flags that the sweep varies, and how many times each combination of values is run.
+type sweepConfig struct {
+	Repetitions int                      `mapstructure:"repetitions"`
+	Parameters  map[string][]interface{} `mapstructure:"parameters"`
+}
+
+// sweepRun is a combination of parameter values of a sweep.
+type sweepRun struct {
+	values map[string]interface{} // the values to set, by flag name
+	params map[string]string      // the resolved values, as they are written to the index
+	dir    string                 // the directory of the combination, relative to the output directory
+}
+
+// runSweep runs the experiment once for every combination of the values of the parameters in the sweep section of the
+// experiment file, and for every repetition. The measurements of each run are stored in a directory named after the
+// parameter values, and the runs are listed in an index in the output directory. A run that fails is recorded in the
+// index, and does not stop the sweep. The workers are deployed once, and reset before each run, unless the sweep
+// varies a flag that determines how they are deployed.
+func runSweep(v *viper.Viper, flags *pflag.FlagSet) error {
+	var sweep sweepConfig
+	if err := v.UnmarshalKey("sweep", &sweep); err != nil {
+		return fmt.Errorf("failed to unmarshal sweep: %w", err)
+	}
+	runs, names, err := sweepRuns(flags, sweep)
+	if err != nil {
+		return err
+	}
+	repetitions := sweep.Repetitions
+	if repetitions < 0 {
+		return fmt.Errorf("invalid number of repetitions: %d", repetitions)
+	} else if repetitions == 0 {
+		repetitions = 1
+	}
+
+	output := v.GetString("output")
+	if output == "" {
+		return fmt.Errorf("a sweep requires an output directory")
+	}
+	outputDir, err := filepath.Abs(output)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// every combination is validated before the first run, such that the sweep does not fail halfway through.
+	for _, run := range runs {
+		setValues(v, run.values)
+		if _, err := newExperiment(v, flags); err != nil {
+			return fmt.Errorf("invalid experiment %s: %w", run.dir, err)
+		}
+	}
+
+	// the workers are deployed once, and reset between the runs, unless the sweep varies how they are deployed.
+	var d *deployment
+	if !sweepsDeployment(names) {
+		if d, err = deploy(v, outputDir, true); err != nil {
+			return err
+		}
+	}
+
+	index := plotting.SweepIndex{Parameters: names}
+	indexFile := filepath.Join(outputDir, plotting.SweepIndexFile)
+	failed := 0
+	for _, run := range runs {
+		for rep := 1; rep <= repetitions; rep++ {
+			dir := filepath.Join(run.dir, strconv.Itoa(rep))
+			log.Printf("Running %s (%d of %d)", dir, len(index.Runs)+1, len(runs)*repetitions)
+			indexed := plotting.SweepRun{Dir: filepath.ToSlash(dir), Parameters: run.params, Repetition: rep}
+			if err := runSweepExperiment(v, flags, run.values, d, outputDir, dir); err != nil {
+				log.Printf("Run %s failed: %v", dir, err)
+				indexed.Error = err.Error()
+				failed++
+			}
+			index.Runs = append(index.Runs, indexed)
+			if err := index.Write(indexFile); err != nil {
+				if d != nil {
+					d.close(outputDir, d.shutdown())
+				}
+				return fmt.Errorf("failed to write sweep index: %w", err)
+			}
+		}
+	}
+	if d != nil {
+		if err := d.close(outputDir, d.shutdown()); err != nil {
+			return err
+		}
+		if err := moveRunData(outputDir, d, index.Runs); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d runs failed", failed, len(index.Runs))
+	}
+	return nil
+}
+
+// runSweepExperiment runs the experiment with the given values in the directory dir of the output directory.
+// If the deployment is nil, the workers are deployed for the run. Otherwise, the workers of the deployment are
+// reset before the run, and kept for the next run.
+func runSweepExperiment(v *viper.Viper, flags *pflag.FlagSet, values map[string]interface{}, d *deployment, outputDir, dir string) error {
+	setValues(v, values)
+	experiment, err := newExperiment(v, flags)
+	if err != nil {
+		return err
+	}
+	runDir := filepath.Join(outputDir, dir)
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if d == nil {
+		return runExperiment(v, experiment, runDir)
+	}
+	d.use(experiment)
+	experiment.KeepWorkers = true
+	if experiment.ArtifactDir != "" {
+		experiment.ArtifactDir = runDir
+	}
+	err = experiment.Reset(filepath.ToSlash(dir))
+	if err == nil {
+		err = experiment.Run()
+	}
+	if rerr := d.report(experiment, runDir); rerr != nil {
+		return rerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run experiment: %w", err)
+	}
+	return nil
+}
+
+// deploymentFlags are the flags that determine how the workers are deployed.
+var deploymentFlags = []string{
+	"output", "hosts", "worker", "exe", "ssh-config", "log-level", "cpu-profile", "mem-profile", "trace",
+	"fgprof-profile", "metrics", "measurement-interval",
+}
+
+// sweepsDeployment returns true if any of the parameters of a sweep determines how the workers are deployed,
+// such that the workers cannot be reused between the runs.
+func sweepsDeployment(names []string) bool {
+	for _, name := range names {
+		for _, flag := range deploymentFlags {
+			if name == flag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// moveRunData moves the data fetched from the remote hosts of a sweep, which is stored in a directory for each host
+// that contains a directory for each run, to a directory for each host in the directory of each run.
+// The directories of the hosts are removed if they are left empty.
+func moveRunData(outputDir string, d *deployment, runs []plotting.SweepRun) error {
+	hosts := make([]string, 0, len(d.sessions)+1)
+	for host := range d.sessions {
+		hosts = append(hosts, host)
+	}
+	// the local worker of a mixed deployment writes its data like a remote host.
+	if d.localDir {
+		hosts = append(hosts, localHost)
+	}
+	for _, host := range hosts {
+		hostDir := filepath.Join(outputDir, host)
+		for _, run := range runs {
+			dir := filepath.FromSlash(run.Dir)
+			src := filepath.Join(hostDir, dir)
+			if _, err := os.Stat(src); os.IsNotExist(err) {
+				continue
+			}
+			if err := os.Rename(src, filepath.Join(outputDir, dir, host)); err != nil {
+				return fmt.Errorf("failed to move the data of %s: %w", run.Dir, err)
+			}
+			os.Remove(filepath.Dir(src))
+		}
+		// the measurements from before the first run are empty.
+		os.Remove(filepath.Join(hostDir, "measurements.json"))
+		os.Remove(hostDir)
+	}
+	return nil
+}
+
+func setValues(v *viper.Viper, values map[string]interface{}) {
+	for name, value := range values {
+		v.Set(name, value)
+	}
+}
+
+// sweepRuns returns the cross product of the values of the parameters of the sweep, and the sorted parameter names.
+func sweepRuns(flags *pflag.FlagSet, sweep sweepConfig) (runs []sweepRun, names []string, err error) {
+	if len(sweep.Parameters) == 0 {
+		return nil, nil, fmt.Errorf("the sweep has no parameters")
+	}
+	for name, values := range sweep.Parameters {
+		flag := flags.Lookup(name)
+		if flag == nil {
+			return nil, nil, fmt.Errorf("unknown sweep parameter: '%s'", name)
+		}
+		if len(values) == 0 {
+			return nil, nil, fmt.Errorf("sweep parameter '%s' has no values", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	runs = []sweepRun{{values: map[string]interface{}{}, params: map[string]string{}}}
+	for _, name := range names {
+		typ := flags.Lookup(name).Value.Type()
+		var next []sweepRun
+		for _, run := range runs {
+			for _, value := range sweep.Parameters[name] {
+				resolved, err := resolveValue(value, typ)
+				if err != nil {
+					return nil, nil, fmt.Errorf("invalid value of sweep parameter '%s': %w", name, err)
+				}
+				param := fmt.Sprint(resolved)
+				if s, ok := resolved.([]string); ok {
+					param = strings.Join(s, ",")
+				}
+				r := sweepRun{
+					values: make(map[string]interface{}, len(run.values)+1),
+					params: make(map[string]string, len(run.params)+1),
+				}
+				for k, val := range run.values {
+					r.values[k] = val
+				}
+				for k, p := range run.params {
+					r.params[k] = p
+				}
+				r.values[name] = value
+				r.params[name] = param
+				part := name + "=" + sanitizePathElement(param)
+				if run.dir == "" {
+					r.dir = part
+				} else {
+					r.dir = run.dir + "_" + part
+				}
+				next = append(next, r)
+			}
+		}
+		runs = next
+	}
+	return runs, names, nil
+}
+
+// sanitizePathElement replaces the characters of s that cannot be used in a directory name.
+func sanitizePathElement(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ' ', ':', '*', '?', '"', '<', '>', '|':
+			return '-'
+		}
+		return r
+	}, s)
+}
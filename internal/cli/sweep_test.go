@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relab/hotstuff/metrics/plotting"
+	"github.com/relab/hotstuff/metrics/types"
+)
+
+// sweepExperiment is a 2x2 sweep where the runs with an invalid status address fail when the replicas are created.
+const sweepExperiment = `
+duration: 1s
+measurement-interval: 200ms
+max-concurrent: 10
+metrics: [client-latency, throughput]
+sweep:
+  parameters:
+    batch-size: [1, 2]
+    status-listen: [":0", invalid]
+`
+
+func TestSweep(t *testing.T) {
+	output := t.TempDir()
+	v, flags, err := readConfig(t, "sweep.yaml", sweepExperiment, "--output="+output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = runSweep(v, flags)
+	if err == nil || !strings.Contains(err.Error(), "2 of 4 runs failed") {
+		t.Errorf("expected the sweep to report 2 of 4 failed runs, got: %v", err)
+	}
+
+	indexFile := filepath.Join(output, plotting.SweepIndexFile)
+	index, err := plotting.ReadSweepIndex(indexFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"batch-size", "status-listen"}; !reflect.DeepEqual(index.Parameters, want) {
+		t.Errorf("got parameters %v, want %v", index.Parameters, want)
+	}
+	wantDirs := []string{
+		"batch-size=1_status-listen=-0/1",
+		"batch-size=1_status-listen=invalid/1",
+		"batch-size=2_status-listen=-0/1",
+		"batch-size=2_status-listen=invalid/1",
+	}
+	if len(index.Runs) != len(wantDirs) {
+		t.Fatalf("got %d runs, want %d", len(index.Runs), len(wantDirs))
+	}
+	for i, run := range index.Runs {
+		if run.Dir != wantDirs[i] {
+			t.Errorf("run %d: got directory %s, want %s", i, run.Dir, wantDirs[i])
+		}
+		if run.Repetition != 1 {
+			t.Errorf("run %d: got repetition %d, want 1", i, run.Repetition)
+		}
+		if fi, err := os.Stat(filepath.Join(output, run.Dir)); err != nil || !fi.IsDir() {
+			t.Errorf("run %d: missing output directory: %v", i, err)
+		}
+		if failed := run.Parameters["status-listen"] == "invalid"; failed != (run.Error != "") {
+			t.Errorf("run %d with parameters %v: unexpected error: '%s'", i, run.Parameters, run.Error)
+		}
+		if run.Error != "" {
+			continue
+		}
+		// the configuration recorded in the measurements contains the swept value.
+		var config string
+		reader := plotting.NewReader(openMeasurements(t, filepath.Join(output, run.Dir)), plotterFunc(func(m interface{}) {
+			if e, ok := m.(*types.StartEvent); ok {
+				config = e.GetConfig()
+			}
+		}))
+		if err := reader.ReadAll(); err != nil {
+			t.Fatal(err)
+		}
+		if want := "batch-size: " + run.Parameters["batch-size"] + "\n"; !strings.Contains(config, want) {
+			t.Errorf("run %d: the recorded configuration does not contain '%s':\n%s", i, want, config)
+		}
+	}
+
+	summaries, err := plotting.SummarizeSweep(indexFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 4 {
+		t.Fatalf("got %d summaries, want 4", len(summaries))
+	}
+	for _, s := range summaries {
+		if s.Parameters["status-listen"] == "invalid" {
+			if s.Runs != 0 || s.Failed != 1 {
+				t.Errorf("%v: got %d runs and %d failed, want 0 and 1", s, s.Runs, s.Failed)
+			}
+		} else if s.Runs != 1 || s.Throughput <= 0 {
+			t.Errorf("%v: got %d runs with throughput %f, want 1 run with positive throughput", s, s.Runs, s.Throughput)
+		}
+	}
+}
+
+func openMeasurements(t *testing.T, dir string) *os.File {
+	t.Helper()
+	f, err := os.Open(filepath.Join(dir, "measurements.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+type plotterFunc func(measurement interface{})
+
+func (f plotterFunc) Add(measurement interface{}) { f(measurement) }
@@ -2,13 +2,20 @@ package cli
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/relab/hotstuff/internal/orchestration"
 	"github.com/relab/hotstuff/internal/profiling"
 	"github.com/relab/hotstuff/internal/protostream"
+	"github.com/relab/hotstuff/logging"
 	"github.com/relab/hotstuff/modules"
 	"github.com/spf13/cobra"
 )
@@ -32,7 +39,7 @@ var workerCmd = &cobra.Command{
 	Long: `Starts a worker that reads commands from stdin and writes responses to stdout.
 This is only intended to be used by a controller (hotstuff run).`,
 	Run: func(cmd *cobra.Command, args []string) {
-		runWorker()
+		os.Exit(runWorker())
 	},
 }
 
@@ -58,7 +65,8 @@ func init() {
 	workerCmd.Flags().DurationVar(&measurementInterval, "measurement-interval", 0, "the interval between measurements")
 }
 
-func runWorker() {
+// runWorker runs the worker and returns the exit status.
+func runWorker() int {
 	stopProfilers, err := profiling.StartProfilers(cpuProfile, memProfile, trace, fgprofProfile)
 	checkf("failed to start profilers: %v", err)
 	defer func() {
@@ -66,25 +74,121 @@ func runWorker() {
 		checkf("failed to stop profilers: %v", err)
 	}()
 
+	// the measurements of each run after a reset are written to a directory named after the run,
+	// next to the measurements before the first reset.
+	var files measurementFiles
+	if dataPath != "" {
+		files.dir = filepath.Dir(dataPath)
+	}
 	metricsLogger := modules.NopLogger()
 	if dataPath != "" {
-		f, err := os.OpenFile(dataPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		metricsLogger, err = files.open(dataPath)
 		checkf("failed to create data path: %v", err)
-		writer := bufio.NewWriter(f)
-		metricsLogger, err = modules.NewJSONLogger(writer)
-		defer func() {
-			err = metricsLogger.Close()
-			checkf("failed to close metrics logger: %v", err)
-			err = writer.Flush()
-			checkf("failed to flush writer: %v", err)
-			err = f.Close()
-			checkf("failed to close writer: %v", err)
-		}()
 	}
+	defer func() {
+		err = files.close()
+		checkf("failed to close measurements: %v", err)
+	}()
+
+	// the output of the loggers is kept for artifact collection, and is also written to stderr,
+	// which is forwarded to the controller.
+	logs := orchestration.NewLogBuffer(orchestration.DefaultLogBufferSize)
+	logging.SetCapture(logs)
+	log.SetOutput(io.MultiWriter(os.Stderr, logs))
+
+	// the worker shuts down gracefully when it is terminated, such that the final measurements are flushed.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(signals)
 
 	worker := orchestration.NewWorker(protostream.NewWriter(os.Stdout), protostream.NewReader(os.Stdin), metricsLogger, metrics, measurementInterval)
-	err = worker.Run()
+	worker.SetLogBuffer(logs)
+	files.worker = &worker
+	if dataPath != "" {
+		worker.AddArtifact("measurements.json", dataPath)
+	}
+	worker.SetResetHandler(files.reset)
+	done := make(chan error, 1)
+	go func() { done <- worker.Run() }()
+	var runErr error
+	select {
+	case runErr = <-done:
+	case sig := <-signals:
+		log.Printf("Received %v, shutting down", sig)
+		runErr = worker.Shutdown()
+	}
+	if runErr != nil {
+		log.Println(runErr)
+		return 1
+	}
+	return 0
+}
+
+// measurementFiles creates the files that a worker writes its measurements to, one for each run.
+// The current file is collected as an artifact of the worker.
+type measurementFiles struct {
+	worker *orchestration.Worker
+	dir    string // the directory that contains a directory for each run, or empty if measurements are discarded
+
+	file   *os.File
+	wr     *bufio.Writer
+	logger modules.MetricsLogger
+}
+
+// open closes the current file, and returns a metrics logger that writes to a new file at the given path.
+func (m *measurementFiles) open(path string) (modules.MetricsLogger, error) {
+	if err := m.close(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	wr := bufio.NewWriter(f)
+	logger, err := modules.NewJSONLogger(wr)
 	if err != nil {
-		log.Println(err)
+		f.Close()
+		return nil, fmt.Errorf("failed to create JSON logger: %w", err)
+	}
+	m.file, m.wr, m.logger = f, wr, logger
+	if m.worker != nil {
+		m.worker.AddArtifact("measurements.json", path)
+	}
+	return logger, nil
+}
+
+// reset is the reset handler of the worker. The measurements of the run are written to measurements.json in
+// the directory of the run.
+func (m *measurementFiles) reset(run string) (modules.MetricsLogger, error) {
+	if m.dir == "" {
+		return modules.NopLogger(), nil
+	}
+	dir := filepath.Join(m.dir, filepath.FromSlash(run))
+	if rel, err := filepath.Rel(m.dir, dir); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("invalid run name: '%s'", run)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+	return m.open(filepath.Join(dir, "measurements.json"))
+}
+
+// close closes the metrics logger and the current file, if any.
+func (m *measurementFiles) close() (err error) {
+	if m.file == nil {
+		return nil
+	}
+	defer func() { m.file, m.wr, m.logger = nil, nil, nil }()
+	if err = m.logger.Close(); err != nil {
+		m.file.Close()
+		return fmt.Errorf("failed to close metrics logger: %w", err)
+	}
+	if err = m.wr.Flush(); err != nil {
+		m.file.Close()
+		return fmt.Errorf("failed to flush writer: %w", err)
+	}
+	if err = m.file.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %w", err)
 	}
+	return nil
 }
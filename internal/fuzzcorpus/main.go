@@ -0,0 +1,292 @@
+// Fuzzcorpus records the seed corpora of the fuzz targets of the hotstuffpb and protostream packages from live runs.
+//
+// It simulates the consensus protocols with each crypto implementation, and records the messages that the replicas
+// send, converted to the wire format. Then it runs a short experiment with a worker in the same process, and records
+// the orchestration messages that are sent between the controller and the worker. The seeds replace those that were
+// recorded before, while the inputs that the fuzzer has added to the corpora are kept.
+//
+// It must be run from the root of the module:
+//
+//	go run ./internal/fuzzcorpus
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/orchestration"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
+	"github.com/relab/hotstuff/internal/protostream"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/simulation"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	hotstuffpbCorpus  = "internal/proto/hotstuffpb/testdata/fuzz"
+	protostreamCorpus = "internal/protostream/testdata/fuzz"
+	seedPrefix        = "seed-"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("fuzzcorpus: ")
+	seeds := flag.Int("seeds", 4, "the number of seeds to record for each fuzz target from each run")
+	flag.Parse()
+
+	runs := []struct{ consensus, crypto string }{
+		{"chainedhotstuff", "ecdsa"},
+		{"fasthotstuff", "bls12"},
+		{"simplehotstuff", "ecdsa"},
+	}
+	corpora := make(map[string]*corpus)
+	for i, run := range runs {
+		c := newCorpus()
+		if err := simulate(c, run.consensus, run.crypto, int64(i)); err != nil {
+			log.Fatalf("failed to simulate %s with %s: %v", run.consensus, run.crypto, err)
+		}
+		c.sample(*seeds)
+		merge(corpora, c, hotstuffpbCorpus)
+	}
+
+	c := newCorpus()
+	if err := experiment(c); err != nil {
+		log.Fatalf("failed to run experiment: %v", err)
+	}
+	c.sample(2 * *seeds)
+	merge(corpora, c, protostreamCorpus)
+
+	for dir, c := range corpora {
+		if err := c.write(dir); err != nil {
+			log.Fatalf("failed to write corpus: %v", err)
+		}
+	}
+}
+
+// corpus holds the encoded messages of each fuzz target, in the order they were recorded.
+type corpus struct {
+	seeds map[string][][]byte
+	seen  map[string]bool
+}
+
+func newCorpus() *corpus {
+	return &corpus{seeds: make(map[string][][]byte), seen: make(map[string]bool)}
+}
+
+// add adds the encoding of the message to the seeds of the fuzz target, unless it has been added before.
+func (c *corpus) add(target string, msg proto.Message) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		log.Fatalf("failed to marshal %T: %v", msg, err)
+	}
+	c.addBytes(target, b)
+}
+
+func (c *corpus) addBytes(target string, b []byte) {
+	key := target + "/" + string(b)
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	c.seeds[target] = append(c.seeds[target], b)
+}
+
+// sample keeps n seeds of each fuzz target that are evenly spread over the run, such that the seeds include both
+// the messages from the start of the run and those that carry the certificates of later views.
+func (c *corpus) sample(n int) {
+	for target, seeds := range c.seeds {
+		if len(seeds) <= n {
+			continue
+		}
+		sampled := make([][]byte, n)
+		for i := range sampled {
+			sampled[i] = seeds[i*len(seeds)/n]
+		}
+		c.seeds[target] = sampled
+	}
+}
+
+// merge adds the seeds of c to the corpus of the directory.
+func merge(corpora map[string]*corpus, c *corpus, dir string) {
+	if corpora[dir] == nil {
+		corpora[dir] = newCorpus()
+	}
+	for target, seeds := range c.seeds {
+		for _, b := range seeds {
+			corpora[dir].addBytes(target, b)
+		}
+	}
+}
+
+// write replaces the seeds in the directory with those of the corpus,
+// in the file format of the corpora of Go's fuzzing engine.
+func (c *corpus) write(dir string) error {
+	for target, seeds := range c.seeds {
+		targetDir := filepath.Join(dir, target)
+		if err := os.MkdirAll(targetDir, 0o755); err != nil {
+			return err
+		}
+		old, err := filepath.Glob(filepath.Join(targetDir, seedPrefix+"*"))
+		if err != nil {
+			return err
+		}
+		for _, path := range old {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+		for _, b := range seeds {
+			name := fmt.Sprintf("%s%x", seedPrefix, sha256.Sum256(b))[:len(seedPrefix)+16]
+			data := fmt.Sprintf("go test fuzz v1\n[]byte(%q)\n", b)
+			if err := os.WriteFile(filepath.Join(targetDir, name), []byte(data), 0o644); err != nil {
+				return err
+			}
+		}
+		log.Printf("wrote %d seeds to %s", len(seeds), targetDir)
+	}
+	return nil
+}
+
+// simulate records the messages of a simulated protocol run, in which a replica crashes such that the views that it
+// leads time out, and the replicas exchange timeout messages and timeout certificates.
+func simulate(c *corpus, consensusName, crypto string, seed int64) error {
+	sim, err := simulation.New(simulation.Config{
+		Replicas:  4,
+		Consensus: consensusName,
+		Crypto:    crypto,
+		Latency:   simulation.UniformLatency(5*time.Millisecond, 15*time.Millisecond),
+		Loss:      0.01,
+		Seed:      seed,
+		Tap: func(_, _ hotstuff.ID, msg interface{}) {
+			record(c, msg)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	sim.At(5*time.Second, func() { sim.Crash(4) })
+	return sim.Run(10 * time.Second)
+}
+
+// record adds the messages that a replica sends, and the certificates that they carry, to the corpus.
+func record(c *corpus, msg interface{}) {
+	switch m := msg.(type) {
+	case consensus.ProposeMsg:
+		p := hotstuffpb.ProposalToProto(m)
+		c.add("FuzzProposal", p)
+		c.add("FuzzBlock", p.GetBlock())
+		c.add("FuzzQuorumCert", p.GetBlock().GetQC())
+		if p.GetAggQC() != nil {
+			c.add("FuzzAggregateQC", p.GetAggQC())
+		}
+	case consensus.VoteMsg:
+		c.add("FuzzPartialCert", hotstuffpb.PartialCertToProto(m.PartialCert))
+	case consensus.TimeoutMsg:
+		tm := hotstuffpb.TimeoutMsgToProto(m)
+		c.add("FuzzTimeoutMsg", tm)
+		recordSyncInfo(c, tm.GetSyncInfo())
+	case consensus.NewViewMsg:
+		recordSyncInfo(c, hotstuffpb.SyncInfoToProto(m.SyncInfo))
+	}
+}
+
+func recordSyncInfo(c *corpus, si *hotstuffpb.SyncInfo) {
+	c.add("FuzzSyncInfo", si)
+	if si.GetQC() != nil {
+		c.add("FuzzQuorumCert", si.GetQC())
+	}
+	if si.GetTC() != nil {
+		c.add("FuzzTimeoutCert", si.GetTC())
+	}
+	if si.GetAggQC() != nil {
+		c.add("FuzzAggregateQC", si.GetAggQC())
+	}
+}
+
+// experiment records the orchestration messages of a short experiment with a worker that runs in the same process.
+func experiment(c *corpus) error {
+	controllerStream, workerStream := net.Pipe()
+	var requests, responses bytes.Buffer
+
+	workerProxy := orchestration.NewRemoteWorker(
+		protostream.NewWriter(controllerStream),
+		protostream.NewReader(io.TeeReader(controllerStream, &responses)),
+	)
+	worker := orchestration.NewWorker(
+		protostream.NewWriter(workerStream),
+		protostream.NewReader(io.TeeReader(workerStream, &requests)),
+		modules.NopLogger(), nil, 0,
+	)
+
+	e := &orchestration.Experiment{
+		Logger:      logging.New("ctrl"),
+		NumReplicas: 4,
+		NumClients:  1,
+		ClientOpts: &orchestrationpb.ClientOpts{
+			ConnectTimeout: durationpb.New(time.Second),
+			MaxConcurrent:  100,
+			PayloadSize:    100,
+		},
+		ReplicaOpts: &orchestrationpb.ReplicaOpts{
+			BatchSize:         100,
+			ConnectTimeout:    durationpb.New(time.Second),
+			InitialTimeout:    durationpb.New(100 * time.Millisecond),
+			TimeoutSamples:    1000,
+			TimeoutMultiplier: 1.2,
+			Consensus:         "chainedhotstuff",
+			Crypto:            "ecdsa",
+			LeaderRotation:    "round-robin",
+		},
+		Duration: time.Second,
+		Hosts:    map[string]orchestration.RemoteWorker{"127.0.0.1": workerProxy},
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- worker.Run()
+	}()
+	if err := e.Run(); err != nil {
+		return err
+	}
+	if err := <-done; err != nil {
+		return err
+	}
+
+	for _, stream := range []*bytes.Buffer{&requests, &responses} {
+		if err := splitMessages(c, stream.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitMessages adds each of the length-prefixed messages of a protostream to the corpus.
+func splitMessages(c *corpus, stream []byte) error {
+	for len(stream) > 0 {
+		if len(stream) < 4 {
+			return fmt.Errorf("truncated message length: %d bytes", len(stream))
+		}
+		n := 4 + int(binary.LittleEndian.Uint32(stream))
+		if len(stream) < n {
+			return fmt.Errorf("truncated message: %d of %d bytes", len(stream), n)
+		}
+		// the seeds are whole messages, including the length prefix, that a reader can read from a stream.
+		c.addBytes("FuzzReader", stream[:n])
+		stream = stream[n:]
+	}
+	return nil
+}
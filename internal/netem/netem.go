@@ -0,0 +1,203 @@
+// Package netem emulates wide area network links between hosts on a local network, by delaying and dropping the
+// packets sent to other hosts with the netem queueing discipline of the Linux traffic control command, tc.
+package netem
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// capNetAdmin is the bit of the CAP_NET_ADMIN capability, which is required to change the queueing disciplines.
+const capNetAdmin = 12
+
+// rate is the rate of the traffic classes, which is high enough that the traffic is not shaped.
+const rate = "100gbit"
+
+// Link is the emulated link to a destination address.
+type Link struct {
+	Addr    net.IP
+	Latency time.Duration // the one-way delay of the packets sent to the address
+	Jitter  time.Duration // the maximum random variation of the delay
+	Loss    float64       // the percentage of the packets that are dropped
+}
+
+func (l Link) emulated() bool {
+	return l.Latency > 0 || l.Jitter > 0 || l.Loss > 0
+}
+
+// Commands returns the tc commands that emulate the links on the network interface. The packets sent to each
+// destination address are sorted into a traffic class of their own, which delays and drops them, while the packets
+// sent to other addresses are not affected. The commands replace the root queueing discipline of the interface,
+// which is restored by ClearCommand.
+func Commands(iface string, links []Link) [][]string {
+	cmds := [][]string{
+		{"tc", "qdisc", "add", "dev", iface, "root", "handle", "1:", "htb", "default", "1"},
+		{"tc", "class", "add", "dev", iface, "parent", "1:", "classid", "1:1", "htb", "rate", rate},
+	}
+	class := 1
+	for _, link := range links {
+		if !link.emulated() {
+			continue
+		}
+		class++
+		classID := fmt.Sprintf("1:%x", class)
+		netem := []string{"tc", "qdisc", "add", "dev", iface, "parent", classID, "handle", fmt.Sprintf("%x:", class+0xf), "netem"}
+		if link.Latency > 0 || link.Jitter > 0 {
+			netem = append(netem, "delay", formatDuration(link.Latency))
+			if link.Jitter > 0 {
+				netem = append(netem, formatDuration(link.Jitter))
+			}
+		}
+		if link.Loss > 0 {
+			netem = append(netem, "loss", strconv.FormatFloat(link.Loss, 'f', -1, 64)+"%")
+		}
+		protocol, match, prefix := "ip", "ip", "/32"
+		if link.Addr.To4() == nil {
+			protocol, match, prefix = "ipv6", "ip6", "/128"
+		}
+		cmds = append(cmds,
+			[]string{"tc", "class", "add", "dev", iface, "parent", "1:", "classid", classID, "htb", "rate", rate},
+			netem,
+			[]string{"tc", "filter", "add", "dev", iface, "parent", "1:", "protocol", protocol, "prio", "1",
+				"u32", "match", match, "dst", link.Addr.String() + prefix, "flowid", classID},
+		)
+	}
+	return cmds
+}
+
+// ClearCommand returns the tc command that removes the emulated links from the network interface.
+func ClearCommand(iface string) []string {
+	return []string{"tc", "qdisc", "del", "dev", iface, "root"}
+}
+
+// formatDuration formats a duration in microseconds, which is the resolution of tc.
+func formatDuration(d time.Duration) string {
+	return strconv.FormatInt(d.Microseconds(), 10) + "us"
+}
+
+// CheckCapability returns an error if the process cannot change the queueing disciplines of the network interfaces,
+// either because the tc command is missing, or because the process does not have the CAP_NET_ADMIN capability.
+func CheckCapability() error {
+	if _, err := exec.LookPath("tc"); err != nil {
+		return fmt.Errorf("network emulation requires the tc command: %w", err)
+	}
+	f, err := os.Open("/proc/self/status")
+	if err != nil {
+		return fmt.Errorf("network emulation is only supported on Linux: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "CapEff:") {
+			continue
+		}
+		caps, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "CapEff:")), 16, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse the capabilities of the process: %w", err)
+		}
+		if caps&(1<<capNetAdmin) == 0 {
+			return fmt.Errorf("network emulation requires root or the CAP_NET_ADMIN capability")
+		}
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read the capabilities of the process: %w", err)
+	}
+	return fmt.Errorf("failed to read the capabilities of the process")
+}
+
+// Apply emulates the links on the network interface. The emulated links are removed again if they cannot all be
+// applied.
+func Apply(iface string, links []Link) error {
+	if err := CheckCapability(); err != nil {
+		return err
+	}
+	for _, cmd := range Commands(iface, links) {
+		if err := run(cmd); err != nil {
+			_ = run(ClearCommand(iface))
+			return err
+		}
+	}
+	return nil
+}
+
+// Clear removes the emulated links from the network interface.
+func Clear(iface string) error {
+	return run(ClearCommand(iface))
+}
+
+func run(cmd []string) error {
+	out, err := exec.Command(cmd[0], cmd[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("'%s' failed: %w: %s", strings.Join(cmd, " "), err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// Interface returns the name of the network interface that the packets sent to the address are routed through.
+func Interface(addr net.IP) (string, error) {
+	// connecting a UDP socket selects the route to the address without sending any packets.
+	conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: addr, Port: 9})
+	if err != nil {
+		return "", fmt.Errorf("no route to %s: %w", addr, err)
+	}
+	local := conn.LocalAddr().(*net.UDPAddr).IP
+	conn.Close()
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", err
+	}
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			if ipNet, ok := a.(*net.IPNet); ok && ipNet.IP.Equal(local) {
+				return iface.Name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no interface has the address %s", local)
+}
+
+// Ping measures the average round-trip time to the address with the ping command.
+func Ping(addr net.IP, count int) (time.Duration, error) {
+	out, err := exec.Command("ping", "-c", strconv.Itoa(count), "-q", addr.String()).CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("ping %s failed: %w: %s", addr, err, bytes.TrimSpace(out))
+	}
+	return ParsePing(string(out))
+}
+
+// ParsePing returns the average round-trip time in the summary printed by the ping command,
+// such as "rtt min/avg/max/mdev = 40.112/40.250/40.391/0.114 ms".
+func ParsePing(out string) (time.Duration, error) {
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.Contains(line, "min/avg/max") {
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i < 0 {
+			break
+		}
+		values := strings.Split(strings.TrimSpace(line[i+1:]), "/")
+		if len(values) < 2 {
+			break
+		}
+		avg, err := strconv.ParseFloat(values[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid round-trip time: %w", err)
+		}
+		return time.Duration(avg * float64(time.Millisecond)), nil
+	}
+	return 0, fmt.Errorf("no round-trip times in the output of ping")
+}
@@ -0,0 +1,67 @@
+package netem_test
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relab/hotstuff/internal/netem"
+)
+
+func TestCommands(t *testing.T) {
+	// the links of the first host of a three-host matrix, and a link to an IPv6 address.
+	links := []netem.Link{
+		{Addr: net.ParseIP("10.0.0.2"), Latency: 20 * time.Millisecond},
+		{Addr: net.ParseIP("10.0.0.3"), Latency: 45500 * time.Microsecond, Jitter: 2 * time.Millisecond, Loss: 0.5},
+		{Addr: net.ParseIP("10.0.0.4")}, // not emulated
+		{Addr: net.ParseIP("fd00::5"), Loss: 1},
+	}
+	want := []string{
+		"tc qdisc add dev eth0 root handle 1: htb default 1",
+		"tc class add dev eth0 parent 1: classid 1:1 htb rate 100gbit",
+		"tc class add dev eth0 parent 1: classid 1:2 htb rate 100gbit",
+		"tc qdisc add dev eth0 parent 1:2 handle 11: netem delay 20000us",
+		"tc filter add dev eth0 parent 1: protocol ip prio 1 u32 match ip dst 10.0.0.2/32 flowid 1:2",
+		"tc class add dev eth0 parent 1: classid 1:3 htb rate 100gbit",
+		"tc qdisc add dev eth0 parent 1:3 handle 12: netem delay 45500us 2000us loss 0.5%",
+		"tc filter add dev eth0 parent 1: protocol ip prio 1 u32 match ip dst 10.0.0.3/32 flowid 1:3",
+		"tc class add dev eth0 parent 1: classid 1:4 htb rate 100gbit",
+		"tc qdisc add dev eth0 parent 1:4 handle 13: netem loss 1%",
+		"tc filter add dev eth0 parent 1: protocol ipv6 prio 1 u32 match ip6 dst fd00::5/128 flowid 1:4",
+	}
+	var got []string
+	for _, cmd := range netem.Commands("eth0", links) {
+		got = append(got, strings.Join(cmd, " "))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got commands:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if got := strings.Join(netem.ClearCommand("eth0"), " "); got != "tc qdisc del dev eth0 root" {
+		t.Errorf("got clear command '%s'", got)
+	}
+}
+
+func TestParsePing(t *testing.T) {
+	outputs := map[string]time.Duration{
+		// iputils
+		"--- 10.0.0.2 ping statistics ---\n3 packets transmitted, 3 received, 0% packet loss, time 2003ms\n" +
+			"rtt min/avg/max/mdev = 40.112/40.250/40.391/0.114 ms\n": 40250 * time.Microsecond,
+		// busybox
+		"--- 10.0.0.2 ping statistics ---\n3 packets transmitted, 3 packets received, 0% packet loss\n" +
+			"round-trip min/avg/max = 0.061/0.075/0.090 ms\n": 75 * time.Microsecond,
+	}
+	for out, want := range outputs {
+		got, err := netem.ParsePing(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+	if _, err := netem.ParsePing("100% packet loss"); err == nil {
+		t.Error("expected an error for output without round-trip times")
+	}
+}
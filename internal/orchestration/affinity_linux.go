@@ -0,0 +1,69 @@
+package orchestration
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// cpuMaskWords is the number of words of the CPU masks, which covers the CPUs that the kernel supports by default.
+const cpuMaskWords = 1024 / 64
+
+type cpuMask [cpuMaskWords]uint64
+
+// getAffinity returns the CPUs that the calling thread may run on.
+func getAffinity() ([]uint32, error) {
+	var mask cpuMask
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY, 0, unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask)))
+	if errno != 0 {
+		return nil, fmt.Errorf("sched_getaffinity: %w", errno)
+	}
+	var cpus []uint32
+	for cpu := uint32(0); cpu < cpuMaskWords*64; cpu++ {
+		if mask[cpu/64]&(1<<(cpu%64)) != 0 {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+// setAffinity pins the threads of the process to the CPUs. The threads that the runtime starts later inherit the
+// affinity of the thread that starts them, so the threads are pinned again until no new threads are found.
+func setAffinity(cpus []uint32) error {
+	var mask cpuMask
+	for _, cpu := range cpus {
+		if cpu >= cpuMaskWords*64 {
+			return fmt.Errorf("CPU %d is out of range", cpu)
+		}
+		mask[cpu/64] |= 1 << (cpu % 64)
+	}
+	pinned := make(map[string]bool)
+	for {
+		tasks, err := os.ReadDir("/proc/self/task")
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, task := range tasks {
+			if pinned[task.Name()] {
+				continue
+			}
+			found = true
+			tid, err := strconv.Atoi(task.Name())
+			if err != nil {
+				continue
+			}
+			_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, uintptr(tid), unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask)))
+			// the thread may have exited since the directory was read.
+			if errno != 0 && errno != syscall.ESRCH {
+				return fmt.Errorf("sched_setaffinity: %w", errno)
+			}
+			pinned[task.Name()] = true
+		}
+		if !found {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package orchestration
+
+import "fmt"
+
+var errAffinityUnsupported = fmt.Errorf("pinning to CPUs is only supported on Linux")
+
+func getAffinity() ([]uint32, error) {
+	return nil, errAffinityUnsupported
+}
+
+func setAffinity(_ []uint32) error {
+	return errAffinityUnsupported
+}
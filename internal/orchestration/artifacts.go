@@ -0,0 +1,287 @@
+package orchestration
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+
+	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/modules"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultMaxArtifactSize is the default maximum number of bytes of each artifact that is collected from a worker.
+	DefaultMaxArtifactSize = 64 << 20
+	// DefaultLogBufferSize is the default number of bytes of log output that a worker keeps for artifact collection.
+	DefaultLogBufferSize = 4 << 20
+
+	// artifactChunkSize is the maximum number of bytes of an artifact that is sent in a single message.
+	artifactChunkSize = 64 << 10
+	// logArtifact is the name of the artifact containing the log of a worker.
+	logArtifact = "worker.log"
+)
+
+// LogBuffer holds the most recent log output of a worker, up to a maximum size.
+// The oldest output is discarded when the buffer is full.
+type LogBuffer struct {
+	mut  sync.Mutex
+	size int
+	buf  []byte
+}
+
+// NewLogBuffer returns a log buffer that holds at most size bytes.
+func NewLogBuffer(size int) *LogBuffer {
+	return &LogBuffer{size: size}
+}
+
+// Write appends p to the buffer, discarding the oldest output if the buffer is full.
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	b.buf = append(b.buf, p...)
+	if excess := len(b.buf) - b.size; excess > 0 {
+		b.buf = append(b.buf[:0], b.buf[excess:]...)
+	}
+	return len(p), nil
+}
+
+// Bytes returns a copy of the contents of the buffer.
+func (b *LogBuffer) Bytes() []byte {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	return append([]byte(nil), b.buf...)
+}
+
+// Reset discards the contents of the buffer.
+func (b *LogBuffer) Reset() {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	b.buf = b.buf[:0]
+}
+
+// SetLogBuffer sets the buffer whose contents are sent to the controller as the log of the worker
+// when the artifacts of the worker are collected.
+func (w *Worker) SetLogBuffer(logs *LogBuffer) {
+	w.logs = logs
+}
+
+// AddArtifact adds a file that is sent to the controller with the given name when the artifacts of the worker
+// are collected, such as the file that the metrics logger of the worker writes to.
+func (w *Worker) AddArtifact(name, path string) {
+	w.artifacts[name] = path
+}
+
+func (w *Worker) startProfile(req *orchestrationpb.StartProfileRequest) (*orchestrationpb.StartProfileResponse, error) {
+	if req.GetCPU() {
+		if w.cpuProfile != nil {
+			return nil, fmt.Errorf("a CPU profile is already running")
+		}
+		profile := new(bytes.Buffer)
+		if err := pprof.StartCPUProfile(profile); err != nil {
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		w.cpuProfile = profile
+	}
+	w.heapProfile = req.GetHeap()
+	return &orchestrationpb.StartProfileResponse{}, nil
+}
+
+// collectArtifacts sends the profiles, the files added by AddArtifact, and the log of the worker to the controller.
+// The metrics logger is closed first, such that the measurement files are complete.
+func (w *Worker) collectArtifacts(req *orchestrationpb.CollectArtifactsRequest) (*orchestrationpb.CollectArtifactsResponse, error) {
+	maxSize := req.GetMaxSize()
+	if maxSize == 0 {
+		maxSize = DefaultMaxArtifactSize
+	}
+
+	if w.cpuProfile != nil {
+		pprof.StopCPUProfile()
+		profile := w.cpuProfile
+		w.cpuProfile = nil
+		if err := w.sendArtifact("cpu.pprof", profile, maxSize); err != nil {
+			return nil, err
+		}
+	}
+	if w.heapProfile {
+		w.heapProfile = false
+		var profile bytes.Buffer
+		runtime.GC() // get up-to-date statistics
+		if err := pprof.WriteHeapProfile(&profile); err != nil {
+			return nil, fmt.Errorf("failed to write heap profile: %w", err)
+		}
+		if err := w.sendArtifact("heap.pprof", &profile, maxSize); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(w.artifacts) > 0 {
+		if err := w.metricsLogger.Close(); err != nil {
+			return nil, fmt.Errorf("failed to close metrics logger: %w", err)
+		}
+		if f, ok := w.metricsLogger.(modules.Flusher); ok {
+			if err := f.Flush(); err != nil {
+				return nil, fmt.Errorf("failed to flush metrics logger: %w", err)
+			}
+		}
+	}
+	names := make([]string, 0, len(w.artifacts))
+	for name := range w.artifacts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := w.sendFile(name, w.artifacts[name], maxSize); err != nil {
+			return nil, err
+		}
+	}
+
+	// the log is sent last, such that it includes any errors logged while collecting the other artifacts.
+	if w.logs != nil {
+		if err := w.sendArtifact(logArtifact, bytes.NewReader(w.logs.Bytes()), maxSize); err != nil {
+			return nil, err
+		}
+	}
+	return &orchestrationpb.CollectArtifactsResponse{}, nil
+}
+
+func (w *Worker) sendFile(name, path string, maxSize uint64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open artifact '%s': %w", name, err)
+	}
+	defer f.Close()
+	return w.sendArtifact(name, f, maxSize)
+}
+
+// sendArtifact sends at most maxSize bytes read from r to the controller in chunks.
+func (w *Worker) sendArtifact(name string, r io.Reader, maxSize uint64) error {
+	limited := &io.LimitedReader{R: r, N: int64(maxSize)}
+	buf := make([]byte, artifactChunkSize)
+	for sent := false; ; sent = true {
+		n, err := io.ReadFull(limited, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("failed to read artifact '%s': %w", name, err)
+		}
+		if n > 0 || !sent {
+			if werr := w.send.WriteAny(&orchestrationpb.ArtifactChunk{Name: name, Data: buf[:n]}); werr != nil {
+				return werr
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if limited.N > 0 {
+		return nil
+	}
+	// the limit was reached, so we check if anything was left out.
+	if n, _ := r.Read(make([]byte, 1)); n > 0 {
+		return w.send.WriteAny(&orchestrationpb.ArtifactChunk{Name: name, Truncated: true})
+	}
+	return nil
+}
+
+// artifactWriter stores the artifacts received from a worker in a directory.
+type artifactWriter struct {
+	logger  logging.Logger
+	host    string
+	dir     string
+	maxSize uint64
+
+	name    string
+	file    *os.File
+	written uint64
+}
+
+func (a *artifactWriter) write(chunk *orchestrationpb.ArtifactChunk) error {
+	name := chunk.GetName()
+	if chunk.GetTruncated() {
+		a.logger.Warnf("Artifact '%s' from %s was truncated to %d bytes", name, a.host, a.written)
+		return nil
+	}
+	if name != a.name || a.file == nil {
+		if err := a.close(); err != nil {
+			return err
+		}
+		// the names are chosen by the worker, so they must not refer to files outside of the directory.
+		if name == "" || name == "." || name == ".." || filepath.Base(name) != name {
+			return fmt.Errorf("invalid artifact name from %s: '%s'", a.host, name)
+		}
+		f, err := os.Create(filepath.Join(a.dir, name))
+		if err != nil {
+			return fmt.Errorf("failed to create artifact file: %w", err)
+		}
+		a.name, a.file, a.written = name, f, 0
+	}
+	a.written += uint64(len(chunk.GetData()))
+	if a.written > a.maxSize {
+		return fmt.Errorf("artifact '%s' from %s exceeds %d bytes", name, a.host, a.maxSize)
+	}
+	if _, err := a.file.Write(chunk.GetData()); err != nil {
+		return fmt.Errorf("failed to write artifact file: %w", err)
+	}
+	return nil
+}
+
+// close closes the file of the current artifact.
+func (a *artifactWriter) close() error {
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+// startProfiles asks the workers to start the profiles that are collected as artifacts.
+func (e *Experiment) startProfiles() error {
+	if !e.CPUProfile && !e.HeapProfile {
+		return nil
+	}
+	for host := range e.workers() {
+		_, err := e.call(host, StartPhase, e.Timeouts.Start, func(w RemoteWorker) (proto.Message, error) {
+			return w.StartProfile(&orchestrationpb.StartProfileRequest{CPU: e.CPUProfile, Heap: e.HeapProfile})
+		})
+		if err != nil && !e.hasFailed(host) {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectArtifacts stores the artifacts of each worker in a directory named after its host in ArtifactDir.
+func (e *Experiment) collectArtifacts() error {
+	maxSize := e.MaxArtifactSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxArtifactSize
+	}
+	for host := range e.workers() {
+		dir := filepath.Join(e.ArtifactDir, host)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create artifact directory: %w", err)
+		}
+		wr := &artifactWriter{logger: e.Logger, host: host, dir: dir, maxSize: maxSize}
+		_, err := e.call(host, CollectPhase, e.Timeouts.Collect, func(w RemoteWorker) (proto.Message, error) {
+			return w.CollectArtifacts(&orchestrationpb.CollectArtifactsRequest{MaxSize: maxSize}, wr.write)
+		})
+		if e.hasFailed(host) {
+			// the artifact writer may still be in use by the abandoned request.
+			continue
+		}
+		if cerr := wr.close(); err == nil && cerr != nil {
+			err = fmt.Errorf("failed to close artifact file: %w", cerr)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to collect artifacts from %s: %w", host, err)
+		}
+	}
+	return nil
+}
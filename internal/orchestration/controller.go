@@ -7,14 +7,22 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/consensus/byzantine"
 	"github.com/relab/hotstuff/crypto/keygen"
 	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
 	"github.com/relab/hotstuff/logging"
 	"go.uber.org/multierr"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 // HostConfig specifies the number of replicas and clients that should be started on a specific host.
@@ -36,7 +44,82 @@ type Experiment struct {
 
 	Hosts       map[string]RemoteWorker
 	HostConfigs map[string]HostConfig
-	Byzantine   map[string]int // number of replicas to assign to each byzantine strategy
+	// HostAddresses are the addresses that the replicas on some of the hosts are reached at by the replicas and
+	// clients on the other hosts, such as the address of the controller for a worker that runs in its process.
+	// The replicas on the other hosts are reached at the names of their hosts.
+	HostAddresses map[string]string
+	Byzantine     map[string]int // number of replicas to assign to each byzantine strategy
+
+	// ByzantineReplicas assigns byzantine strategies to specific replicas.
+	// The replicas counted by Byzantine are assigned among the remaining replicas.
+	ByzantineReplicas map[hotstuff.ID]string
+
+	// ReplicaOverrides replaces the options of specific replicas, such as the consensus rules or the size of the
+	// crypto cache. The fields that are set in the overrides of a replica are merged into a copy of ReplicaOpts,
+	// such that the other options of the replica are the global ones.
+	ReplicaOverrides map[hotstuff.ID]*orchestrationpb.ReplicaOpts
+
+	// Faults are injected into the replicas at the times given by their start offsets from the start of the replicas.
+	// The faults of each replica must fit within the duration of the experiment, and apply to the state that the
+	// replica is in at the time, such that only a crashed replica can be restarted.
+	Faults map[hotstuff.ID][]*orchestrationpb.Fault
+
+	// ReplicaResources and ClientResources limit the CPUs that specific replicas and clients run on, such that the
+	// replicas and clients that share a machine do not distort each other's results. The clients are identified by
+	// the ID of their first session. The workers combine the limits of the replicas and clients that they run, as
+	// they run in the process of the worker, and record the limits applied in the start events.
+	ReplicaResources map[hotstuff.ID]*orchestrationpb.Resources
+	ClientResources  map[hotstuff.ID]*orchestrationpb.Resources
+
+	// Emulation optionally emulates the network links between the hosts with tc netem.
+	Emulation *NetworkEmulation
+
+	// LatencyMatrix optionally specifies the emulated one-way latency between replicas,
+	// such that LatencyMatrix[i][j] is the latency from replica i+1 to replica j+1.
+	LatencyMatrix [][]time.Duration
+
+	// Trace is the workload trace that the clients replay in the trace load mode.
+	Trace []byte
+
+	// ArtifactDir is the directory that the artifacts of the workers are collected to when the replicas have stopped,
+	// in a directory for each host. The artifacts are the logs, profiles, and measurement files of the workers.
+	// Artifacts are not collected if it is empty.
+	ArtifactDir string
+	// MaxArtifactSize is the maximum number of bytes of each artifact. Larger artifacts are truncated.
+	// DefaultMaxArtifactSize is used if it is zero.
+	MaxArtifactSize uint64
+	// CPUProfile and HeapProfile determine whether the workers profile the run, such that the profiles are
+	// collected as artifacts.
+	CPUProfile  bool
+	HeapProfile bool
+
+	// ProgressInterval is how often the progress of the replicas and clients is requested from the workers and
+	// logged while the experiment runs. Progress is not reported if it is zero.
+	ProgressInterval time.Duration
+	// StallTimeout aborts the experiment if no commands are committed for this long, such as when a misconfiguration
+	// deadlocks the replicas. The clients and replicas are then stopped, and the artifacts collected, as usual,
+	// before Run returns an error. It requires ProgressInterval, and is disabled if zero.
+	StallTimeout time.Duration
+
+	// Timeouts are the deadlines of the phases of the experiment. A worker that misses a deadline is marked as failed,
+	// and the experiment continues with the other workers.
+	Timeouts Timeouts
+
+	// KeepWorkers leaves the workers running when the experiment has finished, such that they can be reset and used
+	// for another run, such as the next run of a sweep, instead of being redeployed. The caller must shut them down.
+	KeepWorkers bool
+
+	// Seed is the master seed of the experiment. The seeds of the replicas and clients, such as those of the latency
+	// emulation, the gossip layer, the leader rotation, the payloads, and the arrival times, are derived from it,
+	// and recorded in their start events, such that runs with the same seed make the same random choices.
+	// The shared seed and payload seed are only derived if they are not set in ReplicaOpts and ClientOpts.
+	// A seed based on the current time is chosen, and logged, if it is zero.
+	Seed int64
+
+	// Config is the resolved configuration of the experiment, such as the experiment file with the flags applied.
+	// It is recorded verbatim in the start events of the replicas and clients, such that the measurements are
+	// self-describing.
+	Config string
 
 	// the host associated with each replica.
 	hostsToReplicas map[string][]hotstuff.ID
@@ -45,22 +128,55 @@ type Experiment struct {
 	hostsToClients map[string][]hotstuff.ID
 	caKey          *ecdsa.PrivateKey
 	ca             *x509.Certificate
+
+	mut     sync.Mutex
+	failed  map[string]hostFailure       // the workers that have failed, by host
+	pending map[string]<-chan callResult // the progress requests that have not been answered in time, by host
+	retries map[string]int               // the number of times the progress of each host has been awaited again
 }
 
-// Run runs the experiment.
+// Run runs the experiment. If some of the workers fail, the experiment is completed with the other workers,
+// and an error wrapping ErrMissingData is returned.
 func (e *Experiment) Run() (err error) {
+	e.failed = make(map[string]hostFailure)
+	e.pending = make(map[string]<-chan callResult)
+	e.retries = make(map[string]int)
 	defer func() {
+		if e.KeepWorkers {
+			return
+		}
 		qerr := e.quit()
 		if err == nil {
 			err = qerr
 		}
 	}()
 
+	err = e.Validate()
+	if err != nil {
+		return err
+	}
+
+	e.initSeed()
 	err = e.assignReplicasAndClients()
 	if err != nil {
 		return err
 	}
 
+	if e.Emulation != nil {
+		// the links are removed even if the experiment fails.
+		defer func() {
+			cerr := e.clearNetwork()
+			if err == nil {
+				err = cerr
+			}
+		}()
+		e.Logger.Info("Emulating the network...")
+		err = e.emulateNetwork()
+		if err != nil {
+			return err
+		}
+	}
+
 	e.Logger.Info("Creating replicas...")
 	cfg, err := e.createReplicas()
 	if err != nil {
@@ -73,13 +189,22 @@ func (e *Experiment) Run() (err error) {
 		return fmt.Errorf("failed to start replicas: %w", err)
 	}
 
+	err = e.startProfiles()
+	if err != nil {
+		return fmt.Errorf("failed to start profiles: %w", err)
+	}
+
 	e.Logger.Info("Starting clients...")
 	err = e.startClients(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to start clients: %w", err)
 	}
 
-	time.Sleep(e.Duration)
+	// the experiment is stopped as usual if it is aborted, such that the measurements so far are kept.
+	aborted := e.waitForRun()
+	if aborted != nil {
+		e.Logger.Errorf("Aborting the experiment: %v", aborted)
+	}
 
 	e.Logger.Info("Stopping clients...")
 	err = e.stopClients()
@@ -98,6 +223,20 @@ func (e *Experiment) Run() (err error) {
 		return fmt.Errorf("failed to stop replicas: %w", err)
 	}
 
+	if e.ArtifactDir != "" {
+		e.Logger.Info("Collecting artifacts...")
+		err = e.collectArtifacts()
+		if err != nil {
+			return fmt.Errorf("failed to collect artifacts: %w", err)
+		}
+	}
+
+	if aborted != nil {
+		return fmt.Errorf("the experiment was aborted: %w", aborted)
+	}
+	if report := e.Report(); len(report.Missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingData, report)
+	}
 	return nil
 }
 
@@ -109,7 +248,7 @@ func (e *Experiment) createReplicas() (cfg *orchestrationpb.ReplicaConfiguration
 
 	cfg = &orchestrationpb.ReplicaConfiguration{Replicas: make(map[uint32]*orchestrationpb.ReplicaInfo)}
 
-	for host, worker := range e.Hosts {
+	for host := range e.workers() {
 		req := &orchestrationpb.CreateReplicaRequest{Replicas: make(map[uint32]*orchestrationpb.ReplicaOpts)}
 		for _, id := range e.hostsToReplicas[host] {
 			opts := e.replicaOpts[id]
@@ -117,14 +256,21 @@ func (e *Experiment) createReplicas() (cfg *orchestrationpb.ReplicaConfiguration
 
 			// the generated certificate should be valid for the hostname and its ip addresses.
 			validFor := []string{host}
-			ips, err := net.LookupIP(host)
+			if addr := e.hostAddress(host); addr != host {
+				validFor = append(validFor, addr)
+			}
+			ips, err := net.LookupIP(e.hostAddress(host))
 			if err == nil {
 				for _, ip := range ips {
 					validFor = append(validFor, ip.String())
 				}
 			}
+			if opts.GetUnixSocketDir() != "" {
+				// connections through unix domain sockets verify the certificate against localhost.
+				validFor = append(validFor, "localhost")
+			}
 
-			keyChain, err := keygen.GenerateKeyChain(id, validFor, e.Crypto, e.ca, e.caKey)
+			keyChain, err := keygen.GenerateKeyChain(id, validFor, opts.GetCrypto(), e.ca, e.caKey)
 			if err != nil {
 				return nil, fmt.Errorf("failed to generate keychain: %w", err)
 			}
@@ -135,20 +281,170 @@ func (e *Experiment) createReplicas() (cfg *orchestrationpb.ReplicaConfiguration
 			opts.CertificateKey = keyChain.CertificateKey
 			req.Replicas[opts.ID] = opts
 		}
-		wcfg, err := worker.CreateReplica(req)
-		if err != nil {
+		res, err := e.call(host, StartPhase, e.Timeouts.Start, func(w RemoteWorker) (proto.Message, error) {
+			return w.CreateReplica(req)
+		})
+		if e.hasFailed(host) {
+			continue
+		} else if err != nil {
 			return nil, err
 		}
 
-		for id, replicaCfg := range wcfg.GetReplicas() {
-			replicaCfg.Address = host
+		for id, replicaCfg := range res.(*orchestrationpb.CreateReplicaResponse).GetReplicas() {
+			replicaCfg.Address = e.hostAddress(host)
 			cfg.Replicas[id] = replicaCfg
+			if port := replicaCfg.GetStatusPort(); port != 0 {
+				log.Printf("replica %d serves its status on %s", id, net.JoinHostPort(replicaCfg.Address, strconv.Itoa(int(port))))
+			}
+			if port := replicaCfg.GetCommitStreamPort(); port != 0 {
+				log.Printf("replica %d streams its commits on %s", id, net.JoinHostPort(replicaCfg.Address, strconv.Itoa(int(port))))
+			}
 		}
 	}
 
 	return cfg, nil
 }
 
+// hostAddress returns the address that the replicas on the host are reached at.
+func (e *Experiment) hostAddress(host string) string {
+	if addr, ok := e.HostAddresses[host]; ok {
+		return addr
+	}
+	return host
+}
+
+// Validate checks the configuration of the experiment before it is deployed: the modules must be registered,
+// and the counts of replicas and clients must be consistent with the host configurations, the byzantine strategies,
+// the faults, and the latency matrix. The hosts are only used for their names, so the workers may be nil.
+func (e *Experiment) Validate() error {
+	if e.NumReplicas < 1 {
+		return fmt.Errorf("invalid replica configuration: at least one replica is required, got %d", e.NumReplicas)
+	}
+	if e.NumClients < 0 {
+		return fmt.Errorf("invalid client configuration: negative number of clients: %d", e.NumClients)
+	}
+
+	if _, err := consensus.GetRules(e.ReplicaOpts.GetConsensus()); err != nil {
+		return err
+	}
+	if _, err := consensus.GetCrypto(e.ReplicaOpts.GetCrypto()); err != nil {
+		return err
+	}
+	if _, err := consensus.GetLeaderRotation(e.ReplicaOpts.GetLeaderRotation()); err != nil {
+		return err
+	}
+	if _, err := backend.GetTransport(e.ReplicaOpts.GetTransport()); err != nil {
+		return err
+	}
+	for name := range e.ReplicaOpts.GetInboundRateLimits() {
+		if _, err := backend.ParseMessageClass(name); err != nil {
+			return err
+		}
+	}
+
+	for host := range e.HostAddresses {
+		if _, ok := e.Hosts[host]; !ok {
+			return fmt.Errorf("invalid host address: host '%s' is not one of the hosts of the experiment", host)
+		}
+	}
+
+	var replicas, clients int
+	for host, hostCfg := range e.HostConfigs {
+		if _, ok := e.Hosts[host]; !ok {
+			return fmt.Errorf("invalid host configuration: host '%s' is not one of the hosts of the experiment", host)
+		}
+		if hostCfg.Replicas < 0 || hostCfg.Clients < 0 {
+			return fmt.Errorf("invalid host configuration: negative number of replicas or clients for host '%s'", host)
+		}
+		replicas += hostCfg.Replicas
+		clients += hostCfg.Clients
+	}
+	if replicas > e.NumReplicas {
+		return fmt.Errorf("invalid replica configuration: %d replicas requested, but host configuration specifies %d",
+			e.NumReplicas, replicas)
+	}
+	if clients > e.NumClients {
+		return fmt.Errorf("invalid client configuration: %d clients requested, but host configuration specifies %d",
+			e.NumClients, clients)
+	}
+	if len(e.Hosts) > 0 && len(e.HostConfigs) == len(e.Hosts) && (replicas != e.NumReplicas || clients != e.NumClients) {
+		return fmt.Errorf("invalid host configuration: all hosts are configured with %d replicas and %d clients, "+
+			"but %d replicas and %d clients are requested", replicas, clients, e.NumReplicas, e.NumClients)
+	}
+
+	if _, err := e.assignByzantine(); err != nil {
+		return err
+	}
+	for id, overrides := range e.ReplicaOverrides {
+		if err := validateOverrides(id, overrides, e.NumReplicas); err != nil {
+			return err
+		}
+	}
+	for id, faults := range e.Faults {
+		if id < 1 || int(id) > e.NumReplicas {
+			return fmt.Errorf("cannot inject faults into replica %d: there are %d replicas", id, e.NumReplicas)
+		}
+		if err := validateFaults(id, faults, e.Duration, e.ReplicaOpts.GetShards()); err != nil {
+			return err
+		}
+	}
+	if err := e.validateResources(); err != nil {
+		return err
+	}
+	if len(e.LatencyMatrix) > 0 && len(e.LatencyMatrix) < e.NumReplicas {
+		return fmt.Errorf("the latency matrix has %d rows, but there are %d replicas", len(e.LatencyMatrix), e.NumReplicas)
+	}
+	if e.Emulation != nil {
+		if err := e.Emulation.validate(e.Hosts); err != nil {
+			return err
+		}
+	}
+	if e.ProgressInterval < 0 || e.StallTimeout < 0 {
+		return fmt.Errorf("negative progress interval or stall timeout")
+	}
+	if e.StallTimeout > 0 && e.ProgressInterval == 0 {
+		return fmt.Errorf("the stall timeout requires a progress interval")
+	}
+	t := e.Timeouts
+	if t.Start < 0 || t.Run < 0 || t.Stop < 0 || t.Collect < 0 || t.RunRetries < 0 {
+		return fmt.Errorf("negative phase timeout or number of retries")
+	}
+	if (e.CPUProfile || e.HeapProfile) && e.ArtifactDir == "" {
+		return fmt.Errorf("profiles require artifacts to be collected")
+	}
+	return nil
+}
+
+// validateOverrides checks that the overrides of the options of a replica select known modules,
+// and that they do not set the options that have dedicated fields in the experiment.
+func validateOverrides(id hotstuff.ID, overrides *orchestrationpb.ReplicaOpts, numReplicas int) error {
+	if id < 1 || int(id) > numReplicas {
+		return fmt.Errorf("cannot override the options of replica %d: there are %d replicas", id, numReplicas)
+	}
+	if overrides.GetID() != 0 || overrides.GetByzantineStrategy() != "" || len(overrides.GetFaults()) > 0 ||
+		len(overrides.GetLatencies()) > 0 || len(overrides.GetPrivateKey()) > 0 || len(overrides.GetPublicKey()) > 0 ||
+		overrides.GetShards() != 0 {
+		return fmt.Errorf("invalid overrides of replica %d: the ID, keys, byzantine strategy, faults, latencies, "+
+			"and shards of a replica cannot be overridden", id)
+	}
+	if name := overrides.GetConsensus(); name != "" {
+		if _, err := consensus.GetRules(name); err != nil {
+			return fmt.Errorf("invalid overrides of replica %d: %w", id, err)
+		}
+	}
+	if name := overrides.GetCrypto(); name != "" {
+		if _, err := consensus.GetCrypto(name); err != nil {
+			return fmt.Errorf("invalid overrides of replica %d: %w", id, err)
+		}
+	}
+	if name := overrides.GetLeaderRotation(); name != "" {
+		if _, err := consensus.GetLeaderRotation(name); err != nil {
+			return fmt.Errorf("invalid overrides of replica %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
 // assignReplicasAndClients assigns replica and client ids to each host,
 // based on the requested amount of replicas/clients and the assignments for each host.
 func (e *Experiment) assignReplicasAndClients() (err error) {
@@ -156,8 +452,17 @@ func (e *Experiment) assignReplicasAndClients() (err error) {
 	e.replicaOpts = make(map[hotstuff.ID]*orchestrationpb.ReplicaOpts)
 	e.hostsToClients = make(map[string][]hotstuff.ID)
 
+	strategies, err := e.assignByzantine()
+	if err != nil {
+		return err
+	}
+
 	nextReplicaID := hotstuff.ID(1)
 	nextClientID := hotstuff.ID(1)
+	sessions := e.ClientOpts.GetSessions()
+	if sessions < 1 {
+		sessions = 1
+	}
 
 	// number of replicas that should be auto assigned
 	remainingReplicas := e.NumReplicas
@@ -168,7 +473,6 @@ func (e *Experiment) assignReplicasAndClients() (err error) {
 
 	// determine how many replicas should be assigned automatically
 	for _, hostCfg := range e.HostConfigs {
-		// TODO: ensure that this host is part of e.Hosts
 		remainingReplicas -= hostCfg.Replicas
 		remainingClients -= hostCfg.Clients
 		autoConfig--
@@ -228,48 +532,122 @@ func (e *Experiment) assignReplicasAndClients() (err error) {
 		}
 
 		for i := 0; i < numReplicas; i++ {
-			var byzantineStrategy string
-			for strategy, count := range e.Byzantine {
-				if count > 0 {
-					e.Byzantine[strategy]--
-					byzantineStrategy = strategy
-				}
-			}
-
 			// copy the replica opts
 			replicaOpts := proto.Clone(e.ReplicaOpts).(*orchestrationpb.ReplicaOpts)
+			if overrides, ok := e.ReplicaOverrides[nextReplicaID]; ok {
+				proto.Merge(replicaOpts, overrides)
+			}
 			replicaOpts.ID = uint32(nextReplicaID)
-			replicaOpts.ByzantineStrategy = byzantineStrategy
+			replicaOpts.ByzantineStrategy = strategies[nextReplicaID]
+			replicaOpts.Faults = e.Faults[nextReplicaID]
+			replicaOpts.Resources = e.ReplicaResources[nextReplicaID]
+			e.seedReplica(replicaOpts)
+			if row := int(nextReplicaID) - 1; row < len(e.LatencyMatrix) {
+				replicaOpts.Latencies = make(map[uint32]*durationpb.Duration, len(e.LatencyMatrix[row]))
+				for col, latency := range e.LatencyMatrix[row] {
+					replicaOpts.Latencies[uint32(col+1)] = durationpb.New(latency)
+				}
+			}
 
 			e.hostsToReplicas[host] = append(e.hostsToReplicas[host], nextReplicaID)
 			e.replicaOpts[nextReplicaID] = replicaOpts
-			log.Printf("replica %d assigned to host %s", nextReplicaID, host)
+			if strategy := strategies[nextReplicaID]; strategy != "" {
+				log.Printf("replica %d assigned to host %s (byzantine strategy: %s)", nextReplicaID, host, strategy)
+			} else {
+				log.Printf("replica %d assigned to host %s", nextReplicaID, host)
+			}
 			nextReplicaID++
 		}
 
 		for i := 0; i < numClients; i++ {
 			e.hostsToClients[host] = append(e.hostsToClients[host], nextClientID)
 			log.Printf("client %d assigned to host %s", nextClientID, host)
-			nextClientID++
+			// the sessions of the client use the following client IDs.
+			nextClientID += hotstuff.ID(sessions)
 		}
 	}
 	// TODO: warn if not all clients/replicas were assigned
 	return nil
 }
 
+// assignByzantine returns the byzantine strategy of each byzantine replica.
+// The replicas in ByzantineReplicas get their strategies first, and the counts in Byzantine are then assigned to the
+// remaining replicas in order of their IDs, taking the strategies in order of their names.
+// Exceeding the number of faults that the configuration tolerates is allowed, but logs a warning.
+func (e *Experiment) assignByzantine() (map[hotstuff.ID]string, error) {
+	strategies := make(map[hotstuff.ID]string)
+	known := byzantine.Names()
+	isKnown := func(name string) bool {
+		for _, n := range known {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	for id, strategy := range e.ByzantineReplicas {
+		if !isKnown(strategy) {
+			return nil, fmt.Errorf("unknown byzantine strategy '%s' for replica %d: valid strategies are %s",
+				strategy, id, strings.Join(known, ", "))
+		}
+		if id < 1 || int(id) > e.NumReplicas {
+			return nil, fmt.Errorf("cannot assign byzantine strategy '%s' to replica %d: there are %d replicas",
+				strategy, id, e.NumReplicas)
+		}
+		strategies[id] = strategy
+	}
+
+	names := make([]string, 0, len(e.Byzantine))
+	for name := range e.Byzantine {
+		if !isKnown(name) {
+			return nil, fmt.Errorf("unknown byzantine strategy '%s': valid strategies are %s", name, strings.Join(known, ", "))
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	next := hotstuff.ID(1)
+	for _, name := range names {
+		for i := 0; i < e.Byzantine[name]; i++ {
+			for strategies[next] != "" {
+				next++
+			}
+			if int(next) > e.NumReplicas {
+				return nil, fmt.Errorf("cannot assign byzantine strategy '%s' to %d replicas: there are %d replicas",
+					name, e.Byzantine[name], e.NumReplicas)
+			}
+			strategies[next] = name
+		}
+	}
+
+	if f := (e.NumReplicas - 1) / 3; len(strategies) > f {
+		e.Logger.Warnf("%d of %d replicas are byzantine, but only %d faults are tolerated", len(strategies), e.NumReplicas, f)
+	}
+	return strategies, nil
+}
+
 func (e *Experiment) startReplicas(cfg *orchestrationpb.ReplicaConfiguration) (err error) {
 	errors := make(chan error)
-	for host, worker := range e.Hosts {
-		go func(host string, worker RemoteWorker) {
+	workers := e.workers()
+	for host := range workers {
+		go func(host string) {
 			req := &orchestrationpb.StartReplicaRequest{
-				Configuration: cfg.GetReplicas(),
-				IDs:           getIDs(host, e.hostsToReplicas),
+				Configuration:    cfg.GetReplicas(),
+				IDs:              getIDs(host, e.hostsToReplicas),
+				ExperimentConfig: e.Config,
+				MasterSeed:       e.Seed,
+			}
+			_, err := e.call(host, StartPhase, e.Timeouts.Start, func(w RemoteWorker) (proto.Message, error) {
+				return w.StartReplica(req)
+			})
+			if e.hasFailed(host) {
+				err = nil
 			}
-			_, err := worker.StartReplica(req)
 			errors <- err
-		}(host, worker)
+		}(host)
 	}
-	for range e.Hosts {
+	for range workers {
 		err = multierr.Append(err, <-errors)
 	}
 	return err
@@ -277,63 +655,152 @@ func (e *Experiment) startReplicas(cfg *orchestrationpb.ReplicaConfiguration) (e
 
 func (e *Experiment) stopReplicas() error {
 	hashes := make(map[uint32][]byte)
-	for host, worker := range e.Hosts {
+	stateHashes := make(map[uint32][]byte)
+	shardHashes := make([]map[uint32][]byte, 0, e.ReplicaOpts.GetShards())
+	var incompatible []string
+	for host := range e.workers() {
 		req := &orchestrationpb.StopReplicaRequest{IDs: getIDs(host, e.hostsToReplicas)}
-		res, err := worker.StopReplica(req)
-		if err != nil {
+		msg, err := e.call(host, StopPhase, e.Timeouts.Stop, func(w RemoteWorker) (proto.Message, error) {
+			return w.StopReplica(req)
+		})
+		if e.hasFailed(host) {
+			continue
+		} else if err != nil {
 			return err
 		}
+		res := msg.(*orchestrationpb.StopReplicaResponse)
 		for id, hash := range res.GetHashes() {
 			hashes[id] = hash
 		}
+		for id, hash := range res.GetStateHashes() {
+			stateHashes[id] = hash
+		}
+		for id, shards := range res.GetShardHashes() {
+			for i, hash := range shards.GetHashes() {
+				for len(shardHashes) <= i {
+					shardHashes = append(shardHashes, make(map[uint32][]byte))
+				}
+				shardHashes[i][id] = hash
+			}
+		}
+		for id, peers := range res.GetIncompatible() {
+			for _, peer := range peers.GetPeers() {
+				incompatible = append(incompatible, fmt.Sprintf("replica %d found %s", id, peer))
+			}
+		}
+	}
+	// the replicas cannot agree with incompatible replicas, so the hashes would be compared in vain.
+	if len(incompatible) > 0 {
+		sort.Strings(incompatible)
+		return fmt.Errorf("incompatible replicas: %s", strings.Join(incompatible, "; "))
+	}
+	if !allEqual(hashes) {
+		return fmt.Errorf("hash mismatch")
+	}
+	if !allEqual(stateHashes) {
+		return fmt.Errorf("state hash mismatch")
+	}
+	for i, hashes := range shardHashes {
+		if !allEqual(hashes) {
+			return fmt.Errorf("hash mismatch in shard %d", i+1)
+		}
 	}
+	return nil
+}
+
+// allEqual returns true if the hashes are equal.
+func allEqual(hashes map[uint32][]byte) bool {
 	var cmp []byte
 	for _, hash := range hashes {
 		if cmp == nil {
 			cmp = hash
 		}
 		if !bytes.Equal(cmp, hash) {
-			return fmt.Errorf("hash mismatch")
+			return false
 		}
 	}
-	return nil
+	return true
 }
 
 func (e *Experiment) startClients(cfg *orchestrationpb.ReplicaConfiguration) error {
-	for host, worker := range e.Hosts {
+	for host := range e.workers() {
 		req := &orchestrationpb.StartClientRequest{}
 		req.Clients = make(map[uint32]*orchestrationpb.ClientOpts)
 		req.Configuration = cfg.GetReplicas()
 		req.CertificateAuthority = keygen.CertToPEM(e.ca)
+		req.Trace = e.Trace
+		req.ExperimentConfig = e.Config
+		req.MasterSeed = e.Seed
 		for _, id := range e.hostsToClients[host] {
 			clientOpts := proto.Clone(e.ClientOpts).(*orchestrationpb.ClientOpts)
 			clientOpts.ID = uint32(id)
+			clientOpts.Resources = e.ClientResources[id]
+			clientOpts.Shard = e.clientShard(id)
+			e.seedClient(clientOpts)
 			req.Clients[uint32(id)] = clientOpts
 		}
-		_, err := worker.StartClient(req)
-		if err != nil {
+		_, err := e.call(host, StartPhase, e.Timeouts.Start, func(w RemoteWorker) (proto.Message, error) {
+			return w.StartClient(req)
+		})
+		if err != nil && !e.hasFailed(host) {
 			return err
 		}
 	}
 	return nil
 }
 
+// clientShard returns the shard that a client sends its commands to. The clients are assigned to the shards in turn,
+// by the order of their IDs.
+func (e *Experiment) clientShard(id hotstuff.ID) uint32 {
+	shards := e.ReplicaOpts.GetShards()
+	if shards < 2 {
+		return 0
+	}
+	sessions := e.ClientOpts.GetSessions()
+	if sessions < 1 {
+		sessions = 1
+	}
+	return (uint32(id) - 1) / sessions % shards
+}
+
 func (e *Experiment) stopClients() error {
-	for host, worker := range e.Hosts {
+	for host := range e.workers() {
 		req := &orchestrationpb.StopClientRequest{}
 		req.IDs = getIDs(host, e.hostsToClients)
-		_, err := worker.StopClient(req)
-		if err != nil {
+		_, err := e.call(host, StopPhase, e.Timeouts.Stop, func(w RemoteWorker) (proto.Message, error) {
+			return w.StopClient(req)
+		})
+		if err != nil && !e.hasFailed(host) {
 			return err
 		}
 	}
 	return nil
 }
 
-func (e *Experiment) quit() error {
-	for _, worker := range e.Hosts {
-		err := worker.Quit()
+// Reset resets the workers, such that they can be used for another run after an experiment that kept them.
+// The name of the run is passed to the workers, which use it to keep the measurements of the runs apart.
+// A worker that does not reply within the start timeout is marked as failed, and listed by Report.
+func (e *Experiment) Reset(run string) error {
+	e.failed = make(map[string]hostFailure)
+	for host := range e.Hosts {
+		_, err := e.call(host, StartPhase, e.Timeouts.Start, func(w RemoteWorker) (proto.Message, error) {
+			return w.Reset(&orchestrationpb.ResetRequest{Run: run})
+		})
 		if err != nil {
+			return fmt.Errorf("failed to reset %s: %w", host, err)
+		}
+	}
+	return nil
+}
+
+// quit shuts down the workers, which also shuts down the replicas and clients that are still running
+// if the experiment failed.
+func (e *Experiment) quit() error {
+	for host := range e.workers() {
+		_, err := e.call(host, StopPhase, e.Timeouts.Stop, func(w RemoteWorker) (proto.Message, error) {
+			return w.Shutdown(&orchestrationpb.ShutdownRequest{})
+		})
+		if err != nil && !e.hasFailed(host) {
 			return err
 		}
 	}
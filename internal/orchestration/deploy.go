@@ -8,11 +8,14 @@ package orchestration
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
+	"net"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -32,9 +35,30 @@ type DeployConfig struct {
 	Fgprof              bool
 	Metrics             []string
 	MeasurementInterval time.Duration
+
+	// Timeout is the deadline of each step of the deployment on each host, such as uploading the binary.
+	// The default timeout of iago is used if it is zero.
+	Timeout time.Duration
+}
+
+// DeployError is returned by Deploy when the workers could not be deployed to some of the hosts.
+// The workers of the other hosts are returned along with it, such that the experiment can continue without them.
+type DeployError struct {
+	Failed map[string]error // the error of each host that failed, by host name
+}
+
+func (err *DeployError) Error() string {
+	hosts := make([]string, 0, len(err.Failed))
+	for host, herr := range err.Failed {
+		hosts = append(hosts, fmt.Sprintf("%s: %v", host, herr))
+	}
+	sort.Strings(hosts)
+	return "failed to deploy to " + strings.Join(hosts, "; ")
 }
 
 // Deploy deploys the hotstuff binary to a group of servers and starts a worker on the given port.
+// A host that fails a step of the deployment is skipped in the following steps, and is listed in a *DeployError,
+// unless all of the hosts fail.
 func Deploy(g iago.Group, cfg DeployConfig) (workers map[string]WorkerSession, err error) {
 	w := workerSetup{
 		cfg:     cfg,
@@ -48,17 +72,35 @@ func Deploy(g iago.Group, cfg DeployConfig) (workers map[string]WorkerSession, e
 
 	// catch panics and return any errors
 	defer func() {
-		err, _ = recover().(error)
+		if r := recover(); r != nil {
+			err, _ = r.(error)
+			workers = nil
+		}
 	}()
 
-	// alternative error handler that does not log
+	if cfg.Timeout > 0 {
+		g.Timeout = cfg.Timeout
+	}
+	// the errors of the hosts are recorded, and the hosts are left out of the remaining steps.
+	failed := make(map[string]error)
 	g.ErrorHandler = func(err error) {
-		panic(err)
+		var taskErr iago.TaskError
+		if !errors.As(err, &taskErr) {
+			panic(err)
+		}
+		failed[taskErr.HostName] = err
+	}
+	run := func(name string, f func(ctx context.Context, host iago.Host) error) {
+		g.Run(name, f)
+		g.Hosts = withoutHosts(g.Hosts, failed)
+		if len(g.Hosts) == 0 && len(failed) > 0 {
+			panic(&DeployError{Failed: failed})
+		}
 	}
 
 	tmpDir := "hotstuff." + randString(8)
 
-	g.Run("Create temporary directory",
+	run("Create temporary directory",
 		func(ctx context.Context, host iago.Host) (err error) {
 			testDir := strings.TrimPrefix(tempDirPath(host, tmpDir), "/")
 			dataDir := testDir + "/data"
@@ -68,7 +110,7 @@ func Deploy(g iago.Group, cfg DeployConfig) (workers map[string]WorkerSession, e
 			return err
 		})
 
-	g.Run(
+	run(
 		"Upload hotstuff binary",
 		func(ctx context.Context, host iago.Host) (err error) {
 			dest, err := iago.NewPath("/", iago.GetStringVar(host, "test-dir")+"/hotstuff")
@@ -88,21 +130,52 @@ func Deploy(g iago.Group, cfg DeployConfig) (workers map[string]WorkerSession, e
 			}.Apply(ctx, host)
 		})
 
-	g.Run("Start hotstuff binary", w.Apply)
+	run("Start hotstuff binary", w.Apply)
 
+	if len(failed) > 0 {
+		return w.workers, &DeployError{Failed: failed}
+	}
 	return w.workers, nil
 }
 
+// withoutHosts returns the hosts that are not in the failed map.
+func withoutHosts(hosts []iago.Host, failed map[string]error) []iago.Host {
+	remaining := make([]iago.Host, 0, len(hosts))
+	for _, host := range hosts {
+		if _, ok := failed[host.Name()]; !ok {
+			remaining = append(remaining, host)
+		}
+	}
+	return remaining
+}
+
+// ControllerAddress returns the address of the controller on the route to the host, such that the workers on the
+// remote hosts can reach the replicas of a worker that runs in the process of the controller.
+func ControllerAddress(host iago.Host) (string, error) {
+	remote, _, err := net.SplitHostPort(host.Address())
+	if err != nil {
+		return "", fmt.Errorf("invalid address of %s: %w", host.Name(), err)
+	}
+	// connecting a UDP socket selects the route to the host without sending any packets.
+	conn, err := net.Dial("udp", net.JoinHostPort(remote, "9"))
+	if err != nil {
+		return "", fmt.Errorf("no route to %s: %w", host.Name(), err)
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+}
+
 // FetchData downloads the data from the workers.
 func FetchData(g iago.Group, dest string) (err error) {
-	// catch panics and return any errors
-	defer func() {
-		err, _ = recover().(error)
-	}()
-
-	// alternative error handler that does not log
-	g.ErrorHandler = func(err error) {
-		panic(err)
+	// the errors are collected, such that the data of the other hosts are fetched even if some of the hosts fail.
+	// The test directory is kept on the hosts whose data could not be fetched.
+	failed := make(map[string]error)
+	g.ErrorHandler = func(herr error) {
+		var taskErr iago.TaskError
+		if errors.As(herr, &taskErr) {
+			failed[taskErr.HostName] = herr
+		}
+		err = multierr.Append(err, herr)
 	}
 
 	if dest != "" {
@@ -123,13 +196,14 @@ func FetchData(g iago.Group, dest string) (err error) {
 			})
 	}
 
+	g.Hosts = withoutHosts(g.Hosts, failed)
 	g.Run("Remove test directory",
 		func(ctx context.Context, host iago.Host) (err error) {
 			err = fs.RemoveAll(host.GetFS(), iago.GetStringVar(host, "test-dir"))
 			return err
 		})
 
-	return nil
+	return err
 }
 
 // WorkerSession contains the state of a connected worker.
@@ -0,0 +1,223 @@
+package orchestration
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
+	"github.com/relab/hotstuff/metrics/types"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// The actions of the faults that can be injected into replicas.
+const (
+	CrashFault   = "crash"
+	PauseFault   = "pause"
+	RestartFault = "restart"
+)
+
+// validateFaults checks that the faults of a replica can be injected in order during an experiment of the given
+// duration: the faults must be known and end before the experiment does, a pause must have a duration,
+// and each fault must apply to the state that the replica is in when the fault starts.
+// A replica that runs several shards can only be paused, one shard at a time.
+// The faults are sorted by their start times.
+func validateFaults(id hotstuff.ID, faults []*orchestrationpb.Fault, duration time.Duration, shards uint32) error {
+	sort.SliceStable(faults, func(i, j int) bool {
+		return faults[i].GetStart().AsDuration() < faults[j].GetStart().AsDuration()
+	})
+	var (
+		crashed bool
+		busy    time.Duration // the time at which the previous fault with a duration ends
+	)
+	for _, fault := range faults {
+		start, length := fault.GetStart().AsDuration(), fault.GetDuration().AsDuration()
+		if start < 0 || length < 0 {
+			return fmt.Errorf("invalid %s fault of replica %d: negative start time or duration", fault.GetAction(), id)
+		}
+		if start+length > duration {
+			return fmt.Errorf("the %s fault of replica %d at %v ends after the experiment (%v)", fault.GetAction(), id, start, duration)
+		}
+		if start < busy {
+			return fmt.Errorf("the %s fault of replica %d at %v overlaps the previous fault, which ends at %v", fault.GetAction(), id, start, busy)
+		}
+		if shards > 1 && fault.GetAction() != PauseFault {
+			return fmt.Errorf("cannot inject the %s fault into replica %d: it runs %d shards, which can only be paused", fault.GetAction(), id, shards)
+		}
+		if shard := fault.GetShard(); shard > 0 && shard >= shards {
+			return fmt.Errorf("cannot pause shard %d of replica %d: it runs %d shards", shard, id, shards)
+		}
+		switch fault.GetAction() {
+		case CrashFault:
+			if crashed {
+				return fmt.Errorf("cannot crash replica %d at %v: it has already crashed", id, start)
+			}
+			// a crash with a duration is followed by a restart.
+			crashed = length == 0
+		case PauseFault:
+			if crashed {
+				return fmt.Errorf("cannot pause replica %d at %v: it has crashed", id, start)
+			}
+			if length == 0 {
+				return fmt.Errorf("the pause of replica %d at %v has no duration", id, start)
+			}
+		case RestartFault:
+			if !crashed {
+				return fmt.Errorf("cannot restart replica %d at %v: it has not crashed", id, start)
+			}
+			if length > 0 {
+				return fmt.Errorf("the restart of replica %d at %v cannot have a duration", id, start)
+			}
+			crashed = false
+		default:
+			return fmt.Errorf("unknown fault '%s' for replica %d: valid faults are %s, %s, and %s",
+				fault.GetAction(), id, CrashFault, PauseFault, RestartFault)
+		}
+		busy = start + length
+	}
+	return nil
+}
+
+// faultState is the state of the faults that are injected into a replica by a worker.
+type faultState struct {
+	timers  []*time.Timer
+	resume  func() // resumes the replica if it is paused
+	faulty  bool   // true if the replica has crashed, such that its hashes may differ from the others
+	stopped bool   // true when the replica has been stopped, after which no more faults are injected
+}
+
+// listenAddrs are the addresses that a replica listens on, which are reused when the replica is restarted.
+type listenAddrs struct {
+	replica string
+	client  string
+}
+
+// scheduleFaults starts the timers that inject the faults of the replica. The caller must hold the mutex.
+func (w *Worker) scheduleFaults(id hotstuff.ID, faults []*orchestrationpb.Fault) {
+	if len(faults) == 0 {
+		return
+	}
+	state := &faultState{}
+	w.faults[id] = state
+	for _, fault := range faults {
+		fault := fault
+		state.timers = append(state.timers, time.AfterFunc(fault.GetStart().AsDuration(), func() {
+			w.mut.Lock()
+			defer w.mut.Unlock()
+			w.injectFault(id, fault.GetShard(), fault.GetAction(), fault.GetDuration().AsDuration())
+		}))
+	}
+}
+
+// injectFault injects a fault into the given shard of the replica and logs it. The caller must hold the mutex.
+func (w *Worker) injectFault(id hotstuff.ID, shard uint32, action string, duration time.Duration) {
+	state := w.faults[id]
+	r, ok := w.replicas[id]
+	if !ok || state == nil || state.stopped {
+		return
+	}
+	event := types.NewReplicaEvent(uint32(id), time.Now())
+	if shard > 0 {
+		r = w.shards[id][shard-1]
+		event.Instance = shard
+	}
+	w.metricsLogger.Log(&types.FaultEvent{
+		Event:    event,
+		Action:   action,
+		Duration: durationpb.New(duration),
+	})
+	switch action {
+	case CrashFault:
+		state.faulty = true
+		r.Stop()
+		if duration > 0 {
+			state.timers = append(state.timers, time.AfterFunc(duration, func() {
+				w.mut.Lock()
+				defer w.mut.Unlock()
+				w.injectFault(id, 0, RestartFault, 0)
+			}))
+		}
+	case PauseFault:
+		state.resume = r.Pause()
+		state.timers = append(state.timers, time.AfterFunc(duration, func() {
+			w.mut.Lock()
+			defer w.mut.Unlock()
+			if !state.stopped && state.resume != nil {
+				state.resume()
+				state.resume = nil
+			}
+		}))
+	case RestartFault:
+		if err := w.restartReplica(id); err != nil {
+			log.Printf("failed to restart replica %d: %v", id, err)
+		}
+	}
+}
+
+// restartReplica replaces a crashed replica by a new one, which is created from the same options and listens on the
+// same addresses, such that the other replicas and the clients reconnect to it. The new replica recovers its state
+// from its durable snapshots or from the other replicas, depending on the options. The caller must hold the mutex.
+func (w *Worker) restartReplica(id hotstuff.ID) error {
+	opts := w.replicaOpts[id]
+	transport, err := backend.GetTransport(opts.GetTransport())
+	if err != nil {
+		return err
+	}
+	r, err := w.createReplica(opts, transport)
+	if err != nil {
+		return err
+	}
+	addrs := w.listenAddrs[id]
+	replicaListener, err := r.Transport().Listen(addrs.replica)
+	if err != nil {
+		return fmt.Errorf("failed to create listener: %w", err)
+	}
+	defaultTransport, _ := backend.GetTransport("")
+	clientListener, err := defaultTransport.Listen(addrs.client)
+	if err != nil {
+		replicaListener.Close()
+		return fmt.Errorf("failed to create listener: %w", err)
+	}
+	if opts.GetStatusListenAddress() != "" {
+		if _, err := r.ListenStatus(); err != nil {
+			log.Printf("restarted replica %d without a status server: %v", id, err)
+		}
+	}
+	if opts.GetCommitStreamListenAddress() != "" {
+		if _, err := r.ListenCommitStream(); err != nil {
+			log.Printf("restarted replica %d without a commit stream server: %v", id, err)
+		}
+	}
+	r.StartServers(replicaListener, clientListener)
+	w.replicas[id] = r
+	cfg, err := getConfiguration(w.configuration, false, 0)
+	if err != nil {
+		return err
+	}
+	if err := r.Connect(cfg); err != nil {
+		return err
+	}
+	r.Start()
+	return nil
+}
+
+// stopFaults stops injecting faults into the replica and resumes it if it is paused. It returns true if the replica has
+// crashed during the experiment, and false if it is running. The caller must hold the mutex.
+func (w *Worker) stopFaults(id hotstuff.ID) (faulty bool) {
+	state, ok := w.faults[id]
+	if !ok {
+		return false
+	}
+	delete(w.faults, id)
+	state.stopped = true
+	for _, timer := range state.timers {
+		timer.Stop()
+	}
+	if state.resume != nil {
+		state.resume()
+	}
+	return state.faulty
+}
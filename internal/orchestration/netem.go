@@ -0,0 +1,222 @@
+package orchestration
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/relab/hotstuff/internal/netem"
+	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
+	"github.com/relab/hotstuff/metrics/types"
+	"go.uber.org/multierr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// pingCount is the number of pings that the round-trip time of an emulated link is measured with.
+const pingCount = 3
+
+// EmulatedLink is the emulation of the network link from one host to another.
+type EmulatedLink struct {
+	Latency time.Duration // the one-way latency of the packets sent on the link
+	Jitter  time.Duration // the maximum random variation of the latency
+	Loss    float64       // the percentage of the packets sent on the link that are dropped
+}
+
+// NetworkEmulation emulates the network links between the hosts of an experiment with tc netem, such that a local
+// cluster behaves like a wide area network. The links are emulated by the workers before the replicas are created,
+// and removed when the experiment has finished, or failed. It requires that the workers run as root, or have the
+// CAP_NET_ADMIN capability.
+type NetworkEmulation struct {
+	// Interface is the network interface that the workers emulate the links on. If it is empty, each worker uses the
+	// interface that its packets to the first of the other hosts are routed through.
+	Interface string
+	// Links are the emulated links, such that Links[a][b] is the link from host a to host b.
+	// The packets sent on other links are not affected.
+	Links map[string]map[string]EmulatedLink
+}
+
+// validate checks that the links are between hosts of the experiment, and that they are valid.
+func (n *NetworkEmulation) validate(hosts map[string]RemoteWorker) error {
+	for from, links := range n.Links {
+		if _, ok := hosts[from]; !ok {
+			return fmt.Errorf("invalid network emulation: host '%s' is not one of the hosts of the experiment", from)
+		}
+		for to, link := range links {
+			if _, ok := hosts[to]; !ok {
+				return fmt.Errorf("invalid network emulation: host '%s' is not one of the hosts of the experiment", to)
+			}
+			if from == to {
+				return fmt.Errorf("invalid network emulation: the link from host '%s' to itself cannot be emulated", from)
+			}
+			if link.Latency < 0 || link.Jitter < 0 || link.Loss < 0 || link.Loss > 100 {
+				return fmt.Errorf("invalid network emulation of the link from '%s' to '%s': "+
+					"negative latency or jitter, or loss outside 0-100%%", from, to)
+			}
+		}
+	}
+	return nil
+}
+
+// request returns the request that emulates the links from the host.
+func (n *NetworkEmulation) request(host string) *orchestrationpb.EmulateNetworkRequest {
+	req := &orchestrationpb.EmulateNetworkRequest{Interface: n.Interface}
+	for to, link := range n.Links[host] {
+		req.Links = append(req.Links, &orchestrationpb.LinkEmulation{
+			Host:    to,
+			Latency: durationpb.New(link.Latency),
+			Jitter:  durationpb.New(link.Jitter),
+			Loss:    link.Loss,
+		})
+	}
+	// the interface is selected by the route to the first host.
+	sort.Slice(req.Links, func(i, j int) bool { return req.Links[i].GetHost() < req.Links[j].GetHost() })
+	return req
+}
+
+// emulateNetwork asks the workers to emulate their links, and to measure the round-trip times of the links when all
+// of them are emulated, which are logged.
+func (e *Experiment) emulateNetwork() error {
+	for host := range e.workers() {
+		if len(e.Emulation.Links[host]) == 0 {
+			continue
+		}
+		req := e.Emulation.request(host)
+		_, err := e.call(host, StartPhase, e.Timeouts.Start, func(w RemoteWorker) (proto.Message, error) {
+			return w.EmulateNetwork(req)
+		})
+		if err != nil && !e.hasFailed(host) {
+			return fmt.Errorf("failed to emulate the network of %s: %w", host, err)
+		}
+	}
+	for host := range e.workers() {
+		if len(e.Emulation.Links[host]) == 0 {
+			continue
+		}
+		res, err := e.call(host, StartPhase, e.Timeouts.Start, func(w RemoteWorker) (proto.Message, error) {
+			return w.VerifyNetwork(&orchestrationpb.VerifyNetworkRequest{})
+		})
+		if e.hasFailed(host) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to verify the network of %s: %w", host, err)
+		}
+		for to, rtt := range res.(*orchestrationpb.VerifyNetworkResponse).GetRoundTripTimes() {
+			emulated := e.Emulation.Links[host][to].Latency + e.Emulation.Links[to][host].Latency
+			e.Logger.Infof("Round-trip time from %s to %s: %v (emulated: %v)", host, to, rtt.AsDuration(), emulated)
+		}
+	}
+	return nil
+}
+
+// clearNetwork asks the workers to remove their emulated links.
+func (e *Experiment) clearNetwork() (err error) {
+	for host := range e.workers() {
+		if len(e.Emulation.Links[host]) == 0 {
+			continue
+		}
+		_, cerr := e.call(host, StopPhase, e.Timeouts.Stop, func(w RemoteWorker) (proto.Message, error) {
+			return w.ClearNetwork(&orchestrationpb.ClearNetworkRequest{})
+		})
+		if cerr != nil && !e.hasFailed(host) {
+			err = multierr.Append(err, fmt.Errorf("failed to clear the network emulation of %s: %w", host, cerr))
+		}
+	}
+	return err
+}
+
+// emulatedLink is a link emulated by a worker, with the addresses of the other host.
+type emulatedLink struct {
+	opts  *orchestrationpb.LinkEmulation
+	addrs []net.IP
+}
+
+// emulateNetwork emulates the links from the host of the worker, replacing the links that it emulated before.
+func (w *Worker) emulateNetwork(req *orchestrationpb.EmulateNetworkRequest) (*orchestrationpb.EmulateNetworkResponse, error) {
+	if err := w.clearEmulation(); err != nil {
+		return nil, err
+	}
+	if err := netem.CheckCapability(); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	var (
+		links    []emulatedLink
+		netLinks []netem.Link
+	)
+	for _, opts := range req.GetLinks() {
+		addrs, err := net.LookupIP(opts.GetHost())
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", opts.GetHost(), err)
+		}
+		links = append(links, emulatedLink{opts: opts, addrs: addrs})
+		for _, addr := range addrs {
+			netLinks = append(netLinks, netem.Link{
+				Addr:    addr,
+				Latency: opts.GetLatency().AsDuration(),
+				Jitter:  opts.GetJitter().AsDuration(),
+				Loss:    opts.GetLoss(),
+			})
+		}
+	}
+	if len(netLinks) == 0 {
+		return &orchestrationpb.EmulateNetworkResponse{}, nil
+	}
+	iface := req.GetInterface()
+	if iface == "" {
+		var err error
+		if iface, err = netem.Interface(netLinks[0].Addr); err != nil {
+			return nil, err
+		}
+	}
+	if err := netem.Apply(iface, netLinks); err != nil {
+		return nil, err
+	}
+	w.emulatedInterface = iface
+	w.emulatedLinks = links
+	return &orchestrationpb.EmulateNetworkResponse{}, nil
+}
+
+// verifyNetwork measures the round-trip times to the hosts of the emulated links, and logs them.
+func (w *Worker) verifyNetwork(_ *orchestrationpb.VerifyNetworkRequest) (*orchestrationpb.VerifyNetworkResponse, error) {
+	res := &orchestrationpb.VerifyNetworkResponse{RoundTripTimes: make(map[string]*durationpb.Duration)}
+	for _, link := range w.emulatedLinks {
+		rtt, err := netem.Ping(link.addrs[0], pingCount)
+		if err != nil {
+			return nil, err
+		}
+		res.RoundTripTimes[link.opts.GetHost()] = durationpb.New(rtt)
+		w.metricsLogger.Log(&types.NetworkEmulationEvent{
+			Event:         &types.Event{Timestamp: timestamppb.Now()},
+			Host:          link.opts.GetHost(),
+			Latency:       link.opts.GetLatency(),
+			Jitter:        link.opts.GetJitter(),
+			Loss:          link.opts.GetLoss(),
+			RoundTripTime: durationpb.New(rtt),
+		})
+	}
+	return res, nil
+}
+
+func (w *Worker) clearNetwork(_ *orchestrationpb.ClearNetworkRequest) (*orchestrationpb.ClearNetworkResponse, error) {
+	if err := w.clearEmulation(); err != nil {
+		return nil, err
+	}
+	return &orchestrationpb.ClearNetworkResponse{}, nil
+}
+
+// clearEmulation removes the emulated links, if any. The caller must hold the mutex.
+func (w *Worker) clearEmulation() error {
+	if w.emulatedInterface == "" {
+		return nil
+	}
+	if err := netem.Clear(w.emulatedInterface); err != nil {
+		return err
+	}
+	w.emulatedInterface = ""
+	w.emulatedLinks = nil
+	return nil
+}
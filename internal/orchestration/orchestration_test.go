@@ -1,8 +1,15 @@
 package orchestration_test
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -12,17 +19,25 @@ import (
 	"testing"
 	"time"
 
+	"github.com/relab/gorums"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/client"
+	"github.com/relab/hotstuff/crypto/keygen"
 	"github.com/relab/hotstuff/internal/orchestration"
 	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
 	"github.com/relab/hotstuff/internal/protostream"
 	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/metrics/plotting"
+	"github.com/relab/hotstuff/metrics/types"
 	"github.com/relab/hotstuff/modules"
 	"github.com/relab/iago/iagotest"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 func TestOrchestration(t *testing.T) {
-	run := func(consensusImpl string, crypto string) {
+	run := func(consensusImpl string, crypto string, socketDir string, kv bool, transport string) {
 		controllerStream, workerStream := net.Pipe()
 
 		workerProxy := orchestration.NewRemoteWorker(protostream.NewWriter(controllerStream), protostream.NewReader(controllerStream))
@@ -46,33 +61,1138 @@ func TestOrchestration(t *testing.T) {
 				Consensus:         consensusImpl,
 				Crypto:            crypto,
 				LeaderRotation:    "round-robin",
+				UnixSocketDir:     socketDir,
+				Transport:         transport,
 			},
 			Duration: 1 * time.Second,
 			Hosts:    map[string]orchestration.RemoteWorker{"127.0.0.1": workerProxy},
 		}
+		if transport != "" {
+			// the replicas and clients use TLS as they do in experiments run by the CLI,
+			// which the transport must support.
+			experiment.ReplicaOpts.UseTLS = true
+			experiment.ClientOpts.UseTLS = true
+		}
+		if kv {
+			// the state hashes of the key-value stores of the replicas are compared when they are stopped.
+			experiment.ClientOpts.Workload = "kv"
+			experiment.ClientOpts.KVReadRatio = 0.2
+			experiment.ClientOpts.KVDeleteRatio = 0.2
+			experiment.ClientOpts.KVCheckpointInterval = 100
+			experiment.ReplicaOpts.Application = "kvstore"
+		}
+
+		c := make(chan error)
+		go func() {
+			c <- worker.Run()
+		}()
+
+		err := experiment.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = <-c
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("ChainedHotStuff+ECDSA", func(t *testing.T) { run("chainedhotstuff", "ecdsa", "", false, "") })
+	t.Run("ChainedHotStuff+BLS12", func(t *testing.T) { run("chainedhotstuff", "bls12", "", false, "") })
+	t.Run("Fast-HotStuff+ECDSA", func(t *testing.T) { run("fasthotstuff", "ecdsa", "", false, "") })
+	t.Run("Fast-HotStuff+BLS12", func(t *testing.T) { run("fasthotstuff", "bls12", "", false, "") })
+	t.Run("Simple-HotStuff+ECDSA", func(t *testing.T) { run("simplehotstuff", "ecdsa", "", false, "") })
+	t.Run("Simple-HotStuff+BLS12", func(t *testing.T) { run("simplehotstuff", "bls12", "", false, "") })
+	t.Run("KVStore", func(t *testing.T) { run("chainedhotstuff", "ecdsa", "", true, "") })
+	t.Run("UnixSockets", func(t *testing.T) {
+		dir := t.TempDir()
+		run("chainedhotstuff", "ecdsa", dir, false, "")
+		// the socket files should be removed when the replicas are stopped.
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) > 0 {
+			t.Errorf("socket files were not removed: %v", entries)
+		}
+	})
+	t.Run("QUIC+TLS", func(t *testing.T) { run("chainedhotstuff", "ecdsa", "", false, "quic") })
+}
+
+// commitCounter counts the commits reported by the throughput measurements of the replicas.
+type commitCounter struct {
+	commits map[uint32]uint64
+}
+
+func (c *commitCounter) Add(msg interface{}) {
+	if m, ok := msg.(*types.ThroughputMeasurement); ok && !m.GetEvent().GetClient() {
+		c.commits[m.GetEvent().GetID()] += m.GetCommits()
+	}
+}
+
+func TestByzantineReplica(t *testing.T) {
+	controllerStream, workerStream := net.Pipe()
+
+	var measurements bytes.Buffer
+	metricsLogger, err := modules.NewJSONLogger(&measurements)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workerProxy := orchestration.NewRemoteWorker(protostream.NewWriter(controllerStream), protostream.NewReader(controllerStream))
+	worker := orchestration.NewWorker(protostream.NewWriter(workerStream), protostream.NewReader(workerStream),
+		metricsLogger, []string{"throughput"}, 100*time.Millisecond)
+
+	experiment := &orchestration.Experiment{
+		Logger:      logging.New("ctrl"),
+		NumReplicas: 4,
+		NumClients:  2,
+		ClientOpts: &orchestrationpb.ClientOpts{
+			ConnectTimeout: durationpb.New(time.Second),
+			MaxConcurrent:  250,
+			PayloadSize:    100,
+		},
+		ReplicaOpts: &orchestrationpb.ReplicaOpts{
+			BatchSize:         100,
+			ConnectTimeout:    durationpb.New(time.Second),
+			InitialTimeout:    durationpb.New(100 * time.Millisecond),
+			TimeoutSamples:    1000,
+			TimeoutMultiplier: 1.2,
+			Consensus:         "chainedhotstuff",
+			Crypto:            "ecdsa",
+			LeaderRotation:    "round-robin",
+		},
+		ByzantineReplicas: map[hotstuff.ID]string{3: "silence"},
+		Duration:          2 * time.Second,
+		Hosts:             map[string]orchestration.RemoteWorker{"127.0.0.1": workerProxy},
+		Config:            "byzantine-replicas:\n- 3:silence\n",
+	}
+
+	c := make(chan error)
+	go func() {
+		c <- worker.Run()
+	}()
+
+	err = experiment.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = <-c
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = metricsLogger.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byzantineReplicas := plotting.NewByzantineReplicas()
+	commits := commitCounter{commits: make(map[uint32]uint64)}
+	configs := startConfigs{configs: make(map[string]int)}
+	err = plotting.NewReader(&measurements, &byzantineReplicas, &commits, &configs).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := byzantineReplicas.IDs(); len(ids) != 1 || byzantineReplicas.Strategy(3) != "silence" {
+		t.Errorf("expected replica 3 to be recorded as running the silence strategy, got: %v", &byzantineReplicas)
+	}
+	for _, id := range []uint32{1, 2, 4} {
+		if commits.commits[id] == 0 {
+			t.Errorf("replica %d did not commit anything", id)
+		}
+	}
+	if len(configs.configs) != 1 || configs.configs[experiment.Config] != 6 {
+		t.Errorf("expected the configuration to be recorded in the start events of the replicas and clients, got: %v", configs.configs)
+	}
+}
+
+// artifactWorker starts an in-process worker whose measurements are written to a file in dir,
+// and whose log buffer contains logLine.
+func artifactWorker(t *testing.T, dir, logLine string) (orchestration.RemoteWorker, <-chan error) {
+	t.Helper()
+	f, err := os.Create(filepath.Join(dir, "measurements.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	metricsLogger, err := modules.NewJSONLogger(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := orchestration.NewLogBuffer(orchestration.DefaultLogBufferSize)
+	if _, err := io.WriteString(logs, logLine); err != nil {
+		t.Fatal(err)
+	}
+
+	controllerStream, workerStream := net.Pipe()
+	worker := orchestration.NewWorker(protostream.NewWriter(workerStream), protostream.NewReader(workerStream),
+		metricsLogger, []string{"throughput"}, 100*time.Millisecond)
+	worker.SetLogBuffer(logs)
+	worker.AddArtifact("measurements.json", f.Name())
+
+	c := make(chan error, 1)
+	go func() { c <- worker.Run() }()
+	return orchestration.NewRemoteWorker(protostream.NewWriter(controllerStream), protostream.NewReader(controllerStream)), c
+}
+
+func artifactExperiment(hosts map[string]orchestration.RemoteWorker, dir string) *orchestration.Experiment {
+	return &orchestration.Experiment{
+		Logger:      logging.New("ctrl"),
+		NumReplicas: 4,
+		NumClients:  2,
+		ClientOpts: &orchestrationpb.ClientOpts{
+			ConnectTimeout: durationpb.New(time.Second),
+			MaxConcurrent:  250,
+			PayloadSize:    100,
+		},
+		ReplicaOpts: &orchestrationpb.ReplicaOpts{
+			BatchSize:         100,
+			ConnectTimeout:    durationpb.New(time.Second),
+			InitialTimeout:    durationpb.New(100 * time.Millisecond),
+			TimeoutSamples:    1000,
+			TimeoutMultiplier: 1.2,
+			Consensus:         "chainedhotstuff",
+			Crypto:            "ecdsa",
+			LeaderRotation:    "round-robin",
+		},
+		Duration:    time.Second,
+		Hosts:       hosts,
+		ArtifactDir: dir,
+	}
+}
+
+func TestArtifacts(t *testing.T) {
+	output := t.TempDir()
+	hosts := make(map[string]orchestration.RemoteWorker)
+	var done []<-chan error
+	for _, host := range []string{"127.0.0.1", "localhost"} {
+		worker, c := artifactWorker(t, t.TempDir(), "log of "+host+"\n")
+		hosts[host] = worker
+		done = append(done, c)
+	}
+	experiment := artifactExperiment(hosts, output)
+	experiment.HeapProfile = true
+	if err := experiment.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range done {
+		if err := <-c; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for host := range hosts {
+		dir := filepath.Join(output, host)
+		logs, err := os.ReadFile(filepath.Join(dir, "worker.log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(logs) != "log of "+host+"\n" {
+			t.Errorf("%s: got log '%s'", host, logs)
+		}
+		if fi, err := os.Stat(filepath.Join(dir, "heap.pprof")); err != nil || fi.Size() == 0 {
+			t.Errorf("%s: missing heap profile: %v", host, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "cpu.pprof")); !os.IsNotExist(err) {
+			t.Errorf("%s: unexpected CPU profile: %v", host, err)
+		}
+		// the measurements are complete, since the metrics logger is closed before they are collected.
+		f, err := os.Open(filepath.Join(dir, "measurements.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		commits := commitCounter{commits: make(map[uint32]uint64)}
+		err = plotting.NewReader(f, &commits).ReadAll()
+		f.Close()
+		if err != nil {
+			t.Errorf("%s: failed to read measurements: %v", host, err)
+		}
+		if len(commits.commits) == 0 {
+			t.Errorf("%s: no throughput measurements", host)
+		}
+	}
+}
+
+// startedComponents counts the replicas and clients that logged a start event.
+type startedComponents struct {
+	replicas, clients int
+}
+
+func (c *startedComponents) Add(msg interface{}) {
+	if m, ok := msg.(*types.StartEvent); ok {
+		if m.GetEvent().GetClient() {
+			c.clients++
+		} else {
+			c.replicas++
+		}
+	}
+}
+
+func TestMixedDeployment(t *testing.T) {
+	output := t.TempDir()
+	// the replicas run on a host that is only reached through its address, like a remote host,
+	// while the clients run on the host of the controller.
+	replicaWorker, replicasDone := artifactWorker(t, t.TempDir(), "")
+	clientWorker, clientsDone := artifactWorker(t, t.TempDir(), "")
+	hosts := map[string]orchestration.RemoteWorker{"remote": replicaWorker, "localhost": clientWorker}
+	experiment := artifactExperiment(hosts, output)
+	experiment.HostAddresses = map[string]string{"remote": "127.0.0.1"}
+	experiment.HostConfigs = map[string]orchestration.HostConfig{
+		"remote":    {Replicas: 4},
+		"localhost": {Clients: 2},
+	}
+	if err := experiment.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []<-chan error{replicasDone, clientsDone} {
+		if err := <-c; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the measurements of both hosts are collected to the same output directory.
+	want := map[string]startedComponents{"remote": {replicas: 4}, "localhost": {clients: 2}}
+	for host, want := range want {
+		f, err := os.Open(filepath.Join(output, host, "measurements.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var started startedComponents
+		commits := commitCounter{commits: make(map[uint32]uint64)}
+		err = plotting.NewReader(f, &started, &commits).ReadAll()
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: failed to read measurements: %v", host, err)
+		}
+		if started != want {
+			t.Errorf("%s: got %d replicas and %d clients, want %d and %d",
+				host, started.replicas, started.clients, want.replicas, want.clients)
+		}
+		var total uint64
+		for _, n := range commits.commits {
+			total += n
+		}
+		if want.replicas > 0 && total == 0 {
+			t.Errorf("%s: no commands were committed", host)
+		}
+	}
+}
+
+func TestArtifactsCPUProfile(t *testing.T) {
+	output := t.TempDir()
+	const maxSize = 512
+	worker, done := artifactWorker(t, t.TempDir(), strings.Repeat("x", 2*maxSize))
+	experiment := artifactExperiment(map[string]orchestration.RemoteWorker{"127.0.0.1": worker}, output)
+	experiment.CPUProfile = true
+	experiment.MaxArtifactSize = maxSize
+	if err := experiment.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"cpu.pprof", "measurements.json", "worker.log"} {
+		fi, err := os.Stat(filepath.Join(output, "127.0.0.1", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Size() == 0 || fi.Size() > maxSize {
+			t.Errorf("%s: got %d bytes, want between 1 and %d bytes", name, fi.Size(), maxSize)
+		}
+	}
+}
+
+func TestReplicaOverrides(t *testing.T) {
+	run := func(overrides map[hotstuff.ID]*orchestrationpb.ReplicaOpts) (*bytes.Buffer, error) {
+		controllerStream, workerStream := net.Pipe()
+
+		measurements := new(bytes.Buffer)
+		metricsLogger, err := modules.NewJSONLogger(measurements)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		workerProxy := orchestration.NewRemoteWorker(protostream.NewWriter(controllerStream), protostream.NewReader(controllerStream))
+		worker := orchestration.NewWorker(protostream.NewWriter(workerStream), protostream.NewReader(workerStream),
+			metricsLogger, []string{"throughput"}, 100*time.Millisecond)
+
+		experiment := &orchestration.Experiment{
+			Logger:      logging.New("ctrl"),
+			NumReplicas: 4,
+			NumClients:  2,
+			ClientOpts: &orchestrationpb.ClientOpts{
+				ConnectTimeout: durationpb.New(time.Second),
+				MaxConcurrent:  250,
+				PayloadSize:    100,
+			},
+			ReplicaOpts: &orchestrationpb.ReplicaOpts{
+				BatchSize:         100,
+				ConnectTimeout:    durationpb.New(time.Second),
+				InitialTimeout:    durationpb.New(100 * time.Millisecond),
+				TimeoutSamples:    1000,
+				TimeoutMultiplier: 1.2,
+				Consensus:         "chainedhotstuff",
+				Crypto:            "ecdsa",
+				LeaderRotation:    "round-robin",
+			},
+			ReplicaOverrides: overrides,
+			Duration:         time.Second,
+			Hosts:            map[string]orchestration.RemoteWorker{"127.0.0.1": workerProxy},
+		}
+
+		c := make(chan error)
+		go func() {
+			c <- worker.Run()
+		}()
+		err = experiment.Run()
+		if werr := <-c; werr != nil {
+			t.Fatal(werr)
+		}
+		if cerr := metricsLogger.Close(); cerr != nil {
+			t.Fatal(cerr)
+		}
+		return measurements, err
+	}
+
+	t.Run("MixedCache", func(t *testing.T) {
+		measurements, err := run(map[hotstuff.ID]*orchestrationpb.ReplicaOpts{
+			3: {CryptoCacheSize: proto.Uint32(0)},
+			4: {CryptoCacheSize: proto.Uint32(0)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		commits := commitCounter{commits: make(map[uint32]uint64)}
+		recorded := replicaModules{modules: make(map[uint32]map[string]string)}
+		if err := plotting.NewReader(measurements, &commits, &recorded).ReadAll(); err != nil {
+			t.Fatal(err)
+		}
+		for id := uint32(1); id <= 4; id++ {
+			if commits.commits[id] == 0 {
+				t.Errorf("replica %d did not commit anything", id)
+			}
+			want := "100"
+			if id > 2 {
+				want = "0"
+			}
+			if got := recorded.modules[id]["crypto-cache-size"]; got != want {
+				t.Errorf("replica %d: got crypto cache size '%s' in the start event, want '%s'", id, got, want)
+			}
+		}
+	})
+
+	t.Run("MixedRules", func(t *testing.T) {
+		measurements, err := run(map[hotstuff.ID]*orchestrationpb.ReplicaOpts{
+			3: {Consensus: "fasthotstuff"},
+			4: {Consensus: "fasthotstuff"},
+		})
+		if err == nil || !strings.Contains(err.Error(), backend.ErrRulesMismatch.Error()) {
+			t.Fatalf("expected the run to fail with a consensus rules mismatch, got: %v", err)
+		}
+		recorded := replicaModules{modules: make(map[uint32]map[string]string)}
+		if err := plotting.NewReader(measurements, &recorded).ReadAll(); err != nil {
+			t.Fatal(err)
+		}
+		if got := recorded.modules[3]["consensus"]; got != "fasthotstuff" {
+			t.Errorf("replica 3: got consensus '%s' in the start event, want 'fasthotstuff'", got)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, overrides := range []map[hotstuff.ID]*orchestrationpb.ReplicaOpts{
+			{5: {Consensus: "fasthotstuff"}},
+			{1: {Consensus: "no-such-rules"}},
+			{1: {ByzantineStrategy: "silence"}},
+		} {
+			experiment := &orchestration.Experiment{
+				Logger:           logging.New("ctrl"),
+				NumReplicas:      4,
+				ReplicaOpts:      validatedOpts(),
+				ClientOpts:       &orchestrationpb.ClientOpts{},
+				ReplicaOverrides: overrides,
+			}
+			if err := experiment.Validate(); err == nil {
+				t.Errorf("expected overrides %v to be rejected", overrides)
+			}
+		}
+	})
+}
+
+// replicaModules records the modules in the start events of the replicas.
+type replicaModules struct {
+	modules map[uint32]map[string]string
+}
+
+func (m *replicaModules) Add(msg interface{}) {
+	if e, ok := msg.(*types.StartEvent); ok && !e.GetEvent().GetClient() {
+		m.modules[e.GetEvent().GetID()] = e.GetModules()
+	}
+}
+
+func TestStalledExperiment(t *testing.T) {
+	output := t.TempDir()
+	worker, done := artifactWorker(t, t.TempDir(), "")
+	experiment := artifactExperiment(map[string]orchestration.RemoteWorker{"127.0.0.1": worker}, output)
+	// the replicas cannot form a quorum after two of them have crashed.
+	crash := []*orchestrationpb.Fault{{Action: "crash", Start: durationpb.New(500 * time.Millisecond)}}
+	experiment.Faults = map[hotstuff.ID][]*orchestrationpb.Fault{3: crash, 4: crash}
+	experiment.Duration = time.Minute
+	experiment.ProgressInterval = 100 * time.Millisecond
+	experiment.StallTimeout = time.Second
+
+	start := time.Now()
+	err := experiment.Run()
+	if err == nil || !strings.Contains(err.Error(), "aborted") {
+		t.Errorf("expected the stalled experiment to be aborted, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Second {
+		t.Errorf("the experiment was aborted after %v", elapsed)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// the measurements are collected when the experiment is aborted.
+	f, err := os.Open(filepath.Join(output, "127.0.0.1", "measurements.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	configs := startConfigs{configs: make(map[string]int)}
+	if err := plotting.NewReader(f, &configs).ReadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if n := configs.configs[""]; n != 6 {
+		t.Errorf("got %d start events in the collected measurements, want 6", n)
+	}
+}
+
+func TestWorkerReuse(t *testing.T) {
+	// the measurements of each run are logged to a separate buffer.
+	runs := make(map[string]*bytes.Buffer)
+	var current modules.MetricsLogger
+	newLogger := func(run string) (err error) {
+		runs[run] = new(bytes.Buffer)
+		current, err = modules.NewJSONLogger(runs[run])
+		return err
+	}
+	if err := newLogger(""); err != nil {
+		t.Fatal(err)
+	}
+	controllerStream, workerStream := net.Pipe()
+	worker := orchestration.NewWorker(protostream.NewWriter(workerStream), protostream.NewReader(workerStream),
+		current, []string{"throughput"}, 100*time.Millisecond)
+	worker.SetResetHandler(func(run string) (modules.MetricsLogger, error) {
+		if err := current.Close(); err != nil {
+			return nil, err
+		}
+		if err := newLogger(run); err != nil {
+			return nil, err
+		}
+		return current, nil
+	})
+	done := make(chan error, 1)
+	go func() { done <- worker.Run() }()
+	hosts := map[string]orchestration.RemoteWorker{
+		"127.0.0.1": orchestration.NewRemoteWorker(protostream.NewWriter(controllerStream), protostream.NewReader(controllerStream)),
+	}
+
+	experiment := artifactExperiment(hosts, "")
+	experiment.Config = "first"
+	experiment.KeepWorkers = true
+	if err := experiment.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := experiment.Reset("second"); err != nil {
+		t.Fatal(err)
+	}
+	experiment = artifactExperiment(hosts, "")
+	experiment.Config = "second"
+	if err := experiment.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := current.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for run, want := range map[string]string{"": "first", "second": "second"} {
+		configs := startConfigs{configs: make(map[string]int)}
+		if err := plotting.NewReader(bytes.NewReader(runs[run].Bytes()), &configs).ReadAll(); err != nil {
+			t.Fatalf("run '%s': %v", run, err)
+		}
+		if n := configs.configs[want]; n != 6 || len(configs.configs) != 1 {
+			t.Errorf("run '%s': got start events %v, want 6 of configuration '%s' only", run, configs.configs, want)
+		}
+	}
+}
+
+func TestWorkerResetWithoutHandler(t *testing.T) {
+	worker, done := artifactWorker(t, t.TempDir(), "")
+	experiment := artifactExperiment(map[string]orchestration.RemoteWorker{"127.0.0.1": worker}, "")
+	if err := experiment.Reset("next"); err == nil {
+		t.Error("expected the worker to reject the reset")
+	}
+	if err := worker.Quit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// unresponsiveWorker starts a worker that stops replying after it has started its clients. If disconnect is set,
+// the connection to the controller is closed instead. The worker is shut down when the test ends.
+func unresponsiveWorker(t *testing.T, disconnect bool) orchestration.RemoteWorker {
+	t.Helper()
+	controllerStream, relayStream := net.Pipe()
+	relayWorkerStream, workerStream := net.Pipe()
+	worker := orchestration.NewWorker(protostream.NewWriter(workerStream), protostream.NewReader(workerStream),
+		modules.NopLogger(), nil, 0)
+	go worker.Run()
+	t.Cleanup(func() {
+		worker.Shutdown()
+		relayWorkerStream.Close()
+		controllerStream.Close()
+	})
+
+	// the requests and replies are relayed between the controller and the worker until the clients are started.
+	go func() {
+		recv, send := protostream.NewReader(relayStream), protostream.NewWriter(relayStream)
+		toWorker, fromWorker := protostream.NewWriter(relayWorkerStream), protostream.NewReader(relayWorkerStream)
+		for {
+			req, err := recv.ReadAny()
+			if err != nil {
+				return
+			}
+			if err := toWorker.WriteAny(req); err != nil {
+				return
+			}
+			res, err := fromWorker.ReadAny()
+			if err != nil {
+				return
+			}
+			if err := send.WriteAny(res); err != nil {
+				return
+			}
+			if _, ok := req.(*orchestrationpb.StartClientRequest); ok {
+				break
+			}
+		}
+		if disconnect {
+			relayStream.Close()
+			return
+		}
+		// the requests are read, but never answered.
+		for {
+			if _, err := recv.ReadAny(); err != nil {
+				return
+			}
+		}
+	}()
+	return orchestration.NewRemoteWorker(protostream.NewWriter(controllerStream), protostream.NewReader(controllerStream))
+}
+
+func TestUnresponsiveWorkers(t *testing.T) {
+	output := t.TempDir()
+	worker, done := artifactWorker(t, t.TempDir(), "")
+	hosts := map[string]orchestration.RemoteWorker{
+		"127.0.0.1": worker,
+		"localhost": unresponsiveWorker(t, false),
+		"127.0.0.2": unresponsiveWorker(t, true),
+	}
+	experiment := artifactExperiment(hosts, output)
+	experiment.HostConfigs = map[string]orchestration.HostConfig{
+		"127.0.0.1": {Replicas: 2, Clients: 1},
+		"localhost": {Replicas: 1, Clients: 1},
+		"127.0.0.2": {Replicas: 1},
+	}
+	experiment.Duration = 2 * time.Second
+	experiment.ProgressInterval = 100 * time.Millisecond
+	experiment.Timeouts = orchestration.Timeouts{
+		Start:      5 * time.Second,
+		Run:        200 * time.Millisecond,
+		RunRetries: 2,
+		Stop:       5 * time.Second,
+		Collect:    5 * time.Second,
+	}
+
+	start := time.Now()
+	err := experiment.Run()
+	if !errors.Is(err, orchestration.ErrMissingData) {
+		t.Fatalf("expected the run to report missing data, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Second {
+		t.Errorf("the run took %v", elapsed)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	report := experiment.Report()
+	if len(report.Missing) != 2 {
+		t.Fatalf("got report %v, want two missing hosts", report)
+	}
+	for i, host := range []string{"127.0.0.2", "localhost"} {
+		if m := report.Missing[i]; m.Host != host || m.Phase != orchestration.RunPhase {
+			t.Errorf("got missing host %s in phase %s, want %s in phase %s", m.Host, m.Phase, host, orchestration.RunPhase)
+		}
+	}
+
+	// the data of the responsive worker are complete.
+	f, err := os.Open(filepath.Join(output, "127.0.0.1", "measurements.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	configs := startConfigs{configs: make(map[string]int)}
+	if err := plotting.NewReader(f, &configs).ReadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if n := configs.configs[""]; n != 3 {
+		t.Errorf("got %d start events from the responsive worker, want 3", n)
+	}
+	for _, host := range []string{"127.0.0.2", "localhost"} {
+		if _, err := os.Stat(filepath.Join(output, host)); !os.IsNotExist(err) {
+			t.Errorf("unexpected artifacts from %s: %v", host, err)
+		}
+	}
+}
+
+// startConfigs counts the start events that record each configuration of the experiment.
+type startConfigs struct {
+	configs map[string]int
+}
+
+func (c *startConfigs) Add(msg interface{}) {
+	if m, ok := msg.(*types.StartEvent); ok {
+		c.configs[m.GetConfig()]++
+	}
+}
+
+// seededRun records the measurements of a run that are determined by the seeds of the experiment.
+type seededRun struct {
+	checkpoints map[uint32]map[uint64][]byte // the checkpoint hashes of each replica, by index
+	seeds       map[string]int64             // the seeds in the start events, by replica or client
+	types       map[string]bool              // the types of the measurements
+}
+
+func (r *seededRun) Add(msg interface{}) {
+	r.types[fmt.Sprintf("%T", msg)] = true
+	switch m := msg.(type) {
+	case *types.StartEvent:
+		r.seeds[fmt.Sprintf("client=%t id=%d", m.GetEvent().GetClient(), m.GetEvent().GetID())] = m.GetSeed()
+		r.seeds["master"] = m.GetMasterSeed()
+	case *types.CheckpointEvent:
+		id := m.GetEvent().GetID()
+		if r.checkpoints[id] == nil {
+			r.checkpoints[id] = make(map[uint64][]byte)
+		}
+		r.checkpoints[id][m.GetIndex()] = m.GetHash()
+	}
+}
+
+// runSeeded runs a local experiment whose commands are committed in a deterministic order,
+// and returns the measurements that are determined by the seed.
+func runSeeded(t *testing.T, seed int64) seededRun {
+	t.Helper()
+	var buf bytes.Buffer
+	metricsLogger, err := modules.NewJSONLogger(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	controllerStream, workerStream := net.Pipe()
+	worker := orchestration.NewWorker(protostream.NewWriter(workerStream), protostream.NewReader(workerStream),
+		metricsLogger, []string{"throughput"}, 100*time.Millisecond)
+	c := make(chan error, 1)
+	go func() { c <- worker.Run() }()
+
+	experiment := artifactExperiment(map[string]orchestration.RemoteWorker{
+		"127.0.0.1": orchestration.NewRemoteWorker(protostream.NewWriter(controllerStream), protostream.NewReader(controllerStream)),
+	}, "")
+	experiment.Seed = seed
+	// the commands of a single client are committed in the order that they are generated, one command per block.
+	experiment.NumClients = 1
+	experiment.ClientOpts.MaxConcurrent = 4
+	experiment.ClientOpts.Workload = "kv"
+	experiment.ClientOpts.KVReadRatio = 0.2
+	experiment.ClientOpts.KVDeleteRatio = 0.2
+	experiment.ClientOpts.KVCheckpointInterval = 10
+	experiment.ReplicaOpts.Application = "kvstore"
+	experiment.ReplicaOpts.BatchSize = 1
+	experiment.ReplicaOpts.Jitter = durationpb.New(time.Millisecond)
+
+	if err := experiment.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-c; err != nil {
+		t.Fatal(err)
+	}
+	if err := metricsLogger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	run := seededRun{
+		checkpoints: make(map[uint32]map[uint64][]byte),
+		seeds:       make(map[string]int64),
+		types:       make(map[string]bool),
+	}
+	if err := plotting.NewReader(&buf, &run).ReadAll(); err != nil {
+		t.Fatal(err)
+	}
+	return run
+}
+
+// sameCheckpoints returns the number of checkpoints that the replicas recorded in both runs,
+// and false if any of them differ.
+func sameCheckpoints(a, b seededRun) (int, bool) {
+	common := 0
+	for id, checkpoints := range a.checkpoints {
+		for index, hash := range checkpoints {
+			other, ok := b.checkpoints[id][index]
+			if !ok {
+				continue
+			}
+			if !bytes.Equal(hash, other) {
+				return common, false
+			}
+			common++
+		}
+	}
+	return common, true
+}
+
+func TestSeededRuns(t *testing.T) {
+	const seed = 42
+	first := runSeeded(t, seed)
+	second := runSeeded(t, seed)
+
+	if common, ok := sameCheckpoints(first, second); !ok {
+		t.Error("the runs with the same seed recorded different checkpoints")
+	} else if common == 0 {
+		t.Error("the runs with the same seed recorded no common checkpoints")
+	}
+	if master := first.seeds["master"]; master != seed {
+		t.Errorf("got master seed %d in the start events, want %d", master, seed)
+	}
+	if len(first.seeds) != 6 {
+		t.Errorf("got the seeds of %d replicas and clients, want 5", len(first.seeds)-1)
+	}
+	for name, s := range first.seeds {
+		if second.seeds[name] != s {
+			t.Errorf("%s: got seed %d in the second run, want %d", name, second.seeds[name], s)
+		}
+	}
+	for typ := range first.types {
+		if !second.types[typ] {
+			t.Errorf("the second run has no measurements of type %s", typ)
+		}
+	}
+	for typ := range second.types {
+		if !first.types[typ] {
+			t.Errorf("the first run has no measurements of type %s", typ)
+		}
+	}
+
+	other := runSeeded(t, seed+1)
+	if common, ok := sameCheckpoints(first, other); ok && common > 0 {
+		t.Error("the runs with different seeds recorded the same checkpoints")
+	}
+}
+
+// startLimits records the limits of the process in the start events of the replicas and clients.
+type startLimits struct {
+	maxProcs map[string]uint32
+}
+
+func (l *startLimits) Add(msg interface{}) {
+	if m, ok := msg.(*types.StartEvent); ok {
+		l.maxProcs[fmt.Sprintf("client=%t id=%d", m.GetEvent().GetClient(), m.GetEvent().GetID())] = m.GetMaxProcs()
+	}
+}
+
+func TestResourceLimits(t *testing.T) {
+	var buf bytes.Buffer
+	metricsLogger, err := modules.NewJSONLogger(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	controllerStream, workerStream := net.Pipe()
+	worker := orchestration.NewWorker(protostream.NewWriter(workerStream), protostream.NewReader(workerStream),
+		metricsLogger, nil, 0)
+	c := make(chan error, 1)
+	go func() { c <- worker.Run() }()
+
+	experiment := artifactExperiment(map[string]orchestration.RemoteWorker{
+		"127.0.0.1": orchestration.NewRemoteWorker(protostream.NewWriter(controllerStream), protostream.NewReader(controllerStream)),
+	}, "")
+	experiment.ReplicaResources = map[hotstuff.ID]*orchestrationpb.Resources{
+		1: {MaxProcs: 2},
+		2: {MaxProcs: 1},
+	}
+	experiment.ClientResources = map[hotstuff.ID]*orchestrationpb.Resources{1: {MaxProcs: 1}}
+
+	defaultProcs := runtime.GOMAXPROCS(0)
+	if err := experiment.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-c; err != nil {
+		t.Fatal(err)
+	}
+	if procs := runtime.GOMAXPROCS(0); procs != defaultProcs {
+		t.Errorf("GOMAXPROCS is %d after the experiment, want %d", procs, defaultProcs)
+	}
+	if err := metricsLogger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	limits := startLimits{maxProcs: make(map[string]uint32)}
+	if err := plotting.NewReader(&buf, &limits).ReadAll(); err != nil {
+		t.Fatal(err)
+	}
+	// the limits of the replicas are combined when they are created, and those of the clients are added to them.
+	want := map[string]uint32{
+		"client=false id=1": 3, "client=false id=2": 3, "client=false id=3": 3, "client=false id=4": 3,
+		"client=true id=1": 4, "client=true id=2": 4,
+	}
+	for name, procs := range want {
+		if got, ok := limits.maxProcs[name]; !ok {
+			t.Errorf("%s: no start event", name)
+		} else if got != procs {
+			t.Errorf("%s: got GOMAXPROCS %d in the start event, want %d", name, got, procs)
+		}
+	}
+}
+
+// validatedOpts returns the replica options of the experiments that fail validation, or that have no hosts,
+// such that they are not rejected for their modules.
+func validatedOpts() *orchestrationpb.ReplicaOpts {
+	return &orchestrationpb.ReplicaOpts{Consensus: "chainedhotstuff", Crypto: "ecdsa", LeaderRotation: "round-robin"}
+}
+
+func TestByzantineAssignment(t *testing.T) {
+	newExperiment := func(byzantine map[string]int, byzantineReplicas map[hotstuff.ID]string) *orchestration.Experiment {
+		return &orchestration.Experiment{
+			Logger:            logging.New("ctrl"),
+			NumReplicas:       4,
+			ReplicaOpts:       validatedOpts(),
+			ClientOpts:        &orchestrationpb.ClientOpts{},
+			Hosts:             map[string]orchestration.RemoteWorker{},
+			Byzantine:         byzantine,
+			ByzantineReplicas: byzantineReplicas,
+		}
+	}
+
+	err := newExperiment(map[string]int{"no-such-strategy": 1}, nil).Run()
+	if err == nil || !strings.Contains(err.Error(), "silence") || !strings.Contains(err.Error(), "fork") {
+		t.Errorf("expected an error listing the valid strategies, got: %v", err)
+	}
+	err = newExperiment(nil, map[hotstuff.ID]string{5: "silence"}).Run()
+	if err == nil {
+		t.Error("expected an error for a replica that does not exist")
+	}
+	err = newExperiment(map[string]int{"silence": 4}, map[hotstuff.ID]string{2: "fork"}).Run()
+	if err == nil {
+		t.Error("expected an error when there are more byzantine replicas than replicas")
+	}
+}
 
-		c := make(chan error)
-		go func() {
-			c <- worker.Run()
-		}()
+// commitRates records the throughput measurements of a replica, such that the rate of commits can be computed for
+// intervals of the experiment.
+type commitRates struct {
+	id           uint32
+	start        time.Time
+	measurements []*types.ThroughputMeasurement
+}
 
-		err := experiment.Run()
-		if err != nil {
-			t.Fatal(err)
+func (c *commitRates) Add(msg interface{}) {
+	switch m := msg.(type) {
+	case *types.StartEvent:
+		if !m.GetEvent().GetClient() && m.GetEvent().GetID() == c.id {
+			c.start = m.GetEvent().GetTimestamp().AsTime()
+		}
+	case *types.ThroughputMeasurement:
+		if !m.GetEvent().GetClient() && m.GetEvent().GetID() == c.id {
+			c.measurements = append(c.measurements, m)
 		}
+	}
+}
 
-		err = <-c
-		if err != nil {
-			t.Fatal(err)
+// rate returns the commits per second of the measurements taken between from and to after the start of the replica.
+func (c *commitRates) rate(from, to time.Duration) float64 {
+	var (
+		commits  uint64
+		duration time.Duration
+	)
+	for _, m := range c.measurements {
+		t := m.GetEvent().GetTimestamp().AsTime().Sub(c.start)
+		if t > from && t <= to {
+			commits += m.GetCommits()
+			duration += m.GetDuration().AsDuration()
+		}
+	}
+	if duration == 0 {
+		return 0
+	}
+	return float64(commits) / duration.Seconds()
+}
+
+func TestFaultSchedule(t *testing.T) {
+	controllerStream, workerStream := net.Pipe()
+
+	var measurements bytes.Buffer
+	metricsLogger, err := modules.NewJSONLogger(&measurements)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workerProxy := orchestration.NewRemoteWorker(protostream.NewWriter(controllerStream), protostream.NewReader(controllerStream))
+	worker := orchestration.NewWorker(protostream.NewWriter(workerStream), protostream.NewReader(workerStream),
+		metricsLogger, []string{"throughput"}, 100*time.Millisecond)
+
+	experiment := &orchestration.Experiment{
+		Logger:      logging.New("ctrl"),
+		NumReplicas: 4,
+		NumClients:  2,
+		ClientOpts: &orchestrationpb.ClientOpts{
+			ConnectTimeout: durationpb.New(time.Second),
+			MaxConcurrent:  100,
+			PayloadSize:    100,
+			// the commands of the proposals that are abandoned when views time out are sent again.
+			RequestTimeout:    durationpb.New(500 * time.Millisecond),
+			MaxRequestTimeout: durationpb.New(time.Second),
+			MaxRetries:        10,
+		},
+		ReplicaOpts: &orchestrationpb.ReplicaOpts{
+			BatchSize:         20,
+			ConnectTimeout:    durationpb.New(time.Second),
+			InitialTimeout:    durationpb.New(100 * time.Millisecond),
+			TimeoutSamples:    1000,
+			TimeoutMultiplier: 1.2,
+			Consensus:         "chainedhotstuff",
+			Crypto:            "ecdsa",
+			LeaderRotation:    "round-robin",
+			// the restarted replica catches up by state transfer, and receives the commands that are forwarded by
+			// the other replicas until the clients reconnect to it.
+			SnapshotInterval: 10,
+			ForwardCommands:  true,
+		},
+		// replica 2 is down from 1s to 2s, such that every fourth view times out, and is then restarted.
+		Faults: map[hotstuff.ID][]*orchestrationpb.Fault{
+			2: {{Action: "crash", Start: durationpb.New(time.Second), Duration: durationpb.New(time.Second)}},
+		},
+		Duration: 5 * time.Second,
+		Hosts:    map[string]orchestration.RemoteWorker{"127.0.0.1": workerProxy},
+	}
+
+	c := make(chan error)
+	go func() {
+		c <- worker.Run()
+	}()
+
+	err = experiment.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = <-c
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = metricsLogger.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	faults := plotting.NewFaults()
+	rates := commitRates{id: 1}
+	err = plotting.NewReader(&measurements, &faults, &rates).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := faults.Faults()
+	if len(got) != 2 || got[0].ID != 2 || got[0].Action != "crash" || got[1].ID != 2 || got[1].Action != "restart" {
+		t.Fatalf("expected a crash and a restart of replica 2 to be recorded, got: %v", &faults)
+	}
+	for i, want := range []time.Duration{time.Second, 2 * time.Second} {
+		if offset := got[i].Offset; offset < want || offset > want+200*time.Millisecond {
+			t.Errorf("the %s was injected at %v, want %v", got[i].Action, offset, want)
 		}
 	}
 
-	t.Run("ChainedHotStuff+ECDSA", func(t *testing.T) { run("chainedhotstuff", "ecdsa") })
-	t.Run("ChainedHotStuff+BLS12", func(t *testing.T) { run("chainedhotstuff", "bls12") })
-	t.Run("Fast-HotStuff+ECDSA", func(t *testing.T) { run("fasthotstuff", "ecdsa") })
-	t.Run("Fast-HotStuff+BLS12", func(t *testing.T) { run("fasthotstuff", "bls12") })
-	t.Run("Simple-HotStuff+ECDSA", func(t *testing.T) { run("simplehotstuff", "ecdsa") })
-	t.Run("Simple-HotStuff+BLS12", func(t *testing.T) { run("simplehotstuff", "bls12") })
+	before := rates.rate(300*time.Millisecond, time.Second)
+	during := rates.rate(1200*time.Millisecond, 2*time.Second)
+	after := rates.rate(3500*time.Millisecond, 5*time.Second)
+	t.Logf("commits per second before: %.1f, during: %.1f, after: %.1f the crash", before, during, after)
+	if during >= before/2 {
+		t.Errorf("the throughput did not dip while replica 2 was down")
+	}
+	if after <= 2*during {
+		t.Errorf("the throughput did not recover after replica 2 was restarted")
+	}
+}
+
+func TestFaultValidation(t *testing.T) {
+	fault := func(action string, start, duration time.Duration) *orchestrationpb.Fault {
+		return &orchestrationpb.Fault{Action: action, Start: durationpb.New(start), Duration: durationpb.New(duration)}
+	}
+	shardPause := fault("pause", time.Second, time.Second)
+	shardPause.Shard = 2
+	tests := []struct {
+		name   string
+		faults map[hotstuff.ID][]*orchestrationpb.Fault
+		shards uint32
+	}{
+		{"UnknownAction", map[hotstuff.ID][]*orchestrationpb.Fault{1: {fault("explode", time.Second, 0)}}, 0},
+		{"UnknownReplica", map[hotstuff.ID][]*orchestrationpb.Fault{5: {fault("crash", time.Second, 0)}}, 0},
+		{"AfterExperiment", map[hotstuff.ID][]*orchestrationpb.Fault{1: {fault("pause", 9*time.Second, 2*time.Second)}}, 0},
+		{"PauseWithoutDuration", map[hotstuff.ID][]*orchestrationpb.Fault{1: {fault("pause", time.Second, 0)}}, 0},
+		{"RestartWithoutCrash", map[hotstuff.ID][]*orchestrationpb.Fault{1: {fault("restart", time.Second, 0)}}, 0},
+		{"Overlap", map[hotstuff.ID][]*orchestrationpb.Fault{1: {fault("pause", time.Second, 2*time.Second), fault("crash", 2*time.Second, 0)}}, 0},
+		{"CrashWhileCrashed", map[hotstuff.ID][]*orchestrationpb.Fault{1: {fault("crash", 2*time.Second, 0), fault("crash", time.Second, 0)}}, 0},
+		{"CrashWithShards", map[hotstuff.ID][]*orchestrationpb.Fault{1: {fault("crash", time.Second, 0)}}, 2},
+		{"UnknownShard", map[hotstuff.ID][]*orchestrationpb.Fault{1: {shardPause}}, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := validatedOpts()
+			opts.Shards = test.shards
+			err := (&orchestration.Experiment{
+				Logger:      logging.New("ctrl"),
+				NumReplicas: 4,
+				Duration:    10 * time.Second,
+				ReplicaOpts: opts,
+				ClientOpts:  &orchestrationpb.ClientOpts{},
+				Hosts:       map[string]orchestration.RemoteWorker{},
+				Faults:      test.faults,
+			}).Run()
+			if err == nil {
+				t.Error("expected the fault schedule to be rejected")
+			}
+		})
+	}
+
+	// the faults are sorted by their start times, such that a restart may be listed before its crash.
+	// the experiment has no hosts, so it only waits for its duration.
+	const ms = time.Millisecond
+	err := (&orchestration.Experiment{
+		Logger:      logging.New("ctrl"),
+		NumReplicas: 4,
+		Duration:    100 * ms,
+		ReplicaOpts: validatedOpts(),
+		ClientOpts:  &orchestrationpb.ClientOpts{},
+		Hosts:       map[string]orchestration.RemoteWorker{},
+		Faults: map[hotstuff.ID][]*orchestrationpb.Fault{
+			1: {fault("restart", 50*ms, 0), fault("crash", 20*ms, 0), fault("pause", 70*ms, 10*ms)},
+			2: {fault("crash", 10*ms, 20*ms), fault("pause", 30*ms, 10*ms)},
+		},
+	}).Run()
+	if err != nil {
+		t.Errorf("expected a valid fault schedule, got: %v", err)
+	}
 }
 
 func TestDeployment(t *testing.T) {
@@ -156,12 +1276,17 @@ func findProjectRoot(t *testing.T) string {
 }
 
 func compileBinary(t *testing.T) string {
+	// assume docker host is using the same architecture
+	return buildBinary(t, "linux")
+}
+
+// buildBinary builds the hotstuff command for the given operating system.
+func buildBinary(t *testing.T, goos string) string {
 	dir := t.TempDir()
 	exe := filepath.Join(dir, "hotstuff")
 	cmd := exec.Command("go", "build", "-o", exe, "./cmd/hotstuff")
 	cmd.Dir = findProjectRoot(t)
-	// assume docker host is using the same architecture
-	cmd.Env = append(os.Environ(), "GOOS=linux", "GOARCH="+runtime.GOARCH)
+	cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+runtime.GOARCH)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		t.Logf("%s", out)
@@ -169,3 +1294,485 @@ func compileBinary(t *testing.T) string {
 	}
 	return exe
 }
+
+func TestStandaloneReplicas(t *testing.T) {
+	const n = 4
+	dir := t.TempDir()
+	caKey, ca, err := keygen.GenerateCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := make(map[uint32]*orchestrationpb.ReplicaOpts, n)
+	peers := make(map[uint32]*orchestrationpb.ReplicaInfo, n)
+	clientInfos := make([]backend.ReplicaInfo, 0, n)
+	for id := uint32(1); id <= n; id++ {
+		keyChain, err := keygen.GenerateKeyChain(hotstuff.ID(id), []string{"localhost"}, "ecdsa", ca, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts[id] = &orchestrationpb.ReplicaOpts{
+			ID:                id,
+			PrivateKey:        keyChain.PrivateKey,
+			BatchSize:         1,
+			ConnectTimeout:    durationpb.New(time.Second),
+			InitialTimeout:    durationpb.New(100 * time.Millisecond),
+			TimeoutSamples:    1000,
+			TimeoutMultiplier: 1.2,
+			Consensus:         "chainedhotstuff",
+			Crypto:            "ecdsa",
+			LeaderRotation:    "round-robin",
+			UnixSocketDir:     dir,
+		}
+		peers[id] = &orchestrationpb.ReplicaInfo{
+			ID:            id,
+			PublicKey:     keyChain.PublicKey,
+			ReplicaSocket: backend.UnixScheme + filepath.Join(dir, fmt.Sprintf("replica-%d.sock", id)),
+		}
+		pubKey, err := keygen.ParsePublicKey(keyChain.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientInfos = append(clientInfos, backend.ReplicaInfo{
+			ID:      hotstuff.ID(id),
+			Address: backend.UnixScheme + filepath.Join(dir, fmt.Sprintf("client-%d.sock", id)),
+			PubKey:  pubKey,
+		})
+	}
+
+	// an invalid peer configuration is reported before the replica listens.
+	invalid := map[uint32]*orchestrationpb.ReplicaInfo{1: peers[1], 2: {ID: 2, ReplicaSocket: peers[2].GetReplicaSocket()}}
+	if _, err := orchestration.NewStandaloneReplica(opts[1], invalid, modules.NopLogger(), nil, 0); err == nil {
+		t.Fatal("expected an error for a peer without a public key")
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) > 0 {
+		t.Fatalf("the replica listened despite the invalid configuration: %v %v", entries, err)
+	}
+
+	// the replicas are started concurrently, as if they were separate processes, and wait for each other.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	replicas := make([]*orchestration.StandaloneReplica, 0, n)
+	errs := make(chan error, n)
+	for id := uint32(1); id <= n; id++ {
+		r, err := orchestration.NewStandaloneReplica(opts[id], peers, modules.NopLogger(), nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replicas = append(replicas, r)
+		go func() { errs <- r.Start(ctx) }()
+		time.Sleep(100 * time.Millisecond)
+	}
+	for range replicas {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cli := client.New(client.Config{
+		ID:             n + 1,
+		MaxConcurrent:  10,
+		PayloadSize:    10,
+		Input:          client.NewPayloadReader(1, false),
+		ManagerOptions: []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+	}, modules.NewBuilder(n+1))
+	if err := cli.Connect(clientInfos); err != nil {
+		t.Fatal(err)
+	}
+	cli.Start()
+	time.Sleep(time.Second)
+	cli.Stop()
+
+	emptyHash := sha256.New().Sum(nil)
+	for i, r := range replicas {
+		hash, err := r.Shutdown()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(hash, emptyHash) {
+			t.Errorf("replica %d did not execute any commands", i+1)
+		}
+	}
+}
+
+// replicaStatus is the part of the status of a replica that is compared by TestKilledReplicaRejoins.
+type replicaStatus struct {
+	CommittedView    uint64 `json:"committed_view"`
+	ExecutedCommands uint64 `json:"executed_commands"`
+	StateHash        string `json:"state_hash"`
+	Events           map[string]struct {
+		Count uint64 `json:"count"`
+	} `json:"events"`
+}
+
+func getStatus(port uint32) (status replicaStatus, err error) {
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/status", port))
+	if err != nil {
+		return status, err
+	}
+	defer resp.Body.Close()
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	return status, err
+}
+
+// TestKilledReplicaRejoins kills the process of a replica with SIGKILL at different times after it was started,
+// and restarts it with its data directory each time. The replica leads the first view, so it would equivocate if it
+// proposed again in the views that it voted in. Once the load stops, the replica must reach the state of the others
+// without executing a command twice, which would change the hash of its executed commands.
+func TestKilledReplicaRejoins(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the hotstuff command")
+	}
+	exe := buildBinary(t, runtime.GOOS)
+
+	const (
+		n      = 4
+		killed = 2 // the leader of the first view under round-robin
+	)
+	dir := t.TempDir()
+	dataDir := t.TempDir()
+	caKey, ca, err := keygen.GenerateCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the killed replica listens on the same ports every time it is started.
+	freePort := func() uint32 {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer lis.Close()
+		return uint32(lis.Addr().(*net.TCPAddr).Port)
+	}
+	killedReplicaPort, killedClientPort, killedStatusPort := freePort(), freePort(), freePort()
+
+	opts := make(map[uint32]*orchestrationpb.ReplicaOpts, n)
+	peers := make(map[uint32]*orchestrationpb.ReplicaInfo, n)
+	clientInfos := make([]backend.ReplicaInfo, 0, n)
+	args := []string{"replica",
+		"--id", fmt.Sprint(killed),
+		"--private-key", filepath.Join(dir, fmt.Sprintf("%d.key", killed)),
+		"--replica-listen", fmt.Sprintf("127.0.0.1:%d", killedReplicaPort),
+		"--client-listen", fmt.Sprintf("127.0.0.1:%d", killedClientPort),
+		"--status-listen", fmt.Sprintf("127.0.0.1:%d", killedStatusPort),
+		"--view-timeout", "100ms",
+		"--connect-timeout", "1s",
+		"--application", "kvstore",
+		"--data-dir", dataDir,
+		"--durable-snapshot-views", "5",
+		"--persist-commands",
+	}
+	for id := uint32(1); id <= n; id++ {
+		keyChain, err := keygen.GenerateKeyChain(hotstuff.ID(id), []string{"localhost"}, "ecdsa", ca, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		publicKey := filepath.Join(dir, fmt.Sprintf("%d.pub", id))
+		if err := os.WriteFile(publicKey, keyChain.PublicKey, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.key", id)), keyChain.PrivateKey, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		peers[id] = &orchestrationpb.ReplicaInfo{ID: id, PublicKey: keyChain.PublicKey}
+		clientAddress := backend.UnixScheme + filepath.Join(dir, fmt.Sprintf("client-%d.sock", id))
+		if id == killed {
+			peers[id].Address, peers[id].ReplicaPort = "127.0.0.1", killedReplicaPort
+			clientAddress = fmt.Sprintf("127.0.0.1:%d", killedClientPort)
+			args = append(args, "--peer", fmt.Sprintf("%d=127.0.0.1:%d=%s", id, killedReplicaPort, publicKey))
+		} else {
+			peers[id].ReplicaSocket = backend.UnixScheme + filepath.Join(dir, fmt.Sprintf("replica-%d.sock", id))
+			args = append(args, "--peer", fmt.Sprintf("%d=%s=%s", id, peers[id].ReplicaSocket, publicKey))
+		}
+		opts[id] = &orchestrationpb.ReplicaOpts{
+			ID:                  id,
+			PrivateKey:          keyChain.PrivateKey,
+			BatchSize:           1,
+			ConnectTimeout:      durationpb.New(time.Second),
+			InitialTimeout:      durationpb.New(100 * time.Millisecond),
+			TimeoutSamples:      1000,
+			TimeoutMultiplier:   1.2,
+			Consensus:           "chainedhotstuff",
+			Crypto:              "ecdsa",
+			LeaderRotation:      "round-robin",
+			UnixSocketDir:       dir,
+			Application:         "kvstore",
+			StatusListenAddress: "127.0.0.1:0",
+		}
+		pubKey, err := keygen.ParsePublicKey(keyChain.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientInfos = append(clientInfos, backend.ReplicaInfo{ID: hotstuff.ID(id), Address: clientAddress, PubKey: pubKey})
+	}
+
+	var output bytes.Buffer
+	start := func() *exec.Cmd {
+		cmd := exec.Command(exe, args...)
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		return cmd
+	}
+	cmd := start()
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+		if t.Failed() {
+			t.Logf("output of replica %d:\n%s", killed, output.String())
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	statusPorts := make(map[uint32]uint32, n)
+	errs := make(chan error, n-1)
+	for id := uint32(1); id <= n; id++ {
+		if id == killed {
+			continue
+		}
+		r, err := orchestration.NewStandaloneReplica(opts[id], peers, modules.NopLogger(), nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		statusPorts[id] = r.Info().GetStatusPort()
+		t.Cleanup(func() { r.Shutdown() })
+		go func() { errs <- r.Start(ctx) }()
+	}
+	for i := 0; i < n-1; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cli := client.New(client.Config{
+		ID:             n + 1,
+		MaxConcurrent:  10,
+		PayloadSize:    10,
+		Input:          client.NewPayloadReader(1, false),
+		Workload:       client.KVWorkload,
+		KVReadRatio:    0.2,
+		KVDeleteRatio:  0.2,
+		ManagerOptions: []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+	}, modules.NewBuilder(n+1))
+	if err := cli.Connect(clientInfos); err != nil {
+		t.Fatal(err)
+	}
+	cli.Start()
+
+	// the replica is killed while it recovers, while it catches up, and while it keeps up with the others.
+	for _, delay := range []time.Duration{20 * time.Millisecond, 300 * time.Millisecond, time.Second, 2 * time.Second} {
+		time.Sleep(delay)
+		cmd.Process.Kill() // SIGKILL, as kill -9
+		cmd.Wait()
+		cmd = start()
+	}
+
+	poll := func(what string, timeout time.Duration, cond func() (bool, error)) {
+		t.Helper()
+		deadline := time.Now().Add(timeout)
+		for {
+			ok, err := cond()
+			if ok {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s: %v", what, err)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	poll("the restarted replica to execute commands", 20*time.Second, func() (bool, error) {
+		status, err := getStatus(killedStatusPort)
+		return err == nil && status.ExecutedCommands > 0, err
+	})
+	cli.Stop()
+
+	var want replicaStatus
+	poll("the restarted replica to reach the state of replica 1", 20*time.Second, func() (bool, error) {
+		if want, err = getStatus(statusPorts[1]); err != nil {
+			return false, err
+		}
+		got, err := getStatus(killedStatusPort)
+		if err != nil {
+			return false, err
+		}
+		return got.StateHash == want.StateHash && got.ExecutedCommands == want.ExecutedCommands,
+			fmt.Errorf("replica %d executed %d commands with the hash %.8s, but replica 1 executed %d with the hash %.8s",
+				killed, got.ExecutedCommands, got.StateHash, want.ExecutedCommands, want.StateHash)
+	})
+	if want.ExecutedCommands == 0 {
+		t.Error("no commands were executed")
+	}
+	for id, port := range statusPorts {
+		status, err := getStatus(port)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count := status.Events["consensus.EquivocationEvent"].Count; count > 0 {
+			t.Errorf("replica %d detected %d equivocations", id, count)
+		}
+	}
+}
+
+// workerProcess starts a worker in a process of its own, which writes its measurements to a file in dir.
+// The returned channel receives the error of the process when it exits.
+func workerProcess(t *testing.T, exe, dir string) (orchestration.RemoteWorker, <-chan error) {
+	t.Helper()
+	cmd := exec.Command(exe, "worker",
+		"--data-path", filepath.Join(dir, "measurements.json"),
+		"--metrics", "throughput",
+		"--measurement-interval", "100ms",
+	)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	c := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		if err != nil {
+			err = fmt.Errorf("%w: %s", err, stderr.String())
+		}
+		c <- err
+	}()
+	t.Cleanup(func() { cmd.Process.Kill() })
+	return orchestration.NewRemoteWorker(protostream.NewWriter(stdin), protostream.NewReader(stdout)), c
+}
+
+// shardCommits records the commits measured by the shards of a replica, by the time since the start of the replica.
+type shardCommits struct {
+	start   time.Time
+	commits map[uint32]map[time.Duration]uint64 // by shard
+}
+
+func (c *shardCommits) Add(msg interface{}) {
+	switch m := msg.(type) {
+	case *types.StartEvent:
+		if !m.GetEvent().GetClient() {
+			c.start = m.GetEvent().GetTimestamp().AsTime()
+		}
+	case *types.ThroughputMeasurement:
+		if m.GetEvent().GetClient() {
+			return
+		}
+		shard := m.GetEvent().GetInstance()
+		if c.commits[shard] == nil {
+			c.commits[shard] = make(map[time.Duration]uint64)
+		}
+		c.commits[shard][m.GetEvent().GetTimestamp().AsTime().Sub(c.start)] += m.GetCommits()
+	}
+}
+
+// count returns the commits of the shard that were measured between from and to after the start of the replica.
+func (c *shardCommits) count(shard uint32, from, to time.Duration) (commits uint64) {
+	for t, n := range c.commits[shard] {
+		if t > from && t <= to {
+			commits += n
+		}
+	}
+	return commits
+}
+
+func TestShardedWorkers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the hotstuff command")
+	}
+	exe := buildBinary(t, runtime.GOOS)
+
+	// each of the 4 replicas runs in a worker process of its own, and runs 2 shards.
+	const n = 4
+	hosts := make(map[string]orchestration.RemoteWorker, n)
+	hostConfigs := make(map[string]orchestration.HostConfig, n)
+	hostAddresses := make(map[string]string, n)
+	dirs := make(map[string]string, n)
+	done := make([]<-chan error, 0, n)
+	for i := 1; i <= n; i++ {
+		host := fmt.Sprintf("worker%d", i)
+		dirs[host] = t.TempDir()
+		worker, c := workerProcess(t, exe, dirs[host])
+		hosts[host] = worker
+		done = append(done, c)
+		hostAddresses[host] = "127.0.0.1"
+		// one client sends commands to each shard.
+		hostConfigs[host] = orchestration.HostConfig{Replicas: 1, Clients: i % 2}
+	}
+
+	// shard 1 stalls while two of its replicas are paused from 1s to 3s, which leaves it without a quorum.
+	pause := func() *orchestrationpb.Fault {
+		return &orchestrationpb.Fault{Action: "pause", Start: durationpb.New(time.Second), Duration: durationpb.New(2 * time.Second), Shard: 1}
+	}
+	experiment := &orchestration.Experiment{
+		Logger:      logging.New("ctrl"),
+		NumReplicas: n,
+		NumClients:  2,
+		ClientOpts: &orchestrationpb.ClientOpts{
+			ConnectTimeout: durationpb.New(time.Second),
+			MaxConcurrent:  100,
+			PayloadSize:    100,
+		},
+		ReplicaOpts: &orchestrationpb.ReplicaOpts{
+			BatchSize:         20,
+			ConnectTimeout:    durationpb.New(time.Second),
+			InitialTimeout:    durationpb.New(100 * time.Millisecond),
+			TimeoutSamples:    1000,
+			TimeoutMultiplier: 1.2,
+			Consensus:         "chainedhotstuff",
+			Crypto:            "ecdsa",
+			LeaderRotation:    "round-robin",
+			Shards:            2,
+		},
+		Faults:        map[hotstuff.ID][]*orchestrationpb.Fault{3: {pause()}, 4: {pause()}},
+		Duration:      5 * time.Second,
+		Hosts:         hosts,
+		HostConfigs:   hostConfigs,
+		HostAddresses: hostAddresses,
+	}
+	// the hashes of the commands executed by the replicas of each shard are compared when they are stopped.
+	if err := experiment.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range done {
+		if err := <-c; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for host, dir := range dirs {
+		f, err := os.Open(filepath.Join(dir, "measurements.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		commits := shardCommits{commits: make(map[uint32]map[time.Duration]uint64)}
+		err = plotting.NewReader(f, &commits).ReadAll()
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: failed to read measurements: %v", host, err)
+		}
+		// the blocks that were certified before the pause may still be committed shortly after it starts.
+		const ms = time.Millisecond
+		before0, before1 := commits.count(0, 300*ms, time.Second), commits.count(1, 300*ms, time.Second)
+		during0, during1 := commits.count(0, 1500*ms, 3*time.Second), commits.count(1, 1500*ms, 3*time.Second)
+		t.Logf("%s: shard 0 committed %d commands before and %d during the stall, shard 1 %d and %d",
+			host, before0, during0, before1, during1)
+		if before0 == 0 || before1 == 0 {
+			t.Errorf("%s: the shards did not both commit before the stall", host)
+		}
+		if during0 == 0 {
+			t.Errorf("%s: shard 0 did not commit while shard 1 was stalled", host)
+		}
+		if during1 > 0 {
+			t.Errorf("%s: shard 1 committed %d commands while two of its replicas were paused", host, during1)
+		}
+	}
+}
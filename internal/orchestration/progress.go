@@ -0,0 +1,133 @@
+package orchestration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func (w *Worker) progress(_ *orchestrationpb.ProgressRequest) (*orchestrationpb.ProgressResponse, error) {
+	res := &orchestrationpb.ProgressResponse{
+		Replicas: make(map[uint32]*orchestrationpb.ReplicaProgress, len(w.replicas)),
+		Clients:  make(map[uint32]uint64, len(w.clients)),
+	}
+	for id, r := range w.replicas {
+		view, committed := r.Progress()
+		res.Replicas[uint32(id)] = &orchestrationpb.ReplicaProgress{View: uint64(view), Committed: committed}
+	}
+	for id, c := range w.clients {
+		res.Clients[uint32(id)] = c.Completed()
+	}
+	return res, nil
+}
+
+// runProgress is the progress of all replicas and clients of an experiment.
+type runProgress struct {
+	view      uint64 // the highest view of any replica
+	committed uint64 // the highest number of commands committed by any replica
+	completed uint64 // the number of commands completed by all clients
+}
+
+// progress asks the workers for the progress of their replicas and clients.
+func (e *Experiment) progress() (p runProgress, err error) {
+	for host := range e.workers() {
+		r, ok := e.awaitProgress(host)
+		if !ok || e.hasFailed(host) {
+			continue
+		} else if r.err != nil {
+			return p, fmt.Errorf("failed to get the progress of %s: %w", host, r.err)
+		}
+		res := r.res.(*orchestrationpb.ProgressResponse)
+		for _, r := range res.GetReplicas() {
+			if r.GetView() > p.view {
+				p.view = r.GetView()
+			}
+			if r.GetCommitted() > p.committed {
+				p.committed = r.GetCommitted()
+			}
+		}
+		for _, completed := range res.GetClients() {
+			p.completed += completed
+		}
+	}
+	return p, nil
+}
+
+// awaitProgress requests the progress of the worker of the host, and waits for the reply within the run timeout.
+// If the reply is late, the request is kept, and is awaited again at the next interval, up to RunRetries times before
+// the worker is marked as failed. It returns false if the reply has not arrived.
+func (e *Experiment) awaitProgress(host string) (callResult, bool) {
+	c, ok := e.pending[host]
+	if !ok {
+		c = e.send(host, func(w RemoteWorker) (proto.Message, error) {
+			return w.Progress(&orchestrationpb.ProgressRequest{})
+		})
+	}
+	r, ok := e.await(host, RunPhase, e.Timeouts.Run, c)
+	if ok {
+		delete(e.pending, host)
+		delete(e.retries, host)
+		return r, true
+	}
+	if e.retries[host] >= e.Timeouts.RunRetries {
+		delete(e.pending, host)
+		e.fail(host, RunPhase, fmt.Errorf("no reply within %v after %d retries", e.Timeouts.Run, e.retries[host]))
+		return callResult{}, false
+	}
+	e.retries[host]++
+	e.pending[host] = c
+	e.Logger.Warnf("No reply from %s within %v, waiting again (%d of %d)", host, e.Timeouts.Run, e.retries[host], e.Timeouts.RunRetries)
+	return callResult{}, false
+}
+
+// settleProgress waits for the replies to the progress requests that are still pending when the experiment stops,
+// such that the workers can be used for other requests. The workers that do not reply are marked as failed.
+func (e *Experiment) settleProgress() {
+	for host, c := range e.pending {
+		if _, ok := e.await(host, RunPhase, e.Timeouts.Run, c); !ok {
+			e.fail(host, RunPhase, fmt.Errorf("no reply within %v after %d retries", e.Timeouts.Run, e.retries[host]))
+		}
+		delete(e.pending, host)
+	}
+}
+
+// waitForRun waits for the duration of the experiment. If ProgressInterval is set, the progress of the experiment is
+// logged at every interval, and an error is returned early if no commands were committed within StallTimeout.
+func (e *Experiment) waitForRun() error {
+	if e.ProgressInterval <= 0 {
+		time.Sleep(e.Duration)
+		return nil
+	}
+	defer e.settleProgress()
+	ticker := time.NewTicker(e.ProgressInterval)
+	defer ticker.Stop()
+	end := time.NewTimer(e.Duration)
+	defer end.Stop()
+
+	start := time.Now()
+	lastTick, lastCommit := start, start
+	var last runProgress
+	for {
+		select {
+		case <-end.C:
+			return nil
+		case now := <-ticker.C:
+			p, err := e.progress()
+			if err != nil {
+				return err
+			}
+			throughput := float64(p.completed-last.completed) / now.Sub(lastTick).Seconds()
+			e.Logger.Infof("Progress after %v: view %d, %d commands committed, %.0f commands/s",
+				now.Sub(start).Round(time.Millisecond), p.view, p.committed, throughput)
+			if p.committed > last.committed {
+				lastCommit = now
+			}
+			last, lastTick = p, now
+			if stalled := now.Sub(lastCommit); e.StallTimeout > 0 && stalled >= e.StallTimeout {
+				return fmt.Errorf("no commands were committed for %v", stalled.Round(time.Millisecond))
+			}
+		}
+	}
+}
@@ -93,6 +93,33 @@ func (w RemoteWorker) StartClient(req *orchestrationpb.StartClientRequest) (res
 	return res, nil
 }
 
+// Progress requests the progress of the replicas and clients of the remote worker.
+func (w RemoteWorker) Progress(req *orchestrationpb.ProgressRequest) (res *orchestrationpb.ProgressResponse, err error) {
+	msg, err := w.rpc(req)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := msg.(*orchestrationpb.ProgressResponse)
+	if !ok {
+		return nil, fmt.Errorf("wrong type for response message: got %T, wanted: %T", msg, res)
+	}
+	return res, nil
+}
+
+// Reset requests that the remote worker tears down the replicas and clients of the previous run, such that it can be
+// used for another run without being redeployed.
+func (w RemoteWorker) Reset(req *orchestrationpb.ResetRequest) (res *orchestrationpb.ResetResponse, err error) {
+	msg, err := w.rpc(req)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := msg.(*orchestrationpb.ResetResponse)
+	if !ok {
+		return nil, fmt.Errorf("wrong type for response message: got %T, wanted: %T", msg, res)
+	}
+	return res, nil
+}
+
 // StopClient requests that the remote worker stops the specified clients.
 func (w RemoteWorker) StopClient(req *orchestrationpb.StopClientRequest) (res *orchestrationpb.StopClientResponse, err error) {
 	msg, err := w.rpc(req)
@@ -106,6 +133,100 @@ func (w RemoteWorker) StopClient(req *orchestrationpb.StopClientRequest) (res *o
 	return res, nil
 }
 
+// Shutdown requests that the remote worker stops its clients and shuts down its replicas gracefully.
+// The remote worker exits after it has replied.
+func (w RemoteWorker) Shutdown(req *orchestrationpb.ShutdownRequest) (res *orchestrationpb.ShutdownResponse, err error) {
+	msg, err := w.rpc(req)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := msg.(*orchestrationpb.ShutdownResponse)
+	if !ok {
+		return nil, fmt.Errorf("wrong type for response message: got %T, wanted: %T", msg, res)
+	}
+	return res, nil
+}
+
+// StartProfile requests that the remote worker starts profiling its process.
+func (w RemoteWorker) StartProfile(req *orchestrationpb.StartProfileRequest) (res *orchestrationpb.StartProfileResponse, err error) {
+	msg, err := w.rpc(req)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := msg.(*orchestrationpb.StartProfileResponse)
+	if !ok {
+		return nil, fmt.Errorf("wrong type for response message: got %T, wanted: %T", msg, res)
+	}
+	return res, nil
+}
+
+// CollectArtifacts requests the artifacts of the remote worker, and calls handle for each chunk that is received.
+// If handle returns an error, the remaining chunks are discarded, and the error is returned.
+func (w RemoteWorker) CollectArtifacts(req *orchestrationpb.CollectArtifactsRequest, handle func(*orchestrationpb.ArtifactChunk) error) (res *orchestrationpb.CollectArtifactsResponse, err error) {
+	err = w.send.WriteAny(req)
+	if err != nil {
+		return nil, err
+	}
+	var handleErr error
+	for {
+		msg, err := w.recv.ReadAny()
+		if err != nil {
+			return nil, err
+		}
+		switch m := msg.(type) {
+		case *orchestrationpb.ArtifactChunk:
+			if handleErr == nil {
+				handleErr = handle(m)
+			}
+		case *orchestrationpb.CollectArtifactsResponse:
+			return m, handleErr
+		case *spb.Status:
+			return nil, status.FromProto(m).Err()
+		default:
+			return nil, fmt.Errorf("wrong type for response message: got %T, wanted: %T", msg, res)
+		}
+	}
+}
+
+// EmulateNetwork requests that the remote worker emulates the network links from its host.
+func (w RemoteWorker) EmulateNetwork(req *orchestrationpb.EmulateNetworkRequest) (res *orchestrationpb.EmulateNetworkResponse, err error) {
+	msg, err := w.rpc(req)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := msg.(*orchestrationpb.EmulateNetworkResponse)
+	if !ok {
+		return nil, fmt.Errorf("wrong type for response message: got %T, wanted: %T", msg, res)
+	}
+	return res, nil
+}
+
+// VerifyNetwork requests that the remote worker measures the round-trip times of its emulated links.
+func (w RemoteWorker) VerifyNetwork(req *orchestrationpb.VerifyNetworkRequest) (res *orchestrationpb.VerifyNetworkResponse, err error) {
+	msg, err := w.rpc(req)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := msg.(*orchestrationpb.VerifyNetworkResponse)
+	if !ok {
+		return nil, fmt.Errorf("wrong type for response message: got %T, wanted: %T", msg, res)
+	}
+	return res, nil
+}
+
+// ClearNetwork requests that the remote worker removes its emulated links.
+func (w RemoteWorker) ClearNetwork(req *orchestrationpb.ClearNetworkRequest) (res *orchestrationpb.ClearNetworkResponse, err error) {
+	msg, err := w.rpc(req)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := msg.(*orchestrationpb.ClearNetworkResponse)
+	if !ok {
+		return nil, fmt.Errorf("wrong type for response message: got %T, wanted: %T", msg, res)
+	}
+	return res, nil
+}
+
 // Quit requests that the remote worker exits.
 func (w RemoteWorker) Quit() (err error) {
 	return w.send.WriteAny(&orchestrationpb.QuitRequest{})
@@ -0,0 +1,86 @@
+package orchestration
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/client"
+	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
+	"github.com/relab/hotstuff/modules"
+)
+
+// SetResetHandler sets the function that is called with the name of the next run when the worker is reset.
+// It returns the metrics logger for the measurements of the next run, such that the measurements of each run
+// are kept apart, and is responsible for closing the metrics logger of the previous run.
+// The worker cannot be reset if no handler is set.
+func (w *Worker) SetResetHandler(handler func(run string) (modules.MetricsLogger, error)) {
+	w.resetHandler = handler
+}
+
+// reset tears down the replicas and clients of the previous run, along with their faults, profiles, and logs,
+// and replaces the metrics logger, such that nothing from the previous run leaks into the next.
+// The caller must hold the mutex.
+func (w *Worker) reset(req *orchestrationpb.ResetRequest) (*orchestrationpb.ResetResponse, error) {
+	if w.resetHandler == nil {
+		return nil, fmt.Errorf("the worker cannot be reset")
+	}
+	// the replicas and clients are left behind if the previous run failed.
+	if _, err := w.shutdown(); err != nil {
+		return nil, fmt.Errorf("failed to stop the previous run: %w", err)
+	}
+	w.heapProfile = false
+	if w.logs != nil {
+		w.logs.Reset()
+	}
+
+	logger, err := w.resetHandler(req.GetRun())
+	if err != nil {
+		return nil, fmt.Errorf("failed to reset the metrics logger: %w", err)
+	}
+	w.metricsLogger = logger
+
+	// the stopped clients are kept until now, such that their progress can be reported.
+	w.clients = make(map[hotstuff.ID]*client.Client)
+	w.drainTimeouts = make(map[hotstuff.ID]time.Duration)
+	w.configuration = nil
+
+	if err := w.checkReset(); err != nil {
+		return nil, fmt.Errorf("the previous run was not torn down: %w", err)
+	}
+	return &orchestrationpb.ResetResponse{}, nil
+}
+
+// checkReset checks that no replicas, faults, or profiles of the previous run remain,
+// and that the addresses that the replicas of the previous run listened on have been released.
+func (w *Worker) checkReset() error {
+	if w.cpuProfile != nil {
+		return fmt.Errorf("a CPU profile is running")
+	}
+	if len(w.replicas) > 0 || len(w.replicaOpts) > 0 || len(w.listenAddrs) > 0 {
+		return fmt.Errorf("%d replicas remain", len(w.replicas))
+	}
+	if len(w.faults) > 0 {
+		return fmt.Errorf("the faults of %d replicas remain", len(w.faults))
+	}
+	addrs := w.usedAddrs
+	w.usedAddrs = nil
+	for _, addr := range addrs {
+		if backend.IsUnixAddress(addr) {
+			if _, err := os.Stat(strings.TrimPrefix(addr, backend.UnixScheme)); err == nil {
+				return fmt.Errorf("the socket %s was not removed", addr)
+			}
+			continue
+		}
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("the address %s is still in use: %w", addr, err)
+		}
+		lis.Close()
+	}
+	return nil
+}
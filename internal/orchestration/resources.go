@@ -0,0 +1,114 @@
+package orchestration
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
+)
+
+// validateResources checks that the resource limits apply to the replicas and clients of the experiment.
+// The clients are identified by the ID of their first session.
+func (e *Experiment) validateResources() error {
+	for id := range e.ReplicaResources {
+		if id < 1 || int(id) > e.NumReplicas {
+			return fmt.Errorf("cannot limit the resources of replica %d: there are %d replicas", id, e.NumReplicas)
+		}
+	}
+	sessions := hotstuff.ID(e.ClientOpts.GetSessions())
+	if sessions < 1 {
+		sessions = 1
+	}
+	for id := range e.ClientResources {
+		if id < 1 || (id-1)%sessions != 0 || int((id-1)/sessions) >= e.NumClients {
+			return fmt.Errorf("cannot limit the resources of client %d: it is not one of the %d clients", id, e.NumClients)
+		}
+	}
+	return nil
+}
+
+// processLimits are the limits of the process of a worker.
+type processLimits struct {
+	cpus     []uint32 // the CPUs that the process may run on
+	cpusErr  error    // the reason that the CPUs are unknown, such that the process cannot be pinned
+	maxProcs int
+}
+
+// limitResources applies the resource limits of the replicas and clients of the worker to its process.
+// As the replicas and clients share the process, it is pinned to the union of their CPUs, and GOMAXPROCS is the sum
+// of their limits. The replicas and clients without limits do not contribute. If the process cannot be pinned,
+// only GOMAXPROCS is limited. The caller must hold the mutex.
+func (w *Worker) limitResources() {
+	var (
+		cpus     = make(map[uint32]bool)
+		maxProcs int
+		limited  int
+	)
+	add := func(r *orchestrationpb.Resources) {
+		if len(r.GetCPUs()) == 0 && r.GetMaxProcs() == 0 {
+			return
+		}
+		limited++
+		for _, cpu := range r.GetCPUs() {
+			cpus[cpu] = true
+		}
+		maxProcs += int(r.GetMaxProcs())
+	}
+	for _, opts := range w.replicaOpts {
+		add(opts.GetResources())
+	}
+	for _, r := range w.clientResources {
+		add(r)
+	}
+	if limited == 0 {
+		return
+	}
+	if limited > 1 {
+		log.Printf("The limits of %d replicas and clients are combined, as they run in the process of the worker.", limited)
+	}
+
+	if w.defaultLimits == nil {
+		w.defaultLimits = &processLimits{maxProcs: runtime.GOMAXPROCS(0)}
+		// the process is pinned to the CPUs that it could run on when the limits are removed.
+		w.defaultLimits.cpus, w.defaultLimits.cpusErr = getAffinity()
+	}
+	if len(cpus) > 0 {
+		set := make([]uint32, 0, len(cpus))
+		for cpu := range cpus {
+			set = append(set, cpu)
+		}
+		sort.Slice(set, func(i, j int) bool { return set[i] < set[j] })
+		err := w.defaultLimits.cpusErr
+		if err == nil {
+			err = setAffinity(set)
+		}
+		if err != nil {
+			log.Printf("Failed to pin the worker to CPUs %v, limiting only GOMAXPROCS: %v", set, err)
+		} else {
+			w.pinnedCPUs = set
+		}
+	}
+	if maxProcs > 0 {
+		runtime.GOMAXPROCS(maxProcs)
+	}
+}
+
+// restoreResources removes the limits of the process of the worker. The caller must hold the mutex.
+func (w *Worker) restoreResources() error {
+	w.clientResources = make(map[hotstuff.ID]*orchestrationpb.Resources)
+	if w.defaultLimits == nil {
+		return nil
+	}
+	runtime.GOMAXPROCS(w.defaultLimits.maxProcs)
+	if w.pinnedCPUs != nil {
+		if err := setAffinity(w.defaultLimits.cpus); err != nil {
+			return fmt.Errorf("failed to restore the CPUs of the worker: %w", err)
+		}
+	}
+	w.defaultLimits = nil
+	w.pinnedCPUs = nil
+	return nil
+}
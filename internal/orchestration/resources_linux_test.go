@@ -0,0 +1,55 @@
+package orchestration
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
+)
+
+func TestPinning(t *testing.T) {
+	allowed, err := getAffinity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpu := allowed[len(allowed)-1]
+	w := &Worker{
+		replicaOpts: map[hotstuff.ID]*orchestrationpb.ReplicaOpts{
+			1: {ID: 1, Resources: &orchestrationpb.Resources{CPUs: []uint32{cpu}}},
+			2: {ID: 2},
+		},
+		clientResources: make(map[hotstuff.ID]*orchestrationpb.Resources),
+	}
+	defaultProcs := runtime.GOMAXPROCS(0)
+
+	w.limitResources()
+	if !reflect.DeepEqual(w.pinnedCPUs, []uint32{cpu}) {
+		t.Fatalf("got pinned CPUs %v, want [%d]", w.pinnedCPUs, cpu)
+	}
+	// the thread that the test runs on is one of the threads of the process.
+	runtime.LockOSThread()
+	pinned, err := getAffinity()
+	runtime.UnlockOSThread()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pinned, []uint32{cpu}) {
+		t.Errorf("the thread may run on CPUs %v, want [%d]", pinned, cpu)
+	}
+	if procs := runtime.GOMAXPROCS(0); procs != defaultProcs {
+		t.Errorf("GOMAXPROCS changed to %d without a limit", procs)
+	}
+
+	if err := w.restoreResources(); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := getAffinity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored, allowed) {
+		t.Errorf("the CPUs were restored to %v, want %v", restored, allowed)
+	}
+}
@@ -0,0 +1,56 @@
+package orchestration
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
+)
+
+// The components whose seeds are derived from the master seed of an experiment.
+const (
+	sharedComponent  = "shared"  // the seed shared by all replicas, such as for the leader rotation
+	payloadComponent = "payload" // the seed of the payloads, to which each client adds its ID
+	replicaComponent = "replica" // the seed of a replica, such as for the latency emulation and gossip
+	clientComponent  = "client"  // the seed of a client, such as for the arrival times
+)
+
+// deriveSeed derives the seed of a component from the master seed of an experiment,
+// such that the seeds of the components are independent of each other, and of the number of components.
+func deriveSeed(master int64, component string, id hotstuff.ID) int64 {
+	var b [12]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(master))
+	binary.BigEndian.PutUint32(b[8:], uint32(id))
+	h := sha256.New()
+	h.Write(b[:])
+	h.Write([]byte(component))
+	return int64(binary.BigEndian.Uint64(h.Sum(nil)))
+}
+
+// initSeed chooses a master seed for the experiment if it has none, and logs it, such that the run can be repeated.
+func (e *Experiment) initSeed() {
+	if e.Seed == 0 {
+		e.Seed = time.Now().UnixNano()
+	}
+	e.Logger.Infof("The master seed of the experiment is %d.", e.Seed)
+}
+
+// seedReplica sets the seeds of a replica that are derived from the master seed.
+// The shared seed is only derived if it was not given explicitly.
+func (e *Experiment) seedReplica(opts *orchestrationpb.ReplicaOpts) {
+	if opts.GetSharedSeed() == 0 {
+		opts.SharedSeed = deriveSeed(e.Seed, sharedComponent, 0)
+	}
+	opts.Seed = deriveSeed(e.Seed, replicaComponent, hotstuff.ID(opts.GetID()))
+}
+
+// seedClient sets the seeds of a client that are derived from the master seed.
+// The payload seed is only derived if it was not given explicitly.
+func (e *Experiment) seedClient(opts *orchestrationpb.ClientOpts) {
+	if opts.GetPayloadSeed() == 0 {
+		opts.PayloadSeed = deriveSeed(e.Seed, payloadComponent, 0)
+	}
+	opts.Seed = deriveSeed(e.Seed, clientComponent, hotstuff.ID(opts.GetID()))
+}
@@ -0,0 +1,145 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
+	"github.com/relab/hotstuff/modules"
+)
+
+// StandaloneReplica is a single replica that is configured locally instead of by a controller.
+// It is created with the same modules as the replicas of a worker.
+type StandaloneReplica struct {
+	worker    *Worker
+	id        uint32
+	peers     map[uint32]*orchestrationpb.ReplicaInfo
+	info      *orchestrationpb.ReplicaInfo
+	transport string
+}
+
+const (
+	// peerRetryDelay is the initial delay between the attempts to reach a peer that is not listening yet.
+	peerRetryDelay = 100 * time.Millisecond
+	// maxPeerRetryDelay is the maximum delay between the attempts to reach a peer.
+	maxPeerRetryDelay = 2 * time.Second
+)
+
+// NewStandaloneReplica validates the options and the peers, and then creates the replica and starts its servers.
+// The peers must include the replica itself. Invalid options are reported before any listener is opened.
+func NewStandaloneReplica(
+	opts *orchestrationpb.ReplicaOpts,
+	peers map[uint32]*orchestrationpb.ReplicaInfo,
+	metricsLogger modules.MetricsLogger,
+	metrics []string,
+	measurementInterval time.Duration,
+) (*StandaloneReplica, error) {
+	if err := validatePeers(opts.GetID(), peers); err != nil {
+		return nil, err
+	}
+	worker := NewWorker(nil, nil, metricsLogger, metrics, measurementInterval)
+	res, err := worker.createReplicas(&orchestrationpb.CreateReplicaRequest{
+		Replicas: map[uint32]*orchestrationpb.ReplicaOpts{opts.GetID(): opts},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &StandaloneReplica{
+		worker:    &worker,
+		id:        opts.GetID(),
+		peers:     peers,
+		info:      res.GetReplicas()[opts.GetID()],
+		transport: opts.GetTransport(),
+	}, nil
+}
+
+// Info returns the ports or sockets that the replica listens on.
+func (r *StandaloneReplica) Info() *orchestrationpb.ReplicaInfo {
+	return r.info
+}
+
+// Start waits until the peers are listening, and then connects to them and starts the replica.
+// Waiting for the peers is aborted when the context is done.
+func (r *StandaloneReplica) Start(ctx context.Context) error {
+	if err := r.waitForPeers(ctx); err != nil {
+		return err
+	}
+	_, err := r.worker.startReplicas(&orchestrationpb.StartReplicaRequest{
+		Configuration: r.peers,
+		IDs:           []uint32{r.id},
+	})
+	return err
+}
+
+// Shutdown shuts down the replica gracefully, and returns the hash of the commands that it executed.
+func (r *StandaloneReplica) Shutdown() ([]byte, error) {
+	r.worker.mut.Lock()
+	defer r.worker.mut.Unlock()
+	res, err := r.worker.shutdown()
+	if err != nil {
+		return nil, err
+	}
+	return res.GetHashes()[r.id], nil
+}
+
+// waitForPeers waits until a connection can be made to each of the peers,
+// since the replicas only connect once, and the peers may be started later than this replica.
+func (r *StandaloneReplica) waitForPeers(ctx context.Context) error {
+	transport, err := backend.GetTransport(r.transport)
+	if err != nil {
+		return err
+	}
+	peers, err := getConfiguration(r.peers, false, 0)
+	if err != nil {
+		return err
+	}
+	for _, peer := range peers {
+		if uint32(peer.ID) == r.id {
+			continue
+		}
+		delay := peerRetryDelay
+		for {
+			dialCtx, cancel := context.WithTimeout(ctx, maxPeerRetryDelay)
+			conn, err := transport.Dial(dialCtx, peer.Address)
+			cancel()
+			if err == nil {
+				conn.Close()
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("failed to reach peer %d: %w", peer.ID, err)
+			case <-time.After(delay):
+			}
+			if delay *= 2; delay > maxPeerRetryDelay {
+				delay = maxPeerRetryDelay
+			}
+		}
+	}
+	return nil
+}
+
+// validatePeers checks that the configuration of the peers is complete and can be parsed.
+func validatePeers(id uint32, peers map[uint32]*orchestrationpb.ReplicaInfo) error {
+	if _, ok := peers[id]; !ok {
+		return fmt.Errorf("the replica itself (ID %d) is missing from the peers", id)
+	}
+	for peerID, peer := range peers {
+		if peer.GetID() != peerID {
+			return fmt.Errorf("peer %d is configured with ID %d", peerID, peer.GetID())
+		}
+		if len(peer.GetPublicKey()) == 0 {
+			return fmt.Errorf("missing public key for peer %d", peerID)
+		}
+		if peer.GetReplicaSocket() == "" && peerID != id && (peer.GetAddress() == "" || peer.GetReplicaPort() == 0) {
+			return fmt.Errorf("missing address for peer %d", peerID)
+		}
+		if _, err := keygen.ParsePublicKey(peer.GetPublicKey()); err != nil {
+			return fmt.Errorf("invalid public key for peer %d: %w", peerID, err)
+		}
+	}
+	return nil
+}
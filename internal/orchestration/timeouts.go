@@ -0,0 +1,177 @@
+package orchestration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrMissingData is returned by Run when some of the workers failed during the experiment, such that their data are
+// missing. The experiment is completed with the other workers, and the failed workers are listed by Report.
+var ErrMissingData = errors.New("the data of some hosts are missing")
+
+// The phases of an experiment, as they are named in the run report.
+const (
+	DeployPhase  = "deploy"
+	StartPhase   = "start"
+	RunPhase     = "run"
+	StopPhase    = "stop"
+	CollectPhase = "collect"
+)
+
+// Timeouts are the deadlines of the phases of an experiment, which bound how long the controller waits for each reply
+// from a worker. A worker that does not reply in time, or whose connection fails, is marked as failed and is not used
+// again, and the experiment continues with the other workers. The controller waits forever if a timeout is zero.
+type Timeouts struct {
+	Start   time.Duration // creating and starting the replicas and clients
+	Run     time.Duration // requesting the progress of the experiment while it runs
+	Stop    time.Duration // stopping the clients and replicas, and shutting down the workers
+	Collect time.Duration // collecting the artifacts
+
+	// RunRetries is the number of progress intervals that the controller keeps waiting for a reply that timed out
+	// while the experiment runs, before the worker is marked as failed.
+	RunRetries int
+}
+
+// MissingHost is a host whose worker failed during an experiment.
+type MissingHost struct {
+	Host  string `json:"host"`
+	Phase string `json:"phase"`
+	Error string `json:"error"`
+}
+
+// RunReport lists the hosts whose data are missing from a run, because their workers failed.
+type RunReport struct {
+	Missing []MissingHost `json:"missing"`
+}
+
+// Add adds a host whose worker failed in the given phase to the report.
+func (r *RunReport) Add(host, phase string, err error) {
+	r.Missing = append(r.Missing, MissingHost{Host: host, Phase: phase, Error: err.Error()})
+	sort.Slice(r.Missing, func(i, j int) bool { return r.Missing[i].Host < r.Missing[j].Host })
+}
+
+// String returns a list of the missing hosts, and the phases in which they failed.
+func (r RunReport) String() string {
+	hosts := make([]string, 0, len(r.Missing))
+	for _, m := range r.Missing {
+		hosts = append(hosts, fmt.Sprintf("%s (%s: %s)", m.Host, m.Phase, m.Error))
+	}
+	return strings.Join(hosts, "; ")
+}
+
+// Write writes the report to a file as JSON.
+func (r RunReport) Write(path string) error {
+	b, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// Report returns the hosts whose workers failed during the last run of the experiment.
+func (e *Experiment) Report() RunReport {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+	var r RunReport
+	for host, f := range e.failed {
+		r.Add(host, f.phase, f.err)
+	}
+	return r
+}
+
+// hostFailure is the reason that a worker was marked as failed.
+type hostFailure struct {
+	phase string
+	err   error
+}
+
+// callResult is the reply of a worker to a request.
+type callResult struct {
+	res proto.Message
+	err error
+}
+
+// fail marks the worker of the host as failed, such that it is not used again.
+func (e *Experiment) fail(host, phase string, err error) {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+	if _, ok := e.failed[host]; ok {
+		return
+	}
+	e.failed[host] = hostFailure{phase: phase, err: err}
+	e.Logger.Errorf("The worker on %s failed during the %s phase, its data will be missing: %v", host, phase, err)
+}
+
+// workers returns the workers that have not failed.
+func (e *Experiment) workers() map[string]RemoteWorker {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+	workers := make(map[string]RemoteWorker, len(e.Hosts))
+	for host, worker := range e.Hosts {
+		if _, ok := e.failed[host]; !ok {
+			workers[host] = worker
+		}
+	}
+	return workers
+}
+
+// hasFailed returns true if the worker of the host has been marked as failed.
+func (e *Experiment) hasFailed(host string) bool {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+	_, ok := e.failed[host]
+	return ok
+}
+
+// call sends a request to the worker of the host through fn, and waits at most timeout for the reply.
+// If the reply does not arrive in time, or the connection fails, the worker is marked as failed, and an error is
+// returned. The request is then abandoned, since the stream of the worker can no longer be used for other requests.
+// Errors that are reported by the worker itself do not mark it as failed.
+func (e *Experiment) call(host, phase string, timeout time.Duration, fn func(RemoteWorker) (proto.Message, error)) (proto.Message, error) {
+	r, ok := e.await(host, phase, timeout, e.send(host, fn))
+	if !ok {
+		err := fmt.Errorf("no reply within %v", timeout)
+		e.fail(host, phase, err)
+		return nil, err
+	}
+	return r.res, r.err
+}
+
+// send sends a request to the worker of the host through fn, and returns a channel that receives the reply.
+func (e *Experiment) send(host string, fn func(RemoteWorker) (proto.Message, error)) <-chan callResult {
+	worker := e.Hosts[host]
+	c := make(chan callResult, 1)
+	go func() {
+		res, err := fn(worker)
+		c <- callResult{res: res, err: err}
+	}()
+	return c
+}
+
+// await waits at most timeout for a reply to arrive on c. It returns false if the timeout expired.
+func (e *Experiment) await(host, phase string, timeout time.Duration, c <-chan callResult) (callResult, bool) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case r := <-c:
+		if _, ok := status.FromError(r.err); !ok {
+			// the worker did not reply with an error of its own, so the connection has failed.
+			e.fail(host, phase, r.err)
+		}
+		return r, true
+	case <-expired:
+		return callResult{}, false
+	}
+}
@@ -1,13 +1,17 @@
 package orchestration
 
 import (
-	"crypto/rand"
+	"bytes"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
-	"io"
 	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/relab/gorums"
@@ -19,6 +23,7 @@ import (
 	"github.com/relab/hotstuff/consensus/byzantine"
 	"github.com/relab/hotstuff/crypto"
 	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/examples/kvstore"
 	"github.com/relab/hotstuff/internal/proto/orchestrationpb"
 	"github.com/relab/hotstuff/internal/protostream"
 	"github.com/relab/hotstuff/metrics"
@@ -40,6 +45,9 @@ import (
 	_ "github.com/relab/hotstuff/leaderrotation"
 )
 
+// DefaultCryptoCacheSize is the size of the crypto cache of the replicas whose options do not set it.
+const DefaultCryptoCacheSize = 100
+
 // Worker starts and runs clients and replicas based on commands from the controller.
 type Worker struct {
 	send *protostream.Writer
@@ -49,19 +57,64 @@ type Worker struct {
 	metrics             []string
 	measurementInterval time.Duration
 
-	replicas map[hotstuff.ID]*replica.Replica
-	clients  map[hotstuff.ID]*client.Client
+	// mut serializes the requests and Shutdown.
+	mut           sync.Mutex
+	replicas      map[hotstuff.ID]*replica.Replica
+	shards        map[hotstuff.ID][]*replica.Replica // the shards after the first of each replica, in order
+	drainTimeouts map[hotstuff.ID]time.Duration      // used when the replicas are shut down
+	clients       map[hotstuff.ID]*client.Client
+
+	// used to restart crashed replicas.
+	replicaOpts   map[hotstuff.ID]*orchestrationpb.ReplicaOpts
+	listenAddrs   map[hotstuff.ID]listenAddrs
+	configuration map[uint32]*orchestrationpb.ReplicaInfo
+	faults        map[hotstuff.ID]*faultState
+
+	// used to reset the worker between runs.
+	resetHandler func(run string) (modules.MetricsLogger, error)
+	usedAddrs    []string // the addresses that the replicas of the run have listened on
+
+	// sent to the controller when the artifacts are collected.
+	logs        *LogBuffer
+	artifacts   map[string]string
+	cpuProfile  *bytes.Buffer
+	heapProfile bool
+
+	// the links emulated on the network interface, which are removed when the worker is reset or exits.
+	emulatedInterface string
+	emulatedLinks     []emulatedLink
+
+	// the resource limits of the clients, and the limits of the process before it was limited, which are restored
+	// when the worker is reset or exits.
+	clientResources map[hotstuff.ID]*orchestrationpb.Resources
+	defaultLimits   *processLimits
+	pinnedCPUs      []uint32
 }
 
-// Run runs the worker until it receives a command to quit.
-func (w *Worker) Run() error {
+// Run runs the worker until it receives a command to quit or shut down.
+func (w *Worker) Run() (err error) {
+	// the emulated links would otherwise outlive the worker, such as when the controller fails.
+	defer func() {
+		w.mut.Lock()
+		defer w.mut.Unlock()
+		if cerr := w.clearEmulation(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to clear the network emulation: %w", cerr)
+		}
+		if rerr := w.restoreResources(); rerr != nil && err == nil {
+			err = rerr
+		}
+	}()
 	for {
 		msg, err := w.recv.ReadAny()
 		if err != nil {
 			return err
 		}
 
-		var res proto.Message
+		var (
+			res  proto.Message
+			quit bool
+		)
+		w.mut.Lock()
 		switch req := msg.(type) {
 		case *orchestrationpb.CreateReplicaRequest:
 			res, err = w.createReplicas(req)
@@ -73,9 +126,28 @@ func (w *Worker) Run() error {
 			res, err = w.startClients(req)
 		case *orchestrationpb.StopClientRequest:
 			res, err = w.stopClients(req)
+		case *orchestrationpb.ResetRequest:
+			res, err = w.reset(req)
+		case *orchestrationpb.ProgressRequest:
+			res, err = w.progress(req)
+		case *orchestrationpb.StartProfileRequest:
+			res, err = w.startProfile(req)
+		case *orchestrationpb.CollectArtifactsRequest:
+			res, err = w.collectArtifacts(req)
+		case *orchestrationpb.EmulateNetworkRequest:
+			res, err = w.emulateNetwork(req)
+		case *orchestrationpb.VerifyNetworkRequest:
+			res, err = w.verifyNetwork(req)
+		case *orchestrationpb.ClearNetworkRequest:
+			res, err = w.clearNetwork(req)
+		case *orchestrationpb.ShutdownRequest:
+			res, err = w.shutdown()
+			quit = true
 		case *orchestrationpb.QuitRequest:
+			w.mut.Unlock()
 			return nil
 		}
+		w.mut.Unlock()
 
 		if err != nil {
 			s, _ := status.FromError(err)
@@ -83,12 +155,53 @@ func (w *Worker) Run() error {
 		}
 
 		err = w.send.WriteAny(res)
-		if err != nil {
+		if err != nil || quit {
 			return err
 		}
 	}
 }
 
+// Shutdown stops the clients of the worker and shuts down its replicas gracefully, such that their final
+// measurements are logged and flushed. It can be called while Run is waiting for requests, for example when
+// the worker receives a signal.
+func (w *Worker) Shutdown() error {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	_, err := w.shutdown()
+	return err
+}
+
+// shutdown stops all clients and shuts down all replicas. The caller must hold the mutex.
+func (w *Worker) shutdown() (*orchestrationpb.ShutdownResponse, error) {
+	clientIDs := make([]uint32, 0, len(w.clients))
+	for id := range w.clients {
+		clientIDs = append(clientIDs, uint32(id))
+	}
+	if _, err := w.stopClients(&orchestrationpb.StopClientRequest{IDs: clientIDs}); err != nil {
+		return nil, err
+	}
+	replicaIDs := make([]uint32, 0, len(w.replicas))
+	for id := range w.replicas {
+		replicaIDs = append(replicaIDs, uint32(id))
+	}
+	res, err := w.stopReplicas(&orchestrationpb.StopReplicaRequest{IDs: replicaIDs})
+	if err != nil {
+		return nil, err
+	}
+	// a profile that was not collected is discarded.
+	if w.cpuProfile != nil {
+		pprof.StopCPUProfile()
+		w.cpuProfile = nil
+	}
+	if err := w.clearEmulation(); err != nil {
+		return nil, err
+	}
+	if err := w.restoreResources(); err != nil {
+		return nil, err
+	}
+	return &orchestrationpb.ShutdownResponse{Hashes: res.GetHashes()}, nil
+}
+
 // NewWorker returns a new worker.
 func NewWorker(send *protostream.Writer, recv *protostream.Reader, dl modules.MetricsLogger, metrics []string, measurementInterval time.Duration) Worker {
 	return Worker{
@@ -98,52 +211,179 @@ func NewWorker(send *protostream.Writer, recv *protostream.Reader, dl modules.Me
 		metrics:             metrics,
 		measurementInterval: measurementInterval,
 		replicas:            make(map[hotstuff.ID]*replica.Replica),
+		shards:              make(map[hotstuff.ID][]*replica.Replica),
+		drainTimeouts:       make(map[hotstuff.ID]time.Duration),
 		clients:             make(map[hotstuff.ID]*client.Client),
+		replicaOpts:         make(map[hotstuff.ID]*orchestrationpb.ReplicaOpts),
+		listenAddrs:         make(map[hotstuff.ID]listenAddrs),
+		faults:              make(map[hotstuff.ID]*faultState),
+		artifacts:           make(map[string]string),
+		clientResources:     make(map[hotstuff.ID]*orchestrationpb.Resources),
 	}
 }
 
 func (w *Worker) createReplicas(req *orchestrationpb.CreateReplicaRequest) (*orchestrationpb.CreateReplicaResponse, error) {
 	resp := &orchestrationpb.CreateReplicaResponse{Replicas: make(map[uint32]*orchestrationpb.ReplicaInfo)}
 	for _, cfg := range req.GetReplicas() {
-		r, err := w.createReplica(cfg)
+		transport, err := backend.GetTransport(cfg.GetTransport())
+		if err != nil {
+			return nil, err
+		}
+
+		w.metricsLogger.Log(cfg)
+		r, err := w.createReplica(cfg, transport)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create replica: %w", err)
 		}
 
+		info := &orchestrationpb.ReplicaInfo{
+			ID:        cfg.GetID(),
+			PublicKey: cfg.GetPublicKey(),
+		}
+
 		// set up listeners and get the ports
-		replicaListener, err := net.Listen("tcp", ":0")
-		if err != nil {
-			return nil, fmt.Errorf("failed to create listener: %w", err)
+		replicaAddr := listenAddress(cfg.GetReplicaListenAddress())
+		clientAddr := listenAddress(cfg.GetClientListenAddress())
+		if dir := cfg.GetUnixSocketDir(); dir != "" {
+			if err := os.MkdirAll(dir, 0o700); err != nil {
+				return nil, fmt.Errorf("failed to create socket directory: %w", err)
+			}
+			replicaAddr = backend.UnixScheme + filepath.Join(dir, fmt.Sprintf("replica-%d.sock", cfg.GetID()))
+			clientAddr = backend.UnixScheme + filepath.Join(dir, fmt.Sprintf("client-%d.sock", cfg.GetID()))
+			info.ReplicaSocket = replicaAddr
+			info.ClientSocket = clientAddr
 		}
-		replicaPort, err := getPort(replicaListener)
+		replicaListener, err := r.Transport().Listen(replicaAddr)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("failed to create listener: %w", err)
 		}
-		clientListener, err := net.Listen("tcp", ":0")
+		// the default transport is used for the client server, as the clients always use it.
+		defaultTransport, _ := backend.GetTransport("")
+		clientListener, err := defaultTransport.Listen(clientAddr)
 		if err != nil {
+			replicaListener.Close()
 			return nil, fmt.Errorf("failed to create listener: %w", err)
 		}
-		clientPort, err := getPort(clientListener)
-		if err != nil {
-			return nil, err
+		if cfg.GetUnixSocketDir() == "" {
+			if info.ReplicaPort, err = getPort(replicaListener.Addr()); err != nil {
+				return nil, err
+			}
+			if info.ClientPort, err = getPort(clientListener.Addr()); err != nil {
+				return nil, err
+			}
+			// a restarted replica must listen on the same ports.
+			replicaAddr = replicaListener.Addr().String()
+			clientAddr = clientListener.Addr().String()
+			// if the client server is bound to a specific host, the clients must connect to that host.
+			if host, _, err := net.SplitHostPort(cfg.GetClientListenAddress()); err == nil {
+				if ip := net.ParseIP(host); host != "" && (ip == nil || !ip.IsUnspecified()) {
+					info.ClientAddress = host
+				}
+			}
+		}
+
+		if cfg.GetStatusListenAddress() != "" {
+			addr, err := r.ListenStatus()
+			if err != nil {
+				replicaListener.Close()
+				clientListener.Close()
+				return nil, err
+			}
+			info.StatusPort = uint32(addr.(*net.TCPAddr).Port)
+		}
+
+		if cfg.GetCommitStreamListenAddress() != "" {
+			addr, err := r.ListenCommitStream()
+			if err != nil {
+				replicaListener.Close()
+				clientListener.Close()
+				return nil, err
+			}
+			info.CommitStreamPort = uint32(addr.(*net.TCPAddr).Port)
 		}
 
 		r.StartServers(replicaListener, clientListener)
+		if err := w.createShards(r, cfg, transport, info); err != nil {
+			w.shutdownShards(hotstuff.ID(cfg.GetID()), 0)
+			delete(w.shards, hotstuff.ID(cfg.GetID()))
+			r.Shutdown(0)
+			return nil, fmt.Errorf("failed to create shards: %w", err)
+		}
 		w.replicas[hotstuff.ID(cfg.GetID())] = r
+		w.drainTimeouts[hotstuff.ID(cfg.GetID())] = cfg.GetDrainTimeout().AsDuration()
+		w.replicaOpts[hotstuff.ID(cfg.GetID())] = cfg
+		w.listenAddrs[hotstuff.ID(cfg.GetID())] = listenAddrs{replica: replicaAddr, client: clientAddr}
+		w.usedAddrs = append(w.usedAddrs, replicaAddr, clientAddr)
+		resp.Replicas[cfg.GetID()] = info
+	}
+	w.limitResources()
+	return resp, nil
+}
+
+// listenAddress returns the given address, or an address with a random port if it is empty.
+func listenAddress(addr string) string {
+	if addr == "" {
+		return ":0"
+	}
+	return addr
+}
 
-		resp.Replicas[cfg.GetID()] = &orchestrationpb.ReplicaInfo{
-			ID:          cfg.GetID(),
-			PublicKey:   cfg.GetPublicKey(),
-			ReplicaPort: replicaPort,
-			ClientPort:  clientPort,
+// createShards creates the shards after the first of a replica, starts their client servers,
+// and adds the addresses of the client servers to the information about the replica.
+func (w *Worker) createShards(r *replica.Replica, opts *orchestrationpb.ReplicaOpts, transport backend.Transport, info *orchestrationpb.ReplicaInfo) error {
+	for instance := uint32(1); instance < opts.GetShards(); instance++ {
+		shard, err := w.createInstance(opts, transport, r, instance)
+		if err != nil {
+			return err
+		}
+		_, clientAddr, err := shard.Listen()
+		if err != nil {
+			return err
 		}
+		if opts.GetUnixSocketDir() != "" {
+			socket := shardSocket(opts, instance)
+			info.ShardClientSockets = append(info.ShardClientSockets, socket)
+			w.usedAddrs = append(w.usedAddrs, socket)
+		} else {
+			port, err := getPort(clientAddr)
+			if err != nil {
+				return err
+			}
+			info.ShardClientPorts = append(info.ShardClientPorts, port)
+			w.usedAddrs = append(w.usedAddrs, clientAddr.String())
+		}
+		w.shards[hotstuff.ID(opts.GetID())] = append(w.shards[hotstuff.ID(opts.GetID())], shard)
 	}
-	return resp, nil
+	return nil
 }
 
-func (w *Worker) createReplica(opts *orchestrationpb.ReplicaOpts) (*replica.Replica, error) {
-	w.metricsLogger.Log(opts)
+// shardClientAddress returns the address that the client server of a shard listens on, which is its own socket,
+// or a random port on the host that the client server of the replica listens on.
+func shardClientAddress(opts *orchestrationpb.ReplicaOpts, instance uint32) string {
+	if opts.GetUnixSocketDir() != "" {
+		return shardSocket(opts, instance)
+	}
+	host, _, err := net.SplitHostPort(opts.GetClientListenAddress())
+	if err != nil {
+		return ":0"
+	}
+	return net.JoinHostPort(host, "0")
+}
+
+// shardSocket returns the address of the unix domain socket of the client server of a shard.
+func shardSocket(opts *orchestrationpb.ReplicaOpts, instance uint32) string {
+	return backend.UnixScheme + filepath.Join(opts.GetUnixSocketDir(), fmt.Sprintf("client-%d.%d.sock", opts.GetID(), instance))
+}
 
+func (w *Worker) createReplica(opts *orchestrationpb.ReplicaOpts, transport backend.Transport) (*replica.Replica, error) {
+	return w.createInstance(opts, transport, nil, 0)
+}
+
+// createInstance creates the given consensus instance of a replica. Instance 0 is the replica itself, which is
+// created if root is nil, while the other instances are shards of root, whose measurements are tagged with their
+// instance. The client server of a shard listens on a random port, or on a socket of its own, and its durable state
+// is kept in a directory of its own.
+func (w *Worker) createInstance(opts *orchestrationpb.ReplicaOpts, transport backend.Transport, root *replica.Replica, instance uint32) (*replica.Replica, error) {
 	// get private key and certificates
 	privKey, err := keygen.ParsePrivateKey(opts.GetPrivateKey())
 	if err != nil {
@@ -162,26 +402,27 @@ func (w *Worker) createReplica(opts *orchestrationpb.ReplicaOpts) (*replica.Repl
 	// prepare modules
 	builder := consensus.NewBuilder(hotstuff.ID(opts.GetID()), privKey)
 
-	var consensusRules consensus.Rules
-	if !modules.GetModule(opts.GetConsensus(), &consensusRules) {
-		return nil, fmt.Errorf("invalid consensus name: '%s'", opts.GetConsensus())
+	consensusRules, err := consensus.GetRules(opts.GetConsensus())
+	if err != nil {
+		return nil, err
 	}
 
-	var byz byzantine.Byzantine
-	if modules.GetModule(opts.GetByzantineStrategy(), &byz) {
+	if strategy := opts.GetByzantineStrategy(); strategy != "" {
+		byz, err := byzantine.Get(strategy)
+		if err != nil {
+			return nil, err
+		}
 		consensusRules = byz.Wrap(consensusRules)
-	} else if opts.GetByzantineStrategy() != "" {
-		return nil, fmt.Errorf("invalid byzantine strategy: '%s'", opts.GetByzantineStrategy())
 	}
 
-	var cryptoImpl consensus.CryptoImpl
-	if !modules.GetModule(opts.GetCrypto(), &cryptoImpl) {
-		return nil, fmt.Errorf("invalid crypto name: '%s'", opts.GetCrypto())
+	cryptoImpl, err := consensus.GetCrypto(opts.GetCrypto())
+	if err != nil {
+		return nil, err
 	}
 
-	var leaderRotation consensus.LeaderRotation
-	if !modules.GetModule(opts.GetLeaderRotation(), &leaderRotation) {
-		return nil, fmt.Errorf("invalid leader-rotation algorithm: '%s'", opts.GetLeaderRotation())
+	leaderRotation, err := consensus.GetLeaderRotation(opts.GetLeaderRotation())
+	if err != nil {
+		return nil, err
 	}
 
 	sync := synchronizer.New(synchronizer.NewViewDuration(
@@ -191,16 +432,23 @@ func (w *Worker) createReplica(opts *orchestrationpb.ReplicaOpts) (*replica.Repl
 		float64(opts.GetTimeoutMultiplier()),
 	))
 
+	metricsLogger := w.metricsLogger
+	if instance > 0 {
+		metricsLogger = metrics.InstanceLogger(metricsLogger, instance)
+	}
 	builder.Register(
 		consensus.New(consensusRules),
-		crypto.NewCache(cryptoImpl, 100), // TODO: consider making this configurable
+		newCrypto(cryptoImpl, opts),
 		leaderRotation,
 		sync,
-		w.metricsLogger,
+		metricsLogger,
 		blockchain.New(),
 	)
 
 	builder.OptionsBuilder().SetSharedRandomSeed(opts.GetSharedSeed())
+	if opts.GetCompressProposals() {
+		builder.OptionsBuilder().SetShouldCompressProposals()
+	}
 
 	if w.measurementInterval > 0 {
 		replicaMetrics := metrics.GetReplicaMetrics(w.metrics...)
@@ -219,18 +467,171 @@ func (w *Worker) createReplica(opts *orchestrationpb.ReplicaOpts) (*replica.Repl
 			gorums.WithDialTimeout(opts.GetConnectTimeout().AsDuration()),
 			gorums.WithGrpcDialOptions(grpc.WithReturnConnectionError()),
 		},
+		Transport: transport,
+		Jitter:    opts.GetJitter().AsDuration(),
+		Seed:      opts.GetSeed(),
+		ReplicaServerLimits: replica.ServerLimits{
+			MaxConcurrentStreams: opts.GetReplicaMaxConcurrentStreams(),
+			MaxRecvMsgSize:       int(opts.GetReplicaMaxMessageSize()),
+		},
+		ClientServerLimits: replica.ServerLimits{
+			MaxConcurrentStreams: opts.GetClientMaxConcurrentStreams(),
+			MaxRecvMsgSize:       int(opts.GetClientMaxMessageSize()),
+		},
+	}
+	if limits := opts.GetInboundRateLimits(); opts.GetRateLimitInbound() || len(limits) > 0 {
+		c.RateLimits = backend.DefaultRateLimits()
+		for name, limit := range limits {
+			class, err := backend.ParseMessageClass(name)
+			if err != nil {
+				return nil, err
+			}
+			c.RateLimits[class] = backend.RateLimit{Rate: limit.GetRate(), Burst: int(limit.GetBurst())}
+		}
+	}
+	if interval := opts.GetGossipInterval().AsDuration(); interval > 0 {
+		c.GossipInterval = interval
+		c.GossipFanout = 2
 	}
+	if interval := opts.GetHealthCheckInterval().AsDuration(); interval > 0 {
+		c.HealthCheckInterval = interval
+		c.HealthCheckThreshold = int(opts.GetHealthCheckThreshold())
+	}
+	if size := opts.GetProposalChunkSize(); size > 0 {
+		c.ProposalChunkSize = int(size)
+	}
+	if size := opts.GetSendQueueSize(); size > 0 {
+		c.SendQueueSize = int(size)
+	}
+	if size := opts.GetDedupSize(); size > 0 {
+		c.DedupSize = int(size)
+		c.DedupTTL = opts.GetDedupTTL().AsDuration()
+	}
+	c.ForwardCommands = opts.GetForwardCommands()
+	c.CommandCacheSize = int(opts.GetCommandCacheSize())
+	c.CommandCacheBytes = int(opts.GetCommandCacheBytes())
+	c.EvictCommands = opts.GetEvictCommands()
+	c.EventQueueCapacity = uint(opts.GetEventQueueCapacity())
+	c.FatalPanics = opts.GetFatalPanics()
+	c.BatchBytes = int(opts.GetBatchBytes())
+	c.BatchDelay = opts.GetBatchDelay().AsDuration()
+	if c.BatchPolicy, err = replica.ParseBatchPolicy(opts.GetBatchPolicy()); err != nil {
+		return nil, err
+	}
+	c.ClientWeights = opts.GetClientWeights()
+	c.MaxBlockBytes = int(opts.GetMaxBlockBytes())
+	c.ExecutionLag = int(opts.GetExecutionLag())
+	c.SnapshotInterval = uint64(opts.GetSnapshotInterval())
+	// the status and commit stream servers report on the first shard.
+	if instance == 0 {
+		c.StatusAddress = opts.GetStatusListenAddress()
+		c.CommitStreamAddress = opts.GetCommitStreamListenAddress()
+		c.CommitStreamBuffer = int(opts.GetCommitStreamBuffer())
+	}
+	if addr := opts.GetApplicationAddress(); addr != "" {
+		if opts.GetShards() > 1 {
+			return nil, fmt.Errorf("shards are not supported with an external application")
+		}
+		if c.SnapshotInterval > 0 {
+			return nil, fmt.Errorf("state transfer is not supported with an external application")
+		}
+		c.ApplicationAddress = addr
+	}
+	switch opts.GetApplication() {
+	case "":
+	case "kvstore":
+		if c.ApplicationAddress != "" {
+			return nil, fmt.Errorf("the kvstore application cannot be used with an external application")
+		}
+		c.Application = kvstore.New()
+	default:
+		return nil, fmt.Errorf("invalid application: '%s'", opts.GetApplication())
+	}
+	if dir := opts.GetDataDir(); dir != "" {
+		if c.ApplicationAddress != "" {
+			return nil, fmt.Errorf("durable snapshots are not supported with an external application")
+		}
+		c.DataDir = filepath.Join(dir, fmt.Sprintf("replica-%d", opts.GetID()))
+		if instance > 0 {
+			c.DataDir = filepath.Join(dir, fmt.Sprintf("replica-%d.%d", opts.GetID(), instance))
+		}
+		c.DurableSnapshotViews = uint64(opts.GetDurableSnapshotViews())
+		c.DurableSnapshotBytes = opts.GetDurableSnapshotBytes()
+		c.PersistCommands = opts.GetPersistCommands()
+		c.CommandLogSync, err = replica.ParseCommandLogSync(opts.GetCommandLogSync())
+		if err != nil {
+			return nil, err
+		}
+		if store, ok := c.Application.(*kvstore.Store); ok {
+			c.Snapshotter = kvstore.NewFileSnapshotter(store, c.DataDir)
+		}
+	}
+	c.Modules = replicaModules(opts)
+	if opts.GetTreeFanout() > 0 {
+		c.TreeFanout = int(opts.GetTreeFanout())
+		// request the proposal from the leader if it has not arrived halfway through the initial view duration.
+		c.TreeSubTimeout = opts.GetInitialTimeout().AsDuration() / 2
+	}
+	if len(opts.GetLatencies()) > 0 {
+		c.Latencies = make(map[hotstuff.ID]time.Duration, len(opts.GetLatencies()))
+		for id, latency := range opts.GetLatencies() {
+			c.Latencies[hotstuff.ID(id)] = latency.AsDuration()
+		}
+	}
+
+	if root != nil {
+		c.ClientAddress = shardClientAddress(opts, instance)
+		return root.NewShard(instance, c, builder)
+	}
+	r := replica.New(c, builder)
+	if err := r.RecoveryError(); err != nil {
+		return nil, fmt.Errorf("failed to recover: %w", err)
+	}
+	return r, nil
+}
+
+// cryptoCacheSize returns the size of the crypto cache of a replica, which is DefaultCryptoCacheSize if it is unset.
+func cryptoCacheSize(opts *orchestrationpb.ReplicaOpts) uint32 {
+	if opts.CryptoCacheSize == nil {
+		return DefaultCryptoCacheSize
+	}
+	return opts.GetCryptoCacheSize()
+}
 
-	return replica.New(c, builder), nil
+// newCrypto returns the crypto module of a replica, which caches the verified signatures and certificates
+// unless the size of the cache is zero.
+func newCrypto(impl consensus.CryptoImpl, opts *orchestrationpb.ReplicaOpts) consensus.Crypto {
+	size := cryptoCacheSize(opts)
+	if size == 0 {
+		return crypto.New(impl)
+	}
+	return crypto.NewCache(impl, int(size))
+}
+
+// replicaModules returns the modules selected by the options of a replica, by the kind of module.
+// They are reported by the status server and recorded in the start event of the replica,
+// since the replicas of an experiment may be configured differently.
+func replicaModules(opts *orchestrationpb.ReplicaOpts) map[string]string {
+	modules := map[string]string{
+		"consensus":         opts.GetConsensus(),
+		"crypto":            opts.GetCrypto(),
+		"leader-rotation":   opts.GetLeaderRotation(),
+		"crypto-cache-size": strconv.FormatUint(uint64(cryptoCacheSize(opts)), 10),
+	}
+	if strategy := opts.GetByzantineStrategy(); strategy != "" {
+		modules["byzantine"] = strategy
+	}
+	return modules
 }
 
 func (w *Worker) startReplicas(req *orchestrationpb.StartReplicaRequest) (*orchestrationpb.StartReplicaResponse, error) {
+	w.configuration = req.GetConfiguration()
 	for _, id := range req.GetIDs() {
 		replica, ok := w.replicas[hotstuff.ID(id)]
 		if !ok {
 			return nil, status.Errorf(codes.NotFound, "The replica with ID %d was not found.", id)
 		}
-		cfg, err := getConfiguration(req.GetConfiguration(), false)
+		cfg, err := getConfiguration(req.GetConfiguration(), false, 0)
 		if err != nil {
 			return nil, err
 		}
@@ -238,9 +639,27 @@ func (w *Worker) startReplicas(req *orchestrationpb.StartReplicaRequest) (*orche
 		if err != nil {
 			return nil, err
 		}
+		// the shards share the connections of the replica, and connect after it.
+		for _, shard := range w.shards[hotstuff.ID(id)] {
+			if err := shard.Connect(cfg); err != nil {
+				return nil, err
+			}
+		}
 		defer func(id uint32) {
-			w.metricsLogger.Log(&types.StartEvent{Event: types.NewReplicaEvent(id, time.Now())})
+			w.metricsLogger.Log(&types.StartEvent{
+				Event:      types.NewReplicaEvent(id, time.Now()),
+				Config:     req.GetExperimentConfig(),
+				Modules:    replicaModules(w.replicaOpts[hotstuff.ID(id)]),
+				Seed:       w.replicaOpts[hotstuff.ID(id)].GetSeed(),
+				MasterSeed: req.GetMasterSeed(),
+				CPUs:       w.pinnedCPUs,
+				MaxProcs:   uint32(runtime.GOMAXPROCS(0)),
+			})
 			replica.Start()
+			for _, shard := range w.shards[hotstuff.ID(id)] {
+				shard.Start()
+			}
+			w.scheduleFaults(hotstuff.ID(id), w.replicaOpts[hotstuff.ID(id)].GetFaults())
 		}(id)
 	}
 	return &orchestrationpb.StartReplicaResponse{}, nil
@@ -248,34 +667,155 @@ func (w *Worker) startReplicas(req *orchestrationpb.StartReplicaRequest) (*orche
 
 func (w *Worker) stopReplicas(req *orchestrationpb.StopReplicaRequest) (*orchestrationpb.StopReplicaResponse, error) {
 	res := &orchestrationpb.StopReplicaResponse{
-		Hashes: make(map[uint32][]byte),
+		Hashes:       make(map[uint32][]byte),
+		StateHashes:  make(map[uint32][]byte),
+		Incompatible: make(map[uint32]*orchestrationpb.IncompatiblePeers),
+		ShardHashes:  make(map[uint32]*orchestrationpb.ShardHashes),
 	}
+	replicas := make(map[uint32]*replica.Replica, len(req.GetIDs()))
 	for _, id := range req.GetIDs() {
 		r, ok := w.replicas[hotstuff.ID(id)]
 		if !ok {
 			return nil, status.Errorf(codes.NotFound, "The replica with id %d was not found.", id)
 		}
-		r.Stop()
-		res.Hashes[id] = r.GetHash()
-		// TODO: return test results
+		replicas[id] = r
+	}
+	faulty := make(map[uint32]bool, len(replicas))
+	for id := range replicas {
+		faulty[id] = w.stopFaults(hotstuff.ID(id))
+	}
+	// the replicas are shut down concurrently, such that they can drain the connections from each other.
+	var (
+		wg  sync.WaitGroup
+		mut sync.Mutex
+		err error
+	)
+	for id, r := range replicas {
+		wg.Add(1)
+		go func(id uint32, r *replica.Replica) {
+			defer wg.Done()
+			// the shards are shut down first, since they share the connections of the replica.
+			serr := w.shutdownShards(hotstuff.ID(id), w.drainTimeouts[hotstuff.ID(id)])
+			if rerr := r.Shutdown(w.drainTimeouts[hotstuff.ID(id)]); serr == nil {
+				serr = rerr
+			}
+			mut.Lock()
+			defer mut.Unlock()
+			if serr != nil && err == nil {
+				err = fmt.Errorf("failed to shut down replica %d: %w", id, serr)
+			}
+			for peer, perr := range r.IncompatiblePeers() {
+				if res.Incompatible[id] == nil {
+					res.Incompatible[id] = &orchestrationpb.IncompatiblePeers{}
+				}
+				res.Incompatible[id].Peers = append(res.Incompatible[id].Peers, fmt.Sprintf("replica %d: %v", peer, perr))
+			}
+			if faulty[id] {
+				return
+			}
+			res.Hashes[id] = r.GetHash()
+			if shards := w.shards[hotstuff.ID(id)]; len(shards) > 0 {
+				res.ShardHashes[id] = &orchestrationpb.ShardHashes{}
+				for _, shard := range shards {
+					res.ShardHashes[id].Hashes = append(res.ShardHashes[id].Hashes, shard.GetHash())
+				}
+			}
+			if store, ok := r.Application().(*kvstore.Store); ok {
+				res.StateHashes[id] = store.StateHash()
+				w.logCheckpoints(id, store)
+			}
+		}(id, r)
+	}
+	wg.Wait()
+	for id := range replicas {
+		delete(w.replicas, hotstuff.ID(id))
+		delete(w.shards, hotstuff.ID(id))
+		delete(w.replicaOpts, hotstuff.ID(id))
+		delete(w.listenAddrs, hotstuff.ID(id))
+	}
+	if err != nil {
+		return nil, err
 	}
 	return res, nil
 }
 
+// shutdownShards shuts down the shards of a replica, and returns the first error.
+func (w *Worker) shutdownShards(id hotstuff.ID, drainTimeout time.Duration) (err error) {
+	for _, shard := range w.shards[id] {
+		if serr := shard.Shutdown(drainTimeout); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+// logCheckpoints logs the checkpoints recorded by the key-value store of a replica,
+// such that the state of the replicas can be compared between runs.
+func (w *Worker) logCheckpoints(id uint32, store *kvstore.Store) {
+	for _, c := range store.Checkpoints() {
+		w.metricsLogger.Log(&types.CheckpointEvent{
+			Event: types.NewReplicaEvent(id, time.Now()),
+			Index: c.Index,
+			Hash:  c.Hash,
+		})
+	}
+}
+
 func (w *Worker) startClients(req *orchestrationpb.StartClientRequest) (*orchestrationpb.StartClientResponse, error) {
 	ca := req.GetCertificateAuthority()
 	cp := x509.NewCertPool()
 	cp.AppendCertsFromPEM(ca)
+	for _, opts := range req.GetClients() {
+		w.clientResources[hotstuff.ID(opts.GetID())] = opts.GetResources()
+	}
+	w.limitResources()
 	for _, opts := range req.GetClients() {
 		w.metricsLogger.Log(opts)
 
+		loadMode, err := client.ParseLoadMode(opts.GetLoadMode())
+		if err != nil {
+			return nil, err
+		}
+		profile := client.LoadProfile{Ramp: opts.GetLoadRamp()}
+		for _, step := range opts.GetLoadProfile() {
+			profile.Steps = append(profile.Steps, client.LoadStep{Duration: step.GetDuration().AsDuration(), Rate: step.GetRate()})
+		}
+		if len(profile.Steps) > 0 {
+			if err := profile.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid load profile: %w", err)
+			}
+		} else if loadMode.IsOpenLoop() && loadMode != client.TraceLoad && opts.GetTargetRate() <= 0 {
+			return nil, fmt.Errorf("invalid target rate for open-loop client: %v", opts.GetTargetRate())
+		}
+		ackMode, err := client.ParseAckMode(opts.GetAckMode())
+		if err != nil {
+			return nil, err
+		}
+		workload, err := client.ParseWorkload(opts.GetWorkload())
+		if err != nil {
+			return nil, err
+		}
+		var trace client.Trace
+		if loadMode == client.TraceLoad {
+			trace, err = client.ParseTrace(bytes.NewReader(req.GetTrace()))
+			if err != nil {
+				return nil, err
+			}
+			if len(trace) == 0 {
+				return nil, fmt.Errorf("no trace for client in trace mode")
+			}
+		}
+
+		// the payloads are generated deterministically, but differ between the clients.
+		seed := opts.GetPayloadSeed() + int64(opts.GetID())
+
 		c := client.Config{
 			ID:            hotstuff.ID(opts.GetID()),
 			TLS:           opts.GetUseTLS(),
 			RootCAs:       cp,
 			MaxConcurrent: opts.GetMaxConcurrent(),
 			PayloadSize:   opts.GetPayloadSize(),
-			Input:         io.NopCloser(rand.Reader),
+			Input:         client.NewPayloadReader(seed, opts.GetCompressiblePayload()),
 			ManagerOptions: []gorums.ManagerOption{
 				gorums.WithDialTimeout(opts.GetConnectTimeout().AsDuration()),
 				gorums.WithGrpcDialOptions(grpc.WithReturnConnectionError()),
@@ -283,6 +823,44 @@ func (w *Worker) startClients(req *orchestrationpb.StartClientRequest) (*orchest
 			RateLimit:        opts.GetRateLimit(),
 			RateStep:         opts.GetRateStep(),
 			RateStepInterval: opts.GetRateStepInterval().AsDuration(),
+			LoadMode:         loadMode,
+			TargetRate:       opts.GetTargetRate(),
+			LoadProfile:      profile,
+			Trace:            trace,
+			TraceSpeed:       opts.GetTraceSpeed(),
+			TraceLoop:        opts.GetTraceLoop(),
+			Seed:             opts.GetSeed(),
+
+			PayloadDistribution: client.SizeDistribution(opts.GetPayloadDistribution()),
+			PayloadMin:          opts.GetPayloadMin(),
+			PayloadMax:          opts.GetPayloadMax(),
+			PayloadZipfS:        opts.GetPayloadZipfS(),
+			PayloadSeed:         seed,
+
+			Workload:             workload,
+			KVKeys:               opts.GetKVKeys(),
+			KVReadRatio:          opts.GetKVReadRatio(),
+			KVDeleteRatio:        opts.GetKVDeleteRatio(),
+			KVCheckpointInterval: opts.GetKVCheckpointInterval(),
+
+			RequestTimeout:    opts.GetRequestTimeout().AsDuration(),
+			MaxRequestTimeout: opts.GetMaxRequestTimeout().AsDuration(),
+			MaxRetries:        int(opts.GetMaxRetries()),
+			DrainTimeout:      opts.GetDrainTimeout().AsDuration(),
+			BatchSize:         opts.GetBatchSize(),
+			BatchTimeout:      opts.GetBatchTimeout().AsDuration(),
+
+			Sessions: int(opts.GetSessions()),
+
+			TargetLatency: opts.GetTargetLatency().AsDuration(),
+			MinConcurrent: opts.GetMinConcurrent(),
+			AckMode:       ackMode,
+		}
+		if err := c.ValidatePayload(); err != nil {
+			return nil, err
+		}
+		if err := c.ValidateKV(); err != nil {
+			return nil, err
 		}
 		mods := modules.NewBuilder(c.ID)
 
@@ -294,7 +872,7 @@ func (w *Worker) startClients(req *orchestrationpb.StartClientRequest) (*orchest
 
 		mods.Register(w.metricsLogger)
 		cli := client.New(c, mods)
-		cfg, err := getConfiguration(req.GetConfiguration(), true)
+		cfg, err := getConfiguration(req.GetConfiguration(), true, opts.GetShard())
 		if err != nil {
 			return nil, err
 		}
@@ -303,33 +881,81 @@ func (w *Worker) startClients(req *orchestrationpb.StartClientRequest) (*orchest
 			return nil, err
 		}
 		cli.Start()
-		w.metricsLogger.Log(&types.StartEvent{Event: types.NewClientEvent(uint32(c.ID), time.Now())})
+		w.metricsLogger.Log(&types.StartEvent{
+			Event: types.NewClientEvent(uint32(c.ID), time.Now()),
+			Payload: &types.Payload{
+				Distribution: opts.GetPayloadDistribution(),
+				Size:         opts.GetPayloadSize(),
+				Min:          opts.GetPayloadMin(),
+				Max:          opts.GetPayloadMax(),
+				ZipfS:        opts.GetPayloadZipfS(),
+				Seed:         seed,
+				Compressible: opts.GetCompressiblePayload(),
+			},
+			Config:     req.GetExperimentConfig(),
+			Seed:       opts.GetSeed(),
+			MasterSeed: req.GetMasterSeed(),
+			CPUs:       w.pinnedCPUs,
+			MaxProcs:   uint32(runtime.GOMAXPROCS(0)),
+		})
 		w.clients[hotstuff.ID(opts.GetID())] = cli
 	}
 	return &orchestrationpb.StartClientResponse{}, nil
 }
 
 func (w *Worker) stopClients(req *orchestrationpb.StopClientRequest) (*orchestrationpb.StopClientResponse, error) {
+	clients := make([]*client.Client, 0, len(req.GetIDs()))
 	for _, id := range req.GetIDs() {
 		cli, ok := w.clients[hotstuff.ID(id)]
 		if !ok {
 			return nil, status.Errorf(codes.NotFound, "the client with ID %d was not found", id)
 		}
-		cli.Stop()
+		clients = append(clients, cli)
 	}
+	// the clients drain their commands in flight concurrently, such that the drain timeout is not multiplied.
+	var wg sync.WaitGroup
+	for _, cli := range clients {
+		wg.Add(1)
+		go func(cli *client.Client) {
+			defer wg.Done()
+			cli.Stop()
+		}(cli)
+	}
+	wg.Wait()
 	return &orchestrationpb.StopClientResponse{}, nil
 }
 
-func getConfiguration(conf map[uint32]*orchestrationpb.ReplicaInfo, client bool) ([]backend.ReplicaInfo, error) {
+// getConfiguration returns the addresses that the replicas or the clients connect to.
+// The clients connect to the client servers of the given shard.
+func getConfiguration(conf map[uint32]*orchestrationpb.ReplicaInfo, client bool, shard uint32) ([]backend.ReplicaInfo, error) {
 	replicas := make([]backend.ReplicaInfo, 0, len(conf))
 	for _, replica := range conf {
 		pubKey, err := keygen.ParsePublicKey(replica.GetPublicKey())
 		if err != nil {
 			return nil, err
 		}
+		clientSocket, clientPort := replica.GetClientSocket(), replica.GetClientPort()
+		if client && shard > 0 {
+			if int(shard) > len(replica.GetShardClientSockets())+len(replica.GetShardClientPorts()) {
+				return nil, fmt.Errorf("replica %d does not run shard %d", replica.GetID(), shard)
+			}
+			if sockets := replica.GetShardClientSockets(); len(sockets) > 0 {
+				clientSocket = sockets[shard-1]
+			} else {
+				clientPort = replica.GetShardClientPorts()[shard-1]
+			}
+		}
 		var addr string
-		if client {
-			addr = net.JoinHostPort(replica.GetAddress(), strconv.Itoa(int(replica.GetClientPort())))
+		if client && clientSocket != "" {
+			addr = clientSocket
+		} else if !client && replica.GetReplicaSocket() != "" {
+			addr = replica.GetReplicaSocket()
+		} else if client {
+			host := replica.GetClientAddress()
+			if host == "" {
+				host = replica.GetAddress()
+			}
+			addr = net.JoinHostPort(host, strconv.Itoa(int(clientPort)))
 		} else {
 			addr = net.JoinHostPort(replica.GetAddress(), strconv.Itoa(int(replica.GetReplicaPort())))
 		}
@@ -342,8 +968,8 @@ func getConfiguration(conf map[uint32]*orchestrationpb.ReplicaInfo, client bool)
 	return replicas, nil
 }
 
-func getPort(lis net.Listener) (uint32, error) {
-	_, portStr, err := net.SplitHostPort(lis.Addr().String())
+func getPort(addr net.Addr) (uint32, error) {
+	_, portStr, err := net.SplitHostPort(addr.String())
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,230 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package apppb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ApplicationClient is the client API for Application service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ApplicationClient interface {
+	// Accept returns whether the replica should accept a proposal with the given
+	// commands. It is only called for proposals that the replica would otherwise
+	// accept. If the application does not answer in time, the proposal is
+	// accepted.
+	Accept(ctx context.Context, in *AcceptRequest, opts ...grpc.CallOption) (*AcceptResponse, error)
+	// BatchHint returns the number of commands that the replica should batch in
+	// its next proposal. It is called before the replica waits for a batch.
+	BatchHint(ctx context.Context, in *BatchHintRequest, opts ...grpc.CallOption) (*BatchHintResponse, error)
+	// Execute receives the committed blocks in the order that they are
+	// committed, and replies once for each block, in the same order, after the
+	// block has been executed. If the stream fails, the replica opens a new
+	// stream and sends the blocks again, starting with the first block that was
+	// not acknowledged. The application must skip blocks that it has already
+	// executed, which it can tell by their height.
+	Execute(ctx context.Context, opts ...grpc.CallOption) (Application_ExecuteClient, error)
+}
+
+type applicationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewApplicationClient(cc grpc.ClientConnInterface) ApplicationClient {
+	return &applicationClient{cc}
+}
+
+func (c *applicationClient) Accept(ctx context.Context, in *AcceptRequest, opts ...grpc.CallOption) (*AcceptResponse, error) {
+	out := new(AcceptResponse)
+	err := c.cc.Invoke(ctx, "/apppb.Application/Accept", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *applicationClient) BatchHint(ctx context.Context, in *BatchHintRequest, opts ...grpc.CallOption) (*BatchHintResponse, error) {
+	out := new(BatchHintResponse)
+	err := c.cc.Invoke(ctx, "/apppb.Application/BatchHint", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *applicationClient) Execute(ctx context.Context, opts ...grpc.CallOption) (Application_ExecuteClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Application_ServiceDesc.Streams[0], "/apppb.Application/Execute", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &applicationExecuteClient{stream}
+	return x, nil
+}
+
+type Application_ExecuteClient interface {
+	Send(*Block) error
+	Recv() (*Executed, error)
+	grpc.ClientStream
+}
+
+type applicationExecuteClient struct {
+	grpc.ClientStream
+}
+
+func (x *applicationExecuteClient) Send(m *Block) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *applicationExecuteClient) Recv() (*Executed, error) {
+	m := new(Executed)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ApplicationServer is the server API for Application service.
+// All implementations must embed UnimplementedApplicationServer
+// for forward compatibility
+type ApplicationServer interface {
+	// Accept returns whether the replica should accept a proposal with the given
+	// commands. It is only called for proposals that the replica would otherwise
+	// accept. If the application does not answer in time, the proposal is
+	// accepted.
+	Accept(context.Context, *AcceptRequest) (*AcceptResponse, error)
+	// BatchHint returns the number of commands that the replica should batch in
+	// its next proposal. It is called before the replica waits for a batch.
+	BatchHint(context.Context, *BatchHintRequest) (*BatchHintResponse, error)
+	// Execute receives the committed blocks in the order that they are
+	// committed, and replies once for each block, in the same order, after the
+	// block has been executed. If the stream fails, the replica opens a new
+	// stream and sends the blocks again, starting with the first block that was
+	// not acknowledged. The application must skip blocks that it has already
+	// executed, which it can tell by their height.
+	Execute(Application_ExecuteServer) error
+	mustEmbedUnimplementedApplicationServer()
+}
+
+// UnimplementedApplicationServer must be embedded to have forward compatible implementations.
+type UnimplementedApplicationServer struct {
+}
+
+func (UnimplementedApplicationServer) Accept(context.Context, *AcceptRequest) (*AcceptResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Accept not implemented")
+}
+func (UnimplementedApplicationServer) BatchHint(context.Context, *BatchHintRequest) (*BatchHintResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchHint not implemented")
+}
+func (UnimplementedApplicationServer) Execute(Application_ExecuteServer) error {
+	return status.Errorf(codes.Unimplemented, "method Execute not implemented")
+}
+func (UnimplementedApplicationServer) mustEmbedUnimplementedApplicationServer() {}
+
+// UnsafeApplicationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ApplicationServer will
+// result in compilation errors.
+type UnsafeApplicationServer interface {
+	mustEmbedUnimplementedApplicationServer()
+}
+
+func RegisterApplicationServer(s grpc.ServiceRegistrar, srv ApplicationServer) {
+	s.RegisterService(&Application_ServiceDesc, srv)
+}
+
+func _Application_Accept_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AcceptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ApplicationServer).Accept(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/apppb.Application/Accept",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ApplicationServer).Accept(ctx, req.(*AcceptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Application_BatchHint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchHintRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ApplicationServer).BatchHint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/apppb.Application/BatchHint",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ApplicationServer).BatchHint(ctx, req.(*BatchHintRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Application_Execute_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ApplicationServer).Execute(&applicationExecuteServer{stream})
+}
+
+type Application_ExecuteServer interface {
+	Send(*Executed) error
+	Recv() (*Block, error)
+	grpc.ServerStream
+}
+
+type applicationExecuteServer struct {
+	grpc.ServerStream
+}
+
+func (x *applicationExecuteServer) Send(m *Executed) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *applicationExecuteServer) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Application_ServiceDesc is the grpc.ServiceDesc for Application service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Application_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "apppb.Application",
+	HandlerType: (*ApplicationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Accept",
+			Handler:    _Application_Accept_Handler,
+		},
+		{
+			MethodName: "BatchHint",
+			Handler:    _Application_BatchHint_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Execute",
+			Handler:       _Application_Execute_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "internal/proto/apppb/app.proto",
+}
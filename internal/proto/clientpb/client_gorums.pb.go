@@ -12,7 +12,6 @@ import (
 	gorums "github.com/relab/gorums"
 	encoding "google.golang.org/grpc/encoding"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
-	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 const (
@@ -122,21 +121,40 @@ type Node struct {
 
 // ExecCommand sends a command to all replicas and waits for valid signatures
 // from f+1 replicas
-func (c *Configuration) ExecCommand(ctx context.Context, in *Command) *AsyncEmpty {
+func (c *Configuration) ExecCommand(ctx context.Context, in *Command) *AsyncCommandResponse {
 	cd := gorums.QuorumCallData{
 		Message: in,
 		Method:  "clientpb.Client.ExecCommand",
 	}
 	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
-		r := make(map[uint32]*emptypb.Empty, len(replies))
+		r := make(map[uint32]*CommandResponse, len(replies))
 		for k, v := range replies {
-			r[k] = v.(*emptypb.Empty)
+			r[k] = v.(*CommandResponse)
 		}
 		return c.qspec.ExecCommandQF(req.(*Command), r)
 	}
 
 	fut := c.Configuration.AsyncCall(ctx, cd)
-	return &AsyncEmpty{fut}
+	return &AsyncCommandResponse{fut}
+}
+
+// ExecCommandBatch sends several commands in one message, and waits until all of them have been executed.
+// The replicas handle each command as if it was sent on its own.
+func (c *Configuration) ExecCommandBatch(ctx context.Context, in *Batch) *AsyncBatchResponse {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "clientpb.Client.ExecCommandBatch",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*BatchResponse, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*BatchResponse)
+		}
+		return c.qspec.ExecCommandBatchQF(req.(*Batch), r)
+	}
+
+	fut := c.Configuration.AsyncCall(ctx, cd)
+	return &AsyncBatchResponse{fut}
 }
 
 // QuorumSpec is the interface of quorum functions for Client.
@@ -148,12 +166,50 @@ type QuorumSpec interface {
 	// supplied to the ExecCommand method at call time, and may or may not
 	// be used by the quorum function. If the in parameter is not needed
 	// you should implement your quorum function with '_ *Command'.
-	ExecCommandQF(in *Command, replies map[uint32]*emptypb.Empty) (*emptypb.Empty, bool)
+	ExecCommandQF(in *Command, replies map[uint32]*CommandResponse) (*CommandResponse, bool)
+
+	// ExecCommandBatchQF is the quorum function for the ExecCommandBatch
+	// asynchronous quorum call method. The in parameter is the request object
+	// supplied to the ExecCommandBatch method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *Batch'.
+	ExecCommandBatchQF(in *Batch, replies map[uint32]*BatchResponse) (*BatchResponse, bool)
+
+	// QueryQF is the quorum function for the Query
+	// quorum call method. The in parameter is the request object
+	// supplied to the Query method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *QueryRequest'.
+	QueryQF(in *QueryRequest, replies map[uint32]*QueryResponse) (*QueryResponse, bool)
+}
+
+// Query sends a read-only query to all replicas, which answer it from their executed state
+// without ordering it through consensus.
+func (c *Configuration) Query(ctx context.Context, in *QueryRequest) (resp *QueryResponse, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "clientpb.Client.Query",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*QueryResponse, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*QueryResponse)
+		}
+		return c.qspec.QueryQF(req.(*QueryRequest), r)
+	}
+
+	res, err := c.Configuration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*QueryResponse), err
 }
 
 // Client is the server-side API for the Client Service
 type Client interface {
-	ExecCommand(ctx gorums.ServerCtx, request *Command) (response *emptypb.Empty, err error)
+	ExecCommand(ctx gorums.ServerCtx, request *Command) (response *CommandResponse, err error)
+	ExecCommandBatch(ctx gorums.ServerCtx, request *Batch) (response *BatchResponse, err error)
+	Query(ctx gorums.ServerCtx, request *QueryRequest) (response *QueryResponse, err error)
 }
 
 func RegisterClientServer(srv *gorums.Server, impl Client) {
@@ -163,25 +219,64 @@ func RegisterClientServer(srv *gorums.Server, impl Client) {
 		resp, err := impl.ExecCommand(ctx, req)
 		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
 	})
+	srv.RegisterHandler("clientpb.Client.ExecCommandBatch", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*Batch)
+		defer ctx.Release()
+		resp, err := impl.ExecCommandBatch(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("clientpb.Client.Query", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*QueryRequest)
+		defer ctx.Release()
+		resp, err := impl.Query(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
 }
 
-type internalEmpty struct {
+type internalBatchResponse struct {
 	nid   uint32
-	reply *emptypb.Empty
+	reply *BatchResponse
 	err   error
 }
 
-// AsyncEmpty is a async object for processing replies.
-type AsyncEmpty struct {
+type internalCommandResponse struct {
+	nid   uint32
+	reply *CommandResponse
+	err   error
+}
+
+type internalQueryResponse struct {
+	nid   uint32
+	reply *QueryResponse
+	err   error
+}
+
+// AsyncBatchResponse is a async object for processing replies.
+type AsyncBatchResponse struct {
+	*gorums.Async
+}
+
+// Get returns the reply and any error associated with the called method.
+// The method blocks until a reply or error is available.
+func (f *AsyncBatchResponse) Get() (*BatchResponse, error) {
+	resp, err := f.Async.Get()
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*BatchResponse), err
+}
+
+// AsyncCommandResponse is a async object for processing replies.
+type AsyncCommandResponse struct {
 	*gorums.Async
 }
 
 // Get returns the reply and any error associated with the called method.
 // The method blocks until a reply or error is available.
-func (f *AsyncEmpty) Get() (*emptypb.Empty, error) {
+func (f *AsyncCommandResponse) Get() (*CommandResponse, error) {
 	resp, err := f.Async.Get()
 	if err != nil {
 		return nil, err
 	}
-	return resp.(*emptypb.Empty), err
+	return resp.(*CommandResponse), err
 }
@@ -0,0 +1,61 @@
+package hotstuffpb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// MaxDecompressedSize is the upper limit on the size of a decompressed command.
+// It protects the receiver against decompression bombs.
+const MaxDecompressedSize = 64 << 20
+
+// CompressBlock compresses the command of the block in place.
+// The compressed command is only used if it is smaller than the original command.
+// Peers that do not compress their proposals can still be understood,
+// because BlockFromProto accepts both compressed and uncompressed commands.
+// CompressBlock returns the size of the command before and after compression.
+func CompressBlock(block *Block) (rawSize, compressedSize int) {
+	rawSize = len(block.GetCommand())
+	if rawSize == 0 || len(block.GetCompressedCommand()) > 0 {
+		return rawSize, len(block.GetCompressedCommand())
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	// writing to a bytes.Buffer cannot fail
+	_, _ = w.Write(block.GetCommand())
+	_ = w.Close()
+
+	if buf.Len() >= rawSize {
+		return rawSize, rawSize
+	}
+
+	block.CompressedCommand = buf.Bytes()
+	block.Command = nil
+	return rawSize, buf.Len()
+}
+
+// blockCommand returns the uncompressed command of the block.
+func blockCommand(block *Block) ([]byte, error) {
+	compressed := block.GetCompressedCommand()
+	if len(compressed) == 0 {
+		return block.GetCommand(), nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compressed command: %w", err)
+	}
+	defer r.Close()
+
+	cmd, err := io.ReadAll(io.LimitReader(r, MaxDecompressedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress command: %w", err)
+	}
+	if len(cmd) > MaxDecompressedSize {
+		return nil, fmt.Errorf("decompressed command exceeds %d bytes", MaxDecompressedSize)
+	}
+	return cmd, nil
+}
@@ -1,7 +1,9 @@
 package hotstuffpb
 
 import (
+	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/consensus"
@@ -27,24 +29,52 @@ func SignatureToProto(sig consensus.Signature) *Signature {
 	return signature
 }
 
+// MaxParticipants is the upper limit on the number of signatures in a threshold signature, and on the number of QCs
+// in an AggregateQC, that the converters accept. It protects the receiver against messages that are expensive to
+// process, since the size of a valid message is bounded by the number of replicas.
+const MaxParticipants = 1024
+
+// maxECDSAComponentSize is the upper limit on the size of the r and s values of an ECDSA signature,
+// which is the size of an integer modulo the order of the largest supported curve (P-521).
+const maxECDSAComponentSize = 66
+
 // SignatureFromProto converts a hotstuffpb.Signature to an ecdsa.Signature.
+// It returns nil if the signature is invalid.
 func SignatureFromProto(sig *Signature) consensus.Signature {
+	s, err := signatureFromProto(sig)
+	if err != nil {
+		return nil
+	}
+	return s
+}
+
+// signatureFromProto converts a hotstuffpb.Signature to a consensus.Signature,
+// and returns an error if the signature is malformed.
+func signatureFromProto(sig *Signature) (consensus.Signature, error) {
 	if signature := sig.GetECDSASig(); signature != nil {
-		r := new(big.Int)
-		r.SetBytes(signature.GetR())
-		s := new(big.Int)
-		s.SetBytes(signature.GetS())
-		return ecdsa.RestoreSignature(r, s, hotstuff.ID(signature.GetSigner()))
+		return ecdsaSignatureFromProto(signature)
 	}
 	if signature := sig.GetBLS12Sig(); signature != nil {
 		s := &bls12.Signature{}
 		err := s.FromBytes(signature.GetSig())
 		if err != nil {
-			return nil
+			return nil, err
 		}
-		return s
+		return s, nil
 	}
-	return nil
+	return nil, nil
+}
+
+// ecdsaSignatureFromProto restores an ECDSA signature, and returns an error if its r or s value is too large.
+func ecdsaSignatureFromProto(sig *ECDSASignature) (*ecdsa.Signature, error) {
+	if len(sig.GetR()) > maxECDSAComponentSize || len(sig.GetS()) > maxECDSAComponentSize {
+		return nil, fmt.Errorf("ecdsa signature exceeds %d bytes per component", maxECDSAComponentSize)
+	}
+	r := new(big.Int)
+	r.SetBytes(sig.GetR())
+	s := new(big.Int)
+	s.SetBytes(sig.GetS())
+	return ecdsa.RestoreSignature(r, s, hotstuff.ID(sig.GetSigner())), nil
 }
 
 // ThresholdSignatureToProto converts a threshold signature to a protocol buffers message.
@@ -60,6 +90,8 @@ func ThresholdSignatureToProto(sig consensus.ThresholdSignature) *ThresholdSigna
 				S:      p.S().Bytes(),
 			})
 		}
+		// the signatures are sorted by signer, such that a threshold signature is always encoded the same way.
+		sort.Slice(sigs, func(i, j int) bool { return sigs[i].GetSigner() < sigs[j].GetSigner() })
 		signature.AggSig = &ThresholdSignature_ECDSASigs{ECDSASigs: &ECDSAThresholdSignature{
 			Sigs: sigs,
 		}}
@@ -73,59 +105,123 @@ func ThresholdSignatureToProto(sig consensus.ThresholdSignature) *ThresholdSigna
 }
 
 // ThresholdSignatureFromProto converts a protocol buffers message to a threshold signature.
+// It returns nil if the threshold signature is invalid.
 func ThresholdSignatureFromProto(sig *ThresholdSignature) consensus.ThresholdSignature {
+	s, err := thresholdSignatureFromProto(sig)
+	if err != nil {
+		return nil
+	}
+	return s
+}
+
+// thresholdSignatureFromProto converts a protocol buffers message to a threshold signature,
+// and returns an error if the threshold signature is malformed or has more than MaxParticipants participants.
+func thresholdSignatureFromProto(sig *ThresholdSignature) (consensus.ThresholdSignature, error) {
 	if signature := sig.GetECDSASigs(); signature != nil {
+		if len(signature.GetSigs()) > MaxParticipants {
+			return nil, fmt.Errorf("threshold signature has more than %d signatures", MaxParticipants)
+		}
 		sigs := make([]*ecdsa.Signature, len(signature.GetSigs()))
+		signers := make(map[uint32]struct{}, len(sigs))
 		for i, sig := range signature.GetSigs() {
-			r := new(big.Int)
-			r.SetBytes(sig.GetR())
-			s := new(big.Int)
-			s.SetBytes(sig.GetS())
-			sigs[i] = ecdsa.RestoreSignature(r, s, hotstuff.ID(sig.GetSigner()))
+			if _, ok := signers[sig.GetSigner()]; ok {
+				return nil, fmt.Errorf("threshold signature has more than one signature from replica %d", sig.GetSigner())
+			}
+			signers[sig.GetSigner()] = struct{}{}
+			s, err := ecdsaSignatureFromProto(sig)
+			if err != nil {
+				return nil, err
+			}
+			sigs[i] = s
 		}
-		return ecdsa.RestoreThresholdSignature(sigs)
+		return ecdsa.RestoreThresholdSignature(sigs), nil
 	}
 	if signature := sig.GetBLS12Sig(); signature != nil {
+		if len(signature.GetParticipants()) > MaxParticipants/8 {
+			return nil, fmt.Errorf("threshold signature has more than %d participants", MaxParticipants)
+		}
 		aggSig, err := bls12.RestoreAggregateSignature(signature.GetSig(), signature.GetParticipants())
 		if err != nil {
-			return nil
+			return nil, err
 		}
-		return aggSig
+		return aggSig, nil
 	}
-	return nil
+	return nil, nil
+}
+
+// hashFromProto converts a hash from the protobuf type to the hotstuff type.
+// An empty hash is the zero hash, and any other hash must be exactly as long as a consensus.Hash.
+func hashFromProto(b []byte) (h consensus.Hash, err error) {
+	if len(b) != 0 && len(b) != len(h) {
+		return h, fmt.Errorf("invalid hash length %d", len(b))
+	}
+	copy(h[:], b)
+	return h, nil
 }
 
 // PartialCertToProto converts a consensus.PartialCert to a hotstuffpb.Partialcert.
 func PartialCertToProto(cert consensus.PartialCert) *PartialCert {
 	hash := cert.BlockHash()
 	return &PartialCert{
-		Sig:  SignatureToProto(cert.Signature()),
-		Hash: hash[:],
+		Sig:     SignatureToProto(cert.Signature()),
+		Hash:    hash[:],
+		Version: WireVersion,
 	}
 }
 
 // PartialCertFromProto converts a hotstuffpb.PartialCert to an ecdsa.PartialCert.
-func PartialCertFromProto(cert *PartialCert) consensus.PartialCert {
-	var h consensus.Hash
-	copy(h[:], cert.GetHash())
-	return consensus.NewPartialCert(SignatureFromProto(cert.GetSig()), h)
+// An UnsupportedVersionError is returned if the certificate uses an unknown wire format version,
+// and an error is returned if the hash or the signature of the certificate is malformed.
+func PartialCertFromProto(cert *PartialCert) (consensus.PartialCert, error) {
+	s, err := shimFor(cert.GetVersion())
+	if err != nil {
+		return consensus.PartialCert{}, err
+	}
+	if s.partialCert != nil {
+		cert = s.partialCert(cert)
+	}
+	h, err := hashFromProto(cert.GetHash())
+	if err != nil {
+		return consensus.PartialCert{}, err
+	}
+	sig, err := signatureFromProto(cert.GetSig())
+	if err != nil {
+		return consensus.PartialCert{}, err
+	}
+	return consensus.NewPartialCert(sig, h), nil
 }
 
 // QuorumCertToProto converts a consensus.QuorumCert to a hotstuffpb.QuorumCert.
 func QuorumCertToProto(qc consensus.QuorumCert) *QuorumCert {
 	hash := qc.BlockHash()
 	return &QuorumCert{
-		Sig:  ThresholdSignatureToProto(qc.Signature()),
-		Hash: hash[:],
-		View: uint64(qc.View()),
+		Sig:     ThresholdSignatureToProto(qc.Signature()),
+		Hash:    hash[:],
+		View:    uint64(qc.View()),
+		Version: WireVersion,
 	}
 }
 
 // QuorumCertFromProto converts a hotstuffpb.QuorumCert to an ecdsa.QuorumCert.
-func QuorumCertFromProto(qc *QuorumCert) consensus.QuorumCert {
-	var h consensus.Hash
-	copy(h[:], qc.GetHash())
-	return consensus.NewQuorumCert(ThresholdSignatureFromProto(qc.GetSig()), consensus.View(qc.GetView()), h)
+// An UnsupportedVersionError is returned if the certificate uses an unknown wire format version,
+// and an error is returned if the hash or the signature of the certificate is malformed.
+func QuorumCertFromProto(qc *QuorumCert) (consensus.QuorumCert, error) {
+	s, err := shimFor(qc.GetVersion())
+	if err != nil {
+		return consensus.QuorumCert{}, err
+	}
+	if s.quorumCert != nil {
+		qc = s.quorumCert(qc)
+	}
+	h, err := hashFromProto(qc.GetHash())
+	if err != nil {
+		return consensus.QuorumCert{}, err
+	}
+	sig, err := thresholdSignatureFromProto(qc.GetSig())
+	if err != nil {
+		return consensus.QuorumCert{}, err
+	}
+	return consensus.NewQuorumCert(sig, consensus.View(qc.GetView()), h), nil
 }
 
 // ProposalToProto converts a ProposeMsg to a protobuf message.
@@ -140,13 +236,19 @@ func ProposalToProto(proposal consensus.ProposeMsg) *Proposal {
 }
 
 // ProposalFromProto converts a protobuf message to a ProposeMsg.
-func ProposalFromProto(p *Proposal) (proposal consensus.ProposeMsg) {
-	proposal.Block = BlockFromProto(p.GetBlock())
+func ProposalFromProto(p *Proposal) (proposal consensus.ProposeMsg, err error) {
+	proposal.Block, err = BlockFromProto(p.GetBlock())
+	if err != nil {
+		return consensus.ProposeMsg{}, err
+	}
 	if p.GetAggQC() != nil {
-		aggQC := AggregateQCFromProto(p.GetAggQC())
+		aggQC, err := AggregateQCFromProto(p.GetAggQC())
+		if err != nil {
+			return consensus.ProposeMsg{}, err
+		}
 		proposal.AggregateQC = &aggQC
 	}
-	return
+	return proposal, nil
 }
 
 // BlockToProto converts a consensus.Block to a hotstuffpb.Block.
@@ -162,29 +264,53 @@ func BlockToProto(block *consensus.Block) *Block {
 }
 
 // BlockFromProto converts a hotstuffpb.Block to a consensus.Block.
-func BlockFromProto(block *Block) *consensus.Block {
-	var p consensus.Hash
-	copy(p[:], block.GetParent())
+// If the command of the block is compressed, it is decompressed.
+// An error is returned if the command cannot be decompressed, or if the parent hash is malformed.
+// An UnsupportedVersionError is returned if the QC of the block uses an unknown wire format version.
+func BlockFromProto(block *Block) (*consensus.Block, error) {
+	p, err := hashFromProto(block.GetParent())
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := blockCommand(block)
+	if err != nil {
+		return nil, err
+	}
+	qc, err := QuorumCertFromProto(block.GetQC())
+	if err != nil {
+		return nil, err
+	}
 	return consensus.NewBlock(
 		p,
-		QuorumCertFromProto(block.GetQC()),
-		consensus.Command(block.GetCommand()),
+		qc,
+		consensus.Command(cmd),
 		consensus.View(block.GetView()),
 		hotstuff.ID(block.GetProposer()),
-	)
+	), nil
 }
 
 // TimeoutMsgFromProto converts a TimeoutMsg proto to the hotstuff type.
-func TimeoutMsgFromProto(m *TimeoutMsg) consensus.TimeoutMsg {
+func TimeoutMsgFromProto(m *TimeoutMsg) (consensus.TimeoutMsg, error) {
+	syncInfo, err := SyncInfoFromProto(m.GetSyncInfo())
+	if err != nil {
+		return consensus.TimeoutMsg{}, err
+	}
+	viewSig, err := signatureFromProto(m.GetViewSig())
+	if err != nil {
+		return consensus.TimeoutMsg{}, err
+	}
 	timeoutMsg := consensus.TimeoutMsg{
 		View:          consensus.View(m.GetView()),
-		SyncInfo:      SyncInfoFromProto(m.GetSyncInfo()),
-		ViewSignature: SignatureFromProto(m.GetViewSig()),
+		SyncInfo:      syncInfo,
+		ViewSignature: viewSig,
 	}
-	if m.GetViewSig() != nil {
-		timeoutMsg.MsgSignature = SignatureFromProto(m.GetMsgSig())
+	if m.GetMsgSig() != nil {
+		timeoutMsg.MsgSignature, err = signatureFromProto(m.GetMsgSig())
+		if err != nil {
+			return consensus.TimeoutMsg{}, err
+		}
 	}
-	return timeoutMsg
+	return timeoutMsg, nil
 }
 
 // TimeoutMsgToProto converts a TimeoutMsg to the protobuf type.
@@ -201,25 +327,59 @@ func TimeoutMsgToProto(timeoutMsg consensus.TimeoutMsg) *TimeoutMsg {
 }
 
 // TimeoutCertFromProto converts a timeout certificate from the protobuf type to the hotstuff type.
-func TimeoutCertFromProto(m *TimeoutCert) consensus.TimeoutCert {
-	return consensus.NewTimeoutCert(ThresholdSignatureFromProto(m.GetSig()), consensus.View(m.GetView()))
+// An UnsupportedVersionError is returned if the certificate uses an unknown wire format version,
+// and an error is returned if the signature of the certificate is malformed.
+func TimeoutCertFromProto(m *TimeoutCert) (consensus.TimeoutCert, error) {
+	s, err := shimFor(m.GetVersion())
+	if err != nil {
+		return consensus.TimeoutCert{}, err
+	}
+	if s.timeoutCert != nil {
+		m = s.timeoutCert(m)
+	}
+	sig, err := thresholdSignatureFromProto(m.GetSig())
+	if err != nil {
+		return consensus.TimeoutCert{}, err
+	}
+	return consensus.NewTimeoutCert(sig, consensus.View(m.GetView())), nil
 }
 
 // TimeoutCertToProto converts a timeout certificate from the hotstuff type to the protobuf type.
 func TimeoutCertToProto(timeoutCert consensus.TimeoutCert) *TimeoutCert {
 	return &TimeoutCert{
-		View: uint64(timeoutCert.View()),
-		Sig:  ThresholdSignatureToProto(timeoutCert.Signature()),
+		View:    uint64(timeoutCert.View()),
+		Sig:     ThresholdSignatureToProto(timeoutCert.Signature()),
+		Version: WireVersion,
 	}
 }
 
 // AggregateQCFromProto converts an AggregateQC from the protobuf type to the hotstuff type.
-func AggregateQCFromProto(m *AggQC) consensus.AggregateQC {
-	qcs := make(map[hotstuff.ID]consensus.QuorumCert)
+// An UnsupportedVersionError is returned if the AggregateQC, or any of its QCs, uses an unknown wire format version.
+// An error is returned if it has more than MaxParticipants QCs, or if any of its signatures is malformed.
+func AggregateQCFromProto(m *AggQC) (consensus.AggregateQC, error) {
+	s, err := shimFor(m.GetVersion())
+	if err != nil {
+		return consensus.AggregateQC{}, err
+	}
+	if s.aggQC != nil {
+		m = s.aggQC(m)
+	}
+	if len(m.GetQCs()) > MaxParticipants {
+		return consensus.AggregateQC{}, fmt.Errorf("aggregateQC has more than %d QCs", MaxParticipants)
+	}
+	qcs := make(map[hotstuff.ID]consensus.QuorumCert, len(m.GetQCs()))
 	for id, pQC := range m.GetQCs() {
-		qcs[hotstuff.ID(id)] = QuorumCertFromProto(pQC)
+		qc, err := QuorumCertFromProto(pQC)
+		if err != nil {
+			return consensus.AggregateQC{}, err
+		}
+		qcs[hotstuff.ID(id)] = qc
+	}
+	sig, err := thresholdSignatureFromProto(m.GetSig())
+	if err != nil {
+		return consensus.AggregateQC{}, err
 	}
-	return consensus.NewAggregateQC(qcs, ThresholdSignatureFromProto(m.GetSig()), consensus.View(m.GetView()))
+	return consensus.NewAggregateQC(qcs, sig, consensus.View(m.GetView())), nil
 }
 
 // AggregateQCToProto converts an AggregateQC from the hotstuff type to the protobuf type.
@@ -228,22 +388,34 @@ func AggregateQCToProto(aggQC consensus.AggregateQC) *AggQC {
 	for id, qc := range aggQC.QCs() {
 		pQCs[uint32(id)] = QuorumCertToProto(qc)
 	}
-	return &AggQC{QCs: pQCs, Sig: ThresholdSignatureToProto(aggQC.Sig()), View: uint64(aggQC.View())}
+	return &AggQC{QCs: pQCs, Sig: ThresholdSignatureToProto(aggQC.Sig()), View: uint64(aggQC.View()), Version: WireVersion}
 }
 
 // SyncInfoFromProto converts a SyncInfo struct from the protobuf type to the hotstuff type.
-func SyncInfoFromProto(m *SyncInfo) consensus.SyncInfo {
+func SyncInfoFromProto(m *SyncInfo) (consensus.SyncInfo, error) {
 	si := consensus.NewSyncInfo()
-	if qc := m.GetQC(); qc != nil {
-		si = si.WithQC(QuorumCertFromProto(qc))
+	if pQC := m.GetQC(); pQC != nil {
+		qc, err := QuorumCertFromProto(pQC)
+		if err != nil {
+			return consensus.SyncInfo{}, err
+		}
+		si = si.WithQC(qc)
 	}
-	if tc := m.GetTC(); tc != nil {
-		si = si.WithTC(TimeoutCertFromProto(tc))
+	if pTC := m.GetTC(); pTC != nil {
+		tc, err := TimeoutCertFromProto(pTC)
+		if err != nil {
+			return consensus.SyncInfo{}, err
+		}
+		si = si.WithTC(tc)
 	}
-	if aggQC := m.GetAggQC(); aggQC != nil {
-		si = si.WithAggQC(AggregateQCFromProto(aggQC))
+	if pAggQC := m.GetAggQC(); pAggQC != nil {
+		aggQC, err := AggregateQCFromProto(pAggQC)
+		if err != nil {
+			return consensus.SyncInfo{}, err
+		}
+		si = si.WithAggQC(aggQC)
 	}
-	return si
+	return si, nil
 }
 
 // SyncInfoToProto converts a SyncInfo struct from the hotstuff type to the protobuf type.
@@ -2,13 +2,17 @@ package hotstuffpb
 
 import (
 	"bytes"
+	"errors"
+	"os"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/consensus"
 	"github.com/relab/hotstuff/crypto"
 	"github.com/relab/hotstuff/crypto/bls12"
 	"github.com/relab/hotstuff/internal/testutil"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestConvertPartialCert(t *testing.T) {
@@ -24,7 +28,10 @@ func TestConvertPartialCert(t *testing.T) {
 	}
 
 	pb := PartialCertToProto(want)
-	got := PartialCertFromProto(pb)
+	got, err := PartialCertFromProto(pb)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if !bytes.Equal(want.ToBytes(), got.ToBytes()) {
 		t.Error("Certificates don't match.")
@@ -47,7 +54,10 @@ func TestConvertQuorumCert(t *testing.T) {
 	}
 
 	pb := QuorumCertToProto(want)
-	got := QuorumCertFromProto(pb)
+	got, err := QuorumCertFromProto(pb)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if !bytes.Equal(want.ToBytes(), got.ToBytes()) {
 		t.Error("Certificates don't match.")
@@ -58,7 +68,10 @@ func TestConvertBlock(t *testing.T) {
 	qc := consensus.NewQuorumCert(nil, 0, consensus.Hash{})
 	want := consensus.NewBlock(consensus.GetGenesis().Hash(), qc, "", 1, 1)
 	pb := BlockToProto(want)
-	got := BlockFromProto(pb)
+	got, err := BlockFromProto(pb)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if want.Hash() != got.Hash() {
 		t.Error("Hashes don't match.")
@@ -77,9 +90,188 @@ func TestConvertTimeoutCertBLS12(t *testing.T) {
 	tc1 := testutil.CreateTC(t, 1, hl.Signers())
 
 	pb := TimeoutCertToProto(tc1)
-	tc2 := TimeoutCertFromProto(pb)
+	tc2, err := TimeoutCertFromProto(pb)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if !hl[0].Crypto().VerifyTimeoutCert(tc2) {
 		t.Fatal("Failed to verify timeout cert")
 	}
 }
+
+func TestConvertCompressedProposal(t *testing.T) {
+	qc := consensus.NewQuorumCert(nil, 0, consensus.Hash{})
+	cmd := consensus.Command(bytes.Repeat([]byte("hotstuff"), 1<<16))
+	want := consensus.NewBlock(consensus.GetGenesis().Hash(), qc, cmd, 1, 1)
+
+	uncompressed := ProposalToProto(consensus.ProposeMsg{Block: want})
+	pb := ProposalToProto(consensus.ProposeMsg{Block: want})
+	raw, compressed := CompressBlock(pb.GetBlock())
+
+	if raw != len(cmd) {
+		t.Errorf("raw size: got %d, want %d", raw, len(cmd))
+	}
+	if compressed >= raw {
+		t.Errorf("compressed size %d is not smaller than raw size %d", compressed, raw)
+	}
+	if proto.Size(pb) >= proto.Size(uncompressed) {
+		t.Errorf("compressed proposal (%d bytes) is not smaller than uncompressed proposal (%d bytes)",
+			proto.Size(pb), proto.Size(uncompressed))
+	}
+
+	// the receiver must understand both compressed and uncompressed proposals
+	for _, p := range []*Proposal{pb, uncompressed} {
+		got, err := ProposalFromProto(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want.Hash() != got.Block.Hash() {
+			t.Error("Hashes don't match.")
+		}
+	}
+}
+
+func TestConvertCorruptCompressedProposal(t *testing.T) {
+	qc := consensus.NewQuorumCert(nil, 0, consensus.Hash{})
+	cmd := consensus.Command(bytes.Repeat([]byte("hotstuff"), 1<<10))
+	pb := ProposalToProto(consensus.ProposeMsg{Block: consensus.NewBlock(consensus.GetGenesis().Hash(), qc, cmd, 1, 1)})
+	CompressBlock(pb.GetBlock())
+	pb.Block.CompressedCommand = pb.Block.CompressedCommand[:len(pb.Block.CompressedCommand)/2]
+
+	if _, err := ProposalFromProto(pb); err == nil {
+		t.Error("expected an error for a truncated compressed command")
+	}
+}
+
+// proposal_v0.bin contains a proposal that was encoded before the version field was added to certificates.
+// The QC of the proposed block is signed by four ECDSA replicas.
+func TestDecodeVersion0Proposal(t *testing.T) {
+	b, err := os.ReadFile("testdata/proposal_v0.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pb Proposal
+	if err := proto.Unmarshal(b, &pb); err != nil {
+		t.Fatal(err)
+	}
+	if v := pb.GetBlock().GetQC().GetVersion(); v != 0 {
+		t.Fatalf("fixture has version %d, want 0", v)
+	}
+
+	proposal, err := ProposalFromProto(&pb)
+	if err != nil {
+		t.Fatalf("failed to decode proposal: %v", err)
+	}
+	block := proposal.Block
+	if block.Command() != "fixture" || block.View() != 2 || block.Proposer() != 2 {
+		t.Errorf("unexpected block: %v", block)
+	}
+	if got, want := block.Hash().String(), "s6Rjlj2lYQAJOFXMuSWdV4dX9AVn71fGhyuHi/q7KiI="; got != want {
+		t.Errorf("block hash: got %s, want %s", got, want)
+	}
+	if got, want := block.QuorumCert().BlockHash().String(), "WqN3jQsH1w6Huv37UDgeRD5wPPz3DOFtoxiw+a43Ymo="; got != want {
+		t.Errorf("QC block hash: got %s, want %s", got, want)
+	}
+	n := 0
+	block.QuorumCert().Signature().Participants().ForEach(func(hotstuff.ID) { n++ })
+	if n != 4 {
+		t.Errorf("QC has %d participants, want 4", n)
+	}
+}
+
+func TestRejectUnknownVersion(t *testing.T) {
+	qc := consensus.NewQuorumCert(nil, 1, consensus.GetGenesis().Hash())
+	b, err := proto.Marshal(QuorumCertToProto(qc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the version is the last field of the encoded certificate, and fits in a single byte.
+	if b[len(b)-1] != WireVersion {
+		t.Fatalf("expected encoded certificate to end with the version byte, got %x", b)
+	}
+	b[len(b)-1]++
+
+	var pb QuorumCert
+	if err := proto.Unmarshal(b, &pb); err != nil {
+		t.Fatal(err)
+	}
+	_, err = QuorumCertFromProto(&pb)
+	var versionErr UnsupportedVersionError
+	if !errors.As(err, &versionErr) {
+		t.Fatalf("expected UnsupportedVersionError, got %v", err)
+	}
+	if versionErr.Version != WireVersion+1 {
+		t.Errorf("error has version %d, want %d", versionErr.Version, WireVersion+1)
+	}
+
+	// the error must also be returned when the certificate is embedded in other messages.
+	block := BlockToProto(consensus.NewBlock(consensus.GetGenesis().Hash(), qc, "", 2, 1))
+	block.QC = &pb
+	if _, err := ProposalFromProto(&Proposal{Block: block}); !errors.As(err, &versionErr) {
+		t.Errorf("expected UnsupportedVersionError for proposal, got %v", err)
+	}
+	if _, err := SyncInfoFromProto(&SyncInfo{QC: &pb}); !errors.As(err, &versionErr) {
+		t.Errorf("expected UnsupportedVersionError for sync info, got %v", err)
+	}
+}
+
+func TestRejectMalformedCertificates(t *testing.T) {
+	hash := consensus.GetGenesis().Hash()
+	ecdsaSig := func(signer uint32) *ECDSASignature {
+		return &ECDSASignature{Signer: signer, R: []byte{1}, S: []byte{2}}
+	}
+	ecdsaQC := func(sigs ...*ECDSASignature) *QuorumCert {
+		return &QuorumCert{
+			Sig:     &ThresholdSignature{AggSig: &ThresholdSignature_ECDSASigs{ECDSASigs: &ECDSAThresholdSignature{Sigs: sigs}}},
+			Hash:    hash[:],
+			Version: WireVersion,
+		}
+	}
+	tooMany := make([]*ECDSASignature, MaxParticipants+1)
+	for i := range tooMany {
+		tooMany[i] = ecdsaSig(uint32(i + 1))
+	}
+
+	qcs := map[string]*QuorumCert{
+		"ShortHash":       {Hash: hash[:8], Version: WireVersion},
+		"LargeComponent":  ecdsaQC(&ECDSASignature{Signer: 1, R: make([]byte, 1<<20), S: []byte{1}}),
+		"DuplicateSigner": ecdsaQC(ecdsaSig(1), ecdsaSig(2), ecdsaSig(1)),
+		"TooManySigners":  ecdsaQC(tooMany...),
+		"TooManyBLS12Bits": {
+			Sig: &ThresholdSignature{AggSig: &ThresholdSignature_BLS12Sig{BLS12Sig: &BLS12AggregateSignature{
+				Participants: make([]byte, MaxParticipants/8+1),
+			}}},
+			Hash:    hash[:],
+			Version: WireVersion,
+		},
+	}
+	for name, qc := range qcs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := QuorumCertFromProto(qc); err == nil {
+				t.Error("expected an error for a malformed QC")
+			}
+		})
+	}
+
+	t.Run("ShortBLS12Signature", func(t *testing.T) {
+		cert := &PartialCert{
+			Sig:     &Signature{Sig: &Signature_BLS12Sig{BLS12Sig: &BLS12Signature{Sig: []byte{1}}}},
+			Hash:    hash[:],
+			Version: WireVersion,
+		}
+		if _, err := PartialCertFromProto(cert); err == nil {
+			t.Error("expected an error for a malformed partial certificate")
+		}
+	})
+
+	t.Run("TooManyQCs", func(t *testing.T) {
+		aggQC := &AggQC{QCs: make(map[uint32]*QuorumCert), Version: WireVersion}
+		for i := uint32(1); i <= MaxParticipants+1; i++ {
+			aggQC.QCs[i] = &QuorumCert{Hash: hash[:], Version: WireVersion}
+		}
+		if _, err := AggregateQCFromProto(aggQC); err == nil {
+			t.Error("expected an error for an AggregateQC with too many QCs")
+		}
+	})
+}
@@ -0,0 +1,163 @@
+//go:build go1.18
+// +build go1.18
+
+package hotstuffpb
+
+// The fuzz targets decode arbitrary bytes as the messages that a peer can send, and check that the converters do not
+// panic, that they do not allocate memory out of proportion to the size of the message, and that the messages they
+// accept survive a round trip through the converters unchanged. The seed corpora in testdata/fuzz are recorded from
+// simulated protocol runs by internal/fuzzcorpus, and the fuzzer mutates them into corrupted messages.
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// allocLimit returns the number of bytes that converting a message of the given size may allocate.
+func allocLimit(size int) uint64 {
+	return 1<<20 + 256*uint64(size)
+}
+
+// blockAllocLimit returns the number of bytes that converting a message with a block of the given size may allocate.
+// The command of the block may be decompressed, and is copied a few times while it is converted.
+func blockAllocLimit(size int) uint64 {
+	return allocLimit(size) + 4*MaxDecompressedSize
+}
+
+// allocated returns the number of bytes that f allocates.
+func allocated(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+// fuzzRoundTrip unmarshals b into msg, and converts it with roundTrip, which converts a message to the hotstuff type
+// and back. The messages that the converters reject are skipped, and the messages that they accept must be converted
+// to the same message again.
+func fuzzRoundTrip(t *testing.T, b []byte, msg proto.Message, limit uint64, roundTrip func(proto.Message) (proto.Message, error)) {
+	if err := proto.Unmarshal(b, msg); err != nil {
+		return
+	}
+	var (
+		first proto.Message
+		err   error
+	)
+	if n := allocated(func() { first, err = roundTrip(msg) }); n > limit {
+		t.Fatalf("converting a message of %d bytes allocated %d bytes, want at most %d", len(b), n, limit)
+	}
+	if err != nil {
+		return
+	}
+	second, err := roundTrip(first)
+	if err != nil {
+		t.Fatalf("failed to convert a message that was created by the converters: %v", err)
+	}
+	if !proto.Equal(first, second) {
+		t.Fatalf("the message changed in a round trip:\n%v\n%v", first, second)
+	}
+}
+
+func FuzzPartialCert(f *testing.F) {
+	f.Fuzz(func(t *testing.T, b []byte) {
+		fuzzRoundTrip(t, b, &PartialCert{}, allocLimit(len(b)), func(m proto.Message) (proto.Message, error) {
+			cert, err := PartialCertFromProto(m.(*PartialCert))
+			if err != nil {
+				return nil, err
+			}
+			return PartialCertToProto(cert), nil
+		})
+	})
+}
+
+func FuzzQuorumCert(f *testing.F) {
+	f.Fuzz(func(t *testing.T, b []byte) {
+		fuzzRoundTrip(t, b, &QuorumCert{}, allocLimit(len(b)), func(m proto.Message) (proto.Message, error) {
+			qc, err := QuorumCertFromProto(m.(*QuorumCert))
+			if err != nil {
+				return nil, err
+			}
+			return QuorumCertToProto(qc), nil
+		})
+	})
+}
+
+func FuzzTimeoutCert(f *testing.F) {
+	f.Fuzz(func(t *testing.T, b []byte) {
+		fuzzRoundTrip(t, b, &TimeoutCert{}, allocLimit(len(b)), func(m proto.Message) (proto.Message, error) {
+			tc, err := TimeoutCertFromProto(m.(*TimeoutCert))
+			if err != nil {
+				return nil, err
+			}
+			return TimeoutCertToProto(tc), nil
+		})
+	})
+}
+
+func FuzzAggregateQC(f *testing.F) {
+	f.Fuzz(func(t *testing.T, b []byte) {
+		fuzzRoundTrip(t, b, &AggQC{}, allocLimit(len(b)), func(m proto.Message) (proto.Message, error) {
+			aggQC, err := AggregateQCFromProto(m.(*AggQC))
+			if err != nil {
+				return nil, err
+			}
+			return AggregateQCToProto(aggQC), nil
+		})
+	})
+}
+
+func FuzzSyncInfo(f *testing.F) {
+	f.Fuzz(func(t *testing.T, b []byte) {
+		fuzzRoundTrip(t, b, &SyncInfo{}, allocLimit(len(b)), func(m proto.Message) (proto.Message, error) {
+			si, err := SyncInfoFromProto(m.(*SyncInfo))
+			if err != nil {
+				return nil, err
+			}
+			return SyncInfoToProto(si), nil
+		})
+	})
+}
+
+func FuzzTimeoutMsg(f *testing.F) {
+	f.Fuzz(func(t *testing.T, b []byte) {
+		fuzzRoundTrip(t, b, &TimeoutMsg{}, allocLimit(len(b)), func(m proto.Message) (proto.Message, error) {
+			timeoutMsg, err := TimeoutMsgFromProto(m.(*TimeoutMsg))
+			if err != nil {
+				return nil, err
+			}
+			return TimeoutMsgToProto(timeoutMsg), nil
+		})
+	})
+}
+
+func FuzzBlock(f *testing.F) {
+	f.Fuzz(func(t *testing.T, b []byte) {
+		fuzzRoundTrip(t, b, &Block{}, blockAllocLimit(len(b)), func(m proto.Message) (proto.Message, error) {
+			block, err := BlockFromProto(m.(*Block))
+			if err != nil {
+				return nil, err
+			}
+			return BlockToProto(block), nil
+		})
+	})
+}
+
+func FuzzProposal(f *testing.F) {
+	// a proposal that was encoded before the version field was added to certificates.
+	if b, err := os.ReadFile("testdata/proposal_v0.bin"); err == nil {
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		fuzzRoundTrip(t, b, &Proposal{}, blockAllocLimit(len(b)), func(m proto.Message) (proto.Message, error) {
+			proposal, err := ProposalFromProto(m.(*Proposal))
+			if err != nil {
+				return nil, err
+			}
+			return ProposalToProto(proposal), nil
+		})
+	})
+}
@@ -29,6 +29,8 @@ type Proposal struct {
 
 	Block *Block `protobuf:"bytes,1,opt,name=Block,proto3" json:"Block,omitempty"`
 	AggQC *AggQC `protobuf:"bytes,2,opt,name=AggQC,proto3,oneof" json:"AggQC,omitempty"`
+	// The consensus instance that the message is addressed to, if several instances share the connections.
+	Instance uint32 `protobuf:"varint,3,opt,name=Instance,proto3" json:"Instance,omitempty"`
 }
 
 func (x *Proposal) Reset() {
@@ -77,31 +79,646 @@ func (x *Proposal) GetAggQC() *AggQC {
 	return nil
 }
 
+func (x *Proposal) GetInstance() uint32 {
+	if x != nil {
+		return x.Instance
+	}
+	return 0
+}
+
+// ProposalChunk is a part of a serialized Proposal that is too large to be sent in a single message.
+type ProposalChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The view of the proposed block.
+	View uint64 `protobuf:"varint,1,opt,name=View,proto3" json:"View,omitempty"`
+	// The hash of the proposed block.
+	Hash []byte `protobuf:"bytes,2,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	// The position of this chunk in the serialized proposal.
+	Index uint32 `protobuf:"varint,3,opt,name=Index,proto3" json:"Index,omitempty"`
+	// The number of chunks that the proposal was split into.
+	Total uint32 `protobuf:"varint,4,opt,name=Total,proto3" json:"Total,omitempty"`
+	Data  []byte `protobuf:"bytes,5,opt,name=Data,proto3" json:"Data,omitempty"`
+	// The consensus instance that the message is addressed to, if several instances share the connections.
+	Instance uint32 `protobuf:"varint,6,opt,name=Instance,proto3" json:"Instance,omitempty"`
+}
+
+func (x *ProposalChunk) Reset() {
+	*x = ProposalChunk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProposalChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProposalChunk) ProtoMessage() {}
+
+func (x *ProposalChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProposalChunk.ProtoReflect.Descriptor instead.
+func (*ProposalChunk) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProposalChunk) GetView() uint64 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *ProposalChunk) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *ProposalChunk) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ProposalChunk) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ProposalChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ProposalChunk) GetInstance() uint32 {
+	if x != nil {
+		return x.Instance
+	}
+	return 0
+}
+
 type BlockHash struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Hash []byte `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
-}
-
-func (x *BlockHash) Reset() {
-	*x = BlockHash{}
+	Hash []byte `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	// The consensus instance that the message is addressed to, if several instances share the connections.
+	Instance uint32 `protobuf:"varint,2,opt,name=Instance,proto3" json:"Instance,omitempty"`
+}
+
+func (x *BlockHash) Reset() {
+	*x = BlockHash{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlockHash) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockHash) ProtoMessage() {}
+
+func (x *BlockHash) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockHash.ProtoReflect.Descriptor instead.
+func (*BlockHash) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BlockHash) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *BlockHash) GetInstance() uint32 {
+	if x != nil {
+		return x.Instance
+	}
+	return 0
+}
+
+// ClientCommand is a serialized client command that is forwarded to the leader.
+type ClientCommand struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Command []byte `protobuf:"bytes,1,opt,name=Command,proto3" json:"Command,omitempty"`
+	// The consensus instance that the message is addressed to, if several instances share the connections.
+	Instance uint32 `protobuf:"varint,2,opt,name=Instance,proto3" json:"Instance,omitempty"`
+}
+
+func (x *ClientCommand) Reset() {
+	*x = ClientCommand{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientCommand) ProtoMessage() {}
+
+func (x *ClientCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientCommand.ProtoReflect.Descriptor instead.
+func (*ClientCommand) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ClientCommand) GetCommand() []byte {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *ClientCommand) GetInstance() uint32 {
+	if x != nil {
+		return x.Instance
+	}
+	return 0
+}
+
+// ProposalRequest asks the leader of a view to resend its proposal.
+type ProposalRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	View uint64 `protobuf:"varint,1,opt,name=View,proto3" json:"View,omitempty"`
+	// The consensus instance that the message is addressed to, if several instances share the connections.
+	Instance uint32 `protobuf:"varint,2,opt,name=Instance,proto3" json:"Instance,omitempty"`
+}
+
+func (x *ProposalRequest) Reset() {
+	*x = ProposalRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProposalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProposalRequest) ProtoMessage() {}
+
+func (x *ProposalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProposalRequest.ProtoReflect.Descriptor instead.
+func (*ProposalRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ProposalRequest) GetView() uint64 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *ProposalRequest) GetInstance() uint32 {
+	if x != nil {
+		return x.Instance
+	}
+	return 0
+}
+
+// BlockID identifies a block.
+type BlockID struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	View uint64 `protobuf:"varint,1,opt,name=View,proto3" json:"View,omitempty"`
+	Hash []byte `protobuf:"bytes,2,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	// QC is a quorum certificate for the block, which the receiver verifies before it fetches the block.
+	QC *QuorumCert `protobuf:"bytes,3,opt,name=QC,proto3" json:"QC,omitempty"`
+}
+
+func (x *BlockID) Reset() {
+	*x = BlockID{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlockID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockID) ProtoMessage() {}
+
+func (x *BlockID) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockID.ProtoReflect.Descriptor instead.
+func (*BlockID) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BlockID) GetView() uint64 {
+	if x != nil {
+		return x.View
+	}
+	return 0
+}
+
+func (x *BlockID) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *BlockID) GetQC() *QuorumCert {
+	if x != nil {
+		return x.QC
+	}
+	return nil
+}
+
+// BlockOffer is sent by the gossip layer to inform a replica about recent blocks.
+type BlockOffer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Blocks []*BlockID `protobuf:"bytes,1,rep,name=Blocks,proto3" json:"Blocks,omitempty"`
+	// The consensus instance that the message is addressed to, if several instances share the connections.
+	Instance uint32 `protobuf:"varint,2,opt,name=Instance,proto3" json:"Instance,omitempty"`
+}
+
+func (x *BlockOffer) Reset() {
+	*x = BlockOffer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlockOffer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockOffer) ProtoMessage() {}
+
+func (x *BlockOffer) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockOffer.ProtoReflect.Descriptor instead.
+func (*BlockOffer) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BlockOffer) GetBlocks() []*BlockID {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (x *BlockOffer) GetInstance() uint32 {
+	if x != nil {
+		return x.Instance
+	}
+	return 0
+}
+
+// SnapshotRequest asks a replica for a part of its snapshot of the state after the given block.
+// If Block is empty, the replica describes its latest snapshot instead.
+type SnapshotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The hash of the block after which the snapshot was taken.
+	Block []byte `protobuf:"bytes,1,opt,name=Block,proto3" json:"Block,omitempty"`
+	// The position of the first requested byte of the snapshot.
+	Offset uint64 `protobuf:"varint,2,opt,name=Offset,proto3" json:"Offset,omitempty"`
+	// The maximum number of bytes to return.
+	Length uint32 `protobuf:"varint,3,opt,name=Length,proto3" json:"Length,omitempty"`
+	// The consensus instance that the message is addressed to, if several instances share the connections.
+	Instance uint32 `protobuf:"varint,4,opt,name=Instance,proto3" json:"Instance,omitempty"`
+}
+
+func (x *SnapshotRequest) Reset() {
+	*x = SnapshotRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotRequest) ProtoMessage() {}
+
+func (x *SnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotRequest.ProtoReflect.Descriptor instead.
+func (*SnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SnapshotRequest) GetBlock() []byte {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+func (x *SnapshotRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *SnapshotRequest) GetLength() uint32 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *SnapshotRequest) GetInstance() uint32 {
+	if x != nil {
+		return x.Instance
+	}
+	return 0
+}
+
+// SnapshotChunk is the response to a SnapshotRequest.
+// A description of a snapshot has the Block, QC, Digest, and Size fields set, while a part of a snapshot has only Data.
+type SnapshotChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The block after which the snapshot was taken.
+	Block *Block `protobuf:"bytes,1,opt,name=Block,proto3" json:"Block,omitempty"`
+	// A quorum certificate for the block.
+	QC *QuorumCert `protobuf:"bytes,2,opt,name=QC,proto3" json:"QC,omitempty"`
+	// The SHA-256 hash of the snapshot.
+	Digest []byte `protobuf:"bytes,3,opt,name=Digest,proto3" json:"Digest,omitempty"`
+	// The size of the snapshot in bytes.
+	Size uint64 `protobuf:"varint,4,opt,name=Size,proto3" json:"Size,omitempty"`
+	Data []byte `protobuf:"bytes,5,opt,name=Data,proto3" json:"Data,omitempty"`
+}
+
+func (x *SnapshotChunk) Reset() {
+	*x = SnapshotChunk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SnapshotChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotChunk) ProtoMessage() {}
+
+func (x *SnapshotChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotChunk.ProtoReflect.Descriptor instead.
+func (*SnapshotChunk) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SnapshotChunk) GetBlock() *Block {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+func (x *SnapshotChunk) GetQC() *QuorumCert {
+	if x != nil {
+		return x.QC
+	}
+	return nil
+}
+
+func (x *SnapshotChunk) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *SnapshotChunk) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *SnapshotChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// PingMsg is sent by the health checker to verify that a replica is responsive.
+type PingMsg struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nonce uint64 `protobuf:"varint,1,opt,name=Nonce,proto3" json:"Nonce,omitempty"`
+}
+
+func (x *PingMsg) Reset() {
+	*x = PingMsg{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PingMsg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingMsg) ProtoMessage() {}
+
+func (x *PingMsg) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingMsg.ProtoReflect.Descriptor instead.
+func (*PingMsg) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PingMsg) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+// HandshakeMsg is exchanged when a connection between two replicas is
+// established, in order to verify that the replicas are compatible.
+type HandshakeMsg struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The hash of the genesis block.
+	GenesisHash []byte `protobuf:"bytes,1,opt,name=GenesisHash,proto3" json:"GenesisHash,omitempty"`
+	// The newest protocol version supported by the replica.
+	Version uint32 `protobuf:"varint,2,opt,name=Version,proto3" json:"Version,omitempty"`
+	// The oldest protocol version supported by the replica.
+	MinVersion uint32 `protobuf:"varint,3,opt,name=MinVersion,proto3" json:"MinVersion,omitempty"`
+	// Identifies the crypto implementation used by the replica.
+	CryptoSuite string `protobuf:"bytes,4,opt,name=CryptoSuite,proto3" json:"CryptoSuite,omitempty"`
+	// The consensus instance that the message is addressed to, if several instances share the connections.
+	Instance uint32 `protobuf:"varint,5,opt,name=Instance,proto3" json:"Instance,omitempty"`
+	// The number of chained blocks that the consensus rules of the replica require in order to commit.
+	ChainLength uint32 `protobuf:"varint,6,opt,name=ChainLength,proto3" json:"ChainLength,omitempty"`
+}
+
+func (x *HandshakeMsg) Reset() {
+	*x = HandshakeMsg{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[1]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *BlockHash) String() string {
+func (x *HandshakeMsg) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BlockHash) ProtoMessage() {}
+func (*HandshakeMsg) ProtoMessage() {}
 
-func (x *BlockHash) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[1]
+func (x *HandshakeMsg) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -112,18 +729,53 @@ func (x *BlockHash) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BlockHash.ProtoReflect.Descriptor instead.
-func (*BlockHash) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use HandshakeMsg.ProtoReflect.Descriptor instead.
+func (*HandshakeMsg) Descriptor() ([]byte, []int) {
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *BlockHash) GetHash() []byte {
+func (x *HandshakeMsg) GetGenesisHash() []byte {
 	if x != nil {
-		return x.Hash
+		return x.GenesisHash
 	}
 	return nil
 }
 
+func (x *HandshakeMsg) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *HandshakeMsg) GetMinVersion() uint32 {
+	if x != nil {
+		return x.MinVersion
+	}
+	return 0
+}
+
+func (x *HandshakeMsg) GetCryptoSuite() string {
+	if x != nil {
+		return x.CryptoSuite
+	}
+	return ""
+}
+
+func (x *HandshakeMsg) GetInstance() uint32 {
+	if x != nil {
+		return x.Instance
+	}
+	return 0
+}
+
+func (x *HandshakeMsg) GetChainLength() uint32 {
+	if x != nil {
+		return x.ChainLength
+	}
+	return 0
+}
+
 type Block struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -134,12 +786,14 @@ type Block struct {
 	View     uint64      `protobuf:"varint,3,opt,name=View,proto3" json:"View,omitempty"`
 	Command  []byte      `protobuf:"bytes,4,opt,name=Command,proto3" json:"Command,omitempty"`
 	Proposer uint32      `protobuf:"varint,5,opt,name=Proposer,proto3" json:"Proposer,omitempty"`
+	// The gzip-compressed command. If set, Command is left empty.
+	CompressedCommand []byte `protobuf:"bytes,6,opt,name=CompressedCommand,proto3" json:"CompressedCommand,omitempty"`
 }
 
 func (x *Block) Reset() {
 	*x = Block{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[2]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -152,7 +806,7 @@ func (x *Block) String() string {
 func (*Block) ProtoMessage() {}
 
 func (x *Block) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[2]
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -165,7 +819,7 @@ func (x *Block) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Block.ProtoReflect.Descriptor instead.
 func (*Block) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{2}
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *Block) GetParent() []byte {
@@ -203,6 +857,13 @@ func (x *Block) GetProposer() uint32 {
 	return 0
 }
 
+func (x *Block) GetCompressedCommand() []byte {
+	if x != nil {
+		return x.CompressedCommand
+	}
+	return nil
+}
+
 type ECDSASignature struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -216,7 +877,7 @@ type ECDSASignature struct {
 func (x *ECDSASignature) Reset() {
 	*x = ECDSASignature{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[3]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -229,7 +890,7 @@ func (x *ECDSASignature) String() string {
 func (*ECDSASignature) ProtoMessage() {}
 
 func (x *ECDSASignature) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[3]
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -242,7 +903,7 @@ func (x *ECDSASignature) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ECDSASignature.ProtoReflect.Descriptor instead.
 func (*ECDSASignature) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{3}
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *ECDSASignature) GetSigner() uint32 {
@@ -277,7 +938,7 @@ type BLS12Signature struct {
 func (x *BLS12Signature) Reset() {
 	*x = BLS12Signature{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[4]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -290,7 +951,7 @@ func (x *BLS12Signature) String() string {
 func (*BLS12Signature) ProtoMessage() {}
 
 func (x *BLS12Signature) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[4]
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -303,7 +964,7 @@ func (x *BLS12Signature) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BLS12Signature.ProtoReflect.Descriptor instead.
 func (*BLS12Signature) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{4}
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *BLS12Signature) GetSig() []byte {
@@ -327,7 +988,7 @@ type Signature struct {
 func (x *Signature) Reset() {
 	*x = Signature{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[5]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -340,7 +1001,7 @@ func (x *Signature) String() string {
 func (*Signature) ProtoMessage() {}
 
 func (x *Signature) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[5]
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -353,7 +1014,7 @@ func (x *Signature) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Signature.ProtoReflect.Descriptor instead.
 func (*Signature) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{5}
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{14}
 }
 
 func (m *Signature) GetSig() isSignature_Sig {
@@ -400,12 +1061,16 @@ type PartialCert struct {
 
 	Sig  *Signature `protobuf:"bytes,1,opt,name=Sig,proto3" json:"Sig,omitempty"`
 	Hash []byte     `protobuf:"bytes,2,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	// Version is the wire format version of the certificate. See WireVersion.
+	Version uint32 `protobuf:"varint,3,opt,name=Version,proto3" json:"Version,omitempty"`
+	// The consensus instance that the message is addressed to, if several instances share the connections.
+	Instance uint32 `protobuf:"varint,4,opt,name=Instance,proto3" json:"Instance,omitempty"`
 }
 
 func (x *PartialCert) Reset() {
 	*x = PartialCert{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[6]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -418,7 +1083,7 @@ func (x *PartialCert) String() string {
 func (*PartialCert) ProtoMessage() {}
 
 func (x *PartialCert) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[6]
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -431,7 +1096,7 @@ func (x *PartialCert) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PartialCert.ProtoReflect.Descriptor instead.
 func (*PartialCert) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{6}
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *PartialCert) GetSig() *Signature {
@@ -448,6 +1113,20 @@ func (x *PartialCert) GetHash() []byte {
 	return nil
 }
 
+func (x *PartialCert) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *PartialCert) GetInstance() uint32 {
+	if x != nil {
+		return x.Instance
+	}
+	return 0
+}
+
 type ECDSAThresholdSignature struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -459,7 +1138,7 @@ type ECDSAThresholdSignature struct {
 func (x *ECDSAThresholdSignature) Reset() {
 	*x = ECDSAThresholdSignature{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[7]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -472,7 +1151,7 @@ func (x *ECDSAThresholdSignature) String() string {
 func (*ECDSAThresholdSignature) ProtoMessage() {}
 
 func (x *ECDSAThresholdSignature) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[7]
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -485,7 +1164,7 @@ func (x *ECDSAThresholdSignature) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ECDSAThresholdSignature.ProtoReflect.Descriptor instead.
 func (*ECDSAThresholdSignature) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{7}
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *ECDSAThresholdSignature) GetSigs() []*ECDSASignature {
@@ -507,7 +1186,7 @@ type BLS12AggregateSignature struct {
 func (x *BLS12AggregateSignature) Reset() {
 	*x = BLS12AggregateSignature{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[8]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -520,7 +1199,7 @@ func (x *BLS12AggregateSignature) String() string {
 func (*BLS12AggregateSignature) ProtoMessage() {}
 
 func (x *BLS12AggregateSignature) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[8]
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -533,7 +1212,7 @@ func (x *BLS12AggregateSignature) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BLS12AggregateSignature.ProtoReflect.Descriptor instead.
 func (*BLS12AggregateSignature) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{8}
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *BLS12AggregateSignature) GetSig() []byte {
@@ -564,7 +1243,7 @@ type ThresholdSignature struct {
 func (x *ThresholdSignature) Reset() {
 	*x = ThresholdSignature{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[9]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -577,7 +1256,7 @@ func (x *ThresholdSignature) String() string {
 func (*ThresholdSignature) ProtoMessage() {}
 
 func (x *ThresholdSignature) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[9]
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -590,7 +1269,7 @@ func (x *ThresholdSignature) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ThresholdSignature.ProtoReflect.Descriptor instead.
 func (*ThresholdSignature) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{9}
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{18}
 }
 
 func (m *ThresholdSignature) GetAggSig() isThresholdSignature_AggSig {
@@ -638,12 +1317,14 @@ type QuorumCert struct {
 	Sig  *ThresholdSignature `protobuf:"bytes,1,opt,name=Sig,proto3" json:"Sig,omitempty"`
 	View uint64              `protobuf:"varint,2,opt,name=View,proto3" json:"View,omitempty"`
 	Hash []byte              `protobuf:"bytes,3,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	// Version is the wire format version of the certificate. See WireVersion.
+	Version uint32 `protobuf:"varint,4,opt,name=Version,proto3" json:"Version,omitempty"`
 }
 
 func (x *QuorumCert) Reset() {
 	*x = QuorumCert{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[10]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -656,7 +1337,7 @@ func (x *QuorumCert) String() string {
 func (*QuorumCert) ProtoMessage() {}
 
 func (x *QuorumCert) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[10]
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -669,7 +1350,7 @@ func (x *QuorumCert) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QuorumCert.ProtoReflect.Descriptor instead.
 func (*QuorumCert) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{10}
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *QuorumCert) GetSig() *ThresholdSignature {
@@ -693,6 +1374,13 @@ func (x *QuorumCert) GetHash() []byte {
 	return nil
 }
 
+func (x *QuorumCert) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 type TimeoutCert struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -700,12 +1388,14 @@ type TimeoutCert struct {
 
 	Sig  *ThresholdSignature `protobuf:"bytes,1,opt,name=Sig,proto3" json:"Sig,omitempty"`
 	View uint64              `protobuf:"varint,2,opt,name=View,proto3" json:"View,omitempty"`
+	// Version is the wire format version of the certificate. See WireVersion.
+	Version uint32 `protobuf:"varint,3,opt,name=Version,proto3" json:"Version,omitempty"`
 }
 
 func (x *TimeoutCert) Reset() {
 	*x = TimeoutCert{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[11]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -718,7 +1408,7 @@ func (x *TimeoutCert) String() string {
 func (*TimeoutCert) ProtoMessage() {}
 
 func (x *TimeoutCert) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[11]
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -731,7 +1421,7 @@ func (x *TimeoutCert) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TimeoutCert.ProtoReflect.Descriptor instead.
 func (*TimeoutCert) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{11}
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *TimeoutCert) GetSig() *ThresholdSignature {
@@ -748,6 +1438,13 @@ func (x *TimeoutCert) GetView() uint64 {
 	return 0
 }
 
+func (x *TimeoutCert) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 type TimeoutMsg struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -757,12 +1454,14 @@ type TimeoutMsg struct {
 	SyncInfo *SyncInfo  `protobuf:"bytes,2,opt,name=SyncInfo,proto3" json:"SyncInfo,omitempty"`
 	ViewSig  *Signature `protobuf:"bytes,3,opt,name=ViewSig,proto3" json:"ViewSig,omitempty"`
 	MsgSig   *Signature `protobuf:"bytes,4,opt,name=MsgSig,proto3,oneof" json:"MsgSig,omitempty"`
+	// The consensus instance that the message is addressed to, if several instances share the connections.
+	Instance uint32 `protobuf:"varint,5,opt,name=Instance,proto3" json:"Instance,omitempty"`
 }
 
 func (x *TimeoutMsg) Reset() {
 	*x = TimeoutMsg{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[12]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -775,7 +1474,7 @@ func (x *TimeoutMsg) String() string {
 func (*TimeoutMsg) ProtoMessage() {}
 
 func (x *TimeoutMsg) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[12]
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -788,7 +1487,7 @@ func (x *TimeoutMsg) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TimeoutMsg.ProtoReflect.Descriptor instead.
 func (*TimeoutMsg) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{12}
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *TimeoutMsg) GetView() uint64 {
@@ -819,6 +1518,13 @@ func (x *TimeoutMsg) GetMsgSig() *Signature {
 	return nil
 }
 
+func (x *TimeoutMsg) GetInstance() uint32 {
+	if x != nil {
+		return x.Instance
+	}
+	return 0
+}
+
 type SyncInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -827,12 +1533,14 @@ type SyncInfo struct {
 	QC    *QuorumCert  `protobuf:"bytes,1,opt,name=QC,proto3,oneof" json:"QC,omitempty"`
 	TC    *TimeoutCert `protobuf:"bytes,2,opt,name=TC,proto3,oneof" json:"TC,omitempty"`
 	AggQC *AggQC       `protobuf:"bytes,3,opt,name=AggQC,proto3,oneof" json:"AggQC,omitempty"`
+	// The consensus instance that the message is addressed to, if several instances share the connections.
+	Instance uint32 `protobuf:"varint,4,opt,name=Instance,proto3" json:"Instance,omitempty"`
 }
 
 func (x *SyncInfo) Reset() {
 	*x = SyncInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[13]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -845,7 +1553,7 @@ func (x *SyncInfo) String() string {
 func (*SyncInfo) ProtoMessage() {}
 
 func (x *SyncInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[13]
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -858,7 +1566,7 @@ func (x *SyncInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SyncInfo.ProtoReflect.Descriptor instead.
 func (*SyncInfo) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{13}
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *SyncInfo) GetQC() *QuorumCert {
@@ -882,6 +1590,13 @@ func (x *SyncInfo) GetAggQC() *AggQC {
 	return nil
 }
 
+func (x *SyncInfo) GetInstance() uint32 {
+	if x != nil {
+		return x.Instance
+	}
+	return 0
+}
+
 type AggQC struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -890,12 +1605,14 @@ type AggQC struct {
 	QCs  map[uint32]*QuorumCert `protobuf:"bytes,1,rep,name=QCs,proto3" json:"QCs,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	Sig  *ThresholdSignature    `protobuf:"bytes,2,opt,name=Sig,proto3" json:"Sig,omitempty"`
 	View uint64                 `protobuf:"varint,3,opt,name=View,proto3" json:"View,omitempty"`
+	// Version is the wire format version of the certificate. See WireVersion.
+	Version uint32 `protobuf:"varint,4,opt,name=Version,proto3" json:"Version,omitempty"`
 }
 
 func (x *AggQC) Reset() {
 	*x = AggQC{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[14]
+		mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -908,7 +1625,7 @@ func (x *AggQC) String() string {
 func (*AggQC) ProtoMessage() {}
 
 func (x *AggQC) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[14]
+	mi := &file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -921,7 +1638,7 @@ func (x *AggQC) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AggQC.ProtoReflect.Descriptor instead.
 func (*AggQC) Descriptor() ([]byte, []int) {
-	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{14}
+	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *AggQC) GetQCs() map[uint32]*QuorumCert {
@@ -945,6 +1662,13 @@ func (x *AggQC) GetView() uint64 {
 	return 0
 }
 
+func (x *AggQC) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 var File_internal_proto_hotstuffpb_hotstuff_proto protoreflect.FileDescriptor
 
 var file_internal_proto_hotstuffpb_hotstuff_proto_rawDesc = []byte{
@@ -954,137 +1678,251 @@ var file_internal_proto_hotstuffpb_hotstuff_proto_rawDesc = []byte{
 	0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x1a, 0x0c, 0x67, 0x6f, 0x72, 0x75, 0x6d, 0x73, 0x2e, 0x70,
 	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f,
 	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x22, 0x6b, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x27, 0x0a,
-	0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x68,
-	0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52,
-	0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x2c, 0x0a, 0x05, 0x41, 0x67, 0x67, 0x51, 0x43, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66,
-	0x70, 0x62, 0x2e, 0x41, 0x67, 0x67, 0x51, 0x43, 0x48, 0x00, 0x52, 0x05, 0x41, 0x67, 0x67, 0x51,
-	0x43, 0x88, 0x01, 0x01, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x41, 0x67, 0x67, 0x51, 0x43, 0x22, 0x1f,
-	0x0a, 0x09, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x48,
-	0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x22,
-	0x91, 0x01, 0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x50, 0x61, 0x72,
-	0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x50, 0x61, 0x72, 0x65, 0x6e,
-	0x74, 0x12, 0x26, 0x0a, 0x02, 0x51, 0x43, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e,
-	0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75,
-	0x6d, 0x43, 0x65, 0x72, 0x74, 0x52, 0x02, 0x51, 0x43, 0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65,
-	0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56, 0x69, 0x65, 0x77, 0x12, 0x18, 0x0a,
-	0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x70, 0x6f,
-	0x73, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x50, 0x72, 0x6f, 0x70, 0x6f,
-	0x73, 0x65, 0x72, 0x22, 0x44, 0x0a, 0x0e, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x6e,
-	0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12, 0x0c, 0x0a,
-	0x01, 0x52, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x52, 0x12, 0x0c, 0x0a, 0x01, 0x53,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x53, 0x22, 0x22, 0x0a, 0x0e, 0x42, 0x4c, 0x53,
-	0x31, 0x32, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x53,
-	0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x53, 0x69, 0x67, 0x22, 0x86, 0x01,
-	0x0a, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x38, 0x0a, 0x08, 0x45,
-	0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
-	0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x45, 0x43, 0x44, 0x53, 0x41,
-	0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x08, 0x45, 0x43, 0x44,
-	0x53, 0x41, 0x53, 0x69, 0x67, 0x12, 0x38, 0x0a, 0x08, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69,
-	0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75,
-	0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
-	0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x08, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69, 0x67, 0x42,
-	0x05, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x22, 0x4a, 0x0a, 0x0b, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61,
+	0x6f, 0x22, 0x87, 0x01, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x27,
+	0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x52, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x2c, 0x0a, 0x05, 0x41, 0x67, 0x67, 0x51, 0x43,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66,
+	0x66, 0x70, 0x62, 0x2e, 0x41, 0x67, 0x67, 0x51, 0x43, 0x48, 0x00, 0x52, 0x05, 0x41, 0x67, 0x67,
+	0x51, 0x43, 0x88, 0x01, 0x01, 0x12, 0x1a, 0x0a, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x41, 0x67, 0x67, 0x51, 0x43, 0x22, 0x93, 0x01, 0x0a, 0x0d,
+	0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x12, 0x0a,
+	0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56, 0x69, 0x65,
+	0x77, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x04, 0x48, 0x61, 0x73, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x14, 0x0a, 0x05, 0x54,
+	0x6f, 0x74, 0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x54, 0x6f, 0x74, 0x61,
+	0x6c, 0x12, 0x12, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x04, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1a, 0x0a, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x22, 0x3b, 0x0a, 0x09, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x12,
+	0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61,
+	0x73, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0x45,
+	0x0a, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
+	0x18, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x49, 0x6e, 0x73,
+	0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x49, 0x6e, 0x73,
+	0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0x41, 0x0a, 0x0f, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61,
+	0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56, 0x69, 0x65, 0x77, 0x12, 0x1a, 0x0a, 0x08,
+	0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08,
+	0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0x59, 0x0a, 0x07, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x49, 0x44, 0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x04, 0x56, 0x69, 0x65, 0x77, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x12, 0x26, 0x0a, 0x02, 0x51,
+	0x43, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75,
+	0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x52,
+	0x02, 0x51, 0x43, 0x22, 0x55, 0x0a, 0x0a, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x4f, 0x66, 0x66, 0x65,
+	0x72, 0x12, 0x2b, 0x0a, 0x06, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x44, 0x52, 0x06, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x1a,
+	0x0a, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0x73, 0x0a, 0x0f, 0x53, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a,
+	0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x06, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x4c,
+	0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x4c, 0x65, 0x6e,
+	0x67, 0x74, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22,
+	0xa0, 0x01, 0x0a, 0x0d, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x68, 0x75, 0x6e,
+	0x6b, 0x12, 0x27, 0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x52, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x26, 0x0a, 0x02, 0x51, 0x43,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66,
+	0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x52, 0x02,
+	0x51, 0x43, 0x12, 0x16, 0x0a, 0x06, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x06, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x53, 0x69,
+	0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x44, 0x61,
+	0x74, 0x61, 0x22, 0x1f, 0x0a, 0x07, 0x50, 0x69, 0x6e, 0x67, 0x4d, 0x73, 0x67, 0x12, 0x14, 0x0a,
+	0x05, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x4e, 0x6f,
+	0x6e, 0x63, 0x65, 0x22, 0xca, 0x01, 0x0a, 0x0c, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b,
+	0x65, 0x4d, 0x73, 0x67, 0x12, 0x20, 0x0a, 0x0b, 0x47, 0x65, 0x6e, 0x65, 0x73, 0x69, 0x73, 0x48,
+	0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x47, 0x65, 0x6e, 0x65, 0x73,
+	0x69, 0x73, 0x48, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x1e, 0x0a, 0x0a, 0x4d, 0x69, 0x6e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x4d, 0x69, 0x6e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x20, 0x0a, 0x0b, 0x43, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x53, 0x75, 0x69, 0x74, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x43, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x53, 0x75, 0x69,
+	0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x20,
+	0x0a, 0x0b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68,
+	0x22, 0xbf, 0x01, 0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x50, 0x61,
+	0x72, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x50, 0x61, 0x72, 0x65,
+	0x6e, 0x74, 0x12, 0x26, 0x0a, 0x02, 0x51, 0x43, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72,
+	0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x52, 0x02, 0x51, 0x43, 0x12, 0x12, 0x0a, 0x04, 0x56, 0x69,
+	0x65, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56, 0x69, 0x65, 0x77, 0x12, 0x18,
+	0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x50, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x72, 0x12, 0x2c, 0x0a, 0x11, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x65, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x11, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x22, 0x44, 0x0a, 0x0e, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12, 0x0c, 0x0a, 0x01,
+	0x52, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x52, 0x12, 0x0c, 0x0a, 0x01, 0x53, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x53, 0x22, 0x22, 0x0a, 0x0e, 0x42, 0x4c, 0x53, 0x31,
+	0x32, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x53, 0x69,
+	0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x53, 0x69, 0x67, 0x22, 0x86, 0x01, 0x0a,
+	0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x38, 0x0a, 0x08, 0x45, 0x43,
+	0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x68,
+	0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x08, 0x45, 0x43, 0x44, 0x53,
+	0x41, 0x53, 0x69, 0x67, 0x12, 0x38, 0x0a, 0x08, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69, 0x67,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66,
+	0x66, 0x70, 0x62, 0x2e, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
+	0x72, 0x65, 0x48, 0x00, 0x52, 0x08, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69, 0x67, 0x42, 0x05,
+	0x0a, 0x03, 0x53, 0x69, 0x67, 0x22, 0x80, 0x01, 0x0a, 0x0b, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61,
 	0x6c, 0x43, 0x65, 0x72, 0x74, 0x12, 0x27, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01,
 	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e,
 	0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x03, 0x53, 0x69, 0x67, 0x12, 0x12,
 	0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61,
-	0x73, 0x68, 0x22, 0x49, 0x0a, 0x17, 0x45, 0x43, 0x44, 0x53, 0x41, 0x54, 0x68, 0x72, 0x65, 0x73,
-	0x68, 0x6f, 0x6c, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x2e, 0x0a,
-	0x04, 0x53, 0x69, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x68, 0x6f,
-	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69,
-	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x04, 0x53, 0x69, 0x67, 0x73, 0x22, 0x4f, 0x0a,
-	0x17, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53,
-	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x53, 0x69, 0x67, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x61,
-	0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x73, 0x22, 0xa6,
-	0x01, 0x0a, 0x12, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x53, 0x69, 0x67, 0x6e,
-	0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x43, 0x0a, 0x09, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69,
-	0x67, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74,
-	0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x45, 0x43, 0x44, 0x53, 0x41, 0x54, 0x68, 0x72, 0x65, 0x73,
-	0x68, 0x6f, 0x6c, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52,
-	0x09, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x73, 0x12, 0x41, 0x0a, 0x08, 0x42, 0x4c,
-	0x53, 0x31, 0x32, 0x53, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x68,
-	0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x41,
-	0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
-	0x65, 0x48, 0x00, 0x52, 0x08, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69, 0x67, 0x42, 0x08, 0x0a,
-	0x06, 0x41, 0x67, 0x67, 0x53, 0x69, 0x67, 0x22, 0x66, 0x0a, 0x0a, 0x51, 0x75, 0x6f, 0x72, 0x75,
-	0x6d, 0x43, 0x65, 0x72, 0x74, 0x12, 0x30, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e,
-	0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
-	0x72, 0x65, 0x52, 0x03, 0x53, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56, 0x69, 0x65, 0x77, 0x12, 0x12, 0x0a, 0x04, 0x48,
-	0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x22,
-	0x53, 0x0a, 0x0b, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x43, 0x65, 0x72, 0x74, 0x12, 0x30,
-	0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x68, 0x6f,
-	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f,
-	0x6c, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x03, 0x53, 0x69, 0x67,
-	0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04,
-	0x56, 0x69, 0x65, 0x77, 0x22, 0xc2, 0x01, 0x0a, 0x0a, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
-	0x4d, 0x73, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x04, 0x56, 0x69, 0x65, 0x77, 0x12, 0x30, 0x0a, 0x08, 0x53, 0x79, 0x6e, 0x63, 0x49,
-	0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x68, 0x6f, 0x74, 0x73,
-	0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x52,
-	0x08, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x2f, 0x0a, 0x07, 0x56, 0x69, 0x65,
-	0x77, 0x53, 0x69, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x68, 0x6f, 0x74,
-	0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
-	0x65, 0x52, 0x07, 0x56, 0x69, 0x65, 0x77, 0x53, 0x69, 0x67, 0x12, 0x32, 0x0a, 0x06, 0x4d, 0x73,
-	0x67, 0x53, 0x69, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x68, 0x6f, 0x74,
-	0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
-	0x65, 0x48, 0x00, 0x52, 0x06, 0x4d, 0x73, 0x67, 0x53, 0x69, 0x67, 0x88, 0x01, 0x01, 0x42, 0x09,
-	0x0a, 0x07, 0x5f, 0x4d, 0x73, 0x67, 0x53, 0x69, 0x67, 0x22, 0xab, 0x01, 0x0a, 0x08, 0x53, 0x79,
-	0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x2b, 0x0a, 0x02, 0x51, 0x43, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e,
-	0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x48, 0x00, 0x52, 0x02, 0x51, 0x43,
-	0x88, 0x01, 0x01, 0x12, 0x2c, 0x0a, 0x02, 0x54, 0x43, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x17, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x54, 0x69, 0x6d,
-	0x65, 0x6f, 0x75, 0x74, 0x43, 0x65, 0x72, 0x74, 0x48, 0x01, 0x52, 0x02, 0x54, 0x43, 0x88, 0x01,
-	0x01, 0x12, 0x2c, 0x0a, 0x05, 0x41, 0x67, 0x67, 0x51, 0x43, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x11, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x41, 0x67,
-	0x67, 0x51, 0x43, 0x48, 0x02, 0x52, 0x05, 0x41, 0x67, 0x67, 0x51, 0x43, 0x88, 0x01, 0x01, 0x42,
-	0x05, 0x0a, 0x03, 0x5f, 0x51, 0x43, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x54, 0x43, 0x42, 0x08, 0x0a,
-	0x06, 0x5f, 0x41, 0x67, 0x67, 0x51, 0x43, 0x22, 0xcb, 0x01, 0x0a, 0x05, 0x41, 0x67, 0x67, 0x51,
-	0x43, 0x12, 0x2c, 0x0a, 0x03, 0x51, 0x43, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a,
-	0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x41, 0x67, 0x67, 0x51,
-	0x43, 0x2e, 0x51, 0x43, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x03, 0x51, 0x43, 0x73, 0x12,
-	0x30, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x68,
+	0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08,
+	0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08,
+	0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0x49, 0x0a, 0x17, 0x45, 0x43, 0x44, 0x53,
+	0x41, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x12, 0x2e, 0x0a, 0x04, 0x53, 0x69, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x45,
+	0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x04, 0x53,
+	0x69, 0x67, 0x73, 0x22, 0x4f, 0x0a, 0x17, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x41, 0x67, 0x67, 0x72,
+	0x65, 0x67, 0x61, 0x74, 0x65, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x10,
+	0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x53, 0x69, 0x67,
+	0x12, 0x22, 0x0a, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70,
+	0x61, 0x6e, 0x74, 0x73, 0x22, 0xa6, 0x01, 0x0a, 0x12, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f,
+	0x6c, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x43, 0x0a, 0x09, 0x45,
+	0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23,
+	0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x45, 0x43, 0x44, 0x53,
+	0x41, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x09, 0x45, 0x43, 0x44, 0x53, 0x41, 0x53, 0x69, 0x67, 0x73,
+	0x12, 0x41, 0x0a, 0x08, 0x42, 0x4c, 0x53, 0x31, 0x32, 0x53, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x23, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e,
+	0x42, 0x4c, 0x53, 0x31, 0x32, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x08, 0x42, 0x4c, 0x53, 0x31, 0x32,
+	0x53, 0x69, 0x67, 0x42, 0x08, 0x0a, 0x06, 0x41, 0x67, 0x67, 0x53, 0x69, 0x67, 0x22, 0x80, 0x01,
+	0x0a, 0x0a, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x12, 0x30, 0x0a, 0x03,
+	0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x68, 0x6f, 0x74, 0x73,
+	0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64,
+	0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x03, 0x53, 0x69, 0x67, 0x12, 0x12,
+	0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56, 0x69,
+	0x65, 0x77, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x22, 0x6d, 0x0a, 0x0b, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x43, 0x65, 0x72, 0x74, 0x12,
+	0x30, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x68,
 	0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68,
 	0x6f, 0x6c, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x03, 0x53, 0x69,
-	0x67, 0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52,
-	0x04, 0x56, 0x69, 0x65, 0x77, 0x1a, 0x4e, 0x0a, 0x08, 0x51, 0x43, 0x73, 0x45, 0x6e, 0x74, 0x72,
-	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03,
-	0x6b, 0x65, 0x79, 0x12, 0x2c, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e,
-	0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x3a, 0x02, 0x38, 0x01, 0x32, 0xc1, 0x02, 0x0a, 0x08, 0x48, 0x6f, 0x74, 0x73, 0x74, 0x75,
-	0x66, 0x66, 0x12, 0x3d, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x12, 0x14, 0x2e,
-	0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x6f, 0x70, 0x6f,
-	0x73, 0x61, 0x6c, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x98, 0xb5, 0x18,
-	0x01, 0x12, 0x3d, 0x0a, 0x04, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x68, 0x6f, 0x74, 0x73,
-	0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x43, 0x65,
-	0x72, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x90, 0xb5, 0x18, 0x01,
-	0x12, 0x3f, 0x0a, 0x07, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x16, 0x2e, 0x68, 0x6f,
-	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
-	0x4d, 0x73, 0x67, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x98, 0xb5, 0x18,
-	0x01, 0x12, 0x3d, 0x0a, 0x07, 0x4e, 0x65, 0x77, 0x56, 0x69, 0x65, 0x77, 0x12, 0x14, 0x2e, 0x68,
-	0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e,
-	0x66, 0x6f, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x90, 0xb5, 0x18, 0x01,
-	0x12, 0x37, 0x0a, 0x05, 0x46, 0x65, 0x74, 0x63, 0x68, 0x12, 0x15, 0x2e, 0x68, 0x6f, 0x74, 0x73,
-	0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68,
-	0x1a, 0x11, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c,
-	0x6f, 0x63, 0x6b, 0x22, 0x04, 0xa0, 0xb5, 0x18, 0x01, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x69, 0x74,
-	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x65, 0x6c, 0x61, 0x62, 0x2f, 0x68, 0x6f,
-	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x67, 0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x04, 0x56, 0x69, 0x65, 0x77, 0x12, 0x18, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22,
+	0xde, 0x01, 0x0a, 0x0a, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x67, 0x12, 0x12,
+	0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x56, 0x69,
+	0x65, 0x77, 0x12, 0x30, 0x0a, 0x08, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70,
+	0x62, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x08, 0x53, 0x79, 0x6e, 0x63,
+	0x49, 0x6e, 0x66, 0x6f, 0x12, 0x2f, 0x0a, 0x07, 0x56, 0x69, 0x65, 0x77, 0x53, 0x69, 0x67, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66,
+	0x70, 0x62, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x07, 0x56, 0x69,
+	0x65, 0x77, 0x53, 0x69, 0x67, 0x12, 0x32, 0x0a, 0x06, 0x4d, 0x73, 0x67, 0x53, 0x69, 0x67, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66,
+	0x70, 0x62, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x06,
+	0x4d, 0x73, 0x67, 0x53, 0x69, 0x67, 0x88, 0x01, 0x01, 0x12, 0x1a, 0x0a, 0x08, 0x49, 0x6e, 0x73,
+	0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x49, 0x6e, 0x73,
+	0x74, 0x61, 0x6e, 0x63, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x4d, 0x73, 0x67, 0x53, 0x69, 0x67,
+	0x22, 0xc7, 0x01, 0x0a, 0x08, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x2b, 0x0a,
+	0x02, 0x51, 0x43, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73,
+	0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72,
+	0x74, 0x48, 0x00, 0x52, 0x02, 0x51, 0x43, 0x88, 0x01, 0x01, 0x12, 0x2c, 0x0a, 0x02, 0x54, 0x43,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66,
+	0x66, 0x70, 0x62, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x43, 0x65, 0x72, 0x74, 0x48,
+	0x01, 0x52, 0x02, 0x54, 0x43, 0x88, 0x01, 0x01, 0x12, 0x2c, 0x0a, 0x05, 0x41, 0x67, 0x67, 0x51,
+	0x43, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75,
+	0x66, 0x66, 0x70, 0x62, 0x2e, 0x41, 0x67, 0x67, 0x51, 0x43, 0x48, 0x02, 0x52, 0x05, 0x41, 0x67,
+	0x67, 0x51, 0x43, 0x88, 0x01, 0x01, 0x12, 0x1a, 0x0a, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e,
+	0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e,
+	0x63, 0x65, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x51, 0x43, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x54, 0x43,
+	0x42, 0x08, 0x0a, 0x06, 0x5f, 0x41, 0x67, 0x67, 0x51, 0x43, 0x22, 0xe5, 0x01, 0x0a, 0x05, 0x41,
+	0x67, 0x67, 0x51, 0x43, 0x12, 0x2c, 0x0a, 0x03, 0x51, 0x43, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x41,
+	0x67, 0x67, 0x51, 0x43, 0x2e, 0x51, 0x43, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x03, 0x51,
+	0x43, 0x73, 0x12, 0x30, 0x0a, 0x03, 0x53, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1e, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x54, 0x68, 0x72,
+	0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52,
+	0x03, 0x53, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x04, 0x56, 0x69, 0x65, 0x77, 0x12, 0x18, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x1a, 0x4e, 0x0a, 0x08, 0x51, 0x43, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x2c, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x51, 0x75, 0x6f,
+	0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x32, 0xe3, 0x06, 0x0a, 0x08, 0x48, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x12,
+	0x3d, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x12, 0x14, 0x2e, 0x68, 0x6f, 0x74,
+	0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c,
+	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x98, 0xb5, 0x18, 0x01, 0x12, 0x3d,
+	0x0a, 0x04, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66,
+	0x66, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x43, 0x65, 0x72, 0x74, 0x1a,
+	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x90, 0xb5, 0x18, 0x01, 0x12, 0x3f, 0x0a,
+	0x07, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74,
+	0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x67,
+	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x98, 0xb5, 0x18, 0x01, 0x12, 0x3d,
+	0x0a, 0x07, 0x4e, 0x65, 0x77, 0x56, 0x69, 0x65, 0x77, 0x12, 0x14, 0x2e, 0x68, 0x6f, 0x74, 0x73,
+	0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x1a,
+	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x90, 0xb5, 0x18, 0x01, 0x12, 0x37, 0x0a,
+	0x05, 0x46, 0x65, 0x74, 0x63, 0x68, 0x12, 0x15, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66,
+	0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x1a, 0x11, 0x2e,
+	0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x22, 0x04, 0xa0, 0xb5, 0x18, 0x01, 0x12, 0x3d, 0x0a, 0x07, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72,
+	0x64, 0x12, 0x14, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50,
+	0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22,
+	0x04, 0x98, 0xb5, 0x18, 0x01, 0x12, 0x4c, 0x0a, 0x0f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x1b, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74,
+	0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x90,
+	0xb5, 0x18, 0x01, 0x12, 0x3d, 0x0a, 0x05, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x12, 0x16, 0x2e, 0x68,
+	0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x4f,
+	0x66, 0x66, 0x65, 0x72, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x90, 0xb5,
+	0x18, 0x01, 0x12, 0x32, 0x0a, 0x04, 0x50, 0x69, 0x6e, 0x67, 0x12, 0x13, 0x2e, 0x68, 0x6f, 0x74,
+	0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50, 0x69, 0x6e, 0x67, 0x4d, 0x73, 0x67, 0x1a,
+	0x13, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50, 0x69, 0x6e,
+	0x67, 0x4d, 0x73, 0x67, 0x22, 0x00, 0x12, 0x41, 0x0a, 0x09, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x68,
+	0x61, 0x6b, 0x65, 0x12, 0x18, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62,
+	0x2e, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x4d, 0x73, 0x67, 0x1a, 0x18, 0x2e,
+	0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x48, 0x61, 0x6e, 0x64, 0x73,
+	0x68, 0x61, 0x6b, 0x65, 0x4d, 0x73, 0x67, 0x22, 0x00, 0x12, 0x47, 0x0a, 0x0c, 0x50, 0x72, 0x6f,
+	0x70, 0x6f, 0x73, 0x65, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x19, 0x2e, 0x68, 0x6f, 0x74, 0x73,
+	0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x43,
+	0x68, 0x75, 0x6e, 0x6b, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x98, 0xb5,
+	0x18, 0x01, 0x12, 0x49, 0x0a, 0x0e, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x12, 0x19, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70,
+	0x62, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x1a,
+	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x04, 0x90, 0xb5, 0x18, 0x01, 0x12, 0x49, 0x0a,
+	0x0d, 0x46, 0x65, 0x74, 0x63, 0x68, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x1b,
+	0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x68, 0x6f,
+	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
+	0x74, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0x00, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x65, 0x6c, 0x61, 0x62, 0x2f, 0x68, 0x6f, 0x74,
+	0x73, 0x74, 0x75, 0x66, 0x66, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1099,62 +1937,91 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_rawDescGZIP() []byte {
 	return file_internal_proto_hotstuffpb_hotstuff_proto_rawDescData
 }
 
-var file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
 var file_internal_proto_hotstuffpb_hotstuff_proto_goTypes = []interface{}{
 	(*Proposal)(nil),                // 0: hotstuffpb.Proposal
-	(*BlockHash)(nil),               // 1: hotstuffpb.BlockHash
-	(*Block)(nil),                   // 2: hotstuffpb.Block
-	(*ECDSASignature)(nil),          // 3: hotstuffpb.ECDSASignature
-	(*BLS12Signature)(nil),          // 4: hotstuffpb.BLS12Signature
-	(*Signature)(nil),               // 5: hotstuffpb.Signature
-	(*PartialCert)(nil),             // 6: hotstuffpb.PartialCert
-	(*ECDSAThresholdSignature)(nil), // 7: hotstuffpb.ECDSAThresholdSignature
-	(*BLS12AggregateSignature)(nil), // 8: hotstuffpb.BLS12AggregateSignature
-	(*ThresholdSignature)(nil),      // 9: hotstuffpb.ThresholdSignature
-	(*QuorumCert)(nil),              // 10: hotstuffpb.QuorumCert
-	(*TimeoutCert)(nil),             // 11: hotstuffpb.TimeoutCert
-	(*TimeoutMsg)(nil),              // 12: hotstuffpb.TimeoutMsg
-	(*SyncInfo)(nil),                // 13: hotstuffpb.SyncInfo
-	(*AggQC)(nil),                   // 14: hotstuffpb.AggQC
-	nil,                             // 15: hotstuffpb.AggQC.QCsEntry
-	(*emptypb.Empty)(nil),           // 16: google.protobuf.Empty
+	(*ProposalChunk)(nil),           // 1: hotstuffpb.ProposalChunk
+	(*BlockHash)(nil),               // 2: hotstuffpb.BlockHash
+	(*ClientCommand)(nil),           // 3: hotstuffpb.ClientCommand
+	(*ProposalRequest)(nil),         // 4: hotstuffpb.ProposalRequest
+	(*BlockID)(nil),                 // 5: hotstuffpb.BlockID
+	(*BlockOffer)(nil),              // 6: hotstuffpb.BlockOffer
+	(*SnapshotRequest)(nil),         // 7: hotstuffpb.SnapshotRequest
+	(*SnapshotChunk)(nil),           // 8: hotstuffpb.SnapshotChunk
+	(*PingMsg)(nil),                 // 9: hotstuffpb.PingMsg
+	(*HandshakeMsg)(nil),            // 10: hotstuffpb.HandshakeMsg
+	(*Block)(nil),                   // 11: hotstuffpb.Block
+	(*ECDSASignature)(nil),          // 12: hotstuffpb.ECDSASignature
+	(*BLS12Signature)(nil),          // 13: hotstuffpb.BLS12Signature
+	(*Signature)(nil),               // 14: hotstuffpb.Signature
+	(*PartialCert)(nil),             // 15: hotstuffpb.PartialCert
+	(*ECDSAThresholdSignature)(nil), // 16: hotstuffpb.ECDSAThresholdSignature
+	(*BLS12AggregateSignature)(nil), // 17: hotstuffpb.BLS12AggregateSignature
+	(*ThresholdSignature)(nil),      // 18: hotstuffpb.ThresholdSignature
+	(*QuorumCert)(nil),              // 19: hotstuffpb.QuorumCert
+	(*TimeoutCert)(nil),             // 20: hotstuffpb.TimeoutCert
+	(*TimeoutMsg)(nil),              // 21: hotstuffpb.TimeoutMsg
+	(*SyncInfo)(nil),                // 22: hotstuffpb.SyncInfo
+	(*AggQC)(nil),                   // 23: hotstuffpb.AggQC
+	nil,                             // 24: hotstuffpb.AggQC.QCsEntry
+	(*emptypb.Empty)(nil),           // 25: google.protobuf.Empty
 }
 var file_internal_proto_hotstuffpb_hotstuff_proto_depIdxs = []int32{
-	2,  // 0: hotstuffpb.Proposal.Block:type_name -> hotstuffpb.Block
-	14, // 1: hotstuffpb.Proposal.AggQC:type_name -> hotstuffpb.AggQC
-	10, // 2: hotstuffpb.Block.QC:type_name -> hotstuffpb.QuorumCert
-	3,  // 3: hotstuffpb.Signature.ECDSASig:type_name -> hotstuffpb.ECDSASignature
-	4,  // 4: hotstuffpb.Signature.BLS12Sig:type_name -> hotstuffpb.BLS12Signature
-	5,  // 5: hotstuffpb.PartialCert.Sig:type_name -> hotstuffpb.Signature
-	3,  // 6: hotstuffpb.ECDSAThresholdSignature.Sigs:type_name -> hotstuffpb.ECDSASignature
-	7,  // 7: hotstuffpb.ThresholdSignature.ECDSASigs:type_name -> hotstuffpb.ECDSAThresholdSignature
-	8,  // 8: hotstuffpb.ThresholdSignature.BLS12Sig:type_name -> hotstuffpb.BLS12AggregateSignature
-	9,  // 9: hotstuffpb.QuorumCert.Sig:type_name -> hotstuffpb.ThresholdSignature
-	9,  // 10: hotstuffpb.TimeoutCert.Sig:type_name -> hotstuffpb.ThresholdSignature
-	13, // 11: hotstuffpb.TimeoutMsg.SyncInfo:type_name -> hotstuffpb.SyncInfo
-	5,  // 12: hotstuffpb.TimeoutMsg.ViewSig:type_name -> hotstuffpb.Signature
-	5,  // 13: hotstuffpb.TimeoutMsg.MsgSig:type_name -> hotstuffpb.Signature
-	10, // 14: hotstuffpb.SyncInfo.QC:type_name -> hotstuffpb.QuorumCert
-	11, // 15: hotstuffpb.SyncInfo.TC:type_name -> hotstuffpb.TimeoutCert
-	14, // 16: hotstuffpb.SyncInfo.AggQC:type_name -> hotstuffpb.AggQC
-	15, // 17: hotstuffpb.AggQC.QCs:type_name -> hotstuffpb.AggQC.QCsEntry
-	9,  // 18: hotstuffpb.AggQC.Sig:type_name -> hotstuffpb.ThresholdSignature
-	10, // 19: hotstuffpb.AggQC.QCsEntry.value:type_name -> hotstuffpb.QuorumCert
-	0,  // 20: hotstuffpb.Hotstuff.Propose:input_type -> hotstuffpb.Proposal
-	6,  // 21: hotstuffpb.Hotstuff.Vote:input_type -> hotstuffpb.PartialCert
-	12, // 22: hotstuffpb.Hotstuff.Timeout:input_type -> hotstuffpb.TimeoutMsg
-	13, // 23: hotstuffpb.Hotstuff.NewView:input_type -> hotstuffpb.SyncInfo
-	1,  // 24: hotstuffpb.Hotstuff.Fetch:input_type -> hotstuffpb.BlockHash
-	16, // 25: hotstuffpb.Hotstuff.Propose:output_type -> google.protobuf.Empty
-	16, // 26: hotstuffpb.Hotstuff.Vote:output_type -> google.protobuf.Empty
-	16, // 27: hotstuffpb.Hotstuff.Timeout:output_type -> google.protobuf.Empty
-	16, // 28: hotstuffpb.Hotstuff.NewView:output_type -> google.protobuf.Empty
-	2,  // 29: hotstuffpb.Hotstuff.Fetch:output_type -> hotstuffpb.Block
-	25, // [25:30] is the sub-list for method output_type
-	20, // [20:25] is the sub-list for method input_type
-	20, // [20:20] is the sub-list for extension type_name
-	20, // [20:20] is the sub-list for extension extendee
-	0,  // [0:20] is the sub-list for field type_name
+	11, // 0: hotstuffpb.Proposal.Block:type_name -> hotstuffpb.Block
+	23, // 1: hotstuffpb.Proposal.AggQC:type_name -> hotstuffpb.AggQC
+	19, // 2: hotstuffpb.BlockID.QC:type_name -> hotstuffpb.QuorumCert
+	5,  // 3: hotstuffpb.BlockOffer.Blocks:type_name -> hotstuffpb.BlockID
+	11, // 4: hotstuffpb.SnapshotChunk.Block:type_name -> hotstuffpb.Block
+	19, // 5: hotstuffpb.SnapshotChunk.QC:type_name -> hotstuffpb.QuorumCert
+	19, // 6: hotstuffpb.Block.QC:type_name -> hotstuffpb.QuorumCert
+	12, // 7: hotstuffpb.Signature.ECDSASig:type_name -> hotstuffpb.ECDSASignature
+	13, // 8: hotstuffpb.Signature.BLS12Sig:type_name -> hotstuffpb.BLS12Signature
+	14, // 9: hotstuffpb.PartialCert.Sig:type_name -> hotstuffpb.Signature
+	12, // 10: hotstuffpb.ECDSAThresholdSignature.Sigs:type_name -> hotstuffpb.ECDSASignature
+	16, // 11: hotstuffpb.ThresholdSignature.ECDSASigs:type_name -> hotstuffpb.ECDSAThresholdSignature
+	17, // 12: hotstuffpb.ThresholdSignature.BLS12Sig:type_name -> hotstuffpb.BLS12AggregateSignature
+	18, // 13: hotstuffpb.QuorumCert.Sig:type_name -> hotstuffpb.ThresholdSignature
+	18, // 14: hotstuffpb.TimeoutCert.Sig:type_name -> hotstuffpb.ThresholdSignature
+	22, // 15: hotstuffpb.TimeoutMsg.SyncInfo:type_name -> hotstuffpb.SyncInfo
+	14, // 16: hotstuffpb.TimeoutMsg.ViewSig:type_name -> hotstuffpb.Signature
+	14, // 17: hotstuffpb.TimeoutMsg.MsgSig:type_name -> hotstuffpb.Signature
+	19, // 18: hotstuffpb.SyncInfo.QC:type_name -> hotstuffpb.QuorumCert
+	20, // 19: hotstuffpb.SyncInfo.TC:type_name -> hotstuffpb.TimeoutCert
+	23, // 20: hotstuffpb.SyncInfo.AggQC:type_name -> hotstuffpb.AggQC
+	24, // 21: hotstuffpb.AggQC.QCs:type_name -> hotstuffpb.AggQC.QCsEntry
+	18, // 22: hotstuffpb.AggQC.Sig:type_name -> hotstuffpb.ThresholdSignature
+	19, // 23: hotstuffpb.AggQC.QCsEntry.value:type_name -> hotstuffpb.QuorumCert
+	0,  // 24: hotstuffpb.Hotstuff.Propose:input_type -> hotstuffpb.Proposal
+	15, // 25: hotstuffpb.Hotstuff.Vote:input_type -> hotstuffpb.PartialCert
+	21, // 26: hotstuffpb.Hotstuff.Timeout:input_type -> hotstuffpb.TimeoutMsg
+	22, // 27: hotstuffpb.Hotstuff.NewView:input_type -> hotstuffpb.SyncInfo
+	2,  // 28: hotstuffpb.Hotstuff.Fetch:input_type -> hotstuffpb.BlockHash
+	0,  // 29: hotstuffpb.Hotstuff.Forward:input_type -> hotstuffpb.Proposal
+	4,  // 30: hotstuffpb.Hotstuff.RequestProposal:input_type -> hotstuffpb.ProposalRequest
+	6,  // 31: hotstuffpb.Hotstuff.Offer:input_type -> hotstuffpb.BlockOffer
+	9,  // 32: hotstuffpb.Hotstuff.Ping:input_type -> hotstuffpb.PingMsg
+	10, // 33: hotstuffpb.Hotstuff.Handshake:input_type -> hotstuffpb.HandshakeMsg
+	1,  // 34: hotstuffpb.Hotstuff.ProposeChunk:input_type -> hotstuffpb.ProposalChunk
+	3,  // 35: hotstuffpb.Hotstuff.ForwardCommand:input_type -> hotstuffpb.ClientCommand
+	7,  // 36: hotstuffpb.Hotstuff.FetchSnapshot:input_type -> hotstuffpb.SnapshotRequest
+	25, // 37: hotstuffpb.Hotstuff.Propose:output_type -> google.protobuf.Empty
+	25, // 38: hotstuffpb.Hotstuff.Vote:output_type -> google.protobuf.Empty
+	25, // 39: hotstuffpb.Hotstuff.Timeout:output_type -> google.protobuf.Empty
+	25, // 40: hotstuffpb.Hotstuff.NewView:output_type -> google.protobuf.Empty
+	11, // 41: hotstuffpb.Hotstuff.Fetch:output_type -> hotstuffpb.Block
+	25, // 42: hotstuffpb.Hotstuff.Forward:output_type -> google.protobuf.Empty
+	25, // 43: hotstuffpb.Hotstuff.RequestProposal:output_type -> google.protobuf.Empty
+	25, // 44: hotstuffpb.Hotstuff.Offer:output_type -> google.protobuf.Empty
+	9,  // 45: hotstuffpb.Hotstuff.Ping:output_type -> hotstuffpb.PingMsg
+	10, // 46: hotstuffpb.Hotstuff.Handshake:output_type -> hotstuffpb.HandshakeMsg
+	25, // 47: hotstuffpb.Hotstuff.ProposeChunk:output_type -> google.protobuf.Empty
+	25, // 48: hotstuffpb.Hotstuff.ForwardCommand:output_type -> google.protobuf.Empty
+	8,  // 49: hotstuffpb.Hotstuff.FetchSnapshot:output_type -> hotstuffpb.SnapshotChunk
+	37, // [37:50] is the sub-list for method output_type
+	24, // [24:37] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
 }
 
 func init() { file_internal_proto_hotstuffpb_hotstuff_proto_init() }
@@ -1176,7 +2043,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BlockHash); i {
+			switch v := v.(*ProposalChunk); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1188,7 +2055,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Block); i {
+			switch v := v.(*BlockHash); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1200,7 +2067,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ECDSASignature); i {
+			switch v := v.(*ClientCommand); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1212,7 +2079,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BLS12Signature); i {
+			switch v := v.(*ProposalRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1224,7 +2091,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Signature); i {
+			switch v := v.(*BlockID); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1236,7 +2103,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PartialCert); i {
+			switch v := v.(*BlockOffer); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1248,7 +2115,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ECDSAThresholdSignature); i {
+			switch v := v.(*SnapshotRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1260,7 +2127,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BLS12AggregateSignature); i {
+			switch v := v.(*SnapshotChunk); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1272,7 +2139,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ThresholdSignature); i {
+			switch v := v.(*PingMsg); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1284,7 +2151,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QuorumCert); i {
+			switch v := v.(*HandshakeMsg); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1296,7 +2163,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TimeoutCert); i {
+			switch v := v.(*Block); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1308,7 +2175,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TimeoutMsg); i {
+			switch v := v.(*ECDSASignature); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1320,7 +2187,7 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SyncInfo); i {
+			switch v := v.(*BLS12Signature); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1332,6 +2199,114 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 			}
 		}
 		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Signature); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PartialCert); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ECDSAThresholdSignature); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BLS12AggregateSignature); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ThresholdSignature); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QuorumCert); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TimeoutCert); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TimeoutMsg); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SyncInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*AggQC); i {
 			case 0:
 				return &v.state
@@ -1345,23 +2320,23 @@ func file_internal_proto_hotstuffpb_hotstuff_proto_init() {
 		}
 	}
 	file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[0].OneofWrappers = []interface{}{}
-	file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[5].OneofWrappers = []interface{}{
+	file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[14].OneofWrappers = []interface{}{
 		(*Signature_ECDSASig)(nil),
 		(*Signature_BLS12Sig)(nil),
 	}
-	file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[9].OneofWrappers = []interface{}{
+	file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[18].OneofWrappers = []interface{}{
 		(*ThresholdSignature_ECDSASigs)(nil),
 		(*ThresholdSignature_BLS12Sig)(nil),
 	}
-	file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[12].OneofWrappers = []interface{}{}
-	file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[13].OneofWrappers = []interface{}{}
+	file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[21].OneofWrappers = []interface{}{}
+	file_internal_proto_hotstuffpb_hotstuff_proto_msgTypes[22].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_internal_proto_hotstuffpb_hotstuff_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   16,
+			NumMessages:   25,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -148,6 +148,34 @@ func (c *Configuration) Timeout(ctx context.Context, in *TimeoutMsg, opts ...gor
 	c.Configuration.Multicast(ctx, cd, opts...)
 }
 
+// Reference imports to suppress errors if they are not otherwise used.
+var _ emptypb.Empty
+
+// Forward is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (c *Configuration) Forward(ctx context.Context, in *Proposal, opts ...gorums.CallOption) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.Forward",
+	}
+
+	c.Configuration.Multicast(ctx, cd, opts...)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ emptypb.Empty
+
+// ProposeChunk is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (c *Configuration) ProposeChunk(ctx context.Context, in *ProposalChunk, opts ...gorums.CallOption) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.ProposeChunk",
+	}
+
+	c.Configuration.Multicast(ctx, cd, opts...)
+}
+
 // QuorumSpec is the interface of quorum functions for Hotstuff.
 type QuorumSpec interface {
 	gorums.ConfigOption
@@ -182,6 +210,51 @@ func (c *Configuration) Fetch(ctx context.Context, in *BlockHash) (resp *Block,
 	return res.(*Block), err
 }
 
+// Ping is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (n *Node) Ping(ctx context.Context, in *PingMsg) (resp *PingMsg, err error) {
+	cd := gorums.CallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.Ping",
+	}
+
+	res, err := n.Node.RPCCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*PingMsg), err
+}
+
+// Handshake is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (n *Node) Handshake(ctx context.Context, in *HandshakeMsg) (resp *HandshakeMsg, err error) {
+	cd := gorums.CallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.Handshake",
+	}
+
+	res, err := n.Node.RPCCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*HandshakeMsg), err
+}
+
+// FetchSnapshot is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (n *Node) FetchSnapshot(ctx context.Context, in *SnapshotRequest) (resp *SnapshotChunk, err error) {
+	cd := gorums.CallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.FetchSnapshot",
+	}
+
+	res, err := n.Node.RPCCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*SnapshotChunk), err
+}
+
 // Hotstuff is the server-side API for the Hotstuff Service
 type Hotstuff interface {
 	Propose(ctx gorums.ServerCtx, request *Proposal)
@@ -189,6 +262,14 @@ type Hotstuff interface {
 	Timeout(ctx gorums.ServerCtx, request *TimeoutMsg)
 	NewView(ctx gorums.ServerCtx, request *SyncInfo)
 	Fetch(ctx gorums.ServerCtx, request *BlockHash) (response *Block, err error)
+	Forward(ctx gorums.ServerCtx, request *Proposal)
+	RequestProposal(ctx gorums.ServerCtx, request *ProposalRequest)
+	Offer(ctx gorums.ServerCtx, request *BlockOffer)
+	Ping(ctx gorums.ServerCtx, request *PingMsg) (response *PingMsg, err error)
+	Handshake(ctx gorums.ServerCtx, request *HandshakeMsg) (response *HandshakeMsg, err error)
+	ProposeChunk(ctx gorums.ServerCtx, request *ProposalChunk)
+	ForwardCommand(ctx gorums.ServerCtx, request *ClientCommand)
+	FetchSnapshot(ctx gorums.ServerCtx, request *SnapshotRequest) (response *SnapshotChunk, err error)
 }
 
 func RegisterHotstuffServer(srv *gorums.Server, impl Hotstuff) {
@@ -216,10 +297,50 @@ func RegisterHotstuffServer(srv *gorums.Server, impl Hotstuff) {
 		req := in.Message.(*BlockHash)
 		defer ctx.Release()
 		resp, err := impl.Fetch(ctx, req)
-		select {
-		case finished <- gorums.WrapMessage(in.Metadata, resp, err):
-		case <-ctx.Done():
-		}
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.Forward", func(ctx gorums.ServerCtx, in *gorums.Message, _ chan<- *gorums.Message) {
+		req := in.Message.(*Proposal)
+		defer ctx.Release()
+		impl.Forward(ctx, req)
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.RequestProposal", func(ctx gorums.ServerCtx, in *gorums.Message, _ chan<- *gorums.Message) {
+		req := in.Message.(*ProposalRequest)
+		defer ctx.Release()
+		impl.RequestProposal(ctx, req)
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.Offer", func(ctx gorums.ServerCtx, in *gorums.Message, _ chan<- *gorums.Message) {
+		req := in.Message.(*BlockOffer)
+		defer ctx.Release()
+		impl.Offer(ctx, req)
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.Ping", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*PingMsg)
+		defer ctx.Release()
+		resp, err := impl.Ping(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.Handshake", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*HandshakeMsg)
+		defer ctx.Release()
+		resp, err := impl.Handshake(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.ProposeChunk", func(ctx gorums.ServerCtx, in *gorums.Message, _ chan<- *gorums.Message) {
+		req := in.Message.(*ProposalChunk)
+		defer ctx.Release()
+		impl.ProposeChunk(ctx, req)
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.ForwardCommand", func(ctx gorums.ServerCtx, in *gorums.Message, _ chan<- *gorums.Message) {
+		req := in.Message.(*ClientCommand)
+		defer ctx.Release()
+		impl.ForwardCommand(ctx, req)
+	})
+	srv.RegisterHandler("hotstuffpb.Hotstuff.FetchSnapshot", func(ctx gorums.ServerCtx, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*SnapshotRequest)
+		defer ctx.Release()
+		resp, err := impl.FetchSnapshot(ctx, req)
+		gorums.SendMessage(ctx, finished, gorums.WrapMessage(in.Metadata, resp, err))
 	})
 }
 
@@ -256,3 +377,45 @@ func (n *Node) NewView(ctx context.Context, in *SyncInfo, opts ...gorums.CallOpt
 
 	n.Node.Unicast(ctx, cd, opts...)
 }
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ emptypb.Empty
+
+// RequestProposal is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (n *Node) RequestProposal(ctx context.Context, in *ProposalRequest, opts ...gorums.CallOption) {
+	cd := gorums.CallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.RequestProposal",
+	}
+
+	n.Node.Unicast(ctx, cd, opts...)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ emptypb.Empty
+
+// Offer is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (n *Node) Offer(ctx context.Context, in *BlockOffer, opts ...gorums.CallOption) {
+	cd := gorums.CallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.Offer",
+	}
+
+	n.Node.Unicast(ctx, cd, opts...)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ emptypb.Empty
+
+// ForwardCommand is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (n *Node) ForwardCommand(ctx context.Context, in *ClientCommand, opts ...gorums.CallOption) {
+	cd := gorums.CallData{
+		Message: in,
+		Method:  "hotstuffpb.Hotstuff.ForwardCommand",
+	}
+
+	n.Node.Unicast(ctx, cd, opts...)
+}
@@ -0,0 +1,47 @@
+package hotstuffpb
+
+import "fmt"
+
+// WireVersion is the version of the wire format that is written by the converters.
+// It is embedded in every serialized certificate, and must be incremented whenever
+// the encoding of a certificate changes in a way that older replicas cannot decode.
+const WireVersion = 1
+
+// UnsupportedVersionError is returned by the converters when a message was encoded
+// with a version of the wire format that cannot be decoded.
+type UnsupportedVersionError struct {
+	Version uint32
+}
+
+func (err UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("unsupported wire format version %d (newest supported version is %d)", err.Version, WireVersion)
+}
+
+// shim upgrades messages encoded with a previous version of the wire format to the current version.
+// A nil function means that the message is encoded the same way in both versions.
+type shim struct {
+	partialCert func(*PartialCert) *PartialCert
+	quorumCert  func(*QuorumCert) *QuorumCert
+	timeoutCert func(*TimeoutCert) *TimeoutCert
+	aggQC       func(*AggQC) *AggQC
+}
+
+// shims contains the previous versions of the wire format that can still be decoded.
+// When WireVersion is incremented, the shim for the previous version should be added here.
+var shims = map[uint32]shim{
+	// version 0 denotes certificates encoded before the version field was introduced.
+	// Apart from the version field, they are identical to version 1.
+	0: {},
+}
+
+// shimFor returns the shim for the given version, or an error if the version is not supported.
+func shimFor(version uint32) (shim, error) {
+	if version == WireVersion {
+		return shim{}, nil
+	}
+	s, ok := shims[version]
+	if !ok {
+		return shim{}, UnsupportedVersionError{Version: version}
+	}
+	return s, nil
+}
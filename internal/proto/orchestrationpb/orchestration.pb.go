@@ -64,6 +64,156 @@ type ReplicaOpts struct {
 	ByzantineStrategy string `protobuf:"bytes,18,opt,name=ByzantineStrategy,proto3" json:"ByzantineStrategy,omitempty"`
 	// A shared random number for seeding random number generators.
 	SharedSeed int64 `protobuf:"varint,20,opt,name=SharedSeed,proto3" json:"SharedSeed,omitempty"`
+	// Determines whether the commands of proposals should be compressed.
+	CompressProposals bool `protobuf:"varint,21,opt,name=CompressProposals,proto3" json:"CompressProposals,omitempty"`
+	// The name of the transport used for replica-to-replica communication.
+	Transport string `protobuf:"bytes,22,opt,name=Transport,proto3" json:"Transport,omitempty"`
+	// The emulated one-way latency of messages sent to each replica.
+	Latencies map[uint32]*durationpb.Duration `protobuf:"bytes,23,rep,name=Latencies,proto3" json:"Latencies,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The maximum jitter added to or subtracted from the emulated latencies.
+	Jitter *durationpb.Duration `protobuf:"bytes,24,opt,name=Jitter,proto3" json:"Jitter,omitempty"`
+	// Determines whether inbound messages from each replica should be rate limited.
+	RateLimitInbound bool `protobuf:"varint,25,opt,name=RateLimitInbound,proto3" json:"RateLimitInbound,omitempty"`
+	// The fanout of the tree used to disseminate proposals. If zero, proposals
+	// are broadcast by the leader.
+	TreeFanout uint32 `protobuf:"varint,26,opt,name=TreeFanout,proto3" json:"TreeFanout,omitempty"`
+	// How often to offer recent blocks to other replicas. Gossip is disabled if
+	// zero.
+	GossipInterval *durationpb.Duration `protobuf:"bytes,27,opt,name=GossipInterval,proto3" json:"GossipInterval,omitempty"`
+	// The address that the replica server should listen on. If empty, the
+	// replica server listens on a random port on all interfaces.
+	ReplicaListenAddress string `protobuf:"bytes,28,opt,name=ReplicaListenAddress,proto3" json:"ReplicaListenAddress,omitempty"`
+	// The address that the client server should listen on. If empty, the
+	// client server listens on a random port on all interfaces.
+	ClientListenAddress string `protobuf:"bytes,29,opt,name=ClientListenAddress,proto3" json:"ClientListenAddress,omitempty"`
+	// The maximum number of concurrent streams on each connection to the
+	// replica server. Unlimited if zero.
+	ReplicaMaxConcurrentStreams uint32 `protobuf:"varint,30,opt,name=ReplicaMaxConcurrentStreams,proto3" json:"ReplicaMaxConcurrentStreams,omitempty"`
+	// The maximum size in bytes of messages received by the replica server.
+	ReplicaMaxMessageSize uint32 `protobuf:"varint,31,opt,name=ReplicaMaxMessageSize,proto3" json:"ReplicaMaxMessageSize,omitempty"`
+	// The maximum number of concurrent streams on each connection to the
+	// client server. Unlimited if zero.
+	ClientMaxConcurrentStreams uint32 `protobuf:"varint,32,opt,name=ClientMaxConcurrentStreams,proto3" json:"ClientMaxConcurrentStreams,omitempty"`
+	// The maximum size in bytes of messages received by the client server.
+	ClientMaxMessageSize uint32 `protobuf:"varint,33,opt,name=ClientMaxMessageSize,proto3" json:"ClientMaxMessageSize,omitempty"`
+	// How often to ping the other replicas. Health checking is disabled if
+	// zero.
+	HealthCheckInterval *durationpb.Duration `protobuf:"bytes,34,opt,name=HealthCheckInterval,proto3" json:"HealthCheckInterval,omitempty"`
+	// The number of consecutive missed pings before a replica is considered
+	// unreachable.
+	HealthCheckThreshold uint32 `protobuf:"varint,35,opt,name=HealthCheckThreshold,proto3" json:"HealthCheckThreshold,omitempty"`
+	// Proposals larger than this number of bytes are sent in chunks. Chunking
+	// is disabled if zero.
+	ProposalChunkSize uint32 `protobuf:"varint,36,opt,name=ProposalChunkSize,proto3" json:"ProposalChunkSize,omitempty"`
+	// The number of messages that can be waiting to be sent to each replica.
+	// Messages are sent directly if zero.
+	SendQueueSize uint32 `protobuf:"varint,37,opt,name=SendQueueSize,proto3" json:"SendQueueSize,omitempty"`
+	// If set, the replica and client servers listen on unix domain sockets in
+	// this directory instead of TCP ports. Only useful when all replicas and
+	// clients run on the same machine.
+	UnixSocketDir string `protobuf:"bytes,38,opt,name=UnixSocketDir,proto3" json:"UnixSocketDir,omitempty"`
+	// The number of recently received messages that are remembered in order to
+	// drop duplicates. Duplicate suppression is disabled if zero.
+	DedupSize uint32 `protobuf:"varint,39,opt,name=DedupSize,proto3" json:"DedupSize,omitempty"`
+	// How long received messages are remembered by the duplicate filter.
+	DedupTTL *durationpb.Duration `protobuf:"bytes,40,opt,name=DedupTTL,proto3" json:"DedupTTL,omitempty"`
+	// Forward commands received from clients to the leader instead of only
+	// buffering them until the replica becomes the leader.
+	ForwardCommands bool `protobuf:"varint,41,opt,name=ForwardCommands,proto3" json:"ForwardCommands,omitempty"`
+	// How long the event loop of the replica processes its queued events, and
+	// its servers wait for the RPCs in progress, when the replica is shut down.
+	DrainTimeout *durationpb.Duration `protobuf:"bytes,42,opt,name=DrainTimeout,proto3" json:"DrainTimeout,omitempty"`
+	// The number of views between the snapshots that are transferred to
+	// replicas that fall too far behind. State transfer is disabled if zero.
+	SnapshotInterval uint32 `protobuf:"varint,43,opt,name=SnapshotInterval,proto3" json:"SnapshotInterval,omitempty"`
+	// The address that the HTTP status server of the replica listens on.
+	// The status server is disabled if empty.
+	StatusListenAddress string `protobuf:"bytes,44,opt,name=StatusListenAddress,proto3" json:"StatusListenAddress,omitempty"`
+	// The address of an external application that executes the committed
+	// commands. The commands are only hashed if empty.
+	ApplicationAddress string `protobuf:"bytes,45,opt,name=ApplicationAddress,proto3" json:"ApplicationAddress,omitempty"`
+	// The application that executes the committed commands in the replica:
+	// "kvstore" runs the key-value store of the kvstore package, whose state
+	// hashes are compared when the replicas are stopped. The commands are only
+	// hashed if empty.
+	Application string `protobuf:"bytes,46,opt,name=Application,proto3" json:"Application,omitempty"`
+	// The directory in which the replicas save durable snapshots of their
+	// state, each in a subdirectory named after its ID, such that restarted
+	// replicas recover from their latest snapshots. Durable snapshots are
+	// disabled if empty.
+	DataDir string `protobuf:"bytes,47,opt,name=DataDir,proto3" json:"DataDir,omitempty"`
+	// The number of views between durable snapshots.
+	DurableSnapshotViews uint32 `protobuf:"varint,48,opt,name=DurableSnapshotViews,proto3" json:"DurableSnapshotViews,omitempty"`
+	// The number of bytes of executed commands between durable snapshots.
+	DurableSnapshotBytes uint64 `protobuf:"varint,49,opt,name=DurableSnapshotBytes,proto3" json:"DurableSnapshotBytes,omitempty"`
+	// The maximum number of commands that wait to be proposed in the command
+	// cache of the replica. Unbounded if zero.
+	CommandCacheSize uint32 `protobuf:"varint,50,opt,name=CommandCacheSize,proto3" json:"CommandCacheSize,omitempty"`
+	// The maximum number of bytes of the commands in the command cache,
+	// including their payloads. Unbounded if zero.
+	CommandCacheBytes uint64 `protobuf:"varint,51,opt,name=CommandCacheBytes,proto3" json:"CommandCacheBytes,omitempty"`
+	// Evict the oldest commands from a full command cache, rather than
+	// rejecting new commands.
+	EvictCommands bool `protobuf:"varint,52,opt,name=EvictCommands,proto3" json:"EvictCommands,omitempty"`
+	// The faults that are injected into the replica, in order of their start
+	// times.
+	Faults []*Fault `protobuf:"bytes,53,rep,name=Faults,proto3" json:"Faults,omitempty"`
+	// The inbound rate limits of message classes, by the name of the class,
+	// which replace the default rate limits. Inbound messages are rate limited
+	// if RateLimitInbound is set or if this map is not empty.
+	InboundRateLimits map[string]*RateLimit `protobuf:"bytes,54,rep,name=InboundRateLimits,proto3" json:"InboundRateLimits,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The number of verified signatures and certificates that the replica
+	// caches. A default size is used if unset, and the cache is disabled if
+	// zero.
+	CryptoCacheSize *uint32 `protobuf:"varint,55,opt,name=CryptoCacheSize,proto3,oneof" json:"CryptoCacheSize,omitempty"`
+	// The seed of the random number generators of the replica, such as those of
+	// the latency emulation and the gossip layer. It is derived from the master
+	// seed of the experiment.
+	Seed int64 `protobuf:"varint,56,opt,name=Seed,proto3" json:"Seed,omitempty"`
+	// The limits of the CPUs that the replica runs on.
+	Resources *Resources `protobuf:"bytes,57,opt,name=Resources,proto3" json:"Resources,omitempty"`
+	// The number of events that each queue of the event loop of the replica
+	// can hold. The default capacity is used if zero.
+	EventQueueCapacity uint32 `protobuf:"varint,58,opt,name=EventQueueCapacity,proto3" json:"EventQueueCapacity,omitempty"`
+	// If true, a panic in an event handler crashes the replica, instead of
+	// being recovered and logged.
+	FatalPanics bool `protobuf:"varint,59,opt,name=FatalPanics,proto3" json:"FatalPanics,omitempty"`
+	// The maximum size in bytes of the commands that are batched together in a
+	// block. Unbounded if zero.
+	BatchBytes uint32 `protobuf:"varint,60,opt,name=BatchBytes,proto3" json:"BatchBytes,omitempty"`
+	// How long a command waits for its batch to fill up before a smaller batch
+	// is proposed. If zero, the commands wait until the batch is full.
+	BatchDelay *durationpb.Duration `protobuf:"bytes,61,opt,name=BatchDelay,proto3" json:"BatchDelay,omitempty"`
+	// The maximum size in bytes of a serialized proposal, including its
+	// certificates. If zero, it is the maximum message size of the replica
+	// server, or unbounded if the proposals are sent in chunks.
+	MaxBlockBytes uint32 `protobuf:"varint,62,opt,name=MaxBlockBytes,proto3" json:"MaxBlockBytes,omitempty"`
+	// The number of committed blocks that may wait to be executed by the
+	// execution pipeline. If zero, the blocks are executed as they are committed.
+	ExecutionLag uint32 `protobuf:"varint,63,opt,name=ExecutionLag,proto3" json:"ExecutionLag,omitempty"`
+	// If true, the replica persists the commands that it admits from clients in
+	// its data directory, and reloads them when it is restarted. DataDir must
+	// be set.
+	PersistCommands bool `protobuf:"varint,64,opt,name=PersistCommands,proto3" json:"PersistCommands,omitempty"`
+	// When the log of the persisted commands is synced: "command", "batched",
+	// or "off". Batched if empty.
+	CommandLogSync string `protobuf:"bytes,65,opt,name=CommandLogSync,proto3" json:"CommandLogSync,omitempty"`
+	// The order in which the commands of different clients are batched:
+	// "fifo" or "fair". FIFO if empty.
+	BatchPolicy string `protobuf:"bytes,66,opt,name=BatchPolicy,proto3" json:"BatchPolicy,omitempty"`
+	// The weights of the clients under the fair batch policy, by client ID.
+	// A client without a weight has weight 1.
+	ClientWeights map[uint32]uint32 `protobuf:"bytes,67,rep,name=ClientWeights,proto3" json:"ClientWeights,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	// The address that the commit stream server of the replica listens on.
+	// The commit stream server is disabled if empty.
+	CommitStreamListenAddress string `protobuf:"bytes,68,opt,name=CommitStreamListenAddress,proto3" json:"CommitStreamListenAddress,omitempty"`
+	// The number of committed blocks that are buffered for each subscriber of
+	// the commit stream. The default is used if zero.
+	CommitStreamBuffer uint32 `protobuf:"varint,69,opt,name=CommitStreamBuffer,proto3" json:"CommitStreamBuffer,omitempty"`
+	// The number of consensus instances, called shards, that the replica runs.
+	// The shards share the connections to the other replicas, while each shard
+	// has its own client server and commands. A single instance is run if zero.
+	Shards uint32 `protobuf:"varint,70,opt,name=Shards,proto3" json:"Shards,omitempty"`
 }
 
 func (x *ReplicaOpts) Reset() {
@@ -224,41 +374,1921 @@ func (x *ReplicaOpts) GetSharedSeed() int64 {
 	return 0
 }
 
+func (x *ReplicaOpts) GetCompressProposals() bool {
+	if x != nil {
+		return x.CompressProposals
+	}
+	return false
+}
+
+func (x *ReplicaOpts) GetTransport() string {
+	if x != nil {
+		return x.Transport
+	}
+	return ""
+}
+
+func (x *ReplicaOpts) GetLatencies() map[uint32]*durationpb.Duration {
+	if x != nil {
+		return x.Latencies
+	}
+	return nil
+}
+
+func (x *ReplicaOpts) GetJitter() *durationpb.Duration {
+	if x != nil {
+		return x.Jitter
+	}
+	return nil
+}
+
+func (x *ReplicaOpts) GetRateLimitInbound() bool {
+	if x != nil {
+		return x.RateLimitInbound
+	}
+	return false
+}
+
+func (x *ReplicaOpts) GetTreeFanout() uint32 {
+	if x != nil {
+		return x.TreeFanout
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetGossipInterval() *durationpb.Duration {
+	if x != nil {
+		return x.GossipInterval
+	}
+	return nil
+}
+
+func (x *ReplicaOpts) GetReplicaListenAddress() string {
+	if x != nil {
+		return x.ReplicaListenAddress
+	}
+	return ""
+}
+
+func (x *ReplicaOpts) GetClientListenAddress() string {
+	if x != nil {
+		return x.ClientListenAddress
+	}
+	return ""
+}
+
+func (x *ReplicaOpts) GetReplicaMaxConcurrentStreams() uint32 {
+	if x != nil {
+		return x.ReplicaMaxConcurrentStreams
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetReplicaMaxMessageSize() uint32 {
+	if x != nil {
+		return x.ReplicaMaxMessageSize
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetClientMaxConcurrentStreams() uint32 {
+	if x != nil {
+		return x.ClientMaxConcurrentStreams
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetClientMaxMessageSize() uint32 {
+	if x != nil {
+		return x.ClientMaxMessageSize
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetHealthCheckInterval() *durationpb.Duration {
+	if x != nil {
+		return x.HealthCheckInterval
+	}
+	return nil
+}
+
+func (x *ReplicaOpts) GetHealthCheckThreshold() uint32 {
+	if x != nil {
+		return x.HealthCheckThreshold
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetProposalChunkSize() uint32 {
+	if x != nil {
+		return x.ProposalChunkSize
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetSendQueueSize() uint32 {
+	if x != nil {
+		return x.SendQueueSize
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetUnixSocketDir() string {
+	if x != nil {
+		return x.UnixSocketDir
+	}
+	return ""
+}
+
+func (x *ReplicaOpts) GetDedupSize() uint32 {
+	if x != nil {
+		return x.DedupSize
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetDedupTTL() *durationpb.Duration {
+	if x != nil {
+		return x.DedupTTL
+	}
+	return nil
+}
+
+func (x *ReplicaOpts) GetForwardCommands() bool {
+	if x != nil {
+		return x.ForwardCommands
+	}
+	return false
+}
+
+func (x *ReplicaOpts) GetDrainTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.DrainTimeout
+	}
+	return nil
+}
+
+func (x *ReplicaOpts) GetSnapshotInterval() uint32 {
+	if x != nil {
+		return x.SnapshotInterval
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetStatusListenAddress() string {
+	if x != nil {
+		return x.StatusListenAddress
+	}
+	return ""
+}
+
+func (x *ReplicaOpts) GetApplicationAddress() string {
+	if x != nil {
+		return x.ApplicationAddress
+	}
+	return ""
+}
+
+func (x *ReplicaOpts) GetApplication() string {
+	if x != nil {
+		return x.Application
+	}
+	return ""
+}
+
+func (x *ReplicaOpts) GetDataDir() string {
+	if x != nil {
+		return x.DataDir
+	}
+	return ""
+}
+
+func (x *ReplicaOpts) GetDurableSnapshotViews() uint32 {
+	if x != nil {
+		return x.DurableSnapshotViews
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetDurableSnapshotBytes() uint64 {
+	if x != nil {
+		return x.DurableSnapshotBytes
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetCommandCacheSize() uint32 {
+	if x != nil {
+		return x.CommandCacheSize
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetCommandCacheBytes() uint64 {
+	if x != nil {
+		return x.CommandCacheBytes
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetEvictCommands() bool {
+	if x != nil {
+		return x.EvictCommands
+	}
+	return false
+}
+
+func (x *ReplicaOpts) GetFaults() []*Fault {
+	if x != nil {
+		return x.Faults
+	}
+	return nil
+}
+
+func (x *ReplicaOpts) GetInboundRateLimits() map[string]*RateLimit {
+	if x != nil {
+		return x.InboundRateLimits
+	}
+	return nil
+}
+
+func (x *ReplicaOpts) GetCryptoCacheSize() uint32 {
+	if x != nil && x.CryptoCacheSize != nil {
+		return *x.CryptoCacheSize
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetResources() *Resources {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *ReplicaOpts) GetEventQueueCapacity() uint32 {
+	if x != nil {
+		return x.EventQueueCapacity
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetFatalPanics() bool {
+	if x != nil {
+		return x.FatalPanics
+	}
+	return false
+}
+
+func (x *ReplicaOpts) GetBatchBytes() uint32 {
+	if x != nil {
+		return x.BatchBytes
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetBatchDelay() *durationpb.Duration {
+	if x != nil {
+		return x.BatchDelay
+	}
+	return nil
+}
+
+func (x *ReplicaOpts) GetMaxBlockBytes() uint32 {
+	if x != nil {
+		return x.MaxBlockBytes
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetExecutionLag() uint32 {
+	if x != nil {
+		return x.ExecutionLag
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetPersistCommands() bool {
+	if x != nil {
+		return x.PersistCommands
+	}
+	return false
+}
+
+func (x *ReplicaOpts) GetCommandLogSync() string {
+	if x != nil {
+		return x.CommandLogSync
+	}
+	return ""
+}
+
+func (x *ReplicaOpts) GetBatchPolicy() string {
+	if x != nil {
+		return x.BatchPolicy
+	}
+	return ""
+}
+
+func (x *ReplicaOpts) GetClientWeights() map[uint32]uint32 {
+	if x != nil {
+		return x.ClientWeights
+	}
+	return nil
+}
+
+func (x *ReplicaOpts) GetCommitStreamListenAddress() string {
+	if x != nil {
+		return x.CommitStreamListenAddress
+	}
+	return ""
+}
+
+func (x *ReplicaOpts) GetCommitStreamBuffer() uint32 {
+	if x != nil {
+		return x.CommitStreamBuffer
+	}
+	return 0
+}
+
+func (x *ReplicaOpts) GetShards() uint32 {
+	if x != nil {
+		return x.Shards
+	}
+	return 0
+}
+
+// RateLimit is the rate limit of a class of inbound messages.
+type RateLimit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The number of messages per second that are allowed.
+	Rate float64 `protobuf:"fixed64,1,opt,name=Rate,proto3" json:"Rate,omitempty"`
+	// The number of messages that can be received in a burst.
+	Burst uint32 `protobuf:"varint,2,opt,name=Burst,proto3" json:"Burst,omitempty"`
+}
+
+func (x *RateLimit) Reset() {
+	*x = RateLimit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimit) ProtoMessage() {}
+
+func (x *RateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimit.ProtoReflect.Descriptor instead.
+func (*RateLimit) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RateLimit) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *RateLimit) GetBurst() uint32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
+// Fault is a fault that is injected into a replica during an experiment.
+type Fault struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// What happens to the replica: "crash" stops the replica and closes its
+	// listeners, "pause" stops the replica from processing events, as if its
+	// process was suspended, and "restart" starts a crashed replica again,
+	// which recovers its state like a replica that restarts after a crash.
+	Action string `protobuf:"bytes,1,opt,name=Action,proto3" json:"Action,omitempty"`
+	// The time from the start of the replica until the fault is injected.
+	Start *durationpb.Duration `protobuf:"bytes,2,opt,name=Start,proto3" json:"Start,omitempty"`
+	// How long a pause lasts, or how long a crashed replica is down before it
+	// is restarted. A crashed replica stays down if zero.
+	Duration *durationpb.Duration `protobuf:"bytes,3,opt,name=Duration,proto3" json:"Duration,omitempty"`
+	// The shard that is paused, if the replica runs several shards. The other
+	// faults affect the whole replica, and cannot be injected into a replica
+	// that runs several shards.
+	Shard uint32 `protobuf:"varint,4,opt,name=Shard,proto3" json:"Shard,omitempty"`
+}
+
+func (x *Fault) Reset() {
+	*x = Fault{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Fault) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fault) ProtoMessage() {}
+
+func (x *Fault) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fault.ProtoReflect.Descriptor instead.
+func (*Fault) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Fault) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *Fault) GetStart() *durationpb.Duration {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *Fault) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *Fault) GetShard() uint32 {
+	if x != nil {
+		return x.Shard
+	}
+	return 0
+}
+
+// Resources are the limits of the CPUs that a replica or client runs on. As
+// the replicas and clients run in the process of their worker, the worker
+// combines the limits of its replicas and clients.
+type Resources struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The CPUs that the process is pinned to. It is not pinned if empty.
+	CPUs []uint32 `protobuf:"varint,1,rep,packed,name=CPUs,proto3" json:"CPUs,omitempty"`
+	// The maximum number of threads that execute Go code simultaneously, that
+	// is, GOMAXPROCS. It is not limited if zero.
+	MaxProcs uint32 `protobuf:"varint,2,opt,name=MaxProcs,proto3" json:"MaxProcs,omitempty"`
+}
+
+func (x *Resources) Reset() {
+	*x = Resources{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Resources) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Resources) ProtoMessage() {}
+
+func (x *Resources) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Resources.ProtoReflect.Descriptor instead.
+func (*Resources) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Resources) GetCPUs() []uint32 {
+	if x != nil {
+		return x.CPUs
+	}
+	return nil
+}
+
+func (x *Resources) GetMaxProcs() uint32 {
+	if x != nil {
+		return x.MaxProcs
+	}
+	return 0
+}
+
 // ReplicaInfo is the information that the replicas need about each other.
 type ReplicaInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
-
-	// The ID of the replica.
-	ID uint32 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	// The IP address of the replica.
-	Address string `protobuf:"bytes,2,opt,name=Address,proto3" json:"Address,omitempty"`
-	// The public key of the replica.
-	PublicKey []byte `protobuf:"bytes,3,opt,name=PublicKey,proto3" json:"PublicKey,omitempty"`
-	// The port that other replicas should connect to.
-	ReplicaPort uint32 `protobuf:"varint,4,opt,name=ReplicaPort,proto3" json:"ReplicaPort,omitempty"`
-	// The port that clients should connect to.
-	ClientPort uint32 `protobuf:"varint,5,opt,name=ClientPort,proto3" json:"ClientPort,omitempty"`
+
+	// The ID of the replica.
+	ID uint32 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	// The IP address or host name of the replica.
+	Address string `protobuf:"bytes,2,opt,name=Address,proto3" json:"Address,omitempty"`
+	// The public key of the replica.
+	PublicKey []byte `protobuf:"bytes,3,opt,name=PublicKey,proto3" json:"PublicKey,omitempty"`
+	// The port that other replicas should connect to.
+	ReplicaPort uint32 `protobuf:"varint,4,opt,name=ReplicaPort,proto3" json:"ReplicaPort,omitempty"`
+	// The port that clients should connect to.
+	ClientPort uint32 `protobuf:"varint,5,opt,name=ClientPort,proto3" json:"ClientPort,omitempty"`
+	// The IP address that clients should connect to, if it is different from
+	// Address.
+	ClientAddress string `protobuf:"bytes,6,opt,name=ClientAddress,proto3" json:"ClientAddress,omitempty"`
+	// The address of the unix domain socket that other replicas should connect
+	// to, if the replica is listening on a unix domain socket.
+	ReplicaSocket string `protobuf:"bytes,7,opt,name=ReplicaSocket,proto3" json:"ReplicaSocket,omitempty"`
+	// The address of the unix domain socket that clients should connect to, if
+	// the client server is listening on a unix domain socket.
+	ClientSocket string `protobuf:"bytes,8,opt,name=ClientSocket,proto3" json:"ClientSocket,omitempty"`
+	// The port of the HTTP status server, if it is enabled.
+	StatusPort uint32 `protobuf:"varint,9,opt,name=StatusPort,proto3" json:"StatusPort,omitempty"`
+	// The port of the commit stream server, if it is enabled.
+	CommitStreamPort uint32 `protobuf:"varint,10,opt,name=CommitStreamPort,proto3" json:"CommitStreamPort,omitempty"`
+	// The ports that clients should connect to for the shards after the first,
+	// if the replica runs several shards.
+	ShardClientPorts []uint32 `protobuf:"varint,11,rep,packed,name=ShardClientPorts,proto3" json:"ShardClientPorts,omitempty"`
+	// The addresses of the unix domain sockets of the client servers of the
+	// shards after the first, if the client servers listen on unix domain
+	// sockets.
+	ShardClientSockets []string `protobuf:"bytes,12,rep,name=ShardClientSockets,proto3" json:"ShardClientSockets,omitempty"`
+}
+
+func (x *ReplicaInfo) Reset() {
+	*x = ReplicaInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplicaInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicaInfo) ProtoMessage() {}
+
+func (x *ReplicaInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicaInfo.ProtoReflect.Descriptor instead.
+func (*ReplicaInfo) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ReplicaInfo) GetID() uint32 {
+	if x != nil {
+		return x.ID
+	}
+	return 0
+}
+
+func (x *ReplicaInfo) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ReplicaInfo) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *ReplicaInfo) GetReplicaPort() uint32 {
+	if x != nil {
+		return x.ReplicaPort
+	}
+	return 0
+}
+
+func (x *ReplicaInfo) GetClientPort() uint32 {
+	if x != nil {
+		return x.ClientPort
+	}
+	return 0
+}
+
+func (x *ReplicaInfo) GetClientAddress() string {
+	if x != nil {
+		return x.ClientAddress
+	}
+	return ""
+}
+
+func (x *ReplicaInfo) GetReplicaSocket() string {
+	if x != nil {
+		return x.ReplicaSocket
+	}
+	return ""
+}
+
+func (x *ReplicaInfo) GetClientSocket() string {
+	if x != nil {
+		return x.ClientSocket
+	}
+	return ""
+}
+
+func (x *ReplicaInfo) GetStatusPort() uint32 {
+	if x != nil {
+		return x.StatusPort
+	}
+	return 0
+}
+
+func (x *ReplicaInfo) GetCommitStreamPort() uint32 {
+	if x != nil {
+		return x.CommitStreamPort
+	}
+	return 0
+}
+
+func (x *ReplicaInfo) GetShardClientPorts() []uint32 {
+	if x != nil {
+		return x.ShardClientPorts
+	}
+	return nil
+}
+
+func (x *ReplicaInfo) GetShardClientSockets() []string {
+	if x != nil {
+		return x.ShardClientSockets
+	}
+	return nil
+}
+
+// LoadStep is a step of the load profile of an open-loop client.
+type LoadStep struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// How long the step lasts.
+	Duration *durationpb.Duration `protobuf:"bytes,1,opt,name=Duration,proto3" json:"Duration,omitempty"`
+	// The rate in commands per second at the end of the step.
+	Rate float64 `protobuf:"fixed64,2,opt,name=Rate,proto3" json:"Rate,omitempty"`
+}
+
+func (x *LoadStep) Reset() {
+	*x = LoadStep{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoadStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadStep) ProtoMessage() {}
+
+func (x *LoadStep) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadStep.ProtoReflect.Descriptor instead.
+func (*LoadStep) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *LoadStep) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *LoadStep) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+type ClientOpts struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The client's ID.
+	ID uint32 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	// Determines whether TLS should be used.
+	UseTLS bool `protobuf:"varint,4,opt,name=UseTLS,proto3" json:"UseTLS,omitempty"`
+	// The maximum number of concurrent requests.
+	MaxConcurrent uint32 `protobuf:"varint,8,opt,name=MaxConcurrent,proto3" json:"MaxConcurrent,omitempty"`
+	// The size in bytes of each command.
+	PayloadSize uint32 `protobuf:"varint,9,opt,name=PayloadSize,proto3" json:"PayloadSize,omitempty"`
+	// The timeout of the initial connection.
+	ConnectTimeout *durationpb.Duration `protobuf:"bytes,10,opt,name=ConnectTimeout,proto3" json:"ConnectTimeout,omitempty"`
+	// The limit on how many commands can be sent per second.
+	RateLimit float64 `protobuf:"fixed64,11,opt,name=RateLimit,proto3" json:"RateLimit,omitempty"`
+	// The amount of commands per second to increase the rate limit by.
+	RateStep float64 `protobuf:"fixed64,12,opt,name=RateStep,proto3" json:"RateStep,omitempty"`
+	// How often to increase the rate limit.
+	RateStepInterval *durationpb.Duration `protobuf:"bytes,13,opt,name=RateStepInterval,proto3" json:"RateStepInterval,omitempty"`
+	// How commands are issued: "closed" (the default) issues a command whenever fewer than MaxConcurrent
+	// commands are in flight, whereas "poisson" and "fixed" issue commands at TargetRate with exponentially
+	// distributed or fixed intervals, and drop commands that would exceed MaxConcurrent.
+	LoadMode string `protobuf:"bytes,14,opt,name=LoadMode,proto3" json:"LoadMode,omitempty"`
+	// The rate in commands per second at which commands are issued in open-loop mode.
+	TargetRate float64 `protobuf:"fixed64,15,opt,name=TargetRate,proto3" json:"TargetRate,omitempty"`
+	// The distribution of the payload sizes: "fixed" (the default) uses PayloadSize, whereas "uniform" and "zipf"
+	// draw the sizes from [PayloadMin, PayloadMax].
+	PayloadDistribution string `protobuf:"bytes,16,opt,name=PayloadDistribution,proto3" json:"PayloadDistribution,omitempty"`
+	// The minimum payload size with the uniform and zipf distributions.
+	PayloadMin uint32 `protobuf:"varint,17,opt,name=PayloadMin,proto3" json:"PayloadMin,omitempty"`
+	// The maximum payload size with the uniform and zipf distributions.
+	PayloadMax uint32 `protobuf:"varint,18,opt,name=PayloadMax,proto3" json:"PayloadMax,omitempty"`
+	// The exponent of the zipf distribution, which must be greater than 1.
+	PayloadZipfS float64 `protobuf:"fixed64,19,opt,name=PayloadZipfS,proto3" json:"PayloadZipfS,omitempty"`
+	// The seed for the payload sizes and contents. Each client adds its ID to the seed.
+	PayloadSeed int64 `protobuf:"varint,20,opt,name=PayloadSeed,proto3" json:"PayloadSeed,omitempty"`
+	// Determines whether the payloads should be compressible rather than random.
+	CompressiblePayload bool `protobuf:"varint,21,opt,name=CompressiblePayload,proto3" json:"CompressiblePayload,omitempty"`
+	// The deadline of the first attempt of a command. Commands are not retried if this is zero.
+	RequestTimeout *durationpb.Duration `protobuf:"bytes,22,opt,name=RequestTimeout,proto3" json:"RequestTimeout,omitempty"`
+	// The maximum deadline of a retry. The deadline is doubled for each retry.
+	MaxRequestTimeout *durationpb.Duration `protobuf:"bytes,23,opt,name=MaxRequestTimeout,proto3" json:"MaxRequestTimeout,omitempty"`
+	// The number of times a command is retried before it is considered failed.
+	MaxRetries uint32 `protobuf:"varint,24,opt,name=MaxRetries,proto3" json:"MaxRetries,omitempty"`
+	// The number of sessions run by each client. Each session has its own client ID, sequence numbers, and connections,
+	// and issues commands as configured by these options. The client IDs are assigned such that the sessions do not overlap.
+	Sessions uint32 `protobuf:"varint,25,opt,name=Sessions,proto3" json:"Sessions,omitempty"`
+	// If non-zero, closed-loop clients adapt the number of concurrent requests between MinConcurrent and MaxConcurrent,
+	// such that the latency stays below TargetLatency.
+	TargetLatency *durationpb.Duration `protobuf:"bytes,26,opt,name=TargetLatency,proto3" json:"TargetLatency,omitempty"`
+	// The minimum number of concurrent requests of the adaptive window.
+	MinConcurrent uint32 `protobuf:"varint,27,opt,name=MinConcurrent,proto3" json:"MinConcurrent,omitempty"`
+	// How many acknowledgements a command needs: "quorum" (the default) waits for f+1 replicas to acknowledge
+	// the same block, whereas "first" trusts the first acknowledgement.
+	AckMode string `protobuf:"bytes,28,opt,name=AckMode,proto3" json:"AckMode,omitempty"`
+	// The trace is replayed this many times faster than it was recorded in the "trace" load mode.
+	TraceSpeed float64 `protobuf:"fixed64,29,opt,name=TraceSpeed,proto3" json:"TraceSpeed,omitempty"`
+	// Determines whether the trace is replayed again when it ends, instead of stopping the client.
+	TraceLoop bool `protobuf:"varint,30,opt,name=TraceLoop,proto3" json:"TraceLoop,omitempty"`
+	// How long a stopping client waits for the commands in flight before it abandons them.
+	// The commands in flight are abandoned immediately if this is zero.
+	DrainTimeout *durationpb.Duration `protobuf:"bytes,31,opt,name=DrainTimeout,proto3" json:"DrainTimeout,omitempty"`
+	// If not empty, the "poisson" and "fixed" load modes issue commands at the rates of these steps instead of
+	// TargetRate, and stop after the last step.
+	LoadProfile []*LoadStep `protobuf:"bytes,32,rep,name=LoadProfile,proto3" json:"LoadProfile,omitempty"`
+	// Determines whether the rate changes linearly during each step of the load profile, from the rate of the
+	// previous step, instead of in steps.
+	LoadRamp bool `protobuf:"varint,33,opt,name=LoadRamp,proto3" json:"LoadRamp,omitempty"`
+	// If greater than one, the clients send up to this many commands in a single message.
+	BatchSize uint32 `protobuf:"varint,34,opt,name=BatchSize,proto3" json:"BatchSize,omitempty"`
+	// How long the first command of a partial batch waits for more commands before the batch is sent.
+	BatchTimeout *durationpb.Duration `protobuf:"bytes,35,opt,name=BatchTimeout,proto3" json:"BatchTimeout,omitempty"`
+	// What the commands do: "opaque" (the default) sends the payloads as they are, whereas "kv" sends
+	// commands of the key-value store, which the payloads are the values of.
+	Workload string `protobuf:"bytes,36,opt,name=Workload,proto3" json:"Workload,omitempty"`
+	// The number of keys of the "kv" workload. 1000 keys are used if zero.
+	KVKeys uint32 `protobuf:"varint,37,opt,name=KVKeys,proto3" json:"KVKeys,omitempty"`
+	// The fraction of the commands of the "kv" workload that are gets.
+	KVReadRatio float64 `protobuf:"fixed64,38,opt,name=KVReadRatio,proto3" json:"KVReadRatio,omitempty"`
+	// The fraction of the commands of the "kv" workload that are deletes. The other commands are puts.
+	KVDeleteRatio float64 `protobuf:"fixed64,39,opt,name=KVDeleteRatio,proto3" json:"KVDeleteRatio,omitempty"`
+	// Every KVCheckpointInterval'th command of the "kv" workload records the state hash of the key-value store.
+	KVCheckpointInterval uint32 `protobuf:"varint,40,opt,name=KVCheckpointInterval,proto3" json:"KVCheckpointInterval,omitempty"`
+	// The seed of the arrival processes of the client. It is derived from the
+	// master seed of the experiment, and each session adds its index.
+	Seed int64 `protobuf:"varint,41,opt,name=Seed,proto3" json:"Seed,omitempty"`
+	// The limits of the CPUs that the client runs on.
+	Resources *Resources `protobuf:"bytes,42,opt,name=Resources,proto3" json:"Resources,omitempty"`
+	// The shard that the client sends its commands to, if the replicas run
+	// several shards.
+	Shard uint32 `protobuf:"varint,43,opt,name=Shard,proto3" json:"Shard,omitempty"`
+}
+
+func (x *ClientOpts) Reset() {
+	*x = ClientOpts{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientOpts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientOpts) ProtoMessage() {}
+
+func (x *ClientOpts) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientOpts.ProtoReflect.Descriptor instead.
+func (*ClientOpts) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ClientOpts) GetID() uint32 {
+	if x != nil {
+		return x.ID
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetUseTLS() bool {
+	if x != nil {
+		return x.UseTLS
+	}
+	return false
+}
+
+func (x *ClientOpts) GetMaxConcurrent() uint32 {
+	if x != nil {
+		return x.MaxConcurrent
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetPayloadSize() uint32 {
+	if x != nil {
+		return x.PayloadSize
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetConnectTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.ConnectTimeout
+	}
+	return nil
+}
+
+func (x *ClientOpts) GetRateLimit() float64 {
+	if x != nil {
+		return x.RateLimit
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetRateStep() float64 {
+	if x != nil {
+		return x.RateStep
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetRateStepInterval() *durationpb.Duration {
+	if x != nil {
+		return x.RateStepInterval
+	}
+	return nil
+}
+
+func (x *ClientOpts) GetLoadMode() string {
+	if x != nil {
+		return x.LoadMode
+	}
+	return ""
+}
+
+func (x *ClientOpts) GetTargetRate() float64 {
+	if x != nil {
+		return x.TargetRate
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetPayloadDistribution() string {
+	if x != nil {
+		return x.PayloadDistribution
+	}
+	return ""
+}
+
+func (x *ClientOpts) GetPayloadMin() uint32 {
+	if x != nil {
+		return x.PayloadMin
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetPayloadMax() uint32 {
+	if x != nil {
+		return x.PayloadMax
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetPayloadZipfS() float64 {
+	if x != nil {
+		return x.PayloadZipfS
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetPayloadSeed() int64 {
+	if x != nil {
+		return x.PayloadSeed
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetCompressiblePayload() bool {
+	if x != nil {
+		return x.CompressiblePayload
+	}
+	return false
+}
+
+func (x *ClientOpts) GetRequestTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.RequestTimeout
+	}
+	return nil
+}
+
+func (x *ClientOpts) GetMaxRequestTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.MaxRequestTimeout
+	}
+	return nil
+}
+
+func (x *ClientOpts) GetMaxRetries() uint32 {
+	if x != nil {
+		return x.MaxRetries
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetSessions() uint32 {
+	if x != nil {
+		return x.Sessions
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetTargetLatency() *durationpb.Duration {
+	if x != nil {
+		return x.TargetLatency
+	}
+	return nil
+}
+
+func (x *ClientOpts) GetMinConcurrent() uint32 {
+	if x != nil {
+		return x.MinConcurrent
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetAckMode() string {
+	if x != nil {
+		return x.AckMode
+	}
+	return ""
+}
+
+func (x *ClientOpts) GetTraceSpeed() float64 {
+	if x != nil {
+		return x.TraceSpeed
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetTraceLoop() bool {
+	if x != nil {
+		return x.TraceLoop
+	}
+	return false
+}
+
+func (x *ClientOpts) GetDrainTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.DrainTimeout
+	}
+	return nil
+}
+
+func (x *ClientOpts) GetLoadProfile() []*LoadStep {
+	if x != nil {
+		return x.LoadProfile
+	}
+	return nil
+}
+
+func (x *ClientOpts) GetLoadRamp() bool {
+	if x != nil {
+		return x.LoadRamp
+	}
+	return false
+}
+
+func (x *ClientOpts) GetBatchSize() uint32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetBatchTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.BatchTimeout
+	}
+	return nil
+}
+
+func (x *ClientOpts) GetWorkload() string {
+	if x != nil {
+		return x.Workload
+	}
+	return ""
+}
+
+func (x *ClientOpts) GetKVKeys() uint32 {
+	if x != nil {
+		return x.KVKeys
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetKVReadRatio() float64 {
+	if x != nil {
+		return x.KVReadRatio
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetKVDeleteRatio() float64 {
+	if x != nil {
+		return x.KVDeleteRatio
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetKVCheckpointInterval() uint32 {
+	if x != nil {
+		return x.KVCheckpointInterval
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *ClientOpts) GetResources() *Resources {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *ClientOpts) GetShard() uint32 {
+	if x != nil {
+		return x.Shard
+	}
+	return 0
+}
+
+// ReplicaConfiguration is a configuration of replicas.
+type ReplicaConfiguration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Replicas map[uint32]*ReplicaInfo `protobuf:"bytes,1,rep,name=Replicas,proto3" json:"Replicas,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *ReplicaConfiguration) Reset() {
+	*x = ReplicaConfiguration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplicaConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicaConfiguration) ProtoMessage() {}
+
+func (x *ReplicaConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicaConfiguration.ProtoReflect.Descriptor instead.
+func (*ReplicaConfiguration) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ReplicaConfiguration) GetReplicas() map[uint32]*ReplicaInfo {
+	if x != nil {
+		return x.Replicas
+	}
+	return nil
+}
+
+type CreateReplicaRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Replicas map[uint32]*ReplicaOpts `protobuf:"bytes,1,rep,name=Replicas,proto3" json:"Replicas,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *CreateReplicaRequest) Reset() {
+	*x = CreateReplicaRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateReplicaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateReplicaRequest) ProtoMessage() {}
+
+func (x *CreateReplicaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateReplicaRequest.ProtoReflect.Descriptor instead.
+func (*CreateReplicaRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CreateReplicaRequest) GetReplicas() map[uint32]*ReplicaOpts {
+	if x != nil {
+		return x.Replicas
+	}
+	return nil
+}
+
+type CreateReplicaResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Replicas map[uint32]*ReplicaInfo `protobuf:"bytes,1,rep,name=Replicas,proto3" json:"Replicas,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *CreateReplicaResponse) Reset() {
+	*x = CreateReplicaResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateReplicaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateReplicaResponse) ProtoMessage() {}
+
+func (x *CreateReplicaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateReplicaResponse.ProtoReflect.Descriptor instead.
+func (*CreateReplicaResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CreateReplicaResponse) GetReplicas() map[uint32]*ReplicaInfo {
+	if x != nil {
+		return x.Replicas
+	}
+	return nil
+}
+
+type StartReplicaRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The replica IDs that should be started.
+	IDs []uint32 `protobuf:"varint,1,rep,packed,name=IDs,proto3" json:"IDs,omitempty"`
+	// The configuration of replicas to connect to.
+	Configuration map[uint32]*ReplicaInfo `protobuf:"bytes,2,rep,name=Configuration,proto3" json:"Configuration,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The resolved configuration of the experiment, which is recorded in the start events of the replicas.
+	ExperimentConfig string `protobuf:"bytes,3,opt,name=ExperimentConfig,proto3" json:"ExperimentConfig,omitempty"`
+	// The master seed of the experiment, which is recorded in the start events of the replicas.
+	MasterSeed int64 `protobuf:"varint,4,opt,name=MasterSeed,proto3" json:"MasterSeed,omitempty"`
+}
+
+func (x *StartReplicaRequest) Reset() {
+	*x = StartReplicaRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StartReplicaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartReplicaRequest) ProtoMessage() {}
+
+func (x *StartReplicaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartReplicaRequest.ProtoReflect.Descriptor instead.
+func (*StartReplicaRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StartReplicaRequest) GetIDs() []uint32 {
+	if x != nil {
+		return x.IDs
+	}
+	return nil
+}
+
+func (x *StartReplicaRequest) GetConfiguration() map[uint32]*ReplicaInfo {
+	if x != nil {
+		return x.Configuration
+	}
+	return nil
+}
+
+func (x *StartReplicaRequest) GetExperimentConfig() string {
+	if x != nil {
+		return x.ExperimentConfig
+	}
+	return ""
+}
+
+func (x *StartReplicaRequest) GetMasterSeed() int64 {
+	if x != nil {
+		return x.MasterSeed
+	}
+	return 0
+}
+
+type StartReplicaResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StartReplicaResponse) Reset() {
+	*x = StartReplicaResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StartReplicaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartReplicaResponse) ProtoMessage() {}
+
+func (x *StartReplicaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartReplicaResponse.ProtoReflect.Descriptor instead.
+func (*StartReplicaResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{11}
+}
+
+type StopReplicaRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IDs []uint32 `protobuf:"varint,1,rep,packed,name=IDs,proto3" json:"IDs,omitempty"`
+}
+
+func (x *StopReplicaRequest) Reset() {
+	*x = StopReplicaRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopReplicaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopReplicaRequest) ProtoMessage() {}
+
+func (x *StopReplicaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopReplicaRequest.ProtoReflect.Descriptor instead.
+func (*StopReplicaRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StopReplicaRequest) GetIDs() []uint32 {
+	if x != nil {
+		return x.IDs
+	}
+	return nil
+}
+
+type StopReplicaResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The hashes of the commands executed by the replicas. The replicas that
+	// crashed during the experiment are left out, since they may have missed
+	// some of the commands.
+	Hashes map[uint32][]byte `protobuf:"bytes,1,rep,name=Hashes,proto3" json:"Hashes,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The state hashes of the applications of the replicas that run the
+	// "kvstore" application.
+	StateHashes map[uint32][]byte `protobuf:"bytes,2,rep,name=StateHashes,proto3" json:"StateHashes,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The replicas that each replica found to be incompatible during the
+	// handshake, such as "replica 3: consensus rules mismatch: ...".
+	Incompatible map[uint32]*IncompatiblePeers `protobuf:"bytes,3,rep,name=Incompatible,proto3" json:"Incompatible,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The hashes of the commands executed by the shards after the first, if
+	// the replicas run several shards.
+	ShardHashes map[uint32]*ShardHashes `protobuf:"bytes,4,rep,name=ShardHashes,proto3" json:"ShardHashes,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *StopReplicaResponse) Reset() {
+	*x = StopReplicaResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopReplicaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopReplicaResponse) ProtoMessage() {}
+
+func (x *StopReplicaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopReplicaResponse.ProtoReflect.Descriptor instead.
+func (*StopReplicaResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StopReplicaResponse) GetHashes() map[uint32][]byte {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+func (x *StopReplicaResponse) GetStateHashes() map[uint32][]byte {
+	if x != nil {
+		return x.StateHashes
+	}
+	return nil
+}
+
+func (x *StopReplicaResponse) GetIncompatible() map[uint32]*IncompatiblePeers {
+	if x != nil {
+		return x.Incompatible
+	}
+	return nil
+}
+
+func (x *StopReplicaResponse) GetShardHashes() map[uint32]*ShardHashes {
+	if x != nil {
+		return x.ShardHashes
+	}
+	return nil
+}
+
+// ShardHashes are the hashes of the commands executed by the shards of a
+// replica, in the order of the shards.
+type ShardHashes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hashes [][]byte `protobuf:"bytes,1,rep,name=Hashes,proto3" json:"Hashes,omitempty"`
+}
+
+func (x *ShardHashes) Reset() {
+	*x = ShardHashes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShardHashes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShardHashes) ProtoMessage() {}
+
+func (x *ShardHashes) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShardHashes.ProtoReflect.Descriptor instead.
+func (*ShardHashes) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ShardHashes) GetHashes() [][]byte {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+// IncompatiblePeers describes the replicas that a replica found to be
+// incompatible.
+type IncompatiblePeers struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Peers []string `protobuf:"bytes,1,rep,name=Peers,proto3" json:"Peers,omitempty"`
+}
+
+func (x *IncompatiblePeers) Reset() {
+	*x = IncompatiblePeers{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IncompatiblePeers) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncompatiblePeers) ProtoMessage() {}
+
+func (x *IncompatiblePeers) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncompatiblePeers.ProtoReflect.Descriptor instead.
+func (*IncompatiblePeers) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *IncompatiblePeers) GetPeers() []string {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+type StartClientRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The clients to create.
+	Clients map[uint32]*ClientOpts `protobuf:"bytes,1,rep,name=Clients,proto3" json:"Clients,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The certificate authority that created the TLS certificates.
+	CertificateAuthority []byte `protobuf:"bytes,7,opt,name=CertificateAuthority,proto3,oneof" json:"CertificateAuthority,omitempty"`
+	// The replicas to connect to.
+	Configuration map[uint32]*ReplicaInfo `protobuf:"bytes,10,rep,name=Configuration,proto3" json:"Configuration,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The workload trace that the clients replay in the "trace" load mode, in the format read by client.ParseTrace.
+	Trace []byte `protobuf:"bytes,11,opt,name=Trace,proto3" json:"Trace,omitempty"`
+	// The resolved configuration of the experiment, which is recorded in the start events of the clients.
+	ExperimentConfig string `protobuf:"bytes,12,opt,name=ExperimentConfig,proto3" json:"ExperimentConfig,omitempty"`
+	// The master seed of the experiment, which is recorded in the start events of the clients.
+	MasterSeed int64 `protobuf:"varint,13,opt,name=MasterSeed,proto3" json:"MasterSeed,omitempty"`
+}
+
+func (x *StartClientRequest) Reset() {
+	*x = StartClientRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StartClientRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartClientRequest) ProtoMessage() {}
+
+func (x *StartClientRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartClientRequest.ProtoReflect.Descriptor instead.
+func (*StartClientRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *StartClientRequest) GetClients() map[uint32]*ClientOpts {
+	if x != nil {
+		return x.Clients
+	}
+	return nil
+}
+
+func (x *StartClientRequest) GetCertificateAuthority() []byte {
+	if x != nil {
+		return x.CertificateAuthority
+	}
+	return nil
+}
+
+func (x *StartClientRequest) GetConfiguration() map[uint32]*ReplicaInfo {
+	if x != nil {
+		return x.Configuration
+	}
+	return nil
+}
+
+func (x *StartClientRequest) GetTrace() []byte {
+	if x != nil {
+		return x.Trace
+	}
+	return nil
+}
+
+func (x *StartClientRequest) GetExperimentConfig() string {
+	if x != nil {
+		return x.ExperimentConfig
+	}
+	return ""
+}
+
+func (x *StartClientRequest) GetMasterSeed() int64 {
+	if x != nil {
+		return x.MasterSeed
+	}
+	return 0
+}
+
+type StartClientResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StartClientResponse) Reset() {
+	*x = StartClientResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StartClientResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartClientResponse) ProtoMessage() {}
+
+func (x *StartClientResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartClientResponse.ProtoReflect.Descriptor instead.
+func (*StartClientResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{17}
+}
+
+type StopClientRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IDs []uint32 `protobuf:"varint,1,rep,packed,name=IDs,proto3" json:"IDs,omitempty"`
+}
+
+func (x *StopClientRequest) Reset() {
+	*x = StopClientRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopClientRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopClientRequest) ProtoMessage() {}
+
+func (x *StopClientRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopClientRequest.ProtoReflect.Descriptor instead.
+func (*StopClientRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *StopClientRequest) GetIDs() []uint32 {
+	if x != nil {
+		return x.IDs
+	}
+	return nil
+}
+
+type StopClientResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StopClientResponse) Reset() {
+	*x = StopClientResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopClientResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopClientResponse) ProtoMessage() {}
+
+func (x *StopClientResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopClientResponse.ProtoReflect.Descriptor instead.
+func (*StopClientResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{19}
+}
+
+type QuitRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *QuitRequest) Reset() {
+	*x = QuitRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QuitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuitRequest) ProtoMessage() {}
+
+func (x *QuitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuitRequest.ProtoReflect.Descriptor instead.
+func (*QuitRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{20}
+}
+
+// ShutdownRequest asks the worker to stop its clients, shut down its replicas
+// gracefully, and exit after it has replied.
+type ShutdownRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 }
 
-func (x *ReplicaInfo) Reset() {
-	*x = ReplicaInfo{}
+func (x *ShutdownRequest) Reset() {
+	*x = ShutdownRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[1]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ReplicaInfo) String() string {
+func (x *ShutdownRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReplicaInfo) ProtoMessage() {}
+func (*ShutdownRequest) ProtoMessage() {}
 
-func (x *ReplicaInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[1]
+func (x *ShutdownRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -269,86 +2299,188 @@ func (x *ReplicaInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReplicaInfo.ProtoReflect.Descriptor instead.
-func (*ReplicaInfo) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use ShutdownRequest.ProtoReflect.Descriptor instead.
+func (*ShutdownRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *ReplicaInfo) GetID() uint32 {
-	if x != nil {
-		return x.ID
+type ShutdownResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The hashes of the commands executed by the replicas.
+	Hashes map[uint32][]byte `protobuf:"bytes,1,rep,name=Hashes,proto3" json:"Hashes,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *ShutdownResponse) Reset() {
+	*x = ShutdownResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return 0
 }
 
-func (x *ReplicaInfo) GetAddress() string {
-	if x != nil {
-		return x.Address
+func (x *ShutdownResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShutdownResponse) ProtoMessage() {}
+
+func (x *ShutdownResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *ReplicaInfo) GetPublicKey() []byte {
+// Deprecated: Use ShutdownResponse.ProtoReflect.Descriptor instead.
+func (*ShutdownResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ShutdownResponse) GetHashes() map[uint32][]byte {
 	if x != nil {
-		return x.PublicKey
+		return x.Hashes
 	}
 	return nil
 }
 
-func (x *ReplicaInfo) GetReplicaPort() uint32 {
+// StartProfileRequest asks the worker to profile its process until its
+// artifacts are collected.
+type StartProfileRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Determines whether a CPU profile should be captured.
+	CPU bool `protobuf:"varint,1,opt,name=CPU,proto3" json:"CPU,omitempty"`
+	// Determines whether a heap profile should be written when the artifacts
+	// are collected.
+	Heap bool `protobuf:"varint,2,opt,name=Heap,proto3" json:"Heap,omitempty"`
+}
+
+func (x *StartProfileRequest) Reset() {
+	*x = StartProfileRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StartProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartProfileRequest) ProtoMessage() {}
+
+func (x *StartProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartProfileRequest.ProtoReflect.Descriptor instead.
+func (*StartProfileRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *StartProfileRequest) GetCPU() bool {
 	if x != nil {
-		return x.ReplicaPort
+		return x.CPU
 	}
-	return 0
+	return false
 }
 
-func (x *ReplicaInfo) GetClientPort() uint32 {
+func (x *StartProfileRequest) GetHeap() bool {
 	if x != nil {
-		return x.ClientPort
+		return x.Heap
 	}
-	return 0
+	return false
 }
 
-type ClientOpts struct {
+type StartProfileResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+}
 
-	// The client's ID.
-	ID uint32 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	// Determines whether TLS should be used.
-	UseTLS bool `protobuf:"varint,4,opt,name=UseTLS,proto3" json:"UseTLS,omitempty"`
-	// The maximum number of concurrent requests.
-	MaxConcurrent uint32 `protobuf:"varint,8,opt,name=MaxConcurrent,proto3" json:"MaxConcurrent,omitempty"`
-	// The size in bytes of each command.
-	PayloadSize uint32 `protobuf:"varint,9,opt,name=PayloadSize,proto3" json:"PayloadSize,omitempty"`
-	// The timeout of the initial connection.
-	ConnectTimeout *durationpb.Duration `protobuf:"bytes,10,opt,name=ConnectTimeout,proto3" json:"ConnectTimeout,omitempty"`
-	// The limit on how many commands can be sent per second.
-	RateLimit float64 `protobuf:"fixed64,11,opt,name=RateLimit,proto3" json:"RateLimit,omitempty"`
-	// The amount of commands per second to increase the rate limit by.
-	RateStep float64 `protobuf:"fixed64,12,opt,name=RateStep,proto3" json:"RateStep,omitempty"`
-	// How often to increase the rate limit.
-	RateStepInterval *durationpb.Duration `protobuf:"bytes,13,opt,name=RateStepInterval,proto3" json:"RateStepInterval,omitempty"`
+func (x *StartProfileResponse) Reset() {
+	*x = StartProfileResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-func (x *ClientOpts) Reset() {
-	*x = ClientOpts{}
+func (x *StartProfileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartProfileResponse) ProtoMessage() {}
+
+func (x *StartProfileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartProfileResponse.ProtoReflect.Descriptor instead.
+func (*StartProfileResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{24}
+}
+
+// CollectArtifactsRequest asks the worker to send its artifacts: the profiles
+// captured since StartProfile, its measurement files, and its log. The worker
+// replies with the chunks of each artifact, followed by a
+// CollectArtifactsResponse.
+type CollectArtifactsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The maximum number of bytes that are sent of each artifact. Larger
+	// artifacts are truncated.
+	MaxSize uint64 `protobuf:"varint,1,opt,name=MaxSize,proto3" json:"MaxSize,omitempty"`
+}
+
+func (x *CollectArtifactsRequest) Reset() {
+	*x = CollectArtifactsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[2]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ClientOpts) String() string {
+func (x *CollectArtifactsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ClientOpts) ProtoMessage() {}
+func (*CollectArtifactsRequest) ProtoMessage() {}
 
-func (x *ClientOpts) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[2]
+func (x *CollectArtifactsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -359,93 +2491,154 @@ func (x *ClientOpts) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ClientOpts.ProtoReflect.Descriptor instead.
-func (*ClientOpts) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use CollectArtifactsRequest.ProtoReflect.Descriptor instead.
+func (*CollectArtifactsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *ClientOpts) GetID() uint32 {
+func (x *CollectArtifactsRequest) GetMaxSize() uint64 {
 	if x != nil {
-		return x.ID
+		return x.MaxSize
 	}
 	return 0
 }
 
-func (x *ClientOpts) GetUseTLS() bool {
-	if x != nil {
-		return x.UseTLS
+// ArtifactChunk is a part of an artifact sent by a worker. The chunks of an
+// artifact are sent in order, and each artifact has at least one chunk.
+type ArtifactChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The file name of the artifact.
+	Name string `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	// The contents of the chunk.
+	Data []byte `protobuf:"bytes,2,opt,name=Data,proto3" json:"Data,omitempty"`
+	// Set on a last chunk without data if the artifact was truncated.
+	Truncated bool `protobuf:"varint,3,opt,name=Truncated,proto3" json:"Truncated,omitempty"`
+}
+
+func (x *ArtifactChunk) Reset() {
+	*x = ArtifactChunk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return false
 }
 
-func (x *ClientOpts) GetMaxConcurrent() uint32 {
-	if x != nil {
-		return x.MaxConcurrent
+func (x *ArtifactChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArtifactChunk) ProtoMessage() {}
+
+func (x *ArtifactChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *ClientOpts) GetPayloadSize() uint32 {
+// Deprecated: Use ArtifactChunk.ProtoReflect.Descriptor instead.
+func (*ArtifactChunk) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ArtifactChunk) GetName() string {
 	if x != nil {
-		return x.PayloadSize
+		return x.Name
 	}
-	return 0
+	return ""
 }
 
-func (x *ClientOpts) GetConnectTimeout() *durationpb.Duration {
+func (x *ArtifactChunk) GetData() []byte {
 	if x != nil {
-		return x.ConnectTimeout
+		return x.Data
 	}
 	return nil
 }
 
-func (x *ClientOpts) GetRateLimit() float64 {
+func (x *ArtifactChunk) GetTruncated() bool {
 	if x != nil {
-		return x.RateLimit
+		return x.Truncated
 	}
-	return 0
+	return false
 }
 
-func (x *ClientOpts) GetRateStep() float64 {
-	if x != nil {
-		return x.RateStep
+type CollectArtifactsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CollectArtifactsResponse) Reset() {
+	*x = CollectArtifactsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return 0
 }
 
-func (x *ClientOpts) GetRateStepInterval() *durationpb.Duration {
-	if x != nil {
-		return x.RateStepInterval
+func (x *CollectArtifactsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectArtifactsResponse) ProtoMessage() {}
+
+func (x *CollectArtifactsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-// ReplicaConfiguration is a configuration of replicas.
-type ReplicaConfiguration struct {
+// Deprecated: Use CollectArtifactsResponse.ProtoReflect.Descriptor instead.
+func (*CollectArtifactsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{27}
+}
+
+// ResetRequest asks the worker to tear down the replicas and clients of the
+// previous run, and to prepare for another run. The worker replies when it is
+// ready for the next run.
+type ResetRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Replicas map[uint32]*ReplicaInfo `protobuf:"bytes,1,rep,name=Replicas,proto3" json:"Replicas,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The name of the next run, such as the directory of the run in a sweep,
+	// which the worker uses to keep the measurements of the runs apart.
+	Run string `protobuf:"bytes,1,opt,name=Run,proto3" json:"Run,omitempty"`
 }
 
-func (x *ReplicaConfiguration) Reset() {
-	*x = ReplicaConfiguration{}
+func (x *ResetRequest) Reset() {
+	*x = ResetRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[3]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[28]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ReplicaConfiguration) String() string {
+func (x *ResetRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReplicaConfiguration) ProtoMessage() {}
+func (*ResetRequest) ProtoMessage() {}
 
-func (x *ReplicaConfiguration) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[3]
+func (x *ResetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[28]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -455,44 +2648,42 @@ func (x *ReplicaConfiguration) ProtoReflect() protoreflect.Message {
 	}
 	return mi.MessageOf(x)
 }
-
-// Deprecated: Use ReplicaConfiguration.ProtoReflect.Descriptor instead.
-func (*ReplicaConfiguration) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{3}
+
+// Deprecated: Use ResetRequest.ProtoReflect.Descriptor instead.
+func (*ResetRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *ReplicaConfiguration) GetReplicas() map[uint32]*ReplicaInfo {
+func (x *ResetRequest) GetRun() string {
 	if x != nil {
-		return x.Replicas
+		return x.Run
 	}
-	return nil
+	return ""
 }
 
-type CreateReplicaRequest struct {
+type ResetResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
-
-	Replicas map[uint32]*ReplicaOpts `protobuf:"bytes,1,rep,name=Replicas,proto3" json:"Replicas,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
-func (x *CreateReplicaRequest) Reset() {
-	*x = CreateReplicaRequest{}
+func (x *ResetResponse) Reset() {
+	*x = ResetResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[4]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[29]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *CreateReplicaRequest) String() string {
+func (x *ResetResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateReplicaRequest) ProtoMessage() {}
+func (*ResetResponse) ProtoMessage() {}
 
-func (x *CreateReplicaRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[4]
+func (x *ResetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[29]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -503,43 +2694,36 @@ func (x *CreateReplicaRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateReplicaRequest.ProtoReflect.Descriptor instead.
-func (*CreateReplicaRequest) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{4}
-}
-
-func (x *CreateReplicaRequest) GetReplicas() map[uint32]*ReplicaOpts {
-	if x != nil {
-		return x.Replicas
-	}
-	return nil
+// Deprecated: Use ResetResponse.ProtoReflect.Descriptor instead.
+func (*ResetResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{29}
 }
 
-type CreateReplicaResponse struct {
+// ProgressRequest asks the worker for the progress of its replicas and
+// clients. The controller sends it periodically while the experiment runs.
+type ProgressRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
-
-	Replicas map[uint32]*ReplicaInfo `protobuf:"bytes,1,rep,name=Replicas,proto3" json:"Replicas,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
-func (x *CreateReplicaResponse) Reset() {
-	*x = CreateReplicaResponse{}
+func (x *ProgressRequest) Reset() {
+	*x = ProgressRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[5]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[30]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *CreateReplicaResponse) String() string {
+func (x *ProgressRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateReplicaResponse) ProtoMessage() {}
+func (*ProgressRequest) ProtoMessage() {}
 
-func (x *CreateReplicaResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[5]
+func (x *ProgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[30]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -550,46 +2734,40 @@ func (x *CreateReplicaResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateReplicaResponse.ProtoReflect.Descriptor instead.
-func (*CreateReplicaResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{5}
-}
-
-func (x *CreateReplicaResponse) GetReplicas() map[uint32]*ReplicaInfo {
-	if x != nil {
-		return x.Replicas
-	}
-	return nil
+// Deprecated: Use ProgressRequest.ProtoReflect.Descriptor instead.
+func (*ProgressRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{30}
 }
 
-type StartReplicaRequest struct {
+// ReplicaProgress is the progress of a replica.
+type ReplicaProgress struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The replica IDs that should be started.
-	IDs []uint32 `protobuf:"varint,1,rep,packed,name=IDs,proto3" json:"IDs,omitempty"`
-	// The configuration of replicas to connect to.
-	Configuration map[uint32]*ReplicaInfo `protobuf:"bytes,2,rep,name=Configuration,proto3" json:"Configuration,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The current view of the replica.
+	View uint64 `protobuf:"varint,1,opt,name=View,proto3" json:"View,omitempty"`
+	// The number of commands committed by the replica.
+	Committed uint64 `protobuf:"varint,2,opt,name=Committed,proto3" json:"Committed,omitempty"`
 }
 
-func (x *StartReplicaRequest) Reset() {
-	*x = StartReplicaRequest{}
+func (x *ReplicaProgress) Reset() {
+	*x = ReplicaProgress{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[6]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[31]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StartReplicaRequest) String() string {
+func (x *ReplicaProgress) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StartReplicaRequest) ProtoMessage() {}
+func (*ReplicaProgress) ProtoMessage() {}
 
-func (x *StartReplicaRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[6]
+func (x *ReplicaProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[31]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -600,48 +2778,53 @@ func (x *StartReplicaRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StartReplicaRequest.ProtoReflect.Descriptor instead.
-func (*StartReplicaRequest) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use ReplicaProgress.ProtoReflect.Descriptor instead.
+func (*ReplicaProgress) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *StartReplicaRequest) GetIDs() []uint32 {
+func (x *ReplicaProgress) GetView() uint64 {
 	if x != nil {
-		return x.IDs
+		return x.View
 	}
-	return nil
+	return 0
 }
 
-func (x *StartReplicaRequest) GetConfiguration() map[uint32]*ReplicaInfo {
+func (x *ReplicaProgress) GetCommitted() uint64 {
 	if x != nil {
-		return x.Configuration
+		return x.Committed
 	}
-	return nil
+	return 0
 }
 
-type StartReplicaResponse struct {
+type ProgressResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	// The progress of each replica of the worker.
+	Replicas map[uint32]*ReplicaProgress `protobuf:"bytes,1,rep,name=Replicas,proto3" json:"Replicas,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The number of commands completed by each client of the worker.
+	Clients map[uint32]uint64 `protobuf:"bytes,2,rep,name=Clients,proto3" json:"Clients,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
 }
 
-func (x *StartReplicaResponse) Reset() {
-	*x = StartReplicaResponse{}
+func (x *ProgressResponse) Reset() {
+	*x = ProgressResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[7]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[32]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StartReplicaResponse) String() string {
+func (x *ProgressResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StartReplicaResponse) ProtoMessage() {}
+func (*ProgressResponse) ProtoMessage() {}
 
-func (x *StartReplicaResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[7]
+func (x *ProgressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[32]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -652,36 +2835,58 @@ func (x *StartReplicaResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StartReplicaResponse.ProtoReflect.Descriptor instead.
-func (*StartReplicaResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use ProgressResponse.ProtoReflect.Descriptor instead.
+func (*ProgressResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{32}
 }
 
-type StopReplicaRequest struct {
+func (x *ProgressResponse) GetReplicas() map[uint32]*ReplicaProgress {
+	if x != nil {
+		return x.Replicas
+	}
+	return nil
+}
+
+func (x *ProgressResponse) GetClients() map[uint32]uint64 {
+	if x != nil {
+		return x.Clients
+	}
+	return nil
+}
+
+// LinkEmulation is an emulated link from the host of a worker to another host.
+type LinkEmulation struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	IDs []uint32 `protobuf:"varint,1,rep,packed,name=IDs,proto3" json:"IDs,omitempty"`
+	// The name of the other host, which the worker resolves to its addresses.
+	Host string `protobuf:"bytes,1,opt,name=Host,proto3" json:"Host,omitempty"`
+	// The one-way latency of the packets sent to the host.
+	Latency *durationpb.Duration `protobuf:"bytes,2,opt,name=Latency,proto3" json:"Latency,omitempty"`
+	// The maximum random variation of the latency.
+	Jitter *durationpb.Duration `protobuf:"bytes,3,opt,name=Jitter,proto3" json:"Jitter,omitempty"`
+	// The percentage of the packets sent to the host that are dropped.
+	Loss float64 `protobuf:"fixed64,4,opt,name=Loss,proto3" json:"Loss,omitempty"`
 }
 
-func (x *StopReplicaRequest) Reset() {
-	*x = StopReplicaRequest{}
+func (x *LinkEmulation) Reset() {
+	*x = LinkEmulation{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[8]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[33]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StopReplicaRequest) String() string {
+func (x *LinkEmulation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopReplicaRequest) ProtoMessage() {}
+func (*LinkEmulation) ProtoMessage() {}
 
-func (x *StopReplicaRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[8]
+func (x *LinkEmulation) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[33]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -692,43 +2897,70 @@ func (x *StopReplicaRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopReplicaRequest.ProtoReflect.Descriptor instead.
-func (*StopReplicaRequest) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use LinkEmulation.ProtoReflect.Descriptor instead.
+func (*LinkEmulation) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *StopReplicaRequest) GetIDs() []uint32 {
+func (x *LinkEmulation) GetHost() string {
 	if x != nil {
-		return x.IDs
+		return x.Host
+	}
+	return ""
+}
+
+func (x *LinkEmulation) GetLatency() *durationpb.Duration {
+	if x != nil {
+		return x.Latency
 	}
 	return nil
 }
 
-type StopReplicaResponse struct {
+func (x *LinkEmulation) GetJitter() *durationpb.Duration {
+	if x != nil {
+		return x.Jitter
+	}
+	return nil
+}
+
+func (x *LinkEmulation) GetLoss() float64 {
+	if x != nil {
+		return x.Loss
+	}
+	return 0
+}
+
+// EmulateNetworkRequest asks the worker to emulate the links from its host to
+// the other hosts with the netem queueing discipline of tc. The emulated links
+// are removed by ClearNetworkRequest, and when the worker is reset or quits.
+type EmulateNetworkRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Hashes map[uint32][]byte `protobuf:"bytes,1,rep,name=Hashes,proto3" json:"Hashes,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The network interface that the links are emulated on. If empty, the
+	// interface that the packets to the first host are routed through is used.
+	Interface string           `protobuf:"bytes,1,opt,name=Interface,proto3" json:"Interface,omitempty"`
+	Links     []*LinkEmulation `protobuf:"bytes,2,rep,name=Links,proto3" json:"Links,omitempty"`
 }
 
-func (x *StopReplicaResponse) Reset() {
-	*x = StopReplicaResponse{}
+func (x *EmulateNetworkRequest) Reset() {
+	*x = EmulateNetworkRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[9]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[34]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StopReplicaResponse) String() string {
+func (x *EmulateNetworkRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopReplicaResponse) ProtoMessage() {}
+func (*EmulateNetworkRequest) ProtoMessage() {}
 
-func (x *StopReplicaResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[9]
+func (x *EmulateNetworkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[34]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -739,48 +2971,48 @@ func (x *StopReplicaResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopReplicaResponse.ProtoReflect.Descriptor instead.
-func (*StopReplicaResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use EmulateNetworkRequest.ProtoReflect.Descriptor instead.
+func (*EmulateNetworkRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *StopReplicaResponse) GetHashes() map[uint32][]byte {
+func (x *EmulateNetworkRequest) GetInterface() string {
 	if x != nil {
-		return x.Hashes
+		return x.Interface
+	}
+	return ""
+}
+
+func (x *EmulateNetworkRequest) GetLinks() []*LinkEmulation {
+	if x != nil {
+		return x.Links
 	}
 	return nil
 }
 
-type StartClientRequest struct {
+type EmulateNetworkResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
-
-	// The clients to create.
-	Clients map[uint32]*ClientOpts `protobuf:"bytes,1,rep,name=Clients,proto3" json:"Clients,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	// The certificate authority that created the TLS certificates.
-	CertificateAuthority []byte `protobuf:"bytes,7,opt,name=CertificateAuthority,proto3,oneof" json:"CertificateAuthority,omitempty"`
-	// The replicas to connect to.
-	Configuration map[uint32]*ReplicaInfo `protobuf:"bytes,10,rep,name=Configuration,proto3" json:"Configuration,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
-func (x *StartClientRequest) Reset() {
-	*x = StartClientRequest{}
+func (x *EmulateNetworkResponse) Reset() {
+	*x = EmulateNetworkResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[10]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[35]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StartClientRequest) String() string {
+func (x *EmulateNetworkResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StartClientRequest) ProtoMessage() {}
+func (*EmulateNetworkResponse) ProtoMessage() {}
 
-func (x *StartClientRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[10]
+func (x *EmulateNetworkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[35]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -791,55 +3023,38 @@ func (x *StartClientRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StartClientRequest.ProtoReflect.Descriptor instead.
-func (*StartClientRequest) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{10}
-}
-
-func (x *StartClientRequest) GetClients() map[uint32]*ClientOpts {
-	if x != nil {
-		return x.Clients
-	}
-	return nil
-}
-
-func (x *StartClientRequest) GetCertificateAuthority() []byte {
-	if x != nil {
-		return x.CertificateAuthority
-	}
-	return nil
-}
-
-func (x *StartClientRequest) GetConfiguration() map[uint32]*ReplicaInfo {
-	if x != nil {
-		return x.Configuration
-	}
-	return nil
+// Deprecated: Use EmulateNetworkResponse.ProtoReflect.Descriptor instead.
+func (*EmulateNetworkResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{35}
 }
 
-type StartClientResponse struct {
+// VerifyNetworkRequest asks the worker to measure the round-trip times to the
+// hosts of its emulated links with ping. It is sent when the links of all
+// hosts are emulated, and the worker records the round-trip times in its
+// measurements.
+type VerifyNetworkRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 }
 
-func (x *StartClientResponse) Reset() {
-	*x = StartClientResponse{}
+func (x *VerifyNetworkRequest) Reset() {
+	*x = VerifyNetworkRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[11]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[36]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StartClientResponse) String() string {
+func (x *VerifyNetworkRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StartClientResponse) ProtoMessage() {}
+func (*VerifyNetworkRequest) ProtoMessage() {}
 
-func (x *StartClientResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[11]
+func (x *VerifyNetworkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[36]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -850,36 +3065,37 @@ func (x *StartClientResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StartClientResponse.ProtoReflect.Descriptor instead.
-func (*StartClientResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use VerifyNetworkRequest.ProtoReflect.Descriptor instead.
+func (*VerifyNetworkRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{36}
 }
 
-type StopClientRequest struct {
+type VerifyNetworkResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	IDs []uint32 `protobuf:"varint,1,rep,packed,name=IDs,proto3" json:"IDs,omitempty"`
+	// The average round-trip time to each host.
+	RoundTripTimes map[string]*durationpb.Duration `protobuf:"bytes,1,rep,name=RoundTripTimes,proto3" json:"RoundTripTimes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
-func (x *StopClientRequest) Reset() {
-	*x = StopClientRequest{}
+func (x *VerifyNetworkResponse) Reset() {
+	*x = VerifyNetworkResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[12]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[37]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StopClientRequest) String() string {
+func (x *VerifyNetworkResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopClientRequest) ProtoMessage() {}
+func (*VerifyNetworkResponse) ProtoMessage() {}
 
-func (x *StopClientRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[12]
+func (x *VerifyNetworkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[37]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -890,41 +3106,41 @@ func (x *StopClientRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopClientRequest.ProtoReflect.Descriptor instead.
-func (*StopClientRequest) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use VerifyNetworkResponse.ProtoReflect.Descriptor instead.
+func (*VerifyNetworkResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *StopClientRequest) GetIDs() []uint32 {
+func (x *VerifyNetworkResponse) GetRoundTripTimes() map[string]*durationpb.Duration {
 	if x != nil {
-		return x.IDs
+		return x.RoundTripTimes
 	}
 	return nil
 }
 
-type StopClientResponse struct {
+type ClearNetworkRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 }
 
-func (x *StopClientResponse) Reset() {
-	*x = StopClientResponse{}
+func (x *ClearNetworkRequest) Reset() {
+	*x = ClearNetworkRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[13]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[38]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StopClientResponse) String() string {
+func (x *ClearNetworkRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopClientResponse) ProtoMessage() {}
+func (*ClearNetworkRequest) ProtoMessage() {}
 
-func (x *StopClientResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[13]
+func (x *ClearNetworkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[38]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -935,34 +3151,34 @@ func (x *StopClientResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopClientResponse.ProtoReflect.Descriptor instead.
-func (*StopClientResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use ClearNetworkRequest.ProtoReflect.Descriptor instead.
+func (*ClearNetworkRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{38}
 }
 
-type QuitRequest struct {
+type ClearNetworkResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 }
 
-func (x *QuitRequest) Reset() {
-	*x = QuitRequest{}
+func (x *ClearNetworkResponse) Reset() {
+	*x = ClearNetworkResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[14]
+		mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[39]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *QuitRequest) String() string {
+func (x *ClearNetworkResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QuitRequest) ProtoMessage() {}
+func (*ClearNetworkResponse) ProtoMessage() {}
 
-func (x *QuitRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[14]
+func (x *ClearNetworkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[39]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -973,9 +3189,9 @@ func (x *QuitRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QuitRequest.ProtoReflect.Descriptor instead.
-func (*QuitRequest) Descriptor() ([]byte, []int) {
-	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use ClearNetworkResponse.ProtoReflect.Descriptor instead.
+func (*ClearNetworkResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP(), []int{39}
 }
 
 var File_internal_proto_orchestrationpb_orchestration_proto protoreflect.FileDescriptor
@@ -987,7 +3203,7 @@ var file_internal_proto_orchestrationpb_orchestration_proto_rawDesc = []byte{
 	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0f, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74,
 	0x69, 0x6f, 0x6e, 0x70, 0x62, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
 	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x9d, 0x06, 0x0a, 0x0b, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x9c, 0x1b, 0x0a, 0x0b, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63,
 	0x61, 0x4f, 0x70, 0x74, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x0d, 0x52, 0x02, 0x49, 0x44, 0x12, 0x1e, 0x0a, 0x0a, 0x50, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65,
 	0x4b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x50, 0x72, 0x69, 0x76, 0x61,
@@ -1033,145 +3249,570 @@ var file_internal_proto_orchestrationpb_orchestration_proto_rawDesc = []byte{
 	0x18, 0x12, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x42, 0x79, 0x7a, 0x61, 0x6e, 0x74, 0x69, 0x6e,
 	0x65, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x53, 0x68, 0x61,
 	0x72, 0x65, 0x64, 0x53, 0x65, 0x65, 0x64, 0x18, 0x14, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x53,
-	0x68, 0x61, 0x72, 0x65, 0x64, 0x53, 0x65, 0x65, 0x64, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x43, 0x65,
-	0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x43, 0x65,
-	0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x42, 0x17, 0x0a, 0x15,
-	0x5f, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x41, 0x75, 0x74, 0x68,
-	0x6f, 0x72, 0x69, 0x74, 0x79, 0x22, 0x97, 0x01, 0x0a, 0x0b, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63,
-	0x61, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x02, 0x49, 0x44, 0x12, 0x18, 0x0a, 0x07, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12,
-	0x1c, 0x0a, 0x09, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x09, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x20, 0x0a,
-	0x0b, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x0d, 0x52, 0x0b, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x50, 0x6f, 0x72, 0x74, 0x12,
-	0x1e, 0x0a, 0x0a, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x05, 0x20,
-	0x01, 0x28, 0x0d, 0x52, 0x0a, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x22,
-	0xc0, 0x02, 0x0a, 0x0a, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4f, 0x70, 0x74, 0x73, 0x12, 0x0e,
-	0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x49, 0x44, 0x12, 0x16,
-	0x0a, 0x06, 0x55, 0x73, 0x65, 0x54, 0x4c, 0x53, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
-	0x55, 0x73, 0x65, 0x54, 0x4c, 0x53, 0x12, 0x24, 0x0a, 0x0d, 0x4d, 0x61, 0x78, 0x43, 0x6f, 0x6e,
-	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x4d,
-	0x61, 0x78, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x20, 0x0a, 0x0b,
-	0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x0b, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x41,
-	0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
-	0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x52, 0x0e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75,
-	0x74, 0x12, 0x1c, 0x0a, 0x09, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x0b,
-	0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12,
-	0x1a, 0x0a, 0x08, 0x52, 0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x70, 0x18, 0x0c, 0x20, 0x01, 0x28,
-	0x01, 0x52, 0x08, 0x52, 0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x70, 0x12, 0x45, 0x0a, 0x10, 0x52,
-	0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x70, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18,
-	0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x68, 0x61, 0x72, 0x65, 0x64, 0x53, 0x65, 0x65, 0x64, 0x12, 0x2c, 0x0a, 0x11, 0x43, 0x6f, 0x6d,
+	0x70, 0x72, 0x65, 0x73, 0x73, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x73, 0x18, 0x15,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x50, 0x72,
+	0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x70, 0x6f, 0x72, 0x74, 0x18, 0x16, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x49, 0x0a, 0x09, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x69,
+	0x65, 0x73, 0x18, 0x17, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65,
+	0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69,
+	0x63, 0x61, 0x4f, 0x70, 0x74, 0x73, 0x2e, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73,
+	0x12, 0x31, 0x0a, 0x06, 0x4a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x18, 0x18, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x4a, 0x69, 0x74,
+	0x74, 0x65, 0x72, 0x12, 0x2a, 0x0a, 0x10, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74,
+	0x49, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x19, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x52,
+	0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x49, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x12,
+	0x1e, 0x0a, 0x0a, 0x54, 0x72, 0x65, 0x65, 0x46, 0x61, 0x6e, 0x6f, 0x75, 0x74, 0x18, 0x1a, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0a, 0x54, 0x72, 0x65, 0x65, 0x46, 0x61, 0x6e, 0x6f, 0x75, 0x74, 0x12,
+	0x41, 0x0a, 0x0e, 0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61,
+	0x6c, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x0e, 0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76,
+	0x61, 0x6c, 0x12, 0x32, 0x0a, 0x14, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x4c, 0x69, 0x73,
+	0x74, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x14, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x41,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x30, 0x0a, 0x13, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x1d, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x13, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x65,
+	0x6e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x40, 0x0a, 0x1b, 0x52, 0x65, 0x70, 0x6c,
+	0x69, 0x63, 0x61, 0x4d, 0x61, 0x78, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x1b, 0x52,
+	0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x4d, 0x61, 0x78, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x6e, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x12, 0x34, 0x0a, 0x15, 0x52, 0x65,
+	0x70, 0x6c, 0x69, 0x63, 0x61, 0x4d, 0x61, 0x78, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x53,
+	0x69, 0x7a, 0x65, 0x18, 0x1f, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x15, 0x52, 0x65, 0x70, 0x6c, 0x69,
+	0x63, 0x61, 0x4d, 0x61, 0x78, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x3e, 0x0a, 0x1a, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x61, 0x78, 0x43, 0x6f, 0x6e,
+	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x18, 0x20,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x1a, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x61, 0x78, 0x43,
+	0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73,
+	0x12, 0x32, 0x0a, 0x14, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x61, 0x78, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x21, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x61, 0x78, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x53, 0x69, 0x7a, 0x65, 0x12, 0x4b, 0x0a, 0x13, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x22, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x13, 0x48, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61,
+	0x6c, 0x12, 0x32, 0x0a, 0x14, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x23, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x14, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x54, 0x68, 0x72, 0x65,
+	0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x2c, 0x0a, 0x11, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61,
+	0x6c, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x24, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x11, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x53,
+	0x69, 0x7a, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x53, 0x65, 0x6e, 0x64, 0x51, 0x75, 0x65, 0x75, 0x65,
+	0x53, 0x69, 0x7a, 0x65, 0x18, 0x25, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x53, 0x65, 0x6e, 0x64,
+	0x51, 0x75, 0x65, 0x75, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x55, 0x6e, 0x69,
+	0x78, 0x53, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x44, 0x69, 0x72, 0x18, 0x26, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0d, 0x55, 0x6e, 0x69, 0x78, 0x53, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x44, 0x69, 0x72, 0x12,
+	0x1c, 0x0a, 0x09, 0x44, 0x65, 0x64, 0x75, 0x70, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x27, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x09, 0x44, 0x65, 0x64, 0x75, 0x70, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x35, 0x0a,
+	0x08, 0x44, 0x65, 0x64, 0x75, 0x70, 0x54, 0x54, 0x4c, 0x18, 0x28, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x44, 0x65, 0x64, 0x75,
+	0x70, 0x54, 0x54, 0x4c, 0x12, 0x28, 0x0a, 0x0f, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x29, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x46,
+	0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x3d,
+	0x0a, 0x0c, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x2a,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x0c, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x2a, 0x0a,
+	0x10, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61,
+	0x6c, 0x18, 0x2b, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x10, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
+	0x74, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x30, 0x0a, 0x13, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x18, 0x2c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x4c, 0x69,
+	0x73, 0x74, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x2e, 0x0a, 0x12, 0x41,
+	0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x18, 0x2d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x41, 0x70, 0x70, 0x6c, 0x69, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x41,
+	0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x2e, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x41, 0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a,
+	0x07, 0x44, 0x61, 0x74, 0x61, 0x44, 0x69, 0x72, 0x18, 0x2f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x44, 0x61, 0x74, 0x61, 0x44, 0x69, 0x72, 0x12, 0x32, 0x0a, 0x14, 0x44, 0x75, 0x72, 0x61, 0x62,
+	0x6c, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x56, 0x69, 0x65, 0x77, 0x73, 0x18,
+	0x30, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x44, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x53, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x56, 0x69, 0x65, 0x77, 0x73, 0x12, 0x32, 0x0a, 0x14, 0x44,
+	0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x42, 0x79,
+	0x74, 0x65, 0x73, 0x18, 0x31, 0x20, 0x01, 0x28, 0x04, 0x52, 0x14, 0x44, 0x75, 0x72, 0x61, 0x62,
+	0x6c, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12,
+	0x2a, 0x0a, 0x10, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53,
+	0x69, 0x7a, 0x65, 0x18, 0x32, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x10, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x2c, 0x0a, 0x11, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x43, 0x61, 0x63, 0x68, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73,
+	0x18, 0x33, 0x20, 0x01, 0x28, 0x04, 0x52, 0x11, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x43,
+	0x61, 0x63, 0x68, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x45, 0x76, 0x69,
+	0x63, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x34, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0d, 0x45, 0x76, 0x69, 0x63, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12,
+	0x2e, 0x0a, 0x06, 0x46, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x35, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x16, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70,
+	0x62, 0x2e, 0x46, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x06, 0x46, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x12,
+	0x61, 0x0a, 0x11, 0x49, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69,
+	0x6d, 0x69, 0x74, 0x73, 0x18, 0x36, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x33, 0x2e, 0x6f, 0x72, 0x63,
+	0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x70,
+	0x6c, 0x69, 0x63, 0x61, 0x4f, 0x70, 0x74, 0x73, 0x2e, 0x49, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64,
+	0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x11, 0x49, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69,
+	0x74, 0x73, 0x12, 0x2d, 0x0a, 0x0f, 0x43, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x43, 0x61, 0x63, 0x68,
+	0x65, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x37, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x03, 0x52, 0x0f, 0x43,
+	0x72, 0x79, 0x70, 0x74, 0x6f, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x88, 0x01,
+	0x01, 0x12, 0x12, 0x0a, 0x04, 0x53, 0x65, 0x65, 0x64, 0x18, 0x38, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x04, 0x53, 0x65, 0x65, 0x64, 0x12, 0x38, 0x0a, 0x09, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x73, 0x18, 0x39, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65,
+	0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x73, 0x52, 0x09, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x12,
+	0x2e, 0x0a, 0x12, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x51, 0x75, 0x65, 0x75, 0x65, 0x43, 0x61, 0x70,
+	0x61, 0x63, 0x69, 0x74, 0x79, 0x18, 0x3a, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x12, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x51, 0x75, 0x65, 0x75, 0x65, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x12,
+	0x20, 0x0a, 0x0b, 0x46, 0x61, 0x74, 0x61, 0x6c, 0x50, 0x61, 0x6e, 0x69, 0x63, 0x73, 0x18, 0x3b,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x46, 0x61, 0x74, 0x61, 0x6c, 0x50, 0x61, 0x6e, 0x69, 0x63,
+	0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x42, 0x61, 0x74, 0x63, 0x68, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18,
+	0x3c, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x42, 0x61, 0x74, 0x63, 0x68, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x12, 0x39, 0x0a, 0x0a, 0x42, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x6c, 0x61, 0x79, 0x18,
+	0x3d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
 	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x52, 0x10, 0x52, 0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x70, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76,
-	0x61, 0x6c, 0x22, 0xc2, 0x01, 0x0a, 0x14, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x43, 0x6f,
-	0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x4f, 0x0a, 0x08, 0x52,
-	0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x33, 0x2e,
+	0x52, 0x0a, 0x42, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x6c, 0x61, 0x79, 0x12, 0x24, 0x0a, 0x0d,
+	0x4d, 0x61, 0x78, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18, 0x3e, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0d, 0x4d, 0x61, 0x78, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x42, 0x79, 0x74,
+	0x65, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x4c,
+	0x61, 0x67, 0x18, 0x3f, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74,
+	0x69, 0x6f, 0x6e, 0x4c, 0x61, 0x67, 0x12, 0x28, 0x0a, 0x0f, 0x50, 0x65, 0x72, 0x73, 0x69, 0x73,
+	0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x40, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0f, 0x50, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73,
+	0x12, 0x26, 0x0a, 0x0e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x4c, 0x6f, 0x67, 0x53, 0x79,
+	0x6e, 0x63, 0x18, 0x41, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x4c, 0x6f, 0x67, 0x53, 0x79, 0x6e, 0x63, 0x12, 0x20, 0x0a, 0x0b, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x42, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x55, 0x0a, 0x0d, 0x43, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x18, 0x43, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x2f, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x4f, 0x70, 0x74, 0x73, 0x2e,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x73, 0x12, 0x3c, 0x0a, 0x19, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x44,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x19, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12,
+	0x2e, 0x0a, 0x12, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x42,
+	0x75, 0x66, 0x66, 0x65, 0x72, 0x18, 0x45, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x12, 0x43, 0x6f, 0x6d,
+	0x6d, 0x69, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x42, 0x75, 0x66, 0x66, 0x65, 0x72, 0x12,
+	0x16, 0x0a, 0x06, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18, 0x46, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x06, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x1a, 0x57, 0x0a, 0x0e, 0x4c, 0x61, 0x74, 0x65, 0x6e,
+	0x63, 0x69, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2f, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x1a, 0x60, 0x0a, 0x16, 0x49, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x52, 0x61, 0x74, 0x65, 0x4c,
+	0x69, 0x6d, 0x69, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x30, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6f, 0x72,
+	0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52, 0x61,
+	0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x1a, 0x40, 0x0a, 0x12, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x57, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69,
+	0x63, 0x61, 0x74, 0x65, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69,
+	0x63, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x43, 0x65, 0x72, 0x74,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74, 0x79,
+	0x42, 0x12, 0x0a, 0x10, 0x5f, 0x43, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x43, 0x61, 0x63, 0x68, 0x65,
+	0x53, 0x69, 0x7a, 0x65, 0x22, 0x35, 0x0a, 0x09, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x52, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x04, 0x52, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x42, 0x75, 0x72, 0x73, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x42, 0x75, 0x72, 0x73, 0x74, 0x22, 0x9d, 0x01, 0x0a, 0x05,
+	0x46, 0x61, 0x75, 0x6c, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2f, 0x0a,
+	0x05, 0x53, 0x74, 0x61, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x05, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x35,
+	0x0a, 0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x44, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x53, 0x68, 0x61, 0x72, 0x64, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x53, 0x68, 0x61, 0x72, 0x64, 0x22, 0x3b, 0x0a, 0x09, 0x52,
+	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x43, 0x50, 0x55, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x04, 0x43, 0x50, 0x55, 0x73, 0x12, 0x1a, 0x0a, 0x08,
+	0x4d, 0x61, 0x78, 0x50, 0x72, 0x6f, 0x63, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08,
+	0x4d, 0x61, 0x78, 0x50, 0x72, 0x6f, 0x63, 0x73, 0x22, 0xaf, 0x03, 0x0a, 0x0b, 0x52, 0x65, 0x70,
+	0x6c, 0x69, 0x63, 0x61, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x49, 0x44, 0x12, 0x18, 0x0a, 0x07, 0x41, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x41, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79,
+	0x12, 0x20, 0x0a, 0x0b, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x50, 0x6f, 0x72, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x50, 0x6f,
+	0x72, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x50, 0x6f, 0x72, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x50, 0x6f,
+	0x72, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x41, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x52, 0x65, 0x70, 0x6c,
+	0x69, 0x63, 0x61, 0x53, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0d, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x53, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x22,
+	0x0a, 0x0c, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x6f, 0x63, 0x6b,
+	0x65, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x50, 0x6f, 0x72, 0x74,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x50, 0x6f,
+	0x72, 0x74, 0x12, 0x2a, 0x0a, 0x10, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x10, 0x43, 0x6f,
+	0x6d, 0x6d, 0x69, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x2a,
+	0x0a, 0x10, 0x53, 0x68, 0x61, 0x72, 0x64, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x50, 0x6f, 0x72,
+	0x74, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x10, 0x53, 0x68, 0x61, 0x72, 0x64, 0x43,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x73, 0x12, 0x2e, 0x0a, 0x12, 0x53, 0x68,
+	0x61, 0x72, 0x64, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x73,
+	0x18, 0x0c, 0x20, 0x03, 0x28, 0x09, 0x52, 0x12, 0x53, 0x68, 0x61, 0x72, 0x64, 0x43, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x53, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x22, 0x55, 0x0a, 0x08, 0x4c, 0x6f,
+	0x61, 0x64, 0x53, 0x74, 0x65, 0x70, 0x12, 0x35, 0x0a, 0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a,
+	0x04, 0x52, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x52, 0x61, 0x74,
+	0x65, 0x22, 0xf6, 0x0b, 0x0a, 0x0a, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4f, 0x70, 0x74, 0x73,
+	0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x49, 0x44,
+	0x12, 0x16, 0x0a, 0x06, 0x55, 0x73, 0x65, 0x54, 0x4c, 0x53, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x55, 0x73, 0x65, 0x54, 0x4c, 0x53, 0x12, 0x24, 0x0a, 0x0d, 0x4d, 0x61, 0x78, 0x43,
+	0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0d, 0x4d, 0x61, 0x78, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x20,
+	0x0a, 0x0b, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0b, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x41, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x0e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x54, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69,
+	0x74, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x70, 0x18, 0x0c, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x08, 0x52, 0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x70, 0x12, 0x45, 0x0a,
+	0x10, 0x52, 0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x70, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61,
+	0x6c, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x10, 0x52, 0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x70, 0x49, 0x6e, 0x74, 0x65,
+	0x72, 0x76, 0x61, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x4c, 0x6f, 0x61, 0x64, 0x4d, 0x6f, 0x64, 0x65,
+	0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x4c, 0x6f, 0x61, 0x64, 0x4d, 0x6f, 0x64, 0x65,
+	0x12, 0x1e, 0x0a, 0x0a, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x52, 0x61, 0x74, 0x65, 0x18, 0x0f,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x52, 0x61, 0x74, 0x65,
+	0x12, 0x30, 0x0a, 0x13, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x44, 0x69, 0x73, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x50,
+	0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x4d, 0x69, 0x6e,
+	0x18, 0x11, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x4d,
+	0x69, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x4d, 0x61, 0x78,
+	0x18, 0x12, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x4d,
+	0x61, 0x78, 0x12, 0x22, 0x0a, 0x0c, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x5a, 0x69, 0x70,
+	0x66, 0x53, 0x18, 0x13, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61,
+	0x64, 0x5a, 0x69, 0x70, 0x66, 0x53, 0x12, 0x20, 0x0a, 0x0b, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61,
+	0x64, 0x53, 0x65, 0x65, 0x64, 0x18, 0x14, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x50, 0x61, 0x79,
+	0x6c, 0x6f, 0x61, 0x64, 0x53, 0x65, 0x65, 0x64, 0x12, 0x30, 0x0a, 0x13, 0x43, 0x6f, 0x6d, 0x70,
+	0x72, 0x65, 0x73, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18,
+	0x15, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69,
+	0x62, 0x6c, 0x65, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x41, 0x0a, 0x0e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x16, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x47, 0x0a,
+	0x11, 0x4d, 0x61, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x18, 0x17, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x11, 0x4d, 0x61, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x54,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x4d, 0x61, 0x78, 0x52, 0x65, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x18, 0x18, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x4d, 0x61, 0x78, 0x52,
+	0x65, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x19, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x3f, 0x0a, 0x0d, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x4c, 0x61, 0x74, 0x65,
+	0x6e, 0x63, 0x79, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x4c, 0x61, 0x74, 0x65,
+	0x6e, 0x63, 0x79, 0x12, 0x24, 0x0a, 0x0d, 0x4d, 0x69, 0x6e, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x74, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x4d, 0x69, 0x6e, 0x43,
+	0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x41, 0x63, 0x6b,
+	0x4d, 0x6f, 0x64, 0x65, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x41, 0x63, 0x6b, 0x4d,
+	0x6f, 0x64, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x54, 0x72, 0x61, 0x63, 0x65, 0x53, 0x70, 0x65, 0x65,
+	0x64, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x54, 0x72, 0x61, 0x63, 0x65, 0x53, 0x70,
+	0x65, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x54, 0x72, 0x61, 0x63, 0x65, 0x4c, 0x6f, 0x6f, 0x70,
+	0x18, 0x1e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x54, 0x72, 0x61, 0x63, 0x65, 0x4c, 0x6f, 0x6f,
+	0x70, 0x12, 0x3d, 0x0a, 0x0c, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x18, 0x1f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x0c, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x12, 0x3b, 0x0a, 0x0b, 0x4c, 0x6f, 0x61, 0x64, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18,
+	0x20, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x61, 0x64, 0x53, 0x74, 0x65, 0x70,
+	0x52, 0x0b, 0x4c, 0x6f, 0x61, 0x64, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x4c, 0x6f, 0x61, 0x64, 0x52, 0x61, 0x6d, 0x70, 0x18, 0x21, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x08, 0x4c, 0x6f, 0x61, 0x64, 0x52, 0x61, 0x6d, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x22, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x3d, 0x0a, 0x0c, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x23, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x42, 0x61, 0x74, 0x63, 0x68, 0x54,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x57, 0x6f, 0x72, 0x6b, 0x6c, 0x6f,
+	0x61, 0x64, 0x18, 0x24, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x57, 0x6f, 0x72, 0x6b, 0x6c, 0x6f,
+	0x61, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x4b, 0x56, 0x4b, 0x65, 0x79, 0x73, 0x18, 0x25, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x06, 0x4b, 0x56, 0x4b, 0x65, 0x79, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x4b, 0x56,
+	0x52, 0x65, 0x61, 0x64, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x18, 0x26, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0b, 0x4b, 0x56, 0x52, 0x65, 0x61, 0x64, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x12, 0x24, 0x0a, 0x0d,
+	0x4b, 0x56, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x18, 0x27, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0d, 0x4b, 0x56, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x61, 0x74,
+	0x69, 0x6f, 0x12, 0x32, 0x0a, 0x14, 0x4b, 0x56, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x28, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x14, 0x4b, 0x56, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x49, 0x6e,
+	0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x53, 0x65, 0x65, 0x64, 0x18, 0x29,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x53, 0x65, 0x65, 0x64, 0x12, 0x38, 0x0a, 0x09, 0x52, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x18, 0x2a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
 	0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e,
-	0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x52, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x1a, 0x59, 0x0a, 0x0d,
-	0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
-	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
-	0x32, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c,
-	0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62,
-	0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xc2, 0x01, 0x0a, 0x14, 0x43, 0x72, 0x65, 0x61,
-	0x74, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x4f, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x33, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x69,
-	0x63, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63,
-	0x61, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61,
-	0x73, 0x1a, 0x59, 0x0a, 0x0d, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x03, 0x6b, 0x65, 0x79, 0x12, 0x32, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x4f, 0x70, 0x74,
-	0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xc4, 0x01, 0x0a,
-	0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x50, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63,
-	0x61, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x34, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65,
-	0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
-	0x65, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08,
-	0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x1a, 0x59, 0x0a, 0x0d, 0x52, 0x65, 0x70, 0x6c,
-	0x69, 0x63, 0x61, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x32, 0x0a, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6f, 0x72, 0x63,
-	0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x70,
-	0x6c, 0x69, 0x63, 0x61, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
-	0x02, 0x38, 0x01, 0x22, 0xe6, 0x01, 0x0a, 0x13, 0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x70,
-	0x6c, 0x69, 0x63, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x49,
-	0x44, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x03, 0x49, 0x44, 0x73, 0x12, 0x5d, 0x0a,
-	0x0d, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x37, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x70, 0x6c,
-	0x69, 0x63, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69,
-	0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x43,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x5e, 0x0a, 0x12,
-	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x03, 0x6b, 0x65, 0x79, 0x12, 0x32, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x49, 0x6e, 0x66,
-	0x6f, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x16, 0x0a, 0x14,
-	0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x26, 0x0a, 0x12, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x70, 0x6c,
-	0x69, 0x63, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x49, 0x44,
-	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x03, 0x49, 0x44, 0x73, 0x22, 0x9a, 0x01, 0x0a,
-	0x13, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x06, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x70, 0x6c, 0x69,
-	0x63, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x65,
-	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x1a, 0x39,
-	0x0a, 0x0b, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
-	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
-	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
-	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xc9, 0x03, 0x0a, 0x12, 0x53, 0x74,
-	0x61, 0x72, 0x74, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x4a, 0x0a, 0x07, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x30, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x45, 0x6e,
-	0x74, 0x72, 0x79, 0x52, 0x07, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x37, 0x0a, 0x14,
-	0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x41, 0x75, 0x74, 0x68, 0x6f,
-	0x72, 0x69, 0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x14, 0x43, 0x65,
-	0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69,
-	0x74, 0x79, 0x88, 0x01, 0x01, 0x12, 0x5c, 0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
-	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x36, 0x2e, 0x6f,
+	0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x52, 0x09, 0x52, 0x65, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x53, 0x68, 0x61, 0x72, 0x64, 0x18, 0x2b, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x05, 0x53, 0x68, 0x61, 0x72, 0x64, 0x22, 0xc2, 0x01, 0x0a, 0x14, 0x52,
+	0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x4f, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x33, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x52, 0x65, 0x70,
+	0x6c, 0x69, 0x63, 0x61, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x52, 0x65, 0x70, 0x6c,
+	0x69, 0x63, 0x61, 0x73, 0x1a, 0x59, 0x0a, 0x0d, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x32, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
+	0xc2, 0x01, 0x0a, 0x14, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63,
+	0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x4f, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c,
+	0x69, 0x63, 0x61, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x33, 0x2e, 0x6f, 0x72, 0x63,
+	0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x1a, 0x59, 0x0a, 0x0d, 0x52, 0x65, 0x70,
+	0x6c, 0x69, 0x63, 0x61, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x32, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6f, 0x72,
+	0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65,
+	0x70, 0x6c, 0x69, 0x63, 0x61, 0x4f, 0x70, 0x74, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0xc4, 0x01, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x50,
+	0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x34, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73,
+	0x1a, 0x59, 0x0a, 0x0d, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x32, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x49, 0x6e, 0x66, 0x6f,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xb2, 0x02, 0x0a, 0x13,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0d,
+	0x52, 0x03, 0x49, 0x44, 0x73, 0x12, 0x5d, 0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x37, 0x2e, 0x6f,
 	0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x53,
-	0x74, 0x61, 0x72, 0x74, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45,
-	0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x1a, 0x57, 0x0a, 0x0c, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x45, 0x6e,
-	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x31, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4f, 0x70, 0x74,
-	0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x5e, 0x0a, 0x12,
-	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x03, 0x6b, 0x65, 0x79, 0x12, 0x32, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x49, 0x6e, 0x66,
-	0x6f, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x17, 0x0a, 0x15,
-	0x5f, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x41, 0x75, 0x74, 0x68,
-	0x6f, 0x72, 0x69, 0x74, 0x79, 0x22, 0x15, 0x0a, 0x13, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6c,
-	0x69, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x25, 0x0a, 0x11,
-	0x53, 0x74, 0x6f, 0x70, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x10, 0x0a, 0x03, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x03,
-	0x49, 0x44, 0x73, 0x22, 0x14, 0x0a, 0x12, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6c, 0x69, 0x65, 0x6e,
-	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x0d, 0x0a, 0x0b, 0x51, 0x75, 0x69,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x42, 0x3a, 0x5a, 0x38, 0x67, 0x69, 0x74, 0x68,
-	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x65, 0x6c, 0x61, 0x62, 0x2f, 0x68, 0x6f, 0x74,
-	0x73, 0x74, 0x75, 0x66, 0x66, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x10, 0x45, 0x78, 0x70, 0x65, 0x72, 0x69, 0x6d, 0x65,
+	0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10,
+	0x45, 0x78, 0x70, 0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x12, 0x1e, 0x0a, 0x0a, 0x4d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x53, 0x65, 0x65, 0x64, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x4d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x53, 0x65, 0x65, 0x64,
+	0x1a, 0x5e, 0x0a, 0x12, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x32, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73,
+	0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63,
+	0x61, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x22, 0x16, 0x0a, 0x14, 0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x26, 0x0a, 0x12, 0x53, 0x74, 0x6f, 0x70,
+	0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x03, 0x49, 0x44, 0x73,
+	0x22, 0xab, 0x05, 0x0a, 0x13, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x06, 0x48, 0x61, 0x73, 0x68,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65,
+	0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x52,
+	0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x48,
+	0x61, 0x73, 0x68, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x48, 0x61, 0x73, 0x68,
+	0x65, 0x73, 0x12, 0x57, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x48, 0x61, 0x73, 0x68, 0x65,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x35, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73,
+	0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65,
+	0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x12, 0x5a, 0x0a, 0x0c, 0x49,
+	0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x36, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x49, 0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x74,
+	0x69, 0x62, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x49, 0x6e, 0x63, 0x6f, 0x6d,
+	0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x12, 0x57, 0x0a, 0x0b, 0x53, 0x68, 0x61, 0x72, 0x64,
+	0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x35, 0x2e, 0x6f,
+	0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x53,
+	0x74, 0x6f, 0x70, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x2e, 0x53, 0x68, 0x61, 0x72, 0x64, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x0b, 0x53, 0x68, 0x61, 0x72, 0x64, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73,
+	0x1a, 0x39, 0x0a, 0x0b, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x3e, 0x0a, 0x10, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x63, 0x0a, 0x11, 0x49,
+	0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x38, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x22, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x70, 0x62, 0x2e, 0x49, 0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65,
+	0x50, 0x65, 0x65, 0x72, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x1a, 0x5c, 0x0a, 0x10, 0x53, 0x68, 0x61, 0x72, 0x64, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x32, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x53, 0x68, 0x61, 0x72, 0x64, 0x48, 0x61, 0x73,
+	0x68, 0x65, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x25,
+	0x0a, 0x0b, 0x53, 0x68, 0x61, 0x72, 0x64, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x48,
+	0x61, 0x73, 0x68, 0x65, 0x73, 0x22, 0x29, 0x0a, 0x11, 0x49, 0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x61,
+	0x74, 0x69, 0x62, 0x6c, 0x65, 0x50, 0x65, 0x65, 0x72, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x50, 0x65,
+	0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x50, 0x65, 0x65, 0x72, 0x73,
+	0x22, 0xab, 0x04, 0x0a, 0x12, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x4a, 0x0a, 0x07, 0x43, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65,
+	0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x43, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x73, 0x12, 0x37, 0x0a, 0x14, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61,
+	0x74, 0x65, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0c, 0x48, 0x00, 0x52, 0x14, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65,
+	0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x88, 0x01, 0x01, 0x12, 0x5c, 0x0a, 0x0d,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x36, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x54, 0x72,
+	0x61, 0x63, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x54, 0x72, 0x61, 0x63, 0x65,
+	0x12, 0x2a, 0x0a, 0x10, 0x45, 0x78, 0x70, 0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x45, 0x78, 0x70, 0x65,
+	0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x1e, 0x0a, 0x0a,
+	0x4d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x53, 0x65, 0x65, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0a, 0x4d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x53, 0x65, 0x65, 0x64, 0x1a, 0x57, 0x0a, 0x0c,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x31,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
+	0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4f, 0x70, 0x74, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x5e, 0x0a, 0x12, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x32, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6f,
+	0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x52,
+	0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x65, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x22, 0x15,
+	0x0a, 0x13, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x25, 0x0a, 0x11, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x49, 0x44,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x03, 0x49, 0x44, 0x73, 0x22, 0x14, 0x0a, 0x12,
+	0x53, 0x74, 0x6f, 0x70, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x0d, 0x0a, 0x0b, 0x51, 0x75, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x22, 0x11, 0x0a, 0x0f, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x22, 0x94, 0x01, 0x0a, 0x10, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77,
+	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a, 0x06, 0x48, 0x61, 0x73,
+	0x68, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x6f, 0x72, 0x63, 0x68,
+	0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x53, 0x68, 0x75, 0x74,
+	0x64, 0x6f, 0x77, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x48, 0x61, 0x73,
+	0x68, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73,
+	0x1a, 0x39, 0x0a, 0x0b, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x3b, 0x0a, 0x13, 0x53,
+	0x74, 0x61, 0x72, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x43, 0x50, 0x55, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x03, 0x43, 0x50, 0x55, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x65, 0x61, 0x70, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x04, 0x48, 0x65, 0x61, 0x70, 0x22, 0x16, 0x0a, 0x14, 0x53, 0x74, 0x61, 0x72,
+	0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x33, 0x0a, 0x17, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x41, 0x72, 0x74, 0x69, 0x66,
+	0x61, 0x63, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x4d,
+	0x61, 0x78, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x4d, 0x61,
+	0x78, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x55, 0x0a, 0x0d, 0x41, 0x72, 0x74, 0x69, 0x66, 0x61, 0x63,
+	0x74, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x44, 0x61,
+	0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1c,
+	0x0a, 0x09, 0x54, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x54, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x22, 0x1a, 0x0a, 0x18,
+	0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x41, 0x72, 0x74, 0x69, 0x66, 0x61, 0x63, 0x74, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x20, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x65,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x52, 0x75, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x52, 0x75, 0x6e, 0x22, 0x0f, 0x0a, 0x0d, 0x52, 0x65,
+	0x73, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x11, 0x0a, 0x0f, 0x50,
+	0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x43,
+	0x0a, 0x0f, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73,
+	0x73, 0x12, 0x12, 0x0a, 0x04, 0x56, 0x69, 0x65, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x04, 0x56, 0x69, 0x65, 0x77, 0x12, 0x1c, 0x0a, 0x09, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74,
+	0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74,
+	0x74, 0x65, 0x64, 0x22, 0xc4, 0x02, 0x0a, 0x10, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4b, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c,
+	0x69, 0x63, 0x61, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x6f, 0x72, 0x63,
+	0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x6f,
+	0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x52, 0x65,
+	0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x52, 0x65, 0x70,
+	0x6c, 0x69, 0x63, 0x61, 0x73, 0x12, 0x48, 0x0a, 0x07, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x1a,
+	0x5d, 0x0a, 0x0d, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x36, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x50, 0x72, 0x6f, 0x67, 0x72,
+	0x65, 0x73, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x3a,
+	0x0a, 0x0c, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x9f, 0x01, 0x0a, 0x0d, 0x4c,
+	0x69, 0x6e, 0x6b, 0x45, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04,
+	0x48, 0x6f, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x48, 0x6f, 0x73, 0x74,
+	0x12, 0x33, 0x0a, 0x07, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x4c, 0x61,
+	0x74, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x31, 0x0a, 0x06, 0x4a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x06, 0x4a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x4c, 0x6f, 0x73, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x4c, 0x6f, 0x73, 0x73, 0x22, 0x6b, 0x0a, 0x15,
+	0x45, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61,
+	0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66,
+	0x61, 0x63, 0x65, 0x12, 0x34, 0x0a, 0x05, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x45, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x05, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x22, 0x18, 0x0a, 0x16, 0x45, 0x6d, 0x75,
+	0x6c, 0x61, 0x74, 0x65, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x16, 0x0a, 0x14, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x4e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xd9, 0x01, 0x0a, 0x15,
+	0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x62, 0x0a, 0x0e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x54, 0x72,
+	0x69, 0x70, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3a, 0x2e,
+	0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x62, 0x2e,
+	0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x54, 0x72, 0x69, 0x70, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0e, 0x52, 0x6f, 0x75, 0x6e, 0x64,
+	0x54, 0x72, 0x69, 0x70, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x1a, 0x5c, 0x0a, 0x13, 0x52, 0x6f, 0x75,
+	0x6e, 0x64, 0x54, 0x72, 0x69, 0x70, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x2f, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x15, 0x0a, 0x13, 0x43, 0x6c, 0x65, 0x61, 0x72,
+	0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x16,
+	0x0a, 0x14, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x3a, 0x5a, 0x38, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x65, 0x6c, 0x61, 0x62, 0x2f, 0x68, 0x6f, 0x74, 0x73, 0x74,
+	0x75, 0x66, 0x66, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2f, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1186,56 +3827,128 @@ func file_internal_proto_orchestrationpb_orchestration_proto_rawDescGZIP() []byt
 	return file_internal_proto_orchestrationpb_orchestration_proto_rawDescData
 }
 
-var file_internal_proto_orchestrationpb_orchestration_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_internal_proto_orchestrationpb_orchestration_proto_msgTypes = make([]protoimpl.MessageInfo, 57)
 var file_internal_proto_orchestrationpb_orchestration_proto_goTypes = []interface{}{
-	(*ReplicaOpts)(nil),           // 0: orchestrationpb.ReplicaOpts
-	(*ReplicaInfo)(nil),           // 1: orchestrationpb.ReplicaInfo
-	(*ClientOpts)(nil),            // 2: orchestrationpb.ClientOpts
-	(*ReplicaConfiguration)(nil),  // 3: orchestrationpb.ReplicaConfiguration
-	(*CreateReplicaRequest)(nil),  // 4: orchestrationpb.CreateReplicaRequest
-	(*CreateReplicaResponse)(nil), // 5: orchestrationpb.CreateReplicaResponse
-	(*StartReplicaRequest)(nil),   // 6: orchestrationpb.StartReplicaRequest
-	(*StartReplicaResponse)(nil),  // 7: orchestrationpb.StartReplicaResponse
-	(*StopReplicaRequest)(nil),    // 8: orchestrationpb.StopReplicaRequest
-	(*StopReplicaResponse)(nil),   // 9: orchestrationpb.StopReplicaResponse
-	(*StartClientRequest)(nil),    // 10: orchestrationpb.StartClientRequest
-	(*StartClientResponse)(nil),   // 11: orchestrationpb.StartClientResponse
-	(*StopClientRequest)(nil),     // 12: orchestrationpb.StopClientRequest
-	(*StopClientResponse)(nil),    // 13: orchestrationpb.StopClientResponse
-	(*QuitRequest)(nil),           // 14: orchestrationpb.QuitRequest
-	nil,                           // 15: orchestrationpb.ReplicaConfiguration.ReplicasEntry
-	nil,                           // 16: orchestrationpb.CreateReplicaRequest.ReplicasEntry
-	nil,                           // 17: orchestrationpb.CreateReplicaResponse.ReplicasEntry
-	nil,                           // 18: orchestrationpb.StartReplicaRequest.ConfigurationEntry
-	nil,                           // 19: orchestrationpb.StopReplicaResponse.HashesEntry
-	nil,                           // 20: orchestrationpb.StartClientRequest.ClientsEntry
-	nil,                           // 21: orchestrationpb.StartClientRequest.ConfigurationEntry
-	(*durationpb.Duration)(nil),   // 22: google.protobuf.Duration
+	(*ReplicaOpts)(nil),              // 0: orchestrationpb.ReplicaOpts
+	(*RateLimit)(nil),                // 1: orchestrationpb.RateLimit
+	(*Fault)(nil),                    // 2: orchestrationpb.Fault
+	(*Resources)(nil),                // 3: orchestrationpb.Resources
+	(*ReplicaInfo)(nil),              // 4: orchestrationpb.ReplicaInfo
+	(*LoadStep)(nil),                 // 5: orchestrationpb.LoadStep
+	(*ClientOpts)(nil),               // 6: orchestrationpb.ClientOpts
+	(*ReplicaConfiguration)(nil),     // 7: orchestrationpb.ReplicaConfiguration
+	(*CreateReplicaRequest)(nil),     // 8: orchestrationpb.CreateReplicaRequest
+	(*CreateReplicaResponse)(nil),    // 9: orchestrationpb.CreateReplicaResponse
+	(*StartReplicaRequest)(nil),      // 10: orchestrationpb.StartReplicaRequest
+	(*StartReplicaResponse)(nil),     // 11: orchestrationpb.StartReplicaResponse
+	(*StopReplicaRequest)(nil),       // 12: orchestrationpb.StopReplicaRequest
+	(*StopReplicaResponse)(nil),      // 13: orchestrationpb.StopReplicaResponse
+	(*ShardHashes)(nil),              // 14: orchestrationpb.ShardHashes
+	(*IncompatiblePeers)(nil),        // 15: orchestrationpb.IncompatiblePeers
+	(*StartClientRequest)(nil),       // 16: orchestrationpb.StartClientRequest
+	(*StartClientResponse)(nil),      // 17: orchestrationpb.StartClientResponse
+	(*StopClientRequest)(nil),        // 18: orchestrationpb.StopClientRequest
+	(*StopClientResponse)(nil),       // 19: orchestrationpb.StopClientResponse
+	(*QuitRequest)(nil),              // 20: orchestrationpb.QuitRequest
+	(*ShutdownRequest)(nil),          // 21: orchestrationpb.ShutdownRequest
+	(*ShutdownResponse)(nil),         // 22: orchestrationpb.ShutdownResponse
+	(*StartProfileRequest)(nil),      // 23: orchestrationpb.StartProfileRequest
+	(*StartProfileResponse)(nil),     // 24: orchestrationpb.StartProfileResponse
+	(*CollectArtifactsRequest)(nil),  // 25: orchestrationpb.CollectArtifactsRequest
+	(*ArtifactChunk)(nil),            // 26: orchestrationpb.ArtifactChunk
+	(*CollectArtifactsResponse)(nil), // 27: orchestrationpb.CollectArtifactsResponse
+	(*ResetRequest)(nil),             // 28: orchestrationpb.ResetRequest
+	(*ResetResponse)(nil),            // 29: orchestrationpb.ResetResponse
+	(*ProgressRequest)(nil),          // 30: orchestrationpb.ProgressRequest
+	(*ReplicaProgress)(nil),          // 31: orchestrationpb.ReplicaProgress
+	(*ProgressResponse)(nil),         // 32: orchestrationpb.ProgressResponse
+	(*LinkEmulation)(nil),            // 33: orchestrationpb.LinkEmulation
+	(*EmulateNetworkRequest)(nil),    // 34: orchestrationpb.EmulateNetworkRequest
+	(*EmulateNetworkResponse)(nil),   // 35: orchestrationpb.EmulateNetworkResponse
+	(*VerifyNetworkRequest)(nil),     // 36: orchestrationpb.VerifyNetworkRequest
+	(*VerifyNetworkResponse)(nil),    // 37: orchestrationpb.VerifyNetworkResponse
+	(*ClearNetworkRequest)(nil),      // 38: orchestrationpb.ClearNetworkRequest
+	(*ClearNetworkResponse)(nil),     // 39: orchestrationpb.ClearNetworkResponse
+	nil,                              // 40: orchestrationpb.ReplicaOpts.LatenciesEntry
+	nil,                              // 41: orchestrationpb.ReplicaOpts.InboundRateLimitsEntry
+	nil,                              // 42: orchestrationpb.ReplicaOpts.ClientWeightsEntry
+	nil,                              // 43: orchestrationpb.ReplicaConfiguration.ReplicasEntry
+	nil,                              // 44: orchestrationpb.CreateReplicaRequest.ReplicasEntry
+	nil,                              // 45: orchestrationpb.CreateReplicaResponse.ReplicasEntry
+	nil,                              // 46: orchestrationpb.StartReplicaRequest.ConfigurationEntry
+	nil,                              // 47: orchestrationpb.StopReplicaResponse.HashesEntry
+	nil,                              // 48: orchestrationpb.StopReplicaResponse.StateHashesEntry
+	nil,                              // 49: orchestrationpb.StopReplicaResponse.IncompatibleEntry
+	nil,                              // 50: orchestrationpb.StopReplicaResponse.ShardHashesEntry
+	nil,                              // 51: orchestrationpb.StartClientRequest.ClientsEntry
+	nil,                              // 52: orchestrationpb.StartClientRequest.ConfigurationEntry
+	nil,                              // 53: orchestrationpb.ShutdownResponse.HashesEntry
+	nil,                              // 54: orchestrationpb.ProgressResponse.ReplicasEntry
+	nil,                              // 55: orchestrationpb.ProgressResponse.ClientsEntry
+	nil,                              // 56: orchestrationpb.VerifyNetworkResponse.RoundTripTimesEntry
+	(*durationpb.Duration)(nil),      // 57: google.protobuf.Duration
 }
 var file_internal_proto_orchestrationpb_orchestration_proto_depIdxs = []int32{
-	22, // 0: orchestrationpb.ReplicaOpts.ConnectTimeout:type_name -> google.protobuf.Duration
-	22, // 1: orchestrationpb.ReplicaOpts.InitialTimeout:type_name -> google.protobuf.Duration
-	22, // 2: orchestrationpb.ReplicaOpts.MaxTimeout:type_name -> google.protobuf.Duration
-	22, // 3: orchestrationpb.ClientOpts.ConnectTimeout:type_name -> google.protobuf.Duration
-	22, // 4: orchestrationpb.ClientOpts.RateStepInterval:type_name -> google.protobuf.Duration
-	15, // 5: orchestrationpb.ReplicaConfiguration.Replicas:type_name -> orchestrationpb.ReplicaConfiguration.ReplicasEntry
-	16, // 6: orchestrationpb.CreateReplicaRequest.Replicas:type_name -> orchestrationpb.CreateReplicaRequest.ReplicasEntry
-	17, // 7: orchestrationpb.CreateReplicaResponse.Replicas:type_name -> orchestrationpb.CreateReplicaResponse.ReplicasEntry
-	18, // 8: orchestrationpb.StartReplicaRequest.Configuration:type_name -> orchestrationpb.StartReplicaRequest.ConfigurationEntry
-	19, // 9: orchestrationpb.StopReplicaResponse.Hashes:type_name -> orchestrationpb.StopReplicaResponse.HashesEntry
-	20, // 10: orchestrationpb.StartClientRequest.Clients:type_name -> orchestrationpb.StartClientRequest.ClientsEntry
-	21, // 11: orchestrationpb.StartClientRequest.Configuration:type_name -> orchestrationpb.StartClientRequest.ConfigurationEntry
-	1,  // 12: orchestrationpb.ReplicaConfiguration.ReplicasEntry.value:type_name -> orchestrationpb.ReplicaInfo
-	0,  // 13: orchestrationpb.CreateReplicaRequest.ReplicasEntry.value:type_name -> orchestrationpb.ReplicaOpts
-	1,  // 14: orchestrationpb.CreateReplicaResponse.ReplicasEntry.value:type_name -> orchestrationpb.ReplicaInfo
-	1,  // 15: orchestrationpb.StartReplicaRequest.ConfigurationEntry.value:type_name -> orchestrationpb.ReplicaInfo
-	2,  // 16: orchestrationpb.StartClientRequest.ClientsEntry.value:type_name -> orchestrationpb.ClientOpts
-	1,  // 17: orchestrationpb.StartClientRequest.ConfigurationEntry.value:type_name -> orchestrationpb.ReplicaInfo
-	18, // [18:18] is the sub-list for method output_type
-	18, // [18:18] is the sub-list for method input_type
-	18, // [18:18] is the sub-list for extension type_name
-	18, // [18:18] is the sub-list for extension extendee
-	0,  // [0:18] is the sub-list for field type_name
+	57, // 0: orchestrationpb.ReplicaOpts.ConnectTimeout:type_name -> google.protobuf.Duration
+	57, // 1: orchestrationpb.ReplicaOpts.InitialTimeout:type_name -> google.protobuf.Duration
+	57, // 2: orchestrationpb.ReplicaOpts.MaxTimeout:type_name -> google.protobuf.Duration
+	40, // 3: orchestrationpb.ReplicaOpts.Latencies:type_name -> orchestrationpb.ReplicaOpts.LatenciesEntry
+	57, // 4: orchestrationpb.ReplicaOpts.Jitter:type_name -> google.protobuf.Duration
+	57, // 5: orchestrationpb.ReplicaOpts.GossipInterval:type_name -> google.protobuf.Duration
+	57, // 6: orchestrationpb.ReplicaOpts.HealthCheckInterval:type_name -> google.protobuf.Duration
+	57, // 7: orchestrationpb.ReplicaOpts.DedupTTL:type_name -> google.protobuf.Duration
+	57, // 8: orchestrationpb.ReplicaOpts.DrainTimeout:type_name -> google.protobuf.Duration
+	2,  // 9: orchestrationpb.ReplicaOpts.Faults:type_name -> orchestrationpb.Fault
+	41, // 10: orchestrationpb.ReplicaOpts.InboundRateLimits:type_name -> orchestrationpb.ReplicaOpts.InboundRateLimitsEntry
+	3,  // 11: orchestrationpb.ReplicaOpts.Resources:type_name -> orchestrationpb.Resources
+	57, // 12: orchestrationpb.ReplicaOpts.BatchDelay:type_name -> google.protobuf.Duration
+	42, // 13: orchestrationpb.ReplicaOpts.ClientWeights:type_name -> orchestrationpb.ReplicaOpts.ClientWeightsEntry
+	57, // 14: orchestrationpb.Fault.Start:type_name -> google.protobuf.Duration
+	57, // 15: orchestrationpb.Fault.Duration:type_name -> google.protobuf.Duration
+	57, // 16: orchestrationpb.LoadStep.Duration:type_name -> google.protobuf.Duration
+	57, // 17: orchestrationpb.ClientOpts.ConnectTimeout:type_name -> google.protobuf.Duration
+	57, // 18: orchestrationpb.ClientOpts.RateStepInterval:type_name -> google.protobuf.Duration
+	57, // 19: orchestrationpb.ClientOpts.RequestTimeout:type_name -> google.protobuf.Duration
+	57, // 20: orchestrationpb.ClientOpts.MaxRequestTimeout:type_name -> google.protobuf.Duration
+	57, // 21: orchestrationpb.ClientOpts.TargetLatency:type_name -> google.protobuf.Duration
+	57, // 22: orchestrationpb.ClientOpts.DrainTimeout:type_name -> google.protobuf.Duration
+	5,  // 23: orchestrationpb.ClientOpts.LoadProfile:type_name -> orchestrationpb.LoadStep
+	57, // 24: orchestrationpb.ClientOpts.BatchTimeout:type_name -> google.protobuf.Duration
+	3,  // 25: orchestrationpb.ClientOpts.Resources:type_name -> orchestrationpb.Resources
+	43, // 26: orchestrationpb.ReplicaConfiguration.Replicas:type_name -> orchestrationpb.ReplicaConfiguration.ReplicasEntry
+	44, // 27: orchestrationpb.CreateReplicaRequest.Replicas:type_name -> orchestrationpb.CreateReplicaRequest.ReplicasEntry
+	45, // 28: orchestrationpb.CreateReplicaResponse.Replicas:type_name -> orchestrationpb.CreateReplicaResponse.ReplicasEntry
+	46, // 29: orchestrationpb.StartReplicaRequest.Configuration:type_name -> orchestrationpb.StartReplicaRequest.ConfigurationEntry
+	47, // 30: orchestrationpb.StopReplicaResponse.Hashes:type_name -> orchestrationpb.StopReplicaResponse.HashesEntry
+	48, // 31: orchestrationpb.StopReplicaResponse.StateHashes:type_name -> orchestrationpb.StopReplicaResponse.StateHashesEntry
+	49, // 32: orchestrationpb.StopReplicaResponse.Incompatible:type_name -> orchestrationpb.StopReplicaResponse.IncompatibleEntry
+	50, // 33: orchestrationpb.StopReplicaResponse.ShardHashes:type_name -> orchestrationpb.StopReplicaResponse.ShardHashesEntry
+	51, // 34: orchestrationpb.StartClientRequest.Clients:type_name -> orchestrationpb.StartClientRequest.ClientsEntry
+	52, // 35: orchestrationpb.StartClientRequest.Configuration:type_name -> orchestrationpb.StartClientRequest.ConfigurationEntry
+	53, // 36: orchestrationpb.ShutdownResponse.Hashes:type_name -> orchestrationpb.ShutdownResponse.HashesEntry
+	54, // 37: orchestrationpb.ProgressResponse.Replicas:type_name -> orchestrationpb.ProgressResponse.ReplicasEntry
+	55, // 38: orchestrationpb.ProgressResponse.Clients:type_name -> orchestrationpb.ProgressResponse.ClientsEntry
+	57, // 39: orchestrationpb.LinkEmulation.Latency:type_name -> google.protobuf.Duration
+	57, // 40: orchestrationpb.LinkEmulation.Jitter:type_name -> google.protobuf.Duration
+	33, // 41: orchestrationpb.EmulateNetworkRequest.Links:type_name -> orchestrationpb.LinkEmulation
+	56, // 42: orchestrationpb.VerifyNetworkResponse.RoundTripTimes:type_name -> orchestrationpb.VerifyNetworkResponse.RoundTripTimesEntry
+	57, // 43: orchestrationpb.ReplicaOpts.LatenciesEntry.value:type_name -> google.protobuf.Duration
+	1,  // 44: orchestrationpb.ReplicaOpts.InboundRateLimitsEntry.value:type_name -> orchestrationpb.RateLimit
+	4,  // 45: orchestrationpb.ReplicaConfiguration.ReplicasEntry.value:type_name -> orchestrationpb.ReplicaInfo
+	0,  // 46: orchestrationpb.CreateReplicaRequest.ReplicasEntry.value:type_name -> orchestrationpb.ReplicaOpts
+	4,  // 47: orchestrationpb.CreateReplicaResponse.ReplicasEntry.value:type_name -> orchestrationpb.ReplicaInfo
+	4,  // 48: orchestrationpb.StartReplicaRequest.ConfigurationEntry.value:type_name -> orchestrationpb.ReplicaInfo
+	15, // 49: orchestrationpb.StopReplicaResponse.IncompatibleEntry.value:type_name -> orchestrationpb.IncompatiblePeers
+	14, // 50: orchestrationpb.StopReplicaResponse.ShardHashesEntry.value:type_name -> orchestrationpb.ShardHashes
+	6,  // 51: orchestrationpb.StartClientRequest.ClientsEntry.value:type_name -> orchestrationpb.ClientOpts
+	4,  // 52: orchestrationpb.StartClientRequest.ConfigurationEntry.value:type_name -> orchestrationpb.ReplicaInfo
+	31, // 53: orchestrationpb.ProgressResponse.ReplicasEntry.value:type_name -> orchestrationpb.ReplicaProgress
+	57, // 54: orchestrationpb.VerifyNetworkResponse.RoundTripTimesEntry.value:type_name -> google.protobuf.Duration
+	55, // [55:55] is the sub-list for method output_type
+	55, // [55:55] is the sub-list for method input_type
+	55, // [55:55] is the sub-list for extension type_name
+	55, // [55:55] is the sub-list for extension extendee
+	0,  // [0:55] is the sub-list for field type_name
 }
 
 func init() { file_internal_proto_orchestrationpb_orchestration_proto_init() }
@@ -1256,7 +3969,43 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RateLimit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Fault); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Resources); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ReplicaInfo); i {
 			case 0:
 				return &v.state
@@ -1268,7 +4017,19 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LoadStep); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ClientOpts); i {
 			case 0:
 				return &v.state
@@ -1280,7 +4041,7 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ReplicaConfiguration); i {
 			case 0:
 				return &v.state
@@ -1292,7 +4053,7 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateReplicaRequest); i {
 			case 0:
 				return &v.state
@@ -1304,7 +4065,7 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateReplicaResponse); i {
 			case 0:
 				return &v.state
@@ -1316,7 +4077,7 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*StartReplicaRequest); i {
 			case 0:
 				return &v.state
@@ -1328,7 +4089,7 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*StartReplicaResponse); i {
 			case 0:
 				return &v.state
@@ -1340,7 +4101,7 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*StopReplicaRequest); i {
 			case 0:
 				return &v.state
@@ -1352,7 +4113,7 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*StopReplicaResponse); i {
 			case 0:
 				return &v.state
@@ -1364,7 +4125,31 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShardHashes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IncompatiblePeers); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*StartClientRequest); i {
 			case 0:
 				return &v.state
@@ -1376,7 +4161,7 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*StartClientResponse); i {
 			case 0:
 				return &v.state
@@ -1388,7 +4173,7 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*StopClientRequest); i {
 			case 0:
 				return &v.state
@@ -1400,7 +4185,7 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*StopClientResponse); i {
 			case 0:
 				return &v.state
@@ -1412,7 +4197,7 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*QuitRequest); i {
 			case 0:
 				return &v.state
@@ -1424,16 +4209,244 @@ func file_internal_proto_orchestrationpb_orchestration_proto_init() {
 				return nil
 			}
 		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShutdownRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShutdownResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StartProfileRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StartProfileResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CollectArtifactsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ArtifactChunk); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CollectArtifactsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResetRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResetResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProgressRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReplicaProgress); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProgressResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LinkEmulation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EmulateNetworkRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EmulateNetworkResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyNetworkRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyNetworkResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClearNetworkRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClearNetworkResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[0].OneofWrappers = []interface{}{}
-	file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[10].OneofWrappers = []interface{}{}
+	file_internal_proto_orchestrationpb_orchestration_proto_msgTypes[16].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_internal_proto_orchestrationpb_orchestration_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   22,
+			NumMessages:   57,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,1356 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.1
+// source: internal/proto/replaypb/replay.proto
+
+package replaypb
+
+import (
+	hotstuffpb "github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Entry is an entry of the recording of a replica's execution.
+// The recording holds the events that the replica's event loop processed, in the order in which they were processed,
+// and the other nondeterministic inputs of the consensus modules.
+type Entry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The time at which the entry was recorded.
+	Time *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=Time,proto3" json:"Time,omitempty"`
+	// Types that are assignable to Input:
+	//	*Entry_Replicas
+	//	*Entry_Proposal
+	//	*Entry_Vote
+	//	*Entry_Timeout
+	//	*Entry_NewView
+	//	*Entry_LocalTimeout
+	//	*Entry_Command
+	//	*Entry_Accept
+	//	*Entry_Fetch
+	//	*Entry_Signature
+	//	*Entry_PartialCert
+	//	*Entry_Fetched
+	Input isEntry_Input `protobuf_oneof:"Input"`
+}
+
+func (x *Entry) Reset() {
+	*x = Entry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entry) ProtoMessage() {}
+
+func (x *Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entry.ProtoReflect.Descriptor instead.
+func (*Entry) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Entry) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (m *Entry) GetInput() isEntry_Input {
+	if m != nil {
+		return m.Input
+	}
+	return nil
+}
+
+func (x *Entry) GetReplicas() *Replicas {
+	if x, ok := x.GetInput().(*Entry_Replicas); ok {
+		return x.Replicas
+	}
+	return nil
+}
+
+func (x *Entry) GetProposal() *Proposal {
+	if x, ok := x.GetInput().(*Entry_Proposal); ok {
+		return x.Proposal
+	}
+	return nil
+}
+
+func (x *Entry) GetVote() *Vote {
+	if x, ok := x.GetInput().(*Entry_Vote); ok {
+		return x.Vote
+	}
+	return nil
+}
+
+func (x *Entry) GetTimeout() *Timeout {
+	if x, ok := x.GetInput().(*Entry_Timeout); ok {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Entry) GetNewView() *NewView {
+	if x, ok := x.GetInput().(*Entry_NewView); ok {
+		return x.NewView
+	}
+	return nil
+}
+
+func (x *Entry) GetLocalTimeout() *LocalTimeout {
+	if x, ok := x.GetInput().(*Entry_LocalTimeout); ok {
+		return x.LocalTimeout
+	}
+	return nil
+}
+
+func (x *Entry) GetCommand() *Command {
+	if x, ok := x.GetInput().(*Entry_Command); ok {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *Entry) GetAccept() *Accept {
+	if x, ok := x.GetInput().(*Entry_Accept); ok {
+		return x.Accept
+	}
+	return nil
+}
+
+func (x *Entry) GetFetch() *Fetch {
+	if x, ok := x.GetInput().(*Entry_Fetch); ok {
+		return x.Fetch
+	}
+	return nil
+}
+
+func (x *Entry) GetSignature() *Signature {
+	if x, ok := x.GetInput().(*Entry_Signature); ok {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *Entry) GetPartialCert() *PartialCert {
+	if x, ok := x.GetInput().(*Entry_PartialCert); ok {
+		return x.PartialCert
+	}
+	return nil
+}
+
+func (x *Entry) GetFetched() *Fetched {
+	if x, ok := x.GetInput().(*Entry_Fetched); ok {
+		return x.Fetched
+	}
+	return nil
+}
+
+type isEntry_Input interface {
+	isEntry_Input()
+}
+
+type Entry_Replicas struct {
+	Replicas *Replicas `protobuf:"bytes,2,opt,name=Replicas,proto3,oneof"`
+}
+
+type Entry_Proposal struct {
+	Proposal *Proposal `protobuf:"bytes,3,opt,name=Proposal,proto3,oneof"`
+}
+
+type Entry_Vote struct {
+	Vote *Vote `protobuf:"bytes,4,opt,name=Vote,proto3,oneof"`
+}
+
+type Entry_Timeout struct {
+	Timeout *Timeout `protobuf:"bytes,5,opt,name=Timeout,proto3,oneof"`
+}
+
+type Entry_NewView struct {
+	NewView *NewView `protobuf:"bytes,6,opt,name=NewView,proto3,oneof"`
+}
+
+type Entry_LocalTimeout struct {
+	LocalTimeout *LocalTimeout `protobuf:"bytes,7,opt,name=LocalTimeout,proto3,oneof"`
+}
+
+type Entry_Command struct {
+	Command *Command `protobuf:"bytes,8,opt,name=Command,proto3,oneof"`
+}
+
+type Entry_Accept struct {
+	Accept *Accept `protobuf:"bytes,9,opt,name=Accept,proto3,oneof"`
+}
+
+type Entry_Fetch struct {
+	Fetch *Fetch `protobuf:"bytes,10,opt,name=Fetch,proto3,oneof"`
+}
+
+type Entry_Signature struct {
+	Signature *Signature `protobuf:"bytes,11,opt,name=Signature,proto3,oneof"`
+}
+
+type Entry_PartialCert struct {
+	PartialCert *PartialCert `protobuf:"bytes,12,opt,name=PartialCert,proto3,oneof"`
+}
+
+type Entry_Fetched struct {
+	Fetched *Fetched `protobuf:"bytes,13,opt,name=Fetched,proto3,oneof"`
+}
+
+func (*Entry_Replicas) isEntry_Input() {}
+
+func (*Entry_Proposal) isEntry_Input() {}
+
+func (*Entry_Vote) isEntry_Input() {}
+
+func (*Entry_Timeout) isEntry_Input() {}
+
+func (*Entry_NewView) isEntry_Input() {}
+
+func (*Entry_LocalTimeout) isEntry_Input() {}
+
+func (*Entry_Command) isEntry_Input() {}
+
+func (*Entry_Accept) isEntry_Input() {}
+
+func (*Entry_Fetch) isEntry_Input() {}
+
+func (*Entry_Signature) isEntry_Input() {}
+
+func (*Entry_PartialCert) isEntry_Input() {}
+
+func (*Entry_Fetched) isEntry_Input() {}
+
+// Replicas is the configuration of replicas that the recorded replica connected to.
+type Replicas struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The ID of the recorded replica.
+	ID       uint32     `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Replicas []*Replica `protobuf:"bytes,2,rep,name=Replicas,proto3" json:"Replicas,omitempty"`
+}
+
+func (x *Replicas) Reset() {
+	*x = Replicas{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Replicas) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Replicas) ProtoMessage() {}
+
+func (x *Replicas) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Replicas.ProtoReflect.Descriptor instead.
+func (*Replicas) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Replicas) GetID() uint32 {
+	if x != nil {
+		return x.ID
+	}
+	return 0
+}
+
+func (x *Replicas) GetReplicas() []*Replica {
+	if x != nil {
+		return x.Replicas
+	}
+	return nil
+}
+
+type Replica struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID uint32 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	// The public key of the replica in PEM format.
+	PublicKey []byte `protobuf:"bytes,2,opt,name=PublicKey,proto3" json:"PublicKey,omitempty"`
+}
+
+func (x *Replica) Reset() {
+	*x = Replica{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Replica) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Replica) ProtoMessage() {}
+
+func (x *Replica) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Replica.ProtoReflect.Descriptor instead.
+func (*Replica) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Replica) GetID() uint32 {
+	if x != nil {
+		return x.ID
+	}
+	return 0
+}
+
+func (x *Replica) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+// Proposal is a processed proposal event.
+type Proposal struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID       uint32               `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Proposal *hotstuffpb.Proposal `protobuf:"bytes,2,opt,name=Proposal,proto3" json:"Proposal,omitempty"`
+}
+
+func (x *Proposal) Reset() {
+	*x = Proposal{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Proposal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Proposal) ProtoMessage() {}
+
+func (x *Proposal) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Proposal.ProtoReflect.Descriptor instead.
+func (*Proposal) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Proposal) GetID() uint32 {
+	if x != nil {
+		return x.ID
+	}
+	return 0
+}
+
+func (x *Proposal) GetProposal() *hotstuffpb.Proposal {
+	if x != nil {
+		return x.Proposal
+	}
+	return nil
+}
+
+// Vote is a processed vote event.
+type Vote struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID       uint32                  `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Cert     *hotstuffpb.PartialCert `protobuf:"bytes,2,opt,name=Cert,proto3" json:"Cert,omitempty"`
+	Deferred bool                    `protobuf:"varint,3,opt,name=Deferred,proto3" json:"Deferred,omitempty"`
+}
+
+func (x *Vote) Reset() {
+	*x = Vote{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Vote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vote) ProtoMessage() {}
+
+func (x *Vote) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vote.ProtoReflect.Descriptor instead.
+func (*Vote) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Vote) GetID() uint32 {
+	if x != nil {
+		return x.ID
+	}
+	return 0
+}
+
+func (x *Vote) GetCert() *hotstuffpb.PartialCert {
+	if x != nil {
+		return x.Cert
+	}
+	return nil
+}
+
+func (x *Vote) GetDeferred() bool {
+	if x != nil {
+		return x.Deferred
+	}
+	return false
+}
+
+// Timeout is a processed timeout message event.
+type Timeout struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID  uint32                 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Msg *hotstuffpb.TimeoutMsg `protobuf:"bytes,2,opt,name=Msg,proto3" json:"Msg,omitempty"`
+}
+
+func (x *Timeout) Reset() {
+	*x = Timeout{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Timeout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Timeout) ProtoMessage() {}
+
+func (x *Timeout) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Timeout.ProtoReflect.Descriptor instead.
+func (*Timeout) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Timeout) GetID() uint32 {
+	if x != nil {
+		return x.ID
+	}
+	return 0
+}
+
+func (x *Timeout) GetMsg() *hotstuffpb.TimeoutMsg {
+	if x != nil {
+		return x.Msg
+	}
+	return nil
+}
+
+// NewView is a processed new view event.
+type NewView struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID       uint32               `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	SyncInfo *hotstuffpb.SyncInfo `protobuf:"bytes,2,opt,name=SyncInfo,proto3" json:"SyncInfo,omitempty"`
+}
+
+func (x *NewView) Reset() {
+	*x = NewView{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NewView) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewView) ProtoMessage() {}
+
+func (x *NewView) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewView.ProtoReflect.Descriptor instead.
+func (*NewView) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *NewView) GetID() uint32 {
+	if x != nil {
+		return x.ID
+	}
+	return 0
+}
+
+func (x *NewView) GetSyncInfo() *hotstuffpb.SyncInfo {
+	if x != nil {
+		return x.SyncInfo
+	}
+	return nil
+}
+
+// LocalTimeout is a processed expiry of the view timer.
+type LocalTimeout struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *LocalTimeout) Reset() {
+	*x = LocalTimeout{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LocalTimeout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalTimeout) ProtoMessage() {}
+
+func (x *LocalTimeout) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalTimeout.ProtoReflect.Descriptor instead.
+func (*LocalTimeout) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{7}
+}
+
+// Command is a command returned by the command queue.
+type Command struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Command []byte `protobuf:"bytes,1,opt,name=Command,proto3" json:"Command,omitempty"`
+	OK      bool   `protobuf:"varint,2,opt,name=OK,proto3" json:"OK,omitempty"`
+}
+
+func (x *Command) Reset() {
+	*x = Command{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Command) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Command) ProtoMessage() {}
+
+func (x *Command) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Command.ProtoReflect.Descriptor instead.
+func (*Command) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Command) GetCommand() []byte {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *Command) GetOK() bool {
+	if x != nil {
+		return x.OK
+	}
+	return false
+}
+
+// Accept is the decision of the acceptor for a command.
+type Accept struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Command  []byte `protobuf:"bytes,1,opt,name=Command,proto3" json:"Command,omitempty"`
+	Accepted bool   `protobuf:"varint,2,opt,name=Accepted,proto3" json:"Accepted,omitempty"`
+}
+
+func (x *Accept) Reset() {
+	*x = Accept{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Accept) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Accept) ProtoMessage() {}
+
+func (x *Accept) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Accept.ProtoReflect.Descriptor instead.
+func (*Accept) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Accept) GetCommand() []byte {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *Accept) GetAccepted() bool {
+	if x != nil {
+		return x.Accepted
+	}
+	return false
+}
+
+// Fetch is the result of fetching a block from the other replicas.
+type Fetch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash []byte `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	// The fetched block, if it was found.
+	Block *hotstuffpb.Block `protobuf:"bytes,2,opt,name=Block,proto3" json:"Block,omitempty"`
+}
+
+func (x *Fetch) Reset() {
+	*x = Fetch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Fetch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fetch) ProtoMessage() {}
+
+func (x *Fetch) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fetch.ProtoReflect.Descriptor instead.
+func (*Fetch) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Fetch) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *Fetch) GetBlock() *hotstuffpb.Block {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+// Signature is a signature created by the recorded replica.
+type Signature struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash      []byte                `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	Signature *hotstuffpb.Signature `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
+}
+
+func (x *Signature) Reset() {
+	*x = Signature{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Signature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Signature) ProtoMessage() {}
+
+func (x *Signature) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Signature.ProtoReflect.Descriptor instead.
+func (*Signature) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Signature) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *Signature) GetSignature() *hotstuffpb.Signature {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// PartialCert is a partial certificate created by the recorded replica.
+type PartialCert struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cert *hotstuffpb.PartialCert `protobuf:"bytes,1,opt,name=Cert,proto3" json:"Cert,omitempty"`
+}
+
+func (x *PartialCert) Reset() {
+	*x = PartialCert{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PartialCert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartialCert) ProtoMessage() {}
+
+func (x *PartialCert) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartialCert.ProtoReflect.Descriptor instead.
+func (*PartialCert) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PartialCert) GetCert() *hotstuffpb.PartialCert {
+	if x != nil {
+		return x.Cert
+	}
+	return nil
+}
+
+// Fetched is a processed event that delivered the result of fetching a block from the other replicas in the background.
+type Fetched struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash []byte `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	// The fetched block, if it was found.
+	Block *hotstuffpb.Block `protobuf:"bytes,2,opt,name=Block,proto3" json:"Block,omitempty"`
+}
+
+func (x *Fetched) Reset() {
+	*x = Fetched{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_replaypb_replay_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Fetched) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fetched) ProtoMessage() {}
+
+func (x *Fetched) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_replaypb_replay_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fetched.ProtoReflect.Descriptor instead.
+func (*Fetched) Descriptor() ([]byte, []int) {
+	return file_internal_proto_replaypb_replay_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Fetched) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *Fetched) GetBlock() *hotstuffpb.Block {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+var File_internal_proto_replaypb_replay_proto protoreflect.FileDescriptor
+
+var file_internal_proto_replaypb_replay_proto_rawDesc = []byte{
+	0x0a, 0x24, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x70, 0x62, 0x2f, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x70, 0x62,
+	0x1a, 0x28, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2f, 0x68, 0x6f, 0x74, 0x73,
+	0x74, 0x75, 0x66, 0x66, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x89, 0x05, 0x0a, 0x05,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x2e, 0x0a, 0x04, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x04, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x30, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79,
+	0x70, 0x62, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x48, 0x00, 0x52, 0x08, 0x52,
+	0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x12, 0x30, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x70, 0x6f,
+	0x73, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x72, 0x65, 0x70, 0x6c,
+	0x61, 0x79, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x48, 0x00, 0x52,
+	0x08, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x24, 0x0a, 0x04, 0x56, 0x6f, 0x74,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79,
+	0x70, 0x62, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x48, 0x00, 0x52, 0x04, 0x56, 0x6f, 0x74, 0x65, 0x12,
+	0x2d, 0x0a, 0x07, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x70, 0x62, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x48, 0x00, 0x52, 0x07, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x2d,
+	0x0a, 0x07, 0x4e, 0x65, 0x77, 0x56, 0x69, 0x65, 0x77, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x70, 0x62, 0x2e, 0x4e, 0x65, 0x77, 0x56, 0x69,
+	0x65, 0x77, 0x48, 0x00, 0x52, 0x07, 0x4e, 0x65, 0x77, 0x56, 0x69, 0x65, 0x77, 0x12, 0x3c, 0x0a,
+	0x0c, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x70, 0x62, 0x2e, 0x4c,
+	0x6f, 0x63, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x48, 0x00, 0x52, 0x0c, 0x4c,
+	0x6f, 0x63, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x2d, 0x0a, 0x07, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x72,
+	0x65, 0x70, 0x6c, 0x61, 0x79, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x48,
+	0x00, 0x52, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x2a, 0x0a, 0x06, 0x41, 0x63,
+	0x63, 0x65, 0x70, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x72, 0x65, 0x70,
+	0x6c, 0x61, 0x79, 0x70, 0x62, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x70, 0x74, 0x48, 0x00, 0x52, 0x06,
+	0x41, 0x63, 0x63, 0x65, 0x70, 0x74, 0x12, 0x27, 0x0a, 0x05, 0x46, 0x65, 0x74, 0x63, 0x68, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x70, 0x62,
+	0x2e, 0x46, 0x65, 0x74, 0x63, 0x68, 0x48, 0x00, 0x52, 0x05, 0x46, 0x65, 0x74, 0x63, 0x68, 0x12,
+	0x33, 0x0a, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x13, 0x2e, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x70, 0x62, 0x2e, 0x53, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x12, 0x39, 0x0a, 0x0b, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x43,
+	0x65, 0x72, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x65, 0x70, 0x6c,
+	0x61, 0x79, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x43, 0x65, 0x72, 0x74,
+	0x48, 0x00, 0x52, 0x0b, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x43, 0x65, 0x72, 0x74, 0x12,
+	0x2d, 0x0a, 0x07, 0x46, 0x65, 0x74, 0x63, 0x68, 0x65, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x70, 0x62, 0x2e, 0x46, 0x65, 0x74, 0x63,
+	0x68, 0x65, 0x64, 0x48, 0x00, 0x52, 0x07, 0x46, 0x65, 0x74, 0x63, 0x68, 0x65, 0x64, 0x42, 0x07,
+	0x0a, 0x05, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x22, 0x49, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69,
+	0x63, 0x61, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x02, 0x49, 0x44, 0x12, 0x2d, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x70, 0x62,
+	0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x52, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63,
+	0x61, 0x73, 0x22, 0x37, 0x0a, 0x07, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x12, 0x0e, 0x0a,
+	0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x49, 0x44, 0x12, 0x1c, 0x0a,
+	0x09, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x09, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x22, 0x4c, 0x0a, 0x08, 0x50,
+	0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x02, 0x49, 0x44, 0x12, 0x30, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x70, 0x6f,
+	0x73, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x68, 0x6f, 0x74, 0x73,
+	0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x52,
+	0x08, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x22, 0x5f, 0x0a, 0x04, 0x56, 0x6f, 0x74,
+	0x65, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x49,
+	0x44, 0x12, 0x2b, 0x0a, 0x04, 0x43, 0x65, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x17, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x72,
+	0x74, 0x69, 0x61, 0x6c, 0x43, 0x65, 0x72, 0x74, 0x52, 0x04, 0x43, 0x65, 0x72, 0x74, 0x12, 0x1a,
+	0x0a, 0x08, 0x44, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x08, 0x44, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x64, 0x22, 0x43, 0x0a, 0x07, 0x54, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x02, 0x49, 0x44, 0x12, 0x28, 0x0a, 0x03, 0x4d, 0x73, 0x67, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x67, 0x52, 0x03, 0x4d, 0x73, 0x67, 0x22,
+	0x4b, 0x0a, 0x07, 0x4e, 0x65, 0x77, 0x56, 0x69, 0x65, 0x77, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x49, 0x44, 0x12, 0x30, 0x0a, 0x08, 0x53, 0x79,
+	0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x68,
+	0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e,
+	0x66, 0x6f, 0x52, 0x08, 0x53, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x0e, 0x0a, 0x0c,
+	0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0x33, 0x0a, 0x07,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x12, 0x0e, 0x0a, 0x02, 0x4f, 0x4b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x4f,
+	0x4b, 0x22, 0x3e, 0x0a, 0x06, 0x41, 0x63, 0x63, 0x65, 0x70, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x41, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x41, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65,
+	0x64, 0x22, 0x44, 0x0a, 0x05, 0x46, 0x65, 0x74, 0x63, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61,
+	0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x12, 0x27,
+	0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x52, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x54, 0x0a, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x12, 0x33, 0x0a, 0x09, 0x53, 0x69, 0x67, 0x6e,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x68, 0x6f,
+	0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
+	0x72, 0x65, 0x52, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x3a, 0x0a,
+	0x0b, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x43, 0x65, 0x72, 0x74, 0x12, 0x2b, 0x0a, 0x04,
+	0x43, 0x65, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x68, 0x6f, 0x74,
+	0x73, 0x74, 0x75, 0x66, 0x66, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x43,
+	0x65, 0x72, 0x74, 0x52, 0x04, 0x43, 0x65, 0x72, 0x74, 0x22, 0x46, 0x0a, 0x07, 0x46, 0x65, 0x74,
+	0x63, 0x68, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x12, 0x27, 0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75,
+	0x66, 0x66, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x05, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x72, 0x65, 0x6c, 0x61, 0x62, 0x2f, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x2f, 0x69,
+	0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x72, 0x65,
+	0x70, 0x6c, 0x61, 0x79, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_internal_proto_replaypb_replay_proto_rawDescOnce sync.Once
+	file_internal_proto_replaypb_replay_proto_rawDescData = file_internal_proto_replaypb_replay_proto_rawDesc
+)
+
+func file_internal_proto_replaypb_replay_proto_rawDescGZIP() []byte {
+	file_internal_proto_replaypb_replay_proto_rawDescOnce.Do(func() {
+		file_internal_proto_replaypb_replay_proto_rawDescData = protoimpl.X.CompressGZIP(file_internal_proto_replaypb_replay_proto_rawDescData)
+	})
+	return file_internal_proto_replaypb_replay_proto_rawDescData
+}
+
+var file_internal_proto_replaypb_replay_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_internal_proto_replaypb_replay_proto_goTypes = []interface{}{
+	(*Entry)(nil),                  // 0: replaypb.Entry
+	(*Replicas)(nil),               // 1: replaypb.Replicas
+	(*Replica)(nil),                // 2: replaypb.Replica
+	(*Proposal)(nil),               // 3: replaypb.Proposal
+	(*Vote)(nil),                   // 4: replaypb.Vote
+	(*Timeout)(nil),                // 5: replaypb.Timeout
+	(*NewView)(nil),                // 6: replaypb.NewView
+	(*LocalTimeout)(nil),           // 7: replaypb.LocalTimeout
+	(*Command)(nil),                // 8: replaypb.Command
+	(*Accept)(nil),                 // 9: replaypb.Accept
+	(*Fetch)(nil),                  // 10: replaypb.Fetch
+	(*Signature)(nil),              // 11: replaypb.Signature
+	(*PartialCert)(nil),            // 12: replaypb.PartialCert
+	(*Fetched)(nil),                // 13: replaypb.Fetched
+	(*timestamppb.Timestamp)(nil),  // 14: google.protobuf.Timestamp
+	(*hotstuffpb.Proposal)(nil),    // 15: hotstuffpb.Proposal
+	(*hotstuffpb.PartialCert)(nil), // 16: hotstuffpb.PartialCert
+	(*hotstuffpb.TimeoutMsg)(nil),  // 17: hotstuffpb.TimeoutMsg
+	(*hotstuffpb.SyncInfo)(nil),    // 18: hotstuffpb.SyncInfo
+	(*hotstuffpb.Block)(nil),       // 19: hotstuffpb.Block
+	(*hotstuffpb.Signature)(nil),   // 20: hotstuffpb.Signature
+}
+var file_internal_proto_replaypb_replay_proto_depIdxs = []int32{
+	14, // 0: replaypb.Entry.Time:type_name -> google.protobuf.Timestamp
+	1,  // 1: replaypb.Entry.Replicas:type_name -> replaypb.Replicas
+	3,  // 2: replaypb.Entry.Proposal:type_name -> replaypb.Proposal
+	4,  // 3: replaypb.Entry.Vote:type_name -> replaypb.Vote
+	5,  // 4: replaypb.Entry.Timeout:type_name -> replaypb.Timeout
+	6,  // 5: replaypb.Entry.NewView:type_name -> replaypb.NewView
+	7,  // 6: replaypb.Entry.LocalTimeout:type_name -> replaypb.LocalTimeout
+	8,  // 7: replaypb.Entry.Command:type_name -> replaypb.Command
+	9,  // 8: replaypb.Entry.Accept:type_name -> replaypb.Accept
+	10, // 9: replaypb.Entry.Fetch:type_name -> replaypb.Fetch
+	11, // 10: replaypb.Entry.Signature:type_name -> replaypb.Signature
+	12, // 11: replaypb.Entry.PartialCert:type_name -> replaypb.PartialCert
+	13, // 12: replaypb.Entry.Fetched:type_name -> replaypb.Fetched
+	2,  // 13: replaypb.Replicas.Replicas:type_name -> replaypb.Replica
+	15, // 14: replaypb.Proposal.Proposal:type_name -> hotstuffpb.Proposal
+	16, // 15: replaypb.Vote.Cert:type_name -> hotstuffpb.PartialCert
+	17, // 16: replaypb.Timeout.Msg:type_name -> hotstuffpb.TimeoutMsg
+	18, // 17: replaypb.NewView.SyncInfo:type_name -> hotstuffpb.SyncInfo
+	19, // 18: replaypb.Fetch.Block:type_name -> hotstuffpb.Block
+	20, // 19: replaypb.Signature.Signature:type_name -> hotstuffpb.Signature
+	16, // 20: replaypb.PartialCert.Cert:type_name -> hotstuffpb.PartialCert
+	19, // 21: replaypb.Fetched.Block:type_name -> hotstuffpb.Block
+	22, // [22:22] is the sub-list for method output_type
+	22, // [22:22] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
+}
+
+func init() { file_internal_proto_replaypb_replay_proto_init() }
+func file_internal_proto_replaypb_replay_proto_init() {
+	if File_internal_proto_replaypb_replay_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_internal_proto_replaypb_replay_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Entry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_replaypb_replay_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Replicas); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_replaypb_replay_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Replica); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_replaypb_replay_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Proposal); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_replaypb_replay_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Vote); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_replaypb_replay_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Timeout); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_replaypb_replay_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NewView); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_replaypb_replay_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LocalTimeout); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_replaypb_replay_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Command); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_replaypb_replay_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Accept); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_replaypb_replay_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Fetch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_replaypb_replay_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Signature); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_replaypb_replay_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PartialCert); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_replaypb_replay_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Fetched); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_internal_proto_replaypb_replay_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*Entry_Replicas)(nil),
+		(*Entry_Proposal)(nil),
+		(*Entry_Vote)(nil),
+		(*Entry_Timeout)(nil),
+		(*Entry_NewView)(nil),
+		(*Entry_LocalTimeout)(nil),
+		(*Entry_Command)(nil),
+		(*Entry_Accept)(nil),
+		(*Entry_Fetch)(nil),
+		(*Entry_Signature)(nil),
+		(*Entry_PartialCert)(nil),
+		(*Entry_Fetched)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_internal_proto_replaypb_replay_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_internal_proto_replaypb_replay_proto_goTypes,
+		DependencyIndexes: file_internal_proto_replaypb_replay_proto_depIdxs,
+		MessageInfos:      file_internal_proto_replaypb_replay_proto_msgTypes,
+	}.Build()
+	File_internal_proto_replaypb_replay_proto = out.File
+	file_internal_proto_replaypb_replay_proto_rawDesc = nil
+	file_internal_proto_replaypb_replay_proto_goTypes = nil
+	file_internal_proto_replaypb_replay_proto_depIdxs = nil
+}
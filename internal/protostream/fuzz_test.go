@@ -0,0 +1,64 @@
+//go:build go1.18
+// +build go1.18
+
+package protostream_test
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/relab/hotstuff/internal/protostream"
+	"google.golang.org/protobuf/proto"
+
+	// the message types that are sent over protostreams must be registered to be decoded.
+	_ "github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	_ "github.com/relab/hotstuff/internal/proto/orchestrationpb"
+	_ "github.com/relab/hotstuff/metrics/types"
+)
+
+// allocated returns the number of bytes that f allocates.
+func allocated(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+// FuzzReader decodes arbitrary bytes as a stream of orchestration messages, which is how a worker reads the requests
+// of the controller, and checks that the reader does not panic, that it does not allocate memory out of proportion to
+// the size of the stream, and that the messages it reads are written and read again unchanged. The seed corpus in
+// testdata/fuzz is recorded from an experiment by internal/fuzzcorpus.
+func FuzzReader(f *testing.F) {
+	f.Fuzz(func(t *testing.T, b []byte) {
+		// an unmarshaled message can be much larger than its encoding, since each empty nested message
+		// takes a couple of bytes to encode, but is allocated as a struct with all of its fields.
+		limit := 1<<20 + 1024*uint64(len(b))
+		reader := protostream.NewReader(bytes.NewReader(b))
+		for {
+			var (
+				msg proto.Message
+				err error
+			)
+			if n := allocated(func() { msg, err = reader.ReadAny() }); n > limit {
+				t.Fatalf("reading from a stream of %d bytes allocated %d bytes, want at most %d", len(b), n, limit)
+			}
+			if err != nil {
+				return
+			}
+
+			var buf bytes.Buffer
+			if err := protostream.NewWriter(&buf).WriteAny(msg); err != nil {
+				t.Fatalf("failed to write a message that was read: %v", err)
+			}
+			got, err := protostream.NewReader(&buf).ReadAny()
+			if err != nil {
+				t.Fatalf("failed to read a message that was written: %v", err)
+			}
+			if !proto.Equal(got, msg) {
+				t.Fatalf("the message changed when it was written and read again:\n%v\n%v", msg, got)
+			}
+		}
+	})
+}
@@ -2,6 +2,7 @@
 package protostream
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -109,13 +110,15 @@ func (r *Reader) Read(dst proto.Message) error {
 		return errors.New("protostream: message length is greater than 2 GiB")
 	}
 
-	buf := make([]byte, msgLen)
-	_, err = io.ReadFull(r.src, buf)
+	// the buffer grows as the message is read, such that a corrupt length cannot allocate more memory than the
+	// stream actually holds.
+	var buf bytes.Buffer
+	_, err = io.CopyN(&buf, r.src, int64(msgLen))
 	if err != nil {
 		return fmt.Errorf("protostream: failed to read message: %w", err)
 	}
 
-	err = r.unmarshaler.Unmarshal(buf, dst)
+	err = r.unmarshaler.Unmarshal(buf.Bytes(), dst)
 	if err != nil {
 		return fmt.Errorf("protostream: failed to unmarshal message: %w", err)
 	}
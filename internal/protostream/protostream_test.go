@@ -2,6 +2,7 @@ package protostream_test
 
 import (
 	"bytes"
+	"runtime"
 	"testing"
 
 	"github.com/relab/hotstuff/consensus"
@@ -31,8 +32,32 @@ func TestProtostream(t *testing.T) {
 		t.Fatalf("wrong message type returned: got: %T, want: %T", got, msg)
 	}
 
-	gotBlock := hotstuffpb.BlockFromProto(got)
+	gotBlock, err := hotstuffpb.BlockFromProto(got)
+	if err != nil {
+		t.Fatalf("BlockFromProto failed: %v", err)
+	}
 	if gotBlock.Hash() != consensus.GetGenesis().Hash() {
 		t.Fatalf("message hash did not match")
 	}
 }
+
+func TestCorruptMessageLength(t *testing.T) {
+	// the length prefix claims a message of 1 GiB, but the stream ends after a few bytes.
+	stream := []byte{0, 0, 0, 0x40, 1, 2, 3}
+	reader := protostream.NewReader(bytes.NewReader(stream))
+
+	var (
+		before, after runtime.MemStats
+		err           error
+	)
+	runtime.ReadMemStats(&before)
+	_, err = reader.ReadAny()
+	runtime.ReadMemStats(&after)
+
+	if err == nil {
+		t.Fatal("expected an error for a truncated message")
+	}
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("reading a truncated message allocated %d bytes", n)
+	}
+}
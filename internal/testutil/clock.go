@@ -0,0 +1,99 @@
+package testutil
+
+import (
+	"sync"
+	"time"
+
+	"github.com/relab/hotstuff/eventloop"
+)
+
+// FakeClock is a clock whose time only changes when it is advanced.
+// Its timers fire on the goroutine that advances the clock, when they are due.
+type FakeClock struct {
+	mut    sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFakeClock returns a new fake clock that starts at the given time.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	at     time.Time
+	f      func()
+	active bool
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.now
+}
+
+// AfterFunc returns a timer that calls f when the clock has been advanced by d.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) eventloop.Timer {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	t := &fakeTimer{clock: c, at: c.now.Add(d), f: f, active: true}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mut.Lock()
+	defer t.clock.mut.Unlock()
+	wasActive := t.active
+	t.active = false
+	return wasActive
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mut.Lock()
+	defer t.clock.mut.Unlock()
+	wasActive := t.active
+	t.at = t.clock.now.Add(d)
+	t.active = true
+	return wasActive
+}
+
+// Timers returns the number of timers that have not fired or been stopped.
+// It allows a test to wait until another goroutine has started a timer before advancing the clock.
+func (c *FakeClock) Timers() int {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	n := 0
+	for _, t := range c.timers {
+		if t.active {
+			n++
+		}
+	}
+	return n
+}
+
+// Step moves the time forward by d, and fires the timers that are due in the order of their times.
+func (c *FakeClock) Step(d time.Duration) {
+	c.mut.Lock()
+	end := c.now.Add(d)
+	for {
+		var next *fakeTimer
+		for _, t := range c.timers {
+			if t.active && !t.at.After(end) && (next == nil || t.at.Before(next.at)) {
+				next = t
+			}
+		}
+		if next == nil {
+			break
+		}
+		c.now = next.at
+		next.active = false
+		c.mut.Unlock()
+		next.f()
+		c.mut.Lock()
+	}
+	c.now = end
+	c.mut.Unlock()
+}
@@ -47,6 +47,9 @@ func TestModules(t *testing.T, ctrl *gomock.Controller, id hotstuff.ID, privkey
 	ConfigAddReplica(t, config, replica)
 	config.EXPECT().Replicas().AnyTimes().Return((map[hotstuff.ID]consensus.Replica{1: replica}))
 
+	cs := mocks.NewMockConsensus(ctrl)
+	cs.EXPECT().ChainLength().AnyTimes().Return(3)
+
 	synchronizer := mocks.NewMockSynchronizer(ctrl)
 	synchronizer.EXPECT().Start(gomock.Any()).AnyTimes()
 	synchronizer.EXPECT().ViewContext().AnyTimes().Return(context.Background())
@@ -54,7 +57,7 @@ func TestModules(t *testing.T, ctrl *gomock.Controller, id hotstuff.ID, privkey
 	builder.Register(
 		logging.New(fmt.Sprintf("hs%d", id)),
 		blockchain.New(),
-		mocks.NewMockConsensus(ctrl),
+		cs,
 		leaderrotation.NewFixed(1),
 		synchronizer,
 		config,
@@ -6,11 +6,10 @@ import (
 
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/consensus"
-	"github.com/relab/hotstuff/modules"
 )
 
 func init() {
-	modules.RegisterModule("carousel", NewCarousel)
+	consensus.RegisterLeaderRotation("carousel", NewCarousel)
 }
 
 type carousel struct {
@@ -19,6 +18,7 @@ type carousel struct {
 
 func (c *carousel) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
 	c.mods = mods
+	c.mods.Require(c, consensus.BlockChainModule, consensus.ConfigurationModule, consensus.ConsensusModule)
 }
 
 func (c carousel) GetLeader(round consensus.View) hotstuff.ID {
@@ -8,11 +8,10 @@ import (
 
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/consensus"
-	"github.com/relab/hotstuff/modules"
 )
 
 func init() {
-	modules.RegisterModule("reputation", NewRepBased)
+	consensus.RegisterLeaderRotation("reputation", NewRepBased)
 }
 
 type reputationsMap map[hotstuff.ID]float64
@@ -27,6 +26,7 @@ type repBased struct {
 // It also allows the module to set module options using the OptionsBuilder
 func (r *repBased) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
 	r.mods = mods
+	r.mods.Require(r, consensus.ConfigurationModule, consensus.ConsensusModule)
 }
 
 // TODO: should GetLeader be thread-safe?
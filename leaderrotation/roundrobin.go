@@ -3,11 +3,10 @@ package leaderrotation
 import (
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/consensus"
-	"github.com/relab/hotstuff/modules"
 )
 
 func init() {
-	modules.RegisterModule("round-robin", NewRoundRobin)
+	consensus.RegisterLeaderRotation("round-robin", NewRoundRobin)
 }
 
 type roundRobin struct {
@@ -18,6 +17,7 @@ type roundRobin struct {
 // It also allows the module to set module options using the OptionsBuilder.
 func (rr *roundRobin) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
 	rr.mods = mods
+	rr.mods.Require(rr, consensus.ConfigurationModule)
 }
 
 // GetLeader returns the id of the leader in the given view
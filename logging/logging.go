@@ -17,6 +17,7 @@ import (
 var (
 	logLevel      zapcore.Level
 	packageLevels = make(map[string]zapcore.Level)
+	capture       io.Writer
 	mut           sync.RWMutex
 )
 
@@ -55,6 +56,14 @@ func SetPackageLogLevel(packageName, levelStr string) {
 	mut.Unlock()
 }
 
+// SetCapture sets a writer that receives a copy of the output of the loggers that are created by New after it is set.
+// Capturing is disabled if w is nil.
+func SetCapture(w io.Writer) {
+	mut.Lock()
+	capture = w
+	mut.Unlock()
+}
+
 // Logger is the logging interface used by consensus. It is based on zap.SugaredLogger
 type Logger interface {
 	DPanic(args ...interface{})
@@ -218,11 +227,18 @@ func New(name string) Logger {
 	}
 	mut.RLock()
 	config.Level.SetLevel(logLevel)
+	captureDest := capture
 	mut.RUnlock()
 	l, err := config.Build(zap.AddCallerSkip(1))
 	if err != nil {
 		panic(err)
 	}
+	if captureDest != nil {
+		captureCore := zapcore.NewCore(zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()), zapcore.AddSync(captureDest), config.Level)
+		l = l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, captureCore)
+		}))
+	}
 	return &wrapper{inner: l.Sugar().Named(name), level: config.Level}
 }
 
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/metrics/types"
+	"github.com/relab/hotstuff/modules"
+)
+
+func init() {
+	RegisterReplicaMetric("batch", func() interface{} {
+		return &Batch{}
+	})
+}
+
+// Batch is a metric that measures the sizes of the batches of commands that the replica proposes,
+// the number of commands that wait in each lane of its command queue, and the number of commands of forked blocks
+// that are put back in the queue.
+type Batch struct {
+	mods        *modules.Modules
+	batches     uint64
+	commands    uint64
+	urgent      uint64
+	bytes       uint64
+	maxCommands uint64
+	pending     map[string]uint64
+	requeued    uint64
+	skipped     uint64
+}
+
+// InitModule gives the module access to the other modules.
+func (b *Batch) InitModule(mods *modules.Modules) {
+	b.mods = mods
+
+	b.mods.Logger().Info("Batch metric enabled.")
+
+	b.mods.EventLoop().RegisterHandler(consensus.BatchEvent{}, func(event interface{}) {
+		b.batch(event.(consensus.BatchEvent))
+	})
+
+	b.mods.EventLoop().RegisterHandler(consensus.RequeueEvent{}, func(event interface{}) {
+		requeue := event.(consensus.RequeueEvent)
+		b.requeued += uint64(requeue.Commands)
+		b.skipped += uint64(requeue.Skipped)
+	})
+
+	b.mods.EventLoop().RegisterObserver(types.TickEvent{}, func(event interface{}) {
+		b.tick(event.(types.TickEvent))
+	})
+}
+
+func (b *Batch) batch(event consensus.BatchEvent) {
+	b.batches++
+	b.commands += uint64(event.Commands)
+	b.urgent += uint64(event.Urgent)
+	b.pending = map[string]uint64{"client": uint64(event.Pending), "urgent": uint64(event.UrgentPending)}
+	b.bytes += uint64(event.Bytes)
+	if uint64(event.Commands) > b.maxCommands {
+		b.maxCommands = uint64(event.Commands)
+	}
+}
+
+func (b *Batch) tick(_ types.TickEvent) {
+	b.mods.MetricsLogger().Log(&types.BatchMeasurement{
+		Event:          types.NewReplicaEvent(uint32(b.mods.ID()), b.mods.Clock().Now()),
+		Batches:        b.batches,
+		Commands:       b.commands,
+		Bytes:          b.bytes,
+		MaxCommands:    b.maxCommands,
+		UrgentCommands: b.urgent,
+		Pending:        b.pending,
+		Requeued:       b.requeued,
+		RequeueSkipped: b.skipped,
+	})
+	b.batches = 0
+	b.commands = 0
+	b.urgent = 0
+	b.bytes = 0
+	b.maxCommands = 0
+	b.requeued = 0
+	b.skipped = 0
+}
@@ -1,8 +1,10 @@
 package metrics
 
 import (
+	"sort"
 	"time"
 
+	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/client"
 	"github.com/relab/hotstuff/metrics/types"
 	"github.com/relab/hotstuff/modules"
@@ -14,19 +16,72 @@ func init() {
 	})
 }
 
-// ClientLatency processes LatencyMeasurementEvents, and writes LatencyMeasurements to the metrics logger.
+// ClientLatency processes LatencyMeasurementEvents, as well as events about dropped, retried, failed, and abandoned commands
+// and about the batches, the adaptive window, and the target rate, and writes LatencyMeasurements to the metrics logger.
+// If the client runs several sessions, a measurement is written for each session, with the session's ID.
 type ClientLatency struct {
-	mods *modules.Modules
-	wf   Welford
+	mods     *modules.Modules
+	sessions map[hotstuff.ID]*sessionLatency
+}
+
+// sessionLatency holds the measurements of a session since the last tick.
+type sessionLatency struct {
+	wf        Welford
+	dropped   uint64
+	failed    uint64
+	retries   uint64
+	abandoned uint64
+	batches   uint64  // the number of batches sent
+	batched   uint64  // the number of commands in the batches
+	window    uint32  // the current size of the adaptive window, which is kept between ticks
+	rate      float64 // the current target rate, which is kept between ticks
+	step      uint32  // the current step of the load profile, which is kept between ticks
 }
 
 // InitModule gives the module access to the other modules.
 func (lr *ClientLatency) InitModule(mods *modules.Modules) {
 	lr.mods = mods
+	// the first session has the client's ID, and is measured even if it has not reported anything.
+	lr.sessions = map[hotstuff.ID]*sessionLatency{mods.ID(): {}}
 
 	lr.mods.EventLoop().RegisterHandler(client.LatencyMeasurementEvent{}, func(event interface{}) {
 		latencyEvent := event.(client.LatencyMeasurementEvent)
-		lr.addLatency(latencyEvent.Latency)
+		lr.session(latencyEvent.Session).addLatency(latencyEvent.Latency)
+	})
+
+	lr.mods.EventLoop().RegisterHandler(client.CommandDroppedEvent{}, func(event interface{}) {
+		lr.session(event.(client.CommandDroppedEvent).Session).dropped++
+	})
+
+	lr.mods.EventLoop().RegisterHandler(client.CommandFailedEvent{}, func(event interface{}) {
+		lr.session(event.(client.CommandFailedEvent).Session).failed++
+	})
+
+	lr.mods.EventLoop().RegisterHandler(client.CommandAbandonedEvent{}, func(event interface{}) {
+		lr.session(event.(client.CommandAbandonedEvent).Session).abandoned++
+	})
+
+	lr.mods.EventLoop().RegisterHandler(client.CommandRetriedEvent{}, func(event interface{}) {
+		lr.session(event.(client.CommandRetriedEvent).Session).retries++
+	})
+
+	lr.mods.EventLoop().RegisterHandler(client.WindowSizeEvent{}, func(event interface{}) {
+		windowEvent := event.(client.WindowSizeEvent)
+		lr.session(windowEvent.Session).window = uint32(windowEvent.Size)
+	})
+
+	lr.mods.EventLoop().RegisterHandler(client.BatchSentEvent{}, func(event interface{}) {
+		batchEvent := event.(client.BatchSentEvent)
+		s := lr.session(batchEvent.Session)
+		s.batches++
+		s.batched += uint64(batchEvent.Size)
+	})
+
+	lr.mods.EventLoop().RegisterHandler(client.TargetRateEvent{}, func(event interface{}) {
+		rateEvent := event.(client.TargetRateEvent)
+		s := lr.session(rateEvent.Session)
+		s.rate = rateEvent.Rate
+		s.step = uint32(rateEvent.Step)
 	})
 
 	lr.mods.EventLoop().RegisterObserver(types.TickEvent{}, func(event interface{}) {
@@ -36,20 +91,57 @@ func (lr *ClientLatency) InitModule(mods *modules.Modules) {
 	lr.mods.Logger().Info("Client Latency metric enabled")
 }
 
+func (lr *ClientLatency) session(id hotstuff.ID) *sessionLatency {
+	s, ok := lr.sessions[id]
+	if !ok {
+		s = &sessionLatency{}
+		lr.sessions[id] = s
+	}
+	return s
+}
+
 // AddLatency adds a latency data point to the current measurement.
-func (lr *ClientLatency) addLatency(latency time.Duration) {
+func (s *sessionLatency) addLatency(latency time.Duration) {
 	millis := float64(latency) / float64(time.Millisecond)
-	lr.wf.Update(millis)
+	s.wf.Update(millis)
 }
 
 func (lr *ClientLatency) tick(tick types.TickEvent) {
-	mean, variance, count := lr.wf.Get()
-	event := &types.LatencyMeasurement{
-		Event:    types.NewClientEvent(uint32(lr.mods.ID()), time.Now()),
-		Latency:  mean,
-		Variance: variance,
-		Count:    count,
+	ids := make([]hotstuff.ID, 0, len(lr.sessions))
+	for id := range lr.sessions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	now := lr.mods.Clock().Now()
+	for _, id := range ids {
+		s := lr.sessions[id]
+		mean, variance, count := s.wf.Get()
+		var batchSize float64
+		if s.batches > 0 {
+			batchSize = float64(s.batched) / float64(s.batches)
+		}
+		event := &types.LatencyMeasurement{
+			Event:      types.NewClientEvent(uint32(id), now),
+			Latency:    mean,
+			Variance:   variance,
+			Count:      count,
+			Dropped:    s.dropped,
+			Failed:     s.failed,
+			Retries:    s.retries,
+			Window:     s.window,
+			Abandoned:  s.abandoned,
+			TargetRate: s.rate,
+			LoadStep:   s.step,
+			BatchSize:  batchSize,
+		}
+		lr.mods.MetricsLogger().Log(event)
+		s.wf.Reset()
+		s.dropped = 0
+		s.failed = 0
+		s.retries = 0
+		s.abandoned = 0
+		s.batches = 0
+		s.batched = 0
 	}
-	lr.mods.MetricsLogger().Log(event)
-	lr.wf.Reset()
 }
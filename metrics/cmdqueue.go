@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/metrics/types"
+	"github.com/relab/hotstuff/modules"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func init() {
+	RegisterReplicaMetric("cmdqueue", func() interface{} {
+		return &CommandQueue{}
+	})
+}
+
+// CommandQueue is a metric that measures the depth of the command queue of the replica, how long the client commands
+// wait in it before they are included in a batch, and how full the batches are relative to their limits.
+// The command queue reports its statistics at each tick.
+type CommandQueue struct {
+	mods *modules.Modules
+}
+
+// InitModule gives the module access to the other modules.
+func (q *CommandQueue) InitModule(mods *modules.Modules) {
+	q.mods = mods
+
+	q.mods.Logger().Info("CommandQueue metric enabled.")
+
+	q.mods.EventLoop().RegisterHandler(consensus.CommandQueueEvent{}, func(event interface{}) {
+		q.log(event.(consensus.CommandQueueEvent))
+	})
+}
+
+func (q *CommandQueue) log(event consensus.CommandQueueEvent) {
+	var meanWait time.Duration
+	if event.Commands > 0 {
+		meanWait = event.Wait / time.Duration(event.Commands)
+	}
+	q.mods.MetricsLogger().Log(&types.CommandQueueMeasurement{
+		Event:     types.NewReplicaEvent(uint32(q.mods.ID()), q.mods.Clock().Now()),
+		Depth:     uint64(event.Depth),
+		MaxDepth:  uint64(event.MaxDepth),
+		Commands:  uint64(event.Commands),
+		MeanWait:  durationpb.New(meanWait),
+		CountFill: event.CountFill[:],
+		BytesFill: event.BytesFill[:],
+	})
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/metrics/types"
+	"github.com/relab/hotstuff/modules"
+)
+
+func init() {
+	RegisterReplicaMetric("commits", func() interface{} {
+		return &Commits{}
+	})
+}
+
+// Commits is a metric that measures the number of blocks committed by the replica,
+// the number of commits per view, and the number of commits per proposer.
+type Commits struct {
+	mods       *modules.Modules
+	numCommits uint64
+	proposers  map[hotstuff.ID]uint64
+	lastView   consensus.View // the view of the last committed block
+	tickView   consensus.View // the view of the last committed block at the previous tick
+}
+
+// InitModule gives the module access to the other modules.
+func (c *Commits) InitModule(mods *modules.Modules) {
+	c.mods = mods
+	c.proposers = make(map[hotstuff.ID]uint64)
+
+	c.mods.Logger().Info("Commits metric enabled.")
+
+	c.mods.EventLoop().RegisterHandler(consensus.BlockCommitEvent{}, func(event interface{}) {
+		c.commit(event.(consensus.BlockCommitEvent))
+	})
+
+	c.mods.EventLoop().RegisterObserver(types.TickEvent{}, func(event interface{}) {
+		c.tick(event.(types.TickEvent))
+	})
+}
+
+func (c *Commits) commit(event consensus.BlockCommitEvent) {
+	c.numCommits++
+	c.proposers[event.Block.Proposer()]++
+	c.lastView = event.Block.View()
+}
+
+func (c *Commits) tick(_ types.TickEvent) {
+	measurement := &types.CommitMeasurement{
+		Event:     types.NewReplicaEvent(uint32(c.mods.ID()), c.mods.Clock().Now()),
+		Commits:   c.numCommits,
+		Views:     uint64(c.lastView - c.tickView),
+		Proposers: make(map[uint32]uint64, len(c.proposers)),
+	}
+	for id, commits := range c.proposers {
+		measurement.Proposers[uint32(id)] = commits
+	}
+	c.mods.MetricsLogger().Log(measurement)
+	c.numCommits = 0
+	c.proposers = make(map[hotstuff.ID]uint64)
+	c.tickView = c.lastView
+}
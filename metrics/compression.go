@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/metrics/types"
+	"github.com/relab/hotstuff/modules"
+)
+
+func init() {
+	RegisterReplicaMetric("compression", func() interface{} {
+		return &Compression{}
+	})
+}
+
+// Compression is a metric that measures the size of proposal commands before and after compression.
+type Compression struct {
+	mods            *modules.Modules
+	numProposals    uint64
+	rawBytes        uint64
+	compressedBytes uint64
+}
+
+// InitModule gives the module access to the other modules.
+func (c *Compression) InitModule(mods *modules.Modules) {
+	c.mods = mods
+
+	c.mods.Logger().Info("Compression metric enabled.")
+
+	c.mods.EventLoop().RegisterHandler(backend.ProposalCompressedEvent{}, func(event interface{}) {
+		c.proposalCompressed(event.(backend.ProposalCompressedEvent))
+	})
+
+	c.mods.EventLoop().RegisterObserver(types.TickEvent{}, func(event interface{}) {
+		c.tick(event.(types.TickEvent))
+	})
+}
+
+func (c *Compression) proposalCompressed(event backend.ProposalCompressedEvent) {
+	c.numProposals++
+	c.rawBytes += uint64(event.RawSize)
+	c.compressedBytes += uint64(event.CompressedSize)
+}
+
+func (c *Compression) tick(_ types.TickEvent) {
+	c.mods.MetricsLogger().Log(&types.CompressionMeasurement{
+		Event:           types.NewReplicaEvent(uint32(c.mods.ID()), c.mods.Clock().Now()),
+		Proposals:       c.numProposals,
+		RawBytes:        c.rawBytes,
+		CompressedBytes: c.compressedBytes,
+	})
+	c.numProposals = 0
+	c.rawBytes = 0
+	c.compressedBytes = 0
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"github.com/relab/hotstuff/metrics/types"
+	"github.com/relab/hotstuff/modules"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func init() {
+	RegisterReplicaMetric("eventloop", func() interface{} {
+		return &EventLoop{}
+	})
+}
+
+// EventLoop is a metric that measures the number of events of each type that the event loop of the replica
+// drops because its event queues are full, the panics that it recovers from while handling them, and the number of
+// events of each type that it processes, along with the time spent on them.
+type EventLoop struct {
+	mods             *modules.Modules
+	dropped          map[string]uint64
+	panics           map[string]uint64
+	disabledHandlers map[string]uint64
+}
+
+// InitModule gives the module access to the other modules.
+func (el *EventLoop) InitModule(mods *modules.Modules) {
+	el.mods = mods
+
+	el.mods.Logger().Info("EventLoop metric enabled.")
+	el.mods.EventLoop().EnableStats()
+
+	el.mods.EventLoop().RegisterObserver(types.TickEvent{}, func(event interface{}) {
+		el.tick(event.(types.TickEvent))
+	})
+}
+
+func (el *EventLoop) tick(_ types.TickEvent) {
+	eventLoop := el.mods.EventLoop()
+	measurement := &types.EventLoopMeasurement{
+		Event:            types.NewReplicaEvent(uint32(el.mods.ID()), el.mods.Clock().Now()),
+		Dropped:          countsSince(&el.dropped, eventLoop.DroppedEvents()),
+		Panics:           countsSince(&el.panics, eventLoop.Panics()),
+		DisabledHandlers: countsSince(&el.disabledHandlers, eventLoop.DisabledHandlers()),
+		Stats:            make(map[string]*types.EventStats),
+	}
+	for eventType, stats := range eventLoop.TakeStats() {
+		measurement.Stats[eventType] = &types.EventStats{
+			Count: stats.Count,
+			Total: durationpb.New(stats.Total),
+			Max:   durationpb.New(stats.Max),
+		}
+	}
+	el.mods.MetricsLogger().Log(measurement)
+}
+
+// countsSince returns the counts that have increased since the previous reading, and replaces the previous reading.
+func countsSince(previous *map[string]uint64, current map[string]uint64) map[string]uint64 {
+	diffs := make(map[string]uint64)
+	for eventType, n := range current {
+		if diff := n - (*previous)[eventType]; diff > 0 {
+			diffs[eventType] = diff
+		}
+	}
+	*previous = current
+	return diffs
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/metrics/types"
+	"github.com/relab/hotstuff/modules"
+)
+
+func init() {
+	RegisterReplicaMetric("network", func() interface{} {
+		return &Network{}
+	})
+}
+
+// Network is a metric that measures the number and size of the messages sent and received by the replica,
+// for each type of message.
+type Network struct {
+	mods     *modules.Modules
+	previous backend.NetworkStats
+}
+
+// InitModule gives the module access to the other modules.
+func (n *Network) InitModule(mods *modules.Modules) {
+	n.mods = mods
+
+	n.mods.Logger().Info("Network metric enabled.")
+
+	n.mods.EventLoop().RegisterObserver(types.TickEvent{}, func(event interface{}) {
+		n.tick(event.(types.TickEvent))
+	})
+}
+
+func (n *Network) tick(_ types.TickEvent) {
+	var cfg *backend.Config
+	if !n.mods.GetModuleByType(&cfg) {
+		return
+	}
+	current := cfg.NetworkStats()
+	diff := current.Sub(n.previous)
+	n.previous = current
+
+	measurement := &types.NetworkMeasurement{
+		Event:    types.NewReplicaEvent(uint32(n.mods.ID()), n.mods.Clock().Now()),
+		Messages: make(map[string]*types.NetworkCounters, len(diff)),
+	}
+	for class, s := range diff {
+		measurement.Messages[class.String()] = &types.NetworkCounters{
+			Sent:          s.Sent,
+			Received:      s.Received,
+			Failed:        s.Failed,
+			SentBytes:     s.SentBytes,
+			ReceivedBytes: s.ReceivedBytes,
+			Duplicates:    s.Duplicates,
+		}
+	}
+	n.mods.MetricsLogger().Log(measurement)
+}
@@ -0,0 +1,56 @@
+package plotting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ByzantineReplicas collects the byzantine strategies that the replicas ran, such that plots can be annotated with them.
+// The strategies are read from the replica options that are recorded at the start of each replica.
+type ByzantineReplicas struct {
+	strategies map[uint32]string
+}
+
+// NewByzantineReplicas returns a new ByzantineReplicas instance.
+func NewByzantineReplicas() ByzantineReplicas {
+	return ByzantineReplicas{strategies: make(map[uint32]string)}
+}
+
+// Add adds a measurement.
+func (b *ByzantineReplicas) Add(msg interface{}) {
+	opts, ok := msg.(interface {
+		GetID() uint32
+		GetByzantineStrategy() string
+	})
+	if !ok || opts.GetByzantineStrategy() == "" {
+		return
+	}
+	b.strategies[opts.GetID()] = opts.GetByzantineStrategy()
+}
+
+// Strategy returns the byzantine strategy of the replica with the specified id.
+// The strategy is empty if the replica was correct.
+func (b *ByzantineReplicas) Strategy(id uint32) string {
+	return b.strategies[id]
+}
+
+// IDs returns the IDs of the byzantine replicas in increasing order.
+func (b *ByzantineReplicas) IDs() []uint32 {
+	ids := make([]uint32, 0, len(b.strategies))
+	for id := range b.strategies {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// String returns a description of the byzantine replicas, such as "2: silence, 4: fork",
+// which is suitable for annotating plots.
+func (b *ByzantineReplicas) String() string {
+	var parts []string
+	for _, id := range b.IDs() {
+		parts = append(parts, fmt.Sprintf("%d: %s", id, b.strategies[id]))
+	}
+	return strings.Join(parts, ", ")
+}
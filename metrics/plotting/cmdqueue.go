@@ -0,0 +1,113 @@
+package plotting
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/relab/hotstuff/metrics/types"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+)
+
+// CommandQueuePlot plots how long the commands wait in the command queues of the replicas,
+// and summarizes the depth of the queues and the fill of the batches.
+type CommandQueuePlot struct {
+	startTimes   StartTimes
+	measurements MeasurementMap
+	summary      CommandQueueSummary // the summary, except for the means
+	depths       uint64              // the sum of the depths of the measurements
+	readings     uint64              // the number of measurements
+	wait         time.Duration       // the total time that the included commands waited
+}
+
+// CommandQueueSummary summarizes the command queues of all replicas.
+type CommandQueueSummary struct {
+	Depth     float64       // the mean number of commands in a queue when it was measured
+	MaxDepth  uint64        // the largest number of commands in any queue
+	Commands  uint64        // the number of commands that were included in batches
+	MeanWait  time.Duration // the mean time that the included commands waited in the queues
+	CountFill []uint64      // the histogram of the number of commands in the batches relative to the batch size
+	BytesFill []uint64      // the histogram of the size of the batches relative to their byte limit
+}
+
+// NewCommandQueuePlot returns a new command queue plotter.
+func NewCommandQueuePlot() CommandQueuePlot {
+	return CommandQueuePlot{
+		startTimes:   NewStartTimes(),
+		measurements: NewMeasurementMap(),
+	}
+}
+
+// Add adds a measurement to the plotter.
+func (p *CommandQueuePlot) Add(measurement interface{}) {
+	p.startTimes.Add(measurement)
+
+	queue, ok := measurement.(*types.CommandQueueMeasurement)
+	if !ok {
+		return
+	}
+	p.measurements.Add(queue.GetEvent().GetID(), queue)
+
+	s := &p.summary
+	p.depths += queue.GetDepth()
+	p.readings++
+	p.wait += queue.GetMeanWait().AsDuration() * time.Duration(queue.GetCommands())
+	s.Commands += queue.GetCommands()
+	if queue.GetMaxDepth() > s.MaxDepth {
+		s.MaxDepth = queue.GetMaxDepth()
+	}
+	s.CountFill = addHistogram(s.CountFill, queue.GetCountFill())
+	s.BytesFill = addHistogram(s.BytesFill, queue.GetBytesFill())
+}
+
+// addHistogram adds the counts of the histogram to the sum, which is extended if it has fewer buckets.
+func addHistogram(sum, histogram []uint64) []uint64 {
+	for len(sum) < len(histogram) {
+		sum = append(sum, 0)
+	}
+	for i, count := range histogram {
+		sum[i] += count
+	}
+	return sum
+}
+
+// Summary returns the summary of the command queues of all replicas.
+func (p *CommandQueuePlot) Summary() CommandQueueSummary {
+	summary := p.summary
+	if p.readings > 0 {
+		summary.Depth = float64(p.depths) / float64(p.readings)
+	}
+	if summary.Commands > 0 {
+		summary.MeanWait = p.wait / time.Duration(summary.Commands)
+	}
+	return summary
+}
+
+// PlotWait plots the mean time that the commands waited in the command queues at specified time intervals.
+func (p *CommandQueuePlot) PlotWait(filename string, measurementInterval time.Duration) (err error) {
+	const (
+		xlabel = "Time (seconds)"
+		ylabel = "Queue wait (ms)"
+	)
+	if path.Ext(filename) == ".csv" {
+		return CSVPlot(filename, []string{xlabel, ylabel}, func() plotter.XYer {
+			return avgQueueWait(p, measurementInterval)
+		})
+	}
+	return GonumPlot(filename, xlabel, ylabel, func(plt *plot.Plot) error {
+		if err := plotutil.AddLinePoints(plt, avgQueueWait(p, measurementInterval)); err != nil {
+			return fmt.Errorf("failed to add line plot: %w", err)
+		}
+		return nil
+	})
+}
+
+func avgQueueWait(p *CommandQueuePlot, interval time.Duration) plotter.XYer {
+	intervals := GroupByTimeInterval(&p.startTimes, p.measurements, interval)
+	return TimeAndAverage(intervals, func(m Measurement) (float64, uint64) {
+		queue := m.(*types.CommandQueueMeasurement)
+		return float64(queue.GetMeanWait().AsDuration()) / float64(time.Millisecond), queue.GetCommands()
+	})
+}
@@ -0,0 +1,96 @@
+package plotting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/relab/hotstuff/metrics/types"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+)
+
+// Faults collects the faults that were injected into the replicas, such that plots can mark them.
+type Faults struct {
+	startTimes StartTimes
+	faults     []*types.FaultEvent
+}
+
+// Fault is a fault that was injected into a replica.
+type Fault struct {
+	ID       uint32
+	Action   string
+	Offset   time.Duration // the time from the start of the replica until the fault was injected
+	Duration time.Duration // zero if the replica stayed crashed or the fault was a restart
+}
+
+// NewFaults returns a new Faults instance.
+func NewFaults() Faults {
+	return Faults{startTimes: NewStartTimes()}
+}
+
+// Add adds a measurement.
+func (f *Faults) Add(msg interface{}) {
+	f.startTimes.Add(msg)
+	if fault, ok := msg.(*types.FaultEvent); ok {
+		f.faults = append(f.faults, fault)
+	}
+}
+
+// Faults returns the faults in the order in which they were injected.
+// Faults of replicas without a recorded start time are left out.
+func (f *Faults) Faults() []Fault {
+	faults := make([]Fault, 0, len(f.faults))
+	for _, fault := range f.faults {
+		offset, ok := f.startTimes.ReplicaOffset(fault.GetEvent().GetID(), fault.GetEvent().GetTimestamp().AsTime())
+		if !ok {
+			continue
+		}
+		faults = append(faults, Fault{
+			ID:       fault.GetEvent().GetID(),
+			Action:   fault.GetAction(),
+			Offset:   offset,
+			Duration: fault.GetDuration().AsDuration(),
+		})
+	}
+	sort.SliceStable(faults, func(i, j int) bool { return faults[i].Offset < faults[j].Offset })
+	return faults
+}
+
+// String returns a description of the faults, such as "2: crash at 30s for 15s, 2: restart at 45s".
+func (f *Faults) String() string {
+	var parts []string
+	for _, fault := range f.Faults() {
+		part := fmt.Sprintf("%d: %s at %v", fault.ID, fault.Action, fault.Offset.Round(time.Millisecond))
+		if fault.Duration > 0 {
+			part += fmt.Sprintf(" for %v", fault.Duration)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// mark adds a vertical line to the plot at the time of each fault, and at the end of each fault with a duration.
+func (f *Faults) mark(plt *plot.Plot) error {
+	for i, fault := range f.Faults() {
+		times := []time.Duration{fault.Offset}
+		if fault.Duration > 0 {
+			times = append(times, fault.Offset+fault.Duration)
+		}
+		for j, t := range times {
+			line, err := plotter.NewLine(plotter.XYs{{X: t.Seconds(), Y: plt.Y.Min}, {X: t.Seconds(), Y: plt.Y.Max}})
+			if err != nil {
+				return fmt.Errorf("failed to add fault marker: %w", err)
+			}
+			line.Color = plotutil.Color(i + 1)
+			line.Dashes = plotutil.Dashes(1)
+			plt.Add(line)
+			if j == 0 {
+				plt.Legend.Add(fmt.Sprintf("replica %d: %s", fault.ID, fault.Action), line)
+			}
+		}
+	}
+	return nil
+}
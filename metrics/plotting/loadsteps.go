@@ -0,0 +1,87 @@
+package plotting
+
+import (
+	"sort"
+	"time"
+
+	"github.com/relab/hotstuff/metrics/types"
+)
+
+// LoadSteps groups the latency measurements of the clients by the step of their load profile,
+// such that a single run can be summarized at each load level.
+type LoadSteps struct {
+	steps map[uint32]map[uint32]*clientStep
+}
+
+// clientStep holds the measurements of a client during a load step.
+type clientStep struct {
+	intervals uint64
+	rate      float64 // the sum of the target rates
+	commands  uint64
+	latency   float64 // the sum of the latencies, weighted by the number of commands
+	dropped   uint64
+	failed    uint64
+}
+
+// LoadStepSummary summarizes the measurements of all clients during a load step.
+type LoadStepSummary struct {
+	Step       uint32
+	TargetRate float64 // the total target rate of the clients, in commands per second
+	Throughput float64 // the total rate of executed commands, in commands per second
+	Latency    float64 // the mean latency in milliseconds
+	Commands   uint64
+	Dropped    uint64
+	Failed     uint64
+}
+
+// NewLoadSteps returns a new load step summary.
+func NewLoadSteps() LoadSteps {
+	return LoadSteps{steps: make(map[uint32]map[uint32]*clientStep)}
+}
+
+// Add adds a measurement to the summary. Measurements from clients without a load profile are ignored.
+func (l *LoadSteps) Add(measurement interface{}) {
+	latency, ok := measurement.(*types.LatencyMeasurement)
+	if !ok || !latency.GetEvent().GetClient() || latency.GetLoadStep() == 0 {
+		return
+	}
+	clients, ok := l.steps[latency.GetLoadStep()]
+	if !ok {
+		clients = make(map[uint32]*clientStep)
+		l.steps[latency.GetLoadStep()] = clients
+	}
+	c, ok := clients[latency.GetEvent().GetID()]
+	if !ok {
+		c = &clientStep{}
+		clients[latency.GetEvent().GetID()] = c
+	}
+	c.intervals++
+	c.rate += latency.GetTargetRate()
+	c.commands += latency.GetCount()
+	c.latency += latency.GetLatency() * float64(latency.GetCount())
+	c.dropped += latency.GetDropped()
+	c.failed += latency.GetFailed()
+}
+
+// Summaries returns a summary of each load step, in order. The measurement interval is needed to compute the throughput.
+func (l *LoadSteps) Summaries(measurementInterval time.Duration) []LoadStepSummary {
+	summaries := make([]LoadStepSummary, 0, len(l.steps))
+	for step, clients := range l.steps {
+		summary := LoadStepSummary{Step: step}
+		var latency float64
+		for _, c := range clients {
+			summary.TargetRate += c.rate / float64(c.intervals)
+			summary.Throughput += float64(c.commands) / (float64(c.intervals) * measurementInterval.Seconds())
+			summary.Commands += c.commands
+			summary.Dropped += c.dropped
+			summary.Failed += c.failed
+			latency += c.latency
+		}
+		if summary.Commands > 0 {
+			summary.Latency = latency / float64(summary.Commands)
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Step < summaries[j].Step })
+	return summaries
+}
@@ -0,0 +1,65 @@
+package plotting
+
+import (
+	"math"
+	"sort"
+
+	"github.com/relab/hotstuff/metrics/types"
+)
+
+// LatencyPercentiles merges the latency histograms written by the clients when they stop,
+// and computes percentiles of the latencies of all commands.
+type LatencyPercentiles struct {
+	buckets map[float64]uint64
+	count   uint64
+	max     float64
+}
+
+// NewLatencyPercentiles returns a new latency percentiles summary.
+func NewLatencyPercentiles() LatencyPercentiles {
+	return LatencyPercentiles{buckets: make(map[float64]uint64)}
+}
+
+// Add adds a measurement to the summary.
+func (p *LatencyPercentiles) Add(measurement interface{}) {
+	histogram, ok := measurement.(*types.LatencyHistogram)
+	if !ok {
+		return
+	}
+	for _, bucket := range histogram.GetBuckets() {
+		p.buckets[bucket.GetUpperBound()] += bucket.GetCount()
+	}
+	p.count += histogram.GetCount()
+	if histogram.GetMax() > p.max {
+		p.max = histogram.GetMax()
+	}
+}
+
+// Count returns the number of latencies in the summary.
+func (p *LatencyPercentiles) Count() uint64 {
+	return p.count
+}
+
+// Percentile returns the latency in milliseconds below which the given percentage of the latencies fall.
+func (p *LatencyPercentiles) Percentile(percentile float64) float64 {
+	if p.count == 0 {
+		return math.NaN()
+	}
+	bounds := make([]float64, 0, len(p.buckets))
+	for bound := range p.buckets {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+	rank := uint64(math.Ceil(percentile / 100 * float64(p.count)))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen uint64
+	for _, bound := range bounds {
+		seen += p.buckets[bound]
+		if seen >= rank {
+			return math.Min(bound, p.max)
+		}
+	}
+	return p.max
+}
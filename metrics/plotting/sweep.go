@@ -0,0 +1,253 @@
+package plotting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/relab/hotstuff/internal/atomicfile"
+	"github.com/relab/hotstuff/metrics/types"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+)
+
+// SweepIndexFile is the name of the index in the output directory of a parameter sweep.
+const SweepIndexFile = "index.json"
+
+// SweepIndex lists the runs of a parameter sweep, such that their measurements can be grouped by their parameters.
+type SweepIndex struct {
+	// Parameters are the names of the parameters that the sweep varies, in the order of the run directory names.
+	Parameters []string   `json:"parameters"`
+	Runs       []SweepRun `json:"runs"`
+}
+
+// SweepRun is a run of a parameter sweep.
+type SweepRun struct {
+	Dir        string            `json:"dir"` // the directory of the measurements of the run, relative to the index
+	Parameters map[string]string `json:"parameters"`
+	Repetition int               `json:"repetition"`
+	Error      string            `json:"error,omitempty"` // the reason that the run failed, if it did
+}
+
+// ReadSweepIndex reads the index of a parameter sweep.
+func ReadSweepIndex(filename string) (*SweepIndex, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var index SweepIndex
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("failed to read sweep index: %w", err)
+	}
+	return &index, nil
+}
+
+// Write writes the index to the file, replacing the previous index atomically,
+// such that the index lists the completed runs if the sweep is interrupted.
+func (index *SweepIndex) Write(filename string) error {
+	b, err := json.MarshalIndent(index, "", "\t")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, b)
+}
+
+// SweepSummary summarizes the runs of a parameter sweep that have the same parameters.
+type SweepSummary struct {
+	Parameters map[string]string
+	Runs       int     // the number of successful runs
+	Failed     int     // the number of failed runs
+	Throughput float64 // the mean throughput of the replicas, in commands per second, averaged over the runs
+	Latency    float64 // the mean latency of the clients in milliseconds, averaged over the runs
+}
+
+// String returns the parameters of the summary, such as "batch-size=100 rate-limit=1000".
+func (s SweepSummary) String() string {
+	names := make([]string, 0, len(s.Parameters))
+	for name := range s.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+s.Parameters[name])
+	}
+	return strings.Join(parts, " ")
+}
+
+// SummarizeSweep reads the measurements of the runs listed by the index file,
+// and summarizes the runs that have the same parameters, in the order of the index.
+// The measurements of a run are read from every file in its directory whose name begins with "measurements.json",
+// such that the files fetched from remote workers are included.
+func SummarizeSweep(indexFile string) ([]SweepSummary, error) {
+	index, err := ReadSweepIndex(indexFile)
+	if err != nil {
+		return nil, err
+	}
+	var summaries []SweepSummary
+	find := func(params map[string]string) *SweepSummary {
+	search:
+		for i := range summaries {
+			for _, name := range index.Parameters {
+				if summaries[i].Parameters[name] != params[name] {
+					continue search
+				}
+			}
+			return &summaries[i]
+		}
+		summaries = append(summaries, SweepSummary{Parameters: params})
+		return &summaries[len(summaries)-1]
+	}
+	for _, run := range index.Runs {
+		summary := find(run.Parameters)
+		if run.Error != "" {
+			summary.Failed++
+			continue
+		}
+		s := newRunSummary()
+		files, err := filepath.Glob(filepath.Join(filepath.Dir(indexFile), run.Dir, "measurements.json*"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if err := readMeasurements(file, s); err != nil {
+				return nil, err
+			}
+		}
+		summary.Throughput += s.meanThroughput()
+		summary.Latency += s.meanLatency()
+		summary.Runs++
+	}
+	for i := range summaries {
+		if summaries[i].Runs > 0 {
+			summaries[i].Throughput /= float64(summaries[i].Runs)
+			summaries[i].Latency /= float64(summaries[i].Runs)
+		}
+	}
+	return summaries, nil
+}
+
+func readMeasurements(filename string, plotters ...Plotter) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := NewReader(f, plotters...).ReadAll(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	return nil
+}
+
+// runSummary computes the mean throughput and latency of a run.
+type runSummary struct {
+	throughput map[uint32]*replicaThroughput
+	latency    float64 // the sum of the latencies, weighted by the number of commands
+	commands   uint64
+}
+
+func newRunSummary() *runSummary {
+	return &runSummary{throughput: make(map[uint32]*replicaThroughput)}
+}
+
+type replicaThroughput struct {
+	commands uint64
+	seconds  float64
+}
+
+func (s *runSummary) Add(measurement interface{}) {
+	switch m := measurement.(type) {
+	case *types.ThroughputMeasurement:
+		if m.GetEvent().GetClient() {
+			return
+		}
+		t, ok := s.throughput[m.GetEvent().GetID()]
+		if !ok {
+			t = &replicaThroughput{}
+			s.throughput[m.GetEvent().GetID()] = t
+		}
+		t.commands += m.GetCommands()
+		t.seconds += m.GetDuration().AsDuration().Seconds()
+	case *types.LatencyMeasurement:
+		if !m.GetEvent().GetClient() {
+			return
+		}
+		s.latency += m.GetLatency() * float64(m.GetCount())
+		s.commands += m.GetCount()
+	}
+}
+
+func (s *runSummary) meanThroughput() float64 {
+	var sum float64
+	var replicas int
+	for _, t := range s.throughput {
+		if t.seconds > 0 {
+			sum += float64(t.commands) / t.seconds
+			replicas++
+		}
+	}
+	if replicas == 0 {
+		return 0
+	}
+	return sum / float64(replicas)
+}
+
+func (s *runSummary) meanLatency() float64 {
+	if s.commands == 0 {
+		return 0
+	}
+	return s.latency / float64(s.commands)
+}
+
+// PlotSweep plots the latency against the throughput of the summaries of a sweep.
+// The summaries are plotted as one line for each value of the series parameter, or as a single line if it is empty.
+// A CSV file lists the points of all lines.
+func PlotSweep(filename string, summaries []SweepSummary, series string) error {
+	const (
+		xlabel = "Throughput (commands/second)"
+		ylabel = "Latency (ms)"
+	)
+	var (
+		names  []string
+		points = make(map[string]xyer)
+	)
+	for _, summary := range summaries {
+		if summary.Runs == 0 {
+			continue
+		}
+		name := "runs"
+		if series != "" {
+			name = series + "=" + summary.Parameters[series]
+		}
+		if _, ok := points[name]; !ok {
+			names = append(names, name)
+		}
+		points[name] = append(points[name], point{x: summary.Throughput, y: summary.Latency})
+	}
+	if path.Ext(filename) == ".csv" {
+		return CSVPlot(filename, []string{xlabel, ylabel}, func() plotter.XYer {
+			var all xyer
+			for _, name := range names {
+				all = append(all, points[name]...)
+			}
+			return all
+		})
+	}
+	return GonumPlot(filename, xlabel, ylabel, func(plt *plot.Plot) error {
+		var lines []interface{}
+		for _, name := range names {
+			xy := points[name]
+			sort.Slice(xy, func(i, j int) bool { return xy[i].x < xy[j].x })
+			lines = append(lines, name, xy)
+		}
+		if err := plotutil.AddLinePoints(plt, lines...); err != nil {
+			return fmt.Errorf("failed to add line plot: %w", err)
+		}
+		return nil
+	})
+}
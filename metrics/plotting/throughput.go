@@ -15,6 +15,7 @@ import (
 type ThroughputPlot struct {
 	startTimes   StartTimes
 	measurements MeasurementMap
+	faults       Faults
 }
 
 // NewThroughputPlot returns a new throughput plotter.
@@ -22,12 +23,14 @@ func NewThroughputPlot() ThroughputPlot {
 	return ThroughputPlot{
 		startTimes:   NewStartTimes(),
 		measurements: NewMeasurementMap(),
+		faults:       NewFaults(),
 	}
 }
 
 // Add adds a measurement to the plotter.
 func (p *ThroughputPlot) Add(measurement interface{}) {
 	p.startTimes.Add(measurement)
+	p.faults.Add(measurement)
 
 	throughput, ok := measurement.(*types.ThroughputMeasurement)
 	if !ok {
@@ -44,6 +47,7 @@ func (p *ThroughputPlot) Add(measurement interface{}) {
 }
 
 // PlotAverage plots the average throughput of all replicas at specified time intervals.
+// The faults that were injected into the replicas are marked by vertical lines.
 func (p *ThroughputPlot) PlotAverage(filename string, measurementInterval time.Duration) (err error) {
 	const (
 		xlabel = "Time (seconds)"
@@ -58,7 +62,7 @@ func (p *ThroughputPlot) PlotAverage(filename string, measurementInterval time.D
 		if err := plotutil.AddLinePoints(plt, avgThroughput(p, measurementInterval)); err != nil {
 			return fmt.Errorf("failed to add line plot: %w", err)
 		}
-		return nil
+		return p.faults.mark(plt)
 	})
 }
 
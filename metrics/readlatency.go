@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/relab/hotstuff/client"
+	"github.com/relab/hotstuff/metrics/types"
+	"github.com/relab/hotstuff/modules"
+)
+
+func init() {
+	RegisterClientMetric("read-latency", func() interface{} {
+		return &ReadLatency{}
+	})
+}
+
+// ReadLatency processes QueryLatencyMeasurementEvents and QueryFailedEvents,
+// and writes ReadLatencyMeasurements to the metrics logger.
+type ReadLatency struct {
+	mods   *modules.Modules
+	wf     Welford
+	failed uint64
+}
+
+// InitModule gives the module access to the other modules.
+func (rl *ReadLatency) InitModule(mods *modules.Modules) {
+	rl.mods = mods
+
+	rl.mods.EventLoop().RegisterHandler(client.QueryLatencyMeasurementEvent{}, func(event interface{}) {
+		latency := event.(client.QueryLatencyMeasurementEvent).Latency
+		rl.wf.Update(float64(latency) / float64(time.Millisecond))
+	})
+
+	rl.mods.EventLoop().RegisterHandler(client.QueryFailedEvent{}, func(_ interface{}) {
+		rl.failed++
+	})
+
+	rl.mods.EventLoop().RegisterObserver(types.TickEvent{}, func(event interface{}) {
+		rl.tick(event.(types.TickEvent))
+	})
+
+	rl.mods.Logger().Info("Read Latency metric enabled")
+}
+
+func (rl *ReadLatency) tick(_ types.TickEvent) {
+	mean, variance, count := rl.wf.Get()
+	event := &types.ReadLatencyMeasurement{
+		Event:    types.NewClientEvent(uint32(rl.mods.ID()), rl.mods.Clock().Now()),
+		Latency:  mean,
+		Variance: variance,
+		Count:    count,
+		Failed:   rl.failed,
+	}
+	rl.mods.MetricsLogger().Log(event)
+	rl.wf.Reset()
+	rl.failed = 0
+}
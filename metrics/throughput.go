@@ -1,8 +1,6 @@
 package metrics
 
 import (
-	"time"
-
 	"github.com/relab/hotstuff/consensus"
 	"github.com/relab/hotstuff/metrics/types"
 	"github.com/relab/hotstuff/modules"
@@ -41,7 +39,7 @@ func (t *Throughput) recordCommit(commands int) {
 }
 
 func (t *Throughput) tick(tick types.TickEvent) {
-	now := time.Now()
+	now := t.mods.Clock().Now()
 	event := &types.ThroughputMeasurement{
 		Event:    types.NewReplicaEvent(uint32(t.mods.ID()), now),
 		Commits:  t.commitCount,
@@ -7,12 +7,15 @@ import (
 	"github.com/relab/hotstuff/modules"
 )
 
-// Ticker emits TickEvents on the metrics event loop.
+// Ticker emits TickEvents on the event loop. It uses a ticker of the event loop, such that the events are produced
+// on the event loop, and follow the clock of the modules.
 type Ticker struct {
 	mods     *modules.Modules
 	tickerID int
 	interval time.Duration
-	lastTick time.Time
+	lastTick time.Time // only accessed from the event loop.
+	// the time of the last measurement, which is only accessed from the event loop.
+	lastMeasured time.Time
 }
 
 // NewTicker returns a new ticker.
@@ -24,6 +27,14 @@ func NewTicker(interval time.Duration) *Ticker {
 func (t *Ticker) InitModule(mods *modules.Modules) {
 	t.mods = mods
 	t.tickerID = t.mods.EventLoop().AddTicker(t.interval, t.tick)
+	t.lastMeasured = t.mods.Clock().Now()
+	t.mods.EventLoop().RegisterObserver(types.TickEvent{}, func(_ interface{}) {
+		t.lastMeasured = t.mods.Clock().Now()
+	})
+	// the final measurements cover the time since the last tick, such that they are not lost when the event loop is shut down.
+	t.mods.EventLoop().RegisterShutdownHook(func() interface{} {
+		return types.TickEvent{LastTick: t.lastMeasured}
+	})
 }
 
 func (t *Ticker) tick(tickTime time.Time) interface{} {
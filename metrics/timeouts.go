@@ -1,8 +1,6 @@
 package metrics
 
 import (
-	"time"
-
 	"github.com/relab/hotstuff/metrics/types"
 	"github.com/relab/hotstuff/modules"
 	"github.com/relab/hotstuff/synchronizer"
@@ -45,7 +43,7 @@ func (vt *ViewTimeouts) viewChange(event synchronizer.ViewChangeEvent) {
 
 func (vt *ViewTimeouts) tick(event types.TickEvent) {
 	vt.mods.MetricsLogger().Log(&types.ViewTimeouts{
-		Event:    types.NewReplicaEvent(uint32(vt.mods.ID()), time.Now()),
+		Event:    types.NewReplicaEvent(uint32(vt.mods.ID()), vt.mods.Clock().Now()),
 		Views:    vt.numViews,
 		Timeouts: vt.numTimeouts,
 	})
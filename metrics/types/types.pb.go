@@ -28,6 +28,25 @@ type StartEvent struct {
 	unknownFields protoimpl.UnknownFields
 
 	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	// The payloads generated by a client. Not set for replicas.
+	Payload *Payload `protobuf:"bytes,2,opt,name=Payload,proto3" json:"Payload,omitempty"`
+	// The resolved configuration of the experiment, such that the measurements describe how they were produced.
+	// Empty if the experiment was not started with a configuration.
+	Config string `protobuf:"bytes,3,opt,name=Config,proto3" json:"Config,omitempty"`
+	// The modules of a replica, by the kind of module, such as "consensus" or "crypto".
+	// Not set for clients.
+	Modules map[string]string `protobuf:"bytes,4,rep,name=Modules,proto3" json:"Modules,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The seed of the random number generators of the replica or client.
+	Seed int64 `protobuf:"varint,5,opt,name=Seed,proto3" json:"Seed,omitempty"`
+	// The master seed of the experiment that the seed was derived from.
+	// Zero if the replica or client was not started by an experiment.
+	MasterSeed int64 `protobuf:"varint,6,opt,name=MasterSeed,proto3" json:"MasterSeed,omitempty"`
+	// The CPUs that the process of the replica or client was pinned to when it
+	// was started. Empty if the process was not pinned.
+	CPUs []uint32 `protobuf:"varint,7,rep,packed,name=CPUs,proto3" json:"CPUs,omitempty"`
+	// The GOMAXPROCS of the process of the replica or client when it was
+	// started.
+	MaxProcs uint32 `protobuf:"varint,8,opt,name=MaxProcs,proto3" json:"MaxProcs,omitempty"`
 }
 
 func (x *StartEvent) Reset() {
@@ -69,21 +88,67 @@ func (x *StartEvent) GetEvent() *Event {
 	return nil
 }
 
-// Event is the basic type that is recorded by hotstuff.
-// It contains the ID of the replica/client, the type (replica/client),
-// the timestamp of the event, and the data.
-type Event struct {
+func (x *StartEvent) GetPayload() *Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *StartEvent) GetConfig() string {
+	if x != nil {
+		return x.Config
+	}
+	return ""
+}
+
+func (x *StartEvent) GetModules() map[string]string {
+	if x != nil {
+		return x.Modules
+	}
+	return nil
+}
+
+func (x *StartEvent) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *StartEvent) GetMasterSeed() int64 {
+	if x != nil {
+		return x.MasterSeed
+	}
+	return 0
+}
+
+func (x *StartEvent) GetCPUs() []uint32 {
+	if x != nil {
+		return x.CPUs
+	}
+	return nil
+}
+
+func (x *StartEvent) GetMaxProcs() uint32 {
+	if x != nil {
+		return x.MaxProcs
+	}
+	return 0
+}
+
+// ShutdownEvent is logged when a replica has been shut down gracefully.
+// It follows the final measurements of the replica, and marks that they were flushed.
+type ShutdownEvent struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ID        uint32                 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Client    bool                   `protobuf:"varint,2,opt,name=Client,proto3" json:"Client,omitempty"`
-	Timestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
+	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
 }
 
-func (x *Event) Reset() {
-	*x = Event{}
+func (x *ShutdownEvent) Reset() {
+	*x = ShutdownEvent{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_metrics_types_types_proto_msgTypes[1]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -91,13 +156,13 @@ func (x *Event) Reset() {
 	}
 }
 
-func (x *Event) String() string {
+func (x *ShutdownEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Event) ProtoMessage() {}
+func (*ShutdownEvent) ProtoMessage() {}
 
-func (x *Event) ProtoReflect() protoreflect.Message {
+func (x *ShutdownEvent) ProtoReflect() protoreflect.Message {
 	mi := &file_metrics_types_types_proto_msgTypes[1]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -109,60 +174,1412 @@ func (x *Event) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Event.ProtoReflect.Descriptor instead.
-func (*Event) Descriptor() ([]byte, []int) {
+// Deprecated: Use ShutdownEvent.ProtoReflect.Descriptor instead.
+func (*ShutdownEvent) Descriptor() ([]byte, []int) {
 	return file_metrics_types_types_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *Event) GetID() uint32 {
+func (x *ShutdownEvent) GetEvent() *Event {
 	if x != nil {
-		return x.ID
+		return x.Event
+	}
+	return nil
+}
+
+// CheckpointEvent is logged for each checkpoint recorded by the key-value
+// store of a replica when the replica is stopped. Replicas that executed the
+// same commands in the same order have recorded the same hashes.
+type CheckpointEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	// The number of commands executed by the store before the checkpoint.
+	Index uint64 `protobuf:"varint,2,opt,name=Index,proto3" json:"Index,omitempty"`
+	// The hash of the state of the store at the checkpoint.
+	Hash []byte `protobuf:"bytes,3,opt,name=Hash,proto3" json:"Hash,omitempty"`
+}
+
+func (x *CheckpointEvent) Reset() {
+	*x = CheckpointEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckpointEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckpointEvent) ProtoMessage() {}
+
+func (x *CheckpointEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckpointEvent.ProtoReflect.Descriptor instead.
+func (*CheckpointEvent) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CheckpointEvent) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *CheckpointEvent) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
 	}
 	return 0
 }
 
-func (x *Event) GetClient() bool {
+func (x *CheckpointEvent) GetHash() []byte {
 	if x != nil {
-		return x.Client
+		return x.Hash
 	}
-	return false
+	return nil
 }
 
-func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+// FaultEvent is logged when a fault is injected into a replica,
+// and when a crashed replica is restarted.
+type FaultEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	// The injected fault: "crash", "pause", or "restart".
+	Action string `protobuf:"bytes,2,opt,name=Action,proto3" json:"Action,omitempty"`
+	// How long the fault lasts, or zero if the replica stays crashed or the fault is a restart.
+	Duration *durationpb.Duration `protobuf:"bytes,3,opt,name=Duration,proto3" json:"Duration,omitempty"`
+}
+
+func (x *FaultEvent) Reset() {
+	*x = FaultEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FaultEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FaultEvent) ProtoMessage() {}
+
+func (x *FaultEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FaultEvent.ProtoReflect.Descriptor instead.
+func (*FaultEvent) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FaultEvent) GetEvent() *Event {
 	if x != nil {
-		return x.Timestamp
+		return x.Event
 	}
 	return nil
 }
 
-type ThroughputMeasurement struct {
+func (x *FaultEvent) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *FaultEvent) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+// NetworkEmulationEvent is logged by a worker for each emulated link from its
+// host to another host, when the round-trip time to the host was measured
+// before the experiment. The round-trip time includes the latencies of the
+// links in both directions.
+type NetworkEmulationEvent struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Event    *Event               `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
-	Commits  uint64               `protobuf:"varint,2,opt,name=Commits,proto3" json:"Commits,omitempty"`
-	Commands uint64               `protobuf:"varint,3,opt,name=Commands,proto3" json:"Commands,omitempty"`
-	Duration *durationpb.Duration `protobuf:"bytes,4,opt,name=Duration,proto3" json:"Duration,omitempty"`
+	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	// The name of the other host.
+	Host string `protobuf:"bytes,2,opt,name=Host,proto3" json:"Host,omitempty"`
+	// The emulated one-way latency to the host.
+	Latency *durationpb.Duration `protobuf:"bytes,3,opt,name=Latency,proto3" json:"Latency,omitempty"`
+	Jitter  *durationpb.Duration `protobuf:"bytes,4,opt,name=Jitter,proto3" json:"Jitter,omitempty"`
+	// The emulated percentage of dropped packets.
+	Loss float64 `protobuf:"fixed64,5,opt,name=Loss,proto3" json:"Loss,omitempty"`
+	// The measured average round-trip time to the host.
+	RoundTripTime *durationpb.Duration `protobuf:"bytes,6,opt,name=RoundTripTime,proto3" json:"RoundTripTime,omitempty"`
+}
+
+func (x *NetworkEmulationEvent) Reset() {
+	*x = NetworkEmulationEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NetworkEmulationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkEmulationEvent) ProtoMessage() {}
+
+func (x *NetworkEmulationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkEmulationEvent.ProtoReflect.Descriptor instead.
+func (*NetworkEmulationEvent) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NetworkEmulationEvent) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *NetworkEmulationEvent) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *NetworkEmulationEvent) GetLatency() *durationpb.Duration {
+	if x != nil {
+		return x.Latency
+	}
+	return nil
+}
+
+func (x *NetworkEmulationEvent) GetJitter() *durationpb.Duration {
+	if x != nil {
+		return x.Jitter
+	}
+	return nil
+}
+
+func (x *NetworkEmulationEvent) GetLoss() float64 {
+	if x != nil {
+		return x.Loss
+	}
+	return 0
+}
+
+func (x *NetworkEmulationEvent) GetRoundTripTime() *durationpb.Duration {
+	if x != nil {
+		return x.RoundTripTime
+	}
+	return nil
+}
+
+// Payload describes the payloads generated by a client.
+type Payload struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Distribution string `protobuf:"bytes,1,opt,name=Distribution,proto3" json:"Distribution,omitempty"`
+	// The size with the fixed distribution.
+	Size         uint32  `protobuf:"varint,2,opt,name=Size,proto3" json:"Size,omitempty"`
+	Min          uint32  `protobuf:"varint,3,opt,name=Min,proto3" json:"Min,omitempty"`
+	Max          uint32  `protobuf:"varint,4,opt,name=Max,proto3" json:"Max,omitempty"`
+	ZipfS        float64 `protobuf:"fixed64,5,opt,name=ZipfS,proto3" json:"ZipfS,omitempty"`
+	Seed         int64   `protobuf:"varint,6,opt,name=Seed,proto3" json:"Seed,omitempty"`
+	Compressible bool    `protobuf:"varint,7,opt,name=Compressible,proto3" json:"Compressible,omitempty"`
+}
+
+func (x *Payload) Reset() {
+	*x = Payload{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Payload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Payload) ProtoMessage() {}
+
+func (x *Payload) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Payload.ProtoReflect.Descriptor instead.
+func (*Payload) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Payload) GetDistribution() string {
+	if x != nil {
+		return x.Distribution
+	}
+	return ""
+}
+
+func (x *Payload) GetSize() uint32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Payload) GetMin() uint32 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *Payload) GetMax() uint32 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *Payload) GetZipfS() float64 {
+	if x != nil {
+		return x.ZipfS
+	}
+	return 0
+}
+
+func (x *Payload) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *Payload) GetCompressible() bool {
+	if x != nil {
+		return x.Compressible
+	}
+	return false
+}
+
+// Event is the basic type that is recorded by hotstuff.
+// It contains the ID of the replica/client, the type (replica/client),
+// the timestamp of the event, and the data.
+type Event struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID        uint32                 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Client    bool                   `protobuf:"varint,2,opt,name=Client,proto3" json:"Client,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
+	// The consensus instance of a replica that runs several instances in one process.
+	Instance uint32 `protobuf:"varint,4,opt,name=Instance,proto3" json:"Instance,omitempty"`
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Event) GetID() uint32 {
+	if x != nil {
+		return x.ID
+	}
+	return 0
+}
+
+func (x *Event) GetClient() bool {
+	if x != nil {
+		return x.Client
+	}
+	return false
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Event) GetInstance() uint32 {
+	if x != nil {
+		return x.Instance
+	}
+	return 0
+}
+
+type ThroughputMeasurement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event    *Event               `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	Commits  uint64               `protobuf:"varint,2,opt,name=Commits,proto3" json:"Commits,omitempty"`
+	Commands uint64               `protobuf:"varint,3,opt,name=Commands,proto3" json:"Commands,omitempty"`
+	Duration *durationpb.Duration `protobuf:"bytes,4,opt,name=Duration,proto3" json:"Duration,omitempty"`
+}
+
+func (x *ThroughputMeasurement) Reset() {
+	*x = ThroughputMeasurement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ThroughputMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThroughputMeasurement) ProtoMessage() {}
+
+func (x *ThroughputMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThroughputMeasurement.ProtoReflect.Descriptor instead.
+func (*ThroughputMeasurement) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ThroughputMeasurement) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *ThroughputMeasurement) GetCommits() uint64 {
+	if x != nil {
+		return x.Commits
+	}
+	return 0
+}
+
+func (x *ThroughputMeasurement) GetCommands() uint64 {
+	if x != nil {
+		return x.Commands
+	}
+	return 0
+}
+
+func (x *ThroughputMeasurement) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+type LatencyMeasurement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event    *Event  `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	Latency  float64 `protobuf:"fixed64,2,opt,name=Latency,proto3" json:"Latency,omitempty"`
+	Variance float64 `protobuf:"fixed64,3,opt,name=Variance,proto3" json:"Variance,omitempty"`
+	Count    uint64  `protobuf:"varint,4,opt,name=Count,proto3" json:"Count,omitempty"`
+	// The number of commands dropped by an open-loop client because too many commands were in flight.
+	Dropped uint64 `protobuf:"varint,5,opt,name=Dropped,proto3" json:"Dropped,omitempty"`
+	// The number of commands that failed, for example because the last retry timed out.
+	Failed uint64 `protobuf:"varint,6,opt,name=Failed,proto3" json:"Failed,omitempty"`
+	// The number of times that commands were sent again because they timed out.
+	Retries uint64 `protobuf:"varint,7,opt,name=Retries,proto3" json:"Retries,omitempty"`
+	// The number of commands that may be in flight, if the client adapts it to the latency.
+	Window uint32 `protobuf:"varint,8,opt,name=Window,proto3" json:"Window,omitempty"`
+	// The number of commands that were still in flight when the client stopped, after the drain timeout.
+	Abandoned uint64 `protobuf:"varint,9,opt,name=Abandoned,proto3" json:"Abandoned,omitempty"`
+	// The rate in commands per second at which an open-loop client issued commands at the end of the interval.
+	TargetRate float64 `protobuf:"fixed64,10,opt,name=TargetRate,proto3" json:"TargetRate,omitempty"`
+	// The step of the client's load profile at the end of the interval, starting from one, or zero if there is no profile.
+	LoadStep uint32 `protobuf:"varint,11,opt,name=LoadStep,proto3" json:"LoadStep,omitempty"`
+	// The mean number of commands in the batches sent by the client in the interval, or zero if no batches were sent.
+	BatchSize float64 `protobuf:"fixed64,12,opt,name=BatchSize,proto3" json:"BatchSize,omitempty"`
+}
+
+func (x *LatencyMeasurement) Reset() {
+	*x = LatencyMeasurement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LatencyMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LatencyMeasurement) ProtoMessage() {}
+
+func (x *LatencyMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LatencyMeasurement.ProtoReflect.Descriptor instead.
+func (*LatencyMeasurement) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LatencyMeasurement) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *LatencyMeasurement) GetLatency() float64 {
+	if x != nil {
+		return x.Latency
+	}
+	return 0
+}
+
+func (x *LatencyMeasurement) GetVariance() float64 {
+	if x != nil {
+		return x.Variance
+	}
+	return 0
+}
+
+func (x *LatencyMeasurement) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *LatencyMeasurement) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+func (x *LatencyMeasurement) GetFailed() uint64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *LatencyMeasurement) GetRetries() uint64 {
+	if x != nil {
+		return x.Retries
+	}
+	return 0
+}
+
+func (x *LatencyMeasurement) GetWindow() uint32 {
+	if x != nil {
+		return x.Window
+	}
+	return 0
+}
+
+func (x *LatencyMeasurement) GetAbandoned() uint64 {
+	if x != nil {
+		return x.Abandoned
+	}
+	return 0
+}
+
+func (x *LatencyMeasurement) GetTargetRate() float64 {
+	if x != nil {
+		return x.TargetRate
+	}
+	return 0
+}
+
+func (x *LatencyMeasurement) GetLoadStep() uint32 {
+	if x != nil {
+		return x.LoadStep
+	}
+	return 0
+}
+
+func (x *LatencyMeasurement) GetBatchSize() float64 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+// LatencyHistogram holds the latencies of all commands sent by a client, and is written when the client stops.
+// The latencies are recorded with a bounded relative error, such that percentiles can be computed without the raw samples.
+type LatencyHistogram struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	// The non-empty buckets in increasing order.
+	Buckets []*HistogramBucket `protobuf:"bytes,2,rep,name=Buckets,proto3" json:"Buckets,omitempty"`
+	// The number of recorded latencies.
+	Count uint64 `protobuf:"varint,3,opt,name=Count,proto3" json:"Count,omitempty"`
+	// The minimum and maximum latency in milliseconds.
+	Min float64 `protobuf:"fixed64,4,opt,name=Min,proto3" json:"Min,omitempty"`
+	Max float64 `protobuf:"fixed64,5,opt,name=Max,proto3" json:"Max,omitempty"`
+}
+
+func (x *LatencyHistogram) Reset() {
+	*x = LatencyHistogram{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LatencyHistogram) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LatencyHistogram) ProtoMessage() {}
+
+func (x *LatencyHistogram) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LatencyHistogram.ProtoReflect.Descriptor instead.
+func (*LatencyHistogram) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LatencyHistogram) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *LatencyHistogram) GetBuckets() []*HistogramBucket {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+func (x *LatencyHistogram) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *LatencyHistogram) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *LatencyHistogram) GetMax() float64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+// HistogramBucket counts the latencies that are at most UpperBound milliseconds,
+// and greater than the upper bound of the previous bucket.
+type HistogramBucket struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UpperBound float64 `protobuf:"fixed64,1,opt,name=UpperBound,proto3" json:"UpperBound,omitempty"`
+	Count      uint64  `protobuf:"varint,2,opt,name=Count,proto3" json:"Count,omitempty"`
+}
+
+func (x *HistogramBucket) Reset() {
+	*x = HistogramBucket{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HistogramBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistogramBucket) ProtoMessage() {}
+
+func (x *HistogramBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistogramBucket.ProtoReflect.Descriptor instead.
+func (*HistogramBucket) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *HistogramBucket) GetUpperBound() float64 {
+	if x != nil {
+		return x.UpperBound
+	}
+	return 0
+}
+
+func (x *HistogramBucket) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// ReadLatencyMeasurement measures the latency of the read-only queries of a client,
+// which are measured separately from the commands.
+type ReadLatencyMeasurement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event    *Event  `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	Latency  float64 `protobuf:"fixed64,2,opt,name=Latency,proto3" json:"Latency,omitempty"`
+	Variance float64 `protobuf:"fixed64,3,opt,name=Variance,proto3" json:"Variance,omitempty"`
+	Count    uint64  `protobuf:"varint,4,opt,name=Count,proto3" json:"Count,omitempty"`
+	// The number of queries that failed, for example because the replicas did not give matching answers.
+	Failed uint64 `protobuf:"varint,5,opt,name=Failed,proto3" json:"Failed,omitempty"`
+}
+
+func (x *ReadLatencyMeasurement) Reset() {
+	*x = ReadLatencyMeasurement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReadLatencyMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadLatencyMeasurement) ProtoMessage() {}
+
+func (x *ReadLatencyMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadLatencyMeasurement.ProtoReflect.Descriptor instead.
+func (*ReadLatencyMeasurement) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ReadLatencyMeasurement) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *ReadLatencyMeasurement) GetLatency() float64 {
+	if x != nil {
+		return x.Latency
+	}
+	return 0
+}
+
+func (x *ReadLatencyMeasurement) GetVariance() float64 {
+	if x != nil {
+		return x.Variance
+	}
+	return 0
+}
+
+func (x *ReadLatencyMeasurement) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ReadLatencyMeasurement) GetFailed() uint64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+type ViewTimeouts struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	// Number of views since last reading.
+	Views uint64 `protobuf:"varint,2,opt,name=Views,proto3" json:"Views,omitempty"`
+	// Number of view timeouts.
+	Timeouts uint64 `protobuf:"varint,3,opt,name=Timeouts,proto3" json:"Timeouts,omitempty"`
+}
+
+func (x *ViewTimeouts) Reset() {
+	*x = ViewTimeouts{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ViewTimeouts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ViewTimeouts) ProtoMessage() {}
+
+func (x *ViewTimeouts) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ViewTimeouts.ProtoReflect.Descriptor instead.
+func (*ViewTimeouts) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ViewTimeouts) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *ViewTimeouts) GetViews() uint64 {
+	if x != nil {
+		return x.Views
+	}
+	return 0
+}
+
+func (x *ViewTimeouts) GetTimeouts() uint64 {
+	if x != nil {
+		return x.Timeouts
+	}
+	return 0
+}
+
+type CompressionMeasurement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	// Number of proposals sent since last reading.
+	Proposals uint64 `protobuf:"varint,2,opt,name=Proposals,proto3" json:"Proposals,omitempty"`
+	// Size of the commands before compression.
+	RawBytes uint64 `protobuf:"varint,3,opt,name=RawBytes,proto3" json:"RawBytes,omitempty"`
+	// Size of the commands after compression.
+	CompressedBytes uint64 `protobuf:"varint,4,opt,name=CompressedBytes,proto3" json:"CompressedBytes,omitempty"`
+}
+
+func (x *CompressionMeasurement) Reset() {
+	*x = CompressionMeasurement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompressionMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompressionMeasurement) ProtoMessage() {}
+
+func (x *CompressionMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompressionMeasurement.ProtoReflect.Descriptor instead.
+func (*CompressionMeasurement) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CompressionMeasurement) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *CompressionMeasurement) GetProposals() uint64 {
+	if x != nil {
+		return x.Proposals
+	}
+	return 0
+}
+
+func (x *CompressionMeasurement) GetRawBytes() uint64 {
+	if x != nil {
+		return x.RawBytes
+	}
+	return 0
+}
+
+func (x *CompressionMeasurement) GetCompressedBytes() uint64 {
+	if x != nil {
+		return x.CompressedBytes
+	}
+	return 0
+}
+
+type BatchMeasurement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	// Number of batches proposed since last reading.
+	Batches uint64 `protobuf:"varint,2,opt,name=Batches,proto3" json:"Batches,omitempty"`
+	// Number of commands in the batches.
+	Commands uint64 `protobuf:"varint,3,opt,name=Commands,proto3" json:"Commands,omitempty"`
+	// Size of the marshaled batches.
+	Bytes uint64 `protobuf:"varint,4,opt,name=Bytes,proto3" json:"Bytes,omitempty"`
+	// Number of commands in the largest batch.
+	MaxCommands uint64 `protobuf:"varint,5,opt,name=MaxCommands,proto3" json:"MaxCommands,omitempty"`
+	// Number of urgent commands in the batches, such as reconfiguration
+	// commands, which are proposed ahead of the client commands.
+	UrgentCommands uint64 `protobuf:"varint,6,opt,name=UrgentCommands,proto3" json:"UrgentCommands,omitempty"`
+	// Number of commands waiting in each lane of the command queue after the
+	// last batch, indexed by lane ("client" or "urgent").
+	Pending map[string]uint64 `protobuf:"bytes,7,rep,name=Pending,proto3" json:"Pending,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	// Number of commands of forked blocks that were put back in the command
+	// queue to be proposed again.
+	Requeued uint64 `protobuf:"varint,8,opt,name=Requeued,proto3" json:"Requeued,omitempty"`
+	// Number of commands of forked blocks that were not put back in the command
+	// queue, since they were executed or were already in the queue.
+	RequeueSkipped uint64 `protobuf:"varint,9,opt,name=RequeueSkipped,proto3" json:"RequeueSkipped,omitempty"`
+}
+
+func (x *BatchMeasurement) Reset() {
+	*x = BatchMeasurement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchMeasurement) ProtoMessage() {}
+
+func (x *BatchMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchMeasurement.ProtoReflect.Descriptor instead.
+func (*BatchMeasurement) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *BatchMeasurement) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *BatchMeasurement) GetBatches() uint64 {
+	if x != nil {
+		return x.Batches
+	}
+	return 0
+}
+
+func (x *BatchMeasurement) GetCommands() uint64 {
+	if x != nil {
+		return x.Commands
+	}
+	return 0
+}
+
+func (x *BatchMeasurement) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *BatchMeasurement) GetMaxCommands() uint64 {
+	if x != nil {
+		return x.MaxCommands
+	}
+	return 0
+}
+
+func (x *BatchMeasurement) GetUrgentCommands() uint64 {
+	if x != nil {
+		return x.UrgentCommands
+	}
+	return 0
+}
+
+func (x *BatchMeasurement) GetPending() map[string]uint64 {
+	if x != nil {
+		return x.Pending
+	}
+	return nil
+}
+
+func (x *BatchMeasurement) GetRequeued() uint64 {
+	if x != nil {
+		return x.Requeued
+	}
+	return 0
+}
+
+func (x *BatchMeasurement) GetRequeueSkipped() uint64 {
+	if x != nil {
+		return x.RequeueSkipped
+	}
+	return 0
+}
+
+type CommandQueueMeasurement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	// Number of client commands waiting in the command queue.
+	Depth uint64 `protobuf:"varint,2,opt,name=Depth,proto3" json:"Depth,omitempty"`
+	// Largest number of client commands waiting in the command queue since last
+	// reading.
+	MaxDepth uint64 `protobuf:"varint,3,opt,name=MaxDepth,proto3" json:"MaxDepth,omitempty"`
+	// Number of client commands included in batches since last reading, except
+	// the commands of forked blocks that were put back in the queue.
+	Commands uint64 `protobuf:"varint,4,opt,name=Commands,proto3" json:"Commands,omitempty"`
+	// Mean time that the included commands waited from their admission to the
+	// command queue to their inclusion in a batch.
+	MeanWait *durationpb.Duration `protobuf:"bytes,5,opt,name=MeanWait,proto3" json:"MeanWait,omitempty"`
+	// Histogram of the number of commands in the batches relative to the batch
+	// size, in buckets of equal width from empty to full. Full batches are
+	// counted in the last bucket.
+	CountFill []uint64 `protobuf:"varint,6,rep,packed,name=CountFill,proto3" json:"CountFill,omitempty"`
+	// Histogram of the size of the batches relative to their byte limit, which
+	// is the smaller of the byte limit of the batches and the room in a block,
+	// in the same buckets. Batches without a byte limit are not counted.
+	BytesFill []uint64 `protobuf:"varint,7,rep,packed,name=BytesFill,proto3" json:"BytesFill,omitempty"`
+}
+
+func (x *CommandQueueMeasurement) Reset() {
+	*x = CommandQueueMeasurement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommandQueueMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandQueueMeasurement) ProtoMessage() {}
+
+func (x *CommandQueueMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandQueueMeasurement.ProtoReflect.Descriptor instead.
+func (*CommandQueueMeasurement) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CommandQueueMeasurement) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *CommandQueueMeasurement) GetDepth() uint64 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *CommandQueueMeasurement) GetMaxDepth() uint64 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *CommandQueueMeasurement) GetCommands() uint64 {
+	if x != nil {
+		return x.Commands
+	}
+	return 0
+}
+
+func (x *CommandQueueMeasurement) GetMeanWait() *durationpb.Duration {
+	if x != nil {
+		return x.MeanWait
+	}
+	return nil
+}
+
+func (x *CommandQueueMeasurement) GetCountFill() []uint64 {
+	if x != nil {
+		return x.CountFill
+	}
+	return nil
+}
+
+func (x *CommandQueueMeasurement) GetBytesFill() []uint64 {
+	if x != nil {
+		return x.BytesFill
+	}
+	return nil
+}
+
+type NetworkCounters struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of messages sent, counted once per recipient.
+	Sent uint64 `protobuf:"varint,1,opt,name=Sent,proto3" json:"Sent,omitempty"`
+	// Number of messages received.
+	Received uint64 `protobuf:"varint,2,opt,name=Received,proto3" json:"Received,omitempty"`
+	// Number of messages that could not be sent.
+	Failed uint64 `protobuf:"varint,3,opt,name=Failed,proto3" json:"Failed,omitempty"`
+	// Size of the messages sent.
+	SentBytes uint64 `protobuf:"varint,4,opt,name=SentBytes,proto3" json:"SentBytes,omitempty"`
+	// Size of the messages received.
+	ReceivedBytes uint64 `protobuf:"varint,5,opt,name=ReceivedBytes,proto3" json:"ReceivedBytes,omitempty"`
+	// Number of received messages that were dropped as duplicates.
+	Duplicates uint64 `protobuf:"varint,6,opt,name=Duplicates,proto3" json:"Duplicates,omitempty"`
+}
+
+func (x *NetworkCounters) Reset() {
+	*x = NetworkCounters{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NetworkCounters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkCounters) ProtoMessage() {}
+
+func (x *NetworkCounters) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkCounters.ProtoReflect.Descriptor instead.
+func (*NetworkCounters) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *NetworkCounters) GetSent() uint64 {
+	if x != nil {
+		return x.Sent
+	}
+	return 0
+}
+
+func (x *NetworkCounters) GetReceived() uint64 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *NetworkCounters) GetFailed() uint64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *NetworkCounters) GetSentBytes() uint64 {
+	if x != nil {
+		return x.SentBytes
+	}
+	return 0
+}
+
+func (x *NetworkCounters) GetReceivedBytes() uint64 {
+	if x != nil {
+		return x.ReceivedBytes
+	}
+	return 0
+}
+
+func (x *NetworkCounters) GetDuplicates() uint64 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return 0
+}
+
+type NetworkMeasurement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	// Network counters since last reading, indexed by message type.
+	Messages map[string]*NetworkCounters `protobuf:"bytes,2,rep,name=Messages,proto3" json:"Messages,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *NetworkMeasurement) Reset() {
+	*x = NetworkMeasurement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_types_types_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NetworkMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkMeasurement) ProtoMessage() {}
+
+func (x *NetworkMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkMeasurement.ProtoReflect.Descriptor instead.
+func (*NetworkMeasurement) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *NetworkMeasurement) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *NetworkMeasurement) GetMessages() map[string]*NetworkCounters {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type EventLoopMeasurement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
+	// Number of events dropped from full event queues since last reading,
+	// indexed by event type.
+	Dropped map[string]uint64 `protobuf:"bytes,2,rep,name=Dropped,proto3" json:"Dropped,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	// Number of panics recovered from event handlers since last reading,
+	// indexed by event type.
+	Panics map[string]uint64 `protobuf:"bytes,3,rep,name=Panics,proto3" json:"Panics,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	// Number of handlers unregistered after repeated panics since last
+	// reading, indexed by event type.
+	DisabledHandlers map[string]uint64 `protobuf:"bytes,4,rep,name=DisabledHandlers,proto3" json:"DisabledHandlers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	// Processing statistics since last reading, indexed by event type.
+	Stats map[string]*EventStats `protobuf:"bytes,5,rep,name=Stats,proto3" json:"Stats,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
-func (x *ThroughputMeasurement) Reset() {
-	*x = ThroughputMeasurement{}
+func (x *EventLoopMeasurement) Reset() {
+	*x = EventLoopMeasurement{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_metrics_types_types_proto_msgTypes[2]
+		mi := &file_metrics_types_types_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ThroughputMeasurement) String() string {
+func (x *EventLoopMeasurement) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ThroughputMeasurement) ProtoMessage() {}
+func (*EventLoopMeasurement) ProtoMessage() {}
 
-func (x *ThroughputMeasurement) ProtoReflect() protoreflect.Message {
-	mi := &file_metrics_types_types_proto_msgTypes[2]
+func (x *EventLoopMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -173,67 +1590,77 @@ func (x *ThroughputMeasurement) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ThroughputMeasurement.ProtoReflect.Descriptor instead.
-func (*ThroughputMeasurement) Descriptor() ([]byte, []int) {
-	return file_metrics_types_types_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use EventLoopMeasurement.ProtoReflect.Descriptor instead.
+func (*EventLoopMeasurement) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *ThroughputMeasurement) GetEvent() *Event {
+func (x *EventLoopMeasurement) GetEvent() *Event {
 	if x != nil {
 		return x.Event
 	}
 	return nil
 }
 
-func (x *ThroughputMeasurement) GetCommits() uint64 {
+func (x *EventLoopMeasurement) GetDropped() map[string]uint64 {
 	if x != nil {
-		return x.Commits
+		return x.Dropped
 	}
-	return 0
+	return nil
 }
 
-func (x *ThroughputMeasurement) GetCommands() uint64 {
+func (x *EventLoopMeasurement) GetPanics() map[string]uint64 {
 	if x != nil {
-		return x.Commands
+		return x.Panics
 	}
-	return 0
+	return nil
 }
 
-func (x *ThroughputMeasurement) GetDuration() *durationpb.Duration {
+func (x *EventLoopMeasurement) GetDisabledHandlers() map[string]uint64 {
 	if x != nil {
-		return x.Duration
+		return x.DisabledHandlers
 	}
 	return nil
 }
 
-type LatencyMeasurement struct {
+func (x *EventLoopMeasurement) GetStats() map[string]*EventStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+// EventStats are the processing statistics of an event type.
+type EventStats struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Event    *Event  `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
-	Latency  float64 `protobuf:"fixed64,2,opt,name=Latency,proto3" json:"Latency,omitempty"`
-	Variance float64 `protobuf:"fixed64,3,opt,name=Variance,proto3" json:"Variance,omitempty"`
-	Count    uint64  `protobuf:"varint,4,opt,name=Count,proto3" json:"Count,omitempty"`
+	// Number of events processed.
+	Count uint64 `protobuf:"varint,1,opt,name=Count,proto3" json:"Count,omitempty"`
+	// Total time spent by the observers and handlers of the events.
+	Total *durationpb.Duration `protobuf:"bytes,2,opt,name=Total,proto3" json:"Total,omitempty"`
+	// Longest time spent by the observers and handlers of a single event.
+	Max *durationpb.Duration `protobuf:"bytes,3,opt,name=Max,proto3" json:"Max,omitempty"`
 }
 
-func (x *LatencyMeasurement) Reset() {
-	*x = LatencyMeasurement{}
+func (x *EventStats) Reset() {
+	*x = EventStats{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_metrics_types_types_proto_msgTypes[3]
+		mi := &file_metrics_types_types_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *LatencyMeasurement) String() string {
+func (x *EventStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LatencyMeasurement) ProtoMessage() {}
+func (*EventStats) ProtoMessage() {}
 
-func (x *LatencyMeasurement) ProtoReflect() protoreflect.Message {
-	mi := &file_metrics_types_types_proto_msgTypes[3]
+func (x *EventStats) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -244,68 +1671,65 @@ func (x *LatencyMeasurement) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LatencyMeasurement.ProtoReflect.Descriptor instead.
-func (*LatencyMeasurement) Descriptor() ([]byte, []int) {
-	return file_metrics_types_types_proto_rawDescGZIP(), []int{3}
-}
-
-func (x *LatencyMeasurement) GetEvent() *Event {
-	if x != nil {
-		return x.Event
-	}
-	return nil
+// Deprecated: Use EventStats.ProtoReflect.Descriptor instead.
+func (*EventStats) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *LatencyMeasurement) GetLatency() float64 {
+func (x *EventStats) GetCount() uint64 {
 	if x != nil {
-		return x.Latency
+		return x.Count
 	}
 	return 0
 }
 
-func (x *LatencyMeasurement) GetVariance() float64 {
+func (x *EventStats) GetTotal() *durationpb.Duration {
 	if x != nil {
-		return x.Variance
+		return x.Total
 	}
-	return 0
+	return nil
 }
 
-func (x *LatencyMeasurement) GetCount() uint64 {
+func (x *EventStats) GetMax() *durationpb.Duration {
 	if x != nil {
-		return x.Count
+		return x.Max
 	}
-	return 0
+	return nil
 }
 
-type ViewTimeouts struct {
+type CommitMeasurement struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Event *Event `protobuf:"bytes,1,opt,name=Event,proto3" json:"Event,omitempty"`
-	// Number of views since last reading.
-	Views uint64 `protobuf:"varint,2,opt,name=Views,proto3" json:"Views,omitempty"`
-	// Number of view timeouts.
-	Timeouts uint64 `protobuf:"varint,3,opt,name=Timeouts,proto3" json:"Timeouts,omitempty"`
+	// Number of blocks committed since last reading.
+	Commits uint64 `protobuf:"varint,2,opt,name=Commits,proto3" json:"Commits,omitempty"`
+	// Number of views that the committed chain advanced since last reading,
+	// such that Commits / Views is the number of commits per view.
+	Views uint64 `protobuf:"varint,3,opt,name=Views,proto3" json:"Views,omitempty"`
+	// Number of blocks committed since last reading, indexed by the ID of
+	// the replica that proposed them.
+	Proposers map[uint32]uint64 `protobuf:"bytes,4,rep,name=Proposers,proto3" json:"Proposers,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
 }
 
-func (x *ViewTimeouts) Reset() {
-	*x = ViewTimeouts{}
+func (x *CommitMeasurement) Reset() {
+	*x = CommitMeasurement{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_metrics_types_types_proto_msgTypes[4]
+		mi := &file_metrics_types_types_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ViewTimeouts) String() string {
+func (x *CommitMeasurement) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ViewTimeouts) ProtoMessage() {}
+func (*CommitMeasurement) ProtoMessage() {}
 
-func (x *ViewTimeouts) ProtoReflect() protoreflect.Message {
-	mi := &file_metrics_types_types_proto_msgTypes[4]
+func (x *CommitMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_types_types_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -316,32 +1740,39 @@ func (x *ViewTimeouts) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ViewTimeouts.ProtoReflect.Descriptor instead.
-func (*ViewTimeouts) Descriptor() ([]byte, []int) {
-	return file_metrics_types_types_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use CommitMeasurement.ProtoReflect.Descriptor instead.
+func (*CommitMeasurement) Descriptor() ([]byte, []int) {
+	return file_metrics_types_types_proto_rawDescGZIP(), []int{20}
 }
 
-func (x *ViewTimeouts) GetEvent() *Event {
+func (x *CommitMeasurement) GetEvent() *Event {
 	if x != nil {
 		return x.Event
 	}
 	return nil
 }
 
-func (x *ViewTimeouts) GetViews() uint64 {
+func (x *CommitMeasurement) GetCommits() uint64 {
 	if x != nil {
-		return x.Views
+		return x.Commits
 	}
 	return 0
 }
 
-func (x *ViewTimeouts) GetTimeouts() uint64 {
+func (x *CommitMeasurement) GetViews() uint64 {
 	if x != nil {
-		return x.Timeouts
+		return x.Views
 	}
 	return 0
 }
 
+func (x *CommitMeasurement) GetProposers() map[uint32]uint64 {
+	if x != nil {
+		return x.Proposers
+	}
+	return nil
+}
+
 var File_metrics_types_types_proto protoreflect.FileDescriptor
 
 var file_metrics_types_types_proto_rawDesc = []byte{
@@ -351,45 +1782,287 @@ var file_metrics_types_types_proto_rawDesc = []byte{
 	0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72,
 	0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x22, 0x30, 0x0a, 0x0a, 0x53, 0x74, 0x61, 0x72, 0x74, 0x45, 0x76, 0x65, 0x6e,
-	0x74, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x05,
-	0x45, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x69, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x0e,
-	0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x49, 0x44, 0x12, 0x16,
-	0x0a, 0x06, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
-	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x12, 0x38, 0x0a, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
-	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
-	0x22, 0xa8, 0x01, 0x0a, 0x15, 0x54, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x70, 0x75, 0x74, 0x4d,
-	0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76,
-	0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65,
-	0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x18,
-	0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
-	0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x73, 0x12, 0x35, 0x0a, 0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x52, 0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x84, 0x01, 0x0a, 0x12,
-	0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65,
+	0x6f, 0x74, 0x6f, 0x22, 0xcc, 0x02, 0x0a, 0x0a, 0x53, 0x74, 0x61, 0x72, 0x74, 0x45, 0x76, 0x65,
 	0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
-	0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63,
-	0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79,
-	0x12, 0x1a, 0x0a, 0x08, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x01, 0x52, 0x08, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05,
-	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x43, 0x6f, 0x75,
-	0x6e, 0x74, 0x22, 0x64, 0x0a, 0x0c, 0x56, 0x69, 0x65, 0x77, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75,
-	0x74, 0x73, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
-	0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x69, 0x65, 0x77, 0x73, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x56, 0x69, 0x65, 0x77, 0x73, 0x12, 0x1a, 0x0a, 0x08,
-	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08,
-	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x42, 0x29, 0x5a, 0x27, 0x67, 0x69, 0x74, 0x68,
-	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x65, 0x6c, 0x61, 0x62, 0x2f, 0x68, 0x6f, 0x74,
-	0x73, 0x74, 0x75, 0x66, 0x66, 0x2f, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2f, 0x74, 0x79,
-	0x70, 0x65, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x28, 0x0a, 0x07, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e,
+	0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x07, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64,
+	0x12, 0x16, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x38, 0x0a, 0x07, 0x4d, 0x6f, 0x64, 0x75,
+	0x6c, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x79, 0x70, 0x65,
+	0x73, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x4d, 0x6f, 0x64,
+	0x75, 0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x4d, 0x6f, 0x64, 0x75, 0x6c,
+	0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x53, 0x65, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x04, 0x53, 0x65, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x4d, 0x61, 0x73, 0x74, 0x65, 0x72,
+	0x53, 0x65, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x4d, 0x61, 0x73, 0x74,
+	0x65, 0x72, 0x53, 0x65, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x43, 0x50, 0x55, 0x73, 0x18, 0x07,
+	0x20, 0x03, 0x28, 0x0d, 0x52, 0x04, 0x43, 0x50, 0x55, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x4d, 0x61,
+	0x78, 0x50, 0x72, 0x6f, 0x63, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x4d, 0x61,
+	0x78, 0x50, 0x72, 0x6f, 0x63, 0x73, 0x1a, 0x3a, 0x0a, 0x0c, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0x33, 0x0a, 0x0d, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x5f, 0x0a, 0x0f, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65,
+	0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x49,
+	0x6e, 0x64, 0x65, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x22, 0x7f, 0x0a, 0x0a, 0x46, 0x61, 0x75, 0x6c,
+	0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x41, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x41, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x35, 0x0a, 0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x8c, 0x02, 0x0a, 0x15, 0x4e, 0x65,
+	0x74, 0x77, 0x6f, 0x72, 0x6b, 0x45, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x6f, 0x73, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x48, 0x6f, 0x73, 0x74, 0x12, 0x33, 0x0a, 0x07, 0x4c,
+	0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79,
+	0x12, 0x31, 0x0a, 0x06, 0x4a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x4a, 0x69, 0x74,
+	0x74, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x4c, 0x6f, 0x73, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x04, 0x4c, 0x6f, 0x73, 0x73, 0x12, 0x3f, 0x0a, 0x0d, 0x52, 0x6f, 0x75, 0x6e, 0x64,
+	0x54, 0x72, 0x69, 0x70, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x52, 0x6f, 0x75, 0x6e, 0x64,
+	0x54, 0x72, 0x69, 0x70, 0x54, 0x69, 0x6d, 0x65, 0x22, 0xb3, 0x01, 0x0a, 0x07, 0x50, 0x61, 0x79,
+	0x6c, 0x6f, 0x61, 0x64, 0x12, 0x22, 0x0a, 0x0c, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x44, 0x69, 0x73, 0x74,
+	0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x53, 0x69, 0x7a, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x10, 0x0a, 0x03,
+	0x4d, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x4d, 0x69, 0x6e, 0x12, 0x10,
+	0x0a, 0x03, 0x4d, 0x61, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x4d, 0x61, 0x78,
+	0x12, 0x14, 0x0a, 0x05, 0x5a, 0x69, 0x70, 0x66, 0x53, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x05, 0x5a, 0x69, 0x70, 0x66, 0x53, 0x12, 0x12, 0x0a, 0x04, 0x53, 0x65, 0x65, 0x64, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x53, 0x65, 0x65, 0x64, 0x12, 0x22, 0x0a, 0x0c, 0x43, 0x6f,
+	0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0c, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x22, 0x85,
+	0x01, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x43, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x12, 0x38, 0x0a, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x09, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1a, 0x0a, 0x08, 0x49, 0x6e,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x49, 0x6e,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0xa8, 0x01, 0x0a, 0x15, 0x54, 0x68, 0x72, 0x6f, 0x75,
+	0x67, 0x68, 0x70, 0x75, 0x74, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x05, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x1a,
+	0x0a, 0x08, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x08, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x35, 0x0a, 0x08, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x22, 0xe0, 0x02, 0x0a, 0x12, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4d, 0x65, 0x61,
+	0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07,
+	0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x4c,
+	0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e,
+	0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e,
+	0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x05, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x44, 0x72, 0x6f, 0x70,
+	0x70, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x44, 0x72, 0x6f, 0x70, 0x70,
+	0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x06, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x52, 0x65,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x52, 0x65, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x12, 0x1c, 0x0a, 0x09,
+	0x41, 0x62, 0x61, 0x6e, 0x64, 0x6f, 0x6e, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x09, 0x41, 0x62, 0x61, 0x6e, 0x64, 0x6f, 0x6e, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x54, 0x61,
+	0x72, 0x67, 0x65, 0x74, 0x52, 0x61, 0x74, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a,
+	0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x52, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x4c, 0x6f,
+	0x61, 0x64, 0x53, 0x74, 0x65, 0x70, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x4c, 0x6f,
+	0x61, 0x64, 0x53, 0x74, 0x65, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53,
+	0x69, 0x7a, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x53, 0x69, 0x7a, 0x65, 0x22, 0xa2, 0x01, 0x0a, 0x10, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79,
+	0x48, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73,
+	0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x30, 0x0a,
+	0x07, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72, 0x61, 0x6d,
+	0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x07, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12,
+	0x14, 0x0a, 0x05, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x4d, 0x69, 0x6e, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x03, 0x4d, 0x69, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x4d, 0x61, 0x78, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x4d, 0x61, 0x78, 0x22, 0x47, 0x0a, 0x0f, 0x48, 0x69, 0x73,
+	0x74, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x1e, 0x0a, 0x0a,
+	0x55, 0x70, 0x70, 0x65, 0x72, 0x42, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x0a, 0x55, 0x70, 0x70, 0x65, 0x72, 0x42, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x22, 0xa0, 0x01, 0x0a, 0x16, 0x52, 0x65, 0x61, 0x64, 0x4c, 0x61, 0x74, 0x65, 0x6e,
+	0x63, 0x79, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a,
+	0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74,
+	0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x12, 0x18, 0x0a, 0x07, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x07, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x56,
+	0x61, 0x72, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x56,
+	0x61, 0x72, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x46,
+	0x61, 0x69, 0x6c, 0x65, 0x64, 0x22, 0x64, 0x0a, 0x0c, 0x56, 0x69, 0x65, 0x77, 0x54, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x73, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x69, 0x65,
+	0x77, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x56, 0x69, 0x65, 0x77, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x08, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x22, 0xa0, 0x01, 0x0a, 0x16,
+	0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x61, 0x73, 0x75,
+	0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x50, 0x72,
+	0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x50,
+	0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x61, 0x77, 0x42,
+	0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x52, 0x61, 0x77, 0x42,
+	0x79, 0x74, 0x65, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x65, 0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x43,
+	0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0x8c,
+	0x03, 0x0a, 0x10, 0x42, 0x61, 0x74, 0x63, 0x68, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x42, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x73, 0x12, 0x1a, 0x0a, 0x08, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x08, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x14, 0x0a,
+	0x05, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x42, 0x79,
+	0x74, 0x65, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x4d, 0x61, 0x78, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x4d, 0x61, 0x78, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x55, 0x72, 0x67, 0x65, 0x6e, 0x74, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x55,
+	0x72, 0x67, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x3e, 0x0a,
+	0x07, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24,
+	0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x4d, 0x65, 0x61, 0x73,
+	0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x1a, 0x0a,
+	0x08, 0x52, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x08, 0x52, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x64, 0x12, 0x26, 0x0a, 0x0e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x75, 0x65, 0x53, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x53, 0x6b, 0x69, 0x70, 0x70, 0x65,
+	0x64, 0x1a, 0x3a, 0x0a, 0x0c, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xfe, 0x01,
+	0x0a, 0x17, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x51, 0x75, 0x65, 0x75, 0x65, 0x4d, 0x65,
+	0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73,
+	0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a,
+	0x05, 0x44, 0x65, 0x70, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x44, 0x65,
+	0x70, 0x74, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x4d, 0x61, 0x78, 0x44, 0x65, 0x70, 0x74, 0x68, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x4d, 0x61, 0x78, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12,
+	0x1a, 0x0a, 0x08, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x08, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x35, 0x0a, 0x08, 0x4d,
+	0x65, 0x61, 0x6e, 0x57, 0x61, 0x69, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x4d, 0x65, 0x61, 0x6e, 0x57, 0x61,
+	0x69, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x46, 0x69, 0x6c, 0x6c, 0x18,
+	0x06, 0x20, 0x03, 0x28, 0x04, 0x52, 0x09, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x46, 0x69, 0x6c, 0x6c,
+	0x12, 0x1c, 0x0a, 0x09, 0x42, 0x79, 0x74, 0x65, 0x73, 0x46, 0x69, 0x6c, 0x6c, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x04, 0x52, 0x09, 0x42, 0x79, 0x74, 0x65, 0x73, 0x46, 0x69, 0x6c, 0x6c, 0x22, 0xbd,
+	0x01, 0x0a, 0x0f, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65,
+	0x72, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x53, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x04, 0x53, 0x65, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76,
+	0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76,
+	0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x06, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x65,
+	0x6e, 0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x53,
+	0x65, 0x6e, 0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x52, 0x65, 0x63, 0x65,
+	0x69, 0x76, 0x65, 0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x0d, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1e,
+	0x0a, 0x0a, 0x44, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x0a, 0x44, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x22, 0xd2,
+	0x01, 0x0a, 0x12, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x43, 0x0a, 0x08, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x74, 0x79,
+	0x70, 0x65, 0x73, 0x2e, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x4d, 0x65, 0x61, 0x73, 0x75,
+	0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x1a, 0x53,
+	0x0a, 0x0d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x2c, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0xe5, 0x04, 0x0a, 0x14, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x4c, 0x6f, 0x6f,
+	0x70, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79,
+	0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x12, 0x42, 0x0a, 0x07, 0x44, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x28, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x4c,
+	0x6f, 0x6f, 0x70, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x44,
+	0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x44, 0x72, 0x6f,
+	0x70, 0x70, 0x65, 0x64, 0x12, 0x3f, 0x0a, 0x06, 0x50, 0x61, 0x6e, 0x69, 0x63, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x4c, 0x6f, 0x6f, 0x70, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x2e, 0x50, 0x61, 0x6e, 0x69, 0x63, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x50,
+	0x61, 0x6e, 0x69, 0x63, 0x73, 0x12, 0x5d, 0x0a, 0x10, 0x44, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65,
+	0x64, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x31, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x4c, 0x6f, 0x6f,
+	0x70, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x44, 0x69, 0x73,
+	0x61, 0x62, 0x6c, 0x65, 0x64, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x10, 0x44, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x48, 0x61, 0x6e, 0x64,
+	0x6c, 0x65, 0x72, 0x73, 0x12, 0x3c, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x73, 0x18, 0x05, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x4c, 0x6f, 0x6f, 0x70, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x1a, 0x3a, 0x0a, 0x0c, 0x44, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x39,
+	0x0a, 0x0b, 0x50, 0x61, 0x6e, 0x69, 0x63, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x43, 0x0a, 0x15, 0x44, 0x69, 0x73,
+	0x61, 0x62, 0x6c, 0x65, 0x64, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x4b,
+	0x0a, 0x0a, 0x53, 0x74, 0x61, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x27,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x80, 0x01, 0x0a, 0x0a,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x12, 0x2f, 0x0a, 0x05, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x05, 0x54, 0x6f, 0x74, 0x61,
+	0x6c, 0x12, 0x2b, 0x0a, 0x03, 0x4d, 0x61, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x03, 0x4d, 0x61, 0x78, 0x22, 0xec,
+	0x01, 0x0a, 0x11, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x52, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d,
+	0x69, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x69,
+	0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x69, 0x65, 0x77, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x05, 0x56, 0x69, 0x65, 0x77, 0x73, 0x12, 0x45, 0x0a, 0x09, 0x50, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x74, 0x79,
+	0x70, 0x65, 0x73, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x73, 0x1a,
+	0x3c, 0x0a, 0x0e, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x29, 0x5a,
+	0x27, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x65, 0x6c, 0x61,
+	0x62, 0x2f, 0x68, 0x6f, 0x74, 0x73, 0x74, 0x75, 0x66, 0x66, 0x2f, 0x6d, 0x65, 0x74, 0x72, 0x69,
+	0x63, 0x73, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -404,28 +2077,83 @@ func file_metrics_types_types_proto_rawDescGZIP() []byte {
 	return file_metrics_types_types_proto_rawDescData
 }
 
-var file_metrics_types_types_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_metrics_types_types_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
 var file_metrics_types_types_proto_goTypes = []interface{}{
-	(*StartEvent)(nil),            // 0: types.StartEvent
-	(*Event)(nil),                 // 1: types.Event
-	(*ThroughputMeasurement)(nil), // 2: types.ThroughputMeasurement
-	(*LatencyMeasurement)(nil),    // 3: types.LatencyMeasurement
-	(*ViewTimeouts)(nil),          // 4: types.ViewTimeouts
-	(*timestamppb.Timestamp)(nil), // 5: google.protobuf.Timestamp
-	(*durationpb.Duration)(nil),   // 6: google.protobuf.Duration
+	(*StartEvent)(nil),              // 0: types.StartEvent
+	(*ShutdownEvent)(nil),           // 1: types.ShutdownEvent
+	(*CheckpointEvent)(nil),         // 2: types.CheckpointEvent
+	(*FaultEvent)(nil),              // 3: types.FaultEvent
+	(*NetworkEmulationEvent)(nil),   // 4: types.NetworkEmulationEvent
+	(*Payload)(nil),                 // 5: types.Payload
+	(*Event)(nil),                   // 6: types.Event
+	(*ThroughputMeasurement)(nil),   // 7: types.ThroughputMeasurement
+	(*LatencyMeasurement)(nil),      // 8: types.LatencyMeasurement
+	(*LatencyHistogram)(nil),        // 9: types.LatencyHistogram
+	(*HistogramBucket)(nil),         // 10: types.HistogramBucket
+	(*ReadLatencyMeasurement)(nil),  // 11: types.ReadLatencyMeasurement
+	(*ViewTimeouts)(nil),            // 12: types.ViewTimeouts
+	(*CompressionMeasurement)(nil),  // 13: types.CompressionMeasurement
+	(*BatchMeasurement)(nil),        // 14: types.BatchMeasurement
+	(*CommandQueueMeasurement)(nil), // 15: types.CommandQueueMeasurement
+	(*NetworkCounters)(nil),         // 16: types.NetworkCounters
+	(*NetworkMeasurement)(nil),      // 17: types.NetworkMeasurement
+	(*EventLoopMeasurement)(nil),    // 18: types.EventLoopMeasurement
+	(*EventStats)(nil),              // 19: types.EventStats
+	(*CommitMeasurement)(nil),       // 20: types.CommitMeasurement
+	nil,                             // 21: types.StartEvent.ModulesEntry
+	nil,                             // 22: types.BatchMeasurement.PendingEntry
+	nil,                             // 23: types.NetworkMeasurement.MessagesEntry
+	nil,                             // 24: types.EventLoopMeasurement.DroppedEntry
+	nil,                             // 25: types.EventLoopMeasurement.PanicsEntry
+	nil,                             // 26: types.EventLoopMeasurement.DisabledHandlersEntry
+	nil,                             // 27: types.EventLoopMeasurement.StatsEntry
+	nil,                             // 28: types.CommitMeasurement.ProposersEntry
+	(*durationpb.Duration)(nil),     // 29: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),   // 30: google.protobuf.Timestamp
 }
 var file_metrics_types_types_proto_depIdxs = []int32{
-	1, // 0: types.StartEvent.Event:type_name -> types.Event
-	5, // 1: types.Event.Timestamp:type_name -> google.protobuf.Timestamp
-	1, // 2: types.ThroughputMeasurement.Event:type_name -> types.Event
-	6, // 3: types.ThroughputMeasurement.Duration:type_name -> google.protobuf.Duration
-	1, // 4: types.LatencyMeasurement.Event:type_name -> types.Event
-	1, // 5: types.ViewTimeouts.Event:type_name -> types.Event
-	6, // [6:6] is the sub-list for method output_type
-	6, // [6:6] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	6,  // 0: types.StartEvent.Event:type_name -> types.Event
+	5,  // 1: types.StartEvent.Payload:type_name -> types.Payload
+	21, // 2: types.StartEvent.Modules:type_name -> types.StartEvent.ModulesEntry
+	6,  // 3: types.ShutdownEvent.Event:type_name -> types.Event
+	6,  // 4: types.CheckpointEvent.Event:type_name -> types.Event
+	6,  // 5: types.FaultEvent.Event:type_name -> types.Event
+	29, // 6: types.FaultEvent.Duration:type_name -> google.protobuf.Duration
+	6,  // 7: types.NetworkEmulationEvent.Event:type_name -> types.Event
+	29, // 8: types.NetworkEmulationEvent.Latency:type_name -> google.protobuf.Duration
+	29, // 9: types.NetworkEmulationEvent.Jitter:type_name -> google.protobuf.Duration
+	29, // 10: types.NetworkEmulationEvent.RoundTripTime:type_name -> google.protobuf.Duration
+	30, // 11: types.Event.Timestamp:type_name -> google.protobuf.Timestamp
+	6,  // 12: types.ThroughputMeasurement.Event:type_name -> types.Event
+	29, // 13: types.ThroughputMeasurement.Duration:type_name -> google.protobuf.Duration
+	6,  // 14: types.LatencyMeasurement.Event:type_name -> types.Event
+	6,  // 15: types.LatencyHistogram.Event:type_name -> types.Event
+	10, // 16: types.LatencyHistogram.Buckets:type_name -> types.HistogramBucket
+	6,  // 17: types.ReadLatencyMeasurement.Event:type_name -> types.Event
+	6,  // 18: types.ViewTimeouts.Event:type_name -> types.Event
+	6,  // 19: types.CompressionMeasurement.Event:type_name -> types.Event
+	6,  // 20: types.BatchMeasurement.Event:type_name -> types.Event
+	22, // 21: types.BatchMeasurement.Pending:type_name -> types.BatchMeasurement.PendingEntry
+	6,  // 22: types.CommandQueueMeasurement.Event:type_name -> types.Event
+	29, // 23: types.CommandQueueMeasurement.MeanWait:type_name -> google.protobuf.Duration
+	6,  // 24: types.NetworkMeasurement.Event:type_name -> types.Event
+	23, // 25: types.NetworkMeasurement.Messages:type_name -> types.NetworkMeasurement.MessagesEntry
+	6,  // 26: types.EventLoopMeasurement.Event:type_name -> types.Event
+	24, // 27: types.EventLoopMeasurement.Dropped:type_name -> types.EventLoopMeasurement.DroppedEntry
+	25, // 28: types.EventLoopMeasurement.Panics:type_name -> types.EventLoopMeasurement.PanicsEntry
+	26, // 29: types.EventLoopMeasurement.DisabledHandlers:type_name -> types.EventLoopMeasurement.DisabledHandlersEntry
+	27, // 30: types.EventLoopMeasurement.Stats:type_name -> types.EventLoopMeasurement.StatsEntry
+	29, // 31: types.EventStats.Total:type_name -> google.protobuf.Duration
+	29, // 32: types.EventStats.Max:type_name -> google.protobuf.Duration
+	6,  // 33: types.CommitMeasurement.Event:type_name -> types.Event
+	28, // 34: types.CommitMeasurement.Proposers:type_name -> types.CommitMeasurement.ProposersEntry
+	16, // 35: types.NetworkMeasurement.MessagesEntry.value:type_name -> types.NetworkCounters
+	19, // 36: types.EventLoopMeasurement.StatsEntry.value:type_name -> types.EventStats
+	37, // [37:37] is the sub-list for method output_type
+	37, // [37:37] is the sub-list for method input_type
+	37, // [37:37] is the sub-list for extension type_name
+	37, // [37:37] is the sub-list for extension extendee
+	0,  // [0:37] is the sub-list for field type_name
 }
 
 func init() { file_metrics_types_types_proto_init() }
@@ -447,7 +2175,7 @@ func file_metrics_types_types_proto_init() {
 			}
 		}
 		file_metrics_types_types_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Event); i {
+			switch v := v.(*ShutdownEvent); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -459,7 +2187,7 @@ func file_metrics_types_types_proto_init() {
 			}
 		}
 		file_metrics_types_types_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ThroughputMeasurement); i {
+			switch v := v.(*CheckpointEvent); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -471,7 +2199,7 @@ func file_metrics_types_types_proto_init() {
 			}
 		}
 		file_metrics_types_types_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LatencyMeasurement); i {
+			switch v := v.(*FaultEvent); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -483,6 +2211,102 @@ func file_metrics_types_types_proto_init() {
 			}
 		}
 		file_metrics_types_types_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NetworkEmulationEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Payload); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Event); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ThroughputMeasurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LatencyMeasurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LatencyHistogram); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HistogramBucket); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReadLatencyMeasurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ViewTimeouts); i {
 			case 0:
 				return &v.state
@@ -494,6 +2318,102 @@ func file_metrics_types_types_proto_init() {
 				return nil
 			}
 		}
+		file_metrics_types_types_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CompressionMeasurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchMeasurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommandQueueMeasurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NetworkCounters); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NetworkMeasurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EventLoopMeasurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EventStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_types_types_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommitMeasurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -501,7 +2421,7 @@ func file_metrics_types_types_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_metrics_types_types_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   29,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -16,11 +16,18 @@ type MetricsLogger interface {
 	io.Closer
 }
 
+// Flusher is implemented by metrics loggers that buffer the logged messages.
+type Flusher interface {
+	// Flush writes the buffered messages.
+	Flush() error
+}
+
 type jsonLogger struct {
-	mut   sync.Mutex
-	mods  *Modules
-	wr    io.Writer
-	first bool
+	mut    sync.Mutex
+	mods   *Modules
+	wr     io.Writer
+	first  bool
+	closed bool
 }
 
 // NewJSONLogger returns a new metrics logger that logs to the specified writer.
@@ -60,6 +67,9 @@ func (dl *jsonLogger) write(msg proto.Message) (err error) {
 	dl.mut.Lock()
 	defer dl.mut.Unlock()
 
+	if dl.closed {
+		return fmt.Errorf("the metrics logger is closed")
+	}
 	if dl.first {
 		dl.first = false
 	} else {
@@ -81,8 +91,24 @@ func (dl *jsonLogger) write(msg proto.Message) (err error) {
 	return err
 }
 
-// Close closes the metrics logger
+// Flush flushes the writer, if it is buffered.
+func (dl *jsonLogger) Flush() error {
+	dl.mut.Lock()
+	defer dl.mut.Unlock()
+	if f, ok := dl.wr.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close closes the metrics logger. Closing it again has no effect.
 func (dl *jsonLogger) Close() error {
+	dl.mut.Lock()
+	defer dl.mut.Unlock()
+	if dl.closed {
+		return nil
+	}
+	dl.closed = true
 	_, err := io.WriteString(dl.wr, "\n]")
 	return err
 }
@@ -26,10 +26,10 @@
 // Finally, to set up the module system and its modules, you must create a Builder using the NewBuilder function,
 // and then register all of the modules with the builder using the Register method. For example:
 //
-//  builder := NewBuilder()
-//  // replace the logger
-//  builder.Register(logging.New("foo"))
-//  mods := builder.Build()
+//	builder := NewBuilder()
+//	// replace the logger
+//	builder.Register(logging.New("foo"))
+//	mods := builder.Build()
 //
 // If two modules satisfy the same interface, then the one that was registered last will be returned by the module system,
 // though note that both modules will be initialized if they implement the Module interface.
@@ -56,6 +56,7 @@ type Modules struct {
 	logger        logging.Logger
 	metricsLogger MetricsLogger
 	eventLoop     *eventloop.EventLoop
+	clock         eventloop.Clock
 	modulesByType map[reflect.Type]interface{}
 }
 
@@ -82,6 +83,13 @@ func (mods Modules) EventLoop() *eventloop.EventLoop {
 	return mods.eventLoop
 }
 
+// Clock returns the clock. It is the system clock unless another clock was registered.
+// The modules use the clock instead of the time package, such that the time can be controlled,
+// for example when a recorded execution is replayed, or in tests.
+func (mods Modules) Clock() eventloop.Clock {
+	return mods.clock
+}
+
 // MetricsEventLoop returns the metrics event loop.
 // The metrics event loop is used for processing of measurement data.
 //
@@ -96,8 +104,9 @@ func (mods Modules) MetricsEventLoop() *eventloop.EventLoop {
 //
 // NOTE: dest MUST be a pointer to a variable of the desired type.
 // For example:
-//  var module MyModule
-//  if mods.GetModuleByType(&module) { ... }
+//
+//	var module MyModule
+//	if mods.GetModuleByType(&module) { ... }
 func (mods Modules) GetModuleByType(dest interface{}) bool {
 	outType := reflect.TypeOf(dest)
 	if outType.Kind() != reflect.Ptr {
@@ -123,12 +132,15 @@ func NewBuilder(id hotstuff.ID) Builder {
 		id:            id,
 		logger:        logging.New(""),
 		eventLoop:     eventloop.New(1000),
+		clock:         eventloop.SystemClock{},
 		modulesByType: make(map[reflect.Type]interface{}),
 	}}
 	return bl
 }
 
 // Register registers the modules with the builder.
+// A registered *eventloop.EventLoop replaces the default event loop, such as a synchronous event loop in tests,
+// and a registered eventloop.Clock becomes the clock of the event loop.
 func (b *Builder) Register(modules ...interface{}) {
 	for _, module := range modules {
 		if m, ok := module.(logging.Logger); ok {
@@ -137,6 +149,12 @@ func (b *Builder) Register(modules ...interface{}) {
 		if m, ok := module.(MetricsLogger); ok {
 			b.mods.metricsLogger = m
 		}
+		if m, ok := module.(*eventloop.EventLoop); ok {
+			b.mods.eventLoop = m
+		}
+		if m, ok := module.(eventloop.Clock); ok {
+			b.mods.clock = m
+		}
 		if m, ok := module.(Module); ok {
 			b.modules = append(b.modules, m)
 		}
@@ -146,6 +164,8 @@ func (b *Builder) Register(modules ...interface{}) {
 
 // Build initializes all registered modules and returns the Modules object.
 func (b *Builder) Build() *Modules {
+	b.mods.eventLoop.SetClock(b.mods.clock)
+	b.mods.eventLoop.SetLogger(b.mods.logger)
 	for _, module := range b.modules {
 		module.InitModule(&b.mods)
 	}
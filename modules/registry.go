@@ -3,6 +3,8 @@ package modules
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
 )
 
@@ -14,11 +16,12 @@ var (
 // RegisterModule registers a module implementation with the specified name.
 // constructor must be a function returning the interface of the module.
 // For example:
-//  RegisterModule("chainedhotstuff", func() consensus.Rules { return chainedhotstuff.New() })
+//
+//	RegisterModule("chainedhotstuff", func() consensus.Rules { return chainedhotstuff.New() })
 func RegisterModule(name string, constructor interface{}) {
 	ctorType := reflect.TypeOf(constructor)
 
-	if ctorType.Kind() != reflect.Func && ctorType.NumOut() != 1 && ctorType.Out(0).Kind() != reflect.Interface {
+	if ctorType.Kind() != reflect.Func || ctorType.NumOut() != 1 || ctorType.Out(0).Kind() != reflect.Interface {
 		panic("invalid argument: constructor must be a function returning an interface")
 	}
 
@@ -40,8 +43,9 @@ func RegisterModule(name string, constructor interface{}) {
 // out must be a non-nil pointer to a variable with the interface type of the module.
 // GetModule returns true if the module is found, false otherwise.
 // For example:
-//  var rules consensus.Rules
-//  GetModule("chainedhotstuff", &rules)
+//
+//	var rules consensus.Rules
+//	GetModule("chainedhotstuff", &rules)
 func GetModule(name string, out interface{}) bool {
 	outType := reflect.TypeOf(out)
 
@@ -68,6 +72,40 @@ func GetModule(name string, out interface{}) bool {
 	return true
 }
 
+// NewModule is like GetModule, but returns an error if no module with the specified name is registered.
+// The error lists the names of the modules that are registered with the interface type of out.
+func NewModule(name string, out interface{}) error {
+	if GetModule(name, out) {
+		return nil
+	}
+	names := Names(out)
+	if len(names) == 0 {
+		return fmt.Errorf("unknown %s implementation '%s': no implementations are registered", reflect.TypeOf(out).Elem(), name)
+	}
+	return fmt.Errorf("unknown %s implementation '%s': registered implementations are %s",
+		reflect.TypeOf(out).Elem(), name, strings.Join(names, ", "))
+}
+
+// Names returns the sorted names of the modules that are registered with the interface type of out.
+// out must be a pointer to a variable with the interface type of the modules, as for GetModule.
+func Names(out interface{}) []string {
+	outType := reflect.TypeOf(out)
+
+	if outType.Kind() != reflect.Ptr {
+		panic("invalid argument: out must be a non-nil pointer to an interface variable")
+	}
+
+	registryMut.Lock()
+	defer registryMut.Unlock()
+
+	names := make([]string, 0, len(registry[outType.Elem()]))
+	for name := range registry[outType.Elem()] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // ListModules returns a map of interface names to module names.
 func ListModules() map[string][]string {
 	modules := make(map[string][]string)
@@ -23,6 +23,30 @@ func TestModuleRegistry(t *testing.T) {
 	}
 }
 
+func TestNewModuleUnknownName(t *testing.T) {
+	modules.RegisterModule("frobulator", func() moduleIface { return module{} })
+	modules.RegisterModule("confabulator", func() moduleIface { return module{} })
+
+	var mod moduleIface
+	if err := modules.NewModule("confabulator", &mod); err != nil || mod == nil {
+		t.Fatalf("module was not found: %v", err)
+	}
+
+	names := modules.Names(&mod)
+	if len(names) != 2 || names[0] != "confabulator" || names[1] != "frobulator" {
+		t.Errorf("got names %v, want [confabulator frobulator]", names)
+	}
+
+	err := modules.NewModule("defenestrator", &mod)
+	if err == nil {
+		t.Fatal("expected an error for an unknown module")
+	}
+	want := "unknown modules_test.moduleIface implementation 'defenestrator': registered implementations are confabulator, frobulator"
+	if err.Error() != want {
+		t.Errorf("got error %q, want %q", err, want)
+	}
+}
+
 type moduleIface interface {
 	frobulate(i *int)
 }
@@ -0,0 +1,44 @@
+package replica
+
+import "github.com/relab/hotstuff/consensus"
+
+// ackHistorySize is the number of executed commands whose blocks are remembered.
+const ackHistorySize = 1 << 16
+
+// ackHistory remembers the blocks in which the most recently executed commands were executed,
+// such that a command that is retried after it was executed is acknowledged with the same block hash
+// as the first attempt, and the client can match the acknowledgements of different replicas.
+type ackHistory struct {
+	blocks map[cmdID]consensus.Hash
+	order  []cmdID // the commands in blocks, in the order they were added
+	next   int     // the position in order of the oldest command, once order is full
+}
+
+func newAckHistory(size int) *ackHistory {
+	return &ackHistory{
+		blocks: make(map[cmdID]consensus.Hash, size),
+		order:  make([]cmdID, 0, size),
+	}
+}
+
+// add records that the command was executed in the block with the given hash, and forgets the oldest command
+// if the history is full.
+func (h *ackHistory) add(id cmdID, hash consensus.Hash) {
+	if len(h.order) < cap(h.order) {
+		h.order = append(h.order, id)
+	} else {
+		delete(h.blocks, h.order[h.next])
+		h.order[h.next] = id
+		h.next = (h.next + 1) % len(h.order)
+	}
+	h.blocks[id] = hash
+}
+
+// blockHash returns the hash of the block in which the command was executed, or nil if it is not remembered.
+func (h *ackHistory) blockHash(id cmdID) []byte {
+	hash, ok := h.blocks[id]
+	if !ok {
+		return nil
+	}
+	return hash[:]
+}
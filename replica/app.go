@@ -0,0 +1,64 @@
+package replica
+
+import "github.com/relab/hotstuff/consensus"
+
+// Application is a state machine that executes the data of the commands committed by the replica.
+// The commands are executed in the order that they are committed, and each command is executed once.
+type Application interface {
+	// Execute applies the data of a command to the state.
+	Execute(data []byte)
+}
+
+// ResultExecutor is implemented by applications whose commands have results, such as the value read by a command.
+// The result of a command is returned to the client that submitted it, if the client is connected to the replica.
+// The commands of such applications are executed by ExecuteWithResult instead of Execute.
+type ResultExecutor interface {
+	// ExecuteWithResult applies the data of a command to the state, and returns the result of the command.
+	// The result must be deterministic, since clients may require matching results from several replicas.
+	ExecuteWithResult(data []byte) []byte
+}
+
+// SpeculativeExecutor is implemented by applications that can execute the commands of a block as soon as the block is
+// certified, before it is committed, and undo them if the block is forked. This cuts the latency of the results at the
+// cost of executing the commands of forked blocks, which is rare while the leaders are correct.
+// Speculation is only used if it is enabled in the replica's Config.
+//
+// The commands of the speculated blocks are executed by Exec, in the same order as they would be committed, and are
+// either confirmed when their block is committed, or rolled back. Exec replaces Execute for the commands of the
+// confirmed blocks, which are not executed again. The snapshots and the answers to queries must only include the
+// confirmed commands, and restoring a snapshot discards the commands that have not been confirmed.
+type SpeculativeExecutor interface {
+	// Exec applies the data of a command of the block in the view to the state, and returns the result of the command.
+	Exec(view consensus.View, data []byte) []byte
+	// Confirm is called when the block in the view is committed. The commands of the blocks up to and including it
+	// will not be rolled back.
+	Confirm(view consensus.View)
+	// Rollback undoes the commands of the blocks after the view, in the reverse order of their execution.
+	Rollback(toView consensus.View)
+}
+
+// QueryExecutor is implemented by applications that can answer read-only queries from their state.
+// The queries are answered by each replica without ordering them through consensus.
+type QueryExecutor interface {
+	// Query returns the answer to the query. It must not modify the state.
+	Query(query []byte) ([]byte, error)
+}
+
+// Snapshotter is implemented by applications whose state can be transferred to replicas that have fallen far behind.
+// The snapshots of the replicas must be identical after executing the same commands,
+// since a snapshot is only installed if enough replicas agree on it.
+type Snapshotter interface {
+	// Snapshot returns a serialized copy of the state.
+	Snapshot() ([]byte, error)
+	// Restore replaces the state with the state in the snapshot.
+	Restore(snapshot []byte) error
+}
+
+// Validator is implemented by applications that can tell that a command is invalid before it is ordered,
+// and by the validators that are set in the replica's Config. Invalid commands are rejected when clients send them,
+// and the replicas do not vote for proposals that contain them.
+// The validation must be deterministic, such that the replicas agree on which proposals to vote for.
+type Validator interface {
+	// Validate returns an error if the data of the command cannot be executed.
+	Validate(data []byte) error
+}
@@ -0,0 +1,80 @@
+package replica
+
+import (
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultMaxRecvMsgSize is the maximum size of a message received by a gRPC server, unless it is configured.
+const defaultMaxRecvMsgSize = 4 << 20
+
+// blockMargin is the fraction of the maximum block size that is kept free, such that estimation errors and the framing
+// of the messages do not make a proposal exceed the limit: 1/blockMargin of the limit is not used by the commands.
+const blockMargin = 16
+
+// blockHeaderSize bounds the size of the fields of a proposal other than the commands and the certificates:
+// the parent hash, the view, the proposer, the consensus instance, and the tags and lengths of the fields.
+const blockHeaderSize = 128
+
+// defaultSignatureSize is used to estimate the size of the certificates if there is no crypto implementation that can sign.
+const defaultSignatureSize = 256
+
+// maxBlockBytes returns the maximum size of a serialized proposal. It is the configured limit, or else the maximum size
+// of the messages received by the replica server, since every replica must be able to receive the proposals.
+// The size of the proposals is not bounded by default if they are sent in chunks.
+func maxBlockBytes(conf Config) int {
+	if conf.MaxBlockBytes > 0 {
+		return conf.MaxBlockBytes
+	}
+	if conf.ProposalChunkSize > 0 {
+		return 0
+	}
+	if conf.ReplicaServerLimits.MaxRecvMsgSize > 0 {
+		return conf.ReplicaServerLimits.MaxRecvMsgSize
+	}
+	return defaultMaxRecvMsgSize
+}
+
+// batchEntrySize returns the number of bytes that the command adds to a marshaled batch.
+func batchEntrySize(cmd *clientpb.Command) int {
+	return protowire.SizeTag(1) + protowire.SizeBytes(proto.Size(cmd))
+}
+
+// proposalOverhead estimates the number of bytes that a proposal adds to its batch of commands.
+// The certificates are the largest part of it: a quorum certificate holds a threshold signature, which is at most one
+// signature of the configured crypto implementation per replica, and the aggregate quorum certificate, if it is used,
+// holds a quorum certificate per replica in addition to its own threshold signature.
+func (srv *clientSrv) proposalOverhead() int {
+	n := 0
+	if cfg := srv.mods.Configuration(); cfg != nil {
+		n = cfg.Len()
+	}
+	thresholdSig := n*(srv.signatureSize()+protowire.SizeVarint(uint64(srv.signatureSize()))+1) + 8
+	qc := thresholdSig + 64
+	overhead := blockHeaderSize + qc
+	if srv.mods.Options().ShouldUseAggQC() {
+		overhead += n*(qc+16) + thresholdSig + 32
+	}
+	return overhead
+}
+
+// signatureSize returns the size of a marshaled signature of the configured crypto implementation.
+// It is measured once, by signing a hash.
+func (srv *clientSrv) signatureSize() int {
+	srv.sigSizeOnce.Do(func() {
+		srv.sigSize = defaultSignatureSize
+		if srv.mods.Crypto() == nil {
+			return
+		}
+		sig, err := srv.mods.Crypto().Sign(consensus.Hash{})
+		if err != nil {
+			srv.mods.Logger().Warnf("Failed to measure the size of a signature: %v", err)
+			return
+		}
+		srv.sigSize = proto.Size(hotstuffpb.SignatureToProto(sig))
+	})
+	return srv.sigSize
+}
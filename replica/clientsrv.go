@@ -2,15 +2,17 @@ package replica
 
 import (
 	"crypto/sha256"
+	"encoding"
+	"fmt"
 	"hash"
 	"net"
 	"sync"
 
-	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/relab/gorums"
+	"github.com/relab/hotstuff/backend"
 	"github.com/relab/hotstuff/consensus"
 	"github.com/relab/hotstuff/internal/proto/clientpb"
-	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
@@ -19,33 +21,95 @@ import (
 // clientSrv serves a client.
 type clientSrv struct {
 	mut          sync.Mutex
-	mods         *modules.Modules
+	mods         *consensus.Modules
 	srv          *gorums.Server
-	awaitingCmds map[cmdID]chan<- error
+	awaitingCmds map[cmdID][]awaiter // a command may be awaited more than once if the client retries it
 	cmdCache     *cmdCache
 	hash         hash.Hash
+	forward      bool // forward commands to the leader
+	app          Application
+	height       uint64            // the number of commands executed
+	executedView consensus.View    // the view of the last executed block
+	acks         *ackHistory       // the blocks of the recently executed commands
+	closed       bool              // the replica is shutting down, and no longer accepts commands
+	transfer     *stateTransfer    // takes snapshots of the executed state; nil if state transfer is disabled
+	durable      *durableSnapshots // saves snapshots to stable storage; nil if durable snapshots are disabled
+	pipeline     *execPipeline     // executes the blocks off the event loop; nil if the event loop executes them
+	speculation  *speculation      // executes the certified blocks before they are committed; nil if disabled
+	sigSize      int               // the size of a marshaled signature; see signatureSize
+	sigSizeOnce  sync.Once
+}
+
+// awaiter is a client request that waits for its command to be executed.
+type awaiter struct {
+	done  chan<- execResult
+	proof bool // the client requested a commit proof
+}
+
+// execResult is the outcome of an executed command.
+type execResult struct {
+	blockHash []byte
+	result    []byte // the result returned by the application, if any
+	proof     *clientpb.CommitProof
+	err       error
+}
+
+// commitProofEvent is added to the event loop when a command whose client requested a proof is executed.
+// It is handled after the replica has processed the proposal that committed the command,
+// such that the highQC certifies the blocks that extend it.
+type commitProofEvent struct {
+	block     *consensus.Block
+	position  int
+	blockHash []byte // the block in which the command was first executed
+	result    []byte
+	waiters   []chan<- execResult
 }
 
 // newClientServer returns a new client server.
 func newClientServer(conf Config, srvOpts []gorums.ServerOption) (srv *clientSrv) {
 	srv = &clientSrv{
-		awaitingCmds: make(map[cmdID]chan<- error),
+		awaitingCmds: make(map[cmdID][]awaiter),
 		srv:          gorums.NewServer(srvOpts...),
 		cmdCache:     newCmdCache(int(conf.BatchSize)),
 		hash:         sha256.New(),
+		forward:      conf.ForwardCommands,
+		app:          conf.Application,
+		acks:         newAckHistory(ackHistorySize),
 	}
+	if conf.Validator != nil {
+		srv.cmdCache.validator = conf.Validator
+	} else if validator, ok := conf.Application.(Validator); ok {
+		srv.cmdCache.validator = validator
+	}
+	srv.cmdCache.setLimits(conf.CommandCacheSize, conf.CommandCacheBytes, conf.EvictCommands)
+	srv.cmdCache.setBatchLimits(conf.BatchBytes, conf.BatchDelay)
+	srv.cmdCache.setBatchPolicy(conf.BatchPolicy, conf.ClientWeights)
+	srv.cmdCache.setProposedTTL(conf.ProposedTTL)
+	srv.cmdCache.setMaxBlockBytes(maxBlockBytes(conf))
 	clientpb.RegisterClientServer(srv.srv, srv)
 	return srv
 }
 
-// InitModule gives the module access to the other modules.
-func (srv *clientSrv) InitModule(mods *modules.Modules) {
+// InitConsensusModule gives the module a reference to the Modules object.
+// It also allows the module to set module options using the OptionsBuilder.
+func (srv *clientSrv) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
 	srv.mods = mods
-	srv.cmdCache.InitModule(mods)
+	srv.cmdCache.setBlockOverhead(srv.proposalOverhead)
+	srv.mods.EventLoop().RegisterHandler(commitProofEvent{}, func(event interface{}) {
+		srv.onCommitProof(event.(commitProofEvent))
+	})
+	srv.mods.EventLoop().RegisterShutdownHook(func() interface{} {
+		srv.stopAccepting()
+		return nil
+	})
 }
 
 func (srv *clientSrv) Start(addr string) error {
-	lis, err := net.Listen("tcp", addr)
+	transport, err := backend.GetTransport("")
+	if err != nil {
+		return err
+	}
+	lis, err := transport.Listen(addr)
 	if err != nil {
 		return err
 	}
@@ -66,59 +130,414 @@ func (srv *clientSrv) Stop() {
 	srv.srv.Stop()
 }
 
-func (srv *clientSrv) ExecCommand(ctx gorums.ServerCtx, cmd *clientpb.Command) (*empty.Empty, error) {
-	id := cmdID{cmd.ClientID, cmd.SequenceNumber}
-
-	c := make(chan error)
-	srv.mut.Lock()
-	srv.awaitingCmds[id] = c
-	srv.mut.Unlock()
+func (srv *clientSrv) ExecCommand(ctx gorums.ServerCtx, cmd *clientpb.Command) (*clientpb.CommandResponse, error) {
+	c, resp := srv.submit(cmd)
+	if c == nil {
+		return resp, nil
+	}
+	ctx.Release()
+	res := <-c
+	return &clientpb.CommandResponse{Proof: res.proof, BlockHash: res.blockHash, Tag: cmd.GetTag(), Result: res.result}, res.err
+}
 
-	srv.cmdCache.addCommand(cmd)
+// ExecCommandBatch handles each command in the batch as if it was sent on its own,
+// and replies when all of the commands have been executed.
+func (srv *clientSrv) ExecCommandBatch(ctx gorums.ServerCtx, batch *clientpb.Batch) (*clientpb.BatchResponse, error) {
+	cmds := batch.GetCommands()
+	results := make([]<-chan execResult, len(cmds))
+	resp := &clientpb.BatchResponse{Responses: make([]*clientpb.CommandResponse, len(cmds))}
+	for i, cmd := range cmds {
+		results[i], resp.Responses[i] = srv.submit(cmd)
+	}
 	ctx.Release()
-	err := <-c
-	return &empty.Empty{}, err
+	var err error
+	for i, c := range results {
+		if c == nil {
+			continue
+		}
+		res := <-c
+		if res.err != nil && err == nil {
+			err = res.err
+		}
+		resp.Responses[i] = &clientpb.CommandResponse{
+			Proof:     res.proof,
+			BlockHash: res.blockHash,
+			Tag:       cmds[i].GetTag(),
+			Result:    res.result,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
-func (srv *clientSrv) Exec(cmd consensus.Command) {
-	batch := new(clientpb.Batch)
-	err := proto.UnmarshalOptions{AllowPartial: true}.Unmarshal([]byte(cmd), batch)
+// submit adds the command to the command cache and returns a channel that receives the result of the command
+// when it is executed. If the command has already been executed, the response is returned instead.
+// Commands that the application rejects fail at once, since they would never be accepted in a proposal,
+// and so do commands that do not fit in the command cache.
+func (srv *clientSrv) submit(cmd *clientpb.Command) (<-chan execResult, *clientpb.CommandResponse) {
+	if cmd.GetUrgent() {
+		c := make(chan execResult, 1)
+		c <- execResult{err: status.Error(codes.InvalidArgument, "clients cannot submit urgent commands")}
+		return c, nil
+	}
+	if validator := srv.cmdCache.validator; validator != nil {
+		if err := validator.Validate(cmd.GetData()); err != nil {
+			c := make(chan execResult, 1)
+			c <- execResult{err: status.Errorf(codes.InvalidArgument, "invalid command: %v", err)}
+			return c, nil
+		}
+	}
+	id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
+	c := srv.awaitExecution(id, cmd.GetRequestProof())
+	if c == nil {
+		// the client retried a command that has already been executed.
+		// the block that contained it may no longer be known, so no proof is given.
+		srv.mut.Lock()
+		defer srv.mut.Unlock()
+		return nil, &clientpb.CommandResponse{BlockHash: srv.acks.blockHash(id), Tag: cmd.GetTag()}
+	}
+
+	added, evicted, err := srv.cmdCache.addCommand(cmd, true)
+	srv.failCommands(evicted, errCacheFull)
 	if err != nil {
-		srv.mods.Logger().Errorf("Failed to unmarshal command: %v", err)
+		srv.failCommands([]cmdID{id}, err)
+		return c, nil
+	}
+	if added && srv.forward {
+		srv.forwardCommand(cmd)
+	}
+	return c, nil
+}
+
+// failCommands fails the clients that are waiting for the commands to be executed.
+func (srv *clientSrv) failCommands(ids []cmdID, err error) {
+	if len(ids) == 0 {
 		return
 	}
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+	for _, id := range ids {
+		for _, waiter := range srv.awaitingCmds[id] {
+			waiter.done <- execResult{err: err}
+		}
+		delete(srv.awaitingCmds, id)
+	}
+}
+
+// awaitExecution returns a channel that receives the result of the command when it is executed,
+// or nil if the command has already been executed. If proof is true, the result includes a commit proof.
+// The channel is buffered, such that the commands of a batch can be executed in any order.
+func (srv *clientSrv) awaitExecution(id cmdID, proof bool) <-chan execResult {
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+	if srv.cmdCache.isExecuted(id) {
+		return nil
+	}
+	c := make(chan execResult, 1)
+	if srv.closed {
+		c <- execResult{err: errShuttingDown}
+		return c
+	}
+	srv.awaitingCmds[id] = append(srv.awaitingCmds[id], awaiter{done: c, proof: proof})
+	return c
+}
 
-	srv.mods.EventLoop().AddEvent(consensus.CommitEvent{Commands: len(batch.GetCommands())})
+// errShuttingDown is returned to the clients whose commands have not been executed when the replica shuts down.
+var errShuttingDown = status.Error(codes.Unavailable, "the replica is shutting down")
 
-	for _, cmd := range batch.GetCommands() {
-		_, _ = srv.hash.Write(cmd.Data)
-		srv.mut.Lock()
-		id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
-		if done, ok := srv.awaitingCmds[id]; ok {
-			done <- nil
-			delete(srv.awaitingCmds, id)
+// stopAccepting fails the commands that are waiting to be executed, and the commands that are received later.
+func (srv *clientSrv) stopAccepting() {
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+	srv.closed = true
+	for id, waiters := range srv.awaitingCmds {
+		for _, waiter := range waiters {
+			waiter.done <- execResult{err: errShuttingDown}
 		}
-		srv.mut.Unlock()
+		delete(srv.awaitingCmds, id)
 	}
+}
 
-	srv.mods.Logger().Debugf("Hash: %.8x", srv.hash.Sum(nil))
+// Query answers a read-only query from the executed state, along with the number of commands that have been executed
+// and the view of the last executed block, such that clients can compare the answers of different replicas.
+func (srv *clientSrv) Query(_ gorums.ServerCtx, req *clientpb.QueryRequest) (*clientpb.QueryResponse, error) {
+	queryExecutor, ok := srv.app.(QueryExecutor)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "the application does not support queries")
+	}
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+	result, err := queryExecutor.Query(req.GetData())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &clientpb.QueryResponse{Result: result, Height: srv.height, View: uint64(srv.executedView)}, nil
 }
 
-func (srv *clientSrv) Fork(cmd consensus.Command) {
+// snapshot returns a snapshot of the executed state, including the record of the executed commands.
+func (srv *clientSrv) snapshot() ([]byte, error) {
+	state, err := srv.state(true)
+	if err != nil {
+		return nil, err
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(state)
+}
+
+// state returns the executed state, including the record of the executed commands.
+// The state of the application is only included if withApp is true.
+func (srv *clientSrv) state(withApp bool) (*clientpb.ReplicaState, error) {
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+	state := &clientpb.ReplicaState{
+		Height:   srv.height,
+		View:     uint64(srv.executedView),
+		Executed: srv.cmdCache.executedCommands(),
+	}
+	if srv.app != nil && withApp {
+		snapshotter, ok := srv.app.(Snapshotter)
+		if !ok {
+			return nil, fmt.Errorf("the application does not support snapshots")
+		}
+		app, err := snapshotter.Snapshot()
+		if err != nil {
+			return nil, fmt.Errorf("failed to take a snapshot of the application: %w", err)
+		}
+		state.Application = app
+	}
+	hashState, err := srv.hash.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save the hash of the executed commands: %w", err)
+	}
+	state.Hash = hashState
+	return state, nil
+}
+
+// restore replaces the executed state with the snapshot. The clients that are waiting for commands that were
+// executed according to the snapshot are acknowledged without a block hash.
+func (srv *clientSrv) restore(snapshot []byte) error {
+	state := new(clientpb.ReplicaState)
+	if err := proto.Unmarshal(snapshot, state); err != nil {
+		return fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+	return srv.restoreState(state, func() error {
+		if srv.app == nil {
+			return nil
+		}
+		snapshotter, ok := srv.app.(Snapshotter)
+		if !ok {
+			return fmt.Errorf("the application does not support snapshots")
+		}
+		if err := snapshotter.Restore(state.GetApplication()); err != nil {
+			return fmt.Errorf("failed to restore the application: %w", err)
+		}
+		return nil
+	})
+}
+
+// restoreState replaces the executed state with the given state, after restoreApp has restored the state of the
+// application. The clients that are waiting for commands that were executed according to the state are acknowledged
+// without a block hash.
+func (srv *clientSrv) restoreState(state *clientpb.ReplicaState, restoreApp func() error) error {
+	// check the hash state before the application is restored, such that the state is not left half restored.
+	if err := sha256.New().(encoding.BinaryUnmarshaler).UnmarshalBinary(state.GetHash()); err != nil {
+		return fmt.Errorf("failed to restore the hash of the executed commands: %w", err)
+	}
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+	if err := restoreApp(); err != nil {
+		return err
+	}
+	_ = srv.hash.(encoding.BinaryUnmarshaler).UnmarshalBinary(state.GetHash())
+	srv.height = state.GetHeight()
+	srv.executedView = consensus.View(state.GetView())
+	srv.cmdCache.restoreExecutedCommands(state.GetExecuted())
+	srv.cmdCache.unlogExecuted()
+	if srv.speculation != nil {
+		srv.speculation.reset()
+	}
+	for id, waiters := range srv.awaitingCmds {
+		if !srv.cmdCache.isExecuted(id) {
+			continue
+		}
+		for _, waiter := range waiters {
+			waiter.done <- execResult{}
+		}
+		delete(srv.awaitingCmds, id)
+	}
+	return nil
+}
+
+func (srv *clientSrv) Exec(block *consensus.Block) {
 	batch := new(clientpb.Batch)
-	err := proto.UnmarshalOptions{AllowPartial: true}.Unmarshal([]byte(cmd), batch)
+	err := proto.UnmarshalOptions{AllowPartial: true}.Unmarshal([]byte(block.Command()), batch)
 	if err != nil {
 		srv.mods.Logger().Errorf("Failed to unmarshal command: %v", err)
 		return
 	}
 
-	for _, cmd := range batch.GetCommands() {
+	executed, size := 0, 0
+	var executedIDs []cmdID // the commands to remove from the command log
+	for i, cmd := range batch.GetCommands() {
 		srv.mut.Lock()
 		id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
-		if done, ok := srv.awaitingCmds[id]; ok {
-			done <- status.Error(codes.Aborted, "blockchain was forked")
-			delete(srv.awaitingCmds, id)
+		// a command that was committed more than once is only executed the first time,
+		// but the client is acknowledged either way. The result is only known the first time;
+		// it is discarded if no client is waiting for it.
+		var result []byte
+		if srv.cmdCache.markExecuted(id) {
+			_, _ = srv.hash.Write(cmd.Data)
+			result = srv.execute(id, cmd.Data)
+			srv.height++
+			srv.acks.add(id, block.Hash())
+			executed++
+			size += len(cmd.Data)
+			if srv.cmdCache.log != nil {
+				executedIDs = append(executedIDs, id)
+			}
+		}
+		// the command is acknowledged with the block in which it was first executed.
+		blockHash := srv.acks.blockHash(id)
+		var proofWaiters []chan<- execResult
+		for _, waiter := range srv.awaitingCmds[id] {
+			if waiter.proof {
+				proofWaiters = append(proofWaiters, waiter.done)
+			} else {
+				waiter.done <- execResult{blockHash: blockHash, result: result}
+			}
 		}
+		delete(srv.awaitingCmds, id)
 		srv.mut.Unlock()
+		if len(proofWaiters) > 0 {
+			srv.mods.EventLoop().AddEvent(commitProofEvent{
+				block:     block,
+				position:  i,
+				blockHash: blockHash,
+				result:    result,
+				waiters:   proofWaiters,
+			})
+		}
+	}
+
+	srv.cmdCache.unlog(executedIDs)
+
+	srv.mut.Lock()
+	srv.executedView = block.View()
+	hash := srv.hash.Sum(nil)
+	srv.mut.Unlock()
+
+	if srv.transfer != nil {
+		srv.transfer.executed(block)
+	}
+	if srv.durable != nil {
+		srv.durable.blockExecuted(block, size)
+	}
+
+	srv.mods.EventLoop().AddEvent(consensus.CommitEvent{Block: block, Commands: executed})
+
+	srv.mods.Logger().Debugf("Hash: %.8x", hash)
+}
+
+// execute executes the data of a command with the application, and returns the result of the command,
+// if the application returns results. A command that was executed speculatively is not executed again.
+// It must be called with the mutex held.
+func (srv *clientSrv) execute(id cmdID, data []byte) []byte {
+	if srv.speculation != nil {
+		if result, ok := srv.speculation.result(id); ok {
+			return result
+		}
+	}
+	switch app := srv.app.(type) {
+	case nil:
+		return nil
+	case ResultExecutor:
+		return app.ExecuteWithResult(data)
+	default:
+		app.Execute(data)
+		return nil
+	}
+}
+
+// awaitPipeline waits until the committed blocks have been executed, if they are executed by a pipeline.
+// It must be called by the event loop.
+func (srv *clientSrv) awaitPipeline() {
+	if srv.pipeline != nil {
+		srv.pipeline.wait()
+	}
+}
+
+// onCommitProof replies to the clients that requested a proof that the command was committed.
+func (srv *clientSrv) onCommitProof(event commitProofEvent) {
+	proof, err := srv.commitProof(event.block, event.position)
+	if err != nil {
+		srv.mods.Logger().Warnf("Failed to create commit proof: %v", err)
+		err = status.Error(codes.Internal, err.Error())
+	}
+	for _, done := range event.waiters {
+		done <- execResult{blockHash: event.blockHash, result: event.result, proof: proof, err: err}
+	}
+}
+
+// commitProof returns a proof that the command at the given position in the block was committed.
+// The proof consists of the block and the blocks that extend it, up to the first chain of blocks that
+// satisfies the commit rule, and the QC that certifies the last block of that chain.
+func (srv *clientSrv) commitProof(block *consensus.Block, position int) (*clientpb.CommitProof, error) {
+	highQC := srv.mods.Synchronizer().HighQC()
+
+	// collect the blocks from the block certified by the highQC down to the committed block.
+	var chain []*consensus.Block
+	current, ok := srv.mods.BlockChain().LocalGet(highQC.BlockHash())
+	for ok && current.View() > block.View() {
+		chain = append(chain, current)
+		current, ok = srv.mods.BlockChain().LocalGet(current.Parent())
+	}
+	if !ok || current.Hash() != block.Hash() {
+		return nil, fmt.Errorf("block %.8s is not an ancestor of the highQC", block.Hash())
+	}
+	chain = append(chain, block)
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+
+	// find the first chain of blocks that satisfies the commit rule.
+	chainLength := srv.mods.Consensus().ChainLength()
+	for last := chainLength - 1; last < len(chain); last++ {
+		qc := highQC
+		if last+1 < len(chain) {
+			qc = chain[last+1].QuorumCert()
+		}
+		if !isCommitChain(chain[last-chainLength+1:last+1], qc) {
+			continue
+		}
+		hash := block.Hash()
+		proof := &clientpb.CommitProof{
+			BlockHash: hash[:],
+			Position:  uint32(position),
+			QC:        hotstuffpb.QuorumCertToProto(qc),
+		}
+		for _, b := range chain[:last+1] {
+			proof.Blocks = append(proof.Blocks, hotstuffpb.BlockToProto(b))
+		}
+		return proof, nil
+	}
+	return nil, fmt.Errorf("no chain of %d certified blocks extends block %.8s", chainLength, block.Hash())
+}
+
+// isCommitChain returns true if each block is the parent of the next and is certified by the QC of the next block,
+// and the last block is certified by qc.
+func isCommitChain(chain []*consensus.Block, qc consensus.QuorumCert) bool {
+	for i, block := range chain {
+		next := qc
+		if i+1 < len(chain) {
+			if chain[i+1].Parent() != block.Hash() {
+				return false
+			}
+			next = chain[i+1].QuorumCert()
+		}
+		if next.BlockHash() != block.Hash() {
+			return false
+		}
 	}
+	return true
 }
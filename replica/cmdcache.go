@@ -3,110 +3,571 @@ package replica
 import (
 	"container/list"
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/eventloop"
 	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/metrics/types"
 	"github.com/relab/hotstuff/modules"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
 
+// cmdOverhead approximates the memory used by a command in the cache, in addition to its data and tag.
+const cmdOverhead = 128
+
+// cmdSize returns the number of bytes that a command is accounted for in the cache.
+func cmdSize(cmd *clientpb.Command) int {
+	return cmdOverhead + len(cmd.GetData()) + len(cmd.GetTag())
+}
+
+// maxUrgentCommands is the maximum number of urgent commands at the front of a batch.
+const maxUrgentCommands = 16
+
+// defaultProposedTTL is how long the commands of a block that has been certified, but not committed or forked, are
+// not proposed again, if the TTL is not configured.
+const defaultProposedTTL = 30 * time.Second
+
+// errCacheFull is returned to the clients whose commands are rejected or evicted because the command cache is full.
+// The clients may retry the commands later.
+var errCacheFull = status.Error(codes.ResourceExhausted, "the command cache is full")
+
+// errCommandTooLarge is returned to the clients whose commands do not fit in a block.
+var errCommandTooLarge = status.Error(codes.InvalidArgument, "the command is larger than the maximum block size")
+
+// cacheStats are the size and counters of the command cache.
+type cacheStats struct {
+	commands int    // the number of commands in the cache
+	bytes    int    // the number of bytes accounted for the commands in the cache
+	rejected uint64 // the number of commands that were rejected because the cache was full
+	evicted  uint64 // the number of commands that were evicted to make room for new commands
+	requeued uint64 // the number of commands of forked blocks that were put back in the cache
+}
+
+// queueStats are the statistics of the client commands in the cache since the previous metrics tick.
+type queueStats struct {
+	maxDepth  int           // the largest number of commands in the cache
+	commands  int           // the number of commands that were included in batches, except requeued commands
+	wait      time.Duration // the total time that the included commands waited in the cache
+	countFill [consensus.FillBuckets]uint64
+	bytesFill [consensus.FillBuckets]uint64
+}
+
+// cachedCmd is a command in the cache.
+type cachedCmd struct {
+	cmd        *clientpb.Command
+	fromClient bool          // true if the command was received from a client rather than forwarded by another replica
+	size       int           // the number of bytes that the command adds to a marshaled batch
+	added      time.Time     // when the command was admitted; zero if it was requeued
+	urgent     bool          // true if the command is in the urgent lane
+	clientElem *list.Element // the element of the command in the queue of its client; nil if batches are FIFO
+}
+
 type cmdCache struct {
-	mut           sync.Mutex
-	mods          *modules.Modules
-	c             chan struct{}
-	batchSize     int
-	serialNumbers map[uint32]uint64 // highest proposed serial number per client ID
-	cache         list.List
-	marshaler     proto.MarshalOptions
-	unmarshaler   proto.UnmarshalOptions
+	mut         sync.Mutex
+	mods        *modules.Modules
+	c           chan struct{}
+	batchSize   int           // the maximum number of commands in a batch
+	batchBytes  int           // the maximum size of the marshaled commands in a batch; unbounded if zero
+	batchDelay  time.Duration // how long the oldest command waits for a batch to fill up; until it is full if zero
+	maxBlock    int           // the maximum size of a serialized proposal; unbounded if zero
+	overhead    func() int    // estimates the number of bytes that a proposal adds to its batch; nil if none
+	cmdBytes    int           // the size of the marshaled commands in the cache
+	maxCommands int           // the maximum number of commands in the cache; unbounded if zero
+	maxBytes    int           // the maximum number of bytes accounted for the commands in the cache; unbounded if zero
+	evict       bool          // evict the oldest commands when the cache is full, rather than rejecting new commands
+	stats       cacheStats
+	queue       queueStats              // the statistics of the client commands since the previous metrics tick
+	proposed    *proposedSeqs           // the commands of the uncommitted blocks, which must not be proposed again
+	executed    clientSeqs              // the commands that have been executed, which are skipped if they are committed again
+	pending     map[cmdID]*list.Element // the elements of the commands in the cache
+	validator   Validator               // rejects invalid commands; nil if the commands are not validated
+	log         *cmdLog                 // persists the commands admitted from clients; nil if they are not persisted
+	fair        *fairQueue              // schedules the commands of the clients fairly; nil if batches are FIFO
+	cache       list.List
+	urgent      list.List // the urgent commands, which are proposed ahead of the commands in the cache
+	marshaler   proto.MarshalOptions
+	unmarshaler proto.UnmarshalOptions
 }
 
 func newCmdCache(batchSize int) *cmdCache {
 	return &cmdCache{
-		c:             make(chan struct{}),
-		batchSize:     batchSize,
-		serialNumbers: make(map[uint32]uint64),
-		marshaler:     proto.MarshalOptions{Deterministic: true},
-		unmarshaler:   proto.UnmarshalOptions{DiscardUnknown: true},
+		c:           make(chan struct{}, 1),
+		batchSize:   batchSize,
+		proposed:    newProposedSeqs(defaultProposedTTL),
+		executed:    make(clientSeqs),
+		pending:     make(map[cmdID]*list.Element),
+		marshaler:   proto.MarshalOptions{Deterministic: true},
+		unmarshaler: proto.UnmarshalOptions{DiscardUnknown: true},
 	}
 }
 
 // InitModule gives the module access to the other modules.
 func (c *cmdCache) InitModule(mods *modules.Modules) {
 	c.mods = mods
+	c.proposed.clock = mods.Clock()
+	c.mods.EventLoop().RegisterObserver(types.TickEvent{}, func(_ interface{}) {
+		c.mods.EventLoop().AddEvent(c.queueEvent())
+	})
 }
 
-func (c *cmdCache) addCommand(cmd *clientpb.Command) {
+// setLimits bounds the number of commands and bytes in the cache. If evict is true, the oldest commands are evicted
+// to make room for new commands when the cache is full; otherwise, new commands are rejected.
+func (c *cmdCache) setLimits(maxCommands, maxBytes int, evict bool) {
 	c.mut.Lock()
 	defer c.mut.Unlock()
-	if serialNo := c.serialNumbers[cmd.GetClientID()]; serialNo >= cmd.GetSequenceNumber() {
-		// command is too old
-		return
-	}
-	c.cache.PushBack(cmd)
-	if c.cache.Len() >= c.batchSize {
-		// notify Get that we are ready to send a new batch.
-		select {
-		case c.c <- struct{}{}:
-		default:
-		}
+	c.maxCommands = maxCommands
+	c.maxBytes = maxBytes
+	c.evict = evict
+}
+
+// setBatchLimits sets the limits of the batches in addition to the batch size. A batch is released by Get when it has
+// reached maxBytes bytes, unless maxBytes is zero, or when its oldest command has waited for the delay, unless the
+// delay is zero, such that small batches are proposed when there are not enough commands to fill them.
+func (c *cmdCache) setBatchLimits(maxBytes int, delay time.Duration) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.batchBytes = maxBytes
+	c.batchDelay = delay
+}
+
+// setBatchPolicy sets the order in which the commands of different clients are taken from the cache, and the weights
+// of the clients under the fair policy. It must be called before commands are added.
+func (c *cmdCache) setBatchPolicy(policy BatchPolicy, weights map[uint32]uint32) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.fair = nil
+	if policy == FairBatches {
+		c.fair = newFairQueue(weights)
 	}
 }
 
-// Get returns a batch of commands to propose.
-func (c *cmdCache) Get(ctx context.Context) (cmd consensus.Command, ok bool) {
-	batch := new(clientpb.Batch)
+// setMaxBlockBytes bounds the size of the serialized proposals, unless maxBytes is zero. The batches are limited to the
+// room that is left in a proposal of maxBytes bytes when a margin and the overhead of the proposal are subtracted
+// (see setBlockOverhead). Commands that do not fit in a batch are rejected.
+func (c *cmdCache) setMaxBlockBytes(maxBytes int) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.maxBlock = maxBytes
+}
 
+// setBlockOverhead sets the function that estimates the number of bytes that a proposal adds to its batch,
+// such as the certificates of the proposal. It is called with the mutex held, and must not call the cache.
+func (c *cmdCache) setBlockOverhead(overhead func() int) {
 	c.mut.Lock()
-awaitBatch:
-	// wait until we can send a new batch.
-	for c.cache.Len() <= c.batchSize {
-		c.mut.Unlock()
-		select {
-		case <-c.c:
-		case <-ctx.Done():
-			return
-		}
-		c.mut.Lock()
+	defer c.mut.Unlock()
+	c.overhead = overhead
+}
+
+// blockRoom returns the maximum size of the marshaled commands of a batch, such that the proposal does not exceed the
+// maximum block size, or zero if the block size is unbounded. The caller must hold the mutex.
+func (c *cmdCache) blockRoom() int {
+	if c.maxBlock <= 0 {
+		return 0
+	}
+	room := c.maxBlock - c.maxBlock/blockMargin
+	if c.overhead != nil {
+		room -= c.overhead()
+	}
+	if room < 1 {
+		// no command fits, but zero would mean unbounded.
+		room = 1
+	}
+	return room
+}
+
+// setProposedTTL sets how long the commands of a block that has been certified, but not committed or forked, are not
+// proposed or accepted again. The default TTL is used if it is zero.
+func (c *cmdCache) setProposedTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = defaultProposedTTL
 	}
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.proposed.ttl = ttl
+}
 
-	// Get the batch. Note that we may not be able to fill the batch, but that should be fine as long as we can send
-	// at least one command.
-	for i := 0; i < c.batchSize; i++ {
-		elem := c.cache.Front()
-		if elem == nil {
-			break
+// addCommand adds a command to the cache. It returns false if the command has already been proposed or executed,
+// or if it is already in the cache, for example because it was received both from the client and from another replica,
+// or because the client retried it. Commands that the application rejects are not added either.
+// Commands that do not fit in a block are rejected with errCommandTooLarge.
+// fromClient should be true if the command was received from a client rather than forwarded by another replica.
+//
+// If the cache is full, the command is rejected with errCacheFull, unless the cache evicts the oldest commands.
+// The evicted commands that were received from clients are returned, such that the clients can be told to retry them.
+func (c *cmdCache) addCommand(cmd *clientpb.Command, fromClient bool) (added bool, evicted []cmdID, err error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
+	if c.proposed.contains(id) || c.executed.contains(id) {
+		return false, nil, nil
+	}
+	if cmd.GetUrgent() || (c.validator != nil && c.validator.Validate(cmd.GetData()) != nil) {
+		return false, nil, nil
+	}
+	if _, ok := c.pending[id]; ok {
+		return false, nil, nil
+	}
+	entrySize := batchEntrySize(cmd)
+	if room := c.blockRoom(); room > 0 && entrySize > room {
+		return false, nil, errCommandTooLarge
+	}
+	size := cmdSize(cmd)
+	if c.maxBytes > 0 && size > c.maxBytes {
+		c.stats.rejected++
+		return false, nil, errCacheFull
+	}
+	for c.full(size) {
+		if !c.evict {
+			c.stats.rejected++
+			return false, evicted, errCacheFull
 		}
-		c.cache.Remove(elem)
-		cmd := elem.Value.(*clientpb.Command)
-		if serialNo := c.serialNumbers[cmd.GetClientID()]; serialNo >= cmd.GetSequenceNumber() {
-			// command is too old
-			i--
-			continue
+		old := c.remove(c.cache.Front())
+		c.stats.evicted++
+		if old.fromClient {
+			evicted = append(evicted, cmdID{old.cmd.GetClientID(), old.cmd.GetSequenceNumber()})
+		}
+	}
+	if c.log != nil {
+		c.unlog(evicted)
+		if fromClient {
+			// the command is logged before it is added, such that it cannot be proposed before it is persisted.
+			if err := c.log.append(cmd); err != nil {
+				c.mods.Logger().Warnf("Failed to persist command: %v", err)
+			}
 		}
-		batch.Commands = append(batch.Commands, cmd)
 	}
+	cached := &cachedCmd{cmd: cmd, fromClient: fromClient, size: entrySize, added: c.mods.Clock().Now()}
+	c.pending[id] = c.cache.PushBack(cached)
+	if c.fair != nil {
+		c.fair.push(c.pending[id])
+	}
+	if c.cache.Len() > c.queue.maxDepth {
+		c.queue.maxDepth = c.cache.Len()
+	}
+	c.stats.commands++
+	c.stats.bytes += size
+	c.cmdBytes += cached.size
+	if _, ready := c.batchReady(); ready || (c.batchDelay > 0 && c.cache.Len() == 1) {
+		// notify Get that we are ready to send a new batch, or that it should start waiting for the batch delay.
+		c.notify()
+	}
+	return true, evicted, nil
+}
+
+// AddUrgent adds a system command, such as a reconfiguration command, to the urgent lane of the cache, such that it does
+// not wait behind the client commands. The urgent commands are proposed at the front of the next batch, up to
+// maxUrgentCommands per batch, and the client commands fill the remainder of the batch. The urgent lane is not bounded
+// by the limits of the cache. It returns false if the command has already been proposed or executed, or if it is
+// already in the cache.
+func (c *cmdCache) AddUrgent(cmd *clientpb.Command) bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
+	if c.proposed.contains(id) || c.executed.contains(id) {
+		return false
+	}
+	if _, ok := c.pending[id]; ok {
+		return false
+	}
+	cmd = proto.Clone(cmd).(*clientpb.Command)
+	cmd.Urgent = true
+	c.pending[id] = c.urgent.PushBack(&cachedCmd{cmd: cmd, size: batchEntrySize(cmd), urgent: true})
+	c.notify()
+	return true
+}
 
-	// if we still got no (new) commands, try to wait again
-	if len(batch.Commands) == 0 {
-		goto awaitBatch
+// notify wakes up Get, such that it checks if a batch is ready.
+func (c *cmdCache) notify() {
+	select {
+	case c.c <- struct{}{}:
+	default:
 	}
+}
 
+// batchReady returns true if a batch should be released: when there is an urgent command, when the cache holds a full
+// batch of commands or bytes, or a full block, or when the oldest command has waited for the batch delay. Otherwise,
+// it returns the time until the oldest command has waited for the batch delay, or zero if there is no command or no
+// delay. The caller must hold the mutex.
+func (c *cmdCache) batchReady() (wait time.Duration, ready bool) {
+	if c.urgent.Len() > 0 {
+		return 0, true
+	}
+	if c.cache.Len() == 0 {
+		return 0, false
+	}
+	if c.cache.Len() >= c.batchSize || (c.batchBytes > 0 && c.cmdBytes >= c.batchBytes) {
+		return 0, true
+	}
+	if room := c.blockRoom(); room > 0 && c.cmdBytes >= room {
+		return 0, true
+	}
+	if c.batchDelay <= 0 {
+		return 0, false
+	}
+	oldest := c.cache.Front().Value.(*cachedCmd)
+	wait = c.batchDelay - c.mods.Clock().Now().Sub(oldest.added)
+	return wait, wait <= 0
+}
+
+// full returns true if there is no room for a command of the given size.
+func (c *cmdCache) full(size int) bool {
+	return c.cache.Len() > 0 &&
+		((c.maxCommands > 0 && c.cache.Len() >= c.maxCommands) || (c.maxBytes > 0 && c.stats.bytes+size > c.maxBytes))
+}
+
+// remove removes the element from the cache or the urgent lane and returns its command.
+func (c *cmdCache) remove(elem *list.Element) *cachedCmd {
+	cached := elem.Value.(*cachedCmd)
+	delete(c.pending, cmdID{cached.cmd.GetClientID(), cached.cmd.GetSequenceNumber()})
+	if cached.urgent {
+		c.urgent.Remove(elem)
+		return cached
+	}
+	c.cache.Remove(elem)
+	if c.fair != nil {
+		c.fair.remove(cached)
+	}
+	c.stats.commands--
+	c.stats.bytes -= cmdSize(cached.cmd)
+	c.cmdBytes -= cached.size
+	return cached
+}
+
+// getStats returns the size and counters of the cache.
+func (c *cmdCache) getStats() cacheStats {
+	c.mut.Lock()
 	defer c.mut.Unlock()
+	return c.stats
+}
+
+// len returns the number of commands in the cache.
+func (c *cmdCache) len() int {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.cache.Len()
+}
+
+// setBatchSize changes the number of commands that are batched in the next proposals.
+func (c *cmdCache) setBatchSize(batchSize int) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.batchSize = batchSize
+	if _, ready := c.batchReady(); ready {
+		// a smaller batch may be ready already.
+		c.notify()
+	}
+}
+
+// clientCommands returns the commands in the cache that were received from a client and have not been proposed yet.
+func (c *cmdCache) clientCommands() []*clientpb.Command {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	var cmds []*clientpb.Command
+	for elem := c.cache.Front(); elem != nil; elem = elem.Next() {
+		cached := elem.Value.(*cachedCmd)
+		if !cached.fromClient || c.proposed.contains(cmdID{cached.cmd.GetClientID(), cached.cmd.GetSequenceNumber()}) {
+			continue
+		}
+		cmds = append(cmds, cached.cmd)
+	}
+	return cmds
+}
+
+// Get returns a batch of commands to propose. It waits until a batch is ready: when there are batchSize commands,
+// or batchBytes bytes of commands, or when the oldest command has waited for the batch delay, whichever comes first,
+// or immediately if there is an urgent command. The urgent commands come first in the batch, such that every replica
+// executes them ahead of the client commands of the batch, which are taken in the order given by the batch policy.
+// The batch never exceeds the room left in a block.
+// It returns false if the context is done first, for example because the view has changed.
+func (c *cmdCache) Get(ctx context.Context) (cmd consensus.Command, ok bool) {
+	batch := new(clientpb.Batch)
+	size, urgent := 0, 0
+
+	c.mut.Lock()
+	for len(batch.Commands) == 0 {
+		// wait until we can send a new batch.
+		for {
+			wait, ready := c.batchReady()
+			if ready {
+				break
+			}
+			c.mut.Unlock()
+			if !c.await(ctx, wait) {
+				return "", false
+			}
+			c.mut.Lock()
+		}
+
+		// Get the batch. Note that we may not be able to fill the batch, but that should be fine as long as we can
+		// send at least one command. If all of the commands were proposed or executed already, we wait again.
+		room := c.blockRoom()
+		now := c.mods.Clock().Now()
+		for c.urgent.Len() > 0 && len(batch.Commands) < maxUrgentCommands {
+			if !c.fitsBlock(c.urgent.Front(), size, room) {
+				break
+			}
+			c.take(batch, c.urgent.Front(), &size, now)
+		}
+		urgent = len(batch.Commands)
+		for len(batch.Commands) < c.batchSize {
+			elem := c.next()
+			if elem == nil {
+				break
+			}
+			if !c.fitsBlock(elem, size, room) {
+				break
+			}
+			if c.batchBytes > 0 && len(batch.Commands) > 0 && size+elem.Value.(*cachedCmd).size > c.batchBytes {
+				break
+			}
+			if c.fair != nil {
+				c.fair.take()
+			}
+			c.take(batch, elem, &size, now)
+		}
+	}
+	c.recordFill(len(batch.Commands), size)
+	pending, urgentPending := c.cache.Len(), c.urgent.Len()
+	c.mut.Unlock()
 
-	// otherwise, we should have at least one command
 	b, err := c.marshaler.Marshal(batch)
 	if err != nil {
 		c.mods.Logger().Errorf("Failed to marshal batch: %v", err)
 		return "", false
 	}
+	c.mods.EventLoop().AddEvent(consensus.BatchEvent{
+		Commands:      len(batch.Commands),
+		Urgent:        urgent,
+		Bytes:         len(b),
+		Pending:       pending,
+		UrgentPending: urgentPending,
+	})
 
 	cmd = consensus.Command(b)
 	return cmd, true
 }
 
+// next returns the element of the next client command to take from the cache according to the batch policy,
+// or nil if the cache is empty. The caller must hold the mutex.
+func (c *cmdCache) next() *list.Element {
+	if c.fair != nil {
+		return c.fair.next()
+	}
+	return c.cache.Front()
+}
+
+// take removes the element from the cache or the urgent lane, and adds its command to the batch, unless the command has
+// already been proposed or executed, or has become invalid since it was added. The time that a client command waited
+// since it was admitted is recorded, unless it was requeued. The caller must hold the mutex.
+func (c *cmdCache) take(batch *clientpb.Batch, elem *list.Element, size *int, now time.Time) {
+	cached := c.remove(elem)
+	id := cmdID{cached.cmd.GetClientID(), cached.cmd.GetSequenceNumber()}
+	if c.proposed.contains(id) || c.executed.contains(id) {
+		// command was already proposed, or executed before the state was restored from a snapshot
+		return
+	}
+	if c.validator != nil {
+		// the validity of a command may depend on the state, such as the balance that it spends.
+		// the client is told why the command is invalid when it retries it.
+		if err := c.validator.Validate(cached.cmd.GetData()); err != nil {
+			c.mods.Logger().Infof("Dropped invalid command %d of client %d: %v", id.sequenceNum, id.clientID, err)
+			return
+		}
+	}
+	batch.Commands = append(batch.Commands, cached.cmd)
+	*size += cached.size
+	if !cached.urgent && !cached.added.IsZero() {
+		c.queue.commands++
+		c.queue.wait += now.Sub(cached.added)
+	}
+}
+
+// recordFill records how full a batch of the given number of commands and bytes is, relative to the batch size,
+// and to the smaller of the byte limit of the batches and the room in a block, if either is bounded.
+// The caller must hold the mutex.
+func (c *cmdCache) recordFill(commands, size int) {
+	if c.batchSize > 0 {
+		c.queue.countFill[fillBucket(commands, c.batchSize)]++
+	}
+	limit := c.batchBytes
+	if room := c.blockRoom(); room > 0 && (limit == 0 || room < limit) {
+		limit = room
+	}
+	if limit > 0 {
+		c.queue.bytesFill[fillBucket(size, limit)]++
+	}
+}
+
+// fillBucket returns the bucket of the fill histograms that counts a batch of n commands or bytes with the given limit.
+func fillBucket(n, limit int) int {
+	bucket := n * consensus.FillBuckets / limit
+	if bucket >= consensus.FillBuckets {
+		bucket = consensus.FillBuckets - 1
+	}
+	return bucket
+}
+
+// queueEvent returns the statistics of the client commands since the previous call, and starts over.
+func (c *cmdCache) queueEvent() consensus.CommandQueueEvent {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	event := consensus.CommandQueueEvent{
+		Depth:     c.cache.Len(),
+		MaxDepth:  c.queue.maxDepth,
+		Commands:  c.queue.commands,
+		Wait:      c.queue.wait,
+		CountFill: c.queue.countFill,
+		BytesFill: c.queue.bytesFill,
+	}
+	c.queue = queueStats{maxDepth: c.cache.Len()}
+	return event
+}
+
+// fitsBlock returns true if the command of the element fits in the room left in a block by a batch of the given size.
+// A command that does not fit in an empty block is removed from the cache, since it would never be proposed;
+// this can only happen if the room has shrunk since the command was added, for example because replicas were added.
+// The caller must hold the mutex.
+func (c *cmdCache) fitsBlock(elem *list.Element, size, room int) bool {
+	cached := elem.Value.(*cachedCmd)
+	if room == 0 || size+cached.size <= room {
+		return true
+	}
+	if cached.size > room {
+		c.remove(elem)
+		c.mods.Logger().Warnf("Dropped command %d of client %d, which no longer fits in a block",
+			cached.cmd.GetSequenceNumber(), cached.cmd.GetClientID())
+	}
+	return false
+}
+
+// await waits until Get is notified, or until the duration has elapsed, unless it is zero.
+// It returns false if the context is done first.
+func (c *cmdCache) await(ctx context.Context, wait time.Duration) bool {
+	var timeout <-chan time.Time
+	if wait > 0 {
+		timer := eventloop.NewTimer(c.mods.Clock(), wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-c.c:
+	case <-timeout:
+	case <-ctx.Done():
+		return false
+	}
+	return true
+}
+
 // Accept returns true if the replica can accept the batch.
+// A batch is not accepted if it contains a command that has already been proposed or executed,
+// or an urgent command after the client commands. The validity of the commands is decided by Validate.
+// A command may still be committed twice, for example if two leaders propose it before either proposal is certified,
+// but it is only executed the first time.
 func (c *cmdCache) Accept(cmd consensus.Command) bool {
 	batch := new(clientpb.Batch)
 	err := c.unmarshaler.Unmarshal([]byte(cmd), batch)
@@ -118,17 +579,44 @@ func (c *cmdCache) Accept(cmd consensus.Command) bool {
 	c.mut.Lock()
 	defer c.mut.Unlock()
 
+	clientCmds := false
 	for _, cmd := range batch.GetCommands() {
-		if serialNo := c.serialNumbers[cmd.GetClientID()]; serialNo >= cmd.GetSequenceNumber() {
-			// command is too old, can't accept
+		id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
+		if c.proposed.contains(id) || c.executed.contains(id) {
+			// command was already proposed or executed, can't accept
+			return false
+		}
+		if cmd.GetUrgent() && clientCmds {
+			c.mods.Logger().Info("Rejected batch with an urgent command after the client commands")
 			return false
 		}
+		clientCmds = clientCmds || !cmd.GetUrgent()
 	}
 
 	return true
 }
 
-// Proposed updates the serial numbers such that we will not accept the given batch again.
+// Validate returns an error if the batch contains a command that the validator rejects.
+// The replicas do not vote for blocks with such batches.
+func (c *cmdCache) Validate(cmd consensus.Command) error {
+	if c.validator == nil {
+		return nil
+	}
+	batch := new(clientpb.Batch)
+	if err := c.unmarshaler.Unmarshal([]byte(cmd), batch); err != nil {
+		return fmt.Errorf("failed to unmarshal batch: %w", err)
+	}
+	for _, cmd := range batch.GetCommands() {
+		if err := c.validator.Validate(cmd.GetData()); err != nil {
+			return fmt.Errorf("command %d of client %d: %w", cmd.GetSequenceNumber(), cmd.GetClientID(), err)
+		}
+	}
+	return nil
+}
+
+// Proposed remembers the commands in the batch, such that we will not accept them again until the block is committed
+// or forked, or until the TTL has expired. The commands are removed from the cache, such that they do not take up space
+// that new commands could use.
 func (c *cmdCache) Proposed(cmd consensus.Command) {
 	batch := new(clientpb.Batch)
 	err := c.unmarshaler.Unmarshal([]byte(cmd), batch)
@@ -141,10 +629,137 @@ func (c *cmdCache) Proposed(cmd consensus.Command) {
 	defer c.mut.Unlock()
 
 	for _, cmd := range batch.GetCommands() {
-		if serialNo := c.serialNumbers[cmd.GetClientID()]; serialNo < cmd.GetSequenceNumber() {
-			c.serialNumbers[cmd.GetClientID()] = cmd.GetSequenceNumber()
+		id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
+		c.proposed.add(id)
+		if elem, ok := c.pending[id]; ok {
+			c.remove(elem)
 		}
 	}
 }
 
+// Fork puts the commands of a forked block back at the front of the cache, ahead of the new commands, such that they are
+// proposed again without waiting for the clients to retry them. The commands that have been executed, for example
+// because they were committed on the other branch, and those that are already in the cache are not requeued.
+// The requeued commands were admitted before they were proposed, so they are not bounded by the limits of the cache,
+// and they do not wait for the batch delay again.
+func (c *cmdCache) Fork(block *consensus.Block) {
+	batch := new(clientpb.Batch)
+	err := c.unmarshaler.Unmarshal([]byte(block.Command()), batch)
+	if err != nil {
+		c.mods.Logger().Errorf("Failed to unmarshal batch: %v", err)
+		return
+	}
+
+	c.mut.Lock()
+	// the requeued commands keep their order in front of the commands that were in the cache.
+	front, urgentFront := c.cache.Front(), c.urgent.Front()
+	marks := make(map[uint32]*list.Element) // the fronts of the queues of the clients before the commands are requeued
+	requeued, skipped := 0, 0
+	for _, cmd := range batch.GetCommands() {
+		id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
+		c.proposed.release(id)
+		if _, ok := c.pending[id]; ok || c.executed.contains(id) {
+			skipped++
+			continue
+		}
+		cached := &cachedCmd{cmd: cmd, size: batchEntrySize(cmd), urgent: cmd.GetUrgent()}
+		if cached.urgent {
+			c.pending[id] = insertBefore(&c.urgent, cached, urgentFront)
+		} else {
+			c.pending[id] = insertBefore(&c.cache, cached, front)
+			if c.fair != nil {
+				c.fair.requeue(c.pending[id], marks)
+			}
+			c.stats.commands++
+			c.stats.bytes += cmdSize(cmd)
+			c.cmdBytes += cached.size
+		}
+		requeued++
+	}
+	c.stats.requeued += uint64(requeued)
+	if c.cache.Len() > c.queue.maxDepth {
+		c.queue.maxDepth = c.cache.Len()
+	}
+	if _, ready := c.batchReady(); ready {
+		c.notify()
+	}
+	c.mut.Unlock()
+
+	c.mods.EventLoop().AddEvent(consensus.RequeueEvent{Commands: requeued, Skipped: skipped})
+}
+
+// insertBefore inserts the command before the mark, or at the back of the list if the mark is nil.
+func insertBefore(l *list.List, cached *cachedCmd, mark *list.Element) *list.Element {
+	if mark == nil {
+		return l.PushBack(cached)
+	}
+	return l.InsertBefore(cached, mark)
+}
+
+// isExecuted returns true if the command has been executed.
+func (c *cmdCache) isExecuted(id cmdID) bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.executed.contains(id)
+}
+
+// markExecuted records that the command has been executed, which replaces the record that it has been proposed.
+// It returns false if the command had already been executed, in which case it must not be executed again.
+func (c *cmdCache) markExecuted(id cmdID) bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.proposed.release(id)
+	if c.executed.contains(id) {
+		return false
+	}
+	c.executed.add(id)
+	return true
+}
+
+// release forgets that the command has been proposed, because its block was forked,
+// such that it can be proposed again when the client retries it.
+func (c *cmdCache) release(id cmdID) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.proposed.release(id)
+}
+
+// unlog removes the commands from the command log, if the commands are persisted.
+func (c *cmdCache) unlog(ids []cmdID) {
+	if c.log == nil || len(ids) == 0 {
+		return
+	}
+	if err := c.log.remove(ids); err != nil {
+		c.mods.Logger().Warnf("Failed to remove commands from the command log: %v", err)
+	}
+}
+
+// unlogExecuted removes the commands that have been executed from the command log, if the commands are persisted.
+// It is called when the record of the executed commands has been replaced.
+func (c *cmdCache) unlogExecuted() {
+	if c.log == nil {
+		return
+	}
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if err := c.log.removeExecuted(c.executed.contains); err != nil {
+		c.mods.Logger().Warnf("Failed to remove commands from the command log: %v", err)
+	}
+}
+
+// executedCommands returns the record of the executed commands, which must be saved along with the replica's state.
+func (c *cmdCache) executedCommands() *clientpb.ExecutedCommands {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.executed.toProto()
+}
+
+// restoreExecutedCommands replaces the record of the executed commands, for example when the replica's state is restored.
+func (c *cmdCache) restoreExecutedCommands(msg *clientpb.ExecutedCommands) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.executed = clientSeqsFromProto(msg)
+}
+
 var _ consensus.Acceptor = (*cmdCache)(nil)
+var _ consensus.ForkHandlerExt = (*cmdCache)(nil)
@@ -0,0 +1,289 @@
+package replica
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/relab/hotstuff/internal/atomicfile"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/internal/protostream"
+)
+
+// commandLogFile is the name of the file in the data directory in which the commands admitted from clients are logged.
+const commandLogFile = "commands.log"
+
+// commandLogSyncInterval is how often the command log is synced to stable storage if it is synced in batches.
+const commandLogSyncInterval = 10 * time.Millisecond
+
+// minCompaction is the number of commands that must have been removed from the command log before it is compacted.
+// The log is compacted when the removed commands also outnumber the commands that remain in it.
+const minCompaction = 1024
+
+// CommandLogSync determines when the log of the commands admitted from clients is synced to stable storage.
+type CommandLogSync string
+
+const (
+	// SyncEveryCommand syncs the log before each command is added to the command cache, such that no command that
+	// could have been proposed is lost if the machine crashes.
+	SyncEveryCommand CommandLogSync = "command"
+	// SyncBatched syncs the log periodically, such that the commands that were admitted in the last few milliseconds
+	// may be lost if the machine crashes.
+	SyncBatched CommandLogSync = "batched"
+	// SyncOff leaves the syncing to the operating system, such that the commands survive a crash of the replica,
+	// but not necessarily a crash of the machine.
+	SyncOff CommandLogSync = "off"
+)
+
+// ParseCommandLogSync returns the sync policy of the command log with the given name.
+// The empty string is the batched policy.
+func ParseCommandLogSync(name string) (CommandLogSync, error) {
+	switch policy := CommandLogSync(name); policy {
+	case "":
+		return SyncBatched, nil
+	case SyncEveryCommand, SyncBatched, SyncOff:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown command log sync policy '%s'", name)
+}
+
+// cmdLog is an append-only log of the commands that the replica has admitted from clients, such that a replica that is
+// restarted can reload the commands that were not executed, and propose them without waiting for the clients to retry
+// them. The commands are removed from the log when they are executed or evicted from the command cache.
+// Removals are never synced on their own, since a command that is reloaded after it was executed is skipped
+// when it is committed again.
+type cmdLog struct {
+	mut     sync.Mutex
+	path    string
+	policy  CommandLogSync
+	file    *os.File // nil when the log is closed
+	writer  *protostream.Writer
+	live    map[cmdID]*list.Element
+	order   list.List // the commands that have not been removed, in the order they were added
+	removed int       // the number of commands removed since the log was compacted
+	dirty   bool      // records have been written since the log was synced
+	stop    chan struct{}
+}
+
+// readCmdLog returns the commands in the log at path that have not been removed, in the order they were added.
+// The log ends at the first record that cannot be read, which was partially written if the replica crashed
+// while writing it.
+func readCmdLog(path string) ([]*clientpb.Command, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open the command log: %w", err)
+	}
+	defer f.Close()
+	var order list.List
+	live := make(map[cmdID]*list.Element)
+	reader := protostream.NewReader(bufio.NewReader(f))
+	for {
+		record := new(clientpb.CommandLogRecord)
+		if err := reader.Read(record); err != nil {
+			break
+		}
+		if cmd := record.GetAdded(); cmd != nil {
+			id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
+			if _, ok := live[id]; !ok {
+				live[id] = order.PushBack(cmd)
+			}
+		}
+		for _, cmd := range record.GetRemoved() {
+			id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
+			if elem, ok := live[id]; ok {
+				order.Remove(elem)
+				delete(live, id)
+			}
+		}
+	}
+	cmds := make([]*clientpb.Command, 0, order.Len())
+	for elem := order.Front(); elem != nil; elem = elem.Next() {
+		cmds = append(cmds, elem.Value.(*clientpb.Command))
+	}
+	return cmds, nil
+}
+
+// createCmdLog replaces the log at path with a log that holds the given commands, and opens it for appending.
+func createCmdLog(path string, policy CommandLogSync, cmds []*clientpb.Command) (*cmdLog, error) {
+	l := &cmdLog{
+		path:   path,
+		policy: policy,
+		live:   make(map[cmdID]*list.Element),
+		stop:   make(chan struct{}),
+	}
+	for _, cmd := range cmds {
+		l.live[cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}] = l.order.PushBack(cmd)
+	}
+	if err := l.rewrite(); err != nil {
+		return nil, err
+	}
+	if policy == SyncBatched {
+		go l.syncPeriodically()
+	}
+	return l, nil
+}
+
+// rewrite atomically replaces the log with one that only holds the commands that have not been removed,
+// and opens it for appending. The caller must hold the mutex, unless the log is being created.
+func (l *cmdLog) rewrite() error {
+	var buf bytes.Buffer
+	writer := protostream.NewWriter(&buf)
+	for elem := l.order.Front(); elem != nil; elem = elem.Next() {
+		if err := writer.Write(&clientpb.CommandLogRecord{Added: elem.Value.(*clientpb.Command)}); err != nil {
+			return err
+		}
+	}
+	if err := atomicfile.WriteFile(l.path, buf.Bytes()); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open the command log: %w", err)
+	}
+	if l.file != nil {
+		_ = l.file.Close()
+	}
+	l.file = file
+	l.writer = protostream.NewWriter(file)
+	l.removed = 0
+	l.dirty = false
+	return nil
+}
+
+// append adds a command to the log, and syncs it if every command is synced.
+func (l *cmdLog) append(cmd *clientpb.Command) error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
+	if _, ok := l.live[id]; ok || l.file == nil {
+		return nil
+	}
+	if err := l.writer.Write(&clientpb.CommandLogRecord{Added: cmd}); err != nil {
+		return err
+	}
+	l.live[id] = l.order.PushBack(cmd)
+	l.dirty = true
+	if l.policy == SyncEveryCommand {
+		return l.sync()
+	}
+	return nil
+}
+
+// remove removes the commands from the log. The log is truncated when no commands remain in it,
+// and compacted when most of its records are for commands that have been removed.
+func (l *cmdLog) remove(ids []cmdID) error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	var removed []*clientpb.Command
+	for _, id := range ids {
+		elem, ok := l.live[id]
+		if !ok {
+			continue
+		}
+		l.order.Remove(elem)
+		delete(l.live, id)
+		removed = append(removed, &clientpb.Command{ClientID: id.clientID, SequenceNumber: id.sequenceNum})
+	}
+	return l.removeRecords(removed)
+}
+
+// removeExecuted removes the commands that have been executed according to the executed function,
+// for example after the state of the replica has been restored from a snapshot.
+func (l *cmdLog) removeExecuted(executed func(id cmdID) bool) error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	var removed []*clientpb.Command
+	for id, elem := range l.live {
+		if !executed(id) {
+			continue
+		}
+		l.order.Remove(elem)
+		delete(l.live, id)
+		removed = append(removed, &clientpb.Command{ClientID: id.clientID, SequenceNumber: id.sequenceNum})
+	}
+	return l.removeRecords(removed)
+}
+
+// removeRecords records that the commands have been removed, unless the log is truncated or compacted instead.
+// The caller must hold the mutex.
+func (l *cmdLog) removeRecords(removed []*clientpb.Command) error {
+	if len(removed) == 0 {
+		return nil
+	}
+	l.removed += len(removed)
+	switch {
+	case l.order.Len() == 0:
+		if err := l.file.Truncate(0); err != nil {
+			return fmt.Errorf("failed to truncate the command log: %w", err)
+		}
+		l.removed = 0
+	case l.removed >= minCompaction && l.removed > l.order.Len():
+		return l.rewrite()
+	default:
+		if err := l.writer.Write(&clientpb.CommandLogRecord{Removed: removed}); err != nil {
+			return err
+		}
+	}
+	l.dirty = true
+	return nil
+}
+
+// sync syncs the log to stable storage if records have been written since it was last synced.
+// The caller must hold the mutex.
+func (l *cmdLog) sync() error {
+	if !l.dirty || l.file == nil {
+		return nil
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync the command log: %w", err)
+	}
+	l.dirty = false
+	return nil
+}
+
+// syncPeriodically syncs the log until it is closed.
+func (l *cmdLog) syncPeriodically() {
+	ticker := time.NewTicker(commandLogSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-l.stop:
+			return
+		}
+		l.mut.Lock()
+		// the log remains dirty if the sync fails, such that it is retried at the next tick.
+		_ = l.sync()
+		l.mut.Unlock()
+	}
+}
+
+// close syncs and closes the log. The commands that are appended or removed later are ignored.
+func (l *cmdLog) close() error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	close(l.stop)
+	err := l.sync()
+	if closeErr := l.file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close the command log: %w", closeErr)
+	}
+	l.file = nil
+	return err
+}
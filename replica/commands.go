@@ -0,0 +1,39 @@
+package replica
+
+import (
+	"fmt"
+
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// ClientCommand is a command sent by a client, as it is carried in the blocks.
+type ClientCommand struct {
+	ClientID       uint32
+	SequenceNumber uint64
+	Data           []byte
+	// Tag is the opaque value that the client attached to the command, such as a trace context.
+	// It is nil if the command is untagged.
+	Tag []byte
+}
+
+// ClientCommands returns the client commands in the command of a block, in order.
+// It allows modules such as an Acceptor or Executor to access the commands and their tags.
+func ClientCommands(cmd consensus.Command) ([]ClientCommand, error) {
+	batch := new(clientpb.Batch)
+	err := proto.UnmarshalOptions{AllowPartial: true}.Unmarshal([]byte(cmd), batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal command: %w", err)
+	}
+	cmds := make([]ClientCommand, len(batch.GetCommands()))
+	for i, c := range batch.GetCommands() {
+		cmds[i] = ClientCommand{
+			ClientID:       c.GetClientID(),
+			SequenceNumber: c.GetSequenceNumber(),
+			Data:           c.GetData(),
+			Tag:            c.GetTag(),
+		}
+	}
+	return cmds, nil
+}
@@ -0,0 +1,210 @@
+package replica
+
+import (
+	"net"
+	"sync"
+
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/internal/proto/commitpb"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultCommitStreamBuffer is the number of committed blocks that are buffered for each subscriber by default.
+const defaultCommitStreamBuffer = 1024
+
+// commitStream streams the committed blocks to subscribers over gRPC.
+// The blocks are handed to the subscribers by an observer on the event loop, through a bounded buffer for each
+// subscriber, such that a subscriber that does not keep up is disconnected instead of delaying the replica.
+type commitStream struct {
+	commitpb.UnimplementedCommitStreamServer
+
+	mods   *consensus.Modules
+	buffer int
+	server *grpc.Server
+
+	mut         sync.Mutex
+	head        *consensus.Block // the last block that was handed to the subscribers; nil before the first commit
+	subscribers map[*subscriber]struct{}
+}
+
+// subscriber is a subscription to the committed blocks.
+type subscriber struct {
+	blocks chan *consensus.Block
+	closed chan struct{} // closed when the subscription is ended by the replica
+	err    error         // the reason that the subscription was ended
+}
+
+func newCommitStream(conf Config, creds credentials.TransportCredentials) *commitStream {
+	buffer := conf.CommitStreamBuffer
+	if buffer <= 0 {
+		buffer = defaultCommitStreamBuffer
+	}
+	var opts []grpc.ServerOption
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+	s := &commitStream{
+		buffer:      buffer,
+		server:      grpc.NewServer(opts...),
+		subscribers: make(map[*subscriber]struct{}),
+	}
+	commitpb.RegisterCommitStreamServer(s.server, s)
+	return s
+}
+
+// InitConsensusModule gives the module a reference to the Modules object.
+// It also allows the module to set module options using the OptionsBuilder.
+func (s *commitStream) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
+	s.mods = mods
+	s.mods.EventLoop().RegisterObserver(consensus.CommitEvent{}, func(event interface{}) {
+		s.onCommit(event.(consensus.CommitEvent).Block)
+	})
+}
+
+// onCommit hands the committed block to the subscribers, and disconnects those whose buffers are full.
+func (s *commitStream) onCommit(block *consensus.Block) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	// the blocks that a replica skips when it restores a snapshot from another replica are never executed,
+	// so the subscribers cannot be given every block.
+	if s.head != nil && block.Parent() != s.head.Hash() {
+		for sub := range s.subscribers {
+			s.end(sub, status.Errorf(codes.DataLoss, "the replica skipped the blocks between view %d and %d", s.head.View(), block.View()))
+		}
+	}
+	s.head = block
+
+	for sub := range s.subscribers {
+		select {
+		case sub.blocks <- block:
+		default:
+			s.end(sub, status.Errorf(codes.ResourceExhausted, "the subscriber fell %d blocks behind", s.buffer))
+		}
+	}
+}
+
+// subscribe adds a subscriber, and returns the last block that was committed before it was added.
+// The blocks that are committed after this block are handed to the subscriber.
+func (s *commitStream) subscribe() (sub *subscriber, head *consensus.Block) {
+	sub = &subscriber{
+		blocks: make(chan *consensus.Block, s.buffer),
+		closed: make(chan struct{}),
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.subscribers[sub] = struct{}{}
+	head = s.head
+	if head == nil {
+		// the committed block may be ahead of the blocks that have been handed to the subscribers,
+		// in which case the blocks in between are handed to the subscriber again, and skipped.
+		head = s.mods.Consensus().CommittedBlock()
+	}
+	return sub, head
+}
+
+// end ends the subscription with the given error. It must be called with the mutex held.
+func (s *commitStream) end(sub *subscriber, err error) {
+	if _, ok := s.subscribers[sub]; !ok {
+		return
+	}
+	delete(s.subscribers, sub)
+	sub.err = err
+	close(sub.closed)
+}
+
+func (s *commitStream) unsubscribe(sub *subscriber) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	delete(s.subscribers, sub)
+}
+
+// SubscribeCommits streams the committed blocks whose views are at least the requested view, starting with those that
+// were committed before the subscription, which are read from the blockchain.
+func (s *commitStream) SubscribeCommits(req *commitpb.SubscribeRequest, stream commitpb.CommitStream_SubscribeCommitsServer) error {
+	sub, head := s.subscribe()
+	defer s.unsubscribe(sub)
+
+	from := consensus.View(req.GetFromView())
+	// the genesis block is never committed.
+	if from == 0 {
+		from = 1
+	}
+	var last consensus.View // the view of the last block that was sent
+	send := func(block *consensus.Block) error {
+		if block.View() < from || block.View() <= last {
+			return nil
+		}
+		last = block.View()
+		return stream.Send(committedBlockToProto(block))
+	}
+
+	if head.View() >= from {
+		blocks, ok := s.mods.BlockChain().Range(head, from)
+		if !ok {
+			return status.Errorf(codes.OutOfRange, "the committed blocks from view %d are no longer stored", from)
+		}
+		for _, block := range blocks {
+			if err := send(block); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		select {
+		case block := <-sub.blocks:
+			if err := send(block); err != nil {
+				return err
+			}
+		case <-sub.closed:
+			return sub.err
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+}
+
+// serve serves the subscribers on the listener until the server is closed.
+func (s *commitStream) serve(lis net.Listener) {
+	go func() {
+		if err := s.server.Serve(lis); err != nil {
+			s.mods.Logger().Warnf("Commit stream server failed: %v", err)
+		}
+	}()
+}
+
+// close disconnects the subscribers and stops the server.
+func (s *commitStream) close() {
+	s.server.Stop()
+}
+
+// committedBlockToProto converts a committed block to the message that is streamed to the subscribers.
+func committedBlockToProto(block *consensus.Block) *commitpb.Block {
+	hash, parent := block.Hash(), block.Parent()
+	msg := &commitpb.Block{
+		View:     uint64(block.View()),
+		Hash:     hash[:],
+		Parent:   parent[:],
+		Proposer: uint32(block.Proposer()),
+		QC:       hotstuffpb.QuorumCertToProto(block.QuorumCert()),
+	}
+	batch := new(clientpb.Batch)
+	// the command of a committed block was accepted, so it is a valid batch.
+	_ = proto.UnmarshalOptions{AllowPartial: true}.Unmarshal([]byte(block.Command()), batch)
+	for _, cmd := range batch.GetCommands() {
+		msg.Commands = append(msg.Commands, &commitpb.Command{
+			ClientID:       cmd.GetClientID(),
+			SequenceNumber: cmd.GetSequenceNumber(),
+			Data:           cmd.GetData(),
+			Tag:            cmd.GetTag(),
+		})
+	}
+	return msg
+}
@@ -0,0 +1,203 @@
+package replica
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/atomicfile"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// manifestFile is the name of the file in the data directory that describes the latest durable snapshot.
+const manifestFile = "snapshot.manifest"
+
+// safetyFile is the name of the file in the data directory in which the replica persists the view of its last vote.
+const safetyFile = "safety.state"
+
+// SnapshotSaved is emitted when the replica has saved a durable snapshot of its state.
+type SnapshotSaved struct {
+	Block *consensus.Block       // the last block whose commands are included in the snapshot
+	Meta  consensus.SnapshotMeta // the snapshot of the application; empty if there is no application
+}
+
+// Recovered is emitted when a replica that was restarted has recovered its state from its latest durable snapshot.
+// The replica then executes the blocks that were committed after the snapshot, which it fetches from the other replicas.
+type Recovered struct {
+	Block *consensus.Block       // the last block whose commands are included in the snapshot
+	Meta  consensus.SnapshotMeta // the snapshot of the application; empty if there is no application
+}
+
+// durableSnapshots saves snapshots of the executed state to stable storage, and recovers the state from the latest
+// snapshot when the replica is restarted.
+//
+// A snapshot is taken after executing the first block whose view is at least views views after the block of the
+// previous snapshot, or after executing at least size bytes of commands since the previous snapshot,
+// such that the replicas take their snapshots after the same blocks.
+// The blocks below the block of the previous snapshot are discarded when a snapshot is saved.
+// The blocks between the previous snapshot and the latest are kept, such that a replica that recovers from
+// the previous snapshot can still fetch them.
+type durableSnapshots struct {
+	mods  *consensus.Modules
+	srv   *clientSrv
+	app   consensus.Snapshotter // nil if there is no application
+	dir   string
+	views consensus.View
+	size  uint64
+
+	// the following fields are only accessed by the executor of the blocks.
+	lastView consensus.View // the view of the block of the latest snapshot
+	prevView consensus.View // the view of the block of the snapshot before the latest
+	executed uint64         // the bytes of commands executed since the latest snapshot
+}
+
+func newDurableSnapshots(conf Config, srv *clientSrv) *durableSnapshots {
+	return &durableSnapshots{
+		srv:   srv,
+		app:   conf.Snapshotter,
+		dir:   conf.DataDir,
+		views: consensus.View(conf.DurableSnapshotViews),
+		size:  conf.DurableSnapshotBytes,
+	}
+}
+
+// InitConsensusModule gives the module a reference to the Modules object.
+// It also allows the module to set module options using the OptionsBuilder.
+func (d *durableSnapshots) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
+	d.mods = mods
+}
+
+// blockExecuted is called by the client server after it has executed a block with the given number of bytes of commands.
+// It saves a snapshot if the block is due for one.
+func (d *durableSnapshots) blockExecuted(block *consensus.Block, size int) {
+	d.executed += uint64(size)
+	dueByViews := d.views > 0 && block.View() >= d.lastView+d.views
+	dueBySize := d.size > 0 && d.executed >= d.size
+	if !dueByViews && !dueBySize {
+		return
+	}
+	if err := d.save(block); err != nil {
+		d.mods.Logger().Warnf("Failed to save snapshot: %v", err)
+		return
+	}
+	d.prevView, d.lastView = d.lastView, block.View()
+	d.executed = 0
+	if d.prevView > 0 {
+		d.mods.BlockChain().DiscardBelow(d.prevView)
+	}
+}
+
+// save saves a snapshot of the application, and then replaces the manifest with one that describes the snapshot.
+func (d *durableSnapshots) save(block *consensus.Block) error {
+	var meta consensus.SnapshotMeta
+	if d.app != nil {
+		var err error
+		meta, err = d.app.TakeSnapshot(block.View())
+		if err != nil {
+			return fmt.Errorf("failed to take a snapshot of the application: %w", err)
+		}
+	}
+	state, err := d.srv.state(false)
+	if err != nil {
+		return err
+	}
+	manifest, err := proto.Marshal(&clientpb.SnapshotManifest{
+		Block:           hotstuffpb.BlockToProto(block),
+		State:           state,
+		ApplicationPath: meta.Path,
+		ApplicationSize: meta.Size,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the manifest: %w", err)
+	}
+	if err := atomicfile.WriteFile(filepath.Join(d.dir, manifestFile), manifest); err != nil {
+		return err
+	}
+	d.mods.EventLoop().AddEvent(SnapshotSaved{Block: block, Meta: meta})
+	return nil
+}
+
+// recover restores the state from the latest snapshot, if there is one, and makes its block the committed block.
+// It must be called before the replica is started.
+func (d *durableSnapshots) recover() error {
+	data, err := os.ReadFile(filepath.Join(d.dir, manifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read the manifest: %w", err)
+	}
+	manifest := new(clientpb.SnapshotManifest)
+	if err := proto.Unmarshal(data, manifest); err != nil {
+		return fmt.Errorf("failed to unmarshal the manifest: %w", err)
+	}
+	block, err := hotstuffpb.BlockFromProto(manifest.GetBlock())
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal the block of the snapshot: %w", err)
+	}
+	meta := consensus.SnapshotMeta{View: block.View(), Path: manifest.GetApplicationPath(), Size: manifest.GetApplicationSize()}
+	// the manifest is replaced after the snapshot of the application is written, so the data directory is inconsistent
+	// if the snapshot of the application is missing, or has another size than the manifest records.
+	if meta.Path != "" {
+		info, err := os.Stat(meta.Path)
+		if err != nil {
+			return fmt.Errorf("the snapshot of the application is missing: %w", err)
+		}
+		if meta.Size > 0 && info.Size() != meta.Size {
+			return fmt.Errorf("the snapshot of the application has %d bytes, but the manifest records %d bytes",
+				info.Size(), meta.Size)
+		}
+	}
+	err = d.srv.restoreState(manifest.GetState(), func() error {
+		if meta.Path == "" {
+			if d.srv.app != nil {
+				return fmt.Errorf("the snapshot does not include the state of the application")
+			}
+			return nil
+		}
+		if d.app == nil {
+			return fmt.Errorf("the snapshot includes the state of an application, but the replica has no snapshotter")
+		}
+		f, err := os.Open(meta.Path)
+		if err != nil {
+			return fmt.Errorf("failed to open the snapshot of the application: %w", err)
+		}
+		defer f.Close()
+		if err := d.app.Restore(meta, f); err != nil {
+			return fmt.Errorf("failed to restore the application: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// the chain is not pruned, since the high QC of the synchronizer still refers to the genesis block.
+	d.mods.BlockChain().Store(block)
+	if setter, ok := d.mods.Consensus().(consensus.CommittedBlockSetter); ok {
+		setter.SetCommittedBlock(block)
+	}
+	d.lastView = block.View()
+	d.mods.Logger().Infof("Recovered the snapshot of block %.8s in view %d", block.Hash(), block.View())
+	d.mods.EventLoop().AddEvent(Recovered{Block: block, Meta: meta})
+	return nil
+}
+
+// voteStorage persists the last vote of the replica, but not the last executed block, since the state of the replica
+// is recovered from its latest durable snapshot, after which the blocks that were committed since are executed again.
+type voteStorage struct {
+	consensus.SafetyStorage
+}
+
+// Load returns the persisted state without the last executed block.
+func (s voteStorage) Load() (consensus.SafetyState, error) {
+	state, err := s.SafetyStorage.Load()
+	return consensus.SafetyState{LastVote: state.LastVote}, err
+}
+
+// StoreExecuted does nothing, since the executed state is persisted by the durable snapshots.
+func (voteStorage) StoreExecuted(*consensus.Block) error { return nil }
@@ -0,0 +1,359 @@
+package replica
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/proto/apppb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultAppTimeout is how long the replica waits for the external application to accept a proposal
+	// or to suggest a batch size, unless Config.ApplicationTimeout is set.
+	defaultAppTimeout = time.Second
+	// appRetryDelay is the delay before the stream of committed blocks is reopened after it failed.
+	appRetryDelay = 100 * time.Millisecond
+)
+
+// appConnectParams makes gRPC redial the application soon after it becomes reachable again,
+// since the execution of the committed blocks is stalled until then.
+var appConnectParams = grpc.ConnectParams{
+	Backoff: backoff.Config{
+		BaseDelay:  100 * time.Millisecond,
+		Multiplier: 1.6,
+		Jitter:     0.2,
+		MaxDelay:   time.Second,
+	},
+	MinConnectTimeout: 5 * time.Second,
+}
+
+// externalApp connects the replica to an application that runs as a separate process and implements the
+// application service of apppb. It is registered as the acceptor, command queue, and executor of the replica,
+// and wraps the command cache and the client server:
+// proposals that the command cache accepts must also be accepted by the application,
+// the application may change the batch size before the replica waits for a batch to propose,
+// and the committed blocks are streamed to the application, and are passed on to the client server,
+// which acknowledges the commands to the clients, once the application has executed them.
+//
+// While the application is unreachable, the replica keeps ordering commands, but execution is stalled:
+// proposals are accepted without asking the application, and the committed blocks are queued until
+// the application is reachable again, at which point they are executed in order.
+type externalApp struct {
+	mods      *consensus.Modules
+	addr      string
+	timeout   time.Duration
+	batchSize int // the configured batch size
+	conn      *grpc.ClientConn
+	client    apppb.ApplicationClient
+	dialErr   error // the client is nil if the connection could not be set up
+	cache     *cmdCache
+	executor  consensus.ExecutorExt
+	notify    chan struct{} // signals that a block was committed
+	height    uint64        // the height of the last committed block; only accessed from the event loop
+
+	mut       sync.Mutex
+	pending   []pendingBlock     // the committed blocks that the application has not acknowledged
+	queued    map[cmdID]struct{} // the commands of the pending blocks
+	acked     uint64             // the height of the last block that the application acknowledged
+	reachable bool
+}
+
+// pendingBlock is a committed block that is waiting to be executed by the application.
+type pendingBlock struct {
+	msg   *apppb.Block
+	block *consensus.Block
+	ids   []cmdID // the commands of the block that are executed for the first time
+}
+
+func newExternalApp(conf Config, clientSrv *clientSrv) *externalApp {
+	timeout := conf.ApplicationTimeout
+	if timeout <= 0 {
+		timeout = defaultAppTimeout
+	}
+	a := &externalApp{
+		addr:      conf.ApplicationAddress,
+		timeout:   timeout,
+		batchSize: int(conf.BatchSize),
+		cache:     clientSrv.cmdCache,
+		executor:  clientSrv,
+		notify:    make(chan struct{}, 1),
+		queued:    make(map[cmdID]struct{}),
+		reachable: true,
+	}
+	// the default transport also dials unix domain sockets.
+	transport, _ := backend.GetTransport("")
+	a.conn, a.dialErr = grpc.Dial(
+		backend.GorumsAddress(conf.ApplicationAddress),
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(transport.Dial),
+		grpc.WithConnectParams(appConnectParams),
+	)
+	if a.dialErr == nil {
+		a.client = apppb.NewApplicationClient(a.conn)
+	}
+	return a
+}
+
+// InitConsensusModule gives the module a reference to the Modules object.
+// It also allows the module to set module options using the OptionsBuilder.
+func (a *externalApp) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
+	a.mods = mods
+}
+
+// Accept returns true if both the command cache and the application accept the batch.
+// The batch is accepted without asking the application if it does not answer in time.
+func (a *externalApp) Accept(cmd consensus.Command) bool {
+	if !a.cache.Accept(cmd) {
+		return false
+	}
+	if a.client == nil {
+		return true
+	}
+	cmds, err := ClientCommands(cmd)
+	if err != nil {
+		a.mods.Logger().Errorf("Failed to unmarshal batch: %v", err)
+		return false
+	}
+	req := &apppb.AcceptRequest{Commands: make([]*apppb.Command, len(cmds))}
+	for i, cmd := range cmds {
+		req.Commands[i] = &apppb.Command{ClientID: cmd.ClientID, SequenceNumber: cmd.SequenceNumber, Data: cmd.Data}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	res, err := a.client.Accept(ctx, req)
+	if status.Code(err) == codes.Unimplemented {
+		// the application does not validate proposals.
+		return true
+	}
+	a.setReachable(err)
+	if err != nil {
+		return true
+	}
+	return res.GetAccept()
+}
+
+// Proposed tells the command cache that the batch was proposed.
+func (a *externalApp) Proposed(cmd consensus.Command) {
+	a.cache.Proposed(cmd)
+}
+
+// Get asks the application for the size of the next batch, and then returns a batch from the command cache.
+// The batch size is left unchanged if the application does not answer in time.
+func (a *externalApp) Get(ctx context.Context) (cmd consensus.Command, ok bool) {
+	if a.client != nil {
+		hintCtx, cancel := context.WithTimeout(ctx, a.timeout)
+		res, err := a.client.BatchHint(hintCtx, &apppb.BatchHintRequest{
+			Pending:   uint32(a.cache.len()),
+			BatchSize: uint32(a.batchSize),
+		})
+		cancel()
+		if ctx.Err() != nil {
+			return "", false
+		}
+		if status.Code(err) != codes.Unimplemented {
+			a.setReachable(err)
+		}
+		if err == nil {
+			batchSize := int(res.GetBatchSize())
+			if batchSize == 0 {
+				batchSize = a.batchSize
+			}
+			a.cache.setBatchSize(batchSize)
+		}
+	}
+	return a.cache.Get(ctx)
+}
+
+// Exec queues the block to be sent to the application.
+// The commands that have already been executed, or that are queued in an earlier block, are left out,
+// such that the application executes each command once.
+func (a *externalApp) Exec(block *consensus.Block) {
+	a.height++
+	hash, parent := block.Hash(), block.Parent()
+	p := pendingBlock{
+		msg: &apppb.Block{
+			Height:   a.height,
+			Hash:     hash[:],
+			Parent:   parent[:],
+			View:     uint64(block.View()),
+			Proposer: uint32(block.Proposer()),
+		},
+		block: block,
+	}
+	// a block whose commands cannot be decoded is still sent, such that the heights have no gaps.
+	cmds, err := ClientCommands(block.Command())
+	if err != nil {
+		a.mods.Logger().Errorf("Failed to unmarshal command: %v", err)
+	}
+
+	a.mut.Lock()
+	for _, cmd := range cmds {
+		id := cmdID{cmd.ClientID, cmd.SequenceNumber}
+		if _, ok := a.queued[id]; ok || a.cache.isExecuted(id) {
+			continue
+		}
+		a.queued[id] = struct{}{}
+		p.ids = append(p.ids, id)
+		p.msg.Commands = append(p.msg.Commands, &apppb.Command{
+			ClientID:       cmd.ClientID,
+			SequenceNumber: cmd.SequenceNumber,
+			Data:           cmd.Data,
+		})
+	}
+	a.pending = append(a.pending, p)
+	a.mut.Unlock()
+
+	select {
+	case a.notify <- struct{}{}:
+	default:
+	}
+}
+
+// run streams the committed blocks to the application until the context is cancelled.
+// The stream is reopened whenever it fails.
+func (a *externalApp) run(ctx context.Context) {
+	if a.client == nil {
+		a.mods.Logger().Errorf("Failed to connect to the application at %s: %v", a.addr, a.dialErr)
+		return
+	}
+	for {
+		err := a.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		a.setReachable(err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(appRetryDelay):
+		}
+	}
+}
+
+// stream opens a stream to the application, and sends the blocks that have not been acknowledged,
+// followed by the blocks that are committed later, until the stream fails.
+// The acknowledged blocks are executed by the receiver, which has exited when stream returns,
+// such that the blocks are executed in order even if the stream is reopened.
+func (a *externalApp) stream(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// the stream is opened when the application is reachable.
+	stream, err := a.client.Execute(ctx, grpc.WaitForReady(true))
+	if err != nil {
+		return err
+	}
+	a.setReachable(nil)
+
+	var recvErr error
+	received := make(chan struct{})
+	go func() {
+		recvErr = a.receive(stream)
+		close(received)
+	}()
+	err = a.send(ctx, stream, received)
+	cancel()
+	<-received
+	if err == nil {
+		err = recvErr
+	}
+	return err
+}
+
+// send sends the pending blocks on the stream, starting with the first block that has not been acknowledged.
+// It returns nil if the receiver exits first.
+func (a *externalApp) send(ctx context.Context, stream apppb.Application_ExecuteClient, received <-chan struct{}) error {
+	a.mut.Lock()
+	next := a.acked + 1
+	a.mut.Unlock()
+	for {
+		for _, block := range a.unsent(next) {
+			if err := stream.Send(block); err == io.EOF {
+				// the stream has failed, and the error is returned to the receiver.
+				return nil
+			} else if err != nil {
+				return err
+			}
+			next = block.GetHeight() + 1
+		}
+		select {
+		case <-a.notify:
+		case <-received:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// unsent returns the pending blocks from the given height.
+func (a *externalApp) unsent(height uint64) []*apppb.Block {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	if len(a.pending) == 0 || height < a.pending[0].msg.GetHeight() {
+		return nil
+	}
+	var blocks []*apppb.Block
+	for _, p := range a.pending[height-a.pending[0].msg.GetHeight():] {
+		blocks = append(blocks, p.msg)
+	}
+	return blocks
+}
+
+// receive executes the blocks that the application acknowledges, until the stream fails.
+func (a *externalApp) receive(stream apppb.Application_ExecuteClient) error {
+	for {
+		res, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		a.executed(res.GetHeight())
+	}
+}
+
+// executed passes the blocks up to the given height on to the client server.
+func (a *externalApp) executed(height uint64) {
+	a.mut.Lock()
+	var blocks []pendingBlock
+	for len(a.pending) > 0 && a.pending[0].msg.GetHeight() <= height {
+		blocks = append(blocks, a.pending[0])
+		a.acked = a.pending[0].msg.GetHeight()
+		a.pending = a.pending[1:]
+	}
+	a.mut.Unlock()
+
+	for _, p := range blocks {
+		a.executor.Exec(p.block)
+		a.mut.Lock()
+		for _, id := range p.ids {
+			delete(a.queued, id)
+		}
+		a.mut.Unlock()
+	}
+}
+
+// setReachable records whether the last request to the application succeeded, and logs when this changes.
+func (a *externalApp) setReachable(err error) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	if err == nil && !a.reachable {
+		a.mods.Logger().Infof("Reconnected to the application at %s; executing %d committed blocks", a.addr, len(a.pending))
+	} else if err != nil && a.reachable {
+		a.mods.Logger().Warnf("The application at %s is unreachable, and execution is stalled: %v", a.addr, err)
+	}
+	a.reachable = err == nil
+}
+
+// close closes the connection to the application.
+func (a *externalApp) close() {
+	if a.conn != nil {
+		_ = a.conn.Close()
+	}
+}
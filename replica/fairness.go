@@ -0,0 +1,178 @@
+package replica
+
+import (
+	"container/list"
+	"fmt"
+)
+
+// BatchPolicy determines the order in which the commands of different clients are taken from the command cache
+// when a batch is assembled.
+type BatchPolicy string
+
+const (
+	// FIFOBatches takes the commands in the order they were admitted, such that a client that sends many commands
+	// may fill the batches while the commands of the other clients wait.
+	FIFOBatches BatchPolicy = "fifo"
+	// FairBatches takes the commands of the clients in turn, in proportion to their weights (deficit round-robin),
+	// such that each client that has commands in the cache gets its share of every batch.
+	FairBatches BatchPolicy = "fair"
+)
+
+// ParseBatchPolicy returns the batch policy with the given name. The empty string is the FIFO policy.
+func ParseBatchPolicy(name string) (BatchPolicy, error) {
+	switch policy := BatchPolicy(name); policy {
+	case "":
+		return FIFOBatches, nil
+	case FIFOBatches, FairBatches:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown batch policy '%s'", name)
+}
+
+// clientQueue holds the elements of the commands of a client in the command cache, in the order of the cache.
+type clientQueue struct {
+	clientID uint32
+	cmds     list.List     // the elements of the commands in the cache
+	deficit  int           // the number of commands that the client may still take in its current turn
+	turn     *list.Element // the element of the queue in the ring of active clients
+}
+
+// fairQueue schedules the commands of the command cache by deficit round-robin across the clients.
+// Each client that has commands in the cache is active, and takes its turn in the ring of active clients.
+// At the start of its turn, its deficit is increased by its weight, and it takes one command per unit of deficit
+// until the deficit is spent or it has no more commands, at which point the next client's turn begins.
+// A turn that is interrupted by the end of a batch is continued in the next batch.
+//
+// A client that has no commands left is removed from the ring and loses its deficit, such that a client cannot save up
+// turns while it is idle and then monopolize the batches with a burst. A client that becomes active is added at the end
+// of the current round, such that it takes its turn before any other client takes another turn.
+type fairQueue struct {
+	weights map[uint32]int // the weights of the clients; a client without a weight has weight 1
+	clients map[uint32]*clientQueue
+	ring    list.List     // the active clients, in the order of their turns
+	current *list.Element // the client whose turn it is; nil if no client is active
+}
+
+func newFairQueue(weights map[uint32]uint32) *fairQueue {
+	f := &fairQueue{
+		weights: make(map[uint32]int, len(weights)),
+		clients: make(map[uint32]*clientQueue),
+	}
+	for client, weight := range weights {
+		if weight > 0 {
+			f.weights[client] = int(weight)
+		}
+	}
+	return f
+}
+
+// weight returns the weight of the client.
+func (f *fairQueue) weight(clientID uint32) int {
+	if weight, ok := f.weights[clientID]; ok {
+		return weight
+	}
+	return 1
+}
+
+// queue returns the queue of the client, which is activated if the client has no commands in the cache.
+func (f *fairQueue) queue(clientID uint32) *clientQueue {
+	if q, ok := f.clients[clientID]; ok {
+		return q
+	}
+	q := &clientQueue{clientID: clientID}
+	f.clients[clientID] = q
+	if f.current == nil {
+		q.turn = f.ring.PushBack(q)
+		f.begin(q.turn)
+	} else {
+		// the end of the current round is just before the current client.
+		q.turn = f.ring.InsertBefore(q, f.current)
+	}
+	return q
+}
+
+// begin begins the turn of the client.
+func (f *fairQueue) begin(turn *list.Element) {
+	f.current = turn
+	if turn != nil {
+		q := turn.Value.(*clientQueue)
+		q.deficit += f.weight(q.clientID)
+	}
+}
+
+// following returns the client whose turn follows the turn of the given client, or nil if there is no other client.
+func (f *fairQueue) following(turn *list.Element) *list.Element {
+	next := turn.Next()
+	if next == nil {
+		next = f.ring.Front()
+	}
+	if next == turn {
+		return nil
+	}
+	return next
+}
+
+// push adds the element of a command that was added at the back of the cache.
+func (f *fairQueue) push(elem *list.Element) {
+	cached := elem.Value.(*cachedCmd)
+	q := f.queue(cached.cmd.GetClientID())
+	cached.clientElem = q.cmds.PushBack(elem)
+}
+
+// requeue adds the element of a command that was put back at the front of the cache. The requeued commands of a client
+// keep their order in front of the commands of the client that were in the cache, which are marked by the first call
+// to requeue with the given marks.
+func (f *fairQueue) requeue(elem *list.Element, marks map[uint32]*list.Element) {
+	cached := elem.Value.(*cachedCmd)
+	q := f.queue(cached.cmd.GetClientID())
+	mark, ok := marks[q.clientID]
+	if !ok {
+		mark = q.cmds.Front()
+		marks[q.clientID] = mark
+	}
+	if mark == nil {
+		cached.clientElem = q.cmds.PushBack(elem)
+	} else {
+		cached.clientElem = q.cmds.InsertBefore(elem, mark)
+	}
+}
+
+// remove removes the command from the queue of its client, and deactivates the client if it has no commands left.
+func (f *fairQueue) remove(cached *cachedCmd) {
+	q := f.clients[cached.cmd.GetClientID()]
+	q.cmds.Remove(cached.clientElem)
+	cached.clientElem = nil
+	if q.cmds.Len() > 0 {
+		return
+	}
+	if f.current == q.turn {
+		f.begin(f.following(q.turn))
+	}
+	f.ring.Remove(q.turn)
+	delete(f.clients, q.clientID)
+}
+
+// next returns the element of the next command to take from the cache, or nil if the cache is empty.
+// The command is only charged to its client when it is taken (see take).
+func (f *fairQueue) next() *list.Element {
+	if f.current == nil {
+		return nil
+	}
+	q := f.current.Value.(*clientQueue)
+	for q.deficit <= 0 {
+		f.begin(f.following(f.current))
+		if f.current == nil {
+			// the client is the only active client, so it begins a new turn.
+			f.begin(q.turn)
+		}
+		q = f.current.Value.(*clientQueue)
+	}
+	return q.cmds.Front().Value.(*list.Element)
+}
+
+// take charges the client whose turn it is for a command. It must be called before the command is removed.
+func (f *fairQueue) take() {
+	if f.current != nil {
+		f.current.Value.(*clientQueue).deficit--
+	}
+}
@@ -0,0 +1,78 @@
+package replica
+
+import (
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/synchronizer"
+	"google.golang.org/protobuf/proto"
+)
+
+// forwardCommandEvent asks the event loop to forward a serialized command to the leaders.
+type forwardCommandEvent struct {
+	cmd []byte
+}
+
+// forwardCommand forwards a command received from a client to the leaders.
+// The leaders are looked up by the event loop, as the synchronizer is not safe for concurrent use.
+func (srv *clientSrv) forwardCommand(cmd *clientpb.Command) {
+	b, err := proto.Marshal(cmd)
+	if err != nil {
+		srv.mods.Logger().Errorf("Failed to marshal command: %v", err)
+		return
+	}
+	srv.mods.EventLoop().AddEvent(forwardCommandEvent{cmd: b})
+}
+
+// addForwarded adds a command that was forwarded by another replica to the command cache.
+// Commands that are already in the cache, or have already been proposed, are dropped,
+// and so are commands that do not fit in the cache.
+func (srv *clientSrv) addForwarded(id hotstuff.ID, b []byte) {
+	cmd := new(clientpb.Command)
+	if err := proto.Unmarshal(b, cmd); err != nil {
+		srv.mods.Logger().Infof("Failed to unmarshal command forwarded by replica %d: %v", id, err)
+		return
+	}
+	_, evicted, _ := srv.cmdCache.addCommand(cmd, false)
+	srv.failCommands(evicted, errCacheFull)
+}
+
+func (srv *Replica) initForwarding() {
+	srv.hs.EventLoop().RegisterHandler(forwardCommandEvent{}, func(event interface{}) {
+		srv.forwardToLeaders(event.(forwardCommandEvent).cmd)
+	})
+	// the commands are also kept by this replica, so the newest of them are not forwarded when the event queue is full.
+	srv.hs.EventLoop().SetOverflowPolicy(forwardCommandEvent{}, eventloop.DropNewest)
+	// forwarded commands are accepted regardless of whether this replica forwards its own commands.
+	srv.hsSrv.SetCommandHandler(srv.clientSrv.addForwarded)
+	if !srv.clientSrv.forward {
+		return
+	}
+	// the leaders change with the view, so the commands that are still waiting to be proposed
+	// are forwarded again to the new leaders.
+	srv.hs.EventLoop().RegisterObserver(synchronizer.ViewChangeEvent{}, func(_ interface{}) {
+		for _, cmd := range srv.clientSrv.cmdCache.clientCommands() {
+			b, err := proto.Marshal(cmd)
+			if err != nil {
+				srv.hs.Logger().Errorf("Failed to marshal command: %v", err)
+				continue
+			}
+			srv.forwardToLeaders(b)
+		}
+	})
+}
+
+// forwardToLeaders sends the command to the leaders of the current and the next view.
+// The leader of the current view may still be waiting for commands to propose,
+// whereas the leader of the next view proposes once the current view succeeds.
+func (srv *Replica) forwardToLeaders(cmd []byte) {
+	view := srv.hs.Synchronizer().View()
+	current := srv.hs.LeaderRotation().GetLeader(view)
+	next := srv.hs.LeaderRotation().GetLeader(view + 1)
+	if current != srv.hs.ID() {
+		srv.cfg.ForwardCommand(current, cmd)
+	}
+	if next != current && next != srv.hs.ID() {
+		srv.cfg.ForwardCommand(next, cmd)
+	}
+}
@@ -0,0 +1,103 @@
+package replica
+
+import (
+	"sync"
+
+	"github.com/relab/hotstuff/consensus"
+)
+
+// execPipeline executes the committed blocks on its own goroutine, such that the latency of the application does not
+// add to the latency of the consensus protocol. The blocks are executed one at a time, in the order in which they were
+// committed. The consensus protocol is only held up when the pipeline is full, that is, when lag blocks wait to be
+// executed, such that the executed state does not fall arbitrarily far behind the committed blocks.
+//
+// The pipeline replaces the executor as a module, and is drained when the event loop is shut down.
+type execPipeline struct {
+	mods     *consensus.Modules
+	exec     consensus.ExecutorExt
+	blocks   chan *consensus.Block
+	pending  sync.WaitGroup // the blocks that have been committed, but not executed yet
+	stop     chan struct{}  // closed by close
+	stopOnce sync.Once
+	done     chan struct{} // closed when the pipeline has been drained
+}
+
+// newExecPipeline returns a pipeline that executes the blocks with the executor,
+// and that holds up to lag blocks that wait to be executed.
+func newExecPipeline(exec consensus.ExecutorExt, lag int) *execPipeline {
+	return &execPipeline{
+		exec:   exec,
+		blocks: make(chan *consensus.Block, lag),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// InitConsensusModule gives the module a reference to the Modules object, and starts the pipeline.
+// It also allows the module to set module options using the OptionsBuilder.
+func (p *execPipeline) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
+	p.mods = mods
+	// the hook runs before the hook of the client server, which fails the commands that have not been executed.
+	p.mods.EventLoop().RegisterShutdownHook(func() interface{} {
+		p.close()
+		return nil
+	})
+	go p.run()
+}
+
+// Exec queues the block to be executed. It blocks while the pipeline is full.
+func (p *execPipeline) Exec(block *consensus.Block) {
+	p.pending.Add(1)
+	select {
+	case p.blocks <- block:
+	case <-p.stop:
+		p.pending.Done()
+		p.mods.Logger().Warnf("Block %.8s in view %d was not executed, since the execution pipeline is stopped",
+			block.Hash(), block.View())
+	}
+}
+
+// run executes the queued blocks until the pipeline is stopped, and then executes the blocks that remain.
+func (p *execPipeline) run() {
+	defer close(p.done)
+	for {
+		select {
+		case block := <-p.blocks:
+			p.execute(block)
+		case <-p.stop:
+			for {
+				select {
+				case block := <-p.blocks:
+					p.execute(block)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *execPipeline) execute(block *consensus.Block) {
+	p.exec.Exec(block)
+	p.pending.Done()
+}
+
+// wait waits until the blocks that have been queued have been executed.
+// It must be called by the event loop, which queues the blocks, such that no blocks are queued in the meantime.
+func (p *execPipeline) wait() {
+	p.pending.Wait()
+}
+
+// len returns the number of blocks that wait to be executed.
+func (p *execPipeline) len() int {
+	return len(p.blocks)
+}
+
+// close stops the pipeline after the queued blocks have been executed, and waits for them.
+// The blocks that are committed after close are not executed.
+func (p *execPipeline) close() {
+	p.stopOnce.Do(func() { close(p.stop) })
+	<-p.done
+}
+
+var _ consensus.ExecutorExt = (*execPipeline)(nil)
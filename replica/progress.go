@@ -0,0 +1,29 @@
+package replica
+
+import (
+	"sync/atomic"
+
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/synchronizer"
+)
+
+// progress is the view and the number of committed commands of a replica.
+// It is recorded by observers on the event loop, and can be read without blocking the event loop.
+type progress struct {
+	view      uint64 // accessed atomically
+	committed uint64 // accessed atomically
+}
+
+func (srv *Replica) initProgress() {
+	srv.hs.EventLoop().RegisterObserver(synchronizer.ViewChangeEvent{}, func(event interface{}) {
+		atomic.StoreUint64(&srv.progress.view, uint64(event.(synchronizer.ViewChangeEvent).View))
+	})
+	srv.hs.EventLoop().RegisterObserver(consensus.CommitEvent{}, func(event interface{}) {
+		atomic.AddUint64(&srv.progress.committed, uint64(event.(consensus.CommitEvent).Commands))
+	})
+}
+
+// Progress returns the current view of the replica and the number of commands that it has committed.
+func (srv *Replica) Progress() (view consensus.View, committed uint64) {
+	return consensus.View(atomic.LoadUint64(&srv.progress.view)), atomic.LoadUint64(&srv.progress.committed)
+}
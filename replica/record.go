@@ -0,0 +1,224 @@
+package replica
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"github.com/relab/hotstuff/internal/proto/replaypb"
+	"github.com/relab/hotstuff/internal/protostream"
+	"github.com/relab/hotstuff/synchronizer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// recorder records the execution of a replica, such that it can be replayed by a Replayer.
+//
+// The consensus modules of a replica are deterministic, except for their inputs: the events processed by the event
+// loop, which include the messages received from the other replicas and the expiry of the view timer,
+// the commands returned by the command queue and the decisions of the acceptor, the blocks fetched from the other
+// replicas, and the signatures created by the replica, which are randomized.
+// The events are recorded in the order in which they are processed, and the other inputs when they are produced.
+type recorder struct {
+	mods    *consensus.Modules
+	wr      *protostream.Writer
+	errOnce sync.Once
+}
+
+func newRecorder(wr io.Writer) *recorder {
+	return &recorder{wr: protostream.NewWriter(wr)}
+}
+
+// InitConsensusModule gives the module a reference to the Modules object.
+// It also allows the module to set module options using the OptionsBuilder.
+func (r *recorder) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
+	r.mods = mods
+	r.mods.EventLoop().RegisterTap(func(event interface{}) {
+		if entry := eventToEntry(event); entry != nil {
+			r.record(entry)
+		}
+	})
+}
+
+// record writes the entry to the recording. Only the first error is logged.
+func (r *recorder) record(entry *replaypb.Entry) {
+	entry.Time = timestamppb.New(r.mods.Clock().Now())
+	if err := r.wr.Write(entry); err != nil {
+		r.errOnce.Do(func() {
+			r.mods.Logger().Errorf("Failed to record the execution: %v", err)
+		})
+	}
+}
+
+// recordReplicas records the configuration of replicas that the replica connects to.
+func (r *recorder) recordReplicas(replicas []backend.ReplicaInfo) error {
+	m := &replaypb.Replicas{ID: uint32(r.mods.ID())}
+	for _, replica := range replicas {
+		key, err := keygen.PublicKeyToPEM(replica.PubKey)
+		if err != nil {
+			return err
+		}
+		m.Replicas = append(m.Replicas, &replaypb.Replica{ID: uint32(replica.ID), PublicKey: key})
+	}
+	r.record(&replaypb.Entry{Input: &replaypb.Entry_Replicas{Replicas: m}})
+	return nil
+}
+
+// eventToEntry returns the recording entry of an event, or nil if events of its type are not recorded.
+// The recorded events are those that the consensus modules handle. The other events are either handled by
+// modules that are not replayed, such as the backend and the metrics, or caused by the recorded events.
+func eventToEntry(event interface{}) *replaypb.Entry {
+	switch e := event.(type) {
+	case consensus.ProposeMsg:
+		return &replaypb.Entry{Input: &replaypb.Entry_Proposal{Proposal: &replaypb.Proposal{
+			ID:       uint32(e.ID),
+			Proposal: hotstuffpb.ProposalToProto(e),
+		}}}
+	case consensus.VoteMsg:
+		return &replaypb.Entry{Input: &replaypb.Entry_Vote{Vote: &replaypb.Vote{
+			ID:       uint32(e.ID),
+			Cert:     hotstuffpb.PartialCertToProto(e.PartialCert),
+			Deferred: e.Deferred,
+		}}}
+	case consensus.TimeoutMsg:
+		return &replaypb.Entry{Input: &replaypb.Entry_Timeout{Timeout: &replaypb.Timeout{
+			ID:  uint32(e.ID),
+			Msg: hotstuffpb.TimeoutMsgToProto(e),
+		}}}
+	case consensus.NewViewMsg:
+		return &replaypb.Entry{Input: &replaypb.Entry_NewView{NewView: &replaypb.NewView{
+			ID:       uint32(e.ID),
+			SyncInfo: hotstuffpb.SyncInfoToProto(e.SyncInfo),
+		}}}
+	case synchronizer.LocalTimeoutEvent:
+		return &replaypb.Entry{Input: &replaypb.Entry_LocalTimeout{LocalTimeout: &replaypb.LocalTimeout{}}}
+	case consensus.FetchedEvent:
+		fetched := &replaypb.Fetched{Hash: e.Hash[:]}
+		if e.Block != nil {
+			fetched.Block = hotstuffpb.BlockToProto(e.Block)
+		}
+		return &replaypb.Entry{Input: &replaypb.Entry_Fetched{Fetched: fetched}}
+	}
+	return nil
+}
+
+// entryToEvent returns the event of a recording entry, or nil if the entry is not an event.
+func entryToEvent(entry *replaypb.Entry) (interface{}, error) {
+	switch input := entry.GetInput().(type) {
+	case *replaypb.Entry_Proposal:
+		proposal, err := hotstuffpb.ProposalFromProto(input.Proposal.GetProposal())
+		if err != nil {
+			return nil, err
+		}
+		proposal.ID = hotstuff.ID(input.Proposal.GetID())
+		return proposal, nil
+	case *replaypb.Entry_Vote:
+		cert, err := hotstuffpb.PartialCertFromProto(input.Vote.GetCert())
+		if err != nil {
+			return nil, err
+		}
+		return consensus.VoteMsg{ID: hotstuff.ID(input.Vote.GetID()), PartialCert: cert, Deferred: input.Vote.GetDeferred()}, nil
+	case *replaypb.Entry_Timeout:
+		timeoutMsg, err := hotstuffpb.TimeoutMsgFromProto(input.Timeout.GetMsg())
+		if err != nil {
+			return nil, err
+		}
+		timeoutMsg.ID = hotstuff.ID(input.Timeout.GetID())
+		return timeoutMsg, nil
+	case *replaypb.Entry_NewView:
+		syncInfo, err := hotstuffpb.SyncInfoFromProto(input.NewView.GetSyncInfo())
+		if err != nil {
+			return nil, err
+		}
+		return consensus.NewViewMsg{ID: hotstuff.ID(input.NewView.GetID()), SyncInfo: syncInfo}, nil
+	case *replaypb.Entry_LocalTimeout:
+		return synchronizer.LocalTimeoutEvent{}, nil
+	case *replaypb.Entry_Fetched:
+		event := consensus.FetchedEvent{Hash: toHash(input.Fetched.GetHash())}
+		if input.Fetched.GetBlock() != nil {
+			block, err := hotstuffpb.BlockFromProto(input.Fetched.GetBlock())
+			if err != nil {
+				return nil, err
+			}
+			event.Block = block
+		}
+		return event, nil
+	}
+	return nil, nil
+}
+
+// recordingConfig records the blocks that are fetched from the other replicas.
+type recordingConfig struct {
+	*backend.Config
+	rec *recorder
+}
+
+// Fetch requests a block from all the replicas in the configuration, and records the result.
+func (cfg recordingConfig) Fetch(ctx context.Context, hash consensus.Hash) (*consensus.Block, bool) {
+	block, ok := cfg.Config.Fetch(ctx, hash)
+	fetch := &replaypb.Fetch{Hash: hash[:]}
+	if ok {
+		fetch.Block = hotstuffpb.BlockToProto(block)
+	}
+	cfg.rec.record(&replaypb.Entry{Input: &replaypb.Entry_Fetch{Fetch: fetch}})
+	return block, ok
+}
+
+// recordingCrypto records the signatures created by the replica.
+type recordingCrypto struct {
+	consensus.Crypto
+	rec *recorder
+}
+
+// Sign signs a hash, and records the signature.
+func (c recordingCrypto) Sign(hash consensus.Hash) (consensus.Signature, error) {
+	sig, err := c.Crypto.Sign(hash)
+	if err == nil {
+		c.rec.record(&replaypb.Entry{Input: &replaypb.Entry_Signature{Signature: &replaypb.Signature{
+			Hash:      hash[:],
+			Signature: hotstuffpb.SignatureToProto(sig),
+		}}})
+	}
+	return sig, err
+}
+
+// CreatePartialCert signs a single block, and records the partial certificate.
+func (c recordingCrypto) CreatePartialCert(block *consensus.Block) (consensus.PartialCert, error) {
+	cert, err := c.Crypto.CreatePartialCert(block)
+	if err == nil {
+		c.rec.record(&replaypb.Entry{Input: &replaypb.Entry_PartialCert{PartialCert: &replaypb.PartialCert{
+			Cert: hotstuffpb.PartialCertToProto(cert),
+		}}})
+	}
+	return cert, err
+}
+
+// recordingQueue records the commands returned by the command queue and the decisions of the acceptor.
+type recordingQueue struct {
+	queue    consensus.CommandQueue
+	acceptor consensus.Acceptor
+	rec      *recorder
+}
+
+// Get returns the next command to be proposed, and records it.
+func (q recordingQueue) Get(ctx context.Context) (consensus.Command, bool) {
+	cmd, ok := q.queue.Get(ctx)
+	q.rec.record(&replaypb.Entry{Input: &replaypb.Entry_Command{Command: &replaypb.Command{Command: []byte(cmd), OK: ok}}})
+	return cmd, ok
+}
+
+// Accept returns true if the replica should accept the command, and records the decision.
+func (q recordingQueue) Accept(cmd consensus.Command) bool {
+	accepted := q.acceptor.Accept(cmd)
+	q.rec.record(&replaypb.Entry{Input: &replaypb.Entry_Accept{Accept: &replaypb.Accept{Command: []byte(cmd), Accepted: accepted}}})
+	return accepted
+}
+
+// Proposed tells the acceptor that the propose phase for the given command succeeded.
+func (q recordingQueue) Proposed(cmd consensus.Command) {
+	q.acceptor.Proposed(cmd)
+}
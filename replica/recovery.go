@@ -0,0 +1,92 @@
+package replica
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/relab/hotstuff/consensus"
+)
+
+// recover restores the state of a replica that is restarted with the data directory of its previous run.
+// It is called before the replica listens for connections, and the steps build on each other:
+//
+//  1. The executed state is restored from the latest durable snapshot, whose block becomes the committed block.
+//     The blocks that were committed after it are not replayed from the data directory; they are fetched from the
+//     other replicas and executed once the replica has started, while the blocks covered by the snapshot are skipped.
+//  2. The synchronizer resumes after the last view that the replica voted in, and after the view of the snapshot,
+//     such that the replica neither proposes nor votes again in a view that it took part in before.
+//  3. The commands in the command log that have not been executed according to the restored state are reloaded.
+//
+// An error is returned if the stores in the data directory are inconsistent, in which case the replica must not be
+// started, since it could violate safety or execute commands twice.
+func (srv *Replica) recover(conf Config) error {
+	if srv.durable != nil {
+		if err := srv.restoreSnapshot(conf); err != nil {
+			return err
+		}
+	}
+	if err := srv.restoreView(); err != nil {
+		return err
+	}
+	if conf.PersistCommands {
+		srv.reloadCommands(conf)
+	}
+	return nil
+}
+
+// restoreSnapshot restores the state of the replica from its latest durable snapshot.
+// Durable snapshots are disabled if they cannot be used.
+func (srv *Replica) restoreSnapshot(conf Config) error {
+	if conf.Application != nil && conf.Snapshotter == nil {
+		srv.hs.Logger().Error("Durable snapshots are disabled, since the application has no snapshotter")
+		srv.disableDurableSnapshots()
+		return nil
+	}
+	if err := os.MkdirAll(conf.DataDir, 0o755); err != nil {
+		srv.hs.Logger().Errorf("Durable snapshots are disabled, since the data directory could not be created: %v", err)
+		srv.disableDurableSnapshots()
+		return nil
+	}
+	if err := srv.durable.recover(); err != nil {
+		return fmt.Errorf("failed to recover from the latest snapshot: %w", err)
+	}
+	if srv.clientSrv.transfer != nil {
+		srv.clientSrv.transfer.lastView = srv.durable.lastView
+	}
+	return nil
+}
+
+// restoreView makes the synchronizer resume in the view after the last view that the replica voted in,
+// or after the view of the committed block, if that is later.
+// The leader votes for its own proposal, so this also skips the views that the replica proposed in.
+func (srv *Replica) restoreView() error {
+	storage := srv.hs.SafetyStorage()
+	if storage == nil {
+		return nil
+	}
+	state, err := storage.Load()
+	if err != nil {
+		return fmt.Errorf("the safety state is unusable: %w", err)
+	}
+	view := state.LastVote
+	if committed := srv.hs.Consensus().CommittedBlock().View(); committed > view {
+		view = committed
+	}
+	if view == 0 {
+		return nil
+	}
+	restorer, ok := srv.hs.Synchronizer().(consensus.ViewRestorer)
+	if !ok {
+		return nil
+	}
+	restorer.RestoreView(view + 1)
+	srv.hs.Logger().Infof("Resuming in view %d", view+1)
+	return nil
+}
+
+// RecoveryError returns the error that prevented the replica from recovering its state from its data directory,
+// or nil if it recovered or has no data directory. Listen and Connect fail with this error,
+// since a replica that did not recover must not be started.
+func (srv *Replica) RecoveryError() error {
+	return srv.recoverErr
+}
@@ -0,0 +1,384 @@
+package replica
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"github.com/relab/hotstuff/internal/proto/replaypb"
+	"github.com/relab/hotstuff/internal/protostream"
+	"github.com/relab/hotstuff/logging"
+)
+
+// Replayer replays the recorded execution of a replica (see Config.Recording) in-process, without a network.
+//
+// The consensus modules process the recorded events in the recorded order, while the command queue, the acceptor,
+// the fetching of blocks, and the signing are answered from the recording. The events that the modules add to the
+// event loop themselves are dropped if they are of a recorded type, since the recorded copies are processed instead.
+// The messages that the replica sends are discarded, and the view timer never expires,
+// since its expiries are recorded events. Thus, the replay is deterministic, and can be stepped through event by event,
+// for example in a debugger. After all events have been replayed, Hash returns the hash of the executed commands,
+// which is equal to that of the recorded replica when it stopped.
+type Replayer struct {
+	mods      *consensus.Modules
+	clientSrv *clientSrv
+	clock     *replayClock
+	events    []recordedEvent
+	next      int
+	cancel    context.CancelFunc
+}
+
+type recordedEvent struct {
+	time  time.Time
+	event interface{}
+}
+
+// NewReplayer returns a replayer for the recording read from recording.
+// The configuration must be that of the recorded replica, and the builder must have the same consensus modules
+// registered, such as the consensus, crypto, leader rotation, synchronizer, and blockchain implementations.
+// The network and client settings of the configuration are not used.
+// A recording that ends with a partially written entry, such as that of a replica that crashed, is replayed up to it.
+func NewReplayer(conf Config, builder consensus.Builder, recording io.Reader) (*Replayer, error) {
+	switch {
+	case conf.SnapshotInterval > 0:
+		return nil, errors.New("replay: state transfer cannot be replayed")
+	case conf.DataDir != "":
+		return nil, errors.New("replay: durable snapshots cannot be replayed")
+	case conf.ApplicationAddress != "":
+		return nil, errors.New("replay: an external application cannot be replayed")
+	case conf.GossipInterval > 0:
+		return nil, errors.New("replay: block gossip cannot be replayed")
+	}
+
+	inputs := newReplayInputs()
+	r := &Replayer{clock: &replayClock{}}
+	var replicas *replaypb.Replicas
+	rd := protostream.NewReader(recording)
+	for {
+		entry := &replaypb.Entry{}
+		err := rd.Read(entry)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("replay: failed to read the recording: %w", err)
+		}
+		if m := entry.GetReplicas(); m != nil {
+			replicas = m
+			continue
+		}
+		event, err := entryToEvent(entry)
+		if err != nil {
+			return nil, fmt.Errorf("replay: failed to decode event %d: %w", len(r.events), err)
+		}
+		if event != nil {
+			r.events = append(r.events, recordedEvent{time: entry.GetTime().AsTime(), event: event})
+			continue
+		}
+		if err := inputs.add(entry); err != nil {
+			return nil, fmt.Errorf("replay: failed to decode input: %w", err)
+		}
+	}
+
+	if replicas == nil {
+		return nil, errors.New("replay: the recording does not contain the replicas, since the replica did not connect")
+	}
+	if hotstuff.ID(replicas.GetID()) != conf.ID {
+		return nil, fmt.Errorf("replay: the recording is of replica %d, not %d", replicas.GetID(), conf.ID)
+	}
+	config := &replayConfig{replicas: make(map[hotstuff.ID]consensus.Replica), inputs: inputs}
+	for _, replica := range replicas.GetReplicas() {
+		key, err := keygen.ParsePublicKey(replica.GetPublicKey())
+		if err != nil {
+			return nil, fmt.Errorf("replay: failed to parse the public key of replica %d: %w", replica.GetID(), err)
+		}
+		config.replicas[hotstuff.ID(replica.GetID())] = &replayReplica{id: hotstuff.ID(replica.GetID()), pubKey: key}
+	}
+
+	r.clientSrv = newClientServer(conf, nil)
+	builder.Register(
+		config,
+		r.clientSrv,
+		r.clientSrv.cmdCache,
+		inputs, // replaces the command cache as the acceptor and command queue
+		r.clock,
+		logging.New(fmt.Sprintf("replay%d", conf.ID)),
+	)
+	if crypto := builder.Crypto(); crypto != nil {
+		builder.Register(replayCrypto{crypto, inputs})
+	}
+	// the votes are verified in the order in which they are processed, and the blocks are fetched from the recording
+	// when they are requested.
+	builder.OptionsBuilder().SetShouldVerifyVotesSync()
+	builder.OptionsBuilder().SetShouldFetchSync()
+	mods, err := builder.TryBuild()
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	r.mods = mods
+	r.mods.EventLoop().SetFilter(func(event interface{}) bool {
+		return eventToEntry(event) == nil
+	})
+
+	var ctx context.Context
+	ctx, r.cancel = context.WithCancel(context.Background())
+	if len(r.events) > 0 {
+		r.clock.now = r.events[0].time
+	}
+	r.mods.Synchronizer().Start(ctx)
+	r.drain()
+	return r, nil
+}
+
+// Step replays the next recorded event, and processes the events that it caused.
+// It returns false if all events have been replayed.
+func (r *Replayer) Step() bool {
+	if r.next >= len(r.events) {
+		r.cancel()
+		return false
+	}
+	e := r.events[r.next]
+	r.next++
+	r.clock.now = e.time
+	r.mods.EventLoop().Process(e.event)
+	r.drain()
+	return true
+}
+
+// Run replays the remaining recorded events.
+func (r *Replayer) Run() {
+	for r.Step() {
+	}
+}
+
+// drain processes the events that the modules have added to the event loop.
+func (r *Replayer) drain() {
+	for r.mods.EventLoop().Tick() {
+	}
+}
+
+// Remaining returns the number of recorded events that have not been replayed.
+func (r *Replayer) Remaining() int {
+	return len(r.events) - r.next
+}
+
+// Modules returns the consensus modules of the replayed replica, which can be used to inspect its state.
+func (r *Replayer) Modules() *consensus.Modules {
+	return r.mods
+}
+
+// Hash returns the hash of the commands that have been executed by the replayed replica.
+func (r *Replayer) Hash() []byte {
+	return r.clientSrv.hash.Sum(nil)
+}
+
+// replayInputs answers the command queue, the acceptor, the fetching of blocks, and the signing from the recording.
+// The commands are returned in the recorded order, and the other inputs in the recorded order for each command or hash,
+// since they may also be requested outside of the event loop.
+type replayInputs struct {
+	mods         *consensus.Modules
+	commands     []*replaypb.Command
+	accepts      map[string][]bool
+	fetches      map[consensus.Hash][]*consensus.Block
+	signatures   map[consensus.Hash][]consensus.Signature
+	partialCerts map[consensus.Hash][]consensus.PartialCert
+}
+
+func newReplayInputs() *replayInputs {
+	return &replayInputs{
+		accepts:      make(map[string][]bool),
+		fetches:      make(map[consensus.Hash][]*consensus.Block),
+		signatures:   make(map[consensus.Hash][]consensus.Signature),
+		partialCerts: make(map[consensus.Hash][]consensus.PartialCert),
+	}
+}
+
+// InitConsensusModule gives the module a reference to the Modules object.
+// It also allows the module to set module options using the OptionsBuilder.
+func (in *replayInputs) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
+	in.mods = mods
+}
+
+func (in *replayInputs) add(entry *replaypb.Entry) error {
+	switch input := entry.GetInput().(type) {
+	case *replaypb.Entry_Command:
+		in.commands = append(in.commands, input.Command)
+	case *replaypb.Entry_Accept:
+		cmd := string(input.Accept.GetCommand())
+		in.accepts[cmd] = append(in.accepts[cmd], input.Accept.GetAccepted())
+	case *replaypb.Entry_Fetch:
+		var block *consensus.Block
+		if input.Fetch.GetBlock() != nil {
+			var err error
+			if block, err = hotstuffpb.BlockFromProto(input.Fetch.GetBlock()); err != nil {
+				return err
+			}
+		}
+		hash := toHash(input.Fetch.GetHash())
+		in.fetches[hash] = append(in.fetches[hash], block)
+	case *replaypb.Entry_Signature:
+		hash := toHash(input.Signature.GetHash())
+		in.signatures[hash] = append(in.signatures[hash], hotstuffpb.SignatureFromProto(input.Signature.GetSignature()))
+	case *replaypb.Entry_PartialCert:
+		cert, err := hotstuffpb.PartialCertFromProto(input.PartialCert.GetCert())
+		if err != nil {
+			return err
+		}
+		in.partialCerts[cert.BlockHash()] = append(in.partialCerts[cert.BlockHash()], cert)
+	}
+	return nil
+}
+
+func toHash(b []byte) (hash consensus.Hash) {
+	copy(hash[:], b)
+	return hash
+}
+
+// Get returns the next recorded command.
+func (in *replayInputs) Get(_ context.Context) (consensus.Command, bool) {
+	if len(in.commands) == 0 {
+		in.mods.Logger().Warn("Replay: the recording has no more commands")
+		return "", false
+	}
+	cmd := in.commands[0]
+	in.commands = in.commands[1:]
+	return consensus.Command(cmd.GetCommand()), cmd.GetOK()
+}
+
+// Accept returns the next recorded decision for the command.
+func (in *replayInputs) Accept(cmd consensus.Command) bool {
+	decisions := in.accepts[string(cmd)]
+	if len(decisions) == 0 {
+		in.mods.Logger().Warn("Replay: the recording has no decision for a command")
+		return false
+	}
+	in.accepts[string(cmd)] = decisions[1:]
+	return decisions[0]
+}
+
+// Proposed does nothing, since the decisions of the acceptor are recorded.
+func (in *replayInputs) Proposed(_ consensus.Command) {}
+
+func (in *replayInputs) fetch(hash consensus.Hash) (*consensus.Block, bool) {
+	results := in.fetches[hash]
+	if len(results) == 0 {
+		in.mods.Logger().Warnf("Replay: the recording has no fetch of block %.8s", hash)
+		return nil, false
+	}
+	in.fetches[hash] = results[1:]
+	return results[0], results[0] != nil
+}
+
+// replayConfig is a configuration of replicas that discards the messages sent to them.
+type replayConfig struct {
+	replicas map[hotstuff.ID]consensus.Replica
+	inputs   *replayInputs
+}
+
+// Replicas returns all of the replicas in the configuration.
+func (cfg *replayConfig) Replicas() map[hotstuff.ID]consensus.Replica {
+	return cfg.replicas
+}
+
+// Replica returns a replica if present in the configuration.
+func (cfg *replayConfig) Replica(id hotstuff.ID) (replica consensus.Replica, ok bool) {
+	replica, ok = cfg.replicas[id]
+	return replica, ok
+}
+
+// Len returns the number of replicas in the configuration.
+func (cfg *replayConfig) Len() int {
+	return len(cfg.replicas)
+}
+
+// QuorumSize returns the size of a quorum.
+func (cfg *replayConfig) QuorumSize() int {
+	return hotstuff.QuorumSize(cfg.Len())
+}
+
+// Propose discards the proposal.
+func (cfg *replayConfig) Propose(_ consensus.ProposeMsg) {}
+
+// Timeout discards the timeout message.
+func (cfg *replayConfig) Timeout(_ consensus.TimeoutMsg) {}
+
+// Fetch returns the recorded result of fetching the block.
+func (cfg *replayConfig) Fetch(_ context.Context, hash consensus.Hash) (*consensus.Block, bool) {
+	return cfg.inputs.fetch(hash)
+}
+
+// replayReplica is a replica that discards the messages sent to it.
+type replayReplica struct {
+	id     hotstuff.ID
+	pubKey consensus.PublicKey
+}
+
+// ID returns the replica's id.
+func (r *replayReplica) ID() hotstuff.ID {
+	return r.id
+}
+
+// PublicKey returns the replica's public key.
+func (r *replayReplica) PublicKey() consensus.PublicKey {
+	return r.pubKey
+}
+
+// Vote discards the partial certificate.
+func (r *replayReplica) Vote(_ consensus.PartialCert) {}
+
+// NewView discards the sync info.
+func (r *replayReplica) NewView(_ consensus.SyncInfo) {}
+
+// replayCrypto returns the recorded signatures, since the signatures are randomized.
+// New signatures are only created for hashes that were not signed in the recording.
+type replayCrypto struct {
+	consensus.Crypto
+	inputs *replayInputs
+}
+
+// Sign returns the next recorded signature of the hash.
+func (c replayCrypto) Sign(hash consensus.Hash) (consensus.Signature, error) {
+	sigs := c.inputs.signatures[hash]
+	if len(sigs) == 0 {
+		return c.Crypto.Sign(hash)
+	}
+	c.inputs.signatures[hash] = sigs[1:]
+	return sigs[0], nil
+}
+
+// CreatePartialCert returns the next recorded partial certificate of the block.
+func (c replayCrypto) CreatePartialCert(block *consensus.Block) (consensus.PartialCert, error) {
+	certs := c.inputs.partialCerts[block.Hash()]
+	if len(certs) == 0 {
+		return c.Crypto.CreatePartialCert(block)
+	}
+	c.inputs.partialCerts[block.Hash()] = certs[1:]
+	return certs[0], nil
+}
+
+// replayClock is a clock that tells the time at which the event being replayed was recorded.
+// Its timers never fire, since the expiries of the view timer are recorded events.
+type replayClock struct {
+	now time.Time
+}
+
+func (c *replayClock) Now() time.Time {
+	return c.now
+}
+
+func (c *replayClock) AfterFunc(_ time.Duration, _ func()) consensus.Timer {
+	return stoppedTimer{}
+}
+
+// stoppedTimer is a timer that never fires.
+type stoppedTimer struct{}
+
+func (stoppedTimer) Stop() bool { return false }
+
+func (stoppedTimer) Reset(_ time.Duration) bool { return false }
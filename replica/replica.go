@@ -5,15 +5,25 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"fmt"
+	"io"
 	"net"
+	"os"
+	"path/filepath"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/relab/gorums"
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/backend"
 	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
 	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/metrics/types"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/safety"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
@@ -38,122 +48,828 @@ type Config struct {
 	RootCAs *x509.CertPool
 	// The number of client commands that should be batched together in a block.
 	BatchSize uint32
-	// Options for the client server.
+	// The maximum size in bytes of the commands that are batched together in a block. Unbounded if zero.
+	BatchBytes int
+	// How long a command waits for its batch to fill up before a smaller batch is proposed.
+	// If zero, the commands wait until the batch is full.
+	BatchDelay time.Duration
+	// The order in which the commands of different clients are included in the batches. Under the FIFO policy, the
+	// commands are included in the order they were received, such that a client that sends many commands can crowd out
+	// the others. Under the fair policy, the clients that have commands waiting take turns in proportion to their
+	// weights. The FIFO policy is used if this is empty.
+	BatchPolicy BatchPolicy
+	// The weights of the clients under the fair batch policy, by client ID. A client without a weight has weight 1.
+	ClientWeights map[uint32]uint32
+	// The maximum size in bytes of a serialized proposal, including its certificates. The batches are limited such that
+	// the proposals stay below it with a margin, and commands that do not fit in a block are rejected.
+	// If zero, it is the maximum message size of the replica server, or unbounded if the proposals are sent in chunks.
+	MaxBlockBytes int
+	// The number of committed blocks that may wait to be executed. If positive, the blocks are executed by a pipeline
+	// on its own goroutine, and the consensus protocol only waits for the execution when the pipeline is full.
+	// If zero, the blocks are executed by the event loop as they are committed. An external application
+	// is always executed asynchronously, so it is not affected by this.
+	ExecutionLag int
+	// If true, and the application implements SpeculativeExecutor, the commands of a block are executed as soon as
+	// the block is certified, and rolled back if it is forked before it is committed. The clients are still
+	// acknowledged when the block is committed. ExecutionLag is ignored, since the speculation runs on the event loop.
+	Speculate bool
+	// How long the commands of a block that has been certified, but neither committed nor forked, are not proposed or
+	// accepted again, after which a client may retry them. A default of 30 seconds is used if zero.
+	ProposedTTL time.Duration
+	// Options for the client server. They are applied after the options given by the other fields, and may override them.
 	ClientServerOptions []gorums.ServerOption
-	// Options for the replica server.
+	// Options for the replica server. They are applied after the defaults (see backend.DefaultServerOptions)
+	// and the options given by the other fields, and may override them.
 	ReplicaServerOptions []gorums.ServerOption
-	// Options for the replica manager.
+	// The address that the client server listens on when started by Listen.
+	// Addresses of the form unix:///path/to.sock refer to unix domain sockets.
+	ClientAddress string
+	// The address that the replica server listens on when started by Listen.
+	// Addresses of the form unix:///path/to.sock refer to unix domain sockets.
+	ReplicaAddress string
+	// The TLS configuration of the client server.
+	// If this is nil and TLS is enabled, the client server uses Certificate.
+	ClientTLSConfig *tls.Config
+	// The TLS configuration of the replica server.
+	// If this is nil and TLS is enabled, the replica server uses Certificate and verifies peers against RootCAs.
+	ReplicaTLSConfig *tls.Config
+	// Limits that apply to connections to the client server.
+	ClientServerLimits ServerLimits
+	// Limits that apply to connections to the replica server.
+	ReplicaServerLimits ServerLimits
+	// Options for the replica manager. They are applied after the defaults (see backend.DefaultManagerOptions),
+	// and may override them.
 	ManagerOptions []gorums.ManagerOption
+	// gRPC dial options used when connecting to the other replicas, for example interceptors or keepalive parameters.
+	// They are applied after the defaults (see backend.DefaultDialOptions) and ManagerOptions, and may override them.
+	DialOptions []grpc.DialOption
+	// The transport used to connect to the other replicas, and by Listen to listen for replica connections.
+	// TCP is used if this is nil.
+	Transport backend.Transport
+	// The emulated one-way latency of messages sent to each replica.
+	// Latency emulation is disabled if this is empty.
+	Latencies map[hotstuff.ID]time.Duration
+	// The maximum jitter added to or subtracted from the emulated latencies.
+	Jitter time.Duration
+	// The seed of the random number generators of the latency emulation and gossip.
+	// A seed based on the current time is used if this is zero.
+	Seed int64
+	// Per-peer rate limits for inbound replica messages. Rate limiting is disabled if this is nil.
+	RateLimits backend.RateLimits
+	// The fanout of the tree used to disseminate proposals. Proposals are broadcast if this is zero.
+	TreeFanout int
+	// How long to wait for a proposal to arrive through the tree before requesting it from the leader.
+	TreeSubTimeout time.Duration
+	// How often to offer recently certified blocks to other replicas. Gossip is disabled if this is zero.
+	GossipInterval time.Duration
+	// The number of replicas to offer recent blocks to in each gossip round.
+	GossipFanout int
+	// How often to ping the other replicas. Health checking is disabled if this is zero.
+	HealthCheckInterval time.Duration
+	// The number of consecutive missed pings before a replica is considered unreachable.
+	HealthCheckThreshold int
+	// Proposals larger than this number of bytes are sent in chunks. Chunking is disabled if this is zero.
+	ProposalChunkSize int
+	// How long to wait for all chunks of a proposal. The backend default is used if this is zero.
+	ChunkReassemblyTimeout time.Duration
+	// The number of bytes of incomplete proposals to buffer for each replica. The backend default is used if this is zero.
+	ChunkReassemblyBudget int
+	// The size of the send queue of each replica. Send queues are disabled if this is zero.
+	SendQueueSize int
+	// The number of recently received messages to remember in order to drop duplicates.
+	// Duplicate suppression is disabled if this is zero.
+	DedupSize int
+	// How long received messages are remembered by the duplicate filter. The backend default is used if this is zero.
+	DedupTTL time.Duration
+	// If true, commands received from clients are forwarded to the leaders of the current and the next view,
+	// and forwarded again when the view changes until they are proposed,
+	// such that they can be proposed without waiting for this replica to become the leader.
+	// The commands are also kept by this replica, in case the forwarded copies are lost.
+	ForwardCommands bool
+	// The maximum number of commands that wait to be proposed in the command cache.
+	// The number of commands is unbounded if this is zero.
+	CommandCacheSize int
+	// The maximum number of bytes of the commands that wait to be proposed, including their payloads.
+	// The number of bytes is unbounded if this is zero.
+	CommandCacheBytes int
+	// If true, the oldest commands that have not been proposed are evicted from a full command cache to make room
+	// for new commands. Otherwise, new commands are rejected. Either way, the clients are told to retry the commands later.
+	EvictCommands bool
+	// The number of events that each queue of the event loop can hold. When it is full, events are dropped or wait for
+	// room according to the overflow policies of their types (see eventloop.OverflowPolicy).
+	// The capacity of the builder's event loop is kept if this is zero.
+	EventQueueCapacity uint
+	// If true, a panic in an event handler crashes the replica, instead of being recovered and logged.
+	FatalPanics bool
+	// The application that executes the committed commands. If it implements QueryExecutor, the replica answers
+	// read-only queries from clients, and if it implements Validator, and Validator is not set, invalid commands
+	// are rejected. If this is nil, the commands are only hashed.
+	Application Application
+	// Validates the data of the commands before they are ordered. Invalid commands are rejected when clients send them,
+	// they are left out of the batches, and the replica does not vote for blocks that contain them.
+	// The application is used if this is nil and the application implements Validator.
+	Validator Validator
+	// The address of an application that runs as a separate process and implements the Application service
+	// of internal/proto/apppb. If this is set, the application is asked to accept the commands of proposals and to
+	// suggest batch sizes, and it executes the committed blocks before the clients are acknowledged.
+	// While it is unreachable, the replica keeps ordering commands, but execution is stalled.
+	// Addresses of the form unix:///path/to.sock refer to unix domain sockets.
+	// State transfer must be disabled, since the state of an external application is not included in snapshots.
+	ApplicationAddress string
+	// How long to wait for the external application to accept a proposal or to suggest a batch size,
+	// before the proposal is accepted or the batch size is left unchanged. One second is used if this is zero.
+	ApplicationTimeout time.Duration
+	// The number of views between the snapshots of the replica's state, which are transferred to replicas that
+	// have fallen far behind. The application must be nil or implement Snapshotter.
+	// State transfer is disabled if this is zero.
+	SnapshotInterval uint64
+	// How many views the replica may fall behind before it installs a snapshot from the other replicas,
+	// rather than fetching the blocks that it is missing. The blocks that are older than this when a snapshot is taken
+	// are discarded. Twice the snapshot interval is used if this is zero.
+	StateTransferLag uint64
+	// The directory in which durable snapshots of the replica's state are saved, such that a replica that is restarted
+	// recovers from its latest snapshot and only fetches the blocks that were committed after it.
+	// The blocks below the snapshot before the latest are discarded when a snapshot is saved.
+	// Durable snapshots are disabled if this is empty.
+	DataDir string
+	// Saves the snapshots of the application to the data directory. It must be set if Application is set,
+	// and is typically implemented by the application itself.
+	Snapshotter consensus.Snapshotter
+	// The number of views between durable snapshots. Snapshots are not triggered by views if this is zero.
+	DurableSnapshotViews uint64
+	// The number of bytes of executed commands between durable snapshots.
+	// Snapshots are not triggered by size if this is zero.
+	DurableSnapshotBytes uint64
+	// If true, the commands that the replica admits from clients are appended to a log in the data directory,
+	// from which they are removed when they are executed. A replica that is restarted reloads the commands that have
+	// not been executed according to its recovered state before it starts, and proposes them without waiting for
+	// the clients to retry them. DataDir must be set.
+	PersistCommands bool
+	// When the log of the commands admitted from clients is synced to stable storage: after each command, periodically,
+	// or never, leaving it to the operating system. It is synced periodically if this is empty.
+	CommandLogSync CommandLogSync
+	// The address that the status server listens on when started by ListenStatus.
+	// The status server reports the state of the replica as JSON at /status, and answers /healthz with
+	// status 200 only while the replica participates in consensus. It is disabled if this is empty.
+	StatusAddress string
+	// The names of the configured modules, such as the consensus and crypto implementations,
+	// which are reported by the status server.
+	Modules map[string]string
+	// The status server reports the replica as unhealthy if the view has not changed for this many view timeouts.
+	// Three view timeouts are used if this is zero.
+	StallTimeouts int
+	// The address that the commit stream server listens on when started by ListenCommitStream.
+	// The commit stream server streams the committed blocks to subscribers over gRPC, and uses the TLS configuration
+	// of the client server. It is disabled if this is empty.
+	CommitStreamAddress string
+	// The number of committed blocks that are buffered for each subscriber of the commit stream. A subscriber whose
+	// buffer is full is disconnected, such that it never delays the replica. 1024 blocks are buffered if this is zero.
+	CommitStreamBuffer int
+	// If this is set, the execution of the replica is recorded to it, such that it can be replayed by a Replayer.
+	// The recording holds the events processed by the event loop and the other nondeterministic inputs of the
+	// consensus modules. State transfer, durable snapshots, and external applications are not supported by the replay.
+	Recording io.Writer
+}
+
+// ServerLimits limits the resources used by the connections to a server.
+type ServerLimits struct {
+	// The maximum number of concurrent streams on each connection. There is no limit if this is zero.
+	MaxConcurrentStreams uint32
+	// The maximum size of a received message in bytes. The gRPC default of 4 MB is used if this is zero.
+	MaxRecvMsgSize int
+}
+
+func (l ServerLimits) serverOptions() (opts []gorums.ServerOption) {
+	var grpcOpts []grpc.ServerOption
+	if l.MaxConcurrentStreams > 0 {
+		grpcOpts = append(grpcOpts, grpc.MaxConcurrentStreams(l.MaxConcurrentStreams))
+	}
+	if l.MaxRecvMsgSize > 0 {
+		grpcOpts = append(grpcOpts, grpc.MaxRecvMsgSize(l.MaxRecvMsgSize))
+	}
+	if len(grpcOpts) > 0 {
+		opts = append(opts, gorums.WithGRPCServerOptions(grpcOpts...))
+	}
+	return opts
 }
 
 // Replica is a participant in the consensus protocol.
 type Replica struct {
+	clientAddr  string
+	replicaAddr string
+	transport   backend.Transport
+
 	clientSrv *clientSrv
 	cfg       *backend.Config
 	hsSrv     *backend.Server
 	hs        *consensus.Modules
 
+	statusAddr string
+	status     *statusServer // nil if the status server is disabled
+	commitAddr string
+	commits    *commitStream // nil if the commit stream server is disabled
+
+	app     *externalApp      // nil if the replica is not connected to an external application
+	durable *durableSnapshots // nil if durable snapshots are disabled
+	rec     *recorder         // nil if the execution is not recorded
+	shard   bool              // true if the replica was created by NewShard
+
+	recoverErr error // the error that prevented the replica from recovering its state, if any
+
 	execHandlers map[cmdID]func(*empty.Empty, error)
+	incompatMut  sync.Mutex
+	incompatible map[hotstuff.ID]error // the replicas that the handshake showed to be incompatible
+	progress     progress
+	pauseMut     sync.Mutex
+	resumes      []func() // resume the pauses that have not ended
 	cancel       context.CancelFunc
 	done         chan struct{}
+	started      bool
+	stopOnce     sync.Once // the replica is either stopped or shut down
+	shutdownErr  error
 }
 
 // New returns a new replica.
 func New(conf Config, builder consensus.Builder) (replica *Replica) {
-	clientSrvOpts := conf.ClientServerOptions
+	replicaSrvOpts := conf.ReplicaServerLimits.serverOptions()
+	var serverTLS, clientTLS *tls.Config
+	if conf.TLS {
+		serverTLS = conf.ReplicaTLSConfig
+		if serverTLS == nil {
+			serverTLS = &tls.Config{
+				Certificates: []tls.Certificate{*conf.Certificate},
+				ClientCAs:    conf.RootCAs,
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+			}
+		}
+		clientTLS = &tls.Config{
+			RootCAs:      conf.RootCAs,
+			Certificates: []tls.Certificate{*conf.Certificate},
+		}
+		replicaSrvOpts = append(replicaSrvOpts, gorums.WithGRPCServerOptions(grpc.Creds(credentials.NewTLS(serverTLS))))
+	}
+	replicaSrvOpts = append(replicaSrvOpts, conf.ReplicaServerOptions...)
+
+	hsSrv := backend.NewServer(replicaSrvOpts...)
+	if conf.RateLimits != nil {
+		hsSrv.SetRateLimits(conf.RateLimits)
+	}
 
+	var creds credentials.TransportCredentials
+	managerOpts := append([]gorums.ManagerOption{}, conf.ManagerOptions...)
+	if len(conf.DialOptions) > 0 {
+		managerOpts = append(managerOpts, gorums.WithGrpcDialOptions(conf.DialOptions...))
+	}
 	if conf.TLS {
-		clientSrvOpts = append(clientSrvOpts, gorums.WithGRPCServerOptions(
-			grpc.Creds(credentials.NewServerTLSFromCert(conf.Certificate)),
-		))
+		creds = credentials.NewTLS(clientTLS)
+		// transports that secure their own connections, such as QUIC, use the certificates of the replica as well.
+		if transport, ok := conf.Transport.(backend.TLSTransport); ok {
+			conf.Transport = transport.WithTLS(serverTLS, clientTLS)
+		}
 	}
+	cfg := backend.NewConfig(creds, managerOpts...)
+	if conf.Transport != nil {
+		cfg.SetTransport(conf.Transport)
+	}
+
+	return newReplica(conf, builder, hsSrv, cfg, "hs"+strconv.Itoa(int(conf.ID)))
+}
+
+// NewShard returns a replica that runs another consensus instance, called a shard, in the same process as srv.
+// The shard has its own modules, which are built by the given builder, and its own client server and command space,
+// while the messages to the other replicas are multiplexed over the connections and the replica server of srv.
+// The instance must be greater than zero, which is the instance of srv, and be used by the corresponding shard
+// of each of the other replicas.
+//
+// The ID of the replica, the transport, and the options of the replica server and the connections are taken from
+// srv, and the corresponding fields of conf are ignored. The other fields apply to the shard; in particular, each shard
+// needs its own client address, and its own data directory if durable snapshots are enabled.
+// Listen only starts the client server of a shard, and Connect must be called after it is called on srv.
+// The shards must be stopped before srv, since stopping srv closes the shared connections.
+func (srv *Replica) NewShard(instance uint32, conf Config, builder consensus.Builder) (*Replica, error) {
+	if instance == 0 {
+		return nil, fmt.Errorf("instance 0 is used by the replica itself")
+	}
+	conf.ID = srv.hs.ID()
+	conf.Transport = srv.transport
+	name := fmt.Sprintf("hs%d.%d", conf.ID, instance)
+	shard := newReplica(conf, builder, srv.hsSrv.NewInstance(instance), srv.cfg.NewInstance(instance), name)
+	if shard.recoverErr != nil {
+		return nil, fmt.Errorf("failed to recover: %w", shard.recoverErr)
+	}
+	shard.shard = true
+	return shard, nil
+}
+
+// clientCredentials returns the transport credentials of the servers that clients connect to,
+// or nil if TLS is disabled.
+func clientCredentials(conf Config) credentials.TransportCredentials {
+	if !conf.TLS {
+		return nil
+	}
+	if conf.ClientTLSConfig != nil {
+		return credentials.NewTLS(conf.ClientTLSConfig)
+	}
+	return credentials.NewServerTLSFromCert(conf.Certificate)
+}
+
+// newReplica returns a replica that uses the given replica server and configuration,
+// and the given name for its logger.
+func newReplica(conf Config, builder consensus.Builder, hsSrv *backend.Server, cfg *backend.Config, name string) *Replica {
+	clientSrvOpts := conf.ClientServerLimits.serverOptions()
+	clientCreds := clientCredentials(conf)
+	if clientCreds != nil {
+		clientSrvOpts = append(clientSrvOpts, gorums.WithGRPCServerOptions(grpc.Creds(clientCreds)))
+	}
+	clientSrvOpts = append(clientSrvOpts, conf.ClientServerOptions...)
 
 	clientSrv := newClientServer(conf, clientSrvOpts)
 
 	srv := &Replica{
+		hsSrv:        hsSrv,
+		cfg:          cfg,
+		clientAddr:   conf.ClientAddress,
+		replicaAddr:  conf.ReplicaAddress,
+		transport:    conf.Transport,
+		statusAddr:   conf.StatusAddress,
+		commitAddr:   conf.CommitStreamAddress,
 		clientSrv:    clientSrv,
 		execHandlers: make(map[cmdID]func(*empty.Empty, error)),
 		cancel:       func() {},
 		done:         make(chan struct{}),
 	}
 
-	replicaSrvOpts := conf.ReplicaServerOptions
-	if conf.TLS {
-		replicaSrvOpts = append(replicaSrvOpts, gorums.WithGRPCServerOptions(
-			grpc.Creds(credentials.NewTLS(&tls.Config{
-				Certificates: []tls.Certificate{*conf.Certificate},
-				ClientCAs:    conf.RootCAs,
-				ClientAuth:   tls.RequireAndVerifyClientCert,
-			})),
-		))
+	if conf.ChunkReassemblyTimeout > 0 || conf.ChunkReassemblyBudget > 0 {
+		timeout, budget := conf.ChunkReassemblyTimeout, conf.ChunkReassemblyBudget
+		if timeout <= 0 {
+			timeout = backend.DefaultReassemblyTimeout
+		}
+		if budget <= 0 {
+			budget = backend.DefaultReassemblyBudget
+		}
+		srv.hsSrv.SetChunkReassembly(timeout, budget)
+	}
+	if conf.DedupSize > 0 {
+		ttl := conf.DedupTTL
+		if ttl <= 0 {
+			ttl = backend.DefaultDedupTTL
+		}
+		srv.hsSrv.SetDuplicateSuppression(conf.DedupSize, ttl)
 	}
 
-	srv.hsSrv = backend.NewServer(replicaSrvOpts...)
+	if conf.Seed != 0 {
+		srv.cfg.SetRandomSeed(conf.Seed)
+	}
+	if len(conf.Latencies) > 0 {
+		srv.cfg.SetLatencies(conf.Latencies, conf.Jitter)
+	}
+	if conf.GossipInterval > 0 {
+		// offer the blocks of the last few views
+		srv.cfg.SetGossip(conf.GossipInterval, conf.GossipFanout, 8)
+	}
+	if conf.HealthCheckInterval > 0 {
+		srv.cfg.SetHealthCheck(conf.HealthCheckInterval, conf.HealthCheckThreshold)
+	}
+	if conf.ProposalChunkSize > 0 {
+		srv.cfg.SetChunkSize(conf.ProposalChunkSize)
+	}
+	if conf.SendQueueSize > 0 {
+		srv.cfg.SetSendQueues(conf.SendQueueSize)
+	}
+	if conf.TreeFanout > 0 {
+		srv.cfg.SetTreeDissemination(conf.TreeFanout, conf.TreeSubTimeout)
+	}
 
-	var creds credentials.TransportCredentials
-	managerOpts := conf.ManagerOptions
-	if conf.TLS {
-		creds = credentials.NewTLS(&tls.Config{
-			RootCAs:      conf.RootCAs,
-			Certificates: []tls.Certificate{*conf.Certificate},
-		})
+	if conf.SnapshotInterval > 0 {
+		transfer := newStateTransfer(conf, srv.clientSrv, srv.cfg)
+		srv.clientSrv.transfer = transfer
+		srv.hsSrv.SetSnapshotProvider(transfer)
+		builder.Register(transfer)
+	}
+
+	if conf.DataDir != "" {
+		srv.durable = newDurableSnapshots(conf, srv.clientSrv)
+		srv.clientSrv.durable = srv.durable
+		builder.Register(srv.durable)
+		// the last vote is persisted, such that a replica that is restarted does not vote twice in a view.
+		builder.Register(voteStorage{safety.NewFile(filepath.Join(conf.DataDir, safetyFile))})
+	}
+
+	if conf.StatusAddress != "" {
+		srv.status = newStatusServer(conf, srv.cfg, srv.clientSrv)
+		builder.Register(srv.status)
+	}
+
+	if conf.CommitStreamAddress != "" {
+		srv.commits = newCommitStream(conf, clientCreds)
+		builder.Register(srv.commits)
+	}
+
+	var config consensus.Configuration = srv.cfg
+	if conf.Recording != nil {
+		srv.rec = newRecorder(conf.Recording)
+		config = recordingConfig{srv.cfg, srv.rec}
 	}
-	srv.cfg = backend.NewConfig(creds, managerOpts...)
 
 	builder.Register(
-		srv.cfg,                // configuration
+		config,                 // configuration
 		srv.hsSrv,              // event handling
 		srv.clientSrv,          // executor
-		srv.clientSrv.cmdCache, // acceptor and command queue
-		logging.New("hs"+strconv.Itoa(int(conf.ID))),
+		srv.clientSrv.cmdCache, // acceptor, command queue, and fork handler
+		logging.New(name),
 	)
+	if conf.ApplicationAddress != "" {
+		srv.app = newExternalApp(conf, srv.clientSrv)
+		// the external application replaces the client server and the command cache as the executor,
+		// acceptor, and command queue, since it is registered after them.
+		builder.Register(srv.app)
+	} else if app, ok := conf.Application.(SpeculativeExecutor); ok && conf.Speculate {
+		// the speculation replaces the client server as the executor and the command cache as the fork handler,
+		// since it is registered after them.
+		srv.clientSrv.speculation = newSpeculation(srv.clientSrv, app)
+		builder.Register(srv.clientSrv.speculation)
+	} else if conf.ExecutionLag > 0 {
+		// the pipeline replaces the client server as the executor, since it is registered after it.
+		srv.clientSrv.pipeline = newExecPipeline(srv.clientSrv, conf.ExecutionLag)
+		builder.Register(srv.clientSrv.pipeline)
+	}
+	if srv.rec != nil {
+		// the wrappers replace the modules that they record, since they are registered after them.
+		queue := recordingQueue{queue: srv.clientSrv.cmdCache, acceptor: srv.clientSrv.cmdCache, rec: srv.rec}
+		if srv.app != nil {
+			queue.queue, queue.acceptor = srv.app, srv.app
+		}
+		builder.Register(srv.rec, queue)
+		if crypto := builder.Crypto(); crypto != nil {
+			builder.Register(recordingCrypto{crypto, srv.rec})
+		}
+	}
 	srv.hs = builder.Build()
+	srv.hs.EventLoop().SetCapacity(conf.EventQueueCapacity)
+	srv.hs.EventLoop().SetFatalPanics(conf.FatalPanics)
+	srv.initForwarding()
+	srv.initProgress()
+	srv.hs.EventLoop().RegisterObserver(backend.PeerIncompatible{}, func(event interface{}) {
+		e := event.(backend.PeerIncompatible)
+		srv.incompatMut.Lock()
+		defer srv.incompatMut.Unlock()
+		if srv.incompatible == nil {
+			srv.incompatible = make(map[hotstuff.ID]error)
+		}
+		srv.incompatible[e.ID] = e.Err
+	})
+
+	if err := srv.recover(conf); err != nil {
+		srv.hs.Logger().Errorf("Failed to recover: %v", err)
+		srv.recoverErr = err
+	}
 
 	return srv
 }
 
+// reloadCommands adds the commands in the command log that have not been executed according to the recovered state
+// to the command cache, and then persists the commands that the replica admits from clients.
+// The commands are not persisted if the command log cannot be used.
+func (srv *Replica) reloadCommands(conf Config) {
+	if conf.DataDir == "" {
+		srv.hs.Logger().Error("Commands are not persisted, since there is no data directory")
+		return
+	}
+	policy, err := ParseCommandLogSync(string(conf.CommandLogSync))
+	if err != nil {
+		srv.hs.Logger().Errorf("Commands are not persisted: %v", err)
+		return
+	}
+	if err := os.MkdirAll(conf.DataDir, 0o755); err != nil {
+		srv.hs.Logger().Errorf("Commands are not persisted, since the data directory could not be created: %v", err)
+		return
+	}
+	path := filepath.Join(conf.DataDir, commandLogFile)
+	cmds, err := readCmdLog(path)
+	if err != nil {
+		srv.hs.Logger().Errorf("Commands are not persisted, since the command log could not be read: %v", err)
+		return
+	}
+	// the cache skips the commands that have been executed, such that only the others are kept in the log.
+	cache := srv.clientSrv.cmdCache
+	added := make(map[cmdID]bool, len(cmds))
+	for _, cmd := range cmds {
+		ok, evicted, _ := cache.addCommand(cmd, true)
+		if ok {
+			added[cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}] = true
+		}
+		for _, id := range evicted {
+			delete(added, id)
+		}
+	}
+	reloaded := make([]*clientpb.Command, 0, len(added))
+	for _, cmd := range cmds {
+		if added[cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}] {
+			reloaded = append(reloaded, cmd)
+		}
+	}
+	log, err := createCmdLog(path, policy, reloaded)
+	if err != nil {
+		srv.hs.Logger().Errorf("Commands are not persisted, since the command log could not be created: %v", err)
+		return
+	}
+	cache.log = log
+	if len(cmds) > 0 {
+		srv.hs.Logger().Infof("Reloaded %d of the %d commands in the command log", len(reloaded), len(cmds))
+	}
+}
+
+// closeCommandLog closes the command log, if the commands are persisted.
+func (srv *Replica) closeCommandLog() {
+	if log := srv.clientSrv.cmdCache.log; log != nil {
+		if err := log.close(); err != nil {
+			srv.hs.Logger().Warn(err)
+		}
+	}
+}
+
+func (srv *Replica) disableDurableSnapshots() {
+	srv.durable = nil
+	srv.clientSrv.durable = nil
+}
+
 // StartServers starts the client and replica servers.
 func (srv *Replica) StartServers(replicaListen, clientListen net.Listener) {
 	srv.hsSrv.StartOnListener(replicaListen)
 	srv.clientSrv.StartOnListener(clientListen)
 }
 
-// Connect connects to the other replicas.
+// Transport returns the transport that the replica uses to connect to the other replicas,
+// which must also be used to listen for their connections, since it may carry the TLS configuration of the replica.
+func (srv *Replica) Transport() backend.Transport {
+	if srv.transport == nil {
+		transport, _ := backend.GetTransport("")
+		return transport
+	}
+	return srv.transport
+}
+
+// Listen starts the replica and client servers on the addresses given by ReplicaAddress and ClientAddress.
+// It returns the addresses that the servers are listening on, which is useful if the configured addresses use port 0.
+// A shard only starts its client server, since it shares the replica server, and the returned replica address is nil.
+// Listen fails if the replica did not recover its state from its data directory (see RecoveryError).
+func (srv *Replica) Listen() (replicaAddr, clientAddr net.Addr, err error) {
+	if srv.recoverErr != nil {
+		return nil, nil, fmt.Errorf("failed to recover: %w", srv.recoverErr)
+	}
+	// the default transport also accepts addresses of unix domain sockets.
+	defaultTransport, _ := backend.GetTransport("")
+	if srv.shard {
+		clientListen, err := defaultTransport.Listen(srv.clientAddr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to listen on client address: %w", err)
+		}
+		srv.clientSrv.StartOnListener(clientListen)
+		return nil, clientListen.Addr(), nil
+	}
+	replicaListen, err := srv.Transport().Listen(srv.replicaAddr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to listen on replica address: %w", err)
+	}
+	clientListen, err := defaultTransport.Listen(srv.clientAddr)
+	if err != nil {
+		replicaListen.Close()
+		return nil, nil, fmt.Errorf("failed to listen on client address: %w", err)
+	}
+	srv.StartServers(replicaListen, clientListen)
+	return replicaListen.Addr(), clientListen.Addr(), nil
+}
+
+// ListenStatus starts the status server on the address given by StatusAddress,
+// and returns the address that it listens on.
+func (srv *Replica) ListenStatus() (net.Addr, error) {
+	if srv.status == nil {
+		return nil, fmt.Errorf("the status server is disabled")
+	}
+	lis, err := net.Listen("tcp", srv.statusAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on status address: %w", err)
+	}
+	srv.status.serve(lis)
+	return lis.Addr(), nil
+}
+
+// ListenCommitStream starts the commit stream server on the address given by CommitStreamAddress,
+// and returns the address that it listens on.
+func (srv *Replica) ListenCommitStream() (net.Addr, error) {
+	if srv.commits == nil {
+		return nil, fmt.Errorf("the commit stream server is disabled")
+	}
+	lis, err := net.Listen("tcp", srv.commitAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on commit stream address: %w", err)
+	}
+	srv.commits.serve(lis)
+	return lis.Addr(), nil
+}
+
+// Connect connects to the other replicas. It fails if the replica did not recover its state from its data directory.
 func (srv *Replica) Connect(replicas []backend.ReplicaInfo) error {
-	return srv.cfg.Connect(replicas)
+	if srv.recoverErr != nil {
+		return fmt.Errorf("failed to recover: %w", srv.recoverErr)
+	}
+	if err := srv.cfg.Connect(replicas); err != nil {
+		return err
+	}
+	if srv.rec != nil {
+		if err := srv.rec.recordReplicas(replicas); err != nil {
+			return fmt.Errorf("failed to record the replicas: %w", err)
+		}
+	}
+	if srv.status != nil {
+		srv.status.setPeers(replicas)
+	}
+	return nil
 }
 
 // Start runs the replica in a goroutine.
 func (srv *Replica) Start() {
 	var ctx context.Context
 	ctx, srv.cancel = context.WithCancel(context.Background())
+	srv.started = true
 	go func() {
 		srv.Run(ctx)
 		close(srv.done)
 	}()
 }
 
+// Pause stops the replica from processing events until resume is called, as if its process was suspended.
+// The servers keep receiving messages and the timers keep firing, such that the events are queued and processed
+// when the replica resumes, or dropped if the event queue overflows. Stop and Shutdown resume a paused replica.
+func (srv *Replica) Pause() (resume func()) {
+	resumed := make(chan struct{})
+	var once sync.Once
+	resume = func() { once.Do(func() { close(resumed) }) }
+	srv.pauseMut.Lock()
+	srv.resumes = append(srv.resumes, resume)
+	srv.pauseMut.Unlock()
+	// the event loop blocks on the event until the replica is resumed.
+	srv.hs.EventLoop().AddPriorityEvent(func() { <-resumed })
+	return resume
+}
+
+// resumeAll ends all pauses of the replica.
+func (srv *Replica) resumeAll() {
+	srv.pauseMut.Lock()
+	defer srv.pauseMut.Unlock()
+	for _, resume := range srv.resumes {
+		resume()
+	}
+	srv.resumes = nil
+}
+
 // Stop stops the replica and closes connections.
 func (srv *Replica) Stop() {
+	srv.stopOnce.Do(func() {
+		srv.resumeAll()
+		srv.cancel()
+		<-srv.done
+		srv.Close()
+	})
+}
+
+// Shutdown stops a replica that was started by Start gracefully, and closes its connections.
+// First, the event loop is stopped, which stops the replica from proposing and voting. It stops accepting events,
+// and processes the events that are already queued for up to drainTimeout, if it is positive, before the events that
+// remain are abandoned.
+// The shutdown hooks of the modules are run in the reverse order of their initialization:
+// the execution pipeline, if any, executes the blocks that have been committed,
+// the client server stops accepting commands and fails the commands that have not been executed,
+// and the metrics take their final measurements. Then, the servers wait up to drainTimeout for the RPCs in progress
+// before they are closed. Finally, a ShutdownEvent is logged, and the metrics logger is flushed.
+// Shutdown has no effect if the replica has already been stopped or shut down.
+func (srv *Replica) Shutdown(drainTimeout time.Duration) error {
+	srv.stopOnce.Do(func() {
+		srv.shutdownErr = srv.shutdown(drainTimeout)
+	})
+	return srv.shutdownErr
+}
+
+func (srv *Replica) shutdown(drainTimeout time.Duration) error {
+	srv.resumeAll()
+	if srv.started {
+		ctx, cancel := context.Background(), context.CancelFunc(func() {})
+		if drainTimeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, drainTimeout)
+		}
+		if err := srv.hs.EventLoop().Stop(ctx); err != nil {
+			srv.hs.Logger().Infof("Stopping the event loop: %v", err)
+		}
+		cancel()
+		<-srv.done
+	}
 	srv.cancel()
-	<-srv.done
-	srv.Close()
+	if srv.clientSrv.pipeline != nil {
+		// the pipeline has already been drained by its shutdown hook, unless the replica was not started.
+		srv.clientSrv.pipeline.close()
+	}
+
+	if srv.status != nil {
+		srv.status.close()
+	}
+	if srv.commits != nil {
+		srv.commits.close()
+	}
+	if srv.app != nil {
+		srv.app.close()
+	}
+	// the connections to the other replicas are closed first, such that they can drain their servers as well.
+	srv.cfg.Close()
+	if !drain(drainTimeout, srv.clientSrv.srv.GracefulStop, srv.hsSrv.GracefulStop) {
+		srv.hs.Logger().Infof("Closing the connections that are still in use after %v", drainTimeout)
+		srv.clientSrv.Stop()
+		srv.hsSrv.Stop()
+	}
+	srv.closeCommandLog()
+
+	srv.hs.MetricsLogger().Log(&types.ShutdownEvent{Event: types.NewReplicaEvent(uint32(srv.hs.ID()), time.Now())})
+	if f, ok := srv.hs.MetricsLogger().(modules.Flusher); ok {
+		if err := f.Flush(); err != nil {
+			return fmt.Errorf("failed to flush metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// drain runs the stop functions concurrently, and returns false if they did not return within the timeout.
+func drain(timeout time.Duration, stops ...func()) bool {
+	var wg sync.WaitGroup
+	for _, stop := range stops {
+		wg.Add(1)
+		go func(stop func()) {
+			defer wg.Done()
+			stop()
+		}(stop)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
 }
 
 // Run runs the replica until the context is cancelled.
 func (srv *Replica) Run(ctx context.Context) {
+	if srv.app != nil {
+		go srv.app.run(ctx)
+	}
 	srv.hs.Synchronizer().Start(ctx)
 	srv.hs.Run(ctx)
 }
 
 // Close closes the connections and stops the servers used by the replica.
 func (srv *Replica) Close() {
+	if srv.clientSrv.pipeline != nil {
+		srv.clientSrv.pipeline.close()
+	}
+	if srv.status != nil {
+		srv.status.close()
+	}
+	if srv.commits != nil {
+		srv.commits.close()
+	}
+	if srv.app != nil {
+		srv.app.close()
+	}
 	srv.clientSrv.Stop()
 	srv.cfg.Close()
 	srv.hsSrv.Stop()
+	srv.closeCommandLog()
 }
 
-// GetHash returns the hash of all executed commands.
+// GetHash returns the hash of all executed commands. It is safe to call while the replica is running.
 func (srv *Replica) GetHash() (b []byte) {
+	srv.clientSrv.mut.Lock()
+	defer srv.clientSrv.mut.Unlock()
 	return srv.clientSrv.hash.Sum(b)
 }
+
+// IncompatiblePeers returns the replicas that the handshake showed to be incompatible with this replica,
+// such as replicas that use other consensus rules, and the reason that each of them is incompatible.
+func (srv *Replica) IncompatiblePeers() map[hotstuff.ID]error {
+	srv.incompatMut.Lock()
+	defer srv.incompatMut.Unlock()
+	peers := make(map[hotstuff.ID]error, len(srv.incompatible))
+	for id, err := range srv.incompatible {
+		peers[id] = err
+	}
+	return peers
+}
+
+// Application returns the application that executes the committed commands, or nil if there is none.
+func (srv *Replica) Application() Application {
+	return srv.clientSrv.app
+}
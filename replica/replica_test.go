@@ -0,0 +1,3792 @@
+package replica
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/relab/gorums"
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/blockchain"
+	"github.com/relab/hotstuff/client"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/consensus/chainedhotstuff"
+	"github.com/relab/hotstuff/crypto"
+	"github.com/relab/hotstuff/crypto/bls12"
+	"github.com/relab/hotstuff/crypto/ecdsa"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/examples/counter"
+	"github.com/relab/hotstuff/examples/kvstore"
+	"github.com/relab/hotstuff/internal/proto/apppb"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/internal/proto/commitpb"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"github.com/relab/hotstuff/internal/testutil"
+	"github.com/relab/hotstuff/leaderrotation"
+	"github.com/relab/hotstuff/metrics"
+	"github.com/relab/hotstuff/metrics/types"
+	"github.com/relab/hotstuff/modules"
+	"github.com/relab/hotstuff/synchronizer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSeparateServerLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	keys := testutil.GenerateKeys(t, 2, testutil.GenerateECDSAKey)
+
+	r := New(Config{
+		ID:                  1,
+		PrivateKey:          keys[0],
+		BatchSize:           1,
+		ReplicaAddress:      "127.0.0.1:0",
+		ClientAddress:       "127.0.0.1:0",
+		ReplicaServerLimits: ServerLimits{MaxRecvMsgSize: 1024},
+		// the blocks are not limited by the replica server, such that its limit is tested.
+		MaxBlockBytes: 1 << 20,
+	}, testutil.TestModules(t, ctrl, 1, keys[0]))
+	replicaAddr, clientAddr, err := r.Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if replicaAddr.String() == clientAddr.String() {
+		t.Fatalf("replica and client servers share the address %s", replicaAddr)
+	}
+
+	large := strings.Repeat("x", 4096)
+
+	// the client server should accept a command that exceeds the message size limit of the replica server.
+	mgr := clientpb.NewManager(
+		gorums.WithDialTimeout(time.Second),
+		gorums.WithGrpcDialOptions(grpc.WithBlock(), grpc.WithInsecure()),
+	)
+	defer mgr.Close()
+	clientCfg, err := mgr.NewConfiguration(qspec{}, gorums.WithNodeList([]string{clientAddr.String()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCfg.ExecCommand(context.Background(), &clientpb.Command{ClientID: 1, SequenceNumber: 1, Data: []byte(large)})
+	waitFor(t, "command to be received by the client server", func() bool {
+		r.clientSrv.cmdCache.mut.Lock()
+		defer r.clientSrv.cmdCache.mut.Unlock()
+		return r.clientSrv.cmdCache.cache.Len() == 1
+	})
+
+	// the replica server should receive small proposals, but reject those that exceed its limit.
+	builder := testutil.TestModules(t, ctrl, 2, keys[1])
+	peer := backend.NewConfig(nil, gorums.WithDialTimeout(time.Second))
+	builder.Register(peer)
+	builder.Build()
+	err = peer.Connect([]backend.ReplicaInfo{
+		{ID: 1, Address: replicaAddr.String(), PubKey: keys[0].Public()},
+		{ID: 2, PubKey: keys[1].Public()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+	waitFor(t, "connection to the replica server", func() bool { return peer.IsConnected(1) })
+
+	received := func() uint64 { return r.hsSrv.NetworkStats()[backend.ProposalClass].Received }
+	qc := consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash())
+	peer.Propose(testutil.NewProposeMsg(consensus.GetGenesis().Hash(), qc, "foo", 1, 2))
+	waitFor(t, "small proposal to be received by the replica server", func() bool { return received() == 1 })
+
+	peer.Propose(testutil.NewProposeMsg(consensus.GetGenesis().Hash(), qc, consensus.Command(large), 2, 2))
+	time.Sleep(500 * time.Millisecond)
+	if got := received(); got != 1 {
+		t.Errorf("replica server received %d proposals, want 1", got)
+	}
+}
+
+// countingClientStream counts the messages sent on a client stream.
+type countingClientStream struct {
+	grpc.ClientStream
+	sent *int32
+}
+
+func (s countingClientStream) SendMsg(m interface{}) error {
+	atomic.AddInt32(s.sent, 1)
+	return s.ClientStream.SendMsg(m)
+}
+
+// countingServerStream counts the messages received on a server stream.
+type countingServerStream struct {
+	grpc.ServerStream
+	received *int32
+}
+
+func (s countingServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		atomic.AddInt32(s.received, 1)
+	}
+	return err
+}
+
+func TestDialAndServerOptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	keys := testutil.GenerateKeys(t, 2, testutil.GenerateECDSAKey)
+
+	var sent, received int32
+	clientInterceptor := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return countingClientStream{stream, &sent}, nil
+	}
+	serverInterceptor := func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, countingServerStream{stream, &received})
+	}
+
+	replicas := make([]*Replica, 2)
+	for i := range replicas {
+		replicas[i] = New(Config{
+			ID:                   hotstuff.ID(i + 1),
+			PrivateKey:           keys[i],
+			BatchSize:            1,
+			ReplicaAddress:       "127.0.0.1:0",
+			ClientAddress:        "127.0.0.1:0",
+			ManagerOptions:       []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+			DialOptions:          []grpc.DialOption{grpc.WithChainStreamInterceptor(clientInterceptor)},
+			ReplicaServerOptions: []gorums.ServerOption{gorums.WithGRPCServerOptions(grpc.ChainStreamInterceptor(serverInterceptor))},
+		}, testutil.TestModules(t, ctrl, hotstuff.ID(i+1), keys[i]))
+	}
+	infos := make([]backend.ReplicaInfo, 2)
+	for i, r := range replicas {
+		replicaAddr, _, err := r.Listen()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		infos[i] = backend.ReplicaInfo{ID: hotstuff.ID(i + 1), Address: replicaAddr.String(), PubKey: keys[i].Public()}
+	}
+	if err := replicas[0].Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "connection to replica 2", func() bool { return replicas[0].cfg.IsConnected(2) })
+
+	sentBefore, receivedBefore := atomic.LoadInt32(&sent), atomic.LoadInt32(&received)
+	qc := consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash())
+	replicas[0].cfg.Propose(testutil.NewProposeMsg(consensus.GetGenesis().Hash(), qc, "foo", 1, 1))
+	waitFor(t, "the proposal to pass through the interceptors", func() bool {
+		return atomic.LoadInt32(&sent) > sentBefore && atomic.LoadInt32(&received) > receivedBefore
+	})
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type qspec struct{}
+
+func (qspec) ExecCommandQF(_ *clientpb.Command, replies map[uint32]*clientpb.CommandResponse) (*clientpb.CommandResponse, bool) {
+	for _, reply := range replies {
+		return reply, true
+	}
+	return nil, false
+}
+
+func (qspec) ExecCommandBatchQF(_ *clientpb.Batch, replies map[uint32]*clientpb.BatchResponse) (*clientpb.BatchResponse, bool) {
+	for _, reply := range replies {
+		return reply, true
+	}
+	return nil, false
+}
+
+func (qspec) QueryQF(_ *clientpb.QueryRequest, replies map[uint32]*clientpb.QueryResponse) (*clientpb.QueryResponse, bool) {
+	for _, reply := range replies {
+		return reply, true
+	}
+	return nil, false
+}
+
+// startNetwork starts a network of n replicas running chained HotStuff, and returns the replicas and their client addresses.
+// The returned function stops the replicas. It is also called when the test ends.
+func startNetwork(t *testing.T, n int, forward bool) ([]*Replica, []string, func()) {
+	t.Helper()
+	return startConfiguredNetwork(t, n, func(conf *Config) { conf.ForwardCommands = forward })
+}
+
+// startConfiguredNetwork is like startNetwork, but the configuration of each replica is modified by configure.
+func startConfiguredNetwork(t *testing.T, n int, configure func(conf *Config)) ([]*Replica, []string, func()) {
+	t.Helper()
+	return startNetworkWithModules(t, n, func(conf *Config, _ *consensus.Builder) { configure(conf) })
+}
+
+// startNetworkWithModules is like startConfiguredNetwork, but configure may also register modules with the builder of each replica.
+func startNetworkWithModules(t *testing.T, n int, configure func(conf *Config, builder *consensus.Builder)) ([]*Replica, []string, func()) {
+	t.Helper()
+	keys := testutil.GenerateKeys(t, n, testutil.GenerateECDSAKey)
+	replicas := make([]*Replica, n)
+	infos := make([]backend.ReplicaInfo, n)
+	clientAddrs := make([]string, n)
+	for i := range replicas {
+		replicas[i], infos[i], clientAddrs[i] = newTestReplica(t, hotstuff.ID(i+1), keys[i], "127.0.0.1:0", configure)
+	}
+	for _, r := range replicas {
+		if err := r.Connect(infos); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, r := range replicas {
+		r.Start()
+	}
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			for _, r := range replicas {
+				r.Stop()
+			}
+		})
+	}
+	t.Cleanup(stop)
+	return replicas, clientAddrs, stop
+}
+
+// newUnstartedReplica creates a replica running chained HotStuff, without starting its servers.
+// The replica server listens on replicaAddr. configure may modify the configuration and register modules with the builder.
+func newUnstartedReplica(t *testing.T, id hotstuff.ID, key consensus.PrivateKey, replicaAddr string, configure func(conf *Config, builder *consensus.Builder)) *Replica {
+	t.Helper()
+	builder := consensus.NewBuilder(id, key)
+	builder.Register(
+		consensus.New(chainedhotstuff.New()),
+		crypto.NewCache(ecdsa.New(), 100),
+		leaderrotation.NewRoundRobin(),
+		synchronizer.New(testutil.FixedTimeout(time.Second)),
+		blockchain.New(),
+	)
+	conf := Config{
+		ID:             id,
+		PrivateKey:     key,
+		BatchSize:      1,
+		ReplicaAddress: replicaAddr,
+		ClientAddress:  "127.0.0.1:0",
+		ManagerOptions: []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+	}
+	configure(&conf, &builder)
+	return New(conf, builder)
+}
+
+// newTestReplica creates a replica like newUnstartedReplica, and starts its servers.
+func newTestReplica(t *testing.T, id hotstuff.ID, key consensus.PrivateKey, replicaAddr string, configure func(conf *Config, builder *consensus.Builder)) (*Replica, backend.ReplicaInfo, string) {
+	t.Helper()
+	r := newUnstartedReplica(t, id, key, replicaAddr, configure)
+	listenAddr, clientAddr, err := r.Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, backend.ReplicaInfo{ID: id, Address: listenAddr.String(), PubKey: key.Public()}, clientAddr.String()
+}
+
+// commitLatency runs a network of n replicas, where the clients send commands only to replica 2,
+// which is not the leader of the first view. It returns the median latency of the committed commands.
+func commitLatency(t *testing.T, n int, forward bool) time.Duration {
+	t.Helper()
+	_, clientAddrs, _ := startNetwork(t, n, forward)
+
+	mgr := clientpb.NewManager(
+		gorums.WithDialTimeout(time.Second),
+		gorums.WithGrpcDialOptions(grpc.WithBlock(), grpc.WithInsecure()),
+	)
+	defer mgr.Close()
+	clientCfg, err := mgr.NewConfiguration(qspec{}, gorums.WithNodeList([]string{clientAddrs[1]}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the clients send commands for a fixed duration, as the last commands in the chain are not committed
+	// until they are followed by more proposals.
+	const workers = 16
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	var (
+		mut       sync.Mutex
+		latencies []time.Duration
+		wg        sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := uint64(1); ctx.Err() == nil; i++ {
+				start := time.Now()
+				_, err := clientCfg.ExecCommand(ctx, &clientpb.Command{ClientID: uint32(w + 1), SequenceNumber: i, Data: []byte("foo")}).Get()
+				if err != nil {
+					if ctx.Err() == nil {
+						t.Errorf("command %d of client %d failed: %v", i, w+1, err)
+					}
+					return
+				}
+				mut.Lock()
+				latencies = append(latencies, time.Since(start))
+				mut.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if len(latencies) == 0 {
+		t.Fatal("no commands were committed")
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return latencies[len(latencies)/2]
+}
+
+func TestForwardCommandsToLeader(t *testing.T) {
+	// without forwarding, the commands wait for replica 2 to become the leader,
+	// and the views of the other leaders time out after one second.
+	latency := commitLatency(t, 4, true)
+	if latency > 250*time.Millisecond {
+		t.Errorf("median commit latency was %v, want at most 250ms", latency)
+	}
+}
+
+// blackHoleStream drops the first copy of the command with the given sequence number that is sent on the stream.
+type blackHoleStream struct {
+	grpc.ClientStream
+	seq     uint64
+	dropped bool
+	drops   *int32
+}
+
+func (s *blackHoleStream) SendMsg(m interface{}) error {
+	if msg, ok := m.(*gorums.Message); ok {
+		if cmd, ok := msg.Message.(*clientpb.Command); ok && cmd.GetSequenceNumber() == s.seq && !s.dropped {
+			s.dropped = true
+			atomic.AddInt32(s.drops, 1)
+			return nil
+		}
+	}
+	return s.ClientStream.SendMsg(m)
+}
+
+// commandObserver counts the commands acknowledged, retried, and failed by a client.
+type commandObserver struct {
+	mut                    sync.Mutex
+	acked, retried, failed int
+}
+
+func (o *commandObserver) InitModule(mods *modules.Modules) {
+	count := func(n *int) eventloop.EventHandler {
+		return func(_ interface{}) {
+			o.mut.Lock()
+			*n++
+			o.mut.Unlock()
+		}
+	}
+	mods.EventLoop().RegisterHandler(client.LatencyMeasurementEvent{}, count(&o.acked))
+	mods.EventLoop().RegisterHandler(client.CommandRetriedEvent{}, count(&o.retried))
+	mods.EventLoop().RegisterHandler(client.CommandFailedEvent{}, count(&o.failed))
+}
+
+func (o *commandObserver) counts() (acked, retried, failed int) {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	return o.acked, o.retried, o.failed
+}
+
+// countCommitted returns the number of times that the client's command was committed by the replica.
+// The replica must be stopped.
+func countCommitted(t *testing.T, r *Replica, id cmdID) (count int) {
+	t.Helper()
+	for block := r.hs.Consensus().CommittedBlock(); block.View() > 0; {
+		batch := new(clientpb.Batch)
+		if err := proto.Unmarshal([]byte(block.Command()), batch); err != nil {
+			t.Fatal(err)
+		}
+		for _, cmd := range batch.GetCommands() {
+			if cmd.GetClientID() == id.clientID && cmd.GetSequenceNumber() == id.sequenceNum {
+				count++
+			}
+		}
+		var ok bool
+		if block, ok = r.hs.BlockChain().LocalGet(block.Parent()); !ok {
+			break
+		}
+	}
+	return count
+}
+
+func TestRetryBlackHoledCommand(t *testing.T) {
+	const n = 4
+	replicas, clientAddrs, stop := startNetwork(t, n, false)
+
+	// the first copy of command 1 that is sent to each replica is lost.
+	var drops int32
+	interceptor := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &blackHoleStream{ClientStream: stream, seq: 1, drops: &drops}, nil
+	}
+
+	observer := &commandObserver{}
+	builder := modules.NewBuilder(1)
+	builder.Register(observer)
+	// the client issues commands at a fixed rate, such that the replicas keep proposing while command 1 is retried.
+	cli := client.New(client.Config{
+		ID:            1,
+		MaxConcurrent: 100,
+		PayloadSize:   1,
+		Input:         io.NopCloser(bytes.NewReader(make([]byte, 10000))),
+		ManagerOptions: []gorums.ManagerOption{
+			gorums.WithDialTimeout(time.Second),
+			gorums.WithGrpcDialOptions(grpc.WithChainStreamInterceptor(interceptor)),
+		},
+		LoadMode:       client.FixedLoad,
+		TargetRate:     100,
+		RequestTimeout: 500 * time.Millisecond,
+		MaxRetries:     3,
+	}, builder)
+	infos := make([]backend.ReplicaInfo, n)
+	for i := range infos {
+		infos[i] = backend.ReplicaInfo{ID: hotstuff.ID(i + 1), Address: clientAddrs[i]}
+	}
+	if err := cli.Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	cli.Start()
+
+	// wait for command 1 to be retried, and then give the retry time to commit.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, retried, _ := observer.counts(); retried > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("command 1 was not retried")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(2 * time.Second)
+	cli.Stop()
+	stop()
+
+	if got := atomic.LoadInt32(&drops); got != n {
+		t.Errorf("dropped %d copies of command 1, want %d", got, n)
+	}
+	if _, _, failed := observer.counts(); failed > 0 {
+		t.Errorf("%d commands failed, want 0", failed)
+	}
+	for _, r := range replicas {
+		if count := countCommitted(t, r, cmdID{clientID: 1, sequenceNum: 1}); count != 1 {
+			t.Errorf("replica %d committed command 1 %d times, want 1", r.hs.ID(), count)
+		}
+	}
+}
+
+func TestSeqSet(t *testing.T) {
+	seqs := make(clientSeqs)
+	seqs.add(cmdID{1, 5})
+	seqs.add(cmdID{1, 3})
+	for seq, want := range map[uint64]bool{3: true, 4: false, 5: true, 6: false} {
+		if got := seqs.contains(cmdID{1, seq}); got != want {
+			t.Errorf("contains(%d) = %v, want %v", seq, got, want)
+		}
+	}
+	if seqs.contains(cmdID{2, 5}) {
+		t.Error("sequence numbers of different clients should be separate")
+	}
+
+	// sequence numbers that fall out of the window are forgotten, and considered to be in the set.
+	// every other sequence number is skipped, such that the bits of the skipped ones must be cleared as the window moves.
+	for seq := uint64(10); seq < 10+3*seqWindow; seq += 2 {
+		seqs.add(cmdID{1, seq})
+	}
+	highest := uint64(10 + 3*seqWindow - 2)
+	if n := len(seqs[1].window()); n != seqWindow/2 {
+		t.Errorf("window holds %d sequence numbers, want %d", n, seqWindow/2)
+	}
+	if !seqs.contains(cmdID{1, 4}) || !seqs.contains(cmdID{1, 11}) {
+		t.Error("sequence numbers below the window should be considered to be in the set")
+	}
+	if seqs.contains(cmdID{1, highest - 1}) || !seqs.contains(cmdID{1, highest - 2}) {
+		t.Error("sequence numbers within the window should be remembered individually")
+	}
+	if again := clientSeqsFromProto(seqs.toProto()); *again[1] != *seqs[1] {
+		t.Error("the set differs after a round trip through its protobuf message")
+	}
+}
+
+func TestProposedCommands(t *testing.T) {
+	const ttl = time.Minute
+	srv := newClientServer(Config{BatchSize: 1, ProposedTTL: ttl}, nil)
+	clock := testutil.NewFakeClock(time.Unix(0, 0))
+	builder := consensus.NewBuilder(1, nil)
+	builder.Register(srv, srv.cmdCache, clock)
+	builder.Build()
+	cache := srv.cmdCache
+
+	command := func(seq uint64) *clientpb.Command {
+		return &clientpb.Command{ClientID: 1, SequenceNumber: seq}
+	}
+	batch := func(cmds ...*clientpb.Command) consensus.Command {
+		b, err := proto.Marshal(&clientpb.Batch{Commands: cmds})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return consensus.Command(b)
+	}
+
+	// a client retries a command that is in a certified block.
+	cache.addCommand(command(1), true)
+	proposal, ok := cache.Get(context.Background())
+	if !ok {
+		t.Fatal("the cache did not return a batch")
+	}
+	cache.Proposed(proposal)
+	if added, _, _ := cache.addCommand(command(1), true); added {
+		t.Error("a retry of a proposed command was added to the cache")
+	}
+	if cache.Accept(proposal) {
+		t.Error("a batch with a proposed command was accepted")
+	}
+
+	// the block is forked, and the command is proposed again without a retry.
+	cache.Fork(consensus.NewBlock(consensus.GetGenesis().Hash(), consensus.QuorumCert{}, proposal, 1, 1))
+	if added, _, _ := cache.addCommand(command(1), true); added {
+		t.Error("a retry of a requeued command was added to the cache")
+	}
+	if reproposal, ok := cache.Get(context.Background()); !ok || !cache.Accept(reproposal) {
+		t.Error("a forked command was not proposed again")
+	}
+	cache.Proposed(proposal)
+
+	// the block is committed, and the command is executed.
+	srv.Exec(consensus.NewBlock(consensus.GetGenesis().Hash(), consensus.QuorumCert{}, proposal, 1, 1))
+	if n := cache.proposed.len(1); n != 0 {
+		t.Errorf("%d commands are still remembered as proposed after they were executed", n)
+	}
+	if added, _, _ := cache.addCommand(command(1), true); added {
+		t.Error("a retry of an executed command was added to the cache")
+	}
+	if cache.Accept(proposal) {
+		t.Error("a batch with an executed command was accepted")
+	}
+
+	// the command of a block that is neither committed nor forked expires.
+	cache.Proposed(batch(command(2)))
+	clock.Step(ttl / 2)
+	if cache.Accept(batch(command(2))) {
+		t.Error("a batch with a proposed command was accepted before the command expired")
+	}
+	clock.Step(ttl / 2)
+	if !cache.Accept(batch(command(2))) {
+		t.Error("a batch with an expired command was not accepted")
+	}
+
+	// the memory is bounded when the window overflows.
+	for seq := uint64(3); seq < 3*seqWindow; seq++ {
+		cache.Proposed(batch(command(seq)))
+	}
+	if n := cache.proposed.len(1); n > seqWindow {
+		t.Errorf("%d commands are remembered individually, want at most %d", n, seqWindow)
+	}
+	if cache.Accept(batch(command(3))) {
+		t.Error("a batch with a command that left the window was accepted")
+	}
+	if !cache.Accept(batch(command(3 * seqWindow))) {
+		t.Error("a batch with a new command was not accepted")
+	}
+}
+
+func TestExecuteDuplicateOnce(t *testing.T) {
+	builder := consensus.NewBuilder(1, nil)
+	follower := newClientServer(Config{BatchSize: 1}, nil)
+	builder.Register(follower, follower.cmdCache)
+	builder.Build()
+
+	dup := &clientpb.Command{ClientID: 1, SequenceNumber: 1, Data: []byte("dup")}
+	// the client submits the command twice, for example because the first submission timed out.
+	results := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		c := follower.awaitExecution(cmdID{1, 1}, false)
+		go func() { results <- (<-c).err }()
+	}
+
+	// two leaders propose the command in their own batches.
+	batches := make([]consensus.Command, 2)
+	for i := range batches {
+		leader := newCmdCache(1)
+		leaderBuilder := modules.NewBuilder(hotstuff.ID(i + 2))
+		leaderBuilder.Register(leader)
+		leaderBuilder.Build()
+		leader.addCommand(dup, true)
+		leader.addCommand(&clientpb.Command{ClientID: 2, SequenceNumber: uint64(i + 1)}, true)
+		var ok bool
+		if batches[i], ok = leader.Get(context.Background()); !ok {
+			t.Fatal("leader did not return a batch")
+		}
+		if !follower.cmdCache.Accept(batches[i]) {
+			t.Fatalf("batch %d was not accepted", i)
+		}
+	}
+	for i, batch := range batches {
+		follower.Exec(consensus.NewBlock(consensus.GetGenesis().Hash(), consensus.QuorumCert{}, batch, consensus.View(i+1), hotstuff.ID(i+2)))
+	}
+
+	want := sha256.Sum256(dup.Data)
+	if got := follower.hash.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("hash is %.8x, want %.8x: the command was not executed exactly once", got, want)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-results:
+			if err != nil {
+				t.Errorf("submission was not acknowledged: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("submission was not acknowledged")
+		}
+	}
+	if follower.awaitExecution(cmdID{1, 1}, false) != nil {
+		t.Error("a later submission of the command should be acknowledged immediately")
+	}
+	if follower.cmdCache.Accept(batches[0]) {
+		t.Error("a batch with an executed command should not be accepted")
+	}
+
+	// the executed commands are remembered when the state is restored.
+	restored := newCmdCache(1)
+	restored.restoreExecutedCommands(follower.cmdCache.executedCommands())
+	if !restored.isExecuted(cmdID{1, 1}) || restored.isExecuted(cmdID{1, 2}) {
+		t.Error("the restored record of executed commands differs")
+	}
+	if !proto.Equal(restored.executedCommands(), follower.cmdCache.executedCommands()) {
+		t.Error("the restored record of executed commands differs")
+	}
+
+	// commands that were cached before the state was restored are not proposed if they have been executed.
+	if added, _, _ := restored.addCommand(&clientpb.Command{ClientID: 1, SequenceNumber: 1}, false); added {
+		t.Error("an executed command should not be added to the cache")
+	}
+	stale := newCmdCache(1)
+	staleBuilder := modules.NewBuilder(4)
+	staleBuilder.Register(stale)
+	staleBuilder.Build()
+	stale.addCommand(&clientpb.Command{ClientID: 1, SequenceNumber: 1}, true)
+	stale.addCommand(&clientpb.Command{ClientID: 1, SequenceNumber: 2}, true)
+	stale.restoreExecutedCommands(follower.cmdCache.executedCommands())
+	cmd, ok := stale.Get(context.Background())
+	if !ok || !stale.Accept(cmd) {
+		t.Error("the cache proposed a batch with an executed command")
+	}
+}
+
+// queryClient returns a client that sends queries with the given consistency to the replicas.
+func queryClient(t *testing.T, id hotstuff.ID, consistency client.ReadConsistency, infos []backend.ReplicaInfo) *client.Client {
+	t.Helper()
+	cli := client.New(client.Config{
+		ID:              id,
+		ManagerOptions:  []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+		ReadConsistency: consistency,
+	}, modules.NewBuilder(id))
+	if err := cli.Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	return cli
+}
+
+func TestStaleLocalReadAndQuorumRead(t *testing.T) {
+	const n = 4
+	// the messages to replica 4 are delayed, such that it executes the commands long after the other replicas.
+	_, clientAddrs, _ := startConfiguredNetwork(t, n, func(conf *Config) {
+		conf.Application = kvstore.New()
+		if conf.ID != n {
+			conf.Latencies = map[hotstuff.ID]time.Duration{n: 2 * time.Second}
+		}
+	})
+	infos := make([]backend.ReplicaInfo, n)
+	for i := range infos {
+		infos[i] = backend.ReplicaInfo{ID: hotstuff.ID(i + 1), Address: clientAddrs[i]}
+	}
+
+	mgr := clientpb.NewManager(
+		gorums.WithDialTimeout(time.Second),
+		gorums.WithGrpcDialOptions(grpc.WithBlock(), grpc.WithInsecure()),
+	)
+	defer mgr.Close()
+	writeCfg, err := mgr.NewConfiguration(qspec{}, gorums.WithNodeList(clientAddrs[:n-1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// other commands are sent at a fixed rate while the write is committed, since the last commands in the chain
+	// are not committed until they are followed by more proposals.
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for i := uint64(1); ; i++ {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			writeCfg.ExecCommand(ctx, &clientpb.Command{ClientID: 1, SequenceNumber: i, Data: []byte("foo")})
+		}
+	}()
+	_, err = writeCfg.ExecCommand(context.Background(), &clientpb.Command{ClientID: 2, SequenceNumber: 1, Data: kvstore.Put("x", []byte("1"))}).Get()
+	cancel()
+	wg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local := queryClient(t, 3, client.LocalRead, infos[n-1:])
+	result, err := local.Query(context.Background(), []byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 0 {
+		t.Errorf("local read from the lagging replica returned %q, want the stale empty value", result)
+	}
+
+	// the quorum read fails until f+1 replicas have executed the same commands.
+	quorum := queryClient(t, 4, client.QuorumRead, infos)
+	waitFor(t, "a quorum read", func() bool {
+		result, err = quorum.Query(context.Background(), []byte("x"))
+		return err == nil
+	})
+	if string(result) != "1" {
+		t.Errorf("quorum read returned %q, want %q", result, "1")
+	}
+}
+
+func TestCommitProof(t *testing.T) {
+	const n = 4
+	infos := make([]backend.ReplicaInfo, n)
+	_, clientAddrs, _ := startConfiguredNetwork(t, n, func(conf *Config) {
+		infos[conf.ID-1] = backend.ReplicaInfo{ID: conf.ID, PubKey: conf.PrivateKey.Public()}
+	})
+	for i := range infos {
+		infos[i].Address = clientAddrs[i]
+	}
+
+	mgr := clientpb.NewManager(
+		gorums.WithDialTimeout(time.Second),
+		gorums.WithGrpcDialOptions(grpc.WithBlock(), grpc.WithInsecure()),
+	)
+	defer mgr.Close()
+	fillerCfg, err := mgr.NewConfiguration(qspec{}, gorums.WithNodeList(clientAddrs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the command is not committed until it is followed by more proposals.
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for i := uint64(1); ; i++ {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			fillerCfg.ExecCommand(ctx, &clientpb.Command{ClientID: 1, SequenceNumber: i, Data: []byte("foo")})
+		}
+	}()
+
+	cli := client.New(client.Config{
+		ID:             2,
+		ManagerOptions: []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+	}, modules.NewBuilder(2))
+	if err := cli.Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	cmdCtx, cmdCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cmdCancel()
+	cmd, proof, err := cli.ExecCommandWithProof(cmdCtx, []byte("bar"))
+	cancel()
+	wg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainLength := chainedhotstuff.New().ChainLength()
+	if err := client.VerifyProof(proof, cmd, infos, ecdsa.New(), chainLength); err != nil {
+		t.Fatalf("proof of committed command was rejected: %v", err)
+	}
+
+	other := proto.Clone(cmd).(*clientpb.Command)
+	other.Data = []byte("baz")
+	if err := client.VerifyProof(proof, other, infos, ecdsa.New(), chainLength); !errors.Is(err, client.ErrInvalidProof) {
+		t.Errorf("proof was accepted for a different command: %v", err)
+	}
+
+	// a replica that replaces the command must also replace the block, which is not certified.
+	forged := proto.Clone(proof).(*clientpb.CommitProof)
+	block, err := hotstuffpb.BlockFromProto(forged.Blocks[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := &clientpb.Batch{Commands: []*clientpb.Command{other}}
+	b, err := proto.Marshal(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block = consensus.NewBlock(block.Parent(), block.QuorumCert(), consensus.Command(b), block.View(), block.Proposer())
+	hash := block.Hash()
+	forged.Blocks[0] = hotstuffpb.BlockToProto(block)
+	forged.BlockHash = hash[:]
+	forged.Position = 0
+	if err := client.VerifyProof(forged, other, infos, ecdsa.New(), chainLength); !errors.Is(err, client.ErrInvalidProof) {
+		t.Errorf("proof with a forged block was accepted: %v", err)
+	}
+
+	tampered := proto.Clone(proof).(*clientpb.CommitProof)
+	for _, sig := range tampered.GetQC().GetSig().GetECDSASigs().GetSigs() {
+		sig.R[0] ^= 1
+	}
+	if err := client.VerifyProof(tampered, cmd, infos, ecdsa.New(), chainLength); !errors.Is(err, client.ErrInvalidProof) {
+		t.Errorf("proof with tampered signatures was accepted: %v", err)
+	}
+}
+
+func TestCommandResults(t *testing.T) {
+	const n = 4
+	infos := make([]backend.ReplicaInfo, n)
+	_, clientAddrs, _ := startConfiguredNetwork(t, n, func(conf *Config) {
+		conf.Application = kvstore.New()
+		infos[conf.ID-1] = backend.ReplicaInfo{ID: conf.ID}
+	})
+	for i := range infos {
+		infos[i].Address = clientAddrs[i]
+	}
+
+	mgr := clientpb.NewManager(
+		gorums.WithDialTimeout(time.Second),
+		gorums.WithGrpcDialOptions(grpc.WithBlock(), grpc.WithInsecure()),
+	)
+	defer mgr.Close()
+	fillerCfg, err := mgr.NewConfiguration(qspec{}, gorums.WithNodeList(clientAddrs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the commands are not committed until they are followed by more proposals.
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for i := uint64(1); ; i++ {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			fillerCfg.ExecCommand(ctx, &clientpb.Command{ClientID: 1, SequenceNumber: i, Data: []byte("foo")})
+		}
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	writer := queryClient(t, 2, client.LocalRead, infos)
+	reader := queryClient(t, 3, client.LocalRead, infos)
+	cmdCtx, cmdCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cmdCancel()
+
+	put, err := writer.ExecCommand(cmdCtx, kvstore.Put("x", []byte("1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(put.Result) != 0 {
+		t.Errorf("put returned %q, want no result", put.Result)
+	}
+	if _, err := reader.ExecCommand(cmdCtx, kvstore.Put("y", []byte("2"))); err != nil {
+		t.Fatal(err)
+	}
+
+	// the gets are ordered after the puts, and each client receives the result of its own get.
+	results := make(chan *client.CommandResult, 1)
+	go func() {
+		res, err := reader.ExecCommand(cmdCtx, kvstore.Get("y"))
+		if err != nil {
+			t.Error(err)
+		}
+		results <- res
+	}()
+	got, err := writer.ExecCommand(cmdCtx, kvstore.Get("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Result) != "1" {
+		t.Errorf("get of the writer returned %q, want %q", got.Result, "1")
+	}
+	if res := <-results; res != nil && string(res.Result) != "2" {
+		t.Errorf("get of the reader returned %q, want %q", res.Result, "2")
+	}
+}
+
+func TestClientBatches(t *testing.T) {
+	const commands = 200
+	replicas, clientAddrs, _ := startConfiguredNetwork(t, 4, func(conf *Config) { conf.BatchSize = 4 })
+	infos := make([]backend.ReplicaInfo, len(clientAddrs))
+	for i, addr := range clientAddrs {
+		infos[i] = backend.ReplicaInfo{ID: hotstuff.ID(i + 1), Address: addr}
+	}
+
+	cli := client.New(client.Config{
+		ID:             1,
+		PayloadSize:    1,
+		Input:          io.NopCloser(bytes.NewReader(make([]byte, commands))),
+		MaxConcurrent:  32,
+		RateLimit:      math.Inf(1),
+		BatchSize:      8,
+		BatchTimeout:   5 * time.Millisecond,
+		ManagerOptions: []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+	}, modules.NewBuilder(1))
+	if err := cli.Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	// the client sends more commands as the previous commands are acknowledged,
+	// which also commits the blocks that contain the last of the input.
+	cli.Start()
+	defer cli.Stop()
+
+	for i, r := range replicas {
+		waitFor(t, fmt.Sprintf("replica %d to execute the commands", i+1), func() bool {
+			r.clientSrv.mut.Lock()
+			defer r.clientSrv.mut.Unlock()
+			return r.clientSrv.height >= commands
+		})
+	}
+}
+
+// blockRecorder is an executor that records the views of the blocks that it executes.
+type blockRecorder struct {
+	mut     sync.Mutex
+	views   []consensus.View
+	release chan struct{} // if not nil, each block waits to be released
+}
+
+func (r *blockRecorder) Exec(block *consensus.Block) {
+	if r.release != nil {
+		<-r.release
+	}
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	r.views = append(r.views, block.View())
+}
+
+func (r *blockRecorder) executed() []consensus.View {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return append([]consensus.View(nil), r.views...)
+}
+
+func TestExecPipeline(t *testing.T) {
+	newPipeline := func(rec *blockRecorder, lag int) *execPipeline {
+		p := newExecPipeline(rec, lag)
+		builder := consensus.NewBuilder(1, nil)
+		builder.Register(p)
+		builder.Build()
+		return p
+	}
+	block := func(view consensus.View) *consensus.Block {
+		return consensus.NewBlock(consensus.GetGenesis().Hash(), consensus.QuorumCert{}, "", view, 1)
+	}
+
+	t.Run("Order", func(t *testing.T) {
+		const committers, blocks = 4, 50
+		rec := &blockRecorder{}
+		p := newPipeline(rec, 4)
+		var wg sync.WaitGroup
+		for c := 0; c < committers; c++ {
+			wg.Add(1)
+			go func(c int) {
+				defer wg.Done()
+				for i := 1; i <= blocks; i++ {
+					p.Exec(block(consensus.View(c*1000 + i)))
+				}
+			}(c)
+		}
+		wg.Wait()
+		p.close()
+
+		views := rec.executed()
+		if len(views) != committers*blocks {
+			t.Fatalf("%d blocks were executed, want %d", len(views), committers*blocks)
+		}
+		last := make(map[consensus.View]consensus.View)
+		for _, view := range views {
+			c := view / 1000
+			if view <= last[c] {
+				t.Fatalf("block %d was executed after block %d", view, last[c])
+			}
+			last[c] = view
+		}
+	})
+
+	t.Run("Backpressure", func(t *testing.T) {
+		rec := &blockRecorder{release: make(chan struct{})}
+		p := newPipeline(rec, 2)
+		var committed int32
+		go func() {
+			for view := consensus.View(1); view <= 5; view++ {
+				p.Exec(block(view))
+				atomic.AddInt32(&committed, 1)
+			}
+		}()
+		// one block is being executed, and two blocks wait for it.
+		waitFor(t, "the pipeline to fill up", func() bool { return atomic.LoadInt32(&committed) == 3 && p.len() == 2 })
+		time.Sleep(20 * time.Millisecond)
+		if n := atomic.LoadInt32(&committed); n != 3 {
+			t.Fatalf("%d blocks were committed while the application was stuck, want 3", n)
+		}
+		rec.release <- struct{}{}
+		waitFor(t, "the next block to be committed", func() bool { return atomic.LoadInt32(&committed) == 4 })
+		close(rec.release)
+		waitFor(t, "the blocks to be committed", func() bool { return atomic.LoadInt32(&committed) == 5 })
+		p.close()
+		if got, want := rec.executed(), []consensus.View{1, 2, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+			t.Errorf("executed the blocks %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Drain", func(t *testing.T) {
+		release := make(chan struct{})
+		rec := &blockRecorder{release: release}
+		p := newPipeline(rec, 16)
+		for view := consensus.View(1); view <= 10; view++ {
+			p.Exec(block(view))
+		}
+		closed := make(chan struct{})
+		go func() {
+			p.close()
+			close(closed)
+		}()
+		close(release)
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			t.Fatal("the pipeline was not drained")
+		}
+		if got := rec.executed(); len(got) != 10 {
+			t.Errorf("executed the blocks %v before the pipeline was closed, want 10 blocks", got)
+		}
+		// the blocks that are committed after the pipeline was closed are dropped.
+		p.Exec(block(11))
+		if got := rec.executed(); len(got) != 10 {
+			t.Errorf("executed the blocks %v after the pipeline was closed, want 10 blocks", got)
+		}
+	})
+
+	t.Run("Replicas", func(t *testing.T) {
+		const commands = 200
+		replicas, clientAddrs, _ := startConfiguredNetwork(t, 4, func(conf *Config) {
+			conf.BatchSize = 4
+			conf.ExecutionLag = 4
+		})
+		infos := make([]backend.ReplicaInfo, len(clientAddrs))
+		for i, addr := range clientAddrs {
+			infos[i] = backend.ReplicaInfo{ID: hotstuff.ID(i + 1), Address: addr}
+		}
+		cli := client.New(client.Config{
+			ID:             1,
+			PayloadSize:    1,
+			Input:          io.NopCloser(bytes.NewReader(make([]byte, commands))),
+			MaxConcurrent:  32,
+			RateLimit:      math.Inf(1),
+			ManagerOptions: []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+		}, modules.NewBuilder(1))
+		if err := cli.Connect(infos); err != nil {
+			t.Fatal(err)
+		}
+		cli.Start()
+		defer cli.Stop()
+
+		for i, r := range replicas {
+			waitFor(t, fmt.Sprintf("replica %d to execute the commands", i+1), func() bool {
+				r.clientSrv.mut.Lock()
+				defer r.clientSrv.mut.Unlock()
+				return r.clientSrv.height >= commands
+			})
+			// the snapshots record the view of the last executed block.
+			state, err := r.clientSrv.state(false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if state.GetView() == 0 {
+				t.Errorf("the state of replica %d has no executed view", i+1)
+			}
+		}
+	})
+}
+
+func TestSpeculation(t *testing.T) {
+	t.Run("Fork", func(t *testing.T) {
+		newServer := func(store *kvstore.Store, speculate bool) (*clientSrv, *consensus.Modules) {
+			srv := newClientServer(Config{BatchSize: 1, Application: store}, nil)
+			builder := testutil.TestModules(t, gomock.NewController(t), 1, testutil.GenerateECDSAKey(t))
+			builder.Register(srv, srv.cmdCache)
+			if speculate {
+				srv.speculation = newSpeculation(srv, store)
+				builder.Register(srv.speculation)
+			}
+			return srv, builder.Build()
+		}
+		batch := func(cmds ...*clientpb.Command) consensus.Command {
+			b, err := proto.Marshal(&clientpb.Batch{Commands: cmds})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return consensus.Command(b)
+		}
+		command := func(seq uint64, data []byte) *clientpb.Command {
+			return &clientpb.Command{ClientID: 1, SequenceNumber: seq, Data: data}
+		}
+
+		// the abandoned block b2 and the winning block b3 both extend b1, and both contain the command that sets x to 2.
+		b1 := consensus.NewBlock(consensus.GetGenesis().Hash(), consensus.QuorumCert{}, batch(command(1, kvstore.Put("x", []byte("1")))), 1, 1)
+		b2 := consensus.NewBlock(b1.Hash(), consensus.QuorumCert{}, batch(
+			command(2, kvstore.Put("x", []byte("2"))),
+			command(3, kvstore.Put("y", []byte("1"))),
+		), 2, 2)
+		b3 := consensus.NewBlock(b1.Hash(), consensus.QuorumCert{}, batch(
+			command(4, kvstore.Delete("x")),
+			command(2, kvstore.Put("x", []byte("2"))),
+		), 3, 3)
+		b4 := consensus.NewBlock(b3.Hash(), consensus.QuorumCert{}, batch(command(5, kvstore.Get("x"))), 4, 4)
+
+		store := kvstore.New()
+		srv, mods := newServer(store, true)
+		spec := srv.speculation
+		for _, block := range []*consensus.Block{b1, b2, b3, b4} {
+			mods.BlockChain().Store(block)
+		}
+
+		spec.speculate(b2)
+		if value, _ := store.Query([]byte("x")); len(value) != 0 {
+			t.Errorf("a query returned the speculated value %q", value)
+		}
+		spec.Exec(b1)
+		if value, _ := store.Query([]byte("x")); string(value) != "1" {
+			t.Errorf("a query returned %q after the first block was committed, want %q", value, "1")
+		}
+
+		// b2 is forked, which rolls back its commands, and then the branch of b3 is certified and committed.
+		spec.Fork(b2)
+		if spec.rollbacks != 1 {
+			t.Fatalf("the speculated blocks were rolled back %d times, want 1", spec.rollbacks)
+		}
+		spec.speculate(b4)
+		get := srv.awaitExecution(cmdID{1, 5}, false)
+		spec.Exec(b3)
+		spec.Exec(b4)
+		if res := <-get; string(res.result) != "2" {
+			t.Errorf("the get returned %q, want %q", res.result, "2")
+		}
+
+		// the state equals the state of a replica that only executed the committed blocks.
+		reference := kvstore.New()
+		refSrv, _ := newServer(reference, false)
+		for _, block := range []*consensus.Block{b1, b3, b4} {
+			refSrv.Exec(block)
+		}
+		got, err := store.Snapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := reference.Snapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("the speculating store has the state %q, want %q", got, want)
+		}
+		if value, _ := store.Query([]byte("y")); len(value) != 0 {
+			t.Errorf("the rolled back value of y is %q", value)
+		}
+		gotState, _ := srv.state(false)
+		wantState, _ := refSrv.state(false)
+		if gotState.GetHeight() != wantState.GetHeight() || !bytes.Equal(gotState.GetHash(), wantState.GetHash()) {
+			t.Errorf("the speculating replica executed %d commands, want %d", gotState.GetHeight(), wantState.GetHeight())
+		}
+	})
+
+	t.Run("Replicas", func(t *testing.T) {
+		const commands = 200
+		stores := make([]*kvstore.Store, 4)
+		replicas, clientAddrs, _ := startConfiguredNetwork(t, 4, func(conf *Config) {
+			stores[conf.ID-1] = kvstore.New()
+			conf.Application = stores[conf.ID-1]
+			conf.Speculate = true
+			conf.BatchSize = 4
+		})
+		infos := make([]backend.ReplicaInfo, len(clientAddrs))
+		for i, addr := range clientAddrs {
+			infos[i] = backend.ReplicaInfo{ID: hotstuff.ID(i + 1), Address: addr}
+		}
+		cli := client.New(client.Config{
+			ID:             1,
+			PayloadSize:    1,
+			Input:          io.NopCloser(bytes.NewReader(make([]byte, commands))),
+			MaxConcurrent:  32,
+			RateLimit:      math.Inf(1),
+			KVKeys:         10,
+			KVReadRatio:    0.3,
+			KVDeleteRatio:  0.1,
+			PayloadSeed:    1,
+			ManagerOptions: []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+		}, modules.NewBuilder(1))
+		if err := cli.Connect(infos); err != nil {
+			t.Fatal(err)
+		}
+		cli.Start()
+		defer cli.Stop()
+
+		for i, r := range replicas {
+			if r.clientSrv.speculation == nil {
+				t.Fatalf("replica %d does not speculate", i+1)
+			}
+			waitFor(t, fmt.Sprintf("replica %d to execute the commands", i+1), func() bool {
+				r.clientSrv.mut.Lock()
+				defer r.clientSrv.mut.Unlock()
+				return r.clientSrv.height >= commands
+			})
+		}
+		for i, store := range stores[1:] {
+			if !bytes.Equal(store.StateHash(), stores[0].StateHash()) {
+				t.Errorf("replica %d has a different state than replica 1", i+2)
+			}
+		}
+	})
+}
+
+// tagObserver records the tags that the replicas echoed to a client.
+type tagObserver struct {
+	mut  sync.Mutex
+	tags [][]byte
+}
+
+func (o *tagObserver) InitModule(mods *modules.Modules) {
+	mods.EventLoop().RegisterHandler(client.LatencyMeasurementEvent{}, func(event interface{}) {
+		o.mut.Lock()
+		defer o.mut.Unlock()
+		o.tags = append(o.tags, event.(client.LatencyMeasurementEvent).Tag)
+	})
+}
+
+func (o *tagObserver) echoed() [][]byte {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	return append([][]byte(nil), o.tags...)
+}
+
+func TestCommandTags(t *testing.T) {
+	const n = 4
+	replicas, clientAddrs, stop := startNetwork(t, n, false)
+	infos := make([]backend.ReplicaInfo, n)
+	for i, addr := range clientAddrs {
+		infos[i] = backend.ReplicaInfo{ID: hotstuff.ID(i + 1), Address: addr}
+	}
+
+	// the odd commands are tagged, and the even commands are not.
+	tagOf := func(seq uint64) []byte {
+		if seq%2 == 0 {
+			return nil
+		}
+		return []byte(fmt.Sprintf("trace-%d", seq))
+	}
+	observer := &tagObserver{}
+	builder := modules.NewBuilder(1)
+	builder.Register(observer)
+	cli := client.New(client.Config{
+		ID:             1,
+		MaxConcurrent:  100,
+		PayloadSize:    1,
+		Input:          io.NopCloser(bytes.NewReader(make([]byte, 10000))),
+		ManagerOptions: []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+		LoadMode:       client.FixedLoad,
+		TargetRate:     100,
+		Tagger: func(session hotstuff.ID, seq uint64) []byte {
+			if session != 1 {
+				t.Errorf("tagger called for session %d, want 1", session)
+			}
+			return tagOf(seq)
+		},
+	}, builder)
+	if err := cli.Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	cli.Start()
+	waitFor(t, "the client to receive acknowledgements", func() bool { return len(observer.echoed()) >= 20 })
+	cli.Stop()
+	stop()
+
+	tagged := 0
+	for _, tag := range observer.echoed() {
+		if tag == nil {
+			continue
+		}
+		tagged++
+		if !bytes.HasPrefix(tag, []byte("trace-")) {
+			t.Errorf("the replicas echoed the tag %q", tag)
+		}
+	}
+	if tagged == 0 {
+		t.Error("the replicas did not echo any tags")
+	}
+
+	// the tags are carried in the committed blocks.
+	r := replicas[0]
+	committed := 0
+	for block := r.hs.Consensus().CommittedBlock(); block.View() > 0; {
+		cmds, err := ClientCommands(block.Command())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, cmd := range cmds {
+			committed++
+			if want := tagOf(cmd.SequenceNumber); !bytes.Equal(cmd.Tag, want) {
+				t.Errorf("command %d was committed with the tag %q, want %q", cmd.SequenceNumber, cmd.Tag, want)
+			}
+		}
+		var ok bool
+		if block, ok = r.hs.BlockChain().LocalGet(block.Parent()); !ok {
+			break
+		}
+	}
+	if committed == 0 {
+		t.Error("no commands were committed")
+	}
+}
+
+func TestTagIsCoveredByBlockHash(t *testing.T) {
+	blockWithTag := func(tag []byte) *consensus.Block {
+		cmd, err := proto.Marshal(&clientpb.Batch{Commands: []*clientpb.Command{{ClientID: 1, SequenceNumber: 1, Tag: tag}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return consensus.NewBlock(consensus.GetGenesis().Hash(), consensus.QuorumCert{}, consensus.Command(cmd), 1, 1)
+	}
+	if blockWithTag([]byte("a")).Hash() == blockWithTag([]byte("b")).Hash() {
+		t.Error("blocks that differ only in the tags of their commands have the same hash")
+	}
+}
+
+// hookCounter counts the runs of its shutdown hook.
+type hookCounter struct {
+	runs int32
+}
+
+func (h *hookCounter) InitModule(mods *modules.Modules) {
+	mods.EventLoop().RegisterShutdownHook(func() interface{} {
+		atomic.AddInt32(&h.runs, 1)
+		return nil
+	})
+}
+
+func TestShutdown(t *testing.T) {
+	const n = 4
+	var buf bytes.Buffer
+	wr := bufio.NewWriter(&buf)
+	logger, err := modules.NewJSONLogger(wr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counters := make([]*hookCounter, n)
+	replicas, clientAddrs, _ := startNetworkWithModules(t, n, func(conf *Config, builder *consensus.Builder) {
+		// the command is never proposed, as the batch is never full.
+		conf.BatchSize = 100
+		counters[conf.ID-1] = &hookCounter{}
+		builder.Register(counters[conf.ID-1])
+		if conf.ID == 1 {
+			// the ticker does not tick during the test, so the throughput is only measured when the replica shuts down.
+			builder.Register(logger, &metrics.Throughput{}, metrics.NewTicker(time.Hour))
+		}
+	})
+
+	mgr := clientpb.NewManager(
+		gorums.WithDialTimeout(time.Second),
+		gorums.WithGrpcDialOptions(grpc.WithBlock(), grpc.WithInsecure()),
+	)
+	defer mgr.Close()
+	cfg, err := mgr.NewConfiguration(qspec{}, gorums.WithNodeList(clientAddrs[:1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	promise := cfg.ExecCommand(context.Background(), &clientpb.Command{ClientID: 1, SequenceNumber: 1})
+	waitFor(t, "the command to be received", func() bool {
+		replicas[0].clientSrv.mut.Lock()
+		defer replicas[0].clientSrv.mut.Unlock()
+		return len(replicas[0].clientSrv.awaitingCmds) > 0
+	})
+
+	errs := make(chan error, n)
+	for _, r := range replicas {
+		go func(r *Replica) { errs <- r.Shutdown(5 * time.Second) }(r)
+	}
+	// the command that was waiting to be executed fails, and the client disconnects such that the server can drain.
+	if _, err := promise.Get(); err == nil {
+		t.Error("the command succeeded, but the replica was shut down before it was executed")
+	}
+	mgr.Close()
+	for range replicas {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+	for _, r := range replicas {
+		if err := r.Shutdown(5 * time.Second); err != nil {
+			t.Error(err)
+		}
+		r.Stop()
+	}
+	for i, c := range counters {
+		if runs := atomic.LoadInt32(&c.runs); runs != 1 {
+			t.Errorf("the shutdown hook of replica %d ran %d times, want 1", i+1, runs)
+		}
+	}
+
+	// the metrics were flushed by the shutdown, and end with the final measurement and the shutdown marker.
+	var entries []struct {
+		Type string `json:"@type"`
+	}
+	if err := json.Unmarshal(append(buf.Bytes(), ']'), &entries); err != nil {
+		t.Fatalf("failed to parse the flushed metrics: %v", err)
+	}
+	var logged []string
+	for _, entry := range entries {
+		logged = append(logged, strings.TrimPrefix(entry.Type, "type.googleapis.com/"))
+	}
+	want := []string{"types.ThroughputMeasurement", "types.ShutdownEvent"}
+	if strings.Join(logged, ",") != strings.Join(want, ",") {
+		t.Errorf("got metrics %v, want %v", logged, want)
+	}
+}
+
+// voteCounter counts the votes that a replica receives from another replica.
+type voteCounter struct {
+	from  hotstuff.ID
+	votes int32
+}
+
+func (c *voteCounter) InitModule(mods *modules.Modules) {
+	mods.EventLoop().RegisterObserver(consensus.VoteMsg{}, func(event interface{}) {
+		if event.(consensus.VoteMsg).ID == c.from {
+			atomic.AddInt32(&c.votes, 1)
+		}
+	})
+}
+
+// transferCounter counts the state transfers of a replica.
+type transferCounter struct {
+	transfers int32
+}
+
+func (c *transferCounter) InitModule(mods *modules.Modules) {
+	mods.EventLoop().RegisterObserver(StateTransferred{}, func(_ interface{}) {
+		atomic.AddInt32(&c.transfers, 1)
+	})
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	builder := consensus.NewBuilder(1, nil)
+	srv := newClientServer(Config{BatchSize: 1, Application: kvstore.New()}, nil)
+	builder.Register(srv, srv.cmdCache)
+	builder.Build()
+	cmd, err := proto.Marshal(&clientpb.Batch{Commands: []*clientpb.Command{
+		{ClientID: 1, SequenceNumber: 1, Data: kvstore.Put("x", []byte("1"))},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Exec(consensus.NewBlock(consensus.GetGenesis().Hash(), consensus.QuorumCert{}, consensus.Command(cmd), 1, 1))
+	snapshot, err := srv.snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newClientServer(Config{BatchSize: 1, Application: kvstore.New()}, nil)
+	if err := restored.restore(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if restored.height != srv.height || !bytes.Equal(restored.hash.Sum(nil), srv.hash.Sum(nil)) {
+		t.Error("the restored height or hash differs")
+	}
+	if !restored.cmdCache.isExecuted(cmdID{1, 1}) {
+		t.Error("the restored replica does not know that the command was executed")
+	}
+	if value, _ := restored.app.(*kvstore.Store).Query([]byte("x")); string(value) != "1" {
+		t.Errorf("the restored application has x=%q, want %q", value, "1")
+	}
+	if again, err := restored.snapshot(); err != nil || !bytes.Equal(again, snapshot) {
+		t.Error("the snapshot of the restored state differs")
+	}
+	if err := restored.restore([]byte("garbage")); err == nil {
+		t.Error("a malformed snapshot was restored")
+	}
+}
+
+func TestStateTransfer(t *testing.T) {
+	const n = 4
+	keys := testutil.GenerateKeys(t, n, testutil.GenerateECDSAKey)
+	stores := make([]*kvstore.Store, n)
+	votes := &voteCounter{from: n}
+	transfers := &transferCounter{}
+	configure := func(conf *Config, builder *consensus.Builder) {
+		stores[conf.ID-1] = kvstore.New()
+		conf.Application = stores[conf.ID-1]
+		conf.SnapshotInterval = 10
+		// the replicas reconnect quickly to the replica that is restarted.
+		conf.DialOptions = []grpc.DialOption{grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.Config{BaseDelay: 10 * time.Millisecond, Multiplier: 1.6, MaxDelay: 100 * time.Millisecond},
+			MinConnectTimeout: time.Second,
+		})}
+		// the leader is fixed, such that the views do not time out while replica 4 is down.
+		builder.Register(leaderrotation.NewFixed(1))
+		switch conf.ID {
+		case 1:
+			builder.Register(votes)
+		case n:
+			builder.Register(transfers)
+		}
+	}
+	replicas := make([]*Replica, n)
+	infos := make([]backend.ReplicaInfo, n)
+	clientAddrs := make([]string, n)
+	for i := range replicas {
+		replicas[i], infos[i], clientAddrs[i] = newTestReplica(t, hotstuff.ID(i+1), keys[i], "127.0.0.1:0", configure)
+	}
+	for _, r := range replicas {
+		if err := r.Connect(infos); err != nil {
+			t.Fatal(err)
+		}
+		r.Start()
+	}
+	t.Cleanup(func() {
+		for _, r := range replicas {
+			r.Stop()
+		}
+	})
+
+	mgr := clientpb.NewManager(
+		gorums.WithDialTimeout(time.Second),
+		gorums.WithGrpcDialOptions(grpc.WithBlock(), grpc.WithInsecure()),
+	)
+	defer mgr.Close()
+	cfg, err := mgr.NewConfiguration(qspec{}, gorums.WithNodeList(clientAddrs[:1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// each command sets a new key, such that the state grows while replica 4 is down.
+	var written uint64
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(2 * time.Millisecond)
+		defer ticker.Stop()
+		for i := uint64(1); ; i++ {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			cfg.ExecCommand(ctx, &clientpb.Command{ClientID: 1, SequenceNumber: i, Data: kvstore.Put(fmt.Sprint("k", i), []byte("v"))})
+			atomic.StoreUint64(&written, i)
+		}
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	committedView := func(r *Replica) consensus.View { return r.hs.Consensus().CommittedBlock().View() }
+	hasKey := func(store *kvstore.Store, i uint64) bool {
+		value, _ := store.Query([]byte(fmt.Sprint("k", i)))
+		return len(value) > 0
+	}
+
+	waitFor(t, "replica 4 to commit", func() bool { return committedView(replicas[n-1]) >= 5 })
+	// the state of replica 4 is wiped by replacing it with a new replica that has the same key and address.
+	replicas[n-1].Stop()
+	missed := atomic.LoadUint64(&written) + 1
+	downView := committedView(replicas[0])
+	waitFor(t, "the other replicas to commit without replica 4", func() bool {
+		return committedView(replicas[0]) > downView+50 && hasKey(stores[0], missed)
+	})
+	replicas[n-1], _, _ = newTestReplica(t, n, keys[n-1], infos[n-1].Address, configure)
+	if err := replicas[n-1].Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	replicas[n-1].Start()
+
+	waitFor(t, "replica 4 to install a snapshot", func() bool { return atomic.LoadInt32(&transfers.transfers) > 0 })
+	if !hasKey(stores[n-1], missed) {
+		t.Errorf("the state of replica 4 lacks k%d, which was committed while it was down", missed)
+	}
+	// replica 4 follows the chain from the snapshot, and votes for the new proposals.
+	votesBefore := atomic.LoadInt32(&votes.votes)
+	later := atomic.LoadUint64(&written) + 10
+	waitFor(t, "replica 4 to execute commands after the snapshot", func() bool { return hasKey(stores[n-1], later) })
+	waitFor(t, "replica 4 to vote", func() bool { return atomic.LoadInt32(&votes.votes) > votesBefore })
+	if transfers := atomic.LoadInt32(&transfers.transfers); transfers != 1 {
+		t.Errorf("replica 4 installed %d snapshots, want 1", transfers)
+	}
+}
+
+func getStatus(t *testing.T, url string) (code int, body []byte) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, body
+}
+
+func TestStatusServer(t *testing.T) {
+	const commands = 50
+	moduleNames := map[string]string{"consensus": "chainedhotstuff", "crypto": "ecdsa"}
+	replicas, clientAddrs, _ := startConfiguredNetwork(t, 4, func(conf *Config) {
+		conf.StatusAddress = "127.0.0.1:0"
+		conf.Modules = moduleNames
+	})
+	addr, err := replicas[0].ListenStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	statusURL := "http://" + addr.String() + "/status"
+	healthURL := "http://" + addr.String() + "/healthz"
+
+	infos := make([]backend.ReplicaInfo, len(clientAddrs))
+	for i, addr := range clientAddrs {
+		infos[i] = backend.ReplicaInfo{ID: hotstuff.ID(i + 1), Address: addr}
+	}
+	cli := client.New(client.Config{
+		ID:             1,
+		PayloadSize:    1,
+		Input:          io.NopCloser(bytes.NewReader(make([]byte, commands))),
+		MaxConcurrent:  8,
+		RateLimit:      math.Inf(1),
+		ManagerOptions: []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+	}, modules.NewBuilder(1))
+	if err := cli.Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	cli.Start()
+	defer cli.Stop()
+
+	var status Status
+	waitFor(t, "the status to report executed commands", func() bool {
+		code, body := getStatus(t, statusURL)
+		if code != http.StatusOK {
+			t.Fatalf("GET /status returned %d: %s", code, body)
+		}
+		if err := json.Unmarshal(body, &status); err != nil {
+			t.Fatal(err)
+		}
+		return status.ExecutedCommands > 0 && status.Healthy
+	})
+
+	// check the schema of the response.
+	_, body := getStatus(t, statusURL)
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		t.Fatal(err)
+	}
+	schema := map[string]string{
+		"id": "number", "view": "number", "high_qc_view": "number", "committed_view": "number",
+		"committed_block": "string", "executed_commands": "number", "state_hash": "string",
+		"last_view_change": "string", "view_timeout_ms": "number", "event_queue": "number",
+		"execution_queue": "number", "peers": "array", "healthy": "bool", "modules": "object", "version": "string", "go_version": "string",
+		"events": "object",
+	}
+	for field, kind := range schema {
+		value, ok := fields[field]
+		if !ok {
+			t.Errorf("missing field %s", field)
+			continue
+		}
+		var got string
+		switch value.(type) {
+		case float64:
+			got = "number"
+		case string:
+			got = "string"
+		case bool:
+			got = "bool"
+		case []interface{}:
+			got = "array"
+		case map[string]interface{}:
+			got = "object"
+		}
+		if got != kind {
+			t.Errorf("field %s is a %s, want %s", field, got, kind)
+		}
+	}
+	if status.ID != 1 || status.View == 0 || status.HighQCView == 0 || status.CommittedView == 0 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if len(status.CommittedBlock) != 64 || len(status.StateHash) != 64 {
+		t.Errorf("unexpected hashes: %s %s", status.CommittedBlock, status.StateHash)
+	}
+	if len(status.Peers) != 3 {
+		t.Fatalf("got %d peers, want 3", len(status.Peers))
+	}
+	for i, peer := range status.Peers {
+		if peer.ID != hotstuff.ID(i+2) || !peer.Connected || !peer.Reachable {
+			t.Errorf("unexpected peer status: %+v", peer)
+		}
+	}
+	if status.Modules["consensus"] != "chainedhotstuff" || status.Modules["crypto"] != "ecdsa" {
+		t.Errorf("unexpected modules: %v", status.Modules)
+	}
+	if commits := status.Events["consensus.CommitEvent"]; commits.Count == 0 || commits.Max <= 0 {
+		t.Errorf("unexpected statistics of the commit events: %+v", commits)
+	}
+	if code, body := getStatus(t, healthURL); code != http.StatusOK {
+		t.Errorf("GET /healthz returned %d: %s", code, body)
+	}
+
+	// without a quorum, the replica is no longer participating.
+	replicas[2].Stop()
+	replicas[3].Stop()
+	waitFor(t, "the replica to become unhealthy", func() bool {
+		code, _ := getStatus(t, healthURL)
+		return code == http.StatusServiceUnavailable
+	})
+}
+
+// commitStreamClient connects to the commit stream server on the given address.
+func commitStreamClient(t *testing.T, addr net.Addr) commitpb.CommitStreamClient {
+	t.Helper()
+	conn, err := grpc.Dial(addr.String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return commitpb.NewCommitStreamClient(conn)
+}
+
+// receiveCommits subscribes to the committed blocks from the given view, and disconnects after receiving n blocks.
+func receiveCommits(t *testing.T, cli commitpb.CommitStreamClient, from uint64, n int) []*commitpb.Block {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := cli.SubscribeCommits(ctx, &commitpb.SubscribeRequest{FromView: from})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blocks []*commitpb.Block
+	for len(blocks) < n {
+		block, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("failed to receive block %d of %d: %v", len(blocks)+1, n, err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// startCommandClient starts a client that sends the given number of commands to the replicas.
+func startCommandClient(t *testing.T, clientAddrs []string, commands, payloadSize int) {
+	t.Helper()
+	startTLSCommandClient(t, clientAddrs, commands, payloadSize, nil)
+}
+
+// startTLSCommandClient is like startCommandClient, but connects with TLS to replicas whose certificates
+// are signed by roots, unless roots is nil.
+func startTLSCommandClient(t *testing.T, clientAddrs []string, commands, payloadSize int, roots *x509.CertPool) {
+	t.Helper()
+	infos := make([]backend.ReplicaInfo, len(clientAddrs))
+	for i, addr := range clientAddrs {
+		infos[i] = backend.ReplicaInfo{ID: hotstuff.ID(i + 1), Address: addr}
+	}
+	cli := client.New(client.Config{
+		ID:             1,
+		PayloadSize:    uint32(payloadSize),
+		Input:          io.NopCloser(bytes.NewReader(make([]byte, commands*payloadSize))),
+		MaxConcurrent:  8,
+		RateLimit:      math.Inf(1),
+		TLS:            roots != nil,
+		RootCAs:        roots,
+		ManagerOptions: []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+	}, modules.NewBuilder(1))
+	if err := cli.Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	cli.Start()
+	t.Cleanup(cli.Stop)
+}
+
+func TestCommitStream(t *testing.T) {
+	const blocksPerSubscription = 20
+	replicas, clientAddrs, _ := startConfiguredNetwork(t, 4, func(conf *Config) {
+		conf.CommitStreamAddress = "127.0.0.1:0"
+	})
+	addr, err := replicas[0].ListenCommitStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	startCommandClient(t, clientAddrs, 1000, 1)
+
+	// subscribe after the replicas have committed some blocks, which are read from the blockchain.
+	waitFor(t, "the replica to commit commands", func() bool {
+		_, committed := replicas[0].Progress()
+		return committed >= 10
+	})
+	blocks := receiveCommits(t, commitStreamClient(t, addr), 0, blocksPerSubscription)
+	// resume from the view after the last block that was received, on a new connection.
+	last := blocks[len(blocks)-1]
+	blocks = append(blocks, receiveCommits(t, commitStreamClient(t, addr), last.GetView()+1, blocksPerSubscription)...)
+
+	genesis := consensus.GetGenesis().Hash()
+	parent, view := genesis[:], uint64(0)
+	commands := 0
+	for _, block := range blocks {
+		if block.GetView() <= view {
+			t.Fatalf("received block of view %d after view %d", block.GetView(), view)
+		}
+		if !bytes.Equal(block.GetParent(), parent) {
+			t.Fatalf("the parent of the block of view %d is not the previous block", block.GetView())
+		}
+		if !bytes.Equal(block.GetQC().GetHash(), block.GetParent()) {
+			t.Errorf("the QC of the block of view %d does not certify its parent", block.GetView())
+		}
+		for _, cmd := range block.GetCommands() {
+			if cmd.GetClientID() != 1 {
+				t.Errorf("unexpected command: %v", cmd)
+			}
+			commands++
+		}
+		parent, view = block.GetHash(), block.GetView()
+	}
+	if commands == 0 {
+		t.Error("the blocks carried no commands")
+	}
+}
+
+func TestCommitStreamSlowSubscriber(t *testing.T) {
+	replicas, clientAddrs, _ := startConfiguredNetwork(t, 4, func(conf *Config) {
+		conf.CommitStreamAddress = "127.0.0.1:0"
+		conf.CommitStreamBuffer = 1
+	})
+	addr, err := replicas[0].ListenCommitStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	stream, err := commitStreamClient(t, addr).SubscribeCommits(ctx, &commitpb.SubscribeRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the blocks are large enough that the stream is blocked by flow control after a few of them,
+	// since the subscriber does not read them.
+	startCommandClient(t, clientAddrs, 1000, 1<<10)
+	waitFor(t, "the replica to commit commands", func() bool {
+		_, committed := replicas[0].Progress()
+		return committed >= 500
+	})
+	// the replica keeps committing while the subscriber is behind, and the subscriber is disconnected.
+	for {
+		_, err := stream.Recv()
+		if err == nil {
+			continue
+		}
+		if code := status.Code(err); code != codes.ResourceExhausted {
+			t.Fatalf("the stream was closed with %v, want %v", err, codes.ResourceExhausted)
+		}
+		return
+	}
+}
+
+// serveCounter serves the counter on the unix domain socket until the returned function is called.
+func serveCounter(t *testing.T, app *counter.Counter, addr string) (stop func()) {
+	t.Helper()
+	transport, _ := backend.GetTransport("")
+	lis, err := transport.Listen(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	apppb.RegisterApplicationServer(srv, app)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return srv.Stop
+}
+
+func TestExternalApplication(t *testing.T) {
+	const n = 4
+	dir := t.TempDir()
+	apps := make([]*counter.Counter, n)
+	addrs := make([]string, n)
+	stops := make([]func(), n)
+	for i := range apps {
+		apps[i] = counter.New(counter.Config{})
+		addrs[i] = backend.UnixScheme + filepath.Join(dir, fmt.Sprintf("app-%d.sock", i+1))
+		stops[i] = serveCounter(t, apps[i], addrs[i])
+	}
+	_, clientAddrs, _ := startConfiguredNetwork(t, n, func(conf *Config) {
+		conf.ApplicationAddress = addrs[conf.ID-1]
+	})
+
+	mgr := clientpb.NewManager(
+		gorums.WithDialTimeout(time.Second),
+		gorums.WithGrpcDialOptions(grpc.WithBlock(), grpc.WithInsecure()),
+	)
+	defer mgr.Close()
+	cfg, err := mgr.NewConfiguration(qspec{}, gorums.WithNodeList(clientAddrs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// the commands are not committed until they are followed by more proposals, so the client keeps sending.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(2 * time.Millisecond)
+		defer ticker.Stop()
+		for i := uint64(1); ; i++ {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			cfg.ExecCommand(ctx, &clientpb.Command{ClientID: 1, SequenceNumber: i, Data: []byte(fmt.Sprint(i))})
+		}
+	}()
+
+	waitFor(t, "the applications to execute commands", func() bool {
+		for _, app := range apps {
+			if app.Commands() < 10 {
+				return false
+			}
+		}
+		return true
+	})
+
+	// the replica keeps ordering commands while its application is unreachable, but does not execute them.
+	stops[0]()
+	stalled := apps[0].Height()
+	target := apps[1].Height() + 10
+	waitFor(t, "the other applications to execute more blocks", func() bool {
+		return apps[1].Height() >= target
+	})
+	if height := apps[0].Height(); height != stalled {
+		t.Errorf("unreachable application executed blocks %d to %d", stalled+1, height)
+	}
+
+	// when the application is reachable again, the blocks that were committed meanwhile are executed in order.
+	serveCounter(t, apps[0], addrs[0])
+	target = apps[1].Height()
+	waitFor(t, "the application to catch up", func() bool {
+		return apps[0].Height() >= target
+	})
+
+	cancel()
+	wg.Wait()
+	waitFor(t, "the applications to execute the same blocks", func() bool {
+		for _, app := range apps[1:] {
+			if app.Height() != apps[0].Height() || !bytes.Equal(app.Hash(), apps[0].Hash()) {
+				return false
+			}
+		}
+		return true
+	})
+	if apps[0].Commands() != apps[1].Commands() {
+		t.Errorf("applications executed %d and %d commands", apps[0].Commands(), apps[1].Commands())
+	}
+}
+
+func TestRejectInvalidCommands(t *testing.T) {
+	builder := consensus.NewBuilder(1, nil)
+	srv := newClientServer(Config{BatchSize: 1, Application: kvstore.New()}, nil)
+	builder.Register(srv, srv.cmdCache)
+	builder.Build()
+
+	invalid := &clientpb.Command{ClientID: 1, SequenceNumber: 1, Data: append(kvstore.Get("x"), "value"...)}
+	c, _ := srv.submit(invalid)
+	select {
+	case res := <-c:
+		if status.Code(res.err) != codes.InvalidArgument {
+			t.Errorf("submitting an invalid command failed with %v, want InvalidArgument", res.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the invalid command was not rejected")
+	}
+	if srv.cmdCache.len() != 0 {
+		t.Error("the invalid command was added to the command cache")
+	}
+
+	// a leader whose application does not validate the commands proposes the invalid command.
+	leader := newCmdCache(1)
+	leaderBuilder := modules.NewBuilder(2)
+	leaderBuilder.Register(leader)
+	leaderBuilder.Build()
+	leader.addCommand(invalid, true)
+	leader.addCommand(&clientpb.Command{ClientID: 2, SequenceNumber: 1, Data: kvstore.Put("x", []byte("1"))}, true)
+	batch, ok := leader.Get(context.Background())
+	if !ok {
+		t.Fatal("leader did not return a batch")
+	}
+	if srv.cmdCache.Validate(batch) == nil {
+		t.Error("a batch with an invalid command should not be valid")
+	}
+}
+
+func TestKVStoreStateHashes(t *testing.T) {
+	const n = 4
+	stores := make([]*kvstore.Store, n)
+	replicas, clientAddrs, _ := startConfiguredNetwork(t, n, func(conf *Config) {
+		stores[conf.ID-1] = kvstore.New()
+		conf.Application = stores[conf.ID-1]
+	})
+	infos := make([]backend.ReplicaInfo, n)
+	for i := range infos {
+		infos[i] = backend.ReplicaInfo{ID: hotstuff.ID(i + 1), Address: clientAddrs[i]}
+	}
+
+	cli := client.New(client.Config{
+		ID:                   1,
+		ManagerOptions:       []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+		MaxConcurrent:        100,
+		PayloadSize:          8,
+		Input:                client.NewPayloadReader(1, false),
+		LoadMode:             client.FixedLoad,
+		TargetRate:           500,
+		Workload:             client.KVWorkload,
+		KVKeys:               20,
+		KVReadRatio:          0.2,
+		KVDeleteRatio:        0.2,
+		KVCheckpointInterval: 10,
+	}, modules.NewBuilder(1))
+	if err := cli.Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	cli.Run(ctx)
+
+	// the replicas have the same state once they have executed the same commands.
+	height := func(r *Replica) uint64 {
+		r.clientSrv.mut.Lock()
+		defer r.clientSrv.mut.Unlock()
+		return r.clientSrv.height
+	}
+	waitFor(t, "the replicas to execute the same commands", func() bool {
+		for _, r := range replicas[1:] {
+			if height(r) != height(replicas[0]) {
+				return false
+			}
+		}
+		return height(replicas[0]) > 0
+	})
+	for i, store := range stores[1:] {
+		if !bytes.Equal(store.StateHash(), stores[0].StateHash()) {
+			t.Errorf("the state hash of replica %d is %.8x, but the state hash of replica 1 is %.8x", i+2, store.StateHash(), stores[0].StateHash())
+		}
+	}
+
+	// the checkpoints record the same state hashes at the same points in the order of the commands.
+	checkpoints := stores[0].Checkpoints()
+	if len(checkpoints) == 0 {
+		t.Fatal("no checkpoints were executed")
+	}
+	for i, store := range stores[1:] {
+		other := store.Checkpoints()
+		if len(other) != len(checkpoints) {
+			t.Fatalf("replica %d executed %d checkpoints, but replica 1 executed %d", i+2, len(other), len(checkpoints))
+		}
+		for j, checkpoint := range other {
+			if checkpoint.Index != checkpoints[j].Index || !bytes.Equal(checkpoint.Hash, checkpoints[j].Hash) {
+				t.Errorf("checkpoint %d of replica %d is %d:%.8x, but replica 1 recorded %d:%.8x",
+					j, i+2, checkpoint.Index, checkpoint.Hash, checkpoints[j].Index, checkpoints[j].Hash)
+			}
+		}
+	}
+}
+
+// snapshotObserver records the durable snapshots that a replica saves and recovers from.
+type snapshotObserver struct {
+	saved     int32
+	lastSaved uint64 // the view of the latest saved snapshot
+	recovered uint64 // the view of the snapshot that the replica recovered from
+}
+
+func (o *snapshotObserver) InitModule(mods *modules.Modules) {
+	mods.EventLoop().RegisterObserver(SnapshotSaved{}, func(event interface{}) {
+		atomic.StoreUint64(&o.lastSaved, uint64(event.(SnapshotSaved).Block.View()))
+		atomic.AddInt32(&o.saved, 1)
+	})
+	mods.EventLoop().RegisterObserver(Recovered{}, func(event interface{}) {
+		atomic.StoreUint64(&o.recovered, uint64(event.(Recovered).Block.View()))
+	})
+}
+
+func TestDurableSnapshotRecovery(t *testing.T) {
+	const n = 4
+	keys := testutil.GenerateKeys(t, n, testutil.GenerateECDSAKey)
+	stores := make([]*kvstore.Store, n)
+	dirs := make([]string, n)
+	for i := range dirs {
+		dirs[i] = t.TempDir()
+	}
+	observer := &snapshotObserver{}
+	configure := func(conf *Config, builder *consensus.Builder) {
+		stores[conf.ID-1] = kvstore.New()
+		conf.Application = stores[conf.ID-1]
+		conf.DataDir = dirs[conf.ID-1]
+		conf.Snapshotter = kvstore.NewFileSnapshotter(stores[conf.ID-1], dirs[conf.ID-1])
+		// the other replicas snapshot rarely, such that they keep the blocks after the snapshots of replica 4.
+		conf.DurableSnapshotViews = 1000
+		if conf.ID == n {
+			conf.DurableSnapshotViews = 20
+		}
+		conf.DialOptions = []grpc.DialOption{grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.Config{BaseDelay: 10 * time.Millisecond, Multiplier: 1.6, MaxDelay: 100 * time.Millisecond},
+			MinConnectTimeout: time.Second,
+		})}
+		// the leader is fixed, such that the views do not time out while replica 4 is down.
+		builder.Register(leaderrotation.NewFixed(1))
+		if conf.ID == n {
+			builder.Register(observer)
+		}
+	}
+	replicas := make([]*Replica, n)
+	infos := make([]backend.ReplicaInfo, n)
+	clientAddrs := make([]string, n)
+	for i := range replicas {
+		replicas[i], infos[i], clientAddrs[i] = newTestReplica(t, hotstuff.ID(i+1), keys[i], "127.0.0.1:0", configure)
+	}
+	for _, r := range replicas {
+		if err := r.Connect(infos); err != nil {
+			t.Fatal(err)
+		}
+		r.Start()
+	}
+	t.Cleanup(func() {
+		for _, r := range replicas {
+			r.Stop()
+		}
+	})
+
+	mgr := clientpb.NewManager(
+		gorums.WithDialTimeout(time.Second),
+		gorums.WithGrpcDialOptions(grpc.WithBlock(), grpc.WithInsecure()),
+	)
+	defer mgr.Close()
+	cfg, err := mgr.NewConfiguration(qspec{}, gorums.WithNodeList(clientAddrs[:1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written uint64
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(2 * time.Millisecond)
+		defer ticker.Stop()
+		for i := uint64(1); ; i++ {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			cfg.ExecCommand(ctx, &clientpb.Command{ClientID: 1, SequenceNumber: i, Data: kvstore.Put(fmt.Sprint("k", i), []byte("v"))})
+			atomic.StoreUint64(&written, i)
+		}
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	committedView := func(r *Replica) consensus.View { return r.hs.Consensus().CommittedBlock().View() }
+	hasKey := func(store *kvstore.Store, i uint64) bool {
+		value, _ := store.Query([]byte(fmt.Sprint("k", i)))
+		return len(value) > 0
+	}
+
+	waitFor(t, "replica 4 to save two snapshots", func() bool { return atomic.LoadInt32(&observer.saved) >= 2 })
+	replicas[n-1].Stop()
+	latest := atomic.LoadUint64(&observer.lastSaved)
+	if _, ok := replicas[n-1].hs.BlockChain().LocalGet(consensus.GetGenesis().Hash()); ok {
+		t.Error("replica 4 did not discard the blocks below its previous snapshot")
+	}
+	snapshots, err := filepath.Glob(filepath.Join(dirs[n-1], "kvstore-*.snap"))
+	if err != nil || len(snapshots) != 2 {
+		t.Errorf("replica 4 kept the snapshot files %v, want the latest 2", snapshots)
+	}
+	// the command is committed after the latest snapshot, so it must be executed from the chain.
+	missed := atomic.LoadUint64(&written) + 1
+	downView := committedView(replicas[0])
+	waitFor(t, "the other replicas to commit without replica 4", func() bool {
+		return committedView(replicas[0]) > downView+5 && hasKey(stores[0], missed)
+	})
+	// the restarted replica has a new, empty store, and only the data directory of the old replica.
+	replicas[n-1], _, _ = newTestReplica(t, n, keys[n-1], infos[n-1].Address, configure)
+	if recovered := atomic.LoadUint64(&observer.recovered); recovered != 0 {
+		t.Fatalf("replica 4 recovered before it was started")
+	}
+	if !hasKey(stores[n-1], 1) {
+		t.Error("the restored state of replica 4 lacks k1, which was committed before its snapshots")
+	}
+	if hasKey(stores[n-1], missed) {
+		t.Errorf("the restored state of replica 4 has k%d, which was committed after its latest snapshot", missed)
+	}
+	if err := replicas[n-1].Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	replicas[n-1].Start()
+
+	waitFor(t, "replica 4 to recover", func() bool { return atomic.LoadUint64(&observer.recovered) != 0 })
+	if recovered := atomic.LoadUint64(&observer.recovered); recovered != latest {
+		t.Errorf("replica 4 recovered from the snapshot of view %d, want the latest snapshot of view %d", recovered, latest)
+	}
+	waitFor(t, "replica 4 to execute the chain after its snapshot", func() bool { return hasKey(stores[n-1], missed) })
+
+	// once the load stops, the restarted replica reaches the same state as the others.
+	cancel()
+	wg.Wait()
+	waitFor(t, "replica 4 to reach the state of replica 1", func() bool {
+		return bytes.Equal(stores[n-1].StateHash(), stores[0].StateHash())
+	})
+}
+
+// equivocationCounter counts the equivocations that the replicas detect.
+type equivocationCounter struct {
+	equivocations uint64
+}
+
+func (c *equivocationCounter) InitModule(mods *modules.Modules) {
+	mods.EventLoop().RegisterObserver(consensus.EquivocationEvent{}, func(_ interface{}) {
+		atomic.AddUint64(&c.equivocations, 1)
+	})
+}
+
+// TestRestartedLeaderDoesNotEquivocate checks that the leader of the first view, when it is restarted with its data
+// directory, resumes after the views that it voted in, instead of proposing a different block in the first view.
+func TestRestartedLeaderDoesNotEquivocate(t *testing.T) {
+	const n = 4
+	keys := testutil.GenerateKeys(t, n, testutil.GenerateECDSAKey)
+	dir := t.TempDir()
+	counter := &equivocationCounter{}
+	configure := func(conf *Config, builder *consensus.Builder) {
+		// replica 2 leads the first view under round-robin.
+		if conf.ID == 2 {
+			conf.DataDir = dir
+		} else {
+			builder.Register(counter)
+		}
+		conf.DialOptions = []grpc.DialOption{grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.Config{BaseDelay: 10 * time.Millisecond, Multiplier: 1.6, MaxDelay: 100 * time.Millisecond},
+			MinConnectTimeout: time.Second,
+		})}
+	}
+	replicas := make([]*Replica, n)
+	infos := make([]backend.ReplicaInfo, n)
+	clientAddrs := make([]string, n)
+	for i := range replicas {
+		replicas[i], infos[i], clientAddrs[i] = newTestReplica(t, hotstuff.ID(i+1), keys[i], "127.0.0.1:0", configure)
+	}
+	for _, r := range replicas {
+		if err := r.Connect(infos); err != nil {
+			t.Fatal(err)
+		}
+		r.Start()
+	}
+	t.Cleanup(func() {
+		for _, r := range replicas {
+			r.Stop()
+		}
+	})
+
+	mgr := clientpb.NewManager(
+		gorums.WithDialTimeout(time.Second),
+		gorums.WithGrpcDialOptions(grpc.WithBlock(), grpc.WithInsecure()),
+	)
+	defer mgr.Close()
+	// a command is not committed until it is followed by more proposals, so the commands are sent until load is stopped.
+	var sent uint64
+	load := func() (stop func()) {
+		cfg, err := mgr.NewConfiguration(qspec{}, gorums.WithNodeList(clientAddrs))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(5 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+				seq := atomic.AddUint64(&sent, 1)
+				cfg.ExecCommand(ctx, &clientpb.Command{ClientID: 1, SequenceNumber: seq, Data: []byte(fmt.Sprint(seq))})
+			}
+		}()
+		return func() {
+			cancel()
+			wg.Wait()
+		}
+	}
+	executed := func(r *Replica, seq uint64) bool {
+		return r.clientSrv.cmdCache.isExecuted(cmdID{clientID: 1, sequenceNum: seq})
+	}
+
+	stopLoad := load()
+	waitFor(t, "the first commands to be executed", func() bool { return executed(replicas[1], 8) })
+	stopLoad()
+	replicas[1].Stop()
+	replicas[1] = newUnstartedReplica(t, 2, keys[1], infos[1].Address, configure)
+	if view := replicas[1].hs.Synchronizer().View(); view <= 1 {
+		t.Fatalf("the restarted leader resumes in view %d, want a view after the ones it voted in", view)
+	}
+	_, clientAddr, err := replicas[1].Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientAddrs[1] = clientAddr.String()
+	if err := replicas[1].Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	replicas[1].Start()
+
+	// the restarted leader gets commands to propose, which it must not propose in the views that it voted in.
+	target := atomic.LoadUint64(&sent) + 10
+	stopLoad = load()
+	defer stopLoad()
+	waitFor(t, "the restarted leader to execute new commands", func() bool { return executed(replicas[1], target) })
+	if equivocations := atomic.LoadUint64(&counter.equivocations); equivocations != 0 {
+		t.Errorf("the other replicas detected %d equivocations by the restarted leader", equivocations)
+	}
+}
+
+// TestInconsistentDataDir checks that a replica whose data directory is inconsistent does not recover,
+// and refuses to listen for connections.
+func TestInconsistentDataDir(t *testing.T) {
+	const n = 4
+	keys := testutil.GenerateKeys(t, n, testutil.GenerateECDSAKey)
+	dir := t.TempDir()
+	observer := &snapshotObserver{}
+	configure := func(conf *Config, builder *consensus.Builder) {
+		if conf.ID == 1 {
+			store := kvstore.New()
+			conf.Application = store
+			conf.DataDir = dir
+			conf.Snapshotter = kvstore.NewFileSnapshotter(store, dir)
+			conf.DurableSnapshotViews = 5
+			builder.Register(observer)
+		}
+	}
+	_, clientAddrs, stop := startNetworkWithModules(t, n, configure)
+	startCommandClient(t, clientAddrs, 100, 16)
+	waitFor(t, "replica 1 to save a snapshot", func() bool { return atomic.LoadInt32(&observer.saved) >= 1 })
+	stop()
+
+	snapshots, err := filepath.Glob(filepath.Join(dir, "kvstore-*.snap"))
+	if err != nil || len(snapshots) == 0 {
+		t.Fatalf("replica 1 saved no snapshot of the application: %v", err)
+	}
+	sort.Strings(snapshots)
+	latest := snapshots[len(snapshots)-1]
+	safetyState, err := os.ReadFile(filepath.Join(dir, safetyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		corrupt func() error
+		want    string
+	}{
+		{"TruncatedSafetyState", func() error {
+			return os.WriteFile(filepath.Join(dir, safetyFile), safetyState[:3], 0o600)
+		}, "safety state"},
+		{"TruncatedApplicationSnapshot", func() error {
+			if err := os.WriteFile(filepath.Join(dir, safetyFile), safetyState, 0o600); err != nil {
+				return err
+			}
+			return os.Truncate(latest, 0)
+		}, "manifest records"},
+		{"MissingApplicationSnapshot", func() error {
+			return os.Remove(latest)
+		}, "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.corrupt(); err != nil {
+				t.Fatal(err)
+			}
+			r := newUnstartedReplica(t, 1, keys[0], "127.0.0.1:0", configure)
+			if err := r.RecoveryError(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got the recovery error %v, want one about the %s", err, tt.want)
+			}
+			if _, _, err := r.Listen(); err == nil {
+				t.Error("the replica listens, although it did not recover")
+			}
+		})
+	}
+}
+
+func TestPersistedCommands(t *testing.T) {
+	const (
+		n       = 4
+		first   = 10 // the commands that are proposed before the leader stalls
+		pending = 20 // the commands that the leader admits while it is stalled
+	)
+	keys := testutil.GenerateKeys(t, n, testutil.GenerateECDSAKey)
+	dir := t.TempDir()
+	configure := func(conf *Config, builder *consensus.Builder) {
+		if conf.ID == 1 {
+			conf.DataDir = dir
+			conf.PersistCommands = true
+			conf.CommandLogSync = SyncEveryCommand
+		}
+		conf.DialOptions = []grpc.DialOption{grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.Config{BaseDelay: 10 * time.Millisecond, Multiplier: 1.6, MaxDelay: 100 * time.Millisecond},
+			MinConnectTimeout: time.Second,
+		})}
+		// the leader is fixed, such that the commands are not proposed while it is down.
+		builder.Register(leaderrotation.NewFixed(1))
+	}
+	replicas := make([]*Replica, n)
+	infos := make([]backend.ReplicaInfo, n)
+	clientAddrs := make([]string, n)
+	for i := range replicas {
+		replicas[i], infos[i], clientAddrs[i] = newTestReplica(t, hotstuff.ID(i+1), keys[i], "127.0.0.1:0", configure)
+	}
+	for _, r := range replicas {
+		if err := r.Connect(infos); err != nil {
+			t.Fatal(err)
+		}
+		r.Start()
+	}
+	t.Cleanup(func() {
+		for _, r := range replicas {
+			r.Stop()
+		}
+	})
+
+	// the commands are only sent to the leader, and they are sent once.
+	mgr := clientpb.NewManager(
+		gorums.WithDialTimeout(time.Second),
+		gorums.WithGrpcDialOptions(grpc.WithBlock(), grpc.WithInsecure()),
+	)
+	defer mgr.Close()
+	leaderConfig := func(addr string) *clientpb.Configuration {
+		cfg, err := mgr.NewConfiguration(qspec{}, gorums.WithNodeList([]string{addr}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	send := func(cfg *clientpb.Configuration, client uint32, seqs ...uint64) {
+		for _, seq := range seqs {
+			cfg.ExecCommand(ctx, &clientpb.Command{ClientID: client, SequenceNumber: seq, Data: []byte(fmt.Sprint(client, seq))})
+		}
+	}
+	seqs := func(first, last uint64) (seqs []uint64) {
+		for seq := first; seq <= last; seq++ {
+			seqs = append(seqs, seq)
+		}
+		return seqs
+	}
+	executed := func(r *Replica, seqs []uint64) bool {
+		for _, seq := range seqs {
+			if !r.clientSrv.cmdCache.isExecuted(cmdID{clientID: 1, sequenceNum: seq}) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// the leader proposes the first commands, and then stalls while it admits the others.
+	cfg := leaderConfig(clientAddrs[0])
+	send(cfg, 1, seqs(1, first)...)
+	waitFor(t, "the leader to propose the first commands", func() bool {
+		return executed(replicas[0], seqs(1, first/2)) && replicas[0].clientSrv.cmdCache.len() == 0
+	})
+	stalled := make(chan struct{})
+	replicas[0].hs.EventLoop().AddPriorityEvent(func() { close(stalled) })
+	replicas[0].Pause()
+	select {
+	case <-stalled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the leader to stall")
+	}
+	send(cfg, 1, seqs(first+1, first+pending)...)
+	waitFor(t, "the leader to admit the commands", func() bool { return replicas[0].clientSrv.cmdCache.len() == pending })
+
+	// the leader crashes, and the client does not retry the commands that it admitted.
+	replicas[0].Stop()
+	replicas[0], _, clientAddrs[0] = newTestReplica(t, 1, keys[0], infos[0].Address, configure)
+	// the first commands may be reloaded too, unless they were committed before the leader crashed.
+	if got := replicas[0].clientSrv.cmdCache.len(); got < pending || got > first+pending {
+		t.Errorf("the restarted leader reloaded %d commands, want at least the %d that were not proposed", got, pending)
+	}
+	if err := replicas[0].Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	replicas[0].Start()
+
+	// another client keeps the leader proposing, such that the blocks of the reloaded commands are committed.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		cfg := leaderConfig(clientAddrs[0])
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for seq := uint64(1); ; seq++ {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			send(cfg, 2, seq)
+		}
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	for _, r := range replicas {
+		waitFor(t, fmt.Sprintf("replica %d to execute the commands", r.hs.ID()), func() bool {
+			return executed(r, seqs(1, first+pending))
+		})
+	}
+	cancel()
+	wg.Wait()
+	// the replicas executed each command once, in the same order.
+	waitFor(t, "the replicas to reach the same state", func() bool {
+		for _, r := range replicas[1:] {
+			if !bytes.Equal(r.GetHash(), replicas[0].GetHash()) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestCommandLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), commandLogFile)
+	cmds := make([]*clientpb.Command, 4)
+	for i := range cmds {
+		cmds[i] = &clientpb.Command{ClientID: 1, SequenceNumber: uint64(i + 1), Data: []byte{byte(i)}}
+	}
+	reload := func() []uint64 {
+		t.Helper()
+		reloaded, err := readCmdLog(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seqs := make([]uint64, len(reloaded))
+		for i, cmd := range reloaded {
+			seqs[i] = cmd.GetSequenceNumber()
+		}
+		return seqs
+	}
+
+	log, err := createCmdLog(path, SyncOff, cmds[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range cmds[1:] {
+		if err := log.append(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := log.remove([]cmdID{{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := reload(); !reflect.DeepEqual(got, []uint64{1, 3, 4}) {
+		t.Errorf("reloaded commands %v, want [1 3 4]", got)
+	}
+
+	// a record that was partially written when the replica crashed ends the log.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Write([]byte{100, 0, 0, 0, 1})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reload(); !reflect.DeepEqual(got, []uint64{1, 3, 4}) {
+		t.Errorf("reloaded commands %v after a partial record, want [1 3 4]", got)
+	}
+
+	// the log is truncated when every command has been removed.
+	if err := log.remove([]cmdID{{1, 1}, {1, 3}, {1, 4}}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Errorf("the log was not truncated: %v, %v", info, err)
+	}
+	if err := log.close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := log.append(cmds[0]); err != nil || len(reload()) != 0 {
+		t.Errorf("a command was appended to the closed log: %v", err)
+	}
+
+	if _, err := ParseCommandLogSync("never"); err == nil {
+		t.Error("an unknown sync policy was parsed")
+	}
+}
+
+func TestBoundedCommandCache(t *testing.T) {
+	builder := consensus.NewBuilder(1, nil)
+	srv := newClientServer(Config{BatchSize: 1, CommandCacheSize: 2}, nil)
+	builder.Register(srv, srv.cmdCache)
+	builder.Build()
+	cmds := make([]*clientpb.Command, 4)
+	for i := range cmds {
+		cmds[i] = &clientpb.Command{ClientID: 1, SequenceNumber: uint64(i + 1), Data: bytes.Repeat([]byte{'x'}, 100*(i+1))}
+	}
+	awaitErr := func(c <-chan execResult) error {
+		select {
+		case res := <-c:
+			return res.err
+		case <-time.After(10 * time.Millisecond):
+			return nil
+		}
+	}
+
+	// the cache rejects new commands when it is full.
+	results := make([]<-chan execResult, len(cmds))
+	for i, cmd := range cmds[:3] {
+		results[i], _ = srv.submit(cmd)
+	}
+	if err := awaitErr(results[2]); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("the command submitted to a full cache failed with %v, want ResourceExhausted", err)
+	}
+	if err := awaitErr(results[0]); err != nil {
+		t.Errorf("a command in the cache failed with %v", err)
+	}
+	stats := srv.cmdCache.getStats()
+	if want := cmdSize(cmds[0]) + cmdSize(cmds[1]); stats.commands != 2 || stats.bytes != want || stats.rejected != 1 {
+		t.Errorf("got %d commands of %d bytes and %d rejected, want 2 commands of %d bytes and 1 rejected",
+			stats.commands, stats.bytes, stats.rejected, want)
+	}
+
+	// proposing a command frees its space.
+	if _, ok := srv.cmdCache.Get(context.Background()); !ok {
+		t.Fatal("the cache did not return a batch")
+	}
+	if stats := srv.cmdCache.getStats(); stats.commands != 1 || stats.bytes != cmdSize(cmds[1]) {
+		t.Errorf("got %d commands of %d bytes after proposing one, want 1 command of %d bytes", stats.commands, stats.bytes, cmdSize(cmds[1]))
+	}
+
+	// the oldest command that has not been proposed is evicted to make room for the new ones, and its client is told to retry.
+	srv.cmdCache.setLimits(0, cmdSize(cmds[1])+cmdSize(cmds[2]), true)
+	results[2], _ = srv.submit(cmds[2])
+	results[3], _ = srv.submit(cmds[3])
+	if err := awaitErr(results[1]); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("the evicted command failed with %v, want ResourceExhausted", err)
+	}
+	stats = srv.cmdCache.getStats()
+	if stats.evicted != 2 || stats.commands != 1 || stats.bytes != cmdSize(cmds[3]) {
+		t.Errorf("got %d commands of %d bytes and %d evicted, want 1 command of %d bytes and 2 evicted",
+			stats.commands, stats.bytes, stats.evicted, cmdSize(cmds[3]))
+	}
+	if err := awaitErr(results[2]); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("the second evicted command failed with %v, want ResourceExhausted", err)
+	}
+	// a command that is larger than the cache is rejected even if the cache evicts commands.
+	huge := &clientpb.Command{ClientID: 1, SequenceNumber: 5, Data: make([]byte, 1000)}
+	if c, _ := srv.submit(huge); status.Code(awaitErr(c)) != codes.ResourceExhausted {
+		t.Error("a command larger than the cache was not rejected")
+	}
+}
+
+func TestCommandBatching(t *testing.T) {
+	newCache := func(batchSize, batchBytes int, delay time.Duration) (*cmdCache, *testutil.FakeClock) {
+		cache := newCmdCache(batchSize)
+		cache.setBatchLimits(batchBytes, delay)
+		clock := testutil.NewFakeClock(time.Unix(0, 0))
+		builder := modules.NewBuilder(1)
+		builder.Register(cache, clock)
+		builder.Build()
+		return cache, clock
+	}
+	command := func(seq uint64) *clientpb.Command {
+		return &clientpb.Command{ClientID: 1, SequenceNumber: seq, Data: make([]byte, 10)}
+	}
+	get := func(ctx context.Context, cache *cmdCache) <-chan []uint64 {
+		c := make(chan []uint64, 1)
+		go func() {
+			cmd, ok := cache.Get(ctx)
+			if !ok {
+				close(c)
+				return
+			}
+			batch := new(clientpb.Batch)
+			if err := proto.Unmarshal([]byte(cmd), batch); err != nil {
+				t.Error(err)
+			}
+			var seqs []uint64
+			for _, cmd := range batch.GetCommands() {
+				seqs = append(seqs, cmd.GetSequenceNumber())
+			}
+			c <- seqs
+		}()
+		return c
+	}
+	expect := func(what string, c <-chan []uint64, want ...uint64) {
+		t.Helper()
+		select {
+		case got, ok := <-c:
+			if !ok {
+				t.Fatalf("%s: no batch was returned", what)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: got the batch %v, want %v", what, got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: the batch was not released", what)
+		}
+	}
+	pending := func(what string, c <-chan []uint64) {
+		t.Helper()
+		select {
+		case got := <-c:
+			t.Fatalf("%s: the batch %v was released too early", what, got)
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+
+	t.Run("Size", func(t *testing.T) {
+		cache, _ := newCache(3, 0, 0)
+		c := get(context.Background(), cache)
+		cache.addCommand(command(1), true)
+		cache.addCommand(command(2), true)
+		pending("two commands", c)
+		cache.addCommand(command(3), true)
+		cache.addCommand(command(4), true)
+		expect("three commands", c, 1, 2, 3)
+	})
+
+	t.Run("Bytes", func(t *testing.T) {
+		size := batchEntrySize(command(1))
+		cache, _ := newCache(100, 2*size, 0)
+		c := get(context.Background(), cache)
+		cache.addCommand(command(1), true)
+		pending("one command", c)
+		cache.addCommand(command(2), true)
+		cache.addCommand(command(3), true)
+		expect("two commands", c, 1, 2)
+	})
+
+	t.Run("Delay", func(t *testing.T) {
+		cache, clock := newCache(100, 0, 10*time.Millisecond)
+		c := get(context.Background(), cache)
+		cache.addCommand(command(1), true)
+		waitFor(t, "the batch delay to start", func() bool { return clock.Timers() == 1 })
+		clock.Step(5 * time.Millisecond)
+		cache.addCommand(command(2), true)
+		pending("half of the delay", c)
+		clock.Step(5 * time.Millisecond)
+		expect("the delay", c, 1, 2)
+	})
+
+	t.Run("ViewChange", func(t *testing.T) {
+		cache, clock := newCache(100, 0, 10*time.Millisecond)
+		cache.addCommand(command(1), true)
+		ctx, cancel := context.WithCancel(context.Background())
+		c := get(ctx, cache)
+		waitFor(t, "the batch delay to start", func() bool { return clock.Timers() == 1 })
+		// the view changes before the batch is released.
+		cancel()
+		select {
+		case got, ok := <-c:
+			if ok {
+				t.Fatalf("got the batch %v after the view changed", got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Get did not return when the view changed")
+		}
+		if n := clock.Timers(); n != 0 {
+			t.Errorf("%d timers are still active after the view changed", n)
+		}
+
+		// the commands are kept for the next view, where they have waited for the whole delay.
+		clock.Step(10 * time.Millisecond)
+		expect("the next view", get(context.Background(), cache), 1)
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		size := batchEntrySize(command(1))
+		cache, clock := newCache(4, 8*size, 10*time.Millisecond)
+		for seq := uint64(1); seq <= 3; seq++ {
+			cache.addCommand(command(seq), true)
+		}
+		clock.Step(4 * time.Millisecond)
+		cache.addCommand(command(4), true)
+		// a full batch that fills half of the byte limit, after waiting 4+4+4+0 ms.
+		expect("a full batch", get(context.Background(), cache), 1, 2, 3, 4)
+		cache.addCommand(command(5), true)
+		cache.addCommand(command(6), true)
+		c := get(context.Background(), cache)
+		waitFor(t, "the batch delay to start", func() bool { return clock.Timers() == 1 })
+		clock.Step(10 * time.Millisecond)
+		// a half full batch that fills a quarter of the byte limit, after waiting 10+10 ms.
+		expect("the delay", c, 5, 6)
+		cache.addCommand(command(7), true)
+
+		var countFill, bytesFill [consensus.FillBuckets]uint64
+		countFill[5], countFill[9] = 1, 1
+		bytesFill[2], bytesFill[5] = 1, 1
+		want := consensus.CommandQueueEvent{
+			Depth:     1,
+			MaxDepth:  4,
+			Commands:  6,
+			Wait:      32 * time.Millisecond,
+			CountFill: countFill,
+			BytesFill: bytesFill,
+		}
+		if got := cache.queueEvent(); got != want {
+			t.Errorf("got the statistics %+v, want %+v", got, want)
+		}
+		// the statistics start over, from the commands that are still waiting.
+		if got, want := cache.queueEvent(), (consensus.CommandQueueEvent{Depth: 1, MaxDepth: 1}); got != want {
+			t.Errorf("got the statistics %+v after they were reported, want %+v", got, want)
+		}
+	})
+}
+
+func TestUrgentCommands(t *testing.T) {
+	cache := newCmdCache(20)
+	builder := modules.NewBuilder(1)
+	builder.Register(cache)
+	builder.Build()
+	get := func() (urgent, client []uint64) {
+		t.Helper()
+		cmd, ok := cache.Get(context.Background())
+		if !ok {
+			t.Fatal("the cache did not return a batch")
+		}
+		batch := new(clientpb.Batch)
+		if err := proto.Unmarshal([]byte(cmd), batch); err != nil {
+			t.Fatal(err)
+		}
+		for _, cmd := range batch.GetCommands() {
+			if cmd.GetUrgent() {
+				if len(client) > 0 {
+					t.Fatalf("urgent command %d follows the client commands %v", cmd.GetSequenceNumber(), client)
+				}
+				urgent = append(urgent, cmd.GetSequenceNumber())
+			} else {
+				client = append(client, cmd.GetSequenceNumber())
+			}
+		}
+		if !cache.Accept(consensus.Command(cmd)) {
+			t.Error("the batch was not accepted")
+		}
+		return urgent, client
+	}
+
+	// a system command is submitted while the clients keep the cache full.
+	for seq := uint64(1); seq <= 1000; seq++ {
+		cache.addCommand(&clientpb.Command{ClientID: 1, SequenceNumber: seq}, true)
+	}
+	get()
+	if !cache.AddUrgent(&clientpb.Command{ClientID: 0, SequenceNumber: 1}) {
+		t.Fatal("the urgent command was not added")
+	}
+	if cache.AddUrgent(&clientpb.Command{ClientID: 0, SequenceNumber: 1}) {
+		t.Error("the urgent command was added twice")
+	}
+	urgent, client := get()
+	if !reflect.DeepEqual(urgent, []uint64{1}) || len(client) != 19 || client[0] != 21 {
+		t.Errorf("the next batch has the urgent commands %v and the client commands %v, want the urgent command first",
+			urgent, client)
+	}
+
+	// the number of urgent commands in a batch is capped.
+	for seq := uint64(2); seq < 2+maxUrgentCommands+4; seq++ {
+		cache.AddUrgent(&clientpb.Command{ClientID: 0, SequenceNumber: seq})
+	}
+	if urgent, client := get(); len(urgent) != maxUrgentCommands || len(client) != 20-maxUrgentCommands {
+		t.Errorf("got %d urgent commands and %d client commands, want %d and %d",
+			len(urgent), len(client), maxUrgentCommands, 20-maxUrgentCommands)
+	}
+	if urgent, client := get(); len(urgent) != 4 || len(client) != 16 {
+		t.Errorf("got %d urgent commands and %d client commands, want 4 and 16", len(urgent), len(client))
+	}
+
+	// an urgent command after a client command is not accepted.
+	b, err := proto.Marshal(&clientpb.Batch{Commands: []*clientpb.Command{
+		{ClientID: 2, SequenceNumber: 1},
+		{ClientID: 0, SequenceNumber: 100, Urgent: true},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.Accept(consensus.Command(b)) {
+		t.Error("a batch with an urgent command after a client command was accepted")
+	}
+
+	// clients cannot submit urgent commands.
+	srv := newClientServer(Config{BatchSize: 1}, nil)
+	c, _ := srv.submit(&clientpb.Command{ClientID: 2, SequenceNumber: 1, Urgent: true})
+	if res := <-c; status.Code(res.err) != codes.InvalidArgument {
+		t.Errorf("an urgent command from a client returned %v, want InvalidArgument", res.err)
+	}
+}
+
+func TestForkRequeue(t *testing.T) {
+	// the batch is released when it holds the requeued commands and the new commands.
+	srv := newClientServer(Config{BatchSize: 7}, nil)
+	builder := consensus.NewBuilder(1, nil)
+	builder.Register(srv, srv.cmdCache)
+	builder.Build()
+	cache := srv.cmdCache
+
+	batch := func(seqs ...uint64) consensus.Command {
+		cmds := make([]*clientpb.Command, len(seqs))
+		for i, seq := range seqs {
+			cmds[i] = &clientpb.Command{ClientID: 1, SequenceNumber: seq}
+		}
+		b, err := proto.Marshal(&clientpb.Batch{Commands: cmds})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return consensus.Command(b)
+	}
+	get := func() (seqs []uint64) {
+		t.Helper()
+		cmd, ok := cache.Get(context.Background())
+		if !ok {
+			t.Fatal("the cache did not return a batch")
+		}
+		batch := new(clientpb.Batch)
+		if err := proto.Unmarshal([]byte(cmd), batch); err != nil {
+			t.Fatal(err)
+		}
+		for _, cmd := range batch.GetCommands() {
+			seqs = append(seqs, cmd.GetSequenceNumber())
+		}
+		return seqs
+	}
+
+	// the abandoned block and the winning block both propose the even commands.
+	abandoned := consensus.NewBlock(consensus.GetGenesis().Hash(), consensus.QuorumCert{}, batch(1, 2, 3, 4, 5, 6, 7, 8, 9, 10), 1, 1)
+	cache.Proposed(abandoned.Command())
+	srv.Exec(consensus.NewBlock(consensus.GetGenesis().Hash(), consensus.QuorumCert{}, batch(2, 4, 6, 8, 10), 2, 2))
+
+	// new commands arrive before the fork.
+	cache.addCommand(&clientpb.Command{ClientID: 1, SequenceNumber: 11}, true)
+	cache.addCommand(&clientpb.Command{ClientID: 1, SequenceNumber: 12}, true)
+
+	// the fork is reported twice, but the commands are requeued once.
+	cache.Fork(abandoned)
+	cache.Fork(abandoned)
+	if requeued := cache.getStats().requeued; requeued != 5 {
+		t.Errorf("%d commands were requeued, want 5", requeued)
+	}
+	if got, want := get(), []uint64{1, 3, 5, 7, 9, 11, 12}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got the batch %v, want %v", got, want)
+	}
+	if n := cache.len(); n != 0 {
+		t.Errorf("%d commands are left in the cache, want 0", n)
+	}
+}
+
+func TestFairBatches(t *testing.T) {
+	newCache := func(policy BatchPolicy, weights map[uint32]uint32) *cmdCache {
+		cache := newCmdCache(10)
+		cache.setBatchPolicy(policy, weights)
+		builder := modules.NewBuilder(1)
+		builder.Register(cache, testutil.NewFakeClock(time.Unix(0, 0)))
+		builder.Build()
+		return cache
+	}
+	add := func(cache *cmdCache, client uint32, seqs ...uint64) {
+		t.Helper()
+		for _, seq := range seqs {
+			if added, _, err := cache.addCommand(&clientpb.Command{ClientID: client, SequenceNumber: seq}, true); !added {
+				t.Fatalf("command %d of client %d was not added: %v", seq, client, err)
+			}
+		}
+	}
+	// get returns the commands of the next batch as client:sequence pairs.
+	get := func(cache *cmdCache) (cmds []string) {
+		t.Helper()
+		cmd, ok := cache.Get(context.Background())
+		if !ok {
+			t.Fatal("the cache did not return a batch")
+		}
+		batch := new(clientpb.Batch)
+		if err := proto.Unmarshal([]byte(cmd), batch); err != nil {
+			t.Fatal(err)
+		}
+		for _, cmd := range batch.GetCommands() {
+			cmds = append(cmds, fmt.Sprintf("%d:%d", cmd.GetClientID(), cmd.GetSequenceNumber()))
+		}
+		return cmds
+	}
+	// shares returns the number of commands of each client in the batches, and checks that the commands of each client
+	// are in order.
+	shares := func(batches ...[]string) map[uint32]int {
+		t.Helper()
+		shares := make(map[uint32]int)
+		last := make(map[uint32]uint64)
+		for _, batch := range batches {
+			for _, cmd := range batch {
+				var client uint32
+				var seq uint64
+				if _, err := fmt.Sscanf(cmd, "%d:%d", &client, &seq); err != nil {
+					t.Fatal(err)
+				}
+				if seq <= last[client] {
+					t.Errorf("command %d of client %d was batched after command %d", seq, client, last[client])
+				}
+				last[client] = seq
+				shares[client]++
+			}
+		}
+		return shares
+	}
+
+	// client 1 offers ten times as many commands as client 2, and both have more commands than fit in the batches.
+	tests := []struct {
+		name    string
+		policy  BatchPolicy
+		weights map[uint32]uint32
+		want    map[uint32]int
+	}{
+		{"FIFO", FIFOBatches, nil, map[uint32]int{1: 37, 2: 3}},
+		{"Equal", FairBatches, nil, map[uint32]int{1: 20, 2: 20}},
+		{"Weighted", FairBatches, map[uint32]uint32{1: 3, 2: 1}, map[uint32]int{1: 30, 2: 10}},
+		{"FavorLight", FairBatches, map[uint32]uint32{2: 4}, map[uint32]int{1: 8, 2: 32}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cache := newCache(test.policy, test.weights)
+			for i := uint64(0); i < 40; i++ {
+				add(cache, 1, i*10+1, i*10+2, i*10+3, i*10+4, i*10+5, i*10+6, i*10+7, i*10+8, i*10+9, i*10+10)
+				add(cache, 2, i+1)
+			}
+			var batches [][]string
+			for i := 0; i < 4; i++ {
+				batches = append(batches, get(cache))
+			}
+			if got := shares(batches...); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("the clients got the shares %v of the batches, want %v", got, test.want)
+			}
+		})
+	}
+
+	t.Run("IdleClient", func(t *testing.T) {
+		cache := newCache(FairBatches, nil)
+		add(cache, 1, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25)
+		if got := shares(get(cache)); got[1] != 10 {
+			t.Errorf("client 1 got %d commands of the batch while it was alone, want 10", got[1])
+		}
+		// client 2 was idle during the first batch, so it does not get a larger share when it sends a burst,
+		// but it is not starved by the backlog of client 1 either.
+		add(cache, 2, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20)
+		if got, want := get(cache), []string{"2:1", "1:11", "2:2", "1:12", "2:3", "1:13", "2:4", "1:14", "2:5", "1:15"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got the batch %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Requeue", func(t *testing.T) {
+		cache := newCache(FairBatches, nil)
+		cache.setBatchSize(3)
+		add(cache, 1, 1, 2)
+		add(cache, 2, 1)
+		proposed := get(cache)
+		b, err := proto.Marshal(&clientpb.Batch{Commands: []*clientpb.Command{
+			{ClientID: 1, SequenceNumber: 1}, {ClientID: 2, SequenceNumber: 1}, {ClientID: 1, SequenceNumber: 2},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		block := consensus.NewBlock(consensus.GetGenesis().Hash(), consensus.QuorumCert{}, consensus.Command(b), 1, 1)
+		cache.Proposed(block.Command())
+		add(cache, 1, 3, 4)
+		add(cache, 2, 2)
+		// the requeued commands of each client are taken before its new commands.
+		cache.Fork(block)
+		cache.setBatchSize(6)
+		got := get(cache)
+		if want := []string{"1:1", "2:1", "1:2", "2:2", "1:3", "1:4"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got the batch %v after the fork of %v, want %v", got, proposed, want)
+		}
+		if n := cache.len(); n != 0 {
+			t.Errorf("%d commands are left in the cache, want 0", n)
+		}
+	})
+}
+
+// validatorFunc is a Validator that calls the function.
+type validatorFunc func(data []byte) error
+
+func (f validatorFunc) Validate(data []byte) error { return f(data) }
+
+func TestCommandValidator(t *testing.T) {
+	var banned sync.Map
+	validator := validatorFunc(func(data []byte) error {
+		if _, ok := banned.Load(string(data)); ok {
+			return errors.New("banned")
+		}
+		return kvstore.Validator{MaxValueSize: 4}.Validate(data)
+	})
+	srv := newClientServer(Config{BatchSize: 2, Validator: validator, Application: kvstore.New()}, nil)
+	builder := consensus.NewBuilder(1, nil)
+	builder.Register(srv, srv.cmdCache)
+	mods := builder.Build()
+	cache := srv.cmdCache
+
+	// the validator of the configuration replaces the validation of the application.
+	c, _ := srv.submit(&clientpb.Command{ClientID: 1, SequenceNumber: 1, Data: kvstore.Put("x", []byte("too long"))})
+	if res := <-c; status.Code(res.err) != codes.InvalidArgument {
+		t.Errorf("a command with a large value failed with %v, want InvalidArgument", res.err)
+	}
+
+	// a command that has become invalid since it was added is left out of the batch.
+	cache.addCommand(&clientpb.Command{ClientID: 1, SequenceNumber: 2, Data: kvstore.Put("x", []byte("1"))}, true)
+	cache.addCommand(&clientpb.Command{ClientID: 1, SequenceNumber: 3, Data: kvstore.Put("y", []byte("1"))}, true)
+	banned.Store(string(kvstore.Put("x", []byte("1"))), true)
+	cmd, ok := cache.Get(context.Background())
+	if !ok {
+		t.Fatal("the cache did not return a batch")
+	}
+	batch := new(clientpb.Batch)
+	if err := proto.Unmarshal([]byte(cmd), batch); err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.GetCommands()) != 1 || batch.GetCommands()[0].GetSequenceNumber() != 3 {
+		t.Errorf("got the batch %v, want only command 3", batch.GetCommands())
+	}
+
+	// the replica does not vote for blocks with invalid commands, but it accepts them otherwise.
+	if err := mods.CommandValidator().Validate(cmd); err != nil {
+		t.Errorf("a valid batch was rejected: %v", err)
+	}
+	invalid, err := proto.Marshal(&clientpb.Batch{Commands: []*clientpb.Command{
+		{ClientID: 2, SequenceNumber: 1, Data: kvstore.Put("z", []byte("1"))},
+		{ClientID: 2, SequenceNumber: 2, Data: kvstore.Put("x", []byte("1"))},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mods.CommandValidator().Validate(consensus.Command(invalid)); err == nil {
+		t.Error("a batch with an invalid command was not rejected")
+	}
+	if !cache.Accept(consensus.Command(invalid)) {
+		t.Error("the acceptor rejected a batch because of its validity")
+	}
+}
+
+func TestMaxBlockBytes(t *testing.T) {
+	const limit = 64 << 10
+	for _, tt := range []struct {
+		name    string
+		keyFunc func(t *testing.T) consensus.PrivateKey
+		impl    func() consensus.CryptoImpl
+	}{
+		{"ECDSA", testutil.GenerateECDSAKey, ecdsa.New},
+		{"BLS12", testutil.GenerateBLS12Key, bls12.New},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			builders := testutil.CreateBuilders(t, ctrl, 4, testutil.GenerateKeys(t, 4, tt.keyFunc)...)
+			for _, builder := range builders {
+				builder.Register(crypto.New(tt.impl()))
+			}
+			srv := newClientServer(Config{BatchSize: 1000, MaxBlockBytes: limit, BatchDelay: time.Millisecond}, nil)
+			builders[0].Register(srv, srv.cmdCache)
+			hl := builders.Build()
+			genesis := consensus.GetGenesis()
+			qc := testutil.CreateQC(t, genesis, hl.Signers())
+
+			// a command that does not fit in a block is rejected.
+			c, _ := srv.submit(&clientpb.Command{ClientID: 1, SequenceNumber: 1, Data: make([]byte, limit)})
+			if res := <-c; status.Code(res.err) != codes.InvalidArgument {
+				t.Errorf("an oversized command returned %v, want InvalidArgument", res.err)
+			}
+
+			rnd := rand.New(rand.NewSource(1))
+			added := 0
+			for seq := uint64(2); seq < 200; seq++ {
+				size := rnd.Intn(8 << 10)
+				if seq%10 == 0 {
+					// a few commands that barely fit in a block on their own.
+					size = limit - limit/8
+				}
+				ok, _, err := srv.cmdCache.addCommand(&clientpb.Command{ClientID: 1, SequenceNumber: seq, Data: make([]byte, size)}, true)
+				if err != nil || !ok {
+					t.Fatalf("command %d of %d bytes was not added: %v", seq, size, err)
+				}
+				added++
+			}
+
+			got, largest := 0, 0
+			for srv.cmdCache.len() > 0 {
+				cmd, ok := srv.cmdCache.Get(context.Background())
+				if !ok {
+					t.Fatal("the cache did not return a batch")
+				}
+				batch := new(clientpb.Batch)
+				if err := proto.Unmarshal([]byte(cmd), batch); err != nil {
+					t.Fatal(err)
+				}
+				got += len(batch.GetCommands())
+				size := proto.Size(hotstuffpb.ProposalToProto(testutil.NewProposeMsg(genesis.Hash(), qc, cmd, 1, 1)))
+				if size > limit {
+					t.Errorf("a proposal of %d commands has %d bytes, want at most %d", len(batch.GetCommands()), size, limit)
+				}
+				if size > largest {
+					largest = size
+				}
+			}
+			if got != added {
+				t.Errorf("got %d commands in the batches, want %d", got, added)
+			}
+			if largest < limit*3/4 {
+				t.Errorf("the largest proposal has %d bytes, want the batches to fill the blocks", largest)
+			}
+		})
+	}
+}
+
+func TestCommandCacheOverload(t *testing.T) {
+	const (
+		n         = 4
+		cacheSize = 200
+	)
+	cacheBytes := cacheSize * (cmdOverhead + 64)
+	replicas, clientAddrs, _ := startConfiguredNetwork(t, n, func(conf *Config) {
+		conf.BatchSize = 20
+		conf.CommandCacheSize = cacheSize
+		conf.CommandCacheBytes = cacheBytes
+	})
+	infos := make([]backend.ReplicaInfo, n)
+	for i := range infos {
+		infos[i] = backend.ReplicaInfo{ID: hotstuff.ID(i + 1), Address: clientAddrs[i]}
+	}
+	height := func() uint64 {
+		replicas[0].clientSrv.mut.Lock()
+		defer replicas[0].clientSrv.mut.Unlock()
+		return replicas[0].clientSrv.height
+	}
+	// run runs a client for the duration, and calls sample periodically while it runs.
+	run := func(conf client.Config, duration time.Duration, sample func()) (throughput float64) {
+		conf.ManagerOptions = []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)}
+		conf.PayloadSize = 64
+		conf.Input = client.NewPayloadReader(int64(conf.ID), false)
+		cli := client.New(conf, modules.NewBuilder(conf.ID))
+		if err := cli.Connect(infos); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), duration)
+		defer cancel()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for ctx.Err() == nil {
+				sample()
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+		start, before := time.Now(), height()
+		cli.Run(ctx)
+		<-done
+		return float64(height()-before) / time.Since(start).Seconds()
+	}
+
+	// the capacity is the throughput of a closed-loop client that keeps enough commands in flight to fill the batches.
+	capacity := run(client.Config{ID: 1, MaxConcurrent: cacheSize / 2}, time.Second, func() {})
+	if capacity == 0 {
+		t.Fatal("no commands were executed")
+	}
+
+	// under twice the capacity, the caches stay within their bounds, the excess commands are rejected,
+	// and the replicas keep executing commands.
+	var (
+		maxCommands, maxBytes int
+		heights               []uint64
+	)
+	throughput := run(client.Config{
+		ID:            2,
+		LoadMode:      client.FixedLoad,
+		TargetRate:    2 * capacity,
+		MaxConcurrent: 100000,
+	}, 2*time.Second, func() {
+		for _, r := range replicas {
+			stats := r.clientSrv.cmdCache.getStats()
+			if stats.commands > maxCommands {
+				maxCommands = stats.commands
+			}
+			if stats.bytes > maxBytes {
+				maxBytes = stats.bytes
+			}
+		}
+		heights = append(heights, height())
+	})
+	if maxCommands > cacheSize || maxBytes > cacheBytes {
+		t.Errorf("the caches held up to %d commands of %d bytes, want at most %d commands of %d bytes", maxCommands, maxBytes, cacheSize, cacheBytes)
+	}
+	var rejected uint64
+	for _, r := range replicas {
+		rejected += r.clientSrv.cmdCache.getStats().rejected
+	}
+	if rejected == 0 {
+		t.Error("no commands were rejected under overload")
+	}
+	// the client and the replicas share the CPUs of the machine, so the throughput under overload is not compared with
+	// the capacity, but the replicas must not stall.
+	if mid := len(heights) / 2; len(heights) < 2 || heights[len(heights)-1] <= heights[mid] {
+		t.Error("the replicas stopped executing commands under overload")
+	}
+	t.Logf("capacity %.0f commands/s, throughput under overload %.0f commands/s, %d commands rejected", capacity, throughput, rejected)
+}
+
+// instanceRecorder is a metrics logger that records the instances of the logged events.
+type instanceRecorder struct {
+	mut       sync.Mutex
+	instances map[uint32]int
+}
+
+func (r *instanceRecorder) Log(msg proto.Message) {
+	m, ok := msg.(interface{ GetEvent() *types.Event })
+	if !ok {
+		return
+	}
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	r.instances[m.GetEvent().GetInstance()]++
+}
+
+func (r *instanceRecorder) Close() error { return nil }
+
+func (r *instanceRecorder) count(instance uint32) int {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return r.instances[instance]
+}
+
+// commitRecorder is a metrics logger that records the commit measurements of replica 1.
+type commitRecorder struct {
+	mut          sync.Mutex
+	measurements []*types.CommitMeasurement
+}
+
+func (r *commitRecorder) Log(msg proto.Message) {
+	if m, ok := msg.(*types.CommitMeasurement); ok && m.GetEvent().GetID() == 1 {
+		r.mut.Lock()
+		defer r.mut.Unlock()
+		r.measurements = append(r.measurements, m)
+	}
+}
+
+func (r *commitRecorder) Close() error { return nil }
+
+// totals returns the sums of the measurements recorded so far.
+func (r *commitRecorder) totals() (commits, views uint64, proposers map[uint32]uint64) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	proposers = make(map[uint32]uint64)
+	for _, m := range r.measurements {
+		commits += m.GetCommits()
+		views += m.GetViews()
+		for id, n := range m.GetProposers() {
+			proposers[id] += n
+		}
+	}
+	return commits, views, proposers
+}
+
+// TestCommitsMetric checks that the commits metric counts the blocks committed by the replica, both in total and per
+// proposer, and the number of views that they span.
+func TestCommitsMetric(t *testing.T) {
+	recorder := &commitRecorder{}
+	_, clientAddrs, _ := startNetworkWithModules(t, 4, func(_ *Config, builder *consensus.Builder) {
+		builder.Register(recorder)
+		builder.Register(metrics.GetReplicaMetrics("commits")...)
+		builder.Register(metrics.NewTicker(20 * time.Millisecond))
+	})
+	startCommandClient(t, clientAddrs, 1000, 1)
+
+	waitFor(t, "the replica to commit blocks from every proposer", func() bool {
+		commits, _, proposers := recorder.totals()
+		return commits >= 20 && len(proposers) == 4
+	})
+	commits, views, proposers := recorder.totals()
+	var perProposer uint64
+	for id, n := range proposers {
+		if id < 1 || id > 4 {
+			t.Errorf("%d blocks were proposed by the unknown replica %d", n, id)
+		}
+		perProposer += n
+	}
+	if perProposer != commits {
+		t.Errorf("the proposers committed %d blocks in total, want %d", perProposer, commits)
+	}
+	if views < commits {
+		t.Errorf("%d blocks were committed in %d views, want at most one commit per view", commits, views)
+	}
+}
+
+func TestShards(t *testing.T) {
+	const n = 4
+	keys := testutil.GenerateKeys(t, n, testutil.GenerateECDSAKey)
+	recorder := &instanceRecorder{instances: make(map[uint32]int)}
+	withMetrics := func(builder *consensus.Builder, instance uint32) {
+		builder.Register(metrics.InstanceLogger(recorder, instance))
+		builder.Register(metrics.GetReplicaMetrics("throughput")...)
+		builder.Register(metrics.NewTicker(50 * time.Millisecond))
+	}
+
+	// each replica runs instance 0 and a shard with instance 1, which share the connections.
+	roots := make([]*Replica, n)
+	shards := make([]*Replica, n)
+	infos := make([]backend.ReplicaInfo, n)
+	rootAddrs := make([]backend.ReplicaInfo, n)
+	shardAddrs := make([]backend.ReplicaInfo, n)
+	for i := range roots {
+		id := hotstuff.ID(i + 1)
+		var clientAddr string
+		roots[i], infos[i], clientAddr = newTestReplica(t, id, keys[i], "127.0.0.1:0", func(_ *Config, builder *consensus.Builder) {
+			withMetrics(builder, 0)
+		})
+		rootAddrs[i] = backend.ReplicaInfo{ID: id, Address: clientAddr}
+
+		builder := consensus.NewBuilder(id, keys[i])
+		builder.Register(
+			consensus.New(chainedhotstuff.New()),
+			crypto.NewCache(ecdsa.New(), 100),
+			leaderrotation.NewRoundRobin(),
+			synchronizer.New(testutil.FixedTimeout(time.Second)),
+			blockchain.New(),
+		)
+		withMetrics(&builder, 1)
+		shard, err := roots[i].NewShard(1, Config{PrivateKey: keys[i], BatchSize: 1, ClientAddress: "127.0.0.1:0"}, builder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replicaAddr, clientListen, err := shard.Listen()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if replicaAddr != nil {
+			t.Errorf("shard listens on replica address %v", replicaAddr)
+		}
+		shards[i] = shard
+		shardAddrs[i] = backend.ReplicaInfo{ID: id, Address: clientListen.String()}
+	}
+	for i := range roots {
+		if err := roots[i].Connect(infos); err != nil {
+			t.Fatal(err)
+		}
+		if err := shards[i].Connect(infos); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range roots {
+		roots[i].Start()
+		shards[i].Start()
+	}
+	t.Cleanup(func() {
+		for i := range roots {
+			shards[i].Stop()
+			roots[i].Stop()
+		}
+	})
+
+	if _, err := roots[0].NewShard(0, Config{}, consensus.NewBuilder(1, keys[0])); err == nil {
+		t.Error("NewShard accepted instance 0")
+	}
+
+	height := func(r *Replica) uint64 {
+		r.clientSrv.mut.Lock()
+		defer r.clientSrv.mut.Unlock()
+		return r.clientSrv.height
+	}
+	// run sends commands with the given client ID to the replicas for the duration.
+	run := func(id hotstuff.ID, replicas []backend.ReplicaInfo, duration time.Duration) {
+		cli := client.New(client.Config{
+			ID:             id,
+			ManagerOptions: []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+			MaxConcurrent:  10,
+			PayloadSize:    8,
+			Input:          client.NewPayloadReader(int64(id), false),
+		}, modules.NewBuilder(id))
+		if err := cli.Connect(replicas); err != nil {
+			t.Error(err)
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), duration)
+		defer cancel()
+		cli.Run(ctx)
+	}
+
+	// the shards commit the commands of their own clients independently.
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); run(1, rootAddrs, 500*time.Millisecond) }()
+	go func() { defer wg.Done(); run(2, shardAddrs, 500*time.Millisecond) }()
+	wg.Wait()
+	for _, group := range [][]*Replica{roots, shards} {
+		group := group
+		waitFor(t, "the replicas of each shard to execute the same commands", func() bool {
+			for _, r := range group[1:] {
+				if height(r) != height(group[0]) {
+					return false
+				}
+			}
+			return height(group[0]) > 0
+		})
+		for i, r := range group[1:] {
+			if !bytes.Equal(r.GetHash(), group[0].GetHash()) {
+				t.Errorf("replica %d executed different commands than replica 1 in the same shard", i+2)
+			}
+		}
+	}
+	if bytes.Equal(roots[0].GetHash(), shards[0].GetHash()) {
+		t.Error("the shards executed the same commands")
+	}
+
+	// the shard stalls when two of its replicas stop, but the other shard keeps committing over the same connections.
+	shards[2].Stop()
+	shards[3].Stop()
+	// the blocks that were certified before the replicas stopped may still be committed.
+	time.Sleep(200 * time.Millisecond)
+	stalled := height(shards[0])
+	before := height(roots[0])
+	run(1, rootAddrs, 500*time.Millisecond)
+	waitFor(t, "instance 0 to commit after the shard stalled", func() bool {
+		return height(roots[0]) > before
+	})
+	if h := height(shards[0]); h != stalled {
+		t.Errorf("the stalled shard executed %d commands", h-stalled)
+	}
+	for id := hotstuff.ID(2); id <= n; id++ {
+		if !roots[0].cfg.IsConnected(id) {
+			t.Errorf("replica 1 lost its connection to replica %d", id)
+		}
+	}
+
+	// the measurements of each instance are tagged with its instance.
+	if recorder.count(0) == 0 || recorder.count(1) == 0 {
+		t.Errorf("recorded %d events from instance 0 and %d events from instance 1, want both", recorder.count(0), recorder.count(1))
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	duration := 60 * time.Second
+	if testing.Short() {
+		duration = 2 * time.Second
+	}
+	const n = 4
+	var (
+		recording bytes.Buffer
+		key       consensus.PrivateKey
+	)
+	replicas, clientAddrs, stop := startConfiguredNetwork(t, n, func(conf *Config) {
+		if conf.ID == 1 {
+			conf.Recording = &recording
+			key = conf.PrivateKey
+		}
+	})
+	infos := make([]backend.ReplicaInfo, n)
+	for i := range infos {
+		infos[i] = backend.ReplicaInfo{ID: hotstuff.ID(i + 1), Address: clientAddrs[i]}
+	}
+	cli := client.New(client.Config{
+		ID:             1,
+		ManagerOptions: []gorums.ManagerOption{gorums.WithDialTimeout(time.Second)},
+		MaxConcurrent:  100,
+		PayloadSize:    8,
+		Input:          client.NewPayloadReader(1, false),
+		LoadMode:       client.FixedLoad,
+		TargetRate:     200,
+	}, modules.NewBuilder(1))
+	if err := cli.Connect(infos); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	cli.Run(ctx)
+	stop()
+
+	want := replicas[0].GetHash()
+	replicas[0].clientSrv.mut.Lock()
+	height := replicas[0].clientSrv.height
+	replicas[0].clientSrv.mut.Unlock()
+	if height == 0 {
+		t.Fatal("replica 1 did not execute any commands")
+	}
+
+	// the replayer is built from the same modules as the recorded replica.
+	builder := consensus.NewBuilder(1, key)
+	builder.Register(
+		consensus.New(chainedhotstuff.New()),
+		crypto.NewCache(ecdsa.New(), 100),
+		leaderrotation.NewRoundRobin(),
+		synchronizer.New(testutil.FixedTimeout(time.Second)),
+		blockchain.New(),
+	)
+	replayer, err := NewReplayer(Config{ID: 1, PrivateKey: key, BatchSize: 1}, builder, &recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayer.Remaining() == 0 {
+		t.Fatal("no events were recorded")
+	}
+	replayer.Run()
+	if replayer.clientSrv.height != height {
+		t.Errorf("the replay executed %d commands, but the recorded replica executed %d commands", replayer.clientSrv.height, height)
+	}
+	if got := replayer.Hash(); !bytes.Equal(got, want) {
+		t.Errorf("the replay executed commands with hash %.8x, but the recorded replica executed commands with hash %.8x", got, want)
+	}
+}
+
+// withTestTLS returns a function that configures the replicas to use TLS with certificates signed by a new CA,
+// and the pool that holds the CA.
+func withTestTLS(t *testing.T) (func(conf *Config), *x509.CertPool) {
+	t.Helper()
+	caKey, ca, err := keygen.GenerateCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	return func(conf *Config) {
+		key, err := keygen.GenerateECDSAPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := keygen.GenerateTLSCert(conf.ID, []string{"localhost", "127.0.0.1"}, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conf.TLS = true
+		conf.Certificate = &tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key}
+		conf.RootCAs = roots
+	}, roots
+}
+
+// timeoutCounter counts the local timeouts of a replica.
+type timeoutCounter struct {
+	timeouts uint64
+}
+
+func (c *timeoutCounter) InitModule(mods *modules.Modules) {
+	mods.EventLoop().RegisterObserver(synchronizer.LocalTimeoutEvent{}, func(_ interface{}) {
+		atomic.AddUint64(&c.timeouts, 1)
+	})
+}
+
+// TestQUICTransport checks that a network of replicas that communicate over QUIC commits the commands of a client,
+// both with and without TLS, and that it keeps committing with few view timeouts when 1% of the packets are lost.
+func TestQUICTransport(t *testing.T) {
+	run := func(t *testing.T, transport backend.Transport, useTLS bool) (timeouts uint64) {
+		t.Helper()
+		var configureTLS func(conf *Config)
+		var roots *x509.CertPool
+		if useTLS {
+			configureTLS, roots = withTestTLS(t)
+		}
+		recorder := &commitRecorder{}
+		counter := &timeoutCounter{}
+		_, clientAddrs, stop := startNetworkWithModules(t, 4, func(conf *Config, builder *consensus.Builder) {
+			conf.Transport = transport
+			if configureTLS != nil {
+				configureTLS(conf)
+			}
+			if conf.ID == 1 {
+				builder.Register(recorder, counter)
+				builder.Register(metrics.GetReplicaMetrics("commits")...)
+				builder.Register(metrics.NewTicker(20 * time.Millisecond))
+			}
+		})
+		startTLSCommandClient(t, clientAddrs, 100000, 1, roots)
+
+		waitFor(t, "the replica to commit blocks", func() bool {
+			commits, _, _ := recorder.totals()
+			return commits >= 50
+		})
+		stop()
+		return atomic.LoadUint64(&counter.timeouts)
+	}
+
+	quic, err := backend.GetTransport("quic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("NoTLS", func(t *testing.T) { run(t, quic, false) })
+	t.Run("WithTLS", func(t *testing.T) { run(t, quic, true) })
+	t.Run("PacketLoss", func(t *testing.T) {
+		// QUIC retransmits most lost packets long before the view timer of one second expires,
+		// but a view may time out if a packet and its retransmission are both lost.
+		timeouts := run(t, backend.NewQUICTransport(backend.QUICOptions{PacketLoss: 0.01}), true)
+		t.Logf("%d view timeouts with 1%% packet loss", timeouts)
+		if timeouts > 5 {
+			t.Errorf("the replica timed out %d times while committing 50 blocks with 1%% packet loss", timeouts)
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package replica
+
+import (
+	"sort"
+	"time"
+
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+)
+
+// seqWindow is the number of sequence numbers up to the highest one in a seqSet that are remembered individually.
+// It must be a multiple of 64.
+const seqWindow = 1 << 12
+
+// seqSet is a set of sequence numbers of the commands from a client, such as the commands that have been proposed.
+// Since the commands may be added out of order, for example when a client retries a command,
+// the sequence numbers are remembered individually, but only within seqWindow of the highest one.
+// Older sequence numbers are considered to be in the set.
+// The window is a bitmap, such that the size of the set is fixed regardless of the number of sequence numbers added.
+type seqSet struct {
+	highest uint64
+	bits    [seqWindow / 64]uint64 // bit seq%seqWindow is set if seq is in the window and in the set
+}
+
+func (s *seqSet) contains(seq uint64) bool {
+	if seq+seqWindow <= s.highest {
+		return true
+	}
+	if seq > s.highest {
+		return false
+	}
+	return s.bit(seq)
+}
+
+func (s *seqSet) bit(seq uint64) bool {
+	i := seq % seqWindow
+	return s.bits[i/64]&(1<<(i%64)) != 0
+}
+
+func (s *seqSet) add(seq uint64) {
+	if seq+seqWindow <= s.highest {
+		return
+	}
+	if seq > s.highest {
+		// the bits of the sequence numbers that enter the window hold sequence numbers that leave it.
+		if seq-s.highest >= seqWindow {
+			s.bits = [seqWindow / 64]uint64{}
+		} else {
+			for old := s.highest + 1; old <= seq; old++ {
+				i := old % seqWindow
+				s.bits[i/64] &^= 1 << (i % 64)
+			}
+		}
+		s.highest = seq
+	}
+	i := seq % seqWindow
+	s.bits[i/64] |= 1 << (i % 64)
+}
+
+// window returns the sequence numbers in the set that are within the window, in increasing order.
+func (s *seqSet) window() (seqs []uint64) {
+	var low uint64
+	if s.highest >= seqWindow {
+		low = s.highest - seqWindow + 1
+	}
+	for seq := low; seq <= s.highest; seq++ {
+		if s.bit(seq) {
+			seqs = append(seqs, seq)
+		}
+	}
+	return seqs
+}
+
+// clientSeqs holds a seqSet for each client.
+type clientSeqs map[uint32]*seqSet
+
+func (c clientSeqs) contains(id cmdID) bool {
+	s, ok := c[id.clientID]
+	return ok && s.contains(id.sequenceNum)
+}
+
+func (c clientSeqs) add(id cmdID) {
+	s, ok := c[id.clientID]
+	if !ok {
+		s = new(seqSet)
+		c[id.clientID] = s
+	}
+	s.add(id.sequenceNum)
+}
+
+// toProto returns the sets as an ExecutedCommands message. The clients and sequence numbers are sorted,
+// such that replicas with the same sets produce the same message.
+func (c clientSeqs) toProto() *clientpb.ExecutedCommands {
+	msg := &clientpb.ExecutedCommands{}
+	for clientID, s := range c {
+		msg.Clients = append(msg.Clients, &clientpb.ExecutedWindow{ClientID: clientID, Highest: s.highest, SequenceNumbers: s.window()})
+	}
+	sort.Slice(msg.Clients, func(i, j int) bool { return msg.Clients[i].GetClientID() < msg.Clients[j].GetClientID() })
+	return msg
+}
+
+// clientSeqsFromProto returns the sets stored in an ExecutedCommands message.
+func clientSeqsFromProto(msg *clientpb.ExecutedCommands) clientSeqs {
+	c := make(clientSeqs)
+	for _, window := range msg.GetClients() {
+		s := &seqSet{highest: window.GetHighest()}
+		for _, seq := range window.GetSequenceNumbers() {
+			if seq <= s.highest && seq+seqWindow > s.highest {
+				i := seq % seqWindow
+				s.bits[i/64] |= 1 << (i % 64)
+			}
+		}
+		c[window.GetClientID()] = s
+	}
+	return c
+}
+
+// proposedWindow holds the sequence numbers of the commands from a client that have been proposed in blocks that have
+// not been committed or forked yet, along with the time when they were proposed. Like a seqSet, it only remembers the
+// sequence numbers within seqWindow of the highest one, such that its size is bounded, and the older sequence numbers
+// are considered to be in the set.
+type proposedWindow struct {
+	highest uint64
+	seqs    map[uint64]time.Time
+}
+
+// proposedSeqs holds a proposedWindow for each client. The commands expire after the TTL, such that a command whose
+// block is neither committed nor forked, for example because the block was abandoned after a view change, can be
+// proposed again when the client retries it.
+type proposedSeqs struct {
+	ttl     time.Duration
+	clock   eventloop.Clock // tells when the commands are proposed and expire; set when the cache is initialized
+	clients map[uint32]*proposedWindow
+}
+
+func newProposedSeqs(ttl time.Duration) *proposedSeqs {
+	return &proposedSeqs{ttl: ttl, clients: make(map[uint32]*proposedWindow)}
+}
+
+// contains returns true if the command has been proposed, and has not been released or expired.
+func (p *proposedSeqs) contains(id cmdID) bool {
+	w, ok := p.clients[id.clientID]
+	if !ok || id.sequenceNum > w.highest {
+		return false
+	}
+	if id.sequenceNum+seqWindow <= w.highest {
+		return true
+	}
+	proposed, ok := w.seqs[id.sequenceNum]
+	if !ok {
+		return false
+	}
+	if p.clock.Now().Sub(proposed) >= p.ttl {
+		delete(w.seqs, id.sequenceNum)
+		return false
+	}
+	return true
+}
+
+// add records that the command has been proposed.
+func (p *proposedSeqs) add(id cmdID) {
+	w, ok := p.clients[id.clientID]
+	if !ok {
+		w = &proposedWindow{seqs: make(map[uint64]time.Time)}
+		p.clients[id.clientID] = w
+	}
+	if id.sequenceNum+seqWindow <= w.highest {
+		return
+	}
+	if id.sequenceNum > w.highest {
+		// forget the sequence numbers that leave the window.
+		if id.sequenceNum-w.highest >= seqWindow {
+			w.seqs = make(map[uint64]time.Time)
+		} else {
+			for seq := w.highest + 1; seq <= id.sequenceNum; seq++ {
+				if seq >= seqWindow {
+					delete(w.seqs, seq-seqWindow)
+				}
+			}
+		}
+		w.highest = id.sequenceNum
+	}
+	w.seqs[id.sequenceNum] = p.clock.Now()
+}
+
+// release forgets that the command has been proposed, because its block was committed or forked.
+// A released command that is within the window can be proposed again.
+func (p *proposedSeqs) release(id cmdID) {
+	if w, ok := p.clients[id.clientID]; ok {
+		delete(w.seqs, id.sequenceNum)
+	}
+}
+
+// len returns the number of commands of the client that are remembered individually.
+func (p *proposedSeqs) len(clientID uint32) int {
+	if w, ok := p.clients[clientID]; ok {
+		return len(w.seqs)
+	}
+	return 0
+}
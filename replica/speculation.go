@@ -0,0 +1,176 @@
+package replica
+
+import (
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/proto/clientpb"
+	"github.com/relab/hotstuff/synchronizer"
+	"google.golang.org/protobuf/proto"
+)
+
+// speculation executes the blocks with a SpeculativeExecutor as soon as they are certified, that is, when they become
+// the leaf block of the synchronizer, rather than waiting for them to be committed. It tracks the speculated blocks,
+// which extend the committed block. They are confirmed when they are committed, and rolled back to the committed block
+// when one of them is forked, or when a block that does not extend them is certified or committed.
+//
+// The speculation replaces the client server as the executor and the command cache as the fork handler.
+// The committed blocks are still executed by the client server, which takes the results of the speculated commands
+// instead of executing them again.
+type speculation struct {
+	mods       *consensus.Modules
+	srv        *clientSrv
+	app        SpeculativeExecutor
+	committed  *consensus.Block   // nil until a block is committed after a snapshot has been restored
+	blocks     []speculatedBlock  // the speculated blocks that have not been committed, in the order they extend each other
+	executed   map[cmdID]struct{} // the commands executed by the speculated blocks
+	confirming map[cmdID][]byte   // the results of the commands of the block that is being committed
+	rollbacks  int                // the number of times that speculated blocks were rolled back
+}
+
+// speculatedBlock is a block whose commands have been executed speculatively, along with their results.
+type speculatedBlock struct {
+	block   *consensus.Block
+	results map[cmdID][]byte
+}
+
+func newSpeculation(srv *clientSrv, app SpeculativeExecutor) *speculation {
+	return &speculation{
+		srv:       srv,
+		app:       app,
+		committed: consensus.GetGenesis(),
+		executed:  make(map[cmdID]struct{}),
+	}
+}
+
+// InitConsensusModule gives the module a reference to the Modules object.
+// It also allows the module to set module options using the OptionsBuilder.
+func (s *speculation) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
+	s.mods = mods
+	// the synchronizer changes the view after it has updated the highQC, which certifies its leaf block.
+	s.mods.EventLoop().RegisterHandler(synchronizer.ViewChangeEvent{}, func(_ interface{}) {
+		s.speculate(s.mods.Synchronizer().LeafBlock())
+	})
+}
+
+// speculate executes the blocks from the last speculated block, or the committed block, up to the certified block.
+func (s *speculation) speculate(certified *consensus.Block) {
+	tip := s.committed
+	if len(s.blocks) > 0 {
+		tip = s.blocks[len(s.blocks)-1].block
+	}
+	if tip == nil || certified == nil || certified.View() <= tip.View() {
+		return
+	}
+	var chain []*consensus.Block
+	block := certified
+	for block.View() > tip.View() {
+		chain = append(chain, block)
+		parent, ok := s.mods.BlockChain().LocalGet(block.Parent())
+		if !ok {
+			// the blocks are speculated when the missing blocks have been fetched and a later block is certified.
+			return
+		}
+		block = parent
+	}
+	if block.Hash() != tip.Hash() {
+		if len(s.blocks) > 0 {
+			// the certified block does not extend the speculated blocks, but it may extend the committed block.
+			s.rollback()
+			s.speculate(certified)
+		}
+		return
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		s.execute(chain[i])
+	}
+}
+
+// execute executes the commands of the block speculatively. The commands that have been executed already,
+// by a committed or a speculated block, are skipped, as they would be when the block is committed.
+func (s *speculation) execute(block *consensus.Block) {
+	batch := new(clientpb.Batch)
+	err := proto.UnmarshalOptions{AllowPartial: true}.Unmarshal([]byte(block.Command()), batch)
+	if err != nil {
+		s.mods.Logger().Errorf("Failed to unmarshal command: %v", err)
+	}
+	results := make(map[cmdID][]byte)
+	s.srv.mut.Lock()
+	for _, cmd := range batch.GetCommands() {
+		id := cmdID{cmd.GetClientID(), cmd.GetSequenceNumber()}
+		if _, ok := s.executed[id]; ok || s.srv.cmdCache.isExecuted(id) {
+			continue
+		}
+		s.executed[id] = struct{}{}
+		results[id] = s.app.Exec(block.View(), cmd.GetData())
+	}
+	s.srv.mut.Unlock()
+	s.blocks = append(s.blocks, speculatedBlock{block: block, results: results})
+}
+
+// Exec confirms the block if it was speculated, or else rolls back the speculated blocks,
+// and then executes the block with the client server.
+func (s *speculation) Exec(block *consensus.Block) {
+	if len(s.blocks) > 0 && s.blocks[0].block.Hash() == block.Hash() {
+		confirmed := s.blocks[0]
+		s.blocks = s.blocks[1:]
+		// the block is confirmed before it is executed, since the state may be snapshotted when it has been executed.
+		s.srv.mut.Lock()
+		s.app.Confirm(block.View())
+		s.srv.mut.Unlock()
+		s.confirming = confirmed.results
+		s.srv.Exec(block)
+		s.confirming = nil
+		for id := range confirmed.results {
+			delete(s.executed, id)
+		}
+	} else {
+		s.rollback()
+		s.srv.Exec(block)
+	}
+	s.committed = block
+}
+
+// result returns the result of a command of the block that is being committed, if it was executed speculatively.
+func (s *speculation) result(id cmdID) ([]byte, bool) {
+	result, ok := s.confirming[id]
+	return result, ok
+}
+
+// Fork rolls back the speculated blocks if the forked block is one of them,
+// and hands the block to the command cache, which requeues its commands.
+func (s *speculation) Fork(block *consensus.Block) {
+	for _, speculated := range s.blocks {
+		if speculated.block.Hash() == block.Hash() {
+			s.rollback()
+			break
+		}
+	}
+	s.srv.cmdCache.Fork(block)
+}
+
+// rollback rolls back the speculated blocks to the committed block.
+func (s *speculation) rollback() {
+	if len(s.blocks) == 0 {
+		return
+	}
+	s.mods.Logger().Infof("Rolling back %d speculated blocks to view %d", len(s.blocks), s.committed.View())
+	s.srv.mut.Lock()
+	s.app.Rollback(s.committed.View())
+	s.srv.mut.Unlock()
+	s.blocks = nil
+	s.executed = make(map[cmdID]struct{})
+	s.rollbacks++
+}
+
+// reset forgets the speculated blocks without rolling them back, since the application has restored a snapshot,
+// which discards them. The blocks are speculated again when the blocks after the snapshot are committed.
+// It must be called with the mutex of the client server held.
+func (s *speculation) reset() {
+	s.committed = nil
+	s.blocks = nil
+	s.executed = make(map[cmdID]struct{})
+}
+
+var (
+	_ consensus.ExecutorExt    = (*speculation)(nil)
+	_ consensus.ForkHandlerExt = (*speculation)(nil)
+)
@@ -0,0 +1,280 @@
+package replica
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/synchronizer"
+)
+
+const (
+	// stateTransferTimeout limits how long a replica waits for the snapshots of the other replicas.
+	stateTransferTimeout = 30 * time.Second
+	// snapshotChunkSize is the size of the chunks in which a snapshot is requested.
+	snapshotChunkSize = 256 << 10
+	// maxSnapshots is the number of snapshots that are kept, such that a replica that is downloading a snapshot
+	// can finish even if a new snapshot is taken in the meantime.
+	maxSnapshots = 2
+)
+
+// StateTransferred is emitted when the replica has installed a snapshot of the state of the other replicas,
+// after falling too far behind to fetch the blocks that it was missing.
+type StateTransferred struct {
+	Block  *consensus.Block // the block after which the snapshot was taken
+	Source hotstuff.ID      // the replica that the snapshot was downloaded from
+	Size   int              // the size of the snapshot in bytes
+}
+
+// snapshot is a snapshot of the state of the replica after executing a block.
+type snapshot struct {
+	block     *consensus.Block
+	qc        consensus.QuorumCert
+	certified bool // qc certifies the block; a snapshot is only served once it is certified
+	data      []byte
+	digest    consensus.Hash
+}
+
+// installSnapshotRequest asks the event loop to install a snapshot that was downloaded from another replica,
+// and whose digest was verified. The response is nil, and the error tells why the snapshot was not installed.
+type installSnapshotRequest struct {
+	info   backend.SnapshotInfo
+	source hotstuff.ID
+	data   []byte
+}
+
+// stateTransferEndedEvent is added to the event loop when a state transfer has ended, whether it succeeded or not.
+type stateTransferEndedEvent struct{}
+
+// errSnapshotNotNeeded is returned when the replica has caught up while the snapshot was downloaded.
+var errSnapshotNotNeeded = errors.New("the replica has caught up")
+
+// stateTransfer takes snapshots of the executed state and serves them to the other replicas,
+// and installs a snapshot of the other replicas when the replica has fallen too far behind.
+//
+// A snapshot is taken after executing the first block whose view is at least interval views after the block of the
+// previous snapshot, such that the replicas take their snapshots after the same blocks.
+// The block of a snapshot is verified by its QC, and the state is verified by requiring that f+1 replicas,
+// at least one of which is correct, report the same digest for the snapshot of the block.
+type stateTransfer struct {
+	mods     *consensus.Modules
+	cfg      *backend.Config
+	srv      *clientSrv
+	interval consensus.View
+	lag      consensus.View
+
+	mut       sync.Mutex
+	snapshots []*snapshot // the latest snapshots, oldest first
+
+	// the following fields are only accessed by the executor of the blocks, and by the event loop while no blocks are
+	// being executed.
+	lastView consensus.View // the view of the block of the latest snapshot
+	running  bool           // a state transfer is in progress
+}
+
+func newStateTransfer(conf Config, srv *clientSrv, cfg *backend.Config) *stateTransfer {
+	lag := conf.StateTransferLag
+	if lag == 0 {
+		lag = 2 * conf.SnapshotInterval
+	}
+	return &stateTransfer{
+		cfg:      cfg,
+		srv:      srv,
+		interval: consensus.View(conf.SnapshotInterval),
+		lag:      consensus.View(lag),
+	}
+}
+
+// InitConsensusModule gives the module a reference to the Modules object.
+// It also allows the module to set module options using the OptionsBuilder.
+func (st *stateTransfer) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
+	st.mods = mods
+	st.mods.EventLoop().RegisterObserver(synchronizer.ViewChangeEvent{}, func(event interface{}) {
+		st.checkLag(event.(synchronizer.ViewChangeEvent).View)
+	})
+	st.mods.EventLoop().RegisterHandler(installSnapshotRequest{}, func(event interface{}) {
+		r := event.(*eventloop.Request)
+		r.Respond(nil, st.install(r.Payload.(installSnapshotRequest)))
+	})
+	st.mods.EventLoop().RegisterHandler(stateTransferEndedEvent{}, func(_ interface{}) {
+		st.running = false
+	})
+	// the transfer goroutine waits for room, since the state transfer would never finish without the events.
+	st.mods.EventLoop().SetOverflowPolicy(installSnapshotRequest{}, eventloop.Block)
+	st.mods.EventLoop().SetOverflowPolicy(stateTransferEndedEvent{}, eventloop.Block)
+}
+
+// executed is called by the client server after it has executed a block.
+// It takes a snapshot if the block is due for one, and discards the blocks that are no longer needed.
+func (st *stateTransfer) executed(block *consensus.Block) {
+	st.mut.Lock()
+	for _, s := range st.snapshots {
+		if !s.certified && block.QuorumCert().BlockHash() == s.block.Hash() {
+			s.qc = block.QuorumCert()
+			s.certified = true
+		}
+	}
+	st.mut.Unlock()
+
+	if block.View() < st.lastView+st.interval {
+		return
+	}
+	st.lastView = block.View()
+	data, err := st.srv.snapshot()
+	if err != nil {
+		st.mods.Logger().Warnf("Failed to take snapshot: %v", err)
+		return
+	}
+	st.add(&snapshot{block: block, data: data, digest: sha256.Sum256(data)})
+	if block.View() > st.lag {
+		st.mods.BlockChain().DiscardBelow(block.View() - st.lag)
+	}
+}
+
+func (st *stateTransfer) add(s *snapshot) {
+	st.mut.Lock()
+	defer st.mut.Unlock()
+	st.snapshots = append(st.snapshots, s)
+	if len(st.snapshots) > maxSnapshots {
+		st.snapshots = st.snapshots[len(st.snapshots)-maxSnapshots:]
+	}
+}
+
+// LatestSnapshot returns a description of the most recent snapshot whose block is certified.
+func (st *stateTransfer) LatestSnapshot() (info backend.SnapshotInfo, ok bool) {
+	st.mut.Lock()
+	defer st.mut.Unlock()
+	for i := len(st.snapshots) - 1; i >= 0; i-- {
+		s := st.snapshots[i]
+		if s.certified {
+			return backend.SnapshotInfo{Block: s.block, QC: s.qc, Digest: s.digest, Size: len(s.data)}, true
+		}
+	}
+	return info, false
+}
+
+// Snapshot returns the snapshot of the state after the given block.
+func (st *stateTransfer) Snapshot(block consensus.Hash) (data []byte, ok bool) {
+	st.mut.Lock()
+	defer st.mut.Unlock()
+	for _, s := range st.snapshots {
+		if s.block.Hash() == block {
+			return s.data, true
+		}
+	}
+	return nil, false
+}
+
+// checkLag starts a state transfer if the view is too far ahead of the committed block.
+func (st *stateTransfer) checkLag(view consensus.View) {
+	if st.running {
+		return
+	}
+	if _, ok := st.mods.Consensus().(consensus.CommittedBlockSetter); !ok {
+		return
+	}
+	committed := st.mods.Consensus().CommittedBlock().View()
+	if view <= committed+st.lag {
+		return
+	}
+	st.running = true
+	st.mods.Logger().Infof("The committed block is %d views behind; requesting the snapshots of the other replicas", view-committed)
+	go st.transfer(committed)
+}
+
+// transfer downloads the newest snapshot that enough replicas agree on, and is newer than the committed view.
+// It runs in its own goroutine, such that the snapshot is not replaced by the other replicas while the event loop
+// of this replica is busy, and requests the event loop to install the snapshot when it has been downloaded.
+func (st *stateTransfer) transfer(committed consensus.View) {
+	defer st.mods.EventLoop().AddEvent(stateTransferEndedEvent{})
+	ctx, cancel := context.WithTimeout(context.Background(), stateTransferTimeout)
+	defer cancel()
+	info, sources, ok := st.chooseSnapshot(st.cfg.SnapshotInfos(ctx), committed)
+	if !ok {
+		st.mods.Logger().Infof("State transfer failed: not enough replicas agree on a newer snapshot")
+		return
+	}
+	for _, id := range sources {
+		data, err := st.cfg.FetchSnapshot(ctx, id, info, snapshotChunkSize)
+		if err != nil {
+			st.mods.Logger().Infof("State transfer: %v", err)
+			continue
+		}
+		if sha256.Sum256(data) != info.Digest {
+			st.mods.Logger().Infof("State transfer: the snapshot from replica %d does not match its digest", id)
+			continue
+		}
+		// the snapshots from the other sources have the same digest, so they would not fare better.
+		_, err = st.mods.EventLoop().Request(ctx, installSnapshotRequest{info: info, source: id, data: data})
+		if err != nil && !errors.Is(err, errSnapshotNotNeeded) {
+			st.mods.Logger().Warnf("State transfer failed: %v", err)
+		}
+		return
+	}
+	st.mods.Logger().Infof("State transfer failed: the snapshot could not be downloaded")
+}
+
+// chooseSnapshot returns the newest of the snapshots that are newer than the committed view, whose block is certified,
+// and whose digest is reported by at least f+1 replicas, along with the replicas that reported it.
+func (st *stateTransfer) chooseSnapshot(infos map[hotstuff.ID]backend.SnapshotInfo, committed consensus.View) (best backend.SnapshotInfo, sources []hotstuff.ID, ok bool) {
+	type snapshotKey struct {
+		block  consensus.Hash
+		digest consensus.Hash
+	}
+	reporters := make(map[snapshotKey][]hotstuff.ID)
+	for id, info := range infos {
+		if info.Block.View() <= committed {
+			continue
+		}
+		key := snapshotKey{info.Block.Hash(), info.Digest}
+		reporters[key] = append(reporters[key], id)
+	}
+	for key, ids := range reporters {
+		info := infos[ids[0]]
+		if len(ids) <= hotstuff.NumFaulty(st.mods.Configuration().Len()) {
+			continue
+		}
+		if ok && info.Block.View() <= best.Block.View() {
+			continue
+		}
+		if info.QC.BlockHash() != key.block || !st.mods.Crypto().VerifyQuorumCert(info.QC) {
+			st.mods.Logger().Infof("The QC of the snapshot of block %.8s is invalid", key.block)
+			continue
+		}
+		best, sources, ok = info, ids, true
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i] < sources[j] })
+	return best, sources, ok
+}
+
+// install installs the snapshot, unless the replica has caught up in the meantime.
+// The replica then follows the chain from the block of the snapshot.
+func (st *stateTransfer) install(event installSnapshotRequest) error {
+	block := event.info.Block
+	if block.View() <= st.mods.Consensus().CommittedBlock().View() {
+		return errSnapshotNotNeeded
+	}
+	// the blocks in the execution pipeline are older than the snapshot, and must not be executed on top of it.
+	st.srv.awaitPipeline()
+	if err := st.srv.restore(event.data); err != nil {
+		return err
+	}
+	st.mods.BlockChain().Store(block)
+	st.mods.BlockChain().DiscardBelow(block.View())
+	st.mods.Consensus().(consensus.CommittedBlockSetter).SetCommittedBlock(block)
+	st.lastView = block.View()
+	st.add(&snapshot{block: block, qc: event.info.QC, certified: true, data: event.data, digest: event.info.Digest})
+
+	st.mods.Logger().Infof("Installed the snapshot of block %.8s in view %d from replica %d", block.Hash(), block.View(), event.source)
+	st.mods.Synchronizer().UpdateHighQC(event.info.QC)
+	st.mods.Synchronizer().AdvanceView(consensus.NewSyncInfo().WithQC(event.info.QC))
+	st.mods.EventLoop().AddEvent(StateTransferred{Block: block, Source: event.source, Size: len(event.data)})
+	return nil
+}
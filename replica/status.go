@@ -0,0 +1,262 @@
+package replica
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/backend"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/synchronizer"
+)
+
+// defaultStallTimeouts is the number of view timeouts without a view change after which a replica is unhealthy.
+const defaultStallTimeouts = 3
+
+// Status is the state of a replica, as reported by the /status endpoint of the status server.
+type Status struct {
+	ID               hotstuff.ID                     `json:"id"`
+	View             consensus.View                  `json:"view"`
+	HighQCView       consensus.View                  `json:"high_qc_view"`
+	CommittedView    consensus.View                  `json:"committed_view"`
+	CommittedBlock   string                          `json:"committed_block"`   // the hash of the committed block in hex
+	ExecutedCommands uint64                          `json:"executed_commands"` // the number of commands executed
+	StateHash        string                          `json:"state_hash"`        // the hash of the executed commands in hex
+	LastViewChange   time.Time                       `json:"last_view_change"`  // zero if the view has not changed yet
+	ViewTimeoutMs    int64                           `json:"view_timeout_ms"`   // the timeout of the current view
+	EventQueue       int                             `json:"event_queue"`       // the number of events waiting in the event loop
+	ExecutionQueue   int                             `json:"execution_queue"`   // the number of committed blocks waiting to be executed
+	CommandCache     CommandCacheStatus              `json:"command_cache"`
+	Peers            []PeerStatus                    `json:"peers"`
+	Healthy          bool                            `json:"healthy"`
+	Modules          map[string]string               `json:"modules"`
+	Version          string                          `json:"version"`
+	GoVersion        string                          `json:"go_version"`
+	Events           map[string]eventloop.EventStats `json:"events"` // the processing statistics of each event type; the durations are in nanoseconds
+}
+
+// CommandCacheStatus is the state of the cache of commands that wait to be proposed.
+type CommandCacheStatus struct {
+	Commands int    `json:"commands"`
+	Bytes    int    `json:"bytes"`    // the bytes accounted for the commands, including their payloads
+	Rejected uint64 `json:"rejected"` // the number of commands rejected because the cache was full
+	Evicted  uint64 `json:"evicted"`  // the number of commands evicted to make room for new commands
+	Requeued uint64 `json:"requeued"` // the number of commands of forked blocks that were put back in the cache
+}
+
+// PeerStatus is the state of the connection to another replica.
+type PeerStatus struct {
+	ID        hotstuff.ID `json:"id"`
+	Connected bool        `json:"connected"`
+	Reachable bool        `json:"reachable"` // false if the replica has missed too many health checks
+}
+
+// statusServer serves the status of the replica over HTTP.
+// The status is recorded by observers on the event loop, and is read by the HTTP handlers under a mutex,
+// such that the event loop is never blocked by a status request.
+type statusServer struct {
+	mods          *consensus.Modules
+	cfg           *backend.Config
+	clientSrv     *clientSrv
+	stallTimeouts int
+	server        *http.Server
+
+	mut         sync.Mutex
+	status      Status // the fields that are recorded by the event loop
+	viewTimeout time.Duration
+	unreachable map[hotstuff.ID]bool
+	peers       []hotstuff.ID // the other replicas; set when the replica connects
+	n           int           // the number of replicas in the configuration
+}
+
+func newStatusServer(conf Config, cfg *backend.Config, clientSrv *clientSrv) *statusServer {
+	stallTimeouts := conf.StallTimeouts
+	if stallTimeouts <= 0 {
+		stallTimeouts = defaultStallTimeouts
+	}
+	s := &statusServer{
+		cfg:           cfg,
+		clientSrv:     clientSrv,
+		stallTimeouts: stallTimeouts,
+		unreachable:   make(map[hotstuff.ID]bool),
+		status: Status{
+			ID:        conf.ID,
+			Modules:   make(map[string]string, len(conf.Modules)),
+			Version:   buildVersion(),
+			GoVersion: runtime.Version(),
+		},
+	}
+	for kind, name := range conf.Modules {
+		s.status.Modules[kind] = name
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/healthz", s.handleHealth)
+	s.server = &http.Server{Handler: mux}
+	return s
+}
+
+// InitConsensusModule gives the module a reference to the Modules object.
+// It also allows the module to set module options using the OptionsBuilder.
+func (s *statusServer) InitConsensusModule(mods *consensus.Modules, _ *consensus.OptionsBuilder) {
+	s.mods = mods
+	s.mods.EventLoop().EnableStats()
+	s.mods.EventLoop().RegisterObserver(synchronizer.ViewChangeEvent{}, func(event interface{}) {
+		s.onViewChange(event.(synchronizer.ViewChangeEvent))
+	})
+	s.mods.EventLoop().RegisterObserver(consensus.CommitEvent{}, func(event interface{}) {
+		s.onCommit(event.(consensus.CommitEvent))
+	})
+	s.mods.EventLoop().RegisterObserver(backend.ReplicaUnreachable{}, func(event interface{}) {
+		s.setReachable(event.(backend.ReplicaUnreachable).ID, false)
+	})
+	s.mods.EventLoop().RegisterObserver(backend.ReplicaReachable{}, func(event interface{}) {
+		s.setReachable(event.(backend.ReplicaReachable).ID, true)
+	})
+}
+
+func (s *statusServer) onViewChange(event synchronizer.ViewChangeEvent) {
+	now := time.Now()
+	var timeout time.Duration
+	if deadline, ok := s.mods.Synchronizer().ViewContext().Deadline(); ok {
+		timeout = deadline.Sub(now)
+	}
+	highQC := s.mods.Synchronizer().HighQC()
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.status.View = event.View
+	s.status.HighQCView = highQC.View()
+	s.status.LastViewChange = now
+	s.viewTimeout = timeout
+}
+
+func (s *statusServer) onCommit(event consensus.CommitEvent) {
+	s.clientSrv.mut.Lock()
+	executed := s.clientSrv.height
+	stateHash := s.clientSrv.hash.Sum(nil)
+	s.clientSrv.mut.Unlock()
+
+	hash := event.Block.Hash()
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.status.CommittedView = event.Block.View()
+	s.status.CommittedBlock = fmt.Sprintf("%x", hash[:])
+	s.status.ExecutedCommands = executed
+	s.status.StateHash = fmt.Sprintf("%x", stateHash)
+}
+
+func (s *statusServer) setReachable(id hotstuff.ID, reachable bool) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.unreachable[id] = !reachable
+}
+
+// setPeers records the replicas in the configuration. It is called when the replica connects to them.
+func (s *statusServer) setPeers(replicas []backend.ReplicaInfo) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.peers = s.peers[:0]
+	for _, r := range replicas {
+		if r.ID != s.status.ID {
+			s.peers = append(s.peers, r.ID)
+		}
+	}
+	sort.Slice(s.peers, func(i, j int) bool { return s.peers[i] < s.peers[j] })
+	s.n = len(replicas)
+}
+
+// snapshot returns the current status of the replica.
+func (s *statusServer) snapshot() Status {
+	s.mut.Lock()
+	status := s.status
+	viewTimeout := s.viewTimeout
+	unreachable := make(map[hotstuff.ID]bool, len(s.unreachable))
+	for id, u := range s.unreachable {
+		unreachable[id] = u
+	}
+	peers := append([]hotstuff.ID(nil), s.peers...)
+	n := s.n
+	s.mut.Unlock()
+
+	status.ViewTimeoutMs = viewTimeout.Milliseconds()
+	status.EventQueue = s.mods.EventLoop().Len()
+	if pipeline := s.clientSrv.pipeline; pipeline != nil {
+		status.ExecutionQueue = pipeline.len()
+	}
+	status.Events = s.mods.EventLoop().Stats()
+	stats := s.clientSrv.cmdCache.getStats()
+	status.CommandCache = CommandCacheStatus{
+		Commands: stats.commands,
+		Bytes:    stats.bytes,
+		Rejected: stats.rejected,
+		Evicted:  stats.evicted,
+		Requeued: stats.requeued,
+	}
+	status.Modules = make(map[string]string, len(s.status.Modules))
+	for kind, name := range s.status.Modules {
+		status.Modules[kind] = name
+	}
+
+	status.Peers = make([]PeerStatus, 0, len(peers))
+	connected := 1 // the replica itself
+	for _, id := range peers {
+		peer := PeerStatus{ID: id, Connected: s.cfg.IsConnected(id), Reachable: !unreachable[id]}
+		if peer.Connected {
+			connected++
+		}
+		status.Peers = append(status.Peers, peer)
+	}
+
+	// the replica participates if it is connected to a quorum, and the view has changed recently.
+	advancing := !status.LastViewChange.IsZero() &&
+		time.Since(status.LastViewChange) <= time.Duration(s.stallTimeouts)*viewTimeout
+	status.Healthy = n > 0 && connected >= hotstuff.QuorumSize(n) && advancing
+	return status
+}
+
+func (s *statusServer) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.snapshot()); err != nil {
+		s.mods.Logger().Infof("Failed to write status: %v", err)
+	}
+}
+
+func (s *statusServer) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	if !s.snapshot().Healthy {
+		http.Error(w, "unhealthy", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// serve serves the status on the listener until the server is closed.
+func (s *statusServer) serve(lis net.Listener) {
+	go func() {
+		if err := s.server.Serve(lis); err != nil && err != http.ErrServerClosed {
+			s.mods.Logger().Warnf("Status server failed: %v", err)
+		}
+	}()
+}
+
+func (s *statusServer) close() {
+	_ = s.server.Close()
+}
+
+// buildVersion returns the version of the main module of the binary, if it is known.
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Version
+	}
+	return "unknown"
+}
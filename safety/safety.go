@@ -0,0 +1,102 @@
+// Package safety provides a file-backed implementation of the consensus.SafetyStorage interface.
+package safety
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/internal/atomicfile"
+	"github.com/relab/hotstuff/internal/proto/hotstuffpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// fileStorage keeps the safety state in a single file, which is replaced atomically and synced to stable storage
+// on every update. The file holds the view of the last vote as 8 bytes in big endian,
+// followed by the last executed block in the protobuf encoding, if a block has been executed.
+type fileStorage struct {
+	mut   sync.Mutex
+	path  string
+	state consensus.SafetyState
+}
+
+// NewFile returns a safety storage that keeps the state in the file with the given path.
+// The file and its directory are created on the first update. The file must not be shared with other replicas.
+func NewFile(path string) consensus.SafetyStorage {
+	return &fileStorage{path: path}
+}
+
+// Load returns the persisted state, or the zero state if the file does not exist.
+func (s *fileStorage) Load() (consensus.SafetyState, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s.state, nil
+	} else if err != nil {
+		return consensus.SafetyState{}, fmt.Errorf("failed to read the safety state: %w", err)
+	}
+	if len(data) < 8 {
+		return consensus.SafetyState{}, fmt.Errorf("the safety state in %s is truncated", s.path)
+	}
+	state := consensus.SafetyState{LastVote: consensus.View(binary.BigEndian.Uint64(data))}
+	if len(data) > 8 {
+		pb := new(hotstuffpb.Block)
+		if err := proto.Unmarshal(data[8:], pb); err != nil {
+			return consensus.SafetyState{}, fmt.Errorf("failed to unmarshal the last executed block: %w", err)
+		}
+		if state.Executed, err = hotstuffpb.BlockFromProto(pb); err != nil {
+			return consensus.SafetyState{}, fmt.Errorf("failed to unmarshal the last executed block: %w", err)
+		}
+	}
+	s.state = state
+	return state, nil
+}
+
+// StoreVote persists the view of the last vote.
+func (s *fileStorage) StoreVote(view consensus.View) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	state := s.state
+	state.LastVote = view
+	return s.write(state)
+}
+
+// StoreExecuted persists the last executed block.
+func (s *fileStorage) StoreExecuted(block *consensus.Block) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	state := s.state
+	state.Executed = block
+	return s.write(state)
+}
+
+// write replaces the file with the state, and keeps the state in memory if it was written.
+// It must be called with the mutex held.
+func (s *fileStorage) write(state consensus.SafetyState) error {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, uint64(state.LastVote))
+	if state.Executed != nil {
+		block, err := proto.Marshal(hotstuffpb.BlockToProto(state.Executed))
+		if err != nil {
+			return fmt.Errorf("failed to marshal the last executed block: %w", err)
+		}
+		data = append(data, block...)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create the directory of the safety state: %w", err)
+	}
+	if err := atomicfile.WriteFile(s.path, data); err != nil {
+		return err
+	}
+	s.state = state
+	return nil
+}
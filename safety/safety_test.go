@@ -0,0 +1,58 @@
+package safety_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/safety"
+)
+
+// TestFileStorage checks that the state stored by one file storage is loaded by another one that uses the same file,
+// as it is when a replica is restarted.
+func TestFileStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "safety")
+	storage := safety.NewFile(path)
+	state, err := storage.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.LastVote != 0 || state.Executed != nil {
+		t.Fatalf("got the state %+v before anything was stored, want the zero state", state)
+	}
+
+	genesis := consensus.GetGenesis()
+	block := consensus.NewBlock(genesis.Hash(), consensus.NewQuorumCert(nil, 0, genesis.Hash()), "foo", 1, 1)
+	if err := storage.StoreVote(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.StoreExecuted(block); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.StoreVote(3); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = safety.NewFile(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.LastVote != 3 {
+		t.Errorf("got the last vote in view %d, want view 3", state.LastVote)
+	}
+	if state.Executed == nil || state.Executed.Hash() != block.Hash() {
+		t.Errorf("got the last executed block %v, want %v", state.Executed, block)
+	}
+}
+
+// TestFileStorageTruncated checks that a file that does not hold a complete state is not loaded.
+func TestFileStorageTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "safety")
+	if err := os.WriteFile(path, []byte{0, 0, 1}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := safety.NewFile(path).Load(); err == nil {
+		t.Error("loaded a truncated state")
+	}
+}
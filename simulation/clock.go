@@ -0,0 +1,142 @@
+package simulation
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/relab/hotstuff/eventloop"
+)
+
+// clock is the virtual clock of a simulation, which is shared by all replicas. Its time only advances when the
+// simulation advances it to the time of the next timer, and the timers fire on the goroutine that advances it.
+type clock struct {
+	mut    sync.Mutex
+	now    time.Time
+	timers timerHeap
+	seq    uint64
+}
+
+func newClock(start time.Time) *clock {
+	return &clock{now: start}
+}
+
+// timer is a timer of the virtual clock.
+type timer struct {
+	clock *clock
+	at    time.Time
+	seq   uint64 // orders the timers that fire at the same time by when they were started
+	index int    // the index in the heap while the timer is active; -1 otherwise
+	f     func()
+}
+
+// timerHeap is a min-heap of the active timers, ordered by their times.
+type timerHeap []*timer
+
+func (h timerHeap) Len() int { return len(h) }
+
+func (h timerHeap) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].at.Before(h[j].at)
+}
+
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *timerHeap) Push(x interface{}) {
+	t := x.(*timer)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *timerHeap) Pop() interface{} {
+	old := *h
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	t.index = -1
+	return t
+}
+
+// Now returns the current virtual time.
+func (c *clock) Now() time.Time {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.now
+}
+
+// AfterFunc returns a timer that calls f when the clock has been advanced by d.
+func (c *clock) AfterFunc(d time.Duration, f func()) eventloop.Timer {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	t := &timer{clock: c, index: -1, f: f}
+	c.start(t, d)
+	return t
+}
+
+// start activates the timer to fire after the duration. The caller must hold the mutex.
+func (c *clock) start(t *timer, d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	t.at = c.now.Add(d)
+	t.seq = c.seq
+	c.seq++
+	if t.index < 0 {
+		heap.Push(&c.timers, t)
+	} else {
+		heap.Fix(&c.timers, t.index)
+	}
+}
+
+func (t *timer) Stop() bool {
+	t.clock.mut.Lock()
+	defer t.clock.mut.Unlock()
+	if t.index < 0 {
+		return false
+	}
+	heap.Remove(&t.clock.timers, t.index)
+	return true
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	t.clock.mut.Lock()
+	defer t.clock.mut.Unlock()
+	wasActive := t.index >= 0
+	t.clock.start(t, d)
+	return wasActive
+}
+
+// next returns the time of the next timer, and false if there are no active timers.
+func (c *clock) next() (time.Time, bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if len(c.timers) == 0 {
+		return time.Time{}, false
+	}
+	return c.timers[0].at, true
+}
+
+// advance moves the time forward to the given time, and fires the timers that are due by then, in the order of their
+// times. The timers that are started by the fired timers also fire if they are due.
+func (c *clock) advance(to time.Time) {
+	c.mut.Lock()
+	for len(c.timers) > 0 && !c.timers[0].at.After(to) {
+		t := heap.Pop(&c.timers).(*timer)
+		if t.at.After(c.now) {
+			c.now = t.at
+		}
+		c.mut.Unlock()
+		t.f()
+		c.mut.Lock()
+	}
+	if to.After(c.now) {
+		c.now = to
+	}
+	c.mut.Unlock()
+}
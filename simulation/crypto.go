@@ -0,0 +1,80 @@
+package simulation
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	"github.com/relab/hotstuff/consensus"
+)
+
+// verifiedSet holds the signatures that have been verified by any replica of a simulation.
+type verifiedSet struct {
+	mut  sync.Mutex
+	sigs map[consensus.Hash]struct{}
+}
+
+func newVerifiedSet() *verifiedSet {
+	return &verifiedSet{sigs: make(map[consensus.Hash]struct{})}
+}
+
+func (s *verifiedSet) contains(key consensus.Hash) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	_, ok := s.sigs[key]
+	return ok
+}
+
+func (s *verifiedSet) add(key consensus.Hash) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.sigs[key] = struct{}{}
+}
+
+// sharedVerifier is the crypto implementation of a replica in a simulation, which only verifies each signature once
+// for all replicas, since every replica would otherwise verify the same certificates. The outcome is the same, since
+// a signature that is valid for one replica is valid for all of them, and verifying the certificates is what takes
+// most of the time of a simulation.
+type sharedVerifier struct {
+	consensus.CryptoImpl
+	verified *verifiedSet
+}
+
+// InitConsensusModule gives the module a reference to the Modules object.
+// It also allows the module to set module options using the OptionsBuilder.
+func (v *sharedVerifier) InitConsensusModule(mods *consensus.Modules, opts *consensus.OptionsBuilder) {
+	if mod, ok := v.CryptoImpl.(consensus.Module); ok {
+		mod.InitConsensusModule(mods, opts)
+	}
+}
+
+// Verify verifies a signature given a hash.
+func (v *sharedVerifier) Verify(sig consensus.Signature, hash consensus.Hash) bool {
+	if sig == nil {
+		return false
+	}
+	return v.verify(sha256.Sum256(append(hash[:], sig.ToBytes()...)), func() bool {
+		return v.CryptoImpl.Verify(sig, hash)
+	})
+}
+
+// VerifyThresholdSignature verifies a threshold signature.
+func (v *sharedVerifier) VerifyThresholdSignature(sig consensus.ThresholdSignature, hash consensus.Hash) bool {
+	if sig == nil {
+		return false
+	}
+	return v.verify(sha256.Sum256(append(hash[:], sig.ToBytes()...)), func() bool {
+		return v.CryptoImpl.VerifyThresholdSignature(sig, hash)
+	})
+}
+
+// verify returns true if the signature with the given key has been verified, or if the verify function succeeds.
+func (v *sharedVerifier) verify(key consensus.Hash, verify func() bool) bool {
+	if v.verified.contains(key) {
+		return true
+	}
+	if !verify() {
+		return false
+	}
+	v.verified.add(key)
+	return true
+}
@@ -0,0 +1,65 @@
+package simulation
+
+import (
+	"fmt"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+)
+
+// Invariant checks a property of a simulation, and returns an error if it is violated.
+// The invariants are checked whenever all replicas are idle, before the virtual time advances.
+type Invariant func(s *Simulation) error
+
+// SingleChain returns an invariant that holds while the replicas have committed a single chain of blocks,
+// such that the blocks committed by each replica are a prefix of the blocks committed by any replica that has
+// committed more. The blocks committed by a replica before it crashed are included.
+func SingleChain() Invariant {
+	var chain []consensus.Hash
+	checked := make(map[hotstuff.ID]int) // the number of blocks of each replica that have been checked
+	return func(s *Simulation) error {
+		for _, id := range s.ids {
+			committed := s.nodes[id].committed
+			for i := checked[id]; i < len(committed); i++ {
+				hash := committed[i].Hash()
+				if i == len(chain) {
+					chain = append(chain, hash)
+				} else if chain[i] != hash {
+					return fmt.Errorf("replica %d committed block %.8s (view %d) at height %d, where block %.8s was committed",
+						id, hash, committed[i].View(), i+1, chain[i])
+				}
+			}
+			checked[id] = len(committed)
+		}
+		return nil
+	}
+}
+
+// BoundedViewDivergence returns an invariant that holds while the views of the replicas that have not crashed differ
+// by at most the given number of views.
+func BoundedViewDivergence(max consensus.View) Invariant {
+	return func(s *Simulation) error {
+		var (
+			low, high     consensus.View
+			lowID, highID hotstuff.ID
+			first         = true
+		)
+		for _, id := range s.ids {
+			if s.nodes[id].crashed {
+				continue
+			}
+			view := s.View(id)
+			if first || view < low {
+				low, lowID = view, id
+			}
+			if first || view > high {
+				high, highID = view, id
+			}
+			first = false
+		}
+		if high-low > max {
+			return fmt.Errorf("replica %d is in view %d, while replica %d is in view %d", lowID, low, highID, high)
+		}
+		return nil
+	}
+}
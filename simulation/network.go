@@ -0,0 +1,199 @@
+package simulation
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+)
+
+// Latency is a distribution of the one-way latencies of the messages in a simulated network.
+type Latency interface {
+	// Sample returns a latency drawn from the distribution with the random number generator of the simulation.
+	Sample(rnd *rand.Rand) time.Duration
+}
+
+type fixedLatency time.Duration
+
+func (l fixedLatency) Sample(_ *rand.Rand) time.Duration {
+	return time.Duration(l)
+}
+
+// FixedLatency returns a distribution that always has the given latency.
+func FixedLatency(latency time.Duration) Latency {
+	return fixedLatency(latency)
+}
+
+type uniformLatency struct {
+	min, max time.Duration
+}
+
+func (l uniformLatency) Sample(rnd *rand.Rand) time.Duration {
+	if l.max <= l.min {
+		return l.min
+	}
+	return l.min + time.Duration(rnd.Int63n(int64(l.max-l.min)+1))
+}
+
+// UniformLatency returns a distribution of latencies that are uniformly distributed between min and max.
+func UniformLatency(min, max time.Duration) Latency {
+	return uniformLatency{min, max}
+}
+
+type normalLatency struct {
+	mean, stddev time.Duration
+}
+
+func (l normalLatency) Sample(rnd *rand.Rand) time.Duration {
+	d := l.mean + time.Duration(rnd.NormFloat64()*float64(l.stddev))
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// NormalLatency returns a distribution of latencies that are normally distributed around the mean,
+// with the given standard deviation. The negative latencies are truncated to zero.
+func NormalLatency(mean, stddev time.Duration) Latency {
+	return normalLatency{mean, stddev}
+}
+
+// link is the direction of a connection between two replicas.
+type link struct {
+	from, to hotstuff.ID
+}
+
+// send delivers the message from a replica to another after a latency that is drawn from the latency distribution,
+// unless it is lost, or dropped because the replicas are disconnected. The messages on a link are delivered in the
+// order they were sent, like on a TCP connection, so a message may be delayed by the message before it.
+func (s *Simulation) send(from, to hotstuff.ID, msg interface{}) {
+	s.stats.Sent++
+	if s.tap != nil {
+		s.tap(from, to, msg)
+	}
+	if !s.connected(from, to) || (s.loss > 0 && s.rnd.Float64() < s.loss) {
+		s.stats.Dropped++
+		return
+	}
+	now := s.clock.Now()
+	at := now.Add(s.latency.Sample(s.rnd))
+	l := link{from, to}
+	if last := s.links[l]; at.Before(last) {
+		at = last
+	}
+	s.links[l] = at
+	receiver := s.nodes[to]
+	s.clock.AfterFunc(at.Sub(now), func() {
+		// the receiver may have crashed while the message was in flight.
+		if !receiver.crashed {
+			receiver.loop.AddEvent(msg)
+		}
+	})
+}
+
+// connected returns true if messages from a replica reach the other replica:
+// when neither of them has crashed, and they are in the same partition.
+func (s *Simulation) connected(from, to hotstuff.ID) bool {
+	sender, receiver := s.nodes[from], s.nodes[to]
+	return !sender.crashed && !receiver.crashed && sender.partition == receiver.partition
+}
+
+// configuration is the configuration of a replica in a simulation. It sends the messages of the replica to the other
+// replicas through the simulated network.
+type configuration struct {
+	sim  *Simulation
+	node *node
+}
+
+// broadcast sends the message to all other replicas, in the order of their IDs.
+func (c *configuration) broadcast(msg interface{}) {
+	for _, id := range c.sim.ids {
+		if id != c.node.id {
+			c.sim.send(c.node.id, id, msg)
+		}
+	}
+}
+
+// Replicas returns all of the replicas in the configuration.
+func (c *configuration) Replicas() map[hotstuff.ID]consensus.Replica {
+	replicas := make(map[hotstuff.ID]consensus.Replica, len(c.sim.ids))
+	for _, id := range c.sim.ids {
+		replicas[id] = &replica{config: c, id: id}
+	}
+	return replicas
+}
+
+// Replica returns a replica if present in the configuration.
+func (c *configuration) Replica(id hotstuff.ID) (r consensus.Replica, ok bool) {
+	if _, ok := c.sim.nodes[id]; !ok {
+		return nil, false
+	}
+	return &replica{config: c, id: id}, true
+}
+
+// Len returns the number of replicas in the configuration.
+func (c *configuration) Len() int {
+	return len(c.sim.ids)
+}
+
+// QuorumSize returns the size of a quorum.
+func (c *configuration) QuorumSize() int {
+	return hotstuff.QuorumSize(c.Len())
+}
+
+// Propose sends the block to all replicas in the configuration.
+func (c *configuration) Propose(proposal consensus.ProposeMsg) {
+	c.broadcast(proposal)
+}
+
+// Timeout sends the timeout message to all replicas.
+func (c *configuration) Timeout(msg consensus.TimeoutMsg) {
+	c.broadcast(msg)
+}
+
+// Fetch requests a block from the replicas that the replica is connected to. The block is returned at once,
+// since the replica cannot wait for the virtual time to pass while it is processing an event.
+func (c *configuration) Fetch(_ context.Context, hash consensus.Hash) (block *consensus.Block, ok bool) {
+	for _, id := range c.sim.ids {
+		if id == c.node.id || !c.sim.connected(c.node.id, id) {
+			continue
+		}
+		if block, ok = c.sim.nodes[id].mods.BlockChain().LocalGet(hash); ok {
+			return block, true
+		}
+	}
+	return nil, false
+}
+
+type replica struct {
+	config *configuration // the configuration of the replica that sends to this replica
+	id     hotstuff.ID
+}
+
+// ID returns the replica's id.
+func (r *replica) ID() hotstuff.ID {
+	return r.id
+}
+
+// PublicKey returns the replica's public key.
+func (r *replica) PublicKey() consensus.PublicKey {
+	return r.config.sim.nodes[r.id].mods.PrivateKey().Public()
+}
+
+// Vote sends the partial certificate to the other replica.
+func (r *replica) Vote(cert consensus.PartialCert) {
+	r.config.sim.send(r.config.node.id, r.id, consensus.VoteMsg{
+		ID:          r.config.node.id,
+		PartialCert: cert,
+	})
+}
+
+// NewView sends the quorum certificate to the other replica.
+func (r *replica) NewView(si consensus.SyncInfo) {
+	r.config.sim.send(r.config.node.id, r.id, consensus.NewViewMsg{
+		ID:       r.config.node.id,
+		SyncInfo: si,
+	})
+}
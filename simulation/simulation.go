@@ -0,0 +1,330 @@
+// Package simulation runs many replicas in one process on a virtual clock and a simulated network, such that protocol
+// runs that would take minutes of real time finish in seconds.
+//
+// The replicas share a discrete-event clock, and their event loops are driven by the simulation rather than by their
+// own goroutines. The simulation processes the events of every replica until all of them are idle, and only then
+// advances the clock to the time of the next timer, which may deliver a message or expire a view. Since the replicas,
+// the messages, and the timers are processed in a fixed order, and the latencies and losses are drawn from a random
+// number generator with a fixed seed, a simulation plays out the same way every time it is run with the same seed.
+//
+// Faults are injected with Crash and Partition, which can be scheduled at given virtual times with At, and the
+// invariants that are added with AddInvariant are checked whenever the replicas are idle.
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/blockchain"
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/crypto"
+	"github.com/relab/hotstuff/crypto/bls12"
+	_ "github.com/relab/hotstuff/crypto/ecdsa"
+	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/eventloop"
+	"github.com/relab/hotstuff/internal/testutil"
+	"github.com/relab/hotstuff/leaderrotation"
+	"github.com/relab/hotstuff/logging"
+	"github.com/relab/hotstuff/synchronizer"
+)
+
+// Config configures a simulation.
+type Config struct {
+	// The number of replicas, which get the IDs 1 to Replicas.
+	Replicas int
+	// The name of the consensus rules, which must be registered. Chained HotStuff is used if this is empty.
+	Consensus string
+	// The name of the crypto implementation, either "ecdsa" or "bls12". ECDSA is used if this is empty.
+	Crypto string
+	// How long the replicas wait for a view to succeed before they time out. 100 milliseconds is used if this is zero.
+	ViewTimeout time.Duration
+	// The distribution of the one-way latencies of the messages. The messages are delivered at once if this is nil.
+	Latency Latency
+	// The probability that a message is lost, between 0 and 1.
+	Loss float64
+	// The seed of the random number generator from which the latencies and losses are drawn.
+	Seed int64
+	// The destination of the logs of the replicas. The logs are discarded if this is nil.
+	Log io.Writer
+	// Tap is called with each message that a replica sends to another replica, before the message is delivered
+	// or dropped. It can be used to record the messages of a simulation.
+	Tap func(from, to hotstuff.ID, msg interface{})
+}
+
+// Stats counts the messages and events of a simulation.
+type Stats struct {
+	Sent    uint64 // the number of messages that were sent
+	Dropped uint64 // the number of messages that were lost or dropped because the replicas were disconnected
+	Events  uint64 // the number of events that were processed by the replicas
+}
+
+// node is a replica in a simulation.
+type node struct {
+	id        hotstuff.ID
+	mods      *consensus.Modules
+	loop      *eventloop.EventLoop
+	committed []*consensus.Block
+	crashed   bool
+	partition int
+}
+
+// Simulation runs replicas on a virtual clock and a simulated network.
+type Simulation struct {
+	clock      *clock
+	start      time.Time
+	rnd        *rand.Rand
+	latency    Latency
+	loss       float64
+	ids        []hotstuff.ID // the IDs of the replicas, in increasing order
+	nodes      map[hotstuff.ID]*node
+	links      map[link]time.Time // the time at which the last message on each link is delivered
+	tap        func(from, to hotstuff.ID, msg interface{})
+	verified   *verifiedSet
+	invariants []Invariant
+	started    bool
+	stats      Stats
+}
+
+// New returns a simulation of the replicas that are given by the configuration.
+func New(conf Config) (*Simulation, error) {
+	if conf.Replicas <= 0 {
+		return nil, fmt.Errorf("a simulation needs at least one replica")
+	}
+	if conf.Loss < 0 || conf.Loss > 1 {
+		return nil, fmt.Errorf("invalid loss probability: %v", conf.Loss)
+	}
+	if conf.Consensus == "" {
+		conf.Consensus = "chainedhotstuff"
+	}
+	if conf.Crypto == "" {
+		conf.Crypto = "ecdsa"
+	}
+	if conf.ViewTimeout <= 0 {
+		conf.ViewTimeout = 100 * time.Millisecond
+	}
+	if conf.Latency == nil {
+		conf.Latency = FixedLatency(0)
+	}
+	if conf.Log == nil {
+		conf.Log = io.Discard
+	}
+	start := time.Unix(0, 0)
+	s := &Simulation{
+		clock:    newClock(start),
+		start:    start,
+		rnd:      rand.New(rand.NewSource(conf.Seed)),
+		latency:  conf.Latency,
+		loss:     conf.Loss,
+		tap:      conf.Tap,
+		nodes:    make(map[hotstuff.ID]*node),
+		links:    make(map[link]time.Time),
+		verified: newVerifiedSet(),
+	}
+	for i := 1; i <= conf.Replicas; i++ {
+		s.ids = append(s.ids, hotstuff.ID(i))
+	}
+	for _, id := range s.ids {
+		if err := s.createNode(id, conf); err != nil {
+			return nil, fmt.Errorf("failed to create replica %d: %w", id, err)
+		}
+	}
+	return s, nil
+}
+
+func (s *Simulation) createNode(id hotstuff.ID, conf Config) (err error) {
+	var key consensus.PrivateKey
+	switch conf.Crypto {
+	case "ecdsa":
+		key, err = keygen.GenerateECDSAPrivateKey()
+	case "bls12":
+		key, err = bls12.GeneratePrivateKey()
+	default:
+		return fmt.Errorf("unknown crypto implementation: %s", conf.Crypto)
+	}
+	if err != nil {
+		return err
+	}
+	impl, err := consensus.GetCrypto(conf.Crypto)
+	if err != nil {
+		return err
+	}
+	rules, err := consensus.GetRules(conf.Consensus)
+	if err != nil {
+		return err
+	}
+	n := &node{id: id, loop: eventloop.NewSynchronous()}
+	builder := consensus.NewBuilder(id, key)
+	builder.Register(
+		n.loop,
+		s.clock,
+		logging.NewWithDest(conf.Log, "hs"+strconv.Itoa(int(id))),
+		blockchain.New(),
+		consensus.New(rules),
+		crypto.New(&sharedVerifier{CryptoImpl: impl, verified: s.verified}),
+		synchronizer.New(testutil.FixedTimeout(conf.ViewTimeout)),
+		leaderrotation.NewRoundRobin(),
+		&configuration{sim: s, node: n},
+		&commandModule{node: n},
+	)
+	// the votes are verified and the missing blocks are fetched on the event loop,
+	// such that they are processed before the replica is idle.
+	builder.OptionsBuilder().SetShouldVerifyVotesSync()
+	builder.OptionsBuilder().SetShouldFetchSync()
+	if n.mods, err = builder.TryBuild(); err != nil {
+		return err
+	}
+	s.nodes[id] = n
+	return nil
+}
+
+// Run runs the simulation until the virtual time has advanced by d, and returns an error if an invariant is violated.
+// The replicas are started by the first call to Run.
+func (s *Simulation) Run(d time.Duration) error {
+	if !s.started {
+		s.started = true
+		for _, id := range s.ids {
+			s.nodes[id].mods.Synchronizer().Start(context.Background())
+		}
+	}
+	end := s.clock.Now().Add(d)
+	for {
+		s.runUntilIdle()
+		if err := s.check(); err != nil {
+			return err
+		}
+		next, ok := s.clock.next()
+		if !ok || next.After(end) {
+			break
+		}
+		s.clock.advance(next)
+	}
+	s.clock.advance(end)
+	return nil
+}
+
+// runUntilIdle processes the events of the replicas that have not crashed, in the order of their IDs,
+// until none of them have events to process.
+func (s *Simulation) runUntilIdle() {
+	for {
+		processed := 0
+		for _, id := range s.ids {
+			if n := s.nodes[id]; !n.crashed {
+				processed += n.loop.RunUntilIdle()
+			}
+		}
+		if processed == 0 {
+			return
+		}
+		s.stats.Events += uint64(processed)
+	}
+}
+
+// check checks the invariants, and returns the first violation.
+func (s *Simulation) check() error {
+	for _, invariant := range s.invariants {
+		if err := invariant(s); err != nil {
+			return fmt.Errorf("invariant violated at %v: %w", s.Now(), err)
+		}
+	}
+	return nil
+}
+
+// At calls f when the virtual time has advanced by t since the start of the simulation, or at once if it has already
+// advanced that far. It can be used to inject faults.
+func (s *Simulation) At(t time.Duration, f func()) {
+	s.clock.AfterFunc(s.start.Add(t).Sub(s.clock.Now()), f)
+}
+
+// AddInvariant adds an invariant that is checked whenever the replicas are idle.
+func (s *Simulation) AddInvariant(invariant Invariant) {
+	s.invariants = append(s.invariants, invariant)
+}
+
+// Crash stops the replica. It does not process any events after this, and its messages are dropped,
+// including those that are in flight.
+func (s *Simulation) Crash(id hotstuff.ID) {
+	s.nodes[id].crashed = true
+}
+
+// Partition splits the network into the given groups of replicas, such that the messages between replicas
+// in different groups are dropped. The replicas that are not in any group form a group of their own.
+// It replaces the previous partitions.
+func (s *Simulation) Partition(groups ...[]hotstuff.ID) {
+	for _, n := range s.nodes {
+		n.partition = 0
+	}
+	for i, group := range groups {
+		for _, id := range group {
+			s.nodes[id].partition = i + 1
+		}
+	}
+}
+
+// Heal removes the partitions of the network.
+func (s *Simulation) Heal() {
+	s.Partition()
+}
+
+// Now returns how far the virtual time has advanced since the start of the simulation.
+func (s *Simulation) Now() time.Duration {
+	return s.clock.Now().Sub(s.start)
+}
+
+// Replicas returns the IDs of the replicas, in increasing order.
+func (s *Simulation) Replicas() []hotstuff.ID {
+	return append([]hotstuff.ID(nil), s.ids...)
+}
+
+// View returns the current view of the replica.
+func (s *Simulation) View(id hotstuff.ID) consensus.View {
+	return s.nodes[id].mods.Synchronizer().View()
+}
+
+// Committed returns the blocks that the replica has committed, in the order they were committed.
+func (s *Simulation) Committed(id hotstuff.ID) []*consensus.Block {
+	return s.nodes[id].committed
+}
+
+// Crashed returns true if the replica has crashed.
+func (s *Simulation) Crashed(id hotstuff.ID) bool {
+	return s.nodes[id].crashed
+}
+
+// Stats returns the number of messages and events of the simulation so far.
+func (s *Simulation) Stats() Stats {
+	return s.stats
+}
+
+// commandModule proposes a new command in every view, and records the blocks that the replica commits.
+type commandModule struct {
+	node *node
+	next uint64
+}
+
+// Get returns the next command to be proposed, which is unique to the replica.
+func (c *commandModule) Get(_ context.Context) (cmd consensus.Command, ok bool) {
+	c.next++
+	return consensus.Command(fmt.Sprintf("%d:%d", c.node.id, c.next)), true
+}
+
+// Accept returns true if the replica should accept the command, false otherwise.
+func (c *commandModule) Accept(_ consensus.Command) bool {
+	return true
+}
+
+// Proposed tells the acceptor that the propose phase for the given command succeeded, and it should no longer be
+// accepted in the future.
+func (c *commandModule) Proposed(_ consensus.Command) {}
+
+// Exec records the committed block.
+func (c *commandModule) Exec(block *consensus.Block) {
+	c.node.committed = append(c.node.committed, block)
+}
+
+// Fork is called when a block is forked.
+func (c *commandModule) Fork(_ *consensus.Block) {}
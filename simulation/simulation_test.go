@@ -0,0 +1,161 @@
+package simulation_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relab/hotstuff"
+	"github.com/relab/hotstuff/consensus"
+	_ "github.com/relab/hotstuff/consensus/chainedhotstuff"
+	_ "github.com/relab/hotstuff/consensus/fasthotstuff"
+	"github.com/relab/hotstuff/simulation"
+)
+
+// committedViews returns the views and commands of the blocks that the replica committed.
+func committedViews(sim *simulation.Simulation, id hotstuff.ID) []string {
+	var views []string
+	for _, block := range sim.Committed(id) {
+		views = append(views, fmt.Sprintf("%d/%s", block.View(), block.Command()))
+	}
+	return views
+}
+
+func TestSimulation(t *testing.T) {
+	const duration = time.Minute
+	run := func() *simulation.Simulation {
+		sim, err := simulation.New(simulation.Config{
+			Replicas: 7,
+			Latency:  simulation.NormalLatency(10*time.Millisecond, 2*time.Millisecond),
+			Loss:     0.001,
+			Seed:     1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sim.AddInvariant(simulation.SingleChain())
+		sim.AddInvariant(simulation.BoundedViewDivergence(2))
+		start := time.Now()
+		if err := sim.Run(duration); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("simulated %v in %v: %+v", sim.Now(), time.Since(start), sim.Stats())
+		return sim
+	}
+
+	sim := run()
+	if sim.Now() != duration {
+		t.Errorf("the simulation ran for %v, want %v", sim.Now(), duration)
+	}
+	commits := len(sim.Committed(1))
+	t.Logf("replica 1 committed %d blocks and is in view %d", commits, sim.View(1))
+	if commits < 1000 {
+		t.Errorf("replica 1 committed %d blocks, want at least 1000", commits)
+	}
+	if last := sim.Committed(1)[commits-1].View(); last < 1000 {
+		t.Errorf("the last committed block is in view %d, want at least 1000", last)
+	}
+
+	// the same seed gives the same run.
+	again := run()
+	for _, id := range sim.Replicas() {
+		if got, want := committedViews(again, id), committedViews(sim, id); !reflect.DeepEqual(got, want) {
+			t.Errorf("replica %d committed %d blocks in the second run, and %d blocks in the first run, or other blocks",
+				id, len(got), len(want))
+		}
+		if got, want := again.View(id), sim.View(id); got != want {
+			t.Errorf("replica %d ended in view %d in the second run, and in view %d in the first run", id, got, want)
+		}
+	}
+}
+
+func TestFaults(t *testing.T) {
+	sim, err := simulation.New(simulation.Config{
+		Replicas: 7,
+		Latency:  simulation.UniformLatency(5*time.Millisecond, 15*time.Millisecond),
+		Seed:     2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.AddInvariant(simulation.SingleChain())
+
+	// two replicas crash, and then a third is cut off for a while, such that there is no quorum.
+	sim.At(5*time.Second, func() {
+		sim.Crash(3)
+		sim.Crash(5)
+	})
+	var stalled int
+	sim.At(10*time.Second, func() {
+		sim.Partition([]hotstuff.ID{7})
+		stalled = len(sim.Committed(1))
+	})
+	sim.At(15*time.Second, func() {
+		if n := len(sim.Committed(1)); n > stalled+2 {
+			t.Errorf("replica 1 committed %d blocks without a quorum, want at most 2", n-stalled)
+		}
+		sim.Heal()
+		stalled = len(sim.Committed(1))
+	})
+	if err := sim.Run(30 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	// the views of the crashed leaders time out, and break the chains of the views that follow them.
+	if n := len(sim.Committed(1)); n < stalled+50 {
+		t.Errorf("replica 1 committed %d blocks after the partition healed, want at least 50", n-stalled)
+	}
+	if n := len(sim.Committed(7)); n != len(sim.Committed(1)) {
+		t.Errorf("replica 7 committed %d blocks after it rejoined, want %d", n, len(sim.Committed(1)))
+	}
+	if crashed := sim.Committed(3); len(crashed) == 0 || len(crashed) >= len(sim.Committed(1)) {
+		t.Errorf("replica 3 committed %d blocks before it crashed, want some, but fewer than %d", len(crashed), len(sim.Committed(1)))
+	}
+}
+
+func TestInvariantViolation(t *testing.T) {
+	sim, err := simulation.New(simulation.Config{Replicas: 4, Latency: simulation.FixedLatency(10 * time.Millisecond)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.AddInvariant(simulation.BoundedViewDivergence(5))
+	// the replica that is cut off stays behind while the others make progress.
+	sim.At(time.Second, func() { sim.Partition([]hotstuff.ID{4}) })
+	err = sim.Run(10 * time.Second)
+	if err == nil || !strings.Contains(err.Error(), "replica 4") {
+		t.Fatalf("got the error %v, want a violation of the view divergence by replica 4", err)
+	}
+	if now := sim.Now(); now <= time.Second || now >= 2*time.Second {
+		t.Errorf("the violation was detected at %v, want shortly after the partition", now)
+	}
+}
+
+func TestBLS12(t *testing.T) {
+	var proposals int
+	sim, err := simulation.New(simulation.Config{
+		Replicas:  4,
+		Consensus: "fasthotstuff",
+		Crypto:    "bls12",
+		Latency:   simulation.FixedLatency(10 * time.Millisecond),
+		Tap: func(_, _ hotstuff.ID, msg interface{}) {
+			if _, ok := msg.(consensus.ProposeMsg); ok {
+				proposals++
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.AddInvariant(simulation.SingleChain())
+	if err := sim.Run(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(sim.Committed(1)); n < 100 {
+		t.Errorf("replica 1 committed %d blocks, want at least 100", n)
+	}
+	// each proposal is sent to the three other replicas.
+	if proposals < 3*len(sim.Committed(1)) {
+		t.Errorf("saw %d proposals for %d committed blocks", proposals, len(sim.Committed(1)))
+	}
+}
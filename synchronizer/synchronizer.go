@@ -7,6 +7,7 @@ import (
 
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/eventloop"
 )
 
 // Synchronizer synchronizes replicas to the same view.
@@ -24,7 +25,10 @@ type Synchronizer struct {
 	lastTimeout *consensus.TimeoutMsg
 
 	duration ViewDuration
-	timer    *time.Timer
+	// cancels the local timeout of the current view, which is scheduled on the event loop once the synchronizer
+	// has been started.
+	cancelTimeout eventloop.CancelFunc
+	started       bool
 
 	viewCtx   context.Context // a context that is cancelled at the end of the current view
 	cancelCtx context.CancelFunc
@@ -40,17 +44,27 @@ func (s *Synchronizer) InitConsensusModule(mods *consensus.Modules, opts *consen
 		duration.InitConsensusModule(mods, opts)
 	}
 	s.mods = mods
+	if !s.mods.Require(s, consensus.BlockChainModule, consensus.ConfigurationModule, consensus.ConsensusModule,
+		consensus.CryptoModule, consensus.LeaderRotationModule) {
+		return
+	}
 
-	s.mods.EventLoop().RegisterHandler(consensus.NewViewMsg{}, func(event interface{}) {
+	// the events that advance the view are processed ahead of the proposals and votes that may have piled up,
+	// such that the views do not expire while they wait.
+	s.mods.EventLoop().RegisterPriorityHandler(consensus.NewViewMsg{}, func(event interface{}) {
 		newViewMsg := event.(consensus.NewViewMsg)
 		s.OnNewView(newViewMsg)
 	})
 
-	s.mods.EventLoop().RegisterHandler(consensus.TimeoutMsg{}, func(event interface{}) {
+	s.mods.EventLoop().RegisterPriorityHandler(consensus.TimeoutMsg{}, func(event interface{}) {
 		timeoutMsg := event.(consensus.TimeoutMsg)
 		s.OnRemoteTimeout(timeoutMsg)
 	})
 
+	s.mods.EventLoop().RegisterPriorityHandler(LocalTimeoutEvent{}, func(_ interface{}) {
+		s.OnLocalTimeout()
+	})
+
 	var err error
 	s.highQC, err = s.mods.Crypto().CreateQuorumCert(consensus.GetGenesis(), []consensus.PartialCert{})
 	if err != nil {
@@ -74,24 +88,18 @@ func New(viewDuration ViewDuration) consensus.Synchronizer {
 		cancelCtx: cancel,
 
 		duration: viewDuration,
-		timer:    time.AfterFunc(0, func() {}), // dummy timer that will be replaced after start() is called
 
 		timeouts: make(map[consensus.View]map[hotstuff.ID]consensus.TimeoutMsg),
 	}
 }
 
-// Start starts the synchronizer with the given context.
-func (s *Synchronizer) Start(ctx context.Context) {
-	s.timer = time.AfterFunc(s.duration.Duration(), func() {
-		// The event loop will execute onLocalTimeout for us.
-		s.cancelCtx()
-		s.mods.EventLoop().AddEvent(s.OnLocalTimeout)
-	})
-
-	go func() {
-		<-ctx.Done()
-		s.timer.Stop()
-	}()
+// Start starts the synchronizer with the given context. The local timeouts are scheduled on the event loop,
+// which drops them when it stops.
+func (s *Synchronizer) Start(_ context.Context) {
+	s.started = true
+	duration := s.duration.Duration()
+	s.newCtx(duration)
+	s.startTimer(duration)
 
 	// start the initial proposal
 	if s.currentView == 1 && s.mods.LeaderRotation().GetLeader(s.currentView) == s.mods.ID() {
@@ -99,6 +107,14 @@ func (s *Synchronizer) Start(ctx context.Context) {
 	}
 }
 
+// RestoreView makes the view the current view, if it is later than the current view.
+// It must be called before the synchronizer is started.
+func (s *Synchronizer) RestoreView(view consensus.View) {
+	if view > s.currentView {
+		s.currentView = view
+	}
+}
+
 // HighQC returns the highest known QC.
 func (s *Synchronizer) HighQC() consensus.QuorumCert {
 	return s.highQC
@@ -138,8 +154,10 @@ func (s *Synchronizer) OnLocalTimeout() {
 		if s.viewCtx.Err() != nil {
 			s.newCtx(s.duration.Duration())
 		}
-		s.timer.Reset(s.duration.Duration())
+		s.startTimer(s.duration.Duration())
 	}()
+	// the operations that wait for the end of the view are cancelled.
+	s.cancelCtx()
 
 	if s.lastTimeout != nil && s.lastTimeout.View == s.currentView {
 		s.mods.Configuration().Timeout(*s.lastTimeout)
@@ -285,7 +303,7 @@ func (s *Synchronizer) AdvanceView(syncInfo consensus.SyncInfo) {
 		return
 	}
 
-	s.timer.Stop()
+	s.stopTimer()
 
 	if !timeout {
 		s.duration.ViewSucceeded()
@@ -298,7 +316,7 @@ func (s *Synchronizer) AdvanceView(syncInfo consensus.SyncInfo) {
 	duration := s.duration.Duration()
 	// cancel the old view context and set up the next one
 	s.newCtx(duration)
-	s.timer.Reset(duration)
+	s.startTimer(duration)
 
 	s.mods.Logger().Debugf("advanced to view %d", s.currentView)
 	s.mods.EventLoop().AddEvent(ViewChangeEvent{View: s.currentView, Timeout: timeout})
@@ -337,6 +355,23 @@ func (s *Synchronizer) UpdateHighQC(qc consensus.QuorumCert) {
 	}
 }
 
+// startTimer schedules the local timeout of the current view after the duration, replacing the scheduled timeout.
+// The timeouts are not scheduled before the synchronizer is started, such as when the views are driven by a test.
+func (s *Synchronizer) startTimer(duration time.Duration) {
+	s.stopTimer()
+	if s.started {
+		s.cancelTimeout = s.mods.EventLoop().AddEventAfter(duration, LocalTimeoutEvent{})
+	}
+}
+
+// stopTimer cancels the scheduled local timeout, if any.
+func (s *Synchronizer) stopTimer() {
+	if s.cancelTimeout != nil {
+		s.cancelTimeout()
+		s.cancelTimeout = nil
+	}
+}
+
 func (s *Synchronizer) newCtx(duration time.Duration) {
 	s.cancelCtx()
 	s.viewCtx, s.cancelCtx = context.WithTimeout(context.Background(), duration)
@@ -344,6 +379,9 @@ func (s *Synchronizer) newCtx(duration time.Duration) {
 
 var _ consensus.Synchronizer = (*Synchronizer)(nil)
 
+// LocalTimeoutEvent is sent on the event loop when the timer of the current view expires.
+type LocalTimeoutEvent struct{}
+
 // ViewChangeEvent is sent on the metrics event loop whenever a view change occurs.
 type ViewChangeEvent struct {
 	View    consensus.View
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/eventloop"
 	"github.com/relab/hotstuff/internal/mocks"
 	"github.com/relab/hotstuff/internal/testutil"
 	. "github.com/relab/hotstuff/synchronizer"
@@ -17,14 +19,15 @@ func TestLocalTimeout(t *testing.T) {
 	qc := consensus.NewQuorumCert(nil, 0, consensus.GetGenesis().Hash())
 	builder := testutil.TestModules(t, ctrl, 2, testutil.GenerateECDSAKey(t))
 	hs := mocks.NewMockConsensus(ctrl)
-	s := New(testutil.FixedTimeout(10))
-	builder.Register(hs, s)
+	s := New(testutil.FixedTimeout(10 * time.Millisecond))
+	clock := testutil.NewFakeClock(time.Unix(0, 0))
+	builder.Register(hs, s, eventloop.NewSynchronous(), clock)
 	mods := builder.Build()
 	cfg := mods.Configuration().(*mocks.MockConfiguration)
 	leader := testutil.CreateMockReplica(t, ctrl, 1, testutil.GenerateECDSAKey(t))
 	testutil.ConfigAddReplica(t, cfg, leader)
 
-	c := make(chan struct{})
+	timeouts := 0
 	hs.EXPECT().StopVoting(consensus.View(1)).AnyTimes()
 	cfg.
 		EXPECT().
@@ -42,15 +45,45 @@ func TestLocalTimeout(t *testing.T) {
 			if !mods.Crypto().Verify(msg.ViewSignature, msg.View.ToHash()) {
 				t.Error("failed to verify signature")
 			}
-			c <- struct{}{}
+			timeouts++
 		}).AnyTimes()
-	ctx, cancel := context.WithCancel(context.Background())
-	go func() {
-		mods.Synchronizer().Start(ctx)
-		mods.Run(ctx)
-	}()
-	<-c
-	cancel()
+
+	mods.Synchronizer().Start(context.Background())
+	mods.EventLoop().RunUntilIdle()
+	if timeouts != 0 {
+		t.Fatalf("got %d timeouts before the view duration had elapsed, want 0", timeouts)
+	}
+
+	clock.Step(10 * time.Millisecond)
+	mods.EventLoop().RunUntilIdle()
+	if timeouts != 1 {
+		t.Errorf("got %d timeouts after the view duration had elapsed, want 1", timeouts)
+	}
+}
+
+func TestViewDuration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	builder := testutil.TestModules(t, ctrl, 2, testutil.GenerateECDSAKey(t))
+	vd := NewViewDuration(5, 1000, 0, 2)
+	clock := testutil.NewFakeClock(time.Unix(0, 0))
+	builder.Register(mocks.NewMockConsensus(ctrl), New(vd), eventloop.NewSynchronous(), clock)
+	builder.Build()
+
+	if got := vd.Duration(); got != time.Second {
+		t.Errorf("got duration %v before any views, want %v", got, time.Second)
+	}
+	for i := 0; i < 3; i++ {
+		vd.ViewStarted()
+		clock.Step(100 * time.Millisecond)
+		vd.ViewSucceeded()
+	}
+	if got := vd.Duration(); got != 100*time.Millisecond {
+		t.Errorf("got duration %v after views of 100ms, want %v", got, 100*time.Millisecond)
+	}
+	vd.ViewTimeout()
+	if got := vd.Duration(); got != 200*time.Millisecond {
+		t.Errorf("got duration %v after a timeout, want %v", got, 200*time.Millisecond)
+	}
 }
 
 func TestAdvanceViewQC(t *testing.T) {
@@ -59,7 +92,7 @@ func TestAdvanceViewQC(t *testing.T) {
 	builders := testutil.CreateBuilders(t, ctrl, n)
 	s := New(testutil.FixedTimeout(1000))
 	hs := mocks.NewMockConsensus(ctrl)
-	builders[0].Register(s, hs)
+	builders[0].Register(s, hs, testutil.NewFakeClock(time.Unix(0, 0)))
 
 	hl := builders.Build()
 	signers := hl.Signers()
@@ -89,7 +122,7 @@ func TestAdvanceViewTC(t *testing.T) {
 	builders := testutil.CreateBuilders(t, ctrl, n)
 	s := New(testutil.FixedTimeout(100))
 	hs := mocks.NewMockConsensus(ctrl)
-	builders[0].Register(s, hs)
+	builders[0].Register(s, hs, testutil.NewFakeClock(time.Unix(0, 0)))
 
 	hl := builders.Build()
 	signers := hl.Signers()
@@ -130,3 +163,27 @@ func TestAdvanceViewTC(t *testing.T) {
 // 		t.Errorf("wrong view: expected: %v, got: %v", 2, s.View())
 // 	}
 // }
+
+// TestRestoreView checks that a restored view is only taken if it is later than the current view, and that the
+// synchronizer does not go back to the views before it.
+func TestRestoreView(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	builders := testutil.CreateBuilders(t, ctrl, 4)
+	s := New(testutil.FixedTimeout(100))
+	hs := mocks.NewMockConsensus(ctrl)
+	builders[0].Register(s, hs, testutil.NewFakeClock(time.Unix(0, 0)))
+	signers := builders.Build().Signers()
+
+	var restorer consensus.ViewRestorer = s.(*Synchronizer)
+	restorer.RestoreView(5)
+	restorer.RestoreView(3)
+	if s.View() != 5 {
+		t.Fatalf("wrong view: expected: %v, got: %v", 5, s.View())
+	}
+
+	hs.EXPECT().Propose(gomock.Any()).Times(0)
+	s.AdvanceView(consensus.NewSyncInfo().WithTC(testutil.CreateTC(t, 2, signers)))
+	if s.View() != 5 {
+		t.Errorf("wrong view: expected: %v, got: %v", 5, s.View())
+	}
+}
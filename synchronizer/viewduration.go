@@ -60,7 +60,7 @@ func (v *viewDuration) ViewSucceeded() {
 		return
 	}
 
-	duration := float64(time.Since(v.startTime)) / float64(time.Millisecond)
+	duration := float64(v.mods.Clock().Now().Sub(v.startTime)) / float64(time.Millisecond)
 	v.count++
 
 	// Reset m2 occasionally such that we will pick up on changes in variance faster.
@@ -94,7 +94,7 @@ func (v *viewDuration) ViewTimeout() {
 
 // ViewStarted records the start time of a view.
 func (v *viewDuration) ViewStarted() {
-	v.startTime = time.Now()
+	v.startTime = v.mods.Clock().Now()
 }
 
 // Duration returns the upper bound of the 95% confidence interval for the mean view duration.
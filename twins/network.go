@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/relab/hotstuff"
 	"github.com/relab/hotstuff/blockchain"
@@ -14,9 +15,9 @@ import (
 	"github.com/relab/hotstuff/crypto"
 	"github.com/relab/hotstuff/crypto/ecdsa"
 	"github.com/relab/hotstuff/crypto/keygen"
+	"github.com/relab/hotstuff/eventloop"
 	"github.com/relab/hotstuff/internal/testutil"
 	"github.com/relab/hotstuff/logging"
-	"github.com/relab/hotstuff/modules"
 	"github.com/relab/hotstuff/synchronizer"
 )
 
@@ -88,11 +89,15 @@ func (n *network) createNodes(nodes []NodeID, scenario Scenario, consensusName s
 			id: nodeID,
 		}
 		builder := consensus.NewBuilder(nodeID.ReplicaID, pk)
-		var consensusModule consensus.Rules
-		if !modules.GetModule(consensusName, &consensusModule) {
-			return fmt.Errorf("unknown consensus module: '%s'", consensusName)
+		consensusModule, err := consensus.GetRules(consensusName)
+		if err != nil {
+			return err
 		}
 		builder.Register(
+			// the events are processed on the goroutine that runs the scenario, and the timers only fire
+			// when the clock is advanced, such that the scenario plays out the same way every time.
+			eventloop.NewSynchronous(),
+			testutil.NewFakeClock(time.Unix(0, 0)),
 			logging.NewWithDest(&node.log, fmt.Sprintf("r%dn%d", nodeID.ReplicaID, nodeID.NetworkID)),
 			blockchain.New(),
 			consensus.New(consensusModule),
@@ -106,7 +111,11 @@ func (n *network) createNodes(nodes []NodeID, scenario Scenario, consensusName s
 			commandModule{commandGenerator: cg, node: &node},
 		)
 		builder.OptionsBuilder().SetShouldVerifyVotesSync()
-		node.modules = builder.Build()
+		builder.OptionsBuilder().SetShouldFetchSync()
+		node.modules, err = builder.TryBuild()
+		if err != nil {
+			return err
+		}
 		n.nodes[nodeID.NetworkID] = &node
 		n.replicas[nodeID.ReplicaID] = append(n.replicas[nodeID.ReplicaID], &node)
 	}
@@ -132,15 +141,13 @@ func (n *network) round(view consensus.View) {
 
 	for _, node := range n.nodes {
 		// run each event loop as long as it has events
-		for node.modules.EventLoop().Tick() {
-		}
+		node.modules.EventLoop().RunUntilIdle()
 	}
 
 	// give the next leader the opportunity to process votes and propose a new block
 	for _, node := range n.nodes {
 		if node.modules.LeaderRotation().GetLeader(view+1) == node.modules.ID() {
-			for node.modules.EventLoop().Tick() {
-			}
+			node.modules.EventLoop().RunUntilIdle()
 		}
 	}
 
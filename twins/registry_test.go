@@ -0,0 +1,69 @@
+package twins_test
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/relab/hotstuff/consensus"
+	"github.com/relab/hotstuff/consensus/chainedhotstuff"
+	"github.com/relab/hotstuff/twins"
+)
+
+// votes counts the votes of all instances of countingRules.
+var votes int32
+
+// countingRules is a consensus implementation from outside of the repository's packages.
+// It follows the rules of chained HotStuff, and counts the votes.
+type countingRules struct {
+	*chainedhotstuff.ChainedHotStuff
+}
+
+func (r countingRules) VoteRule(proposal consensus.ProposeMsg) bool {
+	ok := r.ChainedHotStuff.VoteRule(proposal)
+	if ok {
+		atomic.AddInt32(&votes, 1)
+	}
+	return ok
+}
+
+func init() {
+	consensus.RegisterRules("counting", func() consensus.Rules {
+		return countingRules{chainedhotstuff.New().(*chainedhotstuff.ChainedHotStuff)}
+	})
+}
+
+func TestRegisteredRules(t *testing.T) {
+	allNodes := twins.NodeSet{}
+	for i := uint32(1); i <= 4; i++ {
+		allNodes.Add(i)
+	}
+	var s twins.Scenario
+	for i := 0; i < 4; i++ {
+		s = append(s, twins.View{Leader: 1, Partitions: []twins.NodeSet{allNodes}})
+	}
+
+	result, err := twins.ExecuteScenario(s, 4, 0, "counting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Safe {
+		t.Error("Expected no safety violations")
+	}
+	if result.Commits != 1 {
+		t.Errorf("Expected one commit, got %d", result.Commits)
+	}
+	if atomic.LoadInt32(&votes) == 0 {
+		t.Error("The registered implementation was not used")
+	}
+
+	_, err = twins.ExecuteScenario(s, 4, 0, "no-such-rules")
+	if err == nil {
+		t.Fatal("Expected an error for an unknown implementation")
+	}
+	for _, name := range []string{"no-such-rules", "chainedhotstuff", "counting"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Error %q does not mention %q", err, name)
+		}
+	}
+}